@@ -1,6 +1,8 @@
 package actor_v1alpha
 
 import (
+	"slices"
+
 	entity "miren.dev/runtime/pkg/entity"
 	schema "miren.dev/runtime/pkg/entity/schema"
 )
@@ -53,6 +55,13 @@ func (o *Actor) Encode() (attrs []entity.Attr) {
 	return
 }
 
+func (o *Actor) EncodeChanges(old entity.AttrGetter) (ops entity.AttrOps) {
+	desired := entity.New(o.Encode())
+	ops = append(ops, entity.DiffOne(old, desired, ActorNodeId)...)
+	ops = append(ops, entity.DiffOne(old, desired, ActorStateId)...)
+	return
+}
+
 func (o *Actor) Empty() bool {
 	if !entity.Empty(o.Node) {
 		return false
@@ -63,6 +72,19 @@ func (o *Actor) Empty() bool {
 	return true
 }
 
+func (o *Actor) DeepCopy() *Actor {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	out.State = slices.Clone(o.State)
+	return &out
+}
+
+func (o *Actor) Validate() error {
+	return nil
+}
+
 func (o *Actor) InitSchema(sb *schema.SchemaBuilder) {
 	sb.Ref("node", "dev.miren.actor/actor.node", schema.Doc("The node that is serving the actor"))
 	sb.Bytes("state", "dev.miren.actor/actor.state", schema.Doc("The state of an actor"))
@@ -110,6 +132,12 @@ func (o *Node) Encode() (attrs []entity.Attr) {
 	return
 }
 
+func (o *Node) EncodeChanges(old entity.AttrGetter) (ops entity.AttrOps) {
+	desired := entity.New(o.Encode())
+	ops = append(ops, entity.DiffMany(old, desired, NodeEndpointId)...)
+	return
+}
+
 func (o *Node) Empty() bool {
 	if len(o.Endpoint) != 0 {
 		return false
@@ -117,6 +145,19 @@ func (o *Node) Empty() bool {
 	return true
 }
 
+func (o *Node) DeepCopy() *Node {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	out.Endpoint = slices.Clone(o.Endpoint)
+	return &out
+}
+
+func (o *Node) Validate() error {
+	return nil
+}
+
 func (o *Node) InitSchema(sb *schema.SchemaBuilder) {
 	sb.String("endpoint", "dev.miren.actor/node.endpoint", schema.Doc("The address to dial for the node"), schema.Many)
 }
@@ -35,6 +35,10 @@ func (v *LogTarget) SetApp(app string) {
 	v.data.App = &app
 }
 
+func (v *LogTarget) ClearApp() {
+	v.data.App = nil
+}
+
 func (v *LogTarget) HasSandbox() bool {
 	return v.data.Sandbox != nil
 }
@@ -50,6 +54,10 @@ func (v *LogTarget) SetSandbox(sandbox string) {
 	v.data.Sandbox = &sandbox
 }
 
+func (v *LogTarget) ClearSandbox() {
+	v.data.Sandbox = nil
+}
+
 func (v *LogTarget) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -89,6 +97,10 @@ func (v *AutoConcurrency) SetFactor(factor int32) {
 	v.data.Factor = &factor
 }
 
+func (v *AutoConcurrency) ClearFactor() {
+	v.data.Factor = nil
+}
+
 func (v *AutoConcurrency) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -129,6 +141,10 @@ func (v *ServiceCommand) SetService(service string) {
 	v.data.Service = &service
 }
 
+func (v *ServiceCommand) ClearService() {
+	v.data.Service = nil
+}
+
 func (v *ServiceCommand) HasCommand() bool {
 	return v.data.Command != nil
 }
@@ -144,6 +160,10 @@ func (v *ServiceCommand) SetCommand(command string) {
 	v.data.Command = &command
 }
 
+func (v *ServiceCommand) ClearCommand() {
+	v.data.Command = nil
+}
+
 func (v *ServiceCommand) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -184,6 +204,10 @@ func (v *ServiceConfig) SetService(service string) {
 	v.data.Service = &service
 }
 
+func (v *ServiceConfig) ClearService() {
+	v.data.Service = nil
+}
+
 func (v *ServiceConfig) HasServiceEnv() bool {
 	return v.data.ServiceEnv != nil
 }
@@ -200,6 +224,10 @@ func (v *ServiceConfig) SetServiceEnv(service_env []*NamedValue) {
 	v.data.ServiceEnv = &x
 }
 
+func (v *ServiceConfig) ClearServiceEnv() {
+	v.data.ServiceEnv = nil
+}
+
 func (v *ServiceConfig) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -245,6 +273,10 @@ func (v *Configuration) SetEnvVars(env_vars []*NamedValue) {
 	v.data.EnvVars = &x
 }
 
+func (v *Configuration) ClearEnvVars() {
+	v.data.EnvVars = nil
+}
+
 func (v *Configuration) HasConcurrency() bool {
 	return v.data.Concurrency != nil
 }
@@ -260,6 +292,10 @@ func (v *Configuration) SetConcurrency(concurrency int32) {
 	v.data.Concurrency = &concurrency
 }
 
+func (v *Configuration) ClearConcurrency() {
+	v.data.Concurrency = nil
+}
+
 func (v *Configuration) HasAutoConcurrency() bool {
 	return v.data.AutoConcurrency != nil
 }
@@ -272,6 +308,10 @@ func (v *Configuration) SetAutoConcurrency(auto_concurrency *AutoConcurrency) {
 	v.data.AutoConcurrency = auto_concurrency
 }
 
+func (v *Configuration) ClearAutoConcurrency() {
+	v.data.AutoConcurrency = nil
+}
+
 func (v *Configuration) HasCommands() bool {
 	return v.data.Commands != nil
 }
@@ -288,6 +328,10 @@ func (v *Configuration) SetCommands(commands []*ServiceCommand) {
 	v.data.Commands = &x
 }
 
+func (v *Configuration) ClearCommands() {
+	v.data.Commands = nil
+}
+
 func (v *Configuration) HasEntrypoint() bool {
 	return v.data.Entrypoint != nil
 }
@@ -303,6 +347,10 @@ func (v *Configuration) SetEntrypoint(entrypoint string) {
 	v.data.Entrypoint = &entrypoint
 }
 
+func (v *Configuration) ClearEntrypoint() {
+	v.data.Entrypoint = nil
+}
+
 func (v *Configuration) HasServices() bool {
 	return v.data.Services != nil
 }
@@ -319,6 +367,10 @@ func (v *Configuration) SetServices(services []*ServiceConfig) {
 	v.data.Services = &x
 }
 
+func (v *Configuration) ClearServices() {
+	v.data.Services = nil
+}
+
 func (v *Configuration) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -361,6 +413,10 @@ func (v *NamedValue) SetKey(key string) {
 	v.data.Key = &key
 }
 
+func (v *NamedValue) ClearKey() {
+	v.data.Key = nil
+}
+
 func (v *NamedValue) HasValue() bool {
 	return v.data.Value != nil
 }
@@ -376,6 +432,10 @@ func (v *NamedValue) SetValue(value string) {
 	v.data.Value = &value
 }
 
+func (v *NamedValue) ClearValue() {
+	v.data.Value = nil
+}
+
 func (v *NamedValue) HasSensitive() bool {
 	return v.data.Sensitive != nil
 }
@@ -391,6 +451,10 @@ func (v *NamedValue) SetSensitive(sensitive bool) {
 	v.data.Sensitive = &sensitive
 }
 
+func (v *NamedValue) ClearSensitive() {
+	v.data.Sensitive = nil
+}
+
 func (v *NamedValue) HasSource() bool {
 	return v.data.Source != nil
 }
@@ -406,6 +470,10 @@ func (v *NamedValue) SetSource(source string) {
 	v.data.Source = &source
 }
 
+func (v *NamedValue) ClearSource() {
+	v.data.Source = nil
+}
+
 func (v *NamedValue) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -446,6 +514,10 @@ func (v *VersionInfo) SetVersion(version string) {
 	v.data.Version = &version
 }
 
+func (v *VersionInfo) ClearVersion() {
+	v.data.Version = nil
+}
+
 func (v *VersionInfo) HasCreatedAt() bool {
 	return v.data.CreatedAt != nil
 }
@@ -458,6 +530,10 @@ func (v *VersionInfo) SetCreatedAt(created_at *standard.Timestamp) {
 	v.data.CreatedAt = created_at
 }
 
+func (v *VersionInfo) ClearCreatedAt() {
+	v.data.CreatedAt = nil
+}
+
 func (v *VersionInfo) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -499,6 +575,10 @@ func (v *AppInfo) SetName(name string) {
 	v.data.Name = &name
 }
 
+func (v *AppInfo) ClearName() {
+	v.data.Name = nil
+}
+
 func (v *AppInfo) HasCreatedAt() bool {
 	return v.data.CreatedAt != nil
 }
@@ -511,6 +591,10 @@ func (v *AppInfo) SetCreatedAt(created_at *standard.Timestamp) {
 	v.data.CreatedAt = created_at
 }
 
+func (v *AppInfo) ClearCreatedAt() {
+	v.data.CreatedAt = nil
+}
+
 func (v *AppInfo) HasCurrentVersion() bool {
 	return v.data.CurrentVersion != nil
 }
@@ -523,6 +607,10 @@ func (v *AppInfo) SetCurrentVersion(current_version *VersionInfo) {
 	v.data.CurrentVersion = current_version
 }
 
+func (v *AppInfo) ClearCurrentVersion() {
+	v.data.CurrentVersion = nil
+}
+
 func (v *AppInfo) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -560,6 +648,10 @@ func (v *CpuUsage) SetStart(start *standard.Timestamp) {
 	v.data.Start = start
 }
 
+func (v *CpuUsage) ClearStart() {
+	v.data.Start = nil
+}
+
 func (v *CpuUsage) HasCores() bool {
 	return v.data.Cores != nil
 }
@@ -575,6 +667,10 @@ func (v *CpuUsage) SetCores(cores float64) {
 	v.data.Cores = &cores
 }
 
+func (v *CpuUsage) ClearCores() {
+	v.data.Cores = nil
+}
+
 func (v *CpuUsage) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -612,6 +708,10 @@ func (v *MemoryUsage) SetTimestamp(timestamp *standard.Timestamp) {
 	v.data.Timestamp = timestamp
 }
 
+func (v *MemoryUsage) ClearTimestamp() {
+	v.data.Timestamp = nil
+}
+
 func (v *MemoryUsage) HasBytes() bool {
 	return v.data.Bytes != nil
 }
@@ -627,6 +727,10 @@ func (v *MemoryUsage) SetBytes(bytes int64) {
 	v.data.Bytes = &bytes
 }
 
+func (v *MemoryUsage) ClearBytes() {
+	v.data.Bytes = nil
+}
+
 func (v *MemoryUsage) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -668,6 +772,10 @@ func (v *RequestStat) SetTimestamp(timestamp *standard.Timestamp) {
 	v.data.Timestamp = timestamp
 }
 
+func (v *RequestStat) ClearTimestamp() {
+	v.data.Timestamp = nil
+}
+
 func (v *RequestStat) HasCount() bool {
 	return v.data.Count != nil
 }
@@ -683,6 +791,10 @@ func (v *RequestStat) SetCount(count int64) {
 	v.data.Count = &count
 }
 
+func (v *RequestStat) ClearCount() {
+	v.data.Count = nil
+}
+
 func (v *RequestStat) HasAvgDurationMs() bool {
 	return v.data.AvgDurationMs != nil
 }
@@ -698,6 +810,10 @@ func (v *RequestStat) SetAvgDurationMs(avgDurationMs float64) {
 	v.data.AvgDurationMs = &avgDurationMs
 }
 
+func (v *RequestStat) ClearAvgDurationMs() {
+	v.data.AvgDurationMs = nil
+}
+
 func (v *RequestStat) HasErrorRate() bool {
 	return v.data.ErrorRate != nil
 }
@@ -713,6 +829,10 @@ func (v *RequestStat) SetErrorRate(errorRate float64) {
 	v.data.ErrorRate = &errorRate
 }
 
+func (v *RequestStat) ClearErrorRate() {
+	v.data.ErrorRate = nil
+}
+
 func (v *RequestStat) HasP95DurationMs() bool {
 	return v.data.P95DurationMs != nil
 }
@@ -728,6 +848,10 @@ func (v *RequestStat) SetP95DurationMs(p95DurationMs float64) {
 	v.data.P95DurationMs = &p95DurationMs
 }
 
+func (v *RequestStat) ClearP95DurationMs() {
+	v.data.P95DurationMs = nil
+}
+
 func (v *RequestStat) HasP99DurationMs() bool {
 	return v.data.P99DurationMs != nil
 }
@@ -743,6 +867,10 @@ func (v *RequestStat) SetP99DurationMs(p99DurationMs float64) {
 	v.data.P99DurationMs = &p99DurationMs
 }
 
+func (v *RequestStat) ClearP99DurationMs() {
+	v.data.P99DurationMs = nil
+}
+
 func (v *RequestStat) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -785,6 +913,10 @@ func (v *PathStat) SetPath(path string) {
 	v.data.Path = &path
 }
 
+func (v *PathStat) ClearPath() {
+	v.data.Path = nil
+}
+
 func (v *PathStat) HasCount() bool {
 	return v.data.Count != nil
 }
@@ -800,6 +932,10 @@ func (v *PathStat) SetCount(count int64) {
 	v.data.Count = &count
 }
 
+func (v *PathStat) ClearCount() {
+	v.data.Count = nil
+}
+
 func (v *PathStat) HasAvgDurationMs() bool {
 	return v.data.AvgDurationMs != nil
 }
@@ -815,6 +951,10 @@ func (v *PathStat) SetAvgDurationMs(avgDurationMs float64) {
 	v.data.AvgDurationMs = &avgDurationMs
 }
 
+func (v *PathStat) ClearAvgDurationMs() {
+	v.data.AvgDurationMs = nil
+}
+
 func (v *PathStat) HasErrorRate() bool {
 	return v.data.ErrorRate != nil
 }
@@ -830,6 +970,10 @@ func (v *PathStat) SetErrorRate(errorRate float64) {
 	v.data.ErrorRate = &errorRate
 }
 
+func (v *PathStat) ClearErrorRate() {
+	v.data.ErrorRate = nil
+}
+
 func (v *PathStat) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -871,6 +1015,10 @@ func (v *ErrorBreakdown) SetStatusCode(statusCode int32) {
 	v.data.StatusCode = &statusCode
 }
 
+func (v *ErrorBreakdown) ClearStatusCode() {
+	v.data.StatusCode = nil
+}
+
 func (v *ErrorBreakdown) HasCount() bool {
 	return v.data.Count != nil
 }
@@ -886,6 +1034,10 @@ func (v *ErrorBreakdown) SetCount(count int64) {
 	v.data.Count = &count
 }
 
+func (v *ErrorBreakdown) ClearCount() {
+	v.data.Count = nil
+}
+
 func (v *ErrorBreakdown) HasPercentage() bool {
 	return v.data.Percentage != nil
 }
@@ -901,6 +1053,10 @@ func (v *ErrorBreakdown) SetPercentage(percentage float64) {
 	v.data.Percentage = &percentage
 }
 
+func (v *ErrorBreakdown) ClearPercentage() {
+	v.data.Percentage = nil
+}
+
 func (v *ErrorBreakdown) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -943,6 +1099,10 @@ func (v *PoolStatus) SetName(name string) {
 	v.data.Name = &name
 }
 
+func (v *PoolStatus) ClearName() {
+	v.data.Name = nil
+}
+
 func (v *PoolStatus) HasWindows() bool {
 	return v.data.Windows != nil
 }
@@ -959,6 +1119,10 @@ func (v *PoolStatus) SetWindows(windows []*WindowStatus) {
 	v.data.Windows = &x
 }
 
+func (v *PoolStatus) ClearWindows() {
+	v.data.Windows = nil
+}
+
 func (v *PoolStatus) HasIdle() bool {
 	return v.data.Idle != nil
 }
@@ -974,6 +1138,10 @@ func (v *PoolStatus) SetIdle(idle int32) {
 	v.data.Idle = &idle
 }
 
+func (v *PoolStatus) ClearIdle() {
+	v.data.Idle = nil
+}
+
 func (v *PoolStatus) HasIdleUsage() bool {
 	return v.data.IdleUsage != nil
 }
@@ -989,6 +1157,10 @@ func (v *PoolStatus) SetIdleUsage(idleUsage int64) {
 	v.data.IdleUsage = &idleUsage
 }
 
+func (v *PoolStatus) ClearIdleUsage() {
+	v.data.IdleUsage = nil
+}
+
 func (v *PoolStatus) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -1030,6 +1202,10 @@ func (v *WindowStatus) SetVersion(version string) {
 	v.data.Version = &version
 }
 
+func (v *WindowStatus) ClearVersion() {
+	v.data.Version = nil
+}
+
 func (v *WindowStatus) HasLeases() bool {
 	return v.data.Leases != nil
 }
@@ -1045,6 +1221,10 @@ func (v *WindowStatus) SetLeases(leases int32) {
 	v.data.Leases = &leases
 }
 
+func (v *WindowStatus) ClearLeases() {
+	v.data.Leases = nil
+}
+
 func (v *WindowStatus) HasUsage() bool {
 	return v.data.Usage != nil
 }
@@ -1060,6 +1240,10 @@ func (v *WindowStatus) SetUsage(usage int64) {
 	v.data.Usage = &usage
 }
 
+func (v *WindowStatus) ClearUsage() {
+	v.data.Usage = nil
+}
+
 func (v *WindowStatus) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -1112,6 +1296,10 @@ func (v *ApplicationStatus) SetName(name string) {
 	v.data.Name = &name
 }
 
+func (v *ApplicationStatus) ClearName() {
+	v.data.Name = nil
+}
+
 func (v *ApplicationStatus) HasPools() bool {
 	return v.data.Pools != nil
 }
@@ -1128,6 +1316,10 @@ func (v *ApplicationStatus) SetPools(pools []*PoolStatus) {
 	v.data.Pools = &x
 }
 
+func (v *ApplicationStatus) ClearPools() {
+	v.data.Pools = nil
+}
+
 func (v *ApplicationStatus) HasLastMinCPU() bool {
 	return v.data.LastMinCPU != nil
 }
@@ -1143,6 +1335,10 @@ func (v *ApplicationStatus) SetLastMinCPU(lastMinCPU float64) {
 	v.data.LastMinCPU = &lastMinCPU
 }
 
+func (v *ApplicationStatus) ClearLastMinCPU() {
+	v.data.LastMinCPU = nil
+}
+
 func (v *ApplicationStatus) HasLastHourCPU() bool {
 	return v.data.LastHourCPU != nil
 }
@@ -1158,6 +1354,10 @@ func (v *ApplicationStatus) SetLastHourCPU(lastHourCPU float64) {
 	v.data.LastHourCPU = &lastHourCPU
 }
 
+func (v *ApplicationStatus) ClearLastHourCPU() {
+	v.data.LastHourCPU = nil
+}
+
 func (v *ApplicationStatus) HasLastDayCPU() bool {
 	return v.data.LastDayCPU != nil
 }
@@ -1173,6 +1373,10 @@ func (v *ApplicationStatus) SetLastDayCPU(lastDayCPU float64) {
 	v.data.LastDayCPU = &lastDayCPU
 }
 
+func (v *ApplicationStatus) ClearLastDayCPU() {
+	v.data.LastDayCPU = nil
+}
+
 func (v *ApplicationStatus) HasCpuOverHour() bool {
 	return v.data.CpuOverHour != nil
 }
@@ -1189,6 +1393,10 @@ func (v *ApplicationStatus) SetCpuOverHour(cpuOverHour []*CpuUsage) {
 	v.data.CpuOverHour = &x
 }
 
+func (v *ApplicationStatus) ClearCpuOverHour() {
+	v.data.CpuOverHour = nil
+}
+
 func (v *ApplicationStatus) HasMemoryOverHour() bool {
 	return v.data.MemoryOverHour != nil
 }
@@ -1205,6 +1413,10 @@ func (v *ApplicationStatus) SetMemoryOverHour(memoryOverHour []*MemoryUsage) {
 	v.data.MemoryOverHour = &x
 }
 
+func (v *ApplicationStatus) ClearMemoryOverHour() {
+	v.data.MemoryOverHour = nil
+}
+
 func (v *ApplicationStatus) HasActiveVersion() bool {
 	return v.data.ActiveVersion != nil
 }
@@ -1220,6 +1432,10 @@ func (v *ApplicationStatus) SetActiveVersion(activeVersion string) {
 	v.data.ActiveVersion = &activeVersion
 }
 
+func (v *ApplicationStatus) ClearActiveVersion() {
+	v.data.ActiveVersion = nil
+}
+
 func (v *ApplicationStatus) HasLastDeploy() bool {
 	return v.data.LastDeploy != nil
 }
@@ -1232,6 +1448,10 @@ func (v *ApplicationStatus) SetLastDeploy(lastDeploy *standard.Timestamp) {
 	v.data.LastDeploy = lastDeploy
 }
 
+func (v *ApplicationStatus) ClearLastDeploy() {
+	v.data.LastDeploy = nil
+}
+
 func (v *ApplicationStatus) HasAddons() bool {
 	return v.data.Addons != nil
 }
@@ -1248,6 +1468,10 @@ func (v *ApplicationStatus) SetAddons(addons []*AddonInstance) {
 	v.data.Addons = &x
 }
 
+func (v *ApplicationStatus) ClearAddons() {
+	v.data.Addons = nil
+}
+
 func (v *ApplicationStatus) HasRequestsPerSecond() bool {
 	return v.data.RequestsPerSecond != nil
 }
@@ -1263,6 +1487,10 @@ func (v *ApplicationStatus) SetRequestsPerSecond(requestsPerSecond float64) {
 	v.data.RequestsPerSecond = &requestsPerSecond
 }
 
+func (v *ApplicationStatus) ClearRequestsPerSecond() {
+	v.data.RequestsPerSecond = nil
+}
+
 func (v *ApplicationStatus) HasRequestStats() bool {
 	return v.data.RequestStats != nil
 }
@@ -1279,6 +1507,10 @@ func (v *ApplicationStatus) SetRequestStats(requestStats []*RequestStat) {
 	v.data.RequestStats = &x
 }
 
+func (v *ApplicationStatus) ClearRequestStats() {
+	v.data.RequestStats = nil
+}
+
 func (v *ApplicationStatus) HasTopPaths() bool {
 	return v.data.TopPaths != nil
 }
@@ -1295,6 +1527,10 @@ func (v *ApplicationStatus) SetTopPaths(topPaths []*PathStat) {
 	v.data.TopPaths = &x
 }
 
+func (v *ApplicationStatus) ClearTopPaths() {
+	v.data.TopPaths = nil
+}
+
 func (v *ApplicationStatus) HasErrorBreakdown() bool {
 	return v.data.ErrorBreakdown != nil
 }
@@ -1311,6 +1547,10 @@ func (v *ApplicationStatus) SetErrorBreakdown(errorBreakdown []*ErrorBreakdown)
 	v.data.ErrorBreakdown = &x
 }
 
+func (v *ApplicationStatus) ClearErrorBreakdown() {
+	v.data.ErrorBreakdown = nil
+}
+
 func (v *ApplicationStatus) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -1350,6 +1590,10 @@ func (v *LogEntry) SetTimestamp(timestamp *standard.Timestamp) {
 	v.data.Timestamp = timestamp
 }
 
+func (v *LogEntry) ClearTimestamp() {
+	v.data.Timestamp = nil
+}
+
 func (v *LogEntry) HasLine() bool {
 	return v.data.Line != nil
 }
@@ -1365,6 +1609,10 @@ func (v *LogEntry) SetLine(line string) {
 	v.data.Line = &line
 }
 
+func (v *LogEntry) ClearLine() {
+	v.data.Line = nil
+}
+
 func (v *LogEntry) HasStream() bool {
 	return v.data.Stream != nil
 }
@@ -1380,6 +1628,10 @@ func (v *LogEntry) SetStream(stream string) {
 	v.data.Stream = &stream
 }
 
+func (v *LogEntry) ClearStream() {
+	v.data.Stream = nil
+}
+
 func (v *LogEntry) HasSource() bool {
 	return v.data.Source != nil
 }
@@ -1395,6 +1647,10 @@ func (v *LogEntry) SetSource(source string) {
 	v.data.Source = &source
 }
 
+func (v *LogEntry) ClearSource() {
+	v.data.Source = nil
+}
+
 func (v *LogEntry) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -1435,6 +1691,10 @@ func (v *LogChunk) SetEntries(entries []*LogEntry) {
 	v.data.Entries = &x
 }
 
+func (v *LogChunk) ClearEntries() {
+	v.data.Entries = nil
+}
+
 func (v *LogChunk) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -1474,6 +1734,10 @@ func (v *UserInfo) SetSubject(subject string) {
 	v.data.Subject = &subject
 }
 
+func (v *UserInfo) ClearSubject() {
+	v.data.Subject = nil
+}
+
 func (v *UserInfo) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -1515,6 +1779,10 @@ func (v *DiskConfig) SetId(id string) {
 	v.data.Id = &id
 }
 
+func (v *DiskConfig) ClearId() {
+	v.data.Id = nil
+}
+
 func (v *DiskConfig) HasName() bool {
 	return v.data.Name != nil
 }
@@ -1530,6 +1798,10 @@ func (v *DiskConfig) SetName(name string) {
 	v.data.Name = &name
 }
 
+func (v *DiskConfig) ClearName() {
+	v.data.Name = nil
+}
+
 func (v *DiskConfig) HasCapacity() bool {
 	return v.data.Capacity != nil
 }
@@ -1545,6 +1817,10 @@ func (v *DiskConfig) SetCapacity(capacity int64) {
 	v.data.Capacity = &capacity
 }
 
+func (v *DiskConfig) ClearCapacity() {
+	v.data.Capacity = nil
+}
+
 func (v *DiskConfig) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -1587,6 +1863,10 @@ func (v *AddonInstance) SetId(id string) {
 	v.data.Id = &id
 }
 
+func (v *AddonInstance) ClearId() {
+	v.data.Id = nil
+}
+
 func (v *AddonInstance) HasName() bool {
 	return v.data.Name != nil
 }
@@ -1602,6 +1882,10 @@ func (v *AddonInstance) SetName(name string) {
 	v.data.Name = &name
 }
 
+func (v *AddonInstance) ClearName() {
+	v.data.Name = nil
+}
+
 func (v *AddonInstance) HasAddon() bool {
 	return v.data.Addon != nil
 }
@@ -1617,6 +1901,10 @@ func (v *AddonInstance) SetAddon(addon string) {
 	v.data.Addon = &addon
 }
 
+func (v *AddonInstance) ClearAddon() {
+	v.data.Addon = nil
+}
+
 func (v *AddonInstance) HasPlan() bool {
 	return v.data.Plan != nil
 }
@@ -1632,6 +1920,10 @@ func (v *AddonInstance) SetPlan(plan string) {
 	v.data.Plan = &plan
 }
 
+func (v *AddonInstance) ClearPlan() {
+	v.data.Plan = nil
+}
+
 func (v *AddonInstance) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -1648,6 +1940,7 @@ func (v *AddonInstance) UnmarshalJSON(data []byte) error {
 	return json.Unmarshal(data, &v.data)
 }
 
+// Server structs for Crud
 type crudNewArgsData struct {
 	Name *string `cbor:"0,keyasint,omitempty" json:"name,omitempty"`
 }
@@ -1697,6 +1990,10 @@ func (v *CrudNewResults) SetId(id string) {
 	v.data.Id = &id
 }
 
+func (v *CrudNewResults) ClearId() {
+	v.data.Id = nil
+}
+
 func (v *CrudNewResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -1771,6 +2068,10 @@ func (v *CrudSetConfigurationResults) SetVersionId(versionId string) {
 	v.data.VersionId = &versionId
 }
 
+func (v *CrudSetConfigurationResults) ClearVersionId() {
+	v.data.VersionId = nil
+}
+
 func (v *CrudSetConfigurationResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -1837,10 +2138,18 @@ func (v *CrudGetConfigurationResults) SetConfiguration(configuration *Configurat
 	v.data.Configuration = configuration
 }
 
+func (v *CrudGetConfigurationResults) ClearConfiguration() {
+	v.data.Configuration = nil
+}
+
 func (v *CrudGetConfigurationResults) SetVersionId(versionId string) {
 	v.data.VersionId = &versionId
 }
 
+func (v *CrudGetConfigurationResults) ClearVersionId() {
+	v.data.VersionId = nil
+}
+
 func (v *CrudGetConfigurationResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -1965,6 +2274,10 @@ func (v *CrudListResults) SetApps(apps []*AppInfo) {
 	v.data.Apps = &x
 }
 
+func (v *CrudListResults) ClearApps() {
+	v.data.Apps = nil
+}
+
 func (v *CrudListResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -2137,6 +2450,10 @@ func (v *CrudSetEnvVarResults) SetVersionId(versionId string) {
 	v.data.VersionId = &versionId
 }
 
+func (v *CrudSetEnvVarResults) ClearVersionId() {
+	v.data.VersionId = nil
+}
+
 func (v *CrudSetEnvVarResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -2227,10 +2544,18 @@ func (v *CrudDeleteEnvVarResults) SetVersionId(versionId string) {
 	v.data.VersionId = &versionId
 }
 
+func (v *CrudDeleteEnvVarResults) ClearVersionId() {
+	v.data.VersionId = nil
+}
+
 func (v *CrudDeleteEnvVarResults) SetDeletedSource(deletedSource string) {
 	v.data.DeletedSource = &deletedSource
 }
 
+func (v *CrudDeleteEnvVarResults) ClearDeletedSource() {
+	v.data.DeletedSource = nil
+}
+
 func (v *CrudDeleteEnvVarResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -2247,6 +2572,65 @@ func (v *CrudDeleteEnvVarResults) UnmarshalJSON(data []byte) error {
 	return json.Unmarshal(data, &v.data)
 }
 
+type crudListStreamArgsData struct {
+	Apps *rpc.Capability `cbor:"0,keyasint,omitempty" json:"apps,omitempty"`
+}
+
+type CrudListStreamArgs struct {
+	call rpc.Call
+	data crudListStreamArgsData
+}
+
+func (v *CrudListStreamArgs) HasApps() bool {
+	return v.data.Apps != nil
+}
+
+func (v *CrudListStreamArgs) Apps() *stream.SendStreamClient[*AppInfo] {
+	if v.data.Apps == nil {
+		return nil
+	}
+	return &stream.SendStreamClient[*AppInfo]{Client: v.call.NewClient(v.data.Apps)}
+}
+
+func (v *CrudListStreamArgs) MarshalCBOR() ([]byte, error) {
+	return cbor.Marshal(v.data)
+}
+
+func (v *CrudListStreamArgs) UnmarshalCBOR(data []byte) error {
+	return cbor.Unmarshal(data, &v.data)
+}
+
+func (v *CrudListStreamArgs) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.data)
+}
+
+func (v *CrudListStreamArgs) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &v.data)
+}
+
+type crudListStreamResultsData struct{}
+
+type CrudListStreamResults struct {
+	call rpc.Call
+	data crudListStreamResultsData
+}
+
+func (v *CrudListStreamResults) MarshalCBOR() ([]byte, error) {
+	return cbor.Marshal(v.data)
+}
+
+func (v *CrudListStreamResults) UnmarshalCBOR(data []byte) error {
+	return cbor.Unmarshal(data, &v.data)
+}
+
+func (v *CrudListStreamResults) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.data)
+}
+
+func (v *CrudListStreamResults) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &v.data)
+}
+
 type CrudNew struct {
 	rpc.Call
 	args    CrudNewArgs
@@ -2455,6 +2839,32 @@ func (t *CrudDeleteEnvVar) Results() *CrudDeleteEnvVarResults {
 	return results
 }
 
+type CrudListStream struct {
+	rpc.Call
+	args    CrudListStreamArgs
+	results CrudListStreamResults
+}
+
+func (t *CrudListStream) Args() *CrudListStreamArgs {
+	args := &t.args
+	if args.call != nil {
+		return args
+	}
+	args.call = t.Call
+	t.Call.Args(args)
+	return args
+}
+
+func (t *CrudListStream) Results() *CrudListStreamResults {
+	results := &t.results
+	if results.call != nil {
+		return results
+	}
+	results.call = t.Call
+	t.Call.Results(results)
+	return results
+}
+
 type Crud interface {
 	New(ctx context.Context, state *CrudNew) error
 	SetConfiguration(ctx context.Context, state *CrudSetConfiguration) error
@@ -2464,6 +2874,7 @@ type Crud interface {
 	Destroy(ctx context.Context, state *CrudDestroy) error
 	SetEnvVar(ctx context.Context, state *CrudSetEnvVar) error
 	DeleteEnvVar(ctx context.Context, state *CrudDeleteEnvVar) error
+	ListStream(ctx context.Context, state *CrudListStream) error
 }
 
 type reexportCrud struct {
@@ -2502,6 +2913,10 @@ func (reexportCrud) DeleteEnvVar(ctx context.Context, state *CrudDeleteEnvVar) e
 	panic("not implemented")
 }
 
+func (reexportCrud) ListStream(ctx context.Context, state *CrudListStream) error {
+	panic("not implemented")
+}
+
 func (t reexportCrud) CapabilityClient() rpc.Client {
 	return t.client
 }
@@ -2572,6 +2987,14 @@ func AdaptCrud(t Crud) *rpc.Interface {
 				return t.DeleteEnvVar(ctx, &CrudDeleteEnvVar{Call: call})
 			},
 		},
+		{
+			Name:          "listStream",
+			InterfaceName: "Crud",
+			Index:         0,
+			Handler: func(ctx context.Context, call rpc.Call) error {
+				return t.ListStream(ctx, &CrudListStream{Call: call})
+			},
+		},
 	}
 
 	return rpc.NewInterface(methods, t)
@@ -2833,6 +3256,31 @@ func (v CrudClient) DeleteEnvVar(ctx context.Context, app string, key string, se
 	return &CrudClientDeleteEnvVarResults{client: v.Client, data: ret}, nil
 }
 
+type CrudClientListStreamResults struct {
+	client rpc.Client
+	data   crudListStreamResultsData
+}
+
+func (v CrudClient) ListStream(ctx context.Context, apps stream.SendStream[*AppInfo]) (*CrudClientListStreamResults, error) {
+	args := CrudListStreamArgs{}
+	caps := map[rpc.OID]*rpc.InlineCapability{}
+	{
+		ic, oid, c := v.NewInlineCapability(stream.AdaptSendStream[*AppInfo](apps), apps)
+		args.data.Apps = c
+		caps[oid] = ic
+	}
+
+	var ret crudListStreamResultsData
+
+	err := v.CallWithCaps(ctx, "listStream", &args, &ret, caps)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CrudClientListStreamResults{client: v.Client, data: ret}, nil
+}
+
+// Server structs for UserQuery
 type userQueryWhoAmIArgsData struct{}
 
 type UserQueryWhoAmIArgs struct {
@@ -2869,6 +3317,10 @@ func (v *UserQueryWhoAmIResults) SetInfo(info *UserInfo) {
 	v.data.Info = info
 }
 
+func (v *UserQueryWhoAmIResults) ClearInfo() {
+	v.data.Info = nil
+}
+
 func (v *UserQueryWhoAmIResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -2980,6 +3432,7 @@ func (v UserQueryClient) WhoAmI(ctx context.Context) (*UserQueryClientWhoAmIResu
 	return &UserQueryClientWhoAmIResults{client: v.Client, data: ret}, nil
 }
 
+// Server structs for AppStatus
 type appStatusAppInfoArgsData struct {
 	Application *string `cbor:"0,keyasint,omitempty" json:"application,omitempty"`
 }
@@ -3029,6 +3482,10 @@ func (v *AppStatusAppInfoResults) SetStatus(status *ApplicationStatus) {
 	v.data.Status = status
 }
 
+func (v *AppStatusAppInfoResults) ClearStatus() {
+	v.data.Status = nil
+}
+
 func (v *AppStatusAppInfoResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -3141,6 +3598,7 @@ func (v AppStatusClient) AppInfo(ctx context.Context, application string) (*AppS
 	return &AppStatusClientAppInfoResults{client: v.Client, data: ret}, nil
 }
 
+// Server structs for Logs
 type logsAppLogsArgsData struct {
 	Application *string             `cbor:"0,keyasint,omitempty" json:"application,omitempty"`
 	From        *standard.Timestamp `cbor:"1,keyasint,omitempty" json:"from,omitempty"`
@@ -3212,6 +3670,10 @@ func (v *LogsAppLogsResults) SetLogs(logs []*LogEntry) {
 	v.data.Logs = &x
 }
 
+func (v *LogsAppLogsResults) ClearLogs() {
+	v.data.Logs = nil
+}
+
 func (v *LogsAppLogsResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -3299,6 +3761,10 @@ func (v *LogsSandboxLogsResults) SetLogs(logs []*LogEntry) {
 	v.data.Logs = &x
 }
 
+func (v *LogsSandboxLogsResults) ClearLogs() {
+	v.data.Logs = nil
+}
+
 func (v *LogsSandboxLogsResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -3810,6 +4276,7 @@ func (v LogsClient) StreamLogChunks(ctx context.Context, target *LogTarget, from
 	return &LogsClientStreamLogChunksResults{client: v.Client, data: ret}, nil
 }
 
+// Server structs for Disks
 type disksNewArgsData struct {
 	Name     *string `cbor:"0,keyasint,omitempty" json:"name,omitempty"`
 	Capacity *int64  `cbor:"1,keyasint,omitempty" json:"capacity,omitempty"`
@@ -3871,6 +4338,10 @@ func (v *DisksNewResults) SetId(id string) {
 	v.data.Id = &id
 }
 
+func (v *DisksNewResults) ClearId() {
+	v.data.Id = nil
+}
+
 func (v *DisksNewResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -3936,6 +4407,10 @@ func (v *DisksGetByIdResults) SetConfig(config *DiskConfig) {
 	v.data.Config = config
 }
 
+func (v *DisksGetByIdResults) ClearConfig() {
+	v.data.Config = nil
+}
+
 func (v *DisksGetByIdResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -4001,6 +4476,10 @@ func (v *DisksGetByNameResults) SetConfig(config *DiskConfig) {
 	v.data.Config = config
 }
 
+func (v *DisksGetByNameResults) ClearConfig() {
+	v.data.Config = nil
+}
+
 func (v *DisksGetByNameResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -4054,6 +4533,10 @@ func (v *DisksListResults) SetDisks(disks []*DiskConfig) {
 	v.data.Disks = &x
 }
 
+func (v *DisksListResults) ClearDisks() {
+	v.data.Disks = nil
+}
+
 func (v *DisksListResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -4129,6 +4612,65 @@ func (v *DisksDeleteResults) UnmarshalJSON(data []byte) error {
 	return json.Unmarshal(data, &v.data)
 }
 
+type disksListStreamArgsData struct {
+	Disks *rpc.Capability `cbor:"0,keyasint,omitempty" json:"disks,omitempty"`
+}
+
+type DisksListStreamArgs struct {
+	call rpc.Call
+	data disksListStreamArgsData
+}
+
+func (v *DisksListStreamArgs) HasDisks() bool {
+	return v.data.Disks != nil
+}
+
+func (v *DisksListStreamArgs) Disks() *stream.SendStreamClient[*DiskConfig] {
+	if v.data.Disks == nil {
+		return nil
+	}
+	return &stream.SendStreamClient[*DiskConfig]{Client: v.call.NewClient(v.data.Disks)}
+}
+
+func (v *DisksListStreamArgs) MarshalCBOR() ([]byte, error) {
+	return cbor.Marshal(v.data)
+}
+
+func (v *DisksListStreamArgs) UnmarshalCBOR(data []byte) error {
+	return cbor.Unmarshal(data, &v.data)
+}
+
+func (v *DisksListStreamArgs) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.data)
+}
+
+func (v *DisksListStreamArgs) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &v.data)
+}
+
+type disksListStreamResultsData struct{}
+
+type DisksListStreamResults struct {
+	call rpc.Call
+	data disksListStreamResultsData
+}
+
+func (v *DisksListStreamResults) MarshalCBOR() ([]byte, error) {
+	return cbor.Marshal(v.data)
+}
+
+func (v *DisksListStreamResults) UnmarshalCBOR(data []byte) error {
+	return cbor.Unmarshal(data, &v.data)
+}
+
+func (v *DisksListStreamResults) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.data)
+}
+
+func (v *DisksListStreamResults) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &v.data)
+}
+
 type DisksNew struct {
 	rpc.Call
 	args    DisksNewArgs
@@ -4259,12 +4801,39 @@ func (t *DisksDelete) Results() *DisksDeleteResults {
 	return results
 }
 
+type DisksListStream struct {
+	rpc.Call
+	args    DisksListStreamArgs
+	results DisksListStreamResults
+}
+
+func (t *DisksListStream) Args() *DisksListStreamArgs {
+	args := &t.args
+	if args.call != nil {
+		return args
+	}
+	args.call = t.Call
+	t.Call.Args(args)
+	return args
+}
+
+func (t *DisksListStream) Results() *DisksListStreamResults {
+	results := &t.results
+	if results.call != nil {
+		return results
+	}
+	results.call = t.Call
+	t.Call.Results(results)
+	return results
+}
+
 type Disks interface {
 	New(ctx context.Context, state *DisksNew) error
 	GetById(ctx context.Context, state *DisksGetById) error
 	GetByName(ctx context.Context, state *DisksGetByName) error
 	List(ctx context.Context, state *DisksList) error
 	Delete(ctx context.Context, state *DisksDelete) error
+	ListStream(ctx context.Context, state *DisksListStream) error
 }
 
 type reexportDisks struct {
@@ -4291,6 +4860,10 @@ func (reexportDisks) Delete(ctx context.Context, state *DisksDelete) error {
 	panic("not implemented")
 }
 
+func (reexportDisks) ListStream(ctx context.Context, state *DisksListStream) error {
+	panic("not implemented")
+}
+
 func (t reexportDisks) CapabilityClient() rpc.Client {
 	return t.client
 }
@@ -4337,6 +4910,14 @@ func AdaptDisks(t Disks) *rpc.Interface {
 				return t.Delete(ctx, &DisksDelete{Call: call})
 			},
 		},
+		{
+			Name:          "listStream",
+			InterfaceName: "Disks",
+			Index:         0,
+			Handler: func(ctx context.Context, call rpc.Call) error {
+				return t.ListStream(ctx, &DisksListStream{Call: call})
+			},
+		},
 	}
 
 	return rpc.NewInterface(methods, t)
@@ -4487,6 +5068,31 @@ func (v DisksClient) Delete(ctx context.Context, id string) (*DisksClientDeleteR
 	return &DisksClientDeleteResults{client: v.Client, data: ret}, nil
 }
 
+type DisksClientListStreamResults struct {
+	client rpc.Client
+	data   disksListStreamResultsData
+}
+
+func (v DisksClient) ListStream(ctx context.Context, disks stream.SendStream[*DiskConfig]) (*DisksClientListStreamResults, error) {
+	args := DisksListStreamArgs{}
+	caps := map[rpc.OID]*rpc.InlineCapability{}
+	{
+		ic, oid, c := v.NewInlineCapability(stream.AdaptSendStream[*DiskConfig](disks), disks)
+		args.data.Disks = c
+		caps[oid] = ic
+	}
+
+	var ret disksListStreamResultsData
+
+	err := v.CallWithCaps(ctx, "listStream", &args, &ret, caps)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DisksClientListStreamResults{client: v.Client, data: ret}, nil
+}
+
+// Server structs for Addons
 type addonsCreateInstanceArgsData struct {
 	Name  *string `cbor:"0,keyasint,omitempty" json:"name,omitempty"`
 	Addon *string `cbor:"1,keyasint,omitempty" json:"addon,omitempty"`
@@ -4572,6 +5178,10 @@ func (v *AddonsCreateInstanceResults) SetId(id string) {
 	v.data.Id = &id
 }
 
+func (v *AddonsCreateInstanceResults) ClearId() {
+	v.data.Id = nil
+}
+
 func (v *AddonsCreateInstanceResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -4638,6 +5248,10 @@ func (v *AddonsListInstancesResults) SetAddons(addons []*AddonInstance) {
 	v.data.Addons = &x
 }
 
+func (v *AddonsListInstancesResults) ClearAddons() {
+	v.data.Addons = nil
+}
+
 func (v *AddonsListInstancesResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
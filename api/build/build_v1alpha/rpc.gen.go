@@ -102,6 +102,10 @@ func (v *Status) SetKind(kind string) {
 	v.data.Kind = &kind
 }
 
+func (v *Status) ClearKind() {
+	v.data.Kind = nil
+}
+
 func (v *Status) Update() StatusUpdate {
 	return &v.data.statusUpdate
 }
@@ -144,6 +148,10 @@ func (v *AccessInfo) SetHostnames(hostnames *[]string) {
 	v.data.Hostnames = hostnames
 }
 
+func (v *AccessInfo) ClearHostnames() {
+	v.data.Hostnames = nil
+}
+
 func (v *AccessInfo) HasDefaultRoute() bool {
 	return v.data.DefaultRoute != nil
 }
@@ -159,6 +167,10 @@ func (v *AccessInfo) SetDefaultRoute(default_route bool) {
 	v.data.DefaultRoute = &default_route
 }
 
+func (v *AccessInfo) ClearDefaultRoute() {
+	v.data.DefaultRoute = nil
+}
+
 func (v *AccessInfo) HasClusterHostname() bool {
 	return v.data.ClusterHostname != nil
 }
@@ -174,6 +186,10 @@ func (v *AccessInfo) SetClusterHostname(cluster_hostname string) {
 	v.data.ClusterHostname = &cluster_hostname
 }
 
+func (v *AccessInfo) ClearClusterHostname() {
+	v.data.ClusterHostname = nil
+}
+
 func (v *AccessInfo) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -215,6 +231,10 @@ func (v *ServiceInfo) SetName(name string) {
 	v.data.Name = &name
 }
 
+func (v *ServiceInfo) ClearName() {
+	v.data.Name = nil
+}
+
 func (v *ServiceInfo) HasCommand() bool {
 	return v.data.Command != nil
 }
@@ -230,6 +250,10 @@ func (v *ServiceInfo) SetCommand(command string) {
 	v.data.Command = &command
 }
 
+func (v *ServiceInfo) ClearCommand() {
+	v.data.Command = nil
+}
+
 func (v *ServiceInfo) HasSource() bool {
 	return v.data.Source != nil
 }
@@ -245,6 +269,10 @@ func (v *ServiceInfo) SetSource(source string) {
 	v.data.Source = &source
 }
 
+func (v *ServiceInfo) ClearSource() {
+	v.data.Source = nil
+}
+
 func (v *ServiceInfo) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -286,6 +314,10 @@ func (v *DetectionEvent) SetKind(kind string) {
 	v.data.Kind = &kind
 }
 
+func (v *DetectionEvent) ClearKind() {
+	v.data.Kind = nil
+}
+
 func (v *DetectionEvent) HasName() bool {
 	return v.data.Name != nil
 }
@@ -301,6 +333,10 @@ func (v *DetectionEvent) SetName(name string) {
 	v.data.Name = &name
 }
 
+func (v *DetectionEvent) ClearName() {
+	v.data.Name = nil
+}
+
 func (v *DetectionEvent) HasMessage() bool {
 	return v.data.Message != nil
 }
@@ -316,6 +352,10 @@ func (v *DetectionEvent) SetMessage(message string) {
 	v.data.Message = &message
 }
 
+func (v *DetectionEvent) ClearMessage() {
+	v.data.Message = nil
+}
+
 func (v *DetectionEvent) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -362,6 +402,10 @@ func (v *AnalysisResult) SetStack(stack string) {
 	v.data.Stack = &stack
 }
 
+func (v *AnalysisResult) ClearStack() {
+	v.data.Stack = nil
+}
+
 func (v *AnalysisResult) HasServices() bool {
 	return v.data.Services != nil
 }
@@ -374,6 +418,10 @@ func (v *AnalysisResult) SetServices(services *[]ServiceInfo) {
 	v.data.Services = services
 }
 
+func (v *AnalysisResult) ClearServices() {
+	v.data.Services = nil
+}
+
 func (v *AnalysisResult) HasWorkingDir() bool {
 	return v.data.WorkingDir != nil
 }
@@ -389,6 +437,10 @@ func (v *AnalysisResult) SetWorkingDir(working_dir string) {
 	v.data.WorkingDir = &working_dir
 }
 
+func (v *AnalysisResult) ClearWorkingDir() {
+	v.data.WorkingDir = nil
+}
+
 func (v *AnalysisResult) HasEntrypoint() bool {
 	return v.data.Entrypoint != nil
 }
@@ -404,6 +456,10 @@ func (v *AnalysisResult) SetEntrypoint(entrypoint string) {
 	v.data.Entrypoint = &entrypoint
 }
 
+func (v *AnalysisResult) ClearEntrypoint() {
+	v.data.Entrypoint = nil
+}
+
 func (v *AnalysisResult) HasAppName() bool {
 	return v.data.AppName != nil
 }
@@ -419,6 +475,10 @@ func (v *AnalysisResult) SetAppName(app_name string) {
 	v.data.AppName = &app_name
 }
 
+func (v *AnalysisResult) ClearAppName() {
+	v.data.AppName = nil
+}
+
 func (v *AnalysisResult) HasBuildDockerfile() bool {
 	return v.data.BuildDockerfile != nil
 }
@@ -434,6 +494,10 @@ func (v *AnalysisResult) SetBuildDockerfile(build_dockerfile string) {
 	v.data.BuildDockerfile = &build_dockerfile
 }
 
+func (v *AnalysisResult) ClearBuildDockerfile() {
+	v.data.BuildDockerfile = nil
+}
+
 func (v *AnalysisResult) HasEnvVars() bool {
 	return v.data.EnvVars != nil
 }
@@ -446,6 +510,10 @@ func (v *AnalysisResult) SetEnvVars(env_vars *[]string) {
 	v.data.EnvVars = env_vars
 }
 
+func (v *AnalysisResult) ClearEnvVars() {
+	v.data.EnvVars = nil
+}
+
 func (v *AnalysisResult) HasEvents() bool {
 	return v.data.Events != nil
 }
@@ -458,6 +526,10 @@ func (v *AnalysisResult) SetEvents(events *[]DetectionEvent) {
 	v.data.Events = events
 }
 
+func (v *AnalysisResult) ClearEvents() {
+	v.data.Events = nil
+}
+
 func (v *AnalysisResult) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -474,6 +546,7 @@ func (v *AnalysisResult) UnmarshalJSON(data []byte) error {
 	return json.Unmarshal(data, &v.data)
 }
 
+// Server structs for Stream
 type streamRecvArgsData struct {
 	Count *int32 `cbor:"0,keyasint,omitempty" json:"count,omitempty"`
 }
@@ -524,6 +597,10 @@ func (v *StreamRecvResults) SetData(data []byte) {
 	v.data.Data = &x
 }
 
+func (v *StreamRecvResults) ClearData() {
+	v.data.Data = nil
+}
+
 func (v *StreamRecvResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -639,6 +716,7 @@ func (v StreamClient) Recv(ctx context.Context, count int32) (*StreamClientRecvR
 	return &StreamClientRecvResults{client: v.Client, data: ret}, nil
 }
 
+// Server structs for Builder
 type builderBuildFromTarArgsData struct {
 	Application *string         `cbor:"0,keyasint,omitempty" json:"application,omitempty"`
 	Tardata     *rpc.Capability `cbor:"1,keyasint,omitempty" json:"tardata,omitempty"`
@@ -713,10 +791,18 @@ func (v *BuilderBuildFromTarResults) SetVersion(version string) {
 	v.data.Version = &version
 }
 
+func (v *BuilderBuildFromTarResults) ClearVersion() {
+	v.data.Version = nil
+}
+
 func (v *BuilderBuildFromTarResults) SetAccessInfo(access_info **AccessInfo) {
 	v.data.AccessInfo = access_info
 }
 
+func (v *BuilderBuildFromTarResults) ClearAccessInfo() {
+	v.data.AccessInfo = nil
+}
+
 func (v *BuilderBuildFromTarResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -782,6 +868,10 @@ func (v *BuilderAnalyzeAppResults) SetResult(result **AnalysisResult) {
 	v.data.Result = result
 }
 
+func (v *BuilderAnalyzeAppResults) ClearResult() {
+	v.data.Result = nil
+}
+
 func (v *BuilderAnalyzeAppResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
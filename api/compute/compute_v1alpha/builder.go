@@ -0,0 +1,84 @@
+package compute_v1alpha
+
+import (
+	"fmt"
+
+	"miren.dev/runtime/pkg/entity"
+	"miren.dev/runtime/pkg/entity/types"
+)
+
+// SandboxSpecBuilder assembles a SandboxSpec, checking it's one the
+// scheduler can place before it's handed over to be encoded.
+type SandboxSpecBuilder struct {
+	spec SandboxSpec
+}
+
+// NewSandboxSpecBuilder starts an empty sandbox spec.
+func NewSandboxSpecBuilder() *SandboxSpecBuilder {
+	return &SandboxSpecBuilder{}
+}
+
+// Container adds a container to the sandbox.
+func (b *SandboxSpecBuilder) Container(co SandboxSpecContainer) *SandboxSpecBuilder {
+	b.spec.Container = append(b.spec.Container, co)
+	return b
+}
+
+// Version sets the app version the sandbox runs.
+func (b *SandboxSpecBuilder) Version(id entity.Id) *SandboxSpecBuilder {
+	b.spec.Version = id
+	return b
+}
+
+// LogEntity sets the entity the sandbox's logs are associated with.
+func (b *SandboxSpecBuilder) LogEntity(id string) *SandboxSpecBuilder {
+	b.spec.LogEntity = id
+	return b
+}
+
+// LogAttribute adds a label to the sandbox's log entries.
+func (b *SandboxSpecBuilder) LogAttribute(key, value string) *SandboxSpecBuilder {
+	b.spec.LogAttribute = append(b.spec.LogAttribute, types.Label{Key: key, Value: value})
+	return b
+}
+
+// HostNetwork sets whether the sandbox uses the host's network.
+func (b *SandboxSpecBuilder) HostNetwork(on bool) *SandboxSpecBuilder {
+	b.spec.HostNetwork = on
+	return b
+}
+
+// RuntimeClass sets the container runtime the sandbox runs with.
+func (b *SandboxSpecBuilder) RuntimeClass(class string) *SandboxSpecBuilder {
+	b.spec.RuntimeClass = class
+	return b
+}
+
+// Volume adds a volume to the sandbox.
+func (b *SandboxSpecBuilder) Volume(v SandboxSpecVolume) *SandboxSpecBuilder {
+	b.spec.Volume = append(b.spec.Volume, v)
+	return b
+}
+
+// Build returns the spec, or an error if it's invalid: it has no containers,
+// a container has no image, or two ports share a name. Port names have to be
+// unique across the sandbox, as its containers share a network.
+func (b *SandboxSpecBuilder) Build() (SandboxSpec, error) {
+	if err := b.spec.Validate(); err != nil {
+		return SandboxSpec{}, err
+	}
+
+	ports := map[string]string{}
+
+	for _, co := range b.spec.Container {
+		for _, p := range co.Port {
+			if other, ok := ports[p.Name]; ok {
+				return SandboxSpec{}, fmt.Errorf("port %s of container %s is also declared by container %s", p.Name, co.Name, other)
+			}
+
+			ports[p.Name] = co.Name
+		}
+	}
+
+	return *b.spec.DeepCopy(), nil
+}
@@ -0,0 +1,90 @@
+package compute_v1alpha
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"miren.dev/runtime/pkg/entity"
+)
+
+func TestSandboxSpecBuilder(t *testing.T) {
+	t.Run("builds a valid spec", func(t *testing.T) {
+		r := require.New(t)
+
+		b := NewSandboxSpecBuilder().
+			Version("app_version/v1").
+			LogEntity("app/web").
+			LogAttribute("version", "v1").
+			Container(SandboxSpecContainer{
+				Name:  "app",
+				Image: "app:v1",
+				Port:  []SandboxSpecContainerPort{{Name: "http", Port: 3000}},
+			}).
+			Container(SandboxSpecContainer{
+				Name:  "metrics",
+				Image: "exporter:v2",
+				Port:  []SandboxSpecContainerPort{{Name: "metrics", Port: 9100}},
+			})
+
+		spec, err := b.Build()
+		r.NoError(err)
+
+		r.Len(spec.Container, 2)
+		r.Equal(entity.Id("app_version/v1"), spec.Version)
+		r.Equal("app/web", spec.LogEntity)
+
+		v, ok := spec.LogAttribute.Get("version")
+		r.True(ok)
+		r.Equal("v1", v)
+
+		// The built spec doesn't change as the builder is reused
+		b.Container(SandboxSpecContainer{Name: "extra", Image: "extra:v1"})
+		r.Len(spec.Container, 2)
+	})
+
+	t.Run("needs a container", func(t *testing.T) {
+		_, err := NewSandboxSpecBuilder().Build()
+		require.ErrorIs(t, err, entity.ErrRequiredAttr)
+	})
+
+	t.Run("rejects a container without an image", func(t *testing.T) {
+		r := require.New(t)
+
+		_, err := NewSandboxSpecBuilder().
+			Container(SandboxSpecContainer{Name: "app", Image: "app:v1"}).
+			Container(SandboxSpecContainer{Name: "sidecar"}).
+			Build()
+		r.ErrorIs(err, entity.ErrRequiredAttr)
+		r.EqualError(err, "container[1]: required attribute missing: image")
+	})
+
+	t.Run("rejects duplicate port names", func(t *testing.T) {
+		r := require.New(t)
+
+		_, err := NewSandboxSpecBuilder().
+			Container(SandboxSpecContainer{
+				Name:  "app",
+				Image: "app:v1",
+				Port: []SandboxSpecContainerPort{
+					{Name: "http", Port: 3000},
+					{Name: "http", Port: 3001},
+				},
+			}).
+			Build()
+		r.EqualError(err, "port http of container app is also declared by container app")
+
+		_, err = NewSandboxSpecBuilder().
+			Container(SandboxSpecContainer{
+				Name:  "app",
+				Image: "app:v1",
+				Port:  []SandboxSpecContainerPort{{Name: "http", Port: 3000}},
+			}).
+			Container(SandboxSpecContainer{
+				Name:  "proxy",
+				Image: "proxy:v1",
+				Port:  []SandboxSpecContainerPort{{Name: "http", Port: 8080}},
+			}).
+			Build()
+		r.ErrorContains(err, "also declared by container app")
+	})
+}
@@ -0,0 +1,78 @@
+package compute_v1alpha
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"miren.dev/runtime/pkg/entity/types"
+)
+
+func TestDeepCopy(t *testing.T) {
+	newSandbox := func() *Sandbox {
+		return &Sandbox{
+			ID:     "sandbox/web-1",
+			Status: RUNNING,
+			Labels: []string{"app=web"},
+			LogAttribute: types.Labels{
+				{Key: "app", Value: "web"},
+			},
+			Container: []Container{
+				{
+					Name:  "app",
+					Image: "web:v1",
+					Env:   []string{"PORT=3000"},
+					Port:  []Port{{Name: "http", Port: 3000}},
+				},
+			},
+			Spec: SandboxSpec{
+				Container: []SandboxSpecContainer{
+					{Image: "web:v1", Env: []string{"PORT=3000"}},
+				},
+			},
+		}
+	}
+
+	t.Run("mutating the copy leaves the original unchanged", func(t *testing.T) {
+		r := require.New(t)
+
+		orig := newSandbox()
+		cp := orig.DeepCopy()
+		r.Equal(orig, cp)
+
+		cp.Status = DEAD
+		cp.Labels[0] = "app=api"
+		cp.LogAttribute[0].Value = "api"
+		cp.LogAttribute = append(cp.LogAttribute, types.Label{Key: "tier", Value: "backend"})
+		cp.Container[0].Image = "web:v2"
+		cp.Container[0].Env[0] = "PORT=4000"
+		cp.Container[0].Port[0].Port = 4000
+		cp.Container = append(cp.Container, Container{Name: "sidecar", Image: "proxy:v1"})
+		cp.Spec.Container[0].Env[0] = "PORT=4000"
+
+		r.Equal(newSandbox(), orig)
+	})
+
+	t.Run("mutating the original leaves the copy unchanged", func(t *testing.T) {
+		r := require.New(t)
+
+		orig := newSandbox()
+		cp := orig.DeepCopy()
+
+		orig.Container[0].Env[0] = "PORT=4000"
+		orig.Spec.Container[0].Image = "web:v2"
+
+		r.Equal(newSandbox(), cp)
+	})
+
+	t.Run("preserves nil and empty slices", func(t *testing.T) {
+		r := require.New(t)
+
+		cp := (&Sandbox{Container: []Container{}}).DeepCopy()
+		r.NotNil(cp.Container)
+		r.Empty(cp.Container)
+		r.Nil(cp.Network)
+
+		var nilSandbox *Sandbox
+		r.Nil(nilSandbox.DeepCopy())
+	})
+}
@@ -0,0 +1,236 @@
+package compute_v1alpha
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// FieldChange is one difference between two sandboxes. Path names the field
+// the way it's encoded, with elements of a list picked out by name where
+// they have one, such as container[web].env[PORT]. Old is empty for something
+// added and New for something removed.
+type FieldChange struct {
+	Path string
+	Old  string
+	New  string
+}
+
+func (c FieldChange) String() string {
+	switch {
+	case c.Old == "":
+		return fmt.Sprintf("%s: added %s", c.Path, c.New)
+	case c.New == "":
+		return fmt.Sprintf("%s: removed %s", c.Path, c.Old)
+	default:
+		return fmt.Sprintf("%s: %s -> %s", c.Path, c.Old, c.New)
+	}
+}
+
+// DiffSandbox returns what differs between old and new, for explaining why a
+// sandbox is being updated. A nil sandbox is the same as an empty one.
+func DiffSandbox(old, new *Sandbox) []FieldChange {
+	if old == nil {
+		old = &Sandbox{}
+	}
+
+	if new == nil {
+		new = &Sandbox{}
+	}
+
+	var d sandboxDiff
+	d.value("", reflect.ValueOf(old).Elem(), reflect.ValueOf(new).Elem())
+	return d.changes
+}
+
+type sandboxDiff struct {
+	changes []FieldChange
+}
+
+func (d *sandboxDiff) add(path, old, new string) {
+	d.changes = append(d.changes, FieldChange{Path: path, Old: old, New: new})
+}
+
+var timeType = reflect.TypeFor[time.Time]()
+
+func (d *sandboxDiff) value(path string, a, b reflect.Value) {
+	switch {
+	case a.Type() == timeType:
+		if !a.Interface().(time.Time).Equal(b.Interface().(time.Time)) {
+			d.add(path, formatDiffValue(a), formatDiffValue(b))
+		}
+	case a.Kind() == reflect.Struct:
+		t := a.Type()
+
+		for i := range t.NumField() {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+
+			if name == "" {
+				name = f.Name
+			}
+
+			d.value(joinDiffPath(path, name), a.Field(i), b.Field(i))
+		}
+	case a.Kind() == reflect.Slice:
+		d.list(path, a, b)
+	default:
+		if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			d.add(path, formatDiffValue(a), formatDiffValue(b))
+		}
+	}
+}
+
+// list compares the elements of two lists, matched up by key when they all
+// have a distinct one, otherwise by position.
+func (d *sandboxDiff) list(path string, a, b reflect.Value) {
+	keyOf := diffKey(path)
+
+	ak, aok := diffKeys(a, keyOf)
+	bk, bok := diffKeys(b, keyOf)
+
+	if !aok || !bok {
+		for i := range max(a.Len(), b.Len()) {
+			elem := fmt.Sprintf("%s[%d]", path, i)
+
+			switch {
+			case i >= b.Len():
+				d.add(elem, formatDiffValue(a.Index(i)), "")
+			case i >= a.Len():
+				d.add(elem, "", formatDiffValue(b.Index(i)))
+			default:
+				d.value(elem, a.Index(i), b.Index(i))
+			}
+		}
+
+		return
+	}
+
+	inB := make(map[string]int, len(bk))
+	for i, k := range bk {
+		inB[k] = i
+	}
+
+	inA := make(map[string]bool, len(ak))
+
+	for i, k := range ak {
+		inA[k] = true
+		elem := fmt.Sprintf("%s[%s]", path, k)
+
+		j, ok := inB[k]
+		if !ok {
+			d.add(elem, formatDiffValue(a.Index(i)), "")
+			continue
+		}
+
+		// An environment variable is compared by its value alone
+		if isEnvPath(path) {
+			_, av, _ := strings.Cut(a.Index(i).String(), "=")
+			_, bv, _ := strings.Cut(b.Index(j).String(), "=")
+			if av != bv {
+				d.add(elem, av, bv)
+			}
+			continue
+		}
+
+		d.value(elem, a.Index(i), b.Index(j))
+	}
+
+	for j, k := range bk {
+		if !inA[k] {
+			d.add(fmt.Sprintf("%s[%s]", path, k), "", formatDiffValue(b.Index(j)))
+		}
+	}
+}
+
+func isEnvPath(path string) bool {
+	return path == "env" || strings.HasSuffix(path, ".env")
+}
+
+// diffKey returns how to key the elements of the list at path: environment
+// variables by name, and components by their name or key field.
+func diffKey(path string) func(v reflect.Value) string {
+	if isEnvPath(path) {
+		return func(v reflect.Value) string {
+			name, _, _ := strings.Cut(v.String(), "=")
+			return name
+		}
+	}
+
+	return func(v reflect.Value) string {
+		if v.Kind() != reflect.Struct {
+			return ""
+		}
+
+		for _, field := range []string{"Name", "Key"} {
+			if f := v.FieldByName(field); f.IsValid() && f.Kind() == reflect.String {
+				return f.String()
+			}
+		}
+
+		return ""
+	}
+}
+
+// diffKeys returns the key of each element of v, and whether every one has
+// a distinct key.
+func diffKeys(v reflect.Value, keyOf func(reflect.Value) string) ([]string, bool) {
+	keys := make([]string, v.Len())
+	seen := make(map[string]bool, v.Len())
+
+	for i := range v.Len() {
+		k := keyOf(v.Index(i))
+		if k == "" || seen[k] {
+			return nil, false
+		}
+
+		seen[k] = true
+		keys[i] = k
+	}
+
+	return keys, true
+}
+
+func joinDiffPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
+
+func formatDiffValue(v reflect.Value) string {
+	if v.Type() == timeType {
+		t := v.Interface().(time.Time)
+		if t.IsZero() {
+			return ""
+		}
+
+		return t.Format(time.RFC3339Nano)
+	}
+
+	switch v.Kind() {
+	case reflect.Struct, reflect.Slice, reflect.Map:
+		if v.IsZero() {
+			return ""
+		}
+
+		data, err := json.Marshal(v.Interface())
+		if err != nil {
+			return fmt.Sprint(v.Interface())
+		}
+
+		return string(data)
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
@@ -0,0 +1,112 @@
+package compute_v1alpha
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffSandbox(t *testing.T) {
+	newSandbox := func() *Sandbox {
+		return &Sandbox{
+			ID:     "sandbox/web-1",
+			Status: PENDING,
+			Container: []Container{
+				{
+					Name:  "app",
+					Image: "web:v1",
+					Env:   []string{"PORT=3000", "MODE=production"},
+					Port:  []Port{{Name: "http", Port: 3000}},
+				},
+			},
+			Volume: []Volume{
+				{Name: "data", Provider: "disk"},
+			},
+		}
+	}
+
+	t.Run("finds nothing between equal sandboxes", func(t *testing.T) {
+		require.Empty(t, DiffSandbox(newSandbox(), newSandbox()))
+	})
+
+	t.Run("reports added and removed containers", func(t *testing.T) {
+		r := require.New(t)
+
+		old := newSandbox()
+		old.Container = append(old.Container, Container{Name: "logger", Image: "fluent:v1"})
+
+		new := newSandbox()
+		new.Container = append(new.Container, Container{Name: "metrics", Image: "exporter:v1"})
+
+		changes := DiffSandbox(old, new)
+		r.Len(changes, 2)
+
+		r.Equal("container[logger]", changes[0].Path)
+		r.Contains(changes[0].Old, `"image":"fluent:v1"`)
+		r.Empty(changes[0].New)
+
+		r.Equal("container[metrics]", changes[1].Path)
+		r.Empty(changes[1].Old)
+		r.Contains(changes[1].New, `"image":"exporter:v1"`)
+		r.Contains(changes[1].String(), "container[metrics]: added ")
+	})
+
+	t.Run("reports changed env vars by name", func(t *testing.T) {
+		r := require.New(t)
+
+		new := newSandbox()
+		new.Container[0].Env = []string{"MODE=staging", "DEBUG=1"}
+
+		r.Equal([]FieldChange{
+			{Path: "container[app].env[PORT]", Old: "PORT=3000"},
+			{Path: "container[app].env[MODE]", Old: "production", New: "staging"},
+			{Path: "container[app].env[DEBUG]", New: "DEBUG=1"},
+		}, DiffSandbox(newSandbox(), new))
+	})
+
+	t.Run("reports fields of matching elements, status and volumes", func(t *testing.T) {
+		r := require.New(t)
+
+		new := newSandbox()
+		new.Status = RUNNING
+		new.Container[0].Image = "web:v2"
+		new.Container[0].Port[0].Port = 8080
+		new.Volume[0].Provider = "miren"
+
+		changes := DiffSandbox(newSandbox(), new)
+
+		r.Equal([]FieldChange{
+			{Path: "container[app].image", Old: "web:v1", New: "web:v2"},
+			{Path: "container[app].port[http].port", Old: "3000", New: "8080"},
+			{Path: "status", Old: string(PENDING), New: string(RUNNING)},
+			{Path: "volume[data].provider", Old: "disk", New: "miren"},
+		}, changes)
+
+		r.Equal("container[app].image: web:v1 -> web:v2", changes[0].String())
+	})
+
+	t.Run("matches by position when elements aren't named", func(t *testing.T) {
+		r := require.New(t)
+
+		old := newSandbox()
+		old.Route = []Route{{Destination: "10.0.0.0/8", Gateway: "10.0.0.1"}}
+
+		new := newSandbox()
+		new.Route = []Route{
+			{Destination: "10.0.0.0/8", Gateway: "10.0.0.2"},
+			{Destination: "0.0.0.0/0", Gateway: "192.168.1.1"},
+		}
+
+		changes := DiffSandbox(old, new)
+		r.Len(changes, 2)
+		r.Equal(FieldChange{Path: "route[0].gateway", Old: "10.0.0.1", New: "10.0.0.2"}, changes[0])
+		r.Equal("route[1]", changes[1].Path)
+	})
+
+	t.Run("treats nil as an empty sandbox", func(t *testing.T) {
+		r := require.New(t)
+
+		changes := DiffSandbox(nil, &Sandbox{Status: RUNNING})
+		r.Equal([]FieldChange{{Path: "status", New: string(RUNNING)}}, changes)
+	})
+}
@@ -0,0 +1,131 @@
+package compute_v1alpha
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"miren.dev/runtime/pkg/entity"
+	"miren.dev/runtime/pkg/entity/types"
+)
+
+func TestEncodeChanges(t *testing.T) {
+	newSandbox := func() *Sandbox {
+		return &Sandbox{
+			ID:     "sandbox/web-1",
+			Status: PENDING,
+			Container: []Container{
+				{Name: "app", Image: "web:v1"},
+			},
+			Route: []Route{
+				{Destination: "10.0.0.0/8", Gateway: "10.0.0.1"},
+				{Destination: "0.0.0.0/0", Gateway: "192.168.1.1"},
+			},
+			Volume: []Volume{
+				{Name: "data", Provider: "disk"},
+				{Name: "cache", Provider: "tmpfs", Labels: types.Labels{{Key: "size", Value: "1G"}}},
+			},
+		}
+	}
+
+	stored := func(sb *Sandbox) *entity.Entity {
+		return entity.New(
+			entity.Ref(entity.DBId, sb.ID),
+			sb.Encode,
+		)
+	}
+
+	t.Run("unchanged struct has no changes", func(t *testing.T) {
+		sb := newSandbox()
+		require.Empty(t, sb.EncodeChanges(stored(sb)))
+	})
+
+	t.Run("only changed attributes are emitted", func(t *testing.T) {
+		r := require.New(t)
+
+		old := stored(newSandbox())
+
+		sb := newSandbox()
+		sb.Status = RUNNING
+
+		ops := sb.EncodeChanges(old)
+		r.Len(ops, 2)
+		r.Equal(entity.AttrOpCodeRemove, ops[0].Op)
+		r.True(ops[0].Attr.Equal(entity.Ref(SandboxStatusId, SandboxStatusPendingId)))
+		r.Equal(entity.AttrOpCodeAdd, ops[1].Op)
+		r.True(ops[1].Attr.Equal(entity.Ref(SandboxStatusId, SandboxStatusRunningId)))
+
+		r.NoError(old.ApplyOps(ops))
+		r.Equal(0, old.Compare(stored(sb)))
+	})
+
+	t.Run("reordered routes and volumes aren't changes", func(t *testing.T) {
+		sb := newSandbox()
+		old := stored(sb)
+
+		sb.Route[0], sb.Route[1] = sb.Route[1], sb.Route[0]
+		sb.Volume[0], sb.Volume[1] = sb.Volume[1], sb.Volume[0]
+
+		require.Empty(t, sb.EncodeChanges(old))
+	})
+
+	t.Run("removed values are retracted", func(t *testing.T) {
+		r := require.New(t)
+
+		orig := newSandbox()
+		old := stored(orig)
+
+		sb := newSandbox()
+		sb.Route = sb.Route[1:]
+		sb.Volume = append(sb.Volume[:1], Volume{Name: "logs", Provider: "disk"})
+
+		ops := sb.EncodeChanges(old)
+
+		var removed, added []entity.Attr
+		for _, op := range ops {
+			switch op.Op {
+			case entity.AttrOpCodeRemove:
+				removed = append(removed, op.Attr)
+			case entity.AttrOpCodeAdd:
+				added = append(added, op.Attr)
+			}
+		}
+
+		r.Len(removed, 2)
+		r.True(removed[0].Equal(entity.Component(SandboxRouteId, orig.Route[0].Encode())))
+		r.True(removed[1].Equal(entity.Component(SandboxVolumeId, orig.Volume[1].Encode())))
+
+		r.Len(added, 1)
+		r.True(added[0].Equal(entity.Component(SandboxVolumeId, (&Volume{Name: "logs", Provider: "disk"}).Encode())))
+
+		r.NoError(old.ApplyOps(ops))
+		r.Equal(0, old.Compare(stored(sb)))
+	})
+
+	t.Run("changed nested values replace the whole component", func(t *testing.T) {
+		r := require.New(t)
+
+		old := stored(newSandbox())
+
+		sb := newSandbox()
+		sb.Volume[1].Labels[0].Value = "2G"
+
+		ops := sb.EncodeChanges(old)
+		r.Len(ops, 2)
+		r.Equal(entity.AttrOpCodeRemove, ops[0].Op)
+		r.Equal(entity.AttrOpCodeAdd, ops[1].Op)
+
+		r.NoError(old.ApplyOps(ops))
+		r.Equal(0, old.Compare(stored(sb)))
+	})
+
+	t.Run("attributes outside the schema are left alone", func(t *testing.T) {
+		sb := newSandbox()
+		old := entity.New(
+			entity.Ref(entity.DBId, sb.ID),
+			entity.String("test/note", "added by another writer"),
+			sb.Encode,
+		)
+
+		require.Empty(t, sb.EncodeChanges(old))
+	})
+}
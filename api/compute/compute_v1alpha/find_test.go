@@ -0,0 +1,46 @@
+package compute_v1alpha
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"miren.dev/runtime/pkg/entity"
+)
+
+func TestFindNodesByStatus(t *testing.T) {
+	ctx := context.Background()
+	r := require.New(t)
+
+	store := entity.NewMockStore()
+
+	for id, status := range map[entity.Id]NodeStatus{
+		"node/a": READY,
+		"node/b": DISABLED,
+		"node/c": READY,
+		"node/d": UNHEALTHY,
+	} {
+		node := &Node{ID: id, Status: status}
+
+		_, err := store.CreateEntity(ctx, entity.New(
+			entity.Ref(entity.DBId, id),
+			node.Encode,
+		))
+		r.NoError(err)
+	}
+
+	ids, err := FindNodesByStatus(ctx, store, READY)
+	r.NoError(err)
+	r.ElementsMatch([]entity.Id{"node/a", "node/c"}, ids)
+
+	ids, err = FindNodesByStatus(ctx, store, DISABLED)
+	r.NoError(err)
+	r.Equal([]entity.Id{"node/b"}, ids)
+
+	ids, err = FindNodesByStatus(ctx, store, UNKNOWN)
+	r.NoError(err)
+	r.Empty(ids)
+
+	_, err = FindNodesByStatus(ctx, store, "bogus")
+	r.ErrorIs(err, entity.ErrInvalidChoice)
+}
@@ -1,6 +1,9 @@
 package compute_v1alpha
 
 import (
+	"context"
+	"fmt"
+	"slices"
 	"time"
 
 	entity "miren.dev/runtime/pkg/entity"
@@ -9,25 +12,33 @@ import (
 )
 
 const (
-	SandboxSpecContainerId    = entity.Id("dev.miren.compute/component.sandbox_spec.container")
-	SandboxSpecHostNetworkId  = entity.Id("dev.miren.compute/component.sandbox_spec.hostNetwork")
-	SandboxSpecLogAttributeId = entity.Id("dev.miren.compute/component.sandbox_spec.logAttribute")
-	SandboxSpecLogEntityId    = entity.Id("dev.miren.compute/component.sandbox_spec.logEntity")
-	SandboxSpecRouteId        = entity.Id("dev.miren.compute/component.sandbox_spec.route")
-	SandboxSpecStaticHostId   = entity.Id("dev.miren.compute/component.sandbox_spec.static_host")
-	SandboxSpecVersionId      = entity.Id("dev.miren.compute/component.sandbox_spec.version")
-	SandboxSpecVolumeId       = entity.Id("dev.miren.compute/component.sandbox_spec.volume")
+	SandboxSpecContainerId      = entity.Id("dev.miren.compute/component.sandbox_spec.container")
+	SandboxSpecHostNetworkId    = entity.Id("dev.miren.compute/component.sandbox_spec.hostNetwork")
+	SandboxSpecLogAttributeId   = entity.Id("dev.miren.compute/component.sandbox_spec.logAttribute")
+	SandboxSpecLogEntityId      = entity.Id("dev.miren.compute/component.sandbox_spec.logEntity")
+	SandboxSpecPreemptibleId    = entity.Id("dev.miren.compute/component.sandbox_spec.preemptible")
+	SandboxSpecPriorityId       = entity.Id("dev.miren.compute/component.sandbox_spec.priority")
+	SandboxSpecRouteId          = entity.Id("dev.miren.compute/component.sandbox_spec.route")
+	SandboxSpecRuntimeClassId   = entity.Id("dev.miren.compute/component.sandbox_spec.runtimeClass")
+	SandboxSpecStaticHostId     = entity.Id("dev.miren.compute/component.sandbox_spec.static_host")
+	SandboxSpecStructuredLogsId = entity.Id("dev.miren.compute/component.sandbox_spec.structuredLogs")
+	SandboxSpecVersionId        = entity.Id("dev.miren.compute/component.sandbox_spec.version")
+	SandboxSpecVolumeId         = entity.Id("dev.miren.compute/component.sandbox_spec.volume")
 )
 
 type SandboxSpec struct {
-	Container    []SandboxSpecContainer  `cbor:"container" json:"container"`
-	HostNetwork  bool                    `cbor:"hostNetwork,omitempty" json:"hostNetwork,omitempty"`
-	LogAttribute types.Labels            `cbor:"logAttribute,omitempty" json:"logAttribute,omitempty"`
-	LogEntity    string                  `cbor:"logEntity,omitempty" json:"logEntity,omitempty"`
-	Route        []SandboxSpecRoute      `cbor:"route,omitempty" json:"route,omitempty"`
-	StaticHost   []SandboxSpecStaticHost `cbor:"static_host,omitempty" json:"static_host,omitempty"`
-	Version      entity.Id               `cbor:"version,omitempty" json:"version,omitempty"`
-	Volume       []SandboxSpecVolume     `cbor:"volume,omitempty" json:"volume,omitempty"`
+	Container      []SandboxSpecContainer  `cbor:"container" json:"container"`
+	HostNetwork    bool                    `cbor:"hostNetwork,omitempty" json:"hostNetwork,omitempty"`
+	LogAttribute   types.Labels            `cbor:"logAttribute,omitempty" json:"logAttribute,omitempty"`
+	LogEntity      string                  `cbor:"logEntity,omitempty" json:"logEntity,omitempty"`
+	Preemptible    bool                    `cbor:"preemptible,omitempty" json:"preemptible,omitempty"`
+	Priority       int64                   `cbor:"priority,omitempty" json:"priority,omitempty"`
+	Route          []SandboxSpecRoute      `cbor:"route,omitempty" json:"route,omitempty"`
+	RuntimeClass   string                  `cbor:"runtimeClass,omitempty" json:"runtimeClass,omitempty"`
+	StaticHost     []SandboxSpecStaticHost `cbor:"static_host,omitempty" json:"static_host,omitempty"`
+	StructuredLogs bool                    `cbor:"structuredLogs,omitempty" json:"structuredLogs,omitempty"`
+	Version        entity.Id               `cbor:"version,omitempty" json:"version,omitempty"`
+	Volume         []SandboxSpecVolume     `cbor:"volume,omitempty" json:"volume,omitempty"`
 }
 
 func (o *SandboxSpec) Decode(e entity.AttrGetter) {
@@ -49,6 +60,12 @@ func (o *SandboxSpec) Decode(e entity.AttrGetter) {
 	if a, ok := e.Get(SandboxSpecLogEntityId); ok && a.Value.Kind() == entity.KindString {
 		o.LogEntity = a.Value.String()
 	}
+	if a, ok := e.Get(SandboxSpecPreemptibleId); ok && a.Value.Kind() == entity.KindBool {
+		o.Preemptible = a.Value.Bool()
+	}
+	if a, ok := e.Get(SandboxSpecPriorityId); ok && a.Value.Kind() == entity.KindInt64 {
+		o.Priority = a.Value.Int64()
+	}
 	for _, a := range e.GetAll(SandboxSpecRouteId) {
 		if a.Value.Kind() == entity.KindComponent {
 			var v SandboxSpecRoute
@@ -56,6 +73,9 @@ func (o *SandboxSpec) Decode(e entity.AttrGetter) {
 			o.Route = append(o.Route, v)
 		}
 	}
+	if a, ok := e.Get(SandboxSpecRuntimeClassId); ok && a.Value.Kind() == entity.KindString {
+		o.RuntimeClass = a.Value.String()
+	}
 	for _, a := range e.GetAll(SandboxSpecStaticHostId) {
 		if a.Value.Kind() == entity.KindComponent {
 			var v SandboxSpecStaticHost
@@ -63,6 +83,9 @@ func (o *SandboxSpec) Decode(e entity.AttrGetter) {
 			o.StaticHost = append(o.StaticHost, v)
 		}
 	}
+	if a, ok := e.Get(SandboxSpecStructuredLogsId); ok && a.Value.Kind() == entity.KindBool {
+		o.StructuredLogs = a.Value.Bool()
+	}
 	if a, ok := e.Get(SandboxSpecVersionId); ok && a.Value.Kind() == entity.KindId {
 		o.Version = a.Value.Id()
 	}
@@ -86,12 +109,20 @@ func (o *SandboxSpec) Encode() (attrs []entity.Attr) {
 	if !entity.Empty(o.LogEntity) {
 		attrs = append(attrs, entity.String(SandboxSpecLogEntityId, o.LogEntity))
 	}
+	attrs = append(attrs, entity.Bool(SandboxSpecPreemptibleId, o.Preemptible))
+	if !entity.Empty(o.Priority) {
+		attrs = append(attrs, entity.Int64(SandboxSpecPriorityId, o.Priority))
+	}
 	for _, v := range o.Route {
 		attrs = append(attrs, entity.Component(SandboxSpecRouteId, v.Encode()))
 	}
+	if !entity.Empty(o.RuntimeClass) {
+		attrs = append(attrs, entity.String(SandboxSpecRuntimeClassId, o.RuntimeClass))
+	}
 	for _, v := range o.StaticHost {
 		attrs = append(attrs, entity.Component(SandboxSpecStaticHostId, v.Encode()))
 	}
+	attrs = append(attrs, entity.Bool(SandboxSpecStructuredLogsId, o.StructuredLogs))
 	if !entity.Empty(o.Version) {
 		attrs = append(attrs, entity.Ref(SandboxSpecVersionId, o.Version))
 	}
@@ -114,12 +145,24 @@ func (o *SandboxSpec) Empty() bool {
 	if !entity.Empty(o.LogEntity) {
 		return false
 	}
+	if !entity.Empty(o.Preemptible) {
+		return false
+	}
+	if !entity.Empty(o.Priority) {
+		return false
+	}
 	if len(o.Route) != 0 {
 		return false
 	}
+	if !entity.Empty(o.RuntimeClass) {
+		return false
+	}
 	if len(o.StaticHost) != 0 {
 		return false
 	}
+	if !entity.Empty(o.StructuredLogs) {
+		return false
+	}
 	if !entity.Empty(o.Version) {
 		return false
 	}
@@ -129,52 +172,138 @@ func (o *SandboxSpec) Empty() bool {
 	return true
 }
 
+func (o *SandboxSpec) DeepCopy() *SandboxSpec {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	if o.Container != nil {
+		out.Container = make([]SandboxSpecContainer, len(o.Container))
+		for i := range o.Container {
+			out.Container[i] = *o.Container[i].DeepCopy()
+		}
+	}
+	out.LogAttribute = slices.Clone(o.LogAttribute)
+	if o.Route != nil {
+		out.Route = make([]SandboxSpecRoute, len(o.Route))
+		for i := range o.Route {
+			out.Route[i] = *o.Route[i].DeepCopy()
+		}
+	}
+	if o.StaticHost != nil {
+		out.StaticHost = make([]SandboxSpecStaticHost, len(o.StaticHost))
+		for i := range o.StaticHost {
+			out.StaticHost[i] = *o.StaticHost[i].DeepCopy()
+		}
+	}
+	if o.Volume != nil {
+		out.Volume = make([]SandboxSpecVolume, len(o.Volume))
+		for i := range o.Volume {
+			out.Volume[i] = *o.Volume[i].DeepCopy()
+		}
+	}
+	return &out
+}
+
+func (o *SandboxSpec) Validate() error {
+	if len(o.Container) == 0 {
+		return fmt.Errorf("%w: %s", entity.ErrRequiredAttr, "container")
+	}
+	for i, v := range o.Container {
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("%s[%d]: %w", "container", i, err)
+		}
+	}
+	for i, v := range o.Route {
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("%s[%d]: %w", "route", i, err)
+		}
+	}
+	for i, v := range o.StaticHost {
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("%s[%d]: %w", "static_host", i, err)
+		}
+	}
+	for i, v := range o.Volume {
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("%s[%d]: %w", "volume", i, err)
+		}
+	}
+	return nil
+}
+
 func (o *SandboxSpec) InitSchema(sb *schema.SchemaBuilder) {
 	sb.Component("container", "dev.miren.compute/component.sandbox_spec.container", schema.Doc("Container specification"), schema.Many, schema.Required)
 	(&SandboxSpecContainer{}).InitSchema(sb.Builder("component.sandbox_spec.container"))
 	sb.Bool("hostNetwork", "dev.miren.compute/component.sandbox_spec.hostNetwork", schema.Doc("Whether to use host networking"))
 	sb.Label("logAttribute", "dev.miren.compute/component.sandbox_spec.logAttribute", schema.Doc("Labels for log entries"), schema.Many)
 	sb.String("logEntity", "dev.miren.compute/component.sandbox_spec.logEntity", schema.Doc("Entity to associate log output with"))
+	sb.Bool("preemptible", "dev.miren.compute/component.sandbox_spec.preemptible", schema.Doc("Whether the sandbox tolerates preemption, preemptible sandboxes are evicted before all others"))
+	sb.Int64("priority", "dev.miren.compute/component.sandbox_spec.priority", schema.Doc("Eviction priority, sandboxes with lower values are evicted first when a node is drained or preempted"))
 	sb.Component("route", "dev.miren.compute/component.sandbox_spec.route", schema.Doc("Network route configuration"), schema.Many)
 	(&SandboxSpecRoute{}).InitSchema(sb.Builder("component.sandbox_spec.route"))
+	sb.String("runtimeClass", "dev.miren.compute/component.sandbox_spec.runtimeClass", schema.Doc("The container runtime to run the sandbox with, such as runc or runsc. Defaults to runc"))
 	sb.Component("static_host", "dev.miren.compute/component.sandbox_spec.static_host", schema.Doc("Static host-to-IP mapping"), schema.Many)
 	(&SandboxSpecStaticHost{}).InitSchema(sb.Builder("component.sandbox_spec.static_host"))
+	sb.Bool("structuredLogs", "dev.miren.compute/component.sandbox_spec.structuredLogs", schema.Doc("Whether JSON lines the sandbox writes to stdout are stored as structured logs"))
 	sb.Ref("version", "dev.miren.compute/component.sandbox_spec.version", schema.Doc("Application version reference"), schema.Indexed)
 	sb.Component("volume", "dev.miren.compute/component.sandbox_spec.volume", schema.Doc("Volume configuration"), schema.Many)
 	(&SandboxSpecVolume{}).InitSchema(sb.Builder("component.sandbox_spec.volume"))
 }
 
 const (
-	SandboxSpecContainerCommandId    = entity.Id("dev.miren.compute/component.sandbox_spec.container.command")
-	SandboxSpecContainerConfigFileId = entity.Id("dev.miren.compute/component.sandbox_spec.container.config_file")
-	SandboxSpecContainerDirectoryId  = entity.Id("dev.miren.compute/component.sandbox_spec.container.directory")
-	SandboxSpecContainerEnvId        = entity.Id("dev.miren.compute/component.sandbox_spec.container.env")
-	SandboxSpecContainerImageId      = entity.Id("dev.miren.compute/component.sandbox_spec.container.image")
-	SandboxSpecContainerMountId      = entity.Id("dev.miren.compute/component.sandbox_spec.container.mount")
-	SandboxSpecContainerNameId       = entity.Id("dev.miren.compute/component.sandbox_spec.container.name")
-	SandboxSpecContainerOomScoreId   = entity.Id("dev.miren.compute/component.sandbox_spec.container.oom_score")
-	SandboxSpecContainerPortId       = entity.Id("dev.miren.compute/component.sandbox_spec.container.port")
-	SandboxSpecContainerPrivilegedId = entity.Id("dev.miren.compute/component.sandbox_spec.container.privileged")
-	SandboxSpecContainerStdinId      = entity.Id("dev.miren.compute/component.sandbox_spec.container.stdin")
-	SandboxSpecContainerTtyId        = entity.Id("dev.miren.compute/component.sandbox_spec.container.tty")
+	SandboxSpecContainerAddonEnvId         = entity.Id("dev.miren.compute/component.sandbox_spec.container.addon_env")
+	SandboxSpecContainerApparmorProfileId  = entity.Id("dev.miren.compute/component.sandbox_spec.container.apparmor_profile")
+	SandboxSpecContainerCommandId          = entity.Id("dev.miren.compute/component.sandbox_spec.container.command")
+	SandboxSpecContainerConfigFileId       = entity.Id("dev.miren.compute/component.sandbox_spec.container.config_file")
+	SandboxSpecContainerDirectoryId        = entity.Id("dev.miren.compute/component.sandbox_spec.container.directory")
+	SandboxSpecContainerEnvId              = entity.Id("dev.miren.compute/component.sandbox_spec.container.env")
+	SandboxSpecContainerImageId            = entity.Id("dev.miren.compute/component.sandbox_spec.container.image")
+	SandboxSpecContainerMountId            = entity.Id("dev.miren.compute/component.sandbox_spec.container.mount")
+	SandboxSpecContainerNameId             = entity.Id("dev.miren.compute/component.sandbox_spec.container.name")
+	SandboxSpecContainerOomScoreId         = entity.Id("dev.miren.compute/component.sandbox_spec.container.oom_score")
+	SandboxSpecContainerPortId             = entity.Id("dev.miren.compute/component.sandbox_spec.container.port")
+	SandboxSpecContainerPrivilegedId       = entity.Id("dev.miren.compute/component.sandbox_spec.container.privileged")
+	SandboxSpecContainerSeccompProfileId   = entity.Id("dev.miren.compute/component.sandbox_spec.container.seccomp_profile")
+	SandboxSpecContainerStdinId            = entity.Id("dev.miren.compute/component.sandbox_spec.container.stdin")
+	SandboxSpecContainerSysctlId           = entity.Id("dev.miren.compute/component.sandbox_spec.container.sysctl")
+	SandboxSpecContainerTerminationGraceId = entity.Id("dev.miren.compute/component.sandbox_spec.container.termination_grace")
+	SandboxSpecContainerTtyId              = entity.Id("dev.miren.compute/component.sandbox_spec.container.tty")
+	SandboxSpecContainerUlimitId           = entity.Id("dev.miren.compute/component.sandbox_spec.container.ulimit")
 )
 
 type SandboxSpecContainer struct {
-	Command    string                           `cbor:"command,omitempty" json:"command,omitempty"`
-	ConfigFile []SandboxSpecContainerConfigFile `cbor:"config_file,omitempty" json:"config_file,omitempty"`
-	Directory  string                           `cbor:"directory,omitempty" json:"directory,omitempty"`
-	Env        []string                         `cbor:"env,omitempty" json:"env,omitempty"`
-	Image      string                           `cbor:"image" json:"image"`
-	Mount      []SandboxSpecContainerMount      `cbor:"mount,omitempty" json:"mount,omitempty"`
-	Name       string                           `cbor:"name,omitempty" json:"name,omitempty"`
-	OomScore   int64                            `cbor:"oom_score,omitempty" json:"oom_score,omitempty"`
-	Port       []SandboxSpecContainerPort       `cbor:"port,omitempty" json:"port,omitempty"`
-	Privileged bool                             `cbor:"privileged,omitempty" json:"privileged,omitempty"`
-	Stdin      bool                             `cbor:"stdin,omitempty" json:"stdin,omitempty"`
-	Tty        bool                             `cbor:"tty,omitempty" json:"tty,omitempty"`
+	AddonEnv         []SandboxSpecContainerAddonEnv   `cbor:"addon_env,omitempty" json:"addon_env,omitempty"`
+	ApparmorProfile  string                           `cbor:"apparmor_profile,omitempty" json:"apparmor_profile,omitempty"`
+	Command          string                           `cbor:"command,omitempty" json:"command,omitempty"`
+	ConfigFile       []SandboxSpecContainerConfigFile `cbor:"config_file,omitempty" json:"config_file,omitempty"`
+	Directory        string                           `cbor:"directory,omitempty" json:"directory,omitempty"`
+	Env              []string                         `cbor:"env,omitempty" json:"env,omitempty"`
+	Image            string                           `cbor:"image" json:"image"`
+	Mount            []SandboxSpecContainerMount      `cbor:"mount,omitempty" json:"mount,omitempty"`
+	Name             string                           `cbor:"name,omitempty" json:"name,omitempty"`
+	OomScore         int64                            `cbor:"oom_score,omitempty" json:"oom_score,omitempty"`
+	Port             []SandboxSpecContainerPort       `cbor:"port,omitempty" json:"port,omitempty"`
+	Privileged       bool                             `cbor:"privileged,omitempty" json:"privileged,omitempty"`
+	SeccompProfile   string                           `cbor:"seccomp_profile,omitempty" json:"seccomp_profile,omitempty"`
+	Stdin            bool                             `cbor:"stdin,omitempty" json:"stdin,omitempty"`
+	Sysctl           []SandboxSpecContainerSysctl     `cbor:"sysctl,omitempty" json:"sysctl,omitempty"`
+	TerminationGrace time.Duration                    `cbor:"termination_grace,omitempty" json:"termination_grace,omitempty"`
+	Tty              bool                             `cbor:"tty,omitempty" json:"tty,omitempty"`
+	Ulimit           []SandboxSpecContainerUlimit     `cbor:"ulimit,omitempty" json:"ulimit,omitempty"`
 }
 
 func (o *SandboxSpecContainer) Decode(e entity.AttrGetter) {
+	for _, a := range e.GetAll(SandboxSpecContainerAddonEnvId) {
+		if a.Value.Kind() == entity.KindComponent {
+			var v SandboxSpecContainerAddonEnv
+			v.Decode(a.Value.Component())
+			o.AddonEnv = append(o.AddonEnv, v)
+		}
+	}
+	if a, ok := e.Get(SandboxSpecContainerApparmorProfileId); ok && a.Value.Kind() == entity.KindString {
+		o.ApparmorProfile = a.Value.String()
+	}
 	if a, ok := e.Get(SandboxSpecContainerCommandId); ok && a.Value.Kind() == entity.KindString {
 		o.Command = a.Value.String()
 	}
@@ -219,15 +348,41 @@ func (o *SandboxSpecContainer) Decode(e entity.AttrGetter) {
 	if a, ok := e.Get(SandboxSpecContainerPrivilegedId); ok && a.Value.Kind() == entity.KindBool {
 		o.Privileged = a.Value.Bool()
 	}
+	if a, ok := e.Get(SandboxSpecContainerSeccompProfileId); ok && a.Value.Kind() == entity.KindString {
+		o.SeccompProfile = a.Value.String()
+	}
 	if a, ok := e.Get(SandboxSpecContainerStdinId); ok && a.Value.Kind() == entity.KindBool {
 		o.Stdin = a.Value.Bool()
 	}
+	for _, a := range e.GetAll(SandboxSpecContainerSysctlId) {
+		if a.Value.Kind() == entity.KindComponent {
+			var v SandboxSpecContainerSysctl
+			v.Decode(a.Value.Component())
+			o.Sysctl = append(o.Sysctl, v)
+		}
+	}
+	if a, ok := e.Get(SandboxSpecContainerTerminationGraceId); ok && a.Value.Kind() == entity.KindDuration {
+		o.TerminationGrace = a.Value.Duration()
+	}
 	if a, ok := e.Get(SandboxSpecContainerTtyId); ok && a.Value.Kind() == entity.KindBool {
 		o.Tty = a.Value.Bool()
 	}
+	for _, a := range e.GetAll(SandboxSpecContainerUlimitId) {
+		if a.Value.Kind() == entity.KindComponent {
+			var v SandboxSpecContainerUlimit
+			v.Decode(a.Value.Component())
+			o.Ulimit = append(o.Ulimit, v)
+		}
+	}
 }
 
 func (o *SandboxSpecContainer) Encode() (attrs []entity.Attr) {
+	for _, v := range o.AddonEnv {
+		attrs = append(attrs, entity.Component(SandboxSpecContainerAddonEnvId, v.Encode()))
+	}
+	if !entity.Empty(o.ApparmorProfile) {
+		attrs = append(attrs, entity.String(SandboxSpecContainerApparmorProfileId, o.ApparmorProfile))
+	}
 	if !entity.Empty(o.Command) {
 		attrs = append(attrs, entity.String(SandboxSpecContainerCommandId, o.Command))
 	}
@@ -256,12 +411,30 @@ func (o *SandboxSpecContainer) Encode() (attrs []entity.Attr) {
 		attrs = append(attrs, entity.Component(SandboxSpecContainerPortId, v.Encode()))
 	}
 	attrs = append(attrs, entity.Bool(SandboxSpecContainerPrivilegedId, o.Privileged))
+	if !entity.Empty(o.SeccompProfile) {
+		attrs = append(attrs, entity.String(SandboxSpecContainerSeccompProfileId, o.SeccompProfile))
+	}
 	attrs = append(attrs, entity.Bool(SandboxSpecContainerStdinId, o.Stdin))
+	for _, v := range o.Sysctl {
+		attrs = append(attrs, entity.Component(SandboxSpecContainerSysctlId, v.Encode()))
+	}
+	if !entity.Empty(o.TerminationGrace) {
+		attrs = append(attrs, entity.Duration(SandboxSpecContainerTerminationGraceId, o.TerminationGrace))
+	}
 	attrs = append(attrs, entity.Bool(SandboxSpecContainerTtyId, o.Tty))
+	for _, v := range o.Ulimit {
+		attrs = append(attrs, entity.Component(SandboxSpecContainerUlimitId, v.Encode()))
+	}
 	return
 }
 
 func (o *SandboxSpecContainer) Empty() bool {
+	if len(o.AddonEnv) != 0 {
+		return false
+	}
+	if !entity.Empty(o.ApparmorProfile) {
+		return false
+	}
 	if !entity.Empty(o.Command) {
 		return false
 	}
@@ -292,16 +465,113 @@ func (o *SandboxSpecContainer) Empty() bool {
 	if !entity.Empty(o.Privileged) {
 		return false
 	}
+	if !entity.Empty(o.SeccompProfile) {
+		return false
+	}
 	if !entity.Empty(o.Stdin) {
 		return false
 	}
+	if len(o.Sysctl) != 0 {
+		return false
+	}
+	if !entity.Empty(o.TerminationGrace) {
+		return false
+	}
 	if !entity.Empty(o.Tty) {
 		return false
 	}
+	if len(o.Ulimit) != 0 {
+		return false
+	}
 	return true
 }
 
+func (o *SandboxSpecContainer) DeepCopy() *SandboxSpecContainer {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	if o.AddonEnv != nil {
+		out.AddonEnv = make([]SandboxSpecContainerAddonEnv, len(o.AddonEnv))
+		for i := range o.AddonEnv {
+			out.AddonEnv[i] = *o.AddonEnv[i].DeepCopy()
+		}
+	}
+	if o.ConfigFile != nil {
+		out.ConfigFile = make([]SandboxSpecContainerConfigFile, len(o.ConfigFile))
+		for i := range o.ConfigFile {
+			out.ConfigFile[i] = *o.ConfigFile[i].DeepCopy()
+		}
+	}
+	out.Env = slices.Clone(o.Env)
+	if o.Mount != nil {
+		out.Mount = make([]SandboxSpecContainerMount, len(o.Mount))
+		for i := range o.Mount {
+			out.Mount[i] = *o.Mount[i].DeepCopy()
+		}
+	}
+	if o.Port != nil {
+		out.Port = make([]SandboxSpecContainerPort, len(o.Port))
+		for i := range o.Port {
+			out.Port[i] = *o.Port[i].DeepCopy()
+		}
+	}
+	if o.Sysctl != nil {
+		out.Sysctl = make([]SandboxSpecContainerSysctl, len(o.Sysctl))
+		for i := range o.Sysctl {
+			out.Sysctl[i] = *o.Sysctl[i].DeepCopy()
+		}
+	}
+	if o.Ulimit != nil {
+		out.Ulimit = make([]SandboxSpecContainerUlimit, len(o.Ulimit))
+		for i := range o.Ulimit {
+			out.Ulimit[i] = *o.Ulimit[i].DeepCopy()
+		}
+	}
+	return &out
+}
+
+func (o *SandboxSpecContainer) Validate() error {
+	for i, v := range o.AddonEnv {
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("%s[%d]: %w", "addon_env", i, err)
+		}
+	}
+	for i, v := range o.ConfigFile {
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("%s[%d]: %w", "config_file", i, err)
+		}
+	}
+	if entity.Empty(o.Image) {
+		return fmt.Errorf("%w: %s", entity.ErrRequiredAttr, "image")
+	}
+	for i, v := range o.Mount {
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("%s[%d]: %w", "mount", i, err)
+		}
+	}
+	for i, v := range o.Port {
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("%s[%d]: %w", "port", i, err)
+		}
+	}
+	for i, v := range o.Sysctl {
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("%s[%d]: %w", "sysctl", i, err)
+		}
+	}
+	for i, v := range o.Ulimit {
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("%s[%d]: %w", "ulimit", i, err)
+		}
+	}
+	return nil
+}
+
 func (o *SandboxSpecContainer) InitSchema(sb *schema.SchemaBuilder) {
+	sb.Component("addon_env", "dev.miren.compute/component.sandbox_spec.container.addon_env", schema.Doc("Environment variable whose value an addon instance provides, resolved when the container starts"), schema.Many)
+	(&SandboxSpecContainerAddonEnv{}).InitSchema(sb.Builder("component.sandbox_spec.container.addon_env"))
+	sb.String("apparmor_profile", "dev.miren.compute/component.sandbox_spec.container.apparmor_profile", schema.Doc("AppArmor profile for the container, either unconfined (used when unset), default (the runtime's profile), or the name of a profile loaded on the node"))
 	sb.String("command", "dev.miren.compute/component.sandbox_spec.container.command", schema.Doc("Command to run"))
 	sb.Component("config_file", "dev.miren.compute/component.sandbox_spec.container.config_file", schema.Doc("File to write into container"), schema.Many)
 	(&SandboxSpecContainerConfigFile{}).InitSchema(sb.Builder("component.sandbox_spec.container.config_file"))
@@ -315,8 +585,91 @@ func (o *SandboxSpecContainer) InitSchema(sb *schema.SchemaBuilder) {
 	sb.Component("port", "dev.miren.compute/component.sandbox_spec.container.port", schema.Doc("Network port declaration"), schema.Many)
 	(&SandboxSpecContainerPort{}).InitSchema(sb.Builder("component.sandbox_spec.container.port"))
 	sb.Bool("privileged", "dev.miren.compute/component.sandbox_spec.container.privileged", schema.Doc("Whether container runs in privileged mode"))
+	sb.String("seccomp_profile", "dev.miren.compute/component.sandbox_spec.container.seccomp_profile", schema.Doc("Seccomp profile for the container, either default (the runtime's restrictive profile, used when unset), unconfined, or the absolute path of a JSON profile on the node"))
 	sb.Bool("stdin", "dev.miren.compute/component.sandbox_spec.container.stdin", schema.Doc("Keep stdin open for the container"))
+	sb.Component("sysctl", "dev.miren.compute/component.sandbox_spec.container.sysctl", schema.Doc("Kernel parameter to set for the container, which must be allowed by the node"), schema.Many)
+	(&SandboxSpecContainerSysctl{}).InitSchema(sb.Builder("component.sandbox_spec.container.sysctl"))
+	sb.Duration("termination_grace", "dev.miren.compute/component.sandbox_spec.container.termination_grace", schema.Doc("How long the container has to exit after SIGTERM before it is killed (0 uses the node default)"))
 	sb.Bool("tty", "dev.miren.compute/component.sandbox_spec.container.tty", schema.Doc("Allocate a TTY for the container"))
+	sb.Component("ulimit", "dev.miren.compute/component.sandbox_spec.container.ulimit", schema.Doc("Resource limit for the container's processes"), schema.Many)
+	(&SandboxSpecContainerUlimit{}).InitSchema(sb.Builder("component.sandbox_spec.container.ulimit"))
+}
+
+const (
+	SandboxSpecContainerAddonEnvAddonId = entity.Id("dev.miren.compute/component.sandbox_spec.container.addon_env.addon")
+	SandboxSpecContainerAddonEnvKeyId   = entity.Id("dev.miren.compute/component.sandbox_spec.container.addon_env.key")
+	SandboxSpecContainerAddonEnvNameId  = entity.Id("dev.miren.compute/component.sandbox_spec.container.addon_env.name")
+)
+
+type SandboxSpecContainerAddonEnv struct {
+	Addon string `cbor:"addon" json:"addon"`
+	Key   string `cbor:"key" json:"key"`
+	Name  string `cbor:"name" json:"name"`
+}
+
+func (o *SandboxSpecContainerAddonEnv) Decode(e entity.AttrGetter) {
+	if a, ok := e.Get(SandboxSpecContainerAddonEnvAddonId); ok && a.Value.Kind() == entity.KindString {
+		o.Addon = a.Value.String()
+	}
+	if a, ok := e.Get(SandboxSpecContainerAddonEnvKeyId); ok && a.Value.Kind() == entity.KindString {
+		o.Key = a.Value.String()
+	}
+	if a, ok := e.Get(SandboxSpecContainerAddonEnvNameId); ok && a.Value.Kind() == entity.KindString {
+		o.Name = a.Value.String()
+	}
+}
+
+func (o *SandboxSpecContainerAddonEnv) Encode() (attrs []entity.Attr) {
+	if !entity.Empty(o.Addon) {
+		attrs = append(attrs, entity.String(SandboxSpecContainerAddonEnvAddonId, o.Addon))
+	}
+	if !entity.Empty(o.Key) {
+		attrs = append(attrs, entity.String(SandboxSpecContainerAddonEnvKeyId, o.Key))
+	}
+	if !entity.Empty(o.Name) {
+		attrs = append(attrs, entity.String(SandboxSpecContainerAddonEnvNameId, o.Name))
+	}
+	return
+}
+
+func (o *SandboxSpecContainerAddonEnv) Empty() bool {
+	if !entity.Empty(o.Addon) {
+		return false
+	}
+	if !entity.Empty(o.Key) {
+		return false
+	}
+	if !entity.Empty(o.Name) {
+		return false
+	}
+	return true
+}
+
+func (o *SandboxSpecContainerAddonEnv) DeepCopy() *SandboxSpecContainerAddonEnv {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	return &out
+}
+
+func (o *SandboxSpecContainerAddonEnv) Validate() error {
+	if entity.Empty(o.Addon) {
+		return fmt.Errorf("%w: %s", entity.ErrRequiredAttr, "addon")
+	}
+	if entity.Empty(o.Key) {
+		return fmt.Errorf("%w: %s", entity.ErrRequiredAttr, "key")
+	}
+	if entity.Empty(o.Name) {
+		return fmt.Errorf("%w: %s", entity.ErrRequiredAttr, "name")
+	}
+	return nil
+}
+
+func (o *SandboxSpecContainerAddonEnv) InitSchema(sb *schema.SchemaBuilder) {
+	sb.String("addon", "dev.miren.compute/component.sandbox_spec.container.addon_env.addon", schema.Doc("The addon instance providing the value"), schema.Required)
+	sb.String("key", "dev.miren.compute/component.sandbox_spec.container.addon_env.key", schema.Doc("The key of the value among those the addon instance provides"), schema.Required)
+	sb.String("name", "dev.miren.compute/component.sandbox_spec.container.addon_env.name", schema.Doc("The name of the variable"), schema.Required)
 }
 
 const (
@@ -369,6 +722,18 @@ func (o *SandboxSpecContainerConfigFile) Empty() bool {
 	return true
 }
 
+func (o *SandboxSpecContainerConfigFile) DeepCopy() *SandboxSpecContainerConfigFile {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	return &out
+}
+
+func (o *SandboxSpecContainerConfigFile) Validate() error {
+	return nil
+}
+
 func (o *SandboxSpecContainerConfigFile) InitSchema(sb *schema.SchemaBuilder) {
 	sb.String("data", "dev.miren.compute/component.sandbox_spec.container.config_file.data", schema.Doc("File contents"))
 	sb.String("mode", "dev.miren.compute/component.sandbox_spec.container.config_file.mode", schema.Doc("File mode"))
@@ -377,11 +742,13 @@ func (o *SandboxSpecContainerConfigFile) InitSchema(sb *schema.SchemaBuilder) {
 
 const (
 	SandboxSpecContainerMountDestinationId = entity.Id("dev.miren.compute/component.sandbox_spec.container.mount.destination")
+	SandboxSpecContainerMountHostPathId    = entity.Id("dev.miren.compute/component.sandbox_spec.container.mount.host_path")
 	SandboxSpecContainerMountSourceId      = entity.Id("dev.miren.compute/component.sandbox_spec.container.mount.source")
 )
 
 type SandboxSpecContainerMount struct {
 	Destination string `cbor:"destination,omitempty" json:"destination,omitempty"`
+	HostPath    string `cbor:"host_path,omitempty" json:"host_path,omitempty"`
 	Source      string `cbor:"source,omitempty" json:"source,omitempty"`
 }
 
@@ -389,6 +756,9 @@ func (o *SandboxSpecContainerMount) Decode(e entity.AttrGetter) {
 	if a, ok := e.Get(SandboxSpecContainerMountDestinationId); ok && a.Value.Kind() == entity.KindString {
 		o.Destination = a.Value.String()
 	}
+	if a, ok := e.Get(SandboxSpecContainerMountHostPathId); ok && a.Value.Kind() == entity.KindString {
+		o.HostPath = a.Value.String()
+	}
 	if a, ok := e.Get(SandboxSpecContainerMountSourceId); ok && a.Value.Kind() == entity.KindString {
 		o.Source = a.Value.String()
 	}
@@ -398,6 +768,9 @@ func (o *SandboxSpecContainerMount) Encode() (attrs []entity.Attr) {
 	if !entity.Empty(o.Destination) {
 		attrs = append(attrs, entity.String(SandboxSpecContainerMountDestinationId, o.Destination))
 	}
+	if !entity.Empty(o.HostPath) {
+		attrs = append(attrs, entity.String(SandboxSpecContainerMountHostPathId, o.HostPath))
+	}
 	if !entity.Empty(o.Source) {
 		attrs = append(attrs, entity.String(SandboxSpecContainerMountSourceId, o.Source))
 	}
@@ -408,14 +781,30 @@ func (o *SandboxSpecContainerMount) Empty() bool {
 	if !entity.Empty(o.Destination) {
 		return false
 	}
+	if !entity.Empty(o.HostPath) {
+		return false
+	}
 	if !entity.Empty(o.Source) {
 		return false
 	}
 	return true
 }
 
+func (o *SandboxSpecContainerMount) DeepCopy() *SandboxSpecContainerMount {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	return &out
+}
+
+func (o *SandboxSpecContainerMount) Validate() error {
+	return nil
+}
+
 func (o *SandboxSpecContainerMount) InitSchema(sb *schema.SchemaBuilder) {
 	sb.String("destination", "dev.miren.compute/component.sandbox_spec.container.mount.destination", schema.Doc("Mount destination path"))
+	sb.String("host_path", "dev.miren.compute/component.sandbox_spec.container.mount.host_path", schema.Doc("Host path to bind mount instead of a volume, which must be under a prefix allowed by the node"))
 	sb.String("source", "dev.miren.compute/component.sandbox_spec.container.mount.source", schema.Doc("Mount source path"))
 }
 
@@ -501,6 +890,26 @@ func (o *SandboxSpecContainerPort) Empty() bool {
 	return true
 }
 
+func (o *SandboxSpecContainerPort) DeepCopy() *SandboxSpecContainerPort {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	return &out
+}
+
+func (o *SandboxSpecContainerPort) Validate() error {
+	if entity.Empty(o.Name) {
+		return fmt.Errorf("%w: %s", entity.ErrRequiredAttr, "name")
+	}
+	if o.Protocol != "" {
+		if _, ok := SandboxSpecContainerPortprotocolToId[o.Protocol]; !ok {
+			return fmt.Errorf("%w: %s: %q", entity.ErrInvalidChoice, "protocol", o.Protocol)
+		}
+	}
+	return nil
+}
+
 func (o *SandboxSpecContainerPort) InitSchema(sb *schema.SchemaBuilder) {
 	sb.String("name", "dev.miren.compute/component.sandbox_spec.container.port.name", schema.Doc("Port name"), schema.Required)
 	sb.Int64("node_port", "dev.miren.compute/component.sandbox_spec.container.port.node_port", schema.Doc("The port number that should be forwarded from the node to the container"))
@@ -511,6 +920,139 @@ func (o *SandboxSpecContainerPort) InitSchema(sb *schema.SchemaBuilder) {
 	sb.String("type", "dev.miren.compute/component.sandbox_spec.container.port.type", schema.Doc("High-level port type (e.g., http)"))
 }
 
+const (
+	SandboxSpecContainerSysctlNameId  = entity.Id("dev.miren.compute/component.sandbox_spec.container.sysctl.name")
+	SandboxSpecContainerSysctlValueId = entity.Id("dev.miren.compute/component.sandbox_spec.container.sysctl.value")
+)
+
+type SandboxSpecContainerSysctl struct {
+	Name  string `cbor:"name" json:"name"`
+	Value string `cbor:"value" json:"value"`
+}
+
+func (o *SandboxSpecContainerSysctl) Decode(e entity.AttrGetter) {
+	if a, ok := e.Get(SandboxSpecContainerSysctlNameId); ok && a.Value.Kind() == entity.KindString {
+		o.Name = a.Value.String()
+	}
+	if a, ok := e.Get(SandboxSpecContainerSysctlValueId); ok && a.Value.Kind() == entity.KindString {
+		o.Value = a.Value.String()
+	}
+}
+
+func (o *SandboxSpecContainerSysctl) Encode() (attrs []entity.Attr) {
+	if !entity.Empty(o.Name) {
+		attrs = append(attrs, entity.String(SandboxSpecContainerSysctlNameId, o.Name))
+	}
+	if !entity.Empty(o.Value) {
+		attrs = append(attrs, entity.String(SandboxSpecContainerSysctlValueId, o.Value))
+	}
+	return
+}
+
+func (o *SandboxSpecContainerSysctl) Empty() bool {
+	if !entity.Empty(o.Name) {
+		return false
+	}
+	if !entity.Empty(o.Value) {
+		return false
+	}
+	return true
+}
+
+func (o *SandboxSpecContainerSysctl) DeepCopy() *SandboxSpecContainerSysctl {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	return &out
+}
+
+func (o *SandboxSpecContainerSysctl) Validate() error {
+	if entity.Empty(o.Name) {
+		return fmt.Errorf("%w: %s", entity.ErrRequiredAttr, "name")
+	}
+	if entity.Empty(o.Value) {
+		return fmt.Errorf("%w: %s", entity.ErrRequiredAttr, "value")
+	}
+	return nil
+}
+
+func (o *SandboxSpecContainerSysctl) InitSchema(sb *schema.SchemaBuilder) {
+	sb.String("name", "dev.miren.compute/component.sandbox_spec.container.sysctl.name", schema.Doc("Sysctl name (e.g., net.core.somaxconn)"), schema.Required)
+	sb.String("value", "dev.miren.compute/component.sandbox_spec.container.sysctl.value", schema.Doc("Value to set"), schema.Required)
+}
+
+const (
+	SandboxSpecContainerUlimitHardId = entity.Id("dev.miren.compute/component.sandbox_spec.container.ulimit.hard")
+	SandboxSpecContainerUlimitNameId = entity.Id("dev.miren.compute/component.sandbox_spec.container.ulimit.name")
+	SandboxSpecContainerUlimitSoftId = entity.Id("dev.miren.compute/component.sandbox_spec.container.ulimit.soft")
+)
+
+type SandboxSpecContainerUlimit struct {
+	Hard int64  `cbor:"hard,omitempty" json:"hard,omitempty"`
+	Name string `cbor:"name" json:"name"`
+	Soft int64  `cbor:"soft,omitempty" json:"soft,omitempty"`
+}
+
+func (o *SandboxSpecContainerUlimit) Decode(e entity.AttrGetter) {
+	if a, ok := e.Get(SandboxSpecContainerUlimitHardId); ok && a.Value.Kind() == entity.KindInt64 {
+		o.Hard = a.Value.Int64()
+	}
+	if a, ok := e.Get(SandboxSpecContainerUlimitNameId); ok && a.Value.Kind() == entity.KindString {
+		o.Name = a.Value.String()
+	}
+	if a, ok := e.Get(SandboxSpecContainerUlimitSoftId); ok && a.Value.Kind() == entity.KindInt64 {
+		o.Soft = a.Value.Int64()
+	}
+}
+
+func (o *SandboxSpecContainerUlimit) Encode() (attrs []entity.Attr) {
+	if !entity.Empty(o.Hard) {
+		attrs = append(attrs, entity.Int64(SandboxSpecContainerUlimitHardId, o.Hard))
+	}
+	if !entity.Empty(o.Name) {
+		attrs = append(attrs, entity.String(SandboxSpecContainerUlimitNameId, o.Name))
+	}
+	if !entity.Empty(o.Soft) {
+		attrs = append(attrs, entity.Int64(SandboxSpecContainerUlimitSoftId, o.Soft))
+	}
+	return
+}
+
+func (o *SandboxSpecContainerUlimit) Empty() bool {
+	if !entity.Empty(o.Hard) {
+		return false
+	}
+	if !entity.Empty(o.Name) {
+		return false
+	}
+	if !entity.Empty(o.Soft) {
+		return false
+	}
+	return true
+}
+
+func (o *SandboxSpecContainerUlimit) DeepCopy() *SandboxSpecContainerUlimit {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	return &out
+}
+
+func (o *SandboxSpecContainerUlimit) Validate() error {
+	if entity.Empty(o.Name) {
+		return fmt.Errorf("%w: %s", entity.ErrRequiredAttr, "name")
+	}
+	return nil
+}
+
+func (o *SandboxSpecContainerUlimit) InitSchema(sb *schema.SchemaBuilder) {
+	sb.Int64("hard", "dev.miren.compute/component.sandbox_spec.container.ulimit.hard", schema.Doc("Hard limit (0 uses the soft limit)"))
+	sb.String("name", "dev.miren.compute/component.sandbox_spec.container.ulimit.name", schema.Doc("Resource to limit (e.g., nofile)"), schema.Required)
+	sb.Int64("soft", "dev.miren.compute/component.sandbox_spec.container.ulimit.soft", schema.Doc("Soft limit"))
+}
+
 const (
 	SandboxSpecRouteDestinationId = entity.Id("dev.miren.compute/component.sandbox_spec.route.destination")
 	SandboxSpecRouteGatewayId     = entity.Id("dev.miren.compute/component.sandbox_spec.route.gateway")
@@ -550,6 +1092,18 @@ func (o *SandboxSpecRoute) Empty() bool {
 	return true
 }
 
+func (o *SandboxSpecRoute) DeepCopy() *SandboxSpecRoute {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	return &out
+}
+
+func (o *SandboxSpecRoute) Validate() error {
+	return nil
+}
+
 func (o *SandboxSpecRoute) InitSchema(sb *schema.SchemaBuilder) {
 	sb.String("destination", "dev.miren.compute/component.sandbox_spec.route.destination", schema.Doc("Network destination"))
 	sb.String("gateway", "dev.miren.compute/component.sandbox_spec.route.gateway", schema.Doc("Next hop for destination"))
@@ -594,6 +1148,18 @@ func (o *SandboxSpecStaticHost) Empty() bool {
 	return true
 }
 
+func (o *SandboxSpecStaticHost) DeepCopy() *SandboxSpecStaticHost {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	return &out
+}
+
+func (o *SandboxSpecStaticHost) Validate() error {
+	return nil
+}
+
 func (o *SandboxSpecStaticHost) InitSchema(sb *schema.SchemaBuilder) {
 	sb.String("host", "dev.miren.compute/component.sandbox_spec.static_host.host", schema.Doc("Hostname"))
 	sb.String("ip", "dev.miren.compute/component.sandbox_spec.static_host.ip", schema.Doc("IP address"))
@@ -715,6 +1281,19 @@ func (o *SandboxSpecVolume) Empty() bool {
 	return true
 }
 
+func (o *SandboxSpecVolume) DeepCopy() *SandboxSpecVolume {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	out.Labels = slices.Clone(o.Labels)
+	return &out
+}
+
+func (o *SandboxSpecVolume) Validate() error {
+	return nil
+}
+
 func (o *SandboxSpecVolume) InitSchema(sb *schema.SchemaBuilder) {
 	sb.String("disk_name", "dev.miren.compute/component.sandbox_spec.volume.disk_name", schema.Doc("Name of the disk to attach (for disk provider)"))
 	sb.String("filesystem", "dev.miren.compute/component.sandbox_spec.volume.filesystem", schema.Doc("Filesystem type for auto-creation (for disk provider)"))
@@ -783,6 +1362,14 @@ func (o *Lease) Encode() (attrs []entity.Attr) {
 	return
 }
 
+func (o *Lease) EncodeChanges(old entity.AttrGetter) (ops entity.AttrOps) {
+	desired := entity.New(o.Encode())
+	ops = append(ops, entity.DiffOne(old, desired, LeaseLastHeartbeatId)...)
+	ops = append(ops, entity.DiffOne(old, desired, LeaseProjectId)...)
+	ops = append(ops, entity.DiffOne(old, desired, LeaseSandboxId)...)
+	return
+}
+
 func (o *Lease) Empty() bool {
 	if !entity.Empty(o.LastHeartbeat) {
 		return false
@@ -796,15 +1383,36 @@ func (o *Lease) Empty() bool {
 	return true
 }
 
+func (o *Lease) DeepCopy() *Lease {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	return &out
+}
+
+func (o *Lease) Validate() error {
+	return nil
+}
+
 func (o *Lease) InitSchema(sb *schema.SchemaBuilder) {
 	sb.Time("last_heartbeat", "dev.miren.compute/lease.last_heartbeat", schema.Doc("The last time the lease was updated"))
 	sb.Ref("project", "dev.miren.compute/lease.project", schema.Doc("Which project currently holds the lease"), schema.Indexed)
 	sb.Ref("sandbox", "dev.miren.compute/lease.sandbox", schema.Doc("The sandbox that is leased"), schema.Indexed)
 }
 
+func FindLeasesByProject(ctx context.Context, store entity.IndexLister, project entity.Id) ([]entity.Id, error) {
+	return store.ListIndex(ctx, entity.Ref(LeaseProjectId, project))
+}
+
+func FindLeasesBySandbox(ctx context.Context, store entity.IndexLister, sandbox entity.Id) ([]entity.Id, error) {
+	return store.ListIndex(ctx, entity.Ref(LeaseSandboxId, sandbox))
+}
+
 const (
 	NodeApiAddressId      = entity.Id("dev.miren.compute/node.api_address")
 	NodeConstraintsId     = entity.Id("dev.miren.compute/node.constraints")
+	NodeRuntimeClassId    = entity.Id("dev.miren.compute/node.runtime_class")
 	NodeStatusId          = entity.Id("dev.miren.compute/node.status")
 	NodeStatusUnknownId   = entity.Id("dev.miren.compute/status.unknown")
 	NodeStatusReadyId     = entity.Id("dev.miren.compute/status.ready")
@@ -813,10 +1421,11 @@ const (
 )
 
 type Node struct {
-	ID          entity.Id    `json:"id"`
-	ApiAddress  string       `cbor:"api_address,omitempty" json:"api_address,omitempty"`
-	Constraints types.Labels `cbor:"constraints,omitempty" json:"constraints,omitempty"`
-	Status      NodeStatus   `cbor:"status,omitempty" json:"status,omitempty"`
+	ID           entity.Id    `json:"id"`
+	ApiAddress   string       `cbor:"api_address,omitempty" json:"api_address,omitempty"`
+	Constraints  types.Labels `cbor:"constraints,omitempty" json:"constraints,omitempty"`
+	RuntimeClass []string     `cbor:"runtime_class,omitempty" json:"runtime_class,omitempty"`
+	Status       NodeStatus   `cbor:"status,omitempty" json:"status,omitempty"`
 }
 
 type NodeStatus string
@@ -841,6 +1450,11 @@ func (o *Node) Decode(e entity.AttrGetter) {
 			o.Constraints = append(o.Constraints, a.Value.Label())
 		}
 	}
+	for _, a := range e.GetAll(NodeRuntimeClassId) {
+		if a.Value.Kind() == entity.KindString {
+			o.RuntimeClass = append(o.RuntimeClass, a.Value.String())
+		}
+	}
 	if a, ok := e.Get(NodeStatusId); ok && a.Value.Kind() == entity.KindId {
 		o.Status = nodestatusFromId[a.Value.Id()]
 	}
@@ -869,6 +1483,9 @@ func (o *Node) Encode() (attrs []entity.Attr) {
 	for _, v := range o.Constraints {
 		attrs = append(attrs, entity.Label(NodeConstraintsId, v.Key, v.Value))
 	}
+	for _, v := range o.RuntimeClass {
+		attrs = append(attrs, entity.String(NodeRuntimeClassId, v))
+	}
 	if a, ok := nodestatusToId[o.Status]; ok {
 		attrs = append(attrs, entity.Ref(NodeStatusId, a))
 	}
@@ -876,6 +1493,15 @@ func (o *Node) Encode() (attrs []entity.Attr) {
 	return
 }
 
+func (o *Node) EncodeChanges(old entity.AttrGetter) (ops entity.AttrOps) {
+	desired := entity.New(o.Encode())
+	ops = append(ops, entity.DiffOne(old, desired, NodeApiAddressId)...)
+	ops = append(ops, entity.DiffMany(old, desired, NodeConstraintsId)...)
+	ops = append(ops, entity.DiffMany(old, desired, NodeRuntimeClassId)...)
+	ops = append(ops, entity.DiffOne(old, desired, NodeStatusId)...)
+	return
+}
+
 func (o *Node) Empty() bool {
 	if !entity.Empty(o.ApiAddress) {
 		return false
@@ -883,20 +1509,51 @@ func (o *Node) Empty() bool {
 	if len(o.Constraints) != 0 {
 		return false
 	}
+	if len(o.RuntimeClass) != 0 {
+		return false
+	}
 	if o.Status != "" {
 		return false
 	}
 	return true
 }
 
+func (o *Node) DeepCopy() *Node {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	out.Constraints = slices.Clone(o.Constraints)
+	out.RuntimeClass = slices.Clone(o.RuntimeClass)
+	return &out
+}
+
+func (o *Node) Validate() error {
+	if o.Status != "" {
+		if _, ok := nodestatusToId[o.Status]; !ok {
+			return fmt.Errorf("%w: %s: %q", entity.ErrInvalidChoice, "status", o.Status)
+		}
+	}
+	return nil
+}
+
 func (o *Node) InitSchema(sb *schema.SchemaBuilder) {
 	sb.String("api_address", "dev.miren.compute/node.api_address", schema.Doc("The address to connect the node at"))
 	sb.Label("constraints", "dev.miren.compute/node.constraints", schema.Doc("The label constraints the node has, used for scheduling"), schema.Many)
+	sb.String("runtime_class", "dev.miren.compute/node.runtime_class", schema.Doc("The container runtime classes installed on the node"), schema.Many)
 	sb.Singleton("dev.miren.compute/status.unknown")
 	sb.Singleton("dev.miren.compute/status.ready")
 	sb.Singleton("dev.miren.compute/status.disabled")
 	sb.Singleton("dev.miren.compute/status.unhealthy")
-	sb.Ref("status", "dev.miren.compute/node.status", schema.Doc("The status of the node"), schema.Session, schema.Choices(NodeStatusUnknownId, NodeStatusReadyId, NodeStatusDisabledId, NodeStatusUnhealthyId))
+	sb.Ref("status", "dev.miren.compute/node.status", schema.Doc("The status of the node"), schema.Indexed, schema.Session, schema.Choices(NodeStatusUnknownId, NodeStatusReadyId, NodeStatusDisabledId, NodeStatusUnhealthyId))
+}
+
+func FindNodesByStatus(ctx context.Context, store entity.IndexLister, status NodeStatus) ([]entity.Id, error) {
+	id, ok := nodestatusToId[status]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s: %q", entity.ErrInvalidChoice, "status", status)
+	}
+	return store.ListIndex(ctx, entity.Ref(NodeStatusId, id))
 }
 
 const (
@@ -916,6 +1573,7 @@ const (
 	SandboxStatusRunningId  = entity.Id("dev.miren.compute/status.running")
 	SandboxStatusStoppedId  = entity.Id("dev.miren.compute/status.stopped")
 	SandboxStatusDeadId     = entity.Id("dev.miren.compute/status.dead")
+	SandboxTerminationId    = entity.Id("dev.miren.compute/sandbox.termination")
 	SandboxVolumeId         = entity.Id("dev.miren.compute/sandbox.volume")
 )
 
@@ -932,6 +1590,7 @@ type Sandbox struct {
 	Spec         SandboxSpec   `cbor:"spec,omitempty" json:"spec,omitempty"`
 	StaticHost   []StaticHost  `cbor:"static_host,omitempty" json:"static_host,omitempty"`
 	Status       SandboxStatus `cbor:"status,omitempty" json:"status,omitempty"`
+	Termination  Termination   `cbor:"termination,omitempty" json:"termination,omitempty"`
 	Volume       []Volume      `cbor:"volume,omitempty" json:"volume,omitempty"`
 }
 
@@ -1003,6 +1662,9 @@ func (o *Sandbox) Decode(e entity.AttrGetter) {
 	if a, ok := e.Get(SandboxStatusId); ok && a.Value.Kind() == entity.KindId {
 		o.Status = sandboxstatusFromId[a.Value.Id()]
 	}
+	if a, ok := e.Get(SandboxTerminationId); ok && a.Value.Kind() == entity.KindComponent {
+		o.Termination.Decode(a.Value.Component())
+	}
 	for _, a := range e.GetAll(SandboxVolumeId) {
 		if a.Value.Kind() == entity.KindComponent {
 			var v Volume
@@ -1060,6 +1722,9 @@ func (o *Sandbox) Encode() (attrs []entity.Attr) {
 	if a, ok := sandboxstatusToId[o.Status]; ok {
 		attrs = append(attrs, entity.Ref(SandboxStatusId, a))
 	}
+	if !o.Termination.Empty() {
+		attrs = append(attrs, entity.Component(SandboxTerminationId, o.Termination.Encode()))
+	}
 	for _, v := range o.Volume {
 		attrs = append(attrs, entity.Component(SandboxVolumeId, v.Encode()))
 	}
@@ -1067,6 +1732,24 @@ func (o *Sandbox) Encode() (attrs []entity.Attr) {
 	return
 }
 
+func (o *Sandbox) EncodeChanges(old entity.AttrGetter) (ops entity.AttrOps) {
+	desired := entity.New(o.Encode())
+	ops = append(ops, entity.DiffMany(old, desired, SandboxContainerId)...)
+	ops = append(ops, entity.DiffOne(old, desired, SandboxHostNetworkId)...)
+	ops = append(ops, entity.DiffMany(old, desired, SandboxLabelsId)...)
+	ops = append(ops, entity.DiffOne(old, desired, SandboxLastActivityId)...)
+	ops = append(ops, entity.DiffMany(old, desired, SandboxLogAttributeId)...)
+	ops = append(ops, entity.DiffOne(old, desired, SandboxLogEntityId)...)
+	ops = append(ops, entity.DiffMany(old, desired, SandboxNetworkId)...)
+	ops = append(ops, entity.DiffMany(old, desired, SandboxRouteId)...)
+	ops = append(ops, entity.DiffOne(old, desired, SandboxSpecId)...)
+	ops = append(ops, entity.DiffMany(old, desired, SandboxStaticHostId)...)
+	ops = append(ops, entity.DiffOne(old, desired, SandboxStatusId)...)
+	ops = append(ops, entity.DiffOne(old, desired, SandboxTerminationId)...)
+	ops = append(ops, entity.DiffMany(old, desired, SandboxVolumeId)...)
+	return
+}
+
 func (o *Sandbox) Empty() bool {
 	if len(o.Container) != 0 {
 		return false
@@ -1101,12 +1784,104 @@ func (o *Sandbox) Empty() bool {
 	if o.Status != "" {
 		return false
 	}
+	if !o.Termination.Empty() {
+		return false
+	}
 	if len(o.Volume) != 0 {
 		return false
 	}
 	return true
 }
 
+func (o *Sandbox) DeepCopy() *Sandbox {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	if o.Container != nil {
+		out.Container = make([]Container, len(o.Container))
+		for i := range o.Container {
+			out.Container[i] = *o.Container[i].DeepCopy()
+		}
+	}
+	out.Labels = slices.Clone(o.Labels)
+	out.LogAttribute = slices.Clone(o.LogAttribute)
+	if o.Network != nil {
+		out.Network = make([]Network, len(o.Network))
+		for i := range o.Network {
+			out.Network[i] = *o.Network[i].DeepCopy()
+		}
+	}
+	if o.Route != nil {
+		out.Route = make([]Route, len(o.Route))
+		for i := range o.Route {
+			out.Route[i] = *o.Route[i].DeepCopy()
+		}
+	}
+	out.Spec = *o.Spec.DeepCopy()
+	if o.StaticHost != nil {
+		out.StaticHost = make([]StaticHost, len(o.StaticHost))
+		for i := range o.StaticHost {
+			out.StaticHost[i] = *o.StaticHost[i].DeepCopy()
+		}
+	}
+	out.Termination = *o.Termination.DeepCopy()
+	if o.Volume != nil {
+		out.Volume = make([]Volume, len(o.Volume))
+		for i := range o.Volume {
+			out.Volume[i] = *o.Volume[i].DeepCopy()
+		}
+	}
+	return &out
+}
+
+func (o *Sandbox) Validate() error {
+	if len(o.Container) == 0 {
+		return fmt.Errorf("%w: %s", entity.ErrRequiredAttr, "container")
+	}
+	for i, v := range o.Container {
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("%s[%d]: %w", "container", i, err)
+		}
+	}
+	for i, v := range o.Network {
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("%s[%d]: %w", "network", i, err)
+		}
+	}
+	for i, v := range o.Route {
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("%s[%d]: %w", "route", i, err)
+		}
+	}
+	if !o.Spec.Empty() {
+		if err := o.Spec.Validate(); err != nil {
+			return fmt.Errorf("%s: %w", "spec", err)
+		}
+	}
+	for i, v := range o.StaticHost {
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("%s[%d]: %w", "static_host", i, err)
+		}
+	}
+	if o.Status != "" {
+		if _, ok := sandboxstatusToId[o.Status]; !ok {
+			return fmt.Errorf("%w: %s: %q", entity.ErrInvalidChoice, "status", o.Status)
+		}
+	}
+	if !o.Termination.Empty() {
+		if err := o.Termination.Validate(); err != nil {
+			return fmt.Errorf("%s: %w", "termination", err)
+		}
+	}
+	for i, v := range o.Volume {
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("%s[%d]: %w", "volume", i, err)
+		}
+	}
+	return nil
+}
+
 func (o *Sandbox) InitSchema(sb *schema.SchemaBuilder) {
 	sb.Component("container", "dev.miren.compute/sandbox.container", schema.Doc("A container running in the sandbox"), schema.Many, schema.Required)
 	(&Container{}).InitSchema(sb.Builder("sandbox.container"))
@@ -1128,6 +1903,8 @@ func (o *Sandbox) InitSchema(sb *schema.SchemaBuilder) {
 	sb.Singleton("dev.miren.compute/status.stopped")
 	sb.Singleton("dev.miren.compute/status.dead")
 	sb.Ref("status", "dev.miren.compute/sandbox.status", schema.Doc("The status of the pod"), schema.Choices(SandboxStatusPendingId, SandboxStatusNotReadyId, SandboxStatusRunningId, SandboxStatusStoppedId, SandboxStatusDeadId))
+	sb.Component("termination", "dev.miren.compute/sandbox.termination", schema.Doc("How the sandbox's containers were stopped, recorded when it is retired"))
+	(&Termination{}).InitSchema(sb.Builder("sandbox.termination"))
 	sb.Component("volume", "dev.miren.compute/sandbox.volume", schema.Doc("A volume that is available for binding into containers"), schema.Many)
 	(&Volume{}).InitSchema(sb.Builder("sandbox.volume"))
 }
@@ -1289,6 +2066,55 @@ func (o *Container) Empty() bool {
 	return true
 }
 
+func (o *Container) DeepCopy() *Container {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	if o.ConfigFile != nil {
+		out.ConfigFile = make([]ConfigFile, len(o.ConfigFile))
+		for i := range o.ConfigFile {
+			out.ConfigFile[i] = *o.ConfigFile[i].DeepCopy()
+		}
+	}
+	out.Env = slices.Clone(o.Env)
+	if o.Mount != nil {
+		out.Mount = make([]Mount, len(o.Mount))
+		for i := range o.Mount {
+			out.Mount[i] = *o.Mount[i].DeepCopy()
+		}
+	}
+	if o.Port != nil {
+		out.Port = make([]Port, len(o.Port))
+		for i := range o.Port {
+			out.Port[i] = *o.Port[i].DeepCopy()
+		}
+	}
+	return &out
+}
+
+func (o *Container) Validate() error {
+	for i, v := range o.ConfigFile {
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("%s[%d]: %w", "config_file", i, err)
+		}
+	}
+	if entity.Empty(o.Image) {
+		return fmt.Errorf("%w: %s", entity.ErrRequiredAttr, "image")
+	}
+	for i, v := range o.Mount {
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("%s[%d]: %w", "mount", i, err)
+		}
+	}
+	for i, v := range o.Port {
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("%s[%d]: %w", "port", i, err)
+		}
+	}
+	return nil
+}
+
 func (o *Container) InitSchema(sb *schema.SchemaBuilder) {
 	sb.String("command", "dev.miren.compute/container.command", schema.Doc("Command to run in the container"))
 	sb.Component("config_file", "dev.miren.compute/container.config_file", schema.Doc("A file to write into the container before starting"), schema.Many)
@@ -1357,6 +2183,18 @@ func (o *ConfigFile) Empty() bool {
 	return true
 }
 
+func (o *ConfigFile) DeepCopy() *ConfigFile {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	return &out
+}
+
+func (o *ConfigFile) Validate() error {
+	return nil
+}
+
 func (o *ConfigFile) InitSchema(sb *schema.SchemaBuilder) {
 	sb.String("data", "dev.miren.compute/config_file.data", schema.Doc("The configuration data"))
 	sb.String("mode", "dev.miren.compute/config_file.mode", schema.Doc("The file mode to set the configuration to"))
@@ -1402,6 +2240,18 @@ func (o *Mount) Empty() bool {
 	return true
 }
 
+func (o *Mount) DeepCopy() *Mount {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	return &out
+}
+
+func (o *Mount) Validate() error {
+	return nil
+}
+
 func (o *Mount) InitSchema(sb *schema.SchemaBuilder) {
 	sb.String("destination", "dev.miren.compute/mount.destination", schema.Doc("Mount destination path"))
 	sb.String("source", "dev.miren.compute/mount.source", schema.Doc("Mount source path"))
@@ -1489,6 +2339,26 @@ func (o *Port) Empty() bool {
 	return true
 }
 
+func (o *Port) DeepCopy() *Port {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	return &out
+}
+
+func (o *Port) Validate() error {
+	if entity.Empty(o.Name) {
+		return fmt.Errorf("%w: %s", entity.ErrRequiredAttr, "name")
+	}
+	if o.Protocol != "" {
+		if _, ok := PortprotocolToId[o.Protocol]; !ok {
+			return fmt.Errorf("%w: %s: %q", entity.ErrInvalidChoice, "protocol", o.Protocol)
+		}
+	}
+	return nil
+}
+
 func (o *Port) InitSchema(sb *schema.SchemaBuilder) {
 	sb.String("name", "dev.miren.compute/port.name", schema.Doc("Name of the port for reference"), schema.Required)
 	sb.Int64("node_port", "dev.miren.compute/port.node_port", schema.Doc("The port number that should be forwarded from the node to the container"))
@@ -1538,6 +2408,18 @@ func (o *Network) Empty() bool {
 	return true
 }
 
+func (o *Network) DeepCopy() *Network {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	return &out
+}
+
+func (o *Network) Validate() error {
+	return nil
+}
+
 func (o *Network) InitSchema(sb *schema.SchemaBuilder) {
 	sb.String("address", "dev.miren.compute/network.address", schema.Doc("A network address to reach the container at"))
 	sb.String("subnet", "dev.miren.compute/network.subnet", schema.Doc("The subnet that the address is associated with"))
@@ -1582,6 +2464,18 @@ func (o *Route) Empty() bool {
 	return true
 }
 
+func (o *Route) DeepCopy() *Route {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	return &out
+}
+
+func (o *Route) Validate() error {
+	return nil
+}
+
 func (o *Route) InitSchema(sb *schema.SchemaBuilder) {
 	sb.String("destination", "dev.miren.compute/route.destination", schema.Doc("The network destination"))
 	sb.String("gateway", "dev.miren.compute/route.gateway", schema.Doc("The next hop for the destination"))
@@ -1626,11 +2520,89 @@ func (o *StaticHost) Empty() bool {
 	return true
 }
 
+func (o *StaticHost) DeepCopy() *StaticHost {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	return &out
+}
+
+func (o *StaticHost) Validate() error {
+	return nil
+}
+
 func (o *StaticHost) InitSchema(sb *schema.SchemaBuilder) {
 	sb.String("host", "dev.miren.compute/static_host.host", schema.Doc("The hostname"))
 	sb.String("ip", "dev.miren.compute/static_host.ip", schema.Doc("The IP"))
 }
 
+const (
+	TerminationDurationId       = entity.Id("dev.miren.compute/termination.duration")
+	TerminationForceKilledId    = entity.Id("dev.miren.compute/termination.force_killed")
+	TerminationIgnoredSigtermId = entity.Id("dev.miren.compute/termination.ignored_sigterm")
+)
+
+type Termination struct {
+	Duration       time.Duration `cbor:"duration,omitempty" json:"duration,omitempty"`
+	ForceKilled    bool          `cbor:"force_killed,omitempty" json:"force_killed,omitempty"`
+	IgnoredSigterm int64         `cbor:"ignored_sigterm,omitempty" json:"ignored_sigterm,omitempty"`
+}
+
+func (o *Termination) Decode(e entity.AttrGetter) {
+	if a, ok := e.Get(TerminationDurationId); ok && a.Value.Kind() == entity.KindDuration {
+		o.Duration = a.Value.Duration()
+	}
+	if a, ok := e.Get(TerminationForceKilledId); ok && a.Value.Kind() == entity.KindBool {
+		o.ForceKilled = a.Value.Bool()
+	}
+	if a, ok := e.Get(TerminationIgnoredSigtermId); ok && a.Value.Kind() == entity.KindInt64 {
+		o.IgnoredSigterm = a.Value.Int64()
+	}
+}
+
+func (o *Termination) Encode() (attrs []entity.Attr) {
+	if !entity.Empty(o.Duration) {
+		attrs = append(attrs, entity.Duration(TerminationDurationId, o.Duration))
+	}
+	attrs = append(attrs, entity.Bool(TerminationForceKilledId, o.ForceKilled))
+	if !entity.Empty(o.IgnoredSigterm) {
+		attrs = append(attrs, entity.Int64(TerminationIgnoredSigtermId, o.IgnoredSigterm))
+	}
+	return
+}
+
+func (o *Termination) Empty() bool {
+	if !entity.Empty(o.Duration) {
+		return false
+	}
+	if !entity.Empty(o.ForceKilled) {
+		return false
+	}
+	if !entity.Empty(o.IgnoredSigterm) {
+		return false
+	}
+	return true
+}
+
+func (o *Termination) DeepCopy() *Termination {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	return &out
+}
+
+func (o *Termination) Validate() error {
+	return nil
+}
+
+func (o *Termination) InitSchema(sb *schema.SchemaBuilder) {
+	sb.Duration("duration", "dev.miren.compute/termination.duration", schema.Doc("How long the containers took to stop"))
+	sb.Bool("force_killed", "dev.miren.compute/termination.force_killed", schema.Doc("Whether any container had to be killed after its termination grace expired"))
+	sb.Int64("ignored_sigterm", "dev.miren.compute/termination.ignored_sigterm", schema.Doc("Number of containers that did not exit on SIGTERM within their grace"))
+}
+
 const (
 	VolumeLabelsId   = entity.Id("dev.miren.compute/volume.labels")
 	VolumeNameId     = entity.Id("dev.miren.compute/volume.name")
@@ -1683,6 +2655,19 @@ func (o *Volume) Empty() bool {
 	return true
 }
 
+func (o *Volume) DeepCopy() *Volume {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	out.Labels = slices.Clone(o.Labels)
+	return &out
+}
+
+func (o *Volume) Validate() error {
+	return nil
+}
+
 func (o *Volume) InitSchema(sb *schema.SchemaBuilder) {
 	sb.Label("labels", "dev.miren.compute/volume.labels", schema.Doc("Labels that identify the volume to the provider"), schema.Many)
 	sb.String("name", "dev.miren.compute/volume.name", schema.Doc("The name of the volume"))
@@ -1702,22 +2687,26 @@ const (
 	SandboxPoolSandboxPrefixId         = entity.Id("dev.miren.compute/sandbox_pool.sandbox_prefix")
 	SandboxPoolSandboxSpecId           = entity.Id("dev.miren.compute/sandbox_pool.sandbox_spec")
 	SandboxPoolServiceId               = entity.Id("dev.miren.compute/sandbox_pool.service")
+	SandboxPoolWarmIdleTtlId           = entity.Id("dev.miren.compute/sandbox_pool.warm_idle_ttl")
+	SandboxPoolWarmInstancesId         = entity.Id("dev.miren.compute/sandbox_pool.warm_instances")
 )
 
 type SandboxPool struct {
-	ID                    entity.Id    `json:"id"`
-	App                   entity.Id    `cbor:"app,omitempty" json:"app,omitempty"`
-	ConsecutiveCrashCount int64        `cbor:"consecutive_crash_count,omitempty" json:"consecutive_crash_count,omitempty"`
-	CooldownUntil         time.Time    `cbor:"cooldown_until,omitempty" json:"cooldown_until,omitempty"`
-	CurrentInstances      int64        `cbor:"current_instances,omitempty" json:"current_instances,omitempty"`
-	DesiredInstances      int64        `cbor:"desired_instances,omitempty" json:"desired_instances,omitempty"`
-	LastCrashTime         time.Time    `cbor:"last_crash_time,omitempty" json:"last_crash_time,omitempty"`
-	ReadyInstances        int64        `cbor:"ready_instances,omitempty" json:"ready_instances,omitempty"`
-	ReferencedByVersions  []entity.Id  `cbor:"referenced_by_versions,omitempty" json:"referenced_by_versions,omitempty"`
-	SandboxLabels         types.Labels `cbor:"sandbox_labels,omitempty" json:"sandbox_labels,omitempty"`
-	SandboxPrefix         string       `cbor:"sandbox_prefix,omitempty" json:"sandbox_prefix,omitempty"`
-	SandboxSpec           SandboxSpec  `cbor:"sandbox_spec,omitempty" json:"sandbox_spec,omitempty"`
-	Service               string       `cbor:"service,omitempty" json:"service,omitempty"`
+	ID                    entity.Id     `json:"id"`
+	App                   entity.Id     `cbor:"app,omitempty" json:"app,omitempty"`
+	ConsecutiveCrashCount int64         `cbor:"consecutive_crash_count,omitempty" json:"consecutive_crash_count,omitempty"`
+	CooldownUntil         time.Time     `cbor:"cooldown_until,omitempty" json:"cooldown_until,omitempty"`
+	CurrentInstances      int64         `cbor:"current_instances,omitempty" json:"current_instances,omitempty"`
+	DesiredInstances      int64         `cbor:"desired_instances,omitempty" json:"desired_instances,omitempty"`
+	LastCrashTime         time.Time     `cbor:"last_crash_time,omitempty" json:"last_crash_time,omitempty"`
+	ReadyInstances        int64         `cbor:"ready_instances,omitempty" json:"ready_instances,omitempty"`
+	ReferencedByVersions  []entity.Id   `cbor:"referenced_by_versions,omitempty" json:"referenced_by_versions,omitempty"`
+	SandboxLabels         types.Labels  `cbor:"sandbox_labels,omitempty" json:"sandbox_labels,omitempty"`
+	SandboxPrefix         string        `cbor:"sandbox_prefix,omitempty" json:"sandbox_prefix,omitempty"`
+	SandboxSpec           SandboxSpec   `cbor:"sandbox_spec,omitempty" json:"sandbox_spec,omitempty"`
+	Service               string        `cbor:"service,omitempty" json:"service,omitempty"`
+	WarmIdleTtl           time.Duration `cbor:"warm_idle_ttl,omitempty" json:"warm_idle_ttl,omitempty"`
+	WarmInstances         int64         `cbor:"warm_instances,omitempty" json:"warm_instances,omitempty"`
 }
 
 func (o *SandboxPool) Decode(e entity.AttrGetter) {
@@ -1762,6 +2751,12 @@ func (o *SandboxPool) Decode(e entity.AttrGetter) {
 	if a, ok := e.Get(SandboxPoolServiceId); ok && a.Value.Kind() == entity.KindString {
 		o.Service = a.Value.String()
 	}
+	if a, ok := e.Get(SandboxPoolWarmIdleTtlId); ok && a.Value.Kind() == entity.KindDuration {
+		o.WarmIdleTtl = a.Value.Duration()
+	}
+	if a, ok := e.Get(SandboxPoolWarmInstancesId); ok && a.Value.Kind() == entity.KindInt64 {
+		o.WarmInstances = a.Value.Int64()
+	}
 }
 
 func (o *SandboxPool) Is(e entity.AttrGetter) bool {
@@ -1817,10 +2812,35 @@ func (o *SandboxPool) Encode() (attrs []entity.Attr) {
 	if !entity.Empty(o.Service) {
 		attrs = append(attrs, entity.String(SandboxPoolServiceId, o.Service))
 	}
+	if !entity.Empty(o.WarmIdleTtl) {
+		attrs = append(attrs, entity.Duration(SandboxPoolWarmIdleTtlId, o.WarmIdleTtl))
+	}
+	if !entity.Empty(o.WarmInstances) {
+		attrs = append(attrs, entity.Int64(SandboxPoolWarmInstancesId, o.WarmInstances))
+	}
 	attrs = append(attrs, entity.Ref(entity.EntityKind, KindSandboxPool))
 	return
 }
 
+func (o *SandboxPool) EncodeChanges(old entity.AttrGetter) (ops entity.AttrOps) {
+	desired := entity.New(o.Encode())
+	ops = append(ops, entity.DiffOne(old, desired, SandboxPoolAppId)...)
+	ops = append(ops, entity.DiffOne(old, desired, SandboxPoolConsecutiveCrashCountId)...)
+	ops = append(ops, entity.DiffOne(old, desired, SandboxPoolCooldownUntilId)...)
+	ops = append(ops, entity.DiffOne(old, desired, SandboxPoolCurrentInstancesId)...)
+	ops = append(ops, entity.DiffOne(old, desired, SandboxPoolDesiredInstancesId)...)
+	ops = append(ops, entity.DiffOne(old, desired, SandboxPoolLastCrashTimeId)...)
+	ops = append(ops, entity.DiffOne(old, desired, SandboxPoolReadyInstancesId)...)
+	ops = append(ops, entity.DiffMany(old, desired, SandboxPoolReferencedByVersionsId)...)
+	ops = append(ops, entity.DiffMany(old, desired, SandboxPoolSandboxLabelsId)...)
+	ops = append(ops, entity.DiffOne(old, desired, SandboxPoolSandboxPrefixId)...)
+	ops = append(ops, entity.DiffOne(old, desired, SandboxPoolSandboxSpecId)...)
+	ops = append(ops, entity.DiffOne(old, desired, SandboxPoolServiceId)...)
+	ops = append(ops, entity.DiffOne(old, desired, SandboxPoolWarmIdleTtlId)...)
+	ops = append(ops, entity.DiffOne(old, desired, SandboxPoolWarmInstancesId)...)
+	return
+}
+
 func (o *SandboxPool) Empty() bool {
 	if !entity.Empty(o.App) {
 		return false
@@ -1858,9 +2878,35 @@ func (o *SandboxPool) Empty() bool {
 	if !entity.Empty(o.Service) {
 		return false
 	}
+	if !entity.Empty(o.WarmIdleTtl) {
+		return false
+	}
+	if !entity.Empty(o.WarmInstances) {
+		return false
+	}
 	return true
 }
 
+func (o *SandboxPool) DeepCopy() *SandboxPool {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	out.ReferencedByVersions = slices.Clone(o.ReferencedByVersions)
+	out.SandboxLabels = slices.Clone(o.SandboxLabels)
+	out.SandboxSpec = *o.SandboxSpec.DeepCopy()
+	return &out
+}
+
+func (o *SandboxPool) Validate() error {
+	if !o.SandboxSpec.Empty() {
+		if err := o.SandboxSpec.Validate(); err != nil {
+			return fmt.Errorf("%s: %w", "sandbox_spec", err)
+		}
+	}
+	return nil
+}
+
 func (o *SandboxPool) InitSchema(sb *schema.SchemaBuilder) {
 	sb.Ref("app", "dev.miren.compute/sandbox_pool.app", schema.Doc("Reference to the app this pool belongs to"), schema.Indexed, schema.Tags("dev.miren.app_ref"))
 	sb.Int64("consecutive_crash_count", "dev.miren.compute/sandbox_pool.consecutive_crash_count", schema.Doc("Number of consecutive quick crashes (sandboxes that died within 60s of creation)"))
@@ -1874,6 +2920,20 @@ func (o *SandboxPool) InitSchema(sb *schema.SchemaBuilder) {
 	sb.String("sandbox_prefix", "dev.miren.compute/sandbox_pool.sandbox_prefix", schema.Doc("Prefix used when generating sandbox entity names (e.g., \"myapp-web\" produces \"myapp-web-abc123\")"))
 	sb.Component("sandbox_spec", "dev.miren.compute/sandbox_pool.sandbox_spec", schema.Doc("Complete sandbox specification template (includes version ref to AppVersion)"))
 	sb.String("service", "dev.miren.compute/sandbox_pool.service", schema.Doc("Service name (e.g., web, worker) - pool identifier"), schema.Indexed)
+	sb.Duration("warm_idle_ttl", "dev.miren.compute/sandbox_pool.warm_idle_ttl", schema.Doc("How long warm instances are kept after the pool last saw demand (0 keeps them indefinitely)"))
+	sb.Int64("warm_instances", "dev.miren.compute/sandbox_pool.warm_instances", schema.Doc("Number of idle pre-warmed instances kept running in addition to desired_instances"))
+}
+
+func FindSandboxPoolsByApp(ctx context.Context, store entity.IndexLister, app entity.Id) ([]entity.Id, error) {
+	return store.ListIndex(ctx, entity.Ref(SandboxPoolAppId, app))
+}
+
+func FindSandboxPoolsByReferencedByVersions(ctx context.Context, store entity.IndexLister, referencedByVersions entity.Id) ([]entity.Id, error) {
+	return store.ListIndex(ctx, entity.Ref(SandboxPoolReferencedByVersionsId, referencedByVersions))
+}
+
+func FindSandboxPoolsByService(ctx context.Context, store entity.IndexLister, service string) ([]entity.Id, error) {
+	return store.ListIndex(ctx, entity.String(SandboxPoolServiceId, service))
 }
 
 const (
@@ -1916,6 +2976,12 @@ func (o *Schedule) Encode() (attrs []entity.Attr) {
 	return
 }
 
+func (o *Schedule) EncodeChanges(old entity.AttrGetter) (ops entity.AttrOps) {
+	desired := entity.New(o.Encode())
+	ops = append(ops, entity.DiffOne(old, desired, ScheduleKeyId)...)
+	return
+}
+
 func (o *Schedule) Empty() bool {
 	if !o.Key.Empty() {
 		return false
@@ -1923,6 +2989,24 @@ func (o *Schedule) Empty() bool {
 	return true
 }
 
+func (o *Schedule) DeepCopy() *Schedule {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	out.Key = *o.Key.DeepCopy()
+	return &out
+}
+
+func (o *Schedule) Validate() error {
+	if !o.Key.Empty() {
+		if err := o.Key.Validate(); err != nil {
+			return fmt.Errorf("%s: %w", "key", err)
+		}
+	}
+	return nil
+}
+
 func (o *Schedule) InitSchema(sb *schema.SchemaBuilder) {
 	sb.Component("key", "dev.miren.compute/schedule.key", schema.Doc("The scheduling key for an entity"), schema.Indexed)
 	(&Key{}).InitSchema(sb.Builder("schedule.key"))
@@ -1967,6 +3051,18 @@ func (o *Key) Empty() bool {
 	return true
 }
 
+func (o *Key) DeepCopy() *Key {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	return &out
+}
+
+func (o *Key) Validate() error {
+	return nil
+}
+
 func (o *Key) InitSchema(sb *schema.SchemaBuilder) {
 	sb.Ref("kind", "dev.miren.compute/key.kind", schema.Doc("The type of entity this is"))
 	sb.Ref("node", "dev.miren.compute/key.node", schema.Doc("The node id the entity is scheduled for"))
@@ -1990,5 +3086,5 @@ func init() {
 		(&SandboxPool{}).InitSchema(sb)
 		(&Schedule{}).InitSchema(sb)
 	})
-	schema.RegisterEncodedSchema("dev.miren.compute", "v1alpha", []byte("\x1f\x8b\b\x00\x00\x00\x00\x00\x00\xff\xec\\[\xaf\xec\xa4\x17\xff\x1a\xff\xf3W\x8f\xb7x\x8b\xb1[\x8d\xf7x;Q_\xfd\n\rS\xd6t\xd8\xd3B\x0f\xd0\xd93\xbe\xa91\xd1\xc4O\xe1\xd9\xdbo\xa8Ϧ@[\xdaBK\x99\xf3ؗ\x1d\xa0\xac\x1f\xac\v\v\x16\x8b=\xf7\x98\xa2\x12\x9eb8%%\xe1@\x93\x8c\x95U-\x01\x8e\x84b\xf1p~a\xf2\xe5\xa6\xf9\x92P\x86\xe1oE{\x9a\xf6h>j\x80\x7f\xf7\x98\x95\x88\xd0\xe9\x00\xfb=\x81\x02\x8b_\x9f\xed\b>\xbf\xe6\xc6HPER\x841\a!\xd4XG\xbbA^*\xd8\v\xc9\t\xcd\xef\xe7@2F\x85\xe4\x88P)p\x89\xe8\xe5\x1f\re77PP\xa0\x1d\x14\n\xe9%\x0f\x92\x90H\xd6z&{Sn(1к<6\x7f\xd2\x13*j\x10\xf7\xc0\x01\xe1\xcb\xf9\xf1\x14G\x93%\xea{^\xd3#ew\xf4\xfc\x8a\xb7\x9f\xe9q\xc0D\xa0]\x01\xf8\xfc\xaa\xb7kۅ\xd4\xf4\x00\xa8\x90\x87\x8bK\"\x1d\xae铟\x80\v\xc2h~\xfa\x00\x15\xd5\x01\x15\x15'%◴Q\x1fn\xb8>\xbf\xe81\x81\x02\x9006p7\xed\xa2\xbe\xae2\x827= I\x81\x84L\x0f\x80\xb8\xdc\x01\x92j@:jSj\x90\xa4\x04\x85\xf4\xb2\x0f\xa9\xe2\xec\x162\r\x91\xb7\x95\x86vG\xf0<\xa5@\x14\xef\xd8YS\xb6\x15C9+CP\xf4.SPB4PZ\x8c\xe7G\x0e\x85\xe9\x0e\x81\x92\xfc\xf3\xa1\xe1\xe2u/L\xb3\x18$\"\x14\xb8\xb5\x14H\xdf\xd8pD\x1a\x1aF\x81ʾd\xe67\x05N&\xc0\xa13}\xe6\x99i\aԴ\x94\xa8\xb1\xc2F\xe6m\xc5Z\xf5\x8a\u05f7\xe6\x11\xe8\x9e\xe4\xe9\x9e\x140Z\xfa]\xf3\x02\xc77\x01\x1c\xdb\xc3\\\xeb\xf6,\xa8\x04#\x89\xd4,\xb0*Y\x9c\x87P\x97\f\x83\xa6V\xa5\x95\xd4\x15\x92\aM\xadJ\x16\xf5\xac\xb5\xdfj\x8c\x06B\x8d\xf2Ɯv0\xe1\x90I\xc6/\xda\n\xfb\xeaس;Ve\x8f\x02\xf4d\xe96k\xaac^\x1dN\xb3\xa7'%ʵ\xa0@\x17\xc7\x166K]\xb2\x9aJk|\xd0\r\vV\xf5N\x88U)\xa4@{\xfaŷ\x9a\x14H\x82AHB\x91$\x8c\xea\x15`7\x8c\xa5\xe5pU\x1aE\xb0\x9ag`\xb6?]\x0e\xb5\v-\x16\x05\xef\xd8\xecz\x9e\x95\xc5\xf5\x7f\xc6S\x9b5'\xc6\xcaTd\x8ckZ\xd2W\x1b\x94\x8cP\xf9\xb08|Ÿ\xadL\xac\xea\v\xba|;D\x97\rP\xa0*\x7fS\x9c:\xce]\rƒ\x80\x1c\xdci2\x86!U\xdc(\xd9\xf4\xd5V6\xb3\x83v\x84x@\xe3[\x9b\x9a\x863\xc92V(\xbaCWs\x9e\x97\xfe\xcadV\xb9\xec\xae%KdVe5\x9e\xefS\xe3j\x96\v5t'\xb5`\xd3U<\xfb\x0e(\x96\x8699\x91\x02r\xd0\xfbխUW\xc3\xed\x18+\x96\x9d\x91\x90\x98\xe8%\n\xba8\xa4\x9du\x84RjG\x9a5\x85\x8en\x96\xb7~\xe7\xf7-\xaf֔\x0fL\xc8\x1fA\xde1~\xd4\x1e\xc4n\xe8\x06\xbb\xf7\xd8`\x8b\xa2\x8e\xd8\xf6)|oZ\xc6v\xec\xd8\xd5{\f!S\x94Ir\"\x86\xe1r\xd8ԝ\x05\xef=J\xeb\x90X\xfeDJNv\xb5\xb4\x8f\aŠ\xbd\x0f\r|.ւ\xfb\x81\xcavR\xa4\xaf\x06l(-\x065\x12\xedg\x93SK\xc83nh\n\x9a\x8c@Wm%\x8e9\x1a\x98\xc4\x0e\xc8rG0\xe6sD-\xbd\xa8w\x14\xa4\xd9Ft9t-\xb6\xc2x\xf0,\x86\x96cΆ*\x05ݰ \xc2)`2\x00\xbcv/V \xeb\xf6b\a\x8f\x1a%G\x12\xee\x906\xb5\xbc\xad\x04\xef\xc6\n\xe3\u07b3ٷ<\x8b\n2\xed/Ui\xf56x\xd3uiŘ6@\x81R\xfc]\xe9\xf8\xc3P\xd4+\x03\x9b\xe98\xc9\xd28\xab\xe2\x9c/\xd6\xf3\x11\x16\xfe|\x1d\x05|mT4\x1duQ\\\xd1A\xd2w\xd7q\xb8\x14E]\v\xbf\x10f]\v\x1f\x19\x87\x9d\x1f\x1b\xec\x06\xbaC\x1e\x05g_F\xcc-8f\xfb$\x02< \x94\xfb,\x02v1\u008b\x01\x8d\v\xfc\xa6#-/\x9c\xf5q\xe0\xf7\xb1\xfc\xacۜ\xbe\x89\x1e\xe6\x8aH\xf2\xfc\xc8e\xd9}x\xf9iĤ\x16\x82\xaa\x98u\x12\x16\x8c\xc6L6&F\x9d\x8e\xb3lv\xabC\xd6\x181\x85ĴO\xa2q\x83\x82\xde\xe8i\xcfE\xc5\xdfF\x83\xae\x0e\x9bc\x16\xfb`\xa8.\xb8\xbe\x1e\xa9\r\xc1\xa3e\x1a\x19\xa3\x9f\xff\xe7r\n]\xe0\xfeU\xcctB\xe3\xf9\x98\xcdc!̏\xd9;#\xa2\x7f\xe9\x12\x9a\x9a\xc0G\xc1\x13Xq/\xf0q0hL`\x1e\x1e)\x04\xc7\xe9I0d\\\xbc9\xc5\xf7y\xe5\xf5\xe1gx\xc0\x11\x11\x95\x86\xdb\xe7s\bV+\xcbJ\x15\xdc\xc3:\v\x15\x12I\x92\xa5\x8d]\xdaюݼ\xa0\xa7\xe9X>=Y\xa0\xab\xb4\xf5y\f7j\xedig\xd9qak)\xfcpa\x83\x92JA\xeeH\x15\xac\xa1\xdaҐ\x86j\x90\xd4\x1c\xde\x0f\x9e\x83\x19@\xdbH[1\tV\xa5\xf1i&\xce\vŊ\xba\xb4\x97\xe3\u07b4\xac\xcf\xf5͎\x10\xa8\xe2?V\xaaX\x83'\x98\x88cڝ\x8aH_\x1d\xeb9|\xa9\x1b\xe4&\x00\x14\x17!\xa1\xd4[\x9bU\x8f\x8f\xe2\f\xf6\xec\r\xaf\xe5\xae÷\xe4\x16\x18\x90\x80T\x92\x12X-͵\xef\xa0\xe9j\xb1\xa8\x18\"\xed\x02\xee[\xab>\xc6\x0e\xf7>\x06{\xe1\x88\x1b~\x860x\x15g'\x82\x81w\xc7D]\x1b\xe3\xae6:\x0e\b\xa7\x8c\x16fo\xec\xab\xc3\x03J\xb8k1\xb8\x82\xfc\x04i\xbe3\xcf'L\xa5=1\xcf:\x97\xa7\x96s\xd1`\xb3\xdd\v{\U00107164\xc6\xf5[\xc3\x14<q\x80\xaf\xda\v<\x8fv\x02\x9d\xbe\xe7)\xd1\x15\xde\xfd\xb6w\xe9K\xe9\x9d\xd0\xf7Q\x0f\x18\x03r>\xb3j\xdf2\x01\xc2y\x05\x14\x13\x9aϼ\x8e2=r^S:\xdf\xd3\xf4ȅdU\x05^1\xd5\"1=\be2կ\xb8\xfc\xaf\xa8\xba>\xbe\xccr+\x98\xd8\x1di\n\x99\f!CoR}\xa9ڕ^ۡ\xb10\a\xe7H'\x05{\xb2Y\xeb\xdc\a\xf8\x84\xf6\xbd\x96K\x00\xfa\x11Vv\x00\\\x17\xe61\xdb\xf9\xff\x0eE\x9a\x1e\x81\xf2\xfeٛ[18\xc9\x11L\xb8\xd6\x14\x16\xac`\x8a\x93\xd88\xab\xbc\x8b\x83\xb7#\\\x12\xfdگќ*\xd9O\xe2<\x14\xb4\xbb\xf7\xa6\xed\xbd\xf7\xd2S\xb8\x86\xd7\xd9\x0e\x87\x96-W\xf6\xcc~.\x97V\x8c\x15^\xe9\xdcؽV\xa5i\\K\xdd\xc2JP\xa5\xfdg\xd6\x14l!9\xceI\x03\u008cQ\x01Y-\xc9\tҌ#qH3u]\xac\x1eO\xfa>\x0e\xae\xa9\xde[\x1c\x81\x15\x98\xddѴ\xa6\x92\xe8\xeb#:j\x1b>\x92t\x1c͇\x805\xe7@eJ\xa8\x90\x88f\xa0\xfd\xfa\xd3i\xf3`\x9aK\xa8\x18\x04\xe1\x80Ǩ\xd3\xe6\x01\xaa#\x12\x1f\xa0\xaa\a\x00Zt\r\x7f\n\x93\x8d\x1b\x87\xec/A*\xbf>\x9a&\x1b7\x0e\xee\xfc\x1c\xf7\x1b#\xc4=p\xa0\x19\xe0twI\xcd:\xb0\x9d\xee\xc9\xd3\xc3\x18\xda}\x88\x19\xb4\x95\x89G\xa7\xa3/#\xcf\x1e\x8a[qؓ\xf3\x10Ѵ\x8dc\x87w\x03!\xbb<\xf3\xe0\xec\xb6囷|\xf3\x96o\xde\xf2\xcd[\xbey\xcb7o\xf9\xe6E\xc8-\u07fc囷|\xf3\x96o\xde\xf2\xcd[\xbey\xcb7o\xf9\xe6-\u07fc0\x87-\u07fc囷|\xf3\x96o~\x9e\xf9f\xdf\x7f\t\x0e\xaf=\x81\x9f\x88\tF\xf3\xb6\x12\xea\xfa\n\x1bj\xdc\xf3(\x0e\x8c\xcbT\xffr\x87\xfe톹\x9f\xef0\xbfL0\xfb\xf3\x0e]\xeal\xe1\xf7\v\xfa\xcc\xcdR\x8em\xc0AP\x9e\xe7?\x00\x00\x00\xff\xff\x01\x00\x00\xff\xff%\xe0\xc5c\xa3D\x00\x00"))
+	schema.RegisterEncodedSchema("dev.miren.compute", "v1alpha", []byte("\x1f\x8b\b\x00\x00\x00\x00\x00\x00\xff\xec\\ݲ\xe46\xf1\x7f\x8d\x7f\xf2O6\xbb\t\x10>\xbd\t\xb5d7T>\b\tw\x14\xaf\xe0\xd2X\x1a\x8f\xceؒW\x92\xe7\xccpG\x16.\xa8\n\\\xf0\b\x9c3\xbc!\\S\xfa\xb2\xe5\x0fɲ\x06\x8a\x1bߜ\x92z\xd4?u\xb7Zj\xa9\xa7\xcf<B\x02j\xf4\x1a\xa2SVc\x86HVкi\x05BGL \xbf\x9e\xff\x7f\xf2\xc9s\xf9IF(D\xffP\xbc\xa7\xe9\b\xf9\xa1\x06\xf8\xd7\x1e\xd2\x1a`2\x9d`\xbfǨ\x82\xfc\x8f\x0f;\f\xcf\xef\xcfcd\xa0\xc19\x80\x90!\xce\xd5\\G\x97 .\r\xdas\xc10)\x1fC \x05%\\0\x80\x89\xe0\xb0\x06\xe4\xf2O\r\xe5\x92%\x14\xaa\xc0\x0eU\n\xe9{\x1e$\xd6\x12\x81k\x94\x17\x15\xe0.V=\xfc`,ػ\x1e8.\x80h\xb5b{Ӗ\xac\x10\x91\xb6>\xca?\xf9\tT-⏈!\x00/\xe7'S\x1c͖\xa9\xcf˖\x1c\t\xbd'\xe7\xa7\xdeqf\xc4\x01b\x0ev\x15\x82\xe7gޡv\bn\xc9\x01\x81J\x1c.\xe7\xf7\xbd\x83\xbb1\xe5\t1\x8e))O\x1f\x83\xaa9\x80\xaaa\xb8\x06\xec\x92Ko\x80R\xeb\xf3;S\x14\xf9aV!\xc0\x8dK\xddO\x87\xa8O#}\xea\x8d\xf2\xa9\x1fx@\xb2\np\x91\x1f\x10`b\x87\x80P\x13\x92\x11M-\x83\\j\x85\xf4\x9e\x0f\xa9a\xf4\x0e\x15\x1a\xa2\xb4\x1dɻ\xc30\xcc\xc9\x01\x81;z֜\xb6c8\x836D\x8a\x7f\xce\x15\xa4m,\xae6\xe3\xf9\xad\xe9(3 Ғ\x7f\xb9J->\xf0\xc2d\x05%\x02`\x82\x98\xb3\x1bpO\x94\x1aa\xc9C\t\"\xa2o\x19\xf9\xa6\xc0\xd9\x048R\xd2\xef\x1e<\x92v@\x92\xa1\x06\xd2\v\xa5\xcdm\xc7٫J\xd7\x0f\xc3\bd\x8f\xcb|\x8f+\xe4\xe8{t\xc9\v\x1a?\x8f\xd0؝&R\xfb7\xbeSԁ\xca \x10@\t\fU\xcb\xd1<\x86\xbb\xa6\x10in\xd5Z\xc9\xdd\x00q\xd0ܪ\xe5p\a\xbd\xfdNK !\x94\x8c\xdf\x0f\xad\x0e\xc4\f\x15\x82\xb2\x8b\x9a\b\xf7]g\xb6GϮ\xecQ\x1099k[Ȯï\xa4x\x16\xe2\xc75(\xb5\xa1\x90n:\xdc\xd7E\ue6b6D8\xf3#MX\xf0\xaa\x1f\xc5x\x95B\x8a\xf4\xa7o}\xbbI\x81d\x10q\x81\t\x10\x98\x12%\xe5\xd1%\x8c\xad5sTi\x14N[V \x13\xfet;\xd6/\xb4Y\x94\x90OC\xe6\x94ذ\xff3\x16-\xe8N\x94\xd69/(Ӽ\xb8\xefJ\x94\x02\x13q]\x9c\xbe\xa1\xcc]L\xa8\xfa\vk\xf9Ø\xb5\x94@\x91K\xf9'e\xa5\x99k\x9c\xc4X2Ќv\x9a\x8dB\x94˖\xe2\xc5}\xd7\xda&8i\xc7\xd8\x1bD\xf2\xf8\xf6\xa6\x1c\x945\x8c\nZ\xd0J\xf1\x1d\xba\xde\xec}\xe9\xef\x85(\x9a9\xbf\xb3l\x99(\x9a\xa2\x85\xe11-l\x82Z\xa8\xa9;\xabE\xbb\xae\xd2\xd9wAqV\x98\xe1\x13\xaeP\x89t\xbc\xbas\xfar&\xb8\xa3\xb4Z>\x8c\xb8\x80XoQ\xa4\x9bC\xde\xe0A(\x84>H\v\xd9\xe8\xf8\x82\xba\xf5\x91߷\xbd\xac+\x1f(\x17\xbfC➲\xa3\x9a\xe4\xe8\x12\xba\xc9\x1e=>hQԍݽ\x88\xef\re\xec\xc7\x1f\x860\xb8\xc8A!\xf0\t\x1b\x85\xeb!\xa9\xbb\v>z\x16\xadC\xa2\xe5WB0\xbck\x85{=\xa8\x06\xf4\xfe\xa5\xe1;b\x1d\xb8\xdf\x10a\x85\xc2}7\"\xa0X\fb,\xdaKSZ\xd2\xc21\xf4\xcc\x7f\f\x19\x84U\xa1dFF\x03\x93\xb9\xef\xbbr\xe6m\xe7;\x88,?ow\x04\t\x13Ft;v/Zc\\=\x9b\xc1j\xcc\xe8pI\x91&,\x98\xf0=\xbf\t\x15\xff\xad\xb1X\x81\xac\x8b\xc53:j\x94\x12\bt\x0f\xb4\xab\x95\xb6\x13kFm\x8eGO\xb0\xb7:\xf3\x06\x15\n\x1f\xaa\xd6\xea0\xf8\xbc\x1bb͘K\xa0H+~\xa7\xd6\xf8籨}\xacMz\xd8L\xe7ɖ\xe6\x89\xd4\xe3oJ\x8f\xcf\xd6\xeb!\xf7\x19%\xf9\xf0z\x8b{\xe2\x82F\x9f\xadר\x9f1R7\xfd\x8a\xf9\xf5-\xba\xe9\x96Z-\xa4\x9b\xe3-\xf0\xab\x9b\xe0\x8f\xc8\x04\xc5#\x1a\xec\x0f%\xf9W7A\xfb\xafb\xc1\xcdw~\xc7 K\xe0\x0eW\xe9\x8e\xc8I\xc9\xf5u\x8a\\M\x03XMY\xde0*\x9f_J\xb2fB\x1d\x9b\xe0\x97\tSE\xbd˿H\x02\xee\x1e\xa0\x89\xcf\xf5/\x12\xbcށ_\xe5\xf7)\xab\xb4\xe2y\x7f+\xfc\xc2\xfb\xffV\xf8\xc4\x04\xc1\xf9ɜ\xf7\x8f\xb2\x06)\xa7et2\xe1\x93\x04\xf0\x88\x1cë\x04\xd8\xc5\xd4C\nhZF\xe2U\xc2\xc6Y\x93\xa0С\xe2\x9bT}\xd6ݚR\"\x92R&\x93\xaf\x9a\xbcsm\xdcw\xc7S|\x99<\xc5\rY\x94\xf3[s\x9b\xa7O\xad\xbcL\x10\xca\x1fŒ\xb7b\\\"&Eؔ\xfc\xcc\xcb\x04\xcf^\x9d\xaeI1SL>'冲\"\xe1\x93,v(#\x94raKL\x19}s\xebT]b\xe9v$\x9b~J\xb6ib~\xea\xfc\x7fs\x87B\x97\xb4\xfa<E\x9c\xd8\\V\xcaA\xcb孷n\x06\xf7T:&\x8e\xf7AJ \xf4\xe7\xd2Tl\xfd4\x05\xf2\xc2\vQ9'\xd0\xdeP\x16ΠO\x13\xce \x8d\x1cy\n}\xfb\x90z\xe7\xd6\xd3,\x9dC_\xa6#\xab\xaf\xc4\x15*\xd2\xcdh\xc7~{α5j\xf2mB V\x9bkC^2`\x02\xf1\xeb)YJy\x80-S\xb4\x87ԋ\xe3|N6\xd9\xff\xda\n\xd7؍\x80{C\xf9/\xf8\x9fF\x8e\xf4\xbf7\xc9\xfe\xa7\xa7\xc9\x0e\x80\xe9\xc3\x06\xaa\xd6 J\xdd\x00\xbb\xe0\xd67 s\xba7\x11P\xb5\xac\xc0\t\x1e\xad\x01\x83\x8cb\x8eOi\xf0\"Z\x83\x15\x19\xfc_D\x83\x0eR\xe5\x91)\xf4\xf8\x9c^LF}\xa5\x15\x1a\x86P\xdd\b\xbc3\x91\xe7\xe8\x12:+(ЏW\x80bʬ\x98\x87\xaeg}Bm\xf7,\x1a--y\x9dEo\xf1\xf5\xb9\xec\xf8$QB\x8a;\xfeX\xfd\x0fd\xbe\x1b\x03(\r\xad\xe0\x1e\xd6\xf9\xbb\xa9\x1b\xfbZՓI\t\xaa\x01\xc5\x11\xe3\xba\xce/e]\x16.r\xb9I\x9d\xa5?\xba\xe4\x05\ax\x11\xed\x00\x0e\xe8*7\x88\x8fX\xce\f\xeaImNv\xab\x851\xd1\xca7\xab\v\x8a\x1b\x05\xb9\xc3M\xf4ҷ\x06Lbi()\xd9j\x19X[\x88\x96!\xf8[Z\xea\xaf\xc0Ȉ6<D>\x8a\x866\xb2+L\xab\x88\xad0S\xce\xf4<\x1e\x8aVm\xed\x1e!{CY_\xec\x14\x9c!\xd2{\xfe\xbc\xd2{\xb4\xb0\x19\xc4\xfc\x98w\xb1\x1b\xf7\xdd\xf4\x1c\xb6A\x96\xef\x0e~\xe1\x02\xd5\n\xfa\xce\xe9\xa7g\v\rv\xf0+n'\n~\xbe\x1aX\xd6\x11\xe6\xb2Ƒ\xb6\xc2|\xef= \xddl\x16\x95H\xea\xb3_wN\x7f\x8c\xfdb-\xf6\xc2E\xec\xd5Z\xbc\x86\xd1\x13\x86\x88u\xb9\x02\xdd\x1b\xe3\xaev:Y\x98\x9bSR\x99+G\xdf\x1dn\xeb\x97kq9\xfe=\xca˝\xa9\x1f5\x9d\xa8K\xe3k\x03'\xb7\x86\x06\v\x0e\xaf\xdcٯ\vU\x1dΑ\x9a\x18u\xa6\xe0\xd9\f\xf8\xaa03S\x99\xb8\"\x9e<\rs'\x04\x8e\xbb>Z,շ\xc4\x16\x88_!D`\xb6\xce\xdc\x16s#\x00\xcb\x06\x11\x88I9;\xa1\x1efF\x94\xac%$<Ҍ(\xb9\xa0M\x83\xbcfjyfF`BE.\xdd?TFލy\\p4\xe7}\xad\xacst\t\xe9.\xe6\xa0\xdcZj\xee@e\xf6ٯD\xed\x92\x003)\x81\x1f\x87q\xf6\x94\x15(?\xe2\xaa2\x99\xb4j@\x19\x1e)?\vc\xe1\x92Ȍz\xceq)\xc9\n\x8e\x8e\x89Q\a\x8ak{_I\xa45\xb0\xb97\xac\xbeI<\xf5\xaf٪\xab\xc3\x1b_\x8d\xe1\xcah\xfb\xae\x17a!0=\xf32.G\xa0\xe0*\x18C\x06\xc7\xd8\x7f4\x983\x80t\xf7\x8c\x17\a\x04\xdb\xca\xfc\x17\xc6\xf9\xed\xe90;\"\xd2\xde\x7f\xf0\x16\x05\x19\x9ciAE\xc0\v\xa68\x9d\xc4\x12'R&\xfd\x06\x9d\xd1\xed\x88.\x99\x84P\xcaC\xd52\xf7\xe5\x10\a\xe9\xbe\x17'\xf6{\xf1\xa5\xffᐺ\x06\a\x1c\xacZ\xe7\x0f\xc2\xff\xe7\x917\x94V^\xeb\xd8m\xa7FEZ\xe7\xafޘ\xe9`\xc9\xea\x105i!\x1b\xae\x91>\t\v!\xf3\x81\x1c\x15\xad\xc0'\x94\x17\f\xf0C^\xc8[\xa1\x02\xbb\xf7}h\x8f!߹6\x9a\x81V\x90ޓ\\>\xa4+\x05LF\xb4\xe1\x7f\xf7|\xb4\x04\xd82\x86\x88\xc81\xe1\x02\x90\x02\xe9g\xda\xeb)y \xe6\x12*D\x1cˣv\x84:%\x0fP\xb3\x05TU\xb9\xaaM'o\xf6JR:&\x0e\xd5_\x82T\xf1x$&\x1d\x13\xad\x90\xbet\xdf\bq\x8f\x18\"\x05\x82\xf9\ue49b}\xe0\x1e\xba'\xcf\b\xe3h\x8f1n`;\x93\x13\x9d\x8c>\x19\x9d챸\rC{|\x1e\"\x1a\x9asd+Q\x7f\x12\t\xd9\x15H\x0e\xee\xdc[\xa1\xe4V(\xb9\x15Jn\x85\x92[\xa1\xe4V(\xb9\x15Jn\x85\x92[\xa1\xe4V(\xb9\x15Jn\x85\x92[\xa1\xe4V(\xb9\x15Jn\x85\x92[\xa1\xe4V(\xb9\x15Jn\x85\x92[\xa1\xe4V(\xb9\x15Jn\x85\x92[\xa1\xe4V(\xb9\x15Jn\x85\x92[\xa1\xe4\xff\xb0P\xd2W\x15f\xc7\xe8\xef}\x11;a\xf3\x10*mglȟ.\x80\xdc\x03V\xe7\x18V(\x17B'p\xea!i\xfa\x9eZ\xfa\x8a[\xf3\x0f\xbe\xf0'#Z\x94\x01;\x8b43\xbf\x1bv\xe4\aʄ\x1aǯ\xfa\x87aC?5l~\xf64\xf8۱]yӓp\xd1L_]\xb3T\a5\xd0 \xaa\x16\xe7\xdf\x00\x00\x00\xff\xff\x03\x00F\x9dR\x88OY\x00\x00"))
 }
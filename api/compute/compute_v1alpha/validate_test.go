@@ -0,0 +1,84 @@
+package compute_v1alpha
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"miren.dev/runtime/pkg/entity"
+)
+
+func TestValidate(t *testing.T) {
+	t.Run("container image is required", func(t *testing.T) {
+		r := require.New(t)
+
+		var co Container
+		err := co.Validate()
+		r.ErrorIs(err, entity.ErrRequiredAttr)
+		r.ErrorContains(err, "image")
+
+		co.Image = "nginx:latest"
+		r.NoError(co.Validate())
+	})
+
+	t.Run("sandbox containers are validated", func(t *testing.T) {
+		r := require.New(t)
+
+		sb := Sandbox{
+			Container: []Container{
+				{Name: "app", Image: "app:v1"},
+				{Name: "sidecar"},
+			},
+		}
+
+		err := sb.Validate()
+		r.ErrorIs(err, entity.ErrRequiredAttr)
+		r.EqualError(err, "container[1]: required attribute missing: image")
+
+		sb.Container[1].Image = "sidecar:v1"
+		r.NoError(sb.Validate())
+
+		r.ErrorIs((&Sandbox{}).Validate(), entity.ErrRequiredAttr, "a sandbox needs a container")
+	})
+
+	t.Run("port protocol must be a choice", func(t *testing.T) {
+		r := require.New(t)
+
+		port := SandboxSpecContainerPort{Name: "http", Port: 80}
+		r.NoError(port.Validate(), "protocol is optional")
+
+		port.Protocol = SandboxSpecContainerPortTCP
+		r.NoError(port.Validate())
+
+		port.Protocol = SandboxSpecContainerPortUDP
+		r.NoError(port.Validate())
+
+		port.Protocol = "sctp"
+		err := port.Validate()
+		r.ErrorIs(err, entity.ErrInvalidChoice)
+		r.ErrorContains(err, `"sctp"`)
+	})
+
+	t.Run("port protocol is validated through the pool's spec", func(t *testing.T) {
+		r := require.New(t)
+
+		pool := SandboxPool{
+			SandboxSpec: SandboxSpec{
+				Container: []SandboxSpecContainer{
+					{
+						Image: "app:v1",
+						Port: []SandboxSpecContainerPort{
+							{Name: "dns", Port: 53, Protocol: "icmp"},
+						},
+					},
+				},
+			},
+		}
+
+		err := pool.Validate()
+		r.ErrorIs(err, entity.ErrInvalidChoice)
+		r.ErrorContains(err, "sandbox_spec: container[0]: port[0]: ")
+
+		pool.SandboxSpec.Container[0].Port[0].Protocol = SandboxSpecContainerPortUDP
+		r.NoError(pool.Validate())
+	})
+}
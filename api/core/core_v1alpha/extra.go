@@ -2,6 +2,7 @@ package core_v1alpha
 
 import (
 	"fmt"
+	"strings"
 
 	entity "miren.dev/runtime/pkg/entity"
 )
@@ -23,3 +24,23 @@ func GetServiceConcurrency(ver *AppVersion, serviceName string) (ServiceConcurre
 	}
 	return ServiceConcurrency{}, fmt.Errorf("service %q not found in version config (services should be hydrated with defaults)", serviceName)
 }
+
+// AddonSourcePrefix starts the source of an env var whose value an addon
+// instance provides, written addon:<instance>/<key>.
+const AddonSourcePrefix = "addon:"
+
+// AddonSource returns the addon instance and key that source names, and
+// whether it names one at all.
+func AddonSource(source string) (addon, key string, ok bool) {
+	ref, ok := strings.CutPrefix(source, AddonSourcePrefix)
+	if !ok {
+		return "", "", false
+	}
+
+	addon, key, ok = strings.Cut(ref, "/")
+	if !ok || addon == "" || key == "" {
+		return "", "", false
+	}
+
+	return addon, key, true
+}
@@ -1,11 +1,199 @@
 package core_v1alpha
 
 import (
+	"context"
+	"fmt"
+	"slices"
+
 	entity "miren.dev/runtime/pkg/entity"
 	schema "miren.dev/runtime/pkg/entity/schema"
 	types "miren.dev/runtime/pkg/entity/types"
 )
 
+const (
+	AddonInstanceAddonId         = entity.Id("dev.miren.core/addon_instance.addon")
+	AddonInstanceAppId           = entity.Id("dev.miren.core/addon_instance.app")
+	AddonInstancePlanId          = entity.Id("dev.miren.core/addon_instance.plan")
+	AddonInstanceProvidedValueId = entity.Id("dev.miren.core/addon_instance.provided_value")
+)
+
+type AddonInstance struct {
+	ID            entity.Id       `json:"id"`
+	Addon         string          `cbor:"addon,omitempty" json:"addon,omitempty"`
+	App           entity.Id       `cbor:"app,omitempty" json:"app,omitempty"`
+	Plan          string          `cbor:"plan,omitempty" json:"plan,omitempty"`
+	ProvidedValue []ProvidedValue `cbor:"provided_value,omitempty" json:"provided_value,omitempty"`
+}
+
+func (o *AddonInstance) Decode(e entity.AttrGetter) {
+	o.ID = entity.MustGet(e, entity.DBId).Value.Id()
+	if a, ok := e.Get(AddonInstanceAddonId); ok && a.Value.Kind() == entity.KindString {
+		o.Addon = a.Value.String()
+	}
+	if a, ok := e.Get(AddonInstanceAppId); ok && a.Value.Kind() == entity.KindId {
+		o.App = a.Value.Id()
+	}
+	if a, ok := e.Get(AddonInstancePlanId); ok && a.Value.Kind() == entity.KindString {
+		o.Plan = a.Value.String()
+	}
+	for _, a := range e.GetAll(AddonInstanceProvidedValueId) {
+		if a.Value.Kind() == entity.KindComponent {
+			var v ProvidedValue
+			v.Decode(a.Value.Component())
+			o.ProvidedValue = append(o.ProvidedValue, v)
+		}
+	}
+}
+
+func (o *AddonInstance) Is(e entity.AttrGetter) bool {
+	return entity.Is(e, KindAddonInstance)
+}
+
+func (o *AddonInstance) ShortKind() string {
+	return "addon_instance"
+}
+
+func (o *AddonInstance) Kind() entity.Id {
+	return KindAddonInstance
+}
+
+func (o *AddonInstance) EntityId() entity.Id {
+	return o.ID
+}
+
+func (o *AddonInstance) Encode() (attrs []entity.Attr) {
+	if !entity.Empty(o.Addon) {
+		attrs = append(attrs, entity.String(AddonInstanceAddonId, o.Addon))
+	}
+	if !entity.Empty(o.App) {
+		attrs = append(attrs, entity.Ref(AddonInstanceAppId, o.App))
+	}
+	if !entity.Empty(o.Plan) {
+		attrs = append(attrs, entity.String(AddonInstancePlanId, o.Plan))
+	}
+	for _, v := range o.ProvidedValue {
+		attrs = append(attrs, entity.Component(AddonInstanceProvidedValueId, v.Encode()))
+	}
+	attrs = append(attrs, entity.Ref(entity.EntityKind, KindAddonInstance))
+	return
+}
+
+func (o *AddonInstance) EncodeChanges(old entity.AttrGetter) (ops entity.AttrOps) {
+	desired := entity.New(o.Encode())
+	ops = append(ops, entity.DiffOne(old, desired, AddonInstanceAddonId)...)
+	ops = append(ops, entity.DiffOne(old, desired, AddonInstanceAppId)...)
+	ops = append(ops, entity.DiffOne(old, desired, AddonInstancePlanId)...)
+	ops = append(ops, entity.DiffMany(old, desired, AddonInstanceProvidedValueId)...)
+	return
+}
+
+func (o *AddonInstance) Empty() bool {
+	if !entity.Empty(o.Addon) {
+		return false
+	}
+	if !entity.Empty(o.App) {
+		return false
+	}
+	if !entity.Empty(o.Plan) {
+		return false
+	}
+	if len(o.ProvidedValue) != 0 {
+		return false
+	}
+	return true
+}
+
+func (o *AddonInstance) DeepCopy() *AddonInstance {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	if o.ProvidedValue != nil {
+		out.ProvidedValue = make([]ProvidedValue, len(o.ProvidedValue))
+		for i := range o.ProvidedValue {
+			out.ProvidedValue[i] = *o.ProvidedValue[i].DeepCopy()
+		}
+	}
+	return &out
+}
+
+func (o *AddonInstance) Validate() error {
+	for i, v := range o.ProvidedValue {
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("%s[%d]: %w", "provided_value", i, err)
+		}
+	}
+	return nil
+}
+
+func (o *AddonInstance) InitSchema(sb *schema.SchemaBuilder) {
+	sb.String("addon", "dev.miren.core/addon_instance.addon", schema.Doc("The addon the instance was created from, such as postgresql"))
+	sb.Ref("app", "dev.miren.core/addon_instance.app", schema.Doc("The application the instance is attached to"), schema.Indexed, schema.Tags("dev.miren.app_ref"))
+	sb.String("plan", "dev.miren.core/addon_instance.plan", schema.Doc("The plan the instance was created with"))
+	sb.Component("provided_value", "dev.miren.core/addon_instance.provided_value", schema.Doc("A value the instance provides to the app's sandboxes, such as a connection URL"), schema.Many)
+	(&ProvidedValue{}).InitSchema(sb.Builder("addon_instance.provided_value"))
+}
+
+func FindAddonInstancesByApp(ctx context.Context, store entity.IndexLister, app entity.Id) ([]entity.Id, error) {
+	return store.ListIndex(ctx, entity.Ref(AddonInstanceAppId, app))
+}
+
+const (
+	ProvidedValueKeyId   = entity.Id("dev.miren.core/provided_value.key")
+	ProvidedValueValueId = entity.Id("dev.miren.core/provided_value.value")
+)
+
+type ProvidedValue struct {
+	Key   string `cbor:"key,omitempty" json:"key,omitempty"`
+	Value string `cbor:"value,omitempty" json:"value,omitempty"`
+}
+
+func (o *ProvidedValue) Decode(e entity.AttrGetter) {
+	if a, ok := e.Get(ProvidedValueKeyId); ok && a.Value.Kind() == entity.KindString {
+		o.Key = a.Value.String()
+	}
+	if a, ok := e.Get(ProvidedValueValueId); ok && a.Value.Kind() == entity.KindString {
+		o.Value = a.Value.String()
+	}
+}
+
+func (o *ProvidedValue) Encode() (attrs []entity.Attr) {
+	if !entity.Empty(o.Key) {
+		attrs = append(attrs, entity.String(ProvidedValueKeyId, o.Key))
+	}
+	if !entity.Empty(o.Value) {
+		attrs = append(attrs, entity.String(ProvidedValueValueId, o.Value))
+	}
+	return
+}
+
+func (o *ProvidedValue) Empty() bool {
+	if !entity.Empty(o.Key) {
+		return false
+	}
+	if !entity.Empty(o.Value) {
+		return false
+	}
+	return true
+}
+
+func (o *ProvidedValue) DeepCopy() *ProvidedValue {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	return &out
+}
+
+func (o *ProvidedValue) Validate() error {
+	return nil
+}
+
+func (o *ProvidedValue) InitSchema(sb *schema.SchemaBuilder) {
+	sb.String("key", "dev.miren.core/provided_value.key", schema.Doc("The name of the value"))
+	sb.String("value", "dev.miren.core/provided_value.value", schema.Doc("The value itself"))
+}
+
 const (
 	AppActiveVersionId = entity.Id("dev.miren.core/app.active_version")
 	AppProjectId       = entity.Id("dev.miren.core/app.project")
@@ -54,6 +242,13 @@ func (o *App) Encode() (attrs []entity.Attr) {
 	return
 }
 
+func (o *App) EncodeChanges(old entity.AttrGetter) (ops entity.AttrOps) {
+	desired := entity.New(o.Encode())
+	ops = append(ops, entity.DiffOne(old, desired, AppActiveVersionId)...)
+	ops = append(ops, entity.DiffOne(old, desired, AppProjectId)...)
+	return
+}
+
 func (o *App) Empty() bool {
 	if !entity.Empty(o.ActiveVersion) {
 		return false
@@ -64,6 +259,18 @@ func (o *App) Empty() bool {
 	return true
 }
 
+func (o *App) DeepCopy() *App {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	return &out
+}
+
+func (o *App) Validate() error {
+	return nil
+}
+
 func (o *App) InitSchema(sb *schema.SchemaBuilder) {
 	sb.Ref("active_version", "dev.miren.core/app.active_version", schema.Doc("The version of the project that should be used"))
 	sb.Ref("project", "dev.miren.core/app.project", schema.Doc("The project that the app belongs to"))
@@ -157,6 +364,18 @@ func (o *AppVersion) Encode() (attrs []entity.Attr) {
 	return
 }
 
+func (o *AppVersion) EncodeChanges(old entity.AttrGetter) (ops entity.AttrOps) {
+	desired := entity.New(o.Encode())
+	ops = append(ops, entity.DiffOne(old, desired, AppVersionAppId)...)
+	ops = append(ops, entity.DiffOne(old, desired, AppVersionArtifactId)...)
+	ops = append(ops, entity.DiffOne(old, desired, AppVersionConfigId)...)
+	ops = append(ops, entity.DiffOne(old, desired, AppVersionImageUrlId)...)
+	ops = append(ops, entity.DiffOne(old, desired, AppVersionManifestId)...)
+	ops = append(ops, entity.DiffOne(old, desired, AppVersionManifestDigestId)...)
+	ops = append(ops, entity.DiffOne(old, desired, AppVersionVersionId)...)
+	return
+}
+
 func (o *AppVersion) Empty() bool {
 	if !entity.Empty(o.App) {
 		return false
@@ -182,6 +401,24 @@ func (o *AppVersion) Empty() bool {
 	return true
 }
 
+func (o *AppVersion) DeepCopy() *AppVersion {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	out.Config = *o.Config.DeepCopy()
+	return &out
+}
+
+func (o *AppVersion) Validate() error {
+	if !o.Config.Empty() {
+		if err := o.Config.Validate(); err != nil {
+			return fmt.Errorf("%s: %w", "config", err)
+		}
+	}
+	return nil
+}
+
 func (o *AppVersion) InitSchema(sb *schema.SchemaBuilder) {
 	sb.Ref("app", "dev.miren.core/app_version.app", schema.Doc("The application the version is for"), schema.Indexed, schema.Tags("dev.miren.app_ref"))
 	sb.Ref("artifact", "dev.miren.core/app_version.artifact", schema.Doc("The artifact to deploy for the version"))
@@ -193,6 +430,14 @@ func (o *AppVersion) InitSchema(sb *schema.SchemaBuilder) {
 	sb.String("version", "dev.miren.core/app_version.version", schema.Doc("The version of this app"))
 }
 
+func FindAppVersionsByApp(ctx context.Context, store entity.IndexLister, app entity.Id) ([]entity.Id, error) {
+	return store.ListIndex(ctx, entity.Ref(AppVersionAppId, app))
+}
+
+func FindAppVersionsByManifestDigest(ctx context.Context, store entity.IndexLister, manifestDigest string) ([]entity.Id, error) {
+	return store.ListIndex(ctx, entity.String(AppVersionManifestDigestId, manifestDigest))
+}
+
 const (
 	ConfigCommandsId       = entity.Id("dev.miren.core/config.commands")
 	ConfigEntrypointId     = entity.Id("dev.miren.core/config.entrypoint")
@@ -288,6 +533,51 @@ func (o *Config) Empty() bool {
 	return true
 }
 
+func (o *Config) DeepCopy() *Config {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	if o.Commands != nil {
+		out.Commands = make([]Commands, len(o.Commands))
+		for i := range o.Commands {
+			out.Commands[i] = *o.Commands[i].DeepCopy()
+		}
+	}
+	if o.Services != nil {
+		out.Services = make([]Services, len(o.Services))
+		for i := range o.Services {
+			out.Services[i] = *o.Services[i].DeepCopy()
+		}
+	}
+	if o.Variable != nil {
+		out.Variable = make([]Variable, len(o.Variable))
+		for i := range o.Variable {
+			out.Variable[i] = *o.Variable[i].DeepCopy()
+		}
+	}
+	return &out
+}
+
+func (o *Config) Validate() error {
+	for i, v := range o.Commands {
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("%s[%d]: %w", "commands", i, err)
+		}
+	}
+	for i, v := range o.Services {
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("%s[%d]: %w", "services", i, err)
+		}
+	}
+	for i, v := range o.Variable {
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("%s[%d]: %w", "variable", i, err)
+		}
+	}
+	return nil
+}
+
 func (o *Config) InitSchema(sb *schema.SchemaBuilder) {
 	sb.Component("commands", "dev.miren.core/config.commands", schema.Doc("The command to run for a specific service type"), schema.Many)
 	(&Commands{}).InitSchema(sb.Builder("config.commands"))
@@ -339,6 +629,18 @@ func (o *Commands) Empty() bool {
 	return true
 }
 
+func (o *Commands) DeepCopy() *Commands {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	return &out
+}
+
+func (o *Commands) Validate() error {
+	return nil
+}
+
 func (o *Commands) InitSchema(sb *schema.SchemaBuilder) {
 	sb.String("command", "dev.miren.core/commands.command", schema.Doc("The command to run for the service"))
 	sb.String("service", "dev.miren.core/commands.service", schema.Doc("The service name"))
@@ -457,6 +759,46 @@ func (o *Services) Empty() bool {
 	return true
 }
 
+func (o *Services) DeepCopy() *Services {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	if o.Disks != nil {
+		out.Disks = make([]Disks, len(o.Disks))
+		for i := range o.Disks {
+			out.Disks[i] = *o.Disks[i].DeepCopy()
+		}
+	}
+	if o.Env != nil {
+		out.Env = make([]Env, len(o.Env))
+		for i := range o.Env {
+			out.Env[i] = *o.Env[i].DeepCopy()
+		}
+	}
+	out.ServiceConcurrency = *o.ServiceConcurrency.DeepCopy()
+	return &out
+}
+
+func (o *Services) Validate() error {
+	for i, v := range o.Disks {
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("%s[%d]: %w", "disks", i, err)
+		}
+	}
+	for i, v := range o.Env {
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("%s[%d]: %w", "env", i, err)
+		}
+	}
+	if !o.ServiceConcurrency.Empty() {
+		if err := o.ServiceConcurrency.Validate(); err != nil {
+			return fmt.Errorf("%s: %w", "service_concurrency", err)
+		}
+	}
+	return nil
+}
+
 func (o *Services) InitSchema(sb *schema.SchemaBuilder) {
 	sb.Component("disks", "dev.miren.core/services.disks", schema.Doc("Disk attachments for this service"), schema.Many)
 	(&Disks{}).InitSchema(sb.Builder("services.disks"))
@@ -552,6 +894,18 @@ func (o *Disks) Empty() bool {
 	return true
 }
 
+func (o *Disks) DeepCopy() *Disks {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	return &out
+}
+
+func (o *Disks) Validate() error {
+	return nil
+}
+
 func (o *Disks) InitSchema(sb *schema.SchemaBuilder) {
 	sb.String("filesystem", "dev.miren.core/disks.filesystem", schema.Doc("Filesystem type (ext4, xfs, btrfs) for auto-creating the disk"))
 	sb.String("lease_timeout", "dev.miren.core/disks.lease_timeout", schema.Doc("Timeout for acquiring the disk lease (e.g. 5m, 10m)"))
@@ -620,6 +974,18 @@ func (o *Env) Empty() bool {
 	return true
 }
 
+func (o *Env) DeepCopy() *Env {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	return &out
+}
+
+func (o *Env) Validate() error {
+	return nil
+}
+
 func (o *Env) InitSchema(sb *schema.SchemaBuilder) {
 	sb.String("key", "dev.miren.core/env.key", schema.Doc("The name of the variable"))
 	sb.Bool("sensitive", "dev.miren.core/env.sensitive", schema.Doc("Whether or not the value is sensitive"))
@@ -688,6 +1054,18 @@ func (o *ServiceConcurrency) Empty() bool {
 	return true
 }
 
+func (o *ServiceConcurrency) DeepCopy() *ServiceConcurrency {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	return &out
+}
+
+func (o *ServiceConcurrency) Validate() error {
+	return nil
+}
+
 func (o *ServiceConcurrency) InitSchema(sb *schema.SchemaBuilder) {
 	sb.String("mode", "dev.miren.core/service_concurrency.mode", schema.Doc("The concurrency mode (auto or fixed)"))
 	sb.Int64("num_instances", "dev.miren.core/service_concurrency.num_instances", schema.Doc("For fixed mode, number of instances to maintain"))
@@ -754,6 +1132,18 @@ func (o *Variable) Empty() bool {
 	return true
 }
 
+func (o *Variable) DeepCopy() *Variable {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	return &out
+}
+
+func (o *Variable) Validate() error {
+	return nil
+}
+
 func (o *Variable) InitSchema(sb *schema.SchemaBuilder) {
 	sb.String("key", "dev.miren.core/variable.key", schema.Doc("The name of the variable"))
 	sb.Bool("sensitive", "dev.miren.core/variable.sensitive", schema.Doc("Whether or not the value is sensitive"))
@@ -817,6 +1207,14 @@ func (o *Artifact) Encode() (attrs []entity.Attr) {
 	return
 }
 
+func (o *Artifact) EncodeChanges(old entity.AttrGetter) (ops entity.AttrOps) {
+	desired := entity.New(o.Encode())
+	ops = append(ops, entity.DiffOne(old, desired, ArtifactAppId)...)
+	ops = append(ops, entity.DiffOne(old, desired, ArtifactManifestId)...)
+	ops = append(ops, entity.DiffOne(old, desired, ArtifactManifestDigestId)...)
+	return
+}
+
 func (o *Artifact) Empty() bool {
 	if !entity.Empty(o.App) {
 		return false
@@ -830,12 +1228,32 @@ func (o *Artifact) Empty() bool {
 	return true
 }
 
+func (o *Artifact) DeepCopy() *Artifact {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	return &out
+}
+
+func (o *Artifact) Validate() error {
+	return nil
+}
+
 func (o *Artifact) InitSchema(sb *schema.SchemaBuilder) {
 	sb.Ref("app", "dev.miren.core/artifact.app", schema.Doc("The application the artifact is for"), schema.Indexed, schema.Tags("dev.miren.app_ref"))
 	sb.String("manifest", "dev.miren.core/artifact.manifest", schema.Doc("The OCI image manifest for the version"))
 	sb.String("manifest_digest", "dev.miren.core/artifact.manifest_digest", schema.Doc("The digest of the manifest"), schema.Indexed)
 }
 
+func FindArtifactsByApp(ctx context.Context, store entity.IndexLister, app entity.Id) ([]entity.Id, error) {
+	return store.ListIndex(ctx, entity.Ref(ArtifactAppId, app))
+}
+
+func FindArtifactsByManifestDigest(ctx context.Context, store entity.IndexLister, manifestDigest string) ([]entity.Id, error) {
+	return store.ListIndex(ctx, entity.String(ArtifactManifestDigestId, manifestDigest))
+}
+
 const (
 	DeploymentAppNameId      = entity.Id("dev.miren.core/deployment.app_name")
 	DeploymentAppVersionId   = entity.Id("dev.miren.core/deployment.app_version")
@@ -948,6 +1366,21 @@ func (o *Deployment) Encode() (attrs []entity.Attr) {
 	return
 }
 
+func (o *Deployment) EncodeChanges(old entity.AttrGetter) (ops entity.AttrOps) {
+	desired := entity.New(o.Encode())
+	ops = append(ops, entity.DiffOne(old, desired, DeploymentAppNameId)...)
+	ops = append(ops, entity.DiffOne(old, desired, DeploymentAppVersionId)...)
+	ops = append(ops, entity.DiffOne(old, desired, DeploymentBuildLogsId)...)
+	ops = append(ops, entity.DiffOne(old, desired, DeploymentClusterIdId)...)
+	ops = append(ops, entity.DiffOne(old, desired, DeploymentCompletedAtId)...)
+	ops = append(ops, entity.DiffOne(old, desired, DeploymentDeployedById)...)
+	ops = append(ops, entity.DiffOne(old, desired, DeploymentErrorMessageId)...)
+	ops = append(ops, entity.DiffOne(old, desired, DeploymentGitInfoId)...)
+	ops = append(ops, entity.DiffOne(old, desired, DeploymentPhaseId)...)
+	ops = append(ops, entity.DiffOne(old, desired, DeploymentStatusId)...)
+	return
+}
+
 func (o *Deployment) Empty() bool {
 	if !entity.Empty(o.AppName) {
 		return false
@@ -982,6 +1415,30 @@ func (o *Deployment) Empty() bool {
 	return true
 }
 
+func (o *Deployment) DeepCopy() *Deployment {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	out.DeployedBy = *o.DeployedBy.DeepCopy()
+	out.GitInfo = *o.GitInfo.DeepCopy()
+	return &out
+}
+
+func (o *Deployment) Validate() error {
+	if !o.DeployedBy.Empty() {
+		if err := o.DeployedBy.Validate(); err != nil {
+			return fmt.Errorf("%s: %w", "deployed_by", err)
+		}
+	}
+	if !o.GitInfo.Empty() {
+		if err := o.GitInfo.Validate(); err != nil {
+			return fmt.Errorf("%s: %w", "git_info", err)
+		}
+	}
+	return nil
+}
+
 func (o *Deployment) InitSchema(sb *schema.SchemaBuilder) {
 	sb.String("app_name", "dev.miren.core/deployment.app_name", schema.Doc("The name of the app being deployed"), schema.Indexed)
 	sb.String("app_version", "dev.miren.core/deployment.app_version", schema.Doc("The app version ID or temporary value (pending-build, failed-{id})"))
@@ -997,6 +1454,18 @@ func (o *Deployment) InitSchema(sb *schema.SchemaBuilder) {
 	sb.String("status", "dev.miren.core/deployment.status", schema.Doc("Deployment status (in_progress, active, failed, rolled_back)"), schema.Indexed)
 }
 
+func FindDeploymentsByAppName(ctx context.Context, store entity.IndexLister, appName string) ([]entity.Id, error) {
+	return store.ListIndex(ctx, entity.String(DeploymentAppNameId, appName))
+}
+
+func FindDeploymentsByClusterId(ctx context.Context, store entity.IndexLister, clusterId string) ([]entity.Id, error) {
+	return store.ListIndex(ctx, entity.String(DeploymentClusterIdId, clusterId))
+}
+
+func FindDeploymentsByStatus(ctx context.Context, store entity.IndexLister, status string) ([]entity.Id, error) {
+	return store.ListIndex(ctx, entity.String(DeploymentStatusId, status))
+}
+
 const (
 	DeployedByTimestampId = entity.Id("dev.miren.core/deployed_by.timestamp")
 	DeployedByUserEmailId = entity.Id("dev.miren.core/deployed_by.user_email")
@@ -1058,6 +1527,18 @@ func (o *DeployedBy) Empty() bool {
 	return true
 }
 
+func (o *DeployedBy) DeepCopy() *DeployedBy {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	return &out
+}
+
+func (o *DeployedBy) Validate() error {
+	return nil
+}
+
 func (o *DeployedBy) InitSchema(sb *schema.SchemaBuilder) {
 	sb.String("timestamp", "dev.miren.core/deployed_by.timestamp", schema.Doc("When the deployment was initiated (RFC3339 format)"))
 	sb.String("user_email", "dev.miren.core/deployed_by.user_email", schema.Doc("The email of the user who deployed"))
@@ -1179,6 +1660,18 @@ func (o *GitInfo) Empty() bool {
 	return true
 }
 
+func (o *GitInfo) DeepCopy() *GitInfo {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	return &out
+}
+
+func (o *GitInfo) Validate() error {
+	return nil
+}
+
 func (o *GitInfo) InitSchema(sb *schema.SchemaBuilder) {
 	sb.String("author", "dev.miren.core/git_info.author", schema.Doc("Git commit author"))
 	sb.String("branch", "dev.miren.core/git_info.branch", schema.Doc("Git branch name"))
@@ -1249,6 +1742,14 @@ func (o *Metadata) Encode() (attrs []entity.Attr) {
 	return
 }
 
+func (o *Metadata) EncodeChanges(old entity.AttrGetter) (ops entity.AttrOps) {
+	desired := entity.New(o.Encode())
+	ops = append(ops, entity.DiffMany(old, desired, MetadataLabelsId)...)
+	ops = append(ops, entity.DiffOne(old, desired, MetadataNameId)...)
+	ops = append(ops, entity.DiffOne(old, desired, MetadataProjectId)...)
+	return
+}
+
 func (o *Metadata) Empty() bool {
 	if len(o.Labels) != 0 {
 		return false
@@ -1262,6 +1763,19 @@ func (o *Metadata) Empty() bool {
 	return true
 }
 
+func (o *Metadata) DeepCopy() *Metadata {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	out.Labels = slices.Clone(o.Labels)
+	return &out
+}
+
+func (o *Metadata) Validate() error {
+	return nil
+}
+
 func (o *Metadata) InitSchema(sb *schema.SchemaBuilder) {
 	sb.Label("labels", "dev.miren.core/metadata.labels", schema.Doc("Identifying labels for the entity"), schema.Many)
 	sb.String("name", "dev.miren.core/metadata.name", schema.Doc("The name of the entity"))
@@ -1308,6 +1822,12 @@ func (o *Project) Encode() (attrs []entity.Attr) {
 	return
 }
 
+func (o *Project) EncodeChanges(old entity.AttrGetter) (ops entity.AttrOps) {
+	desired := entity.New(o.Encode())
+	ops = append(ops, entity.DiffOne(old, desired, ProjectOwnerId)...)
+	return
+}
+
 func (o *Project) Empty() bool {
 	if !entity.Empty(o.Owner) {
 		return false
@@ -1315,22 +1835,36 @@ func (o *Project) Empty() bool {
 	return true
 }
 
+func (o *Project) DeepCopy() *Project {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	return &out
+}
+
+func (o *Project) Validate() error {
+	return nil
+}
+
 func (o *Project) InitSchema(sb *schema.SchemaBuilder) {
 	sb.String("owner", "dev.miren.core/project.owner", schema.Doc("The email address of the project owner"))
 }
 
 var (
-	KindApp        = entity.Id("dev.miren.core/kind.app")
-	KindAppVersion = entity.Id("dev.miren.core/kind.app_version")
-	KindArtifact   = entity.Id("dev.miren.core/kind.artifact")
-	KindDeployment = entity.Id("dev.miren.core/kind.deployment")
-	KindMetadata   = entity.Id("dev.miren.core/kind.metadata")
-	KindProject    = entity.Id("dev.miren.core/kind.project")
-	Schema         = entity.Id("dev.miren.core/schema.v1alpha")
+	KindAddonInstance = entity.Id("dev.miren.core/kind.addon_instance")
+	KindApp           = entity.Id("dev.miren.core/kind.app")
+	KindAppVersion    = entity.Id("dev.miren.core/kind.app_version")
+	KindArtifact      = entity.Id("dev.miren.core/kind.artifact")
+	KindDeployment    = entity.Id("dev.miren.core/kind.deployment")
+	KindMetadata      = entity.Id("dev.miren.core/kind.metadata")
+	KindProject       = entity.Id("dev.miren.core/kind.project")
+	Schema            = entity.Id("dev.miren.core/schema.v1alpha")
 )
 
 func init() {
 	schema.Register("dev.miren.core", "v1alpha", func(sb *schema.SchemaBuilder) {
+		(&AddonInstance{}).InitSchema(sb)
 		(&App{}).InitSchema(sb)
 		(&AppVersion{}).InitSchema(sb)
 		(&Artifact{}).InitSchema(sb)
@@ -1338,5 +1872,5 @@ func init() {
 		(&Metadata{}).InitSchema(sb)
 		(&Project{}).InitSchema(sb)
 	})
-	schema.RegisterEncodedSchema("dev.miren.core", "v1alpha", []byte("\x1f\x8b\b\x00\x00\x00\x00\x00\x00\xff\xa4Yٲ\xdc&\x10\xfd\x8d싳;U\x8aSy\xc9S~Eň\x96\xc4\x1d\td@s=y\xcbR\xd9~\xc3\xd7\xfe\xc3\xe49\xc5*ԃ$\xae\xfd2E7s\x0e\xd0\xdd4\rz\xa0\x9c\x8c\xc0)\\\xaa\x91I\xe0U#$\xc0\x99q\xaa^߯\xb5\xdf\x19mE\xa6\xe9\xb5\xc5H\xd4K\xa6\xc9\xe1\xfek\xa9\x18\t㈴m\x19\fT\xfd\xfa\xf2\xc4\xe8\x8bOo\xc1\x15i4\xbb@}\x01\xa9\x98\xe0n^H\xa7\xaf\x13\x9c\x18\xb5\x14\xefe(&)\xee\xa0\xd1\x16\xdb\x05\xc1\x83:O\xd2]\xbe'\xc3ԓa\x92l$\xf2Z\x9bI7d\x9a^\xbc\x9f[\xafgqk\xbe\xa0\x7f\xf8Βu\xffb'\xfdA\x9e\xa0\x12\xf7\x1c\xa4\x1d\x02\\\xd3L\xbaUZ2\xde\xedN<\xac\xf2\x86\xd99Kj֒0{\xec\xcf\xd0[2\xfd\xdf\xec\xf4\xb1\x85\x02\x83\x89\n;\x84\xb1\xe3\xcaK\x9fl!F\xc2Y\v\xca\xf9\xaa\x8fR\xb2n\x8b\xff\xea\b_S\xd6\x05\x1a\x81\x95\xa5V\xec\x03mތ#hB\x89&y3\x86\xde\"3>\x98E}\xb4\xc1P\r\xe4\x04\x83\xa2#\xe1\xd7\x7f\xedX\xadט\x85\x80mg\xc3(\x12\x18\f]~\xb05?\xde½\xf1\xc6\xe9\x03\xc5͢\xac\xe5(L\x83\xb8\x8e\xc0}\b\xbex\x17\xfdk\xf9C\x89\xf9\xfe\xb1\xabx\xb2\xc9aⰎ\xcb\uf8c4\xed\xf0\xc5>C\x9a\x82Ω\x02\xf3|\xbe\xcds\x9a\xd9@\xebAt\xca\xd2\xdc%\xf2#X\x9aaV\x1adͨcId\xcc\xf2\xe5\x0e\x8b\x18\xa7\x014К8\x17\x0f+M\xc2\xf4p`\x1d\xd7\x04Z\x9f\xae\xce:\xa9\xc2\xf00\xc3,8p\xbd\xb4\xbc\xeb\x11m\x95\xa7-\t\x83\xdfw\xccfI*\xcdFP\x9a\x8c.+\xb1E,\x8b\x04G2+\x905\x8c\x84\r\xce\xf8\x89\x8ci\xf2!\x99\xd0x\avA(\x8b\x81\x84 F5[\xc4\xd2\xf4v\x17\xd8N\xd7lRM<\x01R\nY\x8f\xa0\x14\xe9\xdcx\xe3Z\x85\x83eg3vL\u05cc\xb7\xc2m\xc6(\x1d\x84ɓ\xed0\t\x14%1\xf2\xf7\xcb\\\xa6\r\f\x15\x99u/܉\xdb\xfa6v\xc9&\xf6$\toz\x87\xf5m\x8c\xfdv\vۈqd\xbavC&\xc1\xa5r\x1d\x98\xf5\xeb\x03\xd6u\xd4O7Ż\x0f\xe7\xc8\xc7TM\x99\xd4n\x8f\xf7Q2xz\x12b\xc8\x1e&\x11\x9dFO\x97\x89\x9b쎉h\t\x93PL\v\xe9F\xbfKd́ˑȡz\xe2\xca\x11\xd3\xc0\xa8o\xb6P\xf7B\x9e\x19\xefj-\x01\xea\x9e(\xe7\xe2\xe7\xb7\xea\xe2\xe2\xacc\xda0g͕\xc4\xf5\xd4\x13\xe5\xcc\x05\xaey\xe4\xa8\x04\xab4ѳ;cZ\xdf~dZ047\xb3\v\xb5~8\xfa\xfc\xe6\xcc\x14\xdd\xe1\x1f%\x9b\xf2\xcf\xec\xc6JH\xb6\v\xc9\xcf\xf6@\xbez\xf3\xa7~\x90<\xfca\xe3\xc2\x11\xe1\x8d\xe0-\xeb\x9c\t}\xfb G!\xb6ꖭ\xc4\x1a\x7f\xbc\xcaY\xc3\xe1\xed\x96&\x9c\xa6\xc5`\x1fu\a\xd3{z8\xbdH_~e\xc3\x11\x12\x18\x02\x95\xdb\xeeA8\xaa<#Z\x81\xbc\xb0\xc6'\x8b \x14\xd7\xed\x81&\xbbK\xfcR\x81ky\x9d\x04\xe3.>\xee\x12\x19\xcf\x12\x87\xb7g\x98\x84tXj[\x06\xd50\xae\xf7\xdc\xe7W\xb2r_Խ\xbd\xfb\x02U\x89\xfb\xfe\xb2\xf3\xfc\x10\xcd30T\x94\xa9s:Mp\x8a\x839>+\x9f\xa3\x1b\xa1hC\xe4\x13\xa5\x81W-\x1b@]\x95\x86\xd1y1\x91\x0f\x8b1K0\x00Q`\x0fC1;o\x8ek\xd5Q\xc8:\x9aQ\xcc\\\xd7\x13\xd1\xeet\xb8KdLpsױ\x04\aW4\x1cO\x0e$\x81\xd0Z\xf0\xc1\x9d\x89l\x11\xd7G2\xbe\x17:\xb0b?Cݝ\xfc\x16\xf3B\b\xe2\xdd\xfd\xe5b\xe1U\ueb0d\xde\x05~I\xa2\xa71\xe2A\xecT\x8f\x88\x1d\xe0\x97\xe2\x1b\x01~\x9d\x01~\xa9\xce\xe0L֘\x06\xb656\x97\x01(\xe0\x8aiv\xf1\xc5\xf6\"\xae-\x8d}k\xa1b\x96>\x93\xb5\xbe\x8dG|'\x03\xbb\x90a\xf6\xa7\xbfk\x96f?c뗻{\x9b\x8d\xa1\x0e\x03\xd7<\xb2@D\x1eD\xe9&n#Uf\x1f\xfdV\xa0䂳\x88x\xd8}\x06롅\xc1\x8a\xf8\xba\x82\v\xf3\xc8\xe0\x1bu#x3K\t\xbcq\x81\xa3r\x1d\a\x01\xfe\xd3#\x02<C_~\x05\xc6\x17\xb9\fY5\n\xea\x9dh[ؤ\xcf\n(\xf8<\u058c+M\xb89\xbdl\xea\\\xabVn\xfe\xb1\x80Q\xc2\xf3\x19\x94V\xf5d\xaeÞ\xc72\xcf\xf9\xae\xd5\b?\x14\x8c\xa0\x1a2@M\xc5=\xaf)\f\xc49s\xbaі\xee5\xe9\xc7H\x86دL\x82\x83\xb3\xaf\f!\b4\x91\xdaܭ\xa0\x89\xf7\x1d\x81\x95\xc9\f\xf7\n\x8e\v\x91\x8c\x9c\x06H\v\x8e\xa8{\xfb\x82#P\x95\xc7&>0\x02\xc3~J\xc6;<\xa2\n\xf32\xb6\u0382?N\xce8\x8dF\xec\x1bg\xe8\xe8\x81\xdd\x7f\xf9KG\xf6\x1d(u\x85M\xe1\xf5,\u074b\x01[D\xbc\x90\xbd\xbbR\xe1\xbb\xfb\xd3\x02\x8aҧ\xf7lQ\x96\x12\xa6\xef\xad]\xe6\xadu\xd7z\xe9\x03-\xfe\xe3Y\xf5\xe6$p\x9f\xb3̕r\xeb\x93V\xfc\x8c\xb2\xf7\r\xe8\xe0+A\xe8]\x9e\xc4w?&\xa4\xb7\uf0f7\xf3t\x89\x877\xf5\xff\x01\x00\x00\xff\xff\x01\x00\x00\xff\xfft\xeb*\xe1\xd2\x1b\x00\x00"))
+	schema.RegisterEncodedSchema("dev.miren.core", "v1alpha", []byte("\x1f\x8b\b\x00\x00\x00\x00\x00\x00\xff\xa4Y˖\xdc&\x13~\x8d\xff\xb7\xe3\xdb8W'GvN6Y\xe5U8\xb4(ItK \x03\xea\x99\xce.N\x8esy\x8d\x19\xc7O\x98\xacs\xb8\xa9\x11\x8d\x043\xde\xccP\xc0\xf7UQU\x14\xa0\xbe#\f\x0f\xc0\b\x1c\xab\x81\n`U\xcd\x05\xc0\x812\"?^/{_\xeb\xde\n\x8f\xe3\xdf\x06#\xa2Q<\x8e\x16\xf7oC\xf8\x80)\x8bH\x9b\x86BO\xe4\xbb\xdb\x1d%7/.\xc1\x15\xae\x15=\x02:\x82\x90\x943\xa3\x83E}\xea4\u008e\x12C\xf1(A1\n\xbe\x87Z\x19l\xeb\x05\aj\x1dI{\xfc\x1e\xf7c\x87\xfbQ\xd0\x01\x8b\x13\xd2F\xd7x\x1co\x1eG\x8cz\xa0r,v\xcd\xc7h\x86\x1b,Y\xf7/\xc6\xe8\xcf\xd2\x04\x15\xbff \x8c\n\xb0Mmt#\x95\xa0\xac\xdd4ܯ\xf2\x82Y\x9bTa\xa1h\x83\xbd\xf5q<\xfdh\x89\xf9\xbf\x1a\xf3\x1f\xaf0\xe8\xac0\xd6k?z\x87\x1b\xc4\xf35Ā\x19m@\xdaXu\xb3\x14\xac\xdb\xe0\xbf\xca\xe1\x11\xa1\xad\xa7\xe1qg\xa9\x17;O\x9bv\xe3\x00\n\x13\xacpڍ~\xb4ȍwzQOW\x18\xaa\x1e\uf817d\xc0\xec\xf4\x8f\xd1ո\x1e\xbd\x100\xedd\x1a\xcd\x04\x1aC\xce\x7fbo>[\xc3=x\xe3t\x9e\xe2bQ\xda\x1b\x15\x81\xb1\xe7\xa7\x01\x98K\xc1\x9b\xffG\xb3\xce\x13J\xdc\xf7\x97Y\xfd\xd5*\x87\xceC4/\xbf\x9b\xa5\xd8\x0f_l3\xb8\x05\x1b\x92C\xd8\x11\xf3|\xbeγ\x9bhOP\xcf[ih\xf6\x81|\x0f\x96\xba\x9f\xa4\x02\x81(\xb1,\x81\x1c\xb3|\xb9\xc1\u0087\xb1\a\x05\x04a\x1b\xe2~\xd1\x130\xdde\xbccM\x03\x82v'Ct\b;4\x0f\xd5̜\x01S\xe7\x96\v}D[\xa5iK\xd2\xe0\xb7\r\xb7\x19\x92J\xd1\x01\xa4\xc2\xc3hTӳ\x18{-\xbdVK2I\x10\b\x06L{ò\x0f\xe4\x98\xe6*G\xe3\x02\xd8z\xa1,\a\x02\x829\xab\xe9Y,-o{϶;%\x8bj\x10\t\x10\x82\v4\x80\x94\xb8\xb5EdXv\x05:\xef2\x9b\xb1\xa5\nQ\xd6pC\xd3\xcdR&M\xae\xd6\xd3\xc4S\x94\xe4ȟ\xb7\xa9J\xeb\x19*<\xa9\x8e\xdb\x13\xb7q\xed`e\xdb؝\xc0\xac\xee,ֵc\xec\xb7kؚ\x0f\x03UȪ\f\x92K\xa6\x06b֯3\xacˬ\x1f/zc\xbe\xe7k|T\"B\x85\xb2{\xbc\x9b%\x8d';\xce\xfb\xe4a2\xa3\xc3\xeci\x13y\x93\xdc13Z\xc0\xc8%U\\X\xed\xfb@\x8e9\x1e\xafq\xc8\x0e\x1bp\xad\x1b1\xea\x9b5\xd45\x17\a\xcaZ\xa4\x04\x00갴!~{\xd9]\xba\xefږ*mO\xd2]A^\x8f\x1d\x96\xd6]`\x9b\xb9@\x05X\xa9\xb0\x9a\xec\x19Ӹ\xf6=˂\xa6\xb9y\xb6r\xd7\xf7G\x9fۜ\x89K\xb7\x9fQ\xb2)\x7fOn\xac\x80d\xfd\"\xf9r\v\xe4no\xee\xd4\xf7\x92\x83߭<8fx\xcdYC[\x03n\\;S\xa3\"\xb6\xd0Q\x95e(\xf1\xc6\xfb\x0f)oX|\xa57/f$\xbc\fvs_ƼWY\xf3f\xfa\x12;\xdf%\xf3\xd73x*\xe3\xbf\xd6\vq\x06\xaf\xa2%\x88#\xad]\xb1\xf0Bi\x06\xcf\x1eI\xee\x12\xb7T`J\x9cFN\x99\xbd\xfb\xec\x039\xb6\xf2Q\x9aa\xe4\xc2b\x89iiTM\x99\xda\n\x9f[\xc9\"|sߧ\x87\xcfS\x95\x84\xef\x0fc\xe7\x93\xe5\xd8k\xcfP\x11*\x0f\xa1\x99`;26\xbe)\xb7\xd1j(\xb1\xf4}2U\f\xbcjh\x0f\xf2$\x15\f&\x12\xfb@\xce\x1d-\x96\xa0\a,\xc1\x1c\x86|\xb2\xd1\x1c\x96]\xb9\x94\xb54\x03\x9f\x98B#V\xf6t\xd8\arLp\xf1\xd61\x04\x99'Z\x9cOV\xab\x00L\x10g\xbd=\x13\xe9Y\\\x1e\xc9\xf1\xbbЂ%\xfd\x19P\xbbs[\xcc\t>\x897\xf7\x97ͅ\x0f\xa9\xb3v\x8e.\xb0c\x90=\xb5\x163\xb9S\xdd#w\x80\x1dK2Ǽ\b\xe2\xaf3\xc0\x8e\xd5\x01\xac\xcbj݈}\x1d\xbbK\x03$0I\xf5G'\x03\xa3gq\xe9\xe98\xb6\x06\xca'\xe1*Y\xe3ڱ\xc6\xff%`G\xdcO\xee\xf4\xb7\xcd\xd2\xea\xa7}}\xbb\xb9\xb7\xe9\xe0\xefa`\x9b9\x0f\xcc\xc8L\x96\xae\xe2VJe\xf2\xa3\xdf\x02t~\xb6ӳ\x18\xab\xddfг\x03\x06#\x06\fw\xa9\x8b\xf9\xcc\xe0\x1a\xa8欞\x84\x00V\xdbđ\xa9\x81L\x82\xfft\x8f\x04O\xd0\x17'\xfc\xc5C.AV\r\x9c\xb8 \x9aV\xec\xd27i\x97.(\xd84 ʤ¬\x06{\xd5\x1c\x96]\x8b0\xffX\xc0(\xe0\xed\x04RI4\x82\x98y\f\xf3\x94\x1eZh\xf8\xa1@\x83\xacq\x0f\x88\xf0k\x86\b\xf4\xd8\x06s\xbc\xe8-\xddk\xc2\xe9\bTl\xce\xef\xdc|\x99\xfc\xca\xe0\x93@a\xa1\xf4\xdb\n\xea\xf9\xbd\xc3\xe3\xce\xc0\u00ad\v\xc7\x11\v\x8aw=\x04\xb5\xb8\x9b\xfb>\xfd\xc2\xe1\xa9\xcas3>0<\xc3vI~\xb1\x86*\xac\xcbOW\xf1\xf9\xe2\xfcd\r\xfb\xe0\n=G`s\x96{t$\xbf\x03\x85\xa10%\x1cM\xc2~\x8e\xa2g1^\xc8\xd6[\xa9\xf0\xbb\xfb\xab\x02\x8a\xd2O\xef\xc9KYH\xe8\xfe\x1b\x93\xbc\xa7B\x82M\xef\x85\x1fh/\xb4\xe8l\xad0!\x9cͥ\xc4\xe5|\x1c\xec\xe5\xa4\xf24\x7f\xb9\xc9cu\x1b\x8d`\x9b\xb9\x84\x8f\xf1k\xef\xe1\xabm\xdc\xd8c\xe6\xce\xe1\x1e/\xb4\x9a\"\xf2]\x06-\xf8\x91\x12 \xc8f\xfe\xb9\xa4\xb0h$SX\"5զ\x9a\x87\xffz\xb8\xe4\xd9.0/\xb7\xb1\x0f\xde\xea\x83'2\f\x9bS\xd9\xd2\r\xf1܃\xec\xf4m\xc6x\xe3\xa3\xfeA\xedz\xe5\xdb\xcc\xfc\xdb\xdf\xd6\x0f\x97\x99\x9f\xb6\xfc\xe8\xf9w\x9c\xe44?\x1a~2\xca\xfc\xe0\xb3ؗ\xcfV\x96\xe0'D.)\xd9\xc7\xff\x01\x00\x00\xff\xff\x03\x00\x94YS\x1f\xba\x1e\x00\x00"))
 }
@@ -37,6 +37,10 @@ func (v *IPLease) SetIp(ip string) {
 	v.data.Ip = &ip
 }
 
+func (v *IPLease) ClearIp() {
+	v.data.Ip = nil
+}
+
 func (v *IPLease) HasSubnet() bool {
 	return v.data.Subnet != nil
 }
@@ -52,6 +56,10 @@ func (v *IPLease) SetSubnet(subnet string) {
 	v.data.Subnet = &subnet
 }
 
+func (v *IPLease) ClearSubnet() {
+	v.data.Subnet = nil
+}
+
 func (v *IPLease) HasReserved() bool {
 	return v.data.Reserved != nil
 }
@@ -67,6 +75,10 @@ func (v *IPLease) SetReserved(reserved bool) {
 	v.data.Reserved = &reserved
 }
 
+func (v *IPLease) ClearReserved() {
+	v.data.Reserved = nil
+}
+
 func (v *IPLease) HasReleasedAt() bool {
 	return v.data.ReleasedAt != nil
 }
@@ -79,6 +91,10 @@ func (v *IPLease) SetReleasedAt(released_at *standard.Timestamp) {
 	v.data.ReleasedAt = released_at
 }
 
+func (v *IPLease) ClearReleasedAt() {
+	v.data.ReleasedAt = nil
+}
+
 func (v *IPLease) HasSandboxId() bool {
 	return v.data.SandboxId != nil
 }
@@ -94,6 +110,10 @@ func (v *IPLease) SetSandboxId(sandbox_id string) {
 	v.data.SandboxId = &sandbox_id
 }
 
+func (v *IPLease) ClearSandboxId() {
+	v.data.SandboxId = nil
+}
+
 func (v *IPLease) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -137,6 +157,10 @@ func (v *SubnetStatus) SetSubnet(subnet string) {
 	v.data.Subnet = &subnet
 }
 
+func (v *SubnetStatus) ClearSubnet() {
+	v.data.Subnet = nil
+}
+
 func (v *SubnetStatus) HasTotal() bool {
 	return v.data.Total != nil
 }
@@ -152,6 +176,10 @@ func (v *SubnetStatus) SetTotal(total int32) {
 	v.data.Total = &total
 }
 
+func (v *SubnetStatus) ClearTotal() {
+	v.data.Total = nil
+}
+
 func (v *SubnetStatus) HasReserved() bool {
 	return v.data.Reserved != nil
 }
@@ -167,6 +195,10 @@ func (v *SubnetStatus) SetReserved(reserved int32) {
 	v.data.Reserved = &reserved
 }
 
+func (v *SubnetStatus) ClearReserved() {
+	v.data.Reserved = nil
+}
+
 func (v *SubnetStatus) HasReleased() bool {
 	return v.data.Released != nil
 }
@@ -182,6 +214,10 @@ func (v *SubnetStatus) SetReleased(released int32) {
 	v.data.Released = &released
 }
 
+func (v *SubnetStatus) ClearReleased() {
+	v.data.Released = nil
+}
+
 func (v *SubnetStatus) HasCapacity() bool {
 	return v.data.Capacity != nil
 }
@@ -197,6 +233,10 @@ func (v *SubnetStatus) SetCapacity(capacity int32) {
 	v.data.Capacity = &capacity
 }
 
+func (v *SubnetStatus) ClearCapacity() {
+	v.data.Capacity = nil
+}
+
 func (v *SubnetStatus) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -213,6 +253,7 @@ func (v *SubnetStatus) UnmarshalJSON(data []byte) error {
 	return json.Unmarshal(data, &v.data)
 }
 
+// Server structs for NetDB
 type netDBListLeasesArgsData struct {
 	Subnet       *string `cbor:"0,keyasint,omitempty" json:"subnet,omitempty"`
 	ReservedOnly *bool   `cbor:"1,keyasint,omitempty" json:"reserved_only,omitempty"`
@@ -287,6 +328,10 @@ func (v *NetDBListLeasesResults) SetLeases(leases []*IPLease) {
 	v.data.Leases = &x
 }
 
+func (v *NetDBListLeasesResults) ClearLeases() {
+	v.data.Leases = nil
+}
+
 func (v *NetDBListLeasesResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -340,6 +385,10 @@ func (v *NetDBStatusResults) SetSubnets(subnets []*SubnetStatus) {
 	v.data.Subnets = &x
 }
 
+func (v *NetDBStatusResults) ClearSubnets() {
+	v.data.Subnets = nil
+}
+
 func (v *NetDBStatusResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -405,6 +454,10 @@ func (v *NetDBReleaseIPResults) SetReleased(released bool) {
 	v.data.Released = &released
 }
 
+func (v *NetDBReleaseIPResults) ClearReleased() {
+	v.data.Released = nil
+}
+
 func (v *NetDBReleaseIPResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -470,6 +523,10 @@ func (v *NetDBReleaseSubnetResults) SetCount(count int32) {
 	v.data.Count = &count
 }
 
+func (v *NetDBReleaseSubnetResults) ClearCount() {
+	v.data.Count = nil
+}
+
 func (v *NetDBReleaseSubnetResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -522,6 +579,10 @@ func (v *NetDBReleaseAllResults) SetCount(count int32) {
 	v.data.Count = &count
 }
 
+func (v *NetDBReleaseAllResults) ClearCount() {
+	v.data.Count = nil
+}
+
 func (v *NetDBReleaseAllResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -601,10 +662,18 @@ func (v *NetDBGcResults) SetOrphanedIps(orphaned_ips []string) {
 	v.data.OrphanedIps = &x
 }
 
+func (v *NetDBGcResults) ClearOrphanedIps() {
+	v.data.OrphanedIps = nil
+}
+
 func (v *NetDBGcResults) SetReleasedCount(released_count int32) {
 	v.data.ReleasedCount = &released_count
 }
 
+func (v *NetDBGcResults) ClearReleasedCount() {
+	v.data.ReleasedCount = nil
+}
+
 func (v *NetDBGcResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
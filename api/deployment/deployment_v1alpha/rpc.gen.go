@@ -46,6 +46,10 @@ func (v *DeploymentInfo) SetId(id string) {
 	v.data.Id = &id
 }
 
+func (v *DeploymentInfo) ClearId() {
+	v.data.Id = nil
+}
+
 func (v *DeploymentInfo) HasAppName() bool {
 	return v.data.AppName != nil
 }
@@ -61,6 +65,10 @@ func (v *DeploymentInfo) SetAppName(app_name string) {
 	v.data.AppName = &app_name
 }
 
+func (v *DeploymentInfo) ClearAppName() {
+	v.data.AppName = nil
+}
+
 func (v *DeploymentInfo) HasAppVersionId() bool {
 	return v.data.AppVersionId != nil
 }
@@ -76,6 +84,10 @@ func (v *DeploymentInfo) SetAppVersionId(app_version_id string) {
 	v.data.AppVersionId = &app_version_id
 }
 
+func (v *DeploymentInfo) ClearAppVersionId() {
+	v.data.AppVersionId = nil
+}
+
 func (v *DeploymentInfo) HasClusterId() bool {
 	return v.data.ClusterId != nil
 }
@@ -91,6 +103,10 @@ func (v *DeploymentInfo) SetClusterId(cluster_id string) {
 	v.data.ClusterId = &cluster_id
 }
 
+func (v *DeploymentInfo) ClearClusterId() {
+	v.data.ClusterId = nil
+}
+
 func (v *DeploymentInfo) HasStatus() bool {
 	return v.data.Status != nil
 }
@@ -106,6 +122,10 @@ func (v *DeploymentInfo) SetStatus(status string) {
 	v.data.Status = &status
 }
 
+func (v *DeploymentInfo) ClearStatus() {
+	v.data.Status = nil
+}
+
 func (v *DeploymentInfo) HasPhase() bool {
 	return v.data.Phase != nil
 }
@@ -121,6 +141,10 @@ func (v *DeploymentInfo) SetPhase(phase string) {
 	v.data.Phase = &phase
 }
 
+func (v *DeploymentInfo) ClearPhase() {
+	v.data.Phase = nil
+}
+
 func (v *DeploymentInfo) HasDeployedByUserId() bool {
 	return v.data.DeployedByUserId != nil
 }
@@ -136,6 +160,10 @@ func (v *DeploymentInfo) SetDeployedByUserId(deployed_by_user_id string) {
 	v.data.DeployedByUserId = &deployed_by_user_id
 }
 
+func (v *DeploymentInfo) ClearDeployedByUserId() {
+	v.data.DeployedByUserId = nil
+}
+
 func (v *DeploymentInfo) HasDeployedByUserEmail() bool {
 	return v.data.DeployedByUserEmail != nil
 }
@@ -151,6 +179,10 @@ func (v *DeploymentInfo) SetDeployedByUserEmail(deployed_by_user_email string) {
 	v.data.DeployedByUserEmail = &deployed_by_user_email
 }
 
+func (v *DeploymentInfo) ClearDeployedByUserEmail() {
+	v.data.DeployedByUserEmail = nil
+}
+
 func (v *DeploymentInfo) HasDeployedAt() bool {
 	return v.data.DeployedAt != nil
 }
@@ -163,6 +195,10 @@ func (v *DeploymentInfo) SetDeployedAt(deployed_at *standard.Timestamp) {
 	v.data.DeployedAt = deployed_at
 }
 
+func (v *DeploymentInfo) ClearDeployedAt() {
+	v.data.DeployedAt = nil
+}
+
 func (v *DeploymentInfo) HasCompletedAt() bool {
 	return v.data.CompletedAt != nil
 }
@@ -175,6 +211,10 @@ func (v *DeploymentInfo) SetCompletedAt(completed_at *standard.Timestamp) {
 	v.data.CompletedAt = completed_at
 }
 
+func (v *DeploymentInfo) ClearCompletedAt() {
+	v.data.CompletedAt = nil
+}
+
 func (v *DeploymentInfo) HasErrorMessage() bool {
 	return v.data.ErrorMessage != nil
 }
@@ -190,6 +230,10 @@ func (v *DeploymentInfo) SetErrorMessage(error_message string) {
 	v.data.ErrorMessage = &error_message
 }
 
+func (v *DeploymentInfo) ClearErrorMessage() {
+	v.data.ErrorMessage = nil
+}
+
 func (v *DeploymentInfo) HasBuildLogs() bool {
 	return v.data.BuildLogs != nil
 }
@@ -205,6 +249,10 @@ func (v *DeploymentInfo) SetBuildLogs(build_logs string) {
 	v.data.BuildLogs = &build_logs
 }
 
+func (v *DeploymentInfo) ClearBuildLogs() {
+	v.data.BuildLogs = nil
+}
+
 func (v *DeploymentInfo) HasGitInfo() bool {
 	return v.data.GitInfo != nil
 }
@@ -217,6 +265,10 @@ func (v *DeploymentInfo) SetGitInfo(git_info *GitInfo) {
 	v.data.GitInfo = git_info
 }
 
+func (v *DeploymentInfo) ClearGitInfo() {
+	v.data.GitInfo = nil
+}
+
 func (v *DeploymentInfo) HasDeployedByUserName() bool {
 	return v.data.DeployedByUserName != nil
 }
@@ -232,6 +284,10 @@ func (v *DeploymentInfo) SetDeployedByUserName(deployed_by_user_name string) {
 	v.data.DeployedByUserName = &deployed_by_user_name
 }
 
+func (v *DeploymentInfo) ClearDeployedByUserName() {
+	v.data.DeployedByUserName = nil
+}
+
 func (v *DeploymentInfo) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -280,6 +336,10 @@ func (v *GitInfo) SetSha(sha string) {
 	v.data.Sha = &sha
 }
 
+func (v *GitInfo) ClearSha() {
+	v.data.Sha = nil
+}
+
 func (v *GitInfo) HasRef() bool {
 	return v.data.Ref != nil
 }
@@ -295,6 +355,10 @@ func (v *GitInfo) SetRef(ref string) {
 	v.data.Ref = &ref
 }
 
+func (v *GitInfo) ClearRef() {
+	v.data.Ref = nil
+}
+
 func (v *GitInfo) HasBranch() bool {
 	return v.data.Branch != nil
 }
@@ -310,6 +374,10 @@ func (v *GitInfo) SetBranch(branch string) {
 	v.data.Branch = &branch
 }
 
+func (v *GitInfo) ClearBranch() {
+	v.data.Branch = nil
+}
+
 func (v *GitInfo) HasRepository() bool {
 	return v.data.Repository != nil
 }
@@ -325,6 +393,10 @@ func (v *GitInfo) SetRepository(repository string) {
 	v.data.Repository = &repository
 }
 
+func (v *GitInfo) ClearRepository() {
+	v.data.Repository = nil
+}
+
 func (v *GitInfo) HasIsDirty() bool {
 	return v.data.IsDirty != nil
 }
@@ -340,6 +412,10 @@ func (v *GitInfo) SetIsDirty(is_dirty bool) {
 	v.data.IsDirty = &is_dirty
 }
 
+func (v *GitInfo) ClearIsDirty() {
+	v.data.IsDirty = nil
+}
+
 func (v *GitInfo) HasWorkingTreeHash() bool {
 	return v.data.WorkingTreeHash != nil
 }
@@ -355,6 +431,10 @@ func (v *GitInfo) SetWorkingTreeHash(working_tree_hash string) {
 	v.data.WorkingTreeHash = &working_tree_hash
 }
 
+func (v *GitInfo) ClearWorkingTreeHash() {
+	v.data.WorkingTreeHash = nil
+}
+
 func (v *GitInfo) HasCommitMessage() bool {
 	return v.data.CommitMessage != nil
 }
@@ -370,6 +450,10 @@ func (v *GitInfo) SetCommitMessage(commit_message string) {
 	v.data.CommitMessage = &commit_message
 }
 
+func (v *GitInfo) ClearCommitMessage() {
+	v.data.CommitMessage = nil
+}
+
 func (v *GitInfo) HasCommitAuthorName() bool {
 	return v.data.CommitAuthorName != nil
 }
@@ -385,6 +469,10 @@ func (v *GitInfo) SetCommitAuthorName(commit_author_name string) {
 	v.data.CommitAuthorName = &commit_author_name
 }
 
+func (v *GitInfo) ClearCommitAuthorName() {
+	v.data.CommitAuthorName = nil
+}
+
 func (v *GitInfo) HasCommitAuthorEmail() bool {
 	return v.data.CommitAuthorEmail != nil
 }
@@ -400,6 +488,10 @@ func (v *GitInfo) SetCommitAuthorEmail(commit_author_email string) {
 	v.data.CommitAuthorEmail = &commit_author_email
 }
 
+func (v *GitInfo) ClearCommitAuthorEmail() {
+	v.data.CommitAuthorEmail = nil
+}
+
 func (v *GitInfo) HasCommitTimestamp() bool {
 	return v.data.CommitTimestamp != nil
 }
@@ -412,6 +504,10 @@ func (v *GitInfo) SetCommitTimestamp(commit_timestamp *standard.Timestamp) {
 	v.data.CommitTimestamp = commit_timestamp
 }
 
+func (v *GitInfo) ClearCommitTimestamp() {
+	v.data.CommitTimestamp = nil
+}
+
 func (v *GitInfo) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -457,6 +553,10 @@ func (v *DeploymentLockInfo) SetAppName(app_name string) {
 	v.data.AppName = &app_name
 }
 
+func (v *DeploymentLockInfo) ClearAppName() {
+	v.data.AppName = nil
+}
+
 func (v *DeploymentLockInfo) HasClusterId() bool {
 	return v.data.ClusterId != nil
 }
@@ -472,6 +572,10 @@ func (v *DeploymentLockInfo) SetClusterId(cluster_id string) {
 	v.data.ClusterId = &cluster_id
 }
 
+func (v *DeploymentLockInfo) ClearClusterId() {
+	v.data.ClusterId = nil
+}
+
 func (v *DeploymentLockInfo) HasBlockingDeploymentId() bool {
 	return v.data.BlockingDeploymentId != nil
 }
@@ -487,6 +591,10 @@ func (v *DeploymentLockInfo) SetBlockingDeploymentId(blocking_deployment_id stri
 	v.data.BlockingDeploymentId = &blocking_deployment_id
 }
 
+func (v *DeploymentLockInfo) ClearBlockingDeploymentId() {
+	v.data.BlockingDeploymentId = nil
+}
+
 func (v *DeploymentLockInfo) HasStartedBy() bool {
 	return v.data.StartedBy != nil
 }
@@ -502,6 +610,10 @@ func (v *DeploymentLockInfo) SetStartedBy(started_by string) {
 	v.data.StartedBy = &started_by
 }
 
+func (v *DeploymentLockInfo) ClearStartedBy() {
+	v.data.StartedBy = nil
+}
+
 func (v *DeploymentLockInfo) HasStartedAt() bool {
 	return v.data.StartedAt != nil
 }
@@ -514,6 +626,10 @@ func (v *DeploymentLockInfo) SetStartedAt(started_at *standard.Timestamp) {
 	v.data.StartedAt = started_at
 }
 
+func (v *DeploymentLockInfo) ClearStartedAt() {
+	v.data.StartedAt = nil
+}
+
 func (v *DeploymentLockInfo) HasCurrentPhase() bool {
 	return v.data.CurrentPhase != nil
 }
@@ -529,6 +645,10 @@ func (v *DeploymentLockInfo) SetCurrentPhase(current_phase string) {
 	v.data.CurrentPhase = &current_phase
 }
 
+func (v *DeploymentLockInfo) ClearCurrentPhase() {
+	v.data.CurrentPhase = nil
+}
+
 func (v *DeploymentLockInfo) HasLockExpiresAt() bool {
 	return v.data.LockExpiresAt != nil
 }
@@ -541,6 +661,10 @@ func (v *DeploymentLockInfo) SetLockExpiresAt(lock_expires_at *standard.Timestam
 	v.data.LockExpiresAt = lock_expires_at
 }
 
+func (v *DeploymentLockInfo) ClearLockExpiresAt() {
+	v.data.LockExpiresAt = nil
+}
+
 func (v *DeploymentLockInfo) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -557,6 +681,7 @@ func (v *DeploymentLockInfo) UnmarshalJSON(data []byte) error {
 	return json.Unmarshal(data, &v.data)
 }
 
+// Server structs for Deployment
 type deploymentCreateDeploymentArgsData struct {
 	AppName      *string  `cbor:"0,keyasint,omitempty" json:"app_name,omitempty"`
 	ClusterId    *string  `cbor:"1,keyasint,omitempty" json:"cluster_id,omitempty"`
@@ -641,14 +766,26 @@ func (v *DeploymentCreateDeploymentResults) SetDeployment(deployment *Deployment
 	v.data.Deployment = deployment
 }
 
+func (v *DeploymentCreateDeploymentResults) ClearDeployment() {
+	v.data.Deployment = nil
+}
+
 func (v *DeploymentCreateDeploymentResults) SetError(error string) {
 	v.data.Error = &error
 }
 
+func (v *DeploymentCreateDeploymentResults) ClearError() {
+	v.data.Error = nil
+}
+
 func (v *DeploymentCreateDeploymentResults) SetLockInfo(lock_info *DeploymentLockInfo) {
 	v.data.LockInfo = lock_info
 }
 
+func (v *DeploymentCreateDeploymentResults) ClearLockInfo() {
+	v.data.LockInfo = nil
+}
+
 func (v *DeploymentCreateDeploymentResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -738,6 +875,10 @@ func (v *DeploymentUpdateDeploymentStatusResults) SetDeployment(deployment *Depl
 	v.data.Deployment = deployment
 }
 
+func (v *DeploymentUpdateDeploymentStatusResults) ClearDeployment() {
+	v.data.Deployment = nil
+}
+
 func (v *DeploymentUpdateDeploymentStatusResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -815,6 +956,10 @@ func (v *DeploymentUpdateDeploymentPhaseResults) SetDeployment(deployment *Deplo
 	v.data.Deployment = deployment
 }
 
+func (v *DeploymentUpdateDeploymentPhaseResults) ClearDeployment() {
+	v.data.Deployment = nil
+}
+
 func (v *DeploymentUpdateDeploymentPhaseResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -904,6 +1049,10 @@ func (v *DeploymentUpdateFailedDeploymentResults) SetDeployment(deployment *Depl
 	v.data.Deployment = deployment
 }
 
+func (v *DeploymentUpdateFailedDeploymentResults) ClearDeployment() {
+	v.data.Deployment = nil
+}
+
 func (v *DeploymentUpdateFailedDeploymentResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -981,6 +1130,10 @@ func (v *DeploymentUpdateDeploymentAppVersionResults) SetDeployment(deployment *
 	v.data.Deployment = deployment
 }
 
+func (v *DeploymentUpdateDeploymentAppVersionResults) ClearDeployment() {
+	v.data.Deployment = nil
+}
+
 func (v *DeploymentUpdateDeploymentAppVersionResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -1083,6 +1236,10 @@ func (v *DeploymentListDeploymentsResults) SetDeployments(deployments []*Deploym
 	v.data.Deployments = &x
 }
 
+func (v *DeploymentListDeploymentsResults) ClearDeployments() {
+	v.data.Deployments = nil
+}
+
 func (v *DeploymentListDeploymentsResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -1148,6 +1305,10 @@ func (v *DeploymentGetDeploymentByIdResults) SetDeployment(deployment *Deploymen
 	v.data.Deployment = deployment
 }
 
+func (v *DeploymentGetDeploymentByIdResults) ClearDeployment() {
+	v.data.Deployment = nil
+}
+
 func (v *DeploymentGetDeploymentByIdResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -1225,6 +1386,10 @@ func (v *DeploymentGetActiveDeploymentResults) SetDeployment(deployment *Deploym
 	v.data.Deployment = deployment
 }
 
+func (v *DeploymentGetActiveDeploymentResults) ClearDeployment() {
+	v.data.Deployment = nil
+}
+
 func (v *DeploymentGetActiveDeploymentResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
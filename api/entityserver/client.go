@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
@@ -136,6 +138,48 @@ func (c *Client) List(ctx context.Context, index entity.Attr) (*ListResults, err
 	return &lr, nil
 }
 
+// ListByLabels lists the entities of the given kind whose metadata labels
+// include every label in selector, sorted by id. An empty selector matches
+// every entity of the kind.
+func (c *Client) ListByLabels(ctx context.Context, kind entity.Id, selector types.Labels) (*ListResults, error) {
+	ret, err := c.eac.List(ctx, entity.Ref(entity.EntityKind, kind))
+	if err != nil {
+		return nil, err
+	}
+
+	var lr ListResults
+
+	for _, v := range ret.Values() {
+		ent := v.Entity()
+
+		var md core_v1alpha.Metadata
+		md.Decode(ent)
+
+		if !matchLabels(md.Labels, selector) {
+			continue
+		}
+
+		lr.values = append(lr.values, ent)
+		lr.len++
+	}
+
+	slices.SortFunc(lr.values, func(a, b *entity.Entity) int {
+		return strings.Compare(a.Id().String(), b.Id().String())
+	})
+
+	return &lr, nil
+}
+
+func matchLabels(labels, selector types.Labels) bool {
+	for _, sel := range selector {
+		if v, ok := labels.Get(sel.Key); !ok || v != sel.Value {
+			return false
+		}
+	}
+
+	return true
+}
+
 func (c *Client) OneAtIndex(ctx context.Context, index entity.Attr, sc SchemaEncoder) error {
 	ret, err := c.eac.List(ctx, index)
 	if err != nil {
@@ -156,7 +200,8 @@ func (c *Client) OneAtIndex(ctx context.Context, index entity.Attr, sc SchemaEnc
 }
 
 type createOp struct {
-	labels types.Labels
+	labels   types.Labels
+	validate bool
 }
 
 type CreateOptions func(o *createOp)
@@ -167,12 +212,39 @@ func WithLabels(labels types.Labels) CreateOptions {
 	}
 }
 
+// WithValidation checks the entity against its schema's constraints, such as
+// required attributes and enum choices, before it's written.
+func WithValidation() CreateOptions {
+	return func(o *createOp) {
+		o.validate = true
+	}
+}
+
+// check runs sc's generated Validate, if requested and sc has one.
+func (op *createOp) check(sc SchemaEncoder) error {
+	if !op.validate {
+		return nil
+	}
+
+	if v, ok := sc.(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("invalid %s: %w", sc.ShortKind(), err)
+		}
+	}
+
+	return nil
+}
+
 func (c *Client) Create(ctx context.Context, name string, sc SchemaEncoder, opts ...CreateOptions) (entity.Id, error) {
 	var op createOp
 	for _, opt := range opts {
 		opt(&op)
 	}
 
+	if err := op.check(sc); err != nil {
+		return "", err
+	}
+
 	var rpcE entityserver_v1alpha.Entity
 
 	rpcE.SetAttrs(entity.New(
@@ -207,6 +279,10 @@ func (c *Client) CreateOrUpdate(ctx context.Context, name string, sc SchemaEncod
 		opt(&op)
 	}
 
+	if err := op.check(sc); err != nil {
+		return "", err
+	}
+
 	var rpcE entityserver_v1alpha.Entity
 
 	gr, err := c.eac.Get(ctx, sc.ShortKind()+"/"+name)
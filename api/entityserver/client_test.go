@@ -0,0 +1,79 @@
+package entityserver_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"miren.dev/runtime/api/core/core_v1alpha"
+	"miren.dev/runtime/api/entityserver"
+	"miren.dev/runtime/pkg/entity"
+	"miren.dev/runtime/pkg/entity/testutils"
+	"miren.dev/runtime/pkg/entity/types"
+)
+
+func TestClient_ListByLabels(t *testing.T) {
+	ctx := t.Context()
+
+	inmem, cleanup := testutils.NewInMemEntityServer(t)
+	defer cleanup()
+
+	client := inmem.Client
+
+	create := func(name string, sc entityserver.SchemaEncoder, labels ...string) {
+		_, err := client.Create(ctx, name, sc, entityserver.WithLabels(types.LabelSet(labels...)))
+		require.NoError(t, err)
+	}
+
+	create("web", &core_v1alpha.App{}, "team", "a", "tier", "frontend")
+	create("api", &core_v1alpha.App{}, "team", "a", "tier", "backend")
+	create("worker", &core_v1alpha.App{}, "team", "b", "tier", "backend")
+	create("bare", &core_v1alpha.App{})
+
+	// Another kind sharing the labels shouldn't be listed
+	create("team-a", &core_v1alpha.Project{}, "team", "a", "tier", "backend")
+
+	names := func(t *testing.T, selector ...string) []string {
+		lr, err := client.ListByLabels(ctx, core_v1alpha.KindApp, types.LabelSet(selector...))
+		require.NoError(t, err)
+
+		var names []string
+		for lr.Next() {
+			names = append(names, lr.Metadata().Name)
+		}
+
+		require.Equal(t, len(names), lr.Length())
+
+		return names
+	}
+
+	t.Run("matches every label in the selector", func(t *testing.T) {
+		require.Equal(t, []string{"api"}, names(t, "team", "a", "tier", "backend"))
+	})
+
+	t.Run("matches overlapping label sets", func(t *testing.T) {
+		require.Equal(t, []string{"api", "web"}, names(t, "team", "a"))
+		require.Equal(t, []string{"api", "worker"}, names(t, "tier", "backend"))
+	})
+
+	t.Run("lists the whole kind without a selector", func(t *testing.T) {
+		require.Equal(t, []string{"api", "bare", "web", "worker"}, names(t))
+	})
+
+	t.Run("matches nothing on a missing or different value", func(t *testing.T) {
+		require.Empty(t, names(t, "team", "c"))
+		require.Empty(t, names(t, "owner", "a"))
+	})
+
+	t.Run("sorts by id", func(t *testing.T) {
+		lr, err := client.ListByLabels(ctx, core_v1alpha.KindApp, nil)
+		require.NoError(t, err)
+
+		var ids []entity.Id
+		for lr.Next() {
+			ids = append(ids, lr.Entity().Id())
+		}
+
+		require.IsIncreasing(t, ids)
+	})
+}
@@ -35,6 +35,10 @@ func (v *ReindexStat) SetName(name string) {
 	v.data.Name = &name
 }
 
+func (v *ReindexStat) ClearName() {
+	v.data.Name = nil
+}
+
 func (v *ReindexStat) HasValue() bool {
 	return v.data.Value != nil
 }
@@ -50,6 +54,10 @@ func (v *ReindexStat) SetValue(value int64) {
 	v.data.Value = &value
 }
 
+func (v *ReindexStat) ClearValue() {
+	v.data.Value = nil
+}
+
 func (v *ReindexStat) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -95,6 +103,10 @@ func (v *AttributeSchema) SetId(id string) {
 	v.data.Id = &id
 }
 
+func (v *AttributeSchema) ClearId() {
+	v.data.Id = nil
+}
+
 func (v *AttributeSchema) HasDoc() bool {
 	return v.data.Doc != nil
 }
@@ -110,6 +122,10 @@ func (v *AttributeSchema) SetDoc(doc string) {
 	v.data.Doc = &doc
 }
 
+func (v *AttributeSchema) ClearDoc() {
+	v.data.Doc = nil
+}
+
 func (v *AttributeSchema) HasAttrType() bool {
 	return v.data.AttrType != nil
 }
@@ -125,6 +141,10 @@ func (v *AttributeSchema) SetAttrType(attrType string) {
 	v.data.AttrType = &attrType
 }
 
+func (v *AttributeSchema) ClearAttrType() {
+	v.data.AttrType = nil
+}
+
 func (v *AttributeSchema) HasAllowMany() bool {
 	return v.data.AllowMany != nil
 }
@@ -140,6 +160,10 @@ func (v *AttributeSchema) SetAllowMany(allowMany bool) {
 	v.data.AllowMany = &allowMany
 }
 
+func (v *AttributeSchema) ClearAllowMany() {
+	v.data.AllowMany = nil
+}
+
 func (v *AttributeSchema) HasIndexed() bool {
 	return v.data.Indexed != nil
 }
@@ -155,6 +179,10 @@ func (v *AttributeSchema) SetIndexed(indexed bool) {
 	v.data.Indexed = &indexed
 }
 
+func (v *AttributeSchema) ClearIndexed() {
+	v.data.Indexed = nil
+}
+
 func (v *AttributeSchema) HasSession() bool {
 	return v.data.Session != nil
 }
@@ -170,6 +198,10 @@ func (v *AttributeSchema) SetSession(session bool) {
 	v.data.Session = &session
 }
 
+func (v *AttributeSchema) ClearSession() {
+	v.data.Session = nil
+}
+
 func (v *AttributeSchema) HasTags() bool {
 	return v.data.Tags != nil
 }
@@ -186,6 +218,10 @@ func (v *AttributeSchema) SetTags(tags []string) {
 	v.data.Tags = &x
 }
 
+func (v *AttributeSchema) ClearTags() {
+	v.data.Tags = nil
+}
+
 func (v *AttributeSchema) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -229,6 +265,10 @@ func (v *Entity) SetId(id string) {
 	v.data.Id = &id
 }
 
+func (v *Entity) ClearId() {
+	v.data.Id = nil
+}
+
 func (v *Entity) HasRevision() bool {
 	return v.data.Revision != nil
 }
@@ -244,6 +284,10 @@ func (v *Entity) SetRevision(revision int64) {
 	v.data.Revision = &revision
 }
 
+func (v *Entity) ClearRevision() {
+	v.data.Revision = nil
+}
+
 func (v *Entity) HasCreatedAt() bool {
 	return v.data.CreatedAt != nil
 }
@@ -259,6 +303,10 @@ func (v *Entity) SetCreatedAt(created_at int64) {
 	v.data.CreatedAt = &created_at
 }
 
+func (v *Entity) ClearCreatedAt() {
+	v.data.CreatedAt = nil
+}
+
 func (v *Entity) HasUpdatedAt() bool {
 	return v.data.UpdatedAt != nil
 }
@@ -274,6 +322,10 @@ func (v *Entity) SetUpdatedAt(updated_at int64) {
 	v.data.UpdatedAt = &updated_at
 }
 
+func (v *Entity) ClearUpdatedAt() {
+	v.data.UpdatedAt = nil
+}
+
 func (v *Entity) HasAttrs() bool {
 	return v.data.Attrs != nil
 }
@@ -290,6 +342,10 @@ func (v *Entity) SetAttrs(attrs []entity.Attr) {
 	v.data.Attrs = &x
 }
 
+func (v *Entity) ClearAttrs() {
+	v.data.Attrs = nil
+}
+
 func (v *Entity) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -329,6 +385,10 @@ func (v *EntityOp) SetEntity(entity *Entity) {
 	v.data.Entity = entity
 }
 
+func (v *EntityOp) ClearEntity() {
+	v.data.Entity = nil
+}
+
 func (v *EntityOp) HasPrevious() bool {
 	return v.data.Previous != nil
 }
@@ -344,6 +404,10 @@ func (v *EntityOp) SetPrevious(previous int64) {
 	v.data.Previous = &previous
 }
 
+func (v *EntityOp) ClearPrevious() {
+	v.data.Previous = nil
+}
+
 func (v *EntityOp) HasOperation() bool {
 	return v.data.Operation != nil
 }
@@ -359,6 +423,10 @@ func (v *EntityOp) SetOperation(operation int64) {
 	v.data.Operation = &operation
 }
 
+func (v *EntityOp) ClearOperation() {
+	v.data.Operation = nil
+}
+
 func (v *EntityOp) HasEntityId() bool {
 	return v.data.EntityId != nil
 }
@@ -374,6 +442,10 @@ func (v *EntityOp) SetEntityId(entity_id string) {
 	v.data.EntityId = &entity_id
 }
 
+func (v *EntityOp) ClearEntityId() {
+	v.data.EntityId = nil
+}
+
 func (v *EntityOp) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -414,6 +486,10 @@ func (v *ParsedFile) SetFormat(format string) {
 	v.data.Format = &format
 }
 
+func (v *ParsedFile) ClearFormat() {
+	v.data.Format = nil
+}
+
 func (v *ParsedFile) HasEntities() bool {
 	return v.data.Entities != nil
 }
@@ -430,6 +506,10 @@ func (v *ParsedFile) SetEntities(entities []*Entity) {
 	v.data.Entities = &x
 }
 
+func (v *ParsedFile) ClearEntities() {
+	v.data.Entities = nil
+}
+
 func (v *ParsedFile) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -446,6 +526,7 @@ func (v *ParsedFile) UnmarshalJSON(data []byte) error {
 	return json.Unmarshal(data, &v.data)
 }
 
+// Server structs for Stream
 type streamRecvArgsData struct {
 	Count *int32 `cbor:"0,keyasint,omitempty" json:"count,omitempty"`
 }
@@ -496,6 +577,10 @@ func (v *StreamRecvResults) SetData(data []byte) {
 	v.data.Data = &x
 }
 
+func (v *StreamRecvResults) ClearData() {
+	v.data.Data = nil
+}
+
 func (v *StreamRecvResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -611,6 +696,7 @@ func (v StreamClient) Recv(ctx context.Context, count int32) (*StreamClientRecvR
 	return &StreamClientRecvResults{client: v.Client, data: ret}, nil
 }
 
+// Server structs for EntityAccess
 type entityAccessGetArgsData struct {
 	Id *string `cbor:"0,keyasint,omitempty" json:"id,omitempty"`
 }
@@ -660,6 +746,10 @@ func (v *EntityAccessGetResults) SetEntity(entity *Entity) {
 	v.data.Entity = entity
 }
 
+func (v *EntityAccessGetResults) ClearEntity() {
+	v.data.Entity = nil
+}
+
 func (v *EntityAccessGetResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -723,10 +813,18 @@ func (v *EntityAccessPutResults) SetRevision(revision int64) {
 	v.data.Revision = &revision
 }
 
+func (v *EntityAccessPutResults) ClearRevision() {
+	v.data.Revision = nil
+}
+
 func (v *EntityAccessPutResults) SetId(id string) {
 	v.data.Id = &id
 }
 
+func (v *EntityAccessPutResults) ClearId() {
+	v.data.Id = nil
+}
+
 func (v *EntityAccessPutResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -793,10 +891,18 @@ func (v *EntityAccessCreateResults) SetRevision(revision int64) {
 	v.data.Revision = &revision
 }
 
+func (v *EntityAccessCreateResults) ClearRevision() {
+	v.data.Revision = nil
+}
+
 func (v *EntityAccessCreateResults) SetId(id string) {
 	v.data.Id = &id
 }
 
+func (v *EntityAccessCreateResults) ClearId() {
+	v.data.Id = nil
+}
+
 func (v *EntityAccessCreateResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -875,10 +981,18 @@ func (v *EntityAccessReplaceResults) SetRevision(revision int64) {
 	v.data.Revision = &revision
 }
 
+func (v *EntityAccessReplaceResults) ClearRevision() {
+	v.data.Revision = nil
+}
+
 func (v *EntityAccessReplaceResults) SetId(id string) {
 	v.data.Id = &id
 }
 
+func (v *EntityAccessReplaceResults) ClearId() {
+	v.data.Id = nil
+}
+
 func (v *EntityAccessReplaceResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -957,10 +1071,18 @@ func (v *EntityAccessPatchResults) SetRevision(revision int64) {
 	v.data.Revision = &revision
 }
 
+func (v *EntityAccessPatchResults) ClearRevision() {
+	v.data.Revision = nil
+}
+
 func (v *EntityAccessPatchResults) SetId(id string) {
 	v.data.Id = &id
 }
 
+func (v *EntityAccessPatchResults) ClearId() {
+	v.data.Id = nil
+}
+
 func (v *EntityAccessPatchResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -1028,14 +1150,26 @@ func (v *EntityAccessEnsureResults) SetRevision(revision int64) {
 	v.data.Revision = &revision
 }
 
+func (v *EntityAccessEnsureResults) ClearRevision() {
+	v.data.Revision = nil
+}
+
 func (v *EntityAccessEnsureResults) SetId(id string) {
 	v.data.Id = &id
 }
 
+func (v *EntityAccessEnsureResults) ClearId() {
+	v.data.Id = nil
+}
+
 func (v *EntityAccessEnsureResults) SetCreated(created bool) {
 	v.data.Created = &created
 }
 
+func (v *EntityAccessEnsureResults) ClearCreated() {
+	v.data.Created = nil
+}
+
 func (v *EntityAccessEnsureResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -1111,10 +1245,18 @@ func (v *EntityAccessPutSessionResults) SetRevision(revision int64) {
 	v.data.Revision = &revision
 }
 
+func (v *EntityAccessPutSessionResults) ClearRevision() {
+	v.data.Revision = nil
+}
+
 func (v *EntityAccessPutSessionResults) SetId(id string) {
 	v.data.Id = &id
 }
 
+func (v *EntityAccessPutSessionResults) ClearId() {
+	v.data.Id = nil
+}
+
 func (v *EntityAccessPutSessionResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -1180,6 +1322,10 @@ func (v *EntityAccessDeleteResults) SetRevision(revision int64) {
 	v.data.Revision = &revision
 }
 
+func (v *EntityAccessDeleteResults) ClearRevision() {
+	v.data.Revision = nil
+}
+
 func (v *EntityAccessDeleteResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -1382,6 +1528,10 @@ func (v *EntityAccessListResults) SetValues(values []*Entity) {
 	v.data.Values = &x
 }
 
+func (v *EntityAccessListResults) ClearValues() {
+	v.data.Values = nil
+}
+
 func (v *EntityAccessListResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -1459,6 +1609,10 @@ func (v *EntityAccessMakeAttrResults) SetAttr(attr *entity.Attr) {
 	v.data.Attr = attr
 }
 
+func (v *EntityAccessMakeAttrResults) ClearAttr() {
+	v.data.Attr = nil
+}
+
 func (v *EntityAccessMakeAttrResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -1524,6 +1678,10 @@ func (v *EntityAccessLookupKindResults) SetAttr(attr *entity.Attr) {
 	v.data.Attr = attr
 }
 
+func (v *EntityAccessLookupKindResults) ClearAttr() {
+	v.data.Attr = nil
+}
+
 func (v *EntityAccessLookupKindResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -1589,6 +1747,10 @@ func (v *EntityAccessParseResults) SetFile(file *ParsedFile) {
 	v.data.File = file
 }
 
+func (v *EntityAccessParseResults) ClearFile() {
+	v.data.File = nil
+}
+
 func (v *EntityAccessParseResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -1652,6 +1814,10 @@ func (v *EntityAccessFormatResults) SetData(data []byte) {
 	v.data.Data = &x
 }
 
+func (v *EntityAccessFormatResults) ClearData() {
+	v.data.Data = nil
+}
+
 func (v *EntityAccessFormatResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -1729,6 +1895,10 @@ func (v *EntityAccessCreateSessionResults) SetId(id string) {
 	v.data.Id = &id
 }
 
+func (v *EntityAccessCreateSessionResults) ClearId() {
+	v.data.Id = nil
+}
+
 func (v *EntityAccessCreateSessionResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -1913,6 +2083,10 @@ func (v *EntityAccessReindexResults) SetStats(stats []*ReindexStat) {
 	v.data.Stats = &x
 }
 
+func (v *EntityAccessReindexResults) ClearStats() {
+	v.data.Stats = nil
+}
+
 func (v *EntityAccessReindexResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -1979,6 +2153,10 @@ func (v *EntityAccessGetAttributesByTagResults) SetSchemas(schemas []*AttributeS
 	v.data.Schemas = &x
 }
 
+func (v *EntityAccessGetAttributesByTagResults) ClearSchemas() {
+	v.data.Schemas = nil
+}
+
 func (v *EntityAccessGetAttributesByTagResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
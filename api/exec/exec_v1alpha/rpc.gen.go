@@ -34,6 +34,10 @@ func (v *WindowSize) SetHeight(height int32) {
 	v.data.Height = &height
 }
 
+func (v *WindowSize) ClearHeight() {
+	v.data.Height = nil
+}
+
 func (v *WindowSize) HasWidth() bool {
 	return v.data.Width != nil
 }
@@ -49,6 +53,10 @@ func (v *WindowSize) SetWidth(width int32) {
 	v.data.Width = &width
 }
 
+func (v *WindowSize) ClearWidth() {
+	v.data.Width = nil
+}
+
 func (v *WindowSize) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -92,6 +100,10 @@ func (v *ShellOptions) SetTerminal(terminal bool) {
 	v.data.Terminal = &terminal
 }
 
+func (v *ShellOptions) ClearTerminal() {
+	v.data.Terminal = nil
+}
+
 func (v *ShellOptions) HasCommand() bool {
 	return v.data.Command != nil
 }
@@ -108,6 +120,10 @@ func (v *ShellOptions) SetCommand(command []string) {
 	v.data.Command = &x
 }
 
+func (v *ShellOptions) ClearCommand() {
+	v.data.Command = nil
+}
+
 func (v *ShellOptions) HasWinSize() bool {
 	return v.data.WinSize != nil
 }
@@ -120,6 +136,10 @@ func (v *ShellOptions) SetWinSize(win_size *WindowSize) {
 	v.data.WinSize = win_size
 }
 
+func (v *ShellOptions) ClearWinSize() {
+	v.data.WinSize = nil
+}
+
 func (v *ShellOptions) HasEnv() bool {
 	return v.data.Env != nil
 }
@@ -136,6 +156,10 @@ func (v *ShellOptions) SetEnv(env []string) {
 	v.data.Env = &x
 }
 
+func (v *ShellOptions) ClearEnv() {
+	v.data.Env = nil
+}
+
 func (v *ShellOptions) HasPool() bool {
 	return v.data.Pool != nil
 }
@@ -151,6 +175,10 @@ func (v *ShellOptions) SetPool(pool string) {
 	v.data.Pool = &pool
 }
 
+func (v *ShellOptions) ClearPool() {
+	v.data.Pool = nil
+}
+
 func (v *ShellOptions) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -167,6 +195,7 @@ func (v *ShellOptions) UnmarshalJSON(data []byte) error {
 	return json.Unmarshal(data, &v.data)
 }
 
+// Server structs for SandboxExec
 type sandboxExecExecArgsData struct {
 	Category      *string         `cbor:"0,keyasint,omitempty" json:"category,omitempty"`
 	Value         *string         `cbor:"1,keyasint,omitempty" json:"value,omitempty"`
@@ -285,6 +314,10 @@ func (v *SandboxExecExecResults) SetCode(code int32) {
 	v.data.Code = &code
 }
 
+func (v *SandboxExecExecResults) ClearCode() {
+	v.data.Code = nil
+}
+
 func (v *SandboxExecExecResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
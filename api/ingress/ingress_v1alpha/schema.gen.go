@@ -1,6 +1,8 @@
 package ingress_v1alpha
 
 import (
+	"context"
+
 	entity "miren.dev/runtime/pkg/entity"
 	schema "miren.dev/runtime/pkg/entity/schema"
 )
@@ -59,6 +61,14 @@ func (o *HttpRoute) Encode() (attrs []entity.Attr) {
 	return
 }
 
+func (o *HttpRoute) EncodeChanges(old entity.AttrGetter) (ops entity.AttrOps) {
+	desired := entity.New(o.Encode())
+	ops = append(ops, entity.DiffOne(old, desired, HttpRouteAppId)...)
+	ops = append(ops, entity.DiffOne(old, desired, HttpRouteDefaultId)...)
+	ops = append(ops, entity.DiffOne(old, desired, HttpRouteHostId)...)
+	return
+}
+
 func (o *HttpRoute) Empty() bool {
 	if !entity.Empty(o.App) {
 		return false
@@ -72,12 +82,36 @@ func (o *HttpRoute) Empty() bool {
 	return true
 }
 
+func (o *HttpRoute) DeepCopy() *HttpRoute {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	return &out
+}
+
+func (o *HttpRoute) Validate() error {
+	return nil
+}
+
 func (o *HttpRoute) InitSchema(sb *schema.SchemaBuilder) {
 	sb.Ref("app", "dev.miren.ingress/http_route.app", schema.Doc("The application to route to"), schema.Indexed, schema.Tags("dev.miren.app_ref"))
 	sb.Bool("default", "dev.miren.ingress/http_route.default", schema.Doc("Whether this is the default route for routing"), schema.Indexed)
 	sb.String("host", "dev.miren.ingress/http_route.host", schema.Doc("The hostname to match on for the application"), schema.Indexed)
 }
 
+func FindHttpRoutesByApp(ctx context.Context, store entity.IndexLister, app entity.Id) ([]entity.Id, error) {
+	return store.ListIndex(ctx, entity.Ref(HttpRouteAppId, app))
+}
+
+func FindHttpRoutesByDefault(ctx context.Context, store entity.IndexLister, value bool) ([]entity.Id, error) {
+	return store.ListIndex(ctx, entity.Bool(HttpRouteDefaultId, value))
+}
+
+func FindHttpRoutesByHost(ctx context.Context, store entity.IndexLister, host string) ([]entity.Id, error) {
+	return store.ListIndex(ctx, entity.String(HttpRouteHostId, host))
+}
+
 var (
 	KindHttpRoute = entity.Id("dev.miren.ingress/kind.http_route")
 	Schema        = entity.Id("dev.miren.ingress/schema.v1alpha")
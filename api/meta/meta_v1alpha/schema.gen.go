@@ -53,6 +53,13 @@ func (o *Leased) Encode() (attrs []entity.Attr) {
 	return
 }
 
+func (o *Leased) EncodeChanges(old entity.AttrGetter) (ops entity.AttrOps) {
+	desired := entity.New(o.Encode())
+	ops = append(ops, entity.DiffOne(old, desired, LeasedSessionIdId)...)
+	ops = append(ops, entity.DiffOne(old, desired, LeasedTtlId)...)
+	return
+}
+
 func (o *Leased) Empty() bool {
 	if !entity.Empty(o.SessionId) {
 		return false
@@ -63,6 +70,18 @@ func (o *Leased) Empty() bool {
 	return true
 }
 
+func (o *Leased) DeepCopy() *Leased {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	return &out
+}
+
+func (o *Leased) Validate() error {
+	return nil
+}
+
 func (o *Leased) InitSchema(sb *schema.SchemaBuilder) {
 	sb.String("session_id", "dev.miren.meta/leased.session_id", schema.Doc("The unique identifer for the session bound to this entity"))
 	sb.Int64("ttl", "dev.miren.meta/leased.ttl", schema.Doc("The time to live left on the value"))
@@ -116,6 +135,13 @@ func (o *Session) Encode() (attrs []entity.Attr) {
 	return
 }
 
+func (o *Session) EncodeChanges(old entity.AttrGetter) (ops entity.AttrOps) {
+	desired := entity.New(o.Encode())
+	ops = append(ops, entity.DiffOne(old, desired, SessionUniqueIdId)...)
+	ops = append(ops, entity.DiffOne(old, desired, SessionUsageId)...)
+	return
+}
+
 func (o *Session) Empty() bool {
 	if !entity.Empty(o.UniqueId) {
 		return false
@@ -126,6 +152,18 @@ func (o *Session) Empty() bool {
 	return true
 }
 
+func (o *Session) DeepCopy() *Session {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	return &out
+}
+
+func (o *Session) Validate() error {
+	return nil
+}
+
 func (o *Session) InitSchema(sb *schema.SchemaBuilder) {
 	sb.String("unique_id", "dev.miren.meta/session.unique_id", schema.Doc("The identifier for the session"))
 	sb.String("usage", "dev.miren.meta/session.usage", schema.Doc("What the session is being used for"))
@@ -36,6 +36,10 @@ func (v *MetricSnapshot) SetMeasuredAt(measured_at *standard.Timestamp) {
 	v.data.MeasuredAt = measured_at
 }
 
+func (v *MetricSnapshot) ClearMeasuredAt() {
+	v.data.MeasuredAt = nil
+}
+
 func (v *MetricSnapshot) HasTotalCpuTime() bool {
 	return v.data.TotalCpuTime != nil
 }
@@ -51,6 +55,10 @@ func (v *MetricSnapshot) SetTotalCpuTime(total_cpu_time int64) {
 	v.data.TotalCpuTime = &total_cpu_time
 }
 
+func (v *MetricSnapshot) ClearTotalCpuTime() {
+	v.data.TotalCpuTime = nil
+}
+
 func (v *MetricSnapshot) HasKernelCpuTime() bool {
 	return v.data.KernelCpuTime != nil
 }
@@ -66,6 +74,10 @@ func (v *MetricSnapshot) SetKernelCpuTime(kernel_cpu_time int64) {
 	v.data.KernelCpuTime = &kernel_cpu_time
 }
 
+func (v *MetricSnapshot) ClearKernelCpuTime() {
+	v.data.KernelCpuTime = nil
+}
+
 func (v *MetricSnapshot) HasMemoryUsage() bool {
 	return v.data.MemoryUsage != nil
 }
@@ -81,6 +93,10 @@ func (v *MetricSnapshot) SetMemoryUsage(memory_usage int64) {
 	v.data.MemoryUsage = &memory_usage
 }
 
+func (v *MetricSnapshot) ClearMemoryUsage() {
+	v.data.MemoryUsage = nil
+}
+
 func (v *MetricSnapshot) HasMemoryPeak() bool {
 	return v.data.MemoryPeak != nil
 }
@@ -96,6 +112,10 @@ func (v *MetricSnapshot) SetMemoryPeak(memory_peak int64) {
 	v.data.MemoryPeak = &memory_peak
 }
 
+func (v *MetricSnapshot) ClearMemoryPeak() {
+	v.data.MemoryPeak = nil
+}
+
 func (v *MetricSnapshot) HasSwapUsage() bool {
 	return v.data.SwapUsage != nil
 }
@@ -111,6 +131,10 @@ func (v *MetricSnapshot) SetSwapUsage(swap_usage int64) {
 	v.data.SwapUsage = &swap_usage
 }
 
+func (v *MetricSnapshot) ClearSwapUsage() {
+	v.data.SwapUsage = nil
+}
+
 func (v *MetricSnapshot) HasSwapPeak() bool {
 	return v.data.SwapPeak != nil
 }
@@ -126,6 +150,10 @@ func (v *MetricSnapshot) SetSwapPeak(swap_peak int64) {
 	v.data.SwapPeak = &swap_peak
 }
 
+func (v *MetricSnapshot) ClearSwapPeak() {
+	v.data.SwapPeak = nil
+}
+
 func (v *MetricSnapshot) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -166,6 +194,10 @@ func (v *ContainerSnapshot) SetName(name string) {
 	v.data.Name = &name
 }
 
+func (v *ContainerSnapshot) ClearName() {
+	v.data.Name = nil
+}
+
 func (v *ContainerSnapshot) HasMetrics() bool {
 	return v.data.Metrics != nil
 }
@@ -178,6 +210,10 @@ func (v *ContainerSnapshot) SetMetrics(metrics *MetricSnapshot) {
 	v.data.Metrics = metrics
 }
 
+func (v *ContainerSnapshot) ClearMetrics() {
+	v.data.Metrics = nil
+}
+
 func (v *ContainerSnapshot) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -194,6 +230,7 @@ func (v *ContainerSnapshot) UnmarshalJSON(data []byte) error {
 	return json.Unmarshal(data, &v.data)
 }
 
+// Server structs for SandboxMetrics
 type sandboxMetricsSnapshotArgsData struct {
 	Sandbox *string `cbor:"0,keyasint,omitempty" json:"sandbox,omitempty"`
 }
@@ -244,11 +281,19 @@ func (v *SandboxMetricsSnapshotResults) SetMetrics(metrics *MetricSnapshot) {
 	v.data.Metrics = metrics
 }
 
+func (v *SandboxMetricsSnapshotResults) ClearMetrics() {
+	v.data.Metrics = nil
+}
+
 func (v *SandboxMetricsSnapshotResults) SetContainers(containers []*ContainerSnapshot) {
 	x := slices.Clone(containers)
 	v.data.Containers = &x
 }
 
+func (v *SandboxMetricsSnapshotResults) ClearContainers() {
+	v.data.Containers = nil
+}
+
 func (v *SandboxMetricsSnapshotResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -1,6 +1,10 @@
 package network_v1alpha
 
 import (
+	"context"
+	"fmt"
+	"slices"
+
 	entity "miren.dev/runtime/pkg/entity"
 	schema "miren.dev/runtime/pkg/entity/schema"
 	types "miren.dev/runtime/pkg/entity/types"
@@ -58,6 +62,13 @@ func (o *Endpoints) Encode() (attrs []entity.Attr) {
 	return
 }
 
+func (o *Endpoints) EncodeChanges(old entity.AttrGetter) (ops entity.AttrOps) {
+	desired := entity.New(o.Encode())
+	ops = append(ops, entity.DiffMany(old, desired, EndpointsEndpointId)...)
+	ops = append(ops, entity.DiffOne(old, desired, EndpointsServiceId)...)
+	return
+}
+
 func (o *Endpoints) Empty() bool {
 	if len(o.Endpoint) != 0 {
 		return false
@@ -68,12 +79,39 @@ func (o *Endpoints) Empty() bool {
 	return true
 }
 
+func (o *Endpoints) DeepCopy() *Endpoints {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	if o.Endpoint != nil {
+		out.Endpoint = make([]Endpoint, len(o.Endpoint))
+		for i := range o.Endpoint {
+			out.Endpoint[i] = *o.Endpoint[i].DeepCopy()
+		}
+	}
+	return &out
+}
+
+func (o *Endpoints) Validate() error {
+	for i, v := range o.Endpoint {
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("%s[%d]: %w", "endpoint", i, err)
+		}
+	}
+	return nil
+}
+
 func (o *Endpoints) InitSchema(sb *schema.SchemaBuilder) {
 	sb.Component("endpoint", "dev.miren.network/endpoints.endpoint", schema.Doc("The endpoint configuration, per endpoint"), schema.Many)
 	(&Endpoint{}).InitSchema(sb.Builder("endpoints.endpoint"))
 	sb.Ref("service", "dev.miren.network/endpoints.service", schema.Doc("The service that uses these endpoints"), schema.Indexed)
 }
 
+func FindEndpointsByService(ctx context.Context, store entity.IndexLister, service entity.Id) ([]entity.Id, error) {
+	return store.ListIndex(ctx, entity.Ref(EndpointsServiceId, service))
+}
+
 const (
 	EndpointIpId   = entity.Id("dev.miren.network/endpoint.ip")
 	EndpointPortId = entity.Id("dev.miren.network/endpoint.port")
@@ -113,26 +151,55 @@ func (o *Endpoint) Empty() bool {
 	return true
 }
 
+func (o *Endpoint) DeepCopy() *Endpoint {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	return &out
+}
+
+func (o *Endpoint) Validate() error {
+	return nil
+}
+
 func (o *Endpoint) InitSchema(sb *schema.SchemaBuilder) {
 	sb.String("ip", "dev.miren.network/endpoint.ip", schema.Doc("The IP of the endpoint"))
 	sb.Int64("port", "dev.miren.network/endpoint.port", schema.Doc("The port number"))
 }
 
 const (
-	ServiceIpId    = entity.Id("dev.miren.network/service.ip")
-	ServiceMatchId = entity.Id("dev.miren.network/service.match")
-	ServicePortId  = entity.Id("dev.miren.network/service.port")
+	ServiceForwardingId    = entity.Id("dev.miren.network/service.forwarding")
+	ServiceForwardingNatId = entity.Id("dev.miren.network/forwarding.nat")
+	ServiceForwardingDsrId = entity.Id("dev.miren.network/forwarding.dsr")
+	ServiceIpId            = entity.Id("dev.miren.network/service.ip")
+	ServiceMatchId         = entity.Id("dev.miren.network/service.match")
+	ServicePortId          = entity.Id("dev.miren.network/service.port")
 )
 
 type Service struct {
-	ID    entity.Id    `json:"id"`
-	Ip    []string     `cbor:"ip,omitempty" json:"ip,omitempty"`
-	Match types.Labels `cbor:"match,omitempty" json:"match,omitempty"`
-	Port  []Port       `cbor:"port,omitempty" json:"port,omitempty"`
+	ID         entity.Id         `json:"id"`
+	Forwarding ServiceForwarding `cbor:"forwarding,omitempty" json:"forwarding,omitempty"`
+	Ip         []string          `cbor:"ip,omitempty" json:"ip,omitempty"`
+	Match      types.Labels      `cbor:"match,omitempty" json:"match,omitempty"`
+	Port       []Port            `cbor:"port,omitempty" json:"port,omitempty"`
 }
 
+type ServiceForwarding string
+
+const (
+	NAT ServiceForwarding = "forwarding.nat"
+	DSR ServiceForwarding = "forwarding.dsr"
+)
+
+var serviceforwardingFromId = map[entity.Id]ServiceForwarding{ServiceForwardingNatId: NAT, ServiceForwardingDsrId: DSR}
+var serviceforwardingToId = map[ServiceForwarding]entity.Id{NAT: ServiceForwardingNatId, DSR: ServiceForwardingDsrId}
+
 func (o *Service) Decode(e entity.AttrGetter) {
 	o.ID = entity.MustGet(e, entity.DBId).Value.Id()
+	if a, ok := e.Get(ServiceForwardingId); ok && a.Value.Kind() == entity.KindId {
+		o.Forwarding = serviceforwardingFromId[a.Value.Id()]
+	}
 	for _, a := range e.GetAll(ServiceIpId) {
 		if a.Value.Kind() == entity.KindString {
 			o.Ip = append(o.Ip, a.Value.String())
@@ -169,6 +236,9 @@ func (o *Service) EntityId() entity.Id {
 }
 
 func (o *Service) Encode() (attrs []entity.Attr) {
+	if a, ok := serviceforwardingToId[o.Forwarding]; ok {
+		attrs = append(attrs, entity.Ref(ServiceForwardingId, a))
+	}
 	for _, v := range o.Ip {
 		attrs = append(attrs, entity.String(ServiceIpId, v))
 	}
@@ -182,7 +252,19 @@ func (o *Service) Encode() (attrs []entity.Attr) {
 	return
 }
 
+func (o *Service) EncodeChanges(old entity.AttrGetter) (ops entity.AttrOps) {
+	desired := entity.New(o.Encode())
+	ops = append(ops, entity.DiffOne(old, desired, ServiceForwardingId)...)
+	ops = append(ops, entity.DiffMany(old, desired, ServiceIpId)...)
+	ops = append(ops, entity.DiffMany(old, desired, ServiceMatchId)...)
+	ops = append(ops, entity.DiffMany(old, desired, ServicePortId)...)
+	return
+}
+
 func (o *Service) Empty() bool {
+	if o.Forwarding != "" {
+		return false
+	}
 	if len(o.Ip) != 0 {
 		return false
 	}
@@ -195,7 +277,40 @@ func (o *Service) Empty() bool {
 	return true
 }
 
+func (o *Service) DeepCopy() *Service {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	out.Ip = slices.Clone(o.Ip)
+	out.Match = slices.Clone(o.Match)
+	if o.Port != nil {
+		out.Port = make([]Port, len(o.Port))
+		for i := range o.Port {
+			out.Port[i] = *o.Port[i].DeepCopy()
+		}
+	}
+	return &out
+}
+
+func (o *Service) Validate() error {
+	if o.Forwarding != "" {
+		if _, ok := serviceforwardingToId[o.Forwarding]; !ok {
+			return fmt.Errorf("%w: %s: %q", entity.ErrInvalidChoice, "forwarding", o.Forwarding)
+		}
+	}
+	for i, v := range o.Port {
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("%s[%d]: %w", "port", i, err)
+		}
+	}
+	return nil
+}
+
 func (o *Service) InitSchema(sb *schema.SchemaBuilder) {
+	sb.Singleton("dev.miren.network/forwarding.nat")
+	sb.Singleton("dev.miren.network/forwarding.dsr")
+	sb.Ref("forwarding", "dev.miren.network/service.forwarding", schema.Doc("How traffic is forwarded to endpoints. nat (the default) rewrites the destination to the endpoint; dsr routes packets to the endpoint unmodified so it replies to the client directly"), schema.Choices(ServiceForwardingNatId, ServiceForwardingDsrId))
 	sb.String("ip", "dev.miren.network/service.ip", schema.Doc("The IP allocated to the service"), schema.Many)
 	sb.Label("match", "dev.miren.network/service.match", schema.Doc("A label to match against a sandbox"), schema.Many)
 	sb.Component("port", "dev.miren.network/service.port", schema.Doc("A network port the service exposes"), schema.Many)
@@ -295,6 +410,26 @@ func (o *Port) Empty() bool {
 	return true
 }
 
+func (o *Port) DeepCopy() *Port {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	return &out
+}
+
+func (o *Port) Validate() error {
+	if entity.Empty(o.Name) {
+		return fmt.Errorf("%w: %s", entity.ErrRequiredAttr, "name")
+	}
+	if o.Protocol != "" {
+		if _, ok := PortprotocolToId[o.Protocol]; !ok {
+			return fmt.Errorf("%w: %s: %q", entity.ErrInvalidChoice, "protocol", o.Protocol)
+		}
+	}
+	return nil
+}
+
 func (o *Port) InitSchema(sb *schema.SchemaBuilder) {
 	sb.String("name", "dev.miren.network/port.name", schema.Doc("Name of the port for reference"), schema.Required)
 	sb.Int64("node_port", "dev.miren.network/port.node_port", schema.Doc("The port number that should be forwarded from the node to the container"))
@@ -317,5 +452,5 @@ func init() {
 		(&Endpoints{}).InitSchema(sb)
 		(&Service{}).InitSchema(sb)
 	})
-	schema.RegisterEncodedSchema("dev.miren.network", "v1alpha", []byte("\x1f\x8b\b\x00\x00\x00\x00\x00\x00\xff\x8cT\xddҔ0\f}\x0e\xc7\xdfQ\xaf\xeb\xf8D;\xdd&@\x06\xfac[p\xf7Vg|\x90\xef[}C\xbdv\x1a\u0602BYn\x18\xda朤礹\x81\x91\x1a\xbf\x00\x0eB\x93G#\fƯַؒ\x81\xf0ty\xb3:\xf9\x94ND@?\x90\xc2_\f\xbf\xbcXGM\x01#ϟ\n\xac\x96d\xd6y\xaa\x8a\xb0\x83\xf0\xfdv&\xb8\xbc*\xd2\br\xa0\xa5\xb9\xfe\xe6|gr\x10\xaf\x0e\xab\x10=\x99\x9a\xb1o\xcbX-\xa3j\x16p\x1c7\x12\x03v\xf2\x8c\xdd\xcfD\xb0q\xd3;\x81\xb3>.\xf0\xc0\xeb\x04'e\xb5\xb3\x06M\x9c\xff&I\xd6tbIwP\x97\x1fϩ\xb4\x97\xeb\xd2\x12\x87\xe0b\xe6\xcfB\x12\x86\xbd+\xc1,\xe0\x89\xef\x90`4/\x13\x81\"\x13w\x93f \xfc\x83)\x990b\xbc\x8dVَqM^%,\xa0\xe9u\x9b>\xa7Av=\x86'\x15\x95\xdbr\xe3\x0e\x13Q9\xd5\xc3~L\x0f\x8eo\xf1\xbePQ\x94\xbe\xc68\xab\xd0.7\x0e\xe9\xc0\xc5gݗ\xe2\xd7\x03\xfa@\xd6\xd4\xc3gٹFvΓ\x96\xfezJ\x9e\xb3j\xbb\x11\xf5\xd4'[\xfe\xf1\xdbC\x03Β\x89aj\xb5\x8d\ns\xc8\xc1>\xfb\xc6O\xe0\xe3\x0eQκx\bM\xde{\xf0\x18\xd6\xc4bM|\xb4Tv\xe5u\xb9\xd44+6\xa6\xc4s\xa1A3\xac\xd0ػ^e\x05\x98\xfdÞ~\x93\xab\x9c\xe1n1'9\x13\xec\xe6\xa0\xcc\xf1\x7fX\x1b\x1a\xeb\xe3i\x1cչm\x1e\x8c\xec\x99\xeeq\x83\xfd\x05\x00\x00\xff\xff\x01\x00\x00\xff\xff\xa7\xb2\x19\xcc\x1e\x06\x00\x00"))
+	schema.RegisterEncodedSchema("dev.miren.network", "v1alpha", []byte("\x1f\x8b\b\x00\x00\x00\x00\x00\x00\xff\x8cUю\xd30\x10\xfc\x0e\x04\x1c\bx\xf6\x89/\xaa\\\xef&Y\x92\xd8\xc6vs\xed+B|H\xef\x8e?\x84g\xb4\xdb։H\x9c\xe6%\x8a\xe3\x99\xd9\xe9\xce\xda}\x01\xab{\xfc\x0e8\xa8\x9e\x02Ze1=\xb9\xd0bK\x16\xe2\xf9\xf80\xdby\xe4\x1d\x151\fd\xf0\xb7Џo\xe6\xa8+\xe0\xa2\xf3\xb7\x02\xd7k\xb2\xf3:UE\xd8A\xfc\xf9\xb2'8~)ʨʅ'\x1d\x80l-\x15\xbfM\xd6\xe9\xe4\x11\xd0\x1e\xfa\x96\x1f\xbbAw\a\x8cg\x031\x1c?\xce\x05G\xa2\x82\x18\x8c\xd5\xe9\x0e\xca\xea$\xe6ޕ͑\x87^\xdb\xd3\x1f\xb1\xb6'/\x96\xaa\x98\x02\xd9Z\xb8\x1f\xca\xdc^'\xd3L\xe8x\xf9\xc0\n\xd8\xe9=v\xaf,\xf0P\x16\xf0.\xa4\t\x1fd\xcdt2\xae\xf7\u03a2M\xe3\xdb5\xaf\xb9\xdc-P\xc5\xf4\x8d\xa1\xfdzfkogۏ\xac\xa1\xb8\x10\x8c\x8fIK\x84\xb6\xd0\xf4\v\xcd\x01\xee\xf8M\xb84.Y\xc0\x90M\xabE3ql\x03sJ!0H\xf9\xe0\x923\xae\x13^\x93W\x85\xb1J\xc6/\xa5q\xa3\xa9d\xbc9\xc0:\xe6\x00^~ŧ\x05\f;J:Ԙ\xc6.\xb4\xd3\x0f\x9b\xfa\xc0\xa0\xb1\xef\xd3y\xac\a\f\x91\x9c\xad\x87\xaf\xba\xf3\x8d\xee|\xa0^\x87ӎ3\x97\xae\xad\"\xea\xeb\x9c,\x1d\x1aVPh\xc1;\xb2)^Gm\xc1a\x86l\x9c\xb3\x1f\xaf\x85\xcb!\v媓\x83\xd0\xe4ow\x0e\xc3\\Xͅ\xb7Z\x95Tޗ\xad*\xf2K\xb7\xc4s\xe1\x96ȴ\xc2`\xaff\x95; \xea\x9f\xcb\xea\xf1v\xfa\xa5\xc2-b)\xb2'X\xadAY\xe3\x7fX\x1b\x1b\x17\x92\x80\xe29\x8f͝\xff\x93Q\xee\xfe\x80\xfd\x03\x00\x00\xff\xff\x03\x00hαt\xbb\x06\x00\x00"))
 }
@@ -31,6 +31,10 @@ func (v *CpuUsage) SetStart(start *standard.Timestamp) {
 	v.data.Start = start
 }
 
+func (v *CpuUsage) ClearStart() {
+	v.data.Start = nil
+}
+
 func (v *CpuUsage) HasCores() bool {
 	return v.data.Cores != nil
 }
@@ -46,6 +50,10 @@ func (v *CpuUsage) SetCores(cores float64) {
 	v.data.Cores = &cores
 }
 
+func (v *CpuUsage) ClearCores() {
+	v.data.Cores = nil
+}
+
 func (v *CpuUsage) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -83,6 +91,10 @@ func (v *MemoryUsage) SetTimestamp(timestamp *standard.Timestamp) {
 	v.data.Timestamp = timestamp
 }
 
+func (v *MemoryUsage) ClearTimestamp() {
+	v.data.Timestamp = nil
+}
+
 func (v *MemoryUsage) HasBytes() bool {
 	return v.data.Bytes != nil
 }
@@ -98,6 +110,10 @@ func (v *MemoryUsage) SetBytes(bytes int64) {
 	v.data.Bytes = &bytes
 }
 
+func (v *MemoryUsage) ClearBytes() {
+	v.data.Bytes = nil
+}
+
 func (v *MemoryUsage) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -140,6 +156,10 @@ func (v *PoolStatus) SetName(name string) {
 	v.data.Name = &name
 }
 
+func (v *PoolStatus) ClearName() {
+	v.data.Name = nil
+}
+
 func (v *PoolStatus) HasWindows() bool {
 	return v.data.Windows != nil
 }
@@ -156,6 +176,10 @@ func (v *PoolStatus) SetWindows(windows []*WindowStatus) {
 	v.data.Windows = &x
 }
 
+func (v *PoolStatus) ClearWindows() {
+	v.data.Windows = nil
+}
+
 func (v *PoolStatus) HasIdle() bool {
 	return v.data.Idle != nil
 }
@@ -171,6 +195,10 @@ func (v *PoolStatus) SetIdle(idle int32) {
 	v.data.Idle = &idle
 }
 
+func (v *PoolStatus) ClearIdle() {
+	v.data.Idle = nil
+}
+
 func (v *PoolStatus) HasIdleUsage() bool {
 	return v.data.IdleUsage != nil
 }
@@ -186,6 +214,10 @@ func (v *PoolStatus) SetIdleUsage(idleUsage int64) {
 	v.data.IdleUsage = &idleUsage
 }
 
+func (v *PoolStatus) ClearIdleUsage() {
+	v.data.IdleUsage = nil
+}
+
 func (v *PoolStatus) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -227,6 +259,10 @@ func (v *WindowStatus) SetVersion(version string) {
 	v.data.Version = &version
 }
 
+func (v *WindowStatus) ClearVersion() {
+	v.data.Version = nil
+}
+
 func (v *WindowStatus) HasLeases() bool {
 	return v.data.Leases != nil
 }
@@ -242,6 +278,10 @@ func (v *WindowStatus) SetLeases(leases int32) {
 	v.data.Leases = &leases
 }
 
+func (v *WindowStatus) ClearLeases() {
+	v.data.Leases = nil
+}
+
 func (v *WindowStatus) HasUsage() bool {
 	return v.data.Usage != nil
 }
@@ -257,6 +297,10 @@ func (v *WindowStatus) SetUsage(usage int64) {
 	v.data.Usage = &usage
 }
 
+func (v *WindowStatus) ClearUsage() {
+	v.data.Usage = nil
+}
+
 func (v *WindowStatus) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -305,6 +349,10 @@ func (v *ApplicationStatus) SetName(name string) {
 	v.data.Name = &name
 }
 
+func (v *ApplicationStatus) ClearName() {
+	v.data.Name = nil
+}
+
 func (v *ApplicationStatus) HasPools() bool {
 	return v.data.Pools != nil
 }
@@ -321,6 +369,10 @@ func (v *ApplicationStatus) SetPools(pools []*PoolStatus) {
 	v.data.Pools = &x
 }
 
+func (v *ApplicationStatus) ClearPools() {
+	v.data.Pools = nil
+}
+
 func (v *ApplicationStatus) HasLastMinCPU() bool {
 	return v.data.LastMinCPU != nil
 }
@@ -336,6 +388,10 @@ func (v *ApplicationStatus) SetLastMinCPU(lastMinCPU float64) {
 	v.data.LastMinCPU = &lastMinCPU
 }
 
+func (v *ApplicationStatus) ClearLastMinCPU() {
+	v.data.LastMinCPU = nil
+}
+
 func (v *ApplicationStatus) HasLastHourCPU() bool {
 	return v.data.LastHourCPU != nil
 }
@@ -351,6 +407,10 @@ func (v *ApplicationStatus) SetLastHourCPU(lastHourCPU float64) {
 	v.data.LastHourCPU = &lastHourCPU
 }
 
+func (v *ApplicationStatus) ClearLastHourCPU() {
+	v.data.LastHourCPU = nil
+}
+
 func (v *ApplicationStatus) HasLastDayCPU() bool {
 	return v.data.LastDayCPU != nil
 }
@@ -366,6 +426,10 @@ func (v *ApplicationStatus) SetLastDayCPU(lastDayCPU float64) {
 	v.data.LastDayCPU = &lastDayCPU
 }
 
+func (v *ApplicationStatus) ClearLastDayCPU() {
+	v.data.LastDayCPU = nil
+}
+
 func (v *ApplicationStatus) HasCpuOverHour() bool {
 	return v.data.CpuOverHour != nil
 }
@@ -382,6 +446,10 @@ func (v *ApplicationStatus) SetCpuOverHour(cpuOverHour []*CpuUsage) {
 	v.data.CpuOverHour = &x
 }
 
+func (v *ApplicationStatus) ClearCpuOverHour() {
+	v.data.CpuOverHour = nil
+}
+
 func (v *ApplicationStatus) HasMemoryOverHour() bool {
 	return v.data.MemoryOverHour != nil
 }
@@ -398,6 +466,10 @@ func (v *ApplicationStatus) SetMemoryOverHour(memoryOverHour []*MemoryUsage) {
 	v.data.MemoryOverHour = &x
 }
 
+func (v *ApplicationStatus) ClearMemoryOverHour() {
+	v.data.MemoryOverHour = nil
+}
+
 func (v *ApplicationStatus) HasActiveVersion() bool {
 	return v.data.ActiveVersion != nil
 }
@@ -413,6 +485,10 @@ func (v *ApplicationStatus) SetActiveVersion(activeVersion string) {
 	v.data.ActiveVersion = &activeVersion
 }
 
+func (v *ApplicationStatus) ClearActiveVersion() {
+	v.data.ActiveVersion = nil
+}
+
 func (v *ApplicationStatus) HasLastDeploy() bool {
 	return v.data.LastDeploy != nil
 }
@@ -425,6 +501,10 @@ func (v *ApplicationStatus) SetLastDeploy(lastDeploy *standard.Timestamp) {
 	v.data.LastDeploy = lastDeploy
 }
 
+func (v *ApplicationStatus) ClearLastDeploy() {
+	v.data.LastDeploy = nil
+}
+
 func (v *ApplicationStatus) HasAddons() bool {
 	return v.data.Addons != nil
 }
@@ -441,6 +521,10 @@ func (v *ApplicationStatus) SetAddons(addons []*AddonInstance) {
 	v.data.Addons = &x
 }
 
+func (v *ApplicationStatus) ClearAddons() {
+	v.data.Addons = nil
+}
+
 func (v *ApplicationStatus) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -479,6 +563,10 @@ func (v *LogEntry) SetTimestamp(timestamp *standard.Timestamp) {
 	v.data.Timestamp = timestamp
 }
 
+func (v *LogEntry) ClearTimestamp() {
+	v.data.Timestamp = nil
+}
+
 func (v *LogEntry) HasLine() bool {
 	return v.data.Line != nil
 }
@@ -494,6 +582,10 @@ func (v *LogEntry) SetLine(line string) {
 	v.data.Line = &line
 }
 
+func (v *LogEntry) ClearLine() {
+	v.data.Line = nil
+}
+
 func (v *LogEntry) HasStream() bool {
 	return v.data.Stream != nil
 }
@@ -509,6 +601,10 @@ func (v *LogEntry) SetStream(stream string) {
 	v.data.Stream = &stream
 }
 
+func (v *LogEntry) ClearStream() {
+	v.data.Stream = nil
+}
+
 func (v *LogEntry) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -548,6 +644,10 @@ func (v *UserInfo) SetSubject(subject string) {
 	v.data.Subject = &subject
 }
 
+func (v *UserInfo) ClearSubject() {
+	v.data.Subject = nil
+}
+
 func (v *UserInfo) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -589,6 +689,10 @@ func (v *DiskConfig) SetId(id string) {
 	v.data.Id = &id
 }
 
+func (v *DiskConfig) ClearId() {
+	v.data.Id = nil
+}
+
 func (v *DiskConfig) HasName() bool {
 	return v.data.Name != nil
 }
@@ -604,6 +708,10 @@ func (v *DiskConfig) SetName(name string) {
 	v.data.Name = &name
 }
 
+func (v *DiskConfig) ClearName() {
+	v.data.Name = nil
+}
+
 func (v *DiskConfig) HasCapacity() bool {
 	return v.data.Capacity != nil
 }
@@ -619,6 +727,10 @@ func (v *DiskConfig) SetCapacity(capacity int64) {
 	v.data.Capacity = &capacity
 }
 
+func (v *DiskConfig) ClearCapacity() {
+	v.data.Capacity = nil
+}
+
 func (v *DiskConfig) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -661,6 +773,10 @@ func (v *AddonInstance) SetId(id string) {
 	v.data.Id = &id
 }
 
+func (v *AddonInstance) ClearId() {
+	v.data.Id = nil
+}
+
 func (v *AddonInstance) HasName() bool {
 	return v.data.Name != nil
 }
@@ -676,6 +792,10 @@ func (v *AddonInstance) SetName(name string) {
 	v.data.Name = &name
 }
 
+func (v *AddonInstance) ClearName() {
+	v.data.Name = nil
+}
+
 func (v *AddonInstance) HasAddon() bool {
 	return v.data.Addon != nil
 }
@@ -691,6 +811,10 @@ func (v *AddonInstance) SetAddon(addon string) {
 	v.data.Addon = &addon
 }
 
+func (v *AddonInstance) ClearAddon() {
+	v.data.Addon = nil
+}
+
 func (v *AddonInstance) HasPlan() bool {
 	return v.data.Plan != nil
 }
@@ -706,6 +830,10 @@ func (v *AddonInstance) SetPlan(plan string) {
 	v.data.Plan = &plan
 }
 
+func (v *AddonInstance) ClearPlan() {
+	v.data.Plan = nil
+}
+
 func (v *AddonInstance) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -722,6 +850,7 @@ func (v *AddonInstance) UnmarshalJSON(data []byte) error {
 	return json.Unmarshal(data, &v.data)
 }
 
+// Server structs for UserQuery
 type userQueryWhoAmIArgsData struct{}
 
 type UserQueryWhoAmIArgs struct {
@@ -758,6 +887,10 @@ func (v *UserQueryWhoAmIResults) SetInfo(info *UserInfo) {
 	v.data.Info = info
 }
 
+func (v *UserQueryWhoAmIResults) ClearInfo() {
+	v.data.Info = nil
+}
+
 func (v *UserQueryWhoAmIResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -869,6 +1002,7 @@ func (v UserQueryClient) WhoAmI(ctx context.Context) (*UserQueryClientWhoAmIResu
 	return &UserQueryClientWhoAmIResults{client: v.Client, data: ret}, nil
 }
 
+// Server structs for AppInfo
 type appInfoAppInfoArgsData struct {
 	Application *string `cbor:"0,keyasint,omitempty" json:"application,omitempty"`
 }
@@ -918,6 +1052,10 @@ func (v *AppInfoAppInfoResults) SetStatus(status *ApplicationStatus) {
 	v.data.Status = status
 }
 
+func (v *AppInfoAppInfoResults) ClearStatus() {
+	v.data.Status = nil
+}
+
 func (v *AppInfoAppInfoResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -1030,6 +1168,7 @@ func (v AppInfoClient) AppInfo(ctx context.Context, application string) (*AppInf
 	return &AppInfoClientAppInfoResults{client: v.Client, data: ret}, nil
 }
 
+// Server structs for Logs
 type logsAppLogsArgsData struct {
 	Application *string             `cbor:"0,keyasint,omitempty" json:"application,omitempty"`
 	From        *standard.Timestamp `cbor:"1,keyasint,omitempty" json:"from,omitempty"`
@@ -1101,6 +1240,10 @@ func (v *LogsAppLogsResults) SetLogs(logs []*LogEntry) {
 	v.data.Logs = &x
 }
 
+func (v *LogsAppLogsResults) ClearLogs() {
+	v.data.Logs = nil
+}
+
 func (v *LogsAppLogsResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -1218,6 +1361,7 @@ func (v LogsClient) AppLogs(ctx context.Context, application string, from *stand
 	return &LogsClientAppLogsResults{client: v.Client, data: ret}, nil
 }
 
+// Server structs for Disks
 type disksNewArgsData struct {
 	Name     *string `cbor:"0,keyasint,omitempty" json:"name,omitempty"`
 	Capacity *int64  `cbor:"1,keyasint,omitempty" json:"capacity,omitempty"`
@@ -1279,6 +1423,10 @@ func (v *DisksNewResults) SetId(id string) {
 	v.data.Id = &id
 }
 
+func (v *DisksNewResults) ClearId() {
+	v.data.Id = nil
+}
+
 func (v *DisksNewResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -1344,6 +1492,10 @@ func (v *DisksGetByIdResults) SetConfig(config *DiskConfig) {
 	v.data.Config = config
 }
 
+func (v *DisksGetByIdResults) ClearConfig() {
+	v.data.Config = nil
+}
+
 func (v *DisksGetByIdResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -1409,6 +1561,10 @@ func (v *DisksGetByNameResults) SetConfig(config *DiskConfig) {
 	v.data.Config = config
 }
 
+func (v *DisksGetByNameResults) ClearConfig() {
+	v.data.Config = nil
+}
+
 func (v *DisksGetByNameResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -1462,6 +1618,10 @@ func (v *DisksListResults) SetDisks(disks []*DiskConfig) {
 	v.data.Disks = &x
 }
 
+func (v *DisksListResults) ClearDisks() {
+	v.data.Disks = nil
+}
+
 func (v *DisksListResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -1895,6 +2055,7 @@ func (v DisksClient) Delete(ctx context.Context, id string) (*DisksClientDeleteR
 	return &DisksClientDeleteResults{client: v.Client, data: ret}, nil
 }
 
+// Server structs for Addons
 type addonsCreateInstanceArgsData struct {
 	Name  *string `cbor:"0,keyasint,omitempty" json:"name,omitempty"`
 	Addon *string `cbor:"1,keyasint,omitempty" json:"addon,omitempty"`
@@ -1980,6 +2141,10 @@ func (v *AddonsCreateInstanceResults) SetId(id string) {
 	v.data.Id = &id
 }
 
+func (v *AddonsCreateInstanceResults) ClearId() {
+	v.data.Id = nil
+}
+
 func (v *AddonsCreateInstanceResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -2046,6 +2211,10 @@ func (v *AddonsListInstancesResults) SetAddons(addons []*AddonInstance) {
 	v.data.Addons = &x
 }
 
+func (v *AddonsListInstancesResults) ClearAddons() {
+	v.data.Addons = nil
+}
+
 func (v *AddonsListInstancesResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -1,6 +1,8 @@
 package storage_v1alpha
 
 import (
+	"context"
+	"fmt"
 	"time"
 
 	entity "miren.dev/runtime/pkg/entity"
@@ -125,6 +127,18 @@ func (o *Disk) Encode() (attrs []entity.Attr) {
 	return
 }
 
+func (o *Disk) EncodeChanges(old entity.AttrGetter) (ops entity.AttrOps) {
+	desired := entity.New(o.Encode())
+	ops = append(ops, entity.DiffOne(old, desired, DiskCreatedById)...)
+	ops = append(ops, entity.DiffOne(old, desired, DiskFilesystemId)...)
+	ops = append(ops, entity.DiffOne(old, desired, DiskLsvdVolumeIdId)...)
+	ops = append(ops, entity.DiffOne(old, desired, DiskNameId)...)
+	ops = append(ops, entity.DiffOne(old, desired, DiskRemoteOnlyId)...)
+	ops = append(ops, entity.DiffOne(old, desired, DiskSizeGbId)...)
+	ops = append(ops, entity.DiffOne(old, desired, DiskStatusId)...)
+	return
+}
+
 func (o *Disk) Empty() bool {
 	if !entity.Empty(o.CreatedBy) {
 		return false
@@ -150,6 +164,31 @@ func (o *Disk) Empty() bool {
 	return true
 }
 
+func (o *Disk) DeepCopy() *Disk {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	return &out
+}
+
+func (o *Disk) Validate() error {
+	if o.Filesystem != "" {
+		if _, ok := diskfilesystemToId[o.Filesystem]; !ok {
+			return fmt.Errorf("%w: %s: %q", entity.ErrInvalidChoice, "filesystem", o.Filesystem)
+		}
+	}
+	if entity.Empty(o.Name) {
+		return fmt.Errorf("%w: %s", entity.ErrRequiredAttr, "name")
+	}
+	if o.Status != "" {
+		if _, ok := diskstatusToId[o.Status]; !ok {
+			return fmt.Errorf("%w: %s: %q", entity.ErrInvalidChoice, "status", o.Status)
+		}
+	}
+	return nil
+}
+
 func (o *Disk) InitSchema(sb *schema.SchemaBuilder) {
 	sb.Ref("created_by", "dev.miren.storage/disk.created_by", schema.Doc("Application that created this disk (for tracking purposes)"), schema.Indexed, schema.Tags("dev.miren.app_ref"))
 	sb.Singleton("dev.miren.storage/filesystem.ext4")
@@ -169,6 +208,26 @@ func (o *Disk) InitSchema(sb *schema.SchemaBuilder) {
 	sb.Ref("status", "dev.miren.storage/disk.status", schema.Doc("Current state of the disk"), schema.Indexed, schema.Choices(DiskStatusProvisioningId, DiskStatusProvisionedId, DiskStatusAttachedId, DiskStatusDetachedId, DiskStatusDeletingId, DiskStatusErrorId))
 }
 
+func FindDisksByCreatedBy(ctx context.Context, store entity.IndexLister, createdBy entity.Id) ([]entity.Id, error) {
+	return store.ListIndex(ctx, entity.Ref(DiskCreatedById, createdBy))
+}
+
+func FindDisksByLsvdVolumeId(ctx context.Context, store entity.IndexLister, lsvdVolumeId string) ([]entity.Id, error) {
+	return store.ListIndex(ctx, entity.String(DiskLsvdVolumeIdId, lsvdVolumeId))
+}
+
+func FindDisksByName(ctx context.Context, store entity.IndexLister, name string) ([]entity.Id, error) {
+	return store.ListIndex(ctx, entity.String(DiskNameId, name))
+}
+
+func FindDisksByStatus(ctx context.Context, store entity.IndexLister, status DiskStatus) ([]entity.Id, error) {
+	id, ok := diskstatusToId[status]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s: %q", entity.ErrInvalidChoice, "status", status)
+	}
+	return store.ListIndex(ctx, entity.Ref(DiskStatusId, id))
+}
+
 const (
 	DiskLeaseAcquiredAtId     = entity.Id("dev.miren.storage/disk_lease.acquired_at")
 	DiskLeaseAppIdId          = entity.Id("dev.miren.storage/disk_lease.app_id")
@@ -281,6 +340,19 @@ func (o *DiskLease) Encode() (attrs []entity.Attr) {
 	return
 }
 
+func (o *DiskLease) EncodeChanges(old entity.AttrGetter) (ops entity.AttrOps) {
+	desired := entity.New(o.Encode())
+	ops = append(ops, entity.DiffOne(old, desired, DiskLeaseAcquiredAtId)...)
+	ops = append(ops, entity.DiffOne(old, desired, DiskLeaseAppIdId)...)
+	ops = append(ops, entity.DiffOne(old, desired, DiskLeaseDiskIdId)...)
+	ops = append(ops, entity.DiffOne(old, desired, DiskLeaseErrorMessageId)...)
+	ops = append(ops, entity.DiffOne(old, desired, DiskLeaseMountId)...)
+	ops = append(ops, entity.DiffOne(old, desired, DiskLeaseNodeIdId)...)
+	ops = append(ops, entity.DiffOne(old, desired, DiskLeaseSandboxIdId)...)
+	ops = append(ops, entity.DiffOne(old, desired, DiskLeaseStatusId)...)
+	return
+}
+
 func (o *DiskLease) Empty() bool {
 	if !entity.Empty(o.AcquiredAt) {
 		return false
@@ -309,6 +381,35 @@ func (o *DiskLease) Empty() bool {
 	return true
 }
 
+func (o *DiskLease) DeepCopy() *DiskLease {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	out.Mount = *o.Mount.DeepCopy()
+	return &out
+}
+
+func (o *DiskLease) Validate() error {
+	if entity.Empty(o.DiskId) {
+		return fmt.Errorf("%w: %s", entity.ErrRequiredAttr, "disk_id")
+	}
+	if !o.Mount.Empty() {
+		if err := o.Mount.Validate(); err != nil {
+			return fmt.Errorf("%s: %w", "mount", err)
+		}
+	}
+	if entity.Empty(o.NodeId) {
+		return fmt.Errorf("%w: %s", entity.ErrRequiredAttr, "node_id")
+	}
+	if o.Status != "" {
+		if _, ok := disk_leasestatusToId[o.Status]; !ok {
+			return fmt.Errorf("%w: %s: %q", entity.ErrInvalidChoice, "status", o.Status)
+		}
+	}
+	return nil
+}
+
 func (o *DiskLease) InitSchema(sb *schema.SchemaBuilder) {
 	sb.Time("acquired_at", "dev.miren.storage/disk_lease.acquired_at", schema.Doc("When the lease was acquired"))
 	sb.Ref("app_id", "dev.miren.storage/disk_lease.app_id", schema.Doc("Reference to the application (for debugging)"), schema.Indexed, schema.Tags("dev.miren.app_ref"))
@@ -325,6 +426,26 @@ func (o *DiskLease) InitSchema(sb *schema.SchemaBuilder) {
 	sb.Ref("status", "dev.miren.storage/disk_lease.status", schema.Doc("Current state of the lease"), schema.Indexed, schema.Choices(DiskLeaseStatusPendingId, DiskLeaseStatusBoundId, DiskLeaseStatusFailedId, DiskLeaseStatusReleasedId))
 }
 
+func FindDiskLeasesByAppId(ctx context.Context, store entity.IndexLister, appId entity.Id) ([]entity.Id, error) {
+	return store.ListIndex(ctx, entity.Ref(DiskLeaseAppIdId, appId))
+}
+
+func FindDiskLeasesByDiskId(ctx context.Context, store entity.IndexLister, diskId entity.Id) ([]entity.Id, error) {
+	return store.ListIndex(ctx, entity.Ref(DiskLeaseDiskIdId, diskId))
+}
+
+func FindDiskLeasesBySandboxId(ctx context.Context, store entity.IndexLister, sandboxId entity.Id) ([]entity.Id, error) {
+	return store.ListIndex(ctx, entity.Ref(DiskLeaseSandboxIdId, sandboxId))
+}
+
+func FindDiskLeasesByStatus(ctx context.Context, store entity.IndexLister, status DiskLeaseStatus) ([]entity.Id, error) {
+	id, ok := disk_leasestatusToId[status]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s: %q", entity.ErrInvalidChoice, "status", status)
+	}
+	return store.ListIndex(ctx, entity.Ref(DiskLeaseStatusId, id))
+}
+
 const (
 	MountOptionsId  = entity.Id("dev.miren.storage/mount.options")
 	MountPathId     = entity.Id("dev.miren.storage/mount.path")
@@ -373,6 +494,21 @@ func (o *Mount) Empty() bool {
 	return true
 }
 
+func (o *Mount) DeepCopy() *Mount {
+	if o == nil {
+		return nil
+	}
+	out := *o
+	return &out
+}
+
+func (o *Mount) Validate() error {
+	if entity.Empty(o.Path) {
+		return fmt.Errorf("%w: %s", entity.ErrRequiredAttr, "path")
+	}
+	return nil
+}
+
 func (o *Mount) InitSchema(sb *schema.SchemaBuilder) {
 	sb.String("options", "dev.miren.storage/mount.options", schema.Doc("Mount options (e.g., \"rw,noatime\")"))
 	sb.String("path", "dev.miren.storage/mount.path", schema.Doc("Mount path in the container"), schema.Required)
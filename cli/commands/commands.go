@@ -196,6 +196,10 @@ func AllCommands() map[string]cli.CommandFactory {
 			return Infer("server config validate", "Validate a server configuration file", ServerConfigValidate), nil
 		},
 
+		"server config dump": func() (cli.Command, error) {
+			return Infer("server config dump", "Print the effective server configuration as TOML", ServerConfigDump), nil
+		},
+
 		"download release": func() (cli.Command, error) {
 			return Infer("download release", "Download and extract miren release", DownloadRelease), nil
 		},
@@ -117,10 +117,11 @@ func Logs(ctx *Context, opts struct {
 }
 
 var streamTypePrefixes = map[string]string{
-	"stdout":   "S",
-	"stderr":   "E",
-	"error":    "ERR",
-	"user-oob": "U",
+	"stdout":     "S",
+	"stderr":     "E",
+	"error":      "ERR",
+	"user-oob":   "U",
+	"structured": "S",
 }
 
 func streamLogs(ctx *Context, cl *rpc.NetworkClient, app, sandbox string, last *time.Duration, follow bool, filter *logfilter.Filter) error {
@@ -38,6 +38,7 @@ import (
 	"miren.dev/runtime/components/runner"
 	"miren.dev/runtime/components/victorialogs"
 	"miren.dev/runtime/components/victoriametrics"
+	"miren.dev/runtime/controllers/sandbox"
 	"miren.dev/runtime/metrics"
 	"miren.dev/runtime/observability"
 	"miren.dev/runtime/pkg/caauth"
@@ -424,7 +425,6 @@ func Server(ctx *Context, opts serverconfig.CLIFlags) error {
 		buildkitComponent = buildkit.NewComponent(ctx.Log, cc, "miren", cfg.Server.GetDataPath())
 
 		// Parse GC settings
-		gcStorage, _ := units.ParseData(cfg.Buildkit.GetGcKeepStorage())
 		gcDuration, _ := units.ParseDuration(cfg.Buildkit.GetGcKeepDuration())
 
 		// Default socket directory to data_path/buildkit/socket if not set
@@ -435,7 +435,7 @@ func Server(ctx *Context, opts serverconfig.CLIFlags) error {
 
 		buildkitConfig := buildkit.Config{
 			SocketDir:      socketDir,
-			GCKeepStorage:  int64(gcStorage.Bytes()),
+			GCKeepStorage:  cfg.Buildkit.GetGcKeepStorage().Bytes(),
 			GCKeepDuration: int64(gcDuration.Seconds()),
 			RegistryHost:   "cluster.local:5000",
 		}
@@ -495,18 +495,51 @@ func Server(ctx *Context, opts serverconfig.CLIFlags) error {
 		return err
 	}
 
+	// Archive aged logs so they can still be read once VictoriaLogs retention
+	// drops them. The log reader picks the archive up as log-archive.
+	var logArchive *observability.LogArchive
+	if dir := cfg.Victorialogs.GetArchiveDir(); dir != "" {
+		logArchive = &observability.LogArchive{
+			Store: &observability.DirArchiveStore{Root: dir},
+		}
+		ctx.Server.Register("log-archive", logArchive)
+	}
+
 	err = ctx.Server.Resolve(&logs)
 	if err != nil {
 		ctx.Log.Error("failed to resolve log reader", "error", err)
 		return err
 	}
 
+	if logArchive != nil {
+		archiver := &observability.LogArchiver{
+			Log:          ctx.Log.With("module", "log-archiver"),
+			Reader:       logs,
+			Archive:      logArchive,
+			HotRetention: cfg.Victorialogs.GetArchiveAfter(),
+		}
+
+		ctx.Log.Info("archiving aged logs", "dir", cfg.Victorialogs.GetArchiveDir(), "after", archiver.HotRetention)
+
+		eg.Go(func() error {
+			archiver.Run(sub)
+			return nil
+		})
+	}
+
 	err = ctx.Server.Resolve(&logWriter)
 	if err != nil {
 		ctx.Log.Error("failed to resolve log writer", "error", err)
 		return err
 	}
 
+	// Send any logs still buffered before shutting down
+	defer func() {
+		if err := logWriter.Close(); err != nil {
+			ctx.Log.Error("failed to flush log writer", "error", err)
+		}
+	}()
+
 	// Discover local IPs using ipdiscovery
 	discovery, err := ipdiscovery.DiscoverWithTimeout(5*time.Second, ctx.Log)
 	if err != nil {
@@ -605,6 +638,7 @@ func Server(ctx *Context, opts serverconfig.CLIFlags) error {
 		Address:         srvaddr,
 		EtcdEndpoints:   cfg.Etcd.Endpoints,
 		Prefix:          cfg.Etcd.GetPrefix(),
+		DeleteRetention: cfg.Etcd.GetDeleteRetention(),
 		DataPath:        cfg.Server.GetDataPath(),
 		AdditionalNames: cfg.TLS.AdditionalNames,
 		AdditionalIPs:   additionalIps,
@@ -637,6 +671,8 @@ func Server(ctx *Context, opts serverconfig.CLIFlags) error {
 	reg := ctx.Server
 
 	ctx.Server.Register("service-prefixes", subnets)
+	ctx.Server.Register("host-path-allowlist", cfg.Server.SandboxHostPathAllowlist)
+	ctx.Server.Register("sysctl-allowlist", cfg.Server.SandboxSysctlAllowlist)
 
 	gn, err := grunge.NewNetwork(ctx.Log, grunge.NetworkOptions{
 		EtcdEndpoints: cfg.Etcd.Endpoints,
@@ -736,7 +772,7 @@ func Server(ctx *Context, opts serverconfig.CLIFlags) error {
 	// No need for additional checks here
 
 	ingressConfig := httpingress.IngressConfig{
-		RequestTimeout: cfg.Server.HTTPRequestTimeoutDuration(),
+		RequestTimeout: cfg.Server.GetHTTPRequestTimeout(),
 	}
 	hs := httpingress.NewServer(ctx, ctx.Log, ingressConfig, client, aa, &httpMetrics, logWriter)
 
@@ -880,6 +916,32 @@ func Server(ctx *Context, opts serverconfig.CLIFlags) error {
 	ctx.Info("Miren server started successfully! You can now connect to the cluster using `-C %s`\n", cfg.Server.GetConfigClusterName())
 	ctx.Info("For example: cd my-app && miren deploy -C %s", cfg.Server.GetConfigClusterName())
 
+	// Watch for a spot termination notice and move sandboxes off the node
+	// before it's reclaimed
+	if noticeURL := cfg.Server.GetPreemptionNoticeURL(); noticeURL != "" {
+		eg.Go(func() error {
+			w := &sandbox.PreemptionWatcher{Log: ctx.Log, URL: noticeURL}
+
+			deadline, reason, err := w.Watch(sub)
+			if err != nil {
+				return nil
+			}
+
+			ctx.Log.Warn("received preemption notice", "deadline", deadline, "reason", reason)
+
+			preemptCtx, cancel := context.WithDeadline(context.Background(), deadline)
+			defer cancel()
+
+			if err := r.Preempt(preemptCtx, deadline, reason); err != nil {
+				ctx.Log.Error("failed to preempt runner", "error", err)
+				return err
+			}
+
+			ctx.Log.Info("runner preempted, shutting down")
+			return fmt.Errorf("runner preempted, shutting down")
+		})
+	}
+
 	// Set up signal handling for graceful drain on SIGUSR2
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGUSR2)
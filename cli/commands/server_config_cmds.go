@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"os"
 
-	"github.com/pelletier/go-toml/v2"
 	"miren.dev/runtime/pkg/serverconfig"
 )
 
@@ -32,8 +31,9 @@ func ServerConfigGenerate(ctx *Context, opts struct {
 		cfg.Containerd.SetStartEmbedded(true)
 	}
 
-	// Marshal to TOML
-	data, err := toml.Marshal(cfg)
+	// Marshal to TOML. MarshalTOML writes durations in Go syntax, which
+	// toml.Marshal on the struct would write as bare nanoseconds.
+	data, err := cfg.MarshalTOML()
 	if err != nil {
 		return fmt.Errorf("failed to generate TOML: %w", err)
 	}
@@ -66,7 +66,7 @@ func ServerConfigValidate(ctx *Context, opts struct {
 
 	if opts.Verbose {
 		// Print the loaded configuration
-		data, err := toml.Marshal(cfg)
+		data, err := cfg.MarshalTOML()
 		if err != nil {
 			return fmt.Errorf("failed to marshal config: %w", err)
 		}
@@ -76,3 +76,26 @@ func ServerConfigValidate(ctx *Context, opts struct {
 
 	return nil
 }
+
+// ServerConfigDump prints the effective server configuration after merging
+// the config file, environment variables and the given server flags
+func ServerConfigDump(ctx *Context, opts serverconfig.CLIFlags) error {
+	configFile := ""
+	if opts.ConfigFile != nil {
+		configFile = *opts.ConfigFile
+	}
+
+	cfg, err := serverconfig.Load(configFile, &opts, ctx.Log)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	data, err := cfg.MarshalTOML()
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	fmt.Print(string(data))
+
+	return nil
+}
@@ -75,6 +75,10 @@ type CoordinatorConfig struct {
 	// NoAuth disables authentication entirely (for testing only)
 	NoAuth bool `json:"no_auth" yaml:"no_auth"`
 
+	// DeleteRetention keeps deleted entities recoverable for this long
+	// before they're purged. Zero deletes them immediately.
+	DeleteRetention time.Duration `json:"delete_retention" yaml:"delete_retention"`
+
 	Mem       *metrics.MemoryUsage
 	Cpu       *metrics.CPUUsage
 	HTTP      *metrics.HTTPMetrics
@@ -413,6 +417,7 @@ func (c *Coordinator) Start(ctx context.Context) error {
 		rpc.WithCertificateVerification(c.authority.GetCACertificate()),
 		rpc.WithBindAddr(c.Address),
 		rpc.WithLogger(c.Log),
+		rpc.WithDefaultStallTimeouts(),
 	}
 
 	// Add cloud authenticator if enabled
@@ -518,12 +523,32 @@ func (c *Coordinator) Start(ctx context.Context) error {
 		return err
 	}
 
+	keyring, err := entity.LoadNodeKeyring(filepath.Join(c.DataPath, "server", "node.key"))
+	if err != nil {
+		c.Log.Error("failed to load node keyring", "error", err)
+		return err
+	}
+
+	etcdStore.SetKeyring(keyring)
+
+	if c.DeleteRetention > 0 {
+		etcdStore.SetDeleteRetention(c.DeleteRetention)
+		go etcdStore.RunDeleteSweeper(ctx, min(c.DeleteRetention, 10*time.Minute))
+		c.Log.Info("soft deletes enabled", "retention", c.DeleteRetention)
+	}
+
 	err = schema.Apply(ctx, etcdStore)
 	if err != nil {
 		c.Log.Error("failed to apply schema", "error", err)
 		return err
 	}
 
+	err = etcdStore.CheckKeyring(ctx, schema.EncryptedAttrs())
+	if err != nil {
+		c.Log.Error("entity store can't serve encrypted attributes", "error", err)
+		return err
+	}
+
 	// Migrate entities from old format to new attribute-based format
 	migrated, skipped, err := entity.MigrateEntityStore(ctx, c.Log, client, entity.MigrateOptions{
 		Prefix: c.Prefix,
@@ -591,6 +616,11 @@ func (c *Coordinator) Start(ctx context.Context) error {
 		return err
 	}
 
+	// None of these controllers write their own entity while handling it, so
+	// their updates can be made conditional on the revision they read. A
+	// concurrent writer then causes a rehandle rather than being clobbered.
+	const conflictRetries = 3
+
 	// Create controller manager and add controllers
 	c.cm = controller.NewControllerManager()
 
@@ -604,6 +634,7 @@ func (c *Coordinator) Start(ctx context.Context) error {
 		time.Minute, // Resync every minute to ensure pools exist
 		1,           // Single worker to prevent race conditions
 	)
+	launcherController.SetConflictRetries(conflictRetries)
 	c.cm.AddController(launcherController)
 
 	// Add sandbox pool controller (reconciles pool desired_instances to actual sandboxes)
@@ -616,6 +647,7 @@ func (c *Coordinator) Start(ctx context.Context) error {
 		10*time.Second, // Resync every 10 seconds for fast crash detection
 		1,              // Single worker to prevent duplicate sandbox creation races
 	)
+	poolController.SetConflictRetries(conflictRetries)
 	c.cm.AddController(poolController)
 
 	// Add scheduler controller (assigns sandboxes to nodes)
@@ -634,8 +666,28 @@ func (c *Coordinator) Start(ctx context.Context) error {
 		time.Minute, // Resync every minute to catch any missed sandboxes
 		1,           // Single worker
 	)
+	schedulerController.SetConflictRetries(conflictRetries)
 	c.cm.AddController(schedulerController)
 
+	// Add partition monitor (reschedules sandboxes of nodes that stay unreachable)
+	partitions := schedulerctrl.NewPartitionMonitor(c.Log, eac)
+	if err := partitions.Init(ctx); err != nil {
+		c.Log.Error("failed to initialize partition monitor", "error", err)
+		return err
+	}
+
+	partitionController := controller.NewReconcileController(
+		"partition-monitor",
+		c.Log,
+		entity.Ref(entity.EntityKind, compute_v1alpha.KindNode),
+		eac,
+		controller.AdaptReconcileController[compute_v1alpha.Node](partitions),
+		10*time.Second, // Resync often so timeouts are noticed between node events
+		1,              // Single worker
+	)
+	partitionController.SetConflictRetries(conflictRetries)
+	c.cm.AddController(partitionController)
+
 	// Add certificate controller if DNS provider is configured
 	if c.AcmeDNSProvider != "" {
 		c.Log.Info("enabling ACME DNS challenge certificate controller", "provider", c.AcmeDNSProvider)
@@ -676,6 +728,7 @@ func (c *Coordinator) Start(ctx context.Context) error {
 	ai := app.NewAppInfo(c.Log, ec, c.Cpu, c.Mem, c.HTTP)
 	server.ExposeValue("dev.miren.runtime/app", app_v1alpha.AdaptCrud(ai))
 	server.ExposeValue("dev.miren.runtime/app-status", app_v1alpha.AdaptAppStatus(ai))
+	server.Handle("GET /api/v1/apps/{app}/status/stream", app.NewStatusStream(c.Log, ai.Status, 5*time.Second))
 
 	ls := logs.NewServer(c.Log, ec, c.Logs)
 	server.ExposeValue("dev.miren.runtime/logs", app_v1alpha.AdaptLogs(ls))
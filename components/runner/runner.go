@@ -131,15 +131,31 @@ func (r *Runner) Drain(ctx context.Context) error {
 	sandboxCount := results.Length()
 	r.Log.Info("found sandboxes to drain", "count", sandboxCount, "node", r.Id)
 
-	// Stop each sandbox
-	var drainErr error
-	stoppedCount := 0
+	var sandboxes []*sandbox.SandboxWithMeta
 	for results.Next() {
 		md := results.Metadata()
 		if md == nil {
 			continue
 		}
 
+		var sb compute_v1alpha.Sandbox
+		if err := results.Read(&sb); err != nil {
+			return fmt.Errorf("failed to decode sandbox %s: %w", md.ID, err)
+		}
+
+		sandboxes = append(sandboxes, &sandbox.SandboxWithMeta{Sandbox: &sb, Metadata: md})
+	}
+
+	// Stop preemptible and low priority sandboxes first so that the ones we
+	// care most about keep running for as long as possible.
+	sandbox.EvictionOrder(sandboxes)
+
+	// Stop each sandbox
+	var drainErr error
+	stoppedCount := 0
+	for _, sbm := range sandboxes {
+		md := sbm.Metadata
+
 		r.Log.Info("stopping sandbox", "id", md.ID)
 		err := r.sbController.Delete(ctx, md.ID)
 		if err != nil {
@@ -159,6 +175,37 @@ func (r *Runner) Drain(ctx context.Context) error {
 	return nil
 }
 
+// Preempt reacts to the node being reclaimed at deadline, e.g. a spot instance
+// termination notice. Sandboxes are evicted in priority order and given the
+// chance to be relocated to other nodes before the node is drained.
+func (r *Runner) Preempt(ctx context.Context, deadline time.Time, reason string) error {
+	if r.sbController == nil || r.Id == "" {
+		return fmt.Errorf("runner not initialized with sandbox controller")
+	}
+
+	h := &sandbox.PreemptionHandler{
+		Log:   r.Log,
+		EAC:   r.sbController.EAC,
+		Drain: r.Drain,
+
+		// Evict one sandbox of a pool at a time while there's time to wait
+		// for its replacement.
+		MaxUnavailable: 1,
+	}
+
+	res, err := h.HandleNotice(ctx, sandbox.PreemptionNotice{
+		Node:     entity.Id("node/" + r.Id),
+		Deadline: deadline,
+		Reason:   reason,
+	})
+	if err != nil {
+		return err
+	}
+
+	r.Log.Info("runner preempted", "id", r.Id, "sandboxes_evicted", len(res.Evicted))
+	return nil
+}
+
 func (r *Runner) ContainerdNamespace() string {
 	return r.namespace
 }
@@ -188,7 +235,7 @@ func (r *Runner) Start(ctx context.Context) error {
 	)
 
 	if r.Config == nil {
-		rs, err = rpc.NewState(ctx, rpc.WithLogger(r.Log), rpc.WithBindAddr(r.ListenAddress), rpc.WithSkipVerify)
+		rs, err = rpc.NewState(ctx, rpc.WithLogger(r.Log), rpc.WithBindAddr(r.ListenAddress), rpc.WithSkipVerify, rpc.WithDefaultStallTimeouts())
 		if err != nil {
 			return err
 		}
@@ -198,7 +245,7 @@ func (r *Runner) Start(ctx context.Context) error {
 			return err
 		}
 	} else {
-		rs, err = r.Config.State(ctx, rpc.WithLogger(r.Log), rpc.WithBindAddr(r.ListenAddress))
+		rs, err = r.Config.State(ctx, rpc.WithLogger(r.Log), rpc.WithBindAddr(r.ListenAddress), rpc.WithDefaultStallTimeouts())
 		if err != nil {
 			return err
 		}
@@ -261,6 +308,10 @@ func (r *Runner) setupEntity(ctx context.Context, ec *entityserver.Client) error
 		ApiAddress:  r.ListenAddress,
 	}
 
+	if r.sbController != nil {
+		node.RuntimeClass = r.sbController.AvailableRuntimeClasses()
+	}
+
 	res, err := ec.CreateOrUpdate(ctx, r.Id, &node)
 	if err != nil {
 		return err
@@ -289,6 +340,7 @@ func (r *Runner) SetupControllers(
 	cm := controller.NewControllerManager()
 
 	r.reg.Register("entity-client", eas)
+	r.reg.Register("addon-values", &sandbox.EntityAddonValues{EAC: eas})
 	r.reg.Override("node-id", r.Id)
 
 	var sbc sandbox.SandboxController
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"slices"
 	"strings"
 
 	"miren.dev/runtime/api/compute/compute_v1alpha"
@@ -325,26 +326,40 @@ func (l *Launcher) buildSandboxSpec(
 	}
 
 	// Add global config env vars
-	envMap := make(map[string]string)
+	envMap := make(map[string]envValue)
 	for _, x := range ver.Config.Variable {
-		envMap[x.Key] = x.Value
+		envMap[x.Key] = newEnvValue(x.Value, x.Source)
 	}
 
 	// Find and merge per-service env vars (these override global vars)
 	for _, svc := range ver.Config.Services {
 		if svc.Name == serviceName {
 			for _, x := range svc.Env {
-				envMap[x.Key] = x.Value
+				envMap[x.Key] = newEnvValue(x.Value, x.Source)
 			}
 			break
 		}
 	}
 
-	// Convert map to env var slice
+	// Convert map to env var slice. Values provided by addons are left for
+	// the sandbox controller to resolve when the container starts.
 	for k, v := range envMap {
-		appCont.Env = append(appCont.Env, k+"="+v)
+		if v.addon != "" {
+			appCont.AddonEnv = append(appCont.AddonEnv, compute_v1alpha.SandboxSpecContainerAddonEnv{
+				Name:  k,
+				Addon: v.addon,
+				Key:   v.key,
+			})
+			continue
+		}
+
+		appCont.Env = append(appCont.Env, k+"="+v.value)
 	}
 
+	slices.SortFunc(appCont.AddonEnv, func(a, b compute_v1alpha.SandboxSpecContainerAddonEnv) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
 	// Find service command
 	for _, s := range ver.Config.Commands {
 		if s.Service == serviceName && s.Command != "" {
@@ -476,6 +491,10 @@ func (l *Launcher) specsMatch(spec1, spec2 *compute_v1alpha.SandboxSpec) (string
 			return fmt.Sprintf("container[%d] environment variables mismatch", i), false
 		}
 
+		if !slices.Equal(c1.AddonEnv, c2.AddonEnv) {
+			return fmt.Sprintf("container[%d] addon environment variables mismatch", i), false
+		}
+
 		// Compare ports
 		if !portsEqual(c1.Port, c2.Port) {
 			return fmt.Sprintf("container[%d] ports mismatch", i), false
@@ -486,6 +505,21 @@ func (l *Launcher) specsMatch(spec1, spec2 *compute_v1alpha.SandboxSpec) (string
 	return "", true
 }
 
+// envValue is the value of an env var, or the addon instance and key that
+// provide it
+type envValue struct {
+	value      string
+	addon, key string
+}
+
+func newEnvValue(value, source string) envValue {
+	if addon, key, ok := core_v1alpha.AddonSource(source); ok {
+		return envValue{addon: addon, key: key}
+	}
+
+	return envValue{value: value}
+}
+
 // envVarsEqual compares two env var slices in an order-independent way,
 // ignoring version-specific system env vars (MIREN_VERSION, MIREN_APP)
 func envVarsEqual(env1, env2 []string) bool {
@@ -1256,3 +1256,106 @@ func TestPortNameAndType(t *testing.T) {
 	assert.Equal(t, "http", webPort.Name, "web service should default to port name http")
 	assert.Equal(t, "http", webPort.Type, "web service should default to port type http")
 }
+
+// TestAddonEnvVars verifies that env vars provided by addons are left for the
+// sandbox to resolve rather than written into the spec's env
+func TestAddonEnvVars(t *testing.T) {
+	ctx := context.Background()
+	log := slog.Default()
+
+	server, cleanup := testutils.NewInMemEntityServer(t)
+	defer cleanup()
+
+	app := &core_v1alpha.App{
+		Project: entity.Id("project-1"),
+	}
+	appID, err := server.Client.Create(ctx, "test-app", app)
+	require.NoError(t, err)
+	app.ID = appID
+
+	ver := &core_v1alpha.AppVersion{
+		App:      app.ID,
+		Version:  "v1",
+		ImageUrl: "oci.miren.cloud/myapp:latest",
+		Config: core_v1alpha.Config{
+			Port: 3000,
+			Variable: []core_v1alpha.Variable{
+				{Key: "DATABASE_URL", Source: "addon:db/url"},
+				{Key: "REDIS_URL", Source: "addon:cache/url"},
+				{Key: "LOG_LEVEL", Value: "debug"},
+			},
+			Services: []core_v1alpha.Services{
+				{
+					Name: "web",
+					ServiceConcurrency: core_v1alpha.ServiceConcurrency{
+						Mode:         "fixed",
+						NumInstances: 1,
+					},
+					Env: []core_v1alpha.Env{
+						{Key: "REDIS_URL", Value: "redis://localhost:6379"},
+					},
+				},
+			},
+		},
+	}
+	verID, err := server.Client.Create(ctx, "test-v1", ver)
+	require.NoError(t, err)
+	ver.ID = verID
+
+	app.ActiveVersion = ver.ID
+	err = server.Client.Update(ctx, app)
+	require.NoError(t, err)
+
+	launcher := NewLauncher(log, server.EAC)
+	err = launcher.Reconcile(ctx, app, nil)
+	require.NoError(t, err)
+
+	pools := listAllPools(t, ctx, server)
+	require.Len(t, pools, 1)
+
+	cont := pools[0].SandboxSpec.Container[0]
+
+	assert.Equal(t, []compute_v1alpha.SandboxSpecContainerAddonEnv{
+		{Name: "DATABASE_URL", Addon: "db", Key: "url"},
+	}, cont.AddonEnv)
+
+	assert.Contains(t, cont.Env, "LOG_LEVEL=debug")
+	assert.Contains(t, cont.Env, "REDIS_URL=redis://localhost:6379", "service env should override the addon value")
+
+	for _, env := range cont.Env {
+		assert.False(t, strings.HasPrefix(env, "DATABASE_URL="), "addon env var should not be in the spec's env")
+	}
+}
+
+// TestSpecsMatchAddonEnv verifies that changing which addon provides an env
+// var is a spec change, so the pool's sandboxes are replaced
+func TestSpecsMatchAddonEnv(t *testing.T) {
+	launcher := NewLauncher(slog.Default(), nil)
+
+	spec := func(addonEnv ...compute_v1alpha.SandboxSpecContainerAddonEnv) *compute_v1alpha.SandboxSpec {
+		return &compute_v1alpha.SandboxSpec{
+			Container: []compute_v1alpha.SandboxSpecContainer{
+				{
+					Name:     "app",
+					Image:    "oci.miren.cloud/myapp:latest",
+					Env:      []string{"LOG_LEVEL=debug"},
+					AddonEnv: addonEnv,
+				},
+			},
+		}
+	}
+
+	db := compute_v1alpha.SandboxSpecContainerAddonEnv{Name: "DATABASE_URL", Addon: "db", Key: "url"}
+
+	_, ok := launcher.specsMatch(spec(db), spec(db))
+	assert.True(t, ok, "identical addon env vars should match")
+
+	reason, ok := launcher.specsMatch(spec(db), spec(compute_v1alpha.SandboxSpecContainerAddonEnv{
+		Name: "DATABASE_URL", Addon: "db2", Key: "url",
+	}))
+	assert.False(t, ok, "a different addon should be a spec change")
+	assert.Equal(t, "container[0] addon environment variables mismatch", reason)
+
+	_, ok = launcher.specsMatch(spec(db), spec())
+	assert.False(t, ok, "removing an addon env var should be a spec change")
+}
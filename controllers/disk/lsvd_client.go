@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"maps"
 	"net"
+	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -973,6 +975,19 @@ func (c *lsvdClientImpl) ListVolumes(ctx context.Context) ([]string, error) {
 	return volumes, nil
 }
 
+// StatusHandler serves the health, usage, GC state and IO stats of every
+// disk initialized by this client, as described by lsvd.StatusHandler.
+func (c *lsvdClientImpl) StatusHandler() http.Handler {
+	return lsvd.StatusHandler(c.initializedDisks)
+}
+
+func (c *lsvdClientImpl) initializedDisks() map[string]*lsvd.Disk {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return maps.Clone(c.disks)
+}
+
 // AcquireVolumeLease acquires a volume lease from the remote Disk API.
 // Returns the lease nonce if successful, empty string if not using remote storage.
 func (c *lsvdClientImpl) AcquireVolumeLease(ctx context.Context, volumeId, nodeId, appId string) (string, error) {
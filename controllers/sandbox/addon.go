@@ -0,0 +1,94 @@
+package sandbox
+
+import (
+	"context"
+	"fmt"
+
+	compute "miren.dev/runtime/api/compute/compute_v1alpha"
+	"miren.dev/runtime/api/core/core_v1alpha"
+	"miren.dev/runtime/api/entityserver/entityserver_v1alpha"
+	"miren.dev/runtime/pkg/entity"
+)
+
+// AddonValues provides the values addon instances make available to the
+// sandboxes of the apps they're attached to, such as connection URLs and
+// credentials.
+type AddonValues interface {
+	AddonValue(ctx context.Context, sb *compute.Sandbox, addon, key string) (string, error)
+}
+
+// addonEnv returns the env vars of a container whose values come from addon
+// instances. They're resolved each time the container starts so that the
+// values never need to be stored in the sandbox spec.
+func (c *SandboxController) addonEnv(ctx context.Context, sb *compute.Sandbox, co *compute.SandboxSpecContainer) ([]string, error) {
+	if len(co.AddonEnv) == 0 {
+		return nil, nil
+	}
+
+	if c.Addons == nil {
+		return nil, fmt.Errorf("container %s uses addon env vars, but addons aren't available on this node", co.Name)
+	}
+
+	env := make([]string, 0, len(co.AddonEnv))
+
+	for _, ae := range co.AddonEnv {
+		val, err := c.Addons.AddonValue(ctx, sb, ae.Addon, ae.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve env var %s from addon %s: %w", ae.Name, ae.Addon, err)
+		}
+
+		env = append(env, ae.Name+"="+val)
+	}
+
+	return env, nil
+}
+
+// EntityAddonValues resolves addon values from the addon_instance entities
+// attached to a sandbox's app. The instance is found by name, and the value
+// by its key among those the instance provides.
+type EntityAddonValues struct {
+	EAC *entityserver_v1alpha.EntityAccessClient
+}
+
+func (a *EntityAddonValues) AddonValue(ctx context.Context, sb *compute.Sandbox, addon, key string) (string, error) {
+	if sb.Spec.Version == "" {
+		return "", fmt.Errorf("sandbox %s has no app version to find addon instances for", sb.ID)
+	}
+
+	res, err := a.EAC.Get(ctx, sb.Spec.Version.String())
+	if err != nil {
+		return "", fmt.Errorf("failed to get app version %s: %w", sb.Spec.Version, err)
+	}
+
+	var ver core_v1alpha.AppVersion
+	ver.Decode(res.Entity().Entity())
+
+	resp, err := a.EAC.List(ctx, entity.Ref(core_v1alpha.AddonInstanceAppId, ver.App))
+	if err != nil {
+		return "", fmt.Errorf("failed to list addon instances of app %s: %w", ver.App, err)
+	}
+
+	for _, ent := range resp.Values() {
+		var (
+			inst core_v1alpha.AddonInstance
+			md   core_v1alpha.Metadata
+		)
+
+		inst.Decode(ent.Entity())
+		md.Decode(ent.Entity())
+
+		if md.Name != addon {
+			continue
+		}
+
+		for _, pv := range inst.ProvidedValue {
+			if pv.Key == key {
+				return pv.Value, nil
+			}
+		}
+
+		return "", fmt.Errorf("addon instance %s provides no value %s", addon, key)
+	}
+
+	return "", fmt.Errorf("app %s has no addon instance %s", ver.App, addon)
+}
@@ -0,0 +1,128 @@
+package sandbox
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	compute "miren.dev/runtime/api/compute/compute_v1alpha"
+	"miren.dev/runtime/api/core/core_v1alpha"
+	"miren.dev/runtime/pkg/entity/testutils"
+)
+
+type fakeAddons map[string]string
+
+func (f fakeAddons) AddonValue(ctx context.Context, sb *compute.Sandbox, addon, key string) (string, error) {
+	val, ok := f[addon+"/"+key]
+	if !ok {
+		return "", fmt.Errorf("no value for %s", key)
+	}
+
+	return val, nil
+}
+
+func TestAddonEnv(t *testing.T) {
+	ctx := context.Background()
+	sb := &compute.Sandbox{}
+
+	co := &compute.SandboxSpecContainer{
+		Name: "app",
+		AddonEnv: []compute.SandboxSpecContainerAddonEnv{
+			{Name: "DATABASE_URL", Addon: "db", Key: "url"},
+			{Name: "DATABASE_PASSWORD", Addon: "db", Key: "password"},
+		},
+	}
+
+	t.Run("resolves env vars from addons", func(t *testing.T) {
+		c := &SandboxController{
+			Log: slog.Default(),
+			Addons: fakeAddons{
+				"db/url":      "postgres://db:5432/app",
+				"db/password": "hunter2",
+			},
+		}
+
+		env, err := c.addonEnv(ctx, sb, co)
+		require.NoError(t, err)
+
+		require.Equal(t, []string{
+			"DATABASE_URL=postgres://db:5432/app",
+			"DATABASE_PASSWORD=hunter2",
+		}, env)
+	})
+
+	t.Run("sets nothing without addon env vars", func(t *testing.T) {
+		c := &SandboxController{Log: slog.Default()}
+
+		env, err := c.addonEnv(ctx, sb, &compute.SandboxSpecContainer{Name: "app"})
+		require.NoError(t, err)
+		require.Nil(t, env)
+	})
+
+	t.Run("fails without addons", func(t *testing.T) {
+		c := &SandboxController{Log: slog.Default()}
+
+		_, err := c.addonEnv(ctx, sb, co)
+		require.ErrorContains(t, err, "addons aren't available on this node")
+	})
+
+	t.Run("fails when a value can't be resolved", func(t *testing.T) {
+		c := &SandboxController{
+			Log:    slog.Default(),
+			Addons: fakeAddons{"db/url": "postgres://db:5432/app"},
+		}
+
+		_, err := c.addonEnv(ctx, sb, co)
+		require.ErrorContains(t, err, "failed to resolve env var DATABASE_PASSWORD from addon db")
+	})
+}
+
+func TestEntityAddonValues(t *testing.T) {
+	ctx := context.Background()
+
+	server, cleanup := testutils.NewInMemEntityServer(t)
+	defer cleanup()
+
+	appID, err := server.Client.Create(ctx, "test-app", &core_v1alpha.App{})
+	require.NoError(t, err)
+
+	verID, err := server.Client.Create(ctx, "test-v1", &core_v1alpha.AppVersion{
+		App:     appID,
+		Version: "v1",
+	})
+	require.NoError(t, err)
+
+	_, err = server.Client.Create(ctx, "db", &core_v1alpha.AddonInstance{
+		App:   appID,
+		Addon: "postgresql",
+		ProvidedValue: []core_v1alpha.ProvidedValue{
+			{Key: "url", Value: "postgres://db:5432/app"},
+		},
+	})
+	require.NoError(t, err)
+
+	addons := &EntityAddonValues{EAC: server.EAC}
+
+	sb := &compute.Sandbox{
+		Spec: compute.SandboxSpec{Version: verID},
+	}
+
+	t.Run("resolves a value the app's addon instance provides", func(t *testing.T) {
+		val, err := addons.AddonValue(ctx, sb, "db", "url")
+		require.NoError(t, err)
+		require.Equal(t, "postgres://db:5432/app", val)
+	})
+
+	t.Run("fails for a key the instance doesn't provide", func(t *testing.T) {
+		_, err := addons.AddonValue(ctx, sb, "db", "password")
+		require.ErrorContains(t, err, "addon instance db provides no value password")
+	})
+
+	t.Run("fails for an instance the app doesn't have", func(t *testing.T) {
+		_, err := addons.AddonValue(ctx, sb, "cache", "url")
+		require.ErrorContains(t, err, "has no addon instance cache")
+	})
+}
@@ -0,0 +1,66 @@
+package sandbox
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	compute "miren.dev/runtime/api/compute/compute_v1alpha"
+)
+
+// hostPathMount returns the bind mount of a container mount that names a
+// host path, rejecting paths that aren't under a prefix in HostPathAllowlist.
+func (c *SandboxController) hostPathMount(m compute.SandboxSpecContainerMount) (specs.Mount, error) {
+	path, err := c.allowedHostPath(m.HostPath)
+	if err != nil {
+		return specs.Mount{}, err
+	}
+
+	return specs.Mount{
+		Destination: m.Destination,
+		Type:        "bind",
+		Source:      path,
+		Options:     []string{"rbind", "rprivate", "nosuid", "nodev", "rw"},
+	}, nil
+}
+
+// allowedHostPath resolves path and checks that it falls under one of the
+// allowlisted prefixes. Symlinks are resolved first so that a link under an
+// allowed prefix can't be used to mount something outside of it.
+func (c *SandboxController) allowedHostPath(path string) (string, error) {
+	if !filepath.IsAbs(path) {
+		return "", fmt.Errorf("host path must be absolute: %s", path)
+	}
+
+	if len(c.HostPathAllowlist) == 0 {
+		return "", fmt.Errorf("host path mounts are not allowed on this node: %s", path)
+	}
+
+	resolved, err := filepath.EvalSymlinks(filepath.Clean(path))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("host path does not exist: %s", path)
+		}
+		return "", fmt.Errorf("failed to resolve host path %s: %w", path, err)
+	}
+
+	for _, prefix := range c.HostPathAllowlist {
+		if !filepath.IsAbs(prefix) {
+			c.Log.Warn("ignoring relative host path allowlist entry", "prefix", prefix)
+			continue
+		}
+
+		prefix, err := filepath.EvalSymlinks(filepath.Clean(prefix))
+		if err != nil {
+			continue
+		}
+
+		if resolved == prefix || strings.HasPrefix(resolved, strings.TrimSuffix(prefix, "/")+"/") {
+			return resolved, nil
+		}
+	}
+
+	return "", fmt.Errorf("host path %s is not under an allowed prefix", path)
+}
@@ -0,0 +1,96 @@
+package sandbox
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	compute "miren.dev/runtime/api/compute/compute_v1alpha"
+)
+
+func TestHostPathMount(t *testing.T) {
+	setup := func(t *testing.T) (string, *SandboxController) {
+		dir := t.TempDir()
+
+		allowed := filepath.Join(dir, "allowed")
+		require.NoError(t, os.MkdirAll(filepath.Join(allowed, "data"), 0755))
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "secret"), 0755))
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "allowed-not"), 0755))
+
+		c := &SandboxController{
+			Log:               slog.Default(),
+			HostPathAllowlist: []string{allowed},
+		}
+
+		return dir, c
+	}
+
+	t.Run("mounts a path under an allowed prefix", func(t *testing.T) {
+		r := require.New(t)
+		dir, c := setup(t)
+
+		m, err := c.hostPathMount(compute.SandboxSpecContainerMount{
+			HostPath:    filepath.Join(dir, "allowed", "data"),
+			Destination: "/data",
+		})
+		r.NoError(err)
+
+		resolved, err := filepath.EvalSymlinks(filepath.Join(dir, "allowed", "data"))
+		r.NoError(err)
+
+		r.Equal(resolved, m.Source)
+		r.Equal("/data", m.Destination)
+		r.Equal("bind", m.Type)
+	})
+
+	t.Run("rejects a path outside the allowlist", func(t *testing.T) {
+		r := require.New(t)
+		dir, c := setup(t)
+
+		_, err := c.hostPathMount(compute.SandboxSpecContainerMount{
+			HostPath:    filepath.Join(dir, "secret"),
+			Destination: "/data",
+		})
+		r.ErrorContains(err, "not under an allowed prefix")
+
+		// A shared name prefix isn't a shared directory
+		_, err = c.allowedHostPath(filepath.Join(dir, "allowed-not"))
+		r.Error(err)
+
+		// Nor does walking back out of an allowed directory escape it
+		_, err = c.allowedHostPath(filepath.Join(dir, "allowed", "..", "secret"))
+		r.Error(err)
+	})
+
+	t.Run("rejects symlinks that leave the allowlist", func(t *testing.T) {
+		r := require.New(t)
+		dir, c := setup(t)
+
+		link := filepath.Join(dir, "allowed", "escape")
+		r.NoError(os.Symlink(filepath.Join(dir, "secret"), link))
+
+		_, err := c.allowedHostPath(link)
+		r.ErrorContains(err, "not under an allowed prefix")
+	})
+
+	t.Run("rejects all host paths without an allowlist", func(t *testing.T) {
+		r := require.New(t)
+		dir, c := setup(t)
+
+		c.HostPathAllowlist = nil
+
+		_, err := c.allowedHostPath(filepath.Join(dir, "allowed", "data"))
+		r.ErrorContains(err, "not allowed on this node")
+	})
+
+	t.Run("rejects relative paths", func(t *testing.T) {
+		r := require.New(t)
+		_, c := setup(t)
+
+		_, err := c.allowedHostPath("allowed/data")
+		r.ErrorContains(err, "must be absolute")
+	})
+}
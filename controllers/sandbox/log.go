@@ -2,16 +2,14 @@ package sandbox
 
 import (
 	"bytes"
+	"encoding/json"
 	"log/slog"
-	"regexp"
 	"strings"
 	"time"
 
 	"miren.dev/runtime/observability"
 )
 
-var traceIdRegx = regexp.MustCompile(`trace_id"?\s*[=:]\s*\"?(\w+)`)
-
 type SandboxLogs struct {
 	log    *slog.Logger
 	entity string
@@ -19,6 +17,11 @@ type SandboxLogs struct {
 	buf    bytes.Buffer
 	stream observability.LogStream
 	lw     observability.LogWriter
+
+	// structured marks JSON lines on stdout as observability.Structured
+	structured bool
+
+	traceIDs *observability.TraceIDExtractor
 }
 
 func NewSandboxLogs(
@@ -28,11 +31,12 @@ func NewSandboxLogs(
 	lw observability.LogWriter,
 ) *SandboxLogs {
 	return &SandboxLogs{
-		log:    log,
-		entity: entity,
-		attrs:  attrs,
-		stream: observability.Stdout,
-		lw:     lw,
+		log:      log,
+		entity:   entity,
+		attrs:    attrs,
+		stream:   observability.Stdout,
+		lw:       lw,
+		traceIDs: observability.DefaultTraceIDExtractor,
 	}
 }
 
@@ -72,18 +76,15 @@ func (s *SandboxLogs) processLine(line string) {
 	} else if strings.HasPrefix(line, "!ERROR ") {
 		line = strings.TrimPrefix(line, "!ERROR ")
 		stream = observability.Error
-	}
-
-	traceId := ""
-	if matches := traceIdRegx.FindStringSubmatch(line); len(matches) > 1 {
-		traceId = matches[1]
+	} else if s.structured && stream == observability.Stdout && isJSONObject(line) {
+		stream = observability.Structured
 	}
 
 	err := s.lw.WriteEntry(s.entity, observability.LogEntry{
 		Timestamp:  ts,
 		Stream:     stream,
 		Body:       line,
-		TraceID:    traceId,
+		TraceID:    s.traceIDs.Extract(line),
 		Attributes: s.attrs,
 	})
 	if err != nil {
@@ -91,6 +92,33 @@ func (s *SandboxLogs) processLine(line string) {
 	}
 }
 
+// Structured returns logs that store the JSON lines written to stdout as
+// structured logs, so their fields can be queried. Other lines are stored
+// as usual.
+func (s *SandboxLogs) Structured() *SandboxLogs {
+	x := *s
+	x.structured = true
+
+	return &x
+}
+
+// WithTraceIDs returns logs that find the trace IDs of lines with x rather
+// than observability.DefaultTraceIDExtractor. A nil x keeps the default.
+func (s *SandboxLogs) WithTraceIDs(x *observability.TraceIDExtractor) *SandboxLogs {
+	if x == nil {
+		return s
+	}
+
+	y := *s
+	y.traceIDs = x
+
+	return &y
+}
+
+func isJSONObject(line string) bool {
+	return strings.HasPrefix(line, "{") && json.Valid([]byte(line))
+}
+
 func (s *SandboxLogs) Stderr() *SandboxLogs {
 	x := *s
 	x.stream = observability.Stderr
@@ -139,6 +139,25 @@ func TestSandboxLogs(t *testing.T) {
 		r.Equal(traceID, mock.entries[0].log.TraceID)
 	})
 
+	t.Run("extracts trace ID with a configured extractor", func(t *testing.T) {
+		r := require.New(t)
+
+		mock := &mockLogWriter{}
+		entityID := identity.NewID()
+
+		x, err := observability.NewTraceIDExtractor(nil, []string{"request.trace"})
+		r.NoError(err)
+
+		logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+		sl := NewSandboxLogs(logger, entityID, map[string]string{}, mock).WithTraceIDs(x)
+
+		_, err = sl.Write([]byte(`{"msg":"hello","request":{"trace":"json-1"}}` + "\n"))
+		r.NoError(err)
+
+		r.Len(mock.entries, 1)
+		r.Equal("json-1", mock.entries[0].log.TraceID)
+	})
+
 	t.Run("includes attributes in logs", func(t *testing.T) {
 		r := require.New(t)
 
@@ -195,6 +214,30 @@ func TestSandboxLogs(t *testing.T) {
 		r.Equal(observability.Stdout, mock.entries[1].log.Stream)
 	})
 
+	t.Run("Structured marks JSON stdout lines", func(t *testing.T) {
+		r := require.New(t)
+
+		mock := &mockLogWriter{}
+		entityID := identity.NewID()
+
+		logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+		sl := NewSandboxLogs(logger, entityID, map[string]string{}, mock).Structured()
+
+		input := []byte(`{"level":"INFO","msg":"hello"}` + "\nplain line\n{not json\n")
+		_, err := sl.Write(input)
+		r.NoError(err)
+
+		_, err = sl.Stderr().Write([]byte(`{"level":"ERROR","msg":"oops"}` + "\n"))
+		r.NoError(err)
+
+		r.Len(mock.entries, 4)
+		r.Equal(observability.Structured, mock.entries[0].log.Stream)
+		r.Equal(`{"level":"INFO","msg":"hello"}`, mock.entries[0].log.Body)
+		r.Equal(observability.Stdout, mock.entries[1].log.Stream)
+		r.Equal(observability.Stdout, mock.entries[2].log.Stream)
+		r.Equal(observability.Stderr, mock.entries[3].log.Stream)
+	})
+
 	t.Run("handles multiple lines in single write", func(t *testing.T) {
 		r := require.New(t)
 
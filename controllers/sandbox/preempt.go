@@ -0,0 +1,360 @@
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	compute "miren.dev/runtime/api/compute/compute_v1alpha"
+	"miren.dev/runtime/api/core/core_v1alpha"
+	"miren.dev/runtime/api/entityserver/entityserver_v1alpha"
+	"miren.dev/runtime/pkg/cond"
+	"miren.dev/runtime/pkg/entity"
+)
+
+const (
+	// defaultPreemptionGrace is how long before the deadline we give up
+	// waiting on relocation and drain the node outright.
+	defaultPreemptionGrace = 5 * time.Second
+
+	defaultPreemptionPoll = 500 * time.Millisecond
+)
+
+// PreemptionNotice signals that a node is about to be reclaimed, for instance
+// a spot instance termination notice from the cloud provider.
+type PreemptionNotice struct {
+	Node     entity.Id
+	Deadline time.Time
+	Reason   string
+}
+
+// PreemptionResult describes what happened while handling a notice.
+type PreemptionResult struct {
+	// Evicted lists the sandboxes moved off the node, in eviction order.
+	Evicted []entity.Id
+
+	// Relocated is true when every pool that lost a sandbox had its running
+	// instances restored on other nodes before the deadline.
+	Relocated bool
+
+	// Drained is true once Drain stopped whatever was left on the node.
+	Drained bool
+}
+
+// SandboxWithMeta pairs a decoded sandbox with its entity metadata.
+type SandboxWithMeta struct {
+	Sandbox  *compute.Sandbox
+	Metadata *core_v1alpha.Metadata
+}
+
+// EvictionOrder sorts sandboxes into the order they should be evicted from a
+// node under pressure: preemptible sandboxes go first, then the rest by
+// ascending priority. Sandboxes of equal rank keep their relative order.
+func EvictionOrder(sandboxes []*SandboxWithMeta) {
+	slices.SortStableFunc(sandboxes, func(a, b *SandboxWithMeta) int {
+		as, bs := a.Sandbox.Spec, b.Sandbox.Spec
+
+		if as.Preemptible != bs.Preemptible {
+			if as.Preemptible {
+				return -1
+			}
+			return 1
+		}
+
+		switch {
+		case as.Priority < bs.Priority:
+			return -1
+		case as.Priority > bs.Priority:
+			return 1
+		default:
+			return 0
+		}
+	})
+}
+
+// PreemptionHandler moves sandboxes off a node that is about to disappear.
+//
+// The node is disabled so the scheduler stops placing work on it, then its
+// sandboxes are marked STOPPED in eviction order. That lets each sandbox pool
+// bring up a replacement elsewhere while the node controller tears down the
+// originals. The handler waits for the replacements to be running, then
+// drains the node so everything is down before the deadline.
+type PreemptionHandler struct {
+	Log *slog.Logger
+	EAC *entityserver_v1alpha.EntityAccessClient
+
+	// Drain stops whatever is left on the node once relocation has finished
+	// or the grace period has begun, typically Runner.Drain.
+	Drain func(ctx context.Context) error
+
+	// MaxUnavailable is the disruption budget: how many of a pool's sandboxes
+	// may be short of running before the handler waits for replacements
+	// before evicting another. Zero evicts without waiting. The budget is
+	// ignored once the grace period begins.
+	MaxUnavailable int64
+
+	// Grace is how long before the deadline the handler stops waiting for
+	// relocation. Defaults to 5 seconds.
+	Grace time.Duration
+
+	// PollInterval controls how often relocation progress is checked.
+	PollInterval time.Duration
+}
+
+// HandleNotice evicts every sandbox from the noticed node, returning once the
+// sandboxes have been relocated or the deadline has passed. An error wrapping
+// context.DeadlineExceeded is returned if relocation did not finish in time.
+func (h *PreemptionHandler) HandleNotice(ctx context.Context, notice PreemptionNotice) (*PreemptionResult, error) {
+	grace := h.Grace
+	if grace <= 0 {
+		grace = defaultPreemptionGrace
+	}
+
+	poll := h.PollInterval
+	if poll <= 0 {
+		poll = defaultPreemptionPoll
+	}
+
+	h.Log.Warn("handling preemption notice",
+		"node", notice.Node,
+		"deadline", notice.Deadline,
+		"reason", notice.Reason)
+
+	ctx, cancel := context.WithDeadline(ctx, notice.Deadline)
+	defer cancel()
+
+	if _, err := h.EAC.Patch(ctx, entity.New(
+		entity.DBId, notice.Node,
+		(&compute.Node{Status: compute.DISABLED}).Encode,
+	).Attrs(), 0); err != nil {
+		return nil, fmt.Errorf("failed to disable node %s: %w", notice.Node, err)
+	}
+
+	sandboxes, err := h.nodeSandboxes(ctx, notice.Node)
+	if err != nil {
+		return nil, err
+	}
+
+	EvictionOrder(sandboxes)
+
+	waitCtx, waitCancel := context.WithDeadline(ctx, notice.Deadline.Add(-grace))
+	defer waitCancel()
+
+	var (
+		res   PreemptionResult
+		pools = make(map[string]struct{})
+	)
+
+	for _, sbm := range sandboxes {
+		sb := sbm.Sandbox
+
+		pool, hasPool := sbm.Metadata.Labels.Get("pool")
+		if hasPool {
+			if err := h.waitBudget(waitCtx, pool, poll); err != nil {
+				if waitCtx.Err() == nil {
+					return &res, err
+				}
+
+				h.Log.Warn("evicting sandbox over the disruption budget",
+					"sandbox", sb.ID,
+					"pool", pool)
+			}
+		}
+
+		h.Log.Info("evicting sandbox",
+			"sandbox", sb.ID,
+			"preemptible", sb.Spec.Preemptible,
+			"priority", sb.Spec.Priority)
+
+		if _, err := h.EAC.Patch(ctx, entity.New(
+			entity.DBId, sb.ID,
+			(&compute.Sandbox{Status: compute.STOPPED}).Encode,
+		).Attrs(), 0); err != nil {
+			if errors.Is(err, cond.ErrNotFound{}) {
+				continue
+			}
+
+			return &res, fmt.Errorf("failed to evict sandbox %s: %w", sb.ID, err)
+		}
+
+		res.Evicted = append(res.Evicted, sb.ID)
+
+		if hasPool {
+			pools[pool] = struct{}{}
+		}
+	}
+
+	res.Relocated, err = h.waitRelocated(waitCtx, notice.Node, pools, poll)
+	if err != nil && waitCtx.Err() == nil {
+		return &res, err
+	}
+
+	// Drain whatever is left on the node rather than relying on the node
+	// controller to observe the status change before the node disappears.
+	if h.Drain != nil {
+		if err := h.Drain(ctx); err != nil {
+			return &res, fmt.Errorf("failed to drain node %s: %w", notice.Node, err)
+		}
+
+		res.Drained = true
+	}
+
+	if !res.Relocated {
+		return &res, fmt.Errorf("sandboxes on node %s were not relocated before the deadline: %w",
+			notice.Node, context.DeadlineExceeded)
+	}
+
+	h.Log.Info("preemption handled",
+		"node", notice.Node,
+		"evicted", len(res.Evicted),
+		"remaining", time.Until(notice.Deadline))
+
+	return &res, nil
+}
+
+func (h *PreemptionHandler) nodeSandboxes(ctx context.Context, node entity.Id) ([]*SandboxWithMeta, error) {
+	resp, err := h.EAC.List(ctx, compute.Index(compute.KindSandbox, node))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sandboxes on node %s: %w", node, err)
+	}
+
+	var ret []*SandboxWithMeta
+
+	for _, ent := range resp.Values() {
+		var (
+			sb compute.Sandbox
+			md core_v1alpha.Metadata
+		)
+
+		sb.Decode(ent.Entity())
+		md.Decode(ent.Entity())
+
+		switch sb.Status {
+		case compute.STOPPED, compute.DEAD:
+			continue
+		}
+
+		ret = append(ret, &SandboxWithMeta{Sandbox: &sb, Metadata: &md})
+	}
+
+	return ret, nil
+}
+
+// waitRelocated polls until every pool has at least its desired number of
+// running sandboxes on nodes other than the one being preempted.
+func (h *PreemptionHandler) waitRelocated(ctx context.Context, node entity.Id, pools map[string]struct{}, poll time.Duration) (bool, error) {
+	if len(pools) == 0 {
+		return true, nil
+	}
+
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+
+	for {
+		done, err := h.relocated(ctx, node, pools)
+		if err != nil {
+			return false, err
+		}
+
+		if done {
+			return true, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (h *PreemptionHandler) relocated(ctx context.Context, node entity.Id, pools map[string]struct{}) (bool, error) {
+	for pool := range pools {
+		sp, running, err := h.poolRunning(ctx, pool, node)
+		if err != nil {
+			return false, err
+		}
+
+		if sp != nil && running < sp.DesiredInstances {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// waitBudget polls until evicting another of the pool's sandboxes would keep
+// it within MaxUnavailable.
+func (h *PreemptionHandler) waitBudget(ctx context.Context, pool string, poll time.Duration) error {
+	if h.MaxUnavailable <= 0 {
+		return nil
+	}
+
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+
+	for {
+		sp, running, err := h.poolRunning(ctx, pool, "")
+		if err != nil {
+			return err
+		}
+
+		if sp == nil || sp.DesiredInstances-running < h.MaxUnavailable {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// poolRunning returns the pool and how many of its sandboxes are running on
+// nodes other than exclude. The pool is nil if it no longer exists.
+func (h *PreemptionHandler) poolRunning(ctx context.Context, pool string, exclude entity.Id) (*compute.SandboxPool, int64, error) {
+	presp, err := h.EAC.Get(ctx, pool)
+	if err != nil {
+		if errors.Is(err, cond.ErrNotFound{}) {
+			return nil, 0, nil
+		}
+		return nil, 0, err
+	}
+
+	var sp compute.SandboxPool
+	sp.Decode(presp.Entity().Entity())
+
+	// Same lookup the pool manager uses to find its sandboxes.
+	resp, err := h.EAC.List(ctx, entity.Ref(compute.SandboxSpecVersionId, sp.SandboxSpec.Version))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var running int64
+
+	for _, ent := range resp.Values() {
+		var (
+			sb    compute.Sandbox
+			md    core_v1alpha.Metadata
+			sched compute.Schedule
+		)
+
+		sb.Decode(ent.Entity())
+		md.Decode(ent.Entity())
+		sched.Decode(ent.Entity())
+
+		if sb.Status != compute.RUNNING || (exclude != "" && sched.Key.Node == exclude) {
+			continue
+		}
+
+		if p, _ := md.Labels.Get("pool"); p == pool {
+			running++
+		}
+	}
+
+	return &sp, running, nil
+}
@@ -0,0 +1,107 @@
+package sandbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+const defaultNoticePoll = 5 * time.Second
+
+// PreemptionWatcher polls an instance metadata endpoint for a preemption
+// notice. The endpoint follows EC2's spot instance-action: it answers 404
+// until the instance is scheduled to be reclaimed, then returns a body such as
+// {"action": "terminate", "time": "2026-10-16T08:22:00Z"}.
+type PreemptionWatcher struct {
+	Log *slog.Logger
+	URL string
+
+	// Client defaults to an http.Client with a short timeout.
+	Client *http.Client
+
+	// PollInterval controls how often the endpoint is checked. Defaults to 5
+	// seconds, as EC2 recommends.
+	PollInterval time.Duration
+}
+
+type instanceAction struct {
+	Action string    `json:"action"`
+	Time   time.Time `json:"time"`
+}
+
+// Watch polls until a notice is seen and returns its deadline and action.
+// Failed polls are logged and retried, so the only error returned is the
+// context's.
+func (w *PreemptionWatcher) Watch(ctx context.Context) (time.Time, string, error) {
+	client := w.Client
+	if client == nil {
+		client = &http.Client{Timeout: 2 * time.Second}
+	}
+
+	poll := w.PollInterval
+	if poll <= 0 {
+		poll = defaultNoticePoll
+	}
+
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+
+	for {
+		act, err := w.check(ctx, client)
+		if err != nil && ctx.Err() == nil {
+			w.Log.Warn("failed to check for preemption notice", "url", w.URL, "error", err)
+		}
+
+		if act != nil {
+			return act.Time, act.Action, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return time.Time{}, "", ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// check returns the pending instance action, or nil if there is none.
+func (w *PreemptionWatcher) check(ctx context.Context, client *http.Client) (*instanceAction, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, w.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return nil, err
+	}
+
+	var act instanceAction
+	if err := json.Unmarshal(data, &act); err != nil {
+		return nil, fmt.Errorf("invalid preemption notice: %w", err)
+	}
+
+	if act.Time.IsZero() {
+		return nil, fmt.Errorf("preemption notice has no time")
+	}
+
+	return &act, nil
+}
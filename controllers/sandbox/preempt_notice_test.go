@@ -0,0 +1,69 @@
+package sandbox
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"miren.dev/runtime/pkg/entity/testutils"
+)
+
+func TestPreemptionWatcher(t *testing.T) {
+	t.Run("returns the notice once one is posted", func(t *testing.T) {
+		r := require.New(t)
+
+		deadline := time.Date(2026, 10, 16, 8, 22, 0, 0, time.UTC)
+
+		var polls atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			switch polls.Add(1) {
+			case 1, 2:
+				http.NotFound(w, req)
+			case 3:
+				http.Error(w, "busy", http.StatusServiceUnavailable)
+			default:
+				w.Write([]byte(`{"action": "terminate", "time": "2026-10-16T08:22:00Z"}`))
+			}
+		}))
+		defer srv.Close()
+
+		w := &PreemptionWatcher{
+			Log:          testutils.TestLogger(t),
+			URL:          srv.URL,
+			PollInterval: 10 * time.Millisecond,
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		got, action, err := w.Watch(ctx)
+		r.NoError(err)
+		r.True(deadline.Equal(got))
+		r.Equal("terminate", action)
+		r.EqualValues(4, polls.Load())
+	})
+
+	t.Run("stops with the context", func(t *testing.T) {
+		r := require.New(t)
+
+		srv := httptest.NewServer(http.NotFoundHandler())
+		defer srv.Close()
+
+		w := &PreemptionWatcher{
+			Log:          testutils.TestLogger(t),
+			URL:          srv.URL,
+			PollInterval: 10 * time.Millisecond,
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, _, err := w.Watch(ctx)
+		r.ErrorIs(err, context.DeadlineExceeded)
+	})
+}
@@ -0,0 +1,357 @@
+package sandbox
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	compute "miren.dev/runtime/api/compute/compute_v1alpha"
+	"miren.dev/runtime/api/core/core_v1alpha"
+	"miren.dev/runtime/api/entityserver/entityserver_v1alpha"
+	"miren.dev/runtime/controllers/sandboxpool"
+	"miren.dev/runtime/controllers/scheduler"
+	"miren.dev/runtime/pkg/controller"
+	"miren.dev/runtime/pkg/entity"
+	"miren.dev/runtime/pkg/entity/testutils"
+	"miren.dev/runtime/pkg/entity/types"
+)
+
+func TestEvictionOrder(t *testing.T) {
+	r := require.New(t)
+
+	mk := func(id string, priority int64, preemptible bool) *SandboxWithMeta {
+		return &SandboxWithMeta{
+			Sandbox: &compute.Sandbox{
+				ID: entity.Id(id),
+				Spec: compute.SandboxSpec{
+					Priority:    priority,
+					Preemptible: preemptible,
+				},
+			},
+			Metadata: &core_v1alpha.Metadata{},
+		}
+	}
+
+	sandboxes := []*SandboxWithMeta{
+		mk("high", 100, false),
+		mk("default", 0, false),
+		mk("spot-high", 100, true),
+		mk("low", -10, false),
+		mk("spot", 0, true),
+	}
+
+	EvictionOrder(sandboxes)
+
+	var order []string
+	for _, sbm := range sandboxes {
+		order = append(order, sbm.Sandbox.ID.String())
+	}
+
+	r.Equal([]string{"spot", "spot-high", "low", "default", "high"}, order)
+}
+
+func TestPreemptionRelocatesSandboxes(t *testing.T) {
+	r := require.New(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	log := testutils.TestLogger(t)
+
+	server, cleanup := testutils.NewInMemEntityServer(t)
+	defer cleanup()
+
+	nodeA, err := server.Client.Create(ctx, "a", &compute.Node{Status: compute.READY})
+	r.NoError(err)
+
+	web := &compute.SandboxPool{
+		Service:          "web",
+		DesiredInstances: 1,
+		SandboxSpec: compute.SandboxSpec{
+			Version:   entity.Id("ver-web"),
+			Priority:  10,
+			Container: []compute.SandboxSpecContainer{{Image: "web:latest"}},
+		},
+	}
+	web.ID, err = server.Client.Create(ctx, "web", web)
+	r.NoError(err)
+
+	batch := &compute.SandboxPool{
+		Service:          "batch",
+		DesiredInstances: 1,
+		SandboxSpec: compute.SandboxSpec{
+			Version:     entity.Id("ver-batch"),
+			Preemptible: true,
+			Container:   []compute.SandboxSpecContainer{{Image: "batch:latest"}},
+		},
+	}
+	batch.ID, err = server.Client.Create(ctx, "batch", batch)
+	r.NoError(err)
+
+	pools := sandboxpool.NewManager(log, server.EAC)
+	sched := scheduler.NewController(log, server.EAC)
+
+	rc := controller.NewReconcileController(
+		"test-scheduler",
+		log,
+		entity.Ref(entity.EntityKind, compute.KindSandbox),
+		server.EAC,
+		controller.AdaptReconcileController[compute.Sandbox](sched),
+		0,
+		1,
+	)
+
+	// step plays the part of the rest of the cluster: pools create sandboxes,
+	// the scheduler places them, and each node's sandbox controller brings
+	// them up.
+	step := func() error {
+		var sandboxes []*entityserver_v1alpha.Entity
+
+		for _, id := range []entity.Id{web.ID, batch.ID} {
+			resp, err := server.EAC.Get(ctx, id.String())
+			if err != nil {
+				return err
+			}
+
+			var pool compute.SandboxPool
+			pool.Decode(resp.Entity().Entity())
+
+			if err := pools.Reconcile(ctx, &pool, nil); err != nil {
+				return err
+			}
+
+			lr, err := server.EAC.List(ctx, entity.Ref(compute.SandboxSpecVersionId, pool.SandboxSpec.Version))
+			if err != nil {
+				return err
+			}
+
+			sandboxes = append(sandboxes, lr.Values()...)
+		}
+
+		for _, ent := range sandboxes {
+			var sb compute.Sandbox
+			sb.Decode(ent.Entity())
+
+			if sb.Status != compute.PENDING {
+				continue
+			}
+
+			if _, ok := ent.Entity().Get(compute.ScheduleKeyId); !ok {
+				err := rc.ProcessEventForTest(ctx, controller.Event{
+					Type:   controller.EventAdded,
+					Id:     sb.ID,
+					Entity: ent.Entity(),
+				})
+				if err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			_, err := server.EAC.Patch(ctx, entity.New(
+				entity.DBId, sb.ID,
+				(&compute.Sandbox{Status: compute.RUNNING}).Encode,
+			).Attrs(), 0)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	// Bring up the initial sandboxes, all on node a since it's the only one.
+	for range 3 {
+		r.NoError(step())
+	}
+
+	original, err := (&PreemptionHandler{EAC: server.EAC}).nodeSandboxes(ctx, nodeA)
+	r.NoError(err)
+	r.Len(original, 2)
+
+	for _, sbm := range original {
+		r.Equal(compute.RUNNING, sbm.Sandbox.Status)
+	}
+
+	nodeB, err := server.Client.Create(ctx, "b", &compute.Node{Status: compute.READY})
+	r.NoError(err)
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	loopCtx, loopCancel := context.WithCancel(ctx)
+	defer loopCancel()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(20 * time.Millisecond)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-loopCtx.Done():
+				return
+			case <-ticker.C:
+				if err := step(); err != nil && loopCtx.Err() == nil {
+					t.Errorf("cluster step failed: %v", err)
+					return
+				}
+			}
+		}
+	}()
+
+	var drains atomic.Int32
+
+	h := &PreemptionHandler{
+		Log: log,
+		EAC: server.EAC,
+		Drain: func(ctx context.Context) error {
+			drains.Add(1)
+			return nil
+		},
+		MaxUnavailable: 1,
+		Grace:          time.Second,
+		PollInterval:   20 * time.Millisecond,
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	res, err := h.HandleNotice(ctx, PreemptionNotice{
+		Node:     nodeA,
+		Deadline: deadline,
+		Reason:   "spot termination",
+	})
+	r.NoError(err)
+	r.True(time.Now().Before(deadline), "preemption should finish within the deadline")
+
+	loopCancel()
+	wg.Wait()
+
+	r.True(res.Relocated)
+	r.Len(res.Evicted, 2)
+
+	// The preemptible batch sandbox goes first.
+	batchOrig, webOrig := original[0], original[1]
+	if batchOrig.Sandbox.Spec.Version != batch.SandboxSpec.Version {
+		batchOrig, webOrig = webOrig, batchOrig
+	}
+
+	r.Equal([]entity.Id{batchOrig.Sandbox.ID, webOrig.Sandbox.ID}, res.Evicted)
+	r.True(res.Drained)
+	r.EqualValues(1, drains.Load())
+
+	nresp, err := server.EAC.Get(ctx, nodeA.String())
+	r.NoError(err)
+
+	var node compute.Node
+	node.Decode(nresp.Entity().Entity())
+	r.Equal(compute.DISABLED, node.Status)
+
+	running := map[entity.Id]int{}
+
+	var sandboxes []*entityserver_v1alpha.Entity
+	for _, version := range []entity.Id{web.SandboxSpec.Version, batch.SandboxSpec.Version} {
+		resp, err := server.EAC.List(ctx, entity.Ref(compute.SandboxSpecVersionId, version))
+		r.NoError(err)
+
+		sandboxes = append(sandboxes, resp.Values()...)
+	}
+
+	for _, ent := range sandboxes {
+		var (
+			sb compute.Sandbox
+			sc compute.Schedule
+		)
+
+		sb.Decode(ent.Entity())
+		sc.Decode(ent.Entity())
+
+		switch sb.ID {
+		case batchOrig.Sandbox.ID, webOrig.Sandbox.ID:
+			r.Equal(compute.STOPPED, sb.Status)
+			r.Equal(nodeA, sc.Key.Node)
+		default:
+			if sb.Status == compute.RUNNING {
+				r.Equal(nodeB, sc.Key.Node, "replacements should land on the healthy node")
+				running[sb.Spec.Version]++
+			}
+		}
+	}
+
+	r.Equal(1, running[web.SandboxSpec.Version])
+	r.Equal(1, running[batch.SandboxSpec.Version])
+}
+
+func TestPreemptionBudget(t *testing.T) {
+	r := require.New(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	server, cleanup := testutils.NewInMemEntityServer(t)
+	defer cleanup()
+
+	pool := &compute.SandboxPool{
+		Service:          "web",
+		DesiredInstances: 2,
+		SandboxSpec: compute.SandboxSpec{
+			Version: entity.Id("ver-web"),
+		},
+	}
+
+	var err error
+	pool.ID, err = server.Client.Create(ctx, "web", pool)
+	r.NoError(err)
+
+	create := func(name string) entity.Id {
+		resp, err := server.EAC.Create(ctx, entity.New(
+			(&core_v1alpha.Metadata{
+				Name:   name,
+				Labels: types.LabelSet("pool", pool.ID.String()),
+			}).Encode,
+			entity.DBId, entity.Id("sandbox/"+name),
+			(&compute.Sandbox{Status: compute.RUNNING, Spec: pool.SandboxSpec}).Encode,
+		).Attrs())
+		r.NoError(err)
+
+		return entity.Id(resp.Id())
+	}
+
+	first := create("web-1")
+	create("web-2")
+
+	h := &PreemptionHandler{
+		EAC:            server.EAC,
+		MaxUnavailable: 1,
+	}
+
+	wait := func() error {
+		ctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		defer cancel()
+
+		return h.waitBudget(ctx, pool.ID.String(), 10*time.Millisecond)
+	}
+
+	// Nothing is unavailable yet, so the first eviction can go ahead.
+	r.NoError(wait())
+
+	_, err = server.EAC.Patch(ctx, entity.New(
+		entity.DBId, first,
+		(&compute.Sandbox{Status: compute.STOPPED}).Encode,
+	).Attrs(), 0)
+	r.NoError(err)
+
+	// With one sandbox down the budget is spent until a replacement runs.
+	r.ErrorIs(wait(), context.DeadlineExceeded)
+
+	create("web-3")
+
+	r.NoError(wait())
+}
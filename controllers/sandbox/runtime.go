@@ -0,0 +1,75 @@
+package sandbox
+
+import (
+	"fmt"
+	"os/exec"
+	"slices"
+
+	compute "miren.dev/runtime/api/compute/compute_v1alpha"
+)
+
+// DefaultRuntimeClass is the runtime class of sandboxes that don't set one.
+const DefaultRuntimeClass = "runc"
+
+// runtimeClasses maps the runtime classes a sandbox can ask for to the
+// containerd runtime handler that runs them. runc is for trusted workloads;
+// runsc runs the sandbox under gVisor, for untrusted ones.
+var runtimeClasses = map[string]string{
+	"runc":  "io.containerd.runc.v2",
+	"runsc": "io.containerd.runsc.v1",
+}
+
+// runtimeShims are the shim binaries containerd runs for each runtime class,
+// which must be on the PATH for the class to be usable.
+var runtimeShims = map[string]string{
+	"runc":  "containerd-shim-runc-v2",
+	"runsc": "containerd-shim-runsc-v1",
+}
+
+var lookPath = exec.LookPath
+
+// InstalledRuntimeClasses returns the runtime classes whose containerd shim
+// is installed.
+func InstalledRuntimeClasses() []string {
+	var classes []string
+
+	for class, shim := range runtimeShims {
+		if _, err := lookPath(shim); err == nil {
+			classes = append(classes, class)
+		}
+	}
+
+	slices.Sort(classes)
+
+	return classes
+}
+
+// AvailableRuntimeClasses returns the runtime classes sandboxes can use on
+// this node, which are reported on the node entity.
+func (c *SandboxController) AvailableRuntimeClasses() []string {
+	if len(c.RuntimeClasses) > 0 {
+		return c.RuntimeClasses
+	}
+
+	return InstalledRuntimeClasses()
+}
+
+// runtimeHandler returns the containerd runtime handler for a sandbox's
+// runtime class, rejecting classes that aren't available on this node.
+func (c *SandboxController) runtimeHandler(sb *compute.Sandbox) (string, error) {
+	class := sb.Spec.RuntimeClass
+	if class == "" {
+		class = DefaultRuntimeClass
+	}
+
+	handler, ok := runtimeClasses[class]
+	if !ok {
+		return "", fmt.Errorf("unknown runtime class %q", class)
+	}
+
+	if !slices.Contains(c.AvailableRuntimeClasses(), class) {
+		return "", fmt.Errorf("runtime class %s is not available on this node", class)
+	}
+
+	return handler, nil
+}
@@ -0,0 +1,74 @@
+package sandbox
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	compute "miren.dev/runtime/api/compute/compute_v1alpha"
+)
+
+func TestRuntimeHandler(t *testing.T) {
+	sandbox := func(class string) *compute.Sandbox {
+		return &compute.Sandbox{Spec: compute.SandboxSpec{RuntimeClass: class}}
+	}
+
+	t.Run("selects the containerd handler for the class", func(t *testing.T) {
+		r := require.New(t)
+
+		c := &SandboxController{
+			Log:            slog.Default(),
+			RuntimeClasses: []string{"runc", "runsc"},
+		}
+
+		handler, err := c.runtimeHandler(sandbox("runsc"))
+		r.NoError(err)
+		r.Equal("io.containerd.runsc.v1", handler)
+
+		handler, err = c.runtimeHandler(sandbox("runc"))
+		r.NoError(err)
+		r.Equal("io.containerd.runc.v2", handler)
+
+		handler, err = c.runtimeHandler(sandbox(""))
+		r.NoError(err)
+		r.Equal("io.containerd.runc.v2", handler)
+	})
+
+	t.Run("rejects classes the node doesn't have", func(t *testing.T) {
+		r := require.New(t)
+
+		c := &SandboxController{
+			Log:            slog.Default(),
+			RuntimeClasses: []string{"runc"},
+		}
+
+		_, err := c.runtimeHandler(sandbox("runsc"))
+		r.ErrorContains(err, "not available on this node")
+
+		_, err = c.runtimeHandler(sandbox("kata"))
+		r.ErrorContains(err, "unknown runtime class")
+	})
+
+	t.Run("finds the installed shims", func(t *testing.T) {
+		r := require.New(t)
+
+		defer func(orig func(string) (string, error)) { lookPath = orig }(lookPath)
+
+		lookPath = func(name string) (string, error) {
+			if name == "containerd-shim-runc-v2" {
+				return "/usr/bin/" + name, nil
+			}
+
+			return "", errors.New("not found")
+		}
+
+		c := &SandboxController{Log: slog.Default()}
+
+		r.Equal([]string{"runc"}, c.AvailableRuntimeClasses())
+
+		_, err := c.runtimeHandler(sandbox("runsc"))
+		r.ErrorContains(err, "not available on this node")
+	})
+}
@@ -81,6 +81,33 @@ type SandboxController struct {
 	Resolver netresolve.Resolver
 	Metrics  *Metrics
 
+	// TerminationGrace is how long containers whose spec sets no grace have
+	// to exit after SIGTERM before being killed. Zero uses 10 seconds.
+	TerminationGrace time.Duration
+
+	// HostPathAllowlist holds the host path prefixes that containers may
+	// bind mount. When empty, host path mounts are rejected.
+	HostPathAllowlist []string `asm:"host-path-allowlist,optional"`
+
+	// SysctlAllowlist holds the sysctls containers may set in addition to
+	// the safe ones that are always allowed.
+	SysctlAllowlist []string `asm:"sysctl-allowlist,optional"`
+
+	// RuntimeClasses holds the runtime classes sandboxes may use. When
+	// empty, it's those whose containerd shim is installed.
+	RuntimeClasses []string `asm:"runtime-classes,optional"`
+
+	// Addons provides the values of env vars that come from addon
+	// instances. Sandboxes that use them fail to start without it.
+	Addons AddonValues `asm:"addon-values,optional"`
+
+	// TraceIDPatterns and TraceIDFields configure how the trace IDs of
+	// container log lines are found. See observability.TraceIDExtractor.
+	TraceIDPatterns []string `asm:"log-trace-id-patterns,optional"`
+	TraceIDFields   []string `asm:"log-trace-id-fields,optional"`
+
+	traceIDs *observability.TraceIDExtractor
+
 	topCtx context.Context
 	cancel func()
 
@@ -103,6 +130,14 @@ type SandboxController struct {
 
 func (c *SandboxController) Populated() error {
 	c.Log = c.Log.With("module", "sandbox")
+
+	traceIDs, err := observability.NewTraceIDExtractor(c.TraceIDPatterns, c.TraceIDFields)
+	if err != nil {
+		return err
+	}
+
+	c.traceIDs = traceIDs
+
 	return nil
 }
 
@@ -652,6 +687,16 @@ func (c *SandboxController) Create(ctx context.Context, co *compute.Sandbox, met
 
 	switch co.Status {
 	case compute.DEAD:
+		// A sandbox is marked DEAD while its containers still exist here
+		// when this node was partitioned long enough for it to be
+		// rescheduled. The replacement is running elsewhere, so stop this
+		// one rather than leave a duplicate.
+		_, err := c.CC.LoadContainer(namespaces.WithNamespace(ctx, c.Namespace), pauseContainerId(co.ID))
+		if err == nil {
+			c.Log.Warn("sandbox was rescheduled while still running here, stopping it", "id", co.ID)
+			return c.stopSandbox(ctx, co.ID)
+		}
+
 		return nil
 	case compute.STOPPED:
 		c.Log.Debug("sandbox is stopped, verifying it is no longer running")
@@ -746,6 +791,11 @@ func (c *SandboxController) createSandbox(ctx context.Context, co *compute.Sandb
 
 	ctx = namespaces.WithNamespace(ctx, c.Namespace)
 
+	// Check the runtime class before allocating anything for the sandbox
+	if _, err := c.runtimeHandler(co); err != nil {
+		return err
+	}
+
 	ep, err := c.allocateNetwork(ctx, co)
 	if err != nil {
 		return fmt.Errorf("failed to allocate network: %w", err)
@@ -807,7 +857,7 @@ func (c *SandboxController) createSandbox(ctx context.Context, co *compute.Sandb
 			c.deallocateNetwork(ctx, ep)
 
 			// Clean up any subcontainers that might have been created
-			c.destroySubContainers(ctx, co.ID)
+			_, _ = c.destroySubContainers(ctx, co.ID)
 
 			// Clean up the pause container using the common cleanup function
 			c.cleanupContainer(ctx, container)
@@ -1175,6 +1225,11 @@ func (c *SandboxController) buildSpec(
 	[]containerd.NewContainerOpts,
 	error,
 ) {
+	runtime, err := c.runtimeHandler(sb)
+	if err != nil {
+		return nil, err
+	}
+
 	img, err := c.CC.GetImage(ctx, sandboxImage)
 	if err != nil {
 		// If the image is not found, we can try to pull it.
@@ -1307,7 +1362,7 @@ func (c *SandboxController) buildSpec(
 	opts = append(opts,
 		containerd.WithNewSnapshot(id, img),
 		containerd.WithNewSpec(specOpts...),
-		containerd.WithRuntime("io.containerd.runc.v2", nil),
+		containerd.WithRuntime(runtime, nil),
 		containerd.WithAdditionalContainerLabels(lbls),
 	)
 
@@ -1361,7 +1416,13 @@ func (c *SandboxController) logConsumer(sb *compute.Sandbox, container string) *
 		attrs[lbl.Key] = lbl.Value
 	}
 
-	return NewSandboxLogs(c.Log, le, attrs, c.LogWriter)
+	sl := NewSandboxLogs(c.Log, le, attrs, c.LogWriter).WithTraceIDs(c.traceIDs)
+
+	if sb.Spec.StructuredLogs {
+		sl = sl.Structured()
+	}
+
+	return sl
 }
 
 func (c *SandboxController) bootInitialTask(
@@ -1642,6 +1703,11 @@ func (c *SandboxController) buildSubContainerSpec(
 	[]containerd.NewContainerOpts,
 	error,
 ) {
+	runtime, err := c.runtimeHandler(sb)
+	if err != nil {
+		return nil, err
+	}
+
 	img, err := c.CC.GetImage(ctx, co.Image)
 	if err != nil {
 		// If the image is not found, we can try to pull it.
@@ -1735,6 +1801,20 @@ func (c *SandboxController) buildSubContainerSpec(
 	}
 
 	for _, m := range co.Mount {
+		if m.HostPath != "" {
+			hm, err := c.hostPathMount(m)
+			if err != nil {
+				return nil, err
+			}
+
+			c.Log.Debug("adding host path mount",
+				"host_path", hm.Source,
+				"container_dest", m.Destination)
+
+			mounts = append(mounts, hm)
+			continue
+		}
+
 		var rawPath string
 		var ok bool
 
@@ -1826,6 +1906,13 @@ func (c *SandboxController) buildSubContainerSpec(
 		c.Log.Debug("injected instance number into container env", "sandbox_id", sb.ID, "container", co.Name, "instance", instanceStr)
 	}
 
+	addonEnv, err := c.addonEnv(ctx, sb, co)
+	if err != nil {
+		return nil, err
+	}
+
+	envVars = append(slices.Clip(envVars), addonEnv...)
+
 	specOpts := []oci.SpecOpts{
 		oci.WithImageConfig(img),
 		oci.WithDefaultUnixDevices,
@@ -1855,6 +1942,24 @@ func (c *SandboxController) buildSubContainerSpec(
 		specOpts = append(specOpts, containerdx.WithOOMScoreAdj(int(co.OomScore), false))
 	}
 
+	sysctls, err := c.sysctls(co)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sysctls) > 0 {
+		specOpts = append(specOpts, containerdx.WithSysctls(sysctls))
+	}
+
+	rls, err := rlimits(co)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rls) > 0 {
+		specOpts = append(specOpts, containerdx.WithRlimits(rls))
+	}
+
 	if co.Privileged {
 		specOpts = append(specOpts,
 			oci.WithPrivileged,
@@ -1868,8 +1973,16 @@ func (c *SandboxController) buildSubContainerSpec(
 		specOpts = append(specOpts, oci.WithTTY)
 	}
 
+	secOpts, err := securityProfiles(co)
+	if err != nil {
+		return nil, err
+	}
+
+	specOpts = append(specOpts, secOpts...)
+
 	lbls := map[string]string{}
 	lbls[sandboxEntityLabel] = sb.ID.String()
+	lbls[terminationGraceLabel] = c.terminationGrace(co).String()
 
 	if sb.Spec.Version != "" {
 		lbls[sandboxVerEntityLabel] = sb.Spec.Version.String()
@@ -1878,37 +1991,50 @@ func (c *SandboxController) buildSubContainerSpec(
 	opts = append(opts,
 		containerd.WithNewSnapshot(id, img),
 		containerd.WithNewSpec(specOpts...),
-		containerd.WithRuntime("io.containerd.runc.v2", nil),
+		containerd.WithRuntime(runtime, nil),
 		containerd.WithAdditionalContainerLabels(lbls),
 	)
 
 	return opts, nil
 }
 
-func (c *SandboxController) destroySubContainers(ctx context.Context, id entity.Id) error {
+// destroySubContainers stops and deletes the containers of a sandbox, other
+// than its pause container, returning how their tasks were stopped.
+func (c *SandboxController) destroySubContainers(ctx context.Context, id entity.Id) (compute.Termination, error) {
 	ctx = namespaces.WithNamespace(ctx, c.Namespace)
 
 	// Discover subcontainers from containerd
 	containerList, err := c.CC.Containers(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to list containers: %w", err)
+		return compute.Termination{}, fmt.Errorf("failed to list containers: %w", err)
 	}
 
 	prefix := containerPrefix(id)
-	var containerIds []string
+	var (
+		containerIds  []string
+		subContainers []containerd.Container
+	)
 	for _, cont := range containerList {
 		containerID := cont.ID()
 		if strings.HasPrefix(containerID, prefix+"-") {
 			containerIds = append(containerIds, containerID)
+			subContainers = append(subContainers, cont)
 		}
 	}
 
 	if len(containerIds) == 0 {
 		c.Log.Debug("no subcontainers found to destroy", "id", id)
-		return nil
+		return compute.Termination{}, nil
 	}
 
-	// Set up timeout for the entire operation (1 minute max)
+	// Give each container its grace to exit before the deadline below
+	// applies, so a long grace isn't cut short.
+	term := c.terminateContainers(ctx, subContainers)
+
+	c.Log.Info("subcontainers terminated", "id", id,
+		"duration", term.Duration, "force_killed", term.ForceKilled, "ignored_sigterm", term.IgnoredSigterm)
+
+	// Set up timeout for deleting the containers (1 minute max)
 	timeout := 60 * time.Second
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
@@ -1930,9 +2056,9 @@ func (c *SandboxController) destroySubContainers(ctx context.Context, id entity.
 		select {
 		case <-ctx.Done():
 			if ctx.Err() == context.DeadlineExceeded {
-				return fmt.Errorf("failed to destroy subcontainers within %v timeout", timeout)
+				return term, fmt.Errorf("failed to destroy subcontainers within %v timeout", timeout)
 			}
-			return fmt.Errorf("context cancelled while destroying subcontainers: %w", ctx.Err())
+			return term, fmt.Errorf("context cancelled while destroying subcontainers: %w", ctx.Err())
 		default:
 		}
 
@@ -1952,20 +2078,9 @@ func (c *SandboxController) destroySubContainers(ctx context.Context, id entity.
 			}
 			remainingContainers = append(remainingContainers, id)
 
-			// Try to kill and delete the task
+			// Delete the task, killing it if it's somehow still running
 			task, err := cont.Task(ctx, nil)
 			if err == nil {
-				// First try SIGTERM
-				err = task.Kill(ctx, unix.SIGTERM)
-				if err != nil {
-					c.Log.Debug("failed to send SIGTERM", "id", id, "err", err)
-				} else {
-					c.Log.Debug("sent SIGTERM to task", "id", id)
-				}
-
-				// Wait a bit then try to delete the task
-				time.Sleep(50 * time.Millisecond)
-
 				_, err = task.Delete(ctx, containerd.WithProcessKill)
 				if err != nil {
 					c.Log.Debug("failed to delete task", "id", id, "err", err)
@@ -1988,7 +2103,7 @@ func (c *SandboxController) destroySubContainers(ctx context.Context, id entity.
 		// If no containers remain, we're done
 		if len(remainingContainers) == 0 {
 			c.Log.Info("all subcontainers destroyed successfully", "id", id)
-			return nil
+			return term, nil
 		}
 
 		// Update the list of containers to check in the next iteration
@@ -1999,9 +2114,9 @@ func (c *SandboxController) destroySubContainers(ctx context.Context, id entity.
 		select {
 		case <-ctx.Done():
 			if ctx.Err() == context.DeadlineExceeded {
-				return fmt.Errorf("failed to destroy subcontainers within %v timeout, remaining: %v", timeout, containerIds)
+				return term, fmt.Errorf("failed to destroy subcontainers within %v timeout, remaining: %v", timeout, containerIds)
 			}
-			return fmt.Errorf("context cancelled while destroying subcontainers: %w", ctx.Err())
+			return term, fmt.Errorf("context cancelled while destroying subcontainers: %w", ctx.Err())
 		case <-time.After(retryInterval):
 			// Exponential backoff with max limit
 			retryInterval = time.Duration(float64(retryInterval) * 1.5)
@@ -2094,7 +2209,7 @@ func (c *SandboxController) stopSandbox(ctx context.Context, id entity.Id) error
 
 	// Destroy subcontainers - this will discover them from containerd
 	c.Log.Debug("destroying subcontainers", "id", id)
-	err = c.destroySubContainers(ctx, id)
+	term, err := c.destroySubContainers(ctx, id)
 	if err != nil {
 		c.Log.Error("failed to destroy subcontainers", "id", id, "err", err)
 		// Continue with cleanup even if this fails
@@ -2144,11 +2259,12 @@ func (c *SandboxController) stopSandbox(ctx context.Context, id entity.Id) error
 	tmpDir := filepath.Join(c.Tempdir, "containerd", id.PathSafe())
 	_ = os.RemoveAll(tmpDir)
 
-	// Mark sandbox as DEAD in entity store
+	// Mark sandbox as DEAD in entity store, recording how it was terminated
 	result, err := c.EAC.Patch(ctx, entity.New(
 		entity.Ref(entity.DBId, id),
 		(&compute.Sandbox{
-			Status: compute.DEAD,
+			Status:      compute.DEAD,
+			Termination: term,
 		}).Encode,
 	).Attrs(), 0)
 	if err != nil {
@@ -21,6 +21,8 @@ import (
 	"github.com/containerd/containerd/v2/pkg/cio"
 	"github.com/containerd/containerd/v2/pkg/namespaces"
 	"github.com/containerd/containerd/v2/pkg/oci"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/require"
 
 	"github.com/opencontainers/runtime-spec/specs-go"
@@ -398,6 +400,117 @@ func TestSandbox(t *testing.T) {
 		r.Greater(cpuSeconds, 0.5, "should have recorded at least 0.5 CPU seconds")
 	})
 
+	t.Run("resolves addon env vars into the container env", func(t *testing.T) {
+		r := require.New(t)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+		defer cancel()
+
+		reg, cleanup := testutils.Registry(observability.TestInject, build.TestInject)
+		defer cleanup()
+
+		var (
+			cc  *containerd.Client
+			bkl *build.Buildkit
+		)
+
+		err := reg.Init(&cc, &bkl)
+		r.NoError(err)
+
+		dfr, err := tarx.MakeTar("testdata/sort", nil)
+		r.NoError(err)
+
+		datafs, err := tarx.TarFS(dfr, t.TempDir())
+		r.NoError(err)
+
+		o, _, err := bkl.Transform(ctx, datafs)
+		r.NoError(err)
+
+		var ii image.ImageImporter
+
+		err = reg.Populate(&ii)
+		r.NoError(err)
+
+		err = ii.ImportImage(ctx, o, "mn-sort:latest")
+		r.NoError(err)
+
+		ctx = namespaces.WithNamespace(ctx, ii.Namespace)
+
+		var co SandboxController
+
+		err = reg.Populate(&co)
+		r.NoError(err)
+
+		co.Addons = fakeAddons{
+			"db/url": "postgres://db:5432/app",
+		}
+
+		defer co.Close()
+		r.NoError(co.Init(ctx))
+
+		id := entity.Id(sbName())
+
+		var sb compute.Sandbox
+
+		sb.ID = id
+
+		sb.Spec.Container = append(sb.Spec.Container, compute.SandboxSpecContainer{
+			Name:  "sort",
+			Image: "mn-sort:latest",
+			Env:   []string{"LOG_LEVEL=debug"},
+			AddonEnv: []compute.SandboxSpecContainerAddonEnv{
+				{Name: "DATABASE_URL", Addon: "db", Key: "url"},
+			},
+		})
+
+		var rpcE entityserver_v1alpha.Entity
+		rpcE.SetId(id.String())
+		rpcE.SetAttrs(entity.New(
+			entity.DBId, id,
+			sb.Encode).Attrs())
+		_, err = co.EAC.Put(ctx, &rpcE)
+		r.NoError(err)
+
+		result, err := co.EAC.Get(ctx, id.String())
+		r.NoError(err)
+
+		meta := &entity.Meta{
+			Entity:   result.Entity().Entity(),
+			Revision: result.Entity().Revision(),
+		}
+
+		var tco compute.Sandbox
+		tco.Decode(result.Entity().Entity())
+
+		err = co.Create(ctx, &tco, meta)
+		r.NoError(err)
+
+		pc, err := cc.LoadContainer(ctx, pauseContainerId(id))
+		r.NoError(err)
+
+		defer testutils.ClearContainer(ctx, pc)
+
+		c, err := cc.LoadContainer(ctx, containerPrefix(id)+"-sort")
+		r.NoError(err)
+
+		defer testutils.ClearContainer(ctx, c)
+
+		spec, err := c.Spec(ctx)
+		r.NoError(err)
+
+		r.Contains(spec.Process.Env, "DATABASE_URL=postgres://db:5432/app")
+		r.Contains(spec.Process.Env, "LOG_LEVEL=debug")
+
+		// The value is only ever in the container, never the sandbox
+		got, err := co.EAC.Get(ctx, id.String())
+		r.NoError(err)
+
+		var stored compute.Sandbox
+		stored.Decode(got.Entity().Entity())
+
+		r.NotContains(stored.Spec.Container[0].Env, "DATABASE_URL=postgres://db:5432/app")
+	})
+
 	t.Run("configures networking", func(t *testing.T) {
 		r := require.New(t)
 
@@ -1391,6 +1504,92 @@ func TestSandbox(t *testing.T) {
 		r.NoError(err)
 	})
 
+	t.Run("force kills containers that ignore SIGTERM", func(t *testing.T) {
+		r := require.New(t)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+		defer cancel()
+
+		reg, cleanup := testutils.Registry(observability.TestInject, build.TestInject)
+		defer cleanup()
+
+		var co SandboxController
+		err := reg.Populate(&co)
+		r.NoError(err)
+
+		defer checkClosed(t, &co)
+
+		r.NoError(co.Init(ctx))
+
+		ctx = namespaces.WithNamespace(ctx, co.Namespace)
+
+		id := entity.Id(sbName())
+
+		var sb compute.Sandbox
+		sb.ID = id
+		sb.Labels = append(sb.Labels, "runtime.computer/app=ignores-sigterm")
+		sb.Spec = compute.SandboxSpec{
+			Container: []compute.SandboxSpecContainer{
+				{
+					Name:             "stubborn",
+					Image:            "docker.io/library/busybox:latest",
+					Command:          `sh -c 'trap "" TERM; while true; do sleep 1; done'`,
+					TerminationGrace: time.Second,
+				},
+			},
+		}
+
+		var rpcE entityserver_v1alpha.Entity
+		rpcE.SetId(id.String())
+		rpcE.SetAttrs(entity.New(
+			entity.DBId, id,
+			sb.Encode).Attrs())
+		_, err = co.EAC.Put(ctx, &rpcE)
+		r.NoError(err)
+
+		result, err := co.EAC.Get(ctx, id.String())
+		r.NoError(err)
+
+		meta := &entity.Meta{
+			Entity:   result.Entity().Entity(),
+			Revision: result.Entity().Revision(),
+		}
+
+		var tco compute.Sandbox
+		tco.Decode(result.Entity().Entity())
+
+		err = co.Create(ctx, &tco, meta)
+		r.NoError(err)
+
+		durationSamples := func() uint64 {
+			var m dto.Metric
+			r.NoError(terminationDuration.Write(&m))
+			return m.GetHistogram().GetSampleCount()
+		}
+
+		forceKills := testutil.ToFloat64(terminationForceKills)
+		ignored := testutil.ToFloat64(terminationIgnoredSigterm)
+		samples := durationSamples()
+
+		err = co.Delete(ctx, id)
+		r.NoError(err)
+
+		r.Equal(forceKills+1, testutil.ToFloat64(terminationForceKills))
+		r.Equal(ignored+1, testutil.ToFloat64(terminationIgnoredSigterm))
+		r.Equal(samples+1, durationSamples())
+
+		result, err = co.EAC.Get(ctx, id.String())
+		r.NoError(err)
+
+		var dead compute.Sandbox
+		dead.Decode(result.Entity().Entity())
+
+		r.Equal(compute.DEAD, dead.Status)
+		r.True(dead.Termination.ForceKilled)
+		r.Equal(int64(1), dead.Termination.IgnoredSigterm)
+		r.GreaterOrEqual(dead.Termination.Duration, time.Second, "should wait out the grace before killing")
+	})
+
 	t.Run("maps legacy port protocols correctly", func(t *testing.T) {
 		r := require.New(t)
 
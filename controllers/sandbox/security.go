@@ -0,0 +1,101 @@
+package sandbox
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/containerd/containerd/v2/contrib/apparmor"
+	"github.com/containerd/containerd/v2/contrib/seccomp"
+	aasupport "github.com/containerd/containerd/v2/pkg/apparmor"
+	"github.com/containerd/containerd/v2/pkg/oci"
+	"github.com/opencontainers/runtime-spec/specs-go"
+	compute "miren.dev/runtime/api/compute/compute_v1alpha"
+)
+
+const (
+	profileDefault    = "default"
+	profileUnconfined = "unconfined"
+
+	// defaultAppArmorProfile is the name the runtime's AppArmor profile is
+	// loaded under.
+	defaultAppArmorProfile = "miren-default"
+)
+
+// apparmorSupported reports whether the node enforces AppArmor profiles.
+var apparmorSupported = aasupport.HostSupports
+
+// securityProfiles returns the spec options enforcing a container's seccomp
+// and AppArmor profiles. Containers get the runtime's default seccomp
+// profile unless they ask otherwise, except privileged ones, which are
+// unconfined by default. A custom seccomp profile is checked here so a bad
+// one fails the sandbox before anything is launched.
+//
+// They have to be applied after the container's capabilities are set, as
+// the default seccomp profile allows syscalls based on them.
+func securityProfiles(co *compute.SandboxSpecContainer) ([]oci.SpecOpts, error) {
+	var opts []oci.SpecOpts
+
+	switch profile := co.SeccompProfile; profile {
+	case "":
+		if !co.Privileged {
+			opts = append(opts, seccomp.WithDefaultProfile())
+		}
+	case profileDefault:
+		opts = append(opts, seccomp.WithDefaultProfile())
+	case profileUnconfined:
+	default:
+		if err := checkSeccompProfile(profile); err != nil {
+			return nil, err
+		}
+
+		opts = append(opts, seccomp.WithProfile(profile))
+	}
+
+	switch profile := co.ApparmorProfile; profile {
+	case "", profileUnconfined:
+	default:
+		if !apparmorSupported() {
+			return nil, fmt.Errorf("apparmor profile %s requested, but AppArmor is not enabled on this node", profile)
+		}
+
+		if profile == profileDefault {
+			opts = append(opts, apparmor.WithDefaultProfile(defaultAppArmorProfile))
+			break
+		}
+
+		if strings.ContainsAny(profile, " \t\n\x00/") {
+			return nil, fmt.Errorf("invalid apparmor profile name: %q", profile)
+		}
+
+		opts = append(opts, apparmor.WithProfile(profile))
+	}
+
+	return opts, nil
+}
+
+// checkSeccompProfile makes sure path holds a seccomp profile runc can load.
+func checkSeccompProfile(path string) error {
+	if !filepath.IsAbs(path) {
+		return fmt.Errorf("seccomp profile must be default, unconfined or an absolute path: %q", path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading seccomp profile: %w", err)
+	}
+
+	var profile specs.LinuxSeccomp
+
+	if err := json.Unmarshal(data, &profile); err != nil {
+		return fmt.Errorf("decoding seccomp profile %s: %w", path, err)
+	}
+
+	if profile.DefaultAction == "" {
+		return fmt.Errorf("seccomp profile %s has no defaultAction", path)
+	}
+
+	return nil
+}
@@ -0,0 +1,124 @@
+package sandbox
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/stretchr/testify/require"
+
+	compute "miren.dev/runtime/api/compute/compute_v1alpha"
+)
+
+func TestSecurityProfiles(t *testing.T) {
+	// spec applies the options for co to an empty spec, as containerd would.
+	spec := func(t *testing.T, co *compute.SandboxSpecContainer) (*specs.Spec, error) {
+		opts, err := securityProfiles(co)
+		if err != nil {
+			return nil, err
+		}
+
+		s := &specs.Spec{
+			Process: &specs.Process{Capabilities: &specs.LinuxCapabilities{}},
+			Linux:   &specs.Linux{},
+		}
+
+		for _, opt := range opts {
+			require.NoError(t, opt(context.Background(), nil, nil, s))
+		}
+
+		return s, nil
+	}
+
+	writeProfile := func(t *testing.T, data string) string {
+		path := filepath.Join(t.TempDir(), "profile.json")
+		require.NoError(t, os.WriteFile(path, []byte(data), 0644))
+		return path
+	}
+
+	t.Run("uses the default seccomp profile when unset", func(t *testing.T) {
+		r := require.New(t)
+
+		s, err := spec(t, &compute.SandboxSpecContainer{})
+		r.NoError(err)
+
+		r.NotNil(s.Linux.Seccomp)
+		r.Equal(specs.ActErrno, s.Linux.Seccomp.DefaultAction)
+		r.Empty(s.Process.ApparmorProfile)
+	})
+
+	t.Run("leaves privileged containers unconfined unless asked", func(t *testing.T) {
+		r := require.New(t)
+
+		s, err := spec(t, &compute.SandboxSpecContainer{Privileged: true})
+		r.NoError(err)
+		r.Nil(s.Linux.Seccomp)
+
+		s, err = spec(t, &compute.SandboxSpecContainer{Privileged: true, SeccompProfile: "default"})
+		r.NoError(err)
+		r.NotNil(s.Linux.Seccomp)
+	})
+
+	t.Run("can run unconfined", func(t *testing.T) {
+		r := require.New(t)
+
+		s, err := spec(t, &compute.SandboxSpecContainer{SeccompProfile: "unconfined", ApparmorProfile: "unconfined"})
+		r.NoError(err)
+		r.Nil(s.Linux.Seccomp)
+		r.Empty(s.Process.ApparmorProfile)
+	})
+
+	t.Run("passes a custom seccomp profile to the container", func(t *testing.T) {
+		r := require.New(t)
+
+		path := writeProfile(t, `{"defaultAction": "SCMP_ACT_LOG"}`)
+
+		s, err := spec(t, &compute.SandboxSpecContainer{SeccompProfile: path})
+		r.NoError(err)
+
+		r.NotNil(s.Linux.Seccomp)
+		r.Equal(specs.ActLog, s.Linux.Seccomp.DefaultAction)
+	})
+
+	t.Run("rejects bad seccomp profiles", func(t *testing.T) {
+		r := require.New(t)
+
+		_, err := spec(t, &compute.SandboxSpecContainer{SeccompProfile: filepath.Join(t.TempDir(), "missing.json")})
+		r.ErrorContains(err, "reading seccomp profile")
+
+		_, err = spec(t, &compute.SandboxSpecContainer{SeccompProfile: "profile.json"})
+		r.ErrorContains(err, "absolute path")
+
+		_, err = spec(t, &compute.SandboxSpecContainer{SeccompProfile: writeProfile(t, "nope")})
+		r.ErrorContains(err, "decoding seccomp profile")
+
+		_, err = spec(t, &compute.SandboxSpecContainer{SeccompProfile: writeProfile(t, "{}")})
+		r.ErrorContains(err, "no defaultAction")
+	})
+
+	t.Run("passes the apparmor profile to the container", func(t *testing.T) {
+		r := require.New(t)
+
+		defer func(f func() bool) { apparmorSupported = f }(apparmorSupported)
+		apparmorSupported = func() bool { return true }
+
+		s, err := spec(t, &compute.SandboxSpecContainer{ApparmorProfile: "app-profile"})
+		r.NoError(err)
+		r.Equal("app-profile", s.Process.ApparmorProfile)
+
+		_, err = spec(t, &compute.SandboxSpecContainer{ApparmorProfile: "../profile"})
+		r.ErrorContains(err, "invalid apparmor profile")
+	})
+
+	t.Run("rejects apparmor profiles on nodes without AppArmor", func(t *testing.T) {
+		r := require.New(t)
+
+		defer func(f func() bool) { apparmorSupported = f }(apparmorSupported)
+		apparmorSupported = func() bool { return false }
+
+		_, err := spec(t, &compute.SandboxSpecContainer{ApparmorProfile: "app-profile"})
+		r.ErrorContains(err, "not enabled")
+	})
+}
@@ -0,0 +1,129 @@
+package sandbox
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	compute "miren.dev/runtime/api/compute/compute_v1alpha"
+)
+
+// safeSysctls are the sysctls containers may always set. They're namespaced,
+// so setting them only affects the sandbox's own namespaces, and none of
+// them can be used to take resources from other sandboxes on the node.
+// Nodes can allow more with SysctlAllowlist.
+var safeSysctls = []string{
+	"kernel.shm_rmid_forced",
+	"net.core.somaxconn",
+	"net.ipv4.ip_local_port_range",
+	"net.ipv4.ip_local_reserved_ports",
+	"net.ipv4.ip_unprivileged_port_start",
+	"net.ipv4.ping_group_range",
+	"net.ipv4.tcp_fin_timeout",
+	"net.ipv4.tcp_keepalive_intvl",
+	"net.ipv4.tcp_keepalive_probes",
+	"net.ipv4.tcp_keepalive_time",
+	"net.ipv4.tcp_syncookies",
+}
+
+var sysctlName = regexp.MustCompile(`^[a-z0-9_-]+(\.[a-z0-9_-]+)+$`)
+
+// sysctls returns the sysctls a container sets, rejecting any that aren't
+// safe or allowed by the node. Containers in a sandbox share its network
+// namespace, so network sysctls set by one apply to all of them.
+func (c *SandboxController) sysctls(co *compute.SandboxSpecContainer) (map[string]string, error) {
+	if len(co.Sysctl) == 0 {
+		return nil, nil
+	}
+
+	sysctls := make(map[string]string, len(co.Sysctl))
+
+	for _, s := range co.Sysctl {
+		// Both separators are accepted, as by sysctl(8)
+		name := strings.ReplaceAll(s.Name, "/", ".")
+
+		if !sysctlName.MatchString(name) {
+			return nil, fmt.Errorf("invalid sysctl name: %q", s.Name)
+		}
+
+		if !slices.Contains(safeSysctls, name) && !slices.Contains(c.SysctlAllowlist, name) {
+			return nil, fmt.Errorf("sysctl %s is not allowed on this node", name)
+		}
+
+		if s.Value == "" || strings.ContainsAny(s.Value, "\n\x00") {
+			return nil, fmt.Errorf("invalid value for sysctl %s: %q", name, s.Value)
+		}
+
+		if _, ok := sysctls[name]; ok {
+			return nil, fmt.Errorf("sysctl %s is set more than once", name)
+		}
+
+		sysctls[name] = s.Value
+	}
+
+	return sysctls, nil
+}
+
+var rlimitTypes = map[string]string{
+	"as":         "RLIMIT_AS",
+	"core":       "RLIMIT_CORE",
+	"cpu":        "RLIMIT_CPU",
+	"data":       "RLIMIT_DATA",
+	"fsize":      "RLIMIT_FSIZE",
+	"locks":      "RLIMIT_LOCKS",
+	"memlock":    "RLIMIT_MEMLOCK",
+	"msgqueue":   "RLIMIT_MSGQUEUE",
+	"nice":       "RLIMIT_NICE",
+	"nofile":     "RLIMIT_NOFILE",
+	"nproc":      "RLIMIT_NPROC",
+	"rss":        "RLIMIT_RSS",
+	"rtprio":     "RLIMIT_RTPRIO",
+	"rttime":     "RLIMIT_RTTIME",
+	"sigpending": "RLIMIT_SIGPENDING",
+	"stack":      "RLIMIT_STACK",
+}
+
+// rlimits returns the rlimits for a container's ulimits. A hard limit of 0
+// is the same as the soft limit.
+func rlimits(co *compute.SandboxSpecContainer) ([]specs.POSIXRlimit, error) {
+	var (
+		rlimits []specs.POSIXRlimit
+		seen    = map[string]bool{}
+	)
+
+	for _, u := range co.Ulimit {
+		typ, ok := rlimitTypes[strings.ToLower(u.Name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown ulimit: %q", u.Name)
+		}
+
+		if seen[typ] {
+			return nil, fmt.Errorf("ulimit %s is set more than once", u.Name)
+		}
+
+		seen[typ] = true
+
+		hard := u.Hard
+		if hard == 0 {
+			hard = u.Soft
+		}
+
+		if u.Soft < 0 || hard < 0 {
+			return nil, fmt.Errorf("ulimit %s must not be negative", u.Name)
+		}
+
+		if u.Soft > hard {
+			return nil, fmt.Errorf("ulimit %s soft limit %d is above its hard limit %d", u.Name, u.Soft, hard)
+		}
+
+		rlimits = append(rlimits, specs.POSIXRlimit{
+			Type: typ,
+			Soft: uint64(u.Soft),
+			Hard: uint64(hard),
+		})
+	}
+
+	return rlimits, nil
+}
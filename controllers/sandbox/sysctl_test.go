@@ -0,0 +1,114 @@
+package sandbox
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/stretchr/testify/require"
+
+	compute "miren.dev/runtime/api/compute/compute_v1alpha"
+)
+
+func TestSysctls(t *testing.T) {
+	c := &SandboxController{
+		Log:             slog.Default(),
+		SysctlAllowlist: []string{"net.ipv4.tcp_rmem"},
+	}
+
+	sysctls := func(s ...compute.SandboxSpecContainerSysctl) (map[string]string, error) {
+		return c.sysctls(&compute.SandboxSpecContainer{Sysctl: s})
+	}
+
+	t.Run("applies safe and allowlisted sysctls", func(t *testing.T) {
+		r := require.New(t)
+
+		got, err := sysctls(
+			compute.SandboxSpecContainerSysctl{Name: "net.core.somaxconn", Value: "4096"},
+			compute.SandboxSpecContainerSysctl{Name: "net/ipv4/tcp_rmem", Value: "4096 87380 6291456"},
+		)
+		r.NoError(err)
+
+		r.Equal(map[string]string{
+			"net.core.somaxconn": "4096",
+			"net.ipv4.tcp_rmem":  "4096 87380 6291456",
+		}, got)
+	})
+
+	t.Run("sets nothing without sysctls", func(t *testing.T) {
+		got, err := sysctls()
+		require.NoError(t, err)
+		require.Nil(t, got)
+	})
+
+	t.Run("rejects sysctls that aren't allowed", func(t *testing.T) {
+		r := require.New(t)
+
+		_, err := sysctls(compute.SandboxSpecContainerSysctl{Name: "kernel.panic", Value: "1"})
+		r.ErrorContains(err, "not allowed")
+
+		_, err = sysctls(compute.SandboxSpecContainerSysctl{Name: "vm.overcommit_memory", Value: "1"})
+		r.ErrorContains(err, "not allowed")
+	})
+
+	t.Run("rejects bad names and values", func(t *testing.T) {
+		r := require.New(t)
+
+		_, err := sysctls(compute.SandboxSpecContainerSysctl{Name: "../../proc/sysrq-trigger", Value: "b"})
+		r.ErrorContains(err, "invalid sysctl name")
+
+		_, err = sysctls(compute.SandboxSpecContainerSysctl{Name: "net.core.somaxconn", Value: ""})
+		r.ErrorContains(err, "invalid value")
+
+		_, err = sysctls(compute.SandboxSpecContainerSysctl{Name: "net.core.somaxconn", Value: "1\n2"})
+		r.ErrorContains(err, "invalid value")
+
+		_, err = sysctls(
+			compute.SandboxSpecContainerSysctl{Name: "net.core.somaxconn", Value: "1"},
+			compute.SandboxSpecContainerSysctl{Name: "net/core/somaxconn", Value: "2"},
+		)
+		r.ErrorContains(err, "more than once")
+	})
+}
+
+func TestRlimits(t *testing.T) {
+	rl := func(u ...compute.SandboxSpecContainerUlimit) ([]specs.POSIXRlimit, error) {
+		return rlimits(&compute.SandboxSpecContainer{Ulimit: u})
+	}
+
+	t.Run("converts ulimits", func(t *testing.T) {
+		r := require.New(t)
+
+		got, err := rl(
+			compute.SandboxSpecContainerUlimit{Name: "nofile", Soft: 65536, Hard: 131072},
+			compute.SandboxSpecContainerUlimit{Name: "CORE", Soft: 0},
+			compute.SandboxSpecContainerUlimit{Name: "nproc", Soft: 512},
+		)
+		r.NoError(err)
+
+		r.Equal([]specs.POSIXRlimit{
+			{Type: "RLIMIT_NOFILE", Soft: 65536, Hard: 131072},
+			{Type: "RLIMIT_CORE", Soft: 0, Hard: 0},
+			{Type: "RLIMIT_NPROC", Soft: 512, Hard: 512},
+		}, got)
+	})
+
+	t.Run("rejects bad ulimits", func(t *testing.T) {
+		r := require.New(t)
+
+		_, err := rl(compute.SandboxSpecContainerUlimit{Name: "files", Soft: 10})
+		r.ErrorContains(err, "unknown ulimit")
+
+		_, err = rl(compute.SandboxSpecContainerUlimit{Name: "nofile", Soft: 200, Hard: 100})
+		r.ErrorContains(err, "above its hard limit")
+
+		_, err = rl(compute.SandboxSpecContainerUlimit{Name: "nofile", Soft: -1})
+		r.ErrorContains(err, "negative")
+
+		_, err = rl(
+			compute.SandboxSpecContainerUlimit{Name: "nofile", Soft: 10},
+			compute.SandboxSpecContainerUlimit{Name: "NOFILE", Soft: 20},
+		)
+		r.ErrorContains(err, "more than once")
+	})
+}
@@ -0,0 +1,166 @@
+package sandbox
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/errdefs"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"golang.org/x/sys/unix"
+
+	compute "miren.dev/runtime/api/compute/compute_v1alpha"
+)
+
+// defaultTerminationGrace is how long a container has to exit after SIGTERM
+// when neither its spec nor the controller sets a grace.
+const defaultTerminationGrace = 10 * time.Second
+
+// terminationGraceLabel records a container's termination grace so that it
+// is known when the sandbox is stopped, without the sandbox entity.
+const terminationGraceLabel = "runtime.computer/termination-grace"
+
+var (
+	terminationDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "sandbox_termination_duration_seconds",
+		Help:    "Time taken to stop the containers of a sandbox",
+		Buckets: []float64{0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 120},
+	})
+
+	terminationForceKills = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "sandbox_termination_force_kills_total",
+		Help: "Sandboxes with at least one container killed after its termination grace expired",
+	})
+
+	terminationIgnoredSigterm = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "sandbox_termination_ignored_sigterm_total",
+		Help: "Containers that did not exit on SIGTERM within their termination grace",
+	})
+)
+
+// nodeTerminationGrace is the grace of containers whose spec doesn't set one.
+func (c *SandboxController) nodeTerminationGrace() time.Duration {
+	if c.TerminationGrace > 0 {
+		return c.TerminationGrace
+	}
+
+	return defaultTerminationGrace
+}
+
+// terminationGrace returns the grace a container is started with, taking
+// the spec's when set.
+func (c *SandboxController) terminationGrace(co *compute.SandboxSpecContainer) time.Duration {
+	if co.TerminationGrace > 0 {
+		return co.TerminationGrace
+	}
+
+	return c.nodeTerminationGrace()
+}
+
+// terminateContainers stops the tasks of containers, sending each SIGTERM
+// and then SIGKILL if it hasn't exited once its grace expires. Containers
+// are stopped concurrently, and the returned termination describes them all.
+func (c *SandboxController) terminateContainers(ctx context.Context, containers []containerd.Container) compute.Termination {
+	start := time.Now()
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		ignored int64
+	)
+
+	for _, cont := range containers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if c.terminateContainer(ctx, cont) {
+				mu.Lock()
+				ignored++
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	term := compute.Termination{
+		Duration:       time.Since(start),
+		ForceKilled:    ignored > 0,
+		IgnoredSigterm: ignored,
+	}
+
+	terminationDuration.Observe(term.Duration.Seconds())
+	terminationIgnoredSigterm.Add(float64(ignored))
+	if term.ForceKilled {
+		terminationForceKills.Inc()
+	}
+
+	return term
+}
+
+// terminateContainer stops the task of a single container, reporting whether
+// it had to be killed after ignoring SIGTERM.
+func (c *SandboxController) terminateContainer(ctx context.Context, cont containerd.Container) bool {
+	id := cont.ID()
+
+	task, err := cont.Task(ctx, nil)
+	if err != nil {
+		if !errdefs.IsNotFound(err) {
+			c.Log.Debug("failed to load task for termination", "id", id, "err", err)
+		}
+		return false
+	}
+
+	status, err := task.Status(ctx)
+	if err != nil || status.Status == containerd.Stopped {
+		return false
+	}
+
+	// Containers started before the grace was recorded use the node's
+	grace := c.nodeTerminationGrace()
+	if labels, err := cont.Labels(ctx); err == nil {
+		if d, err := time.ParseDuration(labels[terminationGraceLabel]); err == nil && d > 0 {
+			grace = d
+		}
+	}
+
+	exitCh, err := task.Wait(ctx)
+	if err != nil {
+		c.Log.Debug("failed to wait on task for termination", "id", id, "err", err)
+		return false
+	}
+
+	if err := task.Kill(ctx, unix.SIGTERM); err != nil {
+		c.Log.Debug("failed to send SIGTERM", "id", id, "err", err)
+		return false
+	}
+
+	c.Log.Debug("sent SIGTERM to task", "id", id, "grace", grace)
+
+	timer := time.NewTimer(grace)
+	defer timer.Stop()
+
+	select {
+	case <-exitCh:
+		return false
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+	}
+
+	c.Log.Warn("container ignored SIGTERM, killing it", "id", id, "grace", grace)
+
+	if err := task.Kill(ctx, unix.SIGKILL); err != nil {
+		c.Log.Debug("failed to send SIGKILL", "id", id, "err", err)
+	}
+
+	select {
+	case <-exitCh:
+	case <-ctx.Done():
+	}
+
+	return true
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"sync"
 	"time"
 
 	"miren.dev/runtime/api/compute/compute_v1alpha"
@@ -31,6 +32,11 @@ type Manager struct {
 	eac    *entityserver_v1alpha.EntityAccessClient
 	ctx    context.Context
 	cancel context.CancelFunc
+
+	// restarting holds the pools a RollingRestart is replacing the
+	// sandboxes of, which Reconcile leaves it to scale.
+	restartMu  sync.Mutex
+	restarting map[entity.Id]bool
 }
 
 // NewManager creates a new SandboxPoolManager
@@ -137,6 +143,11 @@ func (m *Manager) Reconcile(ctx context.Context, pool *compute_v1alpha.SandboxPo
 		// Pool crash state fields reset, framework will apply via entity.Diff
 	}
 
+	if m.isRestarting(pool.ID) {
+		m.log.Debug("pool is being restarted, leaving scaling to the restart", "pool", pool.ID)
+		return m.updatePoolStatus(ctx, pool, actual, ready, meta)
+	}
+
 	// actual and ready were already counted at the top of Reconcile
 	desired := pool.DesiredInstances
 
@@ -149,53 +160,39 @@ func (m *Manager) Reconcile(ctx context.Context, pool *compute_v1alpha.SandboxPo
 		desired = MaxPoolSize
 	}
 
+	// Keep warm instances running on top of demand so the next request finds a
+	// RUNNING sandbox instead of paying for a cold start
+	var poolCreatedAt time.Time
+	if meta != nil && meta.Entity != nil {
+		poolCreatedAt = meta.Entity.GetCreatedAt()
+	}
+	warm := warmTarget(pool, sandboxes, poolCreatedAt, time.Now())
+
+	target := desired + warm
+	if target > MaxPoolSize {
+		target = MaxPoolSize
+	}
+
 	m.log.Debug("sandbox counts",
 		"pool", pool.ID,
 		"actual", actual,
 		"ready", ready,
-		"desired", desired)
+		"desired", desired,
+		"warm", warm)
 
 	// Scale up if needed
-	if actual < desired {
-		toCreate := desired - actual
+	if actual < target {
+		toCreate := target - actual
 		m.log.Info("scaling up pool",
 			"pool", pool.ID,
 			"service", pool.Service,
 			"current", actual,
 			"desired", desired,
+			"warm", warm,
 			"creating", toCreate)
 
-		// Determine which instance numbers to create
-		// Collect existing instance numbers
-		existingInstances := make(map[int]bool)
-		for _, sbm := range sandboxes {
-			// Get instance number from sandbox metadata
-			resp, err := m.eac.Get(ctx, sbm.sandbox.ID.String())
-			if err != nil {
-				m.log.Warn("failed to get sandbox metadata", "sandbox", sbm.sandbox.ID, "error", err)
-				continue
-			}
-
-			var md core_v1alpha.Metadata
-			md.Decode(resp.Entity().Entity())
-
-			if instanceStr, ok := md.Labels.Get("instance"); ok {
-				var instanceNum int
-				fmt.Sscanf(instanceStr, "%d", &instanceNum)
-				existingInstances[instanceNum] = true
-			}
-		}
-
-		// Find next available instance numbers
-		instancesNeeded := make([]int, 0, toCreate)
-		for instanceNum := 0; len(instancesNeeded) < int(toCreate); instanceNum++ {
-			if !existingInstances[instanceNum] {
-				instancesNeeded = append(instancesNeeded, instanceNum)
-			}
-		}
-
 		// Create sandboxes with assigned instance numbers
-		for _, instanceNum := range instancesNeeded {
+		for _, instanceNum := range m.freeInstanceNumbers(ctx, sandboxes, int(toCreate)) {
 			if err := m.createSandbox(ctx, pool, instanceNum); err != nil {
 				m.log.Error("failed to create sandbox",
 					"pool", pool.ID,
@@ -223,13 +220,14 @@ func (m *Manager) Reconcile(ctx context.Context, pool *compute_v1alpha.SandboxPo
 	}
 
 	// Scale down if needed
-	if actual > desired {
-		toStop := actual - desired
+	if actual > target {
+		toStop := actual - target
 		m.log.Info("scaling down pool",
 			"pool", pool.ID,
 			"service", pool.Service,
 			"current", actual,
 			"desired", desired,
+			"warm", warm,
 			"stopping", toStop)
 
 		if err := m.scaleDown(ctx, pool, sandboxes, toStop); err != nil {
@@ -398,6 +396,40 @@ func (m *Manager) createSandbox(ctx context.Context, pool *compute_v1alpha.Sandb
 	return nil
 }
 
+// freeInstanceNumbers returns the lowest count instance numbers not used by
+// any of the sandboxes.
+func (m *Manager) freeInstanceNumbers(ctx context.Context, sandboxes []*sandboxWithMeta, count int) []int {
+	// Collect existing instance numbers
+	existingInstances := make(map[int]bool)
+	for _, sbm := range sandboxes {
+		// Get instance number from sandbox metadata
+		resp, err := m.eac.Get(ctx, sbm.sandbox.ID.String())
+		if err != nil {
+			m.log.Warn("failed to get sandbox metadata", "sandbox", sbm.sandbox.ID, "error", err)
+			continue
+		}
+
+		var md core_v1alpha.Metadata
+		md.Decode(resp.Entity().Entity())
+
+		if instanceStr, ok := md.Labels.Get("instance"); ok {
+			var instanceNum int
+			fmt.Sscanf(instanceStr, "%d", &instanceNum)
+			existingInstances[instanceNum] = true
+		}
+	}
+
+	// Find next available instance numbers
+	instances := make([]int, 0, count)
+	for instanceNum := 0; len(instances) < count; instanceNum++ {
+		if !existingInstances[instanceNum] {
+			instances = append(instances, instanceNum)
+		}
+	}
+
+	return instances
+}
+
 // scaleDown stops excess sandboxes to bring actual count down to desired count.
 // This is called when actual > desired (proactive scale-down).
 // Sandboxes are retired in priority order: oldest LastActivity first.
@@ -463,13 +495,7 @@ func (m *Manager) scaleDown(ctx context.Context, pool *compute_v1alpha.SandboxPo
 			"sandbox", sb.ID,
 			"last_activity", candidates[i].lastActivity)
 
-		// Mark sandbox as STOPPED
-		if _, err := m.eac.Patch(ctx, entity.New(
-			entity.DBId, sb.ID,
-			(&compute_v1alpha.Sandbox{
-				Status: compute_v1alpha.STOPPED,
-			}).Encode,
-		).Attrs(), 0); err != nil {
+		if err := m.stopSandbox(ctx, sb); err != nil {
 			if errors.Is(err, cond.ErrNotFound{}) {
 				m.log.Warn("sandbox already deleted during scale-down",
 					"pool", pool.ID,
@@ -494,6 +520,54 @@ func (m *Manager) scaleDown(ctx context.Context, pool *compute_v1alpha.SandboxPo
 	return nil
 }
 
+// stopSandbox marks a sandbox STOPPED, retiring it.
+func (m *Manager) stopSandbox(ctx context.Context, sb *compute_v1alpha.Sandbox) error {
+	_, err := m.eac.Patch(ctx, entity.New(
+		entity.DBId, sb.ID,
+		(&compute_v1alpha.Sandbox{
+			Status: compute_v1alpha.STOPPED,
+		}).Encode,
+	).Attrs(), 0)
+
+	return err
+}
+
+// warmTarget returns how many idle instances the pool should keep running in
+// addition to DesiredInstances. Warm capacity is held until the pool goes
+// WarmIdleTtl without demand, measured from the most recent sandbox activity
+// or, if no sandbox has seen activity yet, from when the pool was created.
+func warmTarget(pool *compute_v1alpha.SandboxPool, sandboxes []*sandboxWithMeta, poolCreatedAt, now time.Time) int64 {
+	if pool.WarmInstances <= 0 {
+		return 0
+	}
+
+	if pool.WarmIdleTtl <= 0 {
+		return pool.WarmInstances
+	}
+
+	lastDemand := poolCreatedAt
+	var lastActivity time.Time
+	for _, sbm := range sandboxes {
+		if sbm.sandbox.LastActivity.After(lastActivity) {
+			lastActivity = sbm.sandbox.LastActivity
+		}
+	}
+	if !lastActivity.IsZero() {
+		lastDemand = lastActivity
+	}
+
+	// Without any record of demand, err on the side of keeping capacity warm
+	if lastDemand.IsZero() {
+		return pool.WarmInstances
+	}
+
+	if now.Sub(lastDemand) > pool.WarmIdleTtl {
+		return 0
+	}
+
+	return pool.WarmInstances
+}
+
 // updatePoolStatus updates the pool's CurrentInstances and ReadyInstances fields,
 // then propagates all pool changes back to meta.Entity for the framework to diff and persist.
 // When called with a nil meta (e.g., during testing), it falls back to direct persistence.
@@ -532,7 +606,8 @@ func (m *Manager) updatePoolStatus(ctx context.Context, pool *compute_v1alpha.Sa
 
 // runScaleDownMonitor periodically checks all pools for idle sandboxes that exceed
 // their ScaleDownDelay, and proactively decrements DesiredInstances to trigger scale-down.
-// Also cleans up empty pools that have been idle for over an hour.
+// Also reclaims expired warm instances and cleans up empty pools that have been idle
+// for over an hour.
 func (m *Manager) runScaleDownMonitor(ctx context.Context) {
 	ticker := time.NewTicker(30 * time.Second)
 	defer ticker.Stop()
@@ -548,6 +623,9 @@ func (m *Manager) runScaleDownMonitor(ctx context.Context) {
 			if err := m.checkAllPoolsForScaleDown(ctx); err != nil {
 				m.log.Error("scale-down check failed", "error", err)
 			}
+			if err := m.reclaimIdleWarmInstances(ctx); err != nil {
+				m.log.Error("warm instance reclaim failed", "error", err)
+			}
 			if err := m.cleanupEmptyPools(ctx); err != nil {
 				m.log.Error("pool cleanup failed", "error", err)
 			}
@@ -637,6 +715,9 @@ func (m *Manager) checkPoolForScaleDown(ctx context.Context, pool *compute_v1alp
 		}
 	}
 
+	// Idle warm instances are expected and don't represent excess demand capacity
+	idleCount -= warmTarget(pool, sandboxes, time.Time{}, now)
+
 	// If we have idle sandboxes and we're above minimum instances, decrement desired
 	if idleCount > 0 && pool.DesiredInstances > minInstances {
 		// Only decrement by the number of idle sandboxes, but respect minimum
@@ -669,6 +750,66 @@ func (m *Manager) checkPoolForScaleDown(ctx context.Context, pool *compute_v1alp
 	return nil
 }
 
+// reclaimIdleWarmInstances stops the warm instances of pools that have gone longer
+// than their WarmIdleTtl without demand. Nothing changes the pool entity when its
+// TTL expires, so Reconcile wouldn't otherwise run to retire them.
+func (m *Manager) reclaimIdleWarmInstances(ctx context.Context) error {
+	resp, err := m.eac.List(ctx, entity.Ref(entity.EntityKind, compute_v1alpha.KindSandboxPool))
+	if err != nil {
+		return fmt.Errorf("failed to list pools: %w", err)
+	}
+
+	now := time.Now()
+
+	for _, ent := range resp.Values() {
+		var pool compute_v1alpha.SandboxPool
+		pool.Decode(ent.Entity())
+
+		if pool.WarmInstances <= 0 || pool.WarmIdleTtl <= 0 || m.isRestarting(pool.ID) {
+			continue
+		}
+
+		sandboxes, err := m.listSandboxes(ctx, &pool)
+		if err != nil {
+			m.log.Error("failed to list sandboxes for warm reclaim", "pool", pool.ID, "error", err)
+			continue
+		}
+
+		if warmTarget(&pool, sandboxes, time.UnixMilli(ent.CreatedAt()), now) > 0 {
+			continue
+		}
+
+		actual := int64(0)
+		for _, sbm := range sandboxes {
+			if sbm.sandbox.Status == compute_v1alpha.RUNNING || sbm.sandbox.Status == compute_v1alpha.PENDING {
+				actual++
+			}
+		}
+
+		desired := pool.DesiredInstances
+		if desired > MaxPoolSize {
+			desired = MaxPoolSize
+		}
+
+		if actual <= desired {
+			continue
+		}
+
+		m.log.Info("reclaiming idle warm instances",
+			"pool", pool.ID,
+			"service", pool.Service,
+			"current", actual,
+			"desired", desired,
+			"warm_idle_ttl", pool.WarmIdleTtl)
+
+		if err := m.scaleDown(ctx, &pool, sandboxes, actual-desired); err != nil {
+			m.log.Error("failed to reclaim warm instances", "pool", pool.ID, "error", err)
+		}
+	}
+
+	return nil
+}
+
 // cleanupEmptyPools deletes pools that have desired_instances=0, no associated sandboxes,
 // and haven't been updated in over an hour. This matches the sandbox controller's behavior
 // of deleting DEAD sandboxes after an hour.
@@ -1099,3 +1099,192 @@ func TestCleanupEmptyPoolsNotReferencedByActiveVersion(t *testing.T) {
 	isReferenced := manager.isPoolReferencedByCurrentVersion(ctx, pool)
 	require.False(t, isReferenced, "Pool should NOT be recognized as referenced by active version")
 }
+
+// TestManagerWarmPoolServesFirstRequest tests that a pool with warm instances
+// keeps RUNNING sandboxes with no demand, and that they stay in place to serve
+// traffic while replacement warm capacity is added as demand grows
+func TestManagerWarmPoolServesFirstRequest(t *testing.T) {
+	ctx := context.Background()
+	log := testutils.TestLogger(t)
+
+	server, cleanup := testutils.NewInMemEntityServer(t)
+	defer cleanup()
+
+	pool := &compute_v1alpha.SandboxPool{
+		Service:          "web",
+		DesiredInstances: 0,
+		WarmInstances:    1,
+		WarmIdleTtl:      10 * time.Minute,
+		SandboxSpec: compute_v1alpha.SandboxSpec{
+			Version: entity.Id("ver-1"),
+			Container: []compute_v1alpha.SandboxSpecContainer{
+				{Image: "test:latest"},
+			},
+		},
+	}
+
+	poolID, err := server.Client.Create(ctx, "test-pool", pool)
+	require.NoError(t, err)
+	pool.ID = poolID
+
+	manager := NewManager(log, server.EAC)
+	reconcilePool(t, ctx, server, manager, pool)
+
+	// A warm sandbox is created before any request arrives
+	sandboxes := listSandboxesForPool(t, ctx, server, pool)
+	require.Len(t, sandboxes, 1, "should create a warm sandbox with no demand")
+	assert.Equal(t, compute_v1alpha.PENDING, sandboxes[0].Status)
+
+	warmID := sandboxes[0].ID
+
+	// The sandbox controller boots it
+	_, err = server.EAC.Patch(ctx, []entity.Attr{
+		entity.Ref(entity.DBId, warmID),
+		entity.Ref(compute_v1alpha.SandboxStatusId, compute_v1alpha.SandboxStatusRunningId),
+	}, 0)
+	require.NoError(t, err)
+
+	reconcilePool(t, ctx, server, manager, pool)
+
+	finalPool := getPool(t, ctx, server, poolID)
+	assert.Equal(t, int64(0), finalPool.DesiredInstances, "warm instances don't count as desired")
+	assert.Equal(t, int64(1), finalPool.CurrentInstances)
+	assert.Equal(t, int64(1), finalPool.ReadyInstances, "warm sandbox is ready before the first request")
+
+	// The first request is served by the warm sandbox, and the activator asks
+	// for capacity to match the new demand
+	_, err = server.EAC.Patch(ctx, []entity.Attr{
+		entity.Ref(entity.DBId, warmID),
+		entity.Time(compute_v1alpha.SandboxLastActivityId, time.Now()),
+	}, 0)
+	require.NoError(t, err)
+
+	_, err = server.EAC.Patch(ctx, []entity.Attr{
+		entity.Ref(entity.DBId, poolID),
+		entity.Int64(compute_v1alpha.SandboxPoolDesiredInstancesId, 1),
+	}, 0)
+	require.NoError(t, err)
+
+	reconcilePool(t, ctx, server, manager, pool)
+
+	// The warm sandbox keeps serving and a new warm sandbox is started behind it
+	sandboxes = listSandboxesForPool(t, ctx, server, pool)
+	require.Len(t, sandboxes, 2, "should keep 1 warm instance on top of demand")
+
+	var servingStatus compute_v1alpha.SandboxStatus
+	pending := 0
+	for _, sb := range sandboxes {
+		if sb.ID == warmID {
+			servingStatus = sb.Status
+		} else if sb.Status == compute_v1alpha.PENDING {
+			pending++
+		}
+	}
+
+	assert.Equal(t, compute_v1alpha.RUNNING, servingStatus, "warm sandbox should still be serving")
+	assert.Equal(t, 1, pending, "replacement warm sandbox should be booting")
+}
+
+// TestManagerWarmPoolReclaimIdle tests that warm instances are retired once
+// the pool has gone longer than WarmIdleTtl without demand
+func TestManagerWarmPoolReclaimIdle(t *testing.T) {
+	ctx := context.Background()
+	log := testutils.TestLogger(t)
+
+	server, cleanup := testutils.NewInMemEntityServer(t)
+	defer cleanup()
+
+	pool := &compute_v1alpha.SandboxPool{
+		Service:          "web",
+		DesiredInstances: 0,
+		WarmInstances:    2,
+		WarmIdleTtl:      5 * time.Minute,
+		SandboxSpec: compute_v1alpha.SandboxSpec{
+			Version: entity.Id("ver-1"),
+			Container: []compute_v1alpha.SandboxSpecContainer{
+				{Image: "test:latest"},
+			},
+		},
+	}
+
+	poolID, err := server.Client.Create(ctx, "test-pool", pool)
+	require.NoError(t, err)
+	pool.ID = poolID
+
+	// Two warm sandboxes, last used well beyond the TTL
+	for i := 0; i < 2; i++ {
+		sb := &compute_v1alpha.Sandbox{
+			Status:       compute_v1alpha.RUNNING,
+			LastActivity: time.Now().Add(-10 * time.Minute),
+			Spec:         pool.SandboxSpec,
+		}
+		_, err := server.Client.Create(ctx, fmt.Sprintf("warm-sb-%d", i), sb,
+			entityserver.WithLabels(types.LabelSet("service", "web", "pool", poolID.String())))
+		require.NoError(t, err)
+	}
+
+	manager := NewManager(log, server.EAC)
+	reconcilePool(t, ctx, server, manager, pool)
+
+	sandboxes := listSandboxesForPool(t, ctx, server, pool)
+	assert.Empty(t, sandboxes, "idle warm sandboxes should be stopped")
+
+	finalPool := getPool(t, ctx, server, poolID)
+	assert.Equal(t, int64(0), finalPool.CurrentInstances)
+	assert.Equal(t, int64(0), finalPool.ReadyInstances)
+}
+
+// TestReclaimIdleWarmInstances tests that the background monitor retires
+// expired warm instances without waiting for the pool to be reconciled, and
+// leaves pools that have seen recent demand alone
+func TestReclaimIdleWarmInstances(t *testing.T) {
+	ctx := context.Background()
+	log := testutils.TestLogger(t)
+
+	server, cleanup := testutils.NewInMemEntityServer(t)
+	defer cleanup()
+
+	createPool := func(name, version string, lastActivity time.Time) *compute_v1alpha.SandboxPool {
+		pool := &compute_v1alpha.SandboxPool{
+			Service:          "web",
+			DesiredInstances: 1,
+			WarmInstances:    1,
+			WarmIdleTtl:      5 * time.Minute,
+			SandboxSpec: compute_v1alpha.SandboxSpec{
+				Version: entity.Id(version),
+				Container: []compute_v1alpha.SandboxSpecContainer{
+					{Image: "test:latest"},
+				},
+			},
+		}
+
+		poolID, err := server.Client.Create(ctx, name, pool)
+		require.NoError(t, err)
+		pool.ID = poolID
+
+		for i := 0; i < 2; i++ {
+			sb := &compute_v1alpha.Sandbox{
+				Status:       compute_v1alpha.RUNNING,
+				LastActivity: lastActivity.Add(-time.Duration(i) * time.Second),
+				Spec:         pool.SandboxSpec,
+			}
+			_, err := server.Client.Create(ctx, fmt.Sprintf("%s-sb-%d", name, i), sb,
+				entityserver.WithLabels(types.LabelSet("service", "web", "pool", poolID.String())))
+			require.NoError(t, err)
+		}
+
+		return pool
+	}
+
+	idlePool := createPool("idle-pool", "ver-1", time.Now().Add(-10*time.Minute))
+	activePool := createPool("active-pool", "ver-2", time.Now().Add(-time.Minute))
+
+	manager := NewManager(log, server.EAC)
+	err := manager.reclaimIdleWarmInstances(ctx)
+	require.NoError(t, err)
+
+	assert.Len(t, listSandboxesForPool(t, ctx, server, idlePool), 1,
+		"idle pool should be reduced to its desired instances")
+	assert.Len(t, listSandboxesForPool(t, ctx, server, activePool), 2,
+		"active pool should keep its warm instance")
+}
@@ -0,0 +1,216 @@
+package sandboxpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"miren.dev/runtime/api/compute/compute_v1alpha"
+	"miren.dev/runtime/pkg/cond"
+	"miren.dev/runtime/pkg/entity"
+)
+
+// RollingRestartOptions bound how much of a pool is disrupted at once while
+// its sandboxes are replaced.
+type RollingRestartOptions struct {
+	// MaxSurge is how many sandboxes can run on top of the pool's size while
+	// their replacements start.
+	MaxSurge int
+
+	// MaxUnavailable is how many of the pool's sandboxes can be short of
+	// RUNNING at once. Old sandboxes are only stopped while at least the
+	// pool's size minus MaxUnavailable are RUNNING.
+	MaxUnavailable int
+
+	// PollInterval is how often the restart checks on the replacements,
+	// every 2 seconds by default.
+	PollInterval time.Duration
+}
+
+// RollingRestart replaces each of the pool's sandboxes with a new one
+// created from the pool's current SandboxSpec, such as after a config
+// change, without dropping below the capacity opts allow. Old sandboxes are
+// only stopped once enough replacements are RUNNING, so the restart waits
+// for each batch to become ready before moving on. With neither MaxSurge nor
+// MaxUnavailable set, it surges by one.
+//
+// Reconcile leaves the pool's scaling to the restart until it returns. It
+// fails if a replacement dies, leaving the remaining old sandboxes running.
+func (m *Manager) RollingRestart(ctx context.Context, poolID entity.Id, opts RollingRestartOptions) error {
+	if opts.MaxSurge < 0 || opts.MaxUnavailable < 0 {
+		return fmt.Errorf("surge and unavailable limits can't be negative")
+	}
+
+	if opts.MaxSurge == 0 && opts.MaxUnavailable == 0 {
+		opts.MaxSurge = 1
+	}
+
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = 2 * time.Second
+	}
+
+	if !m.startRestart(poolID) {
+		return cond.Conflict("pool restart", poolID)
+	}
+	defer m.finishRestart(poolID)
+
+	resp, err := m.eac.Get(ctx, poolID.String())
+	if err != nil {
+		return fmt.Errorf("failed to get pool: %w", err)
+	}
+
+	var pool compute_v1alpha.SandboxPool
+	pool.Decode(resp.Entity().Entity())
+
+	sandboxes, err := m.listSandboxes(ctx, &pool)
+	if err != nil {
+		return fmt.Errorf("failed to list sandboxes: %w", err)
+	}
+
+	// Anything not around now is a replacement
+	existing := make(map[entity.Id]bool)
+	old := make(map[entity.Id]bool)
+	for _, sbm := range sandboxes {
+		existing[sbm.sandbox.ID] = true
+
+		if isActive(sbm.sandbox) {
+			old[sbm.sandbox.ID] = true
+		}
+	}
+
+	size := len(old)
+	if size == 0 {
+		return nil
+	}
+
+	m.log.Info("starting rolling restart",
+		"pool", pool.ID,
+		"service", pool.Service,
+		"instances", size,
+		"max_surge", opts.MaxSurge,
+		"max_unavailable", opts.MaxUnavailable)
+
+	ticker := time.NewTicker(opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		sandboxes, err := m.listSandboxes(ctx, &pool)
+		if err != nil {
+			return fmt.Errorf("failed to list sandboxes: %w", err)
+		}
+
+		var (
+			oldActive []*sandboxWithMeta
+			created   int
+			ready     int
+		)
+
+		for _, sbm := range sandboxes {
+			sb := sbm.sandbox
+
+			if !existing[sb.ID] && sb.Status == compute_v1alpha.DEAD {
+				return fmt.Errorf("replacement sandbox %s died, stopping rolling restart", sb.ID)
+			}
+
+			if !isActive(sb) {
+				continue
+			}
+
+			if sb.Status == compute_v1alpha.RUNNING {
+				ready++
+			}
+
+			if old[sb.ID] {
+				oldActive = append(oldActive, sbm)
+			} else if !existing[sb.ID] {
+				created++
+			}
+		}
+
+		if len(oldActive) == 0 && ready >= size {
+			m.log.Info("rolling restart complete", "pool", pool.ID, "instances", size)
+			return nil
+		}
+
+		// Start replacements, as far as the surge allows
+		if toCreate := min(size-created, size+opts.MaxSurge-len(oldActive)-created); toCreate > 0 {
+			for _, instanceNum := range m.freeInstanceNumbers(ctx, sandboxes, toCreate) {
+				if err := m.createSandbox(ctx, &pool, instanceNum); err != nil {
+					return fmt.Errorf("failed to create replacement sandbox: %w", err)
+				}
+			}
+		}
+
+		// Old sandboxes that aren't serving can go right away, running ones
+		// only as far as the RUNNING replacements allow. The oldest go first.
+		sort.SliceStable(oldActive, func(i, j int) bool {
+			ri := oldActive[i].sandbox.Status == compute_v1alpha.RUNNING
+			rj := oldActive[j].sandbox.Status == compute_v1alpha.RUNNING
+			if ri != rj {
+				return !ri
+			}
+
+			return oldActive[i].createdAt.Before(oldActive[j].createdAt)
+		})
+
+		toStop := 0
+		for _, sbm := range oldActive {
+			if sbm.sandbox.Status != compute_v1alpha.RUNNING {
+				toStop++
+			}
+		}
+
+		toStop += max(min(len(oldActive)-toStop, ready-(size-opts.MaxUnavailable)), 0)
+
+		for _, sbm := range oldActive[:toStop] {
+			m.log.Info("stopping sandbox for rolling restart", "pool", pool.ID, "sandbox", sbm.sandbox.ID)
+
+			if err := m.stopSandbox(ctx, sbm.sandbox); err != nil && !errors.Is(err, cond.ErrNotFound{}) {
+				return fmt.Errorf("failed to stop sandbox %s: %w", sbm.sandbox.ID, err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func isActive(sb *compute_v1alpha.Sandbox) bool {
+	return sb.Status == compute_v1alpha.RUNNING || sb.Status == compute_v1alpha.PENDING
+}
+
+func (m *Manager) startRestart(poolID entity.Id) bool {
+	m.restartMu.Lock()
+	defer m.restartMu.Unlock()
+
+	if m.restarting[poolID] {
+		return false
+	}
+
+	if m.restarting == nil {
+		m.restarting = make(map[entity.Id]bool)
+	}
+
+	m.restarting[poolID] = true
+
+	return true
+}
+
+func (m *Manager) finishRestart(poolID entity.Id) {
+	m.restartMu.Lock()
+	defer m.restartMu.Unlock()
+
+	delete(m.restarting, poolID)
+}
+
+func (m *Manager) isRestarting(poolID entity.Id) bool {
+	m.restartMu.Lock()
+	defer m.restartMu.Unlock()
+
+	return m.restarting[poolID]
+}
@@ -0,0 +1,236 @@
+package sandboxpool
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"miren.dev/runtime/api/compute/compute_v1alpha"
+	"miren.dev/runtime/api/core/core_v1alpha"
+	"miren.dev/runtime/api/entityserver"
+	"miren.dev/runtime/pkg/entity"
+	"miren.dev/runtime/pkg/entity/testutils"
+	"miren.dev/runtime/pkg/entity/types"
+)
+
+// restartPool creates a pool with size RUNNING sandboxes.
+func restartPool(t *testing.T, ctx context.Context, server *testutils.InMemEntityServer, size int) (*compute_v1alpha.SandboxPool, map[entity.Id]bool) {
+	pool := &compute_v1alpha.SandboxPool{
+		Service:          "web",
+		DesiredInstances: int64(size),
+		SandboxSpec: compute_v1alpha.SandboxSpec{
+			Version: entity.Id("ver-1"),
+			Container: []compute_v1alpha.SandboxSpecContainer{
+				{Image: "test:latest"},
+			},
+		},
+	}
+
+	poolID, err := server.Client.Create(ctx, "test-pool", pool)
+	require.NoError(t, err)
+	pool.ID = poolID
+
+	original := make(map[entity.Id]bool)
+
+	for i := range size {
+		id, err := server.Client.Create(ctx, fmt.Sprintf("sb-%d", i), &compute_v1alpha.Sandbox{
+			Status: compute_v1alpha.RUNNING,
+			Spec:   pool.SandboxSpec,
+		}, entityserver.WithLabels(types.LabelSet(
+			"service", "web", "pool", poolID.String(), "instance", fmt.Sprint(i))))
+		require.NoError(t, err)
+
+		original[id] = true
+	}
+
+	return pool, original
+}
+
+// poolSandboxes returns all of the pool's sandboxes, including STOPPED ones.
+func poolSandboxes(t *testing.T, ctx context.Context, server *testutils.InMemEntityServer, pool *compute_v1alpha.SandboxPool) []*compute_v1alpha.Sandbox {
+	results, err := server.EAC.List(ctx, entity.Ref(entity.EntityKind, compute_v1alpha.KindSandbox))
+	require.NoError(t, err)
+
+	var sandboxes []*compute_v1alpha.Sandbox
+
+	for _, ent := range results.Values() {
+		var md core_v1alpha.Metadata
+		md.Decode(ent.Entity())
+
+		if getLabel(md.Labels, "pool") != pool.ID.String() {
+			continue
+		}
+
+		var sb compute_v1alpha.Sandbox
+		sb.Decode(ent.Entity())
+
+		sandboxes = append(sandboxes, &sb)
+	}
+
+	return sandboxes
+}
+
+func setSandboxStatus(t *testing.T, ctx context.Context, server *testutils.InMemEntityServer, id entity.Id, status compute_v1alpha.SandboxStatus) {
+	_, err := server.EAC.Patch(ctx, entity.New(
+		entity.DBId, id,
+		(&compute_v1alpha.Sandbox{Status: status}).Encode,
+	).Attrs(), 0)
+	require.NoError(t, err)
+}
+
+func TestRollingRestart(t *testing.T) {
+	const size = 4
+
+	for _, tc := range []struct {
+		surge, unavailable int
+	}{
+		{surge: 1, unavailable: 0},
+		{surge: 0, unavailable: 1},
+		{surge: 2, unavailable: 1},
+	} {
+		t.Run(fmt.Sprintf("surge %d unavailable %d", tc.surge, tc.unavailable), func(t *testing.T) {
+			r := require.New(t)
+			ctx := context.Background()
+			log := testutils.TestLogger(t)
+
+			server, cleanup := testutils.NewInMemEntityServer(t)
+			defer cleanup()
+
+			pool, original := restartPool(t, ctx, server, size)
+
+			manager := NewManager(log, server.EAC)
+
+			done := make(chan error, 1)
+			go func() {
+				done <- manager.RollingRestart(ctx, pool.ID, RollingRestartOptions{
+					MaxSurge:       tc.surge,
+					MaxUnavailable: tc.unavailable,
+					PollInterval:   5 * time.Millisecond,
+				})
+			}()
+
+			deadline := time.After(10 * time.Second)
+
+		restart:
+			for {
+				select {
+				case err := <-done:
+					r.NoError(err)
+					break restart
+				case <-deadline:
+					t.Fatal("rolling restart didn't finish")
+				default:
+				}
+
+				// Reconcile runs alongside the restart, as it would on
+				// the status changes, and must leave the pool to it.
+				reconcilePool(t, ctx, server, manager, pool)
+
+				var (
+					active, running, stopped int
+					pending                  []entity.Id
+				)
+
+				for _, sb := range poolSandboxes(t, ctx, server, pool) {
+					switch sb.Status {
+					case compute_v1alpha.RUNNING:
+						active++
+						running++
+					case compute_v1alpha.PENDING:
+						active++
+						pending = append(pending, sb.ID)
+					case compute_v1alpha.STOPPED:
+						stopped++
+					}
+				}
+
+				r.LessOrEqual(active, size+tc.surge, "ran more sandboxes than the surge allows")
+				r.GreaterOrEqual(running, size-tc.unavailable, "more sandboxes unavailable than allowed")
+
+				if len(pending) == 0 {
+					time.Sleep(5 * time.Millisecond)
+					continue
+				}
+
+				// Without room for unavailability, nothing else can be
+				// stopped until the replacement is ready
+				if tc.unavailable == 0 {
+					time.Sleep(30 * time.Millisecond)
+
+					after := 0
+					for _, sb := range poolSandboxes(t, ctx, server, pool) {
+						if sb.Status == compute_v1alpha.STOPPED {
+							after++
+						}
+					}
+
+					r.Equal(stopped, after, "stopped a sandbox before its replacement was ready")
+				}
+
+				// Bring a replacement up, as the sandbox controller would
+				setSandboxStatus(t, ctx, server, pending[0], compute_v1alpha.RUNNING)
+			}
+
+			var running int
+
+			for _, sb := range poolSandboxes(t, ctx, server, pool) {
+				if original[sb.ID] {
+					r.Equal(compute_v1alpha.STOPPED, sb.Status, "original sandbox %s wasn't stopped", sb.ID)
+				} else {
+					r.Equal(compute_v1alpha.RUNNING, sb.Status)
+					running++
+				}
+			}
+
+			r.Equal(size, running)
+			r.False(manager.isRestarting(pool.ID))
+		})
+	}
+
+	t.Run("stops when a replacement dies", func(t *testing.T) {
+		r := require.New(t)
+		ctx := context.Background()
+		log := testutils.TestLogger(t)
+
+		server, cleanup := testutils.NewInMemEntityServer(t)
+		defer cleanup()
+
+		pool, original := restartPool(t, ctx, server, size)
+
+		manager := NewManager(log, server.EAC)
+
+		done := make(chan error, 1)
+		go func() {
+			done <- manager.RollingRestart(ctx, pool.ID, RollingRestartOptions{
+				MaxSurge:     1,
+				PollInterval: 5 * time.Millisecond,
+			})
+		}()
+
+		r.Eventually(func() bool {
+			for _, sb := range poolSandboxes(t, ctx, server, pool) {
+				if sb.Status == compute_v1alpha.PENDING {
+					setSandboxStatus(t, ctx, server, sb.ID, compute_v1alpha.DEAD)
+					return true
+				}
+			}
+
+			return false
+		}, 5*time.Second, 5*time.Millisecond)
+
+		select {
+		case err := <-done:
+			r.ErrorContains(err, "died")
+		case <-time.After(5 * time.Second):
+			t.Fatal("rolling restart didn't stop")
+		}
+
+		for _, sb := range poolSandboxes(t, ctx, server, pool) {
+			if original[sb.ID] {
+				r.Equal(compute_v1alpha.RUNNING, sb.Status, "original sandbox stopped despite the failed replacement")
+			}
+		}
+	})
+}
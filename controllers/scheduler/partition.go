@@ -0,0 +1,225 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"miren.dev/runtime/api/compute/compute_v1alpha"
+	"miren.dev/runtime/api/entityserver/entityserver_v1alpha"
+	"miren.dev/runtime/pkg/entity"
+)
+
+const (
+	// DefaultHeartbeatTimeout is how long a node can be without a ready
+	// status before it's treated as partitioned.
+	DefaultHeartbeatTimeout = 30 * time.Second
+
+	// DefaultRescheduleAfter is how long a node can be partitioned before
+	// its sandboxes are given up on and rescheduled elsewhere.
+	DefaultRescheduleAfter = 5 * time.Minute
+)
+
+// PartitionMonitor watches for nodes that have lost contact with the control
+// plane. A node's ready status is bound to its entity session, so it drops
+// away when the node misses its session heartbeats.
+//
+// A partitioned node's sandboxes may well still be running, so they're left
+// alone, and still count towards their pools, until the partition has lasted
+// RescheduleAfter. Only then are they marked DEAD so their pools replace them.
+// If the node returns before that, its sandboxes are adopted as they are; if
+// it returns after, it stops the ones that were replaced.
+//
+// Implements controller.ReconcileControllerI[*compute_v1alpha.Node]
+type PartitionMonitor struct {
+	log *slog.Logger
+	eac *entityserver_v1alpha.EntityAccessClient
+
+	// HeartbeatTimeout is how long a node can be without a ready status
+	// before it's treated as partitioned.
+	HeartbeatTimeout time.Duration
+
+	// RescheduleAfter is how long a node can be partitioned before its
+	// sandboxes are rescheduled. It should be long enough that a node
+	// restarting or briefly losing its network isn't mistaken for one
+	// that's gone.
+	RescheduleAfter time.Duration
+
+	now func() time.Time
+
+	mu    sync.Mutex
+	nodes map[entity.Id]*nodeState
+}
+
+type nodeState struct {
+	// lastSeen is when the node was last seen ready.
+	lastSeen time.Time
+
+	partitioned bool
+	rescheduled bool
+}
+
+// NewPartitionMonitor creates a new partition monitor
+func NewPartitionMonitor(
+	log *slog.Logger,
+	eac *entityserver_v1alpha.EntityAccessClient,
+) *PartitionMonitor {
+	return &PartitionMonitor{
+		log:              log.With("module", "partition-monitor"),
+		eac:              eac,
+		HeartbeatTimeout: DefaultHeartbeatTimeout,
+		RescheduleAfter:  DefaultRescheduleAfter,
+		now:              time.Now,
+		nodes:            make(map[entity.Id]*nodeState),
+	}
+}
+
+// Init initializes the controller.
+// Required by ReconcileControllerI.
+func (m *PartitionMonitor) Init(ctx context.Context) error {
+	m.log.Info("initializing partition monitor",
+		"heartbeat_timeout", m.HeartbeatTimeout,
+		"reschedule_after", m.RescheduleAfter)
+	return nil
+}
+
+// Reconcile tracks the node's status, detecting partitions and rejoins.
+// It never updates the node itself, only the sandboxes scheduled to it.
+func (m *PartitionMonitor) Reconcile(ctx context.Context, node *compute_v1alpha.Node, meta *entity.Meta) error {
+	now := m.now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	st, ok := m.nodes[node.ID]
+	if !ok {
+		// Nodes we haven't seen before, such as after the control plane
+		// restarts, get a full timeout before anything is done about them.
+		st = &nodeState{lastSeen: now}
+		m.nodes[node.ID] = st
+	}
+
+	switch node.Status {
+	case compute_v1alpha.READY:
+		if st.partitioned {
+			m.log.Info("node rejoined after partition",
+				"node", node.ID,
+				"partitioned_for", now.Sub(st.lastSeen),
+				"rescheduled", st.rescheduled)
+
+			if err := m.adopt(ctx, node.ID); err != nil {
+				return err
+			}
+		}
+
+		st.lastSeen = now
+		st.partitioned = false
+		st.rescheduled = false
+
+		return nil
+	case compute_v1alpha.DISABLED:
+		// Disabled nodes are being drained deliberately and stop their own
+		// sandboxes.
+		st.lastSeen = now
+		st.partitioned = false
+
+		return nil
+	}
+
+	missing := now.Sub(st.lastSeen)
+
+	if missing >= m.HeartbeatTimeout && !st.partitioned {
+		m.log.Warn("node missed heartbeats, treating as partitioned",
+			"node", node.ID,
+			"status", node.Status,
+			"last_seen", st.lastSeen)
+
+		st.partitioned = true
+	}
+
+	if missing >= m.RescheduleAfter && !st.rescheduled {
+		if err := m.reschedule(ctx, node.ID); err != nil {
+			return err
+		}
+
+		st.rescheduled = true
+	}
+
+	return nil
+}
+
+// Partitioned reports whether node is currently treated as partitioned.
+func (m *PartitionMonitor) Partitioned(node entity.Id) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	st, ok := m.nodes[node]
+	return ok && st.partitioned
+}
+
+// nodeSandboxes returns the sandboxes scheduled to node that are expected to
+// be running.
+func (m *PartitionMonitor) nodeSandboxes(ctx context.Context, node entity.Id) ([]*compute_v1alpha.Sandbox, error) {
+	resp, err := m.eac.List(ctx, compute_v1alpha.Index(compute_v1alpha.KindSandbox, node))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sandboxes on node %s: %w", node, err)
+	}
+
+	var ret []*compute_v1alpha.Sandbox
+
+	for _, ent := range resp.Values() {
+		var sb compute_v1alpha.Sandbox
+		sb.Decode(ent.Entity())
+
+		if sb.Status == compute_v1alpha.RUNNING || sb.Status == compute_v1alpha.PENDING {
+			ret = append(ret, &sb)
+		}
+	}
+
+	return ret, nil
+}
+
+// reschedule marks the sandboxes on a partitioned node DEAD, so that their
+// pools create replacements on nodes that are still reachable.
+func (m *PartitionMonitor) reschedule(ctx context.Context, node entity.Id) error {
+	sandboxes, err := m.nodeSandboxes(ctx, node)
+	if err != nil {
+		return err
+	}
+
+	m.log.Warn("node partitioned for too long, rescheduling its sandboxes",
+		"node", node,
+		"sandboxes", len(sandboxes))
+
+	for _, sb := range sandboxes {
+		_, err := m.eac.Patch(ctx, entity.New(
+			entity.Ref(entity.DBId, sb.ID),
+			(&compute_v1alpha.Sandbox{
+				Status: compute_v1alpha.DEAD,
+			}).Encode,
+		).Attrs(), 0)
+		if err != nil {
+			return fmt.Errorf("failed to mark sandbox %s as DEAD: %w", sb.ID, err)
+		}
+
+		m.log.Info("marked sandbox on partitioned node as DEAD", "sandbox", sb.ID, "node", node)
+	}
+
+	return nil
+}
+
+// adopt takes back the sandboxes of a node that has rejoined. The ones that
+// weren't rescheduled kept running through the partition and are left as
+// they are rather than being replaced.
+func (m *PartitionMonitor) adopt(ctx context.Context, node entity.Id) error {
+	sandboxes, err := m.nodeSandboxes(ctx, node)
+	if err != nil {
+		return err
+	}
+
+	m.log.Info("adopted sandboxes of rejoined node", "node", node, "sandboxes", len(sandboxes))
+
+	return nil
+}
@@ -0,0 +1,268 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"miren.dev/runtime/api/compute/compute_v1alpha"
+	"miren.dev/runtime/controllers/sandboxpool"
+	"miren.dev/runtime/pkg/controller"
+	"miren.dev/runtime/pkg/entity"
+	"miren.dev/runtime/pkg/entity/testutils"
+)
+
+// partitionHarness runs a pool of sandboxes on a node, along with the
+// controllers that react to the node disappearing and coming back.
+type partitionHarness struct {
+	t      *testing.T
+	ctx    context.Context
+	server *testutils.InMemEntityServer
+
+	monitor *PartitionMonitor
+	manager *sandboxpool.Manager
+	clock   time.Time
+
+	node   entity.Id
+	poolID entity.Id
+}
+
+func newPartitionHarness(t *testing.T, instances int64) *partitionHarness {
+	r := require.New(t)
+	ctx := context.Background()
+	log := testutils.TestLogger(t)
+
+	server, cleanup := testutils.NewInMemEntityServer(t)
+	t.Cleanup(cleanup)
+
+	h := &partitionHarness{
+		t:       t,
+		ctx:     ctx,
+		server:  server,
+		monitor: NewPartitionMonitor(log, server.EAC),
+		manager: sandboxpool.NewManager(log, server.EAC),
+		clock:   time.Now(),
+	}
+
+	h.monitor.now = func() time.Time { return h.clock }
+
+	node, err := server.Client.Create(ctx, "node-a", &compute_v1alpha.Node{
+		Status: compute_v1alpha.READY,
+	})
+	r.NoError(err)
+	h.node = node
+
+	poolID, err := server.Client.Create(ctx, "pool", &compute_v1alpha.SandboxPool{
+		Service:          "web",
+		DesiredInstances: instances,
+		SandboxSpec: compute_v1alpha.SandboxSpec{
+			Version: entity.Id("ver-1"),
+			Container: []compute_v1alpha.SandboxSpecContainer{
+				{Image: "test:latest"},
+			},
+		},
+	})
+	r.NoError(err)
+	h.poolID = poolID
+
+	h.reconcilePool()
+
+	// Schedule the pool's sandboxes to the node and start them, as the
+	// scheduler and the node's sandbox controller would.
+	for _, sb := range h.sandboxes() {
+		r.NoError(server.Client.UpdateAttrs(ctx, sb.ID,
+			(&compute_v1alpha.Sandbox{Status: compute_v1alpha.RUNNING}).Encode,
+			(&compute_v1alpha.Schedule{
+				Key: compute_v1alpha.Key{
+					Kind: compute_v1alpha.KindSandbox,
+					Node: node,
+				},
+			}).Encode,
+		))
+
+		// They've been running a while, so being marked DEAD isn't mistaken
+		// for a crash on startup.
+		ent := server.GetEntity(sb.ID)
+		ent.SetCreatedAt(h.clock.Add(-time.Hour))
+		server.AddEntity(ent)
+	}
+
+	h.reconcileNode()
+
+	return h
+}
+
+func (h *partitionHarness) reconcile(name string, id entity.Id, rc *controller.ReconcileController) {
+	h.t.Helper()
+
+	resp, err := h.server.EAC.Get(h.ctx, id.String())
+	require.NoError(h.t, err)
+
+	err = rc.ProcessEventForTest(h.ctx, controller.Event{
+		Type:   controller.EventUpdated,
+		Id:     id,
+		Entity: resp.Entity().Entity(),
+	})
+	require.NoError(h.t, err, name)
+}
+
+func (h *partitionHarness) reconcileNode() {
+	h.t.Helper()
+
+	h.reconcile("partition monitor", h.node, controller.NewReconcileController(
+		"test-partition-monitor",
+		testutils.TestLogger(h.t),
+		entity.Ref(entity.EntityKind, compute_v1alpha.KindNode),
+		h.server.EAC,
+		controller.AdaptReconcileController[compute_v1alpha.Node](h.monitor),
+		0,
+		1,
+	))
+}
+
+func (h *partitionHarness) reconcilePool() {
+	h.t.Helper()
+
+	h.reconcile("sandbox pool", h.poolID, controller.NewReconcileController(
+		"test-sandboxpool",
+		testutils.TestLogger(h.t),
+		entity.Ref(entity.EntityKind, compute_v1alpha.KindSandboxPool),
+		h.server.EAC,
+		controller.AdaptReconcileController[compute_v1alpha.SandboxPool](h.manager),
+		0,
+		1,
+	))
+}
+
+// setStatus sets the node's status, standing in for its session expiring
+// (unknown) or being reestablished (ready).
+func (h *partitionHarness) setStatus(status compute_v1alpha.NodeStatus) {
+	h.t.Helper()
+
+	require.NoError(h.t, h.server.Client.UpdateAttrs(h.ctx, h.node,
+		(&compute_v1alpha.Node{Status: status}).Encode,
+	))
+}
+
+// advance moves the clock on by d and lets the controllers react.
+func (h *partitionHarness) advance(d time.Duration) {
+	h.t.Helper()
+
+	h.clock = h.clock.Add(d)
+	h.reconcileNode()
+	h.reconcilePool()
+}
+
+func (h *partitionHarness) sandboxes() []*compute_v1alpha.Sandbox {
+	h.t.Helper()
+
+	resp, err := h.server.EAC.List(h.ctx, entity.Ref(entity.EntityKind, compute_v1alpha.KindSandbox))
+	require.NoError(h.t, err)
+
+	var ret []*compute_v1alpha.Sandbox
+
+	for _, ent := range resp.Values() {
+		var sb compute_v1alpha.Sandbox
+		sb.Decode(ent.Entity())
+		ret = append(ret, &sb)
+	}
+
+	return ret
+}
+
+// active counts the sandboxes expected to be running, by status.
+func (h *partitionHarness) active() map[compute_v1alpha.SandboxStatus]int {
+	counts := make(map[compute_v1alpha.SandboxStatus]int)
+
+	for _, sb := range h.sandboxes() {
+		if sb.Status == compute_v1alpha.RUNNING || sb.Status == compute_v1alpha.PENDING {
+			counts[sb.Status]++
+		}
+	}
+
+	return counts
+}
+
+func TestPartitionMonitor(t *testing.T) {
+	t.Run("sandboxes are adopted when a node rejoins before being rescheduled", func(t *testing.T) {
+		r := require.New(t)
+		h := newPartitionHarness(t, 2)
+
+		original := h.sandboxes()
+		r.Len(original, 2)
+
+		h.setStatus(compute_v1alpha.UNKNOWN)
+
+		h.advance(10 * time.Second)
+		r.False(h.monitor.Partitioned(h.node), "a brief absence isn't a partition")
+
+		h.advance(time.Minute)
+		r.True(h.monitor.Partitioned(h.node))
+		r.Equal(map[compute_v1alpha.SandboxStatus]int{compute_v1alpha.RUNNING: 2}, h.active(),
+			"sandboxes on a partitioned node should not be replaced yet")
+
+		h.setStatus(compute_v1alpha.READY)
+		h.advance(time.Minute)
+		r.False(h.monitor.Partitioned(h.node))
+
+		r.Equal(map[compute_v1alpha.SandboxStatus]int{compute_v1alpha.RUNNING: 2}, h.active())
+
+		after := h.sandboxes()
+		r.Len(after, 2, "no sandboxes should have been created")
+		r.ElementsMatch(
+			[]entity.Id{original[0].ID, original[1].ID},
+			[]entity.Id{after[0].ID, after[1].ID},
+		)
+	})
+
+	t.Run("sandboxes are replaced once, when a partition outlasts the timeout", func(t *testing.T) {
+		r := require.New(t)
+		h := newPartitionHarness(t, 2)
+
+		h.setStatus(compute_v1alpha.UNKNOWN)
+
+		h.advance(time.Minute)
+		h.advance(DefaultRescheduleAfter)
+
+		r.Equal(map[compute_v1alpha.SandboxStatus]int{compute_v1alpha.PENDING: 2}, h.active(),
+			"the pool should have replaced the partitioned sandboxes")
+		r.Len(h.sandboxes(), 4)
+
+		h.advance(time.Minute)
+		r.Len(h.sandboxes(), 4, "replacements are only made once")
+
+		// When the node returns its old sandboxes stay DEAD, for it to stop,
+		// rather than running alongside their replacements.
+		h.setStatus(compute_v1alpha.READY)
+		h.advance(time.Minute)
+
+		r.Equal(map[compute_v1alpha.SandboxStatus]int{compute_v1alpha.PENDING: 2}, h.active())
+		r.Len(h.sandboxes(), 4)
+	})
+
+	t.Run("nodes aren't treated as partitioned after the monitor restarts", func(t *testing.T) {
+		r := require.New(t)
+		h := newPartitionHarness(t, 1)
+
+		h.setStatus(compute_v1alpha.UNKNOWN)
+
+		h.monitor = NewPartitionMonitor(testutils.TestLogger(t), h.server.EAC)
+		h.monitor.now = func() time.Time { return h.clock }
+
+		h.advance(time.Second)
+		r.False(h.monitor.Partitioned(h.node))
+		r.Equal(map[compute_v1alpha.SandboxStatus]int{compute_v1alpha.RUNNING: 1}, h.active())
+	})
+
+	t.Run("drained nodes aren't partitioned", func(t *testing.T) {
+		r := require.New(t)
+		h := newPartitionHarness(t, 1)
+
+		h.setStatus(compute_v1alpha.DISABLED)
+		h.advance(DefaultRescheduleAfter * 2)
+
+		r.False(h.monitor.Partitioned(h.node))
+		r.Equal(map[compute_v1alpha.SandboxStatus]int{compute_v1alpha.RUNNING: 1}, h.active())
+	})
+}
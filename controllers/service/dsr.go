@@ -0,0 +1,252 @@
+package service
+
+// Direct server return (DSR) forwarding
+//
+// In the default NAT mode a service's traffic is DNATed to the chosen
+// endpoint, and replies are un-NATed on their way back through the node. In
+// DSR mode the node only picks the endpoint: packets keep the service IP as
+// their destination and are policy-routed to the endpoint, which replies to
+// the client directly from the service IP. The node never sees or rewrites
+// the return path.
+//
+// The endpoint is picked by hashing the client address and port, so every
+// packet of a connection goes to the same endpoint without relying on
+// conntrack. Each endpoint gets a firewall mark in the upper 16 bits of the
+// packet mark, and a routing table holding a default route via the endpoint.
+// A fwmark rule selects that table for marked packets.
+//
+// Endpoints of a DSR service must:
+//
+//   - accept traffic for the service IP, typically by adding it to their
+//     loopback interface, without answering ARP or neighbor discovery for it
+//     (arp_ignore=1, arp_announce=2)
+//   - listen on the service port itself, since the destination port can't be
+//     rewritten (target_port, if set, must equal port)
+//   - be on-link with the node, as the node forwards by routing rather than
+//     tunneling
+//
+// The node must allow forwarded packets that conntrack only sees in one
+// direction (net.netfilter.nf_conntrack_tcp_loose=1, the kernel default), and
+// replies sourced from the service IP must not be dropped by reverse path
+// filtering on the endpoint side (rp_filter=0 or 2). Node ports aren't
+// supported for DSR services, since they require rewriting the destination.
+
+import (
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+
+	"github.com/mr-tron/base58"
+	"golang.org/x/crypto/blake2b"
+
+	"miren.dev/runtime/api/network/network_v1alpha"
+)
+
+const (
+	// dsrMarkShift places DSR endpoint marks above the bits used for
+	// masquerade marking.
+	dsrMarkShift = 16
+	dsrMarkMask  = 0xffff0000
+
+	// dsrTableBase is the first routing table used for DSR endpoints.
+	dsrTableBase = 0x4d00
+)
+
+// dsrRoute is the policy routing needed to send marked packets to a DSR
+// endpoint.
+type dsrRoute struct {
+	Endpoint netip.Addr
+	Mark     uint32
+	Table    int
+}
+
+func (s *ServiceController) dsrServiceChain(ip netip.Addr, port uint16) string {
+	x := blake2b.Sum256([]byte(fmt.Sprintf("%s:%d", ip.String(), port)))
+	return fmt.Sprintf("dsr_%s", base58.Encode(x[:]))
+}
+
+func (s *ServiceController) dsrEndpointChain(ip netip.Addr) string {
+	x := blake2b.Sum256([]byte(ip.String()))
+	return fmt.Sprintf("dsrep_%s", base58.Encode(x[:]))
+}
+
+// dsrIndex returns the stable index of a DSR endpoint, used to derive its mark
+// and routing table.
+func (s *ServiceController) dsrIndex(ip netip.Addr) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if idx, ok := s.dsrEndpoints[ip]; ok {
+		return idx
+	}
+
+	idx := len(s.dsrEndpoints)
+	s.dsrEndpoints[ip] = idx
+	return idx
+}
+
+func dsrMark(idx int) uint32 {
+	return uint32(idx+1) << dsrMarkShift
+}
+
+// initDSR adds the maps and chains that DSR services hang off of. Unlike the
+// NAT chains these are filter and route chains hooked at mangle priority, so
+// the mark they set is seen by the routing decision.
+func (s *ServiceController) initDSR(nc *nftCommands) {
+	if !nc.knownMaps.Contains("service_dsr_ip4s") {
+		nc.append("add map inet %s service_dsr_ip4s { type ipv4_addr . inet_proto . inet_service : verdict; }", s.table)
+		nc.knownMaps.Add("service_dsr_ip4s")
+	}
+
+	if !nc.knownMaps.Contains("service_dsr_ip6s") {
+		nc.append("add map inet %s service_dsr_ip6s { type ipv6_addr . inet_proto . inet_service : verdict; }", s.table)
+		nc.knownMaps.Add("service_dsr_ip6s")
+	}
+
+	if !nc.knownChains.Contains("dsr-services") {
+		nc.append("add chain inet %s dsr-services", s.table)
+		nc.append("add counter inet %s dsr", s.table)
+		nc.append("add rule inet %s dsr-services ip daddr . meta l4proto . th dport vmap @service_dsr_ip4s", s.table)
+		nc.append("add rule inet %s dsr-services ip6 daddr . meta l4proto . th dport vmap @service_dsr_ip6s", s.table)
+		nc.knownChains.Add("dsr-services")
+	}
+
+	if !nc.knownChains.Contains("dsr-prerouting") {
+		nc.append("add chain inet %s dsr-prerouting { type filter hook prerouting priority mangle; }", s.table)
+		nc.append("add rule inet %s dsr-prerouting jump dsr-services", s.table)
+		nc.knownChains.Add("dsr-prerouting")
+	}
+
+	if !nc.knownChains.Contains("dsr-output") {
+		nc.append("add chain inet %s dsr-output { type route hook output priority mangle; }", s.table)
+		nc.append("add rule inet %s dsr-output jump dsr-services", s.table)
+		nc.knownChains.Add("dsr-output")
+	}
+}
+
+// checkDSRPorts rejects port settings that DSR can't honor.
+func checkDSRPorts(ports []network_v1alpha.Port) error {
+	for _, tp := range ports {
+		if tp.TargetPort != 0 && tp.TargetPort != tp.Port {
+			return fmt.Errorf("port %s: dsr forwarding can't translate port %d to target port %d", tp.Name, tp.Port, tp.TargetPort)
+		}
+
+		if tp.NodePort != 0 {
+			return fmt.Errorf("port %s: node ports are not supported with dsr forwarding", tp.Name)
+		}
+	}
+
+	return nil
+}
+
+func (s *ServiceController) setupDSREndpointChain(cmd *nftCommands, ip netip.Addr) string {
+	endpoint := s.dsrEndpointChain(ip)
+
+	idx := s.dsrIndex(ip)
+	mark := dsrMark(idx)
+
+	cmd.routes = append(cmd.routes, dsrRoute{
+		Endpoint: ip,
+		Mark:     mark,
+		Table:    dsrTableBase + idx,
+	})
+
+	if cmd.knownChains.Contains(endpoint) {
+		return endpoint
+	}
+
+	cmd.knownChains.Add(endpoint)
+
+	cmd.append("add chain inet %s %s", s.table, endpoint)
+	cmd.append("add rule inet %s %s counter name \"dsr\" meta mark set mark and 0x%x or 0x%x", s.table, endpoint, ^uint32(dsrMarkMask), mark)
+	return endpoint
+}
+
+func (s *ServiceController) createDSRServiceChain(cmd *nftCommands, ip netip.Addr, port int) {
+	// A service switching from NAT must stop matching the NAT map, or it
+	// would be DNATed before the DSR chains see it.
+	if nat := s.serviceChain(ip, uint16(port)); cmd.knownChains.Contains(nat) {
+		cmd.append("delete element inet %s %s { %s . tcp . %d }", s.table, serviceMap(ip, false), ip.String(), port)
+		cmd.knownChains.Remove(nat)
+		s.forgetChainEndpoints(nat)
+	}
+
+	srv := s.dsrServiceChain(ip, uint16(port))
+	if cmd.knownChains.Contains(srv) {
+		return
+	}
+
+	cmd.knownChains.Add(srv)
+
+	cmd.append("add chain inet %s %s", s.table, srv)
+	cmd.append("add element inet %s %s { %s . tcp . %d : goto %s }", s.table, serviceMap(ip, true), ip.String(), port, srv)
+}
+
+// removeDSRServiceChain stops a service that switched back to NAT from
+// matching the DSR map.
+func (s *ServiceController) removeDSRServiceChain(cmd *nftCommands, ip netip.Addr, port int) {
+	srv := s.dsrServiceChain(ip, uint16(port))
+	if !cmd.knownChains.Contains(srv) {
+		return
+	}
+
+	cmd.append("delete element inet %s %s { %s . tcp . %d }", s.table, serviceMap(ip, true), ip.String(), port)
+	cmd.knownChains.Remove(srv)
+	s.forgetChainEndpoints(srv)
+}
+
+func (s *ServiceController) forgetChainEndpoints(chain string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.chainEndpoints, chain)
+}
+
+func (s *ServiceController) updateDSRServiceEndpoints(cmd *nftCommands, sip netip.Addr, sport int, endpoints []string) {
+	if len(endpoints) == 0 {
+		return
+	}
+
+	srv := s.dsrServiceChain(sip, uint16(sport))
+
+	slices.Sort(endpoints)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if cur, ok := s.chainEndpoints[srv]; ok && slices.Equal(cur, endpoints) {
+		return
+	}
+
+	s.chainEndpoints[srv] = endpoints
+
+	cmd.append("flush chain inet %s %s", s.table, srv)
+	var vmap []string
+
+	for i, ep := range endpoints {
+		vmap = append(vmap, fmt.Sprintf("%d : goto %s", i, ep))
+	}
+
+	// Hash the flow rather than picking at random, so that every packet of a
+	// connection reaches the same endpoint.
+	saddr := "ip saddr"
+	if sip.Is6() {
+		saddr = "ip6 saddr"
+	}
+
+	cmd.append("add rule inet %s %s jhash %s . tcp sport mod %d vmap { %s }", s.table, srv, saddr, len(endpoints), strings.Join(vmap, ", "))
+}
+
+func serviceMap(ip netip.Addr, dsr bool) string {
+	switch {
+	case dsr && ip.Is4():
+		return "service_dsr_ip4s"
+	case dsr:
+		return "service_dsr_ip6s"
+	case ip.Is4():
+		return "service_ip4s"
+	default:
+		return "service_ip6s"
+	}
+}
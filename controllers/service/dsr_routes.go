@@ -0,0 +1,62 @@
+//go:build linux
+
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+
+	"github.com/vishvananda/netlink"
+)
+
+// applyRoutes installs the policy routing for DSR endpoints. Existing rules
+// and routes are left in place or replaced, so it's safe to repeat.
+func (s *ServiceController) applyRoutes(routes []dsrRoute) error {
+	for _, r := range routes {
+		family := netlink.FAMILY_V4
+		if r.Endpoint.Is6() {
+			family = netlink.FAMILY_V6
+		}
+
+		gw := net.IP(r.Endpoint.AsSlice())
+
+		// Find the interface the endpoint is reachable on, since the gateway
+		// has to be resolvable from inside the endpoint's own table.
+		via, err := netlink.RouteGet(gw)
+		if err != nil {
+			return fmt.Errorf("failed to find route to dsr endpoint %s: %w", r.Endpoint, err)
+		}
+
+		if len(via) == 0 {
+			return fmt.Errorf("no route to dsr endpoint %s", r.Endpoint)
+		}
+
+		route := &netlink.Route{
+			Family:    family,
+			Table:     r.Table,
+			Gw:        gw,
+			LinkIndex: via[0].LinkIndex,
+			Flags:     int(netlink.FLAG_ONLINK),
+		}
+
+		if err := netlink.RouteReplace(route); err != nil {
+			return fmt.Errorf("failed to add route via dsr endpoint %s: %w", r.Endpoint, err)
+		}
+
+		mask := uint32(dsrMarkMask)
+
+		rule := netlink.NewRule()
+		rule.Family = family
+		rule.Mark = r.Mark
+		rule.Mask = &mask
+		rule.Table = r.Table
+
+		if err := netlink.RuleAdd(rule); err != nil && !errors.Is(err, syscall.EEXIST) {
+			return fmt.Errorf("failed to add rule for dsr endpoint %s: %w", r.Endpoint, err)
+		}
+	}
+
+	return nil
+}
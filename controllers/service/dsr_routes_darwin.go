@@ -0,0 +1,13 @@
+//go:build darwin
+
+package service
+
+import "fmt"
+
+func (s *ServiceController) applyRoutes(routes []dsrRoute) error {
+	if len(routes) > 0 {
+		return fmt.Errorf("dsr forwarding is only supported on linux")
+	}
+
+	return nil
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"miren.dev/runtime/api/network/network_v1alpha"
+	"miren.dev/runtime/pkg/set"
+)
+
+func TestDSRRuleGeneration(t *testing.T) {
+	// dryRun generates the rules for srv without touching nftables.
+	dryRun := func(t *testing.T, s *ServiceController, srv *network_v1alpha.Service, endpoints ...string) *nftCommands {
+		t.Helper()
+
+		var eps []network_v1alpha.Endpoint
+		for _, ip := range endpoints {
+			eps = append(eps, network_v1alpha.Endpoint{Ip: ip, Port: 80})
+		}
+
+		cmd := s.cmd.Clone()
+		require.NoError(t, s.generate(cmd, srv, eps))
+		return cmd
+	}
+
+	newController := func() *ServiceController {
+		s := &ServiceController{
+			table:            "miren",
+			chainEndpoints:   make(map[string][]string),
+			dsrEndpoints:     make(map[netip.Addr]int),
+			routablePrefixes: []netip.Prefix{netip.MustParsePrefix("10.8.0.0/16")},
+			cmd: &nftCommands{
+				knownChains: set.New[string](),
+				knownMaps:   set.New[string](),
+			},
+		}
+		s.initDSR(s.cmd)
+		return s
+	}
+
+	service := func(fwd network_v1alpha.ServiceForwarding) *network_v1alpha.Service {
+		return &network_v1alpha.Service{
+			ID:         "svc-web",
+			Ip:         []string{"10.10.0.1"},
+			Forwarding: fwd,
+			Port: []network_v1alpha.Port{
+				{Name: "http", Port: 80},
+			},
+		}
+	}
+
+	containing := func(cmds []string, substr string) []string {
+		var out []string
+		for _, c := range cmds {
+			if strings.Contains(c, substr) {
+				out = append(out, c)
+			}
+		}
+		return out
+	}
+
+	t.Run("installs DSR hooks at mangle priority", func(t *testing.T) {
+		r := require.New(t)
+
+		s := newController()
+
+		r.Contains(s.cmd.commands, "add chain inet miren dsr-prerouting { type filter hook prerouting priority mangle; }")
+		r.Contains(s.cmd.commands, "add chain inet miren dsr-output { type route hook output priority mangle; }")
+		r.Contains(s.cmd.commands, "add rule inet miren dsr-services ip daddr . meta l4proto . th dport vmap @service_dsr_ip4s")
+
+		// Already known chains and maps aren't added again.
+		again := s.cmd.Clone()
+		s.initDSR(again)
+		r.Empty(again.commands)
+	})
+
+	t.Run("DSR ruleset differs from NAT", func(t *testing.T) {
+		r := require.New(t)
+
+		nat := dryRun(t, newController(), service(network_v1alpha.NAT), "10.8.0.5", "10.8.0.6")
+		dsr := dryRun(t, newController(), service(network_v1alpha.DSR), "10.8.0.5", "10.8.0.6")
+
+		// NAT rewrites the destination, masquerades off-cluster clients and
+		// balances new connections at random. It needs no routing.
+		r.Len(containing(nat.commands, "dnat ip to"), 2)
+		r.Contains(nat.commands[len(nat.commands)-1], "numgen random mod 2")
+		r.NotEmpty(containing(nat.commands, "jump mark-for-masq"))
+		r.Len(containing(nat.commands, "service_ip4s { 10.10.0.1 . tcp . 80"), 1)
+		r.Empty(containing(nat.commands, "service_dsr"))
+		r.Empty(nat.routes)
+
+		// DSR leaves the packet alone apart from marking it, and picks the
+		// endpoint by hashing the flow so a connection stays on one endpoint.
+		r.Empty(containing(dsr.commands, "dnat"))
+		r.Empty(containing(dsr.commands, "masq"))
+		r.Empty(containing(dsr.commands, "numgen"))
+		r.Empty(containing(dsr.commands, "service_ip4s"))
+		r.Len(containing(dsr.commands, "service_dsr_ip4s { 10.10.0.1 . tcp . 80"), 1)
+		r.Len(containing(dsr.commands, "jhash ip saddr . tcp sport mod 2 vmap"), 1)
+		r.Len(containing(dsr.commands, "meta mark set mark and 0xffff or 0x10000"), 1)
+		r.Len(containing(dsr.commands, "meta mark set mark and 0xffff or 0x20000"), 1)
+
+		// Each endpoint is reached through its own routing table.
+		r.Equal([]dsrRoute{
+			{Endpoint: netip.MustParseAddr("10.8.0.5"), Mark: 0x10000, Table: dsrTableBase},
+			{Endpoint: netip.MustParseAddr("10.8.0.6"), Mark: 0x20000, Table: dsrTableBase + 1},
+		}, dsr.routes)
+	})
+
+	t.Run("uses the IPv6 map and hash for IPv6 services", func(t *testing.T) {
+		r := require.New(t)
+
+		srv := service(network_v1alpha.DSR)
+		srv.Ip = []string{"fd00::1"}
+
+		cmd := dryRun(t, newController(), srv, "fd00::10")
+
+		r.Len(containing(cmd.commands, "service_dsr_ip6s { fd00::1 . tcp . 80"), 1)
+		r.Len(containing(cmd.commands, "jhash ip6 saddr . tcp sport mod 1 vmap"), 1)
+	})
+
+	t.Run("endpoints keep their mark across services", func(t *testing.T) {
+		r := require.New(t)
+
+		s := newController()
+
+		dryRun(t, s, service(network_v1alpha.DSR), "10.8.0.5", "10.8.0.6")
+
+		other := service(network_v1alpha.DSR)
+		other.Ip = []string{"10.10.0.2"}
+
+		cmd := dryRun(t, s, other, "10.8.0.6")
+
+		r.Equal([]dsrRoute{
+			{Endpoint: netip.MustParseAddr("10.8.0.6"), Mark: 0x20000, Table: dsrTableBase + 1},
+		}, cmd.routes)
+
+		// The endpoint chain already exists, so only the service is added.
+		r.Empty(containing(cmd.commands, "add chain inet miren dsrep_"))
+	})
+
+	t.Run("switching modes moves the service between maps", func(t *testing.T) {
+		r := require.New(t)
+
+		s := newController()
+
+		dryRun(t, s, service(network_v1alpha.NAT), "10.8.0.5")
+
+		cmd := dryRun(t, s, service(network_v1alpha.DSR), "10.8.0.5")
+		r.Equal("delete element inet miren service_ip4s { 10.10.0.1 . tcp . 80 }", cmd.commands[2])
+		r.Len(containing(cmd.commands, "add element inet miren service_dsr_ip4s"), 1)
+
+		cmd = dryRun(t, s, service(network_v1alpha.NAT), "10.8.0.5")
+		r.Contains(cmd.commands, "delete element inet miren service_dsr_ip4s { 10.10.0.1 . tcp . 80 }")
+		r.Len(containing(cmd.commands, "add element inet miren service_ip4s"), 1)
+		r.Len(containing(cmd.commands, "numgen random mod 1"), 1)
+	})
+
+	t.Run("rejects ports DSR can't forward", func(t *testing.T) {
+		r := require.New(t)
+
+		s := newController()
+
+		srv := service(network_v1alpha.DSR)
+		srv.Port[0].TargetPort = 8080
+		r.ErrorContains(s.generate(s.cmd.Clone(), srv, nil), "target port")
+
+		srv = service(network_v1alpha.DSR)
+		srv.Port[0].NodePort = 30080
+		r.ErrorContains(s.generate(s.cmd.Clone(), srv, nil), "node ports")
+
+		// A target port matching the service port needs no translation.
+		srv = service(network_v1alpha.DSR)
+		srv.Port[0].TargetPort = 80
+		r.NoError(s.generate(s.cmd.Clone(), srv, nil))
+	})
+}
@@ -0,0 +1,263 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+
+	"github.com/mr-tron/base58"
+	"golang.org/x/crypto/blake2b"
+)
+
+// Backend is one destination of a load balanced forward. Connections are
+// spread over the healthy backends in proportion to their weight.
+type Backend struct {
+	Addr netip.Addr
+	Port int
+
+	// Weight is the backend's share of connections relative to the others.
+	// Zero is the same as 1.
+	Weight int
+}
+
+func (b Backend) addrPort() netip.AddrPort {
+	return netip.AddrPortFrom(b.Addr.Unmap(), uint16(b.Port))
+}
+
+func (b Backend) weight() int {
+	return max(b.Weight, 1)
+}
+
+// balanceChain is named for the source alone, so the backends of a forward
+// can change without it moving.
+func (s *ServiceController) balanceChain(src netip.AddrPort) string {
+	x := blake2b.Sum256([]byte(src.String()))
+	return fmt.Sprintf("balance_%s", base58.Encode(x[:]))
+}
+
+func checkBackends(src netip.AddrPort, backends []Backend) error {
+	if len(backends) == 0 {
+		return fmt.Errorf("a load balanced forward needs at least one backend")
+	}
+
+	seen := map[netip.AddrPort]bool{}
+
+	for _, b := range backends {
+		if _, _, err := portForwardAddrs(src.Addr(), int(src.Port()), b.Addr, b.Port); err != nil {
+			return err
+		}
+
+		if b.Weight < 0 {
+			return fmt.Errorf("backend %s has a negative weight", b.addrPort())
+		}
+
+		if seen[b.addrPort()] {
+			return fmt.Errorf("backend %s is listed more than once", b.addrPort())
+		}
+
+		seen[b.addrPort()] = true
+	}
+
+	return nil
+}
+
+func (s *ServiceController) setupBalancedForward(cmd *nftCommands, src netip.AddrPort, backends []Backend) error {
+	s.mu.Lock()
+
+	if cur, ok := s.portForwards[src]; ok {
+		s.mu.Unlock()
+		return fmt.Errorf("%s is already forwarded to %s", src, cur)
+	}
+
+	s.balanced[src] = slices.Clone(backends)
+	s.mu.Unlock()
+
+	chain := s.balanceChain(src)
+	if !cmd.knownChains.Contains(chain) {
+		cmd.knownChains.Add(chain)
+
+		cmd.append("add chain inet %s %s", s.table, chain)
+		cmd.append("add element inet %s %s { %s . tcp . %d : goto %s }", s.table, forwardMap(src.Addr()), src.Addr(), src.Port(), chain)
+	}
+
+	return s.updateBalancedForward(cmd, src)
+}
+
+// updateBalancedForward rewrites the rule spreading src's connections over
+// its healthy backends, if they've changed since it was last written.
+func (s *ServiceController) updateBalancedForward(cmd *nftCommands, src netip.AddrPort) error {
+	s.mu.Lock()
+	backends := s.balanced[src]
+	s.mu.Unlock()
+
+	var (
+		vmap  []string
+		total int
+	)
+
+	for _, b := range backends {
+		if s.isUnhealthy(b.addrPort()) {
+			continue
+		}
+
+		ep, err := s.setupEndpointChain(cmd, b.addrPort().Addr(), b.addrPort().Port())
+		if err != nil {
+			return fmt.Errorf("failed to setup endpoint chain: %w", err)
+		}
+
+		if w := b.weight(); w == 1 {
+			vmap = append(vmap, fmt.Sprintf("%d : goto %s", total, ep))
+		} else {
+			vmap = append(vmap, fmt.Sprintf("%d-%d : goto %s", total, total+w-1, ep))
+		}
+
+		total += b.weight()
+	}
+
+	chain := s.balanceChain(src)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if cur, ok := s.chainEndpoints[chain]; ok && slices.Equal(cur, vmap) {
+		return nil
+	}
+
+	s.chainEndpoints[chain] = vmap
+
+	cmd.append("flush chain inet %s %s", s.table, chain)
+
+	// With every backend down there's nowhere to send the connection, so
+	// refuse it rather than leaving it to hit the source address.
+	if len(vmap) == 0 {
+		cmd.append("add rule inet %s %s counter drop", s.table, chain)
+		return nil
+	}
+
+	cmd.append("add rule inet %s %s numgen random mod %d vmap { %s }", s.table, chain, total, strings.Join(vmap, ", "))
+	return nil
+}
+
+func (s *ServiceController) removeBalancedForward(cmd *nftCommands, src netip.AddrPort) {
+	s.mu.Lock()
+	delete(s.balanced, src)
+	s.mu.Unlock()
+
+	chain := s.balanceChain(src)
+	if !cmd.knownChains.Contains(chain) {
+		return
+	}
+
+	cmd.knownChains.Remove(chain)
+	s.forgetChainEndpoints(chain)
+
+	cmd.append("delete element inet %s %s { %s . tcp . %d }", s.table, forwardMap(src.Addr()), src.Addr(), src.Port())
+	cmd.append("flush chain inet %s %s", s.table, chain)
+	cmd.append("delete chain inet %s %s", s.table, chain)
+}
+
+func (s *ServiceController) isUnhealthy(backend netip.AddrPort) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.unhealthy.Contains(backend)
+}
+
+// setHealth records a backend's health and rewrites the forwards using it.
+func (s *ServiceController) setHealth(cmd *nftCommands, backend netip.AddrPort, healthy bool) error {
+	s.mu.Lock()
+
+	if healthy {
+		s.unhealthy.Remove(backend)
+	} else {
+		s.unhealthy.Add(backend)
+	}
+
+	var affected []netip.AddrPort
+
+	for src, backends := range s.balanced {
+		if slices.ContainsFunc(backends, func(b Backend) bool { return b.addrPort() == backend }) {
+			affected = append(affected, src)
+		}
+	}
+
+	s.mu.Unlock()
+
+	slices.SortFunc(affected, netip.AddrPort.Compare)
+
+	for _, src := range affected {
+		if err := s.updateBalancedForward(cmd, src); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetupLoadBalancedForwarding forwards TCP traffic for src:sport across
+// backends, weighted by their Weight and skipping those marked unhealthy.
+// Calling it again for the same source replaces its backends.
+func (s *ServiceController) SetupLoadBalancedForwarding(ctx context.Context, src netip.Addr, sport int, backends []Backend) error {
+	if !src.IsValid() || sport <= 0 || sport > 65535 {
+		return fmt.Errorf("invalid load balanced forward source %s:%d", src, sport)
+	}
+
+	from := netip.AddrPortFrom(src.Unmap(), uint16(sport))
+
+	if err := checkBackends(from, backends); err != nil {
+		return err
+	}
+
+	cmd := s.cmd.Clone()
+
+	if err := s.setupBalancedForward(cmd, from, backends); err != nil {
+		return err
+	}
+
+	if err := s.apply(ctx, cmd); err != nil {
+		return fmt.Errorf("failed to set up load balanced forward: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveLoadBalancedForwarding removes the forward for src:sport set up by
+// SetupLoadBalancedForwarding.
+func (s *ServiceController) RemoveLoadBalancedForwarding(ctx context.Context, src netip.Addr, sport int) error {
+	cmd := s.cmd.Clone()
+
+	s.removeBalancedForward(cmd, netip.AddrPortFrom(src.Unmap(), uint16(sport)))
+
+	if err := s.apply(ctx, cmd); err != nil {
+		return fmt.Errorf("failed to remove load balanced forward: %w", err)
+	}
+
+	return nil
+}
+
+// MarkUnhealthy stops sending new connections to the backend at ip:port,
+// rewriting every load balanced forward that uses it.
+func (s *ServiceController) MarkUnhealthy(ctx context.Context, ip netip.Addr, port int) error {
+	return s.markHealth(ctx, ip, port, false)
+}
+
+// MarkHealthy returns the backend at ip:port to the forwards that use it.
+func (s *ServiceController) MarkHealthy(ctx context.Context, ip netip.Addr, port int) error {
+	return s.markHealth(ctx, ip, port, true)
+}
+
+func (s *ServiceController) markHealth(ctx context.Context, ip netip.Addr, port int, healthy bool) error {
+	cmd := s.cmd.Clone()
+
+	if err := s.setHealth(cmd, netip.AddrPortFrom(ip.Unmap(), uint16(port)), healthy); err != nil {
+		return err
+	}
+
+	if err := s.apply(ctx, cmd); err != nil {
+		return fmt.Errorf("failed to update backend health: %w", err)
+	}
+
+	return nil
+}
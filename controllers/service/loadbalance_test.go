@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"miren.dev/runtime/pkg/set"
+)
+
+func TestLoadBalancedForwarding(t *testing.T) {
+	newController := func() *ServiceController {
+		s := &ServiceController{
+			table:          "miren",
+			chainEndpoints: make(map[string][]string),
+			dsrEndpoints:   make(map[netip.Addr]int),
+			portForwards:   make(map[netip.AddrPort]netip.AddrPort),
+			balanced:       make(map[netip.AddrPort][]Backend),
+			unhealthy:      set.New[netip.AddrPort](),
+			cmd: &nftCommands{
+				knownChains: set.New[string](),
+				knownMaps:   set.New[string](),
+			},
+		}
+		s.initPortForwards(s.cmd)
+		return s
+	}
+
+	var (
+		src = netip.MustParseAddrPort("10.10.0.5:80")
+
+		blue  = Backend{Addr: netip.MustParseAddr("10.8.0.20"), Port: 3000, Weight: 3}
+		green = Backend{Addr: netip.MustParseAddr("10.8.0.21"), Port: 3000}
+	)
+
+	setup := func(t *testing.T, s *ServiceController, backends ...Backend) []string {
+		t.Helper()
+
+		cmd := s.cmd.Clone()
+		require.NoError(t, s.setupBalancedForward(cmd, src, backends))
+		return cmd.commands
+	}
+
+	health := func(t *testing.T, s *ServiceController, b Backend, healthy bool) []string {
+		t.Helper()
+
+		cmd := s.cmd.Clone()
+		require.NoError(t, s.setHealth(cmd, b.addrPort(), healthy))
+		return cmd.commands
+	}
+
+	// balanceRule returns the rule that picks a backend, or "" if there's
+	// none among cmds.
+	balanceRule := func(s *ServiceController, cmds []string) string {
+		prefix := "add rule inet miren " + s.balanceChain(src) + " "
+
+		for _, c := range cmds {
+			if strings.HasPrefix(c, prefix) {
+				return strings.TrimPrefix(c, prefix)
+			}
+		}
+
+		return ""
+	}
+
+	t.Run("spreads connections by weight", func(t *testing.T) {
+		r := require.New(t)
+
+		s := newController()
+
+		cmds := setup(t, s, blue, green)
+
+		chain := s.balanceChain(src)
+		r.Contains(cmds, "add element inet miren port_forward_ip4s { 10.10.0.5 . tcp . 80 : goto "+chain+" }")
+		r.Contains(cmds, "add rule inet miren "+s.endpointChain(blue.Addr, 3000)+" meta l4proto tcp counter dnat ip to 10.8.0.20:3000")
+
+		r.Equal("numgen random mod 4 vmap { 0-2 : goto "+s.endpointChain(blue.Addr, 3000)+", 3 : goto "+s.endpointChain(green.Addr, 3000)+" }", balanceRule(s, cmds))
+
+		r.Empty(setup(t, s, blue, green), "re-applying the same backends should change nothing")
+	})
+
+	t.Run("rewrites the rule as backends change health", func(t *testing.T) {
+		r := require.New(t)
+
+		s := newController()
+		setup(t, s, blue, green)
+
+		cmds := health(t, s, blue, false)
+		r.Contains(cmds, "flush chain inet miren "+s.balanceChain(src))
+		r.Equal("numgen random mod 1 vmap { 0 : goto "+s.endpointChain(green.Addr, 3000)+" }", balanceRule(s, cmds))
+
+		r.Empty(health(t, s, blue, false), "marking it unhealthy again should change nothing")
+
+		// With nothing healthy, connections are dropped
+		r.Equal("counter drop", balanceRule(s, health(t, s, green, false)))
+
+		r.Equal("numgen random mod 1 vmap { 0 : goto "+s.endpointChain(green.Addr, 3000)+" }", balanceRule(s, health(t, s, green, true)))
+
+		r.Equal("numgen random mod 4 vmap { 0-2 : goto "+s.endpointChain(blue.Addr, 3000)+", 3 : goto "+s.endpointChain(green.Addr, 3000)+" }", balanceRule(s, health(t, s, blue, true)))
+	})
+
+	t.Run("applies health to backends added later", func(t *testing.T) {
+		r := require.New(t)
+
+		s := newController()
+
+		r.Empty(health(t, s, green, false), "no forward uses it yet")
+
+		cmds := setup(t, s, blue, green)
+		r.Equal("numgen random mod 3 vmap { 0-2 : goto "+s.endpointChain(blue.Addr, 3000)+" }", balanceRule(s, cmds))
+	})
+
+	t.Run("replaces the backends of a forward", func(t *testing.T) {
+		r := require.New(t)
+
+		s := newController()
+		setup(t, s, blue)
+
+		cmds := setup(t, s, green)
+		r.NotContains(cmds, "add chain inet miren "+s.balanceChain(src))
+		r.Equal("numgen random mod 1 vmap { 0 : goto "+s.endpointChain(green.Addr, 3000)+" }", balanceRule(s, cmds))
+
+		// blue's health no longer matters to it
+		r.Empty(health(t, s, blue, false))
+	})
+
+	t.Run("removes the forward", func(t *testing.T) {
+		r := require.New(t)
+
+		s := newController()
+		setup(t, s, blue, green)
+
+		chain := s.balanceChain(src)
+
+		cmd := s.cmd.Clone()
+		s.removeBalancedForward(cmd, src)
+		r.Equal([]string{
+			"delete element inet miren port_forward_ip4s { 10.10.0.5 . tcp . 80 }",
+			"flush chain inet miren " + chain,
+			"delete chain inet miren " + chain,
+		}, cmd.commands)
+
+		r.Empty(health(t, s, blue, false))
+
+		// Set up again, the rule is written afresh
+		r.NotEmpty(balanceRule(s, setup(t, s, blue, green)))
+	})
+
+	t.Run("won't balance a port that's already forwarded", func(t *testing.T) {
+		r := require.New(t)
+
+		s := newController()
+
+		r.NoError(s.setupPortForward(s.cmd.Clone(), src, green.addrPort()))
+
+		err := s.setupBalancedForward(s.cmd.Clone(), src, []Backend{blue})
+		r.ErrorContains(err, "already forwarded")
+
+		other := netip.MustParseAddrPort("10.10.0.5:443")
+		r.NoError(s.setupBalancedForward(s.cmd.Clone(), other, []Backend{blue}))
+
+		err = s.setupPortForward(s.cmd.Clone(), other, green.addrPort())
+		r.ErrorContains(err, "already load balanced")
+	})
+
+	t.Run("rejects bad backends", func(t *testing.T) {
+		r := require.New(t)
+
+		s := newController()
+		ctx := context.Background()
+
+		err := s.SetupLoadBalancedForwarding(ctx, src.Addr(), 80, nil)
+		r.ErrorContains(err, "at least one backend")
+
+		err = s.SetupLoadBalancedForwarding(ctx, src.Addr(), 80, []Backend{blue, blue})
+		r.ErrorContains(err, "more than once")
+
+		err = s.SetupLoadBalancedForwarding(ctx, src.Addr(), 80, []Backend{{Addr: blue.Addr, Port: 80, Weight: -1}})
+		r.ErrorContains(err, "negative weight")
+
+		err = s.SetupLoadBalancedForwarding(ctx, src.Addr(), 80, []Backend{{Addr: netip.MustParseAddr("fd00::1"), Port: 80}})
+		r.ErrorContains(err, "address families differ")
+	})
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+
+	"github.com/mr-tron/base58"
+	"golang.org/x/crypto/blake2b"
+)
+
+// Port forwards DNAT TCP traffic for one address and port to another. Each
+// forward has a chain of its own, named for the forward, reached through a
+// map keyed by the source address and port. That lets a forward be removed
+// exactly, by deleting its map element and chain, without disturbing the
+// rules of services or other forwards.
+
+func (s *ServiceController) forwardChain(src netip.AddrPort, dst netip.AddrPort) string {
+	x := blake2b.Sum256([]byte(fmt.Sprintf("%s->%s", src, dst)))
+	return fmt.Sprintf("forward_%s", base58.Encode(x[:]))
+}
+
+func forwardMap(ip netip.Addr) string {
+	if ip.Is4() {
+		return "port_forward_ip4s"
+	}
+
+	return "port_forward_ip6s"
+}
+
+// initPortForwards adds the maps port forwards hang off of, checked by the
+// services chain after the services themselves.
+func (s *ServiceController) initPortForwards(nc *nftCommands) {
+	if !nc.knownMaps.Contains("port_forward_ip4s") {
+		nc.append("add map inet %s port_forward_ip4s { type ipv4_addr . inet_proto . inet_service : verdict; }", s.table)
+		nc.append("add rule inet %s services ip daddr . meta l4proto . th dport vmap @port_forward_ip4s", s.table)
+		nc.knownMaps.Add("port_forward_ip4s")
+	}
+
+	if !nc.knownMaps.Contains("port_forward_ip6s") {
+		nc.append("add map inet %s port_forward_ip6s { type ipv6_addr . inet_proto . inet_service : verdict; }", s.table)
+		nc.append("add rule inet %s services ip6 daddr . meta l4proto . th dport vmap @port_forward_ip6s", s.table)
+		nc.knownMaps.Add("port_forward_ip6s")
+	}
+}
+
+func portForwardAddrs(src netip.Addr, sport int, dst netip.Addr, dport int) (netip.AddrPort, netip.AddrPort, error) {
+	if !src.IsValid() || !dst.IsValid() {
+		return netip.AddrPort{}, netip.AddrPort{}, fmt.Errorf("invalid port forward address")
+	}
+
+	src, dst = src.Unmap(), dst.Unmap()
+
+	if src.Is4() != dst.Is4() {
+		return netip.AddrPort{}, netip.AddrPort{}, fmt.Errorf("can't forward between %s and %s, the address families differ", src, dst)
+	}
+
+	for _, port := range []int{sport, dport} {
+		if port <= 0 || port > 65535 {
+			return netip.AddrPort{}, netip.AddrPort{}, fmt.Errorf("invalid port forward port: %d", port)
+		}
+	}
+
+	return netip.AddrPortFrom(src, uint16(sport)), netip.AddrPortFrom(dst, uint16(dport)), nil
+}
+
+func (s *ServiceController) setupPortForward(cmd *nftCommands, src, dst netip.AddrPort) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if cur, ok := s.portForwards[src]; ok && cur != dst {
+		return fmt.Errorf("%s is already forwarded to %s", src, cur)
+	}
+
+	if _, ok := s.balanced[src]; ok {
+		return fmt.Errorf("%s is already load balanced", src)
+	}
+
+	chain := s.forwardChain(src, dst)
+	if cmd.knownChains.Contains(chain) {
+		s.portForwards[src] = dst
+		return nil
+	}
+
+	cmd.knownChains.Add(chain)
+	s.portForwards[src] = dst
+
+	ip := dst.Addr()
+
+	cmd.append("add chain inet %s %s", s.table, chain)
+	if ip.Is4() {
+		cmd.append("add rule inet %s %s ip saddr %s jump mark-for-masq", s.table, chain, ip)
+		cmd.append("add rule inet %s %s meta l4proto tcp counter dnat ip to %s", s.table, chain, dst)
+	} else {
+		cmd.append("add rule inet %s %s ip6 saddr %s jump mark-for-masq", s.table, chain, ip)
+		cmd.append("add rule inet %s %s meta l4proto tcp counter dnat ip6 to %s", s.table, chain, dst)
+	}
+	cmd.append("add element inet %s %s { %s . tcp . %d : goto %s }", s.table, forwardMap(ip), src.Addr(), src.Port(), chain)
+	return nil
+}
+
+func (s *ServiceController) removePortForward(cmd *nftCommands, src, dst netip.AddrPort) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	chain := s.forwardChain(src, dst)
+	if !cmd.knownChains.Contains(chain) {
+		return
+	}
+
+	cmd.knownChains.Remove(chain)
+
+	if s.portForwards[src] == dst {
+		delete(s.portForwards, src)
+	}
+
+	// The element goes first, as a chain can't be deleted while the map
+	// still jumps to it.
+	cmd.append("delete element inet %s %s { %s . tcp . %d }", s.table, forwardMap(src.Addr()), src.Addr(), src.Port())
+	cmd.append("flush chain inet %s %s", s.table, chain)
+	cmd.append("delete chain inet %s %s", s.table, chain)
+}
+
+// SetupPortForwarding forwards TCP traffic for src:sport to dst:dport.
+// Setting up a forward that's already in place changes nothing, while
+// forwarding src:sport somewhere else needs the old forward removed first.
+func (s *ServiceController) SetupPortForwarding(ctx context.Context, src netip.Addr, sport int, dst netip.Addr, dport int) error {
+	from, to, err := portForwardAddrs(src, sport, dst, dport)
+	if err != nil {
+		return err
+	}
+
+	cmd := s.cmd.Clone()
+
+	if err := s.setupPortForward(cmd, from, to); err != nil {
+		return err
+	}
+
+	if err := s.apply(ctx, cmd); err != nil {
+		return fmt.Errorf("failed to set up port forward: %w", err)
+	}
+
+	return nil
+}
+
+// RemovePortForwarding removes the forward SetupPortForwarding installed for
+// the same addresses and ports. Removing a forward that isn't there is not an
+// error.
+func (s *ServiceController) RemovePortForwarding(ctx context.Context, src netip.Addr, sport int, dst netip.Addr, dport int) error {
+	from, to, err := portForwardAddrs(src, sport, dst, dport)
+	if err != nil {
+		return err
+	}
+
+	cmd := s.cmd.Clone()
+
+	s.removePortForward(cmd, from, to)
+
+	if err := s.apply(ctx, cmd); err != nil {
+		return fmt.Errorf("failed to remove port forward: %w", err)
+	}
+
+	return nil
+}
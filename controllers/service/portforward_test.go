@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"miren.dev/runtime/pkg/set"
+)
+
+func TestPortForwarding(t *testing.T) {
+	newController := func() *ServiceController {
+		s := &ServiceController{
+			table:          "miren",
+			chainEndpoints: make(map[string][]string),
+			dsrEndpoints:   make(map[netip.Addr]int),
+			portForwards:   make(map[netip.AddrPort]netip.AddrPort),
+			cmd: &nftCommands{
+				knownChains: set.New[string](),
+				knownMaps:   set.New[string](),
+			},
+		}
+		s.initPortForwards(s.cmd)
+		return s
+	}
+
+	var (
+		src = netip.AddrPortFrom(netip.MustParseAddr("10.10.0.5"), 8080)
+		dst = netip.AddrPortFrom(netip.MustParseAddr("10.8.0.20"), 80)
+	)
+
+	setup := func(t *testing.T, s *ServiceController, src, dst netip.AddrPort) []string {
+		t.Helper()
+
+		cmd := s.cmd.Clone()
+		require.NoError(t, s.setupPortForward(cmd, src, dst))
+		return cmd.commands
+	}
+
+	remove := func(s *ServiceController, src, dst netip.AddrPort) []string {
+		cmd := s.cmd.Clone()
+		s.removePortForward(cmd, src, dst)
+		return cmd.commands
+	}
+
+	t.Run("hooks the forward maps into the services chain", func(t *testing.T) {
+		r := require.New(t)
+
+		s := newController()
+
+		r.Contains(s.cmd.commands, "add rule inet miren services ip daddr . meta l4proto . th dport vmap @port_forward_ip4s")
+		r.Contains(s.cmd.commands, "add rule inet miren services ip6 daddr . meta l4proto . th dport vmap @port_forward_ip6s")
+
+		again := s.cmd.Clone()
+		s.initPortForwards(again)
+		r.Empty(again.commands)
+	})
+
+	t.Run("sets up a forward once", func(t *testing.T) {
+		r := require.New(t)
+
+		s := newController()
+		chain := s.forwardChain(src, dst)
+
+		cmds := setup(t, s, src, dst)
+		r.Equal([]string{
+			"add chain inet miren " + chain,
+			"add rule inet miren " + chain + " ip saddr 10.8.0.20 jump mark-for-masq",
+			"add rule inet miren " + chain + " meta l4proto tcp counter dnat ip to 10.8.0.20:80",
+			"add element inet miren port_forward_ip4s { 10.10.0.5 . tcp . 8080 : goto " + chain + " }",
+		}, cmds)
+
+		r.Empty(setup(t, s, src, dst), "re-applying a forward should change nothing")
+	})
+
+	t.Run("removes exactly the forward it set up", func(t *testing.T) {
+		r := require.New(t)
+
+		s := newController()
+
+		other := netip.AddrPortFrom(netip.MustParseAddr("10.10.0.5"), 8443)
+
+		setup(t, s, src, dst)
+		setup(t, s, other, dst)
+
+		chain := s.forwardChain(src, dst)
+
+		r.Equal([]string{
+			"delete element inet miren port_forward_ip4s { 10.10.0.5 . tcp . 8080 }",
+			"flush chain inet miren " + chain,
+			"delete chain inet miren " + chain,
+		}, remove(s, src, dst))
+
+		r.False(s.cmd.knownChains.Contains(chain))
+		r.True(s.cmd.knownChains.Contains(s.forwardChain(other, dst)))
+
+		r.Empty(remove(s, src, dst), "removing twice should change nothing")
+
+		// Once removed, it can be set up again
+		r.NotEmpty(setup(t, s, src, dst))
+	})
+
+	t.Run("won't forward one port to two places", func(t *testing.T) {
+		r := require.New(t)
+
+		s := newController()
+
+		setup(t, s, src, dst)
+
+		elsewhere := netip.AddrPortFrom(netip.MustParseAddr("10.8.0.21"), 80)
+
+		err := s.setupPortForward(s.cmd.Clone(), src, elsewhere)
+		r.ErrorContains(err, "already forwarded")
+
+		// Removing a forward that was never set up leaves the real one alone
+		r.Empty(remove(s, src, elsewhere))
+
+		remove(s, src, dst)
+		r.NotEmpty(setup(t, s, src, elsewhere))
+	})
+
+	t.Run("forwards IPv6", func(t *testing.T) {
+		r := require.New(t)
+
+		s := newController()
+
+		src6 := netip.MustParseAddrPort("[fd00::5]:8080")
+		dst6 := netip.MustParseAddrPort("[fd00:8::20]:80")
+
+		cmds := setup(t, s, src6, dst6)
+		r.Contains(cmds, "add rule inet miren "+s.forwardChain(src6, dst6)+" meta l4proto tcp counter dnat ip6 to [fd00:8::20]:80")
+		r.Contains(cmds, "add element inet miren port_forward_ip6s { fd00::5 . tcp . 8080 : goto "+s.forwardChain(src6, dst6)+" }")
+	})
+
+	t.Run("rejects bad forwards", func(t *testing.T) {
+		r := require.New(t)
+
+		s := newController()
+		ctx := context.Background()
+
+		err := s.SetupPortForwarding(ctx, netip.MustParseAddr("10.10.0.5"), 80, netip.MustParseAddr("fd00::1"), 80)
+		r.ErrorContains(err, "address families differ")
+
+		err = s.SetupPortForwarding(ctx, netip.MustParseAddr("10.10.0.5"), 0, netip.MustParseAddr("10.8.0.1"), 80)
+		r.ErrorContains(err, "invalid port")
+
+		err = s.RemovePortForwarding(ctx, netip.Addr{}, 80, netip.MustParseAddr("10.8.0.1"), 80)
+		r.ErrorContains(err, "invalid port forward address")
+	})
+}
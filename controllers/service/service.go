@@ -38,6 +38,10 @@ type ServiceController struct {
 
 	mu             sync.Mutex
 	chainEndpoints map[string][]string
+	dsrEndpoints   map[netip.Addr]int
+	portForwards   map[netip.AddrPort]netip.AddrPort
+	balanced       map[netip.AddrPort][]Backend
+	unhealthy      set.Set[netip.AddrPort]
 }
 
 func (s *ServiceController) UpdateEndpoints(ctx context.Context, event controller.Event) ([]entity.Attr, error) {
@@ -94,6 +98,7 @@ func (s *ServiceController) UpdateEndpoints(ctx context.Context, event controlle
 
 type nftCommands struct {
 	commands    []string
+	routes      []dsrRoute
 	knownChains set.Set[string]
 	knownMaps   set.Set[string]
 }
@@ -362,11 +367,18 @@ func (s *ServiceController) initNFT(table string, nc *nftCommands) error {
 		nc.append("add rule inet %s nat-postrouting jump masq", s.table)
 	}
 
+	s.initDSR(nc)
+	s.initPortForwards(nc)
+
 	return nil
 }
 
 func (s *ServiceController) Init(ctx context.Context) error {
 	s.chainEndpoints = make(map[string][]string)
+	s.dsrEndpoints = make(map[netip.Addr]int)
+	s.portForwards = make(map[netip.AddrPort]netip.AddrPort)
+	s.balanced = make(map[netip.AddrPort][]Backend)
+	s.unhealthy = set.New[netip.AddrPort]()
 	s.routablePrefixes = []netip.Prefix{s.IPv4Routable}
 
 	s.Log.Info("Initializing service controller")
@@ -390,7 +402,7 @@ func (s *ServiceController) Init(ctx context.Context) error {
 
 func (s *ServiceController) apply(ctx context.Context, cmd *nftCommands) error {
 	if len(cmd.commands) == 0 {
-		return nil
+		return s.applyRoutes(cmd.routes)
 	}
 
 	var buf bytes.Buffer
@@ -410,7 +422,7 @@ func (s *ServiceController) apply(ctx context.Context, cmd *nftCommands) error {
 		return fmt.Errorf("failed to execute nft command: %w (%s)", err, string(out))
 	}
 
-	return nil
+	return s.applyRoutes(cmd.routes)
 }
 
 func (s *ServiceController) Create(ctx context.Context, srv *network_v1alpha.Service, meta *entity.Meta) error {
@@ -425,36 +437,67 @@ func (s *ServiceController) Create(ctx context.Context, srv *network_v1alpha.Ser
 		return fmt.Errorf("failed to list endpoints: %w", err)
 	}
 
-	tp := srv.Port[0]
-
-	var epChains []string
-
-	cmd := s.cmd.Clone()
+	var endpoints []network_v1alpha.Endpoint
 
 	for _, ent := range lr.Values() {
 		var eps network_v1alpha.Endpoints
 		eps.Decode(ent.Entity())
 
-		for _, ep := range eps.Endpoint {
-			destIP, err := netip.ParseAddr(ep.Ip)
-			if err != nil {
-				return fmt.Errorf("failed to parse endpoint IP address: %v", err)
-			}
+		endpoints = append(endpoints, eps.Endpoint...)
+	}
 
-			target := tp.TargetPort
-			if target == 0 {
-				target = tp.Port
-			}
+	cmd := s.cmd.Clone()
 
-			ep, err := s.setupEndpointChain(cmd, destIP, uint16(target))
-			if err != nil {
-				return fmt.Errorf("failed to setup endpoint chain: %w", err)
-			}
+	if err := s.generate(cmd, srv, endpoints); err != nil {
+		return err
+	}
+
+	if err := s.apply(ctx, cmd); err != nil {
+		return fmt.Errorf("failed to apply nftables changes: %w", err)
+	}
 
-			epChains = append(epChains, ep)
+	return nil
+}
+
+// generate adds the commands forwarding srv's traffic to endpoints to cmd,
+// without applying them.
+func (s *ServiceController) generate(cmd *nftCommands, srv *network_v1alpha.Service, endpoints []network_v1alpha.Endpoint) error {
+	dsr := srv.Forwarding == network_v1alpha.DSR
+
+	if dsr {
+		if err := checkDSRPorts(srv.Port); err != nil {
+			return err
 		}
 	}
 
+	tp := srv.Port[0]
+
+	var epChains []string
+
+	for _, ep := range endpoints {
+		destIP, err := netip.ParseAddr(ep.Ip)
+		if err != nil {
+			return fmt.Errorf("failed to parse endpoint IP address: %v", err)
+		}
+
+		if dsr {
+			epChains = append(epChains, s.setupDSREndpointChain(cmd, destIP))
+			continue
+		}
+
+		target := tp.TargetPort
+		if target == 0 {
+			target = tp.Port
+		}
+
+		ep, err := s.setupEndpointChain(cmd, destIP, uint16(target))
+		if err != nil {
+			return fmt.Errorf("failed to setup endpoint chain: %w", err)
+		}
+
+		epChains = append(epChains, ep)
+	}
+
 	var firstIp netip.Addr
 
 	for _, sip := range srv.Ip {
@@ -468,6 +511,14 @@ func (s *ServiceController) Create(ctx context.Context, srv *network_v1alpha.Ser
 		}
 
 		for _, tp := range srv.Port {
+			if dsr {
+				s.createDSRServiceChain(cmd, ip, int(tp.Port))
+				s.updateDSRServiceEndpoints(cmd, ip, int(tp.Port), epChains)
+				continue
+			}
+
+			s.removeDSRServiceChain(cmd, ip, int(tp.Port))
+
 			if err := s.createServiceChain(cmd, ip, int(tp.Port)); err != nil {
 				return fmt.Errorf("failed to create service chain: %w", err)
 			}
@@ -486,10 +537,6 @@ func (s *ServiceController) Create(ctx context.Context, srv *network_v1alpha.Ser
 		}
 	}
 
-	if err := s.apply(ctx, cmd); err != nil {
-		return fmt.Errorf("failed to apply nftables changes: %w", err)
-	}
-
 	return nil
 }
 
@@ -1,8 +1,11 @@
 package main
 
 import (
+	"encoding/json"
+	"encoding/xml"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"sort"
 	"strings"
@@ -11,10 +14,10 @@ import (
 )
 
 type PackageCoverage struct {
-	Package         string
-	CoveredStmts    int64
-	TotalStmts      int64
-	CoveragePercent float64
+	Package         string  `json:"package"`
+	CoveredStmts    int64   `json:"covered"`
+	TotalStmts      int64   `json:"total"`
+	CoveragePercent float64 `json:"percent"`
 }
 
 func main() {
@@ -22,6 +25,7 @@ func main() {
 		coverFile   = flag.String("coverage", "coverage.out", "Path to coverage profile")
 		minCoverage = flag.Float64("min", 0, "Minimum coverage threshold (0-100)")
 		sortBy      = flag.String("sort", "name", "Sort by: name, coverage, or statements")
+		format      = flag.String("format", "table", "Output format: table, json, or junit")
 	)
 	flag.Parse()
 
@@ -31,6 +35,32 @@ func main() {
 		os.Exit(1)
 	}
 
+	packages := packageCoverage(profiles)
+	sortPackages(packages, *sortBy)
+
+	switch *format {
+	case "json":
+		err = writeJSON(os.Stdout, packages)
+	case "junit":
+		err = writeJUnit(os.Stdout, packages, *minCoverage)
+	case "table":
+		writeTable(os.Stdout, packages, *minCoverage)
+	default:
+		err = fmt.Errorf("unknown format %q", *format)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing coverage: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *minCoverage > 0 && belowThreshold(packages, *minCoverage) > 0 {
+		os.Exit(1)
+	}
+}
+
+// packageCoverage totals the statements covered in each package.
+func packageCoverage(profiles []*cover.Profile) []PackageCoverage {
 	// Map of package -> coverage stats
 	packageStats := make(map[string]*PackageCoverage)
 
@@ -64,8 +94,11 @@ func main() {
 		packages = append(packages, *stats)
 	}
 
-	// Sort packages
-	switch *sortBy {
+	return packages
+}
+
+func sortPackages(packages []PackageCoverage, sortBy string) {
+	switch sortBy {
 	case "coverage":
 		sort.Slice(packages, func(i, j int) bool {
 			return packages[i].CoveragePercent < packages[j].CoveragePercent
@@ -79,25 +112,38 @@ func main() {
 			return packages[i].Package < packages[j].Package
 		})
 	}
+}
+
+func isBelow(pkg PackageCoverage, minCoverage float64) bool {
+	return minCoverage > 0 && pkg.CoveragePercent < minCoverage
+}
+
+func belowThreshold(packages []PackageCoverage, minCoverage float64) int {
+	below := 0
+	for _, pkg := range packages {
+		if isBelow(pkg, minCoverage) {
+			below++
+		}
+	}
+	return below
+}
 
-	// Print results
-	fmt.Println("Coverage by Package")
-	fmt.Println("===================")
-	fmt.Println()
-	fmt.Printf("%-60s %10s %10s %10s\n", "Package", "Coverage", "Covered", "Total")
-	fmt.Printf("%-60s %10s %10s %10s\n", strings.Repeat("-", 60), "--------", "-------", "-----")
+func writeTable(w io.Writer, packages []PackageCoverage, minCoverage float64) {
+	fmt.Fprintln(w, "Coverage by Package")
+	fmt.Fprintln(w, "===================")
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "%-60s %10s %10s %10s\n", "Package", "Coverage", "Covered", "Total")
+	fmt.Fprintf(w, "%-60s %10s %10s %10s\n", strings.Repeat("-", 60), "--------", "-------", "-----")
 
 	var totalCovered, totalStmts int64
-	belowThreshold := 0
 
 	for _, pkg := range packages {
 		status := ""
-		if *minCoverage > 0 && pkg.CoveragePercent < *minCoverage {
+		if isBelow(pkg, minCoverage) {
 			status = " ⚠️"
-			belowThreshold++
 		}
 
-		fmt.Printf("%-60s %9.1f%% %10d %10d%s\n",
+		fmt.Fprintf(w, "%-60s %9.1f%% %10d %10d%s\n",
 			pkg.Package,
 			pkg.CoveragePercent,
 			pkg.CoveredStmts,
@@ -109,28 +155,97 @@ func main() {
 		totalStmts += pkg.TotalStmts
 	}
 
-	fmt.Printf("%-60s %10s %10s %10s\n", strings.Repeat("-", 60), "--------", "-------", "-----")
+	fmt.Fprintf(w, "%-60s %10s %10s %10s\n", strings.Repeat("-", 60), "--------", "-------", "-----")
 
 	overallPercent := float64(0)
 	if totalStmts > 0 {
 		overallPercent = float64(totalCovered) / float64(totalStmts) * 100
 	}
 
-	fmt.Printf("%-60s %9.1f%% %10d %10d\n",
+	fmt.Fprintf(w, "%-60s %9.1f%% %10d %10d\n",
 		"TOTAL",
 		overallPercent,
 		totalCovered,
 		totalStmts,
 	)
 
-	fmt.Println()
-	fmt.Printf("Packages: %d\n", len(packages))
-	if *minCoverage > 0 {
-		fmt.Printf("Below threshold (%.1f%%): %d\n", *minCoverage, belowThreshold)
-		if belowThreshold > 0 {
-			os.Exit(1)
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "Packages: %d\n", len(packages))
+	if minCoverage > 0 {
+		fmt.Fprintf(w, "Below threshold (%.1f%%): %d\n", minCoverage, belowThreshold(packages, minCoverage))
+	}
+}
+
+func writeJSON(w io.Writer, packages []PackageCoverage) error {
+	if packages == nil {
+		packages = []PackageCoverage{}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(packages)
+}
+
+type junitSuites struct {
+	XMLName xml.Name     `xml:"testsuites"`
+	Suites  []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+}
+
+// writeJUnit writes a test case per package, failing those with coverage
+// below minCoverage so CI can report on each package.
+func writeJUnit(w io.Writer, packages []PackageCoverage, minCoverage float64) error {
+	suite := junitSuite{
+		Name:  "coverage",
+		Tests: len(packages),
+	}
+
+	for _, pkg := range packages {
+		tc := junitCase{
+			ClassName: "coverage",
+			Name:      pkg.Package,
+			SystemOut: fmt.Sprintf("%.1f%% of %d statements covered", pkg.CoveragePercent, pkg.TotalStmts),
+		}
+
+		if isBelow(pkg, minCoverage) {
+			tc.Failure = &junitFailure{
+				Message: fmt.Sprintf("coverage %.1f%% is below threshold %.1f%%", pkg.CoveragePercent, minCoverage),
+			}
+			suite.Failures++
 		}
+
+		suite.Cases = append(suite.Cases, tc)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
 	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(junitSuites{Suites: []junitSuite{suite}}); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
 }
 
 // extractPackage extracts the package path from a file path
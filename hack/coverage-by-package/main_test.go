@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/tools/cover"
+)
+
+const sampleCoverage = `mode: set
+miren.dev/runtime/pkg/entity/store.go:10.2,12.3 3 1
+miren.dev/runtime/pkg/entity/store.go:14.2,16.3 1 0
+miren.dev/runtime/pkg/entity/id.go:5.2,7.3 4 1
+miren.dev/runtime/pkg/rpc/conn.go:20.2,25.3 2 0
+miren.dev/runtime/pkg/rpc/conn.go:30.2,31.3 2 1
+`
+
+func TestCoverageFormats(t *testing.T) {
+	parse := func(t *testing.T) []PackageCoverage {
+		path := filepath.Join(t.TempDir(), "coverage.out")
+		require.NoError(t, os.WriteFile(path, []byte(sampleCoverage), 0644))
+
+		profiles, err := cover.ParseProfiles(path)
+		require.NoError(t, err)
+
+		packages := packageCoverage(profiles)
+		sortPackages(packages, "name")
+		return packages
+	}
+
+	t.Run("writes json", func(t *testing.T) {
+		r := require.New(t)
+
+		var buf bytes.Buffer
+		r.NoError(writeJSON(&buf, parse(t)))
+
+		var packages []map[string]any
+		r.NoError(json.Unmarshal(buf.Bytes(), &packages))
+
+		r.Equal([]map[string]any{
+			{"package": "miren.dev/runtime/pkg/entity", "covered": 7.0, "total": 8.0, "percent": 87.5},
+			{"package": "miren.dev/runtime/pkg/rpc", "covered": 2.0, "total": 4.0, "percent": 50.0},
+		}, packages)
+	})
+
+	t.Run("writes an empty json list", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, writeJSON(&buf, nil))
+		require.JSONEq(t, "[]", buf.String())
+	})
+
+	t.Run("writes junit, failing packages below the threshold", func(t *testing.T) {
+		r := require.New(t)
+
+		var buf bytes.Buffer
+		r.NoError(writeJUnit(&buf, parse(t), 60))
+
+		var report junitSuites
+		r.NoError(xml.Unmarshal(buf.Bytes(), &report))
+
+		r.Len(report.Suites, 1)
+
+		suite := report.Suites[0]
+		r.Equal(2, suite.Tests)
+		r.Equal(1, suite.Failures)
+		r.Len(suite.Cases, 2)
+
+		r.Equal("miren.dev/runtime/pkg/entity", suite.Cases[0].Name)
+		r.Nil(suite.Cases[0].Failure)
+
+		r.Equal("miren.dev/runtime/pkg/rpc", suite.Cases[1].Name)
+		r.NotNil(suite.Cases[1].Failure)
+		r.Equal("coverage 50.0% is below threshold 60.0%", suite.Cases[1].Failure.Message)
+	})
+
+	t.Run("passes every package without a threshold", func(t *testing.T) {
+		r := require.New(t)
+
+		var buf bytes.Buffer
+		r.NoError(writeJUnit(&buf, parse(t), 0))
+
+		var report junitSuites
+		r.NoError(xml.Unmarshal(buf.Bytes(), &report))
+		r.Equal(0, report.Suites[0].Failures)
+	})
+}
@@ -29,7 +29,7 @@ type FileCoverage struct {
 func main() {
 	var (
 		coverFile   = flag.String("coverage", "coverage.out", "Path to coverage profile")
-		baseBranch  = flag.String("base", "main", "Base branch to compare against")
+		baseBranch  = flag.String("base", "main", "Base branch to compare against (comma-separated for several)")
 		minCoverage = flag.Float64("min", 0, "Minimum coverage threshold (0-100)")
 		verbose     = flag.Bool("v", false, "Verbose output (show all files)")
 		summary     = flag.Bool("summary", false, "Show only summary (no per-file breakdown)")
@@ -44,7 +44,7 @@ func main() {
 	}
 
 	// Get changed line ranges from git diff
-	changedRanges, err := getChangedLineRanges(*baseBranch)
+	changedRanges, err := getChangedLineRanges(strings.Split(*baseBranch, ","))
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error getting changed lines: %v\n", err)
 		os.Exit(1)
@@ -172,14 +172,47 @@ func main() {
 	}
 }
 
-func getChangedLineRanges(baseBranch string) ([]ChangedLineRange, error) {
-	// Run: git diff --unified=0 <baseBranch> HEAD -- '*.go'
-	cmd := exec.Command("git", "diff", "--unified=0", baseBranch, "HEAD", "--", "*.go")
-	output, err := cmd.CombinedOutput()
+// getChangedLineRanges returns the lines changed since each of the base
+// branches, so the union of what changed against all of them.
+func getChangedLineRanges(baseBranches []string) ([]ChangedLineRange, error) {
+	var ranges []ChangedLineRange
+
+	for _, baseBranch := range baseBranches {
+		baseBranch = strings.TrimSpace(baseBranch)
+		if baseBranch == "" {
+			continue
+		}
+
+		base := diffBase(baseBranch)
+
+		// Run: git diff --unified=0 <base> HEAD -- '*.go'
+		cmd := exec.Command("git", "diff", "--unified=0", base, "HEAD", "--", "*.go")
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			return nil, fmt.Errorf("git diff failed: %w\nOutput: %s", err, string(output))
+		}
+
+		ranges = append(ranges, parseDiff(string(output))...)
+	}
+
+	return ranges, nil
+}
+
+// diffBase returns the commit HEAD branched from baseBranch at, so lines
+// changed on the base since then aren't counted as this branch's. If there's
+// no merge base to be found, as in a shallow clone, it falls back to
+// diffing against baseBranch directly.
+func diffBase(baseBranch string) string {
+	output, err := exec.Command("git", "merge-base", baseBranch, "HEAD").Output()
 	if err != nil {
-		return nil, fmt.Errorf("git diff failed: %w\nOutput: %s", err, string(output))
+		fmt.Fprintf(os.Stderr, "Warning: no merge base with %s (%v), diffing against it directly\n", baseBranch, err)
+		return baseBranch
 	}
 
+	return strings.TrimSpace(string(output))
+}
+
+func parseDiff(output string) []ChangedLineRange {
 	var ranges []ChangedLineRange
 	var currentFile string
 
@@ -187,7 +220,7 @@ func getChangedLineRanges(baseBranch string) ([]ChangedLineRange, error) {
 	diffFilePattern := regexp.MustCompile(`^diff --git a/(.*) b/(.*)$`)
 	chunkPattern := regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,(\d+))? @@`)
 
-	lines := strings.Split(string(output), "\n")
+	lines := strings.Split(output, "\n")
 	for _, line := range lines {
 		// Track current file (use the "b/" path - new version)
 		if matches := diffFilePattern.FindStringSubmatch(line); matches != nil {
@@ -218,7 +251,7 @@ func getChangedLineRanges(baseBranch string) ([]ChangedLineRange, error) {
 		}
 	}
 
-	return ranges, nil
+	return ranges
 }
 
 func normalizeFilePath(coveragePath string) string {
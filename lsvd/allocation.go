@@ -0,0 +1,344 @@
+package lsvd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+	"sync"
+	"syscall"
+
+	"github.com/oklog/ulid/v2"
+)
+
+// DeviceInfo describes a device as seen by an AllocationPolicy.
+type DeviceInfo struct {
+	Name string
+
+	// Free is the number of bytes available on the device, or -1 if the
+	// device can't report it.
+	Free int64
+}
+
+// AllocationPolicy decides which device a new segment is placed on.
+type AllocationPolicy interface {
+	// Place returns the index into devices of the device the segment, of
+	// size bytes, is written to.
+	Place(seg SegmentId, size int64, devices []DeviceInfo) int
+}
+
+// AllocationPolicyFunc adapts a function to an AllocationPolicy, for custom
+// placement strategies.
+type AllocationPolicyFunc func(seg SegmentId, size int64, devices []DeviceInfo) int
+
+func (f AllocationPolicyFunc) Place(seg SegmentId, size int64, devices []DeviceInfo) int {
+	return f(seg, size, devices)
+}
+
+// FirstDevicePolicy places every segment on the first device, which is how
+// segments are placed with a single device. It is the default.
+type FirstDevicePolicy struct{}
+
+func (FirstDevicePolicy) Place(seg SegmentId, size int64, devices []DeviceInfo) int {
+	return 0
+}
+
+// RoundRobinPolicy places segments on each device in turn.
+type RoundRobinPolicy struct {
+	mu   sync.Mutex
+	next int
+}
+
+func (p *RoundRobinPolicy) Place(seg SegmentId, size int64, devices []DeviceInfo) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	idx := p.next % len(devices)
+	p.next = idx + 1
+
+	return idx
+}
+
+// FreeSpacePolicy places segments on the device with the most free space.
+// Devices that can't report their free space are only used if none can.
+type FreeSpacePolicy struct{}
+
+func (FreeSpacePolicy) Place(seg SegmentId, size int64, devices []DeviceInfo) int {
+	best := 0
+
+	for i, dev := range devices {
+		if dev.Free > devices[best].Free {
+			best = i
+		}
+	}
+
+	return best
+}
+
+// ParseAllocationPolicy returns the policy with the given name, one of
+// "first", "round-robin" or "free-space". An empty name is the default.
+func ParseAllocationPolicy(name string) (AllocationPolicy, error) {
+	switch name {
+	case "", "first":
+		return FirstDevicePolicy{}, nil
+	case "round-robin":
+		return &RoundRobinPolicy{}, nil
+	case "free-space":
+		return FreeSpacePolicy{}, nil
+	default:
+		return nil, fmt.Errorf("unknown allocation policy: %s", name)
+	}
+}
+
+// FreeSpaceReporter is implemented by SegmentAccess implementations that can
+// report how much space is available for new segments.
+type FreeSpaceReporter interface {
+	FreeSpace(ctx context.Context) (int64, error)
+}
+
+var _ FreeSpaceReporter = (*LocalFileAccess)(nil)
+
+func (l *LocalFileAccess) FreeSpace(ctx context.Context) (int64, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(l.Dir, &st); err != nil {
+		return 0, err
+	}
+
+	return int64(st.Bavail) * int64(st.Bsize), nil
+}
+
+// Device is a segment store that segments can be placed on.
+type Device struct {
+	Name   string
+	Access SegmentAccess
+}
+
+// MultiDevice returns a SegmentAccess that spreads the segments of its
+// volumes across devices, placing each new segment on the device chosen by
+// policy. Volume metadata is kept on the first device. A nil policy is
+// FirstDevicePolicy.
+func MultiDevice(log *slog.Logger, policy AllocationPolicy, devices ...Device) (SegmentAccess, error) {
+	if len(devices) == 0 {
+		return nil, fmt.Errorf("no devices to place segments on")
+	}
+
+	if policy == nil {
+		policy = FirstDevicePolicy{}
+	}
+
+	return &multiDevice{
+		log:     log.With("module", "lsvd-devices"),
+		policy:  policy,
+		devices: devices,
+	}, nil
+}
+
+type multiDevice struct {
+	log     *slog.Logger
+	policy  AllocationPolicy
+	devices []Device
+}
+
+func (m *multiDevice) InitContainer(ctx context.Context) error {
+	for _, dev := range m.devices {
+		if err := dev.Access.InitContainer(ctx); err != nil {
+			return fmt.Errorf("device %s: %w", dev.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (m *multiDevice) InitVolume(ctx context.Context, vol *VolumeInfo) error {
+	for _, dev := range m.devices {
+		if err := dev.Access.InitVolume(ctx, vol); err != nil {
+			return fmt.Errorf("device %s: %w", dev.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (m *multiDevice) ListVolumes(ctx context.Context) ([]string, error) {
+	return m.devices[0].Access.ListVolumes(ctx)
+}
+
+func (m *multiDevice) RemoveSegment(ctx context.Context, seg SegmentId) error {
+	for _, dev := range m.devices {
+		if err := dev.Access.RemoveSegment(ctx, seg); err != nil {
+			return fmt.Errorf("device %s: %w", dev.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (m *multiDevice) GetVolumeInfo(ctx context.Context, vol string) (*VolumeInfo, error) {
+	return m.devices[0].Access.GetVolumeInfo(ctx, vol)
+}
+
+func (m *multiDevice) OpenVolume(ctx context.Context, vol string) (Volume, error) {
+	mv := &multiVolume{
+		m:       m,
+		placed:  make(map[SegmentId]int),
+		volumes: make([]Volume, len(m.devices)),
+	}
+
+	for i, dev := range m.devices {
+		v, err := dev.Access.OpenVolume(ctx, vol)
+		if err != nil {
+			return nil, fmt.Errorf("device %s: %w", dev.Name, err)
+		}
+
+		mv.volumes[i] = v
+	}
+
+	return mv, nil
+}
+
+type multiVolume struct {
+	m       *multiDevice
+	volumes []Volume
+
+	mu     sync.Mutex
+	placed map[SegmentId]int
+}
+
+func (v *multiVolume) Info(ctx context.Context) (*VolumeInfo, error) {
+	return v.volumes[0].Info(ctx)
+}
+
+// ListSegments returns the segments of every device in the order they were
+// created, recording which device holds each.
+func (v *multiVolume) ListSegments(ctx context.Context) ([]SegmentId, error) {
+	var segments []SegmentId
+
+	placed := make(map[SegmentId]int)
+
+	for i, vol := range v.volumes {
+		segs, err := vol.ListSegments(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("device %s: %w", v.m.devices[i].Name, err)
+		}
+
+		for _, seg := range segs {
+			placed[seg] = i
+		}
+
+		segments = append(segments, segs...)
+	}
+
+	slices.SortFunc(segments, func(a, b SegmentId) int {
+		return ulid.ULID(a).Compare(ulid.ULID(b))
+	})
+
+	v.mu.Lock()
+	maps.Copy(v.placed, placed)
+	v.mu.Unlock()
+
+	return deduplicateSorted(segments), nil
+}
+
+// locate returns the index of the device holding seg.
+func (v *multiVolume) locate(ctx context.Context, seg SegmentId) (int, error) {
+	v.mu.Lock()
+	idx, ok := v.placed[seg]
+	v.mu.Unlock()
+
+	if ok {
+		return idx, nil
+	}
+
+	if _, err := v.ListSegments(ctx); err != nil {
+		return 0, err
+	}
+
+	v.mu.Lock()
+	idx, ok = v.placed[seg]
+	v.mu.Unlock()
+
+	if !ok {
+		return 0, fmt.Errorf("segment %s: %w", seg, os.ErrNotExist)
+	}
+
+	return idx, nil
+}
+
+func (v *multiVolume) OpenSegment(ctx context.Context, seg SegmentId) (SegmentReader, error) {
+	idx, err := v.locate(ctx, seg)
+	if err != nil {
+		return nil, err
+	}
+
+	return v.volumes[idx].OpenSegment(ctx, seg)
+}
+
+func (v *multiVolume) deviceInfo(ctx context.Context) []DeviceInfo {
+	infos := make([]DeviceInfo, len(v.m.devices))
+
+	for i, dev := range v.m.devices {
+		infos[i] = DeviceInfo{Name: dev.Name, Free: -1}
+
+		fs, ok := dev.Access.(FreeSpaceReporter)
+		if !ok {
+			continue
+		}
+
+		free, err := fs.FreeSpace(ctx)
+		if err != nil {
+			v.m.log.Warn("unable to read free space of device", "device", dev.Name, "error", err)
+			continue
+		}
+
+		infos[i].Free = free
+	}
+
+	return infos
+}
+
+func (v *multiVolume) NewSegment(ctx context.Context, seg SegmentId, layout *SegmentLayout, data *os.File) error {
+	fi, err := data.Stat()
+	if err != nil {
+		return err
+	}
+
+	idx := v.m.policy.Place(seg, fi.Size(), v.deviceInfo(ctx))
+	if idx < 0 || idx >= len(v.volumes) {
+		return fmt.Errorf("allocation policy chose invalid device %d", idx)
+	}
+
+	v.m.log.Debug("placing segment", "segment", seg, "device", v.m.devices[idx].Name)
+
+	if err := v.volumes[idx].NewSegment(ctx, seg, layout, data); err != nil {
+		return fmt.Errorf("device %s: %w", v.m.devices[idx].Name, err)
+	}
+
+	v.mu.Lock()
+	v.placed[seg] = idx
+	v.mu.Unlock()
+
+	return nil
+}
+
+func (v *multiVolume) RemoveSegment(ctx context.Context, seg SegmentId) error {
+	idx, err := v.locate(ctx, seg)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	if err := v.volumes[idx].RemoveSegment(ctx, seg); err != nil {
+		return err
+	}
+
+	v.mu.Lock()
+	delete(v.placed, seg)
+	v.mu.Unlock()
+
+	return nil
+}
@@ -0,0 +1,201 @@
+package lsvd
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDevice is a LocalFileAccess on a device of a fixed capacity, which
+// reports the space its segments leave free.
+type fakeDevice struct {
+	*LocalFileAccess
+	capacity int64
+}
+
+func (f *fakeDevice) FreeSpace(ctx context.Context) (int64, error) {
+	entries, err := os.ReadDir(filepath.Join(f.Dir, "segments"))
+	if err != nil {
+		return 0, err
+	}
+
+	free := f.capacity
+
+	for _, ent := range entries {
+		fi, err := ent.Info()
+		if err != nil {
+			return 0, err
+		}
+
+		free -= fi.Size()
+	}
+
+	return free, nil
+}
+
+func newFakeDevices(t *testing.T, capacities ...int64) []Device {
+	var devices []Device
+
+	for i, capacity := range capacities {
+		devices = append(devices, Device{
+			Name: string(rune('a' + i)),
+			Access: &fakeDevice{
+				LocalFileAccess: &LocalFileAccess{Dir: t.TempDir(), Log: slog.Default()},
+				capacity:        capacity,
+			},
+		})
+	}
+
+	return devices
+}
+
+// placeSegments writes count segments of size bytes to a volume spread over
+// devices by policy, and returns the index of the device each was placed on.
+func placeSegments(t *testing.T, policy AllocationPolicy, devices []Device, count int, size int) ([]SegmentId, []int) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	sa, err := MultiDevice(slog.Default(), policy, devices...)
+	r.NoError(err)
+
+	r.NoError(sa.InitContainer(ctx))
+	r.NoError(sa.InitVolume(ctx, &VolumeInfo{Name: "test"}))
+
+	vol, err := sa.OpenVolume(ctx, "test")
+	r.NoError(err)
+
+	var (
+		segs   []SegmentId
+		placed []int
+	)
+
+	for i := range count {
+		seg := SegmentId(ulid.MustNew(uint64(1000+i), testEntropy))
+
+		data, err := os.CreateTemp(t.TempDir(), "segment")
+		r.NoError(err)
+
+		_, err = data.Write(bytes.Repeat([]byte{byte(i)}, size))
+		r.NoError(err)
+
+		_, err = data.Seek(0, 0)
+		r.NoError(err)
+
+		r.NoError(vol.NewSegment(ctx, seg, &SegmentLayout{}, data))
+		data.Close()
+
+		segs = append(segs, seg)
+
+		for idx, dev := range devices {
+			dvol, err := dev.Access.OpenVolume(ctx, "test")
+			r.NoError(err)
+
+			dsegs, err := dvol.ListSegments(ctx)
+			r.NoError(err)
+
+			if len(dsegs) > 0 && dsegs[len(dsegs)-1] == seg {
+				placed = append(placed, idx)
+			}
+		}
+	}
+
+	r.Len(placed, count, "each segment should be on exactly one device")
+
+	all, err := vol.ListSegments(ctx)
+	r.NoError(err)
+	r.Equal(segs, all, "the volume should list segments from every device in order")
+
+	for i, seg := range segs {
+		sr, err := vol.OpenSegment(ctx, seg)
+		r.NoError(err)
+
+		buf := make([]byte, 1)
+		_, err = sr.ReadAt(buf, 0)
+		r.NoError(err)
+		r.Equal(byte(i), buf[0])
+
+		sr.Close()
+	}
+
+	return segs, placed
+}
+
+func TestAllocationPolicy(t *testing.T) {
+	t.Run("the default places every segment on the first device", func(t *testing.T) {
+		devices := newFakeDevices(t, 1<<20, 1<<20)
+
+		_, placed := placeSegments(t, nil, devices, 4, 1024)
+		require.Equal(t, []int{0, 0, 0, 0}, placed)
+	})
+
+	t.Run("round-robin cycles through the devices", func(t *testing.T) {
+		devices := newFakeDevices(t, 1<<20, 1<<20, 1<<20)
+
+		_, placed := placeSegments(t, &RoundRobinPolicy{}, devices, 7, 1024)
+		require.Equal(t, []int{0, 1, 2, 0, 1, 2, 0}, placed)
+	})
+
+	t.Run("free-space picks the device with the most room", func(t *testing.T) {
+		const k = 1024
+
+		devices := newFakeDevices(t, 100*k, 300*k, 200*k)
+
+		_, placed := placeSegments(t, FreeSpacePolicy{}, devices, 7, 60*k)
+		require.Equal(t, []int{1, 1, 2, 1, 2, 1, 0}, placed)
+	})
+
+	t.Run("free-space prefers devices that report their space", func(t *testing.T) {
+		idx := FreeSpacePolicy{}.Place(SegmentId{}, 1, []DeviceInfo{
+			{Name: "a", Free: -1},
+			{Name: "b", Free: 10},
+		})
+		require.Equal(t, 1, idx)
+	})
+
+	t.Run("removing a segment removes it from its device", func(t *testing.T) {
+		r := require.New(t)
+		ctx := context.Background()
+
+		devices := newFakeDevices(t, 1<<20, 1<<20)
+
+		policy := &RoundRobinPolicy{}
+		segs, _ := placeSegments(t, policy, devices, 4, 1024)
+
+		sa, err := MultiDevice(slog.Default(), policy, devices...)
+		r.NoError(err)
+
+		vol, err := sa.OpenVolume(ctx, "test")
+		r.NoError(err)
+
+		r.NoError(vol.RemoveSegment(ctx, segs[1]))
+
+		all, err := vol.ListSegments(ctx)
+		r.NoError(err)
+		r.Equal([]SegmentId{segs[0], segs[2], segs[3]}, all)
+	})
+
+	t.Run("policies are parsed by name", func(t *testing.T) {
+		r := require.New(t)
+
+		p, err := ParseAllocationPolicy("")
+		r.NoError(err)
+		r.IsType(FirstDevicePolicy{}, p)
+
+		p, err = ParseAllocationPolicy("round-robin")
+		r.NoError(err)
+		r.IsType(&RoundRobinPolicy{}, p)
+
+		p, err = ParseAllocationPolicy("free-space")
+		r.NoError(err)
+		r.IsType(FreeSpacePolicy{}, p)
+
+		_, err = ParseAllocationPolicy("random")
+		r.Error(err)
+	})
+}
@@ -0,0 +1,610 @@
+package lsvd
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/pkg/errors"
+	"miren.dev/runtime/pkg/units"
+)
+
+// BackupStage names a step of a Backup or Restore.
+type BackupStage string
+
+const (
+	// BackupCopy copies segments to the target. Progress counts segments.
+	BackupCopy BackupStage = "copy"
+
+	// BackupDelta rewrites the ranges the copied segments don't reproduce.
+	// Progress counts blocks.
+	BackupDelta BackupStage = "delta"
+
+	// BackupVerify reads the backup back and compares it to the snapshot.
+	// Progress counts blocks.
+	BackupVerify BackupStage = "verify"
+)
+
+// BackupProgress describes how far a Backup or Restore has gotten through
+// its current stage.
+type BackupProgress struct {
+	Stage BackupStage
+	Done  uint64
+	Total uint64
+}
+
+// ErrBackupMismatch is returned when a backup doesn't read back the same as
+// the snapshot it was taken from, or a copied segment differs from its source.
+var ErrBackupMismatch = errors.New("backup does not match its source")
+
+// ErrVolumeExists is returned when a backup or restore would overwrite an
+// existing volume.
+var ErrVolumeExists = errors.New("volume already exists")
+
+type BackupOptions struct {
+	// Volume names the volume created in the target to hold the backup.
+	// It must not already exist.
+	Volume string
+
+	// Path is a scratch directory for staging segments and attaching to
+	// the backup. A temporary directory is used when empty.
+	Path string
+
+	// SkipVerify skips reading the backup back once it is written.
+	SkipVerify bool
+
+	// Progress, when set, is called as each stage advances.
+	Progress func(BackupProgress)
+}
+
+// BackupResult summarizes a completed Backup.
+type BackupResult struct {
+	Volume string
+	Size   int64
+
+	// Segments are the snapshot's segments copied into the backup, and
+	// Bytes their total size.
+	Segments []SegmentId
+	Bytes    int64
+
+	// DeltaBlocks is how much was rewritten on top of the copied segments.
+	DeltaBlocks uint64
+
+	// VerifiedBlocks is how much was compared after writing, zero if
+	// verification was skipped.
+	VerifiedBlocks uint64
+}
+
+// Backup takes a snapshot of the disk and writes a consistent copy of it to
+// target, while the disk stays attached and in use. See Snapshot.Backup.
+func (d *Disk) Backup(ctx context.Context, target SegmentAccess, opts BackupOptions) (*BackupResult, error) {
+	snap, err := d.Snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer snap.Release()
+
+	return snap.Backup(ctx, target, opts)
+}
+
+// Backup writes the snapshot's contents to a new volume in target. The
+// segments the snapshot reads from are copied as they are, then the backup
+// is attached and any range it doesn't read the same as the snapshot is
+// rewritten from it. That covers ranges zeroed by segments the snapshot
+// didn't pin and data from lower layers, which the backup doesn't have.
+//
+// The backup is an ordinary volume, so it can be attached directly or copied
+// elsewhere with Restore. Target should be separate storage from the disk's
+// own, as segments keep their ids when copied.
+func (s *Snapshot) Backup(ctx context.Context, target SegmentAccess, opts BackupOptions) (*BackupResult, error) {
+	if opts.Volume == "" {
+		return nil, fmt.Errorf("backup volume name must not be empty")
+	}
+
+	s.mu.Lock()
+	released := s.released
+	s.mu.Unlock()
+
+	if released {
+		return nil, ErrSnapshotReleased
+	}
+
+	d := s.d
+	log := d.log.With("backup", opts.Volume)
+
+	scratch := opts.Path
+	if scratch == "" {
+		dir, err := os.MkdirTemp("", "lsvd-backup")
+		if err != nil {
+			return nil, err
+		}
+
+		defer os.RemoveAll(dir)
+
+		scratch = dir
+	} else if err := os.MkdirAll(scratch, 0755); err != nil {
+		return nil, err
+	}
+
+	if err := target.InitContainer(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := checkNoVolume(ctx, target, opts.Volume); err != nil {
+		return nil, err
+	}
+
+	info := &VolumeInfo{
+		Name: opts.Volume,
+		Size: units.Bytes(d.size),
+		Metadata: map[string]any{
+			"backup_of":   d.volName,
+			"backup_time": time.Now().UTC().Format(time.RFC3339),
+		},
+	}
+
+	if err := info.Normalize(); err != nil {
+		return nil, err
+	}
+
+	if err := target.InitVolume(ctx, info); err != nil {
+		return nil, errors.Wrapf(err, "creating backup volume")
+	}
+
+	vol, err := target.OpenVolume(ctx, opts.Volume)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &BackupResult{
+		Volume:   opts.Volume,
+		Size:     d.size,
+		Segments: slices.Clone(s.segments),
+	}
+
+	// Segments are copied in creation order, so that rebuilding the backup
+	// resolves overlapping writes mostly as the disk did. The delta covers
+	// any it resolves differently.
+	slices.SortFunc(res.Segments, func(a, b SegmentId) int {
+		return bytes.Compare(a[:], b[:])
+	})
+
+	log.Info("backing up snapshot", "volume", d.volName, "segments", len(res.Segments))
+
+	for i, seg := range res.Segments {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		n, err := copySegment(ctx, d.volume, vol, seg, scratch)
+		if err != nil {
+			return nil, errors.Wrapf(err, "copying segment %s", seg)
+		}
+
+		res.Bytes += n
+
+		reportBackup(opts.Progress, BackupCopy, uint64(i+1), uint64(len(res.Segments)))
+	}
+
+	res.DeltaBlocks, err = s.applyBackupDelta(ctx, log, target, opts, filepath.Join(scratch, "delta"))
+	if err != nil {
+		return nil, err
+	}
+
+	if !opts.SkipVerify {
+		res.VerifiedBlocks, err = s.verifyBackup(ctx, log, target, opts, filepath.Join(scratch, "verify"))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	log.Info("backup complete",
+		"volume", d.volName,
+		"segments", len(res.Segments),
+		"bytes", res.Bytes,
+		"delta_blocks", res.DeltaBlocks,
+		"verified_blocks", res.VerifiedBlocks,
+	)
+
+	return res, nil
+}
+
+// applyBackupDelta attaches to the backup and rewrites every range where its
+// map differs from the snapshot's. Entries present in both maps read the same
+// data, as their segments were copied unchanged, and ranges in neither are
+// zero in both. Anything else is covered by an entry only one side has.
+func (s *Snapshot) applyBackupDelta(ctx context.Context, log *slog.Logger, target SegmentAccess, opts BackupOptions, path string) (uint64, error) {
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return 0, err
+	}
+
+	bd, err := NewDisk(ctx, log, path,
+		WithSegmentAccess(target),
+		WithVolumeName(opts.Volume),
+		AutoCreate(false),
+	)
+	if err != nil {
+		return 0, errors.Wrapf(err, "attaching to backup")
+	}
+
+	defer bd.Close(ctx)
+
+	have := make(map[PartialExtent]struct{})
+	for i := bd.lba2pba.LockedIterator(); i.Valid(); i.Next() {
+		have[i.Value()] = struct{}{}
+	}
+
+	var delta []Extent
+
+	for i := s.m.Iterator(); i.Valid(); i.Next() {
+		pe := i.Value()
+
+		if _, ok := have[pe]; ok {
+			delete(have, pe)
+			continue
+		}
+
+		delta = append(delta, pe.Live)
+	}
+
+	// What remains is only in the backup
+	for pe := range have {
+		delta = append(delta, pe.Live)
+	}
+
+	var total uint64
+	for _, rng := range delta {
+		total += uint64(rng.Blocks)
+	}
+
+	log.Info("applying backup delta", "ranges", len(delta), "blocks", total)
+
+	buf := make([]byte, backupChunkBlocks*BlockSize)
+
+	var done uint64
+
+	err = forEachChunk(delta, func(rng Extent) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		b := buf[:rng.ByteSize()]
+
+		if _, err := s.ReadAt(b, rng.LBA); err != nil {
+			return err
+		}
+
+		if isZero(b) {
+			err = bd.ZeroBlocks(ctx, rng)
+		} else {
+			err = bd.WriteExtent(ctx, MapRangeData(rng, b))
+		}
+		if err != nil {
+			return errors.Wrapf(err, "writing %s to backup", rng)
+		}
+
+		done += uint64(rng.Blocks)
+		reportBackup(opts.Progress, BackupDelta, done, total)
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if err := bd.Close(ctx); err != nil {
+		return 0, errors.Wrapf(err, "closing backup")
+	}
+
+	return total, nil
+}
+
+// verifyBackup attaches to the backup afresh, so its map is rebuilt from
+// what was stored, and compares every range either side has written.
+func (s *Snapshot) verifyBackup(ctx context.Context, log *slog.Logger, target SegmentAccess, opts BackupOptions, path string) (uint64, error) {
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return 0, err
+	}
+
+	bd, err := NewDisk(ctx, log, path,
+		WithSegmentAccess(target),
+		WithVolumeName(opts.Volume),
+		AutoCreate(false),
+		ReadOnly(),
+	)
+	if err != nil {
+		return 0, errors.Wrapf(err, "attaching to backup")
+	}
+
+	defer bd.Close(ctx)
+
+	bsnap, err := bd.Snapshot(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	defer bsnap.Release()
+
+	var ranges []Extent
+
+	for _, m := range []*ExtentMap{s.m, bsnap.m} {
+		for i := m.Iterator(); i.Valid(); i.Next() {
+			ranges = append(ranges, i.Value().Live)
+		}
+	}
+
+	var total uint64
+	for _, rng := range ranges {
+		total += uint64(rng.Blocks)
+	}
+
+	want := make([]byte, backupChunkBlocks*BlockSize)
+	got := make([]byte, backupChunkBlocks*BlockSize)
+
+	var done uint64
+
+	err = forEachChunk(ranges, func(rng Extent) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		w, g := want[:rng.ByteSize()], got[:rng.ByteSize()]
+
+		if _, err := s.ReadAt(w, rng.LBA); err != nil {
+			return err
+		}
+
+		if _, err := bsnap.ReadAt(g, rng.LBA); err != nil {
+			return errors.Wrapf(err, "reading %s from backup", rng)
+		}
+
+		if !bytes.Equal(w, g) {
+			return errors.Wrapf(ErrBackupMismatch, "range %s", rng)
+		}
+
+		done += uint64(rng.Blocks)
+		reportBackup(opts.Progress, BackupVerify, done, total)
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+type RestoreOptions struct {
+	// Path is a scratch directory for staging segments. A temporary
+	// directory is used when empty.
+	Path string
+
+	// Progress, when set, is called after each segment is copied.
+	Progress func(BackupProgress)
+}
+
+// RestoreResult summarizes a completed Restore.
+type RestoreResult struct {
+	Volume   string
+	Segments []SegmentId
+	Bytes    int64
+}
+
+// Restore copies the backup volume named backup from source into a new volume
+// name in target. Segments are copied unchanged and each copy is read back and
+// checked against its source, so the restored volume reads the same as the
+// snapshot the backup was taken from.
+func Restore(ctx context.Context, log *slog.Logger, source SegmentAccess, backup string, target SegmentAccess, name string, opts RestoreOptions) (*RestoreResult, error) {
+	if name == "" {
+		return nil, fmt.Errorf("restored volume name must not be empty")
+	}
+
+	scratch := opts.Path
+	if scratch == "" {
+		dir, err := os.MkdirTemp("", "lsvd-restore")
+		if err != nil {
+			return nil, err
+		}
+
+		defer os.RemoveAll(dir)
+
+		scratch = dir
+	} else if err := os.MkdirAll(scratch, 0755); err != nil {
+		return nil, err
+	}
+
+	src, err := source.OpenVolume(ctx, backup)
+	if err != nil {
+		return nil, err
+	}
+
+	bi, err := src.Info(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading backup %s", backup)
+	}
+
+	segments, err := src.ListSegments(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := target.InitContainer(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := checkNoVolume(ctx, target, name); err != nil {
+		return nil, err
+	}
+
+	info := &VolumeInfo{
+		Name:     name,
+		Size:     bi.Size,
+		Metadata: map[string]any{"restored_from": backup},
+	}
+
+	if err := info.Normalize(); err != nil {
+		return nil, err
+	}
+
+	if err := target.InitVolume(ctx, info); err != nil {
+		return nil, errors.Wrapf(err, "creating restored volume")
+	}
+
+	dst, err := target.OpenVolume(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info("restoring backup", "backup", backup, "volume", name, "segments", len(segments))
+
+	res := &RestoreResult{
+		Volume:   name,
+		Segments: segments,
+	}
+
+	for i, seg := range segments {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		n, err := copySegment(ctx, src, dst, seg, scratch)
+		if err != nil {
+			return nil, errors.Wrapf(err, "copying segment %s", seg)
+		}
+
+		res.Bytes += n
+
+		reportBackup(opts.Progress, BackupCopy, uint64(i+1), uint64(len(segments)))
+	}
+
+	log.Info("restore complete", "backup", backup, "volume", name, "bytes", res.Bytes)
+
+	return res, nil
+}
+
+// backupChunkBlocks bounds how much of a range is read and written at once.
+const backupChunkBlocks = 256
+
+// forEachChunk calls fn with each range split into pieces no larger than
+// backupChunkBlocks.
+func forEachChunk(ranges []Extent, fn func(Extent) error) error {
+	for _, rng := range ranges {
+		for rng.Blocks > 0 {
+			n := min(rng.Blocks, backupChunkBlocks)
+
+			if err := fn(Extent{LBA: rng.LBA, Blocks: n}); err != nil {
+				return err
+			}
+
+			rng.LBA += LBA(n)
+			rng.Blocks -= n
+		}
+	}
+
+	return nil
+}
+
+// copySegment stages seg from one volume in a file under dir, adds it to the
+// other and reads the stored copy back to check it arrived intact.
+func copySegment(ctx context.Context, from, to Volume, seg SegmentId, dir string) (int64, error) {
+	r, err := from.OpenSegment(ctx, seg)
+	if err != nil {
+		return 0, err
+	}
+
+	defer r.Close()
+
+	layout, err := r.Layout(ctx)
+	if err != nil {
+		return 0, errors.Wrapf(err, "reading layout")
+	}
+
+	f, err := os.CreateTemp(dir, "segment-*")
+	if err != nil {
+		return 0, err
+	}
+
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	h := sha256.New()
+
+	n, err := io.Copy(io.MultiWriter(f, h), ToReader(r))
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	if err := to.NewSegment(ctx, seg, layout, f); err != nil {
+		return 0, err
+	}
+
+	sum, err := segmentHash(ctx, to, seg)
+	if err != nil {
+		return 0, errors.Wrapf(err, "reading back copy")
+	}
+
+	if !bytes.Equal(sum, h.Sum(nil)) {
+		return 0, errors.Wrapf(ErrBackupMismatch, "segment %s", seg)
+	}
+
+	return n, nil
+}
+
+func segmentHash(ctx context.Context, vol Volume, seg SegmentId) ([]byte, error) {
+	r, err := vol.OpenSegment(ctx, seg)
+	if err != nil {
+		return nil, err
+	}
+
+	defer r.Close()
+
+	h := sha256.New()
+
+	if _, err := io.Copy(h, ToReader(r)); err != nil {
+		return nil, err
+	}
+
+	return h.Sum(nil), nil
+}
+
+// checkNoVolume returns ErrVolumeExists if sa already has a volume name.
+func checkNoVolume(ctx context.Context, sa SegmentAccess, name string) error {
+	volumes, err := sa.ListVolumes(ctx)
+	if err != nil {
+		return err
+	}
+
+	if slices.Contains(volumes, name) {
+		return errors.Wrapf(ErrVolumeExists, "%s", name)
+	}
+
+	return nil
+}
+
+func reportBackup(fn func(BackupProgress), stage BackupStage, done, total uint64) {
+	if fn != nil {
+		fn(BackupProgress{Stage: stage, Done: done, Total: total})
+	}
+}
+
+func isZero(b []byte) bool {
+	for _, c := range b {
+		if c != 0 {
+			return false
+		}
+	}
+
+	return true
+}
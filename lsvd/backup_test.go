@@ -0,0 +1,194 @@
+package lsvd
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackup(t *testing.T) {
+	log := slog.Default()
+	ctx := context.Background()
+
+	const blocks = 64
+
+	randomRange := func(t *testing.T, ext Extent) RangeData {
+		data := MapRangeData(ext, make([]byte, ext.ByteSize()))
+		_, err := io.ReadFull(rand.Reader, data.WriteData())
+		require.NoError(t, err)
+		return data
+	}
+
+	readAll := func(t *testing.T, read func([]byte, LBA) (int, error)) []byte {
+		buf := make([]byte, blocks*BlockSize)
+		_, err := read(buf, 0)
+		require.NoError(t, err)
+		return buf
+	}
+
+	t.Run("restores a byte-identical copy of the snapshot under concurrent writes", func(t *testing.T) {
+		r := require.New(t)
+
+		tmpdir := t.TempDir()
+
+		d, err := NewDisk(ctx, log, tmpdir)
+		r.NoError(err)
+		defer d.Close(ctx)
+
+		// Spread the data over several segments, overwriting some of it
+		for lba := LBA(0); lba < blocks; lba += 16 {
+			r.NoError(d.WriteExtent(ctx, randomRange(t, Extent{LBA: lba, Blocks: 16})))
+			r.NoError(d.CloseSegment(ctx))
+		}
+
+		r.NoError(d.WriteExtent(ctx, randomRange(t, Extent{LBA: 8, Blocks: 16})))
+		r.NoError(d.CloseSegment(ctx))
+
+		// Zeroed on its own, so the segment holding the zeroes isn't pinned
+		// by the snapshot and the backup must rewrite it
+		r.NoError(d.ZeroBlocks(ctx, Extent{LBA: 40, Blocks: 4}))
+		r.NoError(d.CloseSegment(ctx))
+
+		snap, err := d.Snapshot(ctx)
+		r.NoError(err)
+		defer snap.Release()
+
+		want := readAll(t, snap.ReadAt)
+		r.True(isZero(want[40*BlockSize : 44*BlockSize]))
+
+		stop := make(chan struct{})
+		started := make(chan struct{})
+
+		var (
+			wg       sync.WaitGroup
+			once     sync.Once
+			writeErr error
+		)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer once.Do(func() { close(started) })
+
+			for i := 0; ; i++ {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+
+				data := MapRangeData(Extent{LBA: LBA(i*4) % blocks, Blocks: 4}, make([]byte, 4*BlockSize))
+				rand.Read(data.WriteData())
+
+				if err := d.WriteExtent(ctx, data); err != nil {
+					writeErr = err
+					return
+				}
+
+				if i%8 == 0 {
+					if err := d.CloseSegment(ctx); err != nil {
+						writeErr = err
+						return
+					}
+				}
+
+				// Let some writes land before the backup starts
+				if i == 16 {
+					once.Do(func() { close(started) })
+				}
+			}
+		}()
+
+		<-started
+
+		target := &LocalFileAccess{Dir: filepath.Join(tmpdir, "backup"), Log: log}
+		r.NoError(os.MkdirAll(target.Dir, 0755))
+
+		var stages []BackupStage
+
+		res, err := snap.Backup(ctx, target, BackupOptions{
+			Volume: "backup",
+			Path:   filepath.Join(tmpdir, "scratch"),
+			Progress: func(p BackupProgress) {
+				if len(stages) == 0 || stages[len(stages)-1] != p.Stage {
+					stages = append(stages, p.Stage)
+				}
+			},
+		})
+
+		close(stop)
+		wg.Wait()
+
+		r.NoError(writeErr)
+		r.NoError(err)
+
+		r.NotEmpty(res.Segments)
+		r.NotZero(res.Bytes)
+		r.NotZero(res.DeltaBlocks, "the zeroed range should be in the delta")
+		r.NotZero(res.VerifiedBlocks)
+		r.Equal([]BackupStage{BackupCopy, BackupDelta, BackupVerify}, stages)
+
+		// The disk has moved on from the snapshot
+		now, err := d.Snapshot(ctx)
+		r.NoError(err)
+		r.False(bytes.Equal(want, readAll(t, now.ReadAt)))
+		now.Release()
+
+		dest := &LocalFileAccess{Dir: filepath.Join(tmpdir, "restore"), Log: log}
+		r.NoError(os.MkdirAll(dest.Dir, 0755))
+
+		rres, err := Restore(ctx, log, target, "backup", dest, "restored", RestoreOptions{})
+		r.NoError(err)
+		r.Len(rres.Segments, len(res.Segments)+1, "the delta adds a segment")
+
+		restored := filepath.Join(tmpdir, "restored")
+		r.NoError(os.MkdirAll(restored, 0755))
+
+		rd, err := NewDisk(ctx, log, restored,
+			WithSegmentAccess(dest),
+			WithVolumeName("restored"),
+			AutoCreate(false),
+			ReadOnly(),
+		)
+		r.NoError(err)
+		defer rd.Close(ctx)
+
+		rsnap, err := rd.Snapshot(ctx)
+		r.NoError(err)
+		defer rsnap.Release()
+
+		r.True(bytes.Equal(want, readAll(t, rsnap.ReadAt)), "restored volume differs from the snapshot")
+	})
+
+	t.Run("won't overwrite an existing volume", func(t *testing.T) {
+		r := require.New(t)
+
+		tmpdir := t.TempDir()
+
+		d, err := NewDisk(ctx, log, tmpdir)
+		r.NoError(err)
+		defer d.Close(ctx)
+
+		r.NoError(d.WriteExtent(ctx, randomRange(t, Extent{LBA: 0, Blocks: 4})))
+
+		target := &LocalFileAccess{Dir: filepath.Join(tmpdir, "backup"), Log: log}
+		r.NoError(os.MkdirAll(target.Dir, 0755))
+
+		_, err = d.Backup(ctx, target, BackupOptions{Volume: "backup"})
+		r.NoError(err)
+
+		_, err = d.Backup(ctx, target, BackupOptions{Volume: "backup"})
+		r.ErrorIs(err, ErrVolumeExists)
+
+		_, err = Restore(ctx, log, target, "backup", target, "backup", RestoreOptions{})
+		r.ErrorIs(err, ErrVolumeExists)
+	})
+}
@@ -13,7 +13,6 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"golang.org/x/sys/unix"
 	"miren.dev/runtime/lsvd"
 	"miren.dev/runtime/lsvd/paths"
@@ -186,7 +185,7 @@ func (c *CLI) bind(ctx context.Context, opts struct {
 		d.Close(ctx)
 	}()
 
-	http.Handle("/metrics", promhttp.Handler())
+	http.Handle("/metrics", lsvd.MetricsHandler())
 	// Will also include pprof via the init() in net/http/pprof
 	go http.ListenAndServe(opts.MetricsAddr, nil)
 
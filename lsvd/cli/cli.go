@@ -6,12 +6,14 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
 	"net"
 	"net/http"
 	_ "net/http/pprof"
+	"net/url"
 	"os"
 	"os/signal"
 	"path/filepath"
@@ -31,7 +33,6 @@ import (
 	"github.com/lima-vm/go-qcow2reader"
 	"github.com/mitchellh/cli"
 	"github.com/pkg/errors"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"golang.org/x/sys/unix"
 	"miren.dev/runtime/lsvd"
 	"miren.dev/runtime/lsvd/pkg/nbd"
@@ -91,6 +92,21 @@ func (c *CLI) setupCommands() error {
 		"sha256": func() (cli.Command, error) {
 			return cleo.Infer("sha256", "hash the contents of the volume", c.sha256), nil
 		},
+		"snapshot": func() (cli.Command, error) {
+			return cleo.Infer("snapshot", "write a consistent image of a volume", c.snapshot), nil
+		},
+		"export-snapshot": func() (cli.Command, error) {
+			return cleo.Infer("export-snapshot", "export a snapshot of a served volume read-only over nbd", c.exportSnapshot), nil
+		},
+		"verify": func() (cli.Command, error) {
+			return cleo.Infer("verify", "check the integrity of a volume's segments", c.verify), nil
+		},
+		"backup": func() (cli.Command, error) {
+			return cleo.Infer("backup", "copy a consistent backup of a live volume to other storage", c.backup), nil
+		},
+		"restore": func() (cli.Command, error) {
+			return cleo.Infer("restore", "restore a volume from a backup", c.restore), nil
+		},
 	}
 
 	return nil
@@ -105,7 +121,38 @@ func (c *CLI) loadSegmentAccess(ctx context.Context, path string) (lsvd.SegmentA
 
 	var sa lsvd.SegmentAccess
 
-	if cfg.Storage.FilePath != "" {
+	if len(cfg.Storage.Devices) > 0 {
+		if cfg.Storage.FilePath != "" || cfg.Storage.S3.Bucket != "" {
+			c.log.Error("storage is either devices, filepath, or s3, not more than one")
+			os.Exit(1)
+		}
+
+		policy, err := lsvd.ParseAllocationPolicy(cfg.Storage.Allocation)
+		if err != nil {
+			c.log.Error("error loading allocation policy", "error", err)
+			os.Exit(1)
+		}
+
+		var devices []lsvd.Device
+
+		for _, dir := range cfg.Storage.Devices {
+			storagePath, err := filepath.Abs(dir)
+			if err != nil {
+				c.log.Error("error resolving device path to store objects", "error", err)
+				os.Exit(1)
+			}
+
+			devices = append(devices, lsvd.Device{
+				Name:   storagePath,
+				Access: &lsvd.LocalFileAccess{Dir: storagePath, Log: c.log},
+			})
+		}
+
+		sa, err = lsvd.MultiDevice(c.log, policy, devices...)
+		if err != nil {
+			return nil, err
+		}
+	} else if cfg.Storage.FilePath != "" {
 		if cfg.Storage.S3.Bucket != "" {
 			c.log.Error("storage is either filepath, or s3, not both")
 			os.Exit(1)
@@ -510,13 +557,8 @@ func (c *CLI) nbdServe(ctx context.Context, opts struct {
 		l.Close()
 	}()
 
-	exports := []*nbd.Export{
-		{
-			Name:        name,
-			Description: "disk",
-			Backend:     lsvd.NBDWrapper(ctx, log, d),
-		},
-	}
+	exports := lsvd.NewNBDExports(ctx, log, d, name)
+	defer exports.Close()
 
 	log.Info("listening for connections", "addr", opts.Addr)
 
@@ -525,7 +567,9 @@ func (c *CLI) nbdServe(ctx context.Context, opts struct {
 		PreferredBlockSize: 4096,
 	}
 
-	http.Handle("/metrics", promhttp.Handler())
+	http.Handle("/metrics", lsvd.MetricsHandler())
+	http.Handle("/snapshots", exports.Handler())
+	http.Handle("/snapshots/", exports.Handler())
 	// Will also include pprof via the init() in net/http/pprof
 	go http.ListenAndServe(opts.MetricsAddr, nil)
 
@@ -553,10 +597,16 @@ func (c *CLI) nbdServe(ctx context.Context, opts struct {
 
 		log.Info("connection to nbd server", "remote", c.RemoteAddr().String())
 
-		err = nbd.Handle(log, c, exports, nbdOpts)
-		if err != nil {
-			log.Error("error handling nbd client", "error", err)
-		}
+		// Connections are served concurrently so that snapshot exports
+		// can be read while the live volume stays attached.
+		go func() {
+			defer c.Close()
+
+			err := nbd.Handle(log, c, exports.Exports(), nbdOpts)
+			if err != nil {
+				log.Error("error handling nbd client", "error", err)
+			}
+		}()
 	}
 
 	return nil
@@ -852,3 +902,310 @@ func (c *CLI) sha256(gctx context.Context, opts struct {
 
 	return nil
 }
+
+func (c *CLI) snapshot(ctx context.Context, opts struct {
+	Global
+	Name string `short:"n" long:"name" description:"name of volume to snapshot" required:"true"`
+	Path string `short:"p" long:"path" description:"path for cached data" required:"true"`
+	Out  string `short:"o" long:"out" description:"file to write the image to" required:"true"`
+	BS   int    `long:"bs" description:"how many blocks to read at a time (default 256)"`
+}) error {
+	sa, err := c.loadSegmentAccess(ctx, opts.Config)
+	if err != nil {
+		return err
+	}
+
+	log := c.log
+
+	d, err := lsvd.NewDisk(ctx, log, opts.Path,
+		lsvd.WithSegmentAccess(sa),
+		lsvd.WithVolumeName(opts.Name),
+	)
+	if err != nil {
+		log.Error("error creating new disk", "error", err)
+		os.Exit(1)
+	}
+
+	defer d.Close(ctx)
+
+	snap, err := d.Snapshot(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer snap.Release()
+
+	f, err := os.Create(opts.Out)
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	bs := opts.BS
+	if bs == 0 {
+		bs = 256
+	}
+
+	buf := make([]byte, lsvd.BlockSize*bs)
+
+	start := time.Now()
+	size := d.Size()
+
+	for off := int64(0); off < size; off += int64(len(buf)) {
+		b := buf
+		if left := size - off; left < int64(len(b)) {
+			b = b[:left]
+		}
+
+		_, err := snap.ReadAt(b, lsvd.LBA(off/lsvd.BlockSize))
+		if err != nil {
+			return err
+		}
+
+		if _, err := f.Write(b); err != nil {
+			return err
+		}
+	}
+
+	if err := f.Sync(); err != nil {
+		return err
+	}
+
+	log.Info("snapshot written", "volume", opts.Name, "out", opts.Out, "size", size, "elapsed", time.Since(start))
+
+	return nil
+}
+
+func (c *CLI) exportSnapshot(ctx context.Context, opts struct {
+	Global
+	Snapshot string `short:"s" long:"snapshot" description:"name to export the snapshot under"`
+	Server   string `long:"server" default:"localhost:2121" description:"metrics address of the nbd server serving the volume"`
+	Release  bool   `long:"release" description:"stop exporting the snapshot and release it"`
+}) error {
+	base := "http://" + opts.Server + "/snapshots"
+
+	var (
+		req *http.Request
+		err error
+	)
+
+	switch {
+	case opts.Snapshot == "":
+		if opts.Release {
+			return fmt.Errorf("--release requires --snapshot")
+		}
+
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, base, nil)
+	case opts.Release:
+		req, err = http.NewRequestWithContext(ctx, http.MethodDelete, base+"/"+url.PathEscape(opts.Snapshot), nil)
+	default:
+		req, err = http.NewRequestWithContext(ctx, http.MethodPut, base+"/"+url.PathEscape(opts.Snapshot), nil)
+	}
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "contacting nbd server")
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("nbd server: %s", strings.TrimSpace(string(msg)))
+	}
+
+	switch {
+	case opts.Release:
+		c.log.Info("released snapshot export", "snapshot", opts.Snapshot)
+	case opts.Snapshot == "":
+		var list struct {
+			Snapshots []lsvd.SnapshotExport `json:"snapshots"`
+		}
+
+		if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+			return err
+		}
+
+		tr := tabwriter.NewWriter(os.Stdout, 2, 2, 1, ' ', 0)
+		defer tr.Flush()
+
+		fmt.Fprintf(tr, "SNAPSHOT\tEXPORT\tCREATED\n")
+
+		for _, se := range list.Snapshots {
+			fmt.Fprintf(tr, "%s\t%s\t%s\n", se.Name, se.Export, se.Created.Format(time.RFC3339))
+		}
+	default:
+		var se lsvd.SnapshotExport
+
+		if err := json.NewDecoder(resp.Body).Decode(&se); err != nil {
+			return err
+		}
+
+		fmt.Println(se.Export)
+	}
+
+	return nil
+}
+
+func (c *CLI) verify(ctx context.Context, opts struct {
+	Global
+	Name   string `short:"n" long:"name" description:"name of volume to verify" required:"true"`
+	Path   string `short:"p" long:"path" description:"path for cached data, needed to repair"`
+	Repair bool   `long:"repair" description:"remove bad trailing segments whose writes are still in the write cache"`
+}) error {
+	if opts.Repair && opts.Path == "" {
+		return fmt.Errorf("--repair requires --path to find the write cache")
+	}
+
+	sa, err := c.loadSegmentAccess(ctx, opts.Config)
+	if err != nil {
+		return err
+	}
+
+	vol, err := sa.OpenVolume(ctx, opts.Name)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+
+	res, err := lsvd.VerifyVolume(ctx, c.log, vol)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range res.Problems {
+		fmt.Printf("segment %s offset %d: %s\n", p.Segment, p.Offset, p.Err)
+	}
+
+	fmt.Printf("%d segments scanned, %s verified, %d problems (%s)\n",
+		len(res.Segments), niceSize(res.Bytes), len(res.Problems), time.Since(start))
+
+	if res.OK() {
+		return nil
+	}
+
+	if opts.Repair {
+		removed, err := lsvd.RepairVolume(ctx, c.log, vol, opts.Path, res)
+		if err != nil {
+			return err
+		}
+
+		for _, seg := range removed {
+			fmt.Printf("removed segment %s\n", seg)
+		}
+
+		if len(removed) > 0 {
+			res, err = lsvd.VerifyVolume(ctx, c.log, vol)
+			if err != nil {
+				return err
+			}
+
+			if res.OK() {
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("volume %s failed verification with %d problems", opts.Name, len(res.Problems))
+}
+
+func (c *CLI) backup(ctx context.Context, opts struct {
+	Global
+	Name     string `short:"n" long:"name" description:"name of volume to back up" required:"true"`
+	Path     string `short:"p" long:"path" description:"path for cached data" required:"true"`
+	Target   string `short:"t" long:"target" description:"storage configuration to write the backup to" required:"true"`
+	As       string `long:"as" description:"name of the backup volume in the target" required:"true"`
+	NoVerify bool   `long:"no-verify" description:"skip reading the backup back once written"`
+}) error {
+	sa, err := c.loadSegmentAccess(ctx, opts.Config)
+	if err != nil {
+		return err
+	}
+
+	target, err := c.loadSegmentAccess(ctx, opts.Target)
+	if err != nil {
+		return err
+	}
+
+	log := c.log
+
+	d, err := lsvd.NewDisk(ctx, log, opts.Path,
+		lsvd.WithSegmentAccess(sa),
+		lsvd.WithVolumeName(opts.Name),
+		lsvd.AutoCreate(false),
+	)
+	if err != nil {
+		log.Error("error creating new disk", "error", err)
+		os.Exit(1)
+	}
+
+	defer d.Close(ctx)
+
+	start := time.Now()
+
+	res, err := d.Backup(ctx, target, lsvd.BackupOptions{
+		Volume:     opts.As,
+		SkipVerify: opts.NoVerify,
+		Progress:   printBackupProgress(),
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("backed up %s to %s: %d segments, %s copied, %d blocks rewritten, %d blocks verified (%s)\n",
+		opts.Name, res.Volume, len(res.Segments), niceSize(res.Bytes), res.DeltaBlocks, res.VerifiedBlocks, time.Since(start))
+
+	return nil
+}
+
+func (c *CLI) restore(ctx context.Context, opts struct {
+	Global
+	From   string `short:"f" long:"from" description:"storage configuration holding the backup" required:"true"`
+	Backup string `short:"b" long:"backup" description:"name of the backup volume" required:"true"`
+	Name   string `short:"n" long:"name" description:"name of volume to restore to" required:"true"`
+}) error {
+	sa, err := c.loadSegmentAccess(ctx, opts.Config)
+	if err != nil {
+		return err
+	}
+
+	source, err := c.loadSegmentAccess(ctx, opts.From)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+
+	res, err := lsvd.Restore(ctx, c.log, source, opts.Backup, sa, opts.Name, lsvd.RestoreOptions{
+		Progress: printBackupProgress(),
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("restored %s from %s: %d segments, %s copied (%s)\n",
+		res.Volume, opts.Backup, len(res.Segments), niceSize(res.Bytes), time.Since(start))
+
+	return nil
+}
+
+// printBackupProgress returns a progress callback that prints each stage at
+// most once a second, and when it finishes.
+func printBackupProgress() func(lsvd.BackupProgress) {
+	var last time.Time
+
+	return func(p lsvd.BackupProgress) {
+		if p.Done < p.Total && time.Since(last) < time.Second {
+			return
+		}
+
+		last = time.Now()
+
+		fmt.Printf("%s: %d/%d\n", p.Stage, p.Done, p.Total)
+	}
+}
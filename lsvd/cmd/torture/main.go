@@ -21,10 +21,11 @@ var (
 	flagOps        = flag.Int("ops", 10000, "Number of operations to run")
 	flagDuration   = flag.Duration("duration", 0, "Run for this duration (overrides -ops)")
 	flagConfig     = flag.String("config", "", "Base64-encoded config for reproduction")
-	flagVariation  = flag.String("variation", "", "Test variation: default, no-close-reopen, high-overlap, heavy-zero, durability, boundaries")
+	flagVariation  = flag.String("variation", "", "Test variation: default, no-close-reopen, high-overlap, heavy-zero, durability, boundaries, snapshot, power-fail, discard, block-sizes")
 	flagVerify     = flag.Int("verify", 1000, "Verify every N operations")
 	flagMaxLBA     = flag.Int64("max-lba", 100000, "Maximum LBA to use")
 	flagMaxBlocks  = flag.Int("max-blocks", 64, "Maximum blocks per operation")
+	flagBlockSize  = flag.Int("block-size", 0, "Logical block size in bytes, 512 to 4096 (0 = disk block size)")
 	flagDir        = flag.String("dir", "", "Directory for test data (default: temp dir)")
 	flagLoop       = flag.Bool("loop", false, "Run continuously until failure (cycles through variations)")
 	flagHammer     = flag.Bool("hammer", false, "Run exact same config repeatedly until stopped")
@@ -69,8 +70,8 @@ func main() {
 			fmt.Fprintf(os.Stderr, "Failed to decode config: %v\n", err)
 			os.Exit(1)
 		}
-		fmt.Fprintf(os.Stderr, "Using config: seed=%d ops=%d weights=%+v\n",
-			cfg.Seed, cfg.Operations, cfg.Weights)
+		fmt.Fprintf(os.Stderr, "Using config: seed=%d ops=%d block-size=%d weights=%+v\n",
+			cfg.Seed, cfg.Operations, cfg.BlockSize, cfg.Weights)
 	} else {
 		cfg = lsvd.DefaultTortureConfig
 		cfg.Operations = *flagOps
@@ -78,6 +79,10 @@ func main() {
 		cfg.MaxLBA = lsvd.LBA(*flagMaxLBA)
 		cfg.MaxBlocks = uint32(*flagMaxBlocks)
 
+		if *flagBlockSize != 0 {
+			cfg.BlockSize = *flagBlockSize
+		}
+
 		if *flagSeed != 0 {
 			cfg.Seed = *flagSeed
 		} else {
@@ -95,12 +100,15 @@ func main() {
 					if *flagVariation == "boundaries" {
 						cfg.MaxBlocks = 100
 					}
+					if bs := v.BlockSizeFor(0); bs != 0 && *flagBlockSize == 0 {
+						cfg.BlockSize = bs
+					}
 					found = true
 					break
 				}
 			}
 			if !found {
-				fmt.Fprintf(os.Stderr, "Unknown variation: %s (available: default, no-close-reopen, high-overlap, heavy-zero, durability, boundaries)\n", *flagVariation)
+				fmt.Fprintf(os.Stderr, "Unknown variation: %s (available: default, no-close-reopen, high-overlap, heavy-zero, durability, boundaries, snapshot, power-fail, discard, block-sizes)\n", *flagVariation)
 				os.Exit(1)
 			}
 		}
@@ -179,6 +187,7 @@ func runTortureSingle(ctx context.Context, log *slog.Logger, dir string, cfg lsv
 func runTortureLoop(ctx context.Context, log *slog.Logger, dir string, cfg lsvd.TortureConfig, quiet bool) error {
 	iteration := 0
 	variations := lsvd.DefaultTortureVariations()
+	blockSize := cfg.BlockSize
 
 	fmt.Fprintf(os.Stderr, "Starting continuous torture test (Ctrl+C to stop)\n")
 
@@ -207,9 +216,13 @@ func runTortureLoop(ctx context.Context, log *slog.Logger, dir string, cfg lsvd.
 		if variation.Name == "boundaries" {
 			cfg.MaxBlocks = 100
 		}
+		cfg.BlockSize = blockSize
+		if bs := variation.BlockSizeFor(iteration / len(variations)); bs != 0 {
+			cfg.BlockSize = bs
+		}
 
-		fmt.Fprintf(os.Stderr, "[%d] Running variation '%s' with seed %d\n",
-			iteration+1, variation.Name, cfg.Seed)
+		fmt.Fprintf(os.Stderr, "[%d] Running variation '%s' with seed %d block size %d\n",
+			iteration+1, variation.Name, cfg.Seed, cfg.BlockSize)
 
 		os.RemoveAll(dir)
 		os.Mkdir(dir, 0755)
@@ -281,6 +294,7 @@ func runTortureTimed(ctx context.Context, log *slog.Logger, dir string, cfg lsvd
 	variations := lsvd.DefaultTortureVariations()
 	iteration := 0
 	opsPerRun := 3000
+	blockSize := cfg.BlockSize
 
 	fmt.Fprintf(os.Stderr, "Running torture tests for %v (until %v)\n",
 		duration, deadline.Format(time.RFC3339))
@@ -310,6 +324,10 @@ func runTortureTimed(ctx context.Context, log *slog.Logger, dir string, cfg lsvd
 		if variation.Name == "boundaries" {
 			cfg.MaxBlocks = 100
 		}
+		cfg.BlockSize = blockSize
+		if bs := variation.BlockSizeFor(iteration / len(variations)); bs != 0 {
+			cfg.BlockSize = bs
+		}
 		cfg.VerifyEvery = 300
 
 		remaining := time.Until(deadline)
@@ -317,8 +335,8 @@ func runTortureTimed(ctx context.Context, log *slog.Logger, dir string, cfg lsvd
 			remaining = 0
 		}
 
-		fmt.Fprintf(os.Stderr, "[%d] Running variation '%s' with seed %d",
-			iteration+1, variation.Name, cfg.Seed)
+		fmt.Fprintf(os.Stderr, "[%d] Running variation '%s' with seed %d block size %d",
+			iteration+1, variation.Name, cfg.Seed, cfg.BlockSize)
 		if !loop {
 			fmt.Fprintf(os.Stderr, " (remaining: %v)", remaining.Round(time.Second))
 		}
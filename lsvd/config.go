@@ -10,7 +10,14 @@ type Config struct {
 
 	Storage struct {
 		FilePath string `hcl:"file_path,optional"`
-		S3       struct {
+
+		// Devices are directories, each on its own device, that segments
+		// are spread across instead of being stored under FilePath.
+		// Allocation names the AllocationPolicy placing them.
+		Devices    []string `hcl:"devices,optional"`
+		Allocation string   `hcl:"allocation,optional"`
+
+		S3 struct {
 			Bucket    string `hcl:"bucket"`
 			Region    string `hcl:"region"`
 			AccessKey string `hcl:"access_key,optional"`
@@ -326,8 +326,13 @@ func (c *Controller) startGC(ctx *Context, ev Event) error {
 	gcCount.Inc()
 	s := time.Now()
 
+	done := c.d.stats.startGC(s)
+
 	defer func() {
-		gcTime.Add(time.Since(s).Seconds())
+		dur := time.Since(s).Seconds()
+		gcTime.Add(dur)
+		compactionLatency.Observe(dur)
+		done()
 	}()
 
 	d := c.d
@@ -425,8 +430,13 @@ func (c *Controller) packSegments(ctx *Context, ev Event, segments []SegmentId)
 	gcCount.Inc()
 	s := time.Now()
 
+	done := c.d.stats.startGC(s)
+
 	defer func() {
-		gcTime.Add(time.Since(s).Seconds())
+		dur := time.Since(s).Seconds()
+		gcTime.Add(dur)
+		compactionLatency.Observe(dur)
+		done()
 	}()
 
 	d := c.d
@@ -55,12 +55,27 @@ type Disk struct {
 
 	autoGC bool
 
+	flushPolicy FlushPolicy
+
 	deleteMu sync.Mutex
 
+	// snapshotMu guards the segments pinned by open snapshots, and those
+	// whose removal waits on a snapshot's release.
+	snapshotMu      sync.Mutex
+	pinnedSegments  map[SegmentId]int
+	deferredRemoval map[SegmentId]struct{}
+
+	// worm is the volume's WORM policy, nil unless it's in WORM mode.
+	worm    atomic.Pointer[WORMPolicy]
+	wormMu  sync.Mutex
+	wormNow func() time.Time
+
 	controller *Controller
 	wg         sync.WaitGroup
 	closed     *atomic.Int32
 
+	stats diskStats
+
 	cpsScratch     []CachePosition
 	readReqScratch []readRequest
 	extentsScratch []Extent
@@ -83,6 +98,10 @@ func NewDisk(ctx context.Context, log *slog.Logger, path string, options ...Opti
 		o.volName = "default"
 	}
 
+	if o.flushPolicy == nil {
+		o.flushPolicy = DefaultFlushPolicy()
+	}
+
 	err := o.sa.InitContainer(ctx)
 	if err != nil {
 		return nil, err
@@ -139,6 +158,7 @@ func NewDisk(ctx context.Context, log *slog.Logger, path string, options ...Opti
 		afterNS:        o.afterNS,
 		readOnly:       o.ro,
 		useZstd:        o.useZstd,
+		flushPolicy:    o.flushPolicy,
 		er:             er,
 		prevCache:      NewPreviousCache(),
 		s:              NewSegments(),
@@ -147,6 +167,17 @@ func NewDisk(ctx context.Context, log *slog.Logger, path string, options ...Opti
 		extentsScratch: make([]Extent, 0, 10),
 		peScratch:      make([]PartialExtent, 0, 10),
 		closed:         new(atomic.Int32),
+		wormNow:        time.Now,
+	}
+
+	if err := d.loadWORM(ctx); err != nil {
+		return nil, err
+	}
+
+	if o.wormRetention > 0 {
+		if err := d.EnableWORM(ctx, o.wormRetention); err != nil {
+			return nil, err
+		}
 	}
 
 	d.readDisks = append(d.readDisks, d)
@@ -294,8 +325,10 @@ func (d *Disk) ReadExtentInto(ctx *Context, data RangeData) (CachePosition, erro
 	rng := data.Extent
 
 	blocksRead.Add(float64(rng.Blocks))
+	d.stats.blocksRead.Add(uint64(rng.Blocks))
 
 	iops.Inc()
+	d.stats.iops.Add(1)
 
 	log := d.log
 
@@ -670,32 +703,77 @@ func (d *Disk) ZeroBlocks(ctx context.Context, rng Extent) error {
 		return nil
 	}
 
+	if err := d.checkWORM(rng); err != nil {
+		return err
+	}
+
 	iops.Inc()
 	blocksWritten.Add(float64(rng.Blocks))
+	d.stats.iops.Add(1)
+	d.stats.blocksWritten.Add(uint64(rng.Blocks))
 
 	return d.curOC.ZeroBlocks(rng)
 }
 
+// Discard deallocates blocks starting at lba, as for a TRIM. Discarded blocks
+// read back as zero, and the segment data they held becomes dead so that it
+// can be reclaimed by compaction. Ranges of any size are accepted and split
+// up to keep extents under MaxBlocks.
+func (d *Disk) Discard(ctx context.Context, lba LBA, blocks uint32) error {
+	if d.readOnly {
+		return ErrReadOnly
+	}
+
+	if err := d.checkWORM(Extent{LBA: lba, Blocks: blocks}); err != nil {
+		return err
+	}
+
+	iops.Inc()
+	blocksDiscarded.Add(float64(blocks))
+	d.stats.iops.Add(1)
+	d.stats.blocksDiscarded.Add(uint64(blocks))
+
+	for blocks > 0 {
+		n := min(blocks, MaxBlocks)
+
+		if err := d.curOC.ZeroBlocks(Extent{LBA: lba, Blocks: n}); err != nil {
+			return err
+		}
+
+		lba += LBA(n)
+		blocks -= n
+	}
+
+	return nil
+}
+
 func (d *Disk) checkFlush(ctx context.Context) error {
 	if d.closed.Load() != 0 {
 		return fmt.Errorf("disk is closed")
 	}
 
-	reason := d.curOC.ShouldFlush(FlushThreshHold)
+	if d.curOC.EmptyP() {
+		return nil
+	}
+
+	info := d.curOC.builder.Info(time.Now())
+
+	reason := d.flushPolicy.ShouldFlush(time.Now(), info)
 	if reason == FlushNo {
 		return nil
 	}
 
+	segmentFlushes.WithLabelValues(reason.String()).Inc()
+
 	switch reason {
 	case FlushTime:
-		d.log.Info("flushing segment due to maximum segment lifetime",
-			"age", time.Since(d.curOC.builder.openedAt),
-			"threshold", MaxSegmentLifetime,
+		d.log.Info("flushing segment due to age of pending writes",
+			"age", info.Age,
+			"pending", info.Pending,
 		)
 	case FlushSize:
 		d.log.Info("flushing segment due to size threshold",
-			"body-size", d.curOC.BodySize(),
-			"threshold", FlushThreshHold,
+			"body-size", info.BodySize,
 		)
 	default:
 		d.log.Warn("flushing segment for unknown reason", "reason", reason)
@@ -749,15 +827,24 @@ func (d *Disk) WriteExtent(ctx context.Context, data RangeData) error {
 		return ErrReadOnly
 	}
 
+	if err := d.checkWORM(data.Extent); err != nil {
+		return err
+	}
+
 	start := time.Now()
 
+	// The latency includes checkFlush, which blocks when flushes back up.
 	defer func() {
-		blocksWriteLatency.Observe(time.Since(start).Seconds())
+		latency := time.Since(start)
+		blocksWriteLatency.Observe(latency.Seconds())
+		d.flushPolicy.ObserveWrite(time.Now(), int(data.Blocks)*BlockSize, latency)
 	}()
 
 	blocksWritten.Add(float64(data.Blocks))
+	d.stats.blocksWritten.Add(uint64(data.Blocks))
 
 	iops.Inc()
+	d.stats.iops.Add(1)
 
 	err := d.curOC.WriteExtent(data)
 	if err != nil {
@@ -780,13 +867,27 @@ func (d *Disk) WriteExtents(ctx context.Context, ranges []RangeData) error {
 		return ErrReadOnly
 	}
 
+	for _, data := range ranges {
+		if err := d.checkWORM(data.Extent); err != nil {
+			return err
+		}
+	}
+
 	start := time.Now()
 
 	defer func() {
-		blocksWriteLatency.Observe(time.Since(start).Seconds())
+		var bytes int
+		for _, data := range ranges {
+			bytes += int(data.Blocks) * BlockSize
+		}
+
+		latency := time.Since(start)
+		blocksWriteLatency.Observe(latency.Seconds())
+		d.flushPolicy.ObserveWrite(time.Now(), bytes, latency)
 	}()
 
 	iops.Add(float64(len(ranges)))
+	d.stats.iops.Add(uint64(len(ranges)))
 
 	for _, data := range ranges {
 		err := d.curOC.WriteExtent(data)
@@ -805,6 +906,7 @@ func (d *Disk) SyncWriteCache() error {
 	}
 
 	iops.Inc()
+	d.stats.iops.Add(1)
 
 	if d.curOC != nil {
 		return d.curOC.builder.Sync()
@@ -0,0 +1,160 @@
+package lsvd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// EraseMode selects how SecureErase makes a volume's data unrecoverable.
+type EraseMode int
+
+const (
+	// EraseOverwrite replaces every written block with zeroes and removes the
+	// segments that held the old data.
+	EraseOverwrite EraseMode = iota
+
+	// EraseCryptoShred destroys the key of an encrypted volume, leaving its
+	// segments unreadable without rewriting them.
+	EraseCryptoShred
+)
+
+func (m EraseMode) String() string {
+	switch m {
+	case EraseOverwrite:
+		return "overwrite"
+	case EraseCryptoShred:
+		return "crypto-shred"
+	default:
+		return fmt.Sprintf("EraseMode(%d)", int(m))
+	}
+}
+
+// ErrNotEncrypted is returned when crypto-shredding a volume that has no key
+// to destroy.
+var ErrNotEncrypted = errors.New("volume is not encrypted")
+
+// EraseProgress describes how far a SecureErase has gotten.
+type EraseProgress struct {
+	ErasedBlocks uint64
+	TotalBlocks  uint64
+}
+
+type SecureEraseOptions struct {
+	Mode EraseMode
+
+	// Progress, when set, is called after each written range is erased.
+	Progress func(EraseProgress)
+}
+
+// EraseResult summarizes a completed SecureErase.
+type EraseResult struct {
+	ErasedBlocks    uint64
+	RemovedSegments int
+}
+
+// SecureErase makes all data written to the disk unrecoverable, as when
+// decommissioning a volume. Afterwards every block reads as zero.
+//
+// Segments shared with another volume, such as one cloned from this one,
+// are dropped from this volume but left in storage for the other volume.
+func (d *Disk) SecureErase(ctx context.Context, opts SecureEraseOptions) (*EraseResult, error) {
+	if d.readOnly {
+		return nil, ErrReadOnly
+	}
+
+	switch opts.Mode {
+	case EraseOverwrite:
+		return d.eraseOverwrite(ctx, opts)
+	case EraseCryptoShred:
+		// Segments are stored unencrypted, so there is no key to shred.
+		return nil, ErrNotEncrypted
+	default:
+		return nil, fmt.Errorf("unknown erase mode: %s", opts.Mode)
+	}
+}
+
+func (d *Disk) eraseOverwrite(ctx context.Context, opts SecureEraseOptions) (*EraseResult, error) {
+	// Flush pending writes so that every written range is in the map
+	if err := d.CloseSegment(ctx); err != nil {
+		return nil, errors.Wrapf(err, "closing segment")
+	}
+
+	// Collect the ranges up front, as zeroing them updates the map once the
+	// segment is closed.
+	var (
+		ranges []Extent
+		total  uint64
+	)
+
+	for i := d.lba2pba.LockedIterator(); i.Valid(); i.Next() {
+		pe := i.Value()
+
+		// Already zeroed, so there is no data to remove
+		if pe.Flags() == Empty {
+			continue
+		}
+
+		ranges = append(ranges, pe.Live)
+		total += uint64(pe.Live.Blocks)
+	}
+
+	for _, rng := range ranges {
+		if err := d.checkWORM(rng); err != nil {
+			return nil, err
+		}
+	}
+
+	d.log.Info("securely erasing volume", "volume", d.volName, "mode", opts.Mode, "blocks", total)
+
+	var res EraseResult
+
+	for _, rng := range ranges {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		if err := d.ZeroBlocks(ctx, rng); err != nil {
+			return nil, errors.Wrapf(err, "zeroing %s", rng)
+		}
+
+		res.ErasedBlocks += uint64(rng.Blocks)
+
+		if opts.Progress != nil {
+			opts.Progress(EraseProgress{
+				ErasedBlocks: res.ErasedBlocks,
+				TotalBlocks:  total,
+			})
+		}
+	}
+
+	// Closing the segment remaps the ranges to zeroes, leaving the segments
+	// holding the old data without any live blocks.
+	if err := d.CloseSegment(ctx); err != nil {
+		return nil, errors.Wrapf(err, "closing segment")
+	}
+
+	dead, err := d.s.AllDeadSegments()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, seg := range dead {
+		d.s.SetDeleted(seg, d.log)
+	}
+
+	if err := d.cleanupDeletedSegments(ctx); err != nil {
+		return nil, errors.Wrapf(err, "removing erased segments")
+	}
+
+	res.RemovedSegments = len(dead)
+
+	d.log.Info("securely erased volume",
+		"volume", d.volName,
+		"erased_blocks", res.ErasedBlocks,
+		"removed_segments", res.RemovedSegments,
+	)
+
+	return &res, nil
+}
@@ -195,6 +195,17 @@ func (e *ExtentMap) Populate(log *slog.Logger, o *ExtentMap, diskId uint16) erro
 	return nil
 }
 
+// Clone returns a copy of the map that is unaffected by later updates.
+func (e *ExtentMap) Clone() *ExtentMap {
+	o := NewExtentMap()
+
+	for i := e.LockedIterator(); i.Valid(); i.Next() {
+		o.set(i.Value())
+	}
+
+	return o
+}
+
 /*
 func (e *ExtentMap) find(lba LBA) treemap.ForwardIterator[LBA, PartialExtent] {
 	i := e.m.Floor(lba)
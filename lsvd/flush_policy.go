@@ -0,0 +1,248 @@
+package lsvd
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// OpenSegmentInfo describes the segment currently accepting writes, as seen
+// by a FlushPolicy.
+type OpenSegmentInfo struct {
+	// BodySize is the number of bytes the segment body holds so far
+	BodySize int
+
+	// Age is how long ago the segment was opened
+	Age time.Duration
+
+	// Pending is how long the oldest write in the segment has waited to be
+	// flushed
+	Pending time.Duration
+}
+
+// FlushPolicy decides when the open segment is sealed and flushed to
+// storage.
+type FlushPolicy interface {
+	// ObserveWrite records a write of the given number of bytes, and how long
+	// the write took.
+	ObserveWrite(now time.Time, bytes int, latency time.Duration)
+
+	// ShouldFlush is consulted after writes and periodically, and only with
+	// a segment that holds data.
+	ShouldFlush(now time.Time, seg OpenSegmentInfo) FlushReason
+}
+
+// FixedFlushPolicy flushes at a fixed size or age, regardless of load. It is
+// the default.
+type FixedFlushPolicy struct {
+	Size     int
+	Lifetime time.Duration
+}
+
+// DefaultFlushPolicy returns a fixed policy using FlushThreshHold and
+// MaxSegmentLifetime.
+func DefaultFlushPolicy() *FixedFlushPolicy {
+	return &FixedFlushPolicy{
+		Size:     FlushThreshHold,
+		Lifetime: MaxSegmentLifetime,
+	}
+}
+
+func (p *FixedFlushPolicy) ObserveWrite(now time.Time, bytes int, latency time.Duration) {}
+
+func (p *FixedFlushPolicy) ShouldFlush(now time.Time, seg OpenSegmentInfo) FlushReason {
+	if seg.BodySize >= p.Size {
+		return FlushSize
+	}
+
+	if seg.Age >= p.Lifetime {
+		return FlushTime
+	}
+
+	return FlushNo
+}
+
+// AdaptiveFlushConfig sets the bounds an AdaptiveFlushPolicy tunes between.
+type AdaptiveFlushConfig struct {
+	// MinSize and MaxSize bound how large a segment grows before it's
+	// sealed. Low write pressure seals at MinSize, high pressure at MaxSize.
+	MinSize int
+	MaxSize int
+
+	// MinPending and MaxPending bound how long a write may wait in the open
+	// segment before it's flushed. Low write pressure flushes after
+	// MinPending, for durability, high pressure after MaxPending.
+	MinPending time.Duration
+	MaxPending time.Duration
+
+	// HighWriteRate is the write rate, in bytes per second, considered full
+	// pressure.
+	HighWriteRate float64
+
+	// TargetLatency is the write latency the policy aims to stay under.
+	// Flushing waits for the previous segment to be handed off, so latency
+	// above the target means flushes are backing up writes and the policy
+	// batches more. Latency at twice the target is full pressure.
+	TargetLatency time.Duration
+
+	// Window is the time constant over which the write rate and latency are
+	// averaged.
+	Window time.Duration
+}
+
+// DefaultAdaptiveFlushConfig returns bounds that keep segments between 8MB
+// and 64MB, and unflushed writes between one and ten minutes old.
+func DefaultAdaptiveFlushConfig() AdaptiveFlushConfig {
+	return AdaptiveFlushConfig{
+		MinSize:       8 * 1024 * 1024,
+		MaxSize:       64 * 1024 * 1024,
+		MinPending:    time.Minute,
+		MaxPending:    MaxSegmentLifetime,
+		HighWriteRate: 32 * 1024 * 1024,
+		TargetLatency: 50 * time.Millisecond,
+		Window:        10 * time.Second,
+	}
+}
+
+// AdaptiveFlushPolicy tunes when a segment is flushed based on write
+// pressure. Under light load segments are flushed small and soon, limiting
+// how much data sits unflushed. Under heavy load, or when write latency
+// exceeds its target, segments are allowed to grow larger and older so that
+// fewer flushes are made.
+type AdaptiveFlushPolicy struct {
+	cfg AdaptiveFlushConfig
+
+	mu        sync.Mutex
+	last      time.Time
+	lastWrite time.Time
+	bytes     float64 // exponentially decayed byte count over the window
+	latency   float64 // moving average of write latency, in seconds
+}
+
+// NewAdaptiveFlushPolicy creates a policy bounded by cfg. Zero fields of cfg
+// take the values from DefaultAdaptiveFlushConfig.
+func NewAdaptiveFlushPolicy(cfg AdaptiveFlushConfig) *AdaptiveFlushPolicy {
+	def := DefaultAdaptiveFlushConfig()
+
+	if cfg.MinSize <= 0 {
+		cfg.MinSize = def.MinSize
+	}
+	if cfg.MaxSize <= 0 {
+		cfg.MaxSize = def.MaxSize
+	}
+	if cfg.MaxSize < cfg.MinSize {
+		cfg.MaxSize = cfg.MinSize
+	}
+	if cfg.MinPending <= 0 {
+		cfg.MinPending = def.MinPending
+	}
+	if cfg.MaxPending <= 0 {
+		cfg.MaxPending = def.MaxPending
+	}
+	if cfg.MaxPending < cfg.MinPending {
+		cfg.MaxPending = cfg.MinPending
+	}
+	if cfg.HighWriteRate <= 0 {
+		cfg.HighWriteRate = def.HighWriteRate
+	}
+	if cfg.TargetLatency <= 0 {
+		cfg.TargetLatency = def.TargetLatency
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = def.Window
+	}
+
+	return &AdaptiveFlushPolicy{cfg: cfg}
+}
+
+// decay ages the averages to now. Callers must hold mu.
+func (p *AdaptiveFlushPolicy) decay(now time.Time) {
+	if p.last.IsZero() {
+		p.last = now
+		return
+	}
+
+	dt := now.Sub(p.last)
+	if dt <= 0 {
+		return
+	}
+
+	p.bytes *= math.Exp(-dt.Seconds() / p.cfg.Window.Seconds())
+	p.last = now
+}
+
+func (p *AdaptiveFlushPolicy) ObserveWrite(now time.Time, bytes int, latency time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.decay(now)
+	p.lastWrite = now
+
+	p.bytes += float64(bytes)
+
+	// Latency is a moving average, weighted so that a sustained change
+	// shows up within a few writes.
+	const alpha = 0.2
+	p.latency += alpha * (latency.Seconds() - p.latency)
+}
+
+// Pressure returns the current write pressure, between 0 and 1.
+func (p *AdaptiveFlushPolicy) Pressure(now time.Time) float64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.pressure(now)
+}
+
+func (p *AdaptiveFlushPolicy) pressure(now time.Time) float64 {
+	p.decay(now)
+
+	rate := p.bytes / p.cfg.Window.Seconds()
+
+	ratePressure := rate / p.cfg.HighWriteRate
+
+	// Latency only says something about the current load while writes are
+	// being made.
+	var latencyPressure float64
+	if now.Sub(p.lastWrite) <= p.cfg.Window {
+		latencyPressure = p.latency/p.cfg.TargetLatency.Seconds() - 1
+	}
+
+	return math.Max(0, math.Min(1, math.Max(ratePressure, latencyPressure)))
+}
+
+// Thresholds returns the size and pending time at which a segment is
+// currently flushed.
+func (p *AdaptiveFlushPolicy) Thresholds(now time.Time) (int, time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.thresholds(p.pressure(now))
+}
+
+func (p *AdaptiveFlushPolicy) thresholds(pressure float64) (int, time.Duration) {
+	size := p.cfg.MinSize + int(pressure*float64(p.cfg.MaxSize-p.cfg.MinSize))
+	pending := p.cfg.MinPending + time.Duration(pressure*float64(p.cfg.MaxPending-p.cfg.MinPending))
+	return size, pending
+}
+
+func (p *AdaptiveFlushPolicy) ShouldFlush(now time.Time, seg OpenSegmentInfo) FlushReason {
+	p.mu.Lock()
+	pressure := p.pressure(now)
+	size, pending := p.thresholds(pressure)
+	p.mu.Unlock()
+
+	flushPressure.Set(pressure)
+	flushSizeThreshold.Set(float64(size))
+	flushPendingThreshold.Set(pending.Seconds())
+
+	if seg.BodySize >= size {
+		return FlushSize
+	}
+
+	if seg.Pending >= pending {
+		return FlushTime
+	}
+
+	return FlushNo
+}
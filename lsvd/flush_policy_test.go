@@ -0,0 +1,271 @@
+package lsvd
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// flushWorkload generates writes for a flush policy simulation. It returns
+// the size of the write made at offset, or 0 for none.
+type flushWorkload func(offset time.Duration) int
+
+func steadyWorkload(every time.Duration, size int) flushWorkload {
+	return func(offset time.Duration) int {
+		if offset%every == 0 {
+			return size
+		}
+		return 0
+	}
+}
+
+// burstyWorkload alternates between heavy bursts and a quiet trickle.
+func burstyWorkload(burst, quiet time.Duration) flushWorkload {
+	heavy := steadyWorkload(10*time.Millisecond, 1024*1024)
+	light := steadyWorkload(time.Second, 4*1024)
+
+	return func(offset time.Duration) int {
+		if offset%(burst+quiet) < burst {
+			return heavy(offset)
+		}
+		return light(offset)
+	}
+}
+
+type flushSimResult struct {
+	flushes    int
+	maxPending time.Duration // longest a write waited to be flushed
+	blocked    time.Duration // total time writes waited on earlier flushes
+	avgSize    int
+}
+
+func (r flushSimResult) String() string {
+	return fmt.Sprintf("flushes=%d avg-size=%dKB max-pending=%s blocked=%s",
+		r.flushes, r.avgSize/1024, r.maxPending, r.blocked)
+}
+
+// simulateFlush runs policy against a workload on a simulated clock. Each
+// flush takes a fixed overhead plus time proportional to its size, and, as
+// in Disk, sealing a segment blocks the writer until the previous one has
+// been handed off.
+func simulateFlush(policy FlushPolicy, workload flushWorkload, duration time.Duration) flushSimResult {
+	const (
+		step      = time.Millisecond
+		overhead  = 500 * time.Millisecond
+		bandwidth = 200 * 1024 * 1024 // bytes per second
+		tick      = time.Second
+	)
+
+	var (
+		res        flushSimResult
+		start      = time.Unix(0, 0)
+		busyUntil  time.Time
+		openedAt   = start
+		firstWrite time.Time
+		size       int
+		total      int
+	)
+
+	check := func(now time.Time) time.Duration {
+		if size == 0 {
+			return 0
+		}
+
+		info := OpenSegmentInfo{
+			BodySize: size,
+			Age:      now.Sub(openedAt),
+			Pending:  now.Sub(firstWrite),
+		}
+
+		if policy.ShouldFlush(now, info) == FlushNo {
+			return 0
+		}
+
+		var wait time.Duration
+		if now.Before(busyUntil) {
+			wait = busyUntil.Sub(now)
+		}
+
+		res.flushes++
+		res.maxPending = max(res.maxPending, info.Pending+wait)
+		total += size
+
+		busyUntil = now.Add(wait + overhead + time.Duration(float64(size)/bandwidth*float64(time.Second)))
+		openedAt = now.Add(wait)
+		firstWrite = time.Time{}
+		size = 0
+
+		return wait
+	}
+
+	for offset := time.Duration(0); offset < duration; offset += step {
+		now := start.Add(offset)
+
+		if n := workload(offset); n > 0 {
+			if firstWrite.IsZero() {
+				firstWrite = now
+			}
+			size += n
+
+			latency := time.Millisecond + check(now)
+			res.blocked += latency - time.Millisecond
+
+			policy.ObserveWrite(now, n, latency)
+		} else if offset%tick == 0 {
+			check(now)
+		}
+	}
+
+	if size > 0 {
+		res.maxPending = max(res.maxPending, start.Add(duration).Sub(firstWrite))
+	}
+
+	if res.flushes > 0 {
+		res.avgSize = total / res.flushes
+	}
+
+	return res
+}
+
+func TestAdaptiveFlushPolicy(t *testing.T) {
+	t.Run("pressure follows write rate and latency", func(t *testing.T) {
+		r := require.New(t)
+
+		p := NewAdaptiveFlushPolicy(AdaptiveFlushConfig{
+			HighWriteRate: 10 * 1024 * 1024,
+			TargetLatency: 10 * time.Millisecond,
+			Window:        time.Second,
+		})
+
+		now := time.Unix(0, 0)
+		r.Zero(p.Pressure(now))
+
+		size, pending := p.Thresholds(now)
+		r.Equal(8*1024*1024, size)
+		r.Equal(time.Minute, pending)
+
+		// 20MB/s over a 1s window is beyond the high rate.
+		for i := 0; i < 200; i++ {
+			now = now.Add(10 * time.Millisecond)
+			p.ObserveWrite(now, 200*1024, time.Millisecond)
+		}
+
+		r.Equal(1.0, p.Pressure(now))
+
+		size, pending = p.Thresholds(now)
+		r.Equal(64*1024*1024, size)
+		r.Equal(MaxSegmentLifetime, pending)
+
+		// Pressure falls away once writes stop.
+		now = now.Add(10 * time.Second)
+		r.Less(p.Pressure(now), 0.01)
+
+		// Slow writes raise pressure even at a low rate.
+		for i := 0; i < 20; i++ {
+			now = now.Add(100 * time.Millisecond)
+			p.ObserveWrite(now, 4096, 25*time.Millisecond)
+		}
+
+		r.Greater(p.Pressure(now), 0.9)
+	})
+
+	t.Run("flushes by size or pending age", func(t *testing.T) {
+		r := require.New(t)
+
+		p := NewAdaptiveFlushPolicy(AdaptiveFlushConfig{})
+		now := time.Unix(0, 0)
+
+		r.Equal(FlushNo, p.ShouldFlush(now, OpenSegmentInfo{BodySize: 1024, Age: time.Hour, Pending: time.Second}))
+		r.Equal(FlushSize, p.ShouldFlush(now, OpenSegmentInfo{BodySize: 8 * 1024 * 1024}))
+		r.Equal(FlushTime, p.ShouldFlush(now, OpenSegmentInfo{BodySize: 1024, Pending: time.Minute}))
+	})
+
+	t.Run("fills in unset bounds", func(t *testing.T) {
+		r := require.New(t)
+
+		p := NewAdaptiveFlushPolicy(AdaptiveFlushConfig{MinSize: 128 * 1024 * 1024})
+		r.Equal(128*1024*1024, p.cfg.MaxSize)
+		r.Equal(DefaultAdaptiveFlushConfig().Window, p.cfg.Window)
+	})
+}
+
+func TestAdaptiveFlushVsFixed(t *testing.T) {
+	workloads := []struct {
+		name     string
+		workload flushWorkload
+		duration time.Duration
+	}{
+		{"steady light", steadyWorkload(time.Second, 16*1024), 30 * time.Minute},
+		{"steady heavy", steadyWorkload(10*time.Millisecond, 512*1024), 2 * time.Minute},
+		{"bursty", burstyWorkload(15*time.Second, 3*time.Minute), 30 * time.Minute},
+	}
+
+	results := map[string][2]flushSimResult{}
+
+	for _, w := range workloads {
+		fixed := simulateFlush(DefaultFlushPolicy(), w.workload, w.duration)
+		adaptive := simulateFlush(NewAdaptiveFlushPolicy(DefaultAdaptiveFlushConfig()), w.workload, w.duration)
+
+		t.Logf("%-12s fixed:    %s", w.name, fixed)
+		t.Logf("%-12s adaptive: %s", w.name, adaptive)
+
+		results[w.name] = [2]flushSimResult{fixed, adaptive}
+	}
+
+	t.Run("light load flushes sooner", func(t *testing.T) {
+		fixed, adaptive := results["steady light"][0], results["steady light"][1]
+
+		require.Greater(t, fixed.maxPending, 9*time.Minute)
+		require.Less(t, adaptive.maxPending, 2*time.Minute)
+	})
+
+	t.Run("heavy load batches more", func(t *testing.T) {
+		fixed, adaptive := results["steady heavy"][0], results["steady heavy"][1]
+
+		require.Greater(t, adaptive.avgSize, fixed.avgSize)
+		require.Less(t, adaptive.flushes, fixed.flushes)
+		require.Less(t, adaptive.blocked, fixed.blocked)
+	})
+
+	t.Run("bursts batch while quiet periods stay fresh", func(t *testing.T) {
+		fixed, adaptive := results["bursty"][0], results["bursty"][1]
+
+		require.Less(t, adaptive.maxPending, fixed.maxPending)
+		require.LessOrEqual(t, adaptive.blocked, fixed.blocked)
+	})
+}
+
+func BenchmarkFlushPolicy(b *testing.B) {
+	policies := []struct {
+		name string
+		new  func() FlushPolicy
+	}{
+		{"fixed", func() FlushPolicy { return DefaultFlushPolicy() }},
+		{"adaptive", func() FlushPolicy { return NewAdaptiveFlushPolicy(DefaultAdaptiveFlushConfig()) }},
+	}
+
+	workloads := []struct {
+		name     string
+		workload flushWorkload
+	}{
+		{"steady", steadyWorkload(10*time.Millisecond, 512*1024)},
+		{"bursty", burstyWorkload(15*time.Second, 45*time.Second)},
+	}
+
+	for _, w := range workloads {
+		for _, p := range policies {
+			b.Run(w.name+"/"+p.name, func(b *testing.B) {
+				var res flushSimResult
+				for i := 0; i < b.N; i++ {
+					res = simulateFlush(p.new(), w.workload, 2*time.Minute)
+				}
+
+				b.ReportMetric(float64(res.flushes), "flushes")
+				b.ReportMetric(res.blocked.Seconds(), "blocked-s")
+				b.ReportMetric(res.maxPending.Seconds(), "max-pending-s")
+			})
+		}
+	}
+}
@@ -374,6 +374,11 @@ func (d *Disk) CopyIterator(ctx context.Context, seg SegmentId) (*CopyIterator,
 }
 
 func (d *Disk) removeSegmentIfPossible(ctx context.Context, seg SegmentId) error {
+	if d.deferIfPinned(seg) {
+		d.log.Info("deferring removal of segment held by a snapshot", "segment", seg)
+		return nil
+	}
+
 	volumes, err := d.sa.ListVolumes(ctx)
 	if err != nil {
 		return err
@@ -35,6 +35,16 @@ func (l *LocalFile) Close() error {
 	return l.f.Close()
 }
 
+// Size returns the size of the segment.
+func (l *LocalFile) Size() (int64, error) {
+	fi, err := l.f.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	return fi.Size(), nil
+}
+
 func (l *LocalFile) Layout(_ context.Context) (*SegmentLayout, error) {
 	f, err := os.Open(l.f.Name() + ".layout.cbor")
 	if err != nil {
@@ -67,12 +77,25 @@ type LocalVolume struct {
 	Log  *slog.Logger
 	Dir  string
 	Name string
+
+	// backing is the directory under volumes/ holding this volume's data,
+	// resolved through the superblock when the volume was opened.
+	backing string
+}
+
+func (l *LocalVolume) volumeDir() string {
+	backing := l.backing
+	if backing == "" {
+		backing = l.Name
+	}
+
+	return filepath.Join(l.Dir, "volumes", backing)
 }
 
 var _ Volume = (*LocalVolume)(nil)
 
 func (l *LocalVolume) Info(_ context.Context) (*VolumeInfo, error) {
-	f, err := os.Open(filepath.Join(l.Dir, "volumes", l.Name, "info.json"))
+	f, err := os.Open(filepath.Join(l.volumeDir(), "info.json"))
 	if err != nil {
 		return nil, err
 	}
@@ -85,11 +108,14 @@ func (l *LocalVolume) Info(_ context.Context) (*VolumeInfo, error) {
 		return nil, err
 	}
 
+	// The backing data may have been swapped in from another volume.
+	vi.Name = l.Name
+
 	return &vi, nil
 }
 
 func (l *LocalVolume) ListSegments(_ context.Context) ([]SegmentId, error) {
-	f, err := os.Open(filepath.Join(l.Dir, "volumes", l.Name, "segments"))
+	f, err := os.Open(filepath.Join(l.volumeDir(), "segments"))
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
 			return nil, nil
@@ -147,7 +173,7 @@ func (l *LocalVolume) AppendSegment(ctx context.Context, seg SegmentId, f *os.Fi
 		return err
 	}
 
-	path = filepath.Join(l.Dir, "volumes", l.Name, "segments")
+	path = filepath.Join(l.volumeDir(), "segments")
 
 	segments = append(segments, seg)
 
@@ -175,7 +201,7 @@ func (l *LocalVolume) AppendSegment(ctx context.Context, seg SegmentId, f *os.Fi
 func (l *LocalVolume) RemoveSegment(_ context.Context, seg SegmentId) error {
 	var buf bytes.Buffer
 
-	segmentsPath := filepath.Join(l.Dir, "volumes", l.Name, "segments")
+	segmentsPath := filepath.Join(l.volumeDir(), "segments")
 	f, err := os.OpenFile(segmentsPath, os.O_RDONLY, 0644)
 	if err != nil {
 		return err
@@ -220,10 +246,16 @@ type LocalFileAccess struct {
 var _ SegmentAccess = (*LocalFileAccess)(nil)
 
 func (l *LocalFileAccess) OpenVolume(ctx context.Context, vol string) (Volume, error) {
+	sb, err := l.readSuperblock()
+	if err != nil {
+		return nil, err
+	}
+
 	return &LocalVolume{
-		Log:  l.Log,
-		Dir:  l.Dir,
-		Name: vol,
+		Log:     l.Log,
+		Dir:     l.Dir,
+		Name:    vol,
+		backing: sb.backing(vol),
 	}, nil
 }
 
@@ -255,7 +287,12 @@ func ReadSegmentList(f io.Reader) ([]SegmentId, error) {
 }
 
 func (l *LocalFileAccess) ListSegments(ctx context.Context, vol string) ([]SegmentId, error) {
-	f, err := os.Open(filepath.Join(l.Dir, "volumes", vol, "segments"))
+	dir, err := l.volumeDir(vol)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(filepath.Join(dir, "segments"))
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
 			return nil, nil
@@ -270,12 +307,22 @@ func (l *LocalFileAccess) ListSegments(ctx context.Context, vol string) ([]Segme
 }
 
 func (l *LocalFileAccess) WriteMetadata(ctx context.Context, vol, name string) (io.WriteCloser, error) {
-	f, err := os.Create(filepath.Join(l.Dir, "volumes", vol, name))
+	dir, err := l.volumeDir(vol)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Create(filepath.Join(dir, name))
 	return f, err
 }
 
 func (l *LocalFileAccess) ReadMetadata(ctx context.Context, vol, name string) (io.ReadCloser, error) {
-	f, err := os.Open(filepath.Join(l.Dir, "volumes", vol, name))
+	dir, err := l.volumeDir(vol)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(filepath.Join(dir, name))
 	return f, err
 }
 
@@ -325,12 +372,17 @@ func (l *LocalFileAccess) UploadSegment(ctx context.Context, seg SegmentId, f *o
 }
 
 func (l *LocalFileAccess) AppendToSegments(ctx context.Context, vol string, seg SegmentId) error {
+	dir, err := l.volumeDir(vol)
+	if err != nil {
+		return err
+	}
+
 	segments, err := l.ListSegments(ctx, vol)
 	if err != nil {
 		return err
 	}
 
-	path := filepath.Join(l.Dir, "volumes", vol, "segments")
+	path := filepath.Join(dir, "segments")
 
 	segments = append(segments, seg)
 
@@ -356,9 +408,14 @@ func (l *LocalFileAccess) AppendToSegments(ctx context.Context, vol string, seg
 }
 
 func (l *LocalFileAccess) RemoveSegmentFromVolume(ctx context.Context, vol string, seg SegmentId) error {
+	dir, err := l.volumeDir(vol)
+	if err != nil {
+		return err
+	}
+
 	var buf bytes.Buffer
 
-	segmentsPath := filepath.Join(l.Dir, "volumes", vol, "segments")
+	segmentsPath := filepath.Join(dir, "segments")
 	f, err := os.OpenFile(segmentsPath, os.O_RDONLY, 0644)
 	if err != nil {
 		return err
@@ -409,7 +466,7 @@ func (l *LocalFileAccess) InitContainer(ctx context.Context) error {
 		}
 	}
 
-	return nil
+	return l.removeStagedSuperblocks()
 }
 
 func (l *LocalFileAccess) InitVolume(ctx context.Context, vol *VolumeInfo) error {
@@ -417,9 +474,12 @@ func (l *LocalFileAccess) InitVolume(ctx context.Context, vol *VolumeInfo) error
 		return fmt.Errorf("volume name must not be empty")
 	}
 
-	path := filepath.Join(l.Dir, "volumes", vol.Name)
+	path, err := l.volumeDir(vol.Name)
+	if err != nil {
+		return err
+	}
 
-	_, err := os.Stat(path)
+	_, err = os.Stat(path)
 	if err == nil {
 		return nil
 	}
@@ -458,7 +518,12 @@ func (l *LocalFileAccess) ListVolumes(ctx context.Context) ([]string, error) {
 }
 
 func (l *LocalFileAccess) GetVolumeInfo(ctx context.Context, vol string) (*VolumeInfo, error) {
-	f, err := os.Open(filepath.Join(l.Dir, "volumes", vol, "info.json"))
+	dir, err := l.volumeDir(vol)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(filepath.Join(dir, "info.json"))
 	if err != nil {
 		return nil, err
 	}
@@ -471,5 +536,7 @@ func (l *LocalFileAccess) GetVolumeInfo(ctx context.Context, vol string) (*Volum
 		return nil, err
 	}
 
+	vi.Name = vol
+
 	return &vi, nil
 }
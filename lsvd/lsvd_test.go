@@ -1358,6 +1358,292 @@ func TestLSVD(t *testing.T) {
 		r.True(isEmpty(data))
 	})
 
+	t.Run("secure erase overwrites all written blocks", func(t *testing.T) {
+		r := require.New(t)
+
+		tmpdir, err := os.MkdirTemp("", "lsvd")
+		r.NoError(err)
+		defer os.RemoveAll(tmpdir)
+
+		d, err := NewDisk(ctx, log, tmpdir)
+		r.NoError(err)
+		defer d.Close(ctx)
+
+		err = d.WriteExtent(ctx, testRandX.MapTo(0))
+		r.NoError(err)
+
+		err = d.WriteExtent(ctx, testExtent.MapTo(10))
+		r.NoError(err)
+
+		r.NoError(d.CloseSegment(ctx))
+
+		err = d.WriteExtent(ctx, testExtent2.MapTo(20))
+		r.NoError(err)
+
+		var progress []EraseProgress
+
+		res, err := d.SecureErase(ctx, SecureEraseOptions{
+			Mode: EraseOverwrite,
+			Progress: func(p EraseProgress) {
+				progress = append(progress, p)
+			},
+		})
+		r.NoError(err)
+
+		r.Equal(uint64(3), res.ErasedBlocks)
+		r.Equal(2, res.RemovedSegments)
+
+		r.NotEmpty(progress)
+		r.Equal(EraseProgress{ErasedBlocks: 3, TotalBlocks: 3}, progress[len(progress)-1])
+
+		for _, lba := range []LBA{0, 10, 20} {
+			data, err := d.ReadExtent(ctx, Extent{LBA: lba, Blocks: 1})
+			r.NoError(err)
+
+			r.True(isEmpty(data.RawBlocks().BlockView(0)), "block %d not erased", lba)
+		}
+
+		segments, err := d.volume.ListSegments(ctx)
+		r.NoError(err)
+		r.Len(segments, 1, "only the segment of zeroes should remain")
+	})
+
+	t.Run("discarded blocks read back as zero after reopen", func(t *testing.T) {
+		r := require.New(t)
+
+		tmpdir, err := os.MkdirTemp("", "lsvd")
+		r.NoError(err)
+		defer os.RemoveAll(tmpdir)
+
+		d, err := NewDisk(ctx, log, tmpdir)
+		r.NoError(err)
+
+		err = d.WriteExtent(ctx, testRandX.MapTo(0))
+		r.NoError(err)
+
+		// Past MaxBlocks, so the discard has to be split up
+		far := LBA(MaxBlocks + 5)
+
+		err = d.WriteExtent(ctx, testExtent.MapTo(far))
+		r.NoError(err)
+
+		err = d.Discard(ctx, 0, MaxBlocks+10)
+		r.NoError(err)
+
+		err = d.WriteExtent(ctx, testExtent2.MapTo(3))
+		r.NoError(err)
+
+		r.NoError(d.Close(ctx))
+
+		d, err = NewDisk(ctx, log, tmpdir)
+		r.NoError(err)
+		defer d.Close(ctx)
+
+		for _, lba := range []LBA{0, far} {
+			data, err := d.ReadExtent(ctx, Extent{LBA: lba, Blocks: 1})
+			r.NoError(err)
+
+			r.True(isEmpty(data.RawBlocks().BlockView(0)), "block %d not discarded", lba)
+		}
+
+		data, err := d.ReadExtent(ctx, Extent{LBA: 3, Blocks: 1})
+		r.NoError(err)
+		extentEqual(t, testExtent2, data)
+	})
+
+	t.Run("discard fails on a read-only disk", func(t *testing.T) {
+		r := require.New(t)
+
+		tmpdir, err := os.MkdirTemp("", "lsvd")
+		r.NoError(err)
+		defer os.RemoveAll(tmpdir)
+
+		d, err := NewDisk(ctx, log, tmpdir, ReadOnly())
+		r.NoError(err)
+		defer d.Close(ctx)
+
+		r.ErrorIs(d.Discard(ctx, 0, 1), ErrReadOnly)
+	})
+
+	t.Run("verify checks every segment", func(t *testing.T) {
+		r := require.New(t)
+
+		tmpdir, err := os.MkdirTemp("", "lsvd")
+		r.NoError(err)
+		defer os.RemoveAll(tmpdir)
+
+		d, err := NewDisk(ctx, log, tmpdir)
+		r.NoError(err)
+
+		err = d.WriteExtent(ctx, testRandX.MapTo(0))
+		r.NoError(err)
+
+		r.NoError(d.CloseSegment(ctx))
+
+		err = d.WriteExtent(ctx, testExtent.MapTo(10))
+		r.NoError(err)
+
+		r.NoError(d.Close(ctx))
+
+		res, err := VerifyVolume(ctx, log, d.volume)
+		r.NoError(err)
+
+		r.True(res.OK(), "unexpected problems: %v", res.Problems)
+		r.Len(res.Segments, 2)
+		r.Greater(res.Bytes, int64(BlockSize))
+	})
+
+	t.Run("verify reports a truncated segment and repair drops it", func(t *testing.T) {
+		r := require.New(t)
+
+		tmpdir, err := os.MkdirTemp("", "lsvd")
+		r.NoError(err)
+		defer os.RemoveAll(tmpdir)
+
+		d, err := NewDisk(ctx, log, tmpdir)
+		r.NoError(err)
+
+		err = d.WriteExtent(ctx, testRandX.MapTo(0))
+		r.NoError(err)
+
+		r.NoError(d.CloseSegment(ctx))
+
+		err = d.WriteExtent(ctx, testRandX.MapTo(10))
+		r.NoError(err)
+
+		r.NoError(d.Close(ctx))
+
+		segments, err := d.volume.ListSegments(ctx)
+		r.NoError(err)
+		r.Len(segments, 2)
+
+		last := segments[1]
+
+		path := filepath.Join(tmpdir, "segments", "segment."+last.String())
+
+		hdr, err := ReadSegmentHeader(path)
+		r.NoError(err)
+
+		r.NoError(os.Truncate(path, int64(hdr.DataOffset)+10))
+
+		res, err := VerifyVolume(ctx, log, d.volume)
+		r.NoError(err)
+
+		r.False(res.OK())
+		r.False(res.Bad(segments[0]))
+		r.True(res.Bad(last))
+		r.Equal(int64(hdr.DataOffset)+10, res.Problems[0].Offset)
+
+		// Without its write cache, the segment's writes would be lost.
+		removed, err := RepairVolume(ctx, log, d.volume, tmpdir, res)
+		r.NoError(err)
+		r.Empty(removed)
+
+		r.NoError(os.WriteFile(filepath.Join(tmpdir, "writecache."+last.String()), nil, 0644))
+
+		removed, err = RepairVolume(ctx, log, d.volume, tmpdir, res)
+		r.NoError(err)
+		r.Equal([]SegmentId{last}, removed)
+
+		res, err = VerifyVolume(ctx, log, d.volume)
+		r.NoError(err)
+
+		r.True(res.OK(), "unexpected problems: %v", res.Problems)
+		r.Len(res.Segments, 1)
+	})
+
+	t.Run("snapshots don't see later writes", func(t *testing.T) {
+		r := require.New(t)
+
+		tmpdir, err := os.MkdirTemp("", "lsvd")
+		r.NoError(err)
+		defer os.RemoveAll(tmpdir)
+
+		d, err := NewDisk(ctx, log, tmpdir)
+		r.NoError(err)
+		defer d.Close(ctx)
+
+		err = d.WriteExtent(ctx, testRandX.MapTo(0))
+		r.NoError(err)
+
+		err = d.WriteExtent(ctx, testExtent.MapTo(1))
+		r.NoError(err)
+
+		snap, err := d.Snapshot(ctx)
+		r.NoError(err)
+
+		// Overwrite everything and pack, leaving the snapshot's segment dead
+		err = d.WriteExtent(ctx, testExtent2.MapTo(0))
+		r.NoError(err)
+
+		err = d.ZeroBlocks(ctx, Extent{1, 1})
+		r.NoError(err)
+
+		err = d.WriteExtent(ctx, testExtent3.MapTo(2))
+		r.NoError(err)
+
+		r.NoError(d.Pack(ctx))
+
+		// Drops the packed segments from the volume
+		r.NoError(d.CloseSegment(ctx))
+
+		buf := make([]byte, 3*BlockSize)
+
+		n, err := snap.ReadAt(buf, 0)
+		r.NoError(err)
+		r.Equal(len(buf), n)
+
+		blockEqual(t, testRandX.BlockView(0), buf[:BlockSize])
+		blockEqual(t, testExtent.BlockView(0), buf[BlockSize:2*BlockSize])
+		r.True(isEmpty(buf[2*BlockSize:]))
+
+		data, err := d.ReadExtent(ctx, Extent{LBA: 0, Blocks: 1})
+		r.NoError(err)
+		extentEqual(t, testExtent2, data)
+
+		// The segment is gone from the volume, but its data is kept for the
+		// snapshot
+		r.Len(d.deferredRemoval, 1)
+
+		var held string
+		for seg := range d.deferredRemoval {
+			held = filepath.Join(tmpdir, "segments", "segment."+ulid.ULID(seg).String())
+		}
+
+		r.FileExists(held)
+
+		snap.Release()
+
+		_, err = snap.ReadAt(buf, 0)
+		r.ErrorIs(err, ErrSnapshotReleased)
+
+		r.Empty(d.pinnedSegments)
+		r.Empty(d.deferredRemoval)
+		r.NoFileExists(held, "segment should be removed once the snapshot is released")
+	})
+
+	t.Run("secure erase can't crypto-shred an unencrypted volume", func(t *testing.T) {
+		r := require.New(t)
+
+		tmpdir, err := os.MkdirTemp("", "lsvd")
+		r.NoError(err)
+		defer os.RemoveAll(tmpdir)
+
+		d, err := NewDisk(ctx, log, tmpdir)
+		r.NoError(err)
+		defer d.Close(ctx)
+
+		err = d.WriteExtent(ctx, testRandX.MapTo(0))
+		r.NoError(err)
+
+		_, err = d.SecureErase(ctx, SecureEraseOptions{Mode: EraseCryptoShred})
+		r.ErrorIs(err, ErrNotEncrypted)
+
+		data, err := d.ReadExtent(ctx, Extent{LBA: 0, Blocks: 1})
+		r.NoError(err)
+		blockEqual(t, testRandX.BlockView(0), data.RawBlocks().BlockView(0))
+	})
+
 	t.Run("can use the write cache while currently uploading", func(t *testing.T) {
 		r := require.New(t)
 
@@ -1,12 +1,31 @@
 package lve
 
-import "path/filepath"
+import (
+	"path/filepath"
+	"time"
+)
 
 type CommonOptions struct {
 	WorkDir  string
 	CPUs     int
 	MemoryMB int
 
+	// Sockets, Cores and Threads lay the CPUs out for the guest, and must
+	// multiply to CPUs when set. Left at zero, the guest sees one socket
+	// with a core per CPU.
+	Sockets int
+	Cores   int
+	Threads int
+
+	// Balloon adds a memory balloon, so the host can reclaim memory the
+	// guest isn't using.
+	Balloon bool
+
+	// NestedVirt passes the host's virtualization extensions through, so
+	// the guest can run VMs of its own. The host's kvm module must have
+	// nested virtualization enabled.
+	NestedVirt bool
+
 	UserNat bool
 	MacVtap string
 
@@ -18,6 +37,15 @@ type CommonOptions struct {
 
 	ConfigMount string
 	HostMount   string
+
+	// QMPSocket is the unix socket qemu listens on for QMP commands, which
+	// Shutdown uses to power the guest down cleanly. Without one, Shutdown
+	// can only kill qemu.
+	QMPSocket string
+
+	// ShutdownTimeout is how long Shutdown waits for the guest to power
+	// down before killing qemu. It defaults to 30 seconds.
+	ShutdownTimeout time.Duration
 }
 
 func (co *CommonOptions) MemoryFile() string {
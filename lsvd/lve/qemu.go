@@ -2,19 +2,45 @@ package lve
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"runtime"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"log/slog"
+
+	"miren.dev/runtime/lsvd/lve/pkg/qmp"
+)
+
+var (
+	hostCPUs = runtime.NumCPU
+
+	// kvmModules is where the kvm modules expose their parameters.
+	kvmModules = "/sys/module"
 )
 
+const defaultShutdownTimeout = 30 * time.Second
+
 type QemuInstance struct {
 	options []string
 
 	filesToOpen []string
+
+	log             *slog.Logger
+	qmpSocket       string
+	shutdownTimeout time.Duration
+
+	mu      sync.Mutex
+	proc    *os.Process
+	done    chan struct{}
+	waitErr error
 }
 
 func (q *QemuInstance) openFile(path string) int {
@@ -25,18 +51,94 @@ func (q *QemuInstance) openFile(path string) int {
 	return idx
 }
 
+// smp returns the -smp value for the options' CPUs and their topology,
+// checking the host has that many CPUs to give.
+func smp(co *CommonOptions) (string, error) {
+	cpus := co.CPUs
+
+	sockets, cores, threads := co.Sockets, co.Cores, co.Threads
+	if sockets == 0 && cores == 0 && threads == 0 {
+		sockets, cores, threads = 1, cpus, 1
+	}
+
+	if sockets <= 0 || cores <= 0 || threads <= 0 {
+		return "", fmt.Errorf("invalid cpu topology: %d sockets, %d cores, %d threads", co.Sockets, co.Cores, co.Threads)
+	}
+
+	if topo := sockets * cores * threads; cpus == 0 {
+		cpus = topo
+	} else if topo != cpus {
+		return "", fmt.Errorf("cpu topology of %d sockets, %d cores and %d threads is %d cpus, not %d", sockets, cores, threads, topo, cpus)
+	}
+
+	if cpus <= 0 {
+		return "", fmt.Errorf("invalid number of cpus: %d", cpus)
+	}
+
+	if host := hostCPUs(); cpus > host {
+		return "", fmt.Errorf("requested %d cpus, but the host only has %d", cpus, host)
+	}
+
+	return fmt.Sprintf("cpus=%d,sockets=%d,cores=%d,threads=%d", cpus, sockets, cores, threads), nil
+}
+
+// cpuModel returns the -cpu value, adding the host's virtualization
+// extension when nested virtualization is asked for.
+func cpuModel(co *CommonOptions) (string, error) {
+	if !co.NestedVirt {
+		return "host", nil
+	}
+
+	for _, ext := range []struct{ module, flag string }{
+		{"kvm_intel", "vmx"},
+		{"kvm_amd", "svm"},
+	} {
+		data, err := os.ReadFile(filepath.Join(kvmModules, ext.module, "parameters", "nested"))
+		if err != nil {
+			continue
+		}
+
+		switch strings.TrimSpace(string(data)) {
+		case "Y", "y", "1":
+			return "host,+" + ext.flag, nil
+		default:
+			return "", fmt.Errorf("nested virtualization is disabled in %s, set its nested parameter to enable it", ext.module)
+		}
+	}
+
+	return "", fmt.Errorf("nested virtualization needs the kvm_intel or kvm_amd module loaded")
+}
+
 func (q *QemuInstance) setupOptions(log *slog.Logger, co *CommonOptions) error {
 	s := strconv.Itoa
 	sf := fmt.Sprintf
 
+	cpus, err := smp(co)
+	if err != nil {
+		return err
+	}
+
 	q.options = append(q.options,
-		"-smp", s(co.CPUs),
+		"-smp", cpus,
 		"-m", s(co.MemoryMB),
 		"-object", sf("memory-backend-file,id=ram,size=%dM,mem-path=%s,prealloc=on,share=on", co.MemoryMB, co.MemoryFile()),
 		"-numa", "node,memdev=ram",
 		"-device", sf("virtio-pstore,directory=%s/logs", co.WorkDir),
 	)
 
+	if co.Balloon {
+		q.options = append(q.options,
+			"-device", "virtio-balloon-pci,id=balloon0,deflate-on-oom=on",
+		)
+	}
+
+	if co.QMPSocket != "" {
+		q.options = append(q.options,
+			"-chardev", sf("socket,id=mon0,path=%s,server=on,wait=off", co.QMPSocket),
+			"-mon", "mon0,mode=control",
+		)
+	}
+
 	if co.UserNat {
 		q.options = append(q.options,
 			"-nic", "user",
@@ -79,19 +181,37 @@ func (q *QemuInstance) setupOptions(log *slog.Logger, co *CommonOptions) error {
 	return nil
 }
 
-func (q *QemuInstance) Start(ctx context.Context, log *slog.Logger, co *CommonOptions) error {
+func (q *QemuInstance) buildOptions(log *slog.Logger, co *CommonOptions) error {
+	cpu, err := cpuModel(co)
+	if err != nil {
+		return err
+	}
+
 	q.options = append(q.options,
-		"-cpu", "host",
+		"-cpu", cpu,
 		"-global", "ide-hd.physical_block_size=4096",
 	)
 
-	err := q.setupOptions(log, co)
+	err = q.setupOptions(log, co)
 	if err != nil {
 		return err
 	}
 
 	q.options = append(q.options, "-vnc", "0.0.0.0:11")
 
+	return nil
+}
+
+func (q *QemuInstance) Start(ctx context.Context, log *slog.Logger, co *CommonOptions) error {
+	err := q.buildOptions(log, co)
+	if err != nil {
+		return err
+	}
+
+	q.log = log
+	q.qmpSocket = co.QMPSocket
+	q.shutdownTimeout = co.ShutdownTimeout
+
 	cmd := exec.CommandContext(ctx, "kvm", q.options...)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
@@ -106,5 +226,121 @@ func (q *QemuInstance) Start(ctx context.Context, log *slog.Logger, co *CommonOp
 		cmd.ExtraFiles = append(cmd.ExtraFiles, fd)
 	}
 
-	return cmd.Run()
+	err = q.start(cmd)
+	if err != nil {
+		return err
+	}
+
+	<-q.done
+
+	return q.waitErr
+}
+
+// start runs cmd as the instance's qemu, closing q.done once it exits.
+func (q *QemuInstance) start(cmd *exec.Cmd) error {
+	err := cmd.Start()
+	if err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+
+	q.mu.Lock()
+	q.proc = cmd.Process
+	q.done = done
+	q.mu.Unlock()
+
+	go func() {
+		q.waitErr = cmd.Wait()
+		close(done)
+	}()
+
+	return nil
+}
+
+// Shutdown asks the guest to power down via ACPI, so it can flush its
+// filesystems and close its volumes, and waits for qemu to exit. If the
+// guest can't be asked or doesn't power down within the shutdown timeout,
+// qemu is killed and an error returned saying why.
+func (q *QemuInstance) Shutdown(ctx context.Context) error {
+	q.mu.Lock()
+	proc, done := q.proc, q.done
+	q.mu.Unlock()
+
+	if proc == nil {
+		return fmt.Errorf("qemu is not running")
+	}
+
+	timeout := q.shutdownTimeout
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+
+	err := q.powerdown(ctx, timeout)
+	if err == nil {
+		select {
+		case <-done:
+			return nil
+		case <-ctx.Done():
+			err = ctx.Err()
+		case <-t.C:
+			err = fmt.Errorf("guest did not power down within %s", timeout)
+		}
+	}
+
+	if kerr := proc.Kill(); kerr != nil && !errors.Is(kerr, os.ErrProcessDone) {
+		return fmt.Errorf("unable to kill qemu: %w", kerr)
+	}
+
+	<-done
+
+	return fmt.Errorf("killed qemu: %w", err)
+}
+
+// powerdown sends system_powerdown over the QMP socket.
+func (q *QemuInstance) powerdown(ctx context.Context, timeout time.Duration) error {
+	if q.qmpSocket == "" {
+		return fmt.Errorf("no qmp socket configured")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var d net.Dialer
+
+	c, err := d.DialContext(ctx, "unix", q.qmpSocket)
+	if err != nil {
+		return fmt.Errorf("unable to connect to qmp: %w", err)
+	}
+
+	defer c.Close()
+
+	if dl, ok := ctx.Deadline(); ok {
+		c.SetDeadline(dl)
+	}
+
+	log := q.log
+	if log == nil {
+		log = slog.Default()
+	}
+
+	qconn, err := qmp.Open(log, c, nil)
+	if err != nil {
+		return err
+	}
+
+	err = qconn.Watch(ctx)
+	if err != nil {
+		return fmt.Errorf("error negotiating with qemu via qmp: %w", err)
+	}
+
+	_, err = qconn.Execute(ctx, "system_powerdown", nil)
+	if err != nil {
+		return fmt.Errorf("error issuing powerdown: %w", err)
+	}
+
+	return nil
 }
@@ -0,0 +1,239 @@
+package lve
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQemuOptions(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(os.Stderr, nil))
+
+	withHostCPUs := func(t *testing.T, n int) {
+		old := hostCPUs
+		hostCPUs = func() int { return n }
+		t.Cleanup(func() { hostCPUs = old })
+	}
+
+	withNested := func(t *testing.T, module, value string) {
+		dir := t.TempDir()
+
+		params := filepath.Join(dir, module, "parameters")
+		require.NoError(t, os.MkdirAll(params, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(params, "nested"), []byte(value+"\n"), 0644))
+
+		old := kvmModules
+		kvmModules = dir
+		t.Cleanup(func() { kvmModules = old })
+	}
+
+	// option returns the value following flag in opts.
+	option := func(opts []string, flag string) string {
+		for i, o := range opts {
+			if o == flag && i+1 < len(opts) {
+				return opts[i+1]
+			}
+		}
+
+		return ""
+	}
+
+	build := func(co *CommonOptions) ([]string, error) {
+		var q QemuInstance
+		err := q.buildOptions(log, co)
+		return q.options, err
+	}
+
+	t.Run("lays out the cpu topology", func(t *testing.T) {
+		r := require.New(t)
+		withHostCPUs(t, 16)
+
+		opts, err := build(&CommonOptions{CPUs: 8, Sockets: 2, Cores: 2, Threads: 2, MemoryMB: 1024})
+		r.NoError(err)
+
+		r.Equal("cpus=8,sockets=2,cores=2,threads=2", option(opts, "-smp"))
+		r.Equal("host", option(opts, "-cpu"))
+		r.NotContains(opts, "virtio-balloon-pci,id=balloon0,deflate-on-oom=on")
+	})
+
+	t.Run("defaults to a core per cpu", func(t *testing.T) {
+		r := require.New(t)
+		withHostCPUs(t, 16)
+
+		opts, err := build(&CommonOptions{CPUs: 4, MemoryMB: 1024})
+		r.NoError(err)
+		r.Equal("cpus=4,sockets=1,cores=4,threads=1", option(opts, "-smp"))
+
+		opts, err = build(&CommonOptions{Sockets: 2, Cores: 3, Threads: 1, MemoryMB: 1024})
+		r.NoError(err)
+		r.Equal("cpus=6,sockets=2,cores=3,threads=1", option(opts, "-smp"))
+	})
+
+	t.Run("rejects a topology that doesn't match the cpus", func(t *testing.T) {
+		withHostCPUs(t, 16)
+
+		_, err := build(&CommonOptions{CPUs: 8, Sockets: 2, Cores: 2, Threads: 1, MemoryMB: 1024})
+		require.ErrorContains(t, err, "is 4 cpus, not 8")
+	})
+
+	t.Run("rejects more cpus than the host has", func(t *testing.T) {
+		withHostCPUs(t, 4)
+
+		_, err := build(&CommonOptions{CPUs: 8, MemoryMB: 1024})
+		require.ErrorContains(t, err, "requested 8 cpus, but the host only has 4")
+	})
+
+	t.Run("adds a memory balloon", func(t *testing.T) {
+		withHostCPUs(t, 4)
+
+		opts, err := build(&CommonOptions{CPUs: 2, MemoryMB: 1024, Balloon: true})
+		require.NoError(t, err)
+		require.Contains(t, opts, "virtio-balloon-pci,id=balloon0,deflate-on-oom=on")
+	})
+
+	t.Run("passes virtualization extensions through", func(t *testing.T) {
+		r := require.New(t)
+		withHostCPUs(t, 4)
+		withNested(t, "kvm_amd", "1")
+
+		opts, err := build(&CommonOptions{CPUs: 2, MemoryMB: 1024, NestedVirt: true})
+		r.NoError(err)
+		r.Equal("host,+svm", option(opts, "-cpu"))
+	})
+
+	t.Run("fails when nested virtualization is disabled", func(t *testing.T) {
+		withHostCPUs(t, 4)
+		withNested(t, "kvm_intel", "N")
+
+		_, err := build(&CommonOptions{CPUs: 2, MemoryMB: 1024, NestedVirt: true})
+		require.ErrorContains(t, err, "nested virtualization is disabled in kvm_intel")
+	})
+}
+
+// fakeQMP serves the QMP protocol on a unix socket, recording the commands
+// it's sent and calling onPowerdown when asked to power down.
+func fakeQMP(t *testing.T, onPowerdown func()) (string, chan string) {
+	path := filepath.Join(t.TempDir(), "qmp.sock")
+
+	l, err := net.Listen("unix", path)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { l.Close() })
+
+	cmds := make(chan string, 10)
+
+	go func() {
+		c, err := l.Accept()
+		if err != nil {
+			return
+		}
+
+		defer c.Close()
+
+		enc := json.NewEncoder(c)
+		dec := json.NewDecoder(c)
+
+		enc.Encode(map[string]any{
+			"QMP": map[string]any{"capabilities": []string{}},
+		})
+
+		for {
+			var cmd struct {
+				Execute string `json:"execute"`
+			}
+
+			if dec.Decode(&cmd) != nil {
+				return
+			}
+
+			cmds <- cmd.Execute
+
+			enc.Encode(map[string]any{"return": map[string]any{}})
+
+			if cmd.Execute == "system_powerdown" && onPowerdown != nil {
+				onPowerdown()
+			}
+		}
+	}()
+
+	return path, cmds
+}
+
+func TestQemuShutdown(t *testing.T) {
+	// start runs a stand in for qemu that only exits when signaled.
+	start := func(t *testing.T, q *QemuInstance) *exec.Cmd {
+		cmd := exec.Command("sleep", "60")
+		require.NoError(t, q.start(cmd))
+
+		t.Cleanup(func() {
+			cmd.Process.Kill()
+			<-q.done
+		})
+
+		return cmd
+	}
+
+	signaled := func(cmd *exec.Cmd) syscall.Signal {
+		return cmd.ProcessState.Sys().(syscall.WaitStatus).Signal()
+	}
+
+	t.Run("powers the guest down", func(t *testing.T) {
+		r := require.New(t)
+
+		q := &QemuInstance{shutdownTimeout: 5 * time.Second}
+
+		path, cmds := fakeQMP(t, func() {
+			q.proc.Signal(syscall.SIGTERM)
+		})
+		q.qmpSocket = path
+
+		cmd := start(t, q)
+
+		r.NoError(q.Shutdown(context.Background()))
+
+		r.Equal("qmp_capabilities", <-cmds)
+		r.Equal("system_powerdown", <-cmds)
+		r.Equal(syscall.SIGTERM, signaled(cmd))
+	})
+
+	t.Run("kills qemu when the guest doesn't power down in time", func(t *testing.T) {
+		r := require.New(t)
+
+		path, cmds := fakeQMP(t, nil)
+
+		q := &QemuInstance{qmpSocket: path, shutdownTimeout: 100 * time.Millisecond}
+		cmd := start(t, q)
+
+		err := q.Shutdown(context.Background())
+		r.ErrorContains(err, "did not power down within 100ms")
+
+		r.Equal("qmp_capabilities", <-cmds)
+		r.Equal("system_powerdown", <-cmds)
+		r.Equal(syscall.SIGKILL, signaled(cmd))
+	})
+
+	t.Run("kills qemu when qmp is unreachable", func(t *testing.T) {
+		r := require.New(t)
+
+		q := &QemuInstance{qmpSocket: filepath.Join(t.TempDir(), "missing.sock")}
+		cmd := start(t, q)
+
+		err := q.Shutdown(context.Background())
+		r.ErrorContains(err, "unable to connect to qmp")
+		r.Equal(syscall.SIGKILL, signaled(cmd))
+	})
+
+	t.Run("fails when qemu isn't running", func(t *testing.T) {
+		var q QemuInstance
+		require.ErrorContains(t, q.Shutdown(context.Background()), "not running")
+	})
+}
@@ -2,131 +2,187 @@ package lsvd
 
 import (
 	"log/slog"
+	"net/http"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	dto "github.com/prometheus/client_model/go"
 )
 
+// Registry holds the disk metrics. Both the /metrics endpoint served by
+// MetricsHandler and the summaries written by LogMetrics read from it, so
+// they always agree.
+var Registry = prometheus.NewRegistry()
+
+var metrics = promauto.With(Registry)
+
 var (
-	blocksWritten = promauto.NewCounter(prometheus.CounterOpts{
+	blocksWritten = metrics.NewCounter(prometheus.CounterOpts{
 		Name: "lsvd_blocks_written",
 		Help: "The total number of blocks written",
 	})
 
-	blocksRead = promauto.NewCounter(prometheus.CounterOpts{
+	blocksDiscarded = metrics.NewCounter(prometheus.CounterOpts{
+		Name: "lsvd_blocks_discarded",
+		Help: "The total number of blocks discarded",
+	})
+
+	blocksRead = metrics.NewCounter(prometheus.CounterOpts{
 		Name: "lsvd_blocks_read",
 		Help: "The total number of blocks read",
 	})
 
-	blocksReadLatency = promauto.NewHistogram(prometheus.HistogramOpts{
+	blocksReadLatency = metrics.NewHistogram(prometheus.HistogramOpts{
 		Name:    "lsvd_blocks_read_time",
 		Help:    "The total number of blocks read",
 		Buckets: prometheus.DefBuckets,
 	})
 
-	blocksWriteLatency = promauto.NewHistogram(prometheus.HistogramOpts{
+	blocksWriteLatency = metrics.NewHistogram(prometheus.HistogramOpts{
 		Name:    "lsvd_blocks_write_time",
 		Help:    "The latency of block writes",
 		Buckets: prometheus.DefBuckets,
 	})
 
-	iops = promauto.NewCounter(prometheus.CounterOpts{
+	iops = metrics.NewCounter(prometheus.CounterOpts{
 		Name: "lsvd_iops",
 		Help: "The total number of iops",
 	})
 
-	segmentsWritten = promauto.NewCounter(prometheus.CounterOpts{
+	segmentsWritten = metrics.NewCounter(prometheus.CounterOpts{
 		Name: "lsvd_segments_written",
 		Help: "The total number of segments written",
 	})
 
-	writtenBytes = promauto.NewCounter(prometheus.CounterOpts{
+	writtenBytes = metrics.NewCounter(prometheus.CounterOpts{
 		Name: "lsvd_extent_bytes_written",
 		Help: "The total number of bytes written for extents",
 	})
 
-	segmentsBytes = promauto.NewCounter(prometheus.CounterOpts{
+	segmentsBytes = metrics.NewCounter(prometheus.CounterOpts{
 		Name: "lsvd_segments_bytes_written",
 		Help: "The total number of segments bytes written",
 	})
 
-	segmentTime = promauto.NewHistogram(prometheus.HistogramOpts{
+	segmentTime = metrics.NewHistogram(prometheus.HistogramOpts{
 		Name:    "lsvd_segments_upload_time",
 		Help:    "The time taken to upload segments",
 		Buckets: prometheus.DefBuckets,
 	})
 
-	segmentTotalTime = promauto.NewCounter(prometheus.CounterOpts{
+	segmentTotalTime = metrics.NewCounter(prometheus.CounterOpts{
 		Name: "lsvd_segments_processing_time",
 		Help: "The total time spend processing segments",
 	})
 
-	openSegments = promauto.NewGauge(prometheus.GaugeOpts{
+	openSegments = metrics.NewGauge(prometheus.GaugeOpts{
 		Name: "lsvd_segments_open",
 		Help: "The total number of open segments",
 	})
 
-	extentCacheMiss = promauto.NewCounter(prometheus.CounterOpts{
+	extentCacheMiss = metrics.NewCounter(prometheus.CounterOpts{
 		Name: "lsvd_extent_cache_miss",
 		Help: "Number of times the extent cache did not contain the entry",
 	})
 
-	extentCacheHits = promauto.NewCounter(prometheus.CounterOpts{
+	extentCacheHits = metrics.NewCounter(prometheus.CounterOpts{
 		Name: "lsvd_extent_cache_hits",
 		Help: "Number of times the extent cache contained the entry",
 	})
 
-	readProcessing = promauto.NewCounter(prometheus.CounterOpts{
+	readProcessing = metrics.NewCounter(prometheus.CounterOpts{
 		Name: "lsvd_read_processing",
 		Help: "How many additional seconds is used by processing read requests",
 	})
 
-	compressionOverhead = promauto.NewCounter(prometheus.CounterOpts{
+	compressionOverhead = metrics.NewCounter(prometheus.CounterOpts{
 		Name: "lsvd_compression_read_overhead",
 		Help: "How many additional seconds is added by decompressing on reads",
 	})
 
-	sendfileResponses = promauto.NewCounter(prometheus.CounterOpts{
+	sendfileResponses = metrics.NewCounter(prometheus.CounterOpts{
 		Name: "lsvd_responses_sendfile",
 		Help: "How many responses are replied to with sendfile",
 	})
 
-	writeResponses = promauto.NewCounter(prometheus.CounterOpts{
+	writeResponses = metrics.NewCounter(prometheus.CounterOpts{
 		Name: "lsvd_responses_write",
 		Help: "How many responses are replied to with write",
 	})
 
-	inflateCache = promauto.NewCounter(prometheus.CounterOpts{
+	inflateCache = metrics.NewCounter(prometheus.CounterOpts{
 		Name: "lsvd_cache_inflate",
 		Help: "How often values from the cache are inflated to memory",
 	})
 
-	extents = promauto.NewGauge(prometheus.GaugeOpts{
+	extents = metrics.NewGauge(prometheus.GaugeOpts{
 		Name: "lsvd_active_extents",
 		Help: "How many entries are in the extent map",
 	})
 
-	extentUpdates = promauto.NewCounter(prometheus.CounterOpts{
+	extentUpdates = metrics.NewCounter(prometheus.CounterOpts{
 		Name: "lsvd_extent_updates",
 		Help: "How many times the extent map has been updated",
 	})
 
-	dataDensity = promauto.NewGauge(prometheus.GaugeOpts{
+	dataDensity = metrics.NewGauge(prometheus.GaugeOpts{
 		Name: "lsvd_data_density",
 		Help: "What percent of the stored data is used",
 	})
 
-	gcCount = promauto.NewCounter(prometheus.CounterOpts{
+	gcCount = metrics.NewCounter(prometheus.CounterOpts{
 		Name: "lsvd_gc_cycles",
 		Help: "How many times the GC has run",
 	})
 
-	gcTime = promauto.NewCounter(prometheus.CounterOpts{
+	gcTime = metrics.NewCounter(prometheus.CounterOpts{
 		Name: "lsvd_gc_time",
 		Help: "How many seconds the GC has run for",
 	})
+
+	compactionLatency = metrics.NewHistogram(prometheus.HistogramOpts{
+		Name:    "lsvd_compaction_duration_seconds",
+		Help:    "How long each GC or pack of segments took",
+		Buckets: []float64{0.1, 0.5, 1, 5, 10, 30, 60, 300},
+	})
+
+	segmentFlushes = metrics.NewCounterVec(prometheus.CounterOpts{
+		Name: "lsvd_segment_flushes",
+		Help: "How many segments the flush policy has sealed, by reason",
+	}, []string{"reason"})
+
+	flushPressure = metrics.NewGauge(prometheus.GaugeOpts{
+		Name: "lsvd_flush_pressure",
+		Help: "The write pressure seen by the adaptive flush policy, from 0 to 1",
+	})
+
+	flushSizeThreshold = metrics.NewGauge(prometheus.GaugeOpts{
+		Name: "lsvd_flush_size_threshold_bytes",
+		Help: "The segment size at which the adaptive flush policy currently flushes",
+	})
+
+	flushPendingThreshold = metrics.NewGauge(prometheus.GaugeOpts{
+		Name: "lsvd_flush_pending_threshold_seconds",
+		Help: "How long the adaptive flush policy currently lets writes wait before flushing",
+	})
+
+	tierMovements = metrics.NewCounterVec(prometheus.CounterOpts{
+		Name: "lsvd_tier_movements",
+		Help: "How many segments tiering has moved, by direction (demote or rehydrate)",
+	}, []string{"direction"})
+
+	tierFetchLatency = metrics.NewHistogram(prometheus.HistogramOpts{
+		Name:    "lsvd_tier_fetch_duration_seconds",
+		Help:    "How long fetching a demoted segment back from the cold store took",
+		Buckets: prometheus.DefBuckets,
+	})
+
+	tierLocalBytes = metrics.NewGauge(prometheus.GaugeOpts{
+		Name: "lsvd_tier_local_bytes",
+		Help: "How many bytes of segments tiering has left on the hot store",
+	})
 )
 
 func counterValue(c prometheus.Counter) int64 {
@@ -141,80 +197,123 @@ func counterValueFloat(c prometheus.Counter) float64 {
 	return m.Counter.GetValue()
 }
 
-func gaugeValue(c prometheus.Gauge) float64 {
-	var m dto.Metric
-	c.Write(&m)
-	return m.Gauge.GetValue()
+type MetricSnapshot struct {
+	BlocksWritten   int64
+	BlocksRead      int64
+	IOPS            int64
+	SegmentsWritten int64
 }
 
-func counterAsDuration(c prometheus.Counter) time.Duration {
-	var m dto.Metric
-	c.Write(&m)
-	return time.Duration(m.Counter.GetValue() * float64(time.Second))
+func GetMetrics() MetricSnapshot {
+	return MetricSnapshot{
+		BlocksWritten:   counterValue(blocksWritten),
+		BlocksRead:      counterValue(blocksRead),
+		IOPS:            counterValue(iops),
+		SegmentsWritten: counterValue(segmentsWritten),
+	}
 }
 
-func counterAsSeconds(c prometheus.Counter) float64 {
-	var m dto.Metric
-	c.Write(&m)
-	return m.Counter.GetValue()
+// MetricsHandler serves the disk metrics, along with those of the default
+// registry such as the Go runtime's, in the Prometheus text format.
+func MetricsHandler() http.Handler {
+	return promhttp.HandlerFor(
+		prometheus.Gatherers{Registry, prometheus.DefaultGatherer},
+		promhttp.HandlerOpts{},
+	)
 }
 
-func timeTotalValue(c prometheus.Histogram) time.Duration {
-	var m dto.Metric
-	c.Write(&m)
+// gatheredMetrics are the metric families of Registry by name.
+type gatheredMetrics map[string]*dto.MetricFamily
+
+func gatherMetrics() (gatheredMetrics, error) {
+	families, err := Registry.Gather()
+	if err != nil {
+		return nil, err
+	}
+
+	gm := gatheredMetrics{}
+
+	for _, mf := range families {
+		gm[mf.GetName()] = mf
+	}
 
-	return time.Duration(m.Histogram.GetSampleSum() * float64(time.Second))
+	return gm, nil
 }
 
-func timeAvgValue(c prometheus.Histogram) time.Duration {
-	var m dto.Metric
-	c.Write(&m)
+// value sums a counter or gauge over all of its labels.
+func (gm gatheredMetrics) value(name string) float64 {
+	var total float64
 
-	samples := m.Histogram.GetSampleCount()
-	if samples == 0 {
+	mf, ok := gm[name]
+	if !ok {
 		return 0
 	}
 
-	return time.Duration(m.Histogram.GetSampleSum()*float64(time.Second)) / time.Duration(samples)
+	for _, m := range mf.Metric {
+		switch {
+		case m.Counter != nil:
+			total += m.Counter.GetValue()
+		case m.Gauge != nil:
+			total += m.Gauge.GetValue()
+		}
+	}
+
+	return total
 }
 
-type MetricSnapshot struct {
-	BlocksWritten   int64
-	BlocksRead      int64
-	IOPS            int64
-	SegmentsWritten int64
+func (gm gatheredMetrics) int(name string) int64 {
+	return int64(gm.value(name))
 }
 
-func GetMetrics() MetricSnapshot {
-	return MetricSnapshot{
-		BlocksWritten:   counterValue(blocksWritten),
-		BlocksRead:      counterValue(blocksRead),
-		IOPS:            counterValue(iops),
-		SegmentsWritten: counterValue(segmentsWritten),
+func (gm gatheredMetrics) seconds(name string) time.Duration {
+	return time.Duration(gm.value(name) * float64(time.Second))
+}
+
+// avg is the average observation of a histogram.
+func (gm gatheredMetrics) avg(name string) time.Duration {
+	mf, ok := gm[name]
+	if !ok || len(mf.Metric) == 0 || mf.Metric[0].Histogram == nil {
+		return 0
+	}
+
+	h := mf.Metric[0].Histogram
+
+	if h.GetSampleCount() == 0 {
+		return 0
 	}
+
+	return time.Duration(h.GetSampleSum()*float64(time.Second)) / time.Duration(h.GetSampleCount())
 }
 
 func LogMetrics(log *slog.Logger) {
+	gm, err := gatherMetrics()
+	if err != nil {
+		log.Error("error gathering disk metrics", "error", err)
+		return
+	}
+
 	log.Info("disk stats",
-		"written-bytes", counterValue(writtenBytes),
-		"segment-bytes", counterValue(segmentsBytes),
-		"segments", counterValue(segmentsWritten),
-		"total-segment-process-time", counterAsDuration(segmentTotalTime),
-		"extent-cache-hits", counterValue(extentCacheHits),
-		"extent-cache-misses", counterValue(extentCacheMiss),
-		"sendfile-responses", counterValue(sendfileResponses),
-		"write-responses", counterValue(writeResponses),
-		"cache-inflates", counterValue(inflateCache),
-		"data-density", gaugeValue(dataDensity),
+		"written-bytes", gm.int("lsvd_extent_bytes_written"),
+		"segment-bytes", gm.int("lsvd_segments_bytes_written"),
+		"segments", gm.int("lsvd_segments_written"),
+		"segment-flushes", gm.int("lsvd_segment_flushes"),
+		"total-segment-process-time", gm.seconds("lsvd_segments_processing_time"),
+		"compaction-latency", gm.avg("lsvd_compaction_duration_seconds"),
+		"extent-cache-hits", gm.int("lsvd_extent_cache_hits"),
+		"extent-cache-misses", gm.int("lsvd_extent_cache_miss"),
+		"sendfile-responses", gm.int("lsvd_responses_sendfile"),
+		"write-responses", gm.int("lsvd_responses_write"),
+		"cache-inflates", gm.int("lsvd_cache_inflate"),
+		"data-density", gm.value("lsvd_data_density"),
 	)
 
 	log.Info("client stats",
-		"iops", counterValue(iops),
-		"blocks-written", counterValue(blocksWritten),
-		"blocks-read", counterValue(blocksRead),
-		"block-write-latency", timeAvgValue(blocksWriteLatency),
-		"block-read-latency", timeAvgValue(blocksReadLatency),
-		"compression-overhead", counterAsSeconds(compressionOverhead),
-		"read-processing", counterAsSeconds(readProcessing),
+		"iops", gm.int("lsvd_iops"),
+		"blocks-written", gm.int("lsvd_blocks_written"),
+		"blocks-read", gm.int("lsvd_blocks_read"),
+		"block-write-latency", gm.avg("lsvd_blocks_write_time"),
+		"block-read-latency", gm.avg("lsvd_blocks_read_time"),
+		"compression-overhead", gm.value("lsvd_compression_read_overhead"),
+		"read-processing", gm.value("lsvd_read_processing"),
 	)
 }
@@ -0,0 +1,104 @@
+package lsvd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// scrapeMetrics returns the value of every unlabeled sample served by h.
+func scrapeMetrics(t *testing.T, h http.Handler) map[string]float64 {
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/metrics")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	samples := map[string]float64{}
+
+	sc := bufio.NewScanner(bytes.NewReader(body))
+	for sc.Scan() {
+		line := sc.Text()
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name, val, ok := strings.Cut(line, " ")
+		if !ok || strings.Contains(name, "{") {
+			continue
+		}
+
+		v, err := strconv.ParseFloat(val, 64)
+		require.NoError(t, err, "bad sample: %s", line)
+
+		samples[name] = v
+	}
+
+	return samples
+}
+
+func TestMetricsHandler(t *testing.T) {
+	r := require.New(t)
+
+	ctx := NewContext(context.Background())
+
+	var logs bytes.Buffer
+	log := slog.New(slog.NewTextHandler(&logs, nil))
+
+	tmpdir, err := os.MkdirTemp("", "lsvd")
+	r.NoError(err)
+	defer os.RemoveAll(tmpdir)
+
+	d, err := NewDisk(ctx, log, tmpdir)
+	r.NoError(err)
+
+	err = d.WriteExtent(ctx, testRandX.MapTo(0))
+	r.NoError(err)
+
+	_, err = d.ReadExtent(ctx, Extent{LBA: 0, Blocks: 1})
+	r.NoError(err)
+
+	r.NoError(d.CloseSegment(ctx))
+	r.NoError(d.Close(ctx))
+
+	samples := scrapeMetrics(t, MetricsHandler())
+
+	for _, name := range []string{
+		"lsvd_blocks_read",
+		"lsvd_blocks_written",
+		"lsvd_segments_written",
+		"lsvd_compaction_duration_seconds_count",
+		"go_goroutines",
+	} {
+		r.Contains(samples, name)
+	}
+
+	r.GreaterOrEqual(samples["lsvd_blocks_written"], float64(1))
+	r.GreaterOrEqual(samples["lsvd_blocks_read"], float64(1))
+	r.GreaterOrEqual(samples["lsvd_segments_written"], float64(1))
+
+	logs.Reset()
+	LogMetrics(log)
+
+	out := logs.String()
+
+	r.Contains(out, "blocks-written="+strconv.FormatInt(int64(samples["lsvd_blocks_written"]), 10))
+	r.Contains(out, "blocks-read="+strconv.FormatInt(int64(samples["lsvd_blocks_read"]), 10))
+	r.Contains(out, "segments="+strconv.FormatInt(int64(samples["lsvd_segments_written"]), 10))
+}
@@ -206,7 +206,7 @@ func (n *nbdWrapper) ReadIntoConn(b []byte, off int64, output *os.File) (bool, e
 
 func (n *nbdWrapper) flushPendingWrite() error {
 	if n.pendingTrim.Blocks > 0 {
-		err := n.d.ZeroBlocks(n.ctx, n.pendingTrim)
+		err := n.d.Discard(n.ctx, n.pendingTrim.LBA, n.pendingTrim.Blocks)
 		n.pendingTrim = Extent{}
 		if err != nil {
 			return err
@@ -326,33 +326,21 @@ func (n *nbdWrapper) ZeroAt(off, size int64) error {
 		slog.Int64("blocks", int64(numBlocks)),
 	)
 
-	// We're sent trim sizes FAR larger than the write sizes, which
-	// can create extents that have more than 2^16 blocks in one extent.
-	// For optimization, we want to keep the max blocks in an extent under
-	// 2^16, so we'll break this up.
+	// We're sent trim sizes FAR larger than the write sizes, so these are
+	// discarded directly rather than batched. Discard breaks them up to keep
+	// each extent under 2^16 blocks.
 
 	if numBlocks > MaxBlocks {
-		trace(n.log, "detected very large trim request, breaking up into smaller extents")
+		trace(n.log, "detected very large trim request, discarding directly")
 
 		err := n.flushPendingWrite()
 		if err != nil {
 			return err
 		}
 
-		for numBlocks > MaxBlocks {
-			err := n.d.ZeroBlocks(n.ctx, Extent{blk, MaxBlocks})
-			if err != nil {
-				n.log.Error("nbd write-at error", "error", err, "block", blk)
-				return err
-			}
-
-			blk += MaxBlocks
-			numBlocks -= MaxBlocks
-		}
-
-		err = n.d.ZeroBlocks(n.ctx, Extent{blk, numBlocks})
+		err = n.d.Discard(n.ctx, blk, numBlocks)
 		if err != nil {
-			n.log.Error("nbd write-at error", "error", err, "block", blk)
+			n.log.Error("nbd zero-at error", "error", err, "block", blk)
 			return err
 		}
 
@@ -371,9 +359,9 @@ func (n *nbdWrapper) ZeroAt(off, size int64) error {
 	}
 
 	if !n.queueTrim(ext) {
-		err := n.d.ZeroBlocks(n.ctx, Extent{blk, numBlocks})
+		err := n.d.Discard(n.ctx, blk, numBlocks)
 		if err != nil {
-			n.log.Error("nbd write-at error", "error", err, "block", blk)
+			n.log.Error("nbd zero-at error", "error", err, "block", blk)
 			return err
 		}
 	}
@@ -381,6 +369,8 @@ func (n *nbdWrapper) ZeroAt(off, size int64) error {
 	return nil
 }
 
+// Trim discards a range, as for blkdiscard. Unwritten blocks read as zero,
+// so this is the same as zeroing the range.
 func (n *nbdWrapper) Trim(off, size int64) error {
 	return n.ZeroAt(off, size)
 }
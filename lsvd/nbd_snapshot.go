@@ -0,0 +1,355 @@
+package lsvd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"miren.dev/runtime/lsvd/pkg/nbd"
+)
+
+var (
+	ErrSnapshotExportExists   = errors.New("snapshot export already exists")
+	ErrSnapshotExportNotFound = errors.New("snapshot export not found")
+	ErrReadOnlyExport         = errors.New("export is read-only")
+)
+
+// SnapshotExportName returns the name a snapshot of volume is exported
+// under, so that it can sit alongside the volume's own export.
+func SnapshotExportName(volume, snapshot string) string {
+	return volume + "@" + snapshot
+}
+
+// NBDExports is the set of exports an NBD server offers for a disk: the
+// live volume, plus any snapshots of it exported read-only. Snapshots can
+// be exported and released while the server is running, and each
+// connection sees the exports as they were when it was accepted.
+type NBDExports struct {
+	log  *slog.Logger
+	d    *Disk
+	name string
+	live *nbd.Export
+
+	mu        sync.Mutex
+	snapshots map[string]*snapshotExport
+}
+
+type snapshotExport struct {
+	export  *nbd.Export
+	snap    *Snapshot
+	created time.Time
+}
+
+// NewNBDExports returns the exports for d, with the live volume exported
+// as name.
+func NewNBDExports(ctx context.Context, log *slog.Logger, d *Disk, name string) *NBDExports {
+	return &NBDExports{
+		log:  log,
+		d:    d,
+		name: name,
+		live: &nbd.Export{
+			Name:        name,
+			Description: "disk",
+			Backend:     &lockedBackend{Backend: NBDWrapper(ctx, log, d)},
+		},
+		snapshots: make(map[string]*snapshotExport),
+	}
+}
+
+// Exports returns the live volume's export followed by the snapshot
+// exports, ordered by name.
+func (e *NBDExports) Exports() []*nbd.Export {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	exports := []*nbd.Export{e.live}
+
+	for _, name := range e.snapshotNames() {
+		exports = append(exports, e.snapshots[name].export)
+	}
+
+	return exports
+}
+
+func (e *NBDExports) snapshotNames() []string {
+	names := make([]string, 0, len(e.snapshots))
+	for name := range e.snapshots {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	return names
+}
+
+// ExportSnapshot snapshots the disk and exports the snapshot read-only,
+// under SnapshotExportName of the volume and name.
+func (e *NBDExports) ExportSnapshot(ctx context.Context, name string) (*nbd.Export, error) {
+	if name == "" || strings.ContainsAny(name, "@/") {
+		return nil, fmt.Errorf("invalid snapshot name %q", name)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, ok := e.snapshots[name]; ok {
+		return nil, fmt.Errorf("%w: %s", ErrSnapshotExportExists, name)
+	}
+
+	snap, err := e.d.Snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	size := RoundToBlockSize(e.d.Size())
+	if size == 0 {
+		size = maxSize
+	}
+
+	se := &snapshotExport{
+		export: &nbd.Export{
+			Name:        SnapshotExportName(e.name, name),
+			Description: "snapshot",
+			ReadOnly:    true,
+			Backend:     &snapshotBackend{snap: snap, size: size},
+		},
+		snap:    snap,
+		created: time.Now(),
+	}
+
+	e.snapshots[name] = se
+
+	e.log.Info("exporting snapshot", "export", se.export.Name)
+
+	return se.export, nil
+}
+
+// ReleaseSnapshot stops exporting a snapshot and releases it. Clients still
+// reading from it get errors and are disconnected.
+func (e *NBDExports) ReleaseSnapshot(name string) error {
+	e.mu.Lock()
+	se, ok := e.snapshots[name]
+	delete(e.snapshots, name)
+	e.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrSnapshotExportNotFound, name)
+	}
+
+	se.snap.Release()
+
+	e.log.Info("released snapshot export", "export", se.export.Name)
+
+	return nil
+}
+
+// Close releases all the exported snapshots.
+func (e *NBDExports) Close() {
+	e.mu.Lock()
+	snapshots := e.snapshots
+	e.snapshots = make(map[string]*snapshotExport)
+	e.mu.Unlock()
+
+	for _, se := range snapshots {
+		se.snap.Release()
+	}
+}
+
+// SnapshotExport describes an exported snapshot.
+type SnapshotExport struct {
+	Name    string    `json:"name"`
+	Export  string    `json:"export"`
+	Created time.Time `json:"created"`
+}
+
+// Handler serves control of the snapshot exports:
+//
+//	GET    /snapshots         list the exported snapshots, as JSON
+//	PUT    /snapshots/{name}  snapshot the disk and export it
+//	DELETE /snapshots/{name}  stop exporting a snapshot and release it
+func (e *NBDExports) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	describe := func(name string, se *snapshotExport) SnapshotExport {
+		return SnapshotExport{
+			Name:    name,
+			Export:  se.export.Name,
+			Created: se.created,
+		}
+	}
+
+	mux.HandleFunc("GET /snapshots", func(w http.ResponseWriter, r *http.Request) {
+		e.mu.Lock()
+		resp := struct {
+			Snapshots []SnapshotExport `json:"snapshots"`
+		}{
+			Snapshots: make([]SnapshotExport, 0, len(e.snapshots)),
+		}
+
+		for _, name := range e.snapshotNames() {
+			resp.Snapshots = append(resp.Snapshots, describe(name, e.snapshots[name]))
+		}
+		e.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	})
+
+	mux.HandleFunc("PUT /snapshots/{name}", func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("name")
+
+		_, err := e.ExportSnapshot(r.Context(), name)
+		if err != nil {
+			code := http.StatusInternalServerError
+			if errors.Is(err, ErrSnapshotExportExists) {
+				code = http.StatusConflict
+			}
+
+			http.Error(w, err.Error(), code)
+			return
+		}
+
+		e.mu.Lock()
+		resp := describe(name, e.snapshots[name])
+		e.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(resp)
+	})
+
+	mux.HandleFunc("DELETE /snapshots/{name}", func(w http.ResponseWriter, r *http.Request) {
+		if err := e.ReleaseSnapshot(r.PathValue("name")); err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	return mux
+}
+
+// lockedBackend serializes access to a backend shared by several
+// connections, as the live volume is once snapshot exports let more than
+// one client connect at a time.
+type lockedBackend struct {
+	mu sync.Mutex
+	nbd.Backend
+}
+
+func (l *lockedBackend) ReadAt(b []byte, off int64) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.Backend.ReadAt(b, off)
+}
+
+func (l *lockedBackend) WriteAt(b []byte, off int64) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.Backend.WriteAt(b, off)
+}
+
+func (l *lockedBackend) ReadIntoConn(b []byte, off int64, output *os.File) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.Backend.ReadIntoConn(b, off, output)
+}
+
+func (l *lockedBackend) ZeroAt(off, sz int64) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.Backend.ZeroAt(off, sz)
+}
+
+func (l *lockedBackend) Trim(off, sz int64) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.Backend.Trim(off, sz)
+}
+
+func (l *lockedBackend) Size() (int64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.Backend.Size()
+}
+
+func (l *lockedBackend) Sync() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.Backend.Sync()
+}
+
+func (l *lockedBackend) Tick() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.Backend.Tick()
+}
+
+// snapshotBackend serves a snapshot over NBD. Snapshots can't change, so
+// writes are refused and flushes have nothing to do.
+type snapshotBackend struct {
+	snap *Snapshot
+	size int64
+}
+
+var _ nbd.Backend = &snapshotBackend{}
+
+func (s *snapshotBackend) ReadAt(b []byte, off int64) (int, error) {
+	if off%BlockSize != 0 {
+		return 0, fmt.Errorf("read at offset %d is not block aligned", off)
+	}
+
+	return s.snap.ReadAt(b, LBA(off/BlockSize))
+}
+
+func (s *snapshotBackend) ReadIntoConn(b []byte, off int64, output *os.File) (bool, error) {
+	n, err := s.ReadAt(b, off)
+	if err != nil {
+		return false, err
+	}
+
+	if _, err := output.Write(b[:n]); err != nil {
+		return true, err
+	}
+
+	return true, nil
+}
+
+func (s *snapshotBackend) WriteAt(b []byte, off int64) (int, error) {
+	return 0, ErrReadOnlyExport
+}
+
+func (s *snapshotBackend) ZeroAt(off, sz int64) error {
+	return ErrReadOnlyExport
+}
+
+func (s *snapshotBackend) Trim(off, sz int64) error {
+	return ErrReadOnlyExport
+}
+
+func (s *snapshotBackend) Size() (int64, error) {
+	return s.size, nil
+}
+
+func (s *snapshotBackend) Sync() error {
+	return nil
+}
+
+func (s *snapshotBackend) Tick() {}
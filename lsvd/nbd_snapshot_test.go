@@ -0,0 +1,302 @@
+package lsvd
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"miren.dev/runtime/lsvd/pkg/nbd"
+)
+
+// nbdTestClient speaks just enough of the NBD protocol to open an export
+// and read and write it.
+type nbdTestClient struct {
+	conn   net.Conn
+	size   uint64
+	flags  uint16
+	handle uint64
+	done   chan error
+}
+
+func openNBDExport(t *testing.T, exports *NBDExports, name string) *nbdTestClient {
+	r := require.New(t)
+
+	client, server := net.Pipe()
+
+	c := &nbdTestClient{conn: client, done: make(chan error, 1)}
+
+	go func() {
+		defer server.Close()
+
+		c.done <- nbd.Handle(slog.Default(), server, exports.Exports(), &nbd.Options{
+			MinimumBlockSize:   BlockSize,
+			PreferredBlockSize: BlockSize,
+		})
+	}()
+
+	var hdr nbd.NegotiationNewstyleHeader
+	r.NoError(binary.Read(client, binary.BigEndian, &hdr))
+	r.Equal(nbd.NEGOTIATION_MAGIC_OPTION, hdr.OptionMagic)
+
+	r.NoError(binary.Write(client, binary.BigEndian, uint32(nbd.NEGOTIATION_HANDSHAKE_FLAG_FIXED_NEWSTYLE)))
+
+	r.NoError(binary.Write(client, binary.BigEndian, nbd.NegotiationOptionHeader{
+		OptionMagic: nbd.NEGOTIATION_MAGIC_OPTION,
+		ID:          nbd.NEGOTIATION_ID_OPTION_GO,
+		Length:      uint32(4 + len(name) + 2),
+	}))
+	r.NoError(binary.Write(client, binary.BigEndian, uint32(len(name))))
+	_, err := client.Write([]byte(name))
+	r.NoError(err)
+	r.NoError(binary.Write(client, binary.BigEndian, uint16(0)))
+
+	for {
+		var reply nbd.NegotiationReplyHeader
+		r.NoError(binary.Read(client, binary.BigEndian, &reply))
+		r.Equal(uint32(0), reply.Type&(1<<31), "export %s was refused", name)
+
+		if reply.Type == nbd.NEGOTIATION_TYPE_REPLY_ACK {
+			return c
+		}
+
+		data := make([]byte, reply.Length)
+		_, err := io.ReadFull(client, data)
+		r.NoError(err)
+
+		if binary.BigEndian.Uint16(data) == nbd.NEGOTIATION_TYPE_INFO_EXPORT {
+			c.size = binary.BigEndian.Uint64(data[2:])
+			c.flags = binary.BigEndian.Uint16(data[10:])
+		}
+	}
+}
+
+func (c *nbdTestClient) request(typ uint16, off uint64, length uint32, data []byte) (uint32, []byte, error) {
+	c.handle++
+
+	err := binary.Write(c.conn, binary.BigEndian, nbd.TransmissionRequestHeader{
+		RequestMagic: nbd.TRANSMISSION_MAGIC_REQUEST,
+		Type:         typ,
+		Handle:       c.handle,
+		Offset:       off,
+		Length:       length,
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if data != nil {
+		if _, err := c.conn.Write(data); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	if typ == nbd.TRANSMISSION_TYPE_REQUEST_DISC {
+		return 0, nil, <-c.done
+	}
+
+	var reply nbd.TransmissionReplyHeader
+	if err := binary.Read(c.conn, binary.BigEndian, &reply); err != nil {
+		return 0, nil, err
+	}
+
+	if reply.Handle != c.handle {
+		return 0, nil, fmt.Errorf("reply for handle %d, expected %d", reply.Handle, c.handle)
+	}
+
+	if reply.Error != 0 || typ != nbd.TRANSMISSION_TYPE_REQUEST_READ {
+		return reply.Error, nil, nil
+	}
+
+	buf := make([]byte, length)
+	_, err = io.ReadFull(c.conn, buf)
+
+	return 0, buf, err
+}
+
+func (c *nbdTestClient) read(t *testing.T, off uint64, length uint32) []byte {
+	code, data, err := c.request(nbd.TRANSMISSION_TYPE_REQUEST_READ, off, length, nil)
+	require.NoError(t, err)
+	require.Zero(t, code)
+
+	return data
+}
+
+func (c *nbdTestClient) close(t *testing.T) {
+	_, _, err := c.request(nbd.TRANSMISSION_TYPE_REQUEST_DISC, 0, 0, nil)
+	require.NoError(t, err)
+
+	c.conn.Close()
+}
+
+func TestNBDExports(t *testing.T) {
+	log := slog.Default()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	t.Run("serves a snapshot read-only alongside the live volume", func(t *testing.T) {
+		r := require.New(t)
+
+		dir, err := os.MkdirTemp("", "lsvd")
+		r.NoError(err)
+		defer os.RemoveAll(dir)
+
+		d, err := NewDisk(ctx, log, dir)
+		r.NoError(err)
+		defer d.Close(ctx)
+
+		err = d.WriteExtent(ctx, testRandX.MapTo(0))
+		r.NoError(err)
+
+		exports := NewNBDExports(ctx, log, d, "vol")
+		defer exports.Close()
+
+		exp, err := exports.ExportSnapshot(ctx, "before")
+		r.NoError(err)
+		r.Equal("vol@before", exp.Name)
+		r.True(exp.ReadOnly)
+
+		_, err = exports.ExportSnapshot(ctx, "before")
+		r.ErrorIs(err, ErrSnapshotExportExists)
+
+		// Written after the snapshot, so only visible on the live volume
+		err = d.WriteExtent(ctx, testExtent.MapTo(0))
+		r.NoError(err)
+
+		snap := openNBDExport(t, exports, "vol@before")
+		live := openNBDExport(t, exports, "vol")
+
+		r.NotZero(snap.flags & nbd.NEGO_FLAG_READONLY)
+		r.Zero(live.flags & nbd.NEGO_FLAG_READONLY)
+
+		blockEqual(t, testRandX.BlockView(0), snap.read(t, 0, BlockSize))
+		blockEqual(t, testExtent.BlockView(0), live.read(t, 0, BlockSize))
+
+		code, _, err := snap.request(nbd.TRANSMISSION_TYPE_REQUEST_WRITE, 0, BlockSize, testExtent2.BlockView(0))
+		r.NoError(err)
+		r.Equal(nbd.TRANSMISSION_ERROR_EPERM, code)
+
+		blockEqual(t, testRandX.BlockView(0), snap.read(t, 0, BlockSize))
+
+		snap.close(t)
+		live.close(t)
+	})
+
+	t.Run("lists snapshot exports and drops released ones", func(t *testing.T) {
+		r := require.New(t)
+
+		dir, err := os.MkdirTemp("", "lsvd")
+		r.NoError(err)
+		defer os.RemoveAll(dir)
+
+		d, err := NewDisk(ctx, log, dir)
+		r.NoError(err)
+		defer d.Close(ctx)
+
+		err = d.WriteExtent(ctx, testRandX.MapTo(0))
+		r.NoError(err)
+
+		exports := NewNBDExports(ctx, log, d, "vol")
+		defer exports.Close()
+
+		_, err = exports.ExportSnapshot(ctx, "first")
+		r.NoError(err)
+
+		err = d.WriteExtent(ctx, testExtent.MapTo(0))
+		r.NoError(err)
+
+		_, err = exports.ExportSnapshot(ctx, "second")
+		r.NoError(err)
+
+		var names []string
+		for _, exp := range exports.Exports() {
+			names = append(names, exp.Name)
+		}
+
+		r.Equal([]string{"vol", "vol@first", "vol@second"}, names)
+
+		first := openNBDExport(t, exports, "vol@first")
+		second := openNBDExport(t, exports, "vol@second")
+
+		blockEqual(t, testRandX.BlockView(0), first.read(t, 0, BlockSize))
+		blockEqual(t, testExtent.BlockView(0), second.read(t, 0, BlockSize))
+
+		first.close(t)
+		second.close(t)
+
+		r.NoError(exports.ReleaseSnapshot("first"))
+		r.ErrorIs(exports.ReleaseSnapshot("first"), ErrSnapshotExportNotFound)
+
+		r.Len(exports.Exports(), 2)
+	})
+
+	t.Run("controls snapshot exports over http", func(t *testing.T) {
+		r := require.New(t)
+
+		dir, err := os.MkdirTemp("", "lsvd")
+		r.NoError(err)
+		defer os.RemoveAll(dir)
+
+		d, err := NewDisk(ctx, log, dir)
+		r.NoError(err)
+		defer d.Close(ctx)
+
+		exports := NewNBDExports(ctx, log, d, "vol")
+		defer exports.Close()
+
+		srv := httptest.NewServer(exports.Handler())
+		defer srv.Close()
+
+		do := func(method, path string) *http.Response {
+			req, err := http.NewRequest(method, srv.URL+path, nil)
+			r.NoError(err)
+
+			resp, err := http.DefaultClient.Do(req)
+			r.NoError(err)
+
+			return resp
+		}
+
+		resp := do(http.MethodPut, "/snapshots/backup")
+		r.Equal(http.StatusCreated, resp.StatusCode)
+
+		var se SnapshotExport
+		r.NoError(json.NewDecoder(resp.Body).Decode(&se))
+		resp.Body.Close()
+
+		r.Equal("backup", se.Name)
+		r.Equal("vol@backup", se.Export)
+
+		resp = do(http.MethodPut, "/snapshots/backup")
+		resp.Body.Close()
+		r.Equal(http.StatusConflict, resp.StatusCode)
+
+		resp = do(http.MethodGet, "/snapshots")
+
+		var list struct {
+			Snapshots []SnapshotExport `json:"snapshots"`
+		}
+		r.NoError(json.NewDecoder(resp.Body).Decode(&list))
+		resp.Body.Close()
+
+		r.Len(list.Snapshots, 1)
+		r.Equal("vol@backup", list.Snapshots[0].Export)
+
+		resp = do(http.MethodDelete, "/snapshots/backup")
+		resp.Body.Close()
+		r.Equal(http.StatusNoContent, resp.StatusCode)
+
+		resp = do(http.MethodDelete, "/snapshots/backup")
+		resp.Body.Close()
+		r.Equal(http.StatusNotFound, resp.StatusCode)
+	})
+}
@@ -1,6 +1,10 @@
 package lsvd
 
-import "github.com/oklog/ulid/v2"
+import (
+	"time"
+
+	"github.com/oklog/ulid/v2"
+)
 
 type opts struct {
 	sa         SegmentAccess
@@ -13,6 +17,10 @@ type opts struct {
 	useZstd    bool
 
 	autoGC bool
+
+	flushPolicy FlushPolicy
+
+	wormRetention time.Duration
 }
 
 type Option func(o *opts)
@@ -65,6 +73,14 @@ func WithZstd() Option {
 	}
 }
 
+// WithFlushPolicy sets the policy deciding when segments are flushed. The
+// default is DefaultFlushPolicy.
+func WithFlushPolicy(p FlushPolicy) Option {
+	return func(o *opts) {
+		o.flushPolicy = p
+	}
+}
+
 var EnableAutoGC = func(o *opts) {
 	o.autoGC = true
 }
@@ -26,6 +26,10 @@ type Export struct {
 	Name        string
 	Description string
 
+	// ReadOnly serves the export read-only regardless of Options.ReadOnly,
+	// rejecting writes and advertising it to clients.
+	ReadOnly bool
+
 	BackendOpen BackendOpen
 	Backend     Backend
 }
@@ -181,6 +185,10 @@ nego:
 					transmissionFlags |= NEGOTIATION_REPLY_FLAGS_CAN_MULTI_CONN
 				}
 
+				if options.ReadOnly || export.ReadOnly {
+					transmissionFlags |= NEGO_FLAG_READONLY
+				}
+
 				log.Debug("sending transmission flags", "flags", uint64(transmissionFlags))
 
 				info := &bytes.Buffer{}
@@ -363,6 +371,12 @@ nego:
 		}
 	}
 
+	if export.ReadOnly && !options.ReadOnly {
+		ro := *options
+		ro.ReadOnly = true
+		options = &ro
+	}
+
 	return HandleTransport(log, conn, backend, options)
 }
 
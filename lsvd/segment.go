@@ -24,6 +24,19 @@ const (
 	FlushTime
 )
 
+func (r FlushReason) String() string {
+	switch r {
+	case FlushNo:
+		return "no"
+	case FlushSize:
+		return "size"
+	case FlushTime:
+		return "time"
+	default:
+		return "unknown"
+	}
+}
+
 type SegmentCreator struct {
 	log *slog.Logger
 
@@ -65,7 +78,8 @@ type SegmentBuilder struct {
 	logW      *bufio.Writer
 	curOffset int64
 
-	openedAt time.Time
+	openedAt     time.Time
+	firstWriteAt time.Time
 
 	em *ExtentMap
 
@@ -203,11 +217,23 @@ func (o *SegmentCreator) ZeroBlocks(rng Extent) error {
 }
 
 func (o *SegmentBuilder) ZeroBlocks(rng Extent) error {
+	o.noteWrite()
 	o.cnt++
 
-	o.extents = append(o.extents, ExtentHeader{
+	eh := ExtentHeader{
 		Extent: rng,
-	})
+	}
+
+	// Logged without a body so that the zeroing is restored after a crash
+	// like any other write.
+	_, n, err := o.writeLog(eh, nil)
+	if err != nil {
+		return err
+	}
+
+	o.offset += uint64(n)
+
+	o.extents = append(o.extents, eh)
 
 	return nil
 }
@@ -232,6 +258,30 @@ func (o *SegmentBuilder) BodySize() int {
 	return int(o.offset)
 }
 
+// noteWrite records when the first write that the segment holds was made.
+func (o *SegmentBuilder) noteWrite() {
+	if o.firstWriteAt.IsZero() {
+		o.firstWriteAt = time.Now()
+	}
+}
+
+// Info describes the builder for a FlushPolicy.
+func (o *SegmentBuilder) Info(now time.Time) OpenSegmentInfo {
+	info := OpenSegmentInfo{
+		BodySize: o.BodySize(),
+	}
+
+	if !o.openedAt.IsZero() {
+		info.Age = now.Sub(o.openedAt)
+	}
+
+	if !o.firstWriteAt.IsZero() {
+		info.Pending = now.Sub(o.firstWriteAt)
+	}
+
+	return info
+}
+
 func (o *SegmentCreator) ShouldFlush(sizeThreshold int) FlushReason {
 	if o.EmptyP() {
 		return FlushNo
@@ -313,10 +363,14 @@ func (o *SegmentBuilder) readLog(f *os.File, log *slog.Logger) error {
 
 		hdrLen, err := eh.Read(br)
 		if err != nil {
-			if errors.Is(err, io.EOF) {
+			if errors.Is(err, io.EOF) && hdrLen == 0 {
 				break
 			}
 
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return o.discardTornWrite(f, log)
+			}
+
 			log.Error("observed error reading extent header", "error", err)
 			return err
 		}
@@ -325,10 +379,15 @@ func (o *SegmentBuilder) readLog(f *os.File, log *slog.Logger) error {
 
 		o.totalBlocks += int(eh.Blocks)
 
+		// Restored writes count as pending from when they were restored
+		o.noteWrite()
 		o.cnt++
 
 		if eh.Size > 0 {
 			n, err := br.Discard(int(eh.Size))
+			if errors.Is(err, io.EOF) {
+				return o.discardTornWrite(f, log)
+			}
 			if err != nil {
 				return errors.Wrapf(err, "error copying body, expecting %d, got %d", eh.Size, n)
 			}
@@ -364,6 +423,23 @@ func (o *SegmentBuilder) readLog(f *os.File, log *slog.Logger) error {
 	return nil
 }
 
+// discardTornWrite truncates a log at the end of its last complete write,
+// dropping one that was cut short by a crash. The write was never
+// acknowledged, and new writes must follow the complete ones.
+func (o *SegmentBuilder) discardTornWrite(f *os.File, log *slog.Logger) error {
+	log.Warn("discarding torn write at end of log", "path", f.Name(), "offset", o.offset)
+
+	if err := f.Truncate(int64(o.offset)); err != nil {
+		return errors.Wrapf(err, "truncating torn write")
+	}
+
+	if _, err := f.Seek(int64(o.offset), io.SeekStart); err != nil {
+		return errors.Wrapf(err, "truncating torn write")
+	}
+
+	return nil
+}
+
 // FillExtent attempts to fill as much of +data+ as possible, returning
 // a list of Extents that was unable to fill. That later list is then
 // feed to the system that reads data from segments.
@@ -583,6 +659,7 @@ func (o *SegmentBuilder) WriteExtent(log *slog.Logger, ext RangeDataView) ([]byt
 		Extent: ext.Extent,
 	}
 
+	o.noteWrite()
 	o.cnt++
 
 	if ext.EmptyP() {
@@ -0,0 +1,188 @@
+package lsvd
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+var ErrSnapshotReleased = errors.New("snapshot has been released")
+
+// Snapshot is a read-only view of a disk as it was when the snapshot was
+// taken. Writes made to the disk afterwards are not visible through it, so it
+// can be used to take a crash-consistent backup while the disk stays in use.
+//
+// The segments a snapshot reads from are kept in storage until it is
+// released, even if they are packed or garbage collected away from the disk.
+type Snapshot struct {
+	d        *Disk
+	m        *ExtentMap
+	segments []SegmentId
+
+	mu       sync.Mutex
+	released bool
+}
+
+// Snapshot flushes pending writes and returns a snapshot of the disk's
+// current contents. The snapshot must be released once it's no longer needed.
+func (d *Disk) Snapshot(ctx context.Context) (*Snapshot, error) {
+	if !d.readOnly {
+		if err := d.CloseSegment(ctx); err != nil {
+			return nil, errors.Wrapf(err, "flushing writes for snapshot")
+		}
+	}
+
+	// The map is cloned while holding snapshotMu so that no segment it
+	// references can be removed before it is pinned.
+	d.snapshotMu.Lock()
+	defer d.snapshotMu.Unlock()
+
+	m := d.lba2pba.Clone()
+
+	seen := make(map[SegmentId]struct{})
+
+	var segments []SegmentId
+
+	for i := m.Iterator(); i.Valid(); i.Next() {
+		pe := i.Value()
+
+		// Only segments of this disk are removed by it
+		if pe.Size == 0 || pe.Disk != 0 {
+			continue
+		}
+
+		if _, ok := seen[pe.Segment]; ok {
+			continue
+		}
+
+		seen[pe.Segment] = struct{}{}
+		segments = append(segments, pe.Segment)
+	}
+
+	if d.pinnedSegments == nil {
+		d.pinnedSegments = make(map[SegmentId]int)
+	}
+
+	for _, seg := range segments {
+		d.pinnedSegments[seg]++
+	}
+
+	d.log.Info("created snapshot", "volume", d.volName, "extents", m.Len(), "segments", len(segments))
+
+	return &Snapshot{
+		d:        d,
+		m:        m,
+		segments: segments,
+	}, nil
+}
+
+// ReadAt fills p with the snapshot's data starting at lba. The length of p
+// must be a multiple of BlockSize.
+func (s *Snapshot) ReadAt(p []byte, lba LBA) (int, error) {
+	if len(p)%BlockSize != 0 {
+		return 0, fmt.Errorf("read of %d bytes is not a multiple of the block size", len(p))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.released {
+		return 0, ErrSnapshotReleased
+	}
+
+	rng := Extent{LBA: lba, Blocks: uint32(len(p) / BlockSize)}
+
+	// Ranges with nothing written read as zeroes
+	clear(p)
+
+	pes, err := s.m.Resolve(s.d.log, rng, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx := NewContext(context.Background())
+	defer ctx.Close()
+
+	data := MapRangeData(rng, p)
+
+	for _, pe := range pes {
+		if pe.Size == 0 {
+			continue
+		}
+
+		ld := s.d.readDisks[pe.Disk]
+
+		err := ld.readPartialExtent(ctx, &pe, []Extent{rng}, rng, data)
+		if err != nil {
+			return 0, errors.Wrapf(err, "reading %s for snapshot", pe.Live)
+		}
+	}
+
+	return len(p), nil
+}
+
+// Release unpins the snapshot's segments, removing any that the disk no
+// longer uses. Reads fail once a snapshot is released.
+func (s *Snapshot) Release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.released {
+		return
+	}
+
+	s.released = true
+
+	for _, seg := range s.d.unpinSegments(s.segments) {
+		if err := s.d.removeSegmentIfPossible(context.Background(), seg); err != nil {
+			s.d.log.Error("error removing segment released by snapshot", "segment", seg, "error", err)
+		}
+	}
+}
+
+// unpinSegments drops a snapshot's pins, returning the segments whose
+// removal was deferred and are no longer pinned.
+func (d *Disk) unpinSegments(segments []SegmentId) []SegmentId {
+	d.snapshotMu.Lock()
+	defer d.snapshotMu.Unlock()
+
+	var ready []SegmentId
+
+	for _, seg := range segments {
+		d.pinnedSegments[seg]--
+
+		if d.pinnedSegments[seg] > 0 {
+			continue
+		}
+
+		delete(d.pinnedSegments, seg)
+
+		if _, ok := d.deferredRemoval[seg]; ok {
+			delete(d.deferredRemoval, seg)
+			ready = append(ready, seg)
+		}
+	}
+
+	return ready
+}
+
+// deferIfPinned reports whether seg is held by a snapshot, recording it to
+// be removed once released if so.
+func (d *Disk) deferIfPinned(seg SegmentId) bool {
+	d.snapshotMu.Lock()
+	defer d.snapshotMu.Unlock()
+
+	if d.pinnedSegments[seg] == 0 {
+		return false
+	}
+
+	if d.deferredRemoval == nil {
+		d.deferredRemoval = make(map[SegmentId]struct{})
+	}
+
+	d.deferredRemoval[seg] = struct{}{}
+
+	return true
+}
@@ -0,0 +1,231 @@
+package lsvd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sync/atomic"
+	"text/tabwriter"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+)
+
+// diskStats counts the IO and GC of a single disk, next to the process wide
+// metrics that every disk adds to.
+type diskStats struct {
+	blocksRead      atomic.Uint64
+	blocksWritten   atomic.Uint64
+	blocksDiscarded atomic.Uint64
+	iops            atomic.Uint64
+
+	gcCycles    atomic.Uint64
+	gcRunning   atomic.Bool
+	gcLast      atomic.Int64
+	gcLastTaken atomic.Int64
+}
+
+// startGC notes that a GC started at start, returning a func to call when it
+// finishes.
+func (s *diskStats) startGC(start time.Time) func() {
+	s.gcCycles.Add(1)
+	s.gcRunning.Store(true)
+
+	return func() {
+		s.gcRunning.Store(false)
+		s.gcLast.Store(start.UnixNano())
+		s.gcLastTaken.Store(int64(time.Since(start)))
+	}
+}
+
+// DiskIOStatus counts the IO a disk has served since it was opened.
+type DiskIOStatus struct {
+	IOPS            uint64 `json:"iops"`
+	BlocksRead      uint64 `json:"blocks_read"`
+	BlocksWritten   uint64 `json:"blocks_written"`
+	BlocksDiscarded uint64 `json:"blocks_discarded"`
+}
+
+// DiskGCStatus describes the GC of a disk.
+type DiskGCStatus struct {
+	Auto     bool          `json:"auto"`
+	Running  bool          `json:"running"`
+	Cycles   uint64        `json:"cycles"`
+	LastRun  time.Time     `json:"last_run,omitzero"`
+	LastTook time.Duration `json:"last_took,omitempty"`
+}
+
+// DiskStatus is a point in time summary of a disk's health and usage.
+type DiskStatus struct {
+	Volume   string `json:"volume"`
+	Size     int64  `json:"size"`
+	ReadOnly bool   `json:"read_only"`
+	Closed   bool   `json:"closed"`
+
+	Segments    int     `json:"segments"`
+	TotalBlocks uint64  `json:"total_blocks"`
+	UsedBlocks  uint64  `json:"used_blocks"`
+	Density     float64 `json:"density"`
+	Extents     int     `json:"extents"`
+
+	IO DiskIOStatus `json:"io"`
+	GC DiskGCStatus `json:"gc"`
+}
+
+// segmentStatus is a copy of a segment's stats for reporting.
+type segmentStatus struct {
+	Id SegmentId
+	Segment
+}
+
+func (s *Segments) statuses() []segmentStatus {
+	s.segmentsMu.Lock()
+	defer s.segmentsMu.Unlock()
+
+	ret := make([]segmentStatus, 0, len(s.segments))
+
+	for id, seg := range s.segments {
+		ret = append(ret, segmentStatus{Id: id, Segment: *seg})
+	}
+
+	slices.SortFunc(ret, func(a, b segmentStatus) int {
+		return ulid.ULID(a.Id).Compare(ulid.ULID(b.Id))
+	})
+
+	return ret
+}
+
+// Status returns the current status of the disk.
+func (d *Disk) Status() DiskStatus {
+	st := DiskStatus{
+		Volume:   d.volName,
+		Size:     d.size,
+		ReadOnly: d.readOnly,
+		Closed:   d.closed.Load() != 0,
+		Extents:  d.lba2pba.Len(),
+		IO: DiskIOStatus{
+			IOPS:            d.stats.iops.Load(),
+			BlocksRead:      d.stats.blocksRead.Load(),
+			BlocksWritten:   d.stats.blocksWritten.Load(),
+			BlocksDiscarded: d.stats.blocksDiscarded.Load(),
+		},
+		GC: DiskGCStatus{
+			Auto:     d.autoGC,
+			Running:  d.stats.gcRunning.Load(),
+			Cycles:   d.stats.gcCycles.Load(),
+			LastTook: time.Duration(d.stats.gcLastTaken.Load()),
+		},
+	}
+
+	if last := d.stats.gcLast.Load(); last != 0 {
+		st.GC.LastRun = time.Unix(0, last)
+	}
+
+	for _, seg := range d.s.statuses() {
+		if seg.deleted {
+			continue
+		}
+
+		st.Segments++
+		st.TotalBlocks += seg.Size
+		st.UsedBlocks += seg.Used
+	}
+
+	if st.TotalBlocks > 0 {
+		st.Density = 100.0 * float64(st.UsedBlocks) / float64(st.TotalBlocks)
+	}
+
+	return st
+}
+
+// DumpState writes a human readable dump of the disk's internal state, for
+// debugging.
+func (d *Disk) DumpState(w io.Writer) error {
+	st := d.Status()
+
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "%s\n\n", data)
+
+	d.snapshotMu.Lock()
+	pinned := len(d.pinnedSegments)
+	deferred := len(d.deferredRemoval)
+	d.snapshotMu.Unlock()
+
+	fmt.Fprintf(w, "pinned segments: %d\ndeferred removals: %d\n\n", pinned, deferred)
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "SEGMENT\tBLOCKS\tUSED\tDENSITY\tEXTENTS\tDELETED")
+
+	for _, seg := range d.s.statuses() {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.1f%%\t%d\t%t\n",
+			seg.Id, seg.Size, seg.Used, 100*seg.Density(), seg.Extents, seg.deleted)
+	}
+
+	return tw.Flush()
+}
+
+// DiskSource returns the disks a StatusHandler reports on, by name.
+type DiskSource func() map[string]*Disk
+
+// StatusHandler serves the status of many disks in one place, for a node
+// hosting several volumes:
+//
+//	GET /volumes               status of every disk, as JSON
+//	GET /volumes/{name}/state  internal state dump of one disk
+//	GET /metrics               the metrics of MetricsHandler
+func StatusHandler(disks DiskSource) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /volumes", func(w http.ResponseWriter, r *http.Request) {
+		all := disks()
+
+		names := make([]string, 0, len(all))
+		for name := range all {
+			names = append(names, name)
+		}
+
+		slices.Sort(names)
+
+		type volumeStatus struct {
+			Name string `json:"name"`
+			DiskStatus
+		}
+
+		resp := struct {
+			Volumes []volumeStatus `json:"volumes"`
+		}{
+			Volumes: make([]volumeStatus, 0, len(names)),
+		}
+
+		for _, name := range names {
+			resp.Volumes = append(resp.Volumes, volumeStatus{
+				Name:       name,
+				DiskStatus: all[name].Status(),
+			})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	})
+
+	mux.HandleFunc("GET /volumes/{name}/state", func(w http.ResponseWriter, r *http.Request) {
+		d, ok := disks()[r.PathValue("name")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		d.DumpState(w)
+	})
+
+	mux.Handle("GET /metrics", MetricsHandler())
+
+	return mux
+}
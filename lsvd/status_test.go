@@ -0,0 +1,95 @@
+package lsvd
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusHandler(t *testing.T) {
+	r := require.New(t)
+
+	log := slog.Default()
+	ctx := NewContext(context.Background())
+
+	open := func(name string) *Disk {
+		tmpdir, err := os.MkdirTemp("", "lsvd")
+		r.NoError(err)
+		t.Cleanup(func() { os.RemoveAll(tmpdir) })
+
+		d, err := NewDisk(ctx, log, tmpdir, WithVolumeName(name))
+		r.NoError(err)
+		t.Cleanup(func() { d.Close(ctx) })
+
+		return d
+	}
+
+	a := open("a")
+	b := open("b")
+
+	err := a.WriteExtent(ctx, testRandX.MapTo(0))
+	r.NoError(err)
+
+	r.NoError(a.CloseSegment(ctx))
+
+	_, err = b.ReadExtent(ctx, Extent{LBA: 0, Blocks: 1})
+	r.NoError(err)
+
+	srv := httptest.NewServer(StatusHandler(func() map[string]*Disk {
+		return map[string]*Disk{"a": a, "b": b}
+	}))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/volumes")
+	r.NoError(err)
+	defer resp.Body.Close()
+
+	r.Equal(http.StatusOK, resp.StatusCode)
+
+	var body struct {
+		Volumes []struct {
+			Name string `json:"name"`
+			DiskStatus
+		} `json:"volumes"`
+	}
+
+	r.NoError(json.NewDecoder(resp.Body).Decode(&body))
+	r.Len(body.Volumes, 2)
+
+	va, vb := body.Volumes[0], body.Volumes[1]
+
+	r.Equal("a", va.Name)
+	r.Equal("a", va.Volume)
+	r.Equal(uint64(1), va.IO.BlocksWritten)
+	r.Zero(va.IO.BlocksRead)
+	r.Equal(1, va.Segments)
+	r.Equal(uint64(1), va.UsedBlocks)
+
+	r.Equal("b", vb.Name)
+	r.Equal(uint64(1), vb.IO.BlocksRead)
+	r.Zero(vb.IO.BlocksWritten)
+	r.Zero(vb.Segments)
+
+	resp, err = http.Get(srv.URL + "/volumes/a/state")
+	r.NoError(err)
+	defer resp.Body.Close()
+
+	r.Equal(http.StatusOK, resp.StatusCode)
+
+	state, err := io.ReadAll(resp.Body)
+	r.NoError(err)
+	r.Contains(string(state), "SEGMENT")
+
+	resp, err = http.Get(srv.URL + "/volumes/c/state")
+	r.NoError(err)
+	resp.Body.Close()
+
+	r.Equal(http.StatusNotFound, resp.StatusCode)
+}
@@ -0,0 +1,245 @@
+package lsvd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+// superblockName is the file at the root of a LocalFileAccess directory that
+// maps volume names to the directories under volumes/ holding their data.
+// Volumes without an entry live in the directory matching their name.
+const superblockName = "superblock.json"
+
+// superblockStagePattern names superblocks written but not yet committed.
+// Any left behind by a crash are removed by InitContainer.
+const superblockStagePattern = "superblock-*.tmp"
+
+var ErrSwapConflict = errors.New("volume superblock changed during swap")
+
+// VolumeSwapper is implemented by segment access backends that can exchange
+// the contents of two volumes atomically.
+type VolumeSwapper interface {
+	SwapVolumes(ctx context.Context, a, b string) error
+}
+
+var _ VolumeSwapper = (*LocalFileAccess)(nil)
+
+type superblock struct {
+	Generation uint64            `json:"generation"`
+	Backing    map[string]string `json:"backing,omitempty"`
+}
+
+func (sb *superblock) backing(vol string) string {
+	if b, ok := sb.Backing[vol]; ok {
+		return b
+	}
+
+	return vol
+}
+
+func (sb *superblock) setBacking(vol, backing string) {
+	if vol == backing {
+		delete(sb.Backing, vol)
+		return
+	}
+
+	sb.Backing[vol] = backing
+}
+
+// swapped returns the next generation of sb with the backing directories of a
+// and b exchanged.
+func (sb *superblock) swapped(a, b string) *superblock {
+	next := &superblock{
+		Generation: sb.Generation + 1,
+		Backing:    make(map[string]string, len(sb.Backing)+2),
+	}
+
+	for vol, backing := range sb.Backing {
+		next.Backing[vol] = backing
+	}
+
+	ba, bb := sb.backing(a), sb.backing(b)
+	next.setBacking(a, bb)
+	next.setBacking(b, ba)
+
+	return next
+}
+
+func (l *LocalFileAccess) readSuperblock() (*superblock, error) {
+	data, err := os.ReadFile(filepath.Join(l.Dir, superblockName))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &superblock{}, nil
+		}
+
+		return nil, err
+	}
+
+	var sb superblock
+	if err := json.Unmarshal(data, &sb); err != nil {
+		return nil, errors.Wrap(err, "decoding superblock")
+	}
+
+	return &sb, nil
+}
+
+// volumeDir returns the directory currently holding the data for vol.
+func (l *LocalFileAccess) volumeDir(vol string) (string, error) {
+	sb, err := l.readSuperblock()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(l.Dir, "volumes", sb.backing(vol)), nil
+}
+
+// SwapVolumes atomically exchanges the data of volumes a and b, so that
+// opening a afterwards reads what was in b and the other way around. The swap
+// is committed by renaming a new generation of the superblock over the old
+// one, so a crash leaves either the old or the new mapping and never a mix.
+// Volumes that are already open keep the data they were opened with.
+func (l *LocalFileAccess) SwapVolumes(ctx context.Context, a, b string) error {
+	if a == b {
+		return fmt.Errorf("cannot swap volume %s with itself", a)
+	}
+
+	unlock, err := l.lockSuperblock()
+	if err != nil {
+		return err
+	}
+
+	defer unlock()
+
+	sb, err := l.readSuperblock()
+	if err != nil {
+		return err
+	}
+
+	for _, vol := range []string{a, b} {
+		_, err := os.Stat(filepath.Join(l.Dir, "volumes", sb.backing(vol), "info.json"))
+		if err != nil {
+			return errors.Wrapf(err, "unknown volume: %s", vol)
+		}
+	}
+
+	next := sb.swapped(a, b)
+
+	path, err := l.stageSuperblock(next)
+	if err != nil {
+		return err
+	}
+
+	if err := l.commitSuperblock(sb.Generation, path); err != nil {
+		os.Remove(path)
+		return err
+	}
+
+	l.Log.Info("swapped volumes", "a", a, "b", b, "generation", next.Generation)
+
+	return nil
+}
+
+// stageSuperblock durably writes sb alongside the live superblock and returns
+// its path, ready to be committed.
+func (l *LocalFileAccess) stageSuperblock(sb *superblock) (string, error) {
+	data, err := json.Marshal(sb)
+	if err != nil {
+		return "", err
+	}
+
+	f, err := os.CreateTemp(l.Dir, superblockStagePattern)
+	if err != nil {
+		return "", err
+	}
+
+	defer f.Close()
+
+	if _, err := f.Write(data); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+
+	if err := f.Sync(); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+
+	return f.Name(), nil
+}
+
+// commitSuperblock makes the superblock staged at path live, provided the live
+// superblock is still at the expected generation.
+func (l *LocalFileAccess) commitSuperblock(expected uint64, path string) error {
+	cur, err := l.readSuperblock()
+	if err != nil {
+		return err
+	}
+
+	if cur.Generation != expected {
+		return errors.Wrapf(ErrSwapConflict, "expected generation %d, found %d", expected, cur.Generation)
+	}
+
+	if err := os.Rename(path, filepath.Join(l.Dir, superblockName)); err != nil {
+		return err
+	}
+
+	dir, err := os.Open(l.Dir)
+	if err != nil {
+		return err
+	}
+
+	defer dir.Close()
+
+	return dir.Sync()
+}
+
+// lockSuperblock serializes superblock updates between processes sharing the
+// directory.
+func (l *LocalFileAccess) lockSuperblock() (func(), error) {
+	f, err := os.OpenFile(filepath.Join(l.Dir, superblockName+".lock"), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := unix.Flock(int(f.Fd()), unix.LOCK_EX); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return func() {
+		unix.Flock(int(f.Fd()), unix.LOCK_UN)
+		f.Close()
+	}, nil
+}
+
+// removeStagedSuperblocks removes superblocks that were staged but never
+// committed, such as when the process crashed mid-swap.
+func (l *LocalFileAccess) removeStagedSuperblocks() error {
+	unlock, err := l.lockSuperblock()
+	if err != nil {
+		return err
+	}
+
+	defer unlock()
+
+	staged, err := filepath.Glob(filepath.Join(l.Dir, superblockStagePattern))
+	if err != nil {
+		return err
+	}
+
+	for _, path := range staged {
+		l.Log.Warn("removing uncommitted superblock", "path", path)
+
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,149 @@
+package lsvd
+
+import (
+	"context"
+	"crypto/rand"
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSwapVolumes(t *testing.T) {
+	log := slog.Default()
+
+	gctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ctx := NewContext(gctx)
+
+	// writeVolume creates the named volume in sa, filled with random data.
+	writeVolume := func(t *testing.T, sa *LocalFileAccess, name string) RangeData {
+		r := require.New(t)
+
+		d, err := NewDisk(ctx, log, t.TempDir(),
+			WithSegmentAccess(sa),
+			WithVolumeName(name),
+		)
+		r.NoError(err)
+
+		data := NewRangeData(ctx, Extent{0, 4})
+		_, err = io.ReadFull(rand.Reader, data.WriteData())
+		r.NoError(err)
+
+		r.NoError(d.WriteExtent(ctx, data))
+		r.NoError(d.Close(ctx))
+
+		return data
+	}
+
+	// readVolume opens the named volume fresh, with no local state, and
+	// returns its contents.
+	readVolume := func(t *testing.T, sa *LocalFileAccess, name string) []byte {
+		r := require.New(t)
+
+		d, err := NewDisk(ctx, log, t.TempDir(),
+			WithSegmentAccess(sa),
+			WithVolumeName(name),
+			ReadOnly(),
+		)
+		r.NoError(err)
+		defer d.Close(ctx)
+
+		data, err := d.ReadExtent(ctx, Extent{0, 4})
+		r.NoError(err)
+
+		return append([]byte(nil), data.ReadData()...)
+	}
+
+	t.Run("volumes read each other's data after a swap", func(t *testing.T) {
+		r := require.New(t)
+
+		sa := &LocalFileAccess{Dir: t.TempDir(), Log: log}
+
+		blue := writeVolume(t, sa, "blue")
+		green := writeVolume(t, sa, "green")
+
+		r.NoError(sa.SwapVolumes(ctx, "blue", "green"))
+
+		blockEqual(t, green.ReadData(), readVolume(t, sa, "blue"))
+		blockEqual(t, blue.ReadData(), readVolume(t, sa, "green"))
+
+		info, err := sa.GetVolumeInfo(ctx, "blue")
+		r.NoError(err)
+		r.Equal("blue", info.Name)
+
+		vols, err := sa.ListVolumes(ctx)
+		r.NoError(err)
+		r.ElementsMatch([]string{"blue", "green"}, vols)
+
+		// Swapping back restores the original mapping.
+		r.NoError(sa.SwapVolumes(ctx, "green", "blue"))
+
+		blockEqual(t, blue.ReadData(), readVolume(t, sa, "blue"))
+		blockEqual(t, green.ReadData(), readVolume(t, sa, "green"))
+
+		sb, err := sa.readSuperblock()
+		r.NoError(err)
+		r.Equal(uint64(2), sb.Generation)
+		r.Empty(sb.Backing)
+	})
+
+	t.Run("rejects unknown volumes", func(t *testing.T) {
+		r := require.New(t)
+
+		sa := &LocalFileAccess{Dir: t.TempDir(), Log: log}
+
+		writeVolume(t, sa, "blue")
+
+		r.Error(sa.SwapVolumes(ctx, "blue", "missing"))
+		r.Error(sa.SwapVolumes(ctx, "blue", "blue"))
+	})
+
+	t.Run("a crash mid-swap leaves one state or the other", func(t *testing.T) {
+		r := require.New(t)
+
+		dir := t.TempDir()
+		sa := &LocalFileAccess{Dir: dir, Log: log}
+
+		blue := writeVolume(t, sa, "blue")
+		green := writeVolume(t, sa, "green")
+
+		// Crash after the new superblock is staged but before it is
+		// committed: the swap never happened.
+		sb, err := sa.readSuperblock()
+		r.NoError(err)
+
+		staged, err := sa.stageSuperblock(sb.swapped("blue", "green"))
+		r.NoError(err)
+
+		sa = &LocalFileAccess{Dir: dir, Log: log}
+		r.NoError(sa.InitContainer(ctx))
+
+		_, err = os.Stat(staged)
+		r.ErrorIs(err, os.ErrNotExist, "uncommitted superblock should be cleaned up")
+
+		blockEqual(t, blue.ReadData(), readVolume(t, sa, "blue"))
+		blockEqual(t, green.ReadData(), readVolume(t, sa, "green"))
+
+		// Crash right after the commit: the swap is complete.
+		staged, err = sa.stageSuperblock(sb.swapped("blue", "green"))
+		r.NoError(err)
+		r.NoError(sa.commitSuperblock(sb.Generation, staged))
+
+		sa = &LocalFileAccess{Dir: dir, Log: log}
+		r.NoError(sa.InitContainer(ctx))
+
+		blockEqual(t, green.ReadData(), readVolume(t, sa, "blue"))
+		blockEqual(t, blue.ReadData(), readVolume(t, sa, "green"))
+
+		// A swap planned against a stale generation is refused.
+		staged, err = sa.stageSuperblock(sb.swapped("blue", "green"))
+		r.NoError(err)
+		r.ErrorIs(sa.commitSuperblock(sb.Generation, staged), ErrSwapConflict)
+
+		blockEqual(t, green.ReadData(), readVolume(t, sa, "blue"))
+	})
+}
@@ -0,0 +1,602 @@
+package lsvd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultTieringInterval is how often a TierManager moves cold segments off
+// the hot store if its policy doesn't say.
+const DefaultTieringInterval = time.Minute
+
+// TieringPolicy decides which segments a TierManager keeps on the hot store.
+// Segments are always kept on the cold store; tiering only decides whether
+// they also have a copy on the hot one.
+type TieringPolicy struct {
+	// LocalCapacity is how many bytes of segments to keep on the hot store.
+	// Once they take more, the least recently used segments are demoted
+	// until they fit. 0 means no limit.
+	LocalCapacity int64
+
+	// ColdAfter demotes segments that haven't been read for this long, no
+	// matter how much room there is. 0 keeps segments until they're needed
+	// to fit LocalCapacity.
+	ColdAfter time.Duration
+
+	// Interval is how often Run applies the policy, DefaultTieringInterval
+	// if 0.
+	Interval time.Duration
+}
+
+// TierManager is a SegmentAccess that keeps frequently read segments on a
+// fast, local hot store and the rest only on a remote cold one. Segments are
+// written to both stores, and the copies on the hot store of those that have
+// gone cold are removed by Tier according to the policy. A segment that
+// isn't on the hot store is fetched back into it from the cold store when
+// it's opened, so readers never see the difference.
+//
+// Removing a hot copy that's still open doesn't free its space until it's
+// closed.
+type TierManager struct {
+	log    *slog.Logger
+	hot    SegmentAccess
+	cold   SegmentAccess
+	policy TieringPolicy
+
+	now func() time.Time
+
+	mu      sync.Mutex
+	volumes map[string]*tieredVolume
+}
+
+var _ SegmentAccess = (*TierManager)(nil)
+
+// NewTierManager returns a TierManager tiering segments between hot and
+// cold according to policy.
+func NewTierManager(log *slog.Logger, hot, cold SegmentAccess, policy TieringPolicy) *TierManager {
+	return &TierManager{
+		log:     log.With("module", "lsvd-tiering"),
+		hot:     hot,
+		cold:    cold,
+		policy:  policy,
+		now:     time.Now,
+		volumes: make(map[string]*tieredVolume),
+	}
+}
+
+func (m *TierManager) InitContainer(ctx context.Context) error {
+	if err := m.hot.InitContainer(ctx); err != nil {
+		return err
+	}
+
+	return m.cold.InitContainer(ctx)
+}
+
+func (m *TierManager) InitVolume(ctx context.Context, vol *VolumeInfo) error {
+	if err := vol.Normalize(); err != nil {
+		return err
+	}
+
+	if err := m.hot.InitVolume(ctx, vol); err != nil {
+		return err
+	}
+
+	return m.cold.InitVolume(ctx, vol)
+}
+
+func (m *TierManager) ListVolumes(ctx context.Context) ([]string, error) {
+	return m.cold.ListVolumes(ctx)
+}
+
+func (m *TierManager) GetVolumeInfo(ctx context.Context, vol string) (*VolumeInfo, error) {
+	info, err := m.hot.GetVolumeInfo(ctx, vol)
+	if err == nil {
+		return info, nil
+	}
+
+	return m.cold.GetVolumeInfo(ctx, vol)
+}
+
+func (m *TierManager) RemoveSegment(ctx context.Context, seg SegmentId) error {
+	if err := m.hot.RemoveSegment(ctx, seg); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	for _, tv := range m.volumes {
+		tv.forget(seg)
+	}
+	m.mu.Unlock()
+
+	return m.cold.RemoveSegment(ctx, seg)
+}
+
+// OpenVolume opens vol on both stores. Its segments already on the hot
+// store count as just used.
+func (m *TierManager) OpenVolume(ctx context.Context, vol string) (Volume, error) {
+	m.mu.Lock()
+	tv, ok := m.volumes[vol]
+	m.mu.Unlock()
+
+	if ok {
+		return tv, nil
+	}
+
+	hot, err := m.hot.OpenVolume(ctx, vol)
+	if err != nil {
+		return nil, fmt.Errorf("hot store: %w", err)
+	}
+
+	cold, err := m.cold.OpenVolume(ctx, vol)
+	if err != nil {
+		return nil, fmt.Errorf("cold store: %w", err)
+	}
+
+	tv = &tieredVolume{
+		m:        m,
+		name:     vol,
+		hot:      hot,
+		cold:     cold,
+		local:    make(map[SegmentId]*tierRecord),
+		fetching: make(map[SegmentId]chan struct{}),
+	}
+
+	segs, err := hot.ListSegments(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("hot store: %w", err)
+	}
+
+	for _, seg := range segs {
+		tv.track(seg, -1)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if existing, ok := m.volumes[vol]; ok {
+		return existing, nil
+	}
+
+	m.volumes[vol] = tv
+
+	return tv, nil
+}
+
+// Run applies the policy every Interval until ctx is done.
+func (m *TierManager) Run(ctx context.Context) error {
+	interval := m.policy.Interval
+	if interval <= 0 {
+		interval = DefaultTieringInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if _, err := m.Tier(ctx); err != nil {
+				m.log.Error("error tiering segments", "error", err)
+			}
+		}
+	}
+}
+
+// TierResult describes what a pass of Tier moved.
+type TierResult struct {
+	Demoted      int
+	DemotedBytes int64
+
+	// LocalBytes is how many bytes of segments are left on the hot store.
+	LocalBytes int64
+}
+
+// Tier applies the policy once, removing the hot copies of the segments of
+// the open volumes that have gone cold.
+func (m *TierManager) Tier(ctx context.Context) (TierResult, error) {
+	m.mu.Lock()
+	volumes := make([]*tieredVolume, 0, len(m.volumes))
+	for _, tv := range m.volumes {
+		volumes = append(volumes, tv)
+	}
+	m.mu.Unlock()
+
+	type candidate struct {
+		tv   *tieredVolume
+		seg  SegmentId
+		rec  *tierRecord
+		last time.Time
+	}
+
+	var (
+		res        TierResult
+		candidates []candidate
+	)
+
+	for _, tv := range volumes {
+		for seg, rec := range tv.records() {
+			size, err := tv.segmentSize(ctx, seg, rec)
+			if err != nil {
+				m.log.Warn("unable to size hot segment", "volume", tv.name, "segment", seg, "error", err)
+				continue
+			}
+
+			res.LocalBytes += size
+			candidates = append(candidates, candidate{tv, seg, rec, rec.lastAccess()})
+		}
+	}
+
+	// Least recently used first
+	slices.SortFunc(candidates, func(a, b candidate) int {
+		return a.last.Compare(b.last)
+	})
+
+	now := m.now()
+
+	// The cold segments of each volume, read once it has something to demote
+	coldSegs := make(map[*tieredVolume]map[SegmentId]struct{})
+
+	for _, c := range candidates {
+		cold := m.policy.ColdAfter > 0 && now.Sub(c.last) >= m.policy.ColdAfter
+		over := m.policy.LocalCapacity > 0 && res.LocalBytes > m.policy.LocalCapacity
+
+		if !cold && !over {
+			continue
+		}
+
+		onCold, ok := coldSegs[c.tv]
+		if !ok {
+			segs, err := c.tv.cold.ListSegments(ctx)
+			if err != nil {
+				return res, fmt.Errorf("listing cold segments of %s: %w", c.tv.name, err)
+			}
+
+			onCold = make(map[SegmentId]struct{}, len(segs))
+			for _, seg := range segs {
+				onCold[seg] = struct{}{}
+			}
+
+			coldSegs[c.tv] = onCold
+		}
+
+		// Never drop the only copy of a segment
+		if _, ok := onCold[c.seg]; !ok {
+			m.log.Warn("hot segment missing from cold store, keeping it", "volume", c.tv.name, "segment", c.seg)
+			continue
+		}
+
+		size := c.rec.size.Load()
+
+		if err := c.tv.demote(ctx, c.seg); err != nil {
+			return res, err
+		}
+
+		res.Demoted++
+		res.DemotedBytes += size
+		res.LocalBytes -= size
+
+		tierMovements.WithLabelValues("demote").Inc()
+
+		m.log.Debug("demoted segment", "volume", c.tv.name, "segment", c.seg, "size", size, "cold", cold)
+	}
+
+	tierLocalBytes.Set(float64(res.LocalBytes))
+
+	return res, nil
+}
+
+// tierRecord tracks a segment on the hot store.
+type tierRecord struct {
+	// size is the size of the segment, or -1 until it's known
+	size atomic.Int64
+
+	// last is when the segment was last read, in unix nanoseconds
+	last atomic.Int64
+}
+
+func (r *tierRecord) touch(now time.Time) {
+	r.last.Store(now.UnixNano())
+}
+
+func (r *tierRecord) lastAccess() time.Time {
+	return time.Unix(0, r.last.Load())
+}
+
+type tieredVolume struct {
+	m    *TierManager
+	name string
+	hot  Volume
+	cold Volume
+
+	mu       sync.Mutex
+	local    map[SegmentId]*tierRecord
+	fetching map[SegmentId]chan struct{}
+}
+
+func (v *tieredVolume) track(seg SegmentId, size int64) *tierRecord {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	rec, ok := v.local[seg]
+	if !ok {
+		rec = &tierRecord{}
+		v.local[seg] = rec
+	}
+
+	rec.size.Store(size)
+	rec.touch(v.m.now())
+
+	return rec
+}
+
+func (v *tieredVolume) forget(seg SegmentId) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	delete(v.local, seg)
+}
+
+func (v *tieredVolume) record(seg SegmentId) *tierRecord {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	return v.local[seg]
+}
+
+func (v *tieredVolume) records() map[SegmentId]*tierRecord {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	recs := make(map[SegmentId]*tierRecord, len(v.local))
+	for seg, rec := range v.local {
+		recs[seg] = rec
+	}
+
+	return recs
+}
+
+// segmentSize returns the size of the hot copy of seg, finding it if it
+// isn't known yet.
+func (v *tieredVolume) segmentSize(ctx context.Context, seg SegmentId, rec *tierRecord) (int64, error) {
+	if size := rec.size.Load(); size >= 0 {
+		return size, nil
+	}
+
+	sr, err := v.hot.OpenSegment(ctx, seg)
+	if err != nil {
+		return 0, err
+	}
+
+	defer sr.Close()
+
+	size, err := segmentReaderSize(sr)
+	if err != nil {
+		return 0, err
+	}
+
+	rec.size.Store(size)
+
+	return size, nil
+}
+
+// segmentReaderSize returns how many bytes sr holds.
+func segmentReaderSize(sr SegmentReader) (int64, error) {
+	if s, ok := sr.(interface{ Size() (int64, error) }); ok {
+		return s.Size()
+	}
+
+	return io.Copy(io.Discard, io.NewSectionReader(sr, 0, math.MaxInt64))
+}
+
+// demote removes the hot copy of seg.
+func (v *tieredVolume) demote(ctx context.Context, seg SegmentId) error {
+	v.forget(seg)
+
+	if err := v.hot.RemoveSegment(ctx, seg); err != nil {
+		return fmt.Errorf("removing hot segment %s: %w", seg, err)
+	}
+
+	if err := v.m.hot.RemoveSegment(ctx, seg); err != nil {
+		return fmt.Errorf("removing hot segment %s: %w", seg, err)
+	}
+
+	return nil
+}
+
+func (v *tieredVolume) Info(ctx context.Context) (*VolumeInfo, error) {
+	info, err := v.hot.Info(ctx)
+	if err == nil {
+		return info, nil
+	}
+
+	return v.cold.Info(ctx)
+}
+
+// ListSegments returns the segments of the volume, which the cold store has
+// all of.
+func (v *tieredVolume) ListSegments(ctx context.Context) ([]SegmentId, error) {
+	hot, err := v.hot.ListSegments(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("hot store: %w", err)
+	}
+
+	cold, err := v.cold.ListSegments(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cold store: %w", err)
+	}
+
+	return composeSegmentList(slices.Clone(hot), slices.Clone(cold))
+}
+
+// OpenSegment opens the hot copy of seg, fetching it from the cold store
+// first if it's been demoted.
+func (v *tieredVolume) OpenSegment(ctx context.Context, seg SegmentId) (SegmentReader, error) {
+	rec := v.record(seg)
+	if rec == nil {
+		var err error
+
+		rec, err = v.rehydrate(ctx, seg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sr, err := v.hot.OpenSegment(ctx, seg)
+	if errors.Is(err, os.ErrNotExist) {
+		// The hot copy went missing behind our back, so fetch it again
+		v.forget(seg)
+
+		rec, err = v.rehydrate(ctx, seg)
+		if err != nil {
+			return nil, err
+		}
+
+		sr, err = v.hot.OpenSegment(ctx, seg)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("hot store: %w", err)
+	}
+
+	rec.touch(v.m.now())
+
+	return &tieredReader{SegmentReader: sr, v: v, rec: rec}, nil
+}
+
+// rehydrate copies seg from the cold store back into the hot one. Only one
+// copy of a segment is fetched at a time; others opening it wait for it.
+func (v *tieredVolume) rehydrate(ctx context.Context, seg SegmentId) (*tierRecord, error) {
+	for {
+		v.mu.Lock()
+
+		if rec, ok := v.local[seg]; ok {
+			v.mu.Unlock()
+			return rec, nil
+		}
+
+		wait, ok := v.fetching[seg]
+		if !ok {
+			done := make(chan struct{})
+			v.fetching[seg] = done
+			v.mu.Unlock()
+
+			rec, err := v.fetch(ctx, seg)
+
+			v.mu.Lock()
+			delete(v.fetching, seg)
+			v.mu.Unlock()
+			close(done)
+
+			return rec, err
+		}
+
+		v.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-wait:
+		}
+	}
+}
+
+func (v *tieredVolume) fetch(ctx context.Context, seg SegmentId) (*tierRecord, error) {
+	start := time.Now()
+
+	sr, err := v.cold.OpenSegment(ctx, seg)
+	if err != nil {
+		return nil, fmt.Errorf("cold store: %w", err)
+	}
+
+	defer sr.Close()
+
+	layout, err := sr.Layout(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("reading layout of cold segment %s: %w", seg, err)
+	}
+
+	tmp, err := os.CreateTemp("", "lsvd-rehydrate-*")
+	if err != nil {
+		return nil, err
+	}
+
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	size, err := io.Copy(tmp, io.NewSectionReader(sr, 0, math.MaxInt64))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("fetching cold segment %s: %w", seg, err)
+	}
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	if err := v.hot.NewSegment(ctx, seg, layout, tmp); err != nil {
+		return nil, fmt.Errorf("rehydrating segment %s: %w", seg, err)
+	}
+
+	tierMovements.WithLabelValues("rehydrate").Inc()
+	tierFetchLatency.Observe(time.Since(start).Seconds())
+
+	v.m.log.Debug("rehydrated segment", "volume", v.name, "segment", seg, "size", size, "elapsed", time.Since(start))
+
+	return v.track(seg, size), nil
+}
+
+func (v *tieredVolume) NewSegment(ctx context.Context, seg SegmentId, layout *SegmentLayout, data *os.File) error {
+	fi, err := data.Stat()
+	if err != nil {
+		return err
+	}
+
+	if err := v.hot.NewSegment(ctx, seg, layout, data); err != nil {
+		return fmt.Errorf("hot store: %w", err)
+	}
+
+	if _, err := data.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	if err := v.cold.NewSegment(ctx, seg, layout, data); err != nil {
+		return fmt.Errorf("cold store: %w", err)
+	}
+
+	v.track(seg, fi.Size())
+
+	return nil
+}
+
+func (v *tieredVolume) RemoveSegment(ctx context.Context, seg SegmentId) error {
+	v.forget(seg)
+
+	if err := v.hot.RemoveSegment(ctx, seg); err != nil {
+		return err
+	}
+
+	return v.cold.RemoveSegment(ctx, seg)
+}
+
+// tieredReader records reads of a segment, so the ones in use stay hot.
+type tieredReader struct {
+	SegmentReader
+
+	v   *tieredVolume
+	rec *tierRecord
+}
+
+func (r *tieredReader) ReadAt(b []byte, off int64) (int, error) {
+	r.rec.touch(r.v.m.now())
+	return r.SegmentReader.ReadAt(b, off)
+}
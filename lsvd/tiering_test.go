@@ -0,0 +1,203 @@
+package lsvd
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock is a clock tests move by hand.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+func TestTierManager(t *testing.T) {
+	const segSize = 1024
+
+	// setup returns a tier manager over fresh hot and cold stores, with a
+	// volume holding count segments written through it.
+	setup := func(t *testing.T, policy TieringPolicy, count int) (*TierManager, *fakeClock, *LocalFileAccess, Volume, []SegmentId) {
+		r := require.New(t)
+		ctx := context.Background()
+
+		hot := &LocalFileAccess{Dir: t.TempDir(), Log: slog.Default()}
+		cold := &LocalFileAccess{Dir: t.TempDir(), Log: slog.Default()}
+
+		clock := &fakeClock{now: time.Now()}
+
+		m := NewTierManager(slog.Default(), hot, cold, policy)
+		m.now = clock.Now
+
+		r.NoError(m.InitContainer(ctx))
+		r.NoError(m.InitVolume(ctx, &VolumeInfo{Name: "test"}))
+
+		vol, err := m.OpenVolume(ctx, "test")
+		r.NoError(err)
+
+		var segs []SegmentId
+
+		for i := range count {
+			seg := SegmentId(ulid.MustNew(uint64(1000+i), testEntropy))
+
+			data, err := os.CreateTemp(t.TempDir(), "segment")
+			r.NoError(err)
+
+			_, err = data.Write(bytes.Repeat([]byte{byte(i)}, segSize))
+			r.NoError(err)
+
+			_, err = data.Seek(0, 0)
+			r.NoError(err)
+
+			r.NoError(vol.NewSegment(ctx, seg, &SegmentLayout{}, data))
+			data.Close()
+
+			segs = append(segs, seg)
+		}
+
+		return m, clock, hot, vol, segs
+	}
+
+	onHot := func(hot *LocalFileAccess, seg SegmentId) bool {
+		_, err := os.Stat(filepath.Join(hot.Dir, "segments", "segment."+ulid.ULID(seg).String()))
+		return err == nil
+	}
+
+	read := func(t *testing.T, vol Volume, seg SegmentId) byte {
+		sr, err := vol.OpenSegment(context.Background(), seg)
+		require.NoError(t, err)
+		defer sr.Close()
+
+		buf := make([]byte, segSize)
+		_, err = sr.ReadAt(buf, 0)
+		require.NoError(t, err)
+
+		return buf[segSize-1]
+	}
+
+	t.Run("demotes aged segments and rehydrates them on read", func(t *testing.T) {
+		r := require.New(t)
+		ctx := context.Background()
+
+		m, clock, hot, vol, segs := setup(t, TieringPolicy{ColdAfter: time.Hour}, 4)
+
+		// Nothing has gone cold yet
+		res, err := m.Tier(ctx)
+		r.NoError(err)
+		r.Zero(res.Demoted)
+		r.Equal(int64(4*segSize), res.LocalBytes)
+
+		// Age every segment but keep reading the last one
+		clock.Advance(50 * time.Minute)
+		read(t, vol, segs[3])
+		clock.Advance(20 * time.Minute)
+
+		rehydrated := counterValue(tierMovements.WithLabelValues("rehydrate"))
+
+		res, err = m.Tier(ctx)
+		r.NoError(err)
+		r.Equal(3, res.Demoted)
+		r.Equal(int64(3*segSize), res.DemotedBytes)
+		r.Equal(int64(segSize), res.LocalBytes)
+
+		for _, seg := range segs[:3] {
+			r.False(onHot(hot, seg), "cold segment should be gone from the hot store")
+		}
+		r.True(onHot(hot, segs[3]))
+
+		// The volume still has every segment
+		all, err := vol.ListSegments(ctx)
+		r.NoError(err)
+		r.Equal(segs, all)
+
+		// Reading a cold segment brings it back
+		r.Equal(byte(1), read(t, vol, segs[1]))
+		r.True(onHot(hot, segs[1]))
+		r.Equal(rehydrated+1, counterValue(tierMovements.WithLabelValues("rehydrate")))
+
+		// and it's now as fresh as any
+		res, err = m.Tier(ctx)
+		r.NoError(err)
+		r.Zero(res.Demoted)
+		r.Equal(int64(2*segSize), res.LocalBytes)
+
+		// Reopening the volume only finds the segments still on the hot store
+		m2 := NewTierManager(slog.Default(), hot, m.cold, TieringPolicy{})
+		vol2, err := m2.OpenVolume(ctx, "test")
+		r.NoError(err)
+
+		r.Equal(byte(0), read(t, vol2, segs[0]))
+		r.Equal(byte(2), read(t, vol2, segs[2]))
+	})
+
+	t.Run("demotes the least recently used segments to fit the local capacity", func(t *testing.T) {
+		r := require.New(t)
+		ctx := context.Background()
+
+		m, clock, hot, vol, segs := setup(t, TieringPolicy{LocalCapacity: 2 * segSize}, 4)
+
+		// Read in an order that makes 2 and 0 the most recently used
+		for _, i := range []int{1, 3, 2, 0} {
+			clock.Advance(time.Second)
+			read(t, vol, segs[i])
+		}
+
+		res, err := m.Tier(ctx)
+		r.NoError(err)
+		r.Equal(2, res.Demoted)
+		r.Equal(int64(2*segSize), res.LocalBytes)
+
+		r.True(onHot(hot, segs[0]))
+		r.False(onHot(hot, segs[1]))
+		r.True(onHot(hot, segs[2]))
+		r.False(onHot(hot, segs[3]))
+
+		for i, seg := range segs {
+			r.Equal(byte(i), read(t, vol, seg))
+		}
+	})
+
+	t.Run("keeps segments the cold store doesn't have", func(t *testing.T) {
+		r := require.New(t)
+		ctx := context.Background()
+
+		m, clock, hot, vol, segs := setup(t, TieringPolicy{ColdAfter: time.Minute}, 2)
+
+		coldVol, err := m.cold.OpenVolume(ctx, "test")
+		r.NoError(err)
+		r.NoError(coldVol.RemoveSegment(ctx, segs[0]))
+
+		clock.Advance(time.Hour)
+
+		res, err := m.Tier(ctx)
+		r.NoError(err)
+		r.Equal(1, res.Demoted)
+
+		r.True(onHot(hot, segs[0]))
+		r.False(onHot(hot, segs[1]))
+
+		r.Equal(byte(0), read(t, vol, segs[0]))
+	})
+}
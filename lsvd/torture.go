@@ -1,6 +1,7 @@
 package lsvd
 
 import (
+	"bufio"
 	"context"
 	"crypto/sha256"
 	"encoding/base64"
@@ -11,6 +12,7 @@ import (
 	"log/slog"
 	"math/rand"
 	"os"
+	"slices"
 	"time"
 )
 
@@ -25,29 +27,33 @@ func HashBlock(data []byte) TortureBlockHash {
 }
 
 // TortureDiskModel is a simple reference model tracking expected disk state
+// in logical blocks of blockSize bytes.
 type TortureDiskModel struct {
-	blocks   map[LBA]TortureBlockHash
-	writeSeq uint64
+	blocks    map[LBA]TortureBlockHash
+	blockSize int
+	writeSeq  uint64
 }
 
-func NewTortureDiskModel() *TortureDiskModel {
+func NewTortureDiskModel(blockSize int) *TortureDiskModel {
 	return &TortureDiskModel{
-		blocks: make(map[LBA]TortureBlockHash),
+		blocks:    make(map[LBA]TortureBlockHash),
+		blockSize: blockSize,
 	}
 }
 
 func (m *TortureDiskModel) WriteExtent(lba LBA, data []byte) {
 	m.writeSeq++
-	blocks := uint32(len(data) / BlockSize)
-	for i := uint32(0); i < blocks; i++ {
-		blockData := data[i*BlockSize : (i+1)*BlockSize]
+	bs := m.blockSize
+	blocks := len(data) / bs
+	for i := 0; i < blocks; i++ {
+		blockData := data[i*bs : (i+1)*bs]
 		m.blocks[lba+LBA(i)] = HashBlock(blockData)
 	}
 }
 
 func (m *TortureDiskModel) ZeroBlocks(lba LBA, blocks uint32) {
 	m.writeSeq++
-	zeroHash := HashBlock(make([]byte, BlockSize))
+	zeroHash := HashBlock(make([]byte, m.blockSize))
 	for i := uint32(0); i < blocks; i++ {
 		m.blocks[lba+LBA(i)] = zeroHash
 	}
@@ -66,6 +72,21 @@ func (m *TortureDiskModel) WrittenLBAs() []LBA {
 	return lbas
 }
 
+// Clone returns a copy of the model that is unaffected by later operations.
+func (m *TortureDiskModel) Clone() *TortureDiskModel {
+	o := &TortureDiskModel{
+		blocks:    make(map[LBA]TortureBlockHash, len(m.blocks)),
+		blockSize: m.blockSize,
+		writeSeq:  m.writeSeq,
+	}
+
+	for lba, h := range m.blocks {
+		o.blocks[lba] = h
+	}
+
+	return o
+}
+
 func (m *TortureDiskModel) BlockCount() int {
 	return len(m.blocks)
 }
@@ -84,6 +105,9 @@ const (
 	TortureOpZero
 	TortureOpSync
 	TortureOpCloseReopen
+	TortureOpSnapshot
+	TortureOpPowerFail
+	TortureOpDiscard
 )
 
 func (o TortureOpType) String() string {
@@ -98,6 +122,12 @@ func (o TortureOpType) String() string {
 		return "sync"
 	case TortureOpCloseReopen:
 		return "close"
+	case TortureOpSnapshot:
+		return "snapshot"
+	case TortureOpPowerFail:
+		return "power-fail"
+	case TortureOpDiscard:
+		return "discard"
 	default:
 		return "unknown"
 	}
@@ -133,6 +163,12 @@ func (o TortureOperation) String() string {
 		return "sync"
 	case TortureOpCloseReopen:
 		return "close/reopen"
+	case TortureOpSnapshot:
+		return "snapshot"
+	case TortureOpPowerFail:
+		return fmt.Sprintf("power-fail seed:%d", o.DataSeed)
+	case TortureOpDiscard:
+		return fmt.Sprintf("discard LBA:%-8d Blocks:%-4d", o.Extent.LBA, o.Extent.Blocks)
 	default:
 		return "unknown"
 	}
@@ -145,6 +181,9 @@ type TortureOpWeights struct {
 	Zero        int `json:"Zero"`
 	Sync        int `json:"Sync"`
 	CloseReopen int `json:"CloseReopen"`
+	Snapshot    int `json:"Snapshot,omitempty"`
+	PowerFail   int `json:"PowerFail,omitempty"`
+	Discard     int `json:"Discard,omitempty"`
 }
 
 // DefaultTortureWeights provides sensible defaults for torture testing
@@ -166,6 +205,12 @@ type TortureConfig struct {
 	OverlapProbability float64          `json:"OverlapProbability"`
 	VerifyEvery        int              `json:"VerifyEvery"`
 	PatternWeights     [4]int           `json:"PatternWeights"` // random, zero, compressible, sequential
+
+	// BlockSize is the size in bytes of the logical blocks that operations
+	// address, with MaxLBA and MaxBlocks counted in them. Logical blocks
+	// smaller than the disk's are read-modify-written, as a guest with
+	// smaller sectors would have them. Zero uses the disk's BlockSize.
+	BlockSize int `json:"BlockSize,omitempty"`
 }
 
 // DefaultTortureConfig provides a sensible default configuration
@@ -177,6 +222,22 @@ var DefaultTortureConfig = TortureConfig{
 	OverlapProbability: 0.3,
 	VerifyEvery:        1000,
 	PatternWeights:     [4]int{60, 10, 20, 10},
+	BlockSize:          BlockSize,
+}
+
+// MinTortureBlockSize is the smallest logical block size a torture run
+// supports.
+const MinTortureBlockSize = 512
+
+// validateTortureBlockSize checks that size is a power of two logical block
+// size that disk blocks divide evenly into.
+func validateTortureBlockSize(size int) error {
+	if size < MinTortureBlockSize || size > BlockSize || size&(size-1) != 0 {
+		return fmt.Errorf("unsupported block size %d: must be a power of two from %d to %d",
+			size, MinTortureBlockSize, BlockSize)
+	}
+
+	return nil
 }
 
 // EncodeTortureConfig encodes a TortureConfig to a base64 JSON string for reproduction
@@ -202,8 +263,9 @@ func DecodeTortureConfig(encoded string) (TortureConfig, error) {
 }
 
 // GenerateTortureData generates deterministic data from a seed and pattern
-func GenerateTortureData(rng *rand.Rand, pattern TortureDataPattern, blocks uint32) []byte {
-	data := make([]byte, blocks*BlockSize)
+// for blocks of blockSize bytes.
+func GenerateTortureData(rng *rand.Rand, pattern TortureDataPattern, blocks uint32, blockSize int) []byte {
+	data := make([]byte, int(blocks)*blockSize)
 
 	switch pattern {
 	case TorturePatternRandom:
@@ -218,7 +280,7 @@ func GenerateTortureData(rng *rand.Rand, pattern TortureDataPattern, blocks uint
 		}
 	case TorturePatternSequential:
 		for i := range data {
-			data[i] = byte((i / BlockSize) ^ (i % 256))
+			data[i] = byte((i / blockSize) ^ (i % 256))
 		}
 	}
 
@@ -240,7 +302,8 @@ func NewTortureGenerator(cfg TortureConfig) *TortureGenerator {
 		cfg: cfg,
 	}
 	g.totalWeight = cfg.Weights.Write + cfg.Weights.Read + cfg.Weights.Zero +
-		cfg.Weights.Sync + cfg.Weights.CloseReopen
+		cfg.Weights.Sync + cfg.Weights.CloseReopen + cfg.Weights.Snapshot +
+		cfg.Weights.PowerFail + cfg.Weights.Discard
 	g.patternTotal = cfg.PatternWeights[0] + cfg.PatternWeights[1] +
 		cfg.PatternWeights[2] + cfg.PatternWeights[3]
 	return g
@@ -259,8 +322,14 @@ func (g *TortureGenerator) nextOpType() TortureOpType {
 		return TortureOpZero
 	case choice < w.Write+w.Read+w.Zero+w.Sync:
 		return TortureOpSync
-	default:
+	case choice < w.Write+w.Read+w.Zero+w.Sync+w.CloseReopen:
 		return TortureOpCloseReopen
+	case choice < w.Write+w.Read+w.Zero+w.Sync+w.CloseReopen+w.Snapshot:
+		return TortureOpSnapshot
+	case choice < w.Write+w.Read+w.Zero+w.Sync+w.CloseReopen+w.Snapshot+w.PowerFail:
+		return TortureOpPowerFail
+	default:
+		return TortureOpDiscard
 	}
 }
 
@@ -368,9 +437,12 @@ func (g *TortureGenerator) Next() TortureOperation {
 		g.lastWrite = op.Extent
 	case TortureOpRead:
 		op.Extent = g.nextExtent(false)
-	case TortureOpZero:
+	case TortureOpZero, TortureOpDiscard:
 		op.Extent = g.nextExtent(true)
 		g.lastWrite = op.Extent
+	case TortureOpPowerFail:
+		// Chooses where the write cache is torn
+		op.DataSeed = g.rng.Int63()
 	}
 
 	return op
@@ -389,10 +461,50 @@ type TortureRunner struct {
 	history []TortureOperation
 	output  io.Writer
 
+	// snapshot is the open snapshot, if any, and snapshotModel the model as
+	// it was when the snapshot was taken.
+	snapshot      *Snapshot
+	snapshotModel *TortureDiskModel
+
+	// discarded are the ranges discarded since the disk was last reopened,
+	// which are checked once it has been.
+	discarded []Extent
+
+	// inFlight are the writes only in the write cache since it was last
+	// synced, which a power failure may lose.
+	inFlight []tortureInFlight
+
+	// powerFails describes each power failure by the index of its operation,
+	// and lostBy maps the index of each write it lost to that of the failure.
+	powerFails map[int]torturePowerFail
+	lostBy     map[int]int
+
 	opCount      int
 	lastProgress time.Time
 }
 
+// tortureInFlight is a write whose durability depends on the write cache.
+type tortureInFlight struct {
+	op         int
+	start, end uint64
+	prev       []tortureBlockState
+}
+
+// tortureBlockState is the model's record of a block before a write.
+type tortureBlockState struct {
+	lba     LBA
+	hash    TortureBlockHash
+	written bool
+}
+
+type torturePowerFail struct {
+	cut      uint64
+	size     uint64
+	corrupt  bool
+	inFlight int
+	lost     int
+}
+
 // TortureResult contains the result of a torture test run
 type TortureResult struct {
 	Success    bool
@@ -404,6 +516,14 @@ type TortureResult struct {
 
 // NewTortureRunner creates a new torture test runner
 func NewTortureRunner(gctx context.Context, log *slog.Logger, tmpDir string, cfg TortureConfig) (*TortureRunner, error) {
+	if cfg.BlockSize == 0 {
+		cfg.BlockSize = BlockSize
+	}
+
+	if err := validateTortureBlockSize(cfg.BlockSize); err != nil {
+		return nil, err
+	}
+
 	ctx := NewContext(gctx)
 
 	disk, err := NewDisk(ctx, log, tmpDir)
@@ -416,13 +536,16 @@ func NewTortureRunner(gctx context.Context, log *slog.Logger, tmpDir string, cfg
 		top:     gctx,
 		cfg:     cfg,
 		gen:     NewTortureGenerator(cfg),
-		model:   NewTortureDiskModel(),
+		model:   NewTortureDiskModel(cfg.BlockSize),
 		disk:    disk,
 		ctx:     ctx,
 		log:     log,
 		tmpDir:  tmpDir,
 		history: make([]TortureOperation, 0, cfg.Operations),
 		output:  os.Stderr,
+
+		powerFails: make(map[int]torturePowerFail),
+		lostBy:     make(map[int]int),
 	}, nil
 }
 
@@ -433,6 +556,10 @@ func (r *TortureRunner) SetOutput(w io.Writer) {
 
 // Cleanup cleans up resources used by the runner
 func (r *TortureRunner) Cleanup() {
+	if r.snapshot != nil {
+		r.snapshot.Release()
+		r.snapshot = nil
+	}
 	if r.disk != nil {
 		r.disk.Close(r.ctx)
 		r.disk = nil
@@ -531,6 +658,12 @@ func (r *TortureRunner) executeOp(op TortureOperation) error {
 		return r.execSync()
 	case TortureOpCloseReopen:
 		return r.execCloseReopen()
+	case TortureOpSnapshot:
+		return r.execSnapshot()
+	case TortureOpPowerFail:
+		return r.execPowerFail(op)
+	case TortureOpDiscard:
+		return r.execDiscard(op)
 	default:
 		return fmt.Errorf("unknown operation type: %d", op.Type)
 	}
@@ -538,28 +671,30 @@ func (r *TortureRunner) executeOp(op TortureOperation) error {
 
 func (r *TortureRunner) execWrite(op TortureOperation) error {
 	dataRng := rand.New(rand.NewSource(op.DataSeed))
-	data := GenerateTortureData(dataRng, op.Pattern, op.Extent.Blocks)
+	data := GenerateTortureData(dataRng, op.Pattern, op.Extent.Blocks, r.cfg.BlockSize)
 
+	prev := r.blockStates(op.Extent)
 	r.model.WriteExtent(op.Extent.LBA, data)
 
-	rd := MapRangeData(op.Extent, data)
-	return r.disk.WriteExtent(r.ctx, rd)
+	return r.trackInFlight(prev, func() error {
+		return r.writeLogical(op.Extent, data)
+	})
 }
 
 func (r *TortureRunner) execRead(op TortureOperation) error {
 	defer r.ctx.Reset()
 
-	actual, err := r.disk.ReadExtent(r.ctx, op.Extent)
+	actualData, err := r.readLogical(op.Extent)
 	if err != nil {
 		return fmt.Errorf("read error: %w", err)
 	}
 
-	actualData := actual.ReadData()
-	zeroHash := HashBlock(make([]byte, BlockSize))
+	bs := r.cfg.BlockSize
+	zeroHash := HashBlock(make([]byte, bs))
 
-	for i := uint32(0); i < op.Extent.Blocks; i++ {
+	for i := 0; i < int(op.Extent.Blocks); i++ {
 		lba := op.Extent.LBA + LBA(i)
-		blockData := actualData[i*BlockSize : (i+1)*BlockSize]
+		blockData := actualData[i*bs : (i+1)*bs]
 		actualHash := HashBlock(blockData)
 		expectedHash, modelHas := r.model.ExpectedHash(lba)
 		if !modelHas {
@@ -580,7 +715,7 @@ func (r *TortureRunner) execRead(op TortureOperation) error {
 
 			var relevantWrites []string
 			for idx, histOp := range r.history {
-				if histOp.Type == TortureOpWrite || histOp.Type == TortureOpZero {
+				if histOp.Type == TortureOpWrite || histOp.Type == TortureOpZero || histOp.Type == TortureOpDiscard {
 					start := histOp.Extent.LBA
 					end := start + LBA(histOp.Extent.Blocks)
 					if lba >= start && lba < end {
@@ -593,14 +728,13 @@ func (r *TortureRunner) execRead(op TortureOperation) error {
 			retryHash1 := "read-failed"
 			retryHash2 := "read-failed"
 			r.ctx.Reset()
-			if singleBlock, err := r.disk.ReadExtent(r.ctx, Extent{LBA: lba, Blocks: 1}); err == nil {
-				h := HashBlock(singleBlock.ReadData())
+			if singleBlock, err := r.readLogical(Extent{LBA: lba, Blocks: 1}); err == nil {
+				h := HashBlock(singleBlock)
 				retryHash1 = hex.EncodeToString(h[:])
 			}
 			r.ctx.Reset()
-			if retryExtent, err := r.disk.ReadExtent(r.ctx, op.Extent); err == nil {
-				retryData := retryExtent.ReadData()
-				retryBlockData := retryData[i*BlockSize : (i+1)*BlockSize]
+			if retryData, err := r.readLogical(op.Extent); err == nil {
+				retryBlockData := retryData[i*bs : (i+1)*bs]
 				h := HashBlock(retryBlockData)
 				retryHash2 = hex.EncodeToString(h[:])
 			}
@@ -615,29 +749,380 @@ func (r *TortureRunner) execRead(op TortureOperation) error {
 }
 
 func (r *TortureRunner) execZero(op TortureOperation) error {
+	prev := r.blockStates(op.Extent)
 	r.model.ZeroBlocks(op.Extent.LBA, op.Extent.Blocks)
-	return r.disk.ZeroBlocks(r.ctx, op.Extent)
+
+	return r.trackInFlight(prev, func() error {
+		return r.zeroLogical(op.Extent, func(ext Extent) error {
+			return r.disk.ZeroBlocks(r.ctx, ext)
+		})
+	})
+}
+
+func (r *TortureRunner) execDiscard(op TortureOperation) error {
+	prev := r.blockStates(op.Extent)
+	r.model.ZeroBlocks(op.Extent.LBA, op.Extent.Blocks)
+
+	r.discarded = append(r.discarded, op.Extent)
+
+	return r.trackInFlight(prev, func() error {
+		return r.zeroLogical(op.Extent, func(ext Extent) error {
+			return r.disk.Discard(r.ctx, ext.LBA, ext.Blocks)
+		})
+	})
+}
+
+// diskExtent returns the disk blocks holding the logical blocks of ext, and
+// the offset in bytes of ext within them.
+func (r *TortureRunner) diskExtent(ext Extent) (Extent, int) {
+	start := int64(ext.LBA) * int64(r.cfg.BlockSize)
+	end := start + int64(ext.Blocks)*int64(r.cfg.BlockSize)
+
+	first := start / BlockSize
+	last := (end + BlockSize - 1) / BlockSize
+
+	return Extent{LBA: LBA(first), Blocks: uint32(last - first)}, int(start - first*BlockSize)
+}
+
+// aligned reports whether the logical blocks of ext cover whole disk blocks.
+func (r *TortureRunner) aligned(ext Extent) bool {
+	bs := int64(r.cfg.BlockSize)
+	return (int64(ext.LBA)*bs)%BlockSize == 0 && (int64(ext.Blocks)*bs)%BlockSize == 0
+}
+
+// readLogical returns the data of the logical blocks of ext. The data is
+// only valid until the runner's context is reset.
+func (r *TortureRunner) readLogical(ext Extent) ([]byte, error) {
+	dext, off := r.diskExtent(ext)
+
+	rd, err := r.disk.ReadExtent(r.ctx, dext)
+	if err != nil {
+		return nil, err
+	}
+
+	return rd.ReadData()[off : off+int(ext.Blocks)*r.cfg.BlockSize], nil
+}
+
+// writeLogical writes data to the logical blocks of ext. When they don't
+// cover whole disk blocks, the rest of the disk blocks are read and written
+// back with them in a single write.
+func (r *TortureRunner) writeLogical(ext Extent, data []byte) error {
+	dext, off := r.diskExtent(ext)
+
+	if !r.aligned(ext) {
+		rd, err := r.disk.ReadExtent(r.ctx, dext)
+		if err != nil {
+			return err
+		}
+
+		merged := slices.Clone(rd.ReadData())
+		copy(merged[off:], data)
+
+		data = merged
+	}
+
+	return r.disk.WriteExtent(r.ctx, MapRangeData(dext, data))
+}
+
+// zeroLogical zeroes the logical blocks of ext using zero, unless they don't
+// cover whole disk blocks, in which case zeroes are written to them instead.
+func (r *TortureRunner) zeroLogical(ext Extent, zero func(Extent) error) error {
+	if !r.aligned(ext) {
+		return r.writeLogical(ext, make([]byte, int(ext.Blocks)*r.cfg.BlockSize))
+	}
+
+	dext, _ := r.diskExtent(ext)
+
+	return zero(dext)
 }
 
 func (r *TortureRunner) execSync() error {
-	return r.disk.SyncWriteCache()
+	if err := r.disk.SyncWriteCache(); err != nil {
+		return err
+	}
+
+	r.inFlight = r.inFlight[:0]
+
+	return nil
+}
+
+// blockStates records the model's blocks in ext, so that a write to them can
+// be undone if a power failure loses it.
+func (r *TortureRunner) blockStates(ext Extent) []tortureBlockState {
+	states := make([]tortureBlockState, 0, ext.Blocks)
+
+	for i := uint32(0); i < ext.Blocks; i++ {
+		lba := ext.LBA + LBA(i)
+		h, ok := r.model.ExpectedHash(lba)
+		states = append(states, tortureBlockState{lba: lba, hash: h, written: ok})
+	}
+
+	return states
+}
+
+// trackInFlight performs a write, noting where it ends in the write cache
+// unless it caused the write cache to be flushed to a segment, which makes
+// it and every write before it durable.
+func (r *TortureRunner) trackInFlight(prev []tortureBlockState, write func() error) error {
+	oc := r.disk.curOC
+	start := oc.builder.offset
+
+	if err := write(); err != nil {
+		return err
+	}
+
+	if r.disk.curOC != oc {
+		r.inFlight = r.inFlight[:0]
+		return nil
+	}
+
+	r.inFlight = append(r.inFlight, tortureInFlight{
+		op:    r.opCount - 1,
+		start: start,
+		end:   oc.builder.offset,
+		prev:  prev,
+	})
+
+	return nil
 }
 
 func (r *TortureRunner) execCloseReopen() error {
+	// A snapshot doesn't outlive its disk
+	if err := r.releaseSnapshot(); err != nil {
+		return err
+	}
+
 	if err := r.disk.Close(r.ctx); err != nil {
 		return fmt.Errorf("close error: %w", err)
 	}
 
+	r.inFlight = r.inFlight[:0]
+
 	disk, err := NewDisk(r.ctx, r.log, r.tmpDir)
 	if err != nil {
 		return fmt.Errorf("reopen error: %w", err)
 	}
 	r.disk = disk
 
+	if err := r.verifyDiscarded(); err != nil {
+		return err
+	}
+
 	return r.verifySample(100)
 }
 
+// execPowerFail stops the disk as a power failure would, then tears the end
+// of the write cache at a point chosen by the operation's seed, either
+// truncating it or leaving a partially written record. Writes wholly before
+// the tear must survive the reopen, and those after it are dropped from the
+// model.
+func (r *TortureRunner) execPowerFail(op TortureOperation) error {
+	if err := r.releaseSnapshot(); err != nil {
+		return err
+	}
+
+	rng := rand.New(rand.NewSource(op.DataSeed))
+
+	logPath := r.disk.curOC.builder.logF.Name()
+	size := r.disk.curOC.builder.offset
+
+	// Only what was written since the write cache was synced can be torn
+	durable := size
+	if len(r.inFlight) > 0 {
+		durable = r.inFlight[0].start
+	}
+
+	pf := torturePowerFail{
+		size:     size,
+		corrupt:  rng.Intn(2) == 0,
+		inFlight: len(r.inFlight),
+	}
+
+	if pf.corrupt {
+		// The log has no checksums, so a partial record is left only at the
+		// end of a complete one, where it can be recognized as torn.
+		pf.cut = durable
+		if n := rng.Intn(len(r.inFlight) + 1); n > 0 {
+			pf.cut = r.inFlight[n-1].end
+		}
+	} else {
+		pf.cut = durable + uint64(rng.Int63n(int64(size-durable)+1))
+	}
+
+	r.disk.crash()
+	r.disk = nil
+
+	if err := tearLog(logPath, pf.cut, pf.corrupt, rng); err != nil {
+		return fmt.Errorf("tearing write cache: %w", err)
+	}
+
+	// Undo the lost writes newest first, so each block returns to its state
+	// before the earliest of them.
+	for i := len(r.inFlight) - 1; i >= 0; i-- {
+		w := r.inFlight[i]
+		if w.end <= pf.cut {
+			break
+		}
+
+		for _, st := range w.prev {
+			if st.written {
+				r.model.blocks[st.lba] = st.hash
+			} else {
+				delete(r.model.blocks, st.lba)
+			}
+		}
+
+		r.lostBy[w.op] = r.opCount - 1
+		pf.lost++
+	}
+
+	r.inFlight = r.inFlight[:0]
+	r.powerFails[r.opCount-1] = pf
+
+	disk, err := NewDisk(r.ctx, r.log, r.tmpDir)
+	if err != nil {
+		return fmt.Errorf("reopen after power failure: %w", err)
+	}
+	r.disk = disk
+
+	// Every block is checked, so there's no need to check discards apart
+	r.discarded = r.discarded[:0]
+
+	return r.verifyAll()
+}
+
+// tearLog truncates the write cache at path to cut bytes, then if corrupt
+// appends a record whose body was cut short.
+func tearLog(path string, cut uint64, corrupt bool, rng *rand.Rand) error {
+	f, err := os.OpenFile(path, os.O_RDWR, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := f.Truncate(int64(cut)); err != nil {
+		return err
+	}
+
+	if !corrupt {
+		return f.Sync()
+	}
+
+	if _, err := f.Seek(int64(cut), io.SeekStart); err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(f)
+
+	eh := ExtentHeader{
+		Extent: Extent{LBA: LBA(rng.Intn(1000)), Blocks: 1},
+		Size:   BlockSize,
+	}
+
+	if _, err := eh.Write(w); err != nil {
+		return err
+	}
+
+	body := make([]byte, rng.Intn(BlockSize))
+	rng.Read(body)
+
+	if _, err := w.Write(body); err != nil {
+		return err
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	return f.Sync()
+}
+
+// crash stops the disk as a power failure would, leaving its write cache on
+// disk as it is rather than flushing it to a segment.
+func (d *Disk) crash() {
+	if d.closed.CompareAndSwap(0, 1) == false {
+		return
+	}
+
+	// Background work already queued still completes
+	close(d.controller.EventsCh())
+	d.wg.Wait()
+
+	if d.curOC != nil && d.curOC.builder.logF != nil {
+		d.curOC.builder.logF.Close()
+	}
+
+	d.er.Close()
+}
+
+// execSnapshot verifies and releases the open snapshot, then takes a new one
+// along with a copy of the model to verify it against.
+func (r *TortureRunner) execSnapshot() error {
+	if err := r.releaseSnapshot(); err != nil {
+		return err
+	}
+
+	snap, err := r.disk.Snapshot(r.ctx)
+	if err != nil {
+		return fmt.Errorf("snapshot error: %w", err)
+	}
+
+	r.snapshot = snap
+	r.snapshotModel = r.model.Clone()
+
+	// Taking the snapshot flushed the write cache
+	r.inFlight = r.inFlight[:0]
+
+	return r.verifySnapshot()
+}
+
+func (r *TortureRunner) releaseSnapshot() error {
+	if r.snapshot == nil {
+		return nil
+	}
+
+	err := r.verifySnapshot()
+
+	r.snapshot.Release()
+	r.snapshot = nil
+	r.snapshotModel = nil
+
+	return err
+}
+
+// verifySnapshot checks that the open snapshot still reads as the disk did
+// when it was taken, regardless of writes made since.
+func (r *TortureRunner) verifySnapshot() error {
+	if r.snapshot == nil {
+		return nil
+	}
+
+	buf := make([]byte, BlockSize)
+
+	for _, lba := range r.snapshotModel.WrittenLBAs() {
+		dext, off := r.diskExtent(Extent{LBA: lba, Blocks: 1})
+
+		if _, err := r.snapshot.ReadAt(buf, dext.LBA); err != nil {
+			return fmt.Errorf("snapshot read error at LBA %d: %w", lba, err)
+		}
+
+		actualHash := HashBlock(buf[off : off+r.cfg.BlockSize])
+		expectedHash, _ := r.snapshotModel.ExpectedHash(lba)
+
+		if actualHash != expectedHash {
+			return fmt.Errorf("snapshot verification failed at LBA %d: expected %s, got %s",
+				lba, hex.EncodeToString(expectedHash[:]), hex.EncodeToString(actualHash[:]))
+		}
+	}
+
+	return nil
+}
+
 func (r *TortureRunner) verifyAll() error {
+	if err := r.verifySnapshot(); err != nil {
+		return err
+	}
+
 	lbas := r.model.WrittenLBAs()
 	if len(lbas) == 0 {
 		return nil
@@ -646,12 +1131,12 @@ func (r *TortureRunner) verifyAll() error {
 	for _, lba := range lbas {
 		r.ctx.Reset()
 
-		actual, err := r.disk.ReadExtent(r.ctx, Extent{LBA: lba, Blocks: 1})
+		actual, err := r.readLogical(Extent{LBA: lba, Blocks: 1})
 		if err != nil {
 			return fmt.Errorf("read error at LBA %d: %w", lba, err)
 		}
 
-		actualHash := HashBlock(actual.ReadData())
+		actualHash := HashBlock(actual)
 		expectedHash, _ := r.model.ExpectedHash(lba)
 
 		if actualHash != expectedHash {
@@ -663,6 +1148,37 @@ func (r *TortureRunner) verifyAll() error {
 	return nil
 }
 
+// verifyDiscarded checks every block of the ranges discarded before the disk
+// was reopened, which read back as zero unless written again since.
+func (r *TortureRunner) verifyDiscarded() error {
+	defer func() { r.discarded = r.discarded[:0] }()
+
+	for _, ext := range r.discarded {
+		r.ctx.Reset()
+
+		data, err := r.readLogical(ext)
+		if err != nil {
+			return fmt.Errorf("read error at %s: %w", ext, err)
+		}
+
+		bs := r.cfg.BlockSize
+
+		for i := 0; i < int(ext.Blocks); i++ {
+			lba := ext.LBA + LBA(i)
+
+			actualHash := HashBlock(data[i*bs : (i+1)*bs])
+			expectedHash, _ := r.model.ExpectedHash(lba)
+
+			if actualHash != expectedHash {
+				return fmt.Errorf("discard verification failed at LBA %d: expected %s, got %s",
+					lba, hex.EncodeToString(expectedHash[:]), hex.EncodeToString(actualHash[:]))
+			}
+		}
+	}
+
+	return nil
+}
+
 func (r *TortureRunner) verifySample(count int) error {
 	lbas := r.model.WrittenLBAs()
 	if len(lbas) == 0 {
@@ -683,12 +1199,12 @@ func (r *TortureRunner) verifySample(count int) error {
 		r.ctx.Reset()
 
 		lba := lbas[idx]
-		actual, err := r.disk.ReadExtent(r.ctx, Extent{LBA: lba, Blocks: 1})
+		actual, err := r.readLogical(Extent{LBA: lba, Blocks: 1})
 		if err != nil {
 			return fmt.Errorf("read error at LBA %d: %w", lba, err)
 		}
 
-		actualHash := HashBlock(actual.ReadData())
+		actualHash := HashBlock(actual)
 		expectedHash, _ := r.model.ExpectedHash(lba)
 
 		if actualHash != expectedHash {
@@ -712,7 +1228,7 @@ func (r *TortureRunner) DumpHistory(last int) {
 		if i == len(r.history)-1 {
 			marker = "→ "
 		}
-		fmt.Fprintf(r.output, "%s[%5d] %s\n", marker, i, r.history[i])
+		fmt.Fprintf(r.output, "%s[%5d] %s%s\n", marker, i, r.history[i], r.annotation(i))
 	}
 }
 
@@ -730,10 +1246,29 @@ func (r *TortureRunner) DumpHistoryRange(start, end int) {
 	}
 	fmt.Fprintf(r.output, "--- Operation History [%d, %d) ---\n", start, end)
 	for i := start; i < end; i++ {
-		fmt.Fprintf(r.output, "  [%5d] %s\n", i, r.history[i])
+		fmt.Fprintf(r.output, "  [%5d] %s%s\n", i, r.history[i], r.annotation(i))
 	}
 }
 
+// annotation describes what a power failure did to operation i, marking the
+// in-flight writes it lost and where it tore the write cache.
+func (r *TortureRunner) annotation(i int) string {
+	if pf, ok := r.powerFails[i]; ok {
+		kind := "truncated"
+		if pf.corrupt {
+			kind = "torn record"
+		}
+
+		return fmt.Sprintf("  (%s at %d/%d, %d in flight, %d lost)", kind, pf.cut, pf.size, pf.inFlight, pf.lost)
+	}
+
+	if by, ok := r.lostBy[i]; ok {
+		return fmt.Sprintf("  (lost in power-fail [%d])", by)
+	}
+
+	return ""
+}
+
 // tortureIsEmpty checks if all bytes in the slice are zero
 func tortureIsEmpty(d []byte) bool {
 	for _, b := range d {
@@ -750,16 +1285,34 @@ type TortureVariation struct {
 	Weights TortureOpWeights
 	Overlap float64
 	MaxLBA  LBA
+
+	// BlockSizes are logical block sizes that successive runs of the
+	// variation alternate between. Empty keeps the config's block size.
+	BlockSizes []int
+}
+
+// BlockSizeFor returns the logical block size of the variation's nth run,
+// or zero if the variation doesn't set one.
+func (v TortureVariation) BlockSizeFor(run int) int {
+	if len(v.BlockSizes) == 0 {
+		return 0
+	}
+
+	return v.BlockSizes[run%len(v.BlockSizes)]
 }
 
 // DefaultTortureVariations returns the standard set of torture test variations
 func DefaultTortureVariations() []TortureVariation {
 	return []TortureVariation{
-		{"default", DefaultTortureWeights, 0.3, 100000},
-		{"no-close-reopen", TortureOpWeights{Write: 50, Read: 35, Zero: 10, Sync: 5, CloseReopen: 0}, 0.3, 100000},
-		{"high-overlap", TortureOpWeights{Write: 70, Read: 30, Zero: 0, Sync: 0, CloseReopen: 0}, 0.6, 100000},
-		{"heavy-zero", TortureOpWeights{Write: 40, Read: 30, Zero: 25, Sync: 5, CloseReopen: 0}, 0.3, 100000},
-		{"durability", TortureOpWeights{Write: 40, Read: 30, Zero: 5, Sync: 10, CloseReopen: 15}, 0.3, 100000},
-		{"boundaries", DefaultTortureWeights, 0.5, 1000},
+		{"default", DefaultTortureWeights, 0.3, 100000, nil},
+		{"no-close-reopen", TortureOpWeights{Write: 50, Read: 35, Zero: 10, Sync: 5, CloseReopen: 0}, 0.3, 100000, nil},
+		{"high-overlap", TortureOpWeights{Write: 70, Read: 30, Zero: 0, Sync: 0, CloseReopen: 0}, 0.6, 100000, nil},
+		{"heavy-zero", TortureOpWeights{Write: 40, Read: 30, Zero: 25, Sync: 5, CloseReopen: 0}, 0.3, 100000, nil},
+		{"durability", TortureOpWeights{Write: 40, Read: 30, Zero: 5, Sync: 10, CloseReopen: 15}, 0.3, 100000, nil},
+		{"boundaries", DefaultTortureWeights, 0.5, 1000, nil},
+		{"snapshot", TortureOpWeights{Write: 50, Read: 25, Zero: 10, Sync: 5, CloseReopen: 5, Snapshot: 5}, 0.3, 100000, nil},
+		{"power-fail", TortureOpWeights{Write: 50, Read: 25, Zero: 10, Sync: 5, CloseReopen: 5, PowerFail: 5}, 0.3, 100000, nil},
+		{"discard", TortureOpWeights{Write: 45, Read: 25, Zero: 0, Sync: 5, CloseReopen: 10, Discard: 15}, 0.3, 100000, nil},
+		{"block-sizes", DefaultTortureWeights, 0.5, 20000, []int{512, 4096}},
 	}
 }
@@ -2,9 +2,11 @@ package lsvd
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"math/rand"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -38,3 +40,115 @@ func TestTortureQuick(t *testing.T) {
 	t.Logf("Torture test passed: %d operations, %d unique LBAs written",
 		result.Operations, result.LBAsUsed)
 }
+
+// TestTortureSnapshot runs the snapshot variation, verifying each snapshot
+// against the model as it was when the snapshot was taken.
+func TestTortureSnapshot(t *testing.T) {
+	runTortureVariation(t, "snapshot")
+}
+
+// TestTorturePowerFail runs the power-fail variation, verifying that the
+// disk reopens with every write made before the write cache was torn.
+func TestTorturePowerFail(t *testing.T) {
+	runTortureVariation(t, "power-fail")
+}
+
+// TestTortureDiscard runs the discard variation, verifying that discarded
+// ranges read back as zero once the disk is reopened.
+func TestTortureDiscard(t *testing.T) {
+	runTortureVariation(t, "discard")
+}
+
+// TestTortureBlockSizes runs the block-sizes variation once with each of its
+// logical block sizes, catching alignment bugs in the extent map.
+func TestTortureBlockSizes(t *testing.T) {
+	v := findTortureVariation(t, "block-sizes")
+
+	for run := range v.BlockSizes {
+		bs := v.BlockSizeFor(run)
+
+		t.Run(fmt.Sprintf("%d", bs), func(t *testing.T) {
+			runTortureVariationWith(t, v, bs)
+		})
+	}
+}
+
+func TestTortureRejectsUnsupportedBlockSize(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+		Level: slog.LevelError,
+	}))
+
+	for _, bs := range []int{256, 1000, 8192} {
+		cfg := DefaultTortureConfig
+		cfg.BlockSize = bs
+
+		_, err := NewTortureRunner(context.Background(), log, t.TempDir(), cfg)
+		if err == nil || !strings.Contains(err.Error(), "unsupported block size") {
+			t.Errorf("block size %d: expected unsupported block size error, got %v", bs, err)
+		}
+	}
+}
+
+func TestTortureConfigEncodesBlockSize(t *testing.T) {
+	cfg := DefaultTortureConfig
+	cfg.BlockSize = 512
+
+	decoded, err := DecodeTortureConfig(EncodeTortureConfig(cfg))
+	if err != nil {
+		t.Fatalf("Failed to decode config: %v", err)
+	}
+
+	if decoded.BlockSize != 512 {
+		t.Fatalf("Expected block size 512, got %d", decoded.BlockSize)
+	}
+}
+
+func findTortureVariation(t *testing.T, name string) TortureVariation {
+	for _, v := range DefaultTortureVariations() {
+		if v.Name == name {
+			return v
+		}
+	}
+
+	t.Fatalf("Unknown variation: %s", name)
+	return TortureVariation{}
+}
+
+func runTortureVariation(t *testing.T, name string) {
+	runTortureVariationWith(t, findTortureVariation(t, name), 0)
+}
+
+func runTortureVariationWith(t *testing.T, v TortureVariation, blockSize int) {
+	cfg := DefaultTortureConfig
+	cfg.Seed = rand.Int63()
+	cfg.Operations = 1000
+	cfg.VerifyEvery = 100
+
+	cfg.Weights = v.Weights
+	cfg.OverlapProbability = v.Overlap
+	cfg.MaxLBA = v.MaxLBA
+
+	if blockSize != 0 {
+		cfg.BlockSize = blockSize
+	}
+
+	t.Logf("Torture test starting with seed: %d", cfg.Seed)
+	t.Logf("Reproduce with: go run ./lsvd/cmd/torture -config %s", EncodeTortureConfig(cfg))
+
+	log := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+		Level: slog.LevelError,
+	}))
+
+	runner, err := NewTortureRunner(context.Background(), log, t.TempDir(), cfg)
+	if err != nil {
+		t.Fatalf("Failed to create runner: %v", err)
+	}
+	defer runner.Cleanup()
+
+	result := runner.Run()
+
+	if !result.Success {
+		runner.DumpHistory(50)
+		t.Fatalf("Torture test failed: %v", result.Error)
+	}
+}
@@ -0,0 +1,259 @@
+package lsvd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/pierrec/lz4/v4"
+	"github.com/pkg/errors"
+)
+
+// SegmentProblem is an inconsistency found in a segment by VerifyVolume.
+// Offset is the position in the segment where it was found.
+type SegmentProblem struct {
+	Segment SegmentId
+	Offset  int64
+	Err     error
+}
+
+func (p SegmentProblem) String() string {
+	return fmt.Sprintf("%s@%d: %s", p.Segment, p.Offset, p.Err)
+}
+
+// VerifyResult summarizes a VerifyVolume run.
+type VerifyResult struct {
+	Segments []SegmentId
+	Bytes    int64
+	Problems []SegmentProblem
+}
+
+// OK reports whether no problems were found.
+func (r *VerifyResult) OK() bool {
+	return len(r.Problems) == 0
+}
+
+// Bad reports whether seg had any problems.
+func (r *VerifyResult) Bad(seg SegmentId) bool {
+	for _, p := range r.Problems {
+		if p.Segment == seg {
+			return true
+		}
+	}
+
+	return false
+}
+
+// VerifyVolume reads every segment of vol without attaching a disk to it.
+// Segments carry no data checksums, so each one is checked against the
+// metadata it is stored with instead: the extent headers must parse and
+// agree with the segment's layout, every extent's data must be readable,
+// and compressed data must decompress to the size it was recorded with.
+func VerifyVolume(ctx context.Context, log *slog.Logger, vol Volume) (*VerifyResult, error) {
+	segments, err := vol.ListSegments(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &VerifyResult{Segments: segments}
+
+	for _, seg := range segments {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		log.Debug("verifying segment", "segment", seg)
+
+		f, err := vol.OpenSegment(ctx, seg)
+		if err != nil {
+			res.Problems = append(res.Problems, SegmentProblem{
+				Segment: seg,
+				Err:     errors.Wrapf(err, "opening segment"),
+			})
+			continue
+		}
+
+		n, problems := verifySegment(ctx, seg, f)
+		f.Close()
+
+		res.Bytes += n
+		res.Problems = append(res.Problems, problems...)
+	}
+
+	return res, nil
+}
+
+// countingReader tracks how far into the segment the headers have been read.
+type countingReader struct {
+	br *bufio.Reader
+	n  int64
+}
+
+func (c *countingReader) ReadByte() (byte, error) {
+	b, err := c.br.ReadByte()
+	if err == nil {
+		c.n++
+	}
+
+	return b, err
+}
+
+func verifySegment(ctx context.Context, seg SegmentId, f SegmentReader) (int64, []SegmentProblem) {
+	var problems []SegmentProblem
+
+	problem := func(off int64, err error) {
+		problems = append(problems, SegmentProblem{Segment: seg, Offset: off, Err: err})
+	}
+
+	cr := &countingReader{br: bufio.NewReader(ToReader(f))}
+
+	var hdr SegmentHeader
+
+	if err := hdr.Read(cr.br); err != nil {
+		problem(0, errors.Wrapf(err, "reading segment header"))
+		return 0, problems
+	}
+
+	cr.n = 8
+
+	var headers []ExtentHeader
+
+	for i := uint32(0); i < hdr.ExtentCount; i++ {
+		var eh ExtentHeader
+
+		off := cr.n
+
+		if _, err := eh.Read(cr); err != nil {
+			problem(off, errors.Wrapf(err, "reading extent header %d of %d", i, hdr.ExtentCount))
+			return cr.n, problems
+		}
+
+		headers = append(headers, eh)
+	}
+
+	if cr.n != int64(hdr.DataOffset) {
+		problem(cr.n, fmt.Errorf("extent headers end at %d, data offset is %d", cr.n, hdr.DataOffset))
+		return cr.n, problems
+	}
+
+	verified := cr.n
+
+	layout, err := f.Layout(ctx)
+	if err != nil && !os.IsNotExist(err) {
+		problem(0, errors.Wrapf(err, "reading segment layout"))
+	} else if layout != nil {
+		exts := layout.Extents()
+
+		if len(exts) != len(headers) {
+			problem(0, fmt.Errorf("layout has %d extents, segment has %d", len(exts), len(headers)))
+		} else {
+			for i, eh := range headers {
+				le := exts[i]
+
+				if LBA(le.Lba()) != eh.LBA || le.Blocks() != eh.Blocks ||
+					le.Size() != eh.Size || le.RawSize() != eh.RawSize ||
+					le.Offset() != eh.Offset+hdr.DataOffset {
+					problem(int64(le.Offset()), fmt.Errorf("layout extent %d does not match segment header", i))
+				}
+			}
+		}
+	}
+
+	var buf, uncomp []byte
+
+	for _, eh := range headers {
+		if eh.Size == 0 {
+			continue
+		}
+
+		off := int64(hdr.DataOffset) + int64(eh.Offset)
+
+		if eh.Flags() == Uncompressed && eh.Size != eh.Blocks*BlockSize {
+			problem(off, fmt.Errorf("extent %s stores %d bytes, expected %d", eh.Extent, eh.Size, eh.Blocks*BlockSize))
+			continue
+		}
+
+		if cap(buf) < int(eh.Size) {
+			buf = make([]byte, eh.Size)
+		}
+
+		data := buf[:eh.Size]
+
+		n, err := f.ReadAt(data, off)
+		if n != len(data) {
+			if err == nil || errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+
+			problem(off+int64(n), errors.Wrapf(err, "reading extent %s", eh.Extent))
+			continue
+		}
+
+		verified += int64(n)
+
+		if eh.Flags() != Compressed {
+			continue
+		}
+
+		if eh.RawSize != eh.Blocks*BlockSize {
+			problem(off, fmt.Errorf("extent %s has raw size %d, expected %d", eh.Extent, eh.RawSize, eh.Blocks*BlockSize))
+			continue
+		}
+
+		if cap(uncomp) < int(eh.RawSize) {
+			uncomp = make([]byte, eh.RawSize)
+		}
+
+		un, err := lz4.UncompressBlock(data, uncomp[:eh.RawSize])
+		if err != nil {
+			problem(off, errors.Wrapf(err, "uncompressing extent %s", eh.Extent))
+		} else if un != int(eh.RawSize) {
+			problem(off, fmt.Errorf("extent %s uncompressed to %d bytes, expected %d", eh.Extent, un, eh.RawSize))
+		}
+	}
+
+	return verified, problems
+}
+
+// RepairVolume removes the trailing segments of vol that res found
+// problems with, as long as their writes can be recovered. Writes are
+// acknowledged once they're in a disk's write cache, which is only removed
+// after the segment built from it is stored, so a bad segment whose write
+// cache is still in path held nothing that the cache won't replay the next
+// time the disk is opened. Repair stops at the first segment that is good or
+// can't be recovered. The removed segments are returned.
+func RepairVolume(ctx context.Context, log *slog.Logger, vol Volume, path string, res *VerifyResult) ([]SegmentId, error) {
+	var removed []SegmentId
+
+	for i := len(res.Segments) - 1; i >= 0; i-- {
+		seg := res.Segments[i]
+
+		if !res.Bad(seg) {
+			break
+		}
+
+		_, err := os.Stat(filepath.Join(path, "writecache."+seg.String()))
+		if err != nil {
+			if os.IsNotExist(err) {
+				log.Warn("not removing bad segment, its writes are not in the write cache", "segment", seg)
+				break
+			}
+
+			return removed, err
+		}
+
+		log.Info("removing bad trailing segment, write cache will replay it", "segment", seg)
+
+		if err := vol.RemoveSegment(ctx, seg); err != nil {
+			return removed, errors.Wrapf(err, "removing segment %s", seg)
+		}
+
+		removed = append(removed, seg)
+	}
+
+	return removed, nil
+}
@@ -0,0 +1,263 @@
+package lsvd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/pkg/errors"
+)
+
+// ErrWORMRetention is returned for writes and discards that would change
+// data still under WORM retention.
+var ErrWORMRetention = errors.New("data is under WORM retention")
+
+// wormMetadataName is the volume metadata file the WORM policy is stored in.
+const wormMetadataName = "worm.json"
+
+// MetadataAccess is implemented by the SegmentAccess backends that can keep
+// small files alongside a volume's segments. Missing files read as
+// os.ErrNotExist.
+type MetadataAccess interface {
+	WriteMetadata(ctx context.Context, vol, name string) (io.WriteCloser, error)
+	ReadMetadata(ctx context.Context, vol, name string) (io.ReadCloser, error)
+}
+
+// WORMPolicy puts a volume in write-once-read-many mode. Blocks written
+// after EnabledAt can't be overwritten or discarded until Retention has
+// passed since they were written. Once the volume is sealed, nothing on it
+// can be changed until Retention has passed since SealedAt.
+//
+// A block's write time is taken from the segment holding it. Compaction
+// rewrites live blocks into new segments, which restarts their retention,
+// so blocks are held at least as long as Retention but possibly longer.
+type WORMPolicy struct {
+	Retention time.Duration `json:"retention"`
+	EnabledAt time.Time     `json:"enabled_at"`
+	SealedAt  time.Time     `json:"sealed_at,omitzero"`
+}
+
+// Sealed reports whether the volume has been sealed.
+func (p *WORMPolicy) Sealed() bool {
+	return !p.SealedAt.IsZero()
+}
+
+// WithWORMRetention enables WORM mode on the volume when the disk is
+// opened, as EnableWORM does.
+func WithWORMRetention(retention time.Duration) Option {
+	return func(o *opts) {
+		o.wormRetention = retention
+	}
+}
+
+func (d *Disk) metadataAccess() (MetadataAccess, error) {
+	ma, ok := d.sa.(MetadataAccess)
+	if !ok {
+		return nil, fmt.Errorf("volume storage can't hold a WORM policy")
+	}
+
+	return ma, nil
+}
+
+// loadWORM reads the volume's WORM policy, if it has one.
+func (d *Disk) loadWORM(ctx context.Context) error {
+	ma, ok := d.sa.(MetadataAccess)
+	if !ok {
+		return nil
+	}
+
+	r, err := ma.ReadMetadata(ctx, d.volName, wormMetadataName)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		return errors.Wrapf(err, "reading WORM policy")
+	}
+
+	defer r.Close()
+
+	var p WORMPolicy
+
+	if err := json.NewDecoder(r).Decode(&p); err != nil {
+		return errors.Wrapf(err, "decoding WORM policy")
+	}
+
+	d.worm.Store(&p)
+
+	return nil
+}
+
+func (d *Disk) saveWORM(ctx context.Context, p *WORMPolicy) error {
+	ma, err := d.metadataAccess()
+	if err != nil {
+		return err
+	}
+
+	w, err := ma.WriteMetadata(ctx, d.volName, wormMetadataName)
+	if err != nil {
+		return errors.Wrapf(err, "writing WORM policy")
+	}
+
+	if err := json.NewEncoder(w).Encode(p); err != nil {
+		w.Close()
+		return errors.Wrapf(err, "writing WORM policy")
+	}
+
+	if err := w.Close(); err != nil {
+		return errors.Wrapf(err, "writing WORM policy")
+	}
+
+	d.worm.Store(p)
+
+	return nil
+}
+
+// WORMPolicy returns the volume's WORM policy, or nil if it isn't in WORM
+// mode.
+func (d *Disk) WORMPolicy() *WORMPolicy {
+	if p := d.worm.Load(); p != nil {
+		cp := *p
+		return &cp
+	}
+
+	return nil
+}
+
+// EnableWORM puts the volume in WORM mode with the given retention, storing
+// the policy alongside its segments. WORM mode can't be turned off, and the
+// retention of a volume already in it can only be extended.
+func (d *Disk) EnableWORM(ctx context.Context, retention time.Duration) error {
+	if retention <= 0 {
+		return fmt.Errorf("WORM retention must be positive")
+	}
+
+	d.wormMu.Lock()
+	defer d.wormMu.Unlock()
+
+	var p WORMPolicy
+
+	if cur := d.worm.Load(); cur != nil {
+		if retention <= cur.Retention {
+			return nil
+		}
+
+		p = *cur
+	} else {
+		// Segment ids only carry the time to the millisecond
+		p.EnabledAt = d.wormNow().Truncate(time.Millisecond)
+	}
+
+	p.Retention = retention
+
+	d.log.Info("enabling WORM mode", "volume", d.volName, "retention", retention)
+
+	return d.saveWORM(ctx, &p)
+}
+
+// SealWORM seals a volume in WORM mode, after which none of it can be
+// changed until the retention has passed. Pending writes are flushed first.
+func (d *Disk) SealWORM(ctx context.Context) error {
+	d.wormMu.Lock()
+	defer d.wormMu.Unlock()
+
+	cur := d.worm.Load()
+	if cur == nil {
+		return fmt.Errorf("volume isn't in WORM mode")
+	}
+
+	if cur.Sealed() {
+		return nil
+	}
+
+	if !d.readOnly {
+		if err := d.CloseSegment(ctx); err != nil {
+			return errors.Wrapf(err, "flushing writes before sealing")
+		}
+	}
+
+	p := *cur
+	p.SealedAt = d.wormNow()
+
+	d.log.Info("sealing WORM volume", "volume", d.volName, "until", p.SealedAt.Add(p.Retention))
+
+	return d.saveWORM(ctx, &p)
+}
+
+// checkWORM returns ErrWORMRetention if any block in rng can't be changed
+// yet.
+func (d *Disk) checkWORM(rng Extent) error {
+	p := d.worm.Load()
+	if p == nil {
+		return nil
+	}
+
+	now := d.wormNow()
+
+	if p.Sealed() {
+		if until := p.SealedAt.Add(p.Retention); now.Before(until) {
+			return errors.Wrapf(ErrWORMRetention, "volume is sealed until %s", until.Format(time.RFC3339))
+		}
+
+		return nil
+	}
+
+	// Blocks still in the write cache were only just written
+	if err := d.checkWriteCacheWORM(d.curOC, rng); err != nil {
+		return err
+	}
+
+	if oc := d.prevCache.Acquire(); oc != nil {
+		err := d.checkWriteCacheWORM(oc, rng)
+		d.prevCache.Release()
+
+		if err != nil {
+			return err
+		}
+	}
+
+	pes, err := d.lba2pba.Resolve(d.log, rng, nil)
+	if err != nil {
+		return err
+	}
+
+	for _, pe := range pes {
+		if pe.Flags() == Empty {
+			continue
+		}
+
+		written := ulid.Time(ulid.ULID(pe.Segment).Time())
+		if written.Before(p.EnabledAt) {
+			continue
+		}
+
+		if until := written.Add(p.Retention); now.Before(until) {
+			return errors.Wrapf(ErrWORMRetention, "%s is retained until %s", pe.Live, until.Format(time.RFC3339))
+		}
+	}
+
+	return nil
+}
+
+func (d *Disk) checkWriteCacheWORM(oc *SegmentCreator, rng Extent) error {
+	if oc == nil {
+		return nil
+	}
+
+	pes, err := oc.em.Resolve(d.log, rng, nil)
+	if err != nil {
+		return err
+	}
+
+	for _, pe := range pes {
+		if pe.Flags() != Empty {
+			return errors.Wrapf(ErrWORMRetention, "%s was just written", pe.Live)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,160 @@
+package lsvd
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWORM(t *testing.T) {
+	log := slog.Default()
+
+	gctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ctx := NewContext(gctx)
+
+	// setClock makes d see the time as offset from now.
+	setClock := func(d *Disk, offset time.Duration) {
+		d.wormNow = func() time.Time {
+			return time.Now().Add(offset)
+		}
+	}
+
+	t.Run("protects written blocks until their retention passes", func(t *testing.T) {
+		r := require.New(t)
+
+		tmpdir, err := os.MkdirTemp("", "lsvd")
+		r.NoError(err)
+		defer os.RemoveAll(tmpdir)
+
+		d, err := NewDisk(ctx, log, tmpdir, WithWORMRetention(time.Hour))
+		r.NoError(err)
+
+		r.NoError(d.WriteExtent(ctx, testRandX.MapTo(0)))
+
+		// Still in the write cache
+		r.ErrorIs(d.WriteExtent(ctx, testExtent.MapTo(0)), ErrWORMRetention)
+		r.ErrorIs(d.Discard(ctx, 0, 1), ErrWORMRetention)
+		r.ErrorIs(d.ZeroBlocks(ctx, Extent{LBA: 0, Blocks: 1}), ErrWORMRetention)
+
+		// Blocks nobody has written yet are fine
+		r.NoError(d.WriteExtent(ctx, testExtent.MapTo(10)))
+
+		r.NoError(d.CloseSegment(ctx))
+
+		// Now in a segment
+		r.ErrorIs(d.WriteExtent(ctx, testExtent.MapTo(0)), ErrWORMRetention)
+
+		_, err = d.SecureErase(ctx, SecureEraseOptions{})
+		r.ErrorIs(err, ErrWORMRetention)
+
+		r.NoError(d.Close(ctx))
+
+		// The policy is kept with the volume
+		d, err = NewDisk(ctx, log, tmpdir)
+		r.NoError(err)
+		defer d.Close(ctx)
+
+		p := d.WORMPolicy()
+		r.NotNil(p)
+		r.Equal(time.Hour, p.Retention)
+
+		r.ErrorIs(d.WriteExtent(ctx, testExtent.MapTo(0)), ErrWORMRetention)
+
+		data, err := d.ReadExtent(ctx, Extent{LBA: 0, Blocks: 1})
+		r.NoError(err)
+		extentEqual(t, testRandX, data)
+
+		setClock(d, 2*time.Hour)
+
+		r.NoError(d.WriteExtent(ctx, testExtent.MapTo(0)))
+		r.NoError(d.Discard(ctx, 10, 1))
+	})
+
+	t.Run("rejects any change to a sealed volume until retention passes", func(t *testing.T) {
+		r := require.New(t)
+
+		tmpdir, err := os.MkdirTemp("", "lsvd")
+		r.NoError(err)
+		defer os.RemoveAll(tmpdir)
+
+		d, err := NewDisk(ctx, log, tmpdir)
+		r.NoError(err)
+
+		r.Error(d.SealWORM(ctx), "sealed a volume not in WORM mode")
+
+		r.NoError(d.EnableWORM(ctx, time.Hour))
+		r.NoError(d.WriteExtent(ctx, testRandX.MapTo(0)))
+		r.NoError(d.SealWORM(ctx))
+
+		// Even blocks that were never written
+		r.ErrorIs(d.WriteExtent(ctx, testExtent.MapTo(10)), ErrWORMRetention)
+		r.ErrorIs(d.Discard(ctx, 10, 1), ErrWORMRetention)
+
+		r.NoError(d.Close(ctx))
+
+		d, err = NewDisk(ctx, log, tmpdir)
+		r.NoError(err)
+		defer d.Close(ctx)
+
+		r.True(d.WORMPolicy().Sealed())
+		r.ErrorIs(d.WriteExtent(ctx, testExtent.MapTo(10)), ErrWORMRetention)
+
+		setClock(d, 2*time.Hour)
+
+		r.NoError(d.WriteExtent(ctx, testExtent.MapTo(10)))
+		r.NoError(d.Discard(ctx, 0, 1))
+	})
+
+	t.Run("retention can only be extended", func(t *testing.T) {
+		r := require.New(t)
+
+		tmpdir, err := os.MkdirTemp("", "lsvd")
+		r.NoError(err)
+		defer os.RemoveAll(tmpdir)
+
+		d, err := NewDisk(ctx, log, tmpdir)
+		r.NoError(err)
+		defer d.Close(ctx)
+
+		r.Nil(d.WORMPolicy())
+		r.Error(d.EnableWORM(ctx, 0))
+
+		r.NoError(d.EnableWORM(ctx, time.Hour))
+		enabled := d.WORMPolicy().EnabledAt
+
+		r.NoError(d.EnableWORM(ctx, time.Minute))
+		r.Equal(time.Hour, d.WORMPolicy().Retention)
+
+		r.NoError(d.EnableWORM(ctx, 2*time.Hour))
+		r.Equal(2*time.Hour, d.WORMPolicy().Retention)
+		r.True(enabled.Equal(d.WORMPolicy().EnabledAt))
+	})
+
+	t.Run("leaves blocks written before WORM mode alone", func(t *testing.T) {
+		r := require.New(t)
+
+		tmpdir, err := os.MkdirTemp("", "lsvd")
+		r.NoError(err)
+		defer os.RemoveAll(tmpdir)
+
+		d, err := NewDisk(ctx, log, tmpdir)
+		r.NoError(err)
+		defer d.Close(ctx)
+
+		r.NoError(d.WriteExtent(ctx, testRandX.MapTo(0)))
+		r.NoError(d.CloseSegment(ctx))
+
+		// Segment ids only have millisecond precision
+		time.Sleep(5 * time.Millisecond)
+
+		r.NoError(d.EnableWORM(ctx, time.Hour))
+
+		r.NoError(d.WriteExtent(ctx, testExtent.MapTo(0)))
+	})
+}
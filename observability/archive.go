@@ -0,0 +1,478 @@
+package observability
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ArchiveStore is the object storage backing the log archive. Keys are
+// slash separated paths relative to the root of the store. Get must return an
+// error matching fs.ErrNotExist when the key is absent.
+type ArchiveStore interface {
+	Put(ctx context.Context, key string, data []byte) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+}
+
+// DirArchiveStore stores archive objects as files under a local directory.
+type DirArchiveStore struct {
+	Root string
+}
+
+func (d *DirArchiveStore) Put(ctx context.Context, key string, data []byte) error {
+	path := filepath.Join(d.Root, filepath.FromSlash(key))
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create archive directory: %w", err)
+	}
+
+	// Write to a temp file first so readers never observe a partial object.
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write archive object: %w", err)
+	}
+
+	return os.Rename(tmp, path)
+}
+
+func (d *DirArchiveStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	return os.Open(filepath.Join(d.Root, filepath.FromSlash(key)))
+}
+
+const (
+	archiveCatalogKey    = "catalog.json"
+	archiveFormatVersion = 1
+	archiveDayLayout     = "2006-01-02"
+)
+
+// ArchivePartition describes one archived object, holding the logs of a
+// single entity for a single UTC day.
+type ArchivePartition struct {
+	Entity  string    `json:"entity"`
+	Day     string    `json:"day"`
+	Key     string    `json:"key"`
+	MinTime time.Time `json:"min_time"`
+	MaxTime time.Time `json:"max_time"`
+	Rows    int       `json:"rows"`
+}
+
+// ArchiveCatalog indexes the archived partitions. ArchivedThrough is the
+// watermark before which all logs live in the archive rather than in
+// VictoriaLogs.
+type ArchiveCatalog struct {
+	ArchivedThrough time.Time          `json:"archived_through"`
+	Partitions      []ArchivePartition `json:"partitions"`
+}
+
+// archiveSegment is the serialized form of a partition. Each field is stored
+// as its own column so that the repetitive values (stream, attributes)
+// compress well and a reader can scan timestamps without touching bodies.
+type archiveSegment struct {
+	Version    int                 `json:"version"`
+	Entity     string              `json:"entity"`
+	Day        string              `json:"day"`
+	Timestamp  []int64             `json:"timestamp"`
+	Stream     []string            `json:"stream"`
+	TraceID    []string            `json:"trace_id"`
+	Body       []string            `json:"body"`
+	Attributes []map[string]string `json:"attributes"`
+}
+
+// LogArchive reads and writes archived logs in an ArchiveStore.
+type LogArchive struct {
+	Store ArchiveStore
+
+	mu sync.Mutex
+}
+
+// ArchivePartitionKey returns the object key for an entity's logs on day,
+// partitioned as logs/entity=<entity>/day=<YYYY-MM-DD>/logs.json.gz.
+func ArchivePartitionKey(entity string, day time.Time) string {
+	return fmt.Sprintf("logs/entity=%s/day=%s/logs.json.gz",
+		url.PathEscape(entity), day.UTC().Format(archiveDayLayout))
+}
+
+// Catalog loads the current catalog, returning an empty one if nothing has
+// been archived yet.
+func (a *LogArchive) Catalog(ctx context.Context) (*ArchiveCatalog, error) {
+	rc, err := a.Store.Get(ctx, archiveCatalogKey)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return &ArchiveCatalog{}, nil
+		}
+		return nil, fmt.Errorf("failed to read archive catalog: %w", err)
+	}
+	defer rc.Close()
+
+	var cat ArchiveCatalog
+	if err := json.NewDecoder(rc).Decode(&cat); err != nil {
+		return nil, fmt.Errorf("failed to decode archive catalog: %w", err)
+	}
+
+	return &cat, nil
+}
+
+func (a *LogArchive) saveCatalog(ctx context.Context, cat *ArchiveCatalog) error {
+	data, err := json.Marshal(cat)
+	if err != nil {
+		return fmt.Errorf("failed to marshal archive catalog: %w", err)
+	}
+
+	return a.Store.Put(ctx, archiveCatalogKey, data)
+}
+
+// WritePartition archives entries as the partition for entity on day and
+// records it in the catalog, replacing any previous partition with the same
+// key.
+func (a *LogArchive) WritePartition(ctx context.Context, entity string, day time.Time, entries []LogEntry) (ArchivePartition, error) {
+	day = day.UTC().Truncate(24 * time.Hour)
+
+	sorted := make([]LogEntry, len(entries))
+	copy(sorted, entries)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp.Before(sorted[j].Timestamp)
+	})
+
+	seg := archiveSegment{
+		Version:    archiveFormatVersion,
+		Entity:     entity,
+		Day:        day.Format(archiveDayLayout),
+		Timestamp:  make([]int64, len(sorted)),
+		Stream:     make([]string, len(sorted)),
+		TraceID:    make([]string, len(sorted)),
+		Body:       make([]string, len(sorted)),
+		Attributes: make([]map[string]string, len(sorted)),
+	}
+
+	for i, le := range sorted {
+		seg.Timestamp[i] = le.Timestamp.UnixNano()
+		seg.Stream[i] = string(le.Stream)
+		seg.TraceID[i] = le.TraceID
+		seg.Body[i] = le.Body
+		seg.Attributes[i] = le.Attributes
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(gz).Encode(&seg); err != nil {
+		return ArchivePartition{}, fmt.Errorf("failed to encode archive partition: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return ArchivePartition{}, fmt.Errorf("failed to compress archive partition: %w", err)
+	}
+
+	part := ArchivePartition{
+		Entity: entity,
+		Day:    seg.Day,
+		Key:    ArchivePartitionKey(entity, day),
+		Rows:   len(sorted),
+	}
+
+	if len(sorted) > 0 {
+		part.MinTime = sorted[0].Timestamp.UTC()
+		part.MaxTime = sorted[len(sorted)-1].Timestamp.UTC()
+	}
+
+	if err := a.Store.Put(ctx, part.Key, buf.Bytes()); err != nil {
+		return ArchivePartition{}, fmt.Errorf("failed to store archive partition: %w", err)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	cat, err := a.Catalog(ctx)
+	if err != nil {
+		return ArchivePartition{}, err
+	}
+
+	replaced := false
+	for i, p := range cat.Partitions {
+		if p.Key == part.Key {
+			cat.Partitions[i] = part
+			replaced = true
+			break
+		}
+	}
+
+	if !replaced {
+		cat.Partitions = append(cat.Partitions, part)
+	}
+
+	if err := a.saveCatalog(ctx, cat); err != nil {
+		return ArchivePartition{}, err
+	}
+
+	return part, nil
+}
+
+// MarkArchivedThrough advances the catalog watermark to t. The watermark never
+// moves backwards.
+func (a *LogArchive) MarkArchivedThrough(ctx context.Context, t time.Time) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	cat, err := a.Catalog(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !t.After(cat.ArchivedThrough) {
+		return nil
+	}
+
+	cat.ArchivedThrough = t.UTC()
+
+	return a.saveCatalog(ctx, cat)
+}
+
+func (a *LogArchive) readPartition(ctx context.Context, key string) (*archiveSegment, error) {
+	rc, err := a.Store.Get(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive partition %s: %w", key, err)
+	}
+	defer rc.Close()
+
+	gz, err := gzip.NewReader(rc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress archive partition %s: %w", key, err)
+	}
+	defer gz.Close()
+
+	var seg archiveSegment
+	if err := json.NewDecoder(gz).Decode(&seg); err != nil {
+		return nil, fmt.Errorf("failed to decode archive partition %s: %w", key, err)
+	}
+
+	if seg.Version != archiveFormatVersion {
+		return nil, fmt.Errorf("archive partition %s has unsupported version %d", key, seg.Version)
+	}
+
+	return &seg, nil
+}
+
+// Query returns the archived logs matching target with timestamps in
+// [start, end), sorted by time. LogsQL filters are not evaluated against the
+// archive, so a target with a Filter returns an error.
+func (a *LogArchive) Query(ctx context.Context, target LogTarget, start, end time.Time) ([]LogEntry, error) {
+	cat, err := a.Catalog(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.query(ctx, cat, target, start, end)
+}
+
+func (a *LogArchive) query(ctx context.Context, cat *ArchiveCatalog, target LogTarget, start, end time.Time) ([]LogEntry, error) {
+	if target.Filter != "" {
+		return nil, fmt.Errorf("log filters are not supported on archived logs")
+	}
+
+	var entries []LogEntry
+
+	for _, p := range cat.Partitions {
+		if target.SandboxID == "" && p.Entity != target.EntityID {
+			continue
+		}
+
+		if p.Rows == 0 || p.MaxTime.Before(start) || !p.MinTime.Before(end) {
+			continue
+		}
+
+		seg, err := a.readPartition(ctx, p.Key)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, ns := range seg.Timestamp {
+			ts := time.Unix(0, ns).UTC()
+			if ts.Before(start) || !ts.Before(end) {
+				continue
+			}
+
+			attrs := seg.Attributes[i]
+			if target.SandboxID != "" && attrs["sandbox"] != target.SandboxID {
+				continue
+			}
+
+			if target.TraceID != "" && seg.TraceID[i] != target.TraceID {
+				continue
+			}
+
+			if attrs == nil {
+				attrs = make(map[string]string)
+			}
+
+			entries = append(entries, LogEntry{
+				Timestamp:  ts,
+				Entity:     seg.Entity,
+				Stream:     LogStream(seg.Stream[i]),
+				TraceID:    seg.TraceID[i],
+				Attributes: attrs,
+				Body:       seg.Body[i],
+			})
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.Before(entries[j].Timestamp)
+	})
+
+	return entries, nil
+}
+
+// LogArchiver periodically moves logs older than HotRetention out of
+// VictoriaLogs and into the archive, one UTC day at a time.
+type LogArchiver struct {
+	Log     *slog.Logger
+	Reader  *LogReader
+	Archive *LogArchive
+
+	// HotRetention is how long logs stay queryable only from VictoriaLogs. It
+	// must be shorter than the VictoriaLogs retention period, otherwise logs
+	// are deleted before they can be archived.
+	HotRetention time.Duration
+
+	// Backfill bounds how far before the hot window the first run looks for
+	// logs when the catalog is empty.
+	Backfill time.Duration
+
+	Interval time.Duration
+}
+
+const (
+	DefaultArchiveHotRetention = 7 * 24 * time.Hour
+	DefaultArchiveBackfill     = 7 * 24 * time.Hour
+	DefaultArchiveInterval     = time.Hour
+)
+
+func (a *LogArchiver) defaults() {
+	if a.Log == nil {
+		a.Log = slog.Default()
+	}
+	if a.HotRetention == 0 {
+		a.HotRetention = DefaultArchiveHotRetention
+	}
+	if a.Backfill == 0 {
+		a.Backfill = DefaultArchiveBackfill
+	}
+	if a.Interval == 0 {
+		a.Interval = DefaultArchiveInterval
+	}
+}
+
+// Run archives aged logs every Interval until ctx is cancelled.
+func (a *LogArchiver) Run(ctx context.Context) error {
+	a.defaults()
+
+	ticker := time.NewTicker(a.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := a.ArchiveAged(ctx, time.Now()); err != nil {
+			a.Log.Error("failed to archive logs", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// ArchiveAged archives every full day that ended before now-HotRetention and
+// hasn't been archived yet.
+func (a *LogArchiver) ArchiveAged(ctx context.Context, now time.Time) error {
+	a.defaults()
+
+	cat, err := a.Archive.Catalog(ctx)
+	if err != nil {
+		return err
+	}
+
+	cutoff := now.UTC().Add(-a.HotRetention)
+
+	day := cat.ArchivedThrough
+	if day.IsZero() {
+		day = cutoff.Add(-a.Backfill)
+	}
+	day = day.UTC().Truncate(24 * time.Hour)
+
+	for !day.Add(24 * time.Hour).After(cutoff) {
+		if err := a.ArchiveDay(ctx, day); err != nil {
+			return err
+		}
+
+		day = day.Add(24 * time.Hour)
+	}
+
+	return nil
+}
+
+// ArchiveDay exports all logs for the UTC day containing day, writing one
+// partition per entity, and then advances the catalog watermark past it.
+// Entities are exported one at a time, so only a single entity's logs for the
+// day are held in memory.
+func (a *LogArchiver) ArchiveDay(ctx context.Context, day time.Time) error {
+	day = day.UTC().Truncate(24 * time.Hour)
+	end := day.Add(24 * time.Hour)
+
+	// VictoriaLogs treats end as inclusive, so stop just short of midnight to
+	// keep entries from landing in two partitions.
+	last := end.Add(-time.Nanosecond)
+
+	entities, err := a.Reader.fieldValues(ctx, "*", "entity", day, last)
+	if err != nil {
+		return fmt.Errorf("failed to list entities for %s: %w", day.Format(archiveDayLayout), err)
+	}
+
+	for _, entity := range entities {
+		entries, err := a.exportEntity(ctx, entity, day, last)
+		if err != nil {
+			return fmt.Errorf("failed to export logs of %s for %s: %w", entity, day.Format(archiveDayLayout), err)
+		}
+
+		part, err := a.Archive.WritePartition(ctx, entity, day, entries)
+		if err != nil {
+			return err
+		}
+
+		a.Log.Debug("archived logs", "entity", entity, "day", part.Day, "rows", part.Rows)
+	}
+
+	return a.Archive.MarkArchivedThrough(ctx, end)
+}
+
+// exportEntity streams the logs of entity between start and end, inclusive.
+func (a *LogArchiver) exportEntity(ctx context.Context, entity string, start, end time.Time) ([]LogEntry, error) {
+	logCh := make(chan LogEntry, 100)
+	errCh := make(chan error, 1)
+
+	go func() {
+		defer close(logCh)
+		errCh <- a.Reader.ReadStream(ctx, LogTarget{EntityID: entity}, logCh, WithFromTime(start), WithToTime(end))
+	}()
+
+	var entries []LogEntry
+	for le := range logCh {
+		entries = append(entries, le)
+	}
+
+	if err := <-errCh; err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,212 @@
+package observability_test
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"miren.dev/runtime/observability"
+)
+
+// fakeVictoriaLogs serves /select/logsql/query and /select/logsql/field_values
+// over a fixed set of entries, honoring the entity match and time range the
+// reader sends. Queries must name an entity, so a whole day is never exported
+// in one go.
+func fakeVictoriaLogs(t *testing.T, entries []map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		start, err := time.Parse(time.RFC3339Nano, q.Get("start"))
+		require.NoError(t, err)
+		end, err := time.Parse(time.RFC3339Nano, q.Get("end"))
+		require.NoError(t, err)
+
+		var inRange []map[string]string
+		for _, e := range entries {
+			ts, err := time.Parse(time.RFC3339Nano, e["_time"])
+			require.NoError(t, err)
+
+			if !ts.Before(start) && !ts.After(end) {
+				inRange = append(inRange, e)
+			}
+		}
+
+		switch r.URL.Path {
+		case "/select/logsql/field_values":
+			require.Equal(t, "entity", q.Get("field"))
+
+			type value struct {
+				Value string `json:"value"`
+				Hits  int    `json:"hits"`
+			}
+
+			var values []value
+			seen := make(map[string]int)
+			for _, e := range inRange {
+				if i, ok := seen[e["entity"]]; ok {
+					values[i].Hits++
+					continue
+				}
+
+				seen[e["entity"]] = len(values)
+				values = append(values, value{Value: e["entity"], Hits: 1})
+			}
+
+			require.NoError(t, json.NewEncoder(w).Encode(map[string]any{"values": values}))
+
+		case "/select/logsql/query":
+			query := strings.TrimSuffix(q.Get("query"), " | sort by (_time) asc")
+			require.NotEqual(t, "*", query, "logs should be read one entity at a time")
+
+			enc := json.NewEncoder(w)
+			for _, e := range inRange {
+				if query != `entity:"`+e["entity"]+`"` {
+					continue
+				}
+
+				require.NoError(t, enc.Encode(e))
+			}
+
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestLogArchive(t *testing.T) {
+	t.Run("writes partitions as compressed columns with a catalog", func(t *testing.T) {
+		ctx := context.Background()
+		r := require.New(t)
+
+		dir := t.TempDir()
+		archive := &observability.LogArchive{
+			Store: &observability.DirArchiveStore{Root: dir},
+		}
+
+		day := time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC)
+
+		part, err := archive.WritePartition(ctx, "app/web", day, []observability.LogEntry{
+			{
+				Timestamp: day.Add(2 * time.Hour),
+				Stream:    observability.Stderr,
+				Body:      "second",
+			},
+			{
+				Timestamp:  day.Add(time.Hour),
+				Stream:     observability.Stdout,
+				TraceID:    "abc",
+				Body:       "first",
+				Attributes: map[string]string{"sandbox": "sb-1"},
+			},
+		})
+		r.NoError(err)
+
+		r.Equal("logs/entity=app%2Fweb/day=2026-03-04/logs.json.gz", part.Key)
+		r.Equal(2, part.Rows)
+		r.Equal(day.Add(time.Hour), part.MinTime)
+		r.Equal(day.Add(2*time.Hour), part.MaxTime)
+
+		f, err := os.Open(filepath.Join(dir, filepath.FromSlash(part.Key)))
+		r.NoError(err)
+		defer f.Close()
+
+		gz, err := gzip.NewReader(f)
+		r.NoError(err)
+
+		var seg struct {
+			Version   int      `json:"version"`
+			Entity    string   `json:"entity"`
+			Day       string   `json:"day"`
+			Timestamp []int64  `json:"timestamp"`
+			Stream    []string `json:"stream"`
+			TraceID   []string `json:"trace_id"`
+			Body      []string `json:"body"`
+		}
+		r.NoError(json.NewDecoder(gz).Decode(&seg))
+
+		r.Equal(1, seg.Version)
+		r.Equal("app/web", seg.Entity)
+		r.Equal("2026-03-04", seg.Day)
+		r.Equal([]int64{day.Add(time.Hour).UnixNano(), day.Add(2 * time.Hour).UnixNano()}, seg.Timestamp)
+		r.Equal([]string{"stdout", "stderr"}, seg.Stream)
+		r.Equal([]string{"abc", ""}, seg.TraceID)
+		r.Equal([]string{"first", "second"}, seg.Body)
+
+		cat, err := archive.Catalog(ctx)
+		r.NoError(err)
+		r.Equal([]observability.ArchivePartition{part}, cat.Partitions)
+
+		entries, err := archive.Query(ctx, observability.LogTarget{SandboxID: "sb-1"}, day, day.Add(24*time.Hour))
+		r.NoError(err)
+		r.Len(entries, 1)
+		r.Equal("first", entries[0].Body)
+	})
+
+	t.Run("reader transparently includes archived logs", func(t *testing.T) {
+		ctx := context.Background()
+		r := require.New(t)
+
+		now := time.Now().UTC()
+		old := now.Add(-10 * 24 * time.Hour).Truncate(24 * time.Hour).Add(6 * time.Hour)
+		recent := now.Add(-time.Hour)
+
+		vl := fakeVictoriaLogs(t, []map[string]string{
+			{"_time": old.Format(time.RFC3339Nano), "_msg": "old line", "entity": "app1", "stream": "stdout"},
+			{"_time": old.Add(time.Minute).Format(time.RFC3339Nano), "_msg": "other app", "entity": "app2", "stream": "stdout"},
+			{"_time": recent.Format(time.RFC3339Nano), "_msg": "recent line", "entity": "app1", "stream": "stdout"},
+		})
+		defer vl.Close()
+
+		archive := &observability.LogArchive{
+			Store: &observability.DirArchiveStore{Root: t.TempDir()},
+		}
+
+		reader := &observability.LogReader{
+			Address: vl.URL,
+			Archive: archive,
+		}
+		r.NoError(reader.Populated())
+
+		archiver := &observability.LogArchiver{
+			Reader:       reader,
+			Archive:      archive,
+			HotRetention: 7 * 24 * time.Hour,
+			Backfill:     7 * 24 * time.Hour,
+		}
+
+		r.NoError(archiver.ArchiveAged(ctx, now))
+
+		cat, err := archive.Catalog(ctx)
+		r.NoError(err)
+		r.Len(cat.Partitions, 2)
+		r.False(cat.ArchivedThrough.After(now.Add(-7 * 24 * time.Hour)))
+		r.True(cat.ArchivedThrough.After(old))
+
+		// Stop serving the archived entries from the hot store, as happens once
+		// VictoriaLogs retention expires them.
+		vl.Close()
+		vl = fakeVictoriaLogs(t, []map[string]string{
+			{"_time": recent.Format(time.RFC3339Nano), "_msg": "recent line", "entity": "app1", "stream": "stdout"},
+		})
+		defer vl.Close()
+		reader.Address = vl.URL
+
+		entries, err := reader.Read(ctx, "app1", observability.WithFromTime(now.Add(-30*24*time.Hour)))
+		r.NoError(err)
+		r.Len(entries, 2)
+		r.Equal("old line", entries[0].Body)
+		r.Equal("recent line", entries[1].Body)
+
+		entries, err = reader.Read(ctx, "app1", observability.WithFromTime(now.Add(-2*time.Hour)))
+		r.NoError(err)
+		r.Len(entries, 1)
+		r.Equal("recent line", entries[0].Body)
+	})
+}
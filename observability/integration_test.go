@@ -112,6 +112,7 @@ func TestVictoriaLogsIntegration(t *testing.T) {
 			})
 			r.NoError(err, "failed to write log %d", i)
 		}
+		r.NoError(writer.Flush())
 
 		// Give VictoriaLogs time to index
 		time.Sleep(3 * time.Second)
@@ -196,6 +197,7 @@ func TestVictoriaLogsIntegration(t *testing.T) {
 			})
 			r.NoError(err)
 		}
+		r.NoError(writer.Flush())
 
 		time.Sleep(3 * time.Second)
 
@@ -267,6 +269,7 @@ func TestVictoriaLogsIntegration(t *testing.T) {
 			})
 			r.NoError(err)
 		}
+		r.NoError(writer.Flush())
 
 		time.Sleep(3 * time.Second)
 
@@ -0,0 +1,315 @@
+package observability
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// LogMetricKind is the type of metric a LogMetricRule produces.
+type LogMetricKind string
+
+const (
+	// LogMetricCounter counts the log lines matching a rule.
+	LogMetricCounter LogMetricKind = "counter"
+
+	// LogMetricGauge sets a gauge to the value extracted from the most
+	// recent matching line.
+	LogMetricGauge LogMetricKind = "gauge"
+
+	// LogMetricHistogram observes the value extracted from each matching
+	// line.
+	LogMetricHistogram LogMetricKind = "histogram"
+)
+
+// LogMetricRule derives a metric from log lines, so that apps which only
+// emit logs still produce metrics.
+type LogMetricRule struct {
+	// Name is the name of the metric. Every metric is labeled with the
+	// entity that wrote the line.
+	Name string
+	Help string
+	Kind LogMetricKind
+
+	// Pattern is matched against the body of each line. An empty pattern
+	// matches every line.
+	Pattern string
+
+	// Stream, when set, limits the rule to lines from that stream.
+	Stream LogStream
+
+	// Field names where gauges and histograms get their value: a named
+	// capture group of Pattern, or else an attribute of the entry. Lines
+	// whose value isn't a number are skipped.
+	Field string
+
+	// Buckets are the histogram buckets, defaulting to prometheus.DefBuckets.
+	Buckets []float64
+
+	// Labels promotes values to labels of the metric, each looked up the
+	// same way as Field. Lines without a value get an empty label.
+	Labels []string
+
+	// MaxLabelValues caps how many distinct values each of Labels may take,
+	// DefaultMaxLabelValues if 0. Once a label reaches it, lines with new
+	// values are recorded under OverflowLabelValue instead, so a label fed
+	// unbounded values, like request paths with ids in them, can't explode
+	// the metric's cardinality.
+	MaxLabelValues int
+}
+
+const (
+	DefaultMaxLabelValues = 100
+
+	// OverflowLabelValue is the label value lines are recorded under once
+	// a label has reached its cap.
+	OverflowLabelValue = "__other__"
+)
+
+// labelGuard tracks the values seen for one of a rule's labels.
+type labelGuard struct {
+	name   string
+	group  int
+	seen   map[string]struct{}
+	warned bool
+}
+
+type logMetric struct {
+	rule    LogMetricRule
+	re      *regexp.Regexp
+	group   int
+	counter *prometheus.CounterVec
+	gauge   *prometheus.GaugeVec
+	hist    *prometheus.HistogramVec
+
+	mu     sync.Mutex
+	max    int
+	labels []*labelGuard
+}
+
+// LogMetricExtractor is a LogWriter that updates metrics from the entries
+// written through it according to its rules, then passes them on to Next.
+type LogMetricExtractor struct {
+	Log  *slog.Logger
+	Next LogWriter
+
+	metrics []*logMetric
+}
+
+// NewLogMetricExtractor compiles rules and registers their metrics with reg.
+func NewLogMetricExtractor(next LogWriter, reg prometheus.Registerer, rules []LogMetricRule) (*LogMetricExtractor, error) {
+	x := &LogMetricExtractor{Log: slog.Default(), Next: next}
+
+	for _, rule := range rules {
+		m, err := newLogMetric(rule)
+		if err != nil {
+			return nil, err
+		}
+
+		var c prometheus.Collector
+
+		switch {
+		case m.counter != nil:
+			c = m.counter
+		case m.gauge != nil:
+			c = m.gauge
+		default:
+			c = m.hist
+		}
+
+		if err := reg.Register(c); err != nil {
+			return nil, fmt.Errorf("failed to register log metric %s: %w", rule.Name, err)
+		}
+
+		x.metrics = append(x.metrics, m)
+	}
+
+	return x, nil
+}
+
+func newLogMetric(rule LogMetricRule) (*logMetric, error) {
+	if rule.Name == "" {
+		return nil, fmt.Errorf("log metric rule is missing a name")
+	}
+
+	m := &logMetric{rule: rule, group: -1, max: rule.MaxLabelValues}
+
+	if m.max <= 0 {
+		m.max = DefaultMaxLabelValues
+	}
+
+	if rule.Pattern != "" {
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern for log metric %s: %w", rule.Name, err)
+		}
+
+		m.re = re
+
+		if rule.Field != "" {
+			m.group = re.SubexpIndex(rule.Field)
+		}
+	}
+
+	labels := []string{"entity"}
+
+	for _, name := range rule.Labels {
+		lg := &labelGuard{name: name, group: -1, seen: make(map[string]struct{})}
+
+		if m.re != nil {
+			lg.group = m.re.SubexpIndex(name)
+		}
+
+		labels = append(labels, name)
+		m.labels = append(m.labels, lg)
+	}
+
+	help := rule.Help
+	if help == "" {
+		help = "Derived from logs"
+	}
+
+	switch rule.Kind {
+	case LogMetricCounter:
+		m.counter = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: rule.Name,
+			Help: help,
+		}, labels)
+	case LogMetricGauge, LogMetricHistogram:
+		if rule.Field == "" {
+			return nil, fmt.Errorf("log metric %s needs a field to take its value from", rule.Name)
+		}
+
+		if rule.Kind == LogMetricGauge {
+			m.gauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+				Name: rule.Name,
+				Help: help,
+			}, labels)
+		} else {
+			buckets := rule.Buckets
+			if len(buckets) == 0 {
+				buckets = prometheus.DefBuckets
+			}
+
+			m.hist = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+				Name:    rule.Name,
+				Help:    help,
+				Buckets: buckets,
+			}, labels)
+		}
+	default:
+		return nil, fmt.Errorf("unknown kind %q for log metric %s", rule.Kind, rule.Name)
+	}
+
+	return m, nil
+}
+
+// lookup returns the value of a named capture group of the rule's pattern,
+// or else of the entry's attribute name.
+func lookup(name string, group int, le LogEntry, match []string) (string, bool) {
+	if group >= 0 && match != nil {
+		return match[group], true
+	}
+
+	v, ok := le.Attributes[name]
+	return v, ok
+}
+
+// value returns the number a matching entry carries for the rule's field.
+func (m *logMetric) value(le LogEntry, match []string) (float64, bool) {
+	str, ok := lookup(m.rule.Field, m.group, le, match)
+	if !ok {
+		return 0, false
+	}
+
+	v, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return v, true
+}
+
+// labelValues returns the label values for a matching entry, bucketing
+// values past a label's cap into OverflowLabelValue.
+func (m *logMetric) labelValues(log *slog.Logger, entity string, le LogEntry, match []string) []string {
+	values := []string{entity}
+
+	if len(m.labels) == 0 {
+		return values
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, lg := range m.labels {
+		v, _ := lookup(lg.name, lg.group, le, match)
+
+		if _, ok := lg.seen[v]; !ok {
+			if len(lg.seen) >= m.max {
+				if !lg.warned {
+					lg.warned = true
+					log.Warn("log metric label reached its cap on distinct values, recording new values as "+OverflowLabelValue,
+						"metric", m.rule.Name, "label", lg.name, "max", m.max)
+				}
+
+				v = OverflowLabelValue
+			} else {
+				lg.seen[v] = struct{}{}
+			}
+		}
+
+		values = append(values, v)
+	}
+
+	return values
+}
+
+func (m *logMetric) observe(log *slog.Logger, entity string, le LogEntry) {
+	if m.rule.Stream != "" && le.Stream != m.rule.Stream {
+		return
+	}
+
+	var match []string
+
+	if m.re != nil {
+		match = m.re.FindStringSubmatch(le.Body)
+		if match == nil {
+			return
+		}
+	}
+
+	if m.counter != nil {
+		m.counter.WithLabelValues(m.labelValues(log, entity, le, match)...).Inc()
+		return
+	}
+
+	v, ok := m.value(le, match)
+	if !ok {
+		return
+	}
+
+	lvs := m.labelValues(log, entity, le, match)
+
+	if m.gauge != nil {
+		m.gauge.WithLabelValues(lvs...).Set(v)
+	} else {
+		m.hist.WithLabelValues(lvs...).Observe(v)
+	}
+}
+
+func (x *LogMetricExtractor) WriteEntry(entity string, le LogEntry) error {
+	for _, m := range x.metrics {
+		m.observe(x.Log, entity, le)
+	}
+
+	if x.Next == nil {
+		return nil
+	}
+
+	return x.Next.WriteEntry(entity, le)
+}
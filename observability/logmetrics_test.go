@@ -0,0 +1,201 @@
+package observability_test
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"miren.dev/runtime/observability"
+)
+
+type recordingLogWriter struct {
+	entries []observability.LogEntry
+}
+
+func (w *recordingLogWriter) WriteEntry(entity string, le observability.LogEntry) error {
+	w.entries = append(w.entries, le)
+	return nil
+}
+
+func TestLogMetricExtractor(t *testing.T) {
+	write := func(t *testing.T, x *observability.LogMetricExtractor, entity string, lines ...string) {
+		for _, line := range lines {
+			require.NoError(t, x.WriteEntry(entity, observability.LogEntry{
+				Timestamp: time.Now(),
+				Stream:    observability.Stdout,
+				Body:      line,
+			}))
+		}
+	}
+
+	t.Run("counts lines matching a pattern", func(t *testing.T) {
+		r := require.New(t)
+
+		reg := prometheus.NewRegistry()
+		next := &recordingLogWriter{}
+
+		x, err := observability.NewLogMetricExtractor(next, reg, []observability.LogMetricRule{
+			{
+				Name:    "app_errors_total",
+				Kind:    observability.LogMetricCounter,
+				Pattern: `\bERROR\b`,
+			},
+		})
+		r.NoError(err)
+
+		write(t, x, "app-1",
+			"INFO starting up",
+			"ERROR connection refused",
+			"ERROR timed out",
+		)
+		write(t, x, "app-2", "ERROR disk full")
+
+		// Every entry still reaches the next writer
+		r.Len(next.entries, 4)
+
+		expected := `
+# HELP app_errors_total Derived from logs
+# TYPE app_errors_total counter
+app_errors_total{entity="app-1"} 2
+app_errors_total{entity="app-2"} 1
+`
+		r.NoError(testutil.GatherAndCompare(reg, strings.NewReader(expected), "app_errors_total"))
+	})
+
+	t.Run("observes a numeric field in a histogram", func(t *testing.T) {
+		r := require.New(t)
+
+		reg := prometheus.NewRegistry()
+
+		x, err := observability.NewLogMetricExtractor(nil, reg, []observability.LogMetricRule{
+			{
+				Name:    "app_request_seconds",
+				Help:    "Request durations from access logs",
+				Kind:    observability.LogMetricHistogram,
+				Pattern: `request done duration=(?P<duration>[0-9.]+)s`,
+				Field:   "duration",
+				Buckets: []float64{0.3, 1},
+			},
+		})
+		r.NoError(err)
+
+		write(t, x, "app-1",
+			"request done duration=0.25s",
+			"request done duration=0.5s",
+			"request done duration=2.5s",
+			"request done duration=bogus",
+			"unrelated line",
+		)
+
+		expected := `
+# HELP app_request_seconds Request durations from access logs
+# TYPE app_request_seconds histogram
+app_request_seconds_bucket{entity="app-1",le="0.3"} 1
+app_request_seconds_bucket{entity="app-1",le="1"} 2
+app_request_seconds_bucket{entity="app-1",le="+Inf"} 3
+app_request_seconds_sum{entity="app-1"} 3.25
+app_request_seconds_count{entity="app-1"} 3
+`
+		r.NoError(testutil.GatherAndCompare(reg, strings.NewReader(expected), "app_request_seconds"))
+	})
+
+	t.Run("sets a gauge from an attribute", func(t *testing.T) {
+		r := require.New(t)
+
+		reg := prometheus.NewRegistry()
+
+		x, err := observability.NewLogMetricExtractor(nil, reg, []observability.LogMetricRule{
+			{
+				Name:  "app_queue_depth",
+				Kind:  observability.LogMetricGauge,
+				Field: "queue_depth",
+			},
+		})
+		r.NoError(err)
+
+		for _, depth := range []string{"7", "3"} {
+			r.NoError(x.WriteEntry("app-1", observability.LogEntry{
+				Body:       "queue status",
+				Attributes: map[string]string{"queue_depth": depth},
+			}))
+		}
+
+		expected := `
+# HELP app_queue_depth Derived from logs
+# TYPE app_queue_depth gauge
+app_queue_depth{entity="app-1"} 3
+`
+		r.NoError(testutil.GatherAndCompare(reg, strings.NewReader(expected), "app_queue_depth"))
+	})
+
+	t.Run("buckets label values past the cap", func(t *testing.T) {
+		r := require.New(t)
+
+		reg := prometheus.NewRegistry()
+
+		x, err := observability.NewLogMetricExtractor(nil, reg, []observability.LogMetricRule{
+			{
+				Name:           "app_requests_total",
+				Kind:           observability.LogMetricCounter,
+				Pattern:        `(?P<method>[A-Z]+) (?P<path>/\S*)`,
+				Labels:         []string{"method", "path"},
+				MaxLabelValues: 5,
+			},
+		})
+		r.NoError(err)
+
+		var logs bytes.Buffer
+		x.Log = slog.New(slog.NewTextHandler(&logs, nil))
+
+		for i := range 50 {
+			write(t, x, "app-1", fmt.Sprintf("GET /users/%d", i))
+		}
+
+		// A path seen before the cap keeps its own series
+		write(t, x, "app-1", "GET /users/0", "POST /users/0")
+
+		// Each label is capped on its own, so the series stay bounded by
+		// the product of the caps
+		r.Equal(7, testutil.CollectAndCount(reg, "app_requests_total"))
+
+		expected := `
+# HELP app_requests_total Derived from logs
+# TYPE app_requests_total counter
+app_requests_total{entity="app-1",method="GET",path="/users/0"} 2
+app_requests_total{entity="app-1",method="GET",path="/users/1"} 1
+app_requests_total{entity="app-1",method="GET",path="/users/2"} 1
+app_requests_total{entity="app-1",method="GET",path="/users/3"} 1
+app_requests_total{entity="app-1",method="GET",path="/users/4"} 1
+app_requests_total{entity="app-1",method="GET",path="__other__"} 45
+app_requests_total{entity="app-1",method="POST",path="/users/0"} 1
+`
+		r.NoError(testutil.GatherAndCompare(reg, strings.NewReader(expected), "app_requests_total"))
+
+		r.Equal(1, strings.Count(logs.String(), "level=WARN"), "the overflow is only warned about once")
+		r.Contains(logs.String(), "label=path")
+	})
+
+	t.Run("rejects invalid rules", func(t *testing.T) {
+		r := require.New(t)
+
+		rules := []observability.LogMetricRule{
+			{Name: "bad_pattern", Kind: observability.LogMetricCounter, Pattern: "("},
+			{Name: "no_field", Kind: observability.LogMetricHistogram},
+			{Name: "bad_kind", Kind: "summary"},
+			{Kind: observability.LogMetricCounter},
+			{Name: "bad_label", Kind: observability.LogMetricCounter, Labels: []string{"not-a-label"}},
+			{Name: "dup_label", Kind: observability.LogMetricCounter, Labels: []string{"entity"}},
+		}
+
+		for _, rule := range rules {
+			_, err := observability.NewLogMetricExtractor(nil, prometheus.NewRegistry(), []observability.LogMetricRule{rule})
+			r.Error(err, "rule %s", rule.Name)
+		}
+	})
+}
@@ -0,0 +1,526 @@
+package observability
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// LogQuery is a parsed log search expression, such as
+//
+//	level:error AND attr.region:us-east AND "timeout"
+//
+// The syntax is:
+//   - Free text: `timeout` or `"connection reset"` matches the message
+//   - Field predicates: `level:error`, `stream:stderr`, `attr.region:"us east"`
+//   - Prefix matches: a trailing `*` on an unquoted value, as in `level:warn*`
+//   - Boolean operators: `AND`, `OR` and `NOT` (or a leading `-`), with
+//     parentheses for grouping. Adjacent terms are ANDed.
+//
+// NOT binds tightest, then AND, then OR. Only the fields in logQueryFields and
+// attributes named attr.<name> may be queried.
+//
+// A LogQuery is compiled to LogsQL with every user supplied value and field
+// name quoted, so a query can't escape into raw LogsQL. The result is meant
+// for LogTarget.Filter.
+type LogQuery struct {
+	root logQueryNode
+}
+
+const (
+	maxLogQueryLength = 2048
+	maxLogQueryDepth  = 32
+)
+
+// logQueryFields maps the fields a query may use to the stored field names.
+var logQueryFields = map[string]string{
+	"msg":     "_msg",
+	"level":   "level",
+	"stream":  "stream",
+	"entity":  "entity",
+	"sandbox": "sandbox",
+	"trace":   "trace_id",
+}
+
+var logQueryAttrName = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_.-]*$`)
+
+// LogQueryError reports a malformed query and where in it the problem is.
+type LogQueryError struct {
+	Pos int
+	Msg string
+}
+
+func (e *LogQueryError) Error() string {
+	return fmt.Sprintf("invalid log query at position %d: %s", e.Pos, e.Msg)
+}
+
+// ParseLogQuery parses a log search expression. It returns nil if the input is
+// empty.
+func ParseLogQuery(input string) (*LogQuery, error) {
+	if strings.TrimSpace(input) == "" {
+		return nil, nil
+	}
+
+	if len(input) > maxLogQueryLength {
+		return nil, &LogQueryError{Pos: maxLogQueryLength, Msg: fmt.Sprintf("query is longer than %d bytes", maxLogQueryLength)}
+	}
+
+	toks, err := lexLogQuery(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &logQueryParser{toks: toks, end: len(input)}
+
+	root, err := p.parseOr(0)
+	if err != nil {
+		return nil, err
+	}
+
+	if t := p.peek(); t != nil {
+		if t.kind == lqRParen {
+			return nil, &LogQueryError{Pos: t.pos, Msg: "unmatched )"}
+		}
+		return nil, &LogQueryError{Pos: t.pos, Msg: "unexpected " + t.describe()}
+	}
+
+	return &LogQuery{root: root}, nil
+}
+
+// LogsQL returns the query as a LogsQL filter expression.
+func (q *LogQuery) LogsQL() string {
+	if q == nil {
+		return ""
+	}
+
+	return q.root.logsQL()
+}
+
+// String returns the query in canonical form, with every operator explicit
+// and parenthesized.
+func (q *LogQuery) String() string {
+	if q == nil {
+		return ""
+	}
+
+	return q.root.String()
+}
+
+type logQueryNode interface {
+	logsQL() string
+	String() string
+}
+
+type lqAndNode struct {
+	left, right logQueryNode
+}
+
+func (n *lqAndNode) logsQL() string {
+	return "(" + n.left.logsQL() + " AND " + n.right.logsQL() + ")"
+}
+
+func (n *lqAndNode) String() string {
+	return "(" + n.left.String() + " AND " + n.right.String() + ")"
+}
+
+type lqOrNode struct {
+	left, right logQueryNode
+}
+
+func (n *lqOrNode) logsQL() string {
+	return "(" + n.left.logsQL() + " OR " + n.right.logsQL() + ")"
+}
+
+func (n *lqOrNode) String() string {
+	return "(" + n.left.String() + " OR " + n.right.String() + ")"
+}
+
+type lqNotNode struct {
+	node logQueryNode
+}
+
+func (n *lqNotNode) logsQL() string {
+	return "NOT " + n.node.logsQL()
+}
+
+func (n *lqNotNode) String() string {
+	return "NOT " + n.node.String()
+}
+
+// lqTermNode matches a value, either in the message or in a field.
+type lqTermNode struct {
+	name   string // field as written in the query, empty for free text
+	field  string // stored field name
+	value  string
+	prefix bool
+}
+
+func (n *lqTermNode) logsQL() string {
+	var sb strings.Builder
+
+	switch n.field {
+	case "":
+		// Free text is a phrase filter on the message.
+	case "_msg":
+		sb.WriteString("_msg:")
+	default:
+		// Other fields hold a single value, so they match exactly.
+		sb.WriteString(logsQLQuote(n.field))
+		sb.WriteString(":=")
+	}
+
+	sb.WriteString(logsQLQuote(n.value))
+
+	if n.prefix {
+		sb.WriteString("*")
+	}
+
+	return sb.String()
+}
+
+func (n *lqTermNode) String() string {
+	var sb strings.Builder
+
+	if n.name != "" {
+		sb.WriteString(n.name)
+		sb.WriteString(":")
+	}
+
+	sb.WriteString(logsQLQuote(n.value))
+
+	if n.prefix {
+		sb.WriteString("*")
+	}
+
+	return sb.String()
+}
+
+type lqTokenKind int
+
+const (
+	lqTerm lqTokenKind = iota
+	lqAnd
+	lqOr
+	lqNot
+	lqLParen
+	lqRParen
+)
+
+type lqToken struct {
+	kind lqTokenKind
+	pos  int
+
+	// Set for lqTerm
+	field  string
+	value  string
+	quoted bool
+}
+
+func (t *lqToken) describe() string {
+	switch t.kind {
+	case lqAnd:
+		return "AND"
+	case lqOr:
+		return "OR"
+	case lqNot:
+		return "NOT"
+	case lqLParen:
+		return "("
+	case lqRParen:
+		return ")"
+	default:
+		return fmt.Sprintf("term %q", t.value)
+	}
+}
+
+func lexLogQuery(input string) ([]*lqToken, error) {
+	var toks []*lqToken
+
+	i := 0
+	for i < len(input) {
+		c := input[i]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			toks = append(toks, &lqToken{kind: lqLParen, pos: i})
+			i++
+		case c == ')':
+			toks = append(toks, &lqToken{kind: lqRParen, pos: i})
+			i++
+		case c == '-':
+			// A leading - negates the term that follows it.
+			toks = append(toks, &lqToken{kind: lqNot, pos: i})
+			i++
+		default:
+			tok, next, err := lexLogQueryTerm(input, i)
+			if err != nil {
+				return nil, err
+			}
+			toks = append(toks, tok)
+			i = next
+		}
+	}
+
+	return toks, nil
+}
+
+// lexLogQueryTerm reads a term starting at i: a quoted string, a bare word, or
+// a field name followed by a colon and either of those.
+func lexLogQueryTerm(input string, i int) (*lqToken, int, error) {
+	tok := &lqToken{kind: lqTerm, pos: i}
+
+	if input[i] == '"' {
+		val, next, err := lexLogQueryQuoted(input, i)
+		if err != nil {
+			return nil, 0, err
+		}
+		tok.value, tok.quoted = val, true
+		return tok, next, nil
+	}
+
+	start := i
+	for i < len(input) && !isLogQueryDelim(input[i]) && input[i] != ':' {
+		i++
+	}
+
+	word := input[start:i]
+
+	if i < len(input) && input[i] == ':' {
+		if word == "" {
+			return nil, 0, &LogQueryError{Pos: start, Msg: "missing field name before :"}
+		}
+
+		tok.field = word
+		i++
+
+		if i < len(input) && input[i] == '"' {
+			val, next, err := lexLogQueryQuoted(input, i)
+			if err != nil {
+				return nil, 0, err
+			}
+			tok.value, tok.quoted = val, true
+			return tok, next, nil
+		}
+
+		vstart := i
+		for i < len(input) && !isLogQueryDelim(input[i]) {
+			if input[i] == ':' {
+				return nil, 0, &LogQueryError{Pos: i, Msg: "unexpected : in value, quote values containing :"}
+			}
+			i++
+		}
+
+		if i == vstart {
+			return nil, 0, &LogQueryError{Pos: vstart, Msg: fmt.Sprintf("missing value for field %q", word)}
+		}
+
+		tok.value = input[vstart:i]
+		return tok, i, nil
+	}
+
+	switch word {
+	case "AND":
+		tok.kind = lqAnd
+	case "OR":
+		tok.kind = lqOr
+	case "NOT":
+		tok.kind = lqNot
+	default:
+		tok.value = word
+	}
+
+	return tok, i, nil
+}
+
+func isLogQueryDelim(c byte) bool {
+	switch c {
+	case ' ', '\t', '\n', '\r', '(', ')', '"':
+		return true
+	}
+	return false
+}
+
+// lexLogQueryQuoted reads the double quoted string starting at i, where \"
+// and \\ escape a quote and a backslash.
+func lexLogQueryQuoted(input string, i int) (string, int, error) {
+	start := i
+	i++ // opening quote
+
+	var sb strings.Builder
+
+	for i < len(input) {
+		c := input[i]
+
+		switch c {
+		case '"':
+			return sb.String(), i + 1, nil
+		case '\\':
+			if i+1 >= len(input) || (input[i+1] != '"' && input[i+1] != '\\') {
+				return "", 0, &LogQueryError{Pos: i, Msg: `only \" and \\ may be escaped`}
+			}
+			sb.WriteByte(input[i+1])
+			i += 2
+		default:
+			sb.WriteByte(c)
+			i++
+		}
+	}
+
+	return "", 0, &LogQueryError{Pos: start, Msg: "unterminated quoted string"}
+}
+
+type logQueryParser struct {
+	toks []*lqToken
+	i    int
+	end  int
+}
+
+func (p *logQueryParser) peek() *lqToken {
+	if p.i >= len(p.toks) {
+		return nil
+	}
+	return p.toks[p.i]
+}
+
+func (p *logQueryParser) next() *lqToken {
+	t := p.peek()
+	if t != nil {
+		p.i++
+	}
+	return t
+}
+
+// pos returns where the next token starts, or the end of the input.
+func (p *logQueryParser) pos() int {
+	if t := p.peek(); t != nil {
+		return t.pos
+	}
+	return p.end
+}
+
+func (p *logQueryParser) parseOr(depth int) (logQueryNode, error) {
+	left, err := p.parseAnd(depth)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		t := p.peek()
+		if t == nil || t.kind != lqOr {
+			return left, nil
+		}
+		p.next()
+
+		right, err := p.parseAnd(depth)
+		if err != nil {
+			return nil, err
+		}
+
+		left = &lqOrNode{left: left, right: right}
+	}
+}
+
+func (p *logQueryParser) parseAnd(depth int) (logQueryNode, error) {
+	left, err := p.parseUnary(depth)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		t := p.peek()
+		if t == nil || t.kind == lqOr || t.kind == lqRParen {
+			return left, nil
+		}
+
+		// Adjacent terms are implicitly ANDed.
+		if t.kind == lqAnd {
+			p.next()
+		}
+
+		right, err := p.parseUnary(depth)
+		if err != nil {
+			return nil, err
+		}
+
+		left = &lqAndNode{left: left, right: right}
+	}
+}
+
+func (p *logQueryParser) parseUnary(depth int) (logQueryNode, error) {
+	if depth > maxLogQueryDepth {
+		return nil, &LogQueryError{Pos: p.pos(), Msg: fmt.Sprintf("query nests deeper than %d levels", maxLogQueryDepth)}
+	}
+
+	t := p.next()
+	if t == nil {
+		return nil, &LogQueryError{Pos: p.end, Msg: "unexpected end of query"}
+	}
+
+	switch t.kind {
+	case lqNot:
+		node, err := p.parseUnary(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return &lqNotNode{node: node}, nil
+
+	case lqLParen:
+		node, err := p.parseOr(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+
+		if rp := p.next(); rp == nil || rp.kind != lqRParen {
+			return nil, &LogQueryError{Pos: t.pos, Msg: "unmatched ("}
+		}
+		return node, nil
+
+	case lqTerm:
+		return newLogQueryTerm(t)
+
+	default:
+		return nil, &LogQueryError{Pos: t.pos, Msg: "unexpected " + t.describe()}
+	}
+}
+
+func newLogQueryTerm(t *lqToken) (logQueryNode, error) {
+	n := &lqTermNode{name: t.field, value: t.value}
+
+	if !t.quoted && strings.HasSuffix(n.value, "*") {
+		n.value = strings.TrimSuffix(n.value, "*")
+		n.prefix = true
+	}
+
+	if n.value == "" && !t.quoted {
+		return nil, &LogQueryError{Pos: t.pos, Msg: "empty prefix match"}
+	}
+
+	for _, r := range n.value {
+		if unicode.IsControl(r) {
+			return nil, &LogQueryError{Pos: t.pos, Msg: "control characters are not allowed in values"}
+		}
+	}
+
+	if t.field == "" {
+		return n, nil
+	}
+
+	if name, ok := strings.CutPrefix(t.field, "attr."); ok {
+		if !logQueryAttrName.MatchString(name) {
+			return nil, &LogQueryError{Pos: t.pos, Msg: fmt.Sprintf("invalid attribute name %q", name)}
+		}
+		n.field = name
+		return n, nil
+	}
+
+	field, ok := logQueryFields[t.field]
+	if !ok {
+		return nil, &LogQueryError{Pos: t.pos, Msg: fmt.Sprintf("unknown field %q", t.field)}
+	}
+
+	n.field = field
+	return n, nil
+}
@@ -0,0 +1,160 @@
+package observability_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"miren.dev/runtime/observability"
+)
+
+func TestLogQuery(t *testing.T) {
+	parse := func(t *testing.T, input string) *observability.LogQuery {
+		t.Helper()
+
+		q, err := observability.ParseLogQuery(input)
+		require.NoError(t, err)
+		require.NotNil(t, q)
+		return q
+	}
+
+	t.Run("operator precedence", func(t *testing.T) {
+		tests := []struct {
+			input string
+			want  string
+		}{
+			{`a b`, `("a" AND "b")`},
+			{`a AND b OR c`, `(("a" AND "b") OR "c")`},
+			{`a OR b AND c`, `("a" OR ("b" AND "c"))`},
+			{`a OR b c`, `("a" OR ("b" AND "c"))`},
+			{`(a OR b) c`, `(("a" OR "b") AND "c")`},
+			{`NOT a b`, `(NOT "a" AND "b")`},
+			{`NOT (a b)`, `NOT ("a" AND "b")`},
+			{`-a OR b`, `(NOT "a" OR "b")`},
+			{`a AND b AND c`, `(("a" AND "b") AND "c")`},
+			{`NOT NOT a`, `NOT NOT "a"`},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.input, func(t *testing.T) {
+				require.Equal(t, tt.want, parse(t, tt.input).String())
+			})
+		}
+	})
+
+	t.Run("field predicates", func(t *testing.T) {
+		tests := []struct {
+			input string
+			want  string
+		}{
+			{`level:error`, `"level":="error"`},
+			{`stream:stderr`, `"stream":="stderr"`},
+			{`trace:abc123`, `"trace_id":="abc123"`},
+			{`sandbox:sb-1`, `"sandbox":="sb-1"`},
+			{`attr.region:us-east`, `"region":="us-east"`},
+			{`attr.http.method:"GET"`, `"http.method":="GET"`},
+			{`level:warn*`, `"level":="warn"*`},
+			{`msg:"connection reset"`, `_msg:"connection reset"`},
+			{`"timeout"`, `"timeout"`},
+			{`time*`, `"time"*`},
+			{`"time*"`, `"time*"`},
+			{`level:"AND"`, `"level":="AND"`},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.input, func(t *testing.T) {
+				require.Equal(t, tt.want, parse(t, tt.input).LogsQL())
+			})
+		}
+	})
+
+	t.Run("compiles a full query", func(t *testing.T) {
+		q := parse(t, `level:error AND attr.region:us-east AND "timeout"`)
+
+		require.Equal(t,
+			`(("level":="error" AND "region":="us-east") AND "timeout")`,
+			q.LogsQL())
+	})
+
+	t.Run("user values are always quoted", func(t *testing.T) {
+		tests := []struct {
+			input string
+			want  string
+		}{
+			// A pipe would start a LogsQL pipe stage if it weren't quoted.
+			{`level:x|delete`, `"level":="x|delete"`},
+			{`"a\" | stats count() \\"`, `"a\" | stats count() \\"`},
+			{`attr.user:"x\" OR _msg:*"`, `"user":="x\" OR _msg:*"`},
+			{`*foo`, `"*foo"`},
+			{`~"re.*"`, `("~" AND "re.*")`},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.input, func(t *testing.T) {
+				require.Equal(t, tt.want, parse(t, tt.input).LogsQL())
+			})
+		}
+	})
+
+	t.Run("empty input", func(t *testing.T) {
+		q, err := observability.ParseLogQuery("   ")
+		require.NoError(t, err)
+		require.Nil(t, q)
+		require.Equal(t, "", q.LogsQL())
+	})
+
+	t.Run("rejects malformed queries", func(t *testing.T) {
+		tests := []struct {
+			input string
+			msg   string
+		}{
+			{`color:red`, `unknown field "color"`},
+			{`attr._msg:x`, `invalid attribute name`},
+			{`attr.:x`, `invalid attribute name`},
+			{`level:`, `missing value`},
+			{`:error`, `missing field name`},
+			{`"unterminated`, `unterminated quoted string`},
+			{`"bad \n escape"`, `may be escaped`},
+			{`a AND`, `unexpected end of query`},
+			{`OR a`, `unexpected OR`},
+			{`a OR OR b`, `unexpected OR`},
+			{`(a b`, `unmatched (`},
+			{`a b)`, `unmatched )`},
+			{`()`, `unexpected )`},
+			{`level:a:b`, `unexpected :`},
+			{`level:*`, `empty prefix match`},
+			{"\"tab\x01\"", `control characters`},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.input, func(t *testing.T) {
+				_, err := observability.ParseLogQuery(tt.input)
+				require.ErrorContains(t, err, tt.msg)
+
+				var qe *observability.LogQueryError
+				require.ErrorAs(t, err, &qe)
+			})
+		}
+	})
+
+	t.Run("limits nesting and length", func(t *testing.T) {
+		deep := ""
+		for i := 0; i < 40; i++ {
+			deep += "("
+		}
+		deep += "a"
+		for i := 0; i < 40; i++ {
+			deep += ")"
+		}
+
+		_, err := observability.ParseLogQuery(deep)
+		require.ErrorContains(t, err, "nests deeper")
+
+		long := make([]byte, 3000)
+		for i := range long {
+			long[i] = 'a'
+		}
+
+		_, err = observability.ParseLogQuery(string(long))
+		require.ErrorContains(t, err, "longer than")
+	})
+}
@@ -10,7 +10,9 @@ import (
 	"log/slog"
 	"net/http"
 	"net/url"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"miren.dev/runtime/pkg/asm/autoreg"
@@ -31,23 +33,60 @@ const (
 	Stderr  LogStream = "stderr"
 	Error   LogStream = "error"
 	UserOOB LogStream = "user-oob"
+
+	// Structured is stdout of an app that writes JSON lines. The fields of
+	// its lines are stored alongside them, see promoteStructured.
+	Structured LogStream = "structured"
 )
 
 type LogEntry struct {
 	Timestamp  time.Time
+	Entity     string
 	Stream     LogStream
 	TraceID    string
 	Attributes map[string]string
 	Body       string
 }
 
+// PersistentLogWriter writes log entries to VictoriaLogs. Entries are
+// buffered and sent in batches, once enough have built up or every
+// FlushInterval, so Flush or Close must be called for the last of them to
+// be written.
 type PersistentLogWriter struct {
+	Log     *slog.Logger
 	Address string        `asm:"victorialogs-address"`
 	Timeout time.Duration `asm:"victorialogs-timeout"`
 
+	// BatchSize is how many entries are sent in one insert, and how many
+	// build up before they're sent without waiting for FlushInterval.
+	BatchSize     int           `asm:"victorialogs-batch-size,optional"`
+	FlushInterval time.Duration `asm:"victorialogs-flush-interval,optional"`
+
+	// MaxBuffered is how many entries can wait to be sent before
+	// WriteEntry starts rejecting them.
+	MaxBuffered int `asm:"victorialogs-max-buffered,optional"`
+
 	client *http.Client
+
+	startOnce sync.Once
+	cancel    context.CancelFunc
+	done      chan struct{}
+	flushChan chan struct{}
+
+	mu     sync.Mutex
+	buffer [][]byte
+	closed bool
+
+	// flushMu keeps batches in the order they were written
+	flushMu sync.Mutex
 }
 
+const (
+	defaultLogBatchSize     = 500
+	defaultLogFlushInterval = time.Second
+	defaultLogMaxBuffered   = 50000
+)
+
 var _ = autoreg.Register[PersistentLogWriter]()
 
 func (l *PersistentLogWriter) Populated() error {
@@ -69,7 +108,104 @@ func (l *PersistentLogWriter) Client() *http.Client {
 	return l.client
 }
 
+func (l *PersistentLogWriter) batchSize() int {
+	if l.BatchSize > 0 {
+		return l.BatchSize
+	}
+
+	return defaultLogBatchSize
+}
+
+func (l *PersistentLogWriter) maxBuffered() int {
+	if l.MaxBuffered > 0 {
+		return l.MaxBuffered
+	}
+
+	return defaultLogMaxBuffered
+}
+
+// start begins the background flush routine
+func (l *PersistentLogWriter) start() {
+	l.startOnce.Do(func() {
+		interval := l.FlushInterval
+		if interval <= 0 {
+			interval = defaultLogFlushInterval
+		}
+
+		var ctx context.Context
+		ctx, l.cancel = context.WithCancel(context.Background())
+
+		l.done = make(chan struct{})
+		l.flushChan = make(chan struct{}, 1)
+
+		go l.flushLoop(ctx, interval)
+	})
+}
+
+func (l *PersistentLogWriter) flushLoop(ctx context.Context, interval time.Duration) {
+	defer close(l.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		var err error
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err = l.flush(false)
+		case <-l.flushChan:
+			// Leave a partial batch to fill up until the next tick
+			err = l.flush(true)
+		}
+
+		if err != nil && l.Log != nil {
+			l.Log.Error("failed to send logs to victorialogs", "error", err)
+		}
+	}
+}
+
+// WriteEntry buffers an entry to be sent with the next batch. It fails if
+// too many entries are already waiting to be sent.
 func (l *PersistentLogWriter) WriteEntry(entity string, le LogEntry) error {
+	line, err := encodeLogLine(entity, le)
+	if err != nil {
+		return err
+	}
+
+	l.start()
+
+	l.mu.Lock()
+
+	if l.closed {
+		l.mu.Unlock()
+		return fmt.Errorf("log writer is closed")
+	}
+
+	if len(l.buffer) >= l.maxBuffered() {
+		l.mu.Unlock()
+		return fmt.Errorf("log buffer full (%d entries), rejecting write", len(l.buffer))
+	}
+
+	l.buffer = append(l.buffer, line)
+	needsFlush := len(l.buffer) >= l.batchSize()
+	l.mu.Unlock()
+
+	if needsFlush {
+		select {
+		case l.flushChan <- struct{}{}:
+		default:
+		}
+	}
+
+	return nil
+}
+
+// encodeLogLine formats an entry as a line for VictoriaLogs' jsonline
+// insert API.
+func encodeLogLine(entity string, le LogEntry) ([]byte, error) {
 	// VictoriaLogs requires a non-empty _msg field but we want to preserve
 	// empty log messages because they'll show up as blank lines in the output.
 	// So use a single space if empty
@@ -92,21 +228,78 @@ func (l *PersistentLogWriter) WriteEntry(entity string, le LogEntry) error {
 		logData[k] = v
 	}
 
+	if le.Stream == Structured {
+		promoteStructured(le.Body, logData)
+	}
+
 	// Marshal to JSON
 	jsonData, err := json.Marshal(logData)
 	if err != nil {
-		return fmt.Errorf("failed to marshal log entry: %w", err)
+		return nil, fmt.Errorf("failed to marshal log entry: %w", err)
 	}
 
 	// Add newline for JSON lines format
-	jsonData = append(jsonData, '\n')
+	return append(jsonData, '\n'), nil
+}
+
+// Flush sends every buffered entry, in batches of BatchSize. Entries that
+// couldn't be sent stay buffered to be retried with the next flush.
+func (l *PersistentLogWriter) Flush() error {
+	return l.flush(false)
+}
+
+func (l *PersistentLogWriter) flush(fullOnly bool) error {
+	l.flushMu.Lock()
+	defer l.flushMu.Unlock()
+
+	size := l.batchSize()
+
+	for {
+		l.mu.Lock()
+		n := min(len(l.buffer), size)
+		batch := l.buffer[:n:n]
+		l.mu.Unlock()
+
+		if n == 0 || (fullOnly && n < size) {
+			return nil
+		}
+
+		if err := l.sendLines(batch); err != nil {
+			return err
+		}
+
+		// Only flushes take from the front of the buffer, so it still
+		// starts with the batch.
+		l.mu.Lock()
+		l.buffer = l.buffer[n:]
+		l.mu.Unlock()
+	}
+}
+
+// Close stops the background flush routine and sends the remaining
+// entries. No entries can be written after.
+func (l *PersistentLogWriter) Close() error {
+	l.mu.Lock()
+	l.closed = true
+	l.mu.Unlock()
+
+	if l.cancel != nil {
+		l.cancel()
+		<-l.done
+	}
+
+	return l.Flush()
+}
+
+func (l *PersistentLogWriter) sendLines(lines [][]byte) error {
+	data := bytes.Join(lines, nil)
 
 	// Send to VictoriaLogs
 	baseURL := normalizeBaseURL(l.Address)
 	insertURL := baseURL + "/insert/jsonline"
-	resp, err := l.Client().Post(insertURL, "application/x-ndjson", bytes.NewReader(jsonData))
+	resp, err := l.Client().Post(insertURL, "application/x-ndjson", bytes.NewReader(data))
 	if err != nil {
-		return fmt.Errorf("failed to send log to victorialogs: %w", err)
+		return fmt.Errorf("failed to send logs to victorialogs: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -122,9 +315,14 @@ type PersistentLogReader struct {
 	Address string        `asm:"victorialogs-address"`
 	Timeout time.Duration `asm:"victorialogs-timeout"`
 
+	// PollInterval is how often Follow checks for new entries.
+	PollInterval time.Duration `asm:"victorialogs-poll-interval,optional"`
+
 	client *http.Client
 }
 
+const defaultLogPollInterval = time.Second
+
 func (l *PersistentLogReader) Populated() error {
 	if l.Timeout == 0 {
 		l.Timeout = 30 * time.Second
@@ -137,11 +335,129 @@ func (l *PersistentLogReader) Populated() error {
 }
 
 func (l *PersistentLogReader) Read(ctx context.Context, id string) ([]LogEntry, error) {
-	reader := &LogReader{
+	return l.reader().Read(ctx, id)
+}
+
+func (l *PersistentLogReader) reader() *LogReader {
+	return &LogReader{
 		Address: l.Address,
 		client:  l.client,
 	}
-	return reader.Read(ctx, id)
+}
+
+// LogFilter narrows the entries PersistentLogReader returns.
+type LogFilter struct {
+	// Streams limits entries to those written to any of them.
+	Streams []LogStream
+
+	// Attributes limits entries to those with every one of them, matched
+	// exactly.
+	Attributes map[string]string
+}
+
+// LogsQL returns the filter as a LogsQL expression, for LogTarget.Filter.
+func (f LogFilter) LogsQL() (string, error) {
+	var parts []string
+
+	if len(f.Streams) > 0 {
+		var streams []string
+		for _, s := range f.Streams {
+			streams = append(streams, logsQLQuote(string(s)))
+		}
+
+		parts = append(parts, "stream:in("+strings.Join(streams, ",")+")")
+	}
+
+	keys := make([]string, 0, len(f.Attributes))
+	for k := range f.Attributes {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if !logQueryAttrName.MatchString(k) {
+			return "", fmt.Errorf("invalid attribute name %q", k)
+		}
+
+		parts = append(parts, k+":="+logsQLQuote(f.Attributes[k]))
+	}
+
+	return strings.Join(parts, " "), nil
+}
+
+// Query returns every entry written for entity between from and to that
+// matches filter, oldest first. A zero from is 24 hours ago and a zero to
+// is now.
+func (l *PersistentLogReader) Query(ctx context.Context, entity string, from, to time.Time, filter LogFilter) ([]LogEntry, error) {
+	expr, err := filter.LogsQL()
+	if err != nil {
+		return nil, err
+	}
+
+	target := LogTarget{EntityID: entity, Filter: expr}
+
+	var (
+		entries []LogEntry
+		logCh   = make(chan LogEntry, tailBuffer)
+		errCh   = make(chan error, 1)
+	)
+
+	go func() {
+		defer close(logCh)
+		errCh <- l.reader().ReadStream(ctx, target, logCh, WithFromTime(from), WithToTime(to))
+	}()
+
+	for entry := range logCh {
+		entries = append(entries, entry)
+	}
+
+	if err := <-errCh; err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// Follow sends the entries written for entity since from that match filter
+// to logCh, then polls for new ones every PollInterval until ctx is done.
+// Each poll starts at the newest entry already sent, skipping those it
+// sent before, so entries aren't repeated or missed between polls.
+func (l *PersistentLogReader) Follow(ctx context.Context, entity string, from time.Time, filter LogFilter, logCh chan<- LogEntry) error {
+	expr, err := filter.LogsQL()
+	if err != nil {
+		return err
+	}
+
+	target := LogTarget{EntityID: entity, Filter: expr}
+
+	interval := l.PollInterval
+	if interval <= 0 {
+		interval = defaultLogPollInterval
+	}
+
+	if from.IsZero() {
+		from = time.Now()
+	}
+
+	edge := &tailEdge{at: from}
+
+	for {
+		start := edge.at
+
+		err := streamDeduped(ctx, edge, logCh, func(ctx context.Context, ch chan<- LogEntry) error {
+			return l.reader().executeStreamQuery(ctx, target.Query(), ch, WithFromTime(start), WithToTime(time.Now()))
+		})
+		if err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
 }
 
 type LogsMaintainer struct {
@@ -171,6 +487,10 @@ type LogReader struct {
 	Address string        `asm:"victorialogs-address"`
 	Timeout time.Duration `asm:"victorialogs-timeout"`
 
+	// Archive, when set, serves the part of a query that falls before the
+	// archive watermark.
+	Archive *LogArchive `asm:"log-archive,optional"`
+
 	client *http.Client
 }
 
@@ -189,6 +509,7 @@ func (l *LogReader) Populated() error {
 
 type logReadOpts struct {
 	From  time.Time
+	To    time.Time
 	Limit int
 }
 
@@ -200,6 +521,12 @@ func WithFromTime(t time.Time) LogReaderOption {
 	}
 }
 
+func WithToTime(t time.Time) LogReaderOption {
+	return func(o *logReadOpts) {
+		o.To = t
+	}
+}
+
 func WithLimit(l int) LogReaderOption {
 	return func(o *logReadOpts) {
 		o.Limit = l
@@ -226,9 +553,6 @@ func (l *LogReader) Read(ctx context.Context, id string, opts ...LogReaderOption
 		limit = DefaultLogReadLimit
 	}
 
-	// Build LogsQL query - use simple field matching
-	query := `entity:` + logsQLQuote(id)
-
 	// Victoria Logs often requires a time range
 	// If not provided, use last 24 hours
 	startTime := o.From
@@ -236,7 +560,7 @@ func (l *LogReader) Read(ctx context.Context, id string, opts ...LogReaderOption
 		startTime = time.Now().Add(-24 * time.Hour)
 	}
 
-	return l.executeQuery(ctx, query, limit, startTime, time.Now())
+	return l.readWithArchive(ctx, LogTarget{EntityID: id}, limit, startTime, time.Now())
 }
 
 func (l *LogReader) ReadBySandbox(ctx context.Context, sandboxID string, opts ...LogReaderOption) ([]LogEntry, error) {
@@ -251,22 +575,108 @@ func (l *LogReader) ReadBySandbox(ctx context.Context, sandboxID string, opts ..
 		limit = DefaultLogReadLimit
 	}
 
-	// Build LogsQL query filtering by sandbox attribute
-	query := `sandbox:` + logsQLQuote(sandboxID)
-
 	// Victoria Logs often requires a time range
 	startTime := o.From
 	if startTime.IsZero() {
 		startTime = time.Now().Add(-24 * time.Hour)
 	}
 
-	return l.executeQuery(ctx, query, limit, startTime, time.Now())
+	return l.readWithArchive(ctx, LogTarget{SandboxID: sandboxID}, limit, startTime, time.Now())
+}
+
+// GetRequestLogs returns the log lines for a single request, identified by
+// its trace ID, from the logs of tenant (the entity they were written for).
+// Lines from all streams and sandboxes are merged into one bundle, ordered by
+// timestamp.
+func (l *LogReader) GetRequestLogs(ctx context.Context, tenant, traceID string, opts ...LogReaderOption) ([]LogEntry, error) {
+	if traceID == "" {
+		return nil, fmt.Errorf("trace id is required")
+	}
+
+	var o logReadOpts
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	limit := o.Limit
+	if limit == 0 {
+		limit = DefaultLogReadLimit
+	}
+
+	startTime := o.From
+	if startTime.IsZero() {
+		startTime = time.Now().Add(-24 * time.Hour)
+	}
+
+	endTime := o.To
+	if endTime.IsZero() {
+		endTime = time.Now()
+	}
+
+	entries, err := l.readWithArchive(ctx, LogTarget{EntityID: tenant, TraceID: traceID}, limit, startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+
+	// The bundle is read for debugging a single request, so make its order
+	// explicit rather than relying on how each source happened to sort it.
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.Before(entries[j].Timestamp)
+	})
+
+	return entries, nil
+}
+
+// readWithArchive splits [start, end) at the archive watermark, reading the
+// older part from the archive and the remainder from VictoriaLogs.
+func (l *LogReader) readWithArchive(ctx context.Context, target LogTarget, limit int, start, end time.Time) ([]LogEntry, error) {
+	var entries []LogEntry
+
+	hotStart := start
+
+	if l.Archive != nil && target.Filter == "" {
+		cat, err := l.Archive.Catalog(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if start.Before(cat.ArchivedThrough) {
+			archiveEnd := end
+			if cat.ArchivedThrough.Before(archiveEnd) {
+				archiveEnd = cat.ArchivedThrough
+			}
+
+			entries, err = l.Archive.query(ctx, cat, target, start, archiveEnd)
+			if err != nil {
+				return nil, err
+			}
+
+			hotStart = cat.ArchivedThrough
+		}
+	}
+
+	if len(entries) >= limit {
+		return entries[:limit], nil
+	}
+
+	if !hotStart.Before(end) {
+		return entries, nil
+	}
+
+	hot, err := l.executeQuery(ctx, target.Query(), limit-len(entries), hotStart, end)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(entries, hot...), nil
 }
 
 // LogTarget specifies what logs to query - either by entity ID or sandbox ID.
 type LogTarget struct {
 	EntityID  string
 	SandboxID string
+	TraceID   string // Optional, limits the logs to a single request
 	Filter    string // Optional LogsQL filter expression (e.g., "error" or ~"regex")
 }
 
@@ -279,6 +689,10 @@ func (t LogTarget) Query() string {
 		base = `entity:` + logsQLQuote(t.EntityID)
 	}
 
+	if t.TraceID != "" {
+		base += ` trace_id:=` + logsQLQuote(t.TraceID)
+	}
+
 	if t.Filter != "" {
 		// Append filter to query - VictoriaLogs LogsQL syntax
 		// User can specify word filters, phrase filters ("phrase"), or regex (~"pattern")
@@ -319,8 +733,12 @@ func (l *LogReader) executeStreamQuery(ctx context.Context, query string, logCh
 	if startTime.IsZero() {
 		startTime = time.Now().Add(-24 * time.Hour)
 	}
+	endTime := o.To
+	if endTime.IsZero() {
+		endTime = time.Now()
+	}
 	params.Set("start", startTime.Format(time.RFC3339Nano))
-	params.Set("end", time.Now().Format(time.RFC3339Nano))
+	params.Set("end", endTime.Format(time.RFC3339Nano))
 
 	fullURL := fmt.Sprintf("%s?%s", queryURL, params.Encode())
 
@@ -345,6 +763,56 @@ func (l *LogReader) executeStreamQuery(ctx context.Context, query string, logCh
 	return l.parseLogStream(ctx, resp.Body, logCh)
 }
 
+// fieldValues returns the distinct values of field across the logs matching
+// query between start and end.
+func (l *LogReader) fieldValues(ctx context.Context, query, field string, start, end time.Time) ([]string, error) {
+	baseURL := normalizeBaseURL(l.Address)
+	valuesURL := baseURL + "/select/logsql/field_values"
+
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("field", field)
+	params.Set("start", start.Format(time.RFC3339Nano))
+	params.Set("end", end.Format(time.RFC3339Nano))
+
+	fullURL := fmt.Sprintf("%s?%s", valuesURL, params.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, "GET", fullURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query victorialogs: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("victorialogs returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		Values []struct {
+			Value string `json:"value"`
+		} `json:"values"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode field values: %w", err)
+	}
+
+	values := make([]string, 0, len(result.Values))
+	for _, v := range result.Values {
+		if v.Value != "" {
+			values = append(values, v.Value)
+		}
+	}
+
+	return values, nil
+}
+
 func (l *LogReader) executeTailQuery(ctx context.Context, query string, logCh chan<- LogEntry, opts ...LogReaderOption) error {
 	var o logReadOpts
 	for _, opt := range opts {
@@ -439,6 +907,10 @@ func (l *LogReader) parseLogLine(line []byte) (LogEntry, error) {
 		entry.TraceID = traceID
 	}
 
+	if entity, ok := logData["entity"].(string); ok {
+		entry.Entity = entity
+	}
+
 	// Add all other fields as attributes
 	for k, v := range logData {
 		if k == "_msg" || k == "_time" || k == "stream" || k == "trace_id" || k == "entity" {
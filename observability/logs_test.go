@@ -1,7 +1,14 @@
 package observability_test
 
 import (
+	"bufio"
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -48,6 +55,8 @@ func TestLogs(t *testing.T) {
 		})
 		r.NoError(err)
 
+		r.NoError(pw.Flush())
+
 		entries, err := pr.Read(ctx, id)
 		r.NoError(err)
 
@@ -55,4 +64,463 @@ func TestLogs(t *testing.T) {
 
 		r.Equal("this is a log line", entries[0].Body)
 	})
+
+	t.Run("can query logs back", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		r := require.New(t)
+
+		reg, cleanup := testutils.Registry(observability.TestInject)
+		defer cleanup()
+
+		var (
+			pw observability.PersistentLogWriter
+			pr observability.PersistentLogReader
+		)
+
+		r.NoError(reg.Populate(&pw))
+		r.NoError(reg.Populate(&pr))
+
+		id := identity.NewID()
+		start := time.Now().Add(-time.Minute)
+
+		for i, le := range []observability.LogEntry{
+			{Stream: observability.Stdout, Body: "out a", Attributes: map[string]string{"sandbox": "sb-1"}},
+			{Stream: observability.Stderr, Body: "err a", Attributes: map[string]string{"sandbox": "sb-1"}},
+			{Stream: observability.Stdout, Body: "out b", Attributes: map[string]string{"sandbox": "sb-2"}},
+		} {
+			le.Timestamp = start.Add(time.Duration(i) * time.Second)
+			r.NoError(pw.WriteEntry(id, le))
+		}
+
+		r.NoError(pw.Flush())
+
+		query := func(filter observability.LogFilter) []string {
+			entries, err := pr.Query(ctx, id, start, time.Now(), filter)
+			r.NoError(err)
+
+			var bodies []string
+			for _, e := range entries {
+				bodies = append(bodies, e.Body)
+			}
+
+			return bodies
+		}
+
+		r.Equal([]string{"out a", "err a", "out b"}, query(observability.LogFilter{}))
+		r.Equal([]string{"out a", "out b"}, query(observability.LogFilter{Streams: []observability.LogStream{observability.Stdout}}))
+		r.Equal([]string{"out a", "err a"}, query(observability.LogFilter{Attributes: map[string]string{"sandbox": "sb-1"}}))
+	})
+}
+
+// fakeLogQueries serves lines from the query endpoint whose time falls in
+// the query's start and end, as VictoriaLogs does, recording each query.
+type fakeLogQueries struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	lines   []map[string]string
+	queries []string
+}
+
+func newFakeLogQueries(t *testing.T) *fakeLogQueries {
+	f := &fakeLogQueries{}
+
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		q := req.URL.Query()
+
+		start, _ := time.Parse(time.RFC3339Nano, q.Get("start"))
+		end, _ := time.Parse(time.RFC3339Nano, q.Get("end"))
+
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		f.queries = append(f.queries, q.Get("query"))
+
+		enc := json.NewEncoder(w)
+		for _, l := range f.lines {
+			at, _ := time.Parse(time.RFC3339Nano, l["_time"])
+			if !at.Before(start) && !at.After(end) {
+				enc.Encode(l)
+			}
+		}
+	}))
+	t.Cleanup(f.Close)
+
+	return f
+}
+
+func (f *fakeLogQueries) add(at time.Time, msg string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.lines = append(f.lines, map[string]string{
+		"_time":  at.Format(time.RFC3339Nano),
+		"_msg":   msg,
+		"entity": "app-1",
+		"stream": "stdout",
+	})
+}
+
+func TestPersistentLogReader(t *testing.T) {
+	t.Run("compiles filters to LogsQL", func(t *testing.T) {
+		r := require.New(t)
+
+		expr, err := observability.LogFilter{
+			Streams:    []observability.LogStream{observability.Stdout, observability.Stderr},
+			Attributes: map[string]string{"version": "v2", "sandbox": `sb "1"`},
+		}.LogsQL()
+		r.NoError(err)
+		r.Equal(`stream:in("stdout","stderr") sandbox:="sb \"1\"" version:="v2"`, expr)
+
+		expr, err = observability.LogFilter{}.LogsQL()
+		r.NoError(err)
+		r.Empty(expr)
+
+		_, err = observability.LogFilter{Attributes: map[string]string{"a b": "x"}}.LogsQL()
+		r.ErrorContains(err, "invalid attribute name")
+	})
+
+	t.Run("queries an entity's logs with a filter", func(t *testing.T) {
+		r := require.New(t)
+
+		vl := newFakeLogQueries(t)
+
+		base := time.Now().Add(-time.Hour).Truncate(time.Millisecond)
+		vl.add(base.Add(time.Second), "one")
+		vl.add(base.Add(2*time.Second), "two")
+		vl.add(base.Add(3*time.Second), "three")
+
+		pr := &observability.PersistentLogReader{Address: vl.URL}
+		r.NoError(pr.Populated())
+
+		entries, err := pr.Query(context.Background(), "app-1", base.Add(2*time.Second), base.Add(time.Minute), observability.LogFilter{
+			Streams: []observability.LogStream{observability.Stdout},
+		})
+		r.NoError(err)
+
+		r.Len(entries, 2)
+		r.Equal("two", entries[0].Body)
+		r.Equal("three", entries[1].Body)
+
+		r.Equal([]string{`entity:"app-1" stream:in("stdout") | sort by (_time) asc`}, vl.queries)
+	})
+
+	t.Run("follows new entries without repeating any", func(t *testing.T) {
+		r := require.New(t)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		vl := newFakeLogQueries(t)
+
+		base := time.Now().Add(-time.Minute).Truncate(time.Millisecond)
+		vl.add(base.Add(time.Second), "one")
+		vl.add(base.Add(2*time.Second), "two")
+
+		pr := &observability.PersistentLogReader{Address: vl.URL, PollInterval: 10 * time.Millisecond}
+		r.NoError(pr.Populated())
+
+		logCh := make(chan observability.LogEntry, 10)
+		done := make(chan error, 1)
+
+		go func() {
+			done <- pr.Follow(ctx, "app-1", base, observability.LogFilter{}, logCh)
+		}()
+
+		next := func() string {
+			select {
+			case e := <-logCh:
+				return e.Body
+			case <-time.After(5 * time.Second):
+				r.FailNow("timed out waiting for an entry")
+				return ""
+			}
+		}
+
+		r.Equal("one", next())
+		r.Equal("two", next())
+
+		// Written at the same instant as the last one sent
+		vl.add(base.Add(2*time.Second), "two again")
+		vl.add(time.Now(), "three")
+
+		r.Equal("two again", next())
+		r.Equal("three", next())
+
+		select {
+		case e := <-logCh:
+			r.FailNow("unexpected entry", e.Body)
+		case <-time.After(100 * time.Millisecond):
+		}
+
+		cancel()
+		r.ErrorIs(<-done, context.Canceled)
+	})
+}
+
+func TestGetRequestLogs(t *testing.T) {
+	t.Run("merges a request's logs across streams by timestamp", func(t *testing.T) {
+		ctx := context.Background()
+		r := require.New(t)
+
+		base := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+
+		line := func(offset time.Duration, stream, sandbox, trace, msg string) map[string]string {
+			return map[string]string{
+				"_time":    base.Add(offset).Format(time.RFC3339Nano),
+				"_msg":     msg,
+				"entity":   "app-1",
+				"stream":   stream,
+				"sandbox":  sandbox,
+				"trace_id": trace,
+			}
+		}
+
+		// Grouped by stream rather than time, as separate streams are read.
+		lines := []map[string]string{
+			line(1*time.Millisecond, "stdout", "sb-1", "abc", "request started"),
+			line(3*time.Millisecond, "stdout", "sb-1", "abc", "calling backend"),
+			line(2*time.Millisecond, "stdout", "sb-1", "other", "unrelated request"),
+			line(6*time.Millisecond, "stdout", "sb-1", "abc", "request finished"),
+			line(2*time.Millisecond, "stderr", "sb-1", "abc", "slow auth lookup"),
+			line(5*time.Millisecond, "stderr", "sb-2", "abc", "backend retry"),
+			line(4*time.Millisecond, "stdout", "sb-2", "abc", "backend handling"),
+		}
+
+		var queries []string
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			query := strings.TrimSuffix(req.URL.Query().Get("query"), " | sort by (_time) asc")
+			queries = append(queries, query)
+
+			enc := json.NewEncoder(w)
+			for _, l := range lines {
+				if query == `entity:"`+l["entity"]+`" trace_id:="`+l["trace_id"]+`"` {
+					require.NoError(t, enc.Encode(l))
+				}
+			}
+		}))
+		defer srv.Close()
+
+		reader := &observability.LogReader{Address: srv.URL}
+		r.NoError(reader.Populated())
+
+		entries, err := reader.GetRequestLogs(ctx, "app-1", "abc")
+		r.NoError(err)
+
+		r.Equal([]string{`entity:"app-1" trace_id:="abc"`}, queries)
+
+		var got []string
+		for _, e := range entries {
+			r.Equal("abc", e.TraceID)
+			got = append(got, string(e.Stream)+"/"+e.Attributes["sandbox"]+": "+e.Body)
+		}
+
+		r.Equal([]string{
+			"stdout/sb-1: request started",
+			"stderr/sb-1: slow auth lookup",
+			"stdout/sb-1: calling backend",
+			"stdout/sb-2: backend handling",
+			"stderr/sb-2: backend retry",
+			"stdout/sb-1: request finished",
+		}, got)
+	})
+
+	t.Run("requires a trace id", func(t *testing.T) {
+		reader := &observability.LogReader{Address: "http://127.0.0.1:0"}
+		require.NoError(t, reader.Populated())
+
+		_, err := reader.GetRequestLogs(context.Background(), "app-1", "")
+		require.Error(t, err)
+	})
+}
+
+// fakeVictoriaLogs counts the inserts and lines it's sent, failing inserts
+// while fail is set.
+type fakeVictoriaLogs struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	inserts int
+	lines   []string
+	fail    bool
+}
+
+func newFakeVictoriaLogs(t testing.TB) *fakeVictoriaLogs {
+	f := &fakeVictoriaLogs{}
+
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		if f.fail {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		f.inserts++
+
+		sc := bufio.NewScanner(req.Body)
+		for sc.Scan() {
+			var line map[string]string
+			if err := json.Unmarshal(sc.Bytes(), &line); err == nil {
+				f.lines = append(f.lines, line["_msg"])
+			}
+		}
+	}))
+	t.Cleanup(f.Close)
+
+	return f
+}
+
+func (f *fakeVictoriaLogs) stats() (int, []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.inserts, append([]string(nil), f.lines...)
+}
+
+func TestPersistentLogWriter(t *testing.T) {
+	write := func(t *testing.T, pw *observability.PersistentLogWriter, n int) []string {
+		var bodies []string
+
+		for i := range n {
+			body := fmt.Sprintf("line %d", i)
+			bodies = append(bodies, body)
+
+			require.NoError(t, pw.WriteEntry("app-1", observability.LogEntry{
+				Timestamp: time.Now(),
+				Stream:    observability.Stdout,
+				Body:      body,
+			}))
+		}
+
+		return bodies
+	}
+
+	t.Run("sends entries in batches", func(t *testing.T) {
+		r := require.New(t)
+
+		vl := newFakeVictoriaLogs(t)
+
+		pw := &observability.PersistentLogWriter{
+			Address:       vl.URL,
+			BatchSize:     10,
+			FlushInterval: time.Hour,
+		}
+		r.NoError(pw.Populated())
+
+		bodies := write(t, pw, 25)
+
+		// Full batches go without waiting for the interval
+		r.Eventually(func() bool {
+			inserts, _ := vl.stats()
+			return inserts == 2
+		}, 5*time.Second, 10*time.Millisecond)
+
+		// The partial batch waits for the interval
+		_, sent := vl.stats()
+		r.Equal(bodies[:20], sent)
+
+		r.NoError(pw.Close())
+
+		inserts, lines := vl.stats()
+		r.Equal(3, inserts)
+		r.Equal(bodies, lines)
+
+		r.Error(pw.WriteEntry("app-1", observability.LogEntry{Body: "late"}))
+	})
+
+	t.Run("flushes on the interval", func(t *testing.T) {
+		r := require.New(t)
+
+		vl := newFakeVictoriaLogs(t)
+
+		pw := &observability.PersistentLogWriter{
+			Address:       vl.URL,
+			FlushInterval: 20 * time.Millisecond,
+		}
+		r.NoError(pw.Populated())
+		defer pw.Close()
+
+		bodies := write(t, pw, 3)
+
+		r.Eventually(func() bool {
+			_, lines := vl.stats()
+			return len(lines) == len(bodies)
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("keeps entries that fail to send", func(t *testing.T) {
+		r := require.New(t)
+
+		vl := newFakeVictoriaLogs(t)
+
+		vl.mu.Lock()
+		vl.fail = true
+		vl.mu.Unlock()
+
+		pw := &observability.PersistentLogWriter{
+			Address:       vl.URL,
+			FlushInterval: time.Hour,
+			MaxBuffered:   5,
+		}
+		r.NoError(pw.Populated())
+
+		bodies := write(t, pw, 5)
+		r.Error(pw.Flush())
+
+		// The buffer is bounded while the entries can't be sent
+		r.Error(pw.WriteEntry("app-1", observability.LogEntry{Body: "too many"}))
+
+		vl.mu.Lock()
+		vl.fail = false
+		vl.mu.Unlock()
+
+		r.NoError(pw.Close())
+
+		_, lines := vl.stats()
+		r.Equal(bodies, lines)
+	})
+}
+
+func BenchmarkPersistentLogWriter(b *testing.B) {
+	le := observability.LogEntry{
+		Timestamp: time.Now(),
+		Stream:    observability.Stdout,
+		Body:      "GET /api/items 200 12ms",
+	}
+
+	b.Run("per-line", func(b *testing.B) {
+		vl := newFakeVictoriaLogs(b)
+
+		pw := &observability.PersistentLogWriter{Address: vl.URL, FlushInterval: time.Hour}
+		require.NoError(b, pw.Populated())
+
+		for b.Loop() {
+			require.NoError(b, pw.WriteEntry("app-1", le))
+			require.NoError(b, pw.Flush())
+		}
+
+		require.NoError(b, pw.Close())
+	})
+
+	b.Run("batched", func(b *testing.B) {
+		vl := newFakeVictoriaLogs(b)
+
+		pw := &observability.PersistentLogWriter{Address: vl.URL, FlushInterval: time.Hour}
+		require.NoError(b, pw.Populated())
+
+		for b.Loop() {
+			if err := pw.WriteEntry("app-1", le); err != nil {
+				// The buffer is full, so drain it and try again
+				require.NoError(b, pw.Flush())
+				require.NoError(b, pw.WriteEntry("app-1", le))
+			}
+		}
+
+		require.NoError(b, pw.Close())
+	})
 }
@@ -0,0 +1,213 @@
+package observability
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const (
+	// tailBuffer is how many entries TailLogs holds for a slow reader before
+	// it stops reading from VictoriaLogs.
+	tailBuffer = 100
+
+	tailMinBackoff = 500 * time.Millisecond
+	tailMaxBackoff = 30 * time.Second
+
+	// tailMaxFailures is how many times in a row the tail can fail before
+	// TailLogs gives up.
+	tailMaxFailures = 8
+)
+
+// TailLogs follows the logs of tenant (the entity they were written for),
+// starting with those written since from and then carrying on with new ones
+// as they're written, until ctx is done. If entity is set, only the logs of
+// that sandbox are followed.
+//
+// The history is read with a single streaming query and the live logs with
+// the tail endpoint, both at the pace the returned channel is drained: once
+// it's full, nothing more is read from VictoriaLogs until there's room. The
+// handoff between the two is made at the last entry read, so entries at the
+// boundary are delivered exactly once. A dropped tail is resumed the same
+// way, backing off so an unavailable VictoriaLogs isn't hammered.
+//
+// The entries channel is closed when the follow ends. The error channel then
+// holds the error that ended it, if it wasn't ctx.
+func (l *LogReader) TailLogs(ctx context.Context, tenant, entity string, from time.Time) (<-chan LogEntry, <-chan error) {
+	logCh := make(chan LogEntry, tailBuffer)
+	errCh := make(chan error, 1)
+
+	target := LogTarget{EntityID: tenant, SandboxID: entity}
+
+	go func() {
+		defer close(logCh)
+
+		err := l.tailLogs(ctx, target, from, logCh)
+		if err != nil && !errors.Is(err, context.Canceled) && ctx.Err() == nil {
+			errCh <- err
+		}
+	}()
+
+	return logCh, errCh
+}
+
+func (l *LogReader) tailLogs(ctx context.Context, target LogTarget, from time.Time, logCh chan<- LogEntry) error {
+	handoff := time.Now()
+
+	edge := &tailEdge{at: handoff}
+
+	if !from.IsZero() && from.Before(handoff) {
+		err := streamDeduped(ctx, edge, logCh, func(ctx context.Context, ch chan<- LogEntry) error {
+			return l.executeStreamQuery(ctx, target.Query(), ch, WithFromTime(from), WithToTime(handoff))
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	var (
+		backoff  = tailMinBackoff
+		failures int
+	)
+
+	for {
+		start := time.Now()
+		delivered := edge.delivered
+
+		err := streamDeduped(ctx, edge, logCh, func(ctx context.Context, ch chan<- LogEntry) error {
+			return l.executeTailQuery(ctx, target.Query(), ch, WithFromTime(edge.at))
+		})
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		// A tail that delivered something or ran for a while was a working
+		// connection that dropped, so resume it promptly.
+		if edge.delivered > delivered || time.Since(start) > tailMaxBackoff {
+			backoff = tailMinBackoff
+			failures = 0
+		} else if err != nil {
+			failures++
+			if failures >= tailMaxFailures {
+				return err
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff = min(backoff*2, tailMaxBackoff)
+	}
+}
+
+// streamDeduped runs read, forwarding the entries it produces to logCh
+// unless an earlier read already delivered them.
+func streamDeduped(
+	ctx context.Context,
+	edge *tailEdge,
+	logCh chan<- LogEntry,
+	read func(ctx context.Context, ch chan<- LogEntry) error,
+) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	ch := make(chan LogEntry)
+	errCh := make(chan error, 1)
+
+	go func() {
+		defer close(ch)
+		errCh <- read(ctx, ch)
+	}()
+
+	edge.begin()
+
+	for entry := range ch {
+		if !edge.admit(entry) {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			cancel()
+			for range ch {
+			}
+			return ctx.Err()
+		case logCh <- entry:
+		}
+	}
+
+	return <-errCh
+}
+
+// tailEdge tracks the newest entries delivered, so that a read overlapping
+// the one before it doesn't deliver an entry twice.
+type tailEdge struct {
+	// at is where the next read starts.
+	at time.Time
+
+	delivered int
+
+	// last and seen are the newest entries delivered so far, and boundary
+	// and before are what they were when the current read began.
+	last     time.Time
+	seen     map[tailKey]struct{}
+	boundary time.Time
+	before   map[tailKey]struct{}
+}
+
+type tailKey struct {
+	stream  LogStream
+	sandbox string
+	body    string
+}
+
+func (e *tailEdge) begin() {
+	if e.seen == nil {
+		e.seen = make(map[tailKey]struct{})
+	}
+
+	e.boundary = e.last
+	e.before = e.seen
+}
+
+// admit reports whether entry is new, recording it if so. Only entries up
+// to the boundary of the current read were covered by an earlier one, so
+// anything after it is delivered, even if it arrives out of order.
+func (e *tailEdge) admit(entry LogEntry) bool {
+	ts := entry.Timestamp
+	key := tailKey{stream: entry.Stream, sandbox: entry.Attributes["sandbox"], body: entry.Body}
+
+	if !e.boundary.IsZero() {
+		if ts.Before(e.boundary) {
+			return false
+		}
+
+		if ts.Equal(e.boundary) {
+			if _, ok := e.before[key]; ok {
+				return false
+			}
+		}
+	}
+
+	switch {
+	case ts.After(e.last):
+		e.last = ts
+		e.seen = map[tailKey]struct{}{key: {}}
+		e.at = ts
+	case ts.Equal(e.last):
+		e.seen[key] = struct{}{}
+	}
+
+	// An entry at the boundary that the earlier read missed also has to be
+	// remembered for the next one.
+	if !e.boundary.IsZero() && ts.Equal(e.boundary) {
+		e.before[key] = struct{}{}
+	}
+
+	e.delivered++
+
+	return true
+}
@@ -0,0 +1,208 @@
+package observability_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"miren.dev/runtime/observability"
+)
+
+type fakeLogLine struct {
+	at   time.Time
+	body string
+}
+
+// fakeLogTail serves history from the query endpoint and then each of tails
+// in turn from the tail endpoint. The last tail is held open until the
+// client goes away, the others end once they've been written.
+type fakeLogTail struct {
+	*httptest.Server
+
+	history []fakeLogLine
+	tails   [][]fakeLogLine
+
+	mu          sync.Mutex
+	queries     int
+	tailQueries int
+}
+
+func newFakeLogTail(t *testing.T, history []fakeLogLine, tails ...[]fakeLogLine) *fakeLogTail {
+	f := &fakeLogTail{history: history, tails: tails}
+
+	write := func(w http.ResponseWriter, lines []fakeLogLine) {
+		for _, l := range lines {
+			data, _ := json.Marshal(map[string]string{
+				"_time":  l.at.Format(time.RFC3339Nano),
+				"_msg":   l.body,
+				"stream": "stdout",
+				"entity": "app-1",
+			})
+			fmt.Fprintf(w, "%s\n", data)
+		}
+
+		w.(http.Flusher).Flush()
+	}
+
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/select/logsql/query":
+			f.mu.Lock()
+			f.queries++
+			f.mu.Unlock()
+
+			write(w, f.history)
+		case "/select/logsql/tail":
+			f.mu.Lock()
+			i := f.tailQueries
+			f.tailQueries++
+			f.mu.Unlock()
+
+			if i >= len(f.tails) {
+				http.Error(w, "no more tails", http.StatusServiceUnavailable)
+				return
+			}
+
+			write(w, f.tails[i])
+
+			if i == len(f.tails)-1 {
+				<-req.Context().Done()
+			}
+		default:
+			http.NotFound(w, req)
+		}
+	}))
+	t.Cleanup(f.Close)
+
+	return f
+}
+
+func TestTailLogs(t *testing.T) {
+	base := time.Now().Add(-time.Minute).Truncate(time.Millisecond)
+
+	at := func(sec int) time.Time {
+		return base.Add(time.Duration(sec) * time.Second)
+	}
+
+	reader := func(t *testing.T, address string) *observability.LogReader {
+		lr := &observability.LogReader{Address: address}
+		require.NoError(t, lr.Populated())
+		return lr
+	}
+
+	collect := func(t *testing.T, logCh <-chan observability.LogEntry, n int) []string {
+		var bodies []string
+
+		for len(bodies) < n {
+			select {
+			case entry, ok := <-logCh:
+				if !ok {
+					return bodies
+				}
+				bodies = append(bodies, entry.Body)
+			case <-time.After(5 * time.Second):
+				require.FailNow(t, "timed out waiting for logs", "got %v", bodies)
+			}
+		}
+
+		// Nothing else should follow
+		select {
+		case entry := <-logCh:
+			require.FailNow(t, "unexpected log", "%s", entry.Body)
+		case <-time.After(100 * time.Millisecond):
+		}
+
+		return bodies
+	}
+
+	t.Run("hands off from history to live without a gap or duplicate", func(t *testing.T) {
+		r := require.New(t)
+
+		vl := newFakeLogTail(t,
+			[]fakeLogLine{
+				{at(1), "h1"},
+				{at(2), "h2"},
+				{at(3), "h3a"},
+			},
+			// The tail overlaps the history, including a line written at
+			// the same instant as the last one that the query missed.
+			[]fakeLogLine{
+				{at(2), "h2"},
+				{at(3), "h3a"},
+				{at(3), "h3b"},
+				{at(4), "l4"},
+				{at(5), "l5"},
+			},
+		)
+
+		logCh, errCh := reader(t, vl.URL).TailLogs(t.Context(), "app-1", "", at(0))
+
+		r.Equal([]string{"h1", "h2", "h3a", "h3b", "l4", "l5"}, collect(t, logCh, 6))
+		r.Empty(errCh)
+	})
+
+	t.Run("resumes a dropped tail where it left off", func(t *testing.T) {
+		r := require.New(t)
+
+		vl := newFakeLogTail(t,
+			[]fakeLogLine{{at(1), "h1"}},
+			[]fakeLogLine{{at(2), "l2"}, {at(3), "l3"}},
+			[]fakeLogLine{{at(3), "l3"}, {at(4), "l4"}},
+		)
+
+		logCh, _ := reader(t, vl.URL).TailLogs(t.Context(), "app-1", "", at(0))
+
+		r.Equal([]string{"h1", "l2", "l3", "l4"}, collect(t, logCh, 4))
+
+		vl.mu.Lock()
+		defer vl.mu.Unlock()
+
+		r.Equal(1, vl.queries)
+		r.Equal(2, vl.tailQueries)
+	})
+
+	t.Run("delivers a burst in order", func(t *testing.T) {
+		r := require.New(t)
+
+		var (
+			burst []fakeLogLine
+			want  = []string{"h1"}
+		)
+
+		for i := range 1000 {
+			body := fmt.Sprintf("b%d", i)
+			burst = append(burst, fakeLogLine{at(2).Add(time.Duration(i) * time.Millisecond), body})
+			want = append(want, body)
+		}
+
+		vl := newFakeLogTail(t, []fakeLogLine{{at(1), "h1"}}, burst)
+
+		logCh, _ := reader(t, vl.URL).TailLogs(t.Context(), "app-1", "", at(0))
+
+		// Fall behind the burst before reading it
+		time.Sleep(100 * time.Millisecond)
+		r.Equal(cap(logCh), len(logCh))
+
+		r.Equal(want, collect(t, logCh, len(want)))
+	})
+
+	t.Run("gives up when the logs are unavailable", func(t *testing.T) {
+		r := require.New(t)
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		}))
+		defer srv.Close()
+
+		logCh, errCh := reader(t, srv.URL).TailLogs(t.Context(), "app-1", "", at(0))
+
+		_, ok := <-logCh
+		r.False(ok)
+		r.ErrorContains(<-errCh, "status 503")
+	})
+}
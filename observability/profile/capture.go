@@ -0,0 +1,168 @@
+package profile
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/pprof"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Kind is the kind of profile to capture.
+type Kind string
+
+const (
+	CPU       Kind = "cpu"
+	Heap      Kind = "heap"
+	Allocs    Kind = "allocs"
+	Goroutine Kind = "goroutine"
+	Block     Kind = "block"
+	Mutex     Kind = "mutex"
+)
+
+const (
+	defaultCaptureDuration = 10 * time.Second
+	maxCaptureDuration     = 2 * time.Minute
+)
+
+var (
+	// ErrCaptureDisabled is returned when profiles are requested from a
+	// Capture that isn't enabled.
+	ErrCaptureDisabled = errors.New("profile capture is disabled")
+
+	// ErrCaptureBusy is returned when a CPU profile is requested while
+	// another is being captured, as the runtime only runs one at a time.
+	ErrCaptureBusy = errors.New("a CPU profile is already being captured")
+)
+
+// Capture takes pprof profiles of the running process on request, so the
+// control plane can pull a CPU or heap profile from a sandbox. It's disabled
+// unless Enabled is set, and costs nothing until a profile is asked for.
+type Capture struct {
+	Enabled bool `asm:"profile-capture,optional"`
+
+	// MaxDuration caps how long a CPU profile may run, 2 minutes by default.
+	MaxDuration time.Duration `asm:"profile-capture-max-duration,optional"`
+
+	cpu sync.Mutex
+}
+
+// CaptureProfile returns the pprof encoding of a profile of kind. CPU
+// profiles sample for duration, or until ctx is done; the others are a
+// snapshot and ignore it.
+func (c *Capture) CaptureProfile(ctx context.Context, kind Kind, duration time.Duration) ([]byte, error) {
+	if !c.Enabled {
+		return nil, ErrCaptureDisabled
+	}
+
+	if kind == CPU {
+		return c.captureCPU(ctx, duration)
+	}
+
+	p := pprof.Lookup(string(kind))
+	if p == nil {
+		return nil, fmt.Errorf("unknown profile kind %q", kind)
+	}
+
+	var buf bytes.Buffer
+
+	if err := p.WriteTo(&buf, 0); err != nil {
+		return nil, fmt.Errorf("failed to write %s profile: %w", kind, err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (c *Capture) captureCPU(ctx context.Context, duration time.Duration) ([]byte, error) {
+	if duration <= 0 {
+		duration = defaultCaptureDuration
+	}
+
+	limit := c.MaxDuration
+	if limit <= 0 {
+		limit = maxCaptureDuration
+	}
+
+	duration = min(duration, limit)
+
+	if !c.cpu.TryLock() {
+		return nil, ErrCaptureBusy
+	}
+	defer c.cpu.Unlock()
+
+	var buf bytes.Buffer
+
+	// Something outside of Capture may be running a CPU profile too.
+	if err := pprof.StartCPUProfile(&buf); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCaptureBusy, err)
+	}
+
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	pprof.StopCPUProfile()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Register adds the profile handlers to mux under /debug/pprof, if the
+// Capture is enabled:
+//
+//	GET /debug/pprof/{kind}?seconds=N  a profile of kind, as pprof bytes
+//
+// seconds sets how long a CPU profile samples for.
+func (c *Capture) Register(mux *http.ServeMux) {
+	if !c.Enabled {
+		return
+	}
+
+	mux.HandleFunc("GET /debug/pprof/{kind}", func(w http.ResponseWriter, r *http.Request) {
+		kind := Kind(r.PathValue("kind"))
+		if kind != CPU && pprof.Lookup(string(kind)) == nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		var duration time.Duration
+
+		if s := r.URL.Query().Get("seconds"); s != "" {
+			secs, err := strconv.Atoi(s)
+			if err != nil || secs <= 0 {
+				http.Error(w, "invalid seconds", http.StatusBadRequest)
+				return
+			}
+
+			duration = time.Duration(secs) * time.Second
+		}
+
+		data, err := c.CaptureProfile(r.Context(), kind, duration)
+		switch {
+		case err == nil:
+		case errors.Is(err, ErrCaptureBusy):
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		case r.Context().Err() != nil:
+			return
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", string(kind)+".pb.gz"))
+		w.Write(data)
+	})
+}
@@ -0,0 +1,127 @@
+package profile
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"runtime/pprof"
+	"testing"
+	"time"
+
+	gprofile "github.com/google/pprof/profile"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapture(t *testing.T) {
+	t.Run("is disabled by default", func(t *testing.T) {
+		r := require.New(t)
+
+		var c Capture
+
+		_, err := c.CaptureProfile(context.Background(), Heap, 0)
+		r.ErrorIs(err, ErrCaptureDisabled)
+
+		mux := http.NewServeMux()
+		c.Register(mux)
+
+		srv := httptest.NewServer(mux)
+		defer srv.Close()
+
+		resp, err := http.Get(srv.URL + "/debug/pprof/heap")
+		r.NoError(err)
+		resp.Body.Close()
+
+		r.Equal(http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("captures heap and CPU profiles", func(t *testing.T) {
+		r := require.New(t)
+
+		c := Capture{Enabled: true}
+
+		data, err := c.CaptureProfile(context.Background(), Heap, 0)
+		r.NoError(err)
+
+		prof, err := gprofile.ParseData(data)
+		r.NoError(err)
+		r.NotEmpty(prof.SampleType)
+
+		data, err = c.CaptureProfile(context.Background(), CPU, 100*time.Millisecond)
+		r.NoError(err)
+
+		prof, err = gprofile.ParseData(data)
+		r.NoError(err)
+		r.Equal("cpu", prof.SampleType[len(prof.SampleType)-1].Type)
+
+		_, err = c.CaptureProfile(context.Background(), Kind("nope"), 0)
+		r.Error(err)
+	})
+
+	t.Run("runs one CPU profile at a time", func(t *testing.T) {
+		r := require.New(t)
+
+		c := Capture{Enabled: true}
+
+		c.cpu.Lock()
+		_, err := c.CaptureProfile(context.Background(), CPU, time.Millisecond)
+		c.cpu.Unlock()
+		r.ErrorIs(err, ErrCaptureBusy)
+
+		// Nor while something else in the process is profiling
+		r.NoError(pprof.StartCPUProfile(io.Discard))
+		_, err = c.CaptureProfile(context.Background(), CPU, time.Millisecond)
+		pprof.StopCPUProfile()
+		r.ErrorIs(err, ErrCaptureBusy)
+	})
+
+	t.Run("stops a CPU profile when the context is done", func(t *testing.T) {
+		r := require.New(t)
+
+		c := Capture{Enabled: true}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+
+		_, err := c.CaptureProfile(ctx, CPU, time.Minute)
+		r.ErrorIs(err, context.DeadlineExceeded)
+		r.Less(time.Since(start), 10*time.Second)
+	})
+
+	t.Run("serves profiles over HTTP", func(t *testing.T) {
+		r := require.New(t)
+
+		c := Capture{Enabled: true}
+
+		mux := http.NewServeMux()
+		c.Register(mux)
+
+		srv := httptest.NewServer(mux)
+		defer srv.Close()
+
+		resp, err := http.Get(srv.URL + "/debug/pprof/goroutine")
+		r.NoError(err)
+		defer resp.Body.Close()
+
+		r.Equal(http.StatusOK, resp.StatusCode)
+
+		data, err := io.ReadAll(resp.Body)
+		r.NoError(err)
+
+		_, err = gprofile.ParseData(data)
+		r.NoError(err)
+
+		for path, code := range map[string]int{
+			"/debug/pprof/nope":          http.StatusNotFound,
+			"/debug/pprof/cpu?seconds=x": http.StatusBadRequest,
+		} {
+			resp, err := http.Get(srv.URL + path)
+			r.NoError(err)
+			resp.Body.Close()
+
+			r.Equal(code, resp.StatusCode, path)
+		}
+	})
+}
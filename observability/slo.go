@@ -0,0 +1,299 @@
+package observability
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"miren.dev/runtime/metrics"
+)
+
+// SLO is a service level objective for an app's requests, such as 99.9% of
+// requests succeeding in under 300ms over 30 days.
+type SLO struct {
+	// Objective is the fraction of requests that have to be good, e.g. 0.999.
+	Objective float64
+
+	// Latency is how quickly a request has to be served to be good. With 0,
+	// only failed requests count against the objective.
+	Latency time.Duration
+
+	// Window is the rolling window the error budget is measured over.
+	Window time.Duration
+
+	// BurnRateThreshold is the burn rate over AlertWindow at which an alert
+	// fires. A burn rate of 1 spends the budget exactly by the end of the
+	// window, so 14.4 over an hour spends 2% of a 30 day budget.
+	BurnRateThreshold float64
+	AlertWindow       time.Duration
+}
+
+const (
+	DefaultSLOWindow            = 30 * 24 * time.Hour
+	DefaultSLOAlertWindow       = time.Hour
+	DefaultSLOBurnRateThreshold = 14.4
+
+	// sloBuckets is how many buckets each window is split into. Requests
+	// leave a window one bucket at a time.
+	sloBuckets = 120
+)
+
+func (s *SLO) defaults() {
+	if s.Window == 0 {
+		s.Window = DefaultSLOWindow
+	}
+	if s.AlertWindow == 0 {
+		s.AlertWindow = DefaultSLOAlertWindow
+	}
+	if s.BurnRateThreshold == 0 {
+		s.BurnRateThreshold = DefaultSLOBurnRateThreshold
+	}
+}
+
+func (s *SLO) validate() error {
+	if s.Objective <= 0 || s.Objective >= 1 {
+		return fmt.Errorf("SLO objective must be between 0 and 1, got %v", s.Objective)
+	}
+
+	if s.AlertWindow > s.Window {
+		return fmt.Errorf("SLO alert window %s is longer than its window %s", s.AlertWindow, s.Window)
+	}
+
+	return nil
+}
+
+// good reports whether req met the objective. Server errors and requests
+// slower than Latency are bad.
+func (s *SLO) good(req metrics.HTTPRequest) bool {
+	if req.StatusCode >= 500 {
+		return false
+	}
+
+	if s.Latency > 0 && time.Duration(req.DurationMs)*time.Millisecond > s.Latency {
+		return false
+	}
+
+	return true
+}
+
+// SLOStatus is where an app stands against its SLO.
+type SLOStatus struct {
+	App string
+
+	// Total and Bad count the requests in the window.
+	Total int64
+	Bad   int64
+
+	// BudgetRemaining is the fraction of the window's error budget left. It
+	// goes negative once the budget is overspent.
+	BudgetRemaining float64
+
+	// BurnRate is how fast the budget is being spent over the alert window,
+	// as a multiple of the rate that would spend exactly all of it.
+	BurnRate float64
+
+	// Burning is set while BurnRate is at or above the threshold.
+	Burning bool
+}
+
+type sloBucket struct {
+	start     time.Time
+	total     int64
+	bad       int64
+	populated bool
+}
+
+// sloRing counts requests over a rolling window in fixed width buckets,
+// which are reused once they leave the window.
+type sloRing struct {
+	window  time.Duration
+	width   time.Duration
+	buckets []sloBucket
+}
+
+func newSLORing(window time.Duration) *sloRing {
+	width := max(window/sloBuckets, time.Second)
+
+	return &sloRing{
+		window:  window,
+		width:   width,
+		buckets: make([]sloBucket, int(window/width)+1),
+	}
+}
+
+func (r *sloRing) add(at time.Time, good bool) {
+	start := at.Truncate(r.width)
+
+	b := &r.buckets[int(start.UnixNano()/int64(r.width))%len(r.buckets)]
+	if !b.populated || !b.start.Equal(start) {
+		// Requests older than the bucket's current contents have already
+		// left the window.
+		if b.populated && start.Before(b.start) {
+			return
+		}
+
+		*b = sloBucket{start: start, populated: true}
+	}
+
+	b.total++
+	if !good {
+		b.bad++
+	}
+}
+
+// sum counts the requests in the buckets that started within the window
+// before now.
+func (r *sloRing) sum(now time.Time) (total, bad int64) {
+	since := now.Add(-r.window)
+
+	for _, b := range r.buckets {
+		if b.populated && b.start.After(since) && !b.start.After(now) {
+			total += b.total
+			bad += b.bad
+		}
+	}
+
+	return total, bad
+}
+
+// appSLO tracks one app's requests over both the SLO's window and its
+// shorter alert window, each with buckets to match.
+type appSLO struct {
+	window  *sloRing
+	alert   *sloRing
+	burning bool
+}
+
+// SLOTracker tracks each app's requests against an SLO, computing the error
+// budget left over the rolling window and how fast it's burning. OnAlert is
+// called when an app starts burning its budget faster than the threshold,
+// and again only once it has dropped back below it.
+type SLOTracker struct {
+	Log *slog.Logger
+
+	// OnAlert, if set, is called with the status of an app that just started
+	// burning its budget too fast. It's called with the tracker's lock held,
+	// so it mustn't call back into the tracker.
+	OnAlert func(SLOStatus)
+
+	slo SLO
+	now func() time.Time
+
+	mu   sync.Mutex
+	apps map[string]*appSLO
+}
+
+// NewSLOTracker returns a tracker holding every app to slo.
+func NewSLOTracker(log *slog.Logger, slo SLO) (*SLOTracker, error) {
+	slo.defaults()
+
+	if err := slo.validate(); err != nil {
+		return nil, err
+	}
+
+	return &SLOTracker{
+		Log:  log,
+		slo:  slo,
+		now:  time.Now,
+		apps: make(map[string]*appSLO),
+	}, nil
+}
+
+// Record counts req against its app's SLO, alerting if that leaves the app
+// burning its budget too fast.
+func (t *SLOTracker) Record(req metrics.HTTPRequest) {
+	at := req.Timestamp
+	if at.IsZero() {
+		at = t.now()
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	app, ok := t.apps[req.App]
+	if !ok {
+		app = &appSLO{
+			window: newSLORing(t.slo.Window),
+			alert:  newSLORing(t.slo.AlertWindow),
+		}
+		t.apps[req.App] = app
+	}
+
+	good := t.slo.good(req)
+
+	app.window.add(at, good)
+	app.alert.add(at, good)
+
+	t.checkAlert(req.App, app)
+}
+
+// Status returns where app stands against the SLO at the moment.
+func (t *SLOTracker) Status(app string) SLOStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	as, ok := t.apps[app]
+	if !ok {
+		return SLOStatus{App: app, BudgetRemaining: 1}
+	}
+
+	return t.status(app, as)
+}
+
+// Statuses returns the status of every app the tracker has seen requests
+// for.
+func (t *SLOTracker) Statuses() []SLOStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var statuses []SLOStatus
+
+	for name, app := range t.apps {
+		statuses = append(statuses, t.status(name, app))
+	}
+
+	return statuses
+}
+
+func (t *SLOTracker) status(name string, app *appSLO) SLOStatus {
+	now := t.now()
+	allowed := 1 - t.slo.Objective
+
+	st := SLOStatus{App: name, BudgetRemaining: 1}
+
+	st.Total, st.Bad = app.window.sum(now)
+	if st.Total > 0 {
+		st.BudgetRemaining = 1 - (float64(st.Bad)/float64(st.Total))/allowed
+	}
+
+	if total, bad := app.alert.sum(now); total > 0 {
+		st.BurnRate = (float64(bad) / float64(total)) / allowed
+	}
+
+	st.Burning = st.BurnRate >= t.slo.BurnRateThreshold
+
+	return st
+}
+
+func (t *SLOTracker) checkAlert(name string, app *appSLO) {
+	st := t.status(name, app)
+
+	switch {
+	case st.Burning && !app.burning:
+		app.burning = true
+
+		if t.Log != nil {
+			t.Log.Warn("app is burning its error budget too fast",
+				"app", name,
+				"burn_rate", st.BurnRate,
+				"budget_remaining", st.BudgetRemaining)
+		}
+
+		if t.OnAlert != nil {
+			t.OnAlert(st)
+		}
+	case !st.Burning && app.burning:
+		app.burning = false
+	}
+}
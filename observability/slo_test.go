@@ -0,0 +1,171 @@
+package observability
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"miren.dev/runtime/metrics"
+)
+
+func TestSLOTracker(t *testing.T) {
+	slo := SLO{
+		Objective:         0.99,
+		Latency:           300 * time.Millisecond,
+		Window:            10 * time.Minute,
+		AlertWindow:       time.Minute,
+		BurnRateThreshold: 5,
+	}
+
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	newTracker := func(t *testing.T) (*SLOTracker, *time.Time, *[]SLOStatus) {
+		tr, err := NewSLOTracker(slog.Default(), slo)
+		require.NoError(t, err)
+
+		now := base
+		tr.now = func() time.Time { return now }
+
+		var alerts []SLOStatus
+		tr.OnAlert = func(st SLOStatus) {
+			alerts = append(alerts, st)
+		}
+
+		return tr, &now, &alerts
+	}
+
+	request := func(app string, at time.Time, status int, latency time.Duration) metrics.HTTPRequest {
+		return metrics.HTTPRequest{
+			Timestamp:  at,
+			App:        app,
+			Method:     "GET",
+			Path:       "/",
+			StatusCode: status,
+			DurationMs: latency.Milliseconds(),
+		}
+	}
+
+	t.Run("spends the budget on slow and failed requests", func(t *testing.T) {
+		r := require.New(t)
+		tr, now, alerts := newTracker(t)
+
+		// 1000 requests spread over the window, 5 of them bad: half of the
+		// 1% budget.
+		start := now.Add(-9 * time.Minute)
+		for i := range 1000 {
+			at := start.Add(time.Duration(i) * 500 * time.Millisecond)
+
+			switch i {
+			case 100, 300, 500:
+				tr.Record(request("web", at, 200, time.Second))
+			case 700, 900:
+				tr.Record(request("web", at, 503, 10*time.Millisecond))
+			default:
+				tr.Record(request("web", at, 200, 50*time.Millisecond))
+			}
+		}
+
+		st := tr.Status("web")
+		r.Equal(int64(1000), st.Total)
+		r.Equal(int64(5), st.Bad)
+		r.InDelta(0.5, st.BudgetRemaining, 0.0001)
+		r.False(st.Burning)
+		r.Empty(*alerts)
+
+		// Client errors don't count against the app
+		tr.Record(request("web", *now, 404, 10*time.Millisecond))
+		r.Equal(int64(5), tr.Status("web").Bad)
+
+		r.Equal(SLOStatus{App: "api", BudgetRemaining: 1}, tr.Status("api"))
+	})
+
+	t.Run("forgets requests once they leave the window", func(t *testing.T) {
+		r := require.New(t)
+		tr, now, _ := newTracker(t)
+
+		for i := range 100 {
+			tr.Record(request("web", now.Add(-time.Duration(i)*time.Second), 500, 0))
+		}
+
+		r.Less(tr.Status("web").BudgetRemaining, 0.0)
+
+		*now = now.Add(slo.Window + time.Minute)
+
+		st := tr.Status("web")
+		r.Zero(st.Total)
+		r.Equal(1.0, st.BudgetRemaining)
+		r.Zero(st.BurnRate)
+	})
+
+	t.Run("alerts when the budget burns too fast", func(t *testing.T) {
+		r := require.New(t)
+		tr, now, alerts := newTracker(t)
+
+		for i := range 100 {
+			tr.Record(request("web", now.Add(-time.Duration(i)*100*time.Millisecond), 200, 0))
+		}
+
+		// 3 errors in 103 requests burns at about 2.9, under the threshold
+		for range 3 {
+			tr.Record(request("web", *now, 500, 0))
+		}
+
+		st := tr.Status("web")
+		r.InDelta(2.91, st.BurnRate, 0.01)
+		r.False(st.Burning)
+		r.Empty(*alerts)
+
+		// 7 more gets it to about 9.1
+		for range 7 {
+			tr.Record(request("web", *now, 500, 0))
+		}
+
+		r.Len(*alerts, 1)
+		r.Equal("web", (*alerts)[0].App)
+		r.True((*alerts)[0].Burning)
+		r.GreaterOrEqual((*alerts)[0].BurnRate, slo.BurnRateThreshold)
+
+		// Still burning, so no new alert
+		tr.Record(request("web", *now, 500, 0))
+		r.Len(*alerts, 1)
+
+		// Once the burst leaves the alert window, the burn rate drops even
+		// though the window's budget is still spent
+		*now = now.Add(2 * time.Minute)
+		tr.Record(request("web", *now, 200, 0))
+
+		st = tr.Status("web")
+		r.Zero(st.BurnRate)
+		r.False(st.Burning)
+		r.Less(st.BudgetRemaining, 0.0)
+
+		// A new burst alerts again
+		for range 10 {
+			tr.Record(request("web", *now, 500, 0))
+		}
+
+		r.Len(*alerts, 2)
+	})
+
+	t.Run("tracks apps separately", func(t *testing.T) {
+		r := require.New(t)
+		tr, now, alerts := newTracker(t)
+
+		for range 10 {
+			tr.Record(request("web", *now, 500, 0))
+			tr.Record(request("api", *now, 200, 0))
+		}
+
+		r.Len(*alerts, 1)
+		r.Equal("web", (*alerts)[0].App)
+
+		r.Equal(1.0, tr.Status("api").BudgetRemaining)
+		r.Len(tr.Statuses(), 2)
+	})
+
+	t.Run("rejects an objective out of range", func(t *testing.T) {
+		_, err := NewSLOTracker(slog.Default(), SLO{Objective: 99.9})
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,146 @@
+package observability
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// maxStructuredFields caps how many fields of a structured line are stored,
+// so a line with an unbounded number of keys can't explode the columns.
+const maxStructuredFields = 64
+
+var (
+	structuredLevelKeys = []string{"level", "lvl", "severity"}
+	structuredMsgKeys   = []string{"msg", "message"}
+	structuredTimeKeys  = []string{"time", "ts", "timestamp"}
+)
+
+// promoteStructured adds the fields of a JSON log line to logData, the
+// entry it's being stored as. The level, lowercased, is stored as level and
+// the message as msg, whichever of the common names the logger used for
+// them. The rest are stored under their own names, with nested objects
+// flattened as slog groups are written, like group.key. Fields the entry
+// already has, such as its entity or attributes, are left alone. Lines
+// that aren't JSON objects are stored as they are.
+func promoteStructured(body string, logData map[string]any) {
+	body = strings.TrimSpace(body)
+	if !strings.HasPrefix(body, "{") {
+		return
+	}
+
+	dec := json.NewDecoder(strings.NewReader(body))
+	dec.UseNumber()
+
+	var obj map[string]any
+	if err := dec.Decode(&obj); err != nil {
+		return
+	}
+
+	set := func(key, value string) {
+		if _, ok := logData[key]; !ok {
+			logData[key] = value
+		}
+	}
+
+	if v, ok := firstField(obj, structuredLevelKeys); ok {
+		set("level", strings.ToLower(v))
+	}
+
+	if v, ok := firstField(obj, structuredMsgKeys); ok {
+		set("msg", v)
+	}
+
+	skip := make(map[string]struct{})
+	for _, keys := range [][]string{structuredLevelKeys, structuredMsgKeys, structuredTimeKeys} {
+		for _, k := range keys {
+			skip[k] = struct{}{}
+		}
+	}
+
+	added := 0
+
+	var flatten func(prefix string, obj map[string]any)
+
+	flatten = func(prefix string, obj map[string]any) {
+		for k, v := range obj {
+			if prefix == "" {
+				if _, ok := skip[k]; ok {
+					continue
+				}
+			}
+
+			key := prefix + k
+
+			// VictoriaLogs reserves fields starting with _
+			if key == "" || strings.HasPrefix(key, "_") {
+				continue
+			}
+
+			if nested, ok := v.(map[string]any); ok {
+				flatten(key+".", nested)
+				continue
+			}
+
+			if added >= maxStructuredFields {
+				return
+			}
+
+			str, ok := structuredValue(v)
+			if !ok {
+				continue
+			}
+
+			if _, exists := logData[key]; exists {
+				continue
+			}
+
+			logData[key] = str
+			added++
+		}
+	}
+
+	flatten("", obj)
+}
+
+// firstField returns the first of keys obj has as a scalar.
+func firstField(obj map[string]any, keys []string) (string, bool) {
+	for _, k := range keys {
+		if v, ok := obj[k]; ok {
+			if s, ok := structuredValue(v); ok {
+				return s, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// structuredValue formats a JSON value as the string it's stored as. Nulls
+// aren't stored.
+func structuredValue(v any) (string, bool) {
+	switch v := v.(type) {
+	case nil:
+		return "", false
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case bool:
+		if v {
+			return "true", true
+		}
+		return "false", true
+	default:
+		var buf bytes.Buffer
+
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+
+		if err := enc.Encode(v); err != nil {
+			return "", false
+		}
+
+		return strings.TrimSpace(buf.String()), true
+	}
+}
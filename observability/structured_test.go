@@ -0,0 +1,132 @@
+package observability_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"miren.dev/runtime/observability"
+)
+
+func TestStructuredLogs(t *testing.T) {
+	// write sends entries through a PersistentLogWriter, returning the
+	// fields each was stored with.
+	write := func(t *testing.T, entries ...observability.LogEntry) []map[string]string {
+		r := require.New(t)
+
+		var (
+			mu    sync.Mutex
+			lines []map[string]string
+		)
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			sc := bufio.NewScanner(req.Body)
+			for sc.Scan() {
+				var line map[string]string
+				r.NoError(json.Unmarshal(sc.Bytes(), &line))
+				lines = append(lines, line)
+			}
+		}))
+		defer srv.Close()
+
+		pw := &observability.PersistentLogWriter{Address: srv.URL}
+		r.NoError(pw.Populated())
+		defer pw.Close()
+
+		for _, le := range entries {
+			le.Timestamp = time.Now()
+			r.NoError(pw.WriteEntry("app-1", le))
+		}
+
+		r.NoError(pw.Flush())
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		return lines
+	}
+
+	t.Run("promotes the fields of a slog JSON line", func(t *testing.T) {
+		r := require.New(t)
+
+		var buf bytes.Buffer
+
+		log := slog.New(slog.NewJSONHandler(&buf, nil))
+		log.Warn("disk filling up", "used", 0.93, "ok", false, slog.Group("disk", "path", "/data"))
+
+		body := strings.TrimSpace(buf.String())
+
+		lines := write(t, observability.LogEntry{
+			Stream:     observability.Structured,
+			Body:       body,
+			Attributes: map[string]string{"sandbox": "sb-1"},
+		})
+		r.Len(lines, 1)
+
+		line := lines[0]
+		r.Equal(body, line["_msg"])
+		r.Equal("structured", line["stream"])
+		r.Equal("warn", line["level"])
+		r.Equal("disk filling up", line["msg"])
+		r.Equal("0.93", line["used"])
+		r.Equal("false", line["ok"])
+		r.Equal("/data", line["disk.path"])
+		r.Equal("sb-1", line["sandbox"])
+		r.NotContains(line, "time")
+	})
+
+	t.Run("keeps the entry's own fields", func(t *testing.T) {
+		r := require.New(t)
+
+		lines := write(t, observability.LogEntry{
+			Stream:     observability.Structured,
+			Body:       `{"level":"info","message":"hi","entity":"other","sandbox":"other","_stream":"x"}`,
+			Attributes: map[string]string{"sandbox": "sb-1"},
+		})
+		r.Len(lines, 1)
+
+		line := lines[0]
+		r.Equal("info", line["level"])
+		r.Equal("hi", line["msg"])
+		r.Equal("app-1", line["entity"])
+		r.Equal("sb-1", line["sandbox"])
+		r.NotContains(line, "_stream")
+	})
+
+	t.Run("stores lines that aren't JSON as they are", func(t *testing.T) {
+		r := require.New(t)
+
+		lines := write(t,
+			observability.LogEntry{Stream: observability.Structured, Body: `{"level":"info", truncated`},
+			observability.LogEntry{Stream: observability.Structured, Body: `level=info msg=hi`},
+		)
+		r.Len(lines, 2)
+
+		for _, line := range lines {
+			r.NotContains(line, "level")
+			r.NotContains(line, "msg")
+		}
+	})
+
+	t.Run("only promotes fields of structured lines", func(t *testing.T) {
+		r := require.New(t)
+
+		lines := write(t, observability.LogEntry{
+			Stream: observability.Stdout,
+			Body:   `{"level":"INFO","msg":"hello"}`,
+		})
+		r.Len(lines, 1)
+		r.NotContains(lines[0], "level")
+	})
+}
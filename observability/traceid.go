@@ -0,0 +1,143 @@
+package observability
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultTraceIDPattern finds trace IDs written as trace_id=..., as text
+// loggers and logfmt do.
+const DefaultTraceIDPattern = `trace_id"?\s*[=:]\s*\"?(\w+)`
+
+// DefaultTraceIDField is the key trace IDs are read from in JSON lines, as
+// slog's JSON handler writes them.
+const DefaultTraceIDField = "trace_id"
+
+// DefaultTraceIDExtractor finds trace IDs written the usual ways.
+var DefaultTraceIDExtractor = MustTraceIDExtractor(nil, nil)
+
+// TraceIDExtractor finds the trace ID a log line was written for, so the
+// lines of a request can be found together.
+//
+// Lines that are JSON objects have their trace ID read from the first of
+// Fields they have. Fields can name nested keys with dots, as slog groups
+// them, such as "otel.trace_id". Lines that aren't JSON, or have none of
+// Fields, are matched against Patterns in turn. A pattern's trace_id group
+// is the trace ID, or else its first group, or else the whole match.
+type TraceIDExtractor struct {
+	Fields   []string
+	Patterns []*regexp.Regexp
+}
+
+// NewTraceIDExtractor compiles patterns into an extractor. No patterns means
+// DefaultTraceIDPattern, and no fields means DefaultTraceIDField.
+func NewTraceIDExtractor(patterns, fields []string) (*TraceIDExtractor, error) {
+	if len(patterns) == 0 {
+		patterns = []string{DefaultTraceIDPattern}
+	}
+
+	if len(fields) == 0 {
+		fields = []string{DefaultTraceIDField}
+	}
+
+	x := &TraceIDExtractor{Fields: fields}
+
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trace id pattern %q: %w", p, err)
+		}
+
+		x.Patterns = append(x.Patterns, re)
+	}
+
+	return x, nil
+}
+
+// MustTraceIDExtractor is NewTraceIDExtractor for patterns known to compile.
+func MustTraceIDExtractor(patterns, fields []string) *TraceIDExtractor {
+	x, err := NewTraceIDExtractor(patterns, fields)
+	if err != nil {
+		panic(err)
+	}
+
+	return x
+}
+
+// Extract returns the trace ID in line, or "" if it has none.
+func (x *TraceIDExtractor) Extract(line string) string {
+	if id := x.extractJSON(line); id != "" {
+		return id
+	}
+
+	for _, re := range x.Patterns {
+		m := re.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+
+		if i := re.SubexpIndex("trace_id"); i > 0 && m[i] != "" {
+			return m[i]
+		}
+
+		if len(m) > 1 {
+			if m[1] != "" {
+				return m[1]
+			}
+
+			continue
+		}
+
+		return m[0]
+	}
+
+	return ""
+}
+
+func (x *TraceIDExtractor) extractJSON(line string) string {
+	if len(x.Fields) == 0 {
+		return ""
+	}
+
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "{") {
+		return ""
+	}
+
+	var obj map[string]any
+
+	// Lines that only look like JSON are left to the patterns
+	if err := json.Unmarshal([]byte(line), &obj); err != nil {
+		return ""
+	}
+
+	for _, field := range x.Fields {
+		if id, ok := lookupField(obj, field).(string); ok && id != "" {
+			return id
+		}
+	}
+
+	return ""
+}
+
+// lookupField returns the value of field in obj, following dots into nested
+// objects when obj has no key named field outright.
+func lookupField(obj map[string]any, field string) any {
+	if v, ok := obj[field]; ok {
+		return v
+	}
+
+	head, rest, ok := strings.Cut(field, ".")
+	if !ok {
+		return nil
+	}
+
+	nested, ok := obj[head].(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	return lookupField(nested, rest)
+}
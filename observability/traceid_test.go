@@ -0,0 +1,78 @@
+package observability_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"miren.dev/runtime/observability"
+)
+
+func TestTraceIDExtractor(t *testing.T) {
+	t.Run("reads the trace ID from JSON lines", func(t *testing.T) {
+		r := require.New(t)
+
+		x := observability.DefaultTraceIDExtractor
+
+		r.Equal("4bf92f35-77b3", x.Extract(`{"time":"2026-01-01T00:00:00Z","level":"INFO","msg":"hello","trace_id":"4bf92f35-77b3"}`))
+	})
+
+	t.Run("reads nested and alternate JSON fields", func(t *testing.T) {
+		r := require.New(t)
+
+		x, err := observability.NewTraceIDExtractor(nil, []string{"traceId", "otel.trace_id"})
+		r.NoError(err)
+
+		r.Equal("abc123", x.Extract(`{"msg":"hello","traceId":"abc123"}`))
+		r.Equal("def456", x.Extract(`{"msg":"hello","otel":{"trace_id":"def456"}}`))
+		r.Equal("", x.Extract(`{"msg":"hello","otel":"def456"}`))
+	})
+
+	t.Run("matches text lines", func(t *testing.T) {
+		r := require.New(t)
+
+		x := observability.DefaultTraceIDExtractor
+
+		r.Equal("abc123", x.Extract(`time=2026-01-01T00:00:00Z level=INFO msg=hello trace_id=abc123 status=200`))
+		r.Equal("abc123", x.Extract(`handled request trace_id: "abc123"`))
+	})
+
+	t.Run("falls back to patterns for lines that aren't JSON", func(t *testing.T) {
+		r := require.New(t)
+
+		x := observability.DefaultTraceIDExtractor
+
+		r.Equal("abc123", x.Extract(`{not json} trace_id=abc123`))
+		r.Equal("abc123", x.Extract(`{"msg":"truncated trace_id=abc123`))
+	})
+
+	t.Run("tries each pattern in turn", func(t *testing.T) {
+		r := require.New(t)
+
+		x, err := observability.NewTraceIDExtractor([]string{
+			`traceparent=00-(?P<trace_id>[0-9a-f]{32})-`,
+			`\[trace ([0-9a-f]+)\]`,
+		}, nil)
+		r.NoError(err)
+
+		r.Equal("4bf92f3577b34da6a3ce929d0e0e4736", x.Extract(`GET / traceparent=00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01`))
+		r.Equal("beef", x.Extract(`[trace beef] hello`))
+		r.Equal("", x.Extract(`trace_id=abc123`))
+	})
+
+	t.Run("finds nothing in lines without a trace ID", func(t *testing.T) {
+		r := require.New(t)
+
+		x := observability.DefaultTraceIDExtractor
+
+		r.Equal("", x.Extract(`starting server on :8080`))
+		r.Equal("", x.Extract(`{"msg":"starting server","port":8080}`))
+		r.Equal("", x.Extract(``))
+	})
+
+	t.Run("rejects invalid patterns", func(t *testing.T) {
+		r := require.New(t)
+
+		_, err := observability.NewTraceIDExtractor([]string{`trace_id=(`}, nil)
+		r.ErrorContains(err, "invalid trace id pattern")
+	})
+}
@@ -67,6 +67,40 @@ func Conflict(category string, element any) error {
 	return ErrConflict{Category: category, Element: fmt.Sprint(element)}
 }
 
+type ErrPermissionDenied struct {
+	Category string
+	Element  string
+}
+
+func (e ErrPermissionDenied) Error() string {
+	return "permission denied: " + e.Element
+}
+
+func (e ErrPermissionDenied) ErrorMessage() string {
+	return e.Element
+}
+
+func (e ErrPermissionDenied) ErrorCategory() string {
+	return e.Category
+}
+
+func (e ErrPermissionDenied) ErrorCode() string {
+	return "permission-denied"
+}
+
+func (e ErrPermissionDenied) Is(target error) bool {
+	if target == nil {
+		return false
+	}
+
+	_, ok := target.(ErrPermissionDenied)
+	return ok
+}
+
+func PermissionDenied(category string, element any) error {
+	return ErrPermissionDenied{Category: category, Element: fmt.Sprint(element)}
+}
+
 type ErrCorruption struct {
 	Category string
 	Message  string
@@ -171,6 +205,10 @@ func RemoteError(category, code, message string) error {
 		return Conflict(category, message)
 	case "corruption":
 		return ErrCorruption{Category: category, Message: message}
+	case "permission-denied":
+		return PermissionDenied(category, message)
+	case "deadline-exceeded":
+		return ErrDeadlineExceeded{Message: message}
 	}
 
 	return ErrRemote{
@@ -235,6 +273,37 @@ func Closed(message string) error {
 	return ErrClosed{Message: message}
 }
 
+// ErrDeadlineExceeded is returned when an operation runs past a timeout
+// imposed on it, such as an rpc method's declared timeout.
+type ErrDeadlineExceeded struct {
+	Message string
+}
+
+func (e ErrDeadlineExceeded) Error() string {
+	return "deadline exceeded: " + e.Message
+}
+
+func (e ErrDeadlineExceeded) ErrorCategory() string {
+	return "timeout"
+}
+
+func (e ErrDeadlineExceeded) ErrorCode() string {
+	return "deadline-exceeded"
+}
+
+func (e ErrDeadlineExceeded) ErrorMessage() string {
+	return e.Message
+}
+
+func (e ErrDeadlineExceeded) Is(target error) bool {
+	_, ok := target.(ErrDeadlineExceeded)
+	return ok
+}
+
+func DeadlineExceeded(message string) error {
+	return ErrDeadlineExceeded{Message: message}
+}
+
 func Wrap(err error) error {
 	if err == nil {
 		return nil
@@ -242,7 +311,12 @@ func Wrap(err error) error {
 
 	// Return existing cond errors unchanged
 	switch err.(type) {
-	case ErrNotFound, ErrConflict, ErrCorruption, ErrGeneric, ErrRemote, ErrPanic, ErrClosed, ErrValidationFailure:
+	case ErrNotFound, ErrConflict, ErrCorruption, ErrGeneric, ErrRemote, ErrPanic, ErrClosed, ErrValidationFailure, ErrPermissionDenied, ErrDeadlineExceeded:
+		return err
+	}
+
+	// Errors that carry their own code are sent as is
+	if _, ok := err.(interface{ ErrorCode() string }); ok {
 		return err
 	}
 
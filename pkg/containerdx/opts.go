@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -497,6 +498,23 @@ func WithSysctls(sysctls map[string]string) oci.SpecOpts {
 	}
 }
 
+// WithRlimits sets the provided rlimits onto the spec, replacing any already
+// set for the same resource
+func WithRlimits(rlimits []runtimespec.POSIXRlimit) oci.SpecOpts {
+	return func(ctx context.Context, client oci.Client, c *containers.Container, s *runtimespec.Spec) error {
+		if s.Process == nil {
+			s.Process = &runtimespec.Process{}
+		}
+		for _, rl := range rlimits {
+			s.Process.Rlimits = slices.DeleteFunc(s.Process.Rlimits, func(cur runtimespec.POSIXRlimit) bool {
+				return cur.Type == rl.Type
+			})
+			s.Process.Rlimits = append(s.Process.Rlimits, rl)
+		}
+		return nil
+	}
+}
+
 // WithPodOOMScoreAdj sets the oom score for the pod sandbox
 func WithPodOOMScoreAdj(adj int, restrict bool) oci.SpecOpts {
 	return func(ctx context.Context, client oci.Client, c *containers.Container, s *runtimespec.Spec) error {
@@ -0,0 +1,178 @@
+package controller
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"miren.dev/runtime/pkg/entity"
+)
+
+// BackoffState describes an entity whose handler has been failing. Failures
+// counts the consecutive failures, and the entity's latest event will be
+// retried at RetryAt, Delay after the last one.
+type BackoffState struct {
+	Id       entity.Id
+	Failures int
+	Delay    time.Duration
+	RetryAt  time.Time
+}
+
+type backoffEntry struct {
+	BackoffState
+
+	event     Event
+	failedRev int64
+	timer     *time.Timer
+}
+
+// Backoff delays retrying entities whose handler failed, doubling the delay
+// with each consecutive failure up to a maximum. Each entity backs off on
+// its own, so one that keeps failing doesn't hold up the others.
+//
+// Thread-safe for concurrent access by multiple workers and the retry timers.
+type Backoff struct {
+	base, max time.Duration
+
+	// retry is called with the lock held when an entity's delay is up
+	retry func(Event)
+
+	mu      sync.Mutex
+	items   map[entity.Id]*backoffEntry
+	stopped bool
+}
+
+func NewBackoff(base, max time.Duration, retry func(Event)) *Backoff {
+	return &Backoff{
+		base:  base,
+		max:   max,
+		retry: retry,
+		items: make(map[entity.Id]*backoffEntry),
+	}
+}
+
+// Delay returns how long to wait before retrying after the given number of
+// consecutive failures.
+func (b *Backoff) Delay(failures int) time.Duration {
+	d := b.base
+	for i := 1; i < failures && d < b.max; i++ {
+		d *= 2
+	}
+
+	return min(d, b.max)
+}
+
+// Failed records that handling ev failed and schedules it to be retried
+// once the entity's delay is up. A zero base delay disables retries.
+func (b *Backoff) Failed(ev Event) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.stopped || b.base <= 0 {
+		return 0
+	}
+
+	e, ok := b.items[ev.Id]
+	if !ok {
+		e = &backoffEntry{BackoffState: BackoffState{Id: ev.Id}}
+		b.items[ev.Id] = e
+	}
+
+	e.Failures++
+	e.Delay = b.Delay(e.Failures)
+	e.RetryAt = time.Now().Add(e.Delay)
+	e.event = ev
+	e.failedRev = ev.Rev
+
+	if e.timer != nil {
+		e.timer.Stop()
+	}
+
+	e.timer = time.AfterFunc(e.Delay, func() {
+		b.fire(e)
+	})
+
+	return e.Delay
+}
+
+func (b *Backoff) fire(e *backoffEntry) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.stopped || b.items[e.Id] != e || e.timer == nil {
+		return
+	}
+
+	e.timer = nil
+	b.retry(e.event)
+}
+
+// Succeeded forgets the failures of id.
+func (b *Backoff) Succeeded(id entity.Id) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if e, ok := b.items[id]; ok {
+		if e.timer != nil {
+			e.timer.Stop()
+		}
+
+		delete(b.items, id)
+	}
+}
+
+// Hold reports whether ev's entity is waiting out its delay. If it is, ev
+// replaces the event that will be retried, so the retry sees the latest
+// version of the entity. A delete, or a revision newer than the one that
+// failed, isn't held: it resets the entity's backoff and is handled right
+// away, since it may be what fixes the failure.
+func (b *Backoff) Hold(ev Event) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	e, ok := b.items[ev.Id]
+	if !ok || e.timer == nil {
+		return false
+	}
+
+	if ev.Type == EventDeleted || ev.Rev > e.failedRev {
+		e.timer.Stop()
+		delete(b.items, ev.Id)
+		return false
+	}
+
+	e.event = ev
+	return true
+}
+
+// Stop cancels all pending retries.
+func (b *Backoff) Stop() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.stopped = true
+
+	for _, e := range b.items {
+		if e.timer != nil {
+			e.timer.Stop()
+		}
+	}
+}
+
+// State returns the entities that are backing off, sorted by id.
+func (b *Backoff) State() []BackoffState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	states := make([]BackoffState, 0, len(b.items))
+	for _, e := range b.items {
+		states = append(states, e.BackoffState)
+	}
+
+	slices.SortFunc(states, func(a, b BackoffState) int {
+		return strings.Compare(string(a.Id), string(b.Id))
+	})
+
+	return states
+}
@@ -63,6 +63,9 @@ func WorkerId(ctx context.Context) string {
 // HandlerFunc is a function that processes an entity
 type HandlerFunc func(ctx context.Context, event Event) ([]entity.Attr, error)
 
+// DeleteHandlerFunc cleans up after an entity that was deleted
+type DeleteHandlerFunc func(ctx context.Context, id entity.Id) error
+
 // inFlightEntry tracks an entity being processed and queues additional events
 type inFlightEntry struct {
 	revision      int64
@@ -80,6 +83,7 @@ type ReconcileController struct {
 	name         string
 	index        entity.Attr
 	handler      HandlerFunc
+	onDelete     DeleteHandlerFunc
 	resyncPeriod time.Duration
 	workers      int
 	workQueue    chan Event
@@ -94,14 +98,31 @@ type ReconcileController struct {
 	// Controllers record revisions from their writes to reduce reconciliation noise
 	recentWrites *RingSet
 
+	// Entities whose handler failed are retried on their own schedule,
+	// backing off exponentially while they keep failing
+	backoff *Backoff
+
+	// health tracks how the workers are keeping up, see Health
+	health healthCounters
+
+	// conflictRetries is how many times updates are retried after a
+	// conflicting write, see SetConflictRetries
+	conflictRetries int
+
 	// periodic is an optional periodic callback
 	periodic     func(ctx context.Context) error
 	periodicTime time.Duration
 }
 
+// Default delays for retrying entities whose handler failed, see SetBackoff.
+const (
+	DefaultBackoffBase = time.Second
+	DefaultBackoffMax  = 5 * time.Minute
+)
+
 // NewReconcileController creates a new controller
 func NewReconcileController(name string, log *slog.Logger, index entity.Attr, esc *entityserver_v1alpha.EntityAccessClient, handler HandlerFunc, resyncPeriod time.Duration, workers int) *ReconcileController {
-	return &ReconcileController{
+	c := &ReconcileController{
 		Log:          log.With("module", fmt.Sprintf("reconcile.%s", name)),
 		name:         name,
 		index:        index,
@@ -113,6 +134,43 @@ func NewReconcileController(name string, log *slog.Logger, index entity.Attr, es
 		inFlight:     make(map[entity.Id]*inFlightEntry),
 		recentWrites: NewRingSet(1000), // Track last 1000 revisions written by this controller
 	}
+
+	c.backoff = NewBackoff(DefaultBackoffBase, DefaultBackoffMax, c.requeue)
+
+	return c
+}
+
+// SetBackoff sets how long to wait before retrying an entity whose handler
+// failed. The delay starts at base and doubles with each consecutive
+// failure, up to max. A zero base disables retries, leaving failed entities
+// to the next event or resync. Must be called before Start.
+func (c *ReconcileController) SetBackoff(base, max time.Duration) {
+	c.backoff = NewBackoff(base, max, c.requeue)
+}
+
+// SetDeleteHandler sets a handler that's called instead of the reconcile
+// handler when the watch sees an entity deleted. Like a finalizer, a delete
+// that fails is retried with the controller's backoff until it succeeds, so
+// external state tied to the entity isn't leaked by a transient error. Must
+// be called before Start.
+func (c *ReconcileController) SetDeleteHandler(fn DeleteHandlerFunc) {
+	c.onDelete = fn
+}
+
+// SetConflictRetries makes the controller write a handler's updates only if
+// the entity is still at the revision the handler saw. If another writer
+// changed it in the meantime, the entity is refetched and the handler run
+// again on the fresh copy, up to n times. With n at 0, the default, updates
+// are written regardless of the revision, so handlers that write to their
+// own entity while handling it keep working. Must be called before Start.
+func (c *ReconcileController) SetConflictRetries(n int) {
+	c.conflictRetries = n
+}
+
+// Backoffs returns the entities that are currently backing off after their
+// handler failed.
+func (c *ReconcileController) Backoffs() []BackoffState {
+	return c.backoff.State()
 }
 
 // SetPeriodic sets the periodic callback function
@@ -129,6 +187,10 @@ func (c *ReconcileController) Start(top context.Context) error {
 
 	c.Log.Info("Starting controller", "name", c.name)
 
+	// Count the start as progress, so a controller isn't wedged before it
+	// has had the chance to reconcile anything
+	c.health.lastProcessed.Store(time.Now().UnixNano())
+
 	// Start workers
 	for i := 0; i < c.workers; i++ {
 		c.wg.Go(func() {
@@ -186,17 +248,8 @@ func (c *ReconcileController) Start(top context.Context) error {
 				}
 
 				if op.HasEntity() {
-					aen := op.Entity()
-
-					createdAt := time.UnixMilli(aen.CreatedAt())
-					updatedAt := time.UnixMilli(aen.UpdatedAt())
-
-					ev.Entity = entity.New(aen.Attrs())
-
-					ev.Entity.SetCreatedAt(createdAt)
-					ev.Entity.SetUpdatedAt(updatedAt)
-					ev.Entity.SetRevision(aen.Revision())
-					ev.Rev = aen.Revision()
+					ev.Entity = fromRPC(op.Entity())
+					ev.Rev = ev.Entity.GetRevision()
 				}
 
 				// Skip watch events for revisions we recently wrote to reduce reconciliation noise
@@ -270,6 +323,7 @@ func (c *ReconcileController) Stop() {
 		c.cancel()
 	}
 	c.wg.Wait()
+	c.backoff.Stop()
 	close(c.workQueue)
 }
 
@@ -302,6 +356,29 @@ func (c *ReconcileController) Enqueue(event Event) {
 	}
 }
 
+// requeue adds an event whose backoff is up back to the work queue.
+func (c *ReconcileController) requeue(event Event) {
+	select {
+	case c.workQueue <- event:
+		c.Log.Debug("Retrying entity after backoff", "entity", event.Id, "rev", event.Rev)
+	default:
+		c.Log.Warn("Work queue full, dropping retry", "entity", event.Id, "eventType", event.Type, "queueSize", len(c.workQueue))
+	}
+}
+
+// recordResult starts or resets the backoff of the entity an event was
+// processed for, and updates the controller's health.
+func (c *ReconcileController) recordResult(event Event, err error) time.Duration {
+	c.health.record(time.Now(), err)
+
+	if err != nil {
+		return c.backoff.Failed(event)
+	}
+
+	c.backoff.Succeeded(event.Id)
+	return 0
+}
+
 // runWorker processes items from the work queue
 func (c *ReconcileController) runWorker(ctx context.Context) {
 	id := idgen.Gen("worker")
@@ -321,6 +398,12 @@ func (c *ReconcileController) runWorker(ctx context.Context) {
 				return
 			}
 
+			// Hold events for entities that are backing off until their retry
+			if c.backoff.Hold(event) {
+				c.Log.Debug("Entity is backing off, holding event for retry", "entity", event.Id, "eventRev", event.Rev)
+				continue
+			}
+
 			// Check if this entity is already being processed
 			c.inFlightMu.Lock()
 			if entry, inFlight := c.inFlight[event.Id]; inFlight {
@@ -347,8 +430,8 @@ func (c *ReconcileController) runWorker(ctx context.Context) {
 
 			// Process the event
 			updates, err := c.processItem(ctx, event)
-			if err != nil {
-				c.Log.Error("error processing item", "event", event, "error", err)
+			if retry := c.recordResult(event, err); err != nil {
+				c.Log.Error("error processing item", "event", event, "error", err, "retryIn", retry)
 				// we still try to process updates even if there is an error.
 			}
 
@@ -371,12 +454,17 @@ func (c *ReconcileController) runWorker(ctx context.Context) {
 				entry.revision = event.Rev
 				c.inFlightMu.Unlock()
 
+				if c.backoff.Hold(event) {
+					c.Log.Debug("Entity is backing off, holding pending event for retry", "entity", event.Id, "eventRev", event.Rev)
+					continue
+				}
+
 				c.Log.Info("Processing pending event", "entity", event.Id, "worker", WorkerId(ctx), "rev", event.Rev, "remainingPending", len(entry.pendingEvents))
 
 				// Process the pending event
 				updates, err := c.processItem(ctx, event)
-				if err != nil {
-					c.Log.Error("error processing pending item", "event", event, "error", err)
+				if retry := c.recordResult(event, err); err != nil {
+					c.Log.Error("error processing pending item", "event", event, "error", err, "retryIn", retry)
 				}
 
 				c.applyUpdates(ctx, event, updates)
@@ -389,7 +477,12 @@ func (c *ReconcileController) runWorker(ctx context.Context) {
 func (c *ReconcileController) processItem(ctx context.Context, event Event) ([]entity.Attr, error) {
 	// Handle different event types
 	switch event.Type {
-	case EventAdded, EventUpdated, EventDeleted:
+	case EventDeleted:
+		if c.onDelete != nil {
+			return nil, c.onDelete(ctx, event.Id)
+		}
+		return c.handler(ctx, event)
+	case EventAdded, EventUpdated:
 		return c.handler(ctx, event)
 	default:
 		return nil, fmt.Errorf("unknown event type: %s", event.Type)
@@ -420,24 +513,84 @@ func (c *ReconcileController) applyUpdates(ctx context.Context, event Event, upd
 	// Add entity ID to attrs for Patch
 	attrs := append([]entity.Attr{entity.Ref(entity.DBId, event.Id)}, updates...)
 
-	// Use Patch without OCC (revision 0) to avoid breaking unforeseen code that may depend
-	// on this working without conflict detection. Can revisit with more holistic pass.
-	result, err := c.esc.Patch(ctx, attrs, 0)
-	if err != nil {
-		if errors.Is(err, cond.ErrNotFound{}) {
-			c.Log.Warn("entity not found during update", "entity", event.Id)
+	// Without conflict retries, Patch without OCC (revision 0) to avoid breaking
+	// unforeseen code that may depend on this working without conflict detection.
+	var rev int64
+	if c.conflictRetries > 0 {
+		rev = event.Rev
+	}
+
+	for attempt := 0; ; attempt++ {
+		result, err := c.esc.Patch(ctx, attrs, rev)
+		if err == nil {
+			c.Log.Info("updated entity", "entity", event.Id)
+			// Record the revision we just wrote so we can skip the watch event
+			if result.HasRevision() {
+				c.RecordWrite(result.Revision())
+			}
 			return
 		}
-		c.Log.Error("error updating entity", "entity", event.Id, "error", err)
-	} else {
-		c.Log.Info("updated entity", "entity", event.Id)
-		// Record the revision we just wrote so we can skip the watch event
-		if result.HasRevision() {
-			c.RecordWrite(result.Revision())
+
+		switch {
+		case errors.Is(err, cond.ErrNotFound{}):
+			c.Log.Warn("entity not found during update", "entity", event.Id)
+			return
+		case errors.Is(err, cond.ErrConflict{}) && rev > 0 && attempt < c.conflictRetries:
+			c.Log.Info("entity changed while reconciling, retrying on latest revision", "entity", event.Id, "rev", rev, "attempt", attempt+1)
+
+			event, updates, err = c.rehandle(ctx, event)
+			if err != nil {
+				c.Log.Error("error reconciling latest revision", "entity", event.Id, "error", err)
+				return
+			}
+
+			if len(updates) == 0 {
+				return
+			}
+
+			attrs = append([]entity.Attr{entity.Ref(entity.DBId, event.Id)}, updates...)
+			rev = event.Rev
+		default:
+			c.Log.Error("error updating entity", "entity", event.Id, "error", err)
+			return
 		}
 	}
 }
 
+// rehandle refetches the entity for event and runs the handler on it again,
+// after a write based on the old copy conflicted.
+func (c *ReconcileController) rehandle(ctx context.Context, event Event) (Event, []entity.Attr, error) {
+	res, err := c.esc.Get(ctx, event.Id.String())
+	if err != nil {
+		return event, nil, err
+	}
+
+	en := fromRPC(res.Entity())
+
+	event = Event{
+		Type:    EventUpdated,
+		Id:      event.Id,
+		Entity:  en,
+		Rev:     en.GetRevision(),
+		PrevRev: event.Rev,
+	}
+
+	updates, err := c.processItem(ctx, event)
+
+	return event, updates, err
+}
+
+// fromRPC converts an entity received from the entity server.
+func fromRPC(aen *entityserver_v1alpha.Entity) *entity.Entity {
+	en := entity.New(aen.Attrs())
+
+	en.SetCreatedAt(time.UnixMilli(aen.CreatedAt()))
+	en.SetUpdatedAt(time.UnixMilli(aen.UpdatedAt()))
+	en.SetRevision(aen.Revision())
+
+	return en
+}
+
 // periodicResync periodically resyncs all entities
 func (c *ReconcileController) periodicResync(ctx context.Context) {
 	c.Log.Info("Starting resync")
@@ -461,19 +614,10 @@ func (c *ReconcileController) periodicResync(ctx context.Context) {
 		entities := resp.Values()
 
 		for _, aen := range entities {
-			createdAt := time.UnixMilli(aen.CreatedAt())
-			updatedAt := time.UnixMilli(aen.UpdatedAt())
-
-			en := entity.New(aen.Attrs())
-
-			en.SetCreatedAt(createdAt)
-			en.SetUpdatedAt(updatedAt)
-			en.SetRevision(aen.Revision())
-
 			ev := Event{
 				Type:   EventUpdated,
 				Id:     entity.Id(aen.Id()),
-				Entity: en,
+				Entity: fromRPC(aen),
 				Rev:    aen.Revision(),
 			}
 
@@ -549,6 +693,10 @@ type UpdatingController[P ControllerEntity] interface {
 // across multiple entities. Unlike GenericController which maps 1:1 between
 // an entity and a resource, ReconcileControllerI handles controllers where
 // one entity drives reconciliation of N resources.
+//
+// obj is decoded fresh for each call. Controllers that keep it, or any of its
+// slices, after Reconcile returns should keep obj.DeepCopy() instead, so that
+// later changes to either don't leak into the other.
 type ReconcileControllerI[P ControllerEntity] interface {
 	Init(context.Context) error
 	Reconcile(ctx context.Context, obj P, meta *entity.Meta) error
@@ -2,7 +2,11 @@ package controller
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -1698,3 +1702,544 @@ collectLoop:
 	// Verify the ring doesn't contain revision 1 (the "failed" write)
 	assert.False(t, controller.recentWrites.Contains(1), "Failed write should not be recorded in ring")
 }
+
+func TestReconcileController_Backoff(t *testing.T) {
+	log := slog.New(slogfmt.NewTestHandler(t, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	store := entity.NewMockStore()
+	server := &entityserver.EntityServer{
+		Log:   log,
+		Store: store,
+	}
+
+	sc := &entityserver_v1alpha.EntityAccessClient{
+		Client: rpc.LocalClient(entityserver_v1alpha.AdaptEntityAccess(server)),
+	}
+
+	testIndex := entity.Any(entity.Type, "test/type")
+
+	const (
+		failures = 3
+		base     = 50 * time.Millisecond
+	)
+
+	var (
+		mu      sync.Mutex
+		calls   []time.Time
+		healthy atomic.Uint64
+	)
+
+	handler := func(ctx context.Context, event Event) ([]entity.Attr, error) {
+		if event.Id == "test/healthy" {
+			healthy.Add(1)
+			return nil, nil
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		calls = append(calls, time.Now())
+		if len(calls) <= failures {
+			return nil, fmt.Errorf("failure %d", len(calls))
+		}
+
+		return nil, nil
+	}
+
+	controller := NewReconcileController(
+		"test-controller",
+		log,
+		testIndex,
+		sc,
+		handler,
+		0, // no resync
+		1, // single worker
+	)
+	controller.SetBackoff(base, time.Second)
+
+	ctx := t.Context()
+
+	err := controller.Start(ctx)
+	require.NoError(t, err)
+	defer controller.Stop()
+
+	controller.Enqueue(Event{Type: EventUpdated, Id: "test/failing", Rev: 1})
+
+	// The first failure puts the entity into backoff
+	require.Eventually(t, func() bool {
+		states := controller.Backoffs()
+		return len(states) == 1 && states[0].Failures >= 1
+	}, 5*time.Second, time.Millisecond)
+
+	state := controller.Backoffs()[0]
+	assert.Equal(t, entity.Id("test/failing"), state.Id)
+
+	// Healthy entities are processed while the failing one waits, and events
+	// for the failing revision are held for its retry
+	controller.Enqueue(Event{Type: EventUpdated, Id: "test/healthy", Rev: 1})
+	controller.Enqueue(Event{Type: EventUpdated, Id: "test/failing", Rev: 1})
+
+	require.Eventually(t, func() bool {
+		return healthy.Load() == 1
+	}, 5*time.Second, time.Millisecond)
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(calls) == failures+1
+	}, 5*time.Second, 5*time.Millisecond, "handler should be retried until it succeeds")
+
+	// Success clears the backoff
+	require.Eventually(t, func() bool {
+		return len(controller.Backoffs()) == 0
+	}, time.Second, time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	require.Len(t, calls, failures+1, "held events shouldn't cause extra calls")
+
+	var prev time.Duration
+	for i := 1; i < len(calls); i++ {
+		gap := calls[i].Sub(calls[i-1])
+		want := base << (i - 1)
+
+		assert.GreaterOrEqual(t, gap, want, "retry %d came too soon", i)
+		assert.Greater(t, gap, prev, "retry %d didn't back off further", i)
+
+		prev = gap
+	}
+}
+
+func TestReconcileController_BackoffNewRevision(t *testing.T) {
+	log := slog.New(slogfmt.NewTestHandler(t, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	store := entity.NewMockStore()
+	server := &entityserver.EntityServer{
+		Log:   log,
+		Store: store,
+	}
+
+	sc := &entityserver_v1alpha.EntityAccessClient{
+		Client: rpc.LocalClient(entityserver_v1alpha.AdaptEntityAccess(server)),
+	}
+
+	testIndex := entity.Any(entity.Type, "test/type")
+
+	var (
+		mu      sync.Mutex
+		handled []int64
+	)
+
+	// Revision 1 has a broken spec, revision 2 fixes it
+	handler := func(ctx context.Context, event Event) ([]entity.Attr, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		handled = append(handled, event.Rev)
+		if event.Rev < 2 {
+			return nil, fmt.Errorf("bad spec at revision %d", event.Rev)
+		}
+
+		return nil, nil
+	}
+
+	controller := NewReconcileController(
+		"test-controller",
+		log,
+		testIndex,
+		sc,
+		handler,
+		0, // no resync
+		1, // single worker
+	)
+
+	// Long enough that only skipping the hold can get revision 2 handled
+	controller.SetBackoff(time.Minute, time.Hour)
+
+	ctx := t.Context()
+
+	err := controller.Start(ctx)
+	require.NoError(t, err)
+	defer controller.Stop()
+
+	controller.Enqueue(Event{Type: EventUpdated, Id: "test/fixed", Rev: 1})
+
+	require.Eventually(t, func() bool {
+		return len(controller.Backoffs()) == 1
+	}, 5*time.Second, time.Millisecond)
+
+	// The same revision waits out the backoff
+	controller.Enqueue(Event{Type: EventUpdated, Id: "test/fixed", Rev: 1})
+
+	// The fix is reconciled right away and clears the backoff
+	controller.Enqueue(Event{Type: EventUpdated, Id: "test/fixed", Rev: 2})
+
+	require.Eventually(t, func() bool {
+		return len(controller.Backoffs()) == 0
+	}, 5*time.Second, time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	assert.Equal(t, []int64{1, 2}, handled)
+}
+
+func TestBackoff_Hold(t *testing.T) {
+	b := NewBackoff(time.Minute, time.Hour, func(Event) {})
+	defer b.Stop()
+
+	fail := func() {
+		b.Failed(Event{Type: EventUpdated, Id: "test/a", Rev: 5})
+	}
+
+	fail()
+	assert.True(t, b.Hold(Event{Type: EventUpdated, Id: "test/a", Rev: 5}), "same revision should be held")
+	assert.True(t, b.Hold(Event{Type: EventUpdated, Id: "test/a", Rev: 4}), "older revision should be held")
+	assert.False(t, b.Hold(Event{Type: EventUpdated, Id: "test/b", Rev: 1}), "other entities aren't held")
+
+	assert.False(t, b.Hold(Event{Type: EventUpdated, Id: "test/a", Rev: 6}), "newer revision should skip the hold")
+	assert.Empty(t, b.State(), "newer revision should reset the backoff")
+
+	fail()
+	assert.False(t, b.Hold(Event{Type: EventDeleted, Id: "test/a", Rev: 5}), "deletes should skip the hold")
+	assert.Empty(t, b.State(), "deletes should reset the backoff")
+}
+
+func TestBackoff_Delay(t *testing.T) {
+	b := NewBackoff(time.Second, 10*time.Second, func(Event) {})
+
+	assert.Equal(t, time.Second, b.Delay(1))
+	assert.Equal(t, 2*time.Second, b.Delay(2))
+	assert.Equal(t, 4*time.Second, b.Delay(3))
+	assert.Equal(t, 8*time.Second, b.Delay(4))
+	assert.Equal(t, 10*time.Second, b.Delay(5))
+	assert.Equal(t, 10*time.Second, b.Delay(100))
+}
+
+func TestReconcileController_DeleteHandler(t *testing.T) {
+	log := slog.New(slogfmt.NewTestHandler(t, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	store := entity.NewMockStore()
+	server := &entityserver.EntityServer{
+		Log:   log,
+		Store: store,
+	}
+
+	sc := &entityserver_v1alpha.EntityAccessClient{
+		Client: rpc.LocalClient(entityserver_v1alpha.AdaptEntityAccess(server)),
+	}
+
+	testIndex := entity.Any(entity.Type, "test/type")
+
+	reconciled := make(chan Event, 10)
+	handler := func(ctx context.Context, event Event) ([]entity.Attr, error) {
+		reconciled <- event
+		return nil, nil
+	}
+
+	var (
+		mu      sync.Mutex
+		deletes []entity.Id
+	)
+
+	// External state that's only cleaned up on the third try
+	deleted := make(chan entity.Id, 1)
+	onDelete := func(ctx context.Context, id entity.Id) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		deletes = append(deletes, id)
+		if len(deletes) < 3 {
+			return fmt.Errorf("cleanup failed")
+		}
+
+		deleted <- id
+		return nil
+	}
+
+	controller := NewReconcileController(
+		"test-controller",
+		log,
+		testIndex,
+		sc,
+		handler,
+		0, // no resync
+		1, // single worker
+	)
+	controller.SetBackoff(10*time.Millisecond, 100*time.Millisecond)
+	controller.SetDeleteHandler(onDelete)
+
+	ctx := t.Context()
+
+	err := controller.Start(ctx)
+	require.NoError(t, err)
+	defer controller.Stop()
+
+	err = store.WaitForIndexWatcher(ctx, testIndex)
+	require.NoError(t, err)
+
+	_, err = store.CreateEntity(ctx, entity.New(
+		entity.Ref(entity.DBId, "test/entity1"),
+		entity.String(entity.Type, "test/type"),
+	))
+	require.NoError(t, err)
+
+	select {
+	case event := <-reconciled:
+		assert.Equal(t, EventAdded, event.Type)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for create")
+	}
+
+	err = store.DeleteEntity(ctx, "test/entity1")
+	require.NoError(t, err)
+
+	select {
+	case id := <-deleted:
+		assert.Equal(t, entity.Id("test/entity1"), id)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for delete handler to succeed")
+	}
+
+	mu.Lock()
+	assert.Equal(t, []entity.Id{"test/entity1", "test/entity1", "test/entity1"}, deletes)
+	mu.Unlock()
+
+	assert.Empty(t, controller.Backoffs())
+	assert.Empty(t, reconciled, "the reconcile handler shouldn't see the delete")
+}
+
+func TestReconcileController_ConflictRetries(t *testing.T) {
+	log := slog.New(slogfmt.NewTestHandler(t, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	setup := func(t *testing.T, handler HandlerFunc) (*entity.MockStore, *ReconcileController, Event) {
+		store := entity.NewMockStore()
+		server := &entityserver.EntityServer{
+			Log:   log,
+			Store: store,
+		}
+
+		sc := &entityserver_v1alpha.EntityAccessClient{
+			Client: rpc.LocalClient(entityserver_v1alpha.AdaptEntityAccess(server)),
+		}
+
+		en, err := store.CreateEntity(t.Context(), entity.New(
+			entity.Ref(entity.DBId, "test/entity1"),
+			entity.String(entity.Type, "test/type"),
+			entity.String("count", "0"),
+		))
+		require.NoError(t, err)
+
+		controller := NewReconcileController(
+			"test-controller",
+			log,
+			entity.Any(entity.Type, "test/type"),
+			sc,
+			handler,
+			0, // no resync
+			1, // single worker
+		)
+
+		return store, controller, Event{
+			Type:   EventUpdated,
+			Id:     en.Id(),
+			Entity: en,
+			Rev:    en.GetRevision(),
+		}
+	}
+
+	// A handler that bumps count, racing another writer that also bumps it
+	// the first few times it runs
+	racing := func(store **entity.MockStore, races int, seen *[]int64) HandlerFunc {
+		return func(ctx context.Context, event Event) ([]entity.Attr, error) {
+			*seen = append(*seen, event.Rev)
+
+			attr, _ := event.Entity.Get("count")
+			count, _ := strconv.Atoi(attr.Value.String())
+
+			if len(*seen) <= races {
+				_, err := (*store).UpdateEntity(ctx, event.Id, entity.New(
+					entity.String("count", strconv.Itoa(count+1)),
+				))
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			return []entity.Attr{entity.String("count", strconv.Itoa(count+1))}, nil
+		}
+	}
+
+	count := func(t *testing.T, store *entity.MockStore) string {
+		en, err := store.GetEntity(t.Context(), "test/entity1")
+		require.NoError(t, err)
+
+		attr, _ := en.Get("count")
+		return attr.Value.String()
+	}
+
+	t.Run("refetches and reruns the handler on conflict", func(t *testing.T) {
+		var (
+			store *entity.MockStore
+			seen  []int64
+		)
+
+		store, controller, ev := setup(t, racing(&store, 1, &seen))
+		controller.SetConflictRetries(3)
+
+		err := controller.ProcessEventForTest(t.Context(), ev)
+		require.NoError(t, err)
+
+		// The second run saw the other writer's update, so neither is lost
+		require.Len(t, seen, 2)
+		assert.Equal(t, ev.Rev, seen[0])
+		assert.Greater(t, seen[1], seen[0])
+		assert.Equal(t, "2", count(t, store))
+	})
+
+	t.Run("gives up after the configured retries", func(t *testing.T) {
+		var (
+			store *entity.MockStore
+			seen  []int64
+		)
+
+		store, controller, ev := setup(t, racing(&store, 10, &seen))
+		controller.SetConflictRetries(2)
+
+		err := controller.ProcessEventForTest(t.Context(), ev)
+		require.NoError(t, err)
+
+		assert.Len(t, seen, 3)
+		assert.Equal(t, "3", count(t, store), "only the other writer's updates should land")
+	})
+
+	t.Run("writes blindly by default", func(t *testing.T) {
+		var (
+			store *entity.MockStore
+			seen  []int64
+		)
+
+		store, controller, ev := setup(t, racing(&store, 1, &seen))
+
+		err := controller.ProcessEventForTest(t.Context(), ev)
+		require.NoError(t, err)
+
+		assert.Len(t, seen, 1)
+		assert.Equal(t, "1", count(t, store), "the other writer's update is clobbered")
+	})
+}
+
+func TestControllerManager_Health(t *testing.T) {
+	log := slog.New(slogfmt.NewTestHandler(t, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	store := entity.NewMockStore()
+	server := &entityserver.EntityServer{
+		Log:   log,
+		Store: store,
+	}
+
+	sc := &entityserver_v1alpha.EntityAccessClient{
+		Client: rpc.LocalClient(entityserver_v1alpha.AdaptEntityAccess(server)),
+	}
+
+	// The stalled controller's handler blocks until released
+	release := make(chan struct{})
+	stalledStarted := make(chan struct{}, 10)
+
+	stalled := NewReconcileController("stalled", log, entity.Any(entity.Type, "test/stalled"), sc,
+		func(ctx context.Context, event Event) ([]entity.Attr, error) {
+			stalledStarted <- struct{}{}
+			<-release
+			return nil, nil
+		}, 0, 1)
+
+	var calls atomic.Int32
+
+	healthy := NewReconcileController("healthy", log, entity.Any(entity.Type, "test/healthy"), sc,
+		func(ctx context.Context, event Event) ([]entity.Attr, error) {
+			// Every other reconcile fails
+			if calls.Add(1)%2 == 0 {
+				return nil, fmt.Errorf("reconcile failed")
+			}
+			return nil, nil
+		}, 0, 1)
+	healthy.SetBackoff(0, 0)
+
+	m := NewControllerManager()
+	m.AddController(stalled)
+	m.AddController(healthy)
+
+	ctx := t.Context()
+
+	require.NoError(t, m.Start(ctx))
+	defer func() {
+		close(release)
+		m.Stop()
+	}()
+
+	const stallAfter = 100 * time.Millisecond
+
+	health := m.Health()
+	require.Len(t, health, 2)
+
+	for name, h := range health {
+		assert.False(t, h.Wedged(time.Now(), stallAfter), "%s is wedged before doing anything", name)
+	}
+
+	stalled.Enqueue(Event{Type: EventUpdated, Id: "test/a"})
+	stalled.Enqueue(Event{Type: EventUpdated, Id: "test/b"})
+
+	select {
+	case <-stalledStarted:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for the stalled handler")
+	}
+
+	for i := range 4 {
+		healthy.Enqueue(Event{Type: EventUpdated, Id: entity.Id(fmt.Sprintf("test/%d", i))})
+	}
+
+	require.Eventually(t, func() bool {
+		return calls.Load() == 4
+	}, 5*time.Second, 10*time.Millisecond)
+
+	time.Sleep(2 * stallAfter)
+
+	health = m.Health()
+
+	sh := health["stalled"]
+	assert.Equal(t, 1, sh.InFlight)
+	assert.Equal(t, 1, sh.QueueDepth)
+	assert.True(t, sh.LastSuccess.IsZero())
+	assert.True(t, sh.Wedged(time.Now(), stallAfter), "the stalled controller should be wedged")
+
+	require.Eventually(t, func() bool {
+		return m.Health()["healthy"].InFlight == 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	hh := m.Health()["healthy"]
+	assert.Equal(t, int64(2), hh.Errors)
+	assert.Zero(t, hh.QueueDepth)
+	assert.False(t, hh.LastSuccess.IsZero())
+	assert.False(t, hh.Wedged(time.Now(), stallAfter), "an idle controller isn't wedged")
+
+	rec := httptest.NewRecorder()
+	m.HealthHandler(stallAfter).ServeHTTP(rec, httptest.NewRequest("GET", "/healthz", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	var resp struct {
+		Status      string `json:"status"`
+		Controllers map[string]struct {
+			Wedged bool  `json:"wedged"`
+			Errors int64 `json:"errors"`
+		} `json:"controllers"`
+	}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+
+	assert.Equal(t, "wedged", resp.Status)
+	assert.True(t, resp.Controllers["stalled"].Wedged)
+	assert.False(t, resp.Controllers["healthy"].Wedged)
+	assert.Equal(t, int64(2), resp.Controllers["healthy"].Errors)
+}
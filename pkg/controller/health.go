@@ -0,0 +1,143 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// ControllerHealth is a snapshot of how well a controller is keeping up
+// with its work.
+type ControllerHealth struct {
+	// LastSuccess is when the controller last reconciled an entity without
+	// an error, zero if it hasn't yet.
+	LastSuccess time.Time `json:"last_success"`
+
+	// LastProcessed is when the controller last finished reconciling an
+	// entity, with or without an error, or when it started if it hasn't yet.
+	LastProcessed time.Time `json:"last_processed"`
+
+	// QueueDepth is how many events are waiting to be processed.
+	QueueDepth int `json:"queue_depth"`
+
+	// InFlight is how many entities are being reconciled right now.
+	InFlight int `json:"in_flight"`
+
+	// Errors is how many reconciles have failed since the controller started.
+	Errors int64 `json:"errors"`
+}
+
+// Wedged reports whether the controller has work waiting but hasn't
+// finished reconciling anything for longer than stallAfter.
+func (h ControllerHealth) Wedged(now time.Time, stallAfter time.Duration) bool {
+	return (h.QueueDepth > 0 || h.InFlight > 0) && now.Sub(h.LastProcessed) > stallAfter
+}
+
+// HealthReporter is implemented by controllers that report their health to
+// the ControllerManager.
+type HealthReporter interface {
+	Name() string
+	Health() ControllerHealth
+}
+
+// healthCounters are updated by workers as they finish reconciling, and
+// read without locking by Health.
+type healthCounters struct {
+	lastSuccess   atomic.Int64
+	lastProcessed atomic.Int64
+	errors        atomic.Int64
+}
+
+func (h *healthCounters) record(now time.Time, err error) {
+	h.lastProcessed.Store(now.UnixNano())
+
+	if err != nil {
+		h.errors.Add(1)
+	} else {
+		h.lastSuccess.Store(now.UnixNano())
+	}
+}
+
+func unixNano(ns int64) time.Time {
+	if ns == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(0, ns)
+}
+
+// Name returns the name the controller was created with.
+func (c *ReconcileController) Name() string {
+	return c.name
+}
+
+// Health returns a snapshot of the controller's health.
+func (c *ReconcileController) Health() ControllerHealth {
+	h := ControllerHealth{
+		LastSuccess:   unixNano(c.health.lastSuccess.Load()),
+		LastProcessed: unixNano(c.health.lastProcessed.Load()),
+		QueueDepth:    len(c.workQueue),
+		Errors:        c.health.errors.Load(),
+	}
+
+	c.inFlightMu.Lock()
+	h.InFlight = len(c.inFlight)
+	for _, entry := range c.inFlight {
+		h.QueueDepth += len(entry.pendingEvents)
+	}
+	c.inFlightMu.Unlock()
+
+	return h
+}
+
+// Health returns the health of each controller that reports it, by name.
+func (m *ControllerManager) Health() map[string]ControllerHealth {
+	health := make(map[string]ControllerHealth)
+
+	for _, controller := range m.controllers {
+		if hr, ok := controller.(HealthReporter); ok {
+			health[hr.Name()] = hr.Health()
+		}
+	}
+
+	return health
+}
+
+// HealthHandler returns a handler for serving the controllers' health, such
+// as on /healthz. It responds 503 if any controller is wedged, having had
+// work waiting without finishing a reconcile for longer than stallAfter.
+func (m *ControllerManager) HealthHandler(stallAfter time.Duration) http.Handler {
+	type controllerStatus struct {
+		ControllerHealth
+		Wedged bool `json:"wedged"`
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		now := time.Now()
+
+		resp := struct {
+			Status      string                      `json:"status"`
+			Controllers map[string]controllerStatus `json:"controllers"`
+		}{
+			Status:      "ok",
+			Controllers: make(map[string]controllerStatus),
+		}
+
+		for name, h := range m.Health() {
+			wedged := h.Wedged(now, stallAfter)
+			if wedged {
+				resp.Status = "wedged"
+			}
+
+			resp.Controllers[name] = controllerStatus{ControllerHealth: h, Wedged: wedged}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if resp.Status != "ok" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+
+		json.NewEncoder(w).Encode(resp)
+	})
+}
@@ -0,0 +1,386 @@
+package entity
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mr-tron/base58"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// Change is a single change to an entity, as streamed by a ChangeEmitter.
+type Change struct {
+	Type EntityOpType
+	Id   Id
+
+	// Revision is the store revision the change was made at. Changes are
+	// emitted in increasing revision order, and several changes made in one
+	// transaction share a revision.
+	Revision int64
+
+	// Before is the entity prior to the change, nil for a create
+	Before *Entity
+
+	// After is the entity following the change, nil for a delete
+	After *Entity
+}
+
+// ChangeSink receives changes from a ChangeEmitter, such as by publishing
+// them to a message queue or webhook. Sinks partitioning changes, like onto
+// Kafka partitions, should do so by Id to keep each entity's changes in order.
+type ChangeSink interface {
+	// Deliver is called with batches of changes in order. If it returns an
+	// error the whole batch is delivered again, so sinks must tolerate
+	// receiving a change more than once.
+	Deliver(ctx context.Context, changes []Change) error
+}
+
+// ChangeSinkFunc adapts a function to a ChangeSink.
+type ChangeSinkFunc func(ctx context.Context, changes []Change) error
+
+func (f ChangeSinkFunc) Deliver(ctx context.Context, changes []Change) error {
+	return f(ctx, changes)
+}
+
+// ChangeCursor durably records the revision of the last change a sink has
+// accepted, so that an emitter can resume where it left off.
+type ChangeCursor interface {
+	// Load returns the saved revision, or 0 if none has been saved.
+	Load(ctx context.Context) (int64, error)
+	Save(ctx context.Context, revision int64) error
+}
+
+// ErrChangesCompacted is returned by ChangeEmitter.Run when changes after the
+// cursor have been compacted away, so they can no longer be delivered.
+var ErrChangesCompacted = errors.New("changes after the cursor have been compacted")
+
+// ChangeEmitter streams every entity change in a store to a ChangeSink, with
+// at-least-once delivery. Changes are delivered in the order they were made,
+// and the next batch isn't read until the sink accepts the current one, so a
+// slow sink holds back the stream rather than losing changes.
+type ChangeEmitter struct {
+	log    *slog.Logger
+	store  *EtcdStore
+	sink   ChangeSink
+	cursor ChangeCursor
+
+	maxBatch   int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+}
+
+type ChangeEmitterOption func(*ChangeEmitter)
+
+// WithMaxBatch limits how many changes are passed to the sink at once. The
+// default is 100.
+func WithMaxBatch(n int) ChangeEmitterOption {
+	return func(e *ChangeEmitter) {
+		e.maxBatch = n
+	}
+}
+
+// WithDeliveryBackoff sets the bounds of the delay between attempts to
+// deliver a batch the sink failed. The defaults are 100ms and 30s.
+func WithDeliveryBackoff(minDelay, maxDelay time.Duration) ChangeEmitterOption {
+	return func(e *ChangeEmitter) {
+		e.minBackoff = minDelay
+		e.maxBackoff = maxDelay
+	}
+}
+
+// NewChangeEmitter creates an emitter of the store's changes to sink,
+// resuming from cursor. A cursor that has never been saved starts with the
+// changes made once Run is called.
+func (s *EtcdStore) NewChangeEmitter(sink ChangeSink, cursor ChangeCursor, opts ...ChangeEmitterOption) *ChangeEmitter {
+	e := &ChangeEmitter{
+		log:        s.log.With("component", "change-emitter"),
+		store:      s,
+		sink:       sink,
+		cursor:     cursor,
+		maxBatch:   100,
+		minBackoff: 100 * time.Millisecond,
+		maxBackoff: 30 * time.Second,
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	return e
+}
+
+// Run streams changes until ctx is done or the changes can't be read.
+func (e *ChangeEmitter) Run(ctx context.Context) error {
+	rev, err := e.cursor.Load(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load change cursor: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	prefix := e.store.prefix + "/entity/"
+
+	opts := []clientv3.OpOption{clientv3.WithPrefix(), clientv3.WithPrevKV()}
+	if rev > 0 {
+		opts = append(opts, clientv3.WithRev(rev+1))
+	}
+
+	wc := e.store.client.Watch(clientv3.WithRequireLeader(ctx), prefix, opts...)
+
+	e.log.Info("streaming entity changes", "after_revision", rev)
+
+	for {
+		var wr clientv3.WatchResponse
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case r, ok := <-wc:
+			if !ok {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				return fmt.Errorf("entity watch closed")
+			}
+			wr = r
+		}
+
+		if wr.CompactRevision != 0 {
+			return fmt.Errorf("%w: cursor at %d, compacted through %d", ErrChangesCompacted, rev, wr.CompactRevision)
+		}
+
+		if err := wr.Err(); err != nil {
+			return fmt.Errorf("failed to watch entities: %w", err)
+		}
+
+		changes := e.changes(ctx, prefix, wr.Events)
+
+		for len(changes) > 0 {
+			n := min(len(changes), e.maxBatch)
+
+			if err := e.deliver(ctx, changes[:n]); err != nil {
+				return err
+			}
+
+			changes = changes[n:]
+		}
+
+		// The cursor only moves once the whole response is delivered, so a
+		// restart may deliver part of it again but never skips a change.
+		// Events for session attributes produce no changes but still move it.
+		for _, ev := range wr.Events {
+			rev = max(rev, ev.Kv.ModRevision)
+		}
+
+		if err := e.cursor.Save(ctx, rev); err != nil {
+			return fmt.Errorf("failed to save change cursor: %w", err)
+		}
+	}
+}
+
+// deliver passes a batch to the sink until it is accepted or ctx is done.
+func (e *ChangeEmitter) deliver(ctx context.Context, changes []Change) error {
+	backoff := e.minBackoff
+
+	for {
+		err := e.sink.Deliver(ctx, changes)
+		if err == nil {
+			return nil
+		}
+
+		e.log.Warn("failed to deliver entity changes, retrying",
+			"changes", len(changes),
+			"first_revision", changes[0].Revision,
+			"retry_in", backoff,
+			"error", err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff = min(backoff*2, e.maxBackoff)
+	}
+}
+
+// changes converts the events of a watch response into changes, skipping
+// those for keys other than entities.
+func (e *ChangeEmitter) changes(ctx context.Context, prefix string, events []*clientv3.Event) []Change {
+	var ret []Change
+
+	for _, ev := range events {
+		key := strings.TrimPrefix(string(ev.Kv.Key), prefix)
+
+		// Session attributes are stored under the entity's key
+		if strings.IndexByte(key, '/') != -1 {
+			continue
+		}
+
+		rawId, err := base58.Decode(key)
+		if err != nil {
+			e.log.Error("failed to decode entity key", "key", string(ev.Kv.Key), "error", err)
+			continue
+		}
+
+		ch := Change{
+			Id:       Id(rawId),
+			Revision: ev.Kv.ModRevision,
+		}
+
+		switch {
+		case ev.Type == clientv3.EventTypeDelete:
+			ch.Type = EntityOpDelete
+		case ev.IsCreate():
+			ch.Type = EntityOpCreate
+		default:
+			ch.Type = EntityOpUpdate
+		}
+
+		if ev.PrevKv != nil {
+			ch.Before = e.decode(ctx, ev.PrevKv)
+		}
+
+		if ev.Type != clientv3.EventTypeDelete {
+			ch.After = e.decode(ctx, ev.Kv)
+		}
+
+		ret = append(ret, ch)
+	}
+
+	return ret
+}
+
+func (e *ChangeEmitter) decode(ctx context.Context, kv *mvccpb.KeyValue) *Entity {
+	var entity Entity
+
+	if err := decoder.Unmarshal(kv.Value, &entity); err != nil {
+		e.log.Error("failed to decode entity for change", "key", string(kv.Key), "error", err)
+		return nil
+	}
+
+	if err := e.store.openAttrs(ctx, entity.attrs); err != nil {
+		e.log.Error("failed to decrypt entity for change", "key", string(kv.Key), "error", err)
+		return nil
+	}
+
+	entity.SetRevision(kv.ModRevision)
+	entity.postUnmarshal()
+
+	return &entity
+}
+
+// ChangeCursor returns a cursor stored in the store's etcd under name. Each
+// consumer of changes should use its own name.
+func (s *EtcdStore) ChangeCursor(name string) ChangeCursor {
+	return &etcdChangeCursor{
+		client: s.client,
+		key:    fmt.Sprintf("%s/cdc-cursor/%s", s.prefix, name),
+	}
+}
+
+type etcdChangeCursor struct {
+	client *clientv3.Client
+	key    string
+}
+
+func (c *etcdChangeCursor) Load(ctx context.Context) (int64, error) {
+	resp, err := c.client.Get(ctx, c.key)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(resp.Kvs) == 0 {
+		return 0, nil
+	}
+
+	return strconv.ParseInt(string(resp.Kvs[0].Value), 10, 64)
+}
+
+func (c *etcdChangeCursor) Save(ctx context.Context, revision int64) error {
+	_, err := c.client.Put(ctx, c.key, strconv.FormatInt(revision, 10))
+	return err
+}
+
+// WebhookSink delivers changes by POSTing them as JSON to a URL. Each
+// request body is an array of changes, and any status other than 2xx fails
+// the batch so that it is sent again.
+type WebhookSink struct {
+	URL    string
+	Client *http.Client
+}
+
+type webhookChange struct {
+	Type     string `json:"type"`
+	Id       Id     `json:"id"`
+	Revision int64  `json:"revision"`
+	Before   []Attr `json:"before,omitempty"`
+	After    []Attr `json:"after,omitempty"`
+}
+
+var changeTypeNames = map[EntityOpType]string{
+	EntityOpCreate: "create",
+	EntityOpUpdate: "update",
+	EntityOpDelete: "delete",
+}
+
+func (w *WebhookSink) Deliver(ctx context.Context, changes []Change) error {
+	body := make([]webhookChange, 0, len(changes))
+
+	for _, ch := range changes {
+		wc := webhookChange{
+			Type:     changeTypeNames[ch.Type],
+			Id:       ch.Id,
+			Revision: ch.Revision,
+		}
+
+		if ch.Before != nil {
+			wc.Before = ch.Before.Attrs()
+		}
+
+		if ch.After != nil {
+			wc.After = ch.After.Attrs()
+		}
+
+		body = append(body, wc)
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode changes: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	client := w.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with %s", resp.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,162 @@
+package entity
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// recordingSink records delivered changes, failing the first failures
+// deliveries.
+type recordingSink struct {
+	mu       sync.Mutex
+	failures int
+	attempts int
+	changes  []Change
+}
+
+func (s *recordingSink) Deliver(ctx context.Context, changes []Change) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.attempts++
+
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("sink unavailable")
+	}
+
+	s.changes = append(s.changes, changes...)
+	return nil
+}
+
+func (s *recordingSink) delivered() []Change {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Change(nil), s.changes...)
+}
+
+type changeSummary struct {
+	Type      EntityOpType
+	Id        Id
+	HasBefore bool
+	HasAfter  bool
+}
+
+func summarizeChanges(changes []Change) []changeSummary {
+	var ret []changeSummary
+	for _, ch := range changes {
+		ret = append(ret, changeSummary{ch.Type, ch.Id, ch.Before != nil, ch.After != nil})
+	}
+	return ret
+}
+
+func TestChangeEmitter(t *testing.T) {
+	r := require.New(t)
+	ctx := t.Context()
+
+	client := setupTestEtcd(t)
+	store, err := NewEtcdStore(ctx, slog.Default(), client, "/test-entities")
+	r.NoError(err)
+
+	cursor := store.ChangeCursor("test")
+
+	// Start the cursor at the current revision so only the changes below are
+	// delivered
+	resp, err := client.Get(ctx, "/test-entities/")
+	r.NoError(err)
+	r.NoError(cursor.Save(ctx, resp.Header.Revision))
+
+	a, err := store.CreateEntity(ctx, New(String(Ident, "test/cdc-a"), String(Doc, "first")))
+	r.NoError(err)
+
+	_, err = store.UpdateEntity(ctx, a.Id(), New(String(Doc, "second")))
+	r.NoError(err)
+
+	b, err := store.CreateEntity(ctx, New(String(Ident, "test/cdc-b"), String(Doc, "other")))
+	r.NoError(err)
+
+	r.NoError(store.DeleteEntity(ctx, a.Id()))
+
+	runEmitter := func(sink ChangeSink) (stop func()) {
+		ctx, cancel := context.WithCancel(ctx)
+		done := make(chan error, 1)
+
+		em := store.NewChangeEmitter(sink, cursor, WithDeliveryBackoff(10*time.Millisecond, 50*time.Millisecond))
+		go func() {
+			done <- em.Run(ctx)
+		}()
+
+		return func() {
+			cancel()
+			r.ErrorIs(<-done, context.Canceled)
+		}
+	}
+
+	waitForCursor := func(rev int64) {
+		r.Eventually(func() bool {
+			cur, err := cursor.Load(ctx)
+			return err == nil && cur >= rev
+		}, 5*time.Second, 10*time.Millisecond)
+	}
+
+	t.Run("delivers all changes in order despite sink failures", func(t *testing.T) {
+		sink := &recordingSink{failures: 2}
+		stop := runEmitter(sink)
+
+		require.Eventually(t, func() bool {
+			return len(sink.delivered()) >= 4
+		}, 5*time.Second, 10*time.Millisecond)
+
+		got := sink.delivered()
+		require.Equal(t, []changeSummary{
+			{EntityOpCreate, a.Id(), false, true},
+			{EntityOpUpdate, a.Id(), true, true},
+			{EntityOpCreate, b.Id(), false, true},
+			{EntityOpDelete, a.Id(), true, false},
+		}, summarizeChanges(got))
+
+		for i := 1; i < len(got); i++ {
+			require.Greater(t, got[i].Revision, got[i-1].Revision)
+		}
+
+		doc, ok := got[1].Before.Get(Doc)
+		require.True(t, ok)
+		require.Equal(t, "first", doc.Value.String())
+
+		doc, ok = got[1].After.Get(Doc)
+		require.True(t, ok)
+		require.Equal(t, "second", doc.Value.String())
+
+		require.Greater(t, sink.attempts, 2, "failed batches should be redelivered")
+
+		waitForCursor(got[3].Revision)
+		stop()
+	})
+
+	t.Run("resumes from the cursor after a restart", func(t *testing.T) {
+		// Made while no emitter is running
+		_, err := store.UpdateEntity(ctx, b.Id(), New(String(Doc, "changed")))
+		require.NoError(t, err)
+		require.NoError(t, store.DeleteEntity(ctx, b.Id()))
+
+		sink := &recordingSink{}
+		stop := runEmitter(sink)
+		defer stop()
+
+		require.Eventually(t, func() bool {
+			return len(sink.delivered()) >= 2
+		}, 5*time.Second, 10*time.Millisecond)
+
+		require.Equal(t, []changeSummary{
+			{EntityOpUpdate, b.Id(), true, true},
+			{EntityOpDelete, b.Id(), true, false},
+		}, summarizeChanges(sink.delivered()))
+	})
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDeepCopyGeneration(t *testing.T) {
+	sf := &schemaFile{
+		Domain:  "test",
+		Version: "v1",
+		Components: map[string]schemaAttrs{
+			"mount": {
+				"path": &schemaAttr{
+					Type: "string",
+					Doc:  "Where to mount",
+				},
+			},
+		},
+		Kinds: map[string]schemaAttrs{
+			"widget": {
+				"name": &schemaAttr{
+					Type: "string",
+					Doc:  "The widget name",
+				},
+				"tags": &schemaAttr{
+					Type: "string",
+					Doc:  "Tags on the widget",
+					Many: true,
+				},
+				"labels": &schemaAttr{
+					Type: "label",
+					Doc:  "Labels on the widget",
+					Many: true,
+				},
+				"data": &schemaAttr{
+					Type: "bytes",
+					Doc:  "Opaque data",
+				},
+				"mount": &schemaAttr{
+					Type: "mount",
+					Doc:  "Mounts in the widget",
+					Many: true,
+				},
+				"spec": &schemaAttr{
+					Type: "component",
+					Doc:  "The widget spec",
+					Attrs: map[string]*schemaAttr{
+						"args": {
+							Type: "string",
+							Doc:  "Arguments",
+							Many: true,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	code, err := GenerateSchema(sf, "test")
+	if err != nil {
+		t.Fatalf("Failed to generate schema: %v", err)
+	}
+
+	deepCopy := func(structName string) string {
+		start := strings.Index(code, "func (o *"+structName+") DeepCopy() *"+structName+" {")
+		if start == -1 {
+			t.Fatalf("Could not find DeepCopy() method for %s in generated code:\n%s", structName, code)
+		}
+
+		end := strings.Index(code[start:], "\n}")
+		return code[start : start+end]
+	}
+
+	widget := deepCopy("Widget")
+
+	for _, want := range []string{
+		"out := *o",
+		"out.Tags = slices.Clone(o.Tags)",
+		"out.Labels = slices.Clone(o.Labels)",
+		"out.Data = slices.Clone(o.Data)",
+		"out.Mount = make([]Mount, len(o.Mount))",
+		"out.Mount[i] = *o.Mount[i].DeepCopy()",
+		"out.Spec = *o.Spec.DeepCopy()",
+		"return &out",
+	} {
+		if !strings.Contains(widget, want) {
+			t.Errorf("Widget.DeepCopy() should contain %q", want)
+			t.Logf("DeepCopy() method:\n%s", widget)
+		}
+	}
+
+	if strings.Contains(widget, "o.Name") {
+		t.Error("DeepCopy() should copy value fields along with the struct")
+	}
+
+	if !strings.Contains(deepCopy("Spec"), "out.Args = slices.Clone(o.Args)") {
+		t.Error("Spec.DeepCopy() should clone its args")
+	}
+
+	deepCopy("Mount")
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFinderGeneration(t *testing.T) {
+	sf := &schemaFile{
+		Domain:  "test",
+		Version: "v1",
+		Components: map[string]schemaAttrs{
+			"mount": {
+				"path": &schemaAttr{
+					Type:    "string",
+					Doc:     "Where to mount",
+					Indexed: true,
+				},
+			},
+		},
+		Kinds: map[string]schemaAttrs{
+			"box": {
+				"name": &schemaAttr{
+					Type:    "string",
+					Doc:     "The box name",
+					Indexed: true,
+				},
+				"owner": &schemaAttr{
+					Type:    "ref",
+					Doc:     "Who owns the box",
+					Indexed: true,
+				},
+				"default": &schemaAttr{
+					Type:    "bool",
+					Doc:     "Whether it's the default box",
+					Indexed: true,
+				},
+				"state": &schemaAttr{
+					Type:    "enum",
+					Doc:     "The box state",
+					Choices: []string{"open", "closed"},
+					Indexed: true,
+				},
+				"color": &schemaAttr{
+					Type: "string",
+					Doc:  "Not indexed",
+				},
+			},
+			"policy": {
+				"name": &schemaAttr{
+					Type:    "string",
+					Doc:     "The policy name",
+					Indexed: true,
+				},
+			},
+		},
+	}
+
+	code, err := GenerateSchema(sf, "test")
+	if err != nil {
+		t.Fatalf("Failed to generate schema: %v", err)
+	}
+
+	for _, want := range []string{
+		"func FindBoxesByName(ctx context.Context, store entity.IndexLister, name string) ([]entity.Id, error) {",
+		"return store.ListIndex(ctx, entity.String(BoxNameId, name))",
+		"func FindBoxesByOwner(ctx context.Context, store entity.IndexLister, owner entity.Id) ([]entity.Id, error) {",
+		"return store.ListIndex(ctx, entity.Ref(BoxOwnerId, owner))",
+		"func FindBoxesByDefault(ctx context.Context, store entity.IndexLister, value bool) ([]entity.Id, error) {",
+		"func FindBoxesByState(ctx context.Context, store entity.IndexLister, state BoxState) ([]entity.Id, error) {",
+		"id, ok := boxstateToId[state]",
+		"return store.ListIndex(ctx, entity.Ref(BoxStateId, id))",
+		"func FindPoliciesByName(",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("Generated code missing %q:\n%s", want, code)
+		}
+	}
+
+	for _, unwanted := range []string{
+		"FindBoxesByColor",
+		"FindMountsByPath",
+	} {
+		if strings.Contains(code, unwanted) {
+			t.Errorf("Generated code should not contain %q", unwanted)
+		}
+	}
+}
+
+func TestPlural(t *testing.T) {
+	for in, want := range map[string]string{
+		"Node":      "Nodes",
+		"Box":       "Boxes",
+		"Policy":    "Policies",
+		"Key":       "Keys",
+		"Endpoints": "Endpoints",
+	} {
+		if got := plural(in); got != want {
+			t.Errorf("plural(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
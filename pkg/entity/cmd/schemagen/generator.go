@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"compress/gzip"
 	"fmt"
+	"go/token"
+	"slices"
 	"strings"
 
 	j "github.com/dave/jennifer/jen"
@@ -29,16 +31,17 @@ type schemaFile struct {
 type schemaAttrs map[string]*schemaAttr
 
 type schemaAttr struct {
-	Type     string   `yaml:"type"`
-	Doc      string   `yaml:"doc"`
-	Attr     string   `yaml:"attr,omitempty"`     // for attribute name
-	Many     bool     `yaml:"many,omitempty"`     // for repeated attributes
-	Required bool     `yaml:"required,omitempty"` // for required attributes
-	Choices  []string `yaml:"choices,omitempty"`  // for enum attributes
-	Indexed  bool     `yaml:"indexed,omitempty"`  // for indexed attributes
-	Session  bool     `yaml:"session,omitempty"`  // for session attributes
-	BindTo   string   `yaml:"bind_to,omitempty"`  // for binding to other attributes
-	Tags     []string `yaml:"tags,omitempty"`     // for attribute tags
+	Type      string   `yaml:"type"`
+	Doc       string   `yaml:"doc"`
+	Attr      string   `yaml:"attr,omitempty"`      // for attribute name
+	Many      bool     `yaml:"many,omitempty"`      // for repeated attributes
+	Required  bool     `yaml:"required,omitempty"`  // for required attributes
+	Choices   []string `yaml:"choices,omitempty"`   // for enum attributes
+	Indexed   bool     `yaml:"indexed,omitempty"`   // for indexed attributes
+	Session   bool     `yaml:"session,omitempty"`   // for session attributes
+	Encrypted bool     `yaml:"encrypted,omitempty"` // for attributes encrypted at rest
+	BindTo    string   `yaml:"bind_to,omitempty"`   // for binding to other attributes
+	Tags      []string `yaml:"tags,omitempty"`      // for attribute tags
 
 	Attrs map[string]*schemaAttr `yaml:"attrs,omitempty"` // for nested attributes
 }
@@ -70,6 +73,10 @@ type gen struct {
 	decoders    []j.Code
 	encoders    []j.Code
 	empties     []j.Code
+	validators  []j.Code
+	copiers     []j.Code
+	differs     []j.Code
+	finders     []j.Code
 
 	subgen []*gen // for nested attributes
 }
@@ -284,6 +291,29 @@ func toCamal(s string) string {
 	return b.String()
 }
 
+func lowerCamel(s string) string {
+	if s == "" {
+		return s
+	}
+
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+// plural gives the plural of a kind's name for the finders named after it.
+// Names that already end in s are left alone.
+func plural(s string) string {
+	switch {
+	case strings.HasSuffix(s, "s"):
+		return s
+	case strings.HasSuffix(s, "x"), strings.HasSuffix(s, "ch"), strings.HasSuffix(s, "sh"):
+		return s + "es"
+	case strings.HasSuffix(s, "y") && len(s) > 1 && !strings.ContainsRune("aeiou", rune(s[len(s)-2])):
+		return s[:len(s)-1] + "ies"
+	default:
+		return s + "s"
+	}
+}
+
 func (g *gen) Ident(name string) j.Code {
 	return j.Id(g.local + name + "Id")
 }
@@ -367,6 +397,80 @@ func (g *gen) attr(name string, attr *schemaAttr) {
 		}
 	}
 
+	// unset returns a check that the field holds no value.
+	unset := func() *j.Statement {
+		if attr.Many || attr.Type == "bytes" {
+			return j.Len(j.Id("o").Dot(fname)).Op("==").Lit(0)
+		}
+
+		return j.Qual(top, "Empty").Call(j.Id("o").Dot(fname))
+	}
+
+	// required adds a check to Validate that a required attribute is set.
+	// Zero ints, durations and bools are legal values, so those are always
+	// considered set.
+	required := func(missing *j.Statement) {
+		if !attr.Required {
+			return
+		}
+
+		g.validators = append(g.validators,
+			j.If(missing).Block(
+				j.Return(j.Qual("fmt", "Errorf").Call(j.Lit("%w: %s"), j.Qual(top, "ErrRequiredAttr"), j.Lit(name))),
+			))
+	}
+
+	// choices adds a check to Validate that the attribute only holds one of
+	// its choices, if it has any.
+	choices := func() {
+		if len(attr.Choices) == 0 {
+			return
+		}
+
+		legal := []j.Code{j.Lit("")}
+		for _, v := range attr.Choices {
+			legal = append(legal, j.Lit(v))
+		}
+
+		check := func(v j.Code) *j.Statement {
+			return j.Switch(v).Block(
+				j.Case(legal...),
+				j.Default().Block(
+					j.Return(j.Qual("fmt", "Errorf").Call(j.Lit("%w: %s: %q"), j.Qual(top, "ErrInvalidChoice"), j.Lit(name), v)),
+				),
+			)
+		}
+
+		if attr.Many {
+			g.validators = append(g.validators,
+				j.For(j.List(j.Op("_"), j.Id("v")).Op(":=").Range().Id("o").Dot(fname)).Block(check(j.Id("v"))))
+		} else {
+			g.validators = append(g.validators, check(j.Id("o").Dot(fname)))
+		}
+	}
+
+	// nested adds a check to Validate that a component attribute holds
+	// valid components.
+	nested := func() {
+		if attr.Many {
+			required(unset())
+			g.validators = append(g.validators,
+				j.For(j.List(j.Id("i"), j.Id("v")).Op(":=").Range().Id("o").Dot(fname)).Block(
+					j.If(j.Err().Op(":=").Id("v").Dot("Validate").Call(), j.Err().Op("!=").Nil()).Block(
+						j.Return(j.Qual("fmt", "Errorf").Call(j.Lit("%s[%d]: %w"), j.Lit(name), j.Id("i"), j.Err())),
+					),
+				))
+		} else {
+			required(j.Id("o").Dot(fname).Dot("Empty").Call())
+			g.validators = append(g.validators,
+				j.If(j.Op("!").Id("o").Dot(fname).Dot("Empty").Call()).Block(
+					j.If(j.Err().Op(":=").Id("o").Dot(fname).Dot("Validate").Call(), j.Err().Op("!=").Nil()).Block(
+						j.Return(j.Qual("fmt", "Errorf").Call(j.Lit("%s: %w"), j.Lit(name), j.Err())),
+					),
+				))
+		}
+	}
+
 	simpleDecl := func(method string) {
 		var call []j.Code
 		call = append(call, j.Lit(name), j.Lit(eid))
@@ -387,6 +491,10 @@ func (g *gen) attr(name string, attr *schemaAttr) {
 			call = append(call, j.Qual(sch, "Indexed"))
 		}
 
+		if attr.Encrypted {
+			call = append(call, j.Qual(sch, "Encrypted"))
+		}
+
 		if len(attr.Tags) > 0 {
 			var tagArgs []j.Code
 			for _, tag := range attr.Tags {
@@ -472,6 +580,9 @@ func (g *gen) attr(name string, attr *schemaAttr) {
 		}
 
 		simpleDecl("Component")
+		nested()
+		g.deepCopy(fname, attr, componentName)
+		g.diff(fname, attr)
 
 		// Populate Component field with the schema of the referenced component
 		g.ec.Fields = append(g.ec.Fields, &entity.SchemaField{
@@ -498,6 +609,8 @@ func (g *gen) attr(name string, attr *schemaAttr) {
 		simpleEncoder("String")
 		simpleDecl("String")
 		simpleField("string")
+		required(unset())
+		choices()
 	case "keyword":
 		if attr.Many {
 			g.fields = append(g.fields, j.Id(fname).Index().Qual(topt, "Keyword").Tag(tag))
@@ -510,6 +623,8 @@ func (g *gen) attr(name string, attr *schemaAttr) {
 		simpleEncoder("Keyword")
 		simpleDecl("Keyword")
 		simpleField("keyword")
+		required(unset())
+		choices()
 	case "int":
 		g.fields = append(g.fields, j.Id(fname).Int64().Tag(tag))
 		simpleDecoder("KindInt64", "Int64")
@@ -522,6 +637,7 @@ func (g *gen) attr(name string, attr *schemaAttr) {
 		simpleEncoder("Time")
 		simpleDecl("Time")
 		simpleField("time")
+		required(unset())
 	case "duration":
 		if attr.Many {
 			g.fields = append(g.fields, j.Id(fname).Index().Qual("time", "Duration").Tag(tag))
@@ -551,12 +667,14 @@ func (g *gen) attr(name string, attr *schemaAttr) {
 				j.If(j.Len(j.Id("o").Dot(fname)).Op("!=").Lit(0)).Block(j.Return(j.False())))
 			simpleDecl("Ref")
 			simpleField("id")
+			required(unset())
 		} else {
 			g.fields = append(g.fields, j.Id(fname).Qual(top, "Id").Tag(tag))
 			simpleDecoder("KindId", "Id")
 			simpleEncoder("Ref")
 			simpleDecl("Ref")
 			simpleField("id")
+			required(unset())
 		}
 	case "bool":
 		g.fields = append(g.fields, j.Id(fname).Bool().Tag(tag))
@@ -569,6 +687,7 @@ func (g *gen) attr(name string, attr *schemaAttr) {
 		simpleDecoder("KindBytes", "Bytes")
 		simpleDecl("Bytes")
 		simpleField("bytes")
+		required(unset())
 		if attr.Many {
 			g.encoders = append(g.encoders,
 				j.For(j.List(j.Op("_"), j.Id("v")).Op(":=").Range().Id("o").Dot(fname)).Block(
@@ -609,6 +728,7 @@ func (g *gen) attr(name string, attr *schemaAttr) {
 		simpleDecoder("KindLabel", "Label")
 		simpleDecl("Label")
 		simpleField("label")
+		required(unset())
 
 	case "enum":
 		g.decodeouter = append(g.decodeouter, j.Type().Add(g.NSd(fname)).String())
@@ -690,6 +810,14 @@ func (g *gen) attr(name string, attr *schemaAttr) {
 		g.empties = append(g.empties,
 			j.If(j.Id("o").Dot(fname).Op("!=").Lit("")).Block(j.Return(j.False())))
 
+		required(j.Id("o").Dot(fname).Op("==").Lit(""))
+		g.validators = append(g.validators,
+			j.If(j.Id("o").Dot(fname).Op("!=").Lit("")).Block(
+				j.If(j.List(j.Op("_"), j.Id("ok")).Op(":=").Id(g.name+name+"ToId").Index(j.Id("o").Dot(fname)), j.Op("!").Id("ok")).Block(
+					j.Return(j.Qual("fmt", "Errorf").Call(j.Lit("%w: %s: %q"), j.Qual(top, "ErrInvalidChoice"), j.Lit(name), j.Id("o").Dot(fname))),
+				),
+			))
+
 		var call []j.Code
 		call = append(call, j.Lit(name), j.Lit(eid))
 
@@ -709,6 +837,10 @@ func (g *gen) attr(name string, attr *schemaAttr) {
 			call = append(call, j.Qual(sch, "Indexed"))
 		}
 
+		if attr.Encrypted {
+			call = append(call, j.Qual(sch, "Encrypted"))
+		}
+
 		if attr.Session {
 			call = append(call, j.Qual(sch, "Session"))
 		}
@@ -844,6 +976,9 @@ func (g *gen) attr(name string, attr *schemaAttr) {
 		}
 		simpleDecl("Component")
 
+		nested()
+		g.deepCopy(fname, attr, typeName)
+
 		g.decl = append(g.decl,
 			j.Parens(j.Op("&").Id(typeName).Values()).Dot("InitSchema").Call(j.Id("sb").Dot("Builder").Call(j.Lit(attr.Attr))))
 
@@ -857,6 +992,112 @@ func (g *gen) attr(name string, attr *schemaAttr) {
 	default:
 		panic(fmt.Sprintf("Unknown attribute type: %s", attr.Type))
 	}
+
+	if attr.Type != "component" {
+		g.deepCopy(fname, attr, "")
+	}
+
+	g.diff(fname, attr)
+	g.finder(name, fname, attr)
+}
+
+// finder adds a Find<Kind>sBy<Attr> function for an indexed attribute of a
+// kind, which looks up the ids of the entities holding a value through the
+// attribute's index. Components and attributes whose values can't be
+// passed as a single argument don't get one.
+func (g *gen) finder(name, fname string, attr *schemaAttr) {
+	if !attr.Indexed || g.kind == "" || g.isComponent {
+		return
+	}
+
+	param := lowerCamel(fname)
+	if token.IsKeyword(param) {
+		param = "value"
+	}
+
+	var (
+		typ    j.Code
+		lookup []j.Code
+	)
+
+	value := func(method string) {
+		lookup = append(lookup,
+			j.Return(j.Id("store").Dot("ListIndex").Call(j.Id("ctx"), j.Qual(top, method).Call(g.Ident(fname), j.Id(param)))))
+	}
+
+	switch attr.Type {
+	case "string":
+		typ = j.String()
+		value("String")
+	case "keyword":
+		typ = j.Qual(topt, "Keyword")
+		value("Keyword")
+	case "int":
+		typ = j.Int64()
+		value("Int64")
+	case "bool":
+		typ = j.Bool()
+		value("Bool")
+	case "ref":
+		typ = j.Qual(top, "Id")
+		value("Ref")
+	case "enum":
+		typ = g.NSd(fname)
+		lookup = append(lookup,
+			j.List(j.Id("id"), j.Id("ok")).Op(":=").Id(g.name+name+"ToId").Index(j.Id(param)),
+			j.If(j.Op("!").Id("ok")).Block(
+				j.Return(j.Nil(), j.Qual("fmt", "Errorf").Call(j.Lit("%w: %s: %q"), j.Qual(top, "ErrInvalidChoice"), j.Lit(name), j.Id(param))),
+			),
+			j.Return(j.Id("store").Dot("ListIndex").Call(j.Id("ctx"), j.Qual(top, "Ref").Call(g.Ident(fname), j.Id("id")))),
+		)
+	default:
+		return
+	}
+
+	g.finders = append(g.finders,
+		j.Func().Id("Find"+plural(toCamal(g.kind))+"By"+fname).
+			Params(
+				j.Id("ctx").Qual("context", "Context"),
+				j.Id("store").Qual(top, "IndexLister"),
+				j.Id(param).Add(typ),
+			).
+			Params(j.Index().Qual(top, "Id"), j.Error()).
+			Block(lookup...))
+}
+
+// diff adds the diffing of an attribute to EncodeChanges. Multi-valued
+// attributes are compared as sets, so reordering their values isn't a change.
+func (g *gen) diff(fname string, attr *schemaAttr) {
+	method := "DiffOne"
+	if attr.Many {
+		method = "DiffMany"
+	}
+
+	g.differs = append(g.differs,
+		j.Id("ops").Op("=").Append(j.Id("ops"), j.Qual(top, method).Call(j.Id("old"), j.Id("desired"), g.Ident(fname)).Op("...")))
+}
+
+// deepCopy adds the copying of a field that holds a slice or component to
+// DeepCopy. component is the type of a component field. Other fields are
+// values and are copied along with the struct.
+func (g *gen) deepCopy(fname string, attr *schemaAttr, component string) {
+	switch {
+	case component != "" && attr.Many:
+		g.copiers = append(g.copiers,
+			j.If(j.Id("o").Dot(fname).Op("!=").Nil()).Block(
+				j.Id("out").Dot(fname).Op("=").Make(j.Index().Id(component), j.Len(j.Id("o").Dot(fname))),
+				j.For(j.Id("i").Op(":=").Range().Id("o").Dot(fname)).Block(
+					j.Id("out").Dot(fname).Index(j.Id("i")).Op("=").Op("*").Id("o").Dot(fname).Index(j.Id("i")).Dot("DeepCopy").Call(),
+				),
+			))
+	case component != "":
+		g.copiers = append(g.copiers,
+			j.Id("out").Dot(fname).Op("=").Op("*").Id("o").Dot(fname).Dot("DeepCopy").Call())
+	case attr.Type == "bytes",
+		attr.Many && slices.Contains([]string{"string", "keyword", "duration", "ref", "label"}, attr.Type):
+		g.copiers = append(g.copiers,
+			j.Id("out").Dot(fname).Op("=").Qual("slices", "Clone").Call(j.Id("o").Dot(fname)))
+	}
 }
 
 func (g *gen) generate() {
@@ -959,6 +1200,26 @@ func (g *gen) generate() {
 
 	f.Line()
 
+	// EncodeChanges returns the operations that take old, the entity the
+	// struct was read from, to the struct's current values, so that a small
+	// update doesn't have to rewrite every attribute. Attributes that aren't
+	// part of the schema are left alone. It's only for kinds, which are what's
+	// written to the store.
+	if g.kind != "" && !g.isComponent {
+		f.Func().
+			Params(j.Id("o").Op("*").Id(structName)).Id("EncodeChanges").
+			Params(j.Id("old").Qual(top, "AttrGetter")).Params(j.Id("ops").Qual(top, "AttrOps")).
+			BlockFunc(func(b *j.Group) {
+				b.Id("desired").Op(":=").Qual(top, "New").Call(j.Id("o").Dot("Encode").Call())
+				for _, d := range g.differs {
+					b.Add(d)
+				}
+				b.Return()
+			})
+
+		f.Line()
+	}
+
 	f.Func().
 		Params(j.Id("o").Op("*").Id(structName)).Id("Empty").
 		Params().Params(j.Bool()).
@@ -971,6 +1232,36 @@ func (g *gen) generate() {
 
 	f.Line()
 
+	// DeepCopy copies the struct along with all of its slices and
+	// components, so the copy can be changed without changing the original.
+	f.Func().
+		Params(j.Id("o").Op("*").Id(structName)).Id("DeepCopy").
+		Params().Op("*").Id(structName).
+		BlockFunc(func(b *j.Group) {
+			b.If(j.Id("o").Op("==").Nil()).Block(j.Return(j.Nil()))
+			b.Id("out").Op(":=").Op("*").Id("o")
+			for _, d := range g.copiers {
+				b.Add(d)
+			}
+			b.Return(j.Op("&").Id("out"))
+		})
+
+	f.Line()
+
+	// Validate checks the constraints the schema puts on values, which
+	// Encode doesn't enforce.
+	f.Func().
+		Params(j.Id("o").Op("*").Id(structName)).Id("Validate").
+		Params().Params(j.Error()).
+		BlockFunc(func(b *j.Group) {
+			for _, d := range g.validators {
+				b.Add(d)
+			}
+			b.Return(j.Nil())
+		})
+
+	f.Line()
+
 	f.Func().
 		Params(j.Id("o").Op("*").Id(structName)).
 		Id("InitSchema").Params(j.Id("sb").Op("*").Qual(sch, "SchemaBuilder")).
@@ -982,6 +1273,11 @@ func (g *gen) generate() {
 
 	f.Line()
 
+	for _, fn := range g.finders {
+		f.Add(fn)
+		f.Line()
+	}
+
 	// Generate nested attributes
 	for _, sg := range g.subgen {
 		sg.generate()
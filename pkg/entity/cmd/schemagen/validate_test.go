@@ -0,0 +1,114 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateGeneration(t *testing.T) {
+	sf := &schemaFile{
+		Domain:  "test",
+		Version: "v1",
+		Kinds: map[string]schemaAttrs{
+			"widget": {
+				"name": &schemaAttr{
+					Type:     "string",
+					Doc:      "The widget name",
+					Required: true,
+				},
+				"replicas": &schemaAttr{
+					Type:     "int",
+					Doc:      "Zero is a legal replica count",
+					Required: true,
+				},
+				"shape": &schemaAttr{
+					Type:    "enum",
+					Doc:     "The widget shape",
+					Choices: []string{"round", "square"},
+				},
+				"part": &schemaAttr{
+					Type: "component",
+					Doc:  "Parts of the widget",
+					Many: true,
+					Attrs: map[string]*schemaAttr{
+						"sku": {
+							Type:     "string",
+							Doc:      "Part number",
+							Required: true,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	code, err := GenerateSchema(sf, "test")
+	if err != nil {
+		t.Fatalf("Failed to generate schema: %v", err)
+	}
+
+	validate := func(structName string) string {
+		start := strings.Index(code, "func (o *"+structName+") Validate() error {")
+		if start == -1 {
+			t.Fatalf("Could not find Validate() method for %s in generated code:\n%s", structName, code)
+		}
+
+		end := strings.Index(code[start:], "\n}")
+		return code[start : start+end]
+	}
+
+	widget := validate("Widget")
+
+	for _, want := range []string{
+		`if entity.Empty(o.Name) {`,
+		`return fmt.Errorf("%w: %s", entity.ErrRequiredAttr, "name")`,
+		`if _, ok := widgetshapeToId[o.Shape]; !ok {`,
+		`return fmt.Errorf("%w: %s: %q", entity.ErrInvalidChoice, "shape", o.Shape)`,
+		`if err := v.Validate(); err != nil {`,
+		`return fmt.Errorf("%s[%d]: %w", "part", i, err)`,
+	} {
+		if !strings.Contains(widget, want) {
+			t.Errorf("Widget.Validate() should contain %q", want)
+		}
+	}
+
+	if strings.Contains(widget, "o.Replicas") {
+		t.Error("Validate() should not require int fields to be non-zero")
+		t.Logf("Validate() method:\n%s", widget)
+	}
+
+	if !strings.Contains(validate("Part"), `return fmt.Errorf("%w: %s", entity.ErrRequiredAttr, "sku")`) {
+		t.Error("Part.Validate() should check its required sku")
+	}
+}
+
+func TestValidateStringChoices(t *testing.T) {
+	sf := &schemaFile{
+		Domain:  "test",
+		Version: "v1",
+		Kinds: map[string]schemaAttrs{
+			"disk": {
+				"filesystem": &schemaAttr{
+					Type:    "keyword",
+					Doc:     "The filesystem to format with",
+					Choices: []string{"ext4", "xfs"},
+				},
+			},
+		},
+	}
+
+	code, err := GenerateSchema(sf, "test")
+	if err != nil {
+		t.Fatalf("Failed to generate schema: %v", err)
+	}
+
+	if !strings.Contains(code, `case "", "ext4", "xfs":`) {
+		t.Error("Validate() should accept the empty value and each choice")
+		t.Logf("Generated code:\n%s", code)
+	}
+
+	if !strings.Contains(code, `return fmt.Errorf("%w: %s: %q", entity.ErrInvalidChoice, "filesystem", o.Filesystem)`) {
+		t.Error("Validate() should reject values that aren't a choice")
+		t.Logf("Generated code:\n%s", code)
+	}
+}
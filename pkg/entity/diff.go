@@ -1,5 +1,7 @@
 package entity
 
+import "slices"
+
 func (e *Entity) Clone() *Entity {
 	clonedAttrs := make([]Attr, len(e.attrs))
 	for i, attr := range e.attrs {
@@ -14,20 +16,96 @@ func (e *Entity) Clone() *Entity {
 // Diff returns the difference between two entities.
 // Returns attributes that are in entity 'a' but not in entity 'b'
 func Diff(a, b *Entity) []Attr {
-	var diff []Attr
-
-	for _, aAttr := range a.attrs {
-		found := false
-		for _, bAttr := range b.attrs {
-			if aAttr.Equal(bAttr) {
-				found = true
-				break
-			}
+	diff, _ := DiffSlices(b.attrs, a.attrs, Attr.Compare)
+	return diff
+}
+
+// DiffSlices computes the elements to add to and remove from current to make it
+// hold the same elements as desired, using cmp to decide equality. The slices
+// are treated as sets: order is ignored, so reordering the same elements yields
+// no changes, and duplicates are reported once. Results are in the order the
+// elements first appear in desired and current respectively.
+func DiffSlices[T any](current, desired []T, cmp func(a, b T) int) (added, removed []T) {
+	contains := func(vals []T, v T) bool {
+		return slices.ContainsFunc(vals, func(o T) bool {
+			return cmp(o, v) == 0
+		})
+	}
+
+	for _, v := range desired {
+		if !contains(current, v) && !contains(added, v) {
+			added = append(added, v)
 		}
-		if !found {
-			diff = append(diff, aAttr)
+	}
+
+	for _, v := range current {
+		if !contains(desired, v) && !contains(removed, v) {
+			removed = append(removed, v)
 		}
 	}
 
-	return diff
+	return added, removed
+}
+
+// DiffMany returns the operations that take the values of the multi-valued
+// attribute id on current to those on desired. Values are compared deeply, so
+// components only match when all their nested attributes do. Removals come
+// before additions.
+func DiffMany(current, desired AttrGetter, id Id) AttrOps {
+	added, removed := DiffSlices(current.GetAll(id), desired.GetAll(id), Attr.Compare)
+
+	ops := make(AttrOps, 0, len(added)+len(removed))
+
+	for _, a := range removed {
+		ops = append(ops, AttrRemove(a))
+	}
+
+	for _, a := range added {
+		ops = append(ops, AttrAdd(a))
+	}
+
+	return ops
+}
+
+// DiffOne returns the operations that take the single-valued attribute id on
+// current to its value on desired. A changed value is a removal and an
+// addition, as with DiffMany, and there are no operations when the values are
+// equal.
+func DiffOne(current, desired AttrGetter, id Id) AttrOps {
+	cur, hasCur := current.Get(id)
+	want, hasWant := desired.Get(id)
+
+	if hasCur && hasWant && cur.Compare(want) == 0 {
+		return nil
+	}
+
+	var ops AttrOps
+
+	if hasCur {
+		ops = append(ops, AttrRemove(cur))
+	}
+
+	if hasWant {
+		ops = append(ops, AttrAdd(want))
+	}
+
+	return ops
+}
+
+// ApplyOps adds and removes individual attribute values. Unlike Set, an add
+// keeps any existing values with the same ID, so it is suitable for
+// multi-valued attributes.
+func (e *Entity) ApplyOps(ops AttrOps) error {
+	for _, op := range ops {
+		switch op.Op {
+		case AttrOpCodeAdd:
+			e.attrs = append(e.attrs, op.Attr)
+		case AttrOpCodeRemove:
+			e.attrs = slices.DeleteFunc(e.attrs, func(a Attr) bool {
+				return a.Equal(op.Attr)
+			})
+		}
+	}
+
+	return e.Fixup()
 }
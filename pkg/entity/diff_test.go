@@ -0,0 +1,173 @@
+package entity
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testLabels = Id("test/labels")
+
+func labelEntity(kv ...string) *Entity {
+	attrs := []Attr{Ref(DBId, "test/app")}
+	for i := 0; i < len(kv); i += 2 {
+		attrs = append(attrs, Label(testLabels, kv[i], kv[i+1]))
+	}
+	return New(attrs)
+}
+
+func TestDiffMany(t *testing.T) {
+	t.Run("additions", func(t *testing.T) {
+		current := labelEntity("app", "web")
+		desired := labelEntity("app", "web", "tier", "frontend", "env", "prod")
+
+		ops := DiffMany(current, desired, testLabels)
+
+		// Entities keep their attrs sorted, so additions come out in label order
+		require.Len(t, ops, 2)
+		assert.Equal(t, AttrOpCodeAdd, ops[0].Op)
+		assert.True(t, ops[0].Attr.Equal(Label(testLabels, "env", "prod")))
+		assert.Equal(t, AttrOpCodeAdd, ops[1].Op)
+		assert.True(t, ops[1].Attr.Equal(Label(testLabels, "tier", "frontend")))
+	})
+
+	t.Run("removals", func(t *testing.T) {
+		current := labelEntity("app", "web", "tier", "frontend", "env", "prod")
+		desired := labelEntity("env", "prod")
+
+		ops := DiffMany(current, desired, testLabels)
+
+		require.Len(t, ops, 2)
+		assert.Equal(t, AttrOpCodeRemove, ops[0].Op)
+		assert.True(t, ops[0].Attr.Equal(Label(testLabels, "app", "web")))
+		assert.Equal(t, AttrOpCodeRemove, ops[1].Op)
+		assert.True(t, ops[1].Attr.Equal(Label(testLabels, "tier", "frontend")))
+	})
+
+	t.Run("changed value is a removal and an addition", func(t *testing.T) {
+		current := labelEntity("app", "web", "env", "staging")
+		desired := labelEntity("app", "web", "env", "prod")
+
+		ops := DiffMany(current, desired, testLabels)
+
+		require.Len(t, ops, 2)
+		assert.Equal(t, AttrOpCodeRemove, ops[0].Op)
+		assert.True(t, ops[0].Attr.Equal(Label(testLabels, "env", "staging")))
+		assert.Equal(t, AttrOpCodeAdd, ops[1].Op)
+		assert.True(t, ops[1].Attr.Equal(Label(testLabels, "env", "prod")))
+
+		require.NoError(t, current.ApplyOps(ops))
+		assert.Equal(t, 0, current.Compare(desired))
+	})
+
+	t.Run("reordering without change", func(t *testing.T) {
+		current := &EntityComponent{attrs: []Attr{
+			Label(testLabels, "app", "web"),
+			Label(testLabels, "env", "prod"),
+			Label(testLabels, "tier", "frontend"),
+		}}
+		desired := &EntityComponent{attrs: []Attr{
+			Label(testLabels, "tier", "frontend"),
+			Label(testLabels, "app", "web"),
+			Label(testLabels, "env", "prod"),
+		}}
+
+		assert.Empty(t, DiffMany(current, desired, testLabels))
+	})
+
+	t.Run("components are compared deeply", func(t *testing.T) {
+		port := func(name string, num int64) Attr {
+			return Component("test/port", []Attr{
+				String("test/name", name),
+				Int64("test/port", num),
+			})
+		}
+
+		current := New(Ref(DBId, "test/app"), port("http", 80), port("https", 443))
+		same := New(Ref(DBId, "test/app"), port("https", 443), port("http", 80))
+		changed := New(Ref(DBId, "test/app"), port("http", 8080), port("https", 443))
+
+		assert.Empty(t, DiffMany(current, same, "test/port"))
+
+		ops := DiffMany(current, changed, "test/port")
+		require.Len(t, ops, 2)
+		assert.Equal(t, AttrOpCodeRemove, ops[0].Op)
+		assert.True(t, ops[0].Attr.Equal(port("http", 80)))
+		assert.Equal(t, AttrOpCodeAdd, ops[1].Op)
+		assert.True(t, ops[1].Attr.Equal(port("http", 8080)))
+	})
+}
+
+func TestDiffOne(t *testing.T) {
+	const testStatus = Id("test/status")
+
+	status := func(v string) *Entity {
+		if v == "" {
+			return New(Ref(DBId, "test/app"))
+		}
+		return New(Ref(DBId, "test/app"), Keyword(testStatus, v))
+	}
+
+	t.Run("unchanged", func(t *testing.T) {
+		assert.Empty(t, DiffOne(status("running"), status("running"), testStatus))
+		assert.Empty(t, DiffOne(status(""), status(""), testStatus))
+	})
+
+	t.Run("set", func(t *testing.T) {
+		ops := DiffOne(status(""), status("running"), testStatus)
+
+		require.Len(t, ops, 1)
+		assert.Equal(t, AttrOpCodeAdd, ops[0].Op)
+		assert.True(t, ops[0].Attr.Equal(Keyword(testStatus, "running")))
+	})
+
+	t.Run("cleared", func(t *testing.T) {
+		ops := DiffOne(status("running"), status(""), testStatus)
+
+		require.Len(t, ops, 1)
+		assert.Equal(t, AttrOpCodeRemove, ops[0].Op)
+		assert.True(t, ops[0].Attr.Equal(Keyword(testStatus, "running")))
+	})
+
+	t.Run("changed value is a removal and an addition", func(t *testing.T) {
+		current := status("pending")
+		desired := status("running")
+
+		ops := DiffOne(current, desired, testStatus)
+
+		require.Len(t, ops, 2)
+		assert.Equal(t, AttrOpCodeRemove, ops[0].Op)
+		assert.Equal(t, AttrOpCodeAdd, ops[1].Op)
+
+		require.NoError(t, current.ApplyOps(ops))
+		assert.Equal(t, 0, current.Compare(desired))
+	})
+}
+
+func TestDiffSlices(t *testing.T) {
+	added, removed := DiffSlices(
+		[]string{"c", "a", "b", "a"},
+		[]string{"d", "b", "e", "d", "c"},
+		strings.Compare,
+	)
+
+	assert.Equal(t, []string{"d", "e"}, added, "additions keep desired order, once each")
+	assert.Equal(t, []string{"a"}, removed, "removals keep current order, once each")
+
+	added, removed = DiffSlices([]string{"x", "y"}, []string{"y", "x"}, strings.Compare)
+	assert.Empty(t, added)
+	assert.Empty(t, removed)
+}
+
+func TestDiff(t *testing.T) {
+	a := labelEntity("app", "web", "env", "prod")
+	b := labelEntity("app", "web")
+
+	diff := Diff(a, b)
+	require.Len(t, diff, 1)
+	assert.True(t, diff[0].Equal(Label(testLabels, "env", "prod")))
+
+	assert.Empty(t, Diff(b, b.Clone()))
+}
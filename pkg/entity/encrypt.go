@@ -0,0 +1,317 @@
+package entity
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Keyring holds the keys that values of encrypted attributes are sealed
+// with. Keys are 32 bytes, for AES-256-GCM.
+type Keyring interface {
+	// Current returns the key new values are sealed with, and its id.
+	Current() (id string, key []byte, err error)
+
+	// Key returns the key with id, to open values sealed with it.
+	Key(id string) ([]byte, error)
+}
+
+var (
+	ErrNoKeyring  = errors.New("no keyring for encrypted attribute")
+	ErrUnknownKey = errors.New("unknown encryption key")
+	ErrBadSeal    = errors.New("unable to decrypt attribute value")
+)
+
+// StaticKeyring is a Keyring of keys held in memory.
+type StaticKeyring struct {
+	mu      sync.RWMutex
+	current string
+	keys    map[string][]byte
+}
+
+// NewStaticKeyring returns a keyring sealing values with key, under id.
+func NewStaticKeyring(id string, key []byte) (*StaticKeyring, error) {
+	k := &StaticKeyring{keys: make(map[string][]byte)}
+
+	if err := k.Add(id, key); err != nil {
+		return nil, err
+	}
+
+	k.current = id
+
+	return k, nil
+}
+
+// Add adds a key that values can be opened with, such as one that's been
+// rotated out.
+func (k *StaticKeyring) Add(id string, key []byte) error {
+	if id == "" || len(id) > 255 {
+		return fmt.Errorf("invalid key id %q", id)
+	}
+
+	if len(key) != 32 {
+		return fmt.Errorf("key %s must be 32 bytes, was %d", id, len(key))
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.keys[id] = bytes.Clone(key)
+
+	return nil
+}
+
+func (k *StaticKeyring) Current() (string, []byte, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	return k.current, k.keys[k.current], nil
+}
+
+func (k *StaticKeyring) Key(id string) ([]byte, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	key, ok := k.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, id)
+	}
+
+	return key, nil
+}
+
+// LoadNodeKeyring returns a keyring of the node key stored at path, creating
+// the key if there isn't one. The key's id is derived from the key itself.
+func LoadNodeKeyring(path string) (*StaticKeyring, error) {
+	key, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			return nil, err
+		}
+
+		if err := os.WriteFile(path, key, 0600); err != nil {
+			return nil, fmt.Errorf("failed to write node key: %w", err)
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read node key: %w", err)
+	}
+
+	sum := sha256.Sum256(key)
+
+	return NewStaticKeyring("node-"+hex.EncodeToString(sum[:4]), key)
+}
+
+// sealedMagic prefixes the bytes values that encrypted attributes are stored
+// as, followed by the length and id of the key, the nonce and the ciphertext.
+var sealedMagic = []byte("db/sealed.1\x00")
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// sealAttr returns attr with its value encrypted with the keyring's current
+// key. The attribute's id is authenticated with it, so a sealed value can't
+// be moved to another attribute.
+func sealAttr(kr Keyring, attr Attr) (Attr, error) {
+	if kr == nil {
+		return Attr{}, fmt.Errorf("%w: %s", ErrNoKeyring, attr.ID)
+	}
+
+	id, key, err := kr.Current()
+	if err != nil {
+		return Attr{}, err
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return Attr{}, err
+	}
+
+	plain, err := encoder.Marshal(&attr.Value)
+	if err != nil {
+		return Attr{}, err
+	}
+
+	out := bytes.Clone(sealedMagic)
+	out = append(out, byte(len(id)))
+	out = append(out, id...)
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return Attr{}, err
+	}
+
+	out = append(out, nonce...)
+	out = aead.Seal(out, nonce, plain, []byte(attr.ID))
+
+	return Bytes(attr.ID, out), nil
+}
+
+// isSealed reports whether v looks like a value sealed by sealAttr.
+func isSealed(v Value) bool {
+	return v.Kind() == KindBytes && bytes.HasPrefix(v.Bytes(), sealedMagic)
+}
+
+// openAttr returns attr with the value sealed by sealAttr decrypted.
+func openAttr(kr Keyring, attr Attr) (Attr, error) {
+	if kr == nil {
+		return Attr{}, fmt.Errorf("%w: %s", ErrNoKeyring, attr.ID)
+	}
+
+	data := attr.Value.Bytes()[len(sealedMagic):]
+
+	if len(data) < 1 || len(data) < 1+int(data[0]) {
+		return Attr{}, fmt.Errorf("%w %s: truncated", ErrBadSeal, attr.ID)
+	}
+
+	id := string(data[1 : 1+data[0]])
+	data = data[1+data[0]:]
+
+	key, err := kr.Key(id)
+	if err != nil {
+		return Attr{}, err
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return Attr{}, err
+	}
+
+	if len(data) < aead.NonceSize() {
+		return Attr{}, fmt.Errorf("%w %s: truncated", ErrBadSeal, attr.ID)
+	}
+
+	nonce, ct := data[:aead.NonceSize()], data[aead.NonceSize():]
+
+	plain, err := aead.Open(nil, nonce, ct, []byte(attr.ID))
+	if err != nil {
+		return Attr{}, fmt.Errorf("%w %s: %s", ErrBadSeal, attr.ID, err)
+	}
+
+	var v Value
+	if err := decoder.Unmarshal(plain, &v); err != nil {
+		return Attr{}, fmt.Errorf("%w %s: %s", ErrBadSeal, attr.ID, err)
+	}
+
+	return Attr{ID: attr.ID, Value: v}, nil
+}
+
+// SetKeyring sets the keyring that values of encrypted attributes are sealed
+// and opened with.
+func (s *EtcdStore) SetKeyring(kr Keyring) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keyring = kr
+}
+
+// CheckKeyring returns an error wrapping ErrNoKeyring if any of ids is an
+// encrypted attribute and the store has no keyring, since every write of
+// such an attribute would fail.
+func (s *EtcdStore) CheckKeyring(ctx context.Context, ids []Id) error {
+	if kr := s.currentKeyring(); kr != nil {
+		_, _, err := kr.Current()
+		return err
+	}
+
+	for _, id := range ids {
+		schema, err := s.GetAttributeSchema(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if schema.Encrypted {
+			return fmt.Errorf("%w: %s", ErrNoKeyring, id)
+		}
+	}
+
+	return nil
+}
+
+func (s *EtcdStore) currentKeyring() Keyring {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.keyring
+}
+
+// sealAttrs returns attrs with the values of encrypted attributes sealed,
+// leaving attrs itself untouched.
+func (s *EtcdStore) sealAttrs(ctx context.Context, attrs []Attr) ([]Attr, error) {
+	var out []Attr
+
+	for i, attr := range attrs {
+		schema, err := s.GetAttributeSchema(ctx, attr.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		if !schema.Encrypted {
+			continue
+		}
+
+		if schema.Index {
+			return nil, fmt.Errorf("attribute %s can't be both encrypted and indexed", attr.ID)
+		}
+
+		if out == nil {
+			out = append([]Attr(nil), attrs...)
+		}
+
+		out[i], err = sealAttr(s.currentKeyring(), attr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if out == nil {
+		return attrs, nil
+	}
+
+	return out, nil
+}
+
+// openAttrs decrypts the sealed values in attrs in place.
+func (s *EtcdStore) openAttrs(ctx context.Context, attrs []Attr) error {
+	for i, attr := range attrs {
+		if !isSealed(attr.Value) {
+			continue
+		}
+
+		schema, err := s.GetAttributeSchema(ctx, attr.ID)
+		if err != nil {
+			return err
+		}
+
+		// Just bytes that happen to look sealed
+		if !schema.Encrypted {
+			continue
+		}
+
+		attrs[i], err = openAttr(s.currentKeyring(), attr)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,111 @@
+package entity
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testKeyring(t *testing.T, id string, fill byte) *StaticKeyring {
+	kr, err := NewStaticKeyring(id, bytes.Repeat([]byte{fill}, 32))
+	require.NoError(t, err)
+	return kr
+}
+
+func TestSealAttr(t *testing.T) {
+	kr := testKeyring(t, "k1", 1)
+
+	attr := String("test/token", "hunter2-super-secret")
+
+	sealed, err := sealAttr(kr, attr)
+	require.NoError(t, err)
+
+	t.Run("stores ciphertext", func(t *testing.T) {
+		r := require.New(t)
+
+		r.Equal(attr.ID, sealed.ID)
+		r.True(isSealed(sealed.Value))
+		r.NotContains(string(sealed.Value.Bytes()), "hunter2")
+
+		again, err := sealAttr(kr, attr)
+		r.NoError(err)
+		r.NotEqual(sealed.Value.Bytes(), again.Value.Bytes(), "nonces should differ")
+	})
+
+	t.Run("opens to the plaintext", func(t *testing.T) {
+		r := require.New(t)
+
+		opened, err := openAttr(kr, sealed)
+		r.NoError(err)
+		r.True(attr.Equal(opened))
+	})
+
+	t.Run("keeps the value's kind", func(t *testing.T) {
+		r := require.New(t)
+
+		n := Int64("test/pin", 1234)
+
+		s, err := sealAttr(kr, n)
+		r.NoError(err)
+
+		opened, err := openAttr(kr, s)
+		r.NoError(err)
+		r.Equal(int64(1234), opened.Value.Int64())
+	})
+
+	t.Run("opens with a rotated out key", func(t *testing.T) {
+		r := require.New(t)
+
+		rotated := testKeyring(t, "k2", 2)
+		r.NoError(rotated.Add("k1", bytes.Repeat([]byte{1}, 32)))
+
+		opened, err := openAttr(rotated, sealed)
+		r.NoError(err)
+		r.True(attr.Equal(opened))
+	})
+
+	t.Run("fails with the wrong key", func(t *testing.T) {
+		r := require.New(t)
+
+		_, err := openAttr(testKeyring(t, "k1", 9), sealed)
+		r.ErrorIs(err, ErrBadSeal)
+
+		_, err = openAttr(testKeyring(t, "other", 1), sealed)
+		r.ErrorIs(err, ErrUnknownKey)
+
+		_, err = openAttr(nil, sealed)
+		r.ErrorIs(err, ErrNoKeyring)
+	})
+
+	t.Run("fails when moved to another attribute", func(t *testing.T) {
+		r := require.New(t)
+
+		moved := Attr{ID: "test/other", Value: sealed.Value}
+
+		_, err := openAttr(kr, moved)
+		r.ErrorIs(err, ErrBadSeal)
+	})
+}
+
+func TestLoadNodeKeyring(t *testing.T) {
+	r := require.New(t)
+
+	path := filepath.Join(t.TempDir(), "keys", "node.key")
+
+	kr, err := LoadNodeKeyring(path)
+	r.NoError(err)
+
+	id, key, err := kr.Current()
+	r.NoError(err)
+	r.Len(key, 32)
+
+	again, err := LoadNodeKeyring(path)
+	r.NoError(err)
+
+	id2, key2, err := again.Current()
+	r.NoError(err)
+	r.Equal(id, id2)
+	r.Equal(key, key2)
+}
@@ -25,6 +25,8 @@ var (
 	ErrAttributeNotFound   = errors.New("attribute not found")
 	ErrInvalidAttribute    = errors.New("invalid attribute")
 	ErrSchemaNotFound      = errors.New("schema not found")
+	ErrRequiredAttr        = errors.New("required attribute missing")
+	ErrInvalidChoice       = errors.New("invalid choice for attribute")
 )
 
 type uniq int
@@ -46,6 +48,7 @@ type AttributeSchema struct {
 	AllowMany  bool
 	Index      bool
 	Session    bool
+	Encrypted  bool
 	Predicate  []*Entity
 	CheckProgs []string
 	Tags       []string
@@ -466,6 +469,12 @@ func convertEntityToSchema(ctx context.Context, s EntityStore, entity *Entity) (
 			} else {
 				return nil, fmt.Errorf("invalid index: %v", attr.Value.Any())
 			}
+		case Encrypted:
+			if val, ok := attr.Value.Any().(bool); ok {
+				schema.Encrypted = val
+			} else {
+				return nil, fmt.Errorf("invalid encrypted: %v", attr.Value.Any())
+			}
 		case Tag:
 			if val, ok := attr.Value.Any().(string); ok {
 				schema.Tags = append(schema.Tags, val)
@@ -106,6 +106,11 @@ func (m *MockStore) CreateEntity(ctx context.Context, entity *Entity, opts ...En
 }
 
 func (m *MockStore) UpdateEntity(ctx context.Context, id Id, entity *Entity, opts ...EntityOption) (*Entity, error) {
+	var o entityOpts
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	m.mu.Lock()
 	e, ok := m.Entities[id]
 	if !ok {
@@ -113,6 +118,11 @@ func (m *MockStore) UpdateEntity(ctx context.Context, id Id, entity *Entity, opt
 		return nil, cond.NotFound("entity", id)
 	}
 
+	if o.fromRevision != 0 && e.GetRevision() != o.fromRevision {
+		m.mu.Unlock()
+		return nil, cond.Conflict("entity", id)
+	}
+
 	// Build combined attribute list
 	combinedAttrs := make([]Attr, 0, len(e.attrs))
 
@@ -159,6 +169,11 @@ func (m *MockStore) ReplaceEntity(ctx context.Context, entity *Entity, opts ...E
 		return nil, cond.NotFound("entity", "empty id")
 	}
 
+	var o entityOpts
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	m.mu.Lock()
 	existing, ok := m.Entities[id]
 	if !ok {
@@ -166,6 +181,11 @@ func (m *MockStore) ReplaceEntity(ctx context.Context, entity *Entity, opts ...E
 		return nil, cond.NotFound("entity", id)
 	}
 
+	if o.fromRevision != 0 && existing.GetRevision() != o.fromRevision {
+		m.mu.Unlock()
+		return nil, cond.Conflict("entity", id)
+	}
+
 	// Update revision and timestamp
 	entity.SetRevision(existing.GetRevision() + 1)
 	entity.SetUpdatedAt(m.Now())
@@ -77,6 +77,24 @@ func (b *SchemaBuilder) Apply(ctx context.Context, store entity.Store) error {
 	return nil
 }
 
+// EncryptedAttrs returns the ids of the registered attributes whose values
+// are encrypted at rest.
+func EncryptedAttrs() []entity.Id {
+	var ids []entity.Id
+
+	for _, schema := range defaultRegistry.schemas {
+		for eid, e := range schema.attrs {
+			if attr, ok := e.Get(entity.Encrypted); ok && attr.Value.Bool() {
+				ids = append(ids, eid)
+			}
+		}
+	}
+
+	slices.Sort(ids)
+
+	return ids
+}
+
 func Apply(ctx context.Context, store entity.Store) error {
 	//defaultRegistry.mu.Lock()
 	//defer defaultRegistry.mu.Unlock()
@@ -134,12 +152,13 @@ func (b *SchemaBuilder) Id(name string) entity.Id {
 }
 
 type attrBuilder struct {
-	card     entity.Id
-	doc      string
-	required bool
-	indexed  bool
-	session  bool
-	tags     []string
+	card      entity.Id
+	doc       string
+	required  bool
+	indexed   bool
+	session   bool
+	encrypted bool
+	tags      []string
 
 	choises []entity.Id
 
@@ -170,6 +189,12 @@ func Session(b *attrBuilder) {
 	b.session = true
 }
 
+// Encrypted marks an attribute whose values are encrypted at rest. Encrypted
+// attributes can't be indexed.
+func Encrypted(b *attrBuilder) {
+	b.encrypted = true
+}
+
 func Tags(tags ...string) AttrOption {
 	return func(b *attrBuilder) {
 		b.tags = append(b.tags, tags...)
@@ -217,6 +242,14 @@ func (s *SchemaBuilder) Attr(name, id string, typ entity.Id, opts ...AttrOption)
 		attrs = append(attrs, entity.Session, true)
 	}
 
+	if ab.encrypted {
+		if ab.indexed {
+			panic("Attribute can't be both encrypted and indexed: " + string(eid))
+		}
+
+		attrs = append(attrs, entity.Encrypted, true)
+	}
+
 	for _, tag := range ab.tags {
 		attrs = append(attrs, entity.Tag, tag)
 	}
@@ -0,0 +1,133 @@
+package entity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// ErrReadOnly is returned by the methods of a SnapshotStore that would
+// change the store or watch it for changes.
+var ErrReadOnly = errors.New("store snapshot is read-only")
+
+// SnapshotStore is a read-only view of an EtcdStore pinned to a revision.
+// Every read sees the store as it was at that revision, so something that
+// makes many reads, like a report, works from one consistent state while
+// writes carry on.
+//
+// Reads fail once etcd compacts past the revision.
+type SnapshotStore struct {
+	store *EtcdStore
+	rev   int64
+}
+
+var _ Store = (*SnapshotStore)(nil)
+
+// SnapshotView opens a view of the store as of rev, or of its current
+// revision if rev is 0.
+func (s *EtcdStore) SnapshotView(ctx context.Context, rev int64) (*SnapshotStore, error) {
+	if rev < 0 {
+		return nil, fmt.Errorf("invalid snapshot revision %d", rev)
+	}
+
+	// Read the revision up front, both to find the current one and to fail
+	// now if it's compacted or in the future.
+	resp, err := s.client.Get(ctx, s.prefix+"/", atRevision(rev, clientv3.WithPrefix(), clientv3.WithCountOnly())...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open snapshot at revision %d: %w", rev, err)
+	}
+
+	if rev == 0 {
+		rev = resp.Header.Revision
+	}
+
+	return &SnapshotStore{store: s, rev: rev}, nil
+}
+
+// atRevision adds the option to read at rev to opts, unless rev is 0.
+func atRevision(rev int64, opts ...clientv3.OpOption) []clientv3.OpOption {
+	if rev == 0 {
+		return opts
+	}
+
+	return append(opts, clientv3.WithRev(rev))
+}
+
+// Revision returns the revision the view is pinned to.
+func (s *SnapshotStore) Revision() int64 {
+	return s.rev
+}
+
+func (s *SnapshotStore) GetEntity(ctx context.Context, id Id) (*Entity, error) {
+	return s.store.getEntity(ctx, id, s.rev)
+}
+
+func (s *SnapshotStore) GetEntities(ctx context.Context, ids []Id) ([]*Entity, error) {
+	return s.store.getEntities(ctx, ids, s.rev)
+}
+
+// GetAttributeSchema returns the current schema, as schemas are only ever
+// added to.
+func (s *SnapshotStore) GetAttributeSchema(ctx context.Context, id Id) (*AttributeSchema, error) {
+	return s.store.GetAttributeSchema(ctx, id)
+}
+
+func (s *SnapshotStore) ListIndex(ctx context.Context, attr Attr) ([]Id, error) {
+	return s.store.listIndex(ctx, attr, s.rev)
+}
+
+func (s *SnapshotStore) ListCollection(ctx context.Context, collection string) ([]Id, error) {
+	return s.store.listCollection(ctx, collection, s.rev)
+}
+
+func (s *SnapshotStore) WatchEntity(ctx context.Context, id Id) (chan EntityOp, error) {
+	return nil, ErrReadOnly
+}
+
+func (s *SnapshotStore) WatchIndex(ctx context.Context, attr Attr) (clientv3.WatchChan, error) {
+	return nil, ErrReadOnly
+}
+
+func (s *SnapshotStore) CreateEntity(ctx context.Context, entity *Entity, opts ...EntityOption) (*Entity, error) {
+	return nil, ErrReadOnly
+}
+
+func (s *SnapshotStore) UpdateEntity(ctx context.Context, id Id, entity *Entity, opts ...EntityOption) (*Entity, error) {
+	return nil, ErrReadOnly
+}
+
+func (s *SnapshotStore) ReplaceEntity(ctx context.Context, entity *Entity, opts ...EntityOption) (*Entity, error) {
+	return nil, ErrReadOnly
+}
+
+func (s *SnapshotStore) PatchEntity(ctx context.Context, entity *Entity, opts ...EntityOption) (*Entity, error) {
+	return nil, ErrReadOnly
+}
+
+func (s *SnapshotStore) EnsureEntity(ctx context.Context, entity *Entity, opts ...EntityOption) (*Entity, bool, error) {
+	return nil, false, ErrReadOnly
+}
+
+func (s *SnapshotStore) DeleteEntity(ctx context.Context, id Id) error {
+	return ErrReadOnly
+}
+
+func (s *SnapshotStore) CreateSession(ctx context.Context, ttl int64) ([]byte, error) {
+	return nil, ErrReadOnly
+}
+
+func (s *SnapshotStore) RevokeSession(ctx context.Context, session []byte) error {
+	return ErrReadOnly
+}
+
+func (s *SnapshotStore) PingSession(ctx context.Context, session []byte) error {
+	return ErrReadOnly
+}
+
+// ListSessionEntities lists the entities currently attached to session, as
+// etcd doesn't keep the history of leases.
+func (s *SnapshotStore) ListSessionEntities(ctx context.Context, session []byte) ([]Id, error) {
+	return s.store.ListSessionEntities(ctx, session)
+}
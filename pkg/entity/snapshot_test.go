@@ -0,0 +1,127 @@
+package entity
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEtcdStore_SnapshotView(t *testing.T) {
+	client := setupTestEtcd(t)
+	store, err := NewEtcdStore(t.Context(), slog.Default(), client, "/test-entities")
+	require.NoError(t, err)
+
+	kind, err := store.CreateEntity(t.Context(), New(
+		Any(Ident, KeywordValue("test/kind")),
+	))
+	require.NoError(t, err)
+
+	first, err := store.CreateEntity(t.Context(), New(
+		Any(Ident, KeywordValue("test1")),
+		Any(Doc, "original doc"),
+		Ref(EntityKind, kind.Id()),
+	))
+	require.NoError(t, err)
+
+	second, err := store.CreateEntity(t.Context(), New(
+		Any(Ident, KeywordValue("test2")),
+		Ref(EntityKind, kind.Id()),
+	))
+	require.NoError(t, err)
+
+	byKind := Ref(EntityKind, kind.Id())
+
+	t.Run("doesn't observe writes made after it was opened", func(t *testing.T) {
+		r := require.New(t)
+
+		view, err := store.SnapshotView(t.Context(), 0)
+		r.NoError(err)
+		r.NotZero(view.Revision())
+
+		_, err = store.UpdateEntity(t.Context(), first.Id(), New(Any(Doc, "updated doc")))
+		r.NoError(err)
+
+		_, err = store.CreateEntity(t.Context(), New(
+			Any(Ident, KeywordValue("test3")),
+			Ref(EntityKind, kind.Id()),
+		))
+		r.NoError(err)
+
+		r.NoError(store.DeleteEntity(t.Context(), second.Id()))
+
+		got, err := view.GetEntity(t.Context(), first.Id())
+		r.NoError(err)
+
+		doc, ok := got.Get(Doc)
+		r.True(ok)
+		r.Equal("original doc", doc.Value.String())
+		r.Equal(first.GetRevision(), got.GetRevision())
+
+		got, err = view.GetEntity(t.Context(), second.Id())
+		r.NoError(err)
+		r.Equal(second.Id(), got.Id())
+
+		ents, err := view.GetEntities(t.Context(), []Id{first.Id(), second.Id()})
+		r.NoError(err)
+		r.Len(ents, 2)
+		r.NotNil(ents[1])
+
+		ids, err := view.ListIndex(t.Context(), byKind)
+		r.NoError(err)
+		r.ElementsMatch([]Id{first.Id(), second.Id()}, ids)
+
+		// The store itself has moved on
+		ids, err = store.ListIndex(t.Context(), byKind)
+		r.NoError(err)
+		r.Len(ids, 2)
+		r.NotContains(ids, second.Id())
+
+		got, err = store.GetEntity(t.Context(), first.Id())
+		r.NoError(err)
+
+		doc, ok = got.Get(Doc)
+		r.True(ok)
+		r.Equal("updated doc", doc.Value.String())
+	})
+
+	t.Run("can be opened at an earlier revision", func(t *testing.T) {
+		r := require.New(t)
+
+		view, err := store.SnapshotView(t.Context(), first.GetRevision())
+		r.NoError(err)
+		r.Equal(first.GetRevision(), view.Revision())
+
+		ids, err := view.ListIndex(t.Context(), byKind)
+		r.NoError(err)
+		r.Equal([]Id{first.Id()}, ids)
+
+		_, err = view.GetEntity(t.Context(), second.Id())
+		r.Error(err)
+	})
+
+	t.Run("rejects writes", func(t *testing.T) {
+		r := require.New(t)
+
+		view, err := store.SnapshotView(t.Context(), 0)
+		r.NoError(err)
+
+		_, err = view.CreateEntity(t.Context(), New(Any(Ident, KeywordValue("test4"))))
+		r.ErrorIs(err, ErrReadOnly)
+
+		_, err = view.UpdateEntity(t.Context(), first.Id(), New(Any(Doc, "nope")))
+		r.ErrorIs(err, ErrReadOnly)
+
+		r.ErrorIs(view.DeleteEntity(t.Context(), first.Id()), ErrReadOnly)
+
+		_, err = view.WatchIndex(t.Context(), byKind)
+		r.ErrorIs(err, ErrReadOnly)
+	})
+
+	t.Run("rejects revisions that don't exist yet", func(t *testing.T) {
+		r := require.New(t)
+
+		_, err := store.SnapshotView(t.Context(), 1<<40)
+		r.Error(err)
+	})
+}
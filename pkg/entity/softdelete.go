@@ -0,0 +1,257 @@
+package entity
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mr-tron/base58"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"miren.dev/runtime/pkg/cond"
+)
+
+// DeletedEntity is an entity that was soft deleted and can still be
+// recovered with UndeleteEntity.
+type DeletedEntity struct {
+	*Entity
+
+	DeletedAt time.Time
+}
+
+// tombstone is what's stored for a soft deleted entity, its data as it was
+// stored before the delete.
+type tombstone struct {
+	Entity    []byte `cbor:"entity"`
+	DeletedAt int64  `cbor:"deleted_at"`
+}
+
+// SetDeleteRetention turns on soft deletes. DeleteEntity then moves
+// entities aside rather than removing them, hiding them from reads, lists
+// and indexes while keeping them recoverable with UndeleteEntity until
+// PurgeDeleted removes them once retention has passed. Entities bound to a
+// session are always deleted outright, as they'd have expired with it.
+func (s *EtcdStore) SetDeleteRetention(retention time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deleteRetention = retention
+}
+
+func (s *EtcdStore) currentDeleteRetention() time.Duration {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.deleteRetention
+}
+
+func (s *EtcdStore) deletedKey(id Id) string {
+	return fmt.Sprintf("%s/deleted/%s", s.prefix, base58.Encode([]byte(id)))
+}
+
+// softDeleteOp returns the op that keeps an entity's stored value around as
+// a tombstone, or false if it should be deleted outright.
+func (s *EtcdStore) softDeleteOp(id Id, value []byte, lease int64) (clientv3.Op, bool, error) {
+	if s.currentDeleteRetention() <= 0 || lease != 0 {
+		return clientv3.Op{}, false, nil
+	}
+
+	data, err := encoder.Marshal(tombstone{
+		Entity:    value,
+		DeletedAt: time.Now().UnixMilli(),
+	})
+	if err != nil {
+		return clientv3.Op{}, false, fmt.Errorf("failed to encode deleted entity: %w", err)
+	}
+
+	return clientv3.OpPut(s.deletedKey(id), string(data)), true, nil
+}
+
+func decodeTombstone(value []byte) (tombstone, error) {
+	var ts tombstone
+
+	if err := decoder.Unmarshal(value, &ts); err != nil {
+		return ts, cond.Corruption("entity", "failed to deserialize deleted entity: %s", err)
+	}
+
+	return ts, nil
+}
+
+func (s *EtcdStore) decodeDeleted(ctx context.Context, value []byte) (*DeletedEntity, error) {
+	ts, err := decodeTombstone(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var entity Entity
+
+	if err := decoder.Unmarshal(ts.Entity, &entity); err != nil {
+		return nil, cond.Corruption("entity", "failed to deserialize deleted entity: %s", err)
+	}
+
+	if err := s.openAttrs(ctx, entity.attrs); err != nil {
+		return nil, err
+	}
+
+	entity.postUnmarshal()
+
+	return &DeletedEntity{
+		Entity:    &entity,
+		DeletedAt: time.UnixMilli(ts.DeletedAt),
+	}, nil
+}
+
+// GetDeletedEntity returns a soft deleted entity that hasn't been purged.
+func (s *EtcdStore) GetDeletedEntity(ctx context.Context, id Id) (*DeletedEntity, error) {
+	gr, err := s.client.Get(ctx, s.deletedKey(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deleted entity from etcd: %w", err)
+	}
+
+	if len(gr.Kvs) == 0 {
+		return nil, cond.NotFound("deleted entity", id)
+	}
+
+	return s.decodeDeleted(ctx, gr.Kvs[0].Value)
+}
+
+// ListDeletedEntities returns the soft deleted entities that haven't been
+// purged.
+func (s *EtcdStore) ListDeletedEntities(ctx context.Context) ([]*DeletedEntity, error) {
+	gr, err := s.client.Get(ctx, s.prefix+"/deleted/", clientv3.WithPrefix())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deleted entities: %w", err)
+	}
+
+	var deleted []*DeletedEntity
+
+	for _, kv := range gr.Kvs {
+		de, err := s.decodeDeleted(ctx, kv.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		deleted = append(deleted, de)
+	}
+
+	return deleted, nil
+}
+
+// UndeleteEntity restores a soft deleted entity, along with its index
+// entries. It fails with a conflict if an entity with the same id has been
+// created since.
+func (s *EtcdStore) UndeleteEntity(ctx context.Context, id Id) (*Entity, error) {
+	dkey := s.deletedKey(id)
+
+	gr, err := s.client.Get(ctx, dkey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deleted entity from etcd: %w", err)
+	}
+
+	if len(gr.Kvs) == 0 {
+		return nil, cond.NotFound("deleted entity", id)
+	}
+
+	ts, err := decodeTombstone(gr.Kvs[0].Value)
+	if err != nil {
+		return nil, err
+	}
+
+	de, err := s.decodeDeleted(ctx, gr.Kvs[0].Value)
+	if err != nil {
+		return nil, err
+	}
+
+	indexedAttrs, err := s.collectIndexedAttributes(ctx, de.attrs)
+	if err != nil {
+		return nil, err
+	}
+
+	key := s.buildKey(id)
+
+	ops := []clientv3.Op{
+		clientv3.OpPut(key, string(ts.Entity)),
+		clientv3.OpDelete(dkey),
+	}
+
+	for _, attrs := range indexedAttrs {
+		for _, attr := range attrs {
+			ops = append(ops, s.addToCollectionOp(de.Entity, attr.CAS()))
+		}
+	}
+
+	txnResp, err := s.client.Txn(ctx).
+		If(
+			clientv3.Compare(clientv3.CreateRevision(key), "=", 0),
+			clientv3.Compare(clientv3.ModRevision(dkey), "=", gr.Kvs[0].ModRevision),
+		).
+		Then(ops...).
+		Commit()
+	if err != nil {
+		return nil, fmt.Errorf("failed to undelete entity in etcd: %w", err)
+	}
+
+	if !txnResp.Succeeded {
+		return nil, cond.Conflict("entity", id)
+	}
+
+	return s.GetEntity(ctx, id)
+}
+
+// PurgeDeleted permanently removes the soft deleted entities whose
+// retention has passed, returning how many it removed.
+func (s *EtcdStore) PurgeDeleted(ctx context.Context) (int, error) {
+	gr, err := s.client.Get(ctx, s.prefix+"/deleted/", clientv3.WithPrefix())
+	if err != nil {
+		return 0, fmt.Errorf("failed to list deleted entities: %w", err)
+	}
+
+	cutoff := time.Now().Add(-s.currentDeleteRetention())
+
+	var purged int
+
+	for _, kv := range gr.Kvs {
+		ts, err := decodeTombstone(kv.Value)
+		if err != nil {
+			s.log.Warn("purging undecodable deleted entity", "key", string(kv.Key), "error", err)
+		} else if time.UnixMilli(ts.DeletedAt).After(cutoff) {
+			continue
+		}
+
+		// Skip it if it was undeleted, or deleted again, since we listed it
+		txnResp, err := s.client.Txn(ctx).
+			If(clientv3.Compare(clientv3.ModRevision(string(kv.Key)), "=", kv.ModRevision)).
+			Then(clientv3.OpDelete(string(kv.Key))).
+			Commit()
+		if err != nil {
+			return purged, fmt.Errorf("failed to purge deleted entity: %w", err)
+		}
+
+		if txnResp.Succeeded {
+			purged++
+		}
+	}
+
+	return purged, nil
+}
+
+// RunDeleteSweeper purges soft deleted entities every interval until ctx
+// is done.
+func (s *EtcdStore) RunDeleteSweeper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		purged, err := s.PurgeDeleted(ctx)
+		if err != nil {
+			s.log.Error("failed to purge deleted entities", "error", err)
+		} else if purged > 0 {
+			s.log.Info("purged deleted entities", "count", purged)
+		}
+	}
+}
@@ -25,8 +25,10 @@ type EtcdStore struct {
 	validator *Validator
 	prefix    string
 
-	schemaCache map[Id]*AttributeSchema
-	mu          sync.RWMutex
+	schemaCache     map[Id]*AttributeSchema
+	keyring         Keyring
+	deleteRetention time.Duration
+	mu              sync.RWMutex
 }
 
 type Store interface {
@@ -50,6 +52,12 @@ type Store interface {
 	ListSessionEntities(ctx context.Context, session []byte) ([]Id, error)
 }
 
+// IndexLister looks up entities by the value of an indexed attribute. It's
+// all the generated Find functions need from a store.
+type IndexLister interface {
+	ListIndex(ctx context.Context, attr Attr) ([]Id, error)
+}
+
 var ErrNotFound = errors.New("entity not found")
 
 var _ Store = (*EtcdStore)(nil)
@@ -213,7 +221,7 @@ func (s *EtcdStore) CreateEntity(
 
 	// Build entity save operations
 	key := s.buildKey(entity.Id())
-	txopt, err := s.buildEntitySaveOps(entity, key, primary, session, &o)
+	txopt, err := s.buildEntitySaveOps(ctx, entity, key, primary, session, &o)
 	if err != nil {
 		return nil, err
 	}
@@ -239,7 +247,7 @@ func (s *EtcdStore) CreateEntity(
 
 			var curr Entity
 
-			if decoder.Unmarshal(rng.Kvs[0].Value, &curr) == nil {
+			if decoder.Unmarshal(rng.Kvs[0].Value, &curr) == nil && s.openAttrs(ctx, curr.attrs) == nil {
 				if slices.EqualFunc(curr.attrs, entity.attrs, func(a, b Attr) bool {
 					return a.Equal(b)
 				}) {
@@ -273,11 +281,16 @@ func (s *EtcdStore) CreateEntity(
 
 // GetEntity implements Store interface
 func (s *EtcdStore) GetEntity(ctx context.Context, id Id) (*Entity, error) {
+	return s.getEntity(ctx, id, 0)
+}
+
+// getEntity reads the entity as of rev, or the latest revision if it's 0.
+func (s *EtcdStore) getEntity(ctx context.Context, id Id, rev int64) (*Entity, error) {
 	key := s.buildKey(id)
 
 	tr, err := s.client.Txn(ctx).Then(
-		clientv3.OpGet(key),
-		clientv3.OpGet(key+"/session/", clientv3.WithPrefix()),
+		clientv3.OpGet(key, atRevision(rev)...),
+		clientv3.OpGet(key+"/session/", atRevision(rev, clientv3.WithPrefix())...),
 	).Commit()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get entity from etcd: %w", err)
@@ -333,12 +346,20 @@ func (s *EtcdStore) GetEntity(ctx context.Context, id Id) (*Entity, error) {
 		entity.attrs = append(entity.attrs, attrs...)
 	}
 
+	if err := s.openAttrs(ctx, entity.attrs); err != nil {
+		return nil, err
+	}
+
 	entity.postUnmarshal()
 
 	return &entity, nil
 }
 
 func (s *EtcdStore) GetEntities(ctx context.Context, ids []Id) ([]*Entity, error) {
+	return s.getEntities(ctx, ids, 0)
+}
+
+func (s *EtcdStore) getEntities(ctx context.Context, ids []Id, rev int64) ([]*Entity, error) {
 	if len(ids) == 0 {
 		return []*Entity{}, nil
 	}
@@ -358,8 +379,8 @@ func (s *EtcdStore) GetEntities(ctx context.Context, ids []Id) ([]*Entity, error
 		var ops []clientv3.Op
 		for _, id := range batchIds {
 			key := s.buildKey(id)
-			ops = append(ops, clientv3.OpGet(key))
-			ops = append(ops, clientv3.OpGet(key+"/session/", clientv3.WithPrefix()))
+			ops = append(ops, clientv3.OpGet(key, atRevision(rev)...))
+			ops = append(ops, clientv3.OpGet(key+"/session/", atRevision(rev, clientv3.WithPrefix())...))
 		}
 
 		// Execute transaction for this batch
@@ -417,6 +438,10 @@ func (s *EtcdStore) GetEntities(ctx context.Context, ids []Id) ([]*Entity, error
 				entity.attrs = append(entity.attrs, attrs...)
 			}
 
+			if err := s.openAttrs(ctx, entity.attrs); err != nil {
+				return nil, err
+			}
+
 			entity.postUnmarshal()
 			entities[start+i] = &entity
 		}
@@ -489,6 +514,11 @@ func (s *EtcdStore) WatchEntity(ctx context.Context, id Id) (chan EntityOp, erro
 
 						entity.SetRevision(event.Kv.ModRevision)
 
+						if err := s.openAttrs(ctx, entity.attrs); err != nil {
+							s.log.Error("failed to decrypt entity for event", "error", err, "id", entity.Id())
+							continue
+						}
+
 						entity.postUnmarshal()
 						op.Entity = &entity
 					}
@@ -619,7 +649,7 @@ func (s *EtcdStore) UpdateEntity(
 
 	// Build entity save operations
 	key := s.buildKey(entity.Id())
-	txopt, err := s.buildEntitySaveOps(entity, key, primary, session, &o)
+	txopt, err := s.buildEntitySaveOps(ctx, entity, key, primary, session, &o)
 	if err != nil {
 		return nil, err
 	}
@@ -762,14 +792,24 @@ func (s *EtcdStore) buildCollectionOps(entity *Entity, originalIndexedAttrs, new
 }
 
 // buildEntitySaveOps builds etcd operations for saving entity data (primary and session attributes)
-func (s *EtcdStore) buildEntitySaveOps(entity *Entity, key string, primary, session []Attr, o *entityOpts) ([]clientv3.Op, error) {
+func (s *EtcdStore) buildEntitySaveOps(ctx context.Context, entity *Entity, key string, primary, session []Attr, o *entityOpts) ([]clientv3.Op, error) {
 	var ops []clientv3.Op
 
 	entity.attrs = primary
 	// Store manages UpdatedAt - set it on every save
 	entity.SetUpdatedAt(time.Now())
 
-	data, err := encoder.Marshal(entity)
+	sealed, err := s.sealAttrs(ctx, entity.attrs)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err = s.sealAttrs(ctx, session)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := encoder.Marshal(&Entity{attrs: sealed})
 	if err != nil {
 		return nil, fmt.Errorf("failed to serialize entity: %w", err)
 	}
@@ -875,7 +915,7 @@ func (s *EtcdStore) ReplaceEntity(
 
 	// Build entity save operations
 	key := s.buildKey(repl.Id())
-	txopt, err := s.buildEntitySaveOps(repl, key, primary, session, &o)
+	txopt, err := s.buildEntitySaveOps(ctx, repl, key, primary, session, &o)
 	if err != nil {
 		return nil, err
 	}
@@ -1000,7 +1040,7 @@ func (s *EtcdStore) PatchEntity(
 
 	// Build entity save operations
 	key := s.buildKey(entity.Id())
-	txopt, err := s.buildEntitySaveOps(entity, key, primary, session, &o)
+	txopt, err := s.buildEntitySaveOps(ctx, entity, key, primary, session, &o)
 	if err != nil {
 		return nil, err
 	}
@@ -1107,10 +1147,31 @@ func (s *EtcdStore) DeleteEntity(ctx context.Context, id Id) error {
 
 	key := s.buildKey(id)
 
+	ops := []clientv3.Op{clientv3.OpDelete(key)}
+
+	// With soft deletes on, keep the stored entity aside so it can be undeleted
+	if s.currentDeleteRetention() > 0 {
+		gr, err := s.client.Get(ctx, key)
+		if err != nil {
+			return fmt.Errorf("failed to get entity from etcd: %w", err)
+		}
+
+		if len(gr.Kvs) > 0 {
+			op, ok, err := s.softDeleteOp(id, gr.Kvs[0].Value, gr.Kvs[0].Lease)
+			if err != nil {
+				return err
+			}
+
+			if ok {
+				ops = append(ops, op)
+			}
+		}
+	}
+
 	// Use Txn to check that the key exists before deleting
 	txnResp, err := s.client.Txn(ctx).
 		If(clientv3.Compare(clientv3.ModRevision(key), "=", entity.GetRevision())).
-		Then(clientv3.OpDelete(key)).
+		Then(ops...).
 		Commit()
 
 	if err != nil {
@@ -1195,6 +1256,10 @@ func (s *EtcdStore) deleteFromCollection(entity *Entity, collection string) erro
 }
 
 func (s *EtcdStore) ListIndex(ctx context.Context, attr Attr) ([]Id, error) {
+	return s.listIndex(ctx, attr, 0)
+}
+
+func (s *EtcdStore) listIndex(ctx context.Context, attr Attr, rev int64) ([]Id, error) {
 	if attr.ID == DBId {
 		if attr.Value.Kind() != KindId {
 			return nil, cond.ValidationFailure("attribute", "invalid value type for ID")
@@ -1202,7 +1267,7 @@ func (s *EtcdStore) ListIndex(ctx context.Context, attr Attr) ([]Id, error) {
 
 		id := attr.Value.Id()
 
-		gr, err := s.client.Get(ctx, s.buildKey(id), clientv3.WithCountOnly())
+		gr, err := s.client.Get(ctx, s.buildKey(id), atRevision(rev, clientv3.WithCountOnly())...)
 		if err != nil {
 			return nil, err
 		}
@@ -1223,7 +1288,7 @@ func (s *EtcdStore) ListIndex(ctx context.Context, attr Attr) ([]Id, error) {
 		return nil, fmt.Errorf("attribute %s is not indexed", attr.ID)
 	}
 
-	return s.ListCollection(ctx, attr.CAS())
+	return s.listCollection(ctx, attr.CAS(), rev)
 }
 
 func (s *EtcdStore) IndexPrefix(ctx context.Context, attr Attr) (string, error) {
@@ -1302,11 +1367,15 @@ func (s *EtcdStore) WatchIndex(ctx context.Context, attr Attr) (clientv3.WatchCh
 var tr = strings.NewReplacer("/", "_", ":", "_")
 
 func (s *EtcdStore) ListCollection(ctx context.Context, collection string) ([]Id, error) {
+	return s.listCollection(ctx, collection, 0)
+}
+
+func (s *EtcdStore) listCollection(ctx context.Context, collection string, rev int64) ([]Id, error) {
 	colKey := tr.Replace(collection)
 
 	prefix := fmt.Sprintf("%s/collections/%s/", s.prefix, colKey)
 
-	resp, err := s.client.Get(ctx, prefix, clientv3.WithPrefix())
+	resp, err := s.client.Get(ctx, prefix, atRevision(rev, clientv3.WithPrefix())...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list entities from etcd: %w", err)
 	}
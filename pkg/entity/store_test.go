@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log/slog"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -13,6 +14,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.etcd.io/etcd/api/v3/mvccpb"
 	clientv3 "go.etcd.io/etcd/client/v3"
+	"miren.dev/runtime/pkg/cond"
 )
 
 func setupTestEtcd(t *testing.T) *clientv3.Client {
@@ -669,6 +671,123 @@ func TestEtcdStore_DeleteEntity(t *testing.T) {
 	}
 }
 
+func TestEtcdStore_SoftDelete(t *testing.T) {
+	client := setupTestEtcd(t)
+	store, err := NewEtcdStore(t.Context(), slog.Default(), client, "/test-entities")
+	require.NoError(t, err)
+
+	k, err := store.CreateEntity(t.Context(), New(
+		Any(Ident, KeywordValue("test/kind")),
+	))
+	require.NoError(t, err)
+
+	create := func(t *testing.T, ident string) *Entity {
+		e, err := store.CreateEntity(t.Context(), New(
+			Any(Ident, KeywordValue(ident)),
+			Any(Doc, "test document"),
+			Ref(EntityKind, k.Id()),
+		))
+		require.NoError(t, err)
+		return e
+	}
+
+	t.Run("hides deleted entities until they're undeleted", func(t *testing.T) {
+		r := require.New(t)
+
+		store.SetDeleteRetention(time.Hour)
+
+		e := create(t, "soft1")
+
+		r.NoError(store.DeleteEntity(t.Context(), e.Id()))
+
+		_, err := store.GetEntity(t.Context(), e.Id())
+		r.ErrorIs(err, cond.ErrNotFound{})
+
+		ids, err := store.ListIndex(t.Context(), Ref(EntityKind, k.Id()))
+		r.NoError(err)
+		r.NotContains(ids, e.Id())
+
+		de, err := store.GetDeletedEntity(t.Context(), e.Id())
+		r.NoError(err)
+		r.Equal(e.Id(), de.Id())
+		r.WithinDuration(time.Now(), de.DeletedAt, time.Minute)
+
+		deleted, err := store.ListDeletedEntities(t.Context())
+		r.NoError(err)
+		r.Len(deleted, 1)
+
+		// Still within the window, so the sweeper leaves it alone
+		purged, err := store.PurgeDeleted(t.Context())
+		r.NoError(err)
+		r.Zero(purged)
+
+		restored, err := store.UndeleteEntity(t.Context(), e.Id())
+		r.NoError(err)
+
+		doc, ok := restored.Get(Doc)
+		r.True(ok)
+		r.Equal("test document", doc.Value.String())
+
+		ids, err = store.ListIndex(t.Context(), Ref(EntityKind, k.Id()))
+		r.NoError(err)
+		r.Contains(ids, e.Id())
+
+		_, err = store.GetDeletedEntity(t.Context(), e.Id())
+		r.ErrorIs(err, cond.ErrNotFound{})
+	})
+
+	t.Run("won't undelete over a recreated entity", func(t *testing.T) {
+		r := require.New(t)
+
+		store.SetDeleteRetention(time.Hour)
+
+		e := create(t, "soft2")
+		r.NoError(store.DeleteEntity(t.Context(), e.Id()))
+
+		_, err := store.CreateEntity(t.Context(), New(
+			Ref(DBId, e.Id()),
+			Any(Doc, "replacement"),
+		))
+		r.NoError(err)
+
+		_, err = store.UndeleteEntity(t.Context(), e.Id())
+		r.ErrorIs(err, cond.ErrConflict{})
+	})
+
+	t.Run("purges deleted entities once the window passes", func(t *testing.T) {
+		r := require.New(t)
+
+		_, err := client.Delete(t.Context(), "/test-entities/deleted/", clientv3.WithPrefix())
+		r.NoError(err)
+
+		store.SetDeleteRetention(100 * time.Millisecond)
+
+		e := create(t, "soft3")
+		r.NoError(store.DeleteEntity(t.Context(), e.Id()))
+
+		time.Sleep(200 * time.Millisecond)
+
+		purged, err := store.PurgeDeleted(t.Context())
+		r.NoError(err)
+		r.Equal(1, purged)
+
+		_, err = store.UndeleteEntity(t.Context(), e.Id())
+		r.ErrorIs(err, cond.ErrNotFound{})
+	})
+
+	t.Run("deletes outright without a retention", func(t *testing.T) {
+		r := require.New(t)
+
+		store.SetDeleteRetention(0)
+
+		e := create(t, "soft4")
+		r.NoError(store.DeleteEntity(t.Context(), e.Id()))
+
+		_, err := store.GetDeletedEntity(t.Context(), e.Id())
+		r.ErrorIs(err, cond.ErrNotFound{})
+	})
+}
+
 func TestEtcdStore_ListIndex(t *testing.T) {
 	client := setupTestEtcd(t)
 	store, err := NewEtcdStore(t.Context(), slog.Default(), client, "/test-entities")
@@ -1523,6 +1642,62 @@ func TestEtcdStore_PatchEntity(t *testing.T) {
 		r.True(ok)
 		r.Equal("patched without rev check", doc.Value.String())
 	})
+
+	t.Run("concurrent writers at the same revision conflict", func(t *testing.T) {
+		r := require.New(t)
+
+		initial, err := store.CreateEntity(t.Context(), New(
+			Any(Ident, "test-patch-concurrent"),
+			Any(Doc, "initial doc"),
+		))
+		r.NoError(err)
+
+		const writers = 8
+
+		var (
+			wg        sync.WaitGroup
+			start     = make(chan struct{})
+			errs      = make([]error, writers)
+			winnerDoc string
+		)
+
+		for i := range writers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				<-start
+
+				_, errs[i] = store.PatchEntity(t.Context(), New(
+					Any(DBId, initial.Id()),
+					Any(Doc, fmt.Sprintf("writer %d", i)),
+				), WithFromRevision(initial.GetRevision()))
+			}()
+		}
+
+		close(start)
+		wg.Wait()
+
+		var won int
+		for i, err := range errs {
+			if err == nil {
+				won++
+				winnerDoc = fmt.Sprintf("writer %d", i)
+				continue
+			}
+
+			r.ErrorIs(err, cond.ErrConflict{})
+		}
+
+		r.Equal(1, won, "exactly one writer should win the revision")
+
+		// The losers must not have clobbered the winner's write
+		final, err := store.GetEntity(t.Context(), initial.Id())
+		r.NoError(err)
+
+		doc, ok := final.Get(Doc)
+		r.True(ok)
+		r.Equal(winnerDoc, doc.Value.String())
+	})
 }
 
 func TestEtcdStore_EnsureEntity(t *testing.T) {
@@ -2110,3 +2285,90 @@ func TestExtractEntityId(t *testing.T) {
 		assert.Contains(t, err.Error(), "invalid db/id attribute type")
 	})
 }
+
+func TestEtcdStore_EncryptedAttr(t *testing.T) {
+	client := setupTestEtcd(t)
+	ctx := t.Context()
+
+	store, err := NewEtcdStore(ctx, slog.Default(), client, "/test-entities")
+	require.NoError(t, err)
+
+	key, err := NewStaticKeyring("node", []byte("0123456789abcdef0123456789abcdef"))
+	require.NoError(t, err)
+
+	store.SetKeyring(key)
+
+	_, err = store.CreateEntity(ctx, New(
+		Ident, "test/token",
+		Doc, "An API token",
+		Cardinality, CardinalityOne,
+		Type, TypeStr,
+		Encrypted, true,
+	))
+	require.NoError(t, err)
+
+	created, err := store.CreateEntity(ctx, New(
+		Ident, "test/service",
+		Any("test/token", "tok-very-secret"),
+	))
+	require.NoError(t, err)
+
+	token := func(e *Entity) string {
+		attr, ok := e.Get("test/token")
+		require.True(t, ok, "entity has no token")
+		return attr.Value.String()
+	}
+
+	assert.Equal(t, "tok-very-secret", token(created),
+		"the returned entity should have the plaintext")
+
+	t.Run("stored bytes are ciphertext", func(t *testing.T) {
+		resp, err := client.Get(ctx, store.buildKey(created.Id()))
+		require.NoError(t, err)
+		require.Len(t, resp.Kvs, 1)
+
+		assert.NotContains(t, string(resp.Kvs[0].Value), "tok-very-secret")
+		assert.Contains(t, string(resp.Kvs[0].Value), string(sealedMagic))
+	})
+
+	t.Run("reads return plaintext", func(t *testing.T) {
+		got, err := store.GetEntity(ctx, created.Id())
+		require.NoError(t, err)
+		assert.Equal(t, "tok-very-secret", token(got))
+
+		all, err := store.GetEntities(ctx, []Id{created.Id()})
+		require.NoError(t, err)
+		require.Len(t, all, 1)
+		assert.Equal(t, "tok-very-secret", token(all[0]))
+	})
+
+	t.Run("reads with the wrong key fail", func(t *testing.T) {
+		other, err := NewEtcdStore(ctx, slog.Default(), client, "/test-entities")
+		require.NoError(t, err)
+
+		wrong, err := NewStaticKeyring("node", []byte("fedcba9876543210fedcba9876543210"))
+		require.NoError(t, err)
+
+		other.SetKeyring(wrong)
+
+		_, err = other.GetEntity(ctx, created.Id())
+		assert.ErrorIs(t, err, ErrBadSeal)
+
+		other.SetKeyring(nil)
+
+		_, err = other.GetEntity(ctx, created.Id())
+		assert.ErrorIs(t, err, ErrNoKeyring)
+	})
+	t.Run("a store without a keyring fails the startup check", func(t *testing.T) {
+		other, err := NewEtcdStore(ctx, slog.Default(), client, "/test-entities")
+		require.NoError(t, err)
+
+		err = other.CheckKeyring(ctx, []Id{"test/token"})
+		assert.ErrorIs(t, err, ErrNoKeyring)
+
+		assert.NoError(t, other.CheckKeyring(ctx, []Id{Doc}))
+
+		other.SetKeyring(key)
+		assert.NoError(t, other.CheckKeyring(ctx, []Id{"test/token"}))
+	})
+}
@@ -37,9 +37,10 @@ const (
 	TypeLabel     Id = "db/type.label"
 	TypeBytes     Id = "db/type.bytes"
 
-	Index   Id = "db/index"
-	Session Id = "db/session"
-	Tag     Id = "db/tag"
+	Index     Id = "db/index"
+	Session   Id = "db/session"
+	Encrypted Id = "db/encrypted"
+	Tag       Id = "db/tag"
 
 	AttrSession Id = "db/attr.session"
 
@@ -152,6 +153,13 @@ func InitSystemEntities(save func(*Entity) error) error {
 		Type, TypeBool,
 	)
 
+	encrypted := New(
+		Ident, types.Keyword(Encrypted),
+		Doc, "Values of this attribute are encrypted at rest",
+		Cardinality, CardinalityOne,
+		Type, TypeBool,
+	)
+
 	tag := New(
 		Ident, types.Keyword(Tag),
 		Doc, "Tags for categorizing attributes",
@@ -307,7 +315,7 @@ func InitSystemEntities(save func(*Entity) error) error {
 		ident, doc, uniq, card, typ, enumValues, enumType,
 		uniqueIdentity, uniqueValue, cardOne, cardMany,
 		typeAny, typeRef, typeStr, typeKW, typeInt, typeFloat, typeBool, typeTime, typeEnum,
-		typeArray, typeDuration, typeComponent, typeLabel, typeBytes, index, session, tag, ttl,
+		typeArray, typeDuration, typeComponent, typeLabel, typeBytes, index, session, encrypted, tag, ttl,
 		revision, createdAt, updatedAt,
 		attrSession,
 		attrPred, program, predIP, predCidr, entityAttrs, entityPreds, entityEnsure,
@@ -170,7 +170,7 @@ func (a *ActorClient) Call(ctx context.Context, name string, arg, ret any) error
 		argData: data,
 	}
 
-	err = m.Handler(ctx, call)
+	err = m.invoke(ctx, call)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,187 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/pkg/errors"
+	"miren.dev/runtime/pkg/cond"
+)
+
+// ErrPermissionDenied is returned when a call is made to a method that an
+// attenuated capability does not permit. Use errors.Is to test for it, the
+// check works both for local calls and for errors that crossed the wire.
+var ErrPermissionDenied = cond.ErrPermissionDenied{}
+
+// Attenuate returns a derived interface that only permits calls to the named
+// methods. Calls to any other method fail with ErrPermissionDenied. The
+// returned interface shares the underlying value with i, so it's suitable
+// for handing a restricted view of an object to a less trusted party.
+//
+// Attenuating an already attenuated interface can only narrow it further,
+// a method denied by i stays denied regardless of allowed.
+func (i *Interface) Attenuate(allowed ...string) *Interface {
+	set := make(map[string]struct{}, len(allowed))
+	for _, name := range allowed {
+		if i.permits(name) {
+			set[name] = struct{}{}
+		}
+	}
+
+	methods := make(map[string]Method, len(i.methods))
+	for name, m := range i.methods {
+		if _, ok := set[name]; !ok {
+			m.Handler = denyMethod(i.name, name)
+		}
+
+		methods[name] = m
+	}
+
+	ni := *i
+	ni.methods = methods
+	ni.allowed = set
+
+	// The derived interface doesn't own the value, closing it is left to
+	// the holder of the original.
+	ni.closer = nil
+
+	// Restoring would hand back the unrestricted interface, so an attenuated
+	// capability must be obtained again rather than reconstructed.
+	ni.forbidRestore = true
+	ni.restoreState = nil
+	ni.constructor = nil
+
+	return &ni
+}
+
+// Attenuated reports whether the interface has been restricted by Attenuate.
+func (i *Interface) Attenuated() bool {
+	return i.allowed != nil
+}
+
+func (i *Interface) permits(method string) bool {
+	if _, ok := i.methods[method]; !ok {
+		return false
+	}
+
+	if i.allowed == nil {
+		return true
+	}
+
+	_, ok := i.allowed[method]
+	return ok
+}
+
+func denyMethod(iface, method string) func(ctx context.Context, call Call) error {
+	return func(ctx context.Context, call Call) error {
+		if nc, ok := call.(*NetworkCall); ok {
+			nc.SkipArgs()
+		}
+
+		return cond.PermissionDenied(iface, method)
+	}
+}
+
+type attenuateRequest struct {
+	Methods []string `json:"methods" cbor:"methods"`
+}
+
+func (s *Server) attenuate(w http.ResponseWriter, r *http.Request) {
+	oid := OID(r.PathValue("oid"))
+
+	user, ok := s.authRequest(r, w, oid)
+	if !ok {
+		return
+	}
+
+	var req attenuateRequest
+
+	err := cbor.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/cbor")
+	w.WriteHeader(http.StatusOK)
+
+	s.mu.Lock()
+	hc, ok := s.objects[oid]
+	s.mu.Unlock()
+
+	if !ok {
+		cbor.NewEncoder(w).Encode(lookupResponse{
+			Error: "unknown capability: " + string(oid),
+		})
+		return
+	}
+
+	iface := hc.Attenuate(req.Methods...)
+
+	s.state.log.Debug("attenuated capability",
+		"oid", oid, "interface", iface.name, "methods", strings.Join(req.Methods, ","))
+
+	// The derived capability is issued to the requester so that it can then
+	// be passed along (and reexported) like any other capability.
+	capa := s.assignCapability(iface, user, "", hc.category, false)
+
+	cbor.NewEncoder(w).Encode(lookupResponse{Capability: capa})
+}
+
+// Attenuate asks the server holding this capability to mint a derived
+// capability that only permits the given methods, and returns a client for
+// it. The restriction is enforced by the server, so the derived capability
+// can be safely delegated to a less trusted party, e.g. via Export on a
+// generated client wrapping the result.
+func (c *NetworkClient) Attenuate(ctx context.Context, allowed ...string) (*NetworkClient, error) {
+	if c.localClient != nil {
+		return LocalClient(c.localClient.iface.Attenuate(allowed...)), nil
+	}
+
+	if c.inlineClient != nil {
+		return nil, errors.New("inline capabilities can not be attenuated")
+	}
+
+	body, err := cbor.Marshal(attenuateRequest{Methods: allowed})
+	if err != nil {
+		return nil, err
+	}
+
+	url := "https://" + c.remote + "/_rpc/attenuate/" + string(c.oid)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.prepareRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.roundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var lr lookupResponse
+
+	err = cbor.NewDecoder(resp.Body).Decode(&lr)
+	if err != nil {
+		return nil, err
+	}
+
+	if lr.Error != "" {
+		return nil, errors.New(lr.Error)
+	}
+
+	return c.newClientUnder(lr.Capability), nil
+}
@@ -0,0 +1,131 @@
+package rpc_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"miren.dev/runtime/api/app/app_v1alpha"
+	"miren.dev/runtime/pkg/rpc"
+)
+
+type attenuateCrud struct {
+	destroyed []string
+}
+
+func (c *attenuateCrud) New(ctx context.Context, state *app_v1alpha.CrudNew) error {
+	state.Results().SetId("app-1")
+	return nil
+}
+
+func (c *attenuateCrud) SetConfiguration(ctx context.Context, state *app_v1alpha.CrudSetConfiguration) error {
+	return nil
+}
+
+func (c *attenuateCrud) GetConfiguration(ctx context.Context, state *app_v1alpha.CrudGetConfiguration) error {
+	state.Results().SetVersionId("v1")
+	return nil
+}
+
+func (c *attenuateCrud) SetHost(ctx context.Context, state *app_v1alpha.CrudSetHost) error {
+	return nil
+}
+
+func (c *attenuateCrud) List(ctx context.Context, state *app_v1alpha.CrudList) error {
+	var ai app_v1alpha.AppInfo
+	ai.SetName("app-1")
+
+	state.Results().SetApps([]*app_v1alpha.AppInfo{&ai})
+	return nil
+}
+
+func (c *attenuateCrud) ListStream(ctx context.Context, state *app_v1alpha.CrudListStream) error {
+	var ai app_v1alpha.AppInfo
+	ai.SetName("app-1")
+
+	_, err := state.Args().Apps().Send(ctx, &ai)
+	return err
+}
+
+func (c *attenuateCrud) Destroy(ctx context.Context, state *app_v1alpha.CrudDestroy) error {
+	c.destroyed = append(c.destroyed, state.Args().Name())
+	return nil
+}
+
+func (c *attenuateCrud) SetEnvVar(ctx context.Context, state *app_v1alpha.CrudSetEnvVar) error {
+	return nil
+}
+
+func (c *attenuateCrud) DeleteEnvVar(ctx context.Context, state *app_v1alpha.CrudDeleteEnvVar) error {
+	return nil
+}
+
+func TestAttenuate(t *testing.T) {
+	t.Run("rejects methods outside the allowed set", func(t *testing.T) {
+		r := require.New(t)
+		ctx := t.Context()
+
+		crud := &attenuateCrud{}
+
+		ss, err := rpc.NewState(ctx, rpc.WithSkipVerify)
+		r.NoError(err)
+
+		ss.Server().ExposeValue("crud", app_v1alpha.AdaptCrud(crud))
+
+		cs, err := rpc.NewState(ctx, rpc.WithSkipVerify)
+		r.NoError(err)
+
+		c, err := cs.Connect(ss.ListenAddr(), "crud")
+		r.NoError(err)
+
+		ac, err := c.Attenuate(ctx, "list", "getConfiguration")
+		r.NoError(err)
+
+		methods, err := ac.ListMethods(ctx)
+		r.NoError(err)
+		r.Equal([]string{"getConfiguration", "list"}, methods)
+
+		cc := app_v1alpha.NewCrudClient(ac)
+
+		lr, err := cc.List(ctx)
+		r.NoError(err)
+		r.Len(lr.Apps(), 1)
+		r.Equal("app-1", lr.Apps()[0].Name())
+
+		gr, err := cc.GetConfiguration(ctx, "app-1")
+		r.NoError(err)
+		r.Equal("v1", gr.VersionId())
+
+		_, err = cc.Destroy(ctx, "app-1")
+		r.Error(err)
+		r.ErrorIs(err, rpc.ErrPermissionDenied)
+		r.Empty(crud.destroyed)
+
+		// The original capability is unaffected.
+		_, err = app_v1alpha.NewCrudClient(c).Destroy(ctx, "app-1")
+		r.NoError(err)
+		r.Equal([]string{"app-1"}, crud.destroyed)
+	})
+
+	t.Run("attenuation only narrows", func(t *testing.T) {
+		r := require.New(t)
+		ctx := t.Context()
+
+		crud := &attenuateCrud{}
+
+		iface := app_v1alpha.AdaptCrud(crud).Attenuate("list", "getConfiguration")
+		r.True(iface.Attenuated())
+
+		cc := app_v1alpha.NewCrudClient(rpc.LocalClient(iface.Attenuate("list", "destroy")))
+
+		_, err := cc.List(ctx)
+		r.NoError(err)
+
+		_, err = cc.GetConfiguration(ctx, "app-1")
+		r.ErrorIs(err, rpc.ErrPermissionDenied)
+
+		_, err = cc.Destroy(ctx, "app-1")
+		r.ErrorIs(err, rpc.ErrPermissionDenied)
+		r.Empty(crud.destroyed)
+	})
+}
@@ -13,6 +13,7 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"sync/atomic"
 	"time"
 
 	"github.com/fxamacker/cbor/v2"
@@ -56,6 +57,11 @@ type NetworkClient struct {
 
 	inlineClient *inlineClient
 	localClient  *localClient
+
+	// compression tracks how well this connection's requests compress, once
+	// peerCompression shows the server can read compressed requests.
+	compression     compressionTracker
+	peerCompression atomic.Bool
 }
 
 func setTLSConfigServerName(tlsConf *tls.Config, addr net.Addr, host string) {
@@ -439,6 +445,17 @@ func (c *NetworkClient) Close() error {
 	return c.derefOID(c.State.top, c.oid)
 }
 
+// Reconnect drops the client's connection so its next call dials the
+// server again, such as after the local network has changed. Calls still
+// in flight fail, unless they're resumed (see WithSessionResumption).
+func (c *NetworkClient) Reconnect() error {
+	if c.localClient != nil {
+		return nil
+	}
+
+	return c.htr.Close()
+}
+
 // addBearerToken safely adds a bearer token to the request header if configured
 func (c *NetworkClient) addBearerToken(req *http.Request) {
 	if c.State != nil && c.State.opts != nil && c.State.opts.bearerToken != "" {
@@ -449,6 +466,14 @@ func (c *NetworkClient) addBearerToken(req *http.Request) {
 func (c *NetworkClient) prepareRequest(ctx context.Context, req *http.Request) error {
 	Propagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
 
+	if id := RequestID(ctx); id != "" {
+		req.Header.Set(RequestIDHeader, id)
+	}
+
+	if err := setCallDeadline(ctx, req.Header); err != nil {
+		return err
+	}
+
 	// Add bearer token if configured
 	c.addBearerToken(req)
 
@@ -501,6 +526,21 @@ func (c *NetworkClient) Call(ctx context.Context, method string, args, result an
 		return c.inlineClient.Call(ctx, method, args, result)
 	}
 
+	if rec := c.State.recorder(); rec != nil {
+		data, err := cbor.Marshal(args)
+		if err != nil {
+			return err
+		}
+
+		return rec.record(c.oid, method, data, func() (any, error) {
+			return result, c.networkCall(ctx, method, args, result)
+		})
+	}
+
+	return c.networkCall(ctx, method, args, result)
+}
+
+func (c *NetworkClient) networkCall(ctx context.Context, method string, args, result any) error {
 	ctx, span := Tracer().Start(ctx, "rpc.call."+method)
 	defer span.End()
 
@@ -509,12 +549,19 @@ func (c *NetworkClient) Call(ctx context.Context, method string, args, result an
 		return err
 	}
 
+	body, compressed := c.encodeRequest(data)
+
+	rc := c.resumableCall()
+	if rc != nil {
+		defer rc.finish()
+	}
+
 request:
 	for {
 		span.SetAttributes(attribute.String("oid", string(c.oid)))
 
 		url := "https://" + c.remote + "/_rpc/call/" + string(c.oid) + "/" + method
-		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
 		if err != nil {
 			return err
 		}
@@ -524,9 +571,25 @@ request:
 			return err
 		}
 
+		if c.State.compressionEnabled() {
+			req.Header.Set(acceptCompressionHeader, compressionGzip)
+		}
+
+		if compressed {
+			req.Header.Set(contentCompressionHeader, compressionGzip)
+		}
+
+		if rc != nil {
+			rc.setHeaders(req.Header)
+		}
+
 		hr, err := c.htr.RoundTrip(req)
 		if err != nil {
-			if _, ok := err.(*quic.ApplicationError); ok {
+			if rc != nil {
+				if rc.retry(ctx, err) {
+					continue request
+				}
+			} else if _, ok := err.(*quic.ApplicationError); ok {
 				c.State.log.Info("rpc.call retrying", "oid", string(c.oid), "error", err)
 				continue request
 			}
@@ -536,8 +599,15 @@ request:
 
 		defer hr.Body.Close()
 
+		storeRequestID(ctx, hr.Header.Get(RequestIDHeader))
+
 		if hr.StatusCode == http.StatusOK {
-			err = cbor.NewDecoder(hr.Body).Decode(result)
+			err = c.decodeResponse(hr, result)
+
+			// The connection went while the result was being read
+			if err != nil && rc != nil && rc.retry(ctx, err) {
+				continue request
+			}
 		} else {
 			et, _ := io.ReadAll(hr.Body)
 			err = fmt.Errorf("unexpected status code: %d: %s", hr.StatusCode, et)
@@ -627,6 +697,8 @@ request:
 			return fmt.Errorf("error performing http request to %s: %w", url, err)
 		}
 
+		storeRequestID(ctx, hr.Header.Get(RequestIDHeader))
+
 		retry, err := c.handleCallStream(ctx, hr, sess, method, args, result, caps)
 		if err != nil {
 			return err
@@ -813,7 +885,7 @@ func (c *NetworkClient) callInline(
 		inline: true,
 	}
 
-	err := cond.Wrap(mm.Handler(ctx, call))
+	err := cond.Wrap(mm.invoke(ctx, call))
 
 	// Defensively consume args if the handler didn't read them.
 	// This prevents leftover args from being interpreted as the next stream request.
@@ -11,6 +11,7 @@ var (
 	fPkg    = flag.String("pkg", "", "package name")
 	fInput  = flag.String("input", "", "input file")
 	fOutput = flag.String("output", "", "output file")
+	fPython = flag.String("python", "", "also write Python client stubs to this file")
 )
 
 func main() {
@@ -40,4 +41,16 @@ func main() {
 	if err != nil {
 		panic(err)
 	}
+
+	if *fPython != "" {
+		output, err := g.GeneratePython()
+		if err != nil {
+			panic(err)
+		}
+
+		err = os.WriteFile(*fPython, []byte(output), 0644)
+		if err != nil {
+			panic(err)
+		}
+	}
 }
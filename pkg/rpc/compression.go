@@ -0,0 +1,297 @@
+package rpc
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/quic-go/quic-go"
+)
+
+const (
+	// acceptCompressionHeader is sent by clients able to read compressed
+	// responses. Servers that can too answer with compressionHeader.
+	acceptCompressionHeader = "rpc-accept-compression"
+
+	// compressionHeader marks a response whose body starts with a byte
+	// saying whether the rest is compressed, and tells the client that the
+	// server reads compressed requests.
+	compressionHeader = "rpc-compression"
+
+	// contentCompressionHeader marks a compressed request body.
+	contentCompressionHeader = "rpc-content-compression"
+
+	compressionGzip = "gzip"
+)
+
+// Leading byte of a response body sent with compressionHeader.
+const (
+	payloadRaw  byte = 0
+	payloadGzip byte = 1
+)
+
+const (
+	// minCompressSize is the smallest payload worth compressing.
+	minCompressSize = 1024
+
+	// maxCompressRatio is the compressed to raw size ratio above which
+	// compression isn't considered to help.
+	maxCompressRatio = 0.9
+
+	// compressionSamples is how many payloads are compressed before a
+	// connection's ratio is trusted to disable compression.
+	compressionSamples = 4
+
+	// compressionProbeInterval is how often a payload is still compressed
+	// while compression is disabled, to notice when the data changes.
+	compressionProbeInterval = 16
+
+	// compressionRatioWeight is the weight of each new sample in the
+	// connection's moving average ratio.
+	compressionRatioWeight = 0.25
+)
+
+var (
+	compressionPayloads = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rpc_compression_payloads_total",
+		Help: "RPC payloads by whether compression was applied or why it was skipped",
+	}, []string{"result"})
+
+	compressionRatio = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "rpc_compression_ratio",
+		Help:    "Compressed to raw size ratio of RPC payloads that were compressed",
+		Buckets: []float64{0.05, 0.1, 0.2, 0.3, 0.5, 0.7, 0.9, 1, 1.1},
+	})
+)
+
+// WithoutCompression disables compressing call payloads in both directions.
+func WithoutCompression(o *stateOptions) {
+	o.disableCompression = true
+}
+
+func (s *State) compressionEnabled() bool {
+	return s != nil && s.opts != nil && !s.opts.disableCompression
+}
+
+// compressionTracker decides which of a connection's payloads to compress.
+// It keeps a moving average of how well payloads compress, and once that
+// shows compression isn't helping, as with already compressed data, it skips
+// compressing all but an occasional probe to save the CPU.
+type compressionTracker struct {
+	mu       sync.Mutex
+	ratio    float64
+	samples  int
+	disabled int
+}
+
+// encode returns data compressed, or as is if compressing it was skipped or
+// didn't help, reporting whether it was compressed.
+func (t *compressionTracker) encode(data []byte) ([]byte, bool) {
+	if len(data) < minCompressSize {
+		compressionPayloads.WithLabelValues("skipped-small").Inc()
+		return data, false
+	}
+
+	if !t.shouldSample() {
+		compressionPayloads.WithLabelValues("skipped-disabled").Inc()
+		return data, false
+	}
+
+	var buf bytes.Buffer
+
+	zw, _ := gzip.NewWriterLevel(&buf, gzip.BestSpeed)
+	zw.Write(data)
+	zw.Close()
+
+	ratio := float64(buf.Len()) / float64(len(data))
+	t.observe(ratio)
+
+	compressionRatio.Observe(ratio)
+
+	if ratio > maxCompressRatio {
+		compressionPayloads.WithLabelValues("skipped-ineffective").Inc()
+		return data, false
+	}
+
+	compressionPayloads.WithLabelValues("applied").Inc()
+
+	return buf.Bytes(), true
+}
+
+// shouldSample reports whether the next payload should be compressed.
+func (t *compressionTracker) shouldSample() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.disabledLocked() {
+		return true
+	}
+
+	t.disabled++
+
+	return t.disabled%compressionProbeInterval == 0
+}
+
+func (t *compressionTracker) observe(ratio float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.samples == 0 {
+		t.ratio = ratio
+	} else {
+		t.ratio += (ratio - t.ratio) * compressionRatioWeight
+	}
+
+	t.samples++
+
+	if !t.disabledLocked() {
+		t.disabled = 0
+	}
+}
+
+func (t *compressionTracker) disabledLocked() bool {
+	return t.samples >= compressionSamples && t.ratio > maxCompressRatio
+}
+
+// Disabled reports whether compression is currently being skipped.
+func (t *compressionTracker) Disabled() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.disabledLocked()
+}
+
+// encodeRequest returns the body to send for a call's marshaled arguments,
+// compressing them only once the server has shown it can read them.
+func (c *NetworkClient) encodeRequest(data []byte) ([]byte, bool) {
+	if !c.State.compressionEnabled() || !c.peerCompression.Load() {
+		return data, false
+	}
+
+	return c.compression.encode(data)
+}
+
+// gzipBody decompresses a request body, closing the original with it.
+type gzipBody struct {
+	*gzip.Reader
+	orig io.ReadCloser
+}
+
+func (b *gzipBody) Close() error {
+	b.Reader.Close()
+	return b.orig.Close()
+}
+
+// decompressRequest replaces the body of a compressed request with its
+// decompressed form.
+func decompressRequest(r *http.Request) error {
+	switch enc := r.Header.Get(contentCompressionHeader); enc {
+	case "":
+		return nil
+	case compressionGzip:
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return fmt.Errorf("reading compressed request: %w", err)
+		}
+
+		r.Body = &gzipBody{Reader: zr, orig: r.Body}
+
+		return nil
+	default:
+		return fmt.Errorf("unsupported request compression: %s", enc)
+	}
+}
+
+type quicConnKey struct{}
+
+// connContext records the QUIC connection in each request's context so that
+// state can be kept per connection.
+func connContext(ctx context.Context, c quic.Connection) context.Context {
+	return context.WithValue(ctx, quicConnKey{}, c)
+}
+
+// responseTracker returns the compression tracker of the connection
+// carrying r, so that each connection adapts to its own payloads.
+func (s *Server) responseTracker(r *http.Request) *compressionTracker {
+	conn, ok := r.Context().Value(quicConnKey{}).(quic.Connection)
+	if !ok {
+		return &compressionTracker{}
+	}
+
+	if t, ok := s.compression.Load(conn); ok {
+		return t.(*compressionTracker)
+	}
+
+	t, loaded := s.compression.LoadOrStore(conn, &compressionTracker{})
+	if !loaded {
+		go func() {
+			<-conn.Context().Done()
+			s.compression.Delete(conn)
+		}()
+	}
+
+	return t.(*compressionTracker)
+}
+
+// writePayload writes a response body in the framing announced by
+// compressionHeader.
+func writePayload(w io.Writer, t *compressionTracker, data []byte) error {
+	body, compressed := t.encode(data)
+
+	flag := payloadRaw
+	if compressed {
+		flag = payloadGzip
+	}
+
+	if _, err := w.Write([]byte{flag}); err != nil {
+		return err
+	}
+
+	_, err := w.Write(body)
+	return err
+}
+
+// readPayload returns a reader of a response body sent with
+// compressionHeader.
+func readPayload(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+
+	flag, err := br.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	switch flag {
+	case payloadRaw:
+		return br, nil
+	case payloadGzip:
+		return gzip.NewReader(br)
+	default:
+		return nil, fmt.Errorf("unknown payload encoding: %d", flag)
+	}
+}
+
+// decodeResponse decodes the result of a call, noting whether the server
+// reads compressed requests.
+func (c *NetworkClient) decodeResponse(hr *http.Response, result any) error {
+	if hr.Header.Get(compressionHeader) != compressionGzip {
+		return cbor.NewDecoder(hr.Body).Decode(result)
+	}
+
+	c.peerCompression.Store(true)
+
+	r, err := readPayload(hr.Body)
+	if err != nil {
+		return err
+	}
+
+	return cbor.NewDecoder(r).Decode(result)
+}
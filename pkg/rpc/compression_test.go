@@ -0,0 +1,108 @@
+package rpc_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"miren.dev/runtime/pkg/rpc"
+	"miren.dev/runtime/pkg/rpc/example"
+)
+
+func compressionCount(t *testing.T, result string) float64 {
+	mfs, err := prometheus.DefaultGatherer.Gather()
+	require.NoError(t, err)
+
+	for _, mf := range mfs {
+		if mf.GetName() != "rpc_compression_payloads_total" {
+			continue
+		}
+
+		for _, m := range mf.GetMetric() {
+			for _, lp := range m.GetLabel() {
+				if lp.GetName() == "result" && lp.GetValue() == result {
+					return m.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+
+	return 0
+}
+
+func TestCompression(t *testing.T) {
+	setup := func(t *testing.T, opts ...rpc.StateOption) *example.MeterClient {
+		r := require.New(t)
+		ctx := t.Context()
+
+		ss, err := rpc.NewState(ctx, append([]rpc.StateOption{rpc.WithSkipVerify}, opts...)...)
+		r.NoError(err)
+		t.Cleanup(func() { ss.Close() })
+
+		ss.Server().ExposeValue("meter", example.AdaptMeter(&exampleMeter{temp: 42}))
+
+		cs, err := rpc.NewState(ctx, append([]rpc.StateOption{rpc.WithSkipVerify}, opts...)...)
+		r.NoError(err)
+		t.Cleanup(func() { cs.Close() })
+
+		c, err := cs.Connect(ss.ListenAddr(), "meter")
+		r.NoError(err)
+
+		return &example.MeterClient{Client: c}
+	}
+
+	t.Run("compresses large payloads in both directions", func(t *testing.T) {
+		r := require.New(t)
+		mc := setup(t)
+
+		name := strings.Repeat("thermometer ", 1000)
+
+		before := compressionCount(t, "applied")
+
+		// The first request goes out raw, as the client doesn't yet know the
+		// server reads compressed requests.
+		for range 2 {
+			res, err := mc.ReadTemperature(t.Context(), name)
+			r.NoError(err)
+
+			r.Equal(name, res.Reading().Meter())
+			r.Equal(float32(42), res.Reading().Temperature())
+		}
+
+		// Both responses and the second request
+		r.Equal(float64(3), compressionCount(t, "applied")-before)
+	})
+
+	t.Run("leaves small payloads uncompressed", func(t *testing.T) {
+		r := require.New(t)
+		mc := setup(t)
+
+		before := compressionCount(t, "applied")
+
+		for range 2 {
+			res, err := mc.ReadTemperature(t.Context(), "test")
+			r.NoError(err)
+			r.Equal("test", res.Reading().Meter())
+		}
+
+		r.Equal(float64(0), compressionCount(t, "applied")-before)
+	})
+
+	t.Run("can be disabled", func(t *testing.T) {
+		r := require.New(t)
+		mc := setup(t, rpc.WithoutCompression)
+
+		name := strings.Repeat("thermometer ", 1000)
+
+		before := compressionCount(t, "applied")
+
+		for range 2 {
+			res, err := mc.ReadTemperature(t.Context(), name)
+			r.NoError(err)
+			r.Equal(name, res.Reading().Meter())
+		}
+
+		r.Equal(float64(0), compressionCount(t, "applied")-before)
+	})
+}
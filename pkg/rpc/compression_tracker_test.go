@@ -0,0 +1,99 @@
+package rpc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompressionTracker(t *testing.T) {
+	incompressible := func() []byte {
+		data := make([]byte, 4096)
+		for i := range data {
+			data[i] = byte(rand.Uint32())
+		}
+		return data
+	}
+
+	compressible := bytes.Repeat([]byte("thermometer "), 400)
+
+	t.Run("compresses payloads that shrink", func(t *testing.T) {
+		r := require.New(t)
+
+		var ct compressionTracker
+
+		body, compressed := ct.encode(compressible)
+		r.True(compressed)
+		r.Less(len(body), len(compressible))
+
+		zr, err := gzip.NewReader(bytes.NewReader(body))
+		r.NoError(err)
+
+		data, err := io.ReadAll(zr)
+		r.NoError(err)
+		r.Equal(compressible, data)
+	})
+
+	t.Run("skips small payloads", func(t *testing.T) {
+		r := require.New(t)
+
+		var ct compressionTracker
+
+		_, compressed := ct.encode([]byte("thermometer"))
+		r.False(compressed)
+		r.Equal(0, ct.samples)
+	})
+
+	t.Run("disables itself when payloads don't compress", func(t *testing.T) {
+		r := require.New(t)
+
+		var ct compressionTracker
+
+		for range compressionSamples {
+			r.False(ct.Disabled())
+
+			body, compressed := ct.encode(incompressible())
+			r.False(compressed)
+			r.Len(body, 4096)
+		}
+
+		r.True(ct.Disabled())
+
+		// Only every compressionProbeInterval'th payload is tried
+		samples := ct.samples
+
+		for range compressionProbeInterval - 1 {
+			ct.encode(incompressible())
+		}
+
+		r.Equal(samples, ct.samples)
+
+		ct.encode(incompressible())
+		r.Equal(samples+1, ct.samples)
+	})
+
+	t.Run("reenables once payloads compress again", func(t *testing.T) {
+		r := require.New(t)
+
+		var ct compressionTracker
+
+		for range compressionSamples {
+			ct.encode(incompressible())
+		}
+
+		r.True(ct.Disabled())
+
+		// Probes of compressible data pull the average back down
+		for i := 0; ct.Disabled(); i++ {
+			r.Less(i, 10*compressionProbeInterval)
+			ct.encode(compressible)
+		}
+
+		_, compressed := ct.encode(compressible)
+		r.True(compressed)
+	})
+}
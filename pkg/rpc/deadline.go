@@ -0,0 +1,44 @@
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// DeadlineHeader carries the time a call has left before the caller's
+// deadline, as a Go duration. It's sent relative rather than as a time so
+// that clock skew between the nodes doesn't shift it.
+const DeadlineHeader = "rpc-deadline"
+
+// setCallDeadline sends the deadline of ctx with a call, returning the
+// context's error instead if the call can't complete in time.
+func setCallDeadline(ctx context.Context, h http.Header) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	dl, ok := ctx.Deadline()
+	if !ok {
+		return nil
+	}
+
+	left := time.Until(dl)
+	if left <= 0 {
+		return context.DeadlineExceeded
+	}
+
+	h.Set(DeadlineHeader, left.String())
+
+	return nil
+}
+
+// withCallDeadline returns ctx with the deadline the client sent, if any.
+func withCallDeadline(ctx context.Context, h http.Header) (context.Context, context.CancelFunc) {
+	left, err := time.ParseDuration(h.Get(DeadlineHeader))
+	if err != nil {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, left)
+}
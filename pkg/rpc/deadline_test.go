@@ -0,0 +1,100 @@
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// deadlineTransport is a fake transport that serves requests with the
+// deadline handling of the server, recording what the handler saw.
+type deadlineTransport struct {
+	calls    int
+	header   string
+	deadline time.Time
+	ok       bool
+}
+
+func (f *deadlineTransport) roundTrip(ctx context.Context) error {
+	req := httptest.NewRequestWithContext(ctx, http.MethodPost, "/_rpc/call/oid/method", nil)
+
+	if err := setCallDeadline(ctx, req.Header); err != nil {
+		return err
+	}
+
+	f.calls++
+	f.header = req.Header.Get(DeadlineHeader)
+
+	// The server doesn't share the client's context, only its headers.
+	sctx, cancel := withCallDeadline(context.Background(), req.Header)
+	defer cancel()
+
+	f.deadline, f.ok = sctx.Deadline()
+
+	return nil
+}
+
+func TestCallDeadline(t *testing.T) {
+	t.Run("the client's deadline is sent and applied by the server", func(t *testing.T) {
+		r := require.New(t)
+
+		var ft deadlineTransport
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		clientDeadline, _ := ctx.Deadline()
+
+		r.NoError(ft.roundTrip(ctx))
+		r.Equal(1, ft.calls)
+		r.NotEmpty(ft.header)
+
+		left, err := time.ParseDuration(ft.header)
+		r.NoError(err)
+		r.LessOrEqual(left, 5*time.Second)
+		r.Greater(left, 4*time.Second)
+
+		r.True(ft.ok, "server context has no deadline")
+		r.WithinDuration(clientDeadline, ft.deadline, time.Second)
+	})
+
+	t.Run("calls without a deadline send none", func(t *testing.T) {
+		r := require.New(t)
+
+		var ft deadlineTransport
+
+		r.NoError(ft.roundTrip(context.Background()))
+		r.Equal(1, ft.calls)
+		r.Empty(ft.header)
+		r.False(ft.ok)
+	})
+
+	t.Run("expired calls are not sent", func(t *testing.T) {
+		r := require.New(t)
+
+		var ft deadlineTransport
+
+		ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+		defer cancel()
+
+		r.ErrorIs(ft.roundTrip(ctx), context.DeadlineExceeded)
+		r.Zero(ft.calls)
+	})
+
+	t.Run("bad headers are ignored", func(t *testing.T) {
+		r := require.New(t)
+
+		h := http.Header{}
+		h.Set(DeadlineHeader, "soon")
+
+		ctx, cancel := withCallDeadline(context.Background(), h)
+		defer cancel()
+
+		_, ok := ctx.Deadline()
+		r.False(ok)
+	})
+}
@@ -0,0 +1,33 @@
+package rpc
+
+import (
+	"log/slog"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	suppressDeprecated atomic.Bool
+	warnedDeprecated   sync.Map
+)
+
+// SuppressDeprecationWarnings turns the warnings logged when a deprecated
+// field is decoded off, or back on.
+func SuppressDeprecationWarnings(suppress bool) {
+	suppressDeprecated.Store(suppress)
+}
+
+// WarnDeprecated logs that the deprecated field, named as Type.field, was
+// set in a decoded value. Each field is only warned about once. It's called
+// by generated code.
+func WarnDeprecated(field, reason string) {
+	if suppressDeprecated.Load() {
+		return
+	}
+
+	if _, warned := warnedDeprecated.LoadOrStore(field, struct{}{}); warned {
+		return
+	}
+
+	slog.Warn("deprecated rpc field in use", "field", field, "reason", reason)
+}
@@ -0,0 +1,32 @@
+package rpc
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWarnDeprecated(t *testing.T) {
+	r := require.New(t)
+
+	var buf bytes.Buffer
+
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+	defer slog.SetDefault(prev)
+
+	SuppressDeprecationWarnings(true)
+	WarnDeprecated("Usage.suppressed", "use cpu")
+	SuppressDeprecationWarnings(false)
+
+	r.Empty(buf.String(), "suppressed warnings should not be logged")
+
+	WarnDeprecated("Usage.lastMinCPU", "use cpu")
+	WarnDeprecated("Usage.lastMinCPU", "use cpu")
+
+	r.Equal(1, strings.Count(buf.String(), "Usage.lastMinCPU"), "a field should only be warned about once")
+	r.Contains(buf.String(), "reason=\"use cpu\"")
+}
@@ -1,6 +1,10 @@
 package rpc
 
-import "fmt"
+import (
+	"fmt"
+
+	"miren.dev/runtime/pkg/cond"
+)
 
 type ErrorCategory interface {
 	ErrorCategory() string
@@ -102,3 +106,7 @@ func NewResolveLookupError(msg string) error {
 		Msg:  msg,
 	}
 }
+
+// ErrDeadlineExceeded is returned by a call whose handler ran past the timeout
+// declared for its method. Match it with errors.Is.
+var ErrDeadlineExceeded error = cond.ErrDeadlineExceeded{}
@@ -3,6 +3,7 @@ package example
 import (
 	"context"
 	"encoding/json"
+	"time"
 
 	"github.com/fxamacker/cbor/v2"
 	rpc "miren.dev/runtime/pkg/rpc"
@@ -34,6 +35,10 @@ func (v *Reading) SetTemperature(temperature float32) {
 	v.data.Temperature = &temperature
 }
 
+func (v *Reading) ClearTemperature() {
+	v.data.Temperature = nil
+}
+
 func (v *Reading) HasSeconds() bool {
 	return v.data.Seconds != nil
 }
@@ -49,6 +54,10 @@ func (v *Reading) SetSeconds(seconds int32) {
 	v.data.Seconds = &seconds
 }
 
+func (v *Reading) ClearSeconds() {
+	v.data.Seconds = nil
+}
+
 func (v *Reading) HasMeter() bool {
 	return v.data.Meter != nil
 }
@@ -64,6 +73,10 @@ func (v *Reading) SetMeter(meter string) {
 	v.data.Meter = &meter
 }
 
+func (v *Reading) ClearMeter() {
+	v.data.Meter = nil
+}
+
 func (v *Reading) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -155,6 +168,7 @@ func (v *Value) UnmarshalJSON(data []byte) error {
 	return json.Unmarshal(data, &v.data)
 }
 
+// Server structs for Meter
 type meterReadTemperatureArgsData struct {
 	Name *string `cbor:"0,keyasint,omitempty" json:"name,omitempty"`
 }
@@ -204,6 +218,10 @@ func (v *MeterReadTemperatureResults) SetReading(reading *Reading) {
 	v.data.Reading = reading
 }
 
+func (v *MeterReadTemperatureResults) ClearReading() {
+	v.data.Reading = nil
+}
+
 func (v *MeterReadTemperatureResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -269,6 +287,10 @@ func (v *MeterGetSetterResults) SetSetter(setter SetTemp) {
 	v.data.Setter = v.call.NewCapability(AdaptSetTemp(setter))
 }
 
+func (v *MeterGetSetterResults) ClearSetter() {
+	v.data.Setter = nil
+}
+
 func (v *MeterGetSetterResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -311,6 +333,10 @@ func (t *MeterReadTemperature) Results() *MeterReadTemperatureResults {
 	return results
 }
 
+var (
+	ErrMeterReadTemperatureUnknownMeter = rpc.NewRPCError("not-found")
+)
+
 type MeterGetSetter struct {
 	rpc.Call
 	args    MeterGetSetterArgs
@@ -414,7 +440,7 @@ func (v MeterClient) ReadTemperature(ctx context.Context, name string) (*MeterCl
 
 	err := v.Call(ctx, "readTemperature", &args, &ret)
 	if err != nil {
-		return nil, err
+		return nil, rpc.TypedError(err, "not-found")
 	}
 
 	return &MeterClientReadTemperatureResults{client: v.Client, data: ret}, nil
@@ -445,6 +471,7 @@ func (v MeterClient) GetSetter(ctx context.Context, name string) (*MeterClientGe
 	return &MeterClientGetSetterResults{client: v.Client, data: ret}, nil
 }
 
+// Server structs for SetTemp
 type setTempSetTempArgsData struct {
 	Temp *int32 `cbor:"0,keyasint,omitempty" json:"temp,omitempty"`
 }
@@ -494,6 +521,10 @@ func (v *SetTempSetTempResults) SetTemp(temp int32) {
 	v.data.Temp = &temp
 }
 
+func (v *SetTempSetTempResults) ClearTemp() {
+	v.data.Temp = nil
+}
+
 func (v *SetTempSetTempResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -609,6 +640,7 @@ func (v SetTempClient) SetTemp(ctx context.Context, temp int32) (*SetTempClientS
 	return &SetTempClientSetTempResults{client: v.Client, data: ret}, nil
 }
 
+// Server structs for UpdateReceiver
 type updateReceiverUpdateArgsData struct {
 	Reading *Reading `cbor:"0,keyasint,omitempty" json:"reading,omitempty"`
 }
@@ -753,6 +785,7 @@ func (v UpdateReceiverClient) Update(ctx context.Context, reading *Reading) (*Up
 	return &UpdateReceiverClientUpdateResults{client: v.Client, data: ret}, nil
 }
 
+// Server structs for MeterUpdates
 type meterUpdatesRegisterUpdatesArgsData struct {
 	Recv *rpc.Capability `cbor:"0,keyasint,omitempty" json:"recv,omitempty"`
 }
@@ -905,6 +938,7 @@ func (v MeterUpdatesClient) RegisterUpdates(ctx context.Context, recv UpdateRece
 	return &MeterUpdatesClientRegisterUpdatesResults{client: v.Client, data: ret}, nil
 }
 
+// Server structs for AdjustTemp
 type adjustTempAdjustArgsData struct {
 	Setter *rpc.Capability `cbor:"0,keyasint,omitempty" json:"setter,omitempty"`
 }
@@ -1057,6 +1091,7 @@ func (v AdjustTempClient) Adjust(ctx context.Context, setter SetTemp) (*AdjustTe
 	return &AdjustTempClientAdjustResults{client: v.Client, data: ret}, nil
 }
 
+// Server structs for SetTempG
 type setTempGSetTempArgsData[T any] struct {
 	Temp *T `cbor:"0,keyasint,omitempty" json:"temp,omitempty"`
 }
@@ -1204,6 +1239,7 @@ func (v SetTempGClient[T]) SetTemp(ctx context.Context, temp T) (*SetTempGClient
 	return &SetTempGClientSetTempResults[T]{client: v.Client, data: ret}, nil
 }
 
+// Server structs for EmitTemps
 type emitTempsEmitArgsData struct {
 	Emitter *rpc.Capability `cbor:"0,keyasint,omitempty" json:"emitter,omitempty"`
 }
@@ -1355,3 +1391,153 @@ func (v EmitTempsClient) Emit(ctx context.Context, emitter stream.SendStream[flo
 
 	return &EmitTempsClientEmitResults{client: v.Client, data: ret}, nil
 }
+
+// Server structs for Waiter
+type waiterWaitArgsData struct {
+	Millis *int32 `cbor:"0,keyasint,omitempty" json:"millis,omitempty"`
+}
+
+type WaiterWaitArgs struct {
+	call rpc.Call
+	data waiterWaitArgsData
+}
+
+func (v *WaiterWaitArgs) HasMillis() bool {
+	return v.data.Millis != nil
+}
+
+func (v *WaiterWaitArgs) Millis() int32 {
+	if v.data.Millis == nil {
+		return 0
+	}
+	return *v.data.Millis
+}
+
+func (v *WaiterWaitArgs) MarshalCBOR() ([]byte, error) {
+	return cbor.Marshal(v.data)
+}
+
+func (v *WaiterWaitArgs) UnmarshalCBOR(data []byte) error {
+	return cbor.Unmarshal(data, &v.data)
+}
+
+func (v *WaiterWaitArgs) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.data)
+}
+
+func (v *WaiterWaitArgs) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &v.data)
+}
+
+type waiterWaitResultsData struct{}
+
+type WaiterWaitResults struct {
+	call rpc.Call
+	data waiterWaitResultsData
+}
+
+func (v *WaiterWaitResults) MarshalCBOR() ([]byte, error) {
+	return cbor.Marshal(v.data)
+}
+
+func (v *WaiterWaitResults) UnmarshalCBOR(data []byte) error {
+	return cbor.Unmarshal(data, &v.data)
+}
+
+func (v *WaiterWaitResults) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.data)
+}
+
+func (v *WaiterWaitResults) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &v.data)
+}
+
+type WaiterWait struct {
+	rpc.Call
+	args    WaiterWaitArgs
+	results WaiterWaitResults
+}
+
+func (t *WaiterWait) Args() *WaiterWaitArgs {
+	args := &t.args
+	if args.call != nil {
+		return args
+	}
+	args.call = t.Call
+	t.Call.Args(args)
+	return args
+}
+
+func (t *WaiterWait) Results() *WaiterWaitResults {
+	results := &t.results
+	if results.call != nil {
+		return results
+	}
+	results.call = t.Call
+	t.Call.Results(results)
+	return results
+}
+
+type Waiter interface {
+	Wait(ctx context.Context, state *WaiterWait) error
+}
+
+type reexportWaiter struct {
+	client rpc.Client
+}
+
+func (reexportWaiter) Wait(ctx context.Context, state *WaiterWait) error {
+	panic("not implemented")
+}
+
+func (t reexportWaiter) CapabilityClient() rpc.Client {
+	return t.client
+}
+
+func AdaptWaiter(t Waiter) *rpc.Interface {
+	methods := []rpc.Method{
+		{
+			Name:          "wait",
+			InterfaceName: "Waiter",
+			Index:         0,
+			Handler: func(ctx context.Context, call rpc.Call) error {
+				return t.Wait(ctx, &WaiterWait{Call: call})
+			},
+			Timeout:        100 * time.Millisecond,
+			MessageTimeout: 10 * time.Second,
+		},
+	}
+
+	return rpc.NewInterface(methods, t)
+}
+
+type WaiterClient struct {
+	rpc.Client
+}
+
+func NewWaiterClient(client rpc.Client) *WaiterClient {
+	return &WaiterClient{Client: client}
+}
+
+func (c WaiterClient) Export() Waiter {
+	return reexportWaiter{client: c.Client}
+}
+
+type WaiterClientWaitResults struct {
+	client rpc.Client
+	data   waiterWaitResultsData
+}
+
+func (v WaiterClient) Wait(ctx context.Context, millis int32) (*WaiterClientWaitResults, error) {
+	args := WaiterWaitArgs{}
+	args.data.Millis = &millis
+
+	var ret waiterWaitResultsData
+
+	err := v.Call(ctx, "wait", &args, &ret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WaiterClientWaitResults{client: v.Client, data: ret}, nil
+}
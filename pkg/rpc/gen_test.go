@@ -37,4 +37,31 @@ func TestGeneratedCode(t *testing.T) {
 		r.Equal("", iv2.S())
 		r.Equal(int64(42), iv2.I())
 	})
+	t.Run("a cleared field is unset and omitted", func(t *testing.T) {
+		r := require.New(t)
+
+		var v example.Reading
+
+		v.SetMeter("kitchen")
+		v.SetSeconds(10)
+		r.True(v.HasMeter())
+
+		v.ClearMeter()
+		r.False(v.HasMeter())
+		r.Equal("", v.Meter())
+		r.True(v.HasSeconds())
+
+		data, err := cbor.Marshal(&v)
+		r.NoError(err)
+
+		var fields map[int]any
+		r.NoError(cbor.Unmarshal(data, &fields))
+		r.Len(fields, 1)
+		r.NotContains(fields, 2)
+
+		var v2 example.Reading
+		r.NoError(cbor.Unmarshal(data, &v2))
+		r.False(v2.HasMeter())
+		r.Equal(int32(10), v2.Seconds())
+	})
 }
@@ -3,11 +3,14 @@ package rpc
 import (
 	"bytes"
 	"cmp"
+	_ "embed"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"slices"
 	"strings"
+	"time"
 
 	j "github.com/dave/jennifer/jen"
 	"github.com/pkg/errors"
@@ -46,9 +49,15 @@ func (g *Generator) Read(path string) error {
 		return err
 	}
 
+	defer f.Close()
+
+	return g.read(f, path)
+}
+
+func (g *Generator) read(r io.Reader, path string) error {
 	var df DescFile
 
-	err = yaml.NewDecoder(f).Decode(&df)
+	err := yaml.NewDecoder(r).Decode(&df)
 	if err != nil {
 		return err
 	}
@@ -62,6 +71,16 @@ func (g *Generator) Read(path string) error {
 		return err
 	}
 
+	err = g.resolveDurations()
+	if err != nil {
+		return err
+	}
+
+	err = g.addStreamVariants()
+	if err != nil {
+		return err
+	}
+
 	g.populateTypeInfo()
 
 	ut := make(map[string]*DescType)
@@ -77,6 +96,174 @@ func (g *Generator) Read(path string) error {
 	return nil
 }
 
+// addStreamVariants adds a <name>Stream method next to each method with a
+// streamed list result. It takes the same parameters plus a
+// stream.SendStream that the server sends the list's elements to, one by one,
+// in place of returning them all at once.
+func (g *Generator) addStreamVariants() error {
+	for _, i := range g.Interfaces {
+		var variants []*DescMethods
+
+		for _, m := range i.Method {
+			var (
+				streamed *DescParamater
+				results  []*DescParamater
+			)
+
+			for _, r := range m.Results {
+				if !r.Stream {
+					results = append(results, r)
+					continue
+				}
+
+				if r.Type != "list" || r.Element == "" {
+					return fmt.Errorf("streamed result %s of %s.%s must be a list", r.Name, i.Name, m.Name)
+				}
+
+				if streamed != nil {
+					return fmt.Errorf("%s.%s can only stream one result", i.Name, m.Name)
+				}
+
+				streamed = r
+			}
+
+			if streamed == nil {
+				continue
+			}
+
+			if _, ok := g.Imports["stream"]; !ok {
+				return fmt.Errorf("%s.%s streams a result but stream isn't imported", i.Name, m.Name)
+			}
+
+			elem := streamed.Element
+			if g.isMessageType(elem) {
+				elem = "*" + elem
+			}
+
+			params := slices.Clone(m.Parameters)
+			params = append(params, &DescParamater{
+				Name: streamed.Name,
+				Type: "stream.SendStream[" + elem + "]",
+			})
+
+			variants = append(variants, &DescMethods{
+				Name:       m.Name + "Stream",
+				Index:      m.Index,
+				Parameters: params,
+				Results:    results,
+				Timeout:    m.Timeout,
+				Errors:     m.Errors,
+			})
+		}
+
+		i.Method = append(i.Method, variants...)
+	}
+
+	return nil
+}
+
+//go:embed standard/standard.yml
+var standardSchema []byte
+
+const standardImport = "miren.dev/runtime/pkg/rpc/standard"
+
+// resolveDurations replaces the builtin duration type with standard.Duration,
+// importing the standard package if the schema doesn't already.
+func (g *Generator) resolveDurations() error {
+	var name string
+
+	for iname, imp := range g.Imports {
+		if imp.Import == standardImport {
+			name = iname
+		}
+	}
+
+	used := false
+
+	resolve := func(typ *string) {
+		if *typ != "duration" {
+			return
+		}
+
+		used = true
+
+		if name != "" {
+			*typ = name + ".Duration"
+		}
+	}
+
+	resolveAll := func() {
+		for _, t := range g.Types {
+			for _, f := range t.Fields {
+				resolve(&f.Type)
+				resolve(&f.Element)
+
+				for i := range f.Union {
+					resolve(&f.Union[i].Type)
+					resolve(&f.Union[i].Element)
+				}
+			}
+		}
+
+		for _, i := range g.Interfaces {
+			for _, m := range i.Method {
+				for _, p := range slices.Concat(m.Parameters, m.Results) {
+					resolve(&p.Type)
+					resolve(&p.Element)
+				}
+			}
+		}
+	}
+
+	resolveAll()
+
+	if !used || name != "" {
+		return nil
+	}
+
+	if _, ok := g.Imports["standard"]; ok {
+		return fmt.Errorf("duration requires %s, but standard is imported as something else", standardImport)
+	}
+
+	sg, err := NewGenerator()
+	if err != nil {
+		return err
+	}
+
+	err = sg.read(bytes.NewReader(standardSchema), "standard.yml")
+	if err != nil {
+		return err
+	}
+
+	if g.Imports == nil {
+		g.Imports = make(map[string]Import)
+	}
+
+	name = "standard"
+	g.Imports[name] = Import{Import: standardImport}
+	g.importedGenerators[name] = sg
+
+	resolveAll()
+
+	return nil
+}
+
+// isMessageType reports whether name is one of the generated message types,
+// before typeInfo is populated.
+func (g *Generator) isMessageType(name string) bool {
+	if strings.Contains(name, ".") {
+		return true
+	}
+
+	for _, t := range g.Types {
+		if t.Type == name {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (g *Generator) processImports(src string) error {
 	for name, path := range g.Imports {
 		if path.Path == "" {
@@ -302,6 +489,10 @@ func (g *Generator) generateServerStructs(f *j.File, t *DescInterface) error {
 
 		f.Type().Add(decl).StructFunc(func(gr *j.Group) {
 			for idx, p := range m.Parameters {
+				if p.Deprecated != "" {
+					gr.Comment("Deprecated: " + p.Deprecated)
+				}
+
 				if g.ti(p.Type).isInterface {
 					gr.Id(toCamal(p.Name)).Op("*").Qual("miren.dev/runtime/pkg/rpc", "Capability").Tag(map[string]string{
 						"cbor": fmt.Sprintf("%d,keyasint,omitempty", idx),
@@ -343,21 +534,24 @@ func (g *Generator) generateServerStructs(f *j.File, t *DescInterface) error {
 			g.Id("data").Add(privateArgs)
 		})
 
+		var deprecated []*DescField
+
 		for idx, p := range m.Parameters {
+			field := p.field(idx)
+
 			g.readForField(f,
 				&DescType{Type: tn + "Args", Generic: t.Generic},
-				&DescField{
-					Name:    p.Name,
-					Type:    p.Type,
-					Element: p.Element,
-					Index:   idx,
-				},
+				field,
 			)
+
+			if field.Deprecated != "" {
+				deprecated = append(deprecated, field)
+			}
 		}
 
 		f.Line()
 
-		g.generateMarshalers(f, name.GoString())
+		g.generateMarshalers(f, name.GoString(), deprecated)
 
 		f.Line()
 
@@ -365,6 +559,10 @@ func (g *Generator) generateServerStructs(f *j.File, t *DescInterface) error {
 
 		f.Type().Add(decl).StructFunc(func(gr *j.Group) {
 			for idx, p := range m.Results {
+				if p.Deprecated != "" {
+					gr.Comment("Deprecated: " + p.Deprecated)
+				}
+
 				if g.ti(p.Type).isInterface {
 					gr.Id(capitalize(p.Name)).Op("*").Qual("miren.dev/runtime/pkg/rpc", "Capability").Tag(map[string]string{
 						"cbor": fmt.Sprintf("%d,keyasint,omitempty", idx),
@@ -408,18 +606,13 @@ func (g *Generator) generateServerStructs(f *j.File, t *DescInterface) error {
 		for idx, p := range m.Results {
 			g.writeForField(f,
 				&DescType{Type: tn + "Results", Generic: t.Generic},
-				&DescField{
-					Name:    p.Name,
-					Type:    p.Type,
-					Element: p.Element,
-					Index:   idx,
-				},
+				p.field(idx),
 			)
 		}
 
 		f.Line()
 
-		g.generateMarshalers(f, name.GoString())
+		g.generateMarshalers(f, name.GoString(), nil)
 
 		f.Line()
 	}
@@ -436,7 +629,7 @@ func (g *Generator) readForField(f *j.File, t *DescType, field *DescField) {
 
 	switch field.Type {
 	case "bool":
-		f.Func().Params(
+		fieldFunc(f, field).Params(
 			j.Id("v").Op("*").Add(recv),
 		).Id("Has" + fname).Params().Bool().Block(
 			j.Return(j.Id("v").Dot("data").Dot(name).Op("!=").Nil()),
@@ -444,7 +637,7 @@ func (g *Generator) readForField(f *j.File, t *DescType, field *DescField) {
 
 		f.Line()
 
-		f.Func().Params(
+		fieldFunc(f, field).Params(
 			j.Id("v").Op("*").Add(recv),
 		).Id(fname).Params().Bool().Block(
 			j.If(j.Id("v").Dot("data").Dot(name).Op("==").Nil()).Block(
@@ -455,7 +648,7 @@ func (g *Generator) readForField(f *j.File, t *DescType, field *DescField) {
 
 		f.Line()
 	case "uint32", "int32", "uint64", "int64", "float32", "float64":
-		f.Func().Params(
+		fieldFunc(f, field).Params(
 			j.Id("v").Op("*").Add(recv),
 		).Id("Has" + fname).Params().Bool().Block(
 			j.Return(j.Id("v").Dot("data").Dot(name).Op("!=").Nil()),
@@ -463,7 +656,7 @@ func (g *Generator) readForField(f *j.File, t *DescType, field *DescField) {
 
 		f.Line()
 
-		f.Func().Params(
+		fieldFunc(f, field).Params(
 			j.Id("v").Op("*").Add(recv),
 		).Id(fname).Params().Id(field.Type).Block(
 			j.If(j.Id("v").Dot("data").Dot(name).Op("==").Nil()).Block(
@@ -475,7 +668,7 @@ func (g *Generator) readForField(f *j.File, t *DescType, field *DescField) {
 		f.Line()
 
 	case "bytes":
-		f.Func().Params(
+		fieldFunc(f, field).Params(
 			j.Id("v").Op("*").Add(recv),
 		).Id("Has" + fname).Params().Bool().Block(
 			j.Return(j.Id("v").Dot("data").Dot(name).Op("!=").Nil()),
@@ -483,7 +676,7 @@ func (g *Generator) readForField(f *j.File, t *DescType, field *DescField) {
 
 		f.Line()
 
-		f.Func().Params(
+		fieldFunc(f, field).Params(
 			j.Id("v").Op("*").Add(recv),
 		).Id(fname).Params().Index().Byte().Block(
 			j.If(j.Id("v").Dot("data").Dot(name).Op("==").Nil()).Block(
@@ -494,7 +687,7 @@ func (g *Generator) readForField(f *j.File, t *DescType, field *DescField) {
 
 		f.Line()
 	case "string":
-		f.Func().Params(
+		fieldFunc(f, field).Params(
 			j.Id("v").Op("*").Add(recv),
 		).Id("Has" + fname).Params().Bool().Block(
 			j.Return(j.Id("v").Dot("data").Dot(name).Op("!=").Nil()),
@@ -502,7 +695,7 @@ func (g *Generator) readForField(f *j.File, t *DescType, field *DescField) {
 
 		f.Line()
 
-		f.Func().Params(
+		fieldFunc(f, field).Params(
 			j.Id("v").Op("*").Add(recv),
 		).Id(fname).Params().String().Block(
 			j.If(j.Id("v").Dot("data").Dot(name).Op("==").Nil()).Block(
@@ -513,7 +706,7 @@ func (g *Generator) readForField(f *j.File, t *DescType, field *DescField) {
 
 		f.Line()
 	case "list":
-		f.Func().Params(
+		fieldFunc(f, field).Params(
 			j.Id("v").Op("*").Add(recv),
 		).Id("Has" + fname).Params().Bool().Block(
 			j.Return(j.Id("v").Dot("data").Dot(name).Op("!=").Nil()),
@@ -522,7 +715,7 @@ func (g *Generator) readForField(f *j.File, t *DescType, field *DescField) {
 		f.Line()
 
 		if g.ti(field.Element).isMessage {
-			f.Func().Params(
+			fieldFunc(f, field).Params(
 				j.Id("v").Op("*").Add(recv),
 			).Id(fname).Params().Index().Op("*").Id(field.Element).Block(
 				j.If(j.Id("v").Dot("data").Dot(name).Op("==").Nil()).Block(
@@ -531,7 +724,7 @@ func (g *Generator) readForField(f *j.File, t *DescType, field *DescField) {
 				j.Return(j.Op("*").Id("v").Dot("data").Dot(name)),
 			)
 		} else {
-			f.Func().Params(
+			fieldFunc(f, field).Params(
 				j.Id("v").Op("*").Add(recv),
 			).Id(fname).Params().Index().Id(field.Element).Block(
 				j.If(j.Id("v").Dot("data").Dot(name).Op("==").Nil()).Block(
@@ -544,7 +737,7 @@ func (g *Generator) readForField(f *j.File, t *DescType, field *DescField) {
 		f.Line()
 	default:
 		if g.ti(field.Type).isInterface {
-			f.Func().Params(
+			fieldFunc(f, field).Params(
 				j.Id("v").Op("*").Add(recv),
 			).Id("Has" + fname).Params().Bool().Block(
 				j.Return(j.Id("v").Dot("data").Dot(name).Op("!=").Nil()),
@@ -552,7 +745,7 @@ func (g *Generator) readForField(f *j.File, t *DescType, field *DescField) {
 
 			f.Line()
 
-			f.Func().Params(
+			fieldFunc(f, field).Params(
 				j.Id("v").Op("*").Add(recv),
 			).Id(fname).Params().Op("*").Id(g.deriveType(field.Type, "Client")).Block(
 				j.If(j.Id("v").Dot("data").Dot(name).Op("==").Nil()).Block(
@@ -573,7 +766,7 @@ func (g *Generator) readForField(f *j.File, t *DescType, field *DescField) {
 		}
 
 		if slices.Contains(t.Generic, field.Type) {
-			f.Func().Params(
+			fieldFunc(f, field).Params(
 				j.Id("v").Op("*").Add(recv),
 			).Id("Has" + fname).Params().Bool().Block(
 				j.Return(j.Id("v").Dot("data").Dot(name).Op("!=").Nil()),
@@ -581,7 +774,7 @@ func (g *Generator) readForField(f *j.File, t *DescType, field *DescField) {
 
 			f.Line()
 
-			f.Func().Params(
+			fieldFunc(f, field).Params(
 				j.Id("v").Op("*").Add(recv),
 			).Id(fname).Params().Id(field.Type).Block(
 				j.If(j.Id("v").Dot("data").Dot(name).Op("==").Nil()).Block(
@@ -594,7 +787,7 @@ func (g *Generator) readForField(f *j.File, t *DescType, field *DescField) {
 		}
 
 		if g.ti(field.Type).isMessage {
-			f.Func().Params(
+			fieldFunc(f, field).Params(
 				j.Id("v").Op("*").Add(recv),
 			).Id("Has" + fname).Params().Bool().Block(
 				j.Return(j.Id("v").Dot("data").Dot(name).Op("!=").Nil()),
@@ -602,14 +795,14 @@ func (g *Generator) readForField(f *j.File, t *DescType, field *DescField) {
 
 			f.Line()
 
-			f.Func().Params(
+			fieldFunc(f, field).Params(
 				j.Id("v").Op("*").Add(recv),
 			).Id(fname).Params().Op("*").Add(g.properType(field.Type)).Block(
 				j.Return(j.Id("v").Dot("data").Dot(name)),
 			)
 
 		} else {
-			f.Func().Params(
+			fieldFunc(f, field).Params(
 				j.Id("v").Op("*").Add(recv),
 			).Id("Has" + fname).Params().Bool().Block(
 				j.Return(j.Id("v").Dot("data").Dot(name).Op("!=").Nil()),
@@ -617,7 +810,7 @@ func (g *Generator) readForField(f *j.File, t *DescType, field *DescField) {
 
 			f.Line()
 
-			f.Func().Params(
+			fieldFunc(f, field).Params(
 				j.Id("v").Op("*").Add(recv),
 			).Id(fname).Params().Add(g.properType(field.Type)).Block(
 				j.Return(j.Op("*").Id("v").Dot("data").Dot(name)),
@@ -641,7 +834,7 @@ func (g *Generator) writeForField(f *j.File, t *DescType, field *DescField) {
 
 	switch field.Type {
 	case "bool":
-		f.Func().Params(
+		fieldFunc(f, field).Params(
 			j.Id("v").Op("*").Add(recv),
 		).Id("Set" + fname).Params(
 			j.Id(pname).Bool(),
@@ -650,7 +843,7 @@ func (g *Generator) writeForField(f *j.File, t *DescType, field *DescField) {
 		)
 
 	case "uint32", "int32", "uint64", "int64", "float32", "float64":
-		f.Func().Params(
+		fieldFunc(f, field).Params(
 			j.Id("v").Op("*").Add(recv),
 		).Id("Set" + fname).Params(
 			j.Id(pname).Id(field.Type),
@@ -659,7 +852,7 @@ func (g *Generator) writeForField(f *j.File, t *DescType, field *DescField) {
 		)
 
 	case "bytes":
-		f.Func().Params(
+		fieldFunc(f, field).Params(
 			j.Id("v").Op("*").Add(recv),
 		).Id("Set"+fname).Params(
 			j.Id(pname).Index().Byte(),
@@ -669,7 +862,7 @@ func (g *Generator) writeForField(f *j.File, t *DescType, field *DescField) {
 		)
 
 	case "string":
-		f.Func().Params(
+		fieldFunc(f, field).Params(
 			j.Id("v").Op("*").Add(recv),
 		).Id("Set" + fname).Params(
 			j.Id(pname).String(),
@@ -679,7 +872,7 @@ func (g *Generator) writeForField(f *j.File, t *DescType, field *DescField) {
 
 	case "list":
 		if g.ti(field.Element).isMessage {
-			f.Func().Params(
+			fieldFunc(f, field).Params(
 				j.Id("v").Op("*").Add(recv),
 			).Id("Set"+fname).Params(
 				j.Id(pname).Index().Op("*").Id(field.Element),
@@ -688,7 +881,7 @@ func (g *Generator) writeForField(f *j.File, t *DescType, field *DescField) {
 				j.Id("v").Dot("data").Dot(name).Op("=").Op("&").Id("x"),
 			)
 		} else {
-			f.Func().Params(
+			fieldFunc(f, field).Params(
 				j.Id("v").Op("*").Add(recv),
 			).Id("Set"+fname).Params(
 				j.Id(pname).Index().Id(field.Element),
@@ -700,7 +893,7 @@ func (g *Generator) writeForField(f *j.File, t *DescType, field *DescField) {
 
 	default:
 		if g.ti(field.Type).isInterface {
-			f.Func().Params(
+			fieldFunc(f, field).Params(
 				j.Id("v").Op("*").Add(recv),
 			).Id("Set" + fname).Params(
 				j.Id(pname).Id(field.Type),
@@ -715,23 +908,28 @@ func (g *Generator) writeForField(f *j.File, t *DescType, field *DescField) {
 				}),
 			)
 
+			g.clearForField(f, recv, field)
+
 			f.Line()
 
 			return
 		}
 
 		if slices.Contains(t.Generic, field.Type) {
-			f.Func().Params(
+			fieldFunc(f, field).Params(
 				j.Id("v").Op("*").Add(recv),
 			).Id("Set" + fname).Params(
 				j.Id(pname).Add(g.properType(field.Type)),
 			).Block(
 				j.Id("v").Dot("data").Dot(name).Op("=").Op("&").Id(pname),
 			)
+
+			g.clearForField(f, recv, field)
+
 			return
 		}
 
-		f.Func().Params(
+		fieldFunc(f, field).Params(
 			j.Id("v").Op("*").Add(recv),
 		).Id("Set" + fname).Params(
 			j.Id(pname).Op("*").Add(g.properType(field.Type)),
@@ -740,7 +938,46 @@ func (g *Generator) writeForField(f *j.File, t *DescType, field *DescField) {
 		)
 	}
 
+	g.clearForField(f, recv, field)
+
+	f.Line()
+}
+
+// clearForField generates ClearX, which returns an optional field to unset
+// so that it's omitted when encoded again.
+func (g *Generator) clearForField(f *j.File, recv j.Code, field *DescField) {
+	name := toCamal(field.Name)
+
 	f.Line()
+
+	fieldFunc(f, field).Params(
+		j.Id("v").Op("*").Add(recv),
+	).Id("Clear" + name).Params().Block(
+		j.Id("v").Dot("data").Dot(name).Op("=").Nil(),
+	)
+}
+
+// fieldFunc starts a method accessing field, marking it deprecated when the
+// field is.
+func fieldFunc(f *j.File, field *DescField) *j.Statement {
+	if field.Deprecated != "" {
+		f.Comment("Deprecated: " + field.Deprecated)
+	}
+
+	return f.Func()
+}
+
+// deprecatedFields returns the fields of fields that are deprecated.
+func deprecatedFields(fields []*DescField) []*DescField {
+	var out []*DescField
+
+	for _, field := range fields {
+		if field.Deprecated != "" {
+			out = append(out, field)
+		}
+	}
+
+	return out
 }
 
 // Helper to generate the correct type for a union field
@@ -882,6 +1119,10 @@ func (g *Generator) generateCompactStruct(f *j.File, t *DescType) error {
 		})
 
 		for _, field := range t.Fields {
+			if field.Deprecated != "" {
+				gr.Comment("Deprecated: " + field.Deprecated)
+			}
+
 			switch field.Type {
 			case "list":
 				if g.ti(field.Element).isMessage {
@@ -935,7 +1176,7 @@ func (g *Generator) generateCompactStruct(f *j.File, t *DescType) error {
 		switch field.Type {
 		case "bool":
 			if t.Readable() {
-				f.Func().Params(
+				fieldFunc(f, field).Params(
 					j.Id("v").Op("*").Add(recv),
 				).Id("Has" + fname).Params().Bool().Block(
 					j.Return(j.True()),
@@ -943,7 +1184,7 @@ func (g *Generator) generateCompactStruct(f *j.File, t *DescType) error {
 
 				f.Line()
 
-				f.Func().Params(
+				fieldFunc(f, field).Params(
 					j.Id("v").Op("*").Add(recv),
 				).Id(fname).Params().Bool().Block(
 					j.Return(j.Id("v").Dot("data").Dot(name)),
@@ -953,7 +1194,7 @@ func (g *Generator) generateCompactStruct(f *j.File, t *DescType) error {
 			}
 
 			if t.Writeable() {
-				f.Func().Params(
+				fieldFunc(f, field).Params(
 					j.Id("v").Op("*").Add(recv),
 				).Id("Set" + fname).Params(
 					j.Id(pname).Bool(),
@@ -964,7 +1205,7 @@ func (g *Generator) generateCompactStruct(f *j.File, t *DescType) error {
 
 		case "uint32", "int32", "uint64", "int64", "float32", "float64":
 			if t.Readable() {
-				f.Func().Params(
+				fieldFunc(f, field).Params(
 					j.Id("v").Op("*").Add(recv),
 				).Id("Has" + fname).Params().Bool().Block(
 					j.Return(j.True()),
@@ -972,7 +1213,7 @@ func (g *Generator) generateCompactStruct(f *j.File, t *DescType) error {
 
 				f.Line()
 
-				f.Func().Params(
+				fieldFunc(f, field).Params(
 					j.Id("v").Op("*").Add(recv),
 				).Id(fname).Params().Add(g.properType(field.Type)).Block(
 					j.Return(j.Id("v").Dot("data").Dot(name)),
@@ -982,7 +1223,7 @@ func (g *Generator) generateCompactStruct(f *j.File, t *DescType) error {
 			}
 
 			if t.Writeable() {
-				f.Func().Params(
+				fieldFunc(f, field).Params(
 					j.Id("v").Op("*").Add(recv),
 				).Id("Set" + fname).Params(
 					j.Id(pname).Add(g.properType(field.Type)),
@@ -993,7 +1234,7 @@ func (g *Generator) generateCompactStruct(f *j.File, t *DescType) error {
 
 		case "bytes":
 			if t.Readable() {
-				f.Func().Params(
+				fieldFunc(f, field).Params(
 					j.Id("v").Op("*").Add(recv),
 				).Id("Has" + fname).Params().Bool().Block(
 					j.Return(j.True()),
@@ -1001,7 +1242,7 @@ func (g *Generator) generateCompactStruct(f *j.File, t *DescType) error {
 
 				f.Line()
 
-				f.Func().Params(
+				fieldFunc(f, field).Params(
 					j.Id("v").Op("*").Add(recv),
 				).Id(fname).Params().String().Block(
 					j.Return(j.Id("v").Dot("data").Dot(name)),
@@ -1011,7 +1252,7 @@ func (g *Generator) generateCompactStruct(f *j.File, t *DescType) error {
 			}
 
 			if t.Writeable() {
-				f.Func().Params(
+				fieldFunc(f, field).Params(
 					j.Id("v").Op("*").Add(recv),
 				).Id("Set"+fname).Params(
 					j.Id(pname).String(),
@@ -1022,7 +1263,7 @@ func (g *Generator) generateCompactStruct(f *j.File, t *DescType) error {
 			}
 		case "string":
 			if t.Readable() {
-				f.Func().Params(
+				fieldFunc(f, field).Params(
 					j.Id("v").Op("*").Add(recv),
 				).Id("Has" + fname).Params().Bool().Block(
 					j.Return(j.True()),
@@ -1030,7 +1271,7 @@ func (g *Generator) generateCompactStruct(f *j.File, t *DescType) error {
 
 				f.Line()
 
-				f.Func().Params(
+				fieldFunc(f, field).Params(
 					j.Id("v").Op("*").Add(recv),
 				).Id(fname).Params().String().Block(
 					j.Return(j.Id("v").Dot("data").Dot(name)),
@@ -1040,7 +1281,7 @@ func (g *Generator) generateCompactStruct(f *j.File, t *DescType) error {
 			}
 
 			if t.Writeable() {
-				f.Func().Params(
+				fieldFunc(f, field).Params(
 					j.Id("v").Op("*").Add(recv),
 				).Id("Set" + fname).Params(
 					j.Id(pname).String(),
@@ -1051,7 +1292,7 @@ func (g *Generator) generateCompactStruct(f *j.File, t *DescType) error {
 
 		case "list":
 			if t.Readable() {
-				f.Func().Params(
+				fieldFunc(f, field).Params(
 					j.Id("v").Op("*").Add(recv),
 				).Id("Has" + fname).Params().Bool().Block(
 					j.Return(j.True()),
@@ -1060,13 +1301,13 @@ func (g *Generator) generateCompactStruct(f *j.File, t *DescType) error {
 				f.Line()
 
 				if g.ti(field.Element).isMessage {
-					f.Func().Params(
+					fieldFunc(f, field).Params(
 						j.Id("v").Op("*").Add(recv),
 					).Id(fname).Params().Index().Id(field.Element).Block(
 						j.Return(j.Id("v").Dot("data").Dot(name)),
 					)
 				} else {
-					f.Func().Params(
+					fieldFunc(f, field).Params(
 						j.Id("v").Op("*").Add(recv),
 					).Id(fname).Params().Index().Id(field.Element).Block(
 						j.Return(j.Id("v").Dot("data").Dot(name)),
@@ -1078,7 +1319,7 @@ func (g *Generator) generateCompactStruct(f *j.File, t *DescType) error {
 
 			if t.Writeable() {
 				if g.ti(field.Element).isMessage {
-					f.Func().Params(
+					fieldFunc(f, field).Params(
 						j.Id("v").Op("*").Add(recv),
 					).Id("Set"+fname).Params(
 						j.Id(pname).Index().Id(field.Element),
@@ -1087,7 +1328,7 @@ func (g *Generator) generateCompactStruct(f *j.File, t *DescType) error {
 						j.Id("v").Dot("data").Dot(name).Op("=").Id("x"),
 					)
 				} else {
-					f.Func().Params(
+					fieldFunc(f, field).Params(
 						j.Id("v").Op("*").Add(recv),
 					).Id("Set"+fname).Params(
 						j.Id(pname).Index().Id(field.Element),
@@ -1098,7 +1339,7 @@ func (g *Generator) generateCompactStruct(f *j.File, t *DescType) error {
 				}
 			}
 		case "union":
-			f.Func().Params(
+			fieldFunc(f, field).Params(
 				j.Id("v").Op("*").Add(recv),
 			).Id(fname).Params().Id(capitalize(t.Type) + capitalize(name)).Block(
 				j.Return(j.Op("&").Id("v").Dot("data").Dot(private(t.Type) + capitalize(name))),
@@ -1109,7 +1350,7 @@ func (g *Generator) generateCompactStruct(f *j.File, t *DescType) error {
 		default:
 			if g.ti(field.Type).isInterface {
 				if t.Readable() {
-					f.Func().Params(
+					fieldFunc(f, field).Params(
 						j.Id("v").Op("*").Add(recv),
 					).Id("Has" + fname).Params().Bool().Block(
 						j.Return(j.True()),
@@ -1117,7 +1358,7 @@ func (g *Generator) generateCompactStruct(f *j.File, t *DescType) error {
 
 					f.Line()
 
-					f.Func().Params(
+					fieldFunc(f, field).Params(
 						j.Id("v").Op("*").Add(recv),
 					).Id(fname).Params().Add(g.properType(field.Type)).Block(
 						j.Return(j.Id("v").Dot("data").Dot(name)),
@@ -1127,7 +1368,7 @@ func (g *Generator) generateCompactStruct(f *j.File, t *DescType) error {
 				}
 
 				if t.Writeable() {
-					f.Func().Params(
+					fieldFunc(f, field).Params(
 						j.Id("v").Op("*").Add(recv),
 					).Id("Set" + fname).Params(
 						j.Id(pname).Add(g.properType(field.Type)),
@@ -1149,7 +1390,7 @@ func (g *Generator) generateCompactStruct(f *j.File, t *DescType) error {
 			}
 
 			if t.Readable() {
-				f.Func().Params(
+				fieldFunc(f, field).Params(
 					j.Id("v").Op("*").Add(recv),
 				).Id("Has" + fname).Params().Bool().Block(
 					j.Return(j.Id("v").Dot("data").Dot(name).Op("!=").Nil()),
@@ -1157,7 +1398,7 @@ func (g *Generator) generateCompactStruct(f *j.File, t *DescType) error {
 
 				f.Line()
 
-				f.Func().Params(
+				fieldFunc(f, field).Params(
 					j.Id("v").Op("*").Add(recv),
 				).Id(fname).Params().Op("*").Add(g.properType(field.Type)).Block(
 					j.Return(j.Id("v").Dot("data").Dot(name)),
@@ -1167,7 +1408,7 @@ func (g *Generator) generateCompactStruct(f *j.File, t *DescType) error {
 			}
 
 			if t.Writeable() {
-				f.Func().Params(
+				fieldFunc(f, field).Params(
 					j.Id("v").Op("*").Add(recv),
 				).Id("Set" + fname).Params(
 					j.Id(pname).Op("*").Add(g.properType(field.Type)),
@@ -1180,7 +1421,7 @@ func (g *Generator) generateCompactStruct(f *j.File, t *DescType) error {
 		f.Line()
 	}
 
-	g.generateMarshalers(f, recv.GoString())
+	g.generateMarshalers(f, recv.GoString(), nil)
 	return nil
 }
 
@@ -1217,6 +1458,9 @@ func (g *Generator) generateStruct(f *j.File) error {
 			}
 
 			for _, field := range t.Fields {
+				if field.Deprecated != "" {
+					gr.Comment("Deprecated: " + field.Deprecated)
+				}
 				switch field.Type {
 				case "list":
 					if g.ti(field.Element).isMessage {
@@ -1270,7 +1514,7 @@ func (g *Generator) generateStruct(f *j.File) error {
 			switch field.Type {
 			case "bool":
 				if t.Readable() {
-					f.Func().Params(
+					fieldFunc(f, field).Params(
 						j.Id("v").Op("*").Add(recv),
 					).Id("Has" + fname).Params().Bool().Block(
 						j.Return(j.Id("v").Dot("data").Dot(name).Op("!=").Nil()),
@@ -1278,7 +1522,7 @@ func (g *Generator) generateStruct(f *j.File) error {
 
 					f.Line()
 
-					f.Func().Params(
+					fieldFunc(f, field).Params(
 						j.Id("v").Op("*").Add(recv),
 					).Id(fname).Params().Bool().Block(
 						j.If(j.Id("v").Dot("data").Dot(name).Op("==").Nil()).Block(
@@ -1291,7 +1535,7 @@ func (g *Generator) generateStruct(f *j.File) error {
 				}
 
 				if t.Writeable() {
-					f.Func().Params(
+					fieldFunc(f, field).Params(
 						j.Id("v").Op("*").Add(recv),
 					).Id("Set" + fname).Params(
 						j.Id(pname).Bool(),
@@ -1302,7 +1546,7 @@ func (g *Generator) generateStruct(f *j.File) error {
 
 			case "uint32", "int32", "uint64", "int64", "float32", "float64":
 				if t.Readable() {
-					f.Func().Params(
+					fieldFunc(f, field).Params(
 						j.Id("v").Op("*").Add(recv),
 					).Id("Has" + fname).Params().Bool().Block(
 						j.Return(j.Id("v").Dot("data").Dot(name).Op("!=").Nil()),
@@ -1310,7 +1554,7 @@ func (g *Generator) generateStruct(f *j.File) error {
 
 					f.Line()
 
-					f.Func().Params(
+					fieldFunc(f, field).Params(
 						j.Id("v").Op("*").Add(recv),
 					).Id(fname).Params().Add(g.properType(field.Type)).Block(
 						j.If(j.Id("v").Dot("data").Dot(name).Op("==").Nil()).Block(
@@ -1323,7 +1567,7 @@ func (g *Generator) generateStruct(f *j.File) error {
 				}
 
 				if t.Writeable() {
-					f.Func().Params(
+					fieldFunc(f, field).Params(
 						j.Id("v").Op("*").Add(recv),
 					).Id("Set" + fname).Params(
 						j.Id(pname).Add(g.properType(field.Type)),
@@ -1334,7 +1578,7 @@ func (g *Generator) generateStruct(f *j.File) error {
 
 			case "bytes":
 				if t.Readable() {
-					f.Func().Params(
+					fieldFunc(f, field).Params(
 						j.Id("v").Op("*").Add(recv),
 					).Id("Has" + fname).Params().Bool().Block(
 						j.Return(j.Id("v").Dot("data").Dot(name).Op("!=").Nil()),
@@ -1342,7 +1586,7 @@ func (g *Generator) generateStruct(f *j.File) error {
 
 					f.Line()
 
-					f.Func().Params(
+					fieldFunc(f, field).Params(
 						j.Id("v").Op("*").Add(recv),
 					).Id(fname).Params().String().Block(
 						j.If(j.Id("v").Dot("data").Dot(name).Op("==").Nil()).Block(
@@ -1355,7 +1599,7 @@ func (g *Generator) generateStruct(f *j.File) error {
 				}
 
 				if t.Writeable() {
-					f.Func().Params(
+					fieldFunc(f, field).Params(
 						j.Id("v").Op("*").Add(recv),
 					).Id("Set"+fname).Params(
 						j.Id(pname).String(),
@@ -1366,7 +1610,7 @@ func (g *Generator) generateStruct(f *j.File) error {
 				}
 			case "string":
 				if t.Readable() {
-					f.Func().Params(
+					fieldFunc(f, field).Params(
 						j.Id("v").Op("*").Add(recv),
 					).Id("Has" + fname).Params().Bool().Block(
 						j.Return(j.Id("v").Dot("data").Dot(name).Op("!=").Nil()),
@@ -1374,7 +1618,7 @@ func (g *Generator) generateStruct(f *j.File) error {
 
 					f.Line()
 
-					f.Func().Params(
+					fieldFunc(f, field).Params(
 						j.Id("v").Op("*").Add(recv),
 					).Id(fname).Params().String().Block(
 						j.If(j.Id("v").Dot("data").Dot(name).Op("==").Nil()).Block(
@@ -1387,7 +1631,7 @@ func (g *Generator) generateStruct(f *j.File) error {
 				}
 
 				if t.Writeable() {
-					f.Func().Params(
+					fieldFunc(f, field).Params(
 						j.Id("v").Op("*").Add(recv),
 					).Id("Set" + fname).Params(
 						j.Id(pname).String(),
@@ -1398,7 +1642,7 @@ func (g *Generator) generateStruct(f *j.File) error {
 
 			case "list":
 				if t.Readable() {
-					f.Func().Params(
+					fieldFunc(f, field).Params(
 						j.Id("v").Op("*").Add(recv),
 					).Id("Has" + fname).Params().Bool().Block(
 						j.Return(j.Id("v").Dot("data").Dot(name).Op("!=").Nil()),
@@ -1407,7 +1651,7 @@ func (g *Generator) generateStruct(f *j.File) error {
 					f.Line()
 
 					if g.ti(field.Element).isMessage {
-						f.Func().Params(
+						fieldFunc(f, field).Params(
 							j.Id("v").Op("*").Add(recv),
 						).Id(fname).Params().Index().Op("*").Id(field.Element).Block(
 							j.If(j.Id("v").Dot("data").Dot(name).Op("==").Nil()).Block(
@@ -1416,7 +1660,7 @@ func (g *Generator) generateStruct(f *j.File) error {
 							j.Return(j.Op("*").Id("v").Dot("data").Dot(name)),
 						)
 					} else {
-						f.Func().Params(
+						fieldFunc(f, field).Params(
 							j.Id("v").Op("*").Add(recv),
 						).Id(fname).Params().Index().Id(field.Element).Block(
 							j.If(j.Id("v").Dot("data").Dot(name).Op("==").Nil()).Block(
@@ -1431,7 +1675,7 @@ func (g *Generator) generateStruct(f *j.File) error {
 
 				if t.Writeable() {
 					if g.ti(field.Element).isMessage {
-						f.Func().Params(
+						fieldFunc(f, field).Params(
 							j.Id("v").Op("*").Add(recv),
 						).Id("Set"+fname).Params(
 							j.Id(pname).Index().Op("*").Id(field.Element),
@@ -1440,7 +1684,7 @@ func (g *Generator) generateStruct(f *j.File) error {
 							j.Id("v").Dot("data").Dot(name).Op("=").Op("&").Id("x"),
 						)
 					} else {
-						f.Func().Params(
+						fieldFunc(f, field).Params(
 							j.Id("v").Op("*").Add(recv),
 						).Id("Set"+fname).Params(
 							j.Id(pname).Index().Id(field.Element),
@@ -1451,7 +1695,7 @@ func (g *Generator) generateStruct(f *j.File) error {
 					}
 				}
 			case "union":
-				f.Func().Params(
+				fieldFunc(f, field).Params(
 					j.Id("v").Op("*").Add(recv),
 				).Id(fname).Params().Id(capitalize(t.Type) + capitalize(name)).Block(
 					j.Return(j.Op("&").Id("v").Dot("data").Dot(private(t.Type) + capitalize(name))),
@@ -1462,7 +1706,7 @@ func (g *Generator) generateStruct(f *j.File) error {
 			default:
 				if g.ti(field.Type).isInterface {
 					if t.Readable() {
-						f.Func().Params(
+						fieldFunc(f, field).Params(
 							j.Id("v").Op("*").Add(recv),
 						).Id("Has" + fname).Params().Bool().Block(
 							j.Return(j.Id("v").Dot("data").Dot(name).Op("!=").Lit("")),
@@ -1470,7 +1714,7 @@ func (g *Generator) generateStruct(f *j.File) error {
 
 						f.Line()
 
-						f.Func().Params(
+						fieldFunc(f, field).Params(
 							j.Id("v").Op("*").Add(recv),
 						).Id(fname).Params().Add(g.properType(field.Type)).Block(
 							j.If(j.Id("v").Dot("data").Dot(name).Op("==").Nil()).Block(
@@ -1484,7 +1728,7 @@ func (g *Generator) generateStruct(f *j.File) error {
 					}
 
 					if t.Writeable() {
-						f.Func().Params(
+						fieldFunc(f, field).Params(
 							j.Id("v").Op("*").Add(recv),
 						).Id("Set" + fname).Params(
 							j.Id(pname).Add(g.properType(field.Type)),
@@ -1498,6 +1742,8 @@ func (g *Generator) generateStruct(f *j.File) error {
 								}
 							}),
 						)
+
+						g.clearForField(f, recv, field)
 					}
 
 					f.Line()
@@ -1506,7 +1752,7 @@ func (g *Generator) generateStruct(f *j.File) error {
 				}
 
 				if t.Readable() {
-					f.Func().Params(
+					fieldFunc(f, field).Params(
 						j.Id("v").Op("*").Add(recv),
 					).Id("Has" + fname).Params().Bool().Block(
 						j.Return(j.Id("v").Dot("data").Dot(name).Op("!=").Nil()),
@@ -1514,7 +1760,7 @@ func (g *Generator) generateStruct(f *j.File) error {
 
 					f.Line()
 
-					f.Func().Params(
+					fieldFunc(f, field).Params(
 						j.Id("v").Op("*").Add(recv),
 					).Id(fname).Params().Op("*").Add(g.properType(field.Type)).Block(
 						j.Return(j.Id("v").Dot("data").Dot(name)),
@@ -1524,7 +1770,7 @@ func (g *Generator) generateStruct(f *j.File) error {
 				}
 
 				if t.Writeable() {
-					f.Func().Params(
+					fieldFunc(f, field).Params(
 						j.Id("v").Op("*").Add(recv),
 					).Id("Set" + fname).Params(
 						j.Id(pname).Op("*").Add(g.properType(field.Type)),
@@ -1534,16 +1780,23 @@ func (g *Generator) generateStruct(f *j.File) error {
 				}
 			}
 
+			if t.Writeable() && field.Type != "union" {
+				g.clearForField(f, recv, field)
+			}
+
 			f.Line()
 		}
 
-		g.generateMarshalers(f, recv.GoString())
+		g.generateMarshalers(f, recv.GoString(), deprecatedFields(t.Fields))
 	}
 	return nil
 }
 
-func (g *Generator) generateMarshalers(f *j.File, expName string) {
+// generateMarshalers generates the encoding methods of expName. Decoding a
+// value with any of the deprecated fields set logs a warning.
+func (g *Generator) generateMarshalers(f *j.File, expName string, deprecated []*DescField) {
 	recv := j.Id(expName)
+	typeName, _, _ := strings.Cut(expName, "[")
 
 	f.Func().Params(
 		j.Id("v").Op("*").Add(recv),
@@ -1557,9 +1810,10 @@ func (g *Generator) generateMarshalers(f *j.File, expName string) {
 		j.Id("v").Op("*").Add(recv),
 	).Id("UnmarshalCBOR").Params(
 		j.Id("data").Index().Byte(),
-	).Error().Block(
-		j.Return(j.Qual("github.com/fxamacker/cbor/v2", "Unmarshal").Call(j.Id("data"), j.Op("&").Id("v").Dot("data"))),
-	)
+	).Error().BlockFunc(func(gr *j.Group) {
+		unmarshal := j.Qual("github.com/fxamacker/cbor/v2", "Unmarshal").Call(j.Id("data"), j.Op("&").Id("v").Dot("data"))
+		g.decodeWithWarnings(gr, typeName, unmarshal, deprecated)
+	})
 
 	f.Line()
 
@@ -1575,9 +1829,44 @@ func (g *Generator) generateMarshalers(f *j.File, expName string) {
 		j.Id("v").Op("*").Add(recv),
 	).Id("UnmarshalJSON").Params(
 		j.Id("data").Index().Byte(),
-	).Error().Block(
-		j.Return(j.Qual("encoding/json", "Unmarshal").Call(j.Id("data"), j.Op("&").Id("v").Dot("data"))),
+	).Error().BlockFunc(func(gr *j.Group) {
+		unmarshal := j.Qual("encoding/json", "Unmarshal").Call(j.Id("data"), j.Op("&").Id("v").Dot("data"))
+		g.decodeWithWarnings(gr, typeName, unmarshal, deprecated)
+	})
+}
+
+// decodeWithWarnings generates a call to unmarshal, followed by a warning for
+// each of the deprecated fields it set.
+func (g *Generator) decodeWithWarnings(gr *j.Group, typeName string, unmarshal j.Code, deprecated []*DescField) {
+	if len(deprecated) == 0 {
+		gr.Return(unmarshal)
+		return
+	}
+
+	gr.If(j.Err().Op(":=").Add(unmarshal), j.Err().Op("!=").Nil()).Block(
+		j.Return(j.Err()),
 	)
+
+	gr.Line()
+
+	g.warnDeprecated(gr, typeName, j.Id("v").Dot("data"), deprecated)
+
+	gr.Line()
+
+	gr.Return(j.Nil())
+}
+
+// warnDeprecated generates a warning for each of the deprecated fields set
+// in data.
+func (g *Generator) warnDeprecated(gr *j.Group, typeName string, data *j.Statement, deprecated []*DescField) {
+	for _, field := range deprecated {
+		gr.If(j.Add(data.Clone()).Dot(toCamal(field.Name)).Op("!=").Nil()).Block(
+			j.Qual("miren.dev/runtime/pkg/rpc", "WarnDeprecated").Call(
+				j.Lit(typeName+"."+field.Name),
+				j.Lit(field.Deprecated),
+			),
+		)
+	}
 }
 
 func (g *Generator) generateClient(f *j.File, i *DescInterface) error {
@@ -1642,7 +1931,7 @@ func (g *Generator) generateClient(f *j.File, i *DescInterface) error {
 			name := capitalize(p.Name)
 
 			if g.ti(p.Type).isInterface {
-				f.Func().Params(
+				fieldFunc(f, p.field(0)).Params(
 					j.Id("v").Op("*").Add(i.typeName(tn + "Results")),
 				).Id(name).Params().Op("*").Id(g.deriveType(p.Type, "Client")).Block(
 					j.Return(j.Op("&").Id(g.deriveType(p.Type, "Client")).Values(
@@ -1654,16 +1943,17 @@ func (g *Generator) generateClient(f *j.File, i *DescInterface) error {
 			} else {
 				g.readForField(f,
 					&DescType{Type: tn + "Results", Generic: i.Generic},
-					&DescField{
-						Name:    p.Name,
-						Type:    p.Type,
-						Element: p.Element,
-						Index:   0,
-					})
+					p.field(0))
 			}
 			f.Line()
 		}
 
+		for _, p := range m.Parameters {
+			if p.Deprecated != "" {
+				f.Comment(fmt.Sprintf("The %s parameter of %s is deprecated: %s", private(p.Name), capitalize(m.Name), p.Deprecated))
+			}
+		}
+
 		f.Func().Params(
 			j.Id("v").Add(recv),
 		).Id(capitalize(m.Name)).ParamsFunc(func(gr *j.Group) {
@@ -1745,12 +2035,37 @@ func (g *Generator) generateClient(f *j.File, i *DescInterface) error {
 					j.Op("&").Id("ret"),
 				)
 			}
-			gr.If(j.Id("err").Op("!=").Nil()).Block(
-				j.Return(j.Nil(), j.Id("err")),
-			)
+			if len(m.Errors) > 0 {
+				gr.If(j.Id("err").Op("!=").Nil()).Block(
+					j.Return(j.Nil(), j.Qual(rpc, "TypedError").CallFunc(func(gr *j.Group) {
+						gr.Id("err")
+						for _, e := range m.Errors {
+							gr.Lit(e.Code)
+						}
+					})),
+				)
+			} else {
+				gr.If(j.Id("err").Op("!=").Nil()).Block(
+					j.Return(j.Nil(), j.Id("err")),
+				)
+			}
 
 			gr.Line()
 
+			var deprecated []*DescField
+
+			for idx, p := range m.Results {
+				if p.Deprecated != "" {
+					deprecated = append(deprecated, p.field(idx))
+				}
+			}
+
+			if len(deprecated) > 0 {
+				g.warnDeprecated(gr, tn+"Results", j.Id("ret"), deprecated)
+
+				gr.Line()
+			}
+
 			gr.Return(j.Op("&").Add(i.typeName(tn+"Results")).Values(
 				j.Id("client").Op(":").Id("v").Dot("Client"),
 				j.Id("data").Op(":").Id("ret")),
@@ -1767,6 +2082,36 @@ func (g *Generator) generateClient(f *j.File, i *DescInterface) error {
 func (g *Generator) generateInterfaces(f *j.File) error {
 	rpc := "miren.dev/runtime/pkg/rpc"
 
+	timeouts := map[*DescMethods][]methodTimeout{}
+
+	for _, i := range g.Interfaces {
+		for _, m := range i.Method {
+			for _, e := range m.Errors {
+				if e.Name == "" || e.Code == "" {
+					return fmt.Errorf("error for %s.%s needs a name and code", i.Name, m.Name)
+				}
+			}
+
+			for _, t := range []methodTimeout{
+				{"timeout", "Timeout", m.Timeout, 0},
+				{"message_timeout", "MessageTimeout", m.MessageTimeout, 0},
+				{"read_idle_timeout", "ReadIdleTimeout", m.ReadIdleTimeout, 0},
+			} {
+				if t.value == "" {
+					continue
+				}
+
+				dur, err := time.ParseDuration(t.value)
+				if err != nil || dur <= 0 {
+					return fmt.Errorf("invalid %s for %s.%s: %q", t.key, i.Name, m.Name, t.value)
+				}
+
+				t.dur = dur
+				timeouts[m] = append(timeouts[m], t)
+			}
+		}
+	}
+
 	for _, i := range g.Interfaces {
 		err := g.generateServerStructs(f, i)
 		if err != nil {
@@ -1816,6 +2161,16 @@ func (g *Generator) generateInterfaces(f *j.File) error {
 			)
 
 			f.Line()
+
+			if len(m.Errors) > 0 {
+				f.Var().DefsFunc(func(g *j.Group) {
+					for _, e := range m.Errors {
+						g.Id("Err"+tn+capitalize(e.Name)).Op("=").Qual(rpc, "NewRPCError").Call(j.Lit(e.Code))
+					}
+				})
+
+				f.Line()
+			}
 		}
 
 		interfaceType, _ := i.addGeneric(expName)
@@ -1880,6 +2235,11 @@ func (g *Generator) generateInterfaces(f *j.File) error {
 								j.Id("ctx"),
 								j.Op("&").Add(i.typeName(expName+toCamal(m.Name))).Values(j.Id("Call").Op(":").Id("call")),
 							)))
+
+						for _, t := range timeouts[m] {
+							g.Line().Id(t.field).Op(":").Add(durationCode(t.dur))
+						}
+
 						g.Line()
 					})
 				}
@@ -1946,7 +2306,7 @@ func (g *Generator) Generate(pkgName string) (string, error) {
 		return "", errors.New(sb.String())
 	}
 
-	code, err := imports.Process("out.go", buf.Bytes(), &imports.Options{})
+	code, err := imports.Process("out.go", buf.Bytes(), &imports.Options{Comments: true})
 	if err != nil {
 		str := err.Error()
 		lines := strings.Split(str, "\n")
@@ -2142,6 +2502,10 @@ type DescField struct {
 	Element string       `yaml:"element"`
 	Union   []UnionField `yaml:"union,omitempty"`
 
+	// Deprecated is why the field is being phased out. Its accessors are
+	// marked deprecated and decoding a value with it set logs a warning.
+	Deprecated string `yaml:"deprecated,omitempty"`
+
 	dataOffset int
 	wordOffset int
 
@@ -2167,10 +2531,85 @@ type DescMethods struct {
 	Index      int              `yaml:"index"`
 	Parameters []*DescParamater `yaml:"parameters"`
 	Results    []*DescParamater `yaml:"results"`
+
+	// Timeout is how long the server lets the method run, as a Go
+	// duration such as "30s". Empty means no limit.
+	Timeout string `yaml:"timeout,omitempty"`
+
+	// MessageTimeout and ReadIdleTimeout limit how long the server waits
+	// for the method's arguments to arrive, in total and between reads.
+	// Empty means no limit.
+	MessageTimeout  string `yaml:"message_timeout,omitempty"`
+	ReadIdleTimeout string `yaml:"read_idle_timeout,omitempty"`
+
+	// Errors are the typed errors the method returns.
+	Errors []*DescError `yaml:"errors,omitempty"`
+}
+
+// DescError declares an error a method returns, such as
+//
+//	errors:
+//	  - name: unknownMeter
+//	    code: not-found
+//
+// which generates ErrMeterReadTemperatureUnknownMeter for the server to
+// return and the client to match against with errors.Is.
+type DescError struct {
+	Name string `yaml:"name"`
+	Code string `yaml:"code"`
 }
 
 type DescParamater struct {
 	Name    string `yaml:"name"`
 	Type    string `yaml:"type"`
 	Element string `yaml:"element,omitempty"`
+
+	// Stream, on a list result, adds a variant of the method that streams
+	// the list's elements rather than returning them together.
+	Stream bool `yaml:"stream,omitempty"`
+
+	// Deprecated is why the parameter or result is being phased out, as
+	// with DescField.
+	Deprecated string `yaml:"deprecated,omitempty"`
+}
+
+func (p *DescParamater) field(idx int) *DescField {
+	return &DescField{
+		Name:       p.Name,
+		Type:       p.Type,
+		Element:    p.Element,
+		Index:      idx,
+		Deprecated: p.Deprecated,
+	}
+}
+
+// methodTimeout is a duration declared for a method in the schema, and the
+// rpc.Method field it's generated into.
+type methodTimeout struct {
+	key, field string
+	value      string
+	dur        time.Duration
+}
+
+// durationCode renders dur in the largest unit that holds it exactly, such as
+// 30 * time.Second.
+func durationCode(dur time.Duration) j.Code {
+	units := []struct {
+		unit time.Duration
+		name string
+	}{
+		{time.Hour, "Hour"},
+		{time.Minute, "Minute"},
+		{time.Second, "Second"},
+		{time.Millisecond, "Millisecond"},
+		{time.Microsecond, "Microsecond"},
+	}
+
+	for _, u := range units {
+		if dur%u.unit == 0 {
+			return j.Lit(int(dur/u.unit)).Op("*").Qual("time", u.name)
+		}
+	}
+
+	return j.Qual("time", "Duration").Call(j.Lit(int64(dur)))
 }
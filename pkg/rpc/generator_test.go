@@ -115,4 +115,75 @@ func TestGenerator(t *testing.T) {
 
 		r.Equal(string(data), output)
 	})
+
+	t.Run("can generate a streaming variant of a list method", func(t *testing.T) {
+		r := require.New(t)
+
+		g, err := NewGenerator()
+		r.NoError(err)
+
+		err = g.Read("testdata/streamlist.yml")
+		r.NoError(err)
+
+		output, err := g.Generate("streamlist")
+		r.NoError(err)
+
+		data, err := os.ReadFile("testdata/streamlist.go")
+		r.NoError(err)
+
+		r.Equal(string(data), output)
+	})
+	t.Run("marks deprecated fields", func(t *testing.T) {
+		r := require.New(t)
+
+		g, err := NewGenerator()
+		r.NoError(err)
+
+		err = g.Read("testdata/deprecated.yml")
+		r.NoError(err)
+
+		output, err := g.Generate("deprecated")
+		r.NoError(err)
+
+		data, err := os.ReadFile("testdata/deprecated.go")
+		r.NoError(err)
+
+		r.Equal(string(data), output)
+	})
+
+	t.Run("generates python client stubs", func(t *testing.T) {
+		r := require.New(t)
+
+		g, err := NewGenerator()
+		r.NoError(err)
+
+		err = g.Read("testdata/crud.yml")
+		r.NoError(err)
+
+		output, err := g.GeneratePython()
+		r.NoError(err)
+
+		data, err := os.ReadFile("testdata/crud.py")
+		r.NoError(err)
+
+		r.Equal(string(data), output)
+	})
+
+	t.Run("maps duration to standard.Duration", func(t *testing.T) {
+		r := require.New(t)
+
+		g, err := NewGenerator()
+		r.NoError(err)
+
+		err = g.Read("testdata/duration.yml")
+		r.NoError(err)
+
+		output, err := g.Generate("duration")
+		r.NoError(err)
+
+		data, err := os.ReadFile("testdata/duration.go")
+		r.NoError(err)
+
+		r.Equal(string(data), output)
+	})
 }
@@ -0,0 +1,412 @@
+package rpc
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// pythonKeywords are names that can't be used as Python identifiers.
+var pythonKeywords = []string{
+	"False", "None", "True", "and", "as", "assert", "async", "await",
+	"break", "class", "continue", "def", "del", "elif", "else", "except",
+	"finally", "for", "from", "global", "if", "import", "in", "is",
+	"lambda", "nonlocal", "not", "or", "pass", "raise", "return", "try",
+	"while", "with", "yield",
+}
+
+func pyName(name string) string {
+	name = toSnake(name)
+
+	if slices.Contains(pythonKeywords, name) {
+		return name + "_"
+	}
+
+	return name
+}
+
+const pythonHeader = `# Code generated by rpcgen. DO NOT EDIT.
+
+from __future__ import annotations
+
+import base64
+import json
+from dataclasses import dataclass
+from typing import Any, Callable, Optional, Protocol, TypeVar
+
+import cbor2
+
+T = TypeVar("T")
+
+
+class Transport(Protocol):
+    """Carries calls to a capability. Arguments and results are CBOR maps
+    keyed by field index, as produced by to_cbor and read by from_cbor."""
+
+    def call(self, method: str, args: dict[int, Any]) -> dict[int, Any]: ...
+
+
+def _decode(value: Any, decode: Callable[[Any], T]) -> Optional[T]:
+    return None if value is None else decode(value)
+
+
+def _decode_list(value: Any, decode: Callable[[Any], T]) -> Optional[list[T]]:
+    return None if value is None else [decode(v) for v in value]
+
+
+def dumps_cbor(value: Any) -> bytes:
+    return cbor2.dumps(value.to_cbor())
+
+
+def loads_cbor(cls: Any, data: bytes) -> Any:
+    return cls.from_cbor(cbor2.loads(data))
+
+
+def dumps_json(value: Any) -> str:
+    return json.dumps(value.to_json())
+
+
+def loads_json(cls: Any, data: str) -> Any:
+    return cls.from_json(json.loads(data))
+`
+
+// pyField is a field of a generated dataclass.
+type pyField struct {
+	name     string // python attribute name
+	jsonName string
+	index    int
+	typ      string
+	element  string
+}
+
+// pyWriter accumulates Python source.
+type pyWriter struct {
+	sb strings.Builder
+}
+
+func (w *pyWriter) line(indent int, format string, args ...any) {
+	if format == "" {
+		w.sb.WriteString("\n")
+		return
+	}
+
+	w.sb.WriteString(strings.Repeat("    ", indent))
+	fmt.Fprintf(&w.sb, format, args...)
+	w.sb.WriteString("\n")
+}
+
+// pyType returns the Python annotation for a schema type.
+func (g *Generator) pyType(typ, element string) string {
+	switch typ {
+	case "bool":
+		return "bool"
+	case "int32", "int64", "uint32", "uint64":
+		return "int"
+	case "float32", "float64":
+		return "float"
+	case "string":
+		return "str"
+	case "bytes":
+		return "bytes"
+	case "list":
+		return "list[" + g.pyType(element, "") + "]"
+	}
+
+	if g.pyMessage(typ) {
+		return typ
+	}
+
+	return "Any"
+}
+
+// pyMessage reports whether typ is a message generated in this file.
+func (g *Generator) pyMessage(typ string) bool {
+	if strings.ContainsAny(typ, ".[") {
+		return false
+	}
+
+	for _, t := range g.Types {
+		if t.Type == typ {
+			return len(t.Generic) == 0
+		}
+	}
+
+	return false
+}
+
+// pyEncode returns the expression encoding value of typ for CBOR, or JSON
+// when json is set.
+func (g *Generator) pyEncode(value, typ, element string, json bool) string {
+	method := "to_cbor"
+	if json {
+		method = "to_json"
+	}
+
+	switch {
+	case typ == "bytes" && json:
+		return fmt.Sprintf("base64.b64encode(%s).decode()", value)
+	case typ == "list" && g.pyMessage(element):
+		return fmt.Sprintf("[v.%s() for v in %s]", method, value)
+	case typ == "list" && element == "bytes" && json:
+		return fmt.Sprintf("[base64.b64encode(v).decode() for v in %s]", value)
+	case g.pyMessage(typ):
+		return fmt.Sprintf("%s.%s()", value, method)
+	}
+
+	return value
+}
+
+// pyDecode returns the expression decoding value of typ from CBOR, or JSON
+// when json is set.
+func (g *Generator) pyDecode(value, typ, element string, json bool) string {
+	method := "from_cbor"
+	if json {
+		method = "from_json"
+	}
+
+	switch {
+	case typ == "bytes" && json:
+		return fmt.Sprintf("_decode(%s, base64.b64decode)", value)
+	case typ == "list" && g.pyMessage(element):
+		return fmt.Sprintf("_decode_list(%s, %s.%s)", value, element, method)
+	case typ == "list" && element == "bytes" && json:
+		return fmt.Sprintf("_decode_list(%s, base64.b64decode)", value)
+	case g.pyMessage(typ):
+		return fmt.Sprintf("_decode(%s, %s.%s)", value, typ, method)
+	}
+
+	return value
+}
+
+// writeDataclass writes a dataclass holding fields, with methods converting
+// it to and from its CBOR and JSON forms. Compact types are encoded as CBOR
+// arrays.
+func (g *Generator) writeDataclass(w *pyWriter, name string, fields []pyField, compact bool) {
+	w.line(0, "")
+	w.line(0, "")
+	w.line(0, "@dataclass")
+	w.line(0, "class %s:", name)
+
+	for _, f := range fields {
+		w.line(1, "%s: Optional[%s] = None", f.name, g.pyType(f.typ, f.element))
+	}
+
+	w.line(0, "")
+
+	if compact {
+		w.line(1, "def to_cbor(self) -> list[Any]:")
+		w.line(2, "return [")
+		for _, f := range fields {
+			w.line(3, "%s,", g.pyEncodeOptional("self."+f.name, f, false))
+		}
+		w.line(2, "]")
+	} else {
+		w.line(1, "def to_cbor(self) -> dict[int, Any]:")
+		writeEncodeFields(w, "dict[int, Any]", fields, func(f pyField) (string, string) {
+			return fmt.Sprint(f.index), g.pyEncode("self."+f.name, f.typ, f.element, false)
+		})
+	}
+
+	w.line(0, "")
+	w.line(1, "@classmethod")
+
+	if compact {
+		w.line(1, "def from_cbor(cls, data: list[Any]) -> %s:", name)
+		w.line(2, "return cls(")
+		for i, f := range fields {
+			w.line(3, "%s=%s,", f.name, g.pyDecode(fmt.Sprintf("data[%d] if len(data) > %d else None", i, i), f.typ, f.element, false))
+		}
+		w.line(2, ")")
+	} else {
+		w.line(1, "def from_cbor(cls, data: dict[int, Any]) -> %s:", name)
+		writeDecodeFields(w, fields, func(f pyField) string {
+			return g.pyDecode(fmt.Sprintf("data.get(%d)", f.index), f.typ, f.element, false)
+		})
+	}
+
+	w.line(0, "")
+	w.line(1, "def to_json(self) -> dict[str, Any]:")
+	writeEncodeFields(w, "dict[str, Any]", fields, func(f pyField) (string, string) {
+		return fmt.Sprintf("%q", f.jsonName), g.pyEncode("self."+f.name, f.typ, f.element, true)
+	})
+
+	w.line(0, "")
+	w.line(1, "@classmethod")
+	w.line(1, "def from_json(cls, data: dict[str, Any]) -> %s:", name)
+	writeDecodeFields(w, fields, func(f pyField) string {
+		return g.pyDecode(fmt.Sprintf("data.get(%q)", f.jsonName), f.typ, f.element, true)
+	})
+}
+
+// writeEncodeFields writes the body of a method returning a map of the
+// fields that are set, keyed and encoded by enc.
+func writeEncodeFields(w *pyWriter, typ string, fields []pyField, enc func(f pyField) (key, value string)) {
+	if len(fields) == 0 {
+		w.line(2, "return {}")
+		return
+	}
+
+	w.line(2, "out: %s = {}", typ)
+	for _, f := range fields {
+		key, value := enc(f)
+		w.line(2, "if self.%s is not None:", f.name)
+		w.line(3, "out[%s] = %s", key, value)
+	}
+	w.line(2, "return out")
+}
+
+// writeDecodeFields writes the body of a classmethod constructing the class
+// from fields decoded by dec.
+func writeDecodeFields(w *pyWriter, fields []pyField, dec func(f pyField) string) {
+	if len(fields) == 0 {
+		w.line(2, "return cls()")
+		return
+	}
+
+	w.line(2, "return cls(")
+	for _, f := range fields {
+		w.line(3, "%s=%s,", f.name, dec(f))
+	}
+	w.line(2, ")")
+}
+
+// pyEncodeOptional encodes value, which may be None.
+func (g *Generator) pyEncodeOptional(value string, f pyField, json bool) string {
+	enc := g.pyEncode(value, f.typ, f.element, json)
+	if enc == value {
+		return value
+	}
+
+	return fmt.Sprintf("None if %s is None else %s", value, enc)
+}
+
+// pySupported reports whether a method can be called from the Python client,
+// which doesn't pass capabilities.
+func (g *Generator) pySupported(m *DescMethods) bool {
+	for _, p := range slices.Concat(m.Parameters, m.Results) {
+		if g.ti(p.Type).isInterface || g.ti(p.Element).isInterface {
+			return false
+		}
+	}
+
+	return true
+}
+
+// GeneratePython generates Python client stubs for the schema: a dataclass
+// for each type, and for each interface a client whose methods make calls
+// over a Transport.
+func (g *Generator) GeneratePython() (string, error) {
+	for _, t := range g.Types {
+		err := t.Validate()
+		if err != nil {
+			return "", err
+		}
+	}
+
+	var w pyWriter
+
+	w.sb.WriteString(pythonHeader)
+
+	for _, t := range g.Types {
+		if len(t.Generic) > 0 {
+			w.line(0, "")
+			w.line(0, "")
+			w.line(0, "# %s is generic, which the Python client doesn't support.", t.Type)
+			continue
+		}
+
+		var fields []pyField
+
+		for _, f := range t.Fields {
+			typ := f.Type
+			if typ == "union" {
+				typ = "Any"
+			}
+
+			fields = append(fields, pyField{
+				name:     pyName(f.Name),
+				jsonName: toSnake(f.Name),
+				index:    f.Index,
+				typ:      typ,
+				element:  f.Element,
+			})
+		}
+
+		g.writeDataclass(&w, capitalize(t.Type), fields, t.Compact)
+	}
+
+	for _, i := range g.Interfaces {
+		if len(i.Generic) > 0 {
+			w.line(0, "")
+			w.line(0, "")
+			w.line(0, "# %s is generic, which the Python client doesn't support.", i.Name)
+			continue
+		}
+
+		g.writePythonClient(&w, i)
+	}
+
+	return w.sb.String(), nil
+}
+
+func (g *Generator) writePythonClient(w *pyWriter, i *DescInterface) {
+	name := capitalize(i.Name) + "Client"
+
+	for _, m := range i.Method {
+		if !g.pySupported(m) {
+			continue
+		}
+
+		var fields []pyField
+
+		for idx, p := range m.Results {
+			fields = append(fields, pyField{
+				name:     pyName(p.Name),
+				jsonName: p.Name,
+				index:    idx,
+				typ:      p.Type,
+				element:  p.Element,
+			})
+		}
+
+		g.writeDataclass(w, name+capitalize(m.Name)+"Results", fields, false)
+	}
+
+	w.line(0, "")
+	w.line(0, "")
+	w.line(0, "class %s:", name)
+	w.line(1, `"""Calls the methods of a %s capability."""`, capitalize(i.Name))
+	w.line(0, "")
+	w.line(1, "def __init__(self, transport: Transport) -> None:")
+	w.line(2, "self.transport = transport")
+
+	for _, m := range i.Method {
+		w.line(0, "")
+
+		if !g.pySupported(m) {
+			w.line(1, "# %s passes capabilities, which the Python client doesn't support.", m.Name)
+			continue
+		}
+
+		results := name + capitalize(m.Name) + "Results"
+
+		params := []string{"self"}
+		for _, p := range m.Parameters {
+			params = append(params, fmt.Sprintf("%s: %s", pyName(p.Name), g.pyType(p.Type, p.Element)))
+		}
+
+		w.line(1, "def %s(%s) -> %s:", pyName(m.Name), strings.Join(params, ", "), results)
+
+		if len(m.Parameters) == 0 {
+			w.line(2, "args: dict[int, Any] = {}")
+		} else {
+			w.line(2, "args: dict[int, Any] = {")
+			for idx, p := range m.Parameters {
+				w.line(3, "%d: %s,", idx, g.pyEncode(pyName(p.Name), p.Type, p.Element, false))
+			}
+			w.line(2, "}")
+		}
+
+		w.line(2, "return %s.from_cbor(self.transport.call(%q, args))", results, m.Name)
+	}
+}
@@ -0,0 +1,338 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+	"miren.dev/runtime/pkg/cond"
+)
+
+// Kinds of RecordedMessage.
+const (
+	RecordedCallKind   = "call"
+	RecordedResultKind = "result"
+)
+
+// RecordedMessage is one entry in a recording: the arguments of a call as
+// it was made, or its outcome once it returned. A call and its result share
+// a Seq.
+type RecordedMessage struct {
+	Time   time.Time `cbor:"time"`
+	Kind   string    `cbor:"kind"`
+	Seq    uint64    `cbor:"seq"`
+	OID    OID       `cbor:"oid"`
+	Method string    `cbor:"method"`
+
+	// Data is the call's arguments, or its results if it succeeded.
+	Data cbor.RawMessage `cbor:"data,omitempty"`
+
+	// Status is "ok" or "error" for results, with the error's details.
+	Status        string `cbor:"status,omitempty"`
+	Error         string `cbor:"error,omitempty"`
+	ErrorCategory string `cbor:"error_category,omitempty"`
+	ErrorCode     string `cbor:"error_code,omitempty"`
+}
+
+// Err returns the error a result recorded, as the client saw it.
+func (m *RecordedMessage) Err() error {
+	if m.Status != "error" {
+		return nil
+	}
+
+	return cond.RemoteError(m.ErrorCategory, m.ErrorCode, m.Error)
+}
+
+// Recorder writes the unary calls made or handled by a State to a log, for
+// replaying them later with ReplayCalls or NewReplayClient. Streaming and
+// inline calls aren't recorded.
+type Recorder struct {
+	mu  sync.Mutex
+	enc *cbor.Encoder
+	seq uint64
+	err error
+}
+
+// NewRecorder returns a Recorder writing the log to w.
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{enc: cbor.NewEncoder(w)}
+}
+
+// WithRecorder records the calls the state's clients make and its server
+// handles to rec.
+func WithRecorder(rec *Recorder) StateOption {
+	return func(o *stateOptions) {
+		o.recorder = rec
+	}
+}
+
+func (s *State) recorder() *Recorder {
+	if s == nil || s.opts == nil {
+		return nil
+	}
+
+	return s.opts.recorder
+}
+
+// Err returns the first error writing the log, after which nothing more is
+// recorded.
+func (r *Recorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.err
+}
+
+func (r *Recorder) write(msg *RecordedMessage) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err != nil {
+		return
+	}
+
+	msg.Time = time.Now()
+
+	r.err = r.enc.Encode(msg)
+}
+
+// record logs a call with the given encoded arguments, runs it, and logs
+// the results or error it returns.
+func (r *Recorder) record(oid OID, method string, args []byte, invoke func() (any, error)) error {
+	r.mu.Lock()
+	r.seq++
+	seq := r.seq
+	r.mu.Unlock()
+
+	r.write(&RecordedMessage{
+		Kind:   RecordedCallKind,
+		Seq:    seq,
+		OID:    oid,
+		Method: method,
+		Data:   args,
+	})
+
+	results, err := invoke()
+
+	msg := &RecordedMessage{
+		Kind:   RecordedResultKind,
+		Seq:    seq,
+		OID:    oid,
+		Method: method,
+		Status: "ok",
+	}
+
+	if err != nil {
+		msg.setError(err)
+	} else if data, merr := cbor.Marshal(results); merr == nil {
+		msg.Data = data
+	}
+
+	r.write(msg)
+
+	return err
+}
+
+func (m *RecordedMessage) setError(err error) {
+	m.Status = "error"
+	m.Error = err.Error()
+
+	if emsg, ok := err.(ErrorMessage); ok {
+		m.Error = emsg.ErrorMessage()
+	}
+
+	if ecat, ok := err.(ErrorCategory); ok {
+		m.ErrorCategory = ecat.ErrorCategory()
+	}
+
+	if ecode, ok := err.(ErrorCode); ok {
+		m.ErrorCode = ecode.ErrorCode()
+	}
+}
+
+// RecordedCall is a call read back from a recording, with its result if
+// one was recorded.
+type RecordedCall struct {
+	Time   time.Time
+	OID    OID
+	Method string
+	Args   cbor.RawMessage
+
+	Result *RecordedMessage
+}
+
+// ReadRecording reads the log written by a Recorder, returning the calls
+// in the order they were made.
+func ReadRecording(r io.Reader) ([]*RecordedCall, error) {
+	dec := cbor.NewDecoder(r)
+
+	var (
+		calls []*RecordedCall
+		bySeq = make(map[uint64]*RecordedCall)
+	)
+
+	for {
+		var msg RecordedMessage
+
+		if err := dec.Decode(&msg); err != nil {
+			if errors.Is(err, io.EOF) {
+				return calls, nil
+			}
+
+			return nil, fmt.Errorf("reading recording: %w", err)
+		}
+
+		switch msg.Kind {
+		case RecordedCallKind:
+			call := &RecordedCall{
+				Time:   msg.Time,
+				OID:    msg.OID,
+				Method: msg.Method,
+				Args:   msg.Data,
+			}
+
+			calls = append(calls, call)
+			bySeq[msg.Seq] = call
+		case RecordedResultKind:
+			if call, ok := bySeq[msg.Seq]; ok {
+				call.Result = &msg
+			}
+		default:
+			return nil, fmt.Errorf("unknown recorded message kind %q", msg.Kind)
+		}
+	}
+}
+
+// ReplayedCall is the outcome of replaying a recorded call.
+type ReplayedCall struct {
+	*RecordedCall
+
+	Results cbor.RawMessage
+	Err     error
+}
+
+// Diverged reports whether the replayed call turned out differently than
+// the recorded one, returning other results or a different error.
+func (c *ReplayedCall) Diverged() bool {
+	if c.Result == nil {
+		return false
+	}
+
+	if c.Err != nil || c.Result.Status == "error" {
+		var replayed RecordedMessage
+		if c.Err != nil {
+			replayed.setError(c.Err)
+		}
+
+		return replayed.Status != c.Result.Status ||
+			replayed.Error != c.Result.Error ||
+			replayed.ErrorCode != c.Result.ErrorCode
+	}
+
+	return !bytes.Equal(c.Results, c.Result.Data)
+}
+
+// ReplayCalls invokes each recorded call on iface in the order they were
+// recorded, one at a time, so that a handler sees the same sequence of
+// calls on every replay.
+func ReplayCalls(ctx context.Context, calls []*RecordedCall, iface *Interface) ([]*ReplayedCall, error) {
+	env := &localEnv{caps: make(map[OID]*Interface)}
+
+	var replayed []*ReplayedCall
+
+	for _, rc := range calls {
+		m, ok := iface.methods[rc.Method]
+		if !ok || m.Handler == nil {
+			return replayed, fmt.Errorf("replaying call to unknown method %s", rc.Method)
+		}
+
+		call := &NetworkCall{
+			oid:     rc.OID,
+			method:  rc.Method,
+			argData: rc.Args,
+			local:   &localCall{localEnv: env},
+		}
+
+		rep := &ReplayedCall{RecordedCall: rc}
+
+		rep.Err = m.invoke(ctx, call)
+		if rep.Err == nil {
+			rep.Results, rep.Err = cbor.Marshal(call.results)
+		}
+
+		replayed = append(replayed, rep)
+	}
+
+	return replayed, nil
+}
+
+// replayLog is the recorded calls shared by a replay client and the
+// clients derived from it, consumed in order.
+type replayLog struct {
+	mu    sync.Mutex
+	calls []*RecordedCall
+}
+
+type replayClient struct {
+	log *replayLog
+}
+
+// NewReplayClient returns a client that answers calls with the results
+// recorded for them, rather than contacting a server. Calls have to be
+// made in the order they were recorded; any other call fails. Clients for
+// capabilities returned in the results share the same recording.
+func NewReplayClient(calls []*RecordedCall) Client {
+	return &replayClient{log: &replayLog{calls: calls}}
+}
+
+func (c *replayClient) Call(ctx context.Context, method string, args, result any) error {
+	c.log.mu.Lock()
+
+	if len(c.log.calls) == 0 {
+		c.log.mu.Unlock()
+		return fmt.Errorf("replay diverged: call to %s after the end of the recording", method)
+	}
+
+	rc := c.log.calls[0]
+
+	if rc.Method != method {
+		c.log.mu.Unlock()
+		return fmt.Errorf("replay diverged: call to %s, recorded call was to %s", method, rc.Method)
+	}
+
+	c.log.calls = c.log.calls[1:]
+	c.log.mu.Unlock()
+
+	if rc.Result == nil {
+		return fmt.Errorf("replay diverged: no result recorded for call to %s", method)
+	}
+
+	if err := rc.Result.Err(); err != nil {
+		return err
+	}
+
+	return cbor.Unmarshal(rc.Result.Data, result)
+}
+
+func (c *replayClient) CallWithCaps(ctx context.Context, method string, args, result any, caps map[OID]*InlineCapability) error {
+	return c.Call(ctx, method, args, result)
+}
+
+func (c *replayClient) NewInlineCapability(i *Interface, lower any) (*InlineCapability, OID, *Capability) {
+	capa := &Capability{OID: OID("replay")}
+
+	return &InlineCapability{Capability: capa, Interface: i}, capa.OID, capa
+}
+
+func (c *replayClient) NewClient(capa *Capability) Client {
+	return c
+}
+
+func (c *replayClient) Close() error {
+	return nil
+}
@@ -0,0 +1,135 @@
+package rpc_test
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"miren.dev/runtime/pkg/cond"
+	"miren.dev/runtime/pkg/rpc"
+	"miren.dev/runtime/pkg/rpc/example"
+)
+
+// namedMeter remembers which meters it was asked to read, and fails to
+// read any called "missing".
+type namedMeter struct {
+	exampleMeter
+
+	mu    sync.Mutex
+	names []string
+}
+
+func (m *namedMeter) ReadTemperature(ctx context.Context, call *example.MeterReadTemperature) error {
+	name := call.Args().Name()
+
+	m.mu.Lock()
+	m.names = append(m.names, name)
+	m.mu.Unlock()
+
+	if name == "missing" {
+		return cond.NotFound("meter", name)
+	}
+
+	return m.exampleMeter.ReadTemperature(ctx, call)
+}
+
+func TestRecordReplay(t *testing.T) {
+	r := require.New(t)
+	ctx := t.Context()
+
+	var serverLog, clientLog bytes.Buffer
+
+	meter := &namedMeter{exampleMeter: exampleMeter{temp: 42}}
+
+	ss, err := rpc.NewState(ctx, rpc.WithSkipVerify, rpc.WithRecorder(rpc.NewRecorder(&serverLog)))
+	r.NoError(err)
+	defer ss.Close()
+
+	ss.Server().ExposeValue("meter", example.AdaptMeter(meter))
+
+	cs, err := rpc.NewState(ctx, rpc.WithSkipVerify, rpc.WithRecorder(rpc.NewRecorder(&clientLog)))
+	r.NoError(err)
+	defer cs.Close()
+
+	c, err := cs.Connect(ss.ListenAddr(), "meter")
+	r.NoError(err)
+
+	mc := &example.MeterClient{Client: c}
+
+	for _, name := range []string{"kitchen", "missing", "garage"} {
+		_, err := mc.ReadTemperature(ctx, name)
+		if name == "missing" {
+			r.ErrorIs(err, cond.ErrNotFound{})
+		} else {
+			r.NoError(err)
+		}
+	}
+
+	t.Run("replays a server's calls against a handler", func(t *testing.T) {
+		r := require.New(t)
+
+		calls, err := rpc.ReadRecording(bytes.NewReader(serverLog.Bytes()))
+		r.NoError(err)
+		r.Len(calls, 3)
+
+		replay := &namedMeter{exampleMeter: exampleMeter{temp: 42}}
+
+		replayed, err := rpc.ReplayCalls(ctx, calls, example.AdaptMeter(replay))
+		r.NoError(err)
+		r.Len(replayed, 3)
+
+		r.Equal(meter.names, replay.names)
+
+		for _, rc := range replayed {
+			r.Equal("readTemperature", rc.Method)
+			r.NotNil(rc.Result)
+			r.False(rc.Diverged(), "call to %s diverged", rc.Method)
+		}
+
+		r.ErrorIs(replayed[1].Err, cond.ErrNotFound{})
+	})
+
+	t.Run("flags replayed calls that turn out differently", func(t *testing.T) {
+		r := require.New(t)
+
+		calls, err := rpc.ReadRecording(bytes.NewReader(serverLog.Bytes()))
+		r.NoError(err)
+
+		replayed, err := rpc.ReplayCalls(ctx, calls, example.AdaptMeter(&namedMeter{exampleMeter: exampleMeter{temp: 10}}))
+		r.NoError(err)
+
+		r.True(replayed[0].Diverged())
+		r.False(replayed[1].Diverged())
+		r.True(replayed[2].Diverged())
+	})
+
+	t.Run("replays a server's responses to a client", func(t *testing.T) {
+		r := require.New(t)
+
+		calls, err := rpc.ReadRecording(bytes.NewReader(clientLog.Bytes()))
+		r.NoError(err)
+		r.Len(calls, 3)
+
+		rc := &example.MeterClient{Client: rpc.NewReplayClient(calls)}
+
+		res, err := rc.ReadTemperature(ctx, "kitchen")
+		r.NoError(err)
+		r.Equal("kitchen", res.Reading().Meter())
+		r.Equal(float32(42), res.Reading().Temperature())
+
+		_, err = rc.ReadTemperature(ctx, "missing")
+		r.ErrorIs(err, cond.ErrNotFound{})
+
+		_, err = rc.GetSetter(ctx, "garage")
+		r.ErrorContains(err, "replay diverged")
+
+		res, err = rc.ReadTemperature(ctx, "garage")
+		r.NoError(err)
+		r.Equal("garage", res.Reading().Meter())
+
+		_, err = rc.ReadTemperature(ctx, "attic")
+		r.ErrorContains(err, "end of the recording")
+	})
+}
@@ -0,0 +1,71 @@
+package rpc
+
+import (
+	"context"
+
+	"miren.dev/runtime/pkg/idgen"
+)
+
+// RequestIDHeader carries a call's request id. Clients may send it to choose
+// the id, and servers always return the id they used.
+const RequestIDHeader = "rpc-request-id"
+
+// maxRequestIDLen bounds the length of a client supplied request id.
+const maxRequestIDLen = 128
+
+type requestIDKey struct{}
+
+// WithRequestID returns a context whose calls use id as their request id.
+// Handlers receive a context carrying their call's id, so calls made while
+// handling a call share its id.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request id carried by ctx, or "" if there is none.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+type returnRequestIDKey struct{}
+
+// ReturnRequestID returns a context whose calls store the request id the
+// server used in *id once they complete.
+func ReturnRequestID(ctx context.Context, id *string) context.Context {
+	return context.WithValue(ctx, returnRequestIDKey{}, id)
+}
+
+// storeRequestID records the request id returned by the server for
+// ReturnRequestID.
+func storeRequestID(ctx context.Context, id string) {
+	if p, ok := ctx.Value(returnRequestIDKey{}).(*string); ok && id != "" {
+		*p = id
+	}
+}
+
+// validRequestID reports whether a client supplied id is short and printable
+// enough to be used in headers and logs.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// callRequestID returns the id to use for a call, taking the client's when it
+// supplied a valid one.
+func callRequestID(supplied string) string {
+	if validRequestID(supplied) {
+		return supplied
+	}
+
+	return idgen.Gen("req-")
+}
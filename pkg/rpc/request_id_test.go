@@ -0,0 +1,106 @@
+package rpc_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"miren.dev/runtime/pkg/rpc"
+	"miren.dev/runtime/pkg/rpc/example"
+)
+
+// requestIDMeter records the request id its handler sees at the start and
+// end of each call.
+type requestIDMeter struct {
+	exampleMeter
+
+	mu  sync.Mutex
+	ids [][2]string
+}
+
+func (m *requestIDMeter) ReadTemperature(ctx context.Context, call *example.MeterReadTemperature) error {
+	start := rpc.RequestID(ctx)
+
+	err := m.exampleMeter.ReadTemperature(ctx, call)
+
+	m.mu.Lock()
+	m.ids = append(m.ids, [2]string{start, rpc.RequestID(ctx)})
+	m.mu.Unlock()
+
+	return err
+}
+
+func (m *requestIDMeter) last() [2]string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.ids[len(m.ids)-1]
+}
+
+func TestRequestID(t *testing.T) {
+	r := require.New(t)
+	ctx := t.Context()
+
+	meter := &requestIDMeter{exampleMeter: exampleMeter{temp: 42}}
+
+	ss, err := rpc.NewState(ctx, rpc.WithSkipVerify)
+	r.NoError(err)
+
+	ss.Server().ExposeValue("meter", example.AdaptMeter(meter))
+
+	cs, err := rpc.NewState(ctx, rpc.WithSkipVerify)
+	r.NoError(err)
+
+	c, err := cs.Connect(ss.ListenAddr(), "meter")
+	r.NoError(err)
+
+	mc := &example.MeterClient{Client: c}
+
+	t.Run("server generates an id returned in the response", func(t *testing.T) {
+		r := require.New(t)
+
+		var returned string
+		_, err := mc.ReadTemperature(rpc.ReturnRequestID(ctx, &returned), "test")
+		r.NoError(err)
+
+		ids := meter.last()
+		r.NotEmpty(ids[0])
+		r.True(strings.HasPrefix(ids[0], "req-"), "id %q", ids[0])
+		r.Equal(ids[0], ids[1], "id should be stable across the handler")
+		r.Equal(ids[0], returned)
+
+		// Each call gets its own id.
+		var again string
+		_, err = mc.ReadTemperature(rpc.ReturnRequestID(ctx, &again), "test")
+		r.NoError(err)
+		r.NotEqual(returned, again)
+	})
+
+	t.Run("a client supplied id is used throughout", func(t *testing.T) {
+		r := require.New(t)
+
+		var returned string
+		cctx := rpc.ReturnRequestID(rpc.WithRequestID(ctx, "client-chosen-1"), &returned)
+
+		_, err := mc.ReadTemperature(cctx, "test")
+		r.NoError(err)
+
+		r.Equal([2]string{"client-chosen-1", "client-chosen-1"}, meter.last())
+		r.Equal("client-chosen-1", returned)
+	})
+
+	t.Run("an unusable client id is replaced", func(t *testing.T) {
+		r := require.New(t)
+
+		var returned string
+		cctx := rpc.ReturnRequestID(rpc.WithRequestID(ctx, "has spaces"), &returned)
+
+		_, err := mc.ReadTemperature(cctx, "test")
+		r.NoError(err)
+
+		r.True(strings.HasPrefix(returned, "req-"), "id %q", returned)
+		r.Equal(returned, meter.last()[0])
+	})
+}
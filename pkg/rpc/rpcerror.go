@@ -0,0 +1,130 @@
+package rpc
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"miren.dev/runtime/pkg/cond"
+)
+
+// Well known error codes. The cond package's errors carry these too, so they
+// match RPCErrors declared with the same code.
+const (
+	CodeNotFound         = "not-found"
+	CodeAlreadyExists    = "already-exists"
+	CodeConflict         = "conflict"
+	CodePermissionDenied = "permission-denied"
+	CodeInvalid          = "validation-failure"
+	CodeDeadlineExceeded = "deadline-exceeded"
+)
+
+// RPCError is an error with a code callers can switch on. Methods declare the
+// codes they return under errors in the schema, and rpcgen generates an
+// RPCError for each that the server returns, with WithMessage, and that the
+// method's client returns in place of the error it received.
+type RPCError struct {
+	Code     string
+	Category string
+	Message  string
+
+	// err is the error the client received, kept so that matching it
+	// against cond errors still works.
+	err error
+}
+
+// NewRPCError returns an RPCError with code.
+func NewRPCError(code string) *RPCError {
+	return &RPCError{Code: code}
+}
+
+func (e *RPCError) Error() string {
+	if e.Message == "" {
+		return e.Code
+	}
+
+	return e.Code + ": " + e.Message
+}
+
+func (e *RPCError) ErrorCode() string {
+	return e.Code
+}
+
+func (e *RPCError) ErrorCategory() string {
+	return e.Category
+}
+
+func (e *RPCError) ErrorMessage() string {
+	return e.Message
+}
+
+func (e *RPCError) Unwrap() error {
+	return e.err
+}
+
+// Is matches any RPCError with the same code, so that errors.Is can check a
+// returned error against the ones generated for a method.
+func (e *RPCError) Is(target error) bool {
+	t, ok := target.(*RPCError)
+	return ok && t.Code == e.Code
+}
+
+// WithMessage returns a copy of e with a message, for a handler to return.
+func (e *RPCError) WithMessage(format string, args ...any) *RPCError {
+	c := *e
+	c.Message = fmt.Sprintf(format, args...)
+	return &c
+}
+
+// TypedError returns err as an *RPCError if it carries one of codes, and
+// unchanged otherwise. Generated clients call it with the codes declared
+// for the method.
+func TypedError(err error, codes ...string) error {
+	var ec ErrorCode
+	if err == nil || !errors.As(err, &ec) {
+		return err
+	}
+
+	if !slices.Contains(codes, ec.ErrorCode()) {
+		return err
+	}
+
+	re := &RPCError{
+		Code:    ec.ErrorCode(),
+		Message: errorMessage(err),
+		err:     err,
+	}
+
+	var cat ErrorCategory
+	if errors.As(err, &cat) {
+		re.Category = cat.ErrorCategory()
+	}
+
+	return re
+}
+
+// errorMessage returns the message of err without the prefix describing its
+// code, which the client rebuilds as a cond error.
+func errorMessage(err error) string {
+	var em ErrorMessage
+	if errors.As(err, &em) {
+		return em.ErrorMessage()
+	}
+
+	switch e := err.(type) {
+	case cond.ErrNotFound:
+		return e.Element
+	case cond.ErrConflict:
+		return e.Element
+	case cond.ErrPermissionDenied:
+		return e.Element
+	case cond.ErrValidationFailure:
+		return e.Message
+	case cond.ErrDeadlineExceeded:
+		return e.Message
+	case cond.ErrRemote:
+		return e.Message
+	}
+
+	return err.Error()
+}
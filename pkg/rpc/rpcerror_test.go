@@ -0,0 +1,104 @@
+package rpc_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"miren.dev/runtime/pkg/cond"
+	"miren.dev/runtime/pkg/rpc"
+	"miren.dev/runtime/pkg/rpc/example"
+)
+
+type strictMeter struct {
+	exampleMeter
+}
+
+func (m *strictMeter) ReadTemperature(ctx context.Context, call *example.MeterReadTemperature) error {
+	switch name := call.Args().Name(); name {
+	case "missing":
+		return example.ErrMeterReadTemperatureUnknownMeter.WithMessage("no meter named %s", name)
+	case "gone":
+		return cond.NotFound("meter", name)
+	case "broken":
+		return errors.New("meter is broken")
+	}
+
+	return m.exampleMeter.ReadTemperature(ctx, call)
+}
+
+func TestTypedErrors(t *testing.T) {
+	r := require.New(t)
+	ctx := t.Context()
+
+	ss, err := rpc.NewState(ctx, rpc.WithSkipVerify)
+	r.NoError(err)
+	defer ss.Close()
+
+	ss.Server().ExposeValue("meter", example.AdaptMeter(&strictMeter{}))
+
+	cs, err := rpc.NewState(ctx, rpc.WithSkipVerify)
+	r.NoError(err)
+	defer cs.Close()
+
+	c, err := cs.Connect(ss.ListenAddr(), "meter")
+	r.NoError(err)
+
+	mc := &example.MeterClient{Client: c}
+
+	t.Run("a declared error is returned typed", func(t *testing.T) {
+		r := require.New(t)
+
+		_, err := mc.ReadTemperature(ctx, "missing")
+		r.Error(err)
+
+		var re *rpc.RPCError
+		r.True(errors.As(err, &re), "unexpected error: %v", err)
+		r.Equal(rpc.CodeNotFound, re.Code)
+		r.Equal("no meter named missing", re.Message)
+
+		r.ErrorIs(err, example.ErrMeterReadTemperatureUnknownMeter)
+		r.ErrorIs(err, cond.ErrNotFound{})
+	})
+
+	t.Run("cond errors with a declared code match too", func(t *testing.T) {
+		r := require.New(t)
+
+		_, err := mc.ReadTemperature(ctx, "gone")
+		r.Error(err)
+
+		r.ErrorIs(err, example.ErrMeterReadTemperatureUnknownMeter)
+	})
+
+	t.Run("undeclared errors are returned as before", func(t *testing.T) {
+		r := require.New(t)
+
+		_, err := mc.ReadTemperature(ctx, "broken")
+		r.Error(err)
+
+		var re *rpc.RPCError
+		r.False(errors.As(err, &re))
+		r.Contains(err.Error(), "meter is broken")
+	})
+
+	t.Run("calls that succeed are unaffected", func(t *testing.T) {
+		r := require.New(t)
+
+		res, err := mc.ReadTemperature(ctx, "kitchen")
+		r.NoError(err)
+		r.Equal("kitchen", res.Reading().Meter())
+	})
+}
+
+func TestTypedErrorCodes(t *testing.T) {
+	r := require.New(t)
+
+	err := rpc.TypedError(cond.Conflict("meter", "kitchen"), rpc.CodeNotFound)
+	r.NotErrorIs(err, rpc.NewRPCError(rpc.CodeNotFound))
+
+	err = rpc.TypedError(cond.Conflict("meter", "kitchen"), rpc.CodeNotFound, rpc.CodeConflict)
+	r.ErrorIs(err, rpc.NewRPCError(rpc.CodeConflict))
+
+	r.NoError(rpc.TypedError(nil, rpc.CodeNotFound))
+}
@@ -54,6 +54,13 @@ type Server struct {
 
 	resolvers map[string]HasReconstructFromState
 
+	// compression holds the compressionTracker of each connection
+	compression *sync.Map
+
+	// sessions holds the calls of clients that resume after losing their
+	// connection.
+	sessions *sessionTable
+
 	mux *http.ServeMux
 	ws  *webtransport.Server
 }
@@ -93,6 +100,8 @@ func newServer() *Server {
 		persistent:     make(map[string]*Interface),
 		knownAddresses: make(map[string]string),
 		resolvers:      make(map[string]HasReconstructFromState),
+		compression:    new(sync.Map),
+		sessions:       new(sessionTable),
 	}
 
 	s.setupMux()
@@ -112,6 +121,17 @@ type Method struct {
 	InterfaceName string
 	Index         int
 	Handler       func(ctx context.Context, call Call) error
+
+	// Timeout, when set, is how long the server lets Handler run before
+	// canceling it, whatever the caller's own deadline.
+	Timeout time.Duration
+
+	// MessageTimeout and ReadIdleTimeout, when set, bound how long the
+	// server waits for a call's arguments to arrive, in total and between
+	// reads. A call that stalls past them fails on its own, leaving the
+	// caller's other calls on the connection alone.
+	MessageTimeout  time.Duration
+	ReadIdleTimeout time.Duration
 }
 
 type HasRestoreState interface {
@@ -126,6 +146,10 @@ type Interface struct {
 	value         any
 	aroundContext func(ctx context.Context, call Call) (context.Context, func())
 
+	// allowed is the set of callable methods when the interface has been
+	// attenuated, nil means every method is callable.
+	allowed map[string]struct{}
+
 	forbidRestore bool
 	restoreState  HasRestoreState
 	constructor   HasReconstructFromState
@@ -181,6 +205,12 @@ func (s *Server) ExposeValue(name string, iface *Interface) {
 	}
 }
 
+// Handle serves h for pattern alongside the RPC endpoints, behind the same
+// authentication.
+func (s *Server) Handle(pattern string, h http.Handler) {
+	s.mux.Handle(pattern, h)
+}
+
 const BootstrapOID = "!bootstrap"
 
 func (s *Server) assignCapability(i *Interface, pub ed25519.PublicKey, contactAddr string, category string, inline bool) *Capability {
@@ -289,6 +319,7 @@ func (s *Server) setupMux() {
 	mux.HandleFunc("GET /_rpc/methods/{oid}", s.listMethods)
 	mux.HandleFunc("POST /_rpc/reresolve", s.reresolve)
 	mux.HandleFunc("POST /_rpc/reexport/{oid}", s.reexport)
+	mux.HandleFunc("POST /_rpc/attenuate/{oid}", s.attenuate)
 	mux.HandleFunc("POST /_rpc/ref/{oid}", s.refCapa)
 	mux.HandleFunc("POST /_rpc/deref/{oid}", s.derefCapa)
 	mux.HandleFunc("POST /_rpc/identify", s.clientIdentify)
@@ -584,7 +615,9 @@ func (s *Server) listMethods(w http.ResponseWriter, r *http.Request) {
 
 	methods := make([]string, 0, len(hc.methods))
 	for name := range hc.methods {
-		methods = append(methods, name)
+		if hc.permits(name) {
+			methods = append(methods, name)
+		}
 	}
 	sort.Strings(methods)
 
@@ -894,19 +927,27 @@ func (s *Server) startCallStream(w http.ResponseWriter, r *http.Request) {
 
 	method := r.PathValue("method")
 
+	access := s.startAccess(w, r, oid, method)
+	defer access.log()
+
 	w.Header().Set("Trailer", "rpc-status, rpc-error, rpc-error-category, rpc-error-code")
 
 	user, ok := s.authRequest(r, w, oid)
 	if !ok {
+		access.status = "unauthorized"
 		return
 	}
 
-	ctx := r.Context()
+	ctx := WithRequestID(r.Context(), access.id)
+
+	ctx, cancelDeadline := withCallDeadline(ctx, r.Header)
+	defer cancelDeadline()
 
 	s.mu.Lock()
 	iface, ok := s.objects[oid]
 	s.mu.Unlock()
 	if !ok {
+		access.status = "unknown-capability"
 		w.WriteHeader(http.StatusNotFound)
 		w.Header().Add("rpc-status", "unknown-capability")
 		w.Header().Add("rpc-error", "unknown object: "+string(oid))
@@ -917,12 +958,15 @@ func (s *Server) startCallStream(w http.ResponseWriter, r *http.Request) {
 
 	mm := iface.methods[method]
 	if mm.Handler == nil {
+		access.status = "unknown"
 		w.WriteHeader(http.StatusNotFound)
 		w.Header().Add("rpc-status", "unknown")
 		w.Header().Add("rpc-error", "unknown method: "+method)
 		return
 	}
 
+	access.iface = mm.InterfaceName
+
 	w.WriteHeader(http.StatusOK)
 
 	ctx = Propagator().Extract(ctx, propagation.HeaderCarrier(r.Header))
@@ -933,18 +977,25 @@ func (s *Server) startCallStream(w http.ResponseWriter, r *http.Request) {
 
 	defer span.End()
 
-	span.SetAttributes(attribute.String("oid", string(oid)))
+	span.SetAttributes(
+		attribute.String("oid", string(oid)),
+		attribute.String("rpc.request_id", access.id),
+	)
 
 	sess, err := s.ws.Upgrade(w, r)
 	if err != nil {
-		s.state.log.Error("failed to upgrade connection", "error", err)
+		access.status = "error"
+		access.err = err
+		s.state.log.Error("failed to upgrade connection", "error", err, "request-id", access.id)
 		http.Error(w, "failed to upgrade connection", http.StatusInternalServerError)
 		return
 	}
 
 	ctrlstream, err := sess.AcceptStream(ctx)
 	if err != nil {
-		s.state.log.Error("failed to accept arg stream", "error", err)
+		access.status = "error"
+		access.err = err
+		s.state.log.Error("failed to accept arg stream", "error", err, "request-id", access.id)
 		return
 	}
 
@@ -980,6 +1031,8 @@ func (s *Server) startCallStream(w http.ResponseWriter, r *http.Request) {
 
 	defer func() {
 		if r := recover(); r != nil {
+			access.status = "panic"
+
 			var sr streamRequest
 			sr.Kind = "panic"
 			sr.Error = fmt.Sprint(r)
@@ -988,9 +1041,12 @@ func (s *Server) startCallStream(w http.ResponseWriter, r *http.Request) {
 		}
 	}()
 
-	err = cond.Wrap(mm.Handler(ctx, call))
+	err = cond.Wrap(mm.invoke(ctx, call))
 
 	if err != nil {
+		access.status = "error"
+		access.err = err
+
 		var sr streamRequest
 		sr.Kind = "error"
 
@@ -1022,19 +1078,56 @@ func (s *Server) startCallStream(w http.ResponseWriter, r *http.Request) {
 
 func (s *Server) handleCalls(w http.ResponseWriter, r *http.Request) {
 	oid := OID(r.PathValue("oid"))
+	method := r.PathValue("method")
+
+	access := s.startAccess(w, r, oid, method)
 
 	w.Header().Set("Trailer", "rpc-status, rpc-error, rpc-error-category, rpc-error-code")
 
 	user, ok := s.authRequest(r, w, oid)
 	if !ok {
+		access.status = "unauthorized"
+		access.log()
 		return
 	}
 
-	method := r.PathValue("method")
+	defer r.Body.Close()
 
-	ctx := r.Context()
+	if token, seq, ack, ok := sessionCallID(r.Header); ok && s.sessionGrace() > 0 {
+		// A resumed call reports under the request that started it
+		resumed := s.serveSession(w, r, string(user)+token, seq, ack,
+			func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+				defer access.log()
+				s.invokeCall(ctx, w, r, access, user, oid, method)
+			})
 
-	defer r.Body.Close()
+		if resumed {
+			access.status = "resumed"
+			access.log()
+		}
+
+		return
+	}
+
+	defer access.log()
+
+	s.invokeCall(r.Context(), w, r, access, user, oid, method)
+}
+
+// invokeCall runs the method a call is for and writes its result.
+func (s *Server) invokeCall(
+	ctx context.Context,
+	w http.ResponseWriter,
+	r *http.Request,
+	access *callAccess,
+	user ed25519.PublicKey,
+	oid OID,
+	method string,
+) {
+	ctx = WithRequestID(ctx, access.id)
+
+	ctx, cancel := withCallDeadline(ctx, r.Header)
+	defer cancel()
 
 	s.mu.Lock()
 	iface, ok := s.objects[oid]
@@ -1045,16 +1138,41 @@ func (s *Server) handleCalls(w http.ResponseWriter, r *http.Request) {
 
 		mm := iface.methods[method]
 		if mm.Handler == nil {
+			access.status = "unknown"
 			w.WriteHeader(http.StatusNotFound)
 			w.Header().Add("rpc-status", "unknown")
 			w.Header().Add("rpc-error", "unknown method: "+method)
 			return
 		}
 
+		access.iface = mm.InterfaceName
+
+		s.guardBody(r, mm)
+
+		if err := decompressRequest(r); err != nil {
+			access.status = "error"
+			access.err = err
+			w.WriteHeader(http.StatusBadRequest)
+			w.Header().Add("rpc-status", "error")
+			w.Header().Add("rpc-error", err.Error())
+			return
+		}
+
+		// Compression is offered to clients that can read it, even if
+		// this response isn't compressed, so they start compressing
+		// their requests.
+		compress := s.state.compressionEnabled() &&
+			r.Header.Get(acceptCompressionHeader) == compressionGzip
+
+		if compress {
+			w.Header().Set(compressionHeader, compressionGzip)
+		}
+
 		w.WriteHeader(http.StatusOK)
 
 		defer func() {
 			if r := recover(); r != nil {
+				access.status = "panic"
 				w.Header().Add("rpc-status", "panic")
 				w.Header().Add("rpc-error", fmt.Sprint(r))
 				panic(r)
@@ -1069,7 +1187,10 @@ func (s *Server) handleCalls(w http.ResponseWriter, r *http.Request) {
 
 		defer span.End()
 
-		span.SetAttributes(attribute.String("oid", string(oid)))
+		span.SetAttributes(
+			attribute.String("oid", string(oid)),
+			attribute.String("rpc.request_id", access.id),
+		)
 
 		call := &NetworkCall{
 			s:        s,
@@ -1093,8 +1214,24 @@ func (s *Server) handleCalls(w http.ResponseWriter, r *http.Request) {
 			defer cancel()
 		}
 
-		err := mm.Handler(ctx, call)
+		var err error
+
+		if rec := s.state.recorder(); rec != nil {
+			// Read the args up front so they can be recorded as sent
+			call.argData, err = io.ReadAll(r.Body)
+			if err == nil {
+				err = rec.record(oid, method, call.argData, func() (any, error) {
+					err := mm.invoke(ctx, call)
+					return call.results, err
+				})
+			}
+		} else {
+			err = mm.invoke(ctx, call)
+		}
+
 		if err != nil {
+			access.status = "error"
+			access.err = err
 			w.Header().Add("rpc-status", "error")
 
 			if emsg, ok := err.(ErrorMessage); ok {
@@ -1115,15 +1252,75 @@ func (s *Server) handleCalls(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		cbor.NewEncoder(w).Encode(call.results)
+		if compress {
+			data, err := cbor.Marshal(call.results)
+			if err == nil {
+				err = writePayload(w, s.responseTracker(r), data)
+			}
+
+			if err != nil {
+				s.state.log.Error("error writing call result", "error", err)
+			}
+		} else {
+			cbor.NewEncoder(w).Encode(call.results)
+		}
+
 		w.Header().Add("rpc-status", "ok")
 	} else {
+		access.status = "unknown-capability"
 		w.WriteHeader(http.StatusNotFound)
 		w.Header().Add("rpc-status", "unknown-capability")
 		w.Header().Add("rpc-error", "unknown object: "+string(oid))
 	}
 }
 
+// callAccess collects what the access log line for a call reports.
+type callAccess struct {
+	s      *Server
+	id     string
+	oid    OID
+	iface  string
+	method string
+	start  time.Time
+	status string
+	err    error
+}
+
+// startAccess assigns the call its request id, returning it to the client
+// before any response is written.
+func (s *Server) startAccess(w http.ResponseWriter, r *http.Request, oid OID, method string) *callAccess {
+	a := &callAccess{
+		s:      s,
+		id:     callRequestID(r.Header.Get(RequestIDHeader)),
+		oid:    oid,
+		method: method,
+		start:  time.Now(),
+		status: "ok",
+	}
+
+	w.Header().Set(RequestIDHeader, a.id)
+
+	return a
+}
+
+// log writes the access log line for the call.
+func (a *callAccess) log() {
+	attrs := []any{
+		"request-id", a.id,
+		"oid", a.oid,
+		"interface", a.iface,
+		"method", a.method,
+		"status", a.status,
+		"duration", time.Since(a.start),
+	}
+
+	if a.err != nil {
+		attrs = append(attrs, "error", a.err)
+	}
+
+	a.s.state.log.Debug("rpc call", attrs...)
+}
+
 func (s *Server) handleError(w http.ResponseWriter, _ *http.Request, err error) {
 	http.Error(w, err.Error(), http.StatusInternalServerError)
 }
@@ -0,0 +1,460 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mr-tron/base58"
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/http3"
+)
+
+// DefaultSessionGrace is how long a server holds a session's call results
+// for a client that lost its connection to come back for them.
+const DefaultSessionGrace = 30 * time.Second
+
+const (
+	sessionHeader     = "rpc-session"
+	sessionCallHeader = "rpc-session-call"
+	sessionAckHeader  = "rpc-session-ack"
+)
+
+// WithSessionResumption makes calls survive their connection dropping, such
+// as when a client's network changes. Each call is numbered within a
+// session, and one whose connection is lost is resent on a new connection
+// for up to grace. The server runs each numbered call only once, holding
+// its result for the client to pick up.
+//
+// Calls made with CallWithCaps aren't resumed. Servers honor sessions
+// whether or not they set this option, using grace (or DefaultSessionGrace)
+// as how long to hold results; negative turns that off.
+func WithSessionResumption(grace time.Duration) StateOption {
+	return func(o *stateOptions) {
+		o.sessionGrace = grace
+	}
+}
+
+// clientSession numbers a State's calls to one server.
+type clientSession struct {
+	token string
+
+	mu    sync.Mutex
+	next  uint64
+	acked uint64
+	done  map[uint64]struct{}
+}
+
+func newClientSession() *clientSession {
+	var buf [16]byte
+	rand.Read(buf[:])
+
+	return &clientSession{
+		token: base58.Encode(buf[:]),
+		done:  make(map[uint64]struct{}),
+	}
+}
+
+func (cs *clientSession) begin() uint64 {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	cs.next++
+	return cs.next
+}
+
+// finish records that seq is over, so the server can drop its result once
+// every earlier call is over too.
+func (cs *clientSession) finish(seq uint64) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	cs.done[seq] = struct{}{}
+
+	for {
+		if _, ok := cs.done[cs.acked+1]; !ok {
+			break
+		}
+
+		cs.acked++
+		delete(cs.done, cs.acked)
+	}
+}
+
+func (cs *clientSession) ack() uint64 {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	return cs.acked
+}
+
+// clientSession returns the session for calls to remote, or nil if calls
+// aren't resumed.
+func (s *State) clientSession(remote string) *clientSession {
+	if s == nil || s.opts == nil || s.opts.sessionGrace <= 0 {
+		return nil
+	}
+
+	s.sessionMu.Lock()
+	defer s.sessionMu.Unlock()
+
+	if s.sessions == nil {
+		s.sessions = make(map[string]*clientSession)
+	}
+
+	cs, ok := s.sessions[remote]
+	if !ok {
+		cs = newClientSession()
+		s.sessions[remote] = cs
+	}
+
+	return cs
+}
+
+// resumableCall tracks the attempts to deliver one call of a session.
+type resumableCall struct {
+	sess  *clientSession
+	seq   uint64
+	grace time.Duration
+	log   func(msg string, args ...any)
+
+	deadline time.Time
+	backoff  time.Duration
+}
+
+func (c *NetworkClient) resumableCall() *resumableCall {
+	sess := c.State.clientSession(c.remote)
+	if sess == nil {
+		return nil
+	}
+
+	return &resumableCall{
+		sess:  sess,
+		seq:   sess.begin(),
+		grace: c.State.opts.sessionGrace,
+		log:   c.State.log.Info,
+	}
+}
+
+func (rc *resumableCall) setHeaders(h http.Header) {
+	h.Set(sessionHeader, rc.sess.token)
+	h.Set(sessionCallHeader, strconv.FormatUint(rc.seq, 10))
+	h.Set(sessionAckHeader, strconv.FormatUint(rc.sess.ack(), 10))
+}
+
+func (rc *resumableCall) finish() {
+	rc.sess.finish(rc.seq)
+}
+
+// retry reports whether the call should be sent again after err, waiting a
+// little first so a client without a network doesn't spin.
+func (rc *resumableCall) retry(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || !connectionLost(err) {
+		return false
+	}
+
+	now := time.Now()
+
+	if rc.deadline.IsZero() {
+		rc.deadline = now.Add(rc.grace)
+		rc.backoff = 50 * time.Millisecond
+	} else if now.After(rc.deadline) {
+		return false
+	}
+
+	rc.log("rpc.call resuming", "seq", rc.seq, "error", err)
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(rc.backoff):
+	}
+
+	rc.backoff = min(rc.backoff*2, time.Second)
+
+	return true
+}
+
+// connectionLost reports whether err came from the connection going away
+// rather than from the call itself.
+func connectionLost(err error) bool {
+	var (
+		appErr   *quic.ApplicationError
+		idleErr  *quic.IdleTimeoutError
+		hsErr    *quic.HandshakeTimeoutError
+		resetErr *quic.StatelessResetError
+		h3Err    *http3.Error
+		netErr   net.Error
+	)
+
+	return errors.As(err, &appErr) ||
+		errors.As(err, &idleErr) ||
+		errors.As(err, &hsErr) ||
+		errors.As(err, &resetErr) ||
+		errors.As(err, &h3Err) ||
+		errors.As(err, &netErr) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// sessionCallID returns the session and call number a request was sent
+// with, if any.
+func sessionCallID(h http.Header) (token string, seq, ack uint64, ok bool) {
+	token = h.Get(sessionHeader)
+	if token == "" {
+		return "", 0, 0, false
+	}
+
+	seq, err := strconv.ParseUint(h.Get(sessionCallHeader), 10, 64)
+	if err != nil || seq == 0 {
+		return "", 0, 0, false
+	}
+
+	ack, _ = strconv.ParseUint(h.Get(sessionAckHeader), 10, 64)
+
+	return token, seq, ack, true
+}
+
+// sessionTable holds the calls of the server's sessions until their clients
+// have their results.
+type sessionTable struct {
+	once sync.Once
+
+	mu       sync.Mutex
+	sessions map[string]*serverSession
+}
+
+type serverSession struct {
+	calls    map[uint64]*sessionCall
+	acked    uint64
+	waiting  int
+	lastSeen time.Time
+}
+
+type sessionCall struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+	resp   *recordedResponse
+}
+
+func (s *Server) sessionGrace() time.Duration {
+	return timeoutOrDefault(s.state.opts.sessionGrace, DefaultSessionGrace)
+}
+
+func timeoutOrDefault(d, def time.Duration) time.Duration {
+	if d == 0 {
+		return def
+	}
+
+	return d
+}
+
+// serveSession runs a session's call once, no matter how many times it's
+// sent, writing its result to whichever request is waiting when it's done.
+// The call isn't tied to the request's connection, so it carries on if that
+// goes away. It returns true if the call had already been started by an
+// earlier request.
+func (s *Server) serveSession(
+	w http.ResponseWriter,
+	r *http.Request,
+	key string, seq, ack uint64,
+	invoke func(ctx context.Context, w http.ResponseWriter, r *http.Request),
+) bool {
+	t := s.sessions
+
+	t.once.Do(func() {
+		go t.expire(s.state.top, s.sessionGrace())
+	})
+
+	// Read all of the request up front, so the call doesn't depend on the
+	// connection it came in on.
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return false
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(data))
+
+	t.mu.Lock()
+
+	if t.sessions == nil {
+		t.sessions = make(map[string]*serverSession)
+	}
+
+	sess, ok := t.sessions[key]
+	if !ok {
+		sess = &serverSession{calls: make(map[uint64]*sessionCall)}
+		t.sessions[key] = sess
+	}
+
+	sess.ackCalls(ack)
+
+	call, resumed := sess.calls[seq]
+	if !resumed {
+		if seq <= sess.acked {
+			t.mu.Unlock()
+
+			w.WriteHeader(http.StatusConflict)
+			w.Header().Add("rpc-status", "error")
+			w.Header().Add("rpc-error", "session call already completed")
+			return false
+		}
+
+		ctx, cancel := context.WithCancel(context.WithoutCancel(r.Context()))
+
+		call = &sessionCall{
+			cancel: cancel,
+			done:   make(chan struct{}),
+			resp:   newRecordedResponse(),
+		}
+
+		sess.calls[seq] = call
+
+		go func() {
+			defer close(call.done)
+			defer cancel()
+
+			defer func() {
+				if v := recover(); v != nil {
+					s.state.log.Error("rpc.call panicked", "seq", seq, "panic", v)
+				}
+			}()
+
+			invoke(ctx, call.resp, r)
+		}()
+	}
+
+	sess.waiting++
+	t.mu.Unlock()
+
+	select {
+	case <-call.done:
+		call.resp.replay(w)
+	case <-r.Context().Done():
+	}
+
+	t.mu.Lock()
+	sess.waiting--
+	sess.lastSeen = time.Now()
+	t.mu.Unlock()
+
+	return resumed
+}
+
+// ackCalls drops the results of calls the client says it's received.
+func (ss *serverSession) ackCalls(ack uint64) {
+	if ack <= ss.acked {
+		return
+	}
+
+	ss.acked = ack
+
+	for seq, call := range ss.calls {
+		if seq > ack {
+			continue
+		}
+
+		select {
+		case <-call.done:
+			delete(ss.calls, seq)
+		default:
+		}
+	}
+}
+
+// expire drops sessions nobody has been waiting on for longer than grace,
+// canceling any of their calls still running.
+func (t *sessionTable) expire(ctx context.Context, grace time.Duration) {
+	if grace < 0 {
+		return
+	}
+
+	ticker := time.NewTicker(grace / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			t.sweep(now, grace)
+		}
+	}
+}
+
+func (t *sessionTable) sweep(now time.Time, grace time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for key, sess := range t.sessions {
+		if sess.waiting > 0 || now.Sub(sess.lastSeen) < grace {
+			continue
+		}
+
+		for _, call := range sess.calls {
+			call.cancel()
+		}
+
+		delete(t.sessions, key)
+	}
+}
+
+// recordedResponse holds what a call wrote so it can be written again to
+// a later request. Headers set after WriteHeader are trailers, as they are
+// for the http3 server.
+type recordedResponse struct {
+	h       http.Header
+	header  http.Header
+	status  int
+	body    bytes.Buffer
+	written bool
+}
+
+func newRecordedResponse() *recordedResponse {
+	return &recordedResponse{h: make(http.Header)}
+}
+
+func (rr *recordedResponse) Header() http.Header {
+	return rr.h
+}
+
+func (rr *recordedResponse) WriteHeader(status int) {
+	if rr.written {
+		return
+	}
+
+	rr.written = true
+	rr.status = status
+	rr.header = rr.h.Clone()
+}
+
+func (rr *recordedResponse) Write(b []byte) (int, error) {
+	rr.WriteHeader(http.StatusOK)
+	return rr.body.Write(b)
+}
+
+func (rr *recordedResponse) replay(w http.ResponseWriter) {
+	status := rr.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	for k, v := range rr.header {
+		w.Header()[k] = v
+	}
+
+	w.WriteHeader(status)
+	w.Write(rr.body.Bytes())
+
+	for k, v := range rr.h {
+		if _, ok := rr.header[k]; !ok {
+			w.Header()[k] = v
+		}
+	}
+}
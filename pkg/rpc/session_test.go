@@ -0,0 +1,158 @@
+package rpc_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"miren.dev/runtime/pkg/rpc"
+	"miren.dev/runtime/pkg/rpc/stream"
+)
+
+// chunkSource serves numbered chunks, holding the one at hold until release
+// is closed so the connection can be dropped while it's in flight.
+type chunkSource struct {
+	chunks []string
+	hold   int
+
+	held    chan struct{}
+	release chan struct{}
+
+	mu    sync.Mutex
+	next  int
+	calls int
+}
+
+func (c *chunkSource) Recv(ctx context.Context, state *stream.RecvStreamRecv[[]byte]) error {
+	c.mu.Lock()
+	i := c.next
+	c.next++
+	c.calls++
+	c.mu.Unlock()
+
+	if i == c.hold {
+		close(c.held)
+		<-c.release
+	}
+
+	if i >= len(c.chunks) {
+		state.Results().SetValue(nil)
+		return nil
+	}
+
+	state.Results().SetValue([]byte(c.chunks[i]))
+
+	return nil
+}
+
+func TestSessionResumption(t *testing.T) {
+	newSource := func() *chunkSource {
+		src := &chunkSource{
+			hold:    3,
+			held:    make(chan struct{}),
+			release: make(chan struct{}),
+		}
+
+		for i := range 8 {
+			src.chunks = append(src.chunks, fmt.Sprintf("chunk-%d;", i))
+		}
+
+		return src
+	}
+
+	t.Run("resumes a stream whose connection drops mid-call", func(t *testing.T) {
+		r := require.New(t)
+		ctx := t.Context()
+
+		src := newSource()
+
+		ss, err := rpc.NewState(ctx, rpc.WithSkipVerify)
+		r.NoError(err)
+		defer ss.Close()
+
+		ss.Server().ExposeValue("chunks", stream.AdaptRecvStream[[]byte](src))
+
+		cs, err := rpc.NewState(ctx, rpc.WithSkipVerify, rpc.WithSessionResumption(5*time.Second))
+		r.NoError(err)
+		defer cs.Close()
+
+		c, err := cs.Connect(ss.ListenAddr(), "chunks")
+		r.NoError(err)
+
+		type result struct {
+			data []byte
+			err  error
+		}
+
+		done := make(chan result, 1)
+
+		go func() {
+			data, err := io.ReadAll(stream.ToReader(ctx, stream.NewRecvStreamClient[[]byte](c)))
+			done <- result{data, err}
+		}()
+
+		select {
+		case <-src.held:
+		case <-time.After(5 * time.Second):
+			r.FailNow("stream never reached the held chunk")
+		}
+
+		r.NoError(c.Reconnect())
+		close(src.release)
+
+		var res result
+
+		select {
+		case res = <-done:
+		case <-time.After(5 * time.Second):
+			r.FailNow("stream didn't resume")
+		}
+
+		r.NoError(res.err)
+		r.Equal(strings.Join(src.chunks, ""), string(res.data))
+
+		// Every chunk was read from the source once, plus the read that found
+		// the end.
+		src.mu.Lock()
+		defer src.mu.Unlock()
+
+		r.Equal(len(src.chunks)+1, src.calls)
+	})
+
+	t.Run("gives up once the grace period has passed", func(t *testing.T) {
+		r := require.New(t)
+		ctx := t.Context()
+
+		ss, err := rpc.NewState(ctx, rpc.WithSkipVerify)
+		r.NoError(err)
+
+		ss.Server().ExposeValue("chunks", stream.AdaptRecvStream[[]byte](newSource()))
+
+		cs, err := rpc.NewState(ctx, rpc.WithSkipVerify, rpc.WithSessionResumption(500*time.Millisecond))
+		r.NoError(err)
+		defer cs.Close()
+
+		c, err := cs.Connect(ss.ListenAddr(), "chunks")
+		r.NoError(err)
+
+		rsc := stream.NewRecvStreamClient[[]byte](c)
+
+		_, err = rsc.Recv(ctx, 10)
+		r.NoError(err)
+
+		// The server goes away for good
+		ss.Close()
+
+		start := time.Now()
+
+		// The last attempt can still wait out the QUIC handshake timeout
+		_, err = rsc.Recv(ctx, 10)
+		r.Error(err)
+		r.Less(time.Since(start), 10*time.Second)
+	})
+}
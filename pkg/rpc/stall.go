@@ -0,0 +1,168 @@
+package rpc
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var stalledMessages = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "rpc_server_stalled_messages_total",
+	Help: "Calls failed because their arguments were not received in time",
+}, []string{"reason"})
+
+// Limits set by WithDefaultStallTimeouts. Arguments are small, and large
+// payloads are sent in chunks, so these leave room for a slow link while
+// keeping a client that stops sending from holding a handler open.
+const (
+	DefaultMessageTimeout  = 30 * time.Second
+	DefaultReadIdleTimeout = 10 * time.Second
+)
+
+// WithDefaultStallTimeouts limits how long the server waits for the
+// arguments of calls whose methods don't declare their own limits, using
+// DefaultMessageTimeout and DefaultReadIdleTimeout. Servers that take calls
+// from clients they don't control should set it.
+func WithDefaultStallTimeouts() StateOption {
+	return func(o *stateOptions) {
+		o.messageTimeout = DefaultMessageTimeout
+		o.readIdleTimeout = DefaultReadIdleTimeout
+	}
+}
+
+// WithMessageTimeout sets the maximum time the server waits to receive the
+// complete arguments of a call whose method doesn't declare its own limit.
+// Zero, the default, means no limit.
+func WithMessageTimeout(d time.Duration) StateOption {
+	return func(o *stateOptions) {
+		o.messageTimeout = d
+	}
+}
+
+// WithReadIdleTimeout sets the maximum time the server waits between reads
+// of a call's arguments when its method doesn't declare its own limit. Zero,
+// the default, means no limit.
+func WithReadIdleTimeout(d time.Duration) StateOption {
+	return func(o *stateOptions) {
+		o.readIdleTimeout = d
+	}
+}
+
+var errMessageStalled = errors.New("request body not received in time")
+
+// stallGuard wraps a call's request body and cancels it when a read blocks
+// past the idle timeout or past the message deadline. Only the call's own
+// stream is canceled, so other calls on the connection carry on. Time the
+// handler spends not reading the body isn't counted against the idle timeout.
+type stallGuard struct {
+	io.ReadCloser
+
+	log func(msg string, args ...any)
+
+	deadline time.Time
+	idle     time.Duration
+
+	once     sync.Once
+	expired  atomic.Bool
+	finished atomic.Bool
+}
+
+// guardBody limits how long the arguments of a call to mm may take to
+// arrive, using the limits mm declares or else those of the server. Streams
+// aren't guarded, since their bodies stay open for the life of the call.
+func (s *Server) guardBody(r *http.Request, mm Method) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return
+	}
+
+	msgTimeout := mm.MessageTimeout
+	if msgTimeout == 0 {
+		msgTimeout = s.state.opts.messageTimeout
+	}
+
+	idleTimeout := mm.ReadIdleTimeout
+	if idleTimeout == 0 {
+		idleTimeout = s.state.opts.readIdleTimeout
+	}
+
+	if msgTimeout <= 0 && idleTimeout <= 0 {
+		return
+	}
+
+	g := &stallGuard{
+		ReadCloser: r.Body,
+		log:        s.state.log.Warn,
+		idle:       idleTimeout,
+	}
+
+	if msgTimeout > 0 {
+		g.deadline = time.Now().Add(msgTimeout)
+	}
+
+	r.Body = g
+}
+
+func (g *stallGuard) Read(b []byte) (int, error) {
+	if g.finished.Load() {
+		return g.ReadCloser.Read(b)
+	}
+
+	if g.expired.Load() {
+		return 0, errMessageStalled
+	}
+
+	wait, reason := g.idle, "idle-timeout"
+
+	if !g.deadline.IsZero() {
+		remaining := time.Until(g.deadline)
+		if remaining <= 0 {
+			g.expire("message-timeout")
+			return 0, errMessageStalled
+		}
+
+		if wait <= 0 || remaining < wait {
+			wait, reason = remaining, "message-timeout"
+		}
+	}
+
+	var timer *time.Timer
+	if wait > 0 {
+		timer = time.AfterFunc(wait, func() { g.expire(reason) })
+	}
+
+	n, err := g.ReadCloser.Read(b)
+
+	if timer != nil {
+		timer.Stop()
+	}
+
+	if g.expired.Load() {
+		return n, errMessageStalled
+	}
+
+	if err == io.EOF {
+		g.finished.Store(true)
+	}
+
+	return n, err
+}
+
+// expire cancels the request stream, which unblocks the handler's read with
+// errMessageStalled and tells the client to stop sending.
+func (g *stallGuard) expire(reason string) {
+	g.once.Do(func() {
+		g.expired.Store(true)
+
+		stalledMessages.WithLabelValues(reason).Inc()
+
+		g.log("canceling stalled request", "reason", reason)
+
+		g.ReadCloser.Close()
+	})
+}
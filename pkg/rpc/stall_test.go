@@ -0,0 +1,146 @@
+package rpc
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// trickleBody delivers one byte per interval, as a client that stalls
+// mid-message would, until it's closed.
+type trickleBody struct {
+	interval time.Duration
+	closed   chan struct{}
+	isClosed atomic.Bool
+}
+
+func newTrickleBody(interval time.Duration) *trickleBody {
+	return &trickleBody{interval: interval, closed: make(chan struct{})}
+}
+
+func (b *trickleBody) Read(p []byte) (int, error) {
+	select {
+	case <-b.closed:
+		return 0, io.ErrClosedPipe
+	case <-time.After(b.interval):
+	}
+
+	p[0] = 'a'
+	return 1, nil
+}
+
+func (b *trickleBody) Close() error {
+	if b.isClosed.CompareAndSwap(false, true) {
+		close(b.closed)
+	}
+
+	return nil
+}
+
+func TestStalledMessage(t *testing.T) {
+	newServer := func(opts ...StateOption) *Server {
+		so := &stateOptions{}
+		for _, o := range opts {
+			o(so)
+		}
+
+		return &Server{state: &State{StateCommon: &StateCommon{log: slog.Default(), opts: so}}}
+	}
+
+	newRequest := func(body io.ReadCloser) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/_rpc/call/oid/method", body)
+		req.Body = body
+		return req
+	}
+
+	t.Run("cancels a call whose arguments trickle in past the method's timeout", func(t *testing.T) {
+		r := require.New(t)
+
+		s := newServer()
+
+		body := newTrickleBody(50 * time.Millisecond)
+		req := newRequest(body)
+
+		before := testutil.ToFloat64(stalledMessages.WithLabelValues("message-timeout"))
+
+		s.guardBody(req, Method{MessageTimeout: 300 * time.Millisecond})
+
+		start := time.Now()
+
+		_, err := io.ReadAll(req.Body)
+		r.ErrorIs(err, errMessageStalled)
+
+		r.GreaterOrEqual(time.Since(start), 300*time.Millisecond)
+		r.Less(time.Since(start), 3*time.Second)
+
+		// Only the call's own stream is canceled
+		r.True(body.isClosed.Load())
+
+		r.Equal(before+1, testutil.ToFloat64(stalledMessages.WithLabelValues("message-timeout")))
+	})
+
+	t.Run("cancels a call whose arguments stop arriving", func(t *testing.T) {
+		r := require.New(t)
+
+		s := newServer(WithReadIdleTimeout(100 * time.Millisecond))
+
+		body := newTrickleBody(time.Hour)
+		req := newRequest(body)
+
+		s.guardBody(req, Method{})
+
+		_, err := req.Body.Read(make([]byte, 1))
+		r.ErrorIs(err, errMessageStalled)
+		r.True(body.isClosed.Load())
+	})
+
+	t.Run("leaves calls alone without a limit", func(t *testing.T) {
+		r := require.New(t)
+
+		s := newServer()
+
+		body := newTrickleBody(time.Millisecond)
+		req := newRequest(body)
+
+		s.guardBody(req, Method{})
+
+		r.Same(body, req.Body)
+	})
+
+	t.Run("a method's limit overrides the server's", func(t *testing.T) {
+		r := require.New(t)
+
+		s := newServer(WithMessageTimeout(100 * time.Millisecond))
+
+		body := newTrickleBody(time.Millisecond)
+		req := newRequest(body)
+
+		s.guardBody(req, Method{MessageTimeout: time.Hour})
+
+		g, ok := req.Body.(*stallGuard)
+		r.True(ok)
+		r.WithinDuration(time.Now().Add(time.Hour), g.deadline, time.Minute)
+	})
+	t.Run("default stall timeouts guard methods without their own", func(t *testing.T) {
+		r := require.New(t)
+
+		s := newServer(WithDefaultStallTimeouts())
+
+		body := newTrickleBody(time.Millisecond)
+		req := newRequest(body)
+
+		s.guardBody(req, Method{})
+
+		g, ok := req.Body.(*stallGuard)
+		r.True(ok)
+		r.Equal(DefaultReadIdleTimeout, g.idle)
+		r.WithinDuration(time.Now().Add(DefaultMessageTimeout), g.deadline, time.Second)
+	})
+}
@@ -30,6 +30,10 @@ func (v *Timestamp) SetSeconds(seconds int64) {
 	v.data.Seconds = &seconds
 }
 
+func (v *Timestamp) ClearSeconds() {
+	v.data.Seconds = nil
+}
+
 func (v *Timestamp) HasNanoseconds() bool {
 	return v.data.Nanoseconds != nil
 }
@@ -45,6 +49,10 @@ func (v *Timestamp) SetNanoseconds(nanoseconds int32) {
 	v.data.Nanoseconds = &nanoseconds
 }
 
+func (v *Timestamp) ClearNanoseconds() {
+	v.data.Nanoseconds = nil
+}
+
 func (v *Timestamp) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -62,7 +70,7 @@ func (v *Timestamp) UnmarshalJSON(data []byte) error {
 }
 
 type durationData struct {
-	Nanoseconds *uint64 `cbor:"0,keyasint,omitempty" json:"nanoseconds,omitempty"`
+	Nanoseconds *int64 `cbor:"0,keyasint,omitempty" json:"nanoseconds,omitempty"`
 }
 
 type Duration struct {
@@ -73,17 +81,21 @@ func (v *Duration) HasNanoseconds() bool {
 	return v.data.Nanoseconds != nil
 }
 
-func (v *Duration) Nanoseconds() uint64 {
+func (v *Duration) Nanoseconds() int64 {
 	if v.data.Nanoseconds == nil {
 		return 0
 	}
 	return *v.data.Nanoseconds
 }
 
-func (v *Duration) SetNanoseconds(nanoseconds uint64) {
+func (v *Duration) SetNanoseconds(nanoseconds int64) {
 	v.data.Nanoseconds = &nanoseconds
 }
 
+func (v *Duration) ClearNanoseconds() {
+	v.data.Nanoseconds = nil
+}
+
 func (v *Duration) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
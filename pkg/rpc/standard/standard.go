@@ -24,7 +24,7 @@ func FromTimestamp(ts *Timestamp) time.Time {
 
 func ToDuration(d time.Duration) *Duration {
 	var dur Duration
-	dur.SetNanoseconds(uint64(d.Nanoseconds()))
+	dur.SetNanoseconds(d.Nanoseconds())
 	return &dur
 }
 
@@ -33,5 +33,5 @@ func FromDuration(dur *Duration) time.Duration {
 		return 0
 	}
 
-	return time.Duration(dur.Nanoseconds()) * time.Nanosecond
+	return time.Duration(dur.Nanoseconds())
 }
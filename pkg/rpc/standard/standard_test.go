@@ -0,0 +1,72 @@
+package standard
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDuration(t *testing.T) {
+	durations := []time.Duration{
+		0,
+		time.Nanosecond,
+		250 * time.Millisecond,
+		90 * time.Minute,
+		-time.Nanosecond,
+		-1500 * time.Millisecond,
+		-90 * time.Minute,
+	}
+
+	t.Run("converts to and from time.Duration", func(t *testing.T) {
+		for _, d := range durations {
+			require.Equal(t, d, FromDuration(ToDuration(d)))
+		}
+
+		require.Equal(t, time.Duration(0), FromDuration(nil))
+	})
+
+	t.Run("round trips through cbor", func(t *testing.T) {
+		r := require.New(t)
+
+		for _, d := range durations {
+			data, err := cbor.Marshal(ToDuration(d))
+			r.NoError(err)
+
+			var out Duration
+			r.NoError(cbor.Unmarshal(data, &out))
+
+			r.Equal(d, FromDuration(&out))
+		}
+	})
+
+	t.Run("round trips through json", func(t *testing.T) {
+		r := require.New(t)
+
+		for _, d := range durations {
+			data, err := json.Marshal(ToDuration(d))
+			r.NoError(err)
+
+			var out Duration
+			r.NoError(json.Unmarshal(data, &out))
+
+			r.Equal(d, FromDuration(&out))
+		}
+	})
+}
+
+func TestTimestamp(t *testing.T) {
+	r := require.New(t)
+
+	ts := time.Unix(1700000000, 123456789)
+
+	data, err := cbor.Marshal(ToTimestamp(ts))
+	r.NoError(err)
+
+	var out Timestamp
+	r.NoError(cbor.Unmarshal(data, &out))
+
+	r.True(ts.Equal(FromTimestamp(&out)))
+}
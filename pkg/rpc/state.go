@@ -15,6 +15,7 @@ import (
 	"os/exec"
 	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/quic-go/quic-go"
@@ -88,6 +89,9 @@ type State struct {
 	li     *quic.EarlyListener
 
 	localMP *packet.PacketConnMultiplex
+
+	sessionMu sync.Mutex
+	sessions  map[string]*clientSession
 }
 
 func (s *State) ListenAddr() string {
@@ -127,6 +131,15 @@ type stateOptions struct {
 
 	authenticator Authenticator
 	bearerToken   string // JWT or other bearer token for authentication
+
+	messageTimeout  time.Duration
+	readIdleTimeout time.Duration
+
+	disableCompression bool
+
+	recorder *Recorder
+
+	sessionGrace time.Duration
 }
 
 type StateOption func(*stateOptions)
@@ -429,7 +442,8 @@ func (s *State) startListener(ctx context.Context, so *stateOptions) error {
 
 	s.ws = &webtransport.Server{
 		H3: http3.Server{
-			Handler: s.server,
+			Handler:     s.server,
+			ConnContext: connContext,
 			// Use a logger with LevelWarn to suppress noisy debug messages from quic-go
 			// while preventing nil pointer panics
 			Logger: slog.New(slogfmt.NewTextHandler(os.Stderr, &slog.HandlerOptions{
@@ -146,8 +146,9 @@ func (s *State) startLocalListener(ctx context.Context, addr string) error {
 	subS.server = s.server.Clone(subS)
 
 	serv := &http3.Server{
-		Handler: subS.server,
-		Logger:  subS.log.With("module", "http3-local"),
+		Handler:     subS.server,
+		ConnContext: connContext,
+		Logger:      subS.log.With("module", "http3-local"),
 	}
 
 	subS.hs = serv
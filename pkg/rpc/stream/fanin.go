@@ -0,0 +1,232 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"sync"
+
+	rpc "miren.dev/runtime/pkg/rpc"
+)
+
+// DefaultFanInBuffer is the number of values buffered per producer when
+// NewFanIn is given a non-positive buffer size.
+const DefaultFanInBuffer = 16
+
+var (
+	// ErrFanInClosed is returned to producers sending into a closed FanIn.
+	ErrFanInClosed = errors.New("fan-in closed")
+
+	// ErrProducerClosed is returned when sending on a producer after Close.
+	ErrProducerClosed = errors.New("fan-in producer closed")
+)
+
+// FanIn merges the values sent by many producers into a single consumer.
+//
+// Each producer has its own bounded buffer. A producer whose buffer is full
+// blocks in Send until the consumer catches up, without holding up the other
+// producers. Recv takes values round-robin across the producers that have
+// something buffered, so a busy producer can't starve a quiet one. Values from
+// a single producer are delivered in the order they were sent.
+type FanIn[T any] struct {
+	buffer int
+
+	mu        sync.Mutex
+	producers []*FanInProducer[T]
+	next      int
+	closed    bool
+
+	ready chan struct{}
+	done  chan struct{}
+}
+
+// NewFanIn creates a FanIn that buffers up to buffer values per producer.
+func NewFanIn[T any](buffer int) *FanIn[T] {
+	if buffer <= 0 {
+		buffer = DefaultFanInBuffer
+	}
+
+	return &FanIn[T]{
+		buffer: buffer,
+		ready:  make(chan struct{}, 1),
+		done:   make(chan struct{}),
+	}
+}
+
+// FanInProducer is one input to a FanIn. It implements SendStream so it can be
+// handed to a client as a capability; releasing the capability closes it.
+type FanInProducer[T any] struct {
+	f *FanIn[T]
+
+	// queue and closed are guarded by f.mu
+	queue  []T
+	closed bool
+
+	space chan struct{}
+}
+
+var _ SendStream[int] = (*FanInProducer[int])(nil)
+
+// Producer adds a new producer to the fan-in.
+func (f *FanIn[T]) Producer() *FanInProducer[T] {
+	p := &FanInProducer[T]{
+		f:     f,
+		space: make(chan struct{}, 1),
+	}
+
+	f.mu.Lock()
+	f.producers = append(f.producers, p)
+	f.mu.Unlock()
+
+	return p
+}
+
+// ProducerInterface adds a new producer and returns it as a SendStream
+// capability, ready to be returned to a client.
+func (f *FanIn[T]) ProducerInterface() *rpc.Interface {
+	return AdaptSendStream[T](f.Producer())
+}
+
+func (f *FanIn[T]) signal() {
+	select {
+	case f.ready <- struct{}{}:
+	default:
+	}
+}
+
+// Recv returns the next value from any producer, blocking until one is
+// available. Once the FanIn is closed and everything buffered has been
+// delivered, Recv returns io.EOF.
+func (f *FanIn[T]) Recv(ctx context.Context) (T, error) {
+	f.mu.Lock()
+
+	for {
+		if v, ok := f.takeLocked(); ok {
+			f.mu.Unlock()
+			return v, nil
+		}
+
+		if f.closed {
+			f.mu.Unlock()
+			return rpc.Zero[T](), io.EOF
+		}
+
+		f.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return rpc.Zero[T](), ctx.Err()
+		case <-f.ready:
+		case <-f.done:
+		}
+
+		f.mu.Lock()
+	}
+}
+
+// takeLocked removes the next value in round-robin order, starting after the
+// producer that was last taken from.
+func (f *FanIn[T]) takeLocked() (T, bool) {
+	// Producers that are gone and have nothing left to deliver can be dropped.
+	f.producers = slices.DeleteFunc(f.producers, func(p *FanInProducer[T]) bool {
+		return p.closed && len(p.queue) == 0
+	})
+
+	n := len(f.producers)
+
+	for i := 0; i < n; i++ {
+		idx := (f.next + i) % n
+		p := f.producers[idx]
+
+		if len(p.queue) == 0 {
+			continue
+		}
+
+		v := p.queue[0]
+		p.queue[0] = rpc.Zero[T]()
+		p.queue = p.queue[1:]
+
+		f.next = idx + 1
+
+		select {
+		case p.space <- struct{}{}:
+		default:
+		}
+
+		return v, true
+	}
+
+	return rpc.Zero[T](), false
+}
+
+// Close stops the FanIn from accepting values. Producers blocked in Send are
+// released with ErrFanInClosed, and Recv continues to return values that were
+// already buffered before reporting io.EOF.
+func (f *FanIn[T]) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !f.closed {
+		f.closed = true
+		close(f.done)
+	}
+
+	return nil
+}
+
+// Push adds v to the producer's buffer, blocking while the buffer is full.
+func (p *FanInProducer[T]) Push(ctx context.Context, v T) error {
+	f := p.f
+
+	f.mu.Lock()
+
+	for {
+		switch {
+		case f.closed:
+			f.mu.Unlock()
+			return ErrFanInClosed
+		case p.closed:
+			f.mu.Unlock()
+			return ErrProducerClosed
+		case len(p.queue) < f.buffer:
+			p.queue = append(p.queue, v)
+			f.mu.Unlock()
+			f.signal()
+			return nil
+		}
+
+		f.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-p.space:
+		case <-f.done:
+		}
+
+		f.mu.Lock()
+	}
+}
+
+func (p *FanInProducer[T]) Send(ctx context.Context, state *SendStreamSend[T]) error {
+	if err := p.Push(ctx, state.Args().Value()); err != nil {
+		return err
+	}
+
+	state.Results().SetCount(1)
+
+	return nil
+}
+
+// Close detaches the producer from the FanIn. Values it already buffered are
+// still delivered.
+func (p *FanInProducer[T]) Close() error {
+	p.f.mu.Lock()
+	p.closed = true
+	p.f.mu.Unlock()
+
+	p.f.signal()
+
+	return nil
+}
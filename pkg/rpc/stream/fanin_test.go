@@ -0,0 +1,197 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	rpc "miren.dev/runtime/pkg/rpc"
+)
+
+func TestFanIn(t *testing.T) {
+	t.Run("interleaves producers fairly", func(t *testing.T) {
+		r := require.New(t)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		f := NewFanIn[string](4)
+
+		var producers []*FanInProducer[string]
+		for i := 0; i < 3; i++ {
+			producers = append(producers, f.Producer())
+		}
+
+		for i, p := range producers {
+			for j := 0; j < 4; j++ {
+				r.NoError(p.Push(ctx, fmt.Sprintf("p%d-%d", i, j)))
+			}
+		}
+
+		var got []string
+		for i := 0; i < 12; i++ {
+			v, err := f.Recv(ctx)
+			r.NoError(err)
+			got = append(got, v)
+		}
+
+		r.Equal([]string{
+			"p0-0", "p1-0", "p2-0",
+			"p0-1", "p1-1", "p2-1",
+			"p0-2", "p1-2", "p2-2",
+			"p0-3", "p1-3", "p2-3",
+		}, got)
+	})
+
+	t.Run("a full producer blocks without holding up others", func(t *testing.T) {
+		r := require.New(t)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		f := NewFanIn[int](1)
+
+		busy := f.Producer()
+		quiet := f.Producer()
+
+		r.NoError(busy.Push(ctx, 1))
+
+		blocked := make(chan error, 1)
+		go func() {
+			blocked <- busy.Push(ctx, 2)
+		}()
+
+		select {
+		case err := <-blocked:
+			r.FailNow("push into a full buffer should block", "err: %v", err)
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		r.NoError(quiet.Push(ctx, 10))
+
+		v, err := f.Recv(ctx)
+		r.NoError(err)
+		r.Equal(1, v)
+
+		select {
+		case err := <-blocked:
+			r.NoError(err)
+		case <-time.After(time.Second):
+			r.FailNow("push should proceed once there is space")
+		}
+
+		v, err = f.Recv(ctx)
+		r.NoError(err)
+		r.Equal(10, v)
+
+		v, err = f.Recv(ctx)
+		r.NoError(err)
+		r.Equal(2, v)
+	})
+
+	t.Run("close drains buffered values then reports EOF", func(t *testing.T) {
+		r := require.New(t)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		f := NewFanIn[int](2)
+
+		p := f.Producer()
+		r.NoError(p.Push(ctx, 1))
+		r.NoError(p.Close())
+
+		r.ErrorIs(p.Push(ctx, 2), ErrProducerClosed)
+
+		r.NoError(f.Close())
+		r.ErrorIs(f.Producer().Push(ctx, 3), ErrFanInClosed)
+
+		v, err := f.Recv(ctx)
+		r.NoError(err)
+		r.Equal(1, v)
+
+		_, err = f.Recv(ctx)
+		r.ErrorIs(err, io.EOF)
+	})
+
+	t.Run("aggregates concurrent clients without loss", func(t *testing.T) {
+		r := require.New(t)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		ss, err := rpc.NewState(ctx, rpc.WithSkipVerify)
+		r.NoError(err)
+
+		const (
+			clients   = 4
+			perClient = 50
+		)
+
+		f := NewFanIn[*Thing](2)
+
+		for i := 0; i < clients; i++ {
+			ss.Server().ExposeValue(fmt.Sprintf("logs-%d", i), f.ProducerInterface())
+		}
+
+		var wg sync.WaitGroup
+
+		for i := 0; i < clients; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+
+				cs, err := rpc.NewState(ctx, rpc.WithSkipVerify)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+
+				c, err := cs.Connect(ss.ListenAddr(), fmt.Sprintf("logs-%d", i))
+				if err != nil {
+					t.Error(err)
+					return
+				}
+
+				css, err := ClientSend[*Thing](c)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+
+				for j := 0; j < perClient; j++ {
+					if err := css.Send(ctx, &Thing{Name: fmt.Sprintf("%d:%d", i, j)}); err != nil {
+						t.Error(err)
+						return
+					}
+				}
+			}(i)
+		}
+
+		// Track the next value expected from each client, so that a
+		// dropped, duplicated or reordered value fails the test.
+		next := make([]int, clients)
+
+		for n := 0; n < clients*perClient; n++ {
+			v, err := f.Recv(ctx)
+			r.NoError(err)
+
+			var i, j int
+			_, err = fmt.Sscanf(v.Name, "%d:%d", &i, &j)
+			r.NoError(err)
+
+			r.Equal(next[i], j, "client %d delivered out of order", i)
+			next[i]++
+		}
+
+		wg.Wait()
+
+		for i := range next {
+			r.Equal(perClient, next[i], "client %d", i)
+		}
+	})
+}
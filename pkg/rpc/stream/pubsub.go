@@ -0,0 +1,275 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+
+	rpc "miren.dev/runtime/pkg/rpc"
+)
+
+// DefaultSubscriberBuffer is the number of values buffered per subscriber
+// when NewBroker is given a non-positive buffer size.
+const DefaultSubscriberBuffer = 64
+
+// SlowPolicy decides what a Broker does with a subscriber whose buffer is
+// full when a value is published.
+type SlowPolicy int
+
+const (
+	// DropOldest discards the subscriber's oldest buffered value to make room,
+	// so it keeps up with the latest values at the cost of missing some.
+	DropOldest SlowPolicy = iota
+
+	// Disconnect drops the subscriber, which then sees ErrSubscriberTooSlow
+	// once it has received what was buffered.
+	Disconnect
+)
+
+var (
+	// ErrBrokerClosed is returned when publishing to a closed Broker.
+	ErrBrokerClosed = errors.New("broker closed")
+
+	// ErrSubscriberTooSlow is returned to a subscriber that was disconnected
+	// for falling behind.
+	ErrSubscriberTooSlow = errors.New("subscriber too slow")
+)
+
+// Broker fans each published value out to every subscriber.
+//
+// Publishing never blocks: each subscriber has its own bounded buffer, and
+// one that can't keep up is handled by the broker's SlowPolicy rather than
+// holding up the publisher or the other subscribers. Values reach each
+// subscriber in the order they were published.
+//
+// Topic exposes the broker over RPC, so any schema that declares a
+// stream.Topic[T] can be served by a Broker[T].
+type Broker[T any] struct {
+	buffer int
+	policy SlowPolicy
+
+	mu     sync.Mutex
+	subs   map[*Subscriber[T]]struct{}
+	closed bool
+}
+
+// NewBroker creates a Broker that buffers up to buffer values per subscriber
+// and applies policy to those that fall behind.
+func NewBroker[T any](buffer int, policy SlowPolicy) *Broker[T] {
+	if buffer <= 0 {
+		buffer = DefaultSubscriberBuffer
+	}
+
+	return &Broker[T]{
+		buffer: buffer,
+		policy: policy,
+		subs:   make(map[*Subscriber[T]]struct{}),
+	}
+}
+
+// Subscriber receives the values published to a Broker after it subscribed.
+// It implements RecvStream so it can be handed to a client as a capability;
+// releasing the capability unsubscribes it.
+type Subscriber[T any] struct {
+	b *Broker[T]
+
+	// queue, dropped and err are guarded by b.mu
+	queue   []T
+	dropped int
+	err     error
+
+	ready chan struct{}
+}
+
+var _ RecvStream[int] = (*Subscriber[int])(nil)
+
+// Subscribe adds a new subscriber to the broker. If the broker is closed, the
+// subscriber reports io.EOF straight away.
+func (b *Broker[T]) Subscribe() *Subscriber[T] {
+	s := &Subscriber[T]{
+		b:     b,
+		ready: make(chan struct{}, 1),
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		s.err = io.EOF
+	} else {
+		b.subs[s] = struct{}{}
+	}
+
+	return s
+}
+
+// Publish sends v to every current subscriber.
+func (b *Broker[T]) Publish(v T) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return ErrBrokerClosed
+	}
+
+	for s := range b.subs {
+		if len(s.queue) >= b.buffer {
+			switch b.policy {
+			case Disconnect:
+				s.err = ErrSubscriberTooSlow
+				delete(b.subs, s)
+				s.signal()
+				continue
+			default:
+				s.queue[0] = rpc.Zero[T]()
+				s.queue = s.queue[1:]
+				s.dropped++
+			}
+		}
+
+		s.queue = append(s.queue, v)
+		s.signal()
+	}
+
+	return nil
+}
+
+// Subscribers returns the number of subscribers currently attached.
+func (b *Broker[T]) Subscribers() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return len(b.subs)
+}
+
+// Close stops the broker. Subscribers receive what they already have
+// buffered, then io.EOF.
+func (b *Broker[T]) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil
+	}
+
+	b.closed = true
+
+	for s := range b.subs {
+		s.err = io.EOF
+		s.signal()
+	}
+
+	clear(b.subs)
+
+	return nil
+}
+
+// Topic returns the broker as a Topic, for serving it over RPC.
+func (b *Broker[T]) Topic() Topic[T] {
+	return &brokerTopic[T]{b: b}
+}
+
+// TopicInterface returns the broker as a Topic capability, ready to be
+// exposed or returned to a client.
+func (b *Broker[T]) TopicInterface() *rpc.Interface {
+	return AdaptTopic(b.Topic())
+}
+
+type brokerTopic[T any] struct {
+	b *Broker[T]
+}
+
+func (t *brokerTopic[T]) Subscribe(ctx context.Context, state *TopicSubscribe[T]) error {
+	state.Results().SetStream(t.b.Subscribe())
+	return nil
+}
+
+func (t *brokerTopic[T]) Publish(ctx context.Context, state *TopicPublish[T]) error {
+	return t.b.Publish(state.Args().Value())
+}
+
+func (s *Subscriber[T]) signal() {
+	select {
+	case s.ready <- struct{}{}:
+	default:
+	}
+}
+
+// Next returns the next value published, blocking until there is one. Once
+// the subscriber is detached and everything buffered has been delivered, Next
+// returns io.EOF, or ErrSubscriberTooSlow if it was disconnected.
+func (s *Subscriber[T]) Next(ctx context.Context) (T, error) {
+	b := s.b
+
+	b.mu.Lock()
+
+	for {
+		if len(s.queue) > 0 {
+			v := s.queue[0]
+			s.queue[0] = rpc.Zero[T]()
+			s.queue = s.queue[1:]
+			b.mu.Unlock()
+			return v, nil
+		}
+
+		if s.err != nil {
+			err := s.err
+			b.mu.Unlock()
+			return rpc.Zero[T](), err
+		}
+
+		b.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return rpc.Zero[T](), ctx.Err()
+		case <-s.ready:
+		}
+
+		b.mu.Lock()
+	}
+}
+
+// Dropped returns how many values were discarded because the subscriber fell
+// behind under DropOldest.
+func (s *Subscriber[T]) Dropped() int {
+	s.b.mu.Lock()
+	defer s.b.mu.Unlock()
+
+	return s.dropped
+}
+
+func (s *Subscriber[T]) Recv(ctx context.Context, state *RecvStreamRecv[T]) error {
+	// Consume args from decoder to prevent leftover data from being
+	// interpreted as the next stream request
+	_ = state.Args()
+
+	v, err := s.Next(ctx)
+	if err != nil {
+		return err
+	}
+
+	state.Results().SetValue(v)
+
+	return nil
+}
+
+// Close unsubscribes from the broker, discarding anything still buffered.
+func (s *Subscriber[T]) Close() error {
+	b := s.b
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.subs, s)
+
+	s.queue = nil
+	if s.err == nil {
+		s.err = io.EOF
+	}
+
+	s.signal()
+
+	return nil
+}
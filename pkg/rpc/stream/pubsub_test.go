@@ -0,0 +1,221 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	rpc "miren.dev/runtime/pkg/rpc"
+)
+
+func TestBroker(t *testing.T) {
+	drain := func(ctx context.Context, s *Subscriber[int]) ([]int, error) {
+		var got []int
+
+		for {
+			v, err := s.Next(ctx)
+			if err != nil {
+				return got, err
+			}
+
+			got = append(got, v)
+		}
+	}
+
+	t.Run("fans values out to every subscriber", func(t *testing.T) {
+		r := require.New(t)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		b := NewBroker[int](8, DropOldest)
+
+		early := b.Subscribe()
+		r.NoError(b.Publish(1))
+
+		late := b.Subscribe()
+		r.NoError(b.Publish(2))
+		r.NoError(b.Publish(3))
+
+		r.Equal(2, b.Subscribers())
+		r.NoError(b.Close())
+
+		got, err := drain(ctx, early)
+		r.ErrorIs(err, io.EOF)
+		r.Equal([]int{1, 2, 3}, got)
+
+		got, err = drain(ctx, late)
+		r.ErrorIs(err, io.EOF)
+		r.Equal([]int{2, 3}, got)
+
+		r.ErrorIs(b.Publish(4), ErrBrokerClosed)
+
+		_, err = b.Subscribe().Next(ctx)
+		r.ErrorIs(err, io.EOF)
+	})
+
+	t.Run("drops the oldest values of a slow subscriber", func(t *testing.T) {
+		r := require.New(t)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		b := NewBroker[int](3, DropOldest)
+
+		slow := b.Subscribe()
+		fast := b.Subscribe()
+
+		var fastGot []int
+
+		for i := range 10 {
+			r.NoError(b.Publish(i))
+
+			v, err := fast.Next(ctx)
+			r.NoError(err)
+			fastGot = append(fastGot, v)
+		}
+
+		r.NoError(b.Close())
+
+		got, err := drain(ctx, slow)
+		r.ErrorIs(err, io.EOF)
+		r.Equal([]int{7, 8, 9}, got)
+		r.Equal(7, slow.Dropped())
+
+		r.Equal([]int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, fastGot)
+		r.Zero(fast.Dropped())
+	})
+
+	t.Run("disconnects a slow subscriber", func(t *testing.T) {
+		r := require.New(t)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		b := NewBroker[int](2, Disconnect)
+
+		slow := b.Subscribe()
+		fast := b.Subscribe()
+
+		for i := range 5 {
+			r.NoError(b.Publish(i))
+
+			v, err := fast.Next(ctx)
+			r.NoError(err)
+			r.Equal(i, v)
+		}
+
+		r.Equal(1, b.Subscribers())
+
+		// It still gets what it had buffered before it was cut off
+		got, err := drain(ctx, slow)
+		r.ErrorIs(err, ErrSubscriberTooSlow)
+		r.Equal([]int{0, 1}, got)
+	})
+
+	t.Run("unsubscribes on close", func(t *testing.T) {
+		r := require.New(t)
+
+		b := NewBroker[int](2, Disconnect)
+
+		s := b.Subscribe()
+		r.NoError(b.Publish(1))
+		r.NoError(s.Close())
+
+		r.Zero(b.Subscribers())
+
+		_, err := s.Next(context.Background())
+		r.ErrorIs(err, io.EOF)
+	})
+
+	t.Run("serves a topic to remote subscribers and publishers", func(t *testing.T) {
+		r := require.New(t)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		ss, err := rpc.NewState(ctx, rpc.WithSkipVerify)
+		r.NoError(err)
+
+		const (
+			subscribers = 3
+			values      = 20
+		)
+
+		b := NewBroker[*Thing](values, DropOldest)
+
+		ss.Server().ExposeValue("events", b.TopicInterface())
+
+		connect := func() *TopicClient[*Thing] {
+			cs, err := rpc.NewState(ctx, rpc.WithSkipVerify)
+			r.NoError(err)
+
+			c, err := cs.Connect(ss.ListenAddr(), "events")
+			r.NoError(err)
+
+			return NewTopicClient[*Thing](c)
+		}
+
+		var streams []*RecvStreamClient[*Thing]
+
+		for range subscribers {
+			res, err := connect().Subscribe(ctx)
+			r.NoError(err)
+
+			streams = append(streams, res.Stream())
+		}
+
+		r.Eventually(func() bool {
+			return b.Subscribers() == subscribers
+		}, 5*time.Second, 10*time.Millisecond)
+
+		pub := connect()
+		for i := range values {
+			_, err := pub.Publish(ctx, &Thing{Name: fmt.Sprint(i)})
+			r.NoError(err)
+		}
+
+		var wg sync.WaitGroup
+
+		got := make([][]string, subscribers)
+
+		for i, rs := range streams {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				for range values {
+					ret, err := rs.Recv(ctx, 1)
+					if err != nil {
+						t.Error(err)
+						return
+					}
+
+					got[i] = append(got[i], ret.Value().Name)
+				}
+			}()
+		}
+
+		wg.Wait()
+
+		var want []string
+		for i := range values {
+			want = append(want, fmt.Sprint(i))
+		}
+
+		for i := range got {
+			r.Equal(want, got[i], "subscriber %d", i)
+		}
+
+		// Releasing a subscription's capability unsubscribes it
+		r.NoError(streams[0].Close())
+
+		r.Eventually(func() bool {
+			return b.Subscribers() == subscribers-1
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+}
@@ -8,6 +8,7 @@ import (
 	rpc "miren.dev/runtime/pkg/rpc"
 )
 
+// Server structs for SendStream
 type sendStreamSendArgsData[T any] struct {
 	Value *T `cbor:"0,keyasint,omitempty" json:"value,omitempty"`
 }
@@ -57,6 +58,10 @@ func (v *SendStreamSendResults[T]) SetCount(count int32) {
 	v.data.Count = &count
 }
 
+func (v *SendStreamSendResults[T]) ClearCount() {
+	v.data.Count = nil
+}
+
 func (v *SendStreamSendResults[T]) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -172,6 +177,7 @@ func (v SendStreamClient[T]) Send(ctx context.Context, value T) (*SendStreamClie
 	return &SendStreamClientSendResults[T]{client: v.Client, data: ret}, nil
 }
 
+// Server structs for RecvStream
 type recvStreamRecvArgsData[T any] struct {
 	Count *int32 `cbor:"0,keyasint,omitempty" json:"count,omitempty"`
 }
@@ -221,6 +227,10 @@ func (v *RecvStreamRecvResults[T]) SetValue(value T) {
 	v.data.Value = &value
 }
 
+func (v *RecvStreamRecvResults[T]) ClearValue() {
+	v.data.Value = nil
+}
+
 func (v *RecvStreamRecvResults[T]) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -335,3 +345,270 @@ func (v RecvStreamClient[T]) Recv(ctx context.Context, count int32) (*RecvStream
 
 	return &RecvStreamClientRecvResults[T]{client: v.Client, data: ret}, nil
 }
+
+// Server structs for Topic
+type topicSubscribeArgsData[T any] struct{}
+
+type TopicSubscribeArgs[T any] struct {
+	call rpc.Call
+	data topicSubscribeArgsData[T]
+}
+
+func (v *TopicSubscribeArgs[T]) MarshalCBOR() ([]byte, error) {
+	return cbor.Marshal(v.data)
+}
+
+func (v *TopicSubscribeArgs[T]) UnmarshalCBOR(data []byte) error {
+	return cbor.Unmarshal(data, &v.data)
+}
+
+func (v *TopicSubscribeArgs[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.data)
+}
+
+func (v *TopicSubscribeArgs[T]) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &v.data)
+}
+
+type topicSubscribeResultsData[T any] struct {
+	Stream *rpc.Capability `cbor:"0,keyasint,omitempty" json:"stream,omitempty"`
+}
+
+type TopicSubscribeResults[T any] struct {
+	call rpc.Call
+	data topicSubscribeResultsData[T]
+}
+
+func (v *TopicSubscribeResults[T]) SetStream(stream RecvStream[T]) {
+	v.data.Stream = v.call.NewCapability(AdaptRecvStream[T](stream))
+}
+
+func (v *TopicSubscribeResults[T]) ClearStream() {
+	v.data.Stream = nil
+}
+
+func (v *TopicSubscribeResults[T]) MarshalCBOR() ([]byte, error) {
+	return cbor.Marshal(v.data)
+}
+
+func (v *TopicSubscribeResults[T]) UnmarshalCBOR(data []byte) error {
+	return cbor.Unmarshal(data, &v.data)
+}
+
+func (v *TopicSubscribeResults[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.data)
+}
+
+func (v *TopicSubscribeResults[T]) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &v.data)
+}
+
+type topicPublishArgsData[T any] struct {
+	Value *T `cbor:"0,keyasint,omitempty" json:"value,omitempty"`
+}
+
+type TopicPublishArgs[T any] struct {
+	call rpc.Call
+	data topicPublishArgsData[T]
+}
+
+func (v *TopicPublishArgs[T]) HasValue() bool {
+	return v.data.Value != nil
+}
+
+func (v *TopicPublishArgs[T]) Value() T {
+	if v.data.Value == nil {
+		return rpc.Zero[T]()
+	}
+	return *v.data.Value
+}
+
+func (v *TopicPublishArgs[T]) MarshalCBOR() ([]byte, error) {
+	return cbor.Marshal(v.data)
+}
+
+func (v *TopicPublishArgs[T]) UnmarshalCBOR(data []byte) error {
+	return cbor.Unmarshal(data, &v.data)
+}
+
+func (v *TopicPublishArgs[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.data)
+}
+
+func (v *TopicPublishArgs[T]) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &v.data)
+}
+
+type topicPublishResultsData[T any] struct{}
+
+type TopicPublishResults[T any] struct {
+	call rpc.Call
+	data topicPublishResultsData[T]
+}
+
+func (v *TopicPublishResults[T]) MarshalCBOR() ([]byte, error) {
+	return cbor.Marshal(v.data)
+}
+
+func (v *TopicPublishResults[T]) UnmarshalCBOR(data []byte) error {
+	return cbor.Unmarshal(data, &v.data)
+}
+
+func (v *TopicPublishResults[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.data)
+}
+
+func (v *TopicPublishResults[T]) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &v.data)
+}
+
+type TopicSubscribe[T any] struct {
+	rpc.Call
+	args    TopicSubscribeArgs[T]
+	results TopicSubscribeResults[T]
+}
+
+func (t *TopicSubscribe[T]) Args() *TopicSubscribeArgs[T] {
+	args := &t.args
+	if args.call != nil {
+		return args
+	}
+	args.call = t.Call
+	t.Call.Args(args)
+	return args
+}
+
+func (t *TopicSubscribe[T]) Results() *TopicSubscribeResults[T] {
+	results := &t.results
+	if results.call != nil {
+		return results
+	}
+	results.call = t.Call
+	t.Call.Results(results)
+	return results
+}
+
+type TopicPublish[T any] struct {
+	rpc.Call
+	args    TopicPublishArgs[T]
+	results TopicPublishResults[T]
+}
+
+func (t *TopicPublish[T]) Args() *TopicPublishArgs[T] {
+	args := &t.args
+	if args.call != nil {
+		return args
+	}
+	args.call = t.Call
+	t.Call.Args(args)
+	return args
+}
+
+func (t *TopicPublish[T]) Results() *TopicPublishResults[T] {
+	results := &t.results
+	if results.call != nil {
+		return results
+	}
+	results.call = t.Call
+	t.Call.Results(results)
+	return results
+}
+
+type Topic[T any] interface {
+	Subscribe(ctx context.Context, state *TopicSubscribe[T]) error
+	Publish(ctx context.Context, state *TopicPublish[T]) error
+}
+
+type reexportTopic[T any] struct {
+	client rpc.Client
+}
+
+func (reexportTopic[T]) Subscribe(ctx context.Context, state *TopicSubscribe[T]) error {
+	panic("not implemented")
+}
+
+func (reexportTopic[T]) Publish(ctx context.Context, state *TopicPublish[T]) error {
+	panic("not implemented")
+}
+
+func (t reexportTopic[T]) CapabilityClient() rpc.Client {
+	return t.client
+}
+
+func AdaptTopic[T any](t Topic[T]) *rpc.Interface {
+	methods := []rpc.Method{
+		{
+			Name:          "subscribe",
+			InterfaceName: "Topic",
+			Index:         0,
+			Handler: func(ctx context.Context, call rpc.Call) error {
+				return t.Subscribe(ctx, &TopicSubscribe[T]{Call: call})
+			},
+		},
+		{
+			Name:          "publish",
+			InterfaceName: "Topic",
+			Index:         0,
+			Handler: func(ctx context.Context, call rpc.Call) error {
+				return t.Publish(ctx, &TopicPublish[T]{Call: call})
+			},
+		},
+	}
+
+	return rpc.NewInterface(methods, t)
+}
+
+type TopicClient[T any] struct {
+	rpc.Client
+}
+
+func NewTopicClient[T any](client rpc.Client) *TopicClient[T] {
+	return &TopicClient[T]{Client: client}
+}
+
+func (c TopicClient[T]) Export() Topic[T] {
+	return reexportTopic[T]{client: c.Client}
+}
+
+type TopicClientSubscribeResults[T any] struct {
+	client rpc.Client
+	data   topicSubscribeResultsData[T]
+}
+
+func (v *TopicClientSubscribeResults[T]) Stream() *RecvStreamClient[T] {
+	return &RecvStreamClient[T]{
+		Client: v.client.NewClient(v.data.Stream),
+	}
+}
+
+func (v TopicClient[T]) Subscribe(ctx context.Context) (*TopicClientSubscribeResults[T], error) {
+	args := TopicSubscribeArgs[T]{}
+
+	var ret topicSubscribeResultsData[T]
+
+	err := v.Call(ctx, "subscribe", &args, &ret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TopicClientSubscribeResults[T]{client: v.Client, data: ret}, nil
+}
+
+type TopicClientPublishResults[T any] struct {
+	client rpc.Client
+	data   topicPublishResultsData[T]
+}
+
+func (v TopicClient[T]) Publish(ctx context.Context, value T) (*TopicClientPublishResults[T], error) {
+	args := TopicPublishArgs[T]{}
+	args.data.Value = &value
+
+	var ret topicPublishResultsData[T]
+
+	err := v.Call(ctx, "publish", &args, &ret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TopicClientPublishResults[T]{client: v.Client, data: ret}, nil
+}
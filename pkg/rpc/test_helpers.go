@@ -92,7 +92,7 @@ func (l *localClient) Call(ctx context.Context, name string, arg, ret any) error
 		},
 	}
 
-	err = m.Handler(ctx, call)
+	err = m.invoke(ctx, call)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,321 @@
+package deprecated
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/fxamacker/cbor/v2"
+	rpc "miren.dev/runtime/pkg/rpc"
+)
+
+type usageData struct {
+	Cpu *float64 `cbor:"0,keyasint,omitempty" json:"cpu,omitempty"`
+	// Deprecated: use cpu
+	LastMinCPU *float64 `cbor:"1,keyasint,omitempty" json:"last_min_c_p_u,omitempty"`
+}
+
+type Usage struct {
+	data usageData
+}
+
+func (v *Usage) HasCpu() bool {
+	return v.data.Cpu != nil
+}
+
+func (v *Usage) Cpu() float64 {
+	if v.data.Cpu == nil {
+		return 0
+	}
+	return *v.data.Cpu
+}
+
+func (v *Usage) SetCpu(cpu float64) {
+	v.data.Cpu = &cpu
+}
+
+func (v *Usage) ClearCpu() {
+	v.data.Cpu = nil
+}
+
+// Deprecated: use cpu
+func (v *Usage) HasLastMinCPU() bool {
+	return v.data.LastMinCPU != nil
+}
+
+// Deprecated: use cpu
+func (v *Usage) LastMinCPU() float64 {
+	if v.data.LastMinCPU == nil {
+		return 0
+	}
+	return *v.data.LastMinCPU
+}
+
+// Deprecated: use cpu
+func (v *Usage) SetLastMinCPU(lastMinCPU float64) {
+	v.data.LastMinCPU = &lastMinCPU
+}
+
+// Deprecated: use cpu
+func (v *Usage) ClearLastMinCPU() {
+	v.data.LastMinCPU = nil
+}
+
+func (v *Usage) MarshalCBOR() ([]byte, error) {
+	return cbor.Marshal(v.data)
+}
+
+func (v *Usage) UnmarshalCBOR(data []byte) error {
+	if err := cbor.Unmarshal(data, &v.data); err != nil {
+		return err
+	}
+
+	if v.data.LastMinCPU != nil {
+		rpc.WarnDeprecated("Usage.lastMinCPU", "use cpu")
+	}
+
+	return nil
+}
+
+func (v *Usage) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.data)
+}
+
+func (v *Usage) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &v.data); err != nil {
+		return err
+	}
+
+	if v.data.LastMinCPU != nil {
+		rpc.WarnDeprecated("Usage.lastMinCPU", "use cpu")
+	}
+
+	return nil
+}
+
+// Server structs for Monitor
+type monitorUsageArgsData struct {
+	App *string `cbor:"0,keyasint,omitempty" json:"app,omitempty"`
+	// Deprecated: pools are chosen by app
+	Pool *string `cbor:"1,keyasint,omitempty" json:"pool,omitempty"`
+}
+
+type MonitorUsageArgs struct {
+	call rpc.Call
+	data monitorUsageArgsData
+}
+
+func (v *MonitorUsageArgs) HasApp() bool {
+	return v.data.App != nil
+}
+
+func (v *MonitorUsageArgs) App() string {
+	if v.data.App == nil {
+		return ""
+	}
+	return *v.data.App
+}
+
+// Deprecated: pools are chosen by app
+func (v *MonitorUsageArgs) HasPool() bool {
+	return v.data.Pool != nil
+}
+
+// Deprecated: pools are chosen by app
+func (v *MonitorUsageArgs) Pool() string {
+	if v.data.Pool == nil {
+		return ""
+	}
+	return *v.data.Pool
+}
+
+func (v *MonitorUsageArgs) MarshalCBOR() ([]byte, error) {
+	return cbor.Marshal(v.data)
+}
+
+func (v *MonitorUsageArgs) UnmarshalCBOR(data []byte) error {
+	if err := cbor.Unmarshal(data, &v.data); err != nil {
+		return err
+	}
+
+	if v.data.Pool != nil {
+		rpc.WarnDeprecated("MonitorUsageArgs.pool", "pools are chosen by app")
+	}
+
+	return nil
+}
+
+func (v *MonitorUsageArgs) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.data)
+}
+
+func (v *MonitorUsageArgs) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &v.data); err != nil {
+		return err
+	}
+
+	if v.data.Pool != nil {
+		rpc.WarnDeprecated("MonitorUsageArgs.pool", "pools are chosen by app")
+	}
+
+	return nil
+}
+
+type monitorUsageResultsData struct {
+	Usage *Usage `cbor:"0,keyasint,omitempty" json:"usage,omitempty"`
+	// Deprecated: use usage
+	Cores *int32 `cbor:"1,keyasint,omitempty" json:"cores,omitempty"`
+}
+
+type MonitorUsageResults struct {
+	call rpc.Call
+	data monitorUsageResultsData
+}
+
+func (v *MonitorUsageResults) SetUsage(usage *Usage) {
+	v.data.Usage = usage
+}
+
+func (v *MonitorUsageResults) ClearUsage() {
+	v.data.Usage = nil
+}
+
+// Deprecated: use usage
+func (v *MonitorUsageResults) SetCores(cores int32) {
+	v.data.Cores = &cores
+}
+
+// Deprecated: use usage
+func (v *MonitorUsageResults) ClearCores() {
+	v.data.Cores = nil
+}
+
+func (v *MonitorUsageResults) MarshalCBOR() ([]byte, error) {
+	return cbor.Marshal(v.data)
+}
+
+func (v *MonitorUsageResults) UnmarshalCBOR(data []byte) error {
+	return cbor.Unmarshal(data, &v.data)
+}
+
+func (v *MonitorUsageResults) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.data)
+}
+
+func (v *MonitorUsageResults) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &v.data)
+}
+
+type MonitorUsage struct {
+	rpc.Call
+	args    MonitorUsageArgs
+	results MonitorUsageResults
+}
+
+func (t *MonitorUsage) Args() *MonitorUsageArgs {
+	args := &t.args
+	if args.call != nil {
+		return args
+	}
+	args.call = t.Call
+	t.Call.Args(args)
+	return args
+}
+
+func (t *MonitorUsage) Results() *MonitorUsageResults {
+	results := &t.results
+	if results.call != nil {
+		return results
+	}
+	results.call = t.Call
+	t.Call.Results(results)
+	return results
+}
+
+type Monitor interface {
+	Usage(ctx context.Context, state *MonitorUsage) error
+}
+
+type reexportMonitor struct {
+	client rpc.Client
+}
+
+func (reexportMonitor) Usage(ctx context.Context, state *MonitorUsage) error {
+	panic("not implemented")
+}
+
+func (t reexportMonitor) CapabilityClient() rpc.Client {
+	return t.client
+}
+
+func AdaptMonitor(t Monitor) *rpc.Interface {
+	methods := []rpc.Method{
+		{
+			Name:          "usage",
+			InterfaceName: "Monitor",
+			Index:         0,
+			Handler: func(ctx context.Context, call rpc.Call) error {
+				return t.Usage(ctx, &MonitorUsage{Call: call})
+			},
+		},
+	}
+
+	return rpc.NewInterface(methods, t)
+}
+
+type MonitorClient struct {
+	rpc.Client
+}
+
+func NewMonitorClient(client rpc.Client) *MonitorClient {
+	return &MonitorClient{Client: client}
+}
+
+func (c MonitorClient) Export() Monitor {
+	return reexportMonitor{client: c.Client}
+}
+
+type MonitorClientUsageResults struct {
+	client rpc.Client
+	data   monitorUsageResultsData
+}
+
+func (v *MonitorClientUsageResults) HasUsage() bool {
+	return v.data.Usage != nil
+}
+
+func (v *MonitorClientUsageResults) Usage() *Usage {
+	return v.data.Usage
+}
+
+// Deprecated: use usage
+func (v *MonitorClientUsageResults) HasCores() bool {
+	return v.data.Cores != nil
+}
+
+// Deprecated: use usage
+func (v *MonitorClientUsageResults) Cores() int32 {
+	if v.data.Cores == nil {
+		return 0
+	}
+	return *v.data.Cores
+}
+
+// The pool parameter of Usage is deprecated: pools are chosen by app
+func (v MonitorClient) Usage(ctx context.Context, app string, pool string) (*MonitorClientUsageResults, error) {
+	args := MonitorUsageArgs{}
+	args.data.App = &app
+	args.data.Pool = &pool
+
+	var ret monitorUsageResultsData
+
+	err := v.Call(ctx, "usage", &args, &ret)
+	if err != nil {
+		return nil, err
+	}
+
+	if ret.Cores != nil {
+		rpc.WarnDeprecated("MonitorClientUsageResults.cores", "use usage")
+	}
+
+	return &MonitorClientUsageResults{client: v.Client, data: ret}, nil
+}
@@ -0,0 +1,255 @@
+package duration
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+
+	"github.com/fxamacker/cbor/v2"
+	rpc "miren.dev/runtime/pkg/rpc"
+	"miren.dev/runtime/pkg/rpc/standard"
+)
+
+type windowData struct {
+	Start   *int64                `cbor:"0,keyasint,omitempty" json:"start,omitempty"`
+	Length  *standard.Duration    `cbor:"1,keyasint,omitempty" json:"length,omitempty"`
+	Buckets *[]*standard.Duration `cbor:"2,keyasint,omitempty" json:"buckets,omitempty"`
+}
+
+type Window struct {
+	data windowData
+}
+
+func (v *Window) HasStart() bool {
+	return v.data.Start != nil
+}
+
+func (v *Window) Start() int64 {
+	if v.data.Start == nil {
+		return 0
+	}
+	return *v.data.Start
+}
+
+func (v *Window) SetStart(start int64) {
+	v.data.Start = &start
+}
+
+func (v *Window) ClearStart() {
+	v.data.Start = nil
+}
+
+func (v *Window) HasLength() bool {
+	return v.data.Length != nil
+}
+
+func (v *Window) Length() *standard.Duration {
+	return v.data.Length
+}
+
+func (v *Window) SetLength(length *standard.Duration) {
+	v.data.Length = length
+}
+
+func (v *Window) ClearLength() {
+	v.data.Length = nil
+}
+
+func (v *Window) HasBuckets() bool {
+	return v.data.Buckets != nil
+}
+
+func (v *Window) Buckets() []*standard.Duration {
+	if v.data.Buckets == nil {
+		return nil
+	}
+	return *v.data.Buckets
+}
+
+func (v *Window) SetBuckets(buckets []*standard.Duration) {
+	x := slices.Clone(buckets)
+	v.data.Buckets = &x
+}
+
+func (v *Window) ClearBuckets() {
+	v.data.Buckets = nil
+}
+
+func (v *Window) MarshalCBOR() ([]byte, error) {
+	return cbor.Marshal(v.data)
+}
+
+func (v *Window) UnmarshalCBOR(data []byte) error {
+	return cbor.Unmarshal(data, &v.data)
+}
+
+func (v *Window) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.data)
+}
+
+func (v *Window) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &v.data)
+}
+
+// Server structs for Latency
+type latencyAverageArgsData struct {
+	Window *standard.Duration `cbor:"0,keyasint,omitempty" json:"window,omitempty"`
+}
+
+type LatencyAverageArgs struct {
+	call rpc.Call
+	data latencyAverageArgsData
+}
+
+func (v *LatencyAverageArgs) HasWindow() bool {
+	return v.data.Window != nil
+}
+
+func (v *LatencyAverageArgs) Window() *standard.Duration {
+	return v.data.Window
+}
+
+func (v *LatencyAverageArgs) MarshalCBOR() ([]byte, error) {
+	return cbor.Marshal(v.data)
+}
+
+func (v *LatencyAverageArgs) UnmarshalCBOR(data []byte) error {
+	return cbor.Unmarshal(data, &v.data)
+}
+
+func (v *LatencyAverageArgs) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.data)
+}
+
+func (v *LatencyAverageArgs) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &v.data)
+}
+
+type latencyAverageResultsData struct {
+	Latency *standard.Duration `cbor:"0,keyasint,omitempty" json:"latency,omitempty"`
+}
+
+type LatencyAverageResults struct {
+	call rpc.Call
+	data latencyAverageResultsData
+}
+
+func (v *LatencyAverageResults) SetLatency(latency *standard.Duration) {
+	v.data.Latency = latency
+}
+
+func (v *LatencyAverageResults) ClearLatency() {
+	v.data.Latency = nil
+}
+
+func (v *LatencyAverageResults) MarshalCBOR() ([]byte, error) {
+	return cbor.Marshal(v.data)
+}
+
+func (v *LatencyAverageResults) UnmarshalCBOR(data []byte) error {
+	return cbor.Unmarshal(data, &v.data)
+}
+
+func (v *LatencyAverageResults) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.data)
+}
+
+func (v *LatencyAverageResults) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &v.data)
+}
+
+type LatencyAverage struct {
+	rpc.Call
+	args    LatencyAverageArgs
+	results LatencyAverageResults
+}
+
+func (t *LatencyAverage) Args() *LatencyAverageArgs {
+	args := &t.args
+	if args.call != nil {
+		return args
+	}
+	args.call = t.Call
+	t.Call.Args(args)
+	return args
+}
+
+func (t *LatencyAverage) Results() *LatencyAverageResults {
+	results := &t.results
+	if results.call != nil {
+		return results
+	}
+	results.call = t.Call
+	t.Call.Results(results)
+	return results
+}
+
+type Latency interface {
+	Average(ctx context.Context, state *LatencyAverage) error
+}
+
+type reexportLatency struct {
+	client rpc.Client
+}
+
+func (reexportLatency) Average(ctx context.Context, state *LatencyAverage) error {
+	panic("not implemented")
+}
+
+func (t reexportLatency) CapabilityClient() rpc.Client {
+	return t.client
+}
+
+func AdaptLatency(t Latency) *rpc.Interface {
+	methods := []rpc.Method{
+		{
+			Name:          "average",
+			InterfaceName: "Latency",
+			Index:         0,
+			Handler: func(ctx context.Context, call rpc.Call) error {
+				return t.Average(ctx, &LatencyAverage{Call: call})
+			},
+		},
+	}
+
+	return rpc.NewInterface(methods, t)
+}
+
+type LatencyClient struct {
+	rpc.Client
+}
+
+func NewLatencyClient(client rpc.Client) *LatencyClient {
+	return &LatencyClient{Client: client}
+}
+
+func (c LatencyClient) Export() Latency {
+	return reexportLatency{client: c.Client}
+}
+
+type LatencyClientAverageResults struct {
+	client rpc.Client
+	data   latencyAverageResultsData
+}
+
+func (v *LatencyClientAverageResults) HasLatency() bool {
+	return v.data.Latency != nil
+}
+
+func (v *LatencyClientAverageResults) Latency() *standard.Duration {
+	return v.data.Latency
+}
+
+func (v LatencyClient) Average(ctx context.Context, window *standard.Duration) (*LatencyClientAverageResults, error) {
+	args := LatencyAverageArgs{}
+	args.data.Window = window
+
+	var ret latencyAverageResultsData
+
+	err := v.Call(ctx, "average", &args, &ret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LatencyClientAverageResults{client: v.Client, data: ret}, nil
+}
@@ -30,6 +30,10 @@ func (v *Power) SetWeakness(weakness string) {
 	v.data.Weakness = &weakness
 }
 
+func (v *Power) ClearWeakness() {
+	v.data.Weakness = nil
+}
+
 func (v *Power) HasPower() bool {
 	return v.data.Power != nil
 }
@@ -45,6 +49,10 @@ func (v *Power) SetPower(power float32) {
 	v.data.Power = &power
 }
 
+func (v *Power) ClearPower() {
+	v.data.Power = nil
+}
+
 func (v *Power) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -85,6 +93,10 @@ func (v *Hero) SetAge(age int32) {
 	v.data.Age = &age
 }
 
+func (v *Hero) ClearAge() {
+	v.data.Age = nil
+}
+
 func (v *Hero) HasPower() bool {
 	return v.data.Power != nil
 }
@@ -97,6 +109,10 @@ func (v *Hero) SetPower(power *Power) {
 	v.data.Power = power
 }
 
+func (v *Hero) ClearPower() {
+	v.data.Power = nil
+}
+
 func (v *Hero) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
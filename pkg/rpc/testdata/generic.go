@@ -29,6 +29,10 @@ func (v *Container[T]) SetValue(value *T) {
 	v.data.Value = value
 }
 
+func (v *Container[T]) ClearValue() {
+	v.data.Value = nil
+}
+
 func (v *Container[T]) HasDescription() bool {
 	return v.data.Description != nil
 }
@@ -44,6 +48,10 @@ func (v *Container[T]) SetDescription(description string) {
 	v.data.Description = &description
 }
 
+func (v *Container[T]) ClearDescription() {
+	v.data.Description = nil
+}
+
 func (v *Container[T]) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -60,6 +68,7 @@ func (v *Container[T]) UnmarshalJSON(data []byte) error {
 	return json.Unmarshal(data, &v.data)
 }
 
+// Server structs for Reader
 type readerReadArgsData[T any] struct {
 	Value *Container[T] `cbor:"0,keyasint,omitempty" json:"value,omitempty"`
 }
@@ -32,6 +32,10 @@ func (v *Hero) SetAge(age int32) {
 	v.data.Age = &age
 }
 
+func (v *Hero) ClearAge() {
+	v.data.Age = nil
+}
+
 func (v *Hero) HasPower() bool {
 	return v.data.Power != nil
 }
@@ -47,6 +51,10 @@ func (v *Hero) SetPower(power float32) {
 	v.data.Power = &power
 }
 
+func (v *Hero) ClearPower() {
+	v.data.Power = nil
+}
+
 func (v *Hero) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -63,6 +71,7 @@ func (v *Hero) UnmarshalJSON(data []byte) error {
 	return json.Unmarshal(data, &v.data)
 }
 
+// Server structs for Town
 type townGetHeroArgsData struct {
 	Name *string `cbor:"0,keyasint,omitempty" json:"name,omitempty"`
 }
@@ -113,10 +122,18 @@ func (v *TownGetHeroResults) SetHero(hero *Hero) {
 	v.data.Hero = hero
 }
 
+func (v *TownGetHeroResults) ClearHero() {
+	v.data.Hero = nil
+}
+
 func (v *TownGetHeroResults) SetEmpower(empower Empower) {
 	v.data.Empower = v.call.NewCapability(AdaptEmpower(empower))
 }
 
+func (v *TownGetHeroResults) ClearEmpower() {
+	v.data.Empower = nil
+}
+
 func (v *TownGetHeroResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -182,6 +199,10 @@ func (v *TownHireHeroResults) SetAssigned(assigned bool) {
 	v.data.Assigned = &assigned
 }
 
+func (v *TownHireHeroResults) ClearAssigned() {
+	v.data.Assigned = nil
+}
+
 func (v *TownHireHeroResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -369,6 +390,7 @@ func (v TownClient) HireHero(ctx context.Context, name string) (*TownClientHireH
 	return &TownClientHireHeroResults{client: v.Client, data: ret}, nil
 }
 
+// Server structs for Empower
 type empowerIncreasePowerArgsData struct {
 	Power *int32 `cbor:"0,keyasint,omitempty" json:"power,omitempty"`
 }
@@ -418,6 +440,10 @@ func (v *EmpowerIncreasePowerResults) SetLevel(level int32) {
 	v.data.Level = &level
 }
 
+func (v *EmpowerIncreasePowerResults) ClearLevel() {
+	v.data.Level = nil
+}
+
 func (v *EmpowerIncreasePowerResults) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -0,0 +1,359 @@
+package streamlist
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+
+	"github.com/fxamacker/cbor/v2"
+	rpc "miren.dev/runtime/pkg/rpc"
+	"miren.dev/runtime/pkg/rpc/stream"
+)
+
+type itemData struct {
+	Name *string `cbor:"0,keyasint,omitempty" json:"name,omitempty"`
+}
+
+type Item struct {
+	data itemData
+}
+
+func (v *Item) HasName() bool {
+	return v.data.Name != nil
+}
+
+func (v *Item) Name() string {
+	if v.data.Name == nil {
+		return ""
+	}
+	return *v.data.Name
+}
+
+func (v *Item) SetName(name string) {
+	v.data.Name = &name
+}
+
+func (v *Item) ClearName() {
+	v.data.Name = nil
+}
+
+func (v *Item) MarshalCBOR() ([]byte, error) {
+	return cbor.Marshal(v.data)
+}
+
+func (v *Item) UnmarshalCBOR(data []byte) error {
+	return cbor.Unmarshal(data, &v.data)
+}
+
+func (v *Item) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.data)
+}
+
+func (v *Item) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &v.data)
+}
+
+// Server structs for Catalog
+type catalogListArgsData struct {
+	Prefix *string `cbor:"0,keyasint,omitempty" json:"prefix,omitempty"`
+}
+
+type CatalogListArgs struct {
+	call rpc.Call
+	data catalogListArgsData
+}
+
+func (v *CatalogListArgs) HasPrefix() bool {
+	return v.data.Prefix != nil
+}
+
+func (v *CatalogListArgs) Prefix() string {
+	if v.data.Prefix == nil {
+		return ""
+	}
+	return *v.data.Prefix
+}
+
+func (v *CatalogListArgs) MarshalCBOR() ([]byte, error) {
+	return cbor.Marshal(v.data)
+}
+
+func (v *CatalogListArgs) UnmarshalCBOR(data []byte) error {
+	return cbor.Unmarshal(data, &v.data)
+}
+
+func (v *CatalogListArgs) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.data)
+}
+
+func (v *CatalogListArgs) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &v.data)
+}
+
+type catalogListResultsData struct {
+	Items *[]*Item `cbor:"0,keyasint,omitempty" json:"items,omitempty"`
+}
+
+type CatalogListResults struct {
+	call rpc.Call
+	data catalogListResultsData
+}
+
+func (v *CatalogListResults) SetItems(items []*Item) {
+	x := slices.Clone(items)
+	v.data.Items = &x
+}
+
+func (v *CatalogListResults) ClearItems() {
+	v.data.Items = nil
+}
+
+func (v *CatalogListResults) MarshalCBOR() ([]byte, error) {
+	return cbor.Marshal(v.data)
+}
+
+func (v *CatalogListResults) UnmarshalCBOR(data []byte) error {
+	return cbor.Unmarshal(data, &v.data)
+}
+
+func (v *CatalogListResults) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.data)
+}
+
+func (v *CatalogListResults) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &v.data)
+}
+
+type catalogListStreamArgsData struct {
+	Prefix *string         `cbor:"0,keyasint,omitempty" json:"prefix,omitempty"`
+	Items  *rpc.Capability `cbor:"1,keyasint,omitempty" json:"items,omitempty"`
+}
+
+type CatalogListStreamArgs struct {
+	call rpc.Call
+	data catalogListStreamArgsData
+}
+
+func (v *CatalogListStreamArgs) HasPrefix() bool {
+	return v.data.Prefix != nil
+}
+
+func (v *CatalogListStreamArgs) Prefix() string {
+	if v.data.Prefix == nil {
+		return ""
+	}
+	return *v.data.Prefix
+}
+
+func (v *CatalogListStreamArgs) HasItems() bool {
+	return v.data.Items != nil
+}
+
+func (v *CatalogListStreamArgs) Items() *stream.SendStreamClient[*Item] {
+	if v.data.Items == nil {
+		return nil
+	}
+	return &stream.SendStreamClient[*Item]{Client: v.call.NewClient(v.data.Items)}
+}
+
+func (v *CatalogListStreamArgs) MarshalCBOR() ([]byte, error) {
+	return cbor.Marshal(v.data)
+}
+
+func (v *CatalogListStreamArgs) UnmarshalCBOR(data []byte) error {
+	return cbor.Unmarshal(data, &v.data)
+}
+
+func (v *CatalogListStreamArgs) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.data)
+}
+
+func (v *CatalogListStreamArgs) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &v.data)
+}
+
+type catalogListStreamResultsData struct{}
+
+type CatalogListStreamResults struct {
+	call rpc.Call
+	data catalogListStreamResultsData
+}
+
+func (v *CatalogListStreamResults) MarshalCBOR() ([]byte, error) {
+	return cbor.Marshal(v.data)
+}
+
+func (v *CatalogListStreamResults) UnmarshalCBOR(data []byte) error {
+	return cbor.Unmarshal(data, &v.data)
+}
+
+func (v *CatalogListStreamResults) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.data)
+}
+
+func (v *CatalogListStreamResults) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &v.data)
+}
+
+type CatalogList struct {
+	rpc.Call
+	args    CatalogListArgs
+	results CatalogListResults
+}
+
+func (t *CatalogList) Args() *CatalogListArgs {
+	args := &t.args
+	if args.call != nil {
+		return args
+	}
+	args.call = t.Call
+	t.Call.Args(args)
+	return args
+}
+
+func (t *CatalogList) Results() *CatalogListResults {
+	results := &t.results
+	if results.call != nil {
+		return results
+	}
+	results.call = t.Call
+	t.Call.Results(results)
+	return results
+}
+
+type CatalogListStream struct {
+	rpc.Call
+	args    CatalogListStreamArgs
+	results CatalogListStreamResults
+}
+
+func (t *CatalogListStream) Args() *CatalogListStreamArgs {
+	args := &t.args
+	if args.call != nil {
+		return args
+	}
+	args.call = t.Call
+	t.Call.Args(args)
+	return args
+}
+
+func (t *CatalogListStream) Results() *CatalogListStreamResults {
+	results := &t.results
+	if results.call != nil {
+		return results
+	}
+	results.call = t.Call
+	t.Call.Results(results)
+	return results
+}
+
+type Catalog interface {
+	List(ctx context.Context, state *CatalogList) error
+	ListStream(ctx context.Context, state *CatalogListStream) error
+}
+
+type reexportCatalog struct {
+	client rpc.Client
+}
+
+func (reexportCatalog) List(ctx context.Context, state *CatalogList) error {
+	panic("not implemented")
+}
+
+func (reexportCatalog) ListStream(ctx context.Context, state *CatalogListStream) error {
+	panic("not implemented")
+}
+
+func (t reexportCatalog) CapabilityClient() rpc.Client {
+	return t.client
+}
+
+func AdaptCatalog(t Catalog) *rpc.Interface {
+	methods := []rpc.Method{
+		{
+			Name:          "list",
+			InterfaceName: "Catalog",
+			Index:         0,
+			Handler: func(ctx context.Context, call rpc.Call) error {
+				return t.List(ctx, &CatalogList{Call: call})
+			},
+		},
+		{
+			Name:          "listStream",
+			InterfaceName: "Catalog",
+			Index:         0,
+			Handler: func(ctx context.Context, call rpc.Call) error {
+				return t.ListStream(ctx, &CatalogListStream{Call: call})
+			},
+		},
+	}
+
+	return rpc.NewInterface(methods, t)
+}
+
+type CatalogClient struct {
+	rpc.Client
+}
+
+func NewCatalogClient(client rpc.Client) *CatalogClient {
+	return &CatalogClient{Client: client}
+}
+
+func (c CatalogClient) Export() Catalog {
+	return reexportCatalog{client: c.Client}
+}
+
+type CatalogClientListResults struct {
+	client rpc.Client
+	data   catalogListResultsData
+}
+
+func (v *CatalogClientListResults) HasItems() bool {
+	return v.data.Items != nil
+}
+
+func (v *CatalogClientListResults) Items() []*Item {
+	if v.data.Items == nil {
+		return nil
+	}
+	return *v.data.Items
+}
+
+func (v CatalogClient) List(ctx context.Context, prefix string) (*CatalogClientListResults, error) {
+	args := CatalogListArgs{}
+	args.data.Prefix = &prefix
+
+	var ret catalogListResultsData
+
+	err := v.Call(ctx, "list", &args, &ret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CatalogClientListResults{client: v.Client, data: ret}, nil
+}
+
+type CatalogClientListStreamResults struct {
+	client rpc.Client
+	data   catalogListStreamResultsData
+}
+
+func (v CatalogClient) ListStream(ctx context.Context, prefix string, items stream.SendStream[*Item]) (*CatalogClientListStreamResults, error) {
+	args := CatalogListStreamArgs{}
+	caps := map[rpc.OID]*rpc.InlineCapability{}
+	args.data.Prefix = &prefix
+	{
+		ic, oid, c := v.NewInlineCapability(stream.AdaptSendStream[*Item](items), items)
+		args.data.Items = c
+		caps[oid] = ic
+	}
+
+	var ret catalogListStreamResultsData
+
+	err := v.CallWithCaps(ctx, "listStream", &args, &ret, caps)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CatalogClientListStreamResults{client: v.Client, data: ret}, nil
+}
@@ -32,6 +32,10 @@ func (v *Hero) SetAge(age int32) {
 	v.data.Age = &age
 }
 
+func (v *Hero) ClearAge() {
+	v.data.Age = nil
+}
+
 func (v *Hero) HasName() bool {
 	return v.data.Name != nil
 }
@@ -47,6 +51,10 @@ func (v *Hero) SetName(name string) {
 	v.data.Name = &name
 }
 
+func (v *Hero) ClearName() {
+	v.data.Name = nil
+}
+
 func (v *Hero) HasPeople() bool {
 	return v.data.People != nil
 }
@@ -63,6 +71,10 @@ func (v *Hero) SetPeople(people []string) {
 	v.data.People = &x
 }
 
+func (v *Hero) ClearPeople() {
+	v.data.People = nil
+}
+
 func (v *Hero) MarshalCBOR() ([]byte, error) {
 	return cbor.Marshal(v.data)
 }
@@ -0,0 +1,32 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"miren.dev/runtime/pkg/cond"
+)
+
+var errMethodTimeout = errors.New("method timeout")
+
+// invoke calls the method's handler, canceling its context once it has run
+// for longer than the method's Timeout. The handler still has to return on
+// its own, but whatever it returns is replaced by ErrDeadlineExceeded.
+func (m Method) invoke(ctx context.Context, call Call) error {
+	if m.Timeout <= 0 {
+		return m.Handler(ctx, call)
+	}
+
+	ctx, cancel := context.WithTimeoutCause(ctx, m.Timeout, errMethodTimeout)
+	defer cancel()
+
+	err := m.Handler(ctx, call)
+
+	if context.Cause(ctx) == errMethodTimeout {
+		return cond.DeadlineExceeded(
+			fmt.Sprintf("%s.%s ran past its %s timeout", m.InterfaceName, m.Name, m.Timeout))
+	}
+
+	return err
+}
@@ -0,0 +1,143 @@
+package rpc_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"miren.dev/runtime/pkg/rpc"
+	"miren.dev/runtime/pkg/rpc/example"
+)
+
+type exampleWaiter struct {
+	canceled chan error
+}
+
+func (w *exampleWaiter) Wait(ctx context.Context, call *example.WaiterWait) error {
+	select {
+	case <-time.After(time.Duration(call.Args().Millis()) * time.Millisecond):
+		return nil
+	case <-ctx.Done():
+		w.canceled <- ctx.Err()
+		return ctx.Err()
+	}
+}
+
+func TestMethodTimeout(t *testing.T) {
+	r := require.New(t)
+	ctx := t.Context()
+
+	w := &exampleWaiter{canceled: make(chan error, 1)}
+
+	ss, err := rpc.NewState(ctx, rpc.WithSkipVerify)
+	r.NoError(err)
+	defer ss.Close()
+
+	ss.Server().ExposeValue("waiter", example.AdaptWaiter(w))
+
+	cs, err := rpc.NewState(ctx, rpc.WithSkipVerify)
+	r.NoError(err)
+	defer cs.Close()
+
+	c, err := cs.Connect(ss.ListenAddr(), "waiter")
+	r.NoError(err)
+
+	wc := &example.WaiterClient{Client: c}
+
+	t.Run("a handler within its timeout succeeds", func(t *testing.T) {
+		_, err := wc.Wait(ctx, 1)
+		require.NoError(t, err)
+	})
+
+	t.Run("a handler overrunning its timeout is canceled", func(t *testing.T) {
+		r := require.New(t)
+
+		start := time.Now()
+
+		// The client allows far longer than the method's declared 100ms.
+		cctx, cancel := context.WithTimeout(ctx, time.Minute)
+		defer cancel()
+
+		_, err := wc.Wait(cctx, 10000)
+		r.Error(err)
+		r.True(errors.Is(err, rpc.ErrDeadlineExceeded), "unexpected error: %v", err)
+
+		r.Less(time.Since(start), 5*time.Second)
+
+		select {
+		case err := <-w.canceled:
+			r.ErrorIs(err, context.DeadlineExceeded)
+		default:
+			r.Fail("handler was not canceled")
+		}
+	})
+}
+
+// deadlineMeter records the deadline its handler sees.
+type deadlineMeter struct {
+	exampleMeter
+
+	calls    int
+	deadline time.Time
+	ok       bool
+}
+
+func (m *deadlineMeter) ReadTemperature(ctx context.Context, call *example.MeterReadTemperature) error {
+	m.calls++
+	m.deadline, m.ok = ctx.Deadline()
+	return m.exampleMeter.ReadTemperature(ctx, call)
+}
+
+func TestDeadlinePropagation(t *testing.T) {
+	r := require.New(t)
+	ctx := t.Context()
+
+	meter := &deadlineMeter{}
+
+	ss, err := rpc.NewState(ctx, rpc.WithSkipVerify)
+	r.NoError(err)
+	defer ss.Close()
+
+	ss.Server().ExposeValue("meter", example.AdaptMeter(meter))
+
+	cs, err := rpc.NewState(ctx, rpc.WithSkipVerify)
+	r.NoError(err)
+	defer cs.Close()
+
+	c, err := cs.Connect(ss.ListenAddr(), "meter")
+	r.NoError(err)
+
+	mc := &example.MeterClient{Client: c}
+
+	t.Run("the handler sees the caller's deadline", func(t *testing.T) {
+		r := require.New(t)
+
+		cctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+
+		dl, _ := cctx.Deadline()
+
+		_, err := mc.ReadTemperature(cctx, "test")
+		r.NoError(err)
+
+		r.True(meter.ok, "handler context has no deadline")
+		r.WithinDuration(dl, meter.deadline, time.Second)
+	})
+
+	t.Run("an expired call isn't sent", func(t *testing.T) {
+		r := require.New(t)
+
+		calls := meter.calls
+
+		cctx, cancel := context.WithTimeout(ctx, time.Nanosecond)
+		defer cancel()
+
+		<-cctx.Done()
+
+		_, err := mc.ReadTemperature(cctx, "test")
+		r.ErrorIs(err, context.DeadlineExceeded)
+		r.Equal(calls, meter.calls)
+	})
+}
@@ -0,0 +1,519 @@
+package upload
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/fxamacker/cbor/v2"
+	rpc "miren.dev/runtime/pkg/rpc"
+)
+
+// Server structs for Upload
+type uploadBeginArgsData struct {
+	Id   *string `cbor:"0,keyasint,omitempty" json:"id,omitempty"`
+	Size *int64  `cbor:"1,keyasint,omitempty" json:"size,omitempty"`
+}
+
+type UploadBeginArgs struct {
+	call rpc.Call
+	data uploadBeginArgsData
+}
+
+func (v *UploadBeginArgs) HasId() bool {
+	return v.data.Id != nil
+}
+
+func (v *UploadBeginArgs) Id() string {
+	if v.data.Id == nil {
+		return ""
+	}
+	return *v.data.Id
+}
+
+func (v *UploadBeginArgs) HasSize() bool {
+	return v.data.Size != nil
+}
+
+func (v *UploadBeginArgs) Size() int64 {
+	if v.data.Size == nil {
+		return 0
+	}
+	return *v.data.Size
+}
+
+func (v *UploadBeginArgs) MarshalCBOR() ([]byte, error) {
+	return cbor.Marshal(v.data)
+}
+
+func (v *UploadBeginArgs) UnmarshalCBOR(data []byte) error {
+	return cbor.Unmarshal(data, &v.data)
+}
+
+func (v *UploadBeginArgs) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.data)
+}
+
+func (v *UploadBeginArgs) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &v.data)
+}
+
+type uploadBeginResultsData struct {
+	Offset *int64 `cbor:"0,keyasint,omitempty" json:"offset,omitempty"`
+}
+
+type UploadBeginResults struct {
+	call rpc.Call
+	data uploadBeginResultsData
+}
+
+func (v *UploadBeginResults) SetOffset(offset int64) {
+	v.data.Offset = &offset
+}
+
+func (v *UploadBeginResults) ClearOffset() {
+	v.data.Offset = nil
+}
+
+func (v *UploadBeginResults) MarshalCBOR() ([]byte, error) {
+	return cbor.Marshal(v.data)
+}
+
+func (v *UploadBeginResults) UnmarshalCBOR(data []byte) error {
+	return cbor.Unmarshal(data, &v.data)
+}
+
+func (v *UploadBeginResults) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.data)
+}
+
+func (v *UploadBeginResults) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &v.data)
+}
+
+type uploadWriteArgsData struct {
+	Id       *string `cbor:"0,keyasint,omitempty" json:"id,omitempty"`
+	Offset   *int64  `cbor:"1,keyasint,omitempty" json:"offset,omitempty"`
+	Data     *[]byte `cbor:"2,keyasint,omitempty" json:"data,omitempty"`
+	Checksum *uint32 `cbor:"3,keyasint,omitempty" json:"checksum,omitempty"`
+}
+
+type UploadWriteArgs struct {
+	call rpc.Call
+	data uploadWriteArgsData
+}
+
+func (v *UploadWriteArgs) HasId() bool {
+	return v.data.Id != nil
+}
+
+func (v *UploadWriteArgs) Id() string {
+	if v.data.Id == nil {
+		return ""
+	}
+	return *v.data.Id
+}
+
+func (v *UploadWriteArgs) HasOffset() bool {
+	return v.data.Offset != nil
+}
+
+func (v *UploadWriteArgs) Offset() int64 {
+	if v.data.Offset == nil {
+		return 0
+	}
+	return *v.data.Offset
+}
+
+func (v *UploadWriteArgs) HasData() bool {
+	return v.data.Data != nil
+}
+
+func (v *UploadWriteArgs) Data() []byte {
+	if v.data.Data == nil {
+		return nil
+	}
+	return *v.data.Data
+}
+
+func (v *UploadWriteArgs) HasChecksum() bool {
+	return v.data.Checksum != nil
+}
+
+func (v *UploadWriteArgs) Checksum() uint32 {
+	if v.data.Checksum == nil {
+		return 0
+	}
+	return *v.data.Checksum
+}
+
+func (v *UploadWriteArgs) MarshalCBOR() ([]byte, error) {
+	return cbor.Marshal(v.data)
+}
+
+func (v *UploadWriteArgs) UnmarshalCBOR(data []byte) error {
+	return cbor.Unmarshal(data, &v.data)
+}
+
+func (v *UploadWriteArgs) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.data)
+}
+
+func (v *UploadWriteArgs) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &v.data)
+}
+
+type uploadWriteResultsData struct {
+	Offset *int64 `cbor:"0,keyasint,omitempty" json:"offset,omitempty"`
+}
+
+type UploadWriteResults struct {
+	call rpc.Call
+	data uploadWriteResultsData
+}
+
+func (v *UploadWriteResults) SetOffset(offset int64) {
+	v.data.Offset = &offset
+}
+
+func (v *UploadWriteResults) ClearOffset() {
+	v.data.Offset = nil
+}
+
+func (v *UploadWriteResults) MarshalCBOR() ([]byte, error) {
+	return cbor.Marshal(v.data)
+}
+
+func (v *UploadWriteResults) UnmarshalCBOR(data []byte) error {
+	return cbor.Unmarshal(data, &v.data)
+}
+
+func (v *UploadWriteResults) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.data)
+}
+
+func (v *UploadWriteResults) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &v.data)
+}
+
+type uploadFinishArgsData struct {
+	Id     *string `cbor:"0,keyasint,omitempty" json:"id,omitempty"`
+	Digest *[]byte `cbor:"1,keyasint,omitempty" json:"digest,omitempty"`
+}
+
+type UploadFinishArgs struct {
+	call rpc.Call
+	data uploadFinishArgsData
+}
+
+func (v *UploadFinishArgs) HasId() bool {
+	return v.data.Id != nil
+}
+
+func (v *UploadFinishArgs) Id() string {
+	if v.data.Id == nil {
+		return ""
+	}
+	return *v.data.Id
+}
+
+func (v *UploadFinishArgs) HasDigest() bool {
+	return v.data.Digest != nil
+}
+
+func (v *UploadFinishArgs) Digest() []byte {
+	if v.data.Digest == nil {
+		return nil
+	}
+	return *v.data.Digest
+}
+
+func (v *UploadFinishArgs) MarshalCBOR() ([]byte, error) {
+	return cbor.Marshal(v.data)
+}
+
+func (v *UploadFinishArgs) UnmarshalCBOR(data []byte) error {
+	return cbor.Unmarshal(data, &v.data)
+}
+
+func (v *UploadFinishArgs) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.data)
+}
+
+func (v *UploadFinishArgs) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &v.data)
+}
+
+type uploadFinishResultsData struct {
+	Size *int64 `cbor:"0,keyasint,omitempty" json:"size,omitempty"`
+}
+
+type UploadFinishResults struct {
+	call rpc.Call
+	data uploadFinishResultsData
+}
+
+func (v *UploadFinishResults) SetSize(size int64) {
+	v.data.Size = &size
+}
+
+func (v *UploadFinishResults) ClearSize() {
+	v.data.Size = nil
+}
+
+func (v *UploadFinishResults) MarshalCBOR() ([]byte, error) {
+	return cbor.Marshal(v.data)
+}
+
+func (v *UploadFinishResults) UnmarshalCBOR(data []byte) error {
+	return cbor.Unmarshal(data, &v.data)
+}
+
+func (v *UploadFinishResults) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.data)
+}
+
+func (v *UploadFinishResults) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &v.data)
+}
+
+type UploadBegin struct {
+	rpc.Call
+	args    UploadBeginArgs
+	results UploadBeginResults
+}
+
+func (t *UploadBegin) Args() *UploadBeginArgs {
+	args := &t.args
+	if args.call != nil {
+		return args
+	}
+	args.call = t.Call
+	t.Call.Args(args)
+	return args
+}
+
+func (t *UploadBegin) Results() *UploadBeginResults {
+	results := &t.results
+	if results.call != nil {
+		return results
+	}
+	results.call = t.Call
+	t.Call.Results(results)
+	return results
+}
+
+type UploadWrite struct {
+	rpc.Call
+	args    UploadWriteArgs
+	results UploadWriteResults
+}
+
+func (t *UploadWrite) Args() *UploadWriteArgs {
+	args := &t.args
+	if args.call != nil {
+		return args
+	}
+	args.call = t.Call
+	t.Call.Args(args)
+	return args
+}
+
+func (t *UploadWrite) Results() *UploadWriteResults {
+	results := &t.results
+	if results.call != nil {
+		return results
+	}
+	results.call = t.Call
+	t.Call.Results(results)
+	return results
+}
+
+type UploadFinish struct {
+	rpc.Call
+	args    UploadFinishArgs
+	results UploadFinishResults
+}
+
+func (t *UploadFinish) Args() *UploadFinishArgs {
+	args := &t.args
+	if args.call != nil {
+		return args
+	}
+	args.call = t.Call
+	t.Call.Args(args)
+	return args
+}
+
+func (t *UploadFinish) Results() *UploadFinishResults {
+	results := &t.results
+	if results.call != nil {
+		return results
+	}
+	results.call = t.Call
+	t.Call.Results(results)
+	return results
+}
+
+type Upload interface {
+	Begin(ctx context.Context, state *UploadBegin) error
+	Write(ctx context.Context, state *UploadWrite) error
+	Finish(ctx context.Context, state *UploadFinish) error
+}
+
+type reexportUpload struct {
+	client rpc.Client
+}
+
+func (reexportUpload) Begin(ctx context.Context, state *UploadBegin) error {
+	panic("not implemented")
+}
+
+func (reexportUpload) Write(ctx context.Context, state *UploadWrite) error {
+	panic("not implemented")
+}
+
+func (reexportUpload) Finish(ctx context.Context, state *UploadFinish) error {
+	panic("not implemented")
+}
+
+func (t reexportUpload) CapabilityClient() rpc.Client {
+	return t.client
+}
+
+func AdaptUpload(t Upload) *rpc.Interface {
+	methods := []rpc.Method{
+		{
+			Name:          "begin",
+			InterfaceName: "Upload",
+			Index:         0,
+			Handler: func(ctx context.Context, call rpc.Call) error {
+				return t.Begin(ctx, &UploadBegin{Call: call})
+			},
+		},
+		{
+			Name:          "write",
+			InterfaceName: "Upload",
+			Index:         0,
+			Handler: func(ctx context.Context, call rpc.Call) error {
+				return t.Write(ctx, &UploadWrite{Call: call})
+			},
+		},
+		{
+			Name:          "finish",
+			InterfaceName: "Upload",
+			Index:         0,
+			Handler: func(ctx context.Context, call rpc.Call) error {
+				return t.Finish(ctx, &UploadFinish{Call: call})
+			},
+		},
+	}
+
+	return rpc.NewInterface(methods, t)
+}
+
+type UploadClient struct {
+	rpc.Client
+}
+
+func NewUploadClient(client rpc.Client) *UploadClient {
+	return &UploadClient{Client: client}
+}
+
+func (c UploadClient) Export() Upload {
+	return reexportUpload{client: c.Client}
+}
+
+type UploadClientBeginResults struct {
+	client rpc.Client
+	data   uploadBeginResultsData
+}
+
+func (v *UploadClientBeginResults) HasOffset() bool {
+	return v.data.Offset != nil
+}
+
+func (v *UploadClientBeginResults) Offset() int64 {
+	if v.data.Offset == nil {
+		return 0
+	}
+	return *v.data.Offset
+}
+
+func (v UploadClient) Begin(ctx context.Context, id string, size int64) (*UploadClientBeginResults, error) {
+	args := UploadBeginArgs{}
+	args.data.Id = &id
+	args.data.Size = &size
+
+	var ret uploadBeginResultsData
+
+	err := v.Call(ctx, "begin", &args, &ret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UploadClientBeginResults{client: v.Client, data: ret}, nil
+}
+
+type UploadClientWriteResults struct {
+	client rpc.Client
+	data   uploadWriteResultsData
+}
+
+func (v *UploadClientWriteResults) HasOffset() bool {
+	return v.data.Offset != nil
+}
+
+func (v *UploadClientWriteResults) Offset() int64 {
+	if v.data.Offset == nil {
+		return 0
+	}
+	return *v.data.Offset
+}
+
+func (v UploadClient) Write(ctx context.Context, id string, offset int64, data []byte, checksum uint32) (*UploadClientWriteResults, error) {
+	args := UploadWriteArgs{}
+	args.data.Id = &id
+	args.data.Offset = &offset
+	args.data.Data = &data
+	args.data.Checksum = &checksum
+
+	var ret uploadWriteResultsData
+
+	err := v.Call(ctx, "write", &args, &ret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UploadClientWriteResults{client: v.Client, data: ret}, nil
+}
+
+type UploadClientFinishResults struct {
+	client rpc.Client
+	data   uploadFinishResultsData
+}
+
+func (v *UploadClientFinishResults) HasSize() bool {
+	return v.data.Size != nil
+}
+
+func (v *UploadClientFinishResults) Size() int64 {
+	if v.data.Size == nil {
+		return 0
+	}
+	return *v.data.Size
+}
+
+func (v UploadClient) Finish(ctx context.Context, id string, digest []byte) (*UploadClientFinishResults, error) {
+	args := UploadFinishArgs{}
+	args.data.Id = &id
+	args.data.Digest = &digest
+
+	var ret uploadFinishResultsData
+
+	err := v.Call(ctx, "finish", &args, &ret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UploadClientFinishResults{client: v.Client, data: ret}, nil
+}
@@ -0,0 +1,374 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+//go:generate go run ../cmd/rpcgen/main.go -pkg upload -input upload.yml -output upload.gen.go
+
+var (
+	ErrChecksum       = errors.New("chunk checksum mismatch")
+	ErrDigestMismatch = errors.New("upload digest mismatch")
+	ErrOffset         = errors.New("chunk is not at the acked offset")
+	ErrSize           = errors.New("upload size mismatch")
+	ErrUnknown        = errors.New("unknown upload")
+)
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// Checksum is the checksum sent with each chunk, CRC-32C of its data.
+func Checksum(data []byte) uint32 {
+	return crc32.Checksum(data, castagnoli)
+}
+
+type partial struct {
+	mu     sync.Mutex
+	f      *os.File
+	size   int64
+	offset int64
+	closed bool
+}
+
+// Server receives uploads into a directory. Data is appended to
+// <id>.partial as chunks arrive, and each chunk is acked with the offset
+// the upload has reached. Because the partial file survives disconnects,
+// calling Begin again returns that offset and the client carries on from
+// there. Once Finish has checked the whole upload against its digest it is
+// renamed to <id>.
+type Server struct {
+	dir string
+
+	mu       sync.Mutex
+	partials map[string]*partial
+}
+
+var _ Upload = (*Server)(nil)
+
+func NewServer(dir string) *Server {
+	return &Server{
+		dir:      dir,
+		partials: make(map[string]*partial),
+	}
+}
+
+// Path returns where the upload with the given id is stored once finished.
+func (s *Server) Path(id string) string {
+	return filepath.Join(s.dir, id)
+}
+
+func (s *Server) partialPath(id string) string {
+	return filepath.Join(s.dir, id+".partial")
+}
+
+func checkId(id string) error {
+	if id == "" || id == "." || id == ".." || filepath.Base(id) != id {
+		return fmt.Errorf("invalid upload id: %q", id)
+	}
+
+	return nil
+}
+
+// lock returns the partial upload id with its lock held.
+func (s *Server) lock(id string) (*partial, error) {
+	s.mu.Lock()
+	p, ok := s.partials[id]
+	s.mu.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknown, id)
+	}
+
+	p.mu.Lock()
+
+	if p.closed {
+		p.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrUnknown, id)
+	}
+
+	return p, nil
+}
+
+// open returns the partial upload id, opening its file if it isn't open
+// already.
+func (s *Server) open(id string, size int64) (*partial, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if p, ok := s.partials[id]; ok {
+		return p, nil
+	}
+
+	f, err := os.OpenFile(s.partialPath(id), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	p := &partial{f: f, size: size, offset: fi.Size()}
+	s.partials[id] = p
+
+	return p, nil
+}
+
+func (s *Server) Begin(ctx context.Context, state *UploadBegin) error {
+	args := state.Args()
+
+	id := args.Id()
+	if err := checkId(id); err != nil {
+		return err
+	}
+
+	if args.Size() < 0 {
+		return fmt.Errorf("%w: negative size %d", ErrSize, args.Size())
+	}
+
+	var p *partial
+
+	for {
+		var err error
+
+		p, err = s.open(id, args.Size())
+		if err != nil {
+			return err
+		}
+
+		p.mu.Lock()
+
+		if !p.closed {
+			break
+		}
+
+		// Finish closed it after we looked it up, so open it again
+		p.mu.Unlock()
+	}
+
+	defer p.mu.Unlock()
+
+	// A partial left by an upload of a different size can't be resumed
+	if p.size != args.Size() || p.offset > p.size {
+		if err := p.f.Truncate(0); err != nil {
+			return err
+		}
+
+		p.size = args.Size()
+		p.offset = 0
+	}
+
+	state.Results().SetOffset(p.offset)
+
+	return nil
+}
+
+func (s *Server) Write(ctx context.Context, state *UploadWrite) error {
+	args := state.Args()
+
+	p, err := s.lock(args.Id())
+	if err != nil {
+		return err
+	}
+
+	defer p.mu.Unlock()
+
+	data := args.Data()
+
+	if args.Offset() != p.offset {
+		return fmt.Errorf("%w: chunk at %d, acked %d", ErrOffset, args.Offset(), p.offset)
+	}
+
+	if p.offset+int64(len(data)) > p.size {
+		return fmt.Errorf("%w: chunk ends at %d, upload is %d bytes", ErrSize, p.offset+int64(len(data)), p.size)
+	}
+
+	if Checksum(data) != args.Checksum() {
+		return fmt.Errorf("%w: chunk at %d", ErrChecksum, args.Offset())
+	}
+
+	if _, err := p.f.WriteAt(data, p.offset); err != nil {
+		// Drop whatever part of the chunk made it, so the partial file
+		// only ever holds acked data
+		p.f.Truncate(p.offset)
+		return err
+	}
+
+	p.offset += int64(len(data))
+
+	state.Results().SetOffset(p.offset)
+
+	return nil
+}
+
+func (s *Server) Finish(ctx context.Context, state *UploadFinish) error {
+	args := state.Args()
+
+	id := args.Id()
+
+	p, err := s.lock(id)
+	if err != nil {
+		return err
+	}
+
+	defer p.mu.Unlock()
+
+	if p.offset != p.size {
+		return fmt.Errorf("%w: received %d of %d bytes", ErrSize, p.offset, p.size)
+	}
+
+	h := sha256.New()
+
+	if _, err := io.Copy(h, io.NewSectionReader(p.f, 0, p.size)); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	delete(s.partials, id)
+	s.mu.Unlock()
+
+	p.closed = true
+	p.f.Close()
+
+	if !bytes.Equal(h.Sum(nil), args.Digest()) {
+		// The data is bad somewhere, so start over rather than resume
+		os.Remove(s.partialPath(id))
+		return ErrDigestMismatch
+	}
+
+	if err := os.Rename(s.partialPath(id), s.Path(id)); err != nil {
+		return err
+	}
+
+	state.Results().SetSize(p.size)
+
+	return nil
+}
+
+const DefaultChunkSize = 1024 * 1024
+
+// Options controls Send.
+type Options struct {
+	// ChunkSize is how much data is sent per call, DefaultChunkSize if 0.
+	ChunkSize int
+
+	// Retries is how many attempts in a row may fail without the server
+	// acking any more data before Send gives up.
+	Retries int
+
+	// Progress, if set, is called each time the server acks a chunk.
+	Progress func(acked, size int64)
+}
+
+// Send uploads size bytes from r to the server as id. It connects with
+// connect, and when a call fails it connects again and resumes from the
+// offset the server last acked, so a dropped connection only costs the
+// chunk that was in flight. Once all the data is acked the server checks it
+// against the SHA-256 of r. Each client connect returns is closed once the
+// attempt using it is over.
+func Send(ctx context.Context, connect func(ctx context.Context) (*UploadClient, error), id string, r io.ReaderAt, size int64, opts Options) error {
+	chunkSize := opts.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+
+	h := sha256.New()
+
+	if _, err := io.Copy(h, io.NewSectionReader(r, 0, size)); err != nil {
+		return fmt.Errorf("hashing upload: %w", err)
+	}
+
+	digest := h.Sum(nil)
+
+	buf := make([]byte, chunkSize)
+
+	var (
+		failures int
+		lastErr  error
+	)
+
+	acked := int64(-1)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if failures > opts.Retries {
+			return fmt.Errorf("upload %s failed after %d attempts: %w", id, failures, lastErr)
+		}
+
+		done, offset, err := sendOnce(ctx, connect, id, r, size, digest, buf, opts.Progress)
+		if done {
+			return nil
+		}
+
+		// A failure that got more data acked is progress, not a retry
+		if offset > acked {
+			acked = offset
+			failures = 0
+		}
+
+		failures++
+		lastErr = err
+	}
+}
+
+// sendOnce makes one attempt at the upload over a fresh connection,
+// returning how far the server had acked when it stopped.
+func sendOnce(
+	ctx context.Context,
+	connect func(ctx context.Context) (*UploadClient, error),
+	id string, r io.ReaderAt, size int64, digest, buf []byte,
+	progress func(acked, size int64),
+) (bool, int64, error) {
+	uc, err := connect(ctx)
+	if err != nil {
+		return false, -1, err
+	}
+
+	defer uc.Close()
+
+	res, err := uc.Begin(ctx, id, size)
+	if err != nil {
+		return false, -1, err
+	}
+
+	offset := res.Offset()
+
+	for offset < size {
+		n, err := r.ReadAt(buf[:min(int64(len(buf)), size-offset)], offset)
+		if err != nil && !(errors.Is(err, io.EOF) && int64(n) == size-offset) {
+			return false, offset, err
+		}
+
+		data := buf[:n]
+
+		wr, err := uc.Write(ctx, id, offset, data, Checksum(data))
+		if err != nil {
+			return false, offset, err
+		}
+
+		offset = wr.Offset()
+
+		if progress != nil {
+			progress(offset, size)
+		}
+	}
+
+	if _, err := uc.Finish(ctx, id, digest); err != nil {
+		return false, offset, err
+	}
+
+	return true, offset, nil
+}
@@ -0,0 +1,190 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	rpc "miren.dev/runtime/pkg/rpc"
+)
+
+// flakyClient drops the connection after the write call that brings the
+// upload to dropAt, once the server has stored the chunk but before its ack
+// is seen.
+type flakyClient struct {
+	rpc.Client
+
+	dropAt int64
+	drop   *sync.Once
+
+	writes *[]int64
+}
+
+var errDropped = errors.New("connection dropped")
+
+func (f *flakyClient) Call(ctx context.Context, method string, args, result any) error {
+	if method == "write" {
+		*f.writes = append(*f.writes, args.(*UploadWriteArgs).Offset())
+	}
+
+	err := f.Client.Call(ctx, method, args, result)
+	if err != nil || method != "write" {
+		return err
+	}
+
+	dropped := false
+
+	if res := result.(*uploadWriteResultsData); res.Offset != nil && *res.Offset >= f.dropAt {
+		f.drop.Do(func() { dropped = true })
+	}
+
+	if dropped {
+		return errDropped
+	}
+
+	return nil
+}
+
+func TestSend(t *testing.T) {
+	ctx := context.Background()
+
+	const (
+		size      = 10*1024*1024 + 123
+		chunkSize = 256 * 1024
+	)
+
+	setup := func(t *testing.T) (*Server, func(ctx context.Context) (*rpc.NetworkClient, error)) {
+		r := require.New(t)
+
+		srv := NewServer(t.TempDir())
+
+		ss, err := rpc.NewState(ctx, rpc.WithSkipVerify)
+		r.NoError(err)
+
+		ss.Server().ExposeValue("upload", AdaptUpload(srv))
+
+		cs, err := rpc.NewState(ctx, rpc.WithSkipVerify)
+		r.NoError(err)
+
+		return srv, func(ctx context.Context) (*rpc.NetworkClient, error) {
+			return cs.Connect(ss.ListenAddr(), "upload")
+		}
+	}
+
+	blob := make([]byte, size)
+	_, err := rand.Read(blob)
+	require.NoError(t, err)
+
+	t.Run("resumes from the acked offset after a disconnect", func(t *testing.T) {
+		r := require.New(t)
+
+		srv, dial := setup(t)
+
+		var (
+			writes   []int64
+			drop     sync.Once
+			connects int
+		)
+
+		connect := func(ctx context.Context) (*UploadClient, error) {
+			connects++
+
+			c, err := dial(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			return NewUploadClient(&flakyClient{
+				Client: c,
+				dropAt: size / 2,
+				drop:   &drop,
+				writes: &writes,
+			}), nil
+		}
+
+		err := Send(ctx, connect, "blob", bytes.NewReader(blob), size, Options{
+			ChunkSize: chunkSize,
+			Retries:   1,
+		})
+		r.NoError(err)
+
+		r.Equal(2, connects)
+
+		// The chunk whose ack was lost was stored, so the second connection
+		// carries on after it instead of sending it again or starting over
+		for i := 1; i < len(writes); i++ {
+			r.Equal(writes[i-1]+chunkSize, writes[i], "write %d", i)
+		}
+
+		r.Len(writes, (size+chunkSize-1)/chunkSize)
+
+		got, err := os.ReadFile(srv.Path("blob"))
+		r.NoError(err)
+		r.True(bytes.Equal(blob, got), "uploaded blob differs")
+
+		_, err = os.Stat(srv.partialPath("blob"))
+		r.True(os.IsNotExist(err))
+	})
+
+	t.Run("rejects a chunk with a bad checksum", func(t *testing.T) {
+		r := require.New(t)
+
+		_, dial := setup(t)
+
+		c, err := dial(ctx)
+		r.NoError(err)
+
+		uc := NewUploadClient(c)
+
+		res, err := uc.Begin(ctx, "blob", 8)
+		r.NoError(err)
+		r.Equal(int64(0), res.Offset())
+
+		data := []byte("abcdefgh")
+
+		_, err = uc.Write(ctx, "blob", 0, data, Checksum(data)+1)
+		r.Error(err)
+
+		res, err = uc.Begin(ctx, "blob", 8)
+		r.NoError(err)
+		r.Equal(int64(0), res.Offset(), "the bad chunk shouldn't be acked")
+
+		wr, err := uc.Write(ctx, "blob", 0, data, Checksum(data))
+		r.NoError(err)
+		r.Equal(int64(8), wr.Offset())
+	})
+
+	t.Run("fails the final verify on a digest mismatch", func(t *testing.T) {
+		r := require.New(t)
+
+		srv, dial := setup(t)
+
+		c, err := dial(ctx)
+		r.NoError(err)
+
+		uc := NewUploadClient(c)
+
+		data := []byte("abcdefgh")
+
+		_, err = uc.Begin(ctx, "blob", 8)
+		r.NoError(err)
+
+		_, err = uc.Write(ctx, "blob", 0, data, Checksum(data))
+		r.NoError(err)
+
+		_, err = uc.Finish(ctx, "blob", make([]byte, 32))
+		r.Error(err)
+
+		_, err = os.Stat(srv.Path("blob"))
+		r.True(os.IsNotExist(err))
+
+		res, err := uc.Begin(ctx, "blob", 8)
+		r.NoError(err)
+		r.Equal(int64(0), res.Offset(), "a failed upload starts over")
+	})
+}
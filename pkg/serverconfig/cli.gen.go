@@ -5,44 +5,50 @@ package serverconfig
 // CLIFlags represents command-line flags for server configuration
 // All fields are pointers to distinguish between set and unset values
 type CLIFlags struct {
-	BuildkitConfigGcKeepDuration         *string  `long:"buildkit-gc-duration" description:"How long to keep BuildKit cache entries (e.g., 7d, 24h)"`
-	BuildkitConfigGcKeepStorage          *string  `long:"buildkit-gc-storage" description:"Maximum BuildKit layer cache size (e.g., 10GB, 50GB)"`
-	BuildkitConfigSocketDir              *string  `long:"buildkit-socket-dir" description:"Directory for embedded BuildKit Unix socket (defaults to data_path/buildkit/socket)"`
-	BuildkitConfigSocketPath             *string  `long:"buildkit-socket" description:"Path to external BuildKit Unix socket (for distributed mode)"`
-	BuildkitConfigStartEmbedded          *bool    `long:"start-buildkit" description:"Start embedded BuildKit daemon for container image builds"`
-	ConfigFile                           *string  `long:"config" description:"Path to configuration file"`
-	Mode                                 *string  `long:"mode" short:"m" description:"Server mode: standalone (default), distributed (experimental)"`
-	ContainerdConfigBinaryPath           *string  `long:"containerd-binary" description:"Path to containerd binary"`
-	ContainerdConfigSocketPath           *string  `long:"containerd-socket" description:"Path to containerd socket"`
-	ContainerdConfigStartEmbedded        *bool    `long:"start-containerd" description:"Start embedded containerd daemon"`
-	EtcdConfigClientPort                 *int     `long:"etcd-client-port" description:"Etcd client port"`
-	EtcdConfigEndpoints                  []string `long:"etcd" short:"e" description:"Etcd endpoints"`
-	EtcdConfigHTTPClientPort             *int     `long:"etcd-http-client-port" description:"Etcd HTTP client port"`
-	EtcdConfigPeerPort                   *int     `long:"etcd-peer-port" description:"Etcd peer port"`
-	EtcdConfigPrefix                     *string  `long:"etcd-prefix" short:"p" description:"Etcd prefix"`
-	EtcdConfigStartEmbedded              *bool    `long:"start-etcd" description:"Start embedded etcd server"`
-	ServerConfigAddress                  *string  `long:"address" short:"a" description:"Address to listen on (host:port). For IPv6 use brackets, e.g. \"[::1]:8443\"."`
-	ServerConfigConfigClusterName        *string  `long:"config-cluster-name" short:"C" description:"Name of the cluster in client config"`
-	ServerConfigDataPath                 *string  `long:"data-path" short:"d" description:"Data path"`
-	ServerConfigHTTPRequestTimeout       *int     `long:"http-request-timeout" description:"HTTP request timeout in seconds"`
-	ServerConfigReleasePath              *string  `long:"release-path" description:"Path to release directory containing binaries"`
-	ServerConfigRunnerAddress            *string  `long:"runner-address" description:"Runner address (host:port). For IPv6 use brackets, e.g. \"[::1]:8444\"."`
-	ServerConfigRunnerID                 *string  `long:"runner-id" short:"r" description:"Runner ID"`
-	ServerConfigSkipClientConfig         *bool    `long:"skip-client-config" description:"Skip writing client config file to clientconfig.d"`
-	ServerConfigStopSandboxesOnShutdown  *bool    `long:"stop-sandboxes-on-shutdown" description:"Stop all sandboxes when server shuts down (useful in development)"`
-	TLSConfigAcmeDNSProvider             *string  `long:"acme-dns-provider" description:"DNS provider for ACME DNS-01 challenges (e.g., cloudflare, route53, exec). When set, uses DNS challenge instead of HTTP challenge. See https://go-acme.github.io/lego/dns/ for available providers."`
-	TLSConfigAcmeEmail                   *string  `long:"acme-email" description:"Email address for ACME account registration (recommended for account recovery and notifications)"`
-	TLSConfigAdditionalIPs               []string `long:"ips" description:"Additional IPs assigned to the server cert"`
-	TLSConfigAdditionalNames             []string `long:"dns-names" description:"Additional DNS names assigned to the server cert"`
-	TLSConfigStandardTLS                 *bool    `long:"serve-tls" description:"Expose the http ingress on standard TLS ports"`
-	VictoriaLogsConfigAddress            *string  `long:"victorialogs-addr" description:"VictoriaLogs address (when not using embedded)"`
-	VictoriaLogsConfigHTTPPort           *int     `long:"victorialogs-http-port" description:"VictoriaLogs HTTP port in embedded mode"`
-	VictoriaLogsConfigRetentionPeriod    *string  `long:"victorialogs-retention" description:"VictoriaLogs retention period (e.g. 30d, 2w, 1y)"`
-	VictoriaLogsConfigStartEmbedded      *bool    `long:"start-victorialogs" description:"Start embedded VictoriaLogs server"`
-	VictoriaMetricsConfigAddress         *string  `long:"victoriametrics-addr" description:"VictoriaMetrics address (when not using embedded)"`
-	VictoriaMetricsConfigHTTPPort        *int     `long:"victoriametrics-http-port" description:"VictoriaMetrics HTTP port in embedded mode"`
-	VictoriaMetricsConfigRetentionPeriod *string  `long:"victoriametrics-retention" description:"VictoriaMetrics retention period in months"`
-	VictoriaMetricsConfigStartEmbedded   *bool    `long:"start-victoriametrics" description:"Start embedded VictoriaMetrics server"`
+	BuildkitConfigGcKeepDuration         *string   `long:"buildkit-gc-duration" description:"How long to keep BuildKit cache entries (e.g., 7d, 24h)"`
+	BuildkitConfigGcKeepStorage          *ByteSize `long:"buildkit-gc-storage" description:"Maximum BuildKit layer cache size (e.g., 10GB, 50GiB)"`
+	BuildkitConfigSocketDir              *string   `long:"buildkit-socket-dir" description:"Directory for embedded BuildKit Unix socket (defaults to data_path/buildkit/socket)"`
+	BuildkitConfigSocketPath             *string   `long:"buildkit-socket" description:"Path to external BuildKit Unix socket (for distributed mode)"`
+	BuildkitConfigStartEmbedded          *bool     `long:"start-buildkit" description:"Start embedded BuildKit daemon for container image builds"`
+	ConfigFile                           *string   `long:"config" description:"Path to configuration file"`
+	Mode                                 *string   `long:"mode" short:"m" description:"Server mode: standalone (default), distributed (experimental)"`
+	ContainerdConfigBinaryPath           *string   `long:"containerd-binary" description:"Path to containerd binary"`
+	ContainerdConfigSocketPath           *string   `long:"containerd-socket" description:"Path to containerd socket"`
+	ContainerdConfigStartEmbedded        *bool     `long:"start-containerd" description:"Start embedded containerd daemon"`
+	EtcdConfigClientPort                 *int      `long:"etcd-client-port" description:"Etcd client port"`
+	EtcdConfigDeleteRetention            *Duration `long:"etcd-delete-retention" description:"How long deleted entities can be recovered before they're purged, such as 24h (0 deletes immediately)"`
+	EtcdConfigEndpoints                  []string  `long:"etcd" short:"e" description:"Etcd endpoints"`
+	EtcdConfigHTTPClientPort             *int      `long:"etcd-http-client-port" description:"Etcd HTTP client port"`
+	EtcdConfigPeerPort                   *int      `long:"etcd-peer-port" description:"Etcd peer port"`
+	EtcdConfigPrefix                     *string   `long:"etcd-prefix" short:"p" description:"Etcd prefix"`
+	EtcdConfigStartEmbedded              *bool     `long:"start-etcd" description:"Start embedded etcd server"`
+	ServerConfigAddress                  *string   `long:"address" short:"a" description:"Address to listen on (host:port). For IPv6 use brackets, e.g. \"[::1]:8443\"."`
+	ServerConfigConfigClusterName        *string   `long:"config-cluster-name" short:"C" description:"Name of the cluster in client config"`
+	ServerConfigDataPath                 *string   `long:"data-path" short:"d" description:"Data path"`
+	ServerConfigHTTPRequestTimeout       *Duration `long:"http-request-timeout" description:"HTTP request timeout, such as 30s or 2m"`
+	ServerConfigPreemptionNoticeURL      *string   `long:"preemption-notice-url" description:"URL polled for a spot termination notice, such as http://169.254.169.254/latest/meta-data/spot/instance-action. Sandboxes are moved off the node when one is posted."`
+	ServerConfigReleasePath              *string   `long:"release-path" description:"Path to release directory containing binaries"`
+	ServerConfigRunnerAddress            *string   `long:"runner-address" description:"Runner address (host:port). For IPv6 use brackets, e.g. \"[::1]:8444\"."`
+	ServerConfigRunnerID                 *string   `long:"runner-id" short:"r" description:"Runner ID"`
+	ServerConfigSandboxHostPathAllowlist []string  `long:"sandbox-host-path-allow" description:"Host path prefix that sandboxes may bind mount (repeatable, none are allowed by default)"`
+	ServerConfigSandboxSysctlAllowlist   []string  `long:"sandbox-sysctl-allow" description:"Sysctl that sandbox containers may set, in addition to the safe ones always allowed (repeatable)"`
+	ServerConfigSkipClientConfig         *bool     `long:"skip-client-config" description:"Skip writing client config file to clientconfig.d"`
+	ServerConfigStopSandboxesOnShutdown  *bool     `long:"stop-sandboxes-on-shutdown" description:"Stop all sandboxes when server shuts down (useful in development)"`
+	TLSConfigAcmeDNSProvider             *string   `long:"acme-dns-provider" description:"DNS provider for ACME DNS-01 challenges (e.g., cloudflare, route53, exec). When set, uses DNS challenge instead of HTTP challenge. See https://go-acme.github.io/lego/dns/ for available providers."`
+	TLSConfigAcmeEmail                   *string   `long:"acme-email" description:"Email address for ACME account registration (recommended for account recovery and notifications)"`
+	TLSConfigAdditionalIPs               []string  `long:"ips" description:"Additional IPs assigned to the server cert"`
+	TLSConfigAdditionalNames             []string  `long:"dns-names" description:"Additional DNS names assigned to the server cert"`
+	TLSConfigStandardTLS                 *bool     `long:"serve-tls" description:"Expose the http ingress on standard TLS ports"`
+	VictoriaLogsConfigAddress            *string   `long:"victorialogs-addr" description:"VictoriaLogs address (when not using embedded)"`
+	VictoriaLogsConfigArchiveAfter       *Duration `long:"victorialogs-archive-after" description:"How old logs must be before they're archived, such as 168h. Must be shorter than the retention period"`
+	VictoriaLogsConfigArchiveDir         *string   `long:"victorialogs-archive-dir" description:"Directory to archive aged logs into. Archived logs stay queryable after VictoriaLogs retention drops them (empty disables archiving)"`
+	VictoriaLogsConfigHTTPPort           *int      `long:"victorialogs-http-port" description:"VictoriaLogs HTTP port in embedded mode"`
+	VictoriaLogsConfigRetentionPeriod    *string   `long:"victorialogs-retention" description:"VictoriaLogs retention period (e.g. 30d, 2w, 1y)"`
+	VictoriaLogsConfigStartEmbedded      *bool     `long:"start-victorialogs" description:"Start embedded VictoriaLogs server"`
+	VictoriaMetricsConfigAddress         *string   `long:"victoriametrics-addr" description:"VictoriaMetrics address (when not using embedded)"`
+	VictoriaMetricsConfigHTTPPort        *int      `long:"victoriametrics-http-port" description:"VictoriaMetrics HTTP port in embedded mode"`
+	VictoriaMetricsConfigRetentionPeriod *string   `long:"victoriametrics-retention" description:"VictoriaMetrics retention period in months"`
+	VictoriaMetricsConfigStartEmbedded   *bool     `long:"start-victoriametrics" description:"Start embedded VictoriaMetrics server"`
 }
 
 // NewCLIFlags creates a new CLIFlags struct for parsing
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// Patterns for string formats that JSON Schema has no built-in format for.
+// They are written in the subset of syntax shared by Go and ECMAScript
+// regular expressions, since editors evaluate them as the latter.
+const (
+	// hostPortPattern approximates net.SplitHostPort. An empty string is
+	// allowed as the Go validation skips it.
+	hostPortPattern = `^$|^(\[[^\[\]]*\]|[^:\[\]]*):[^:\[\]]*$`
+
+	// durationPattern accepts what the loader's parseDuration does: Go
+	// duration syntax, or an integer number of seconds.
+	durationPattern = `^[-+]?(\d+|((\d+(\.\d*)?|\.\d+)(ns|us|µs|μs|ms|s|m|h))+)$`
+
+	// byteSizePattern accepts what the loader's parseByteSize does: an
+	// integer with an optional decimal or binary unit, in any case
+	byteSizePattern = `^\s*\d+ *([bB]|[kKmMgGtT][iI]?[bB])?\s*$`
+)
+
+// generateJSONSchema generates a JSON Schema describing a valid config file,
+// for editors and linters to check configs against
+func generateJSONSchema(schema *Schema) ([]byte, error) {
+	root, ok := schema.Configs["Config"]
+	if !ok {
+		return nil, fmt.Errorf("schema has no Config")
+	}
+
+	defs := map[string]any{}
+
+	for name, config := range schema.Configs {
+		if name == "Config" {
+			continue
+		}
+
+		def, err := jsonSchemaObject(config)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		defs[name] = def
+	}
+
+	doc, err := jsonSchemaObject(root)
+	if err != nil {
+		return nil, fmt.Errorf("Config: %w", err)
+	}
+
+	doc["$schema"] = jsonSchemaDraft
+	doc["title"] = "Miren server configuration"
+	doc["$defs"] = defs
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(data, '\n'), nil
+}
+
+// jsonSchemaObject describes the TOML table holding config
+func jsonSchemaObject(config *Config) (map[string]any, error) {
+	props := map[string]any{}
+	var conds []any
+
+	for fname, field := range config.Fields {
+		if field.CLIOnly || field.TOML == "" {
+			continue
+		}
+
+		if field.Nested {
+			props[field.TOML] = map[string]any{"$ref": "#/$defs/" + field.Type}
+			continue
+		}
+
+		if elem := field.ListOf(); elem != "" {
+			props[field.TOML] = map[string]any{
+				"type":  "array",
+				"items": map[string]any{"$ref": "#/$defs/" + elem},
+			}
+			continue
+		}
+
+		prop, err := jsonSchemaField(field)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fname, err)
+		}
+		props[field.TOML] = prop
+
+		// Fields marked required aren't listed as such, since the
+		// environment or flags may set them instead of the file
+		if len(field.RequiredWhen) > 0 {
+			cond, err := jsonSchemaRequiredWhen(config, field)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", fname, err)
+			}
+			conds = append(conds, cond)
+		}
+	}
+
+	obj := map[string]any{
+		"type":                 "object",
+		"properties":           props,
+		"additionalProperties": false,
+	}
+
+	if config.Description != "" {
+		obj["description"] = config.Description
+	}
+
+	switch len(conds) {
+	case 0:
+	case 1:
+		for k, v := range conds[0].(map[string]any) {
+			obj[k] = v
+		}
+	default:
+		obj["allOf"] = conds
+	}
+
+	return obj, nil
+}
+
+// jsonSchemaField describes the value of a single field
+func jsonSchemaField(field *Field) (map[string]any, error) {
+	prop := map[string]any{}
+
+	if field.CLI != nil && field.CLI.Description != "" {
+		prop["description"] = field.CLI.Description
+	}
+
+	if field.Default != nil {
+		prop["default"] = field.Default
+	}
+
+	v := field.Validation
+	if v == nil {
+		v = &Validation{}
+	}
+
+	switch field.Type {
+	case "string":
+		prop["type"] = "string"
+
+		if len(v.Enum) > 0 {
+			prop["enum"] = v.Enum
+		}
+
+		switch {
+		case v.Format == "host:port":
+			prop["pattern"] = hostPortPattern
+		case v.Regex != "":
+			// An empty value skips the regex check in Go
+			prop["pattern"] = "^$|" + v.Regex
+		}
+	case "int":
+		prop["type"] = "integer"
+
+		if v.Port {
+			prop["minimum"] = 1
+			prop["maximum"] = 65535
+		}
+		if v.Min != nil {
+			prop["minimum"] = *v.Min
+		}
+		if v.Max != nil {
+			prop["maximum"] = *v.Max
+		}
+	case "bool":
+		prop["type"] = "boolean"
+	case "[]string":
+		items := map[string]any{"type": "string"}
+		if v.Format == "ip_list" {
+			items["anyOf"] = []any{
+				map[string]any{"format": "ipv4"},
+				map[string]any{"format": "ipv6"},
+			}
+		}
+
+		prop["type"] = "array"
+		prop["items"] = items
+	case "duration":
+		// Bounds can only be checked on the integer form, as JSON Schema
+		// can't compare duration strings
+		secs := map[string]any{"type": "integer"}
+		if v.MinDuration != "" {
+			d, err := time.ParseDuration(v.MinDuration)
+			if err != nil {
+				return nil, fmt.Errorf("invalid min_duration: %w", err)
+			}
+			secs["minimum"] = int64((d + time.Second - 1) / time.Second)
+		}
+		if v.MaxDuration != "" {
+			d, err := time.ParseDuration(v.MaxDuration)
+			if err != nil {
+				return nil, fmt.Errorf("invalid max_duration: %w", err)
+			}
+			secs["maximum"] = int64(d / time.Second)
+		}
+
+		prop["anyOf"] = []any{
+			map[string]any{"type": "string", "pattern": durationPattern},
+			secs,
+		}
+	case "bytesize":
+		bytes := map[string]any{"type": "integer", "minimum": 0}
+		if v.Min != nil {
+			bytes["minimum"] = *v.Min
+		}
+		if v.Max != nil {
+			bytes["maximum"] = *v.Max
+		}
+
+		prop["anyOf"] = []any{
+			map[string]any{"type": "string", "pattern": byteSizePattern},
+			bytes,
+		}
+	default:
+		return nil, fmt.Errorf("unsupported type %q", field.Type)
+	}
+
+	return prop, nil
+}
+
+// jsonSchemaRequiredWhen expresses a required_when rule as an if/then pair.
+// The condition only holds when the file sets the fields explicitly, as
+// defaults and the other config sources aren't visible to the schema.
+func jsonSchemaRequiredWhen(config *Config, field *Field) (map[string]any, error) {
+	var names []string
+	for name := range field.RequiredWhen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	props := map[string]any{}
+	var required []string
+
+	for _, name := range names {
+		other, ok := config.Fields[name]
+		if !ok {
+			return nil, fmt.Errorf("required_when refers to unknown field %q", name)
+		}
+
+		props[other.TOML] = map[string]any{"const": field.RequiredWhen[name]}
+		required = append(required, other.TOML)
+	}
+
+	then := map[string]any{"required": []string{field.TOML}}
+	switch field.Type {
+	case "[]string":
+		then["properties"] = map[string]any{field.TOML: map[string]any{"minItems": 1}}
+	case "string":
+		then["properties"] = map[string]any{field.TOML: map[string]any{"minLength": 1}}
+	}
+
+	return map[string]any{
+		"if": map[string]any{
+			"properties": props,
+			"required":   required,
+		},
+		"then": then,
+	}, nil
+}
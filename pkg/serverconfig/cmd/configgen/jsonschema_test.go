@@ -0,0 +1,443 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+func loadTestSchema(t *testing.T) *Schema {
+	t.Helper()
+
+	data, err := os.ReadFile("../../schema.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var schema Schema
+	if err := yaml.Unmarshal(data, &schema); err != nil {
+		t.Fatal(err)
+	}
+
+	return &schema
+}
+
+func TestJSONSchemaUpToDate(t *testing.T) {
+	generated, err := generateJSONSchema(loadTestSchema(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	committed, err := os.ReadFile("../../config.schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(generated) != string(committed) {
+		t.Error("config.schema.json is out of date, run go generate ./pkg/serverconfig")
+	}
+}
+
+func TestJSONSchemaDefinesEveryConfig(t *testing.T) {
+	schema := loadTestSchema(t)
+
+	data, err := generateJSONSchema(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var root struct {
+		Schema     string                    `json:"$schema"`
+		Properties map[string]map[string]any `json:"properties"`
+		Defs       map[string]any            `json:"$defs"`
+	}
+	if err := json.Unmarshal(data, &root); err != nil {
+		t.Fatalf("generated schema doesn't parse: %v", err)
+	}
+
+	if root.Schema != jsonSchemaDraft {
+		t.Errorf("$schema is %q, want %q", root.Schema, jsonSchemaDraft)
+	}
+
+	for name := range schema.Configs {
+		if name == "Config" {
+			continue
+		}
+
+		if _, ok := root.Defs[name]; !ok {
+			t.Errorf("$defs is missing %s", name)
+		}
+	}
+
+	for fname, field := range schema.Configs["Config"].Fields {
+		if !field.Nested || field.TOML == "" {
+			continue
+		}
+
+		prop, ok := root.Properties[field.TOML]
+		if !ok {
+			t.Errorf("properties is missing %s for %s", field.TOML, fname)
+			continue
+		}
+
+		if want := "#/$defs/" + field.Type; prop["$ref"] != want {
+			t.Errorf("%s refers to %v, want %s", field.TOML, prop["$ref"], want)
+		}
+	}
+}
+
+func TestJSONSchemaValidation(t *testing.T) {
+	data, err := generateJSONSchema(loadTestSchema(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var root map[string]any
+	if err := json.Unmarshal(data, &root); err != nil {
+		t.Fatal(err)
+	}
+
+	validate := func(t *testing.T, config string) []string {
+		t.Helper()
+
+		var doc map[string]any
+		if err := toml.Unmarshal([]byte(config), &doc); err != nil {
+			t.Fatal(err)
+		}
+
+		v := &jsonSchemaValidator{root: root}
+		v.validate("", root, doc)
+		return v.errs
+	}
+
+	t.Run("example config is valid", func(t *testing.T) {
+		example, err := os.ReadFile("../../../../configs/server.toml.example")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if errs := validate(t, string(example)); len(errs) != 0 {
+			t.Errorf("expected no errors, got:\n%s", strings.Join(errs, "\n"))
+		}
+	})
+
+	t.Run("known-good config is valid", func(t *testing.T) {
+		errs := validate(t, `
+mode = "distributed"
+
+[server]
+address = "[::1]:8443"
+http_request_timeout = 90
+
+[tls]
+additional_ips = ["10.0.0.1", "fd00::1"]
+
+[etcd]
+start_embedded = false
+endpoints = ["http://etcd:2379"]
+
+[victorialogs]
+retention_period = "2w"
+
+[buildkit]
+gc_keep_storage = "512 MiB"
+`)
+		if len(errs) != 0 {
+			t.Errorf("expected no errors, got:\n%s", strings.Join(errs, "\n"))
+		}
+	})
+
+	t.Run("known-bad config is rejected", func(t *testing.T) {
+		errs := validate(t, `
+mode = "clustered"
+unknown = true
+
+[server]
+address = "no-port"
+http_request_timeout = "soon"
+
+[tls]
+additional_ips = ["not-an-ip"]
+
+[etcd]
+start_embedded = false
+client_port = 70000
+peer_port = "12380"
+
+[victorialogs]
+retention_period = "30 days"
+
+[buildkit]
+gc_keep_storage = "10 gigs"
+`)
+
+		want := []string{
+			"/mode",
+			"/unknown",
+			"/server/address",
+			"/server/http_request_timeout",
+			"/tls/additional_ips/0",
+			"/etcd/endpoints",
+			"/etcd/client_port",
+			"/etcd/peer_port",
+			"/victorialogs/retention_period",
+			"/buildkit/gc_keep_storage",
+		}
+
+		for _, path := range want {
+			found := false
+			for _, err := range errs {
+				if strings.HasPrefix(err, path+":") {
+					found = true
+					break
+				}
+			}
+			if !found {
+				t.Errorf("expected an error at %s, got:\n%s", path, strings.Join(errs, "\n"))
+			}
+		}
+
+		if len(errs) != len(want) {
+			t.Errorf("expected %d errors, got %d:\n%s", len(want), len(errs), strings.Join(errs, "\n"))
+		}
+	})
+}
+
+func TestJSONSchemaConfigLists(t *testing.T) {
+	data, err := generateJSONSchema(loadListSchema(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var root map[string]any
+	if err := json.Unmarshal(data, &root); err != nil {
+		t.Fatal(err)
+	}
+
+	validate := func(t *testing.T, config string) []string {
+		t.Helper()
+
+		var doc map[string]any
+		if err := toml.Unmarshal([]byte(config), &doc); err != nil {
+			t.Fatal(err)
+		}
+
+		v := &jsonSchemaValidator{root: root}
+		v.validate("", root, doc)
+		return v.errs
+	}
+
+	tests := []struct {
+		name   string
+		config string
+		want   []string
+	}{
+		{
+			name:   "empty",
+			config: ``,
+		},
+		{
+			name: "single",
+			config: `
+[[listener]]
+name = "public"
+address = "0.0.0.0:443"
+idle_timeout = "2m"
+`,
+		},
+		{
+			name: "multiple",
+			config: `
+[[listener]]
+name = "public"
+address = "0.0.0.0:443"
+
+[[listener]]
+name = "internal"
+address = "10.0.0.1:80"
+protocol = "http"
+
+[[server.route]]
+prefix = "/api"
+timeout = 30
+`,
+		},
+		{
+			name: "invalid elements",
+			config: `
+[[listener]]
+name = "public"
+
+[[listener]]
+name = "internal"
+protocol = "gopher"
+idle_timeout = "forever"
+
+[[server.route]]
+prefix = "/api"
+port = 80
+`,
+			want: []string{
+				"/listener/1/protocol",
+				"/listener/1/idle_timeout",
+				"/server/route/0/port",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validate(t, tt.config)
+
+			for _, path := range tt.want {
+				found := false
+				for _, err := range errs {
+					found = found || strings.HasPrefix(err, path+":")
+				}
+				if !found {
+					t.Errorf("expected an error at %s, got:\n%s", path, strings.Join(errs, "\n"))
+				}
+			}
+
+			if len(errs) != len(tt.want) {
+				t.Errorf("expected %d errors, got %d:\n%s", len(tt.want), len(errs), strings.Join(errs, "\n"))
+			}
+		})
+	}
+}
+
+// jsonSchemaValidator checks a document against the subset of JSON Schema
+// that generateJSONSchema emits
+type jsonSchemaValidator struct {
+	root map[string]any
+	errs []string
+}
+
+func (v *jsonSchemaValidator) errorf(path, format string, args ...any) {
+	v.errs = append(v.errs, path+": "+fmt.Sprintf(format, args...))
+}
+
+// matches reports whether value is valid against schema without recording
+// any errors
+func (v *jsonSchemaValidator) matches(path string, schema map[string]any, value any) bool {
+	sub := &jsonSchemaValidator{root: v.root}
+	sub.validate(path, schema, value)
+	return len(sub.errs) == 0
+}
+
+func (v *jsonSchemaValidator) validate(path string, schema map[string]any, value any) {
+	if ref, ok := schema["$ref"].(string); ok {
+		name := strings.TrimPrefix(ref, "#/$defs/")
+		v.validate(path, v.root["$defs"].(map[string]any)[name].(map[string]any), value)
+		return
+	}
+
+	if typ, ok := schema["type"].(string); ok && !jsonSchemaIsType(typ, value) {
+		v.errorf(path, "expected %s, got %T", typ, value)
+		return
+	}
+
+	if c, ok := schema["const"]; ok && !reflect.DeepEqual(c, value) {
+		v.errorf(path, "expected %v", c)
+	}
+
+	if enum, ok := schema["enum"].([]any); ok {
+		found := false
+		for _, e := range enum {
+			found = found || reflect.DeepEqual(e, value)
+		}
+		if !found {
+			v.errorf(path, "%v is not one of %v", value, enum)
+		}
+	}
+
+	if anyOf, ok := schema["anyOf"].([]any); ok {
+		found := false
+		for _, s := range anyOf {
+			found = found || v.matches(path, s.(map[string]any), value)
+		}
+		if !found {
+			v.errorf(path, "%v matches none of the allowed forms", value)
+		}
+	}
+
+	if cond, ok := schema["if"].(map[string]any); ok && v.matches(path, cond, value) {
+		v.validate(path, schema["then"].(map[string]any), value)
+	}
+
+	switch value := value.(type) {
+	case string:
+		if pattern, ok := schema["pattern"].(string); ok && !regexp.MustCompile(pattern).MatchString(value) {
+			v.errorf(path, "%q does not match %s", value, pattern)
+		}
+		if min, ok := schema["minLength"].(float64); ok && float64(len(value)) < min {
+			v.errorf(path, "shorter than %v", min)
+		}
+		if format, ok := schema["format"].(string); ok {
+			ip := net.ParseIP(value)
+			if format == "ipv4" && (ip == nil || ip.To4() == nil) || format == "ipv6" && (ip == nil || ip.To4() != nil) {
+				v.errorf(path, "%q is not an %s address", value, format)
+			}
+		}
+	case int64:
+		if min, ok := schema["minimum"].(float64); ok && float64(value) < min {
+			v.errorf(path, "%d is less than %v", value, min)
+		}
+		if max, ok := schema["maximum"].(float64); ok && float64(value) > max {
+			v.errorf(path, "%d is greater than %v", value, max)
+		}
+	case []any:
+		if min, ok := schema["minItems"].(float64); ok && float64(len(value)) < min {
+			v.errorf(path, "fewer than %v items", min)
+		}
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range value {
+				v.validate(fmt.Sprintf("%s/%d", path, i), items, item)
+			}
+		}
+	case map[string]any:
+		if required, ok := schema["required"].([]any); ok {
+			for _, name := range required {
+				if _, ok := value[name.(string)]; !ok {
+					v.errorf(path+"/"+name.(string), "is required")
+				}
+			}
+		}
+
+		props, _ := schema["properties"].(map[string]any)
+		for name, val := range value {
+			prop, ok := props[name].(map[string]any)
+			if !ok {
+				if schema["additionalProperties"] == false {
+					v.errorf(path+"/"+name, "unknown property")
+				}
+				continue
+			}
+			v.validate(path+"/"+name, prop, val)
+		}
+	}
+}
+
+func jsonSchemaIsType(typ string, value any) bool {
+	switch value.(type) {
+	case string:
+		return typ == "string"
+	case int64:
+		return typ == "integer"
+	case bool:
+		return typ == "boolean"
+	case []any:
+		return typ == "array"
+	case map[string]any:
+		return typ == "object"
+	}
+	return false
+}
@@ -6,10 +6,14 @@ import (
 	"fmt"
 	"go/format"
 	"log"
+	"math"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"text/template"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -24,6 +28,10 @@ type Schema struct {
 	Package string             `yaml:"package"`
 	Imports []string           `yaml:"imports"`
 	Configs map[string]*Config `yaml:"configs"`
+
+	// EnvPrefix is joined with the env names of fields, and can be
+	// replaced at runtime with LoadWithEnvPrefix
+	EnvPrefix string `yaml:"env_prefix"`
 }
 
 // Config represents a configuration struct
@@ -43,6 +51,29 @@ type Field struct {
 	Nested      bool           `yaml:"nested"`
 	ModeDefault map[string]any `yaml:"mode_default"`
 	CLIOnly     bool           `yaml:"cli_only"`
+
+	// Required fields must be set by some source once all are applied
+	Required bool `yaml:"required"`
+
+	// RequiredWhen requires the field to be set when the named fields of
+	// the same config have the given values
+	RequiredWhen map[string]any `yaml:"required_when"`
+
+	// Sensitive fields hold secrets, and String redacts their values
+	Sensitive bool `yaml:"sensitive"`
+
+	// EnvPrefix is joined with Env in place of the schema's prefix. It is
+	// fixed, so it isn't replaced by LoadWithEnvPrefix.
+	EnvPrefix string `yaml:"env_prefix"`
+}
+
+// ListOf returns the config a list field holds, such as ListenerConfig for a
+// field of type []ListenerConfig, or "" if field isn't a list of configs
+func (f *Field) ListOf() string {
+	if f.Type == "[]string" || !strings.HasPrefix(f.Type, "[]") {
+		return ""
+	}
+	return strings.TrimPrefix(f.Type, "[]")
 }
 
 // CLIConfig represents CLI flag configuration
@@ -60,6 +91,236 @@ type Validation struct {
 	Min    *int     `yaml:"min"`
 	Max    *int     `yaml:"max"`
 	Port   bool     `yaml:"port"`
+
+	// MinDuration and MaxDuration bound duration fields, in Go duration syntax
+	MinDuration string `yaml:"min_duration"`
+	MaxDuration string `yaml:"max_duration"`
+}
+
+// HasDurations reports whether any stored field has the duration type
+func (s *Schema) HasDurations() bool {
+	return len(durationKeys(s)) > 0
+}
+
+// HasByteSizes reports whether any stored field has the bytesize type
+func (s *Schema) HasByteSizes() bool {
+	return len(byteSizeKeys(s)) > 0
+}
+
+// HasConfigLists reports whether any config has a list of configs
+func (s *Schema) HasConfigLists() bool {
+	for _, table := range configTables(s) {
+		if table.List {
+			return true
+		}
+	}
+	return false
+}
+
+// IsListElement reports whether the named config is held in a list
+func (s *Schema) IsListElement(name string) bool {
+	return configTables(s)[name].List
+}
+
+// configTable locates a config within Config
+type configTable struct {
+	// Path is the TOML table holding the config, such as "server"
+	Path string
+
+	// GoPath is the field holding the config, such as "Server"
+	GoPath string
+
+	// List is set for configs held in a list, when Path and GoPath name
+	// the list
+	List bool
+}
+
+// configTables returns where each config nested in Config is found. Configs
+// nest a single level below Config, or two for lists held by a nested
+// config.
+func configTables(schema *Schema) map[string]configTable {
+	tables := map[string]configTable{}
+
+	var walk func(cname string, parent configTable)
+	walk = func(cname string, parent configTable) {
+		config, ok := schema.Configs[cname]
+		if !ok {
+			return
+		}
+
+		var names []string
+		for fname := range config.Fields {
+			names = append(names, fname)
+		}
+		sort.Strings(names)
+
+		for _, fname := range names {
+			field := config.Fields[fname]
+			if field.CLIOnly || field.TOML == "" {
+				continue
+			}
+
+			table := configTable{
+				Path:   joinTablePath(parent.Path, field.TOML),
+				GoPath: joinTablePath(parent.GoPath, toGoName(fname)),
+			}
+
+			switch {
+			case field.Nested:
+				tables[field.Type] = table
+				walk(field.Type, table)
+			case field.ListOf() != "":
+				table.List = true
+				tables[field.ListOf()] = table
+			}
+		}
+	}
+	walk("Config", configTable{})
+
+	return tables
+}
+
+func joinTablePath(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
+
+// configList is a list of configs, for the loader to fill in defaults
+type configList struct {
+	GoPath string
+	Elem   string
+}
+
+// configLists returns every list of configs, ordered by their Go path
+func configLists(schema *Schema) []configList {
+	var lists []configList
+	for name, table := range configTables(schema) {
+		if table.List {
+			lists = append(lists, configList{GoPath: table.GoPath, Elem: name})
+		}
+	}
+
+	sort.Slice(lists, func(i, j int) bool {
+		return lists[i].GoPath < lists[j].GoPath
+	})
+
+	return lists
+}
+
+// checkConfigLists checks lists of configs are ones the templates support.
+// Lists are only read from the config file, so neither they nor the fields
+// of their elements may come from the environment or flags, and elements
+// hold plain fields only.
+func checkConfigLists(schema *Schema) error {
+	tables := configTables(schema)
+
+	for cname, config := range schema.Configs {
+		for fname, field := range config.Fields {
+			elem := field.ListOf()
+			if elem == "" {
+				continue
+			}
+
+			if _, ok := schema.Configs[elem]; !ok || elem == "Config" {
+				return fmt.Errorf("%s.%s: %s is not a config", cname, fname, elem)
+			}
+
+			if cname != "Config" && tables[cname].List {
+				return fmt.Errorf("%s.%s: lists can't hold lists", cname, fname)
+			}
+
+			if field.Env != "" || field.CLI != nil || field.Default != nil || field.Required || len(field.RequiredWhen) > 0 {
+				return fmt.Errorf("%s.%s: lists can only be set in the config file", cname, fname)
+			}
+		}
+	}
+
+	for cname, table := range tables {
+		if !table.List {
+			continue
+		}
+
+		for fname, field := range schema.Configs[cname].Fields {
+			if field.Nested || field.ListOf() != "" {
+				return fmt.Errorf("%s.%s: list elements can't hold tables", cname, fname)
+			}
+
+			if field.Env != "" || field.CLI != nil || field.CLIOnly || len(field.ModeDefault) > 0 {
+				return fmt.Errorf("%s.%s: fields of list elements can only be set in the config file", cname, fname)
+			}
+		}
+	}
+
+	return nil
+}
+
+// tomlKey locates a field in the TOML document
+type tomlKey struct {
+	Table string
+	Key   string
+}
+
+// durationKeys returns the TOML location of every duration field
+func durationKeys(schema *Schema) []tomlKey {
+	return tomlKeys(schema, "duration")
+}
+
+// byteSizeKeys returns the TOML location of every bytesize field
+func byteSizeKeys(schema *Schema) []tomlKey {
+	return tomlKeys(schema, "bytesize")
+}
+
+// tomlKeys returns the TOML location of every stored field of fieldType,
+// with top-level fields having an empty Table
+func tomlKeys(schema *Schema, fieldType string) []tomlKey {
+	tables := configTables(schema)
+
+	var keys []tomlKey
+	for cname, config := range schema.Configs {
+		for _, field := range config.Fields {
+			if field.Type != fieldType || field.CLIOnly || field.TOML == "" {
+				continue
+			}
+			keys = append(keys, tomlKey{Table: tables[cname].Path, Key: field.TOML})
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Table != keys[j].Table {
+			return keys[i].Table < keys[j].Table
+		}
+		return keys[i].Key < keys[j].Key
+	})
+
+	return keys
+}
+
+// joinEnvName joins an env name with a prefix, if there is one
+func joinEnvName(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "_" + name
+}
+
+// fullEnvName returns the environment variable a field is read from when
+// loading with the schema's prefix
+func fullEnvName(schema *Schema, field *Field) string {
+	if field.EnvPrefix != "" {
+		return joinEnvName(field.EnvPrefix, field.Env)
+	}
+	return joinEnvName(schema.EnvPrefix, field.Env)
+}
+
+// envNameExpr returns a Go expression for the environment variable a field
+// is read from, in terms of the envPrefix the loader was given
+func envNameExpr(field *Field) string {
+	if field.EnvPrefix != "" {
+		return strconv.Quote(joinEnvName(field.EnvPrefix, field.Env))
+	}
+	return fmt.Sprintf("envName(envPrefix, %q)", field.Env)
 }
 
 func main() {
@@ -80,6 +341,10 @@ func main() {
 		log.Fatalf("Failed to parse schema: %v", err)
 	}
 
+	if err := checkConfigLists(&schema); err != nil {
+		log.Fatalf("Invalid schema: %v", err)
+	}
+
 	// Create output directory if it doesn't exist
 	if err := os.MkdirAll(*outputDir, 0755); err != nil {
 		log.Fatalf("Failed to create output directory: %v", err)
@@ -97,6 +362,7 @@ func main() {
 		{"defaults.gen.go", defaultsTemplate, generateDefaults},
 		{"validation.gen.go", validationTemplate, generateValidation},
 		{"env.gen.go", envTemplate, generateEnv},
+		{"writer.gen.go", writerTemplate, generateWriter},
 	}
 
 	for _, gen := range generators {
@@ -122,12 +388,28 @@ func main() {
 
 		log.Printf("Generated %s", outPath)
 	}
+
+	jsonSchema, err := generateJSONSchema(&schema)
+	if err != nil {
+		log.Fatalf("Failed to generate config.schema.json: %v", err)
+	}
+
+	outPath := filepath.Join(*outputDir, "config.schema.json")
+	if err := os.WriteFile(outPath, jsonSchema, 0644); err != nil {
+		log.Fatalf("Failed to write config.schema.json: %v", err)
+	}
+
+	log.Printf("Generated %s", outPath)
 }
 
 // generateConfig generates the config structs
 func generateConfig(schema *Schema) (string, error) {
 	tmpl, err := template.New("config").Funcs(template.FuncMap{
-		"title": toGoName,
+		"goType": goTypeForField,
+		"title":  toGoName,
+		"fullEnv": func(field *Field) string {
+			return fullEnvName(schema, field)
+		},
 	}).Parse(configTemplate)
 	if err != nil {
 		return "", err
@@ -163,7 +445,17 @@ func generateCLI(schema *Schema) (string, error) {
 // generateLoader generates the loader code
 func generateLoader(schema *Schema) (string, error) {
 	tmpl, err := template.New("loader").Funcs(template.FuncMap{
-		"title": toGoName,
+		"title":        toGoName,
+		"durationKeys": durationKeys,
+		"byteSizeKeys": byteSizeKeys,
+		"configLists":  configLists,
+		"envName": func(cname, fname string) (string, error) {
+			config, ok := schema.Configs[cname]
+			if !ok || config.Fields[fname] == nil || config.Fields[fname].Env == "" {
+				return "", fmt.Errorf("%s has no env field %q", cname, fname)
+			}
+			return envNameExpr(config.Fields[fname]), nil
+		},
 	}).Parse(loaderTemplate)
 	if err != nil {
 		return "", err
@@ -198,7 +490,11 @@ func generateDefaults(schema *Schema) (string, error) {
 // generateValidation generates validation functions
 func generateValidation(schema *Schema) (string, error) {
 	tmpl, err := template.New("validation").Funcs(template.FuncMap{
-		"title": toGoName,
+		"title":        toGoName,
+		"durationExpr": durationExpr,
+		"required": func(cname, fname string, field *Field) (string, error) {
+			return requiredExpr(schema, cname, fname, field)
+		},
 	}).Parse(validationTemplate)
 	if err != nil {
 		return "", err
@@ -215,7 +511,11 @@ func generateValidation(schema *Schema) (string, error) {
 // generateEnv generates environment variable handling
 func generateEnv(schema *Schema) (string, error) {
 	tmpl, err := template.New("env").Funcs(template.FuncMap{
-		"title": toGoName,
+		"title":   toGoName,
+		"envName": envNameExpr,
+		"fullEnv": func(field *Field) string {
+			return fullEnvName(schema, field)
+		},
 	}).Parse(envTemplate)
 	if err != nil {
 		return "", err
@@ -229,6 +529,23 @@ func generateEnv(schema *Schema) (string, error) {
 	return buf.String(), nil
 }
 
+// generateWriter generates TOML serialization for the config structs
+func generateWriter(schema *Schema) (string, error) {
+	tmpl, err := template.New("writer").Funcs(template.FuncMap{
+		"title": toGoName,
+	}).Parse(writerTemplate)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, schema); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
 // Helper functions
 func toGoName(s string) string {
 	// Convert snake_case or kebab-case to PascalCase with proper Go initialisms
@@ -316,6 +633,17 @@ func escapeTagValue(s string) string {
 	return s
 }
 
+// goTypeForField maps a schema type to the Go type used to hold it
+func goTypeForField(fieldType string) string {
+	switch fieldType {
+	case "duration":
+		return "time.Duration"
+	case "bytesize":
+		return "ByteSize"
+	}
+	return fieldType
+}
+
 func goTypeForCLI(fieldType string) string {
 	// For CLI, we use pointers to distinguish set vs unset
 	switch fieldType {
@@ -325,6 +653,10 @@ func goTypeForCLI(fieldType string) string {
 		return "*int"
 	case "bool":
 		return "*bool"
+	case "duration":
+		return "*Duration" // parsed by Duration.UnmarshalFlag
+	case "bytesize":
+		return "*ByteSize" // parsed by ByteSize.UnmarshalFlag
 	case "[]string":
 		return "[]string" // Slices can be nil
 	default:
@@ -332,6 +664,190 @@ func goTypeForCLI(fieldType string) string {
 	}
 }
 
+// durationExpr renders a Go duration string as a readable constant expression,
+// such as "90s" becoming "90 * time.Second"
+func durationExpr(s string) (string, error) {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return "", err
+	}
+
+	units := []struct {
+		unit time.Duration
+		name string
+	}{
+		{time.Hour, "time.Hour"},
+		{time.Minute, "time.Minute"},
+		{time.Second, "time.Second"},
+		{time.Millisecond, "time.Millisecond"},
+		{time.Microsecond, "time.Microsecond"},
+	}
+
+	for _, u := range units {
+		if d != 0 && d%u.unit == 0 {
+			return fmt.Sprintf("%d * %s", d/u.unit, u.name), nil
+		}
+	}
+
+	return fmt.Sprintf("time.Duration(%d)", int64(d)), nil
+}
+
+// byteSizeUnits are the suffixes accepted in byte sizes, matched without
+// regard to case. The loader template accepts the same set.
+var byteSizeUnits = map[string]int64{
+	"":    1,
+	"b":   1,
+	"kb":  1e3,
+	"mb":  1e6,
+	"gb":  1e9,
+	"tb":  1e12,
+	"kib": 1 << 10,
+	"mib": 1 << 20,
+	"gib": 1 << 30,
+	"tib": 1 << 40,
+}
+
+// parseByteSize parses a byte size such as "256MB" or "1GiB"
+func parseByteSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	num := strings.TrimRight(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ ")
+
+	unit, ok := byteSizeUnits[strings.ToLower(strings.TrimSpace(s[len(num):]))]
+	if !ok {
+		return 0, fmt.Errorf("unknown unit in byte size %q", s)
+	}
+
+	n, err := strconv.ParseInt(num, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid byte size %q", s)
+	}
+	if n > math.MaxInt64/unit {
+		return 0, fmt.Errorf("byte size %q is too large", s)
+	}
+
+	return n * unit, nil
+}
+
+// byteSizeExpr renders a byte size string as a readable constant expression,
+// such as "10GB" becoming "10 * GB"
+func byteSizeExpr(s string) (string, error) {
+	n, err := parseByteSize(s)
+	if err != nil {
+		return "", err
+	}
+
+	units := []struct {
+		unit int64
+		name string
+	}{
+		{1 << 40, "TiB"},
+		{1e12, "TB"},
+		{1 << 30, "GiB"},
+		{1e9, "GB"},
+		{1 << 20, "MiB"},
+		{1e6, "MB"},
+		{1 << 10, "KiB"},
+		{1e3, "KB"},
+	}
+
+	for _, u := range units {
+		if n != 0 && n%u.unit == 0 {
+			return fmt.Sprintf("%d * %s", n/u.unit, u.name), nil
+		}
+	}
+
+	return fmt.Sprintf("ByteSize(%d)", n), nil
+}
+
+// requiredExpr renders the check for a field's required or required_when
+// rule. The error names the sources that could set the field.
+func requiredExpr(schema *Schema, cname, fname string, field *Field) (string, error) {
+	var conds, desc []string
+
+	if !field.Required {
+		var names []string
+		for name := range field.RequiredWhen {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		config := schema.Configs[cname]
+		for _, name := range names {
+			other, ok := config.Fields[name]
+			if !ok {
+				return "", fmt.Errorf("%s: required_when refers to unknown field %q", fname, name)
+			}
+
+			val := field.RequiredWhen[name]
+			goName := "c." + toGoName(name)
+			switch val {
+			case true:
+				conds = append(conds, fmt.Sprintf("%s != nil && *%s", goName, goName))
+			case false:
+				conds = append(conds, fmt.Sprintf("%s != nil && !*%s", goName, goName))
+			default:
+				conds = append(conds, fmt.Sprintf("%s != nil && *%s == %#v", goName, goName, val))
+			}
+			desc = append(desc, fmt.Sprintf("%s=%v", other.TOML, val))
+		}
+	}
+
+	goName := "c." + toGoName(fname)
+	switch field.Type {
+	case "[]string":
+		conds = append(conds, fmt.Sprintf("len(%s) == 0", goName))
+	case "string":
+		unset := fmt.Sprintf("%s == nil || *%s == \"\"", goName, goName)
+		if len(conds) > 0 {
+			unset = "(" + unset + ")"
+		}
+		conds = append(conds, unset)
+	default:
+		conds = append(conds, fmt.Sprintf("%s == nil", goName))
+	}
+
+	msg := fname + " is required"
+	if len(desc) > 0 {
+		msg = fmt.Sprintf("%s must be set when %s", fname, strings.Join(desc, ", "))
+	}
+	if sources := fieldSources(schema, cname, field); sources != "" {
+		msg += "; set it with " + sources
+	}
+
+	return fmt.Sprintf("if %s {\n\terrs.add(\"\", fmt.Errorf(%q))\n}", strings.Join(conds, " && "), msg), nil
+}
+
+// fieldSources describes the config file key, environment variable and CLI
+// flag that set field, such as "etcd.endpoints, MIREN_ETCD_ENDPOINTS or --etcd"
+func fieldSources(schema *Schema, cname string, field *Field) string {
+	var sources []string
+
+	if field.TOML != "" && !field.CLIOnly {
+		key := field.TOML
+		if table, ok := configTables(schema)[cname]; ok {
+			key = table.Path + "." + key
+		}
+		sources = append(sources, key)
+	}
+
+	if field.Env != "" {
+		sources = append(sources, fullEnvName(schema, field))
+	}
+
+	if field.CLI != nil && field.CLI.Long != "" {
+		sources = append(sources, "--"+field.CLI.Long)
+	}
+
+	switch len(sources) {
+	case 0:
+		return ""
+	case 1:
+		return sources[0]
+	default:
+		return strings.Join(sources[:len(sources)-1], ", ") + " or " + sources[len(sources)-1]
+	}
+}
+
 func formatDefault(val interface{}, fieldType string) string {
 	// Arrays don't need pointers, they have nil as a zero value
 	if fieldType == "[]string" {
@@ -359,6 +875,22 @@ func formatDefault(val interface{}, fieldType string) string {
 		return "nil"
 	}
 
+	if fieldType == "duration" {
+		expr, err := durationExpr(fmt.Sprint(val))
+		if err != nil {
+			log.Fatalf("Invalid duration default %v: %v", val, err)
+		}
+		return fmt.Sprintf("durationPtr(%s)", expr)
+	}
+
+	if fieldType == "bytesize" {
+		expr, err := byteSizeExpr(fmt.Sprint(val))
+		if err != nil {
+			log.Fatalf("Invalid bytesize default %v: %v", val, err)
+		}
+		return fmt.Sprintf("byteSizePtr(%s)", expr)
+	}
+
 	switch v := val.(type) {
 	case string:
 		s := fmt.Sprintf(`"%s"`, v)
@@ -376,22 +908,144 @@ func formatDefault(val interface{}, fieldType string) string {
 const configTemplate = `// Code generated by configgen. DO NOT EDIT.
 
 package {{.Package}}
-
+{{if or .HasDurations .HasByteSizes}}
 import (
+	{{- if .HasByteSizes}}
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	{{- end}}
+	{{- if .HasDurations}}
 	"time"
+	{{- end}}
 )
+{{end}}
+{{- if .HasByteSizes}}
+// ByteSize is a count of bytes, written in config as a string such as "256MB"
+// or "1GiB". A bare integer is a count of bytes.
+type ByteSize int64
+
+// Common byte sizes, in decimal and binary units
+const (
+	KB ByteSize = 1000
+	MB          = 1000 * KB
+	GB          = 1000 * MB
+	TB          = 1000 * GB
+
+	KiB ByteSize = 1 << 10
+	MiB          = 1 << 20
+	GiB          = 1 << 30
+	TiB          = 1 << 40
+)
+
+// byteSizeUnits are the suffixes accepted by parseByteSize, matched without
+// regard to case
+var byteSizeUnits = map[string]ByteSize{
+	"":    1,
+	"b":   1,
+	"kb":  KB,
+	"mb":  MB,
+	"gb":  GB,
+	"tb":  TB,
+	"kib": KiB,
+	"mib": MiB,
+	"gib": GiB,
+	"tib": TiB,
+}
+
+// parseByteSize parses a byte size such as "256MB" or "1GiB". Units may be
+// separated from the number by a space.
+func parseByteSize(s string) (ByteSize, error) {
+	s = strings.TrimSpace(s)
+	num := strings.TrimRight(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ ")
+
+	unit, ok := byteSizeUnits[strings.ToLower(strings.TrimSpace(s[len(num):]))]
+	if !ok {
+		return 0, fmt.Errorf("unknown unit in byte size %q", s)
+	}
+
+	n, err := strconv.ParseInt(num, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid byte size %q", s)
+	}
+	if n > math.MaxInt64/int64(unit) {
+		return 0, fmt.Errorf("byte size %q is too large", s)
+	}
+
+	return ByteSize(n) * unit, nil
+}
+
+// Bytes returns the size as a count of bytes
+func (b ByteSize) Bytes() int64 {
+	return int64(b)
+}
+
+// String renders the size in the largest unit that holds it exactly, such as
+// "256MiB", so that it parses back to the same value
+func (b ByteSize) String() string {
+	units := []struct {
+		size ByteSize
+		name string
+	}{
+		{TiB, "TiB"},
+		{TB, "TB"},
+		{GiB, "GiB"},
+		{GB, "GB"},
+		{MiB, "MiB"},
+		{MB, "MB"},
+		{KiB, "KiB"},
+		{KB, "KB"},
+	}
+
+	for _, u := range units {
+		if b != 0 && b%u.size == 0 {
+			return strconv.FormatInt(int64(b/u.size), 10) + u.name
+		}
+	}
+
+	return strconv.FormatInt(int64(b), 10) + "B"
+}
+
+// UnmarshalFlag parses a byte size given as a command-line flag
+func (b *ByteSize) UnmarshalFlag(value string) error {
+	size, err := parseByteSize(value)
+	if err != nil {
+		return err
+	}
 
+	*b = size
+	return nil
+}
+{{end}}
+{{- if .HasDurations}}
+// Duration is a length of time given as a command-line flag, in Go duration
+// syntax such as "30s". A bare integer is taken as seconds, as it is in the
+// config file and environment.
+type Duration time.Duration
+
+// UnmarshalFlag parses a duration given as a command-line flag
+func (d *Duration) UnmarshalFlag(value string) error {
+	v, err := parseDuration(value)
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(v)
+	return nil
+}
+{{end}}
 {{range $name, $config := .Configs}}
 // {{$name}} {{if $config.Description}}{{$config.Description}}{{end}}
 type {{$name}} struct {
 	{{- range $fieldName, $field := $config.Fields}}
 	{{- if not $field.CLIOnly}}
-	{{- if $field.Nested}}
+	{{- if or $field.Nested $field.ListOf}}
 	{{$fieldName | title}} {{$field.Type}} ` + "`" + `toml:"{{$field.TOML}}"` + "`" + `
 	{{- else if eq $field.Type "[]string"}}
-	{{$fieldName | title}} {{$field.Type}} ` + "`" + `toml:"{{$field.TOML}}"{{if $field.Env}} env:"{{$field.Env}}"{{end}}` + "`" + `
+	{{$fieldName | title}} {{$field.Type}} ` + "`" + `toml:"{{$field.TOML}}"{{if $field.Env}} env:"{{fullEnv $field}}"{{end}}` + "`" + `
 	{{- else}}
-	{{$fieldName | title}} *{{$field.Type}} ` + "`" + `toml:"{{$field.TOML}}"{{if $field.Env}} env:"{{$field.Env}}"{{end}}` + "`" + `
+	{{$fieldName | title}} *{{goType $field.Type}} ` + "`" + `toml:"{{$field.TOML}}"{{if $field.Env}} env:"{{fullEnv $field}}"{{end}}` + "`" + `
 	{{- end}}
 	{{- end}}
 	{{- end}}
@@ -399,17 +1053,17 @@ type {{$name}} struct {
 
 {{- range $fieldName, $field := $config.Fields}}
 {{- if not $field.CLIOnly}}
-{{- if not $field.Nested}}
+{{- if not (or $field.Nested $field.ListOf)}}
 {{- if ne $field.Type "[]string"}}
 
 // Get{{$fieldName | title}} returns the value of {{$fieldName | title}} or its zero value if nil
-func (c *{{$name}}) Get{{$fieldName | title}}() {{$field.Type}} {
+func (c *{{$name}}) Get{{$fieldName | title}}() {{goType $field.Type}} {
 	if c.{{$fieldName | title}} != nil {
 		return *c.{{$fieldName | title}}
 	}
 	{{- if eq $field.Type "string"}}
 	return ""
-	{{- else if eq $field.Type "int"}}
+	{{- else if or (eq $field.Type "int") (eq $field.Type "duration") (eq $field.Type "bytesize")}}
 	return 0
 	{{- else if eq $field.Type "bool"}}
 	return false
@@ -417,7 +1071,7 @@ func (c *{{$name}}) Get{{$fieldName | title}}() {{$field.Type}} {
 }
 
 // Set{{$fieldName | title}} sets the value of {{$fieldName | title}}
-func (c *{{$name}}) Set{{$fieldName | title}}(v {{$field.Type}}) {
+func (c *{{$name}}) Set{{$fieldName | title}}(v {{goType $field.Type}}) {
 	c.{{$fieldName | title}} = &v
 }
 {{- end}}
@@ -425,14 +1079,6 @@ func (c *{{$name}}) Set{{$fieldName | title}}(v {{$field.Type}}) {
 {{- end}}
 {{- end}}
 {{end}}
-
-// HTTPRequestTimeoutDuration returns the timeout as a time.Duration
-func (c *ServerConfig) HTTPRequestTimeoutDuration() time.Duration {
-	if c.HTTPRequestTimeout != nil {
-		return time.Duration(*c.HTTPRequestTimeout) * time.Second
-	}
-	return 60 * time.Second // default
-}
 `
 
 const cliTemplate = `// Code generated by configgen. DO NOT EDIT.
@@ -466,6 +1112,9 @@ import (
 	"os"
 	"path/filepath"
 	"log/slog"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/pelletier/go-toml/v2"
 )
@@ -473,6 +1122,14 @@ import (
 // Load loads configuration from all sources with proper precedence:
 // CLI flags > Environment variables > Config file > Defaults
 func Load(configPath string, flags *CLIFlags, log *slog.Logger) (*Config, error) {
+	return LoadWithEnvPrefix(EnvPrefix, configPath, flags, log)
+}
+
+// LoadWithEnvPrefix is like Load, but joins the names of environment variables
+// with envPrefix instead of EnvPrefix, so that tests can use their own
+// variables. An empty envPrefix reads the unprefixed names. Fields given their
+// own prefix in the schema are unaffected.
+func LoadWithEnvPrefix(envPrefix, configPath string, flags *CLIFlags, log *slog.Logger) (*Config, error) {
 	if log == nil {
 		log = slog.Default()
 	}
@@ -487,7 +1144,7 @@ func Load(configPath string, flags *CLIFlags, log *slog.Logger) (*Config, error)
 	}
 	if flags != nil && flags.ServerConfigDataPath != nil && *flags.ServerConfigDataPath != "" {
 		dataPathForSearch = *flags.ServerConfigDataPath
-	} else if envDataPath := os.Getenv("MIREN_SERVER_DATA_PATH"); envDataPath != "" {
+	} else if envDataPath := os.Getenv({{envName "ServerConfig" "data_path"}}); envDataPath != "" {
 		dataPathForSearch = envDataPath
 	}
 
@@ -508,7 +1165,7 @@ func Load(configPath string, flags *CLIFlags, log *slog.Logger) (*Config, error)
 	if cfg.Mode != nil {
 		effectiveMode = *cfg.Mode
 	}
-	if envMode := os.Getenv("MIREN_MODE"); envMode != "" {
+	if envMode := os.Getenv({{envName "Config" "mode"}}); envMode != "" {
 		effectiveMode = envMode
 	}
 	if flags != nil && flags.Mode != nil && *flags.Mode != "" {
@@ -533,7 +1190,7 @@ func Load(configPath string, flags *CLIFlags, log *slog.Logger) (*Config, error)
 	{{- end}}
 
 	// Apply environment variables (can override mode defaults)
-	if err := applyEnvironmentVariables(cfg, log); err != nil {
+	if err := applyEnvironmentVariables(cfg, envPrefix, log); err != nil {
 		return nil, fmt.Errorf("failed to apply environment variables: %w", err)
 	}
 
@@ -588,12 +1245,173 @@ func loadConfigFile(path string, cfg *Config) error {
 		return fmt.Errorf("failed to read config file: %w", err)
 	}
 
+	// go-toml can't decode duration or byte size strings, so rewrite them to
+	// nanoseconds and bytes before decoding into the config
+	var doc map[string]any
+	if err := toml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse TOML: %w", err)
+	}
+
+	if err := normalizeDurations(doc); err != nil {
+		return err
+	}
+	{{- if .HasByteSizes}}
+
+	if err := normalizeByteSizes(doc); err != nil {
+		return err
+	}
+	{{- end}}
+
+	data, err = toml.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("failed to re-encode TOML: %w", err)
+	}
+
 	if err := toml.Unmarshal(data, cfg); err != nil {
 		return fmt.Errorf("failed to parse TOML: %w", err)
 	}
+	{{- if .HasConfigLists}}
+
+	applyListDefaults(cfg)
+	{{- end}}
 
 	return nil
 }
+{{- if .HasConfigLists}}
+
+// applyListDefaults fills in the unset fields of each element of the lists
+// of tables, as decoding starts every element empty
+func applyListDefaults(cfg *Config) {
+	{{- range configLists .}}
+	for i := range cfg.{{.GoPath}} {
+		cfg.{{.GoPath}}[i].applyDefaults()
+	}
+	{{- end}}
+}
+{{- end}}
+
+// tomlTable is a table of a decoded TOML document, with the name to report
+// problems in it by
+type tomlTable struct {
+	name  string
+	table map[string]any
+}
+
+// tomlTables returns the tables found at the dotted path in doc. An array of
+// tables gives each element, named by its index, such as "listener[1]".
+func tomlTables(doc map[string]any, path string) []tomlTable {
+	tables := []tomlTable{{"{{"}}table: doc}}
+	if path == "" {
+		return tables
+	}
+
+	for _, key := range strings.Split(path, ".") {
+		var next []tomlTable
+		for _, t := range tables {
+			name := key
+			if t.name != "" {
+				name = t.name + "." + key
+			}
+
+			switch v := t.table[key].(type) {
+			case map[string]any:
+				next = append(next, tomlTable{name: name, table: v})
+			case []any:
+				for i, elem := range v {
+					if sub, ok := elem.(map[string]any); ok {
+						next = append(next, tomlTable{name: fmt.Sprintf("%s[%d]", name, i), table: sub})
+					}
+				}
+			}
+		}
+		tables = next
+	}
+
+	return tables
+}
+
+// parseDuration parses Go duration syntax such as "30s". A bare integer is
+// taken as seconds, matching how these settings were written before they
+// became durations.
+func parseDuration(s string) (time.Duration, error) {
+	if secs, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Duration(secs) * time.Second, nil
+	}
+
+	return time.ParseDuration(s)
+}
+
+// normalizeDurations replaces the duration values in a decoded TOML document
+// with their length in nanoseconds
+func normalizeDurations(doc map[string]any) error {
+	{{- range durationKeys .}}
+	if err := normalizeDuration(doc, "{{.Table}}", "{{.Key}}"); err != nil {
+		return err
+	}
+	{{- end}}
+	return nil
+}
+
+func normalizeDuration(doc map[string]any, table, key string) error {
+	for _, t := range tomlTables(doc, table) {
+		name := key
+		if t.name != "" {
+			name = t.name + "." + key
+		}
+
+		switch v := t.table[key].(type) {
+		case nil:
+		case string:
+			d, err := parseDuration(v)
+			if err != nil {
+				return fmt.Errorf("invalid duration for %s: %w", name, err)
+			}
+			t.table[key] = int64(d)
+		case int64:
+			t.table[key] = int64(time.Duration(v) * time.Second)
+		default:
+			return fmt.Errorf("invalid duration for %s: expected a string, got %T", name, v)
+		}
+	}
+
+	return nil
+}
+{{- if .HasByteSizes}}
+
+// normalizeByteSizes replaces the byte size values in a decoded TOML document
+// with their count of bytes
+func normalizeByteSizes(doc map[string]any) error {
+	{{- range byteSizeKeys .}}
+	if err := normalizeByteSize(doc, "{{.Table}}", "{{.Key}}"); err != nil {
+		return err
+	}
+	{{- end}}
+	return nil
+}
+
+func normalizeByteSize(doc map[string]any, table, key string) error {
+	for _, t := range tomlTables(doc, table) {
+		name := key
+		if t.name != "" {
+			name = t.name + "." + key
+		}
+
+		switch v := t.table[key].(type) {
+		case nil, int64:
+		case string:
+			b, err := parseByteSize(v)
+			if err != nil {
+				return fmt.Errorf("invalid byte size for %s: %w", name, err)
+			}
+			t.table[key] = int64(b)
+		default:
+			return fmt.Errorf("invalid byte size for %s: expected a string, got %T", name, v)
+		}
+	}
+
+	return nil
+}
+{{- end}}
 
 func applyCLIFlags(cfg *Config, flags *CLIFlags) {
 	{{range $cname, $config := .Configs}}
@@ -605,10 +1423,14 @@ func applyCLIFlags(cfg *Config, flags *CLIFlags) {
 	if flags.{{$flagName}} != nil && *flags.{{$flagName}} != "" {
 		cfg.{{if ne $cname "Config"}}{{$structField}}.{{end}}{{$fname | title}} = flags.{{$flagName}}
 	}
-	{{else if eq $field.Type "int"}}
+	{{else if or (eq $field.Type "int") (eq $field.Type "bytesize")}}
 	if flags.{{$flagName}} != nil {
 		cfg.{{if ne $cname "Config"}}{{$structField}}.{{end}}{{$fname | title}} = flags.{{$flagName}}
 	}
+	{{else if eq $field.Type "duration"}}
+	if flags.{{$flagName}} != nil {
+		cfg.{{if ne $cname "Config"}}{{$structField}}.{{end}}{{$fname | title}} = durationPtr(time.Duration(*flags.{{$flagName}}))
+	}
 	{{else if eq $field.Type "bool"}}
 	if flags.{{$flagName}} != nil {
 		cfg.{{if ne $cname "Config"}}{{$structField}}.{{end}}{{$fname | title}} = flags.{{$flagName}}
@@ -628,10 +1450,18 @@ const defaultsTemplate = `// Code generated by configgen. DO NOT EDIT.
 
 package {{.Package}}
 
+import (
+	"time"
+)
+
 // Helper functions for creating pointers to literals
 func boolPtr(b bool) *bool { return &b }
 func intPtr(i int) *int { return &i }
 func strPtr(s string) *string { return &s }
+func durationPtr(d time.Duration) *time.Duration { return &d }
+{{- if .HasByteSizes}}
+func byteSizePtr(b ByteSize) *ByteSize { return &b }
+{{- end}}
 
 // DefaultConfig returns the default configuration
 func DefaultConfig() *Config {
@@ -658,6 +1488,22 @@ func Default{{$name}}() {{$name}} {
 		{{- end}}
 	}
 }
+{{- if $.IsListElement $name}}
+
+// applyDefaults sets the fields of c that are unset to their defaults
+func (c *{{$name}}) applyDefaults() {
+	d := Default{{$name}}()
+	{{- range $fname, $field := $config.Fields}}
+	{{- if eq $field.Type "[]string"}}
+	if len(c.{{$fname | title}}) == 0 {
+	{{- else}}
+	if c.{{$fname | title}} == nil {
+	{{- end}}
+		c.{{$fname | title}} = d.{{$fname | title}}
+	}
+	{{- end}}
+}
+{{- end}}
 {{end}}
 {{end}}
 `
@@ -670,10 +1516,65 @@ import (
 	"fmt"
 	"net"
 	"regexp"
+	"strings"
+	{{- if .HasDurations}}
+	"time"
+	{{- end}}
 )
 
+// ValidationError collects every problem found while validating a
+// configuration, so they can all be fixed at once. Errors from nested
+// configs are prefixed with their table name.
+type ValidationError struct {
+	Errors []error
+}
+
+// Error lists the problems one per line
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap returns the individual problems
+func (e *ValidationError) Unwrap() []error {
+	return e.Errors
+}
+
+// add records err under prefix, flattening nested ValidationErrors
+func (e *ValidationError) add(prefix string, err error) {
+	if err == nil {
+		return
+	}
+
+	if ve, ok := err.(*ValidationError); ok {
+		for _, sub := range ve.Errors {
+			e.add(prefix, sub)
+		}
+		return
+	}
+
+	if prefix != "" {
+		err = fmt.Errorf("%s: %w", prefix, err)
+	}
+
+	e.Errors = append(e.Errors, err)
+}
+
+// result returns e, or nil if nothing was recorded
+func (e *ValidationError) result() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+	return e
+}
+
 // Validate validates the configuration
 func (c *Config) Validate() error {
+	var errs ValidationError
+
 	{{- range $fname, $field := (index .Configs "Config").Fields}}
 	{{- if $field.Validation}}
 	{{- if $field.Validation.Enum}}
@@ -685,45 +1586,63 @@ func (c *Config) Validate() error {
 			{{- end}}
 		}
 		if !validModes[*c.{{$fname | title}}] {
-			return fmt.Errorf("invalid {{$fname}} %q: must be one of {{$field.Validation.Enum}}", *c.{{$fname | title}})
+			errs.add("", fmt.Errorf("invalid {{$fname}} %q: must be one of {{$field.Validation.Enum}}", *c.{{$fname | title}}))
 		}
 	}
 	{{- end}}
 	{{- end}}
+	{{- if or $field.Required $field.RequiredWhen}}
+
+	// Validate {{$fname}} is set
+	{{required "Config" $fname $field}}
+	{{- end}}
+	{{- if eq $field.Type "bytesize"}}
+
+	// Validate {{$fname}} is not negative
+	if c.{{$fname | title}} != nil && *c.{{$fname | title}} < 0 {
+		errs.add("", fmt.Errorf("{{$fname}} must not be negative, got %d", *c.{{$fname | title}}))
+	}
+	{{- end}}
 	{{- if $field.Nested}}
 
-	if err := c.{{$fname | title}}.Validate(); err != nil {
-		return fmt.Errorf("{{$fname}}: %w", err)
+	errs.add("{{$field.TOML}}", c.{{$fname | title}}.Validate())
+	{{- end}}
+	{{- if $field.ListOf}}
+
+	for i := range c.{{$fname | title}} {
+		errs.add(fmt.Sprintf("{{$field.TOML}}[%d]", i), c.{{$fname | title}}[i].Validate())
 	}
 	{{- end}}
 	{{- end}}
-	return nil
+	return errs.result()
 }
 
 {{range $name, $config := .Configs}}
 {{- if ne $name "Config"}}
 // Validate validates {{$name}}
 func (c *{{$name}}) Validate() error {
+	var errs ValidationError
+
 	{{- range $fname, $field := $config.Fields}}
 	{{- if $field.Validation}}
 	{{if eq $field.Validation.Format "host:port"}}
 	// Validate {{$fname}}
 	if c.{{$fname | title}} != nil && *c.{{$fname | title}} != "" {
 		if _, _, err := net.SplitHostPort(*c.{{$fname | title}}); err != nil {
-			return fmt.Errorf("invalid {{$fname}} %q: %w", *c.{{$fname | title}}, err)
+			errs.add("", fmt.Errorf("invalid {{$fname}} %q: %w", *c.{{$fname | title}}, err))
 		}
 	}
 	{{else if eq $field.Validation.Format "ip_list"}}
 	// Validate {{$fname}}
 	for _, ip := range c.{{$fname | title}} {
 		if net.ParseIP(ip) == nil {
-			return fmt.Errorf("invalid IP address %q in {{$fname}}", ip)
+			errs.add("", fmt.Errorf("invalid IP address %q in {{$fname}}", ip))
 		}
 	}
 	{{else if $field.Validation.Port}}
 	// Validate {{$fname}}
 	if c.{{$fname | title}} != nil && (*c.{{$fname | title}} < 1 || *c.{{$fname | title}} > 65535) {
-		return fmt.Errorf("{{$fname}} must be between 1 and 65535, got %d", *c.{{$fname | title}})
+		errs.add("", fmt.Errorf("{{$fname}} must be between 1 and 65535, got %d", *c.{{$fname | title}}))
 	}
 	{{end}}
 	{{if $field.Validation.Regex}}
@@ -731,23 +1650,34 @@ func (c *{{$name}}) Validate() error {
 	if c.{{$fname | title}} != nil && *c.{{$fname | title}} != "" {
 		matched, err := regexp.MatchString(` + "`{{$field.Validation.Regex}}`" + `, *c.{{$fname | title}})
 		if err != nil {
-			return fmt.Errorf("invalid regex pattern for {{$fname}}: %w", err)
-		}
-		if !matched {
-			return fmt.Errorf("invalid {{$fname}} %q: must match pattern %q", *c.{{$fname | title}}, ` + "`{{$field.Validation.Regex}}`" + `)
+			errs.add("", fmt.Errorf("invalid regex pattern for {{$fname}}: %w", err))
+		} else if !matched {
+			errs.add("", fmt.Errorf("invalid {{$fname}} %q: must match pattern %q", *c.{{$fname | title}}, ` + "`{{$field.Validation.Regex}}`" + `))
 		}
 	}
 	{{end}}
 	{{if $field.Validation.Min}}
 	// Validate {{$fname}} minimum
 	if c.{{$fname | title}} != nil && *c.{{$fname | title}} < {{$field.Validation.Min}} {
-		return fmt.Errorf("{{$fname}} must be at least {{$field.Validation.Min}}, got %d", *c.{{$fname | title}})
+		errs.add("", fmt.Errorf("{{$fname}} must be at least {{$field.Validation.Min}}, got %d", *c.{{$fname | title}}))
 	}
 	{{end}}
 	{{if $field.Validation.Max}}
 	// Validate {{$fname}} maximum
 	if c.{{$fname | title}} != nil && *c.{{$fname | title}} > {{$field.Validation.Max}} {
-		return fmt.Errorf("{{$fname}} must be at most {{$field.Validation.Max}}, got %d", *c.{{$fname | title}})
+		errs.add("", fmt.Errorf("{{$fname}} must be at most {{$field.Validation.Max}}, got %d", *c.{{$fname | title}}))
+	}
+	{{end}}
+	{{if $field.Validation.MinDuration}}
+	// Validate {{$fname}} minimum
+	if c.{{$fname | title}} != nil && *c.{{$fname | title}} < {{durationExpr $field.Validation.MinDuration}} {
+		errs.add("", fmt.Errorf("{{$fname}} must be at least {{$field.Validation.MinDuration}}, got %s", *c.{{$fname | title}}))
+	}
+	{{end}}
+	{{if $field.Validation.MaxDuration}}
+	// Validate {{$fname}} maximum
+	if c.{{$fname | title}} != nil && *c.{{$fname | title}} > {{durationExpr $field.Validation.MaxDuration}} {
+		errs.add("", fmt.Errorf("{{$fname}} must be at most {{$field.Validation.MaxDuration}}, got %s", *c.{{$fname | title}}))
 	}
 	{{end}}
 	{{if $field.Validation.Enum}}
@@ -759,11 +1689,17 @@ func (c *{{$name}}) Validate() error {
 			{{- end}}
 		}
 		if !valid{{$fname | title}}[*c.{{$fname | title}}] {
-			return fmt.Errorf("invalid {{$fname}} %q: must be one of {{$field.Validation.Enum}}", *c.{{$fname | title}})
+			errs.add("", fmt.Errorf("invalid {{$fname}} %q: must be one of {{$field.Validation.Enum}}", *c.{{$fname | title}}))
 		}
 	}
 	{{end}}
 	{{end}}
+	{{if eq $field.Type "bytesize"}}
+	// Validate {{$fname}} is not negative
+	if c.{{$fname | title}} != nil && *c.{{$fname | title}} < 0 {
+		errs.add("", fmt.Errorf("{{$fname}} must not be negative, got %d", *c.{{$fname | title}}))
+	}
+	{{end}}
 	{{end}}
 	
 	// Check for port conflicts in {{$name}}
@@ -773,7 +1709,7 @@ func (c *{{$name}}) Validate() error {
 	{{- if $field.Validation}}{{if $field.Validation.Port}}
 	if c.{{$fname | title}} != nil {
 		if seen[*c.{{$fname | title}}] {
-			return fmt.Errorf("port conflict: port %d is used multiple times", *c.{{$fname | title}})
+			errs.add("", fmt.Errorf("port conflict: port %d is used multiple times", *c.{{$fname | title}}))
 		}
 		seen[*c.{{$fname | title}}] = true
 	}
@@ -781,14 +1717,19 @@ func (c *{{$name}}) Validate() error {
 	{{- end}}
 	{{end}}
 	
-	{{if eq $name "EtcdConfig"}}
-	// Validate etcd endpoints requirement
-	if c.StartEmbedded != nil && !*c.StartEmbedded && len(c.Endpoints) == 0 {
-		return fmt.Errorf("etcd endpoints must be set when start_embedded=false")
+	{{range $fname, $field := $config.Fields}}
+	{{- if or $field.Required $field.RequiredWhen}}
+	// Validate {{$fname}} is set
+	{{required $name $fname $field}}
+	{{- end}}
+	{{- if $field.ListOf}}
+	for i := range c.{{$fname | title}} {
+		errs.add(fmt.Sprintf("{{$field.TOML}}[%d]", i), c.{{$fname | title}}[i].Validate())
 	}
-	{{end}}
+	{{- end}}
+	{{- end}}
 	
-	return nil
+	return errs.result()
 }
 {{end}}
 {{end}}
@@ -799,55 +1740,79 @@ const envTemplate = `// Code generated by configgen. DO NOT EDIT.
 package {{.Package}}
 
 import (
+	"encoding/json"
 	"log/slog"
 	"os"
 	"strconv"
 	"strings"
 )
 
-// applyEnvironmentVariables applies environment variables to the configuration
-func applyEnvironmentVariables(cfg *Config, log *slog.Logger) error {
+// EnvPrefix is joined with the names of the environment variables Load reads
+const EnvPrefix = "{{.EnvPrefix}}"
+
+// envName joins an environment variable name with prefix, if there is one
+func envName(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "_" + name
+}
+
+// lookupEnv returns an environment variable's value along with its name
+func lookupEnv(name string) (string, string) {
+	return name, os.Getenv(name)
+}
+
+// applyEnvironmentVariables applies environment variables, with names joined
+// with envPrefix, to the configuration.
+// List values may be a JSON array of strings, such as ["a,b", "c"], for items
+// containing commas. Any other value, or a JSON array that fails to decode, is
+// taken as a comma-separated list.
+func applyEnvironmentVariables(cfg *Config, envPrefix string, log *slog.Logger) error {
 	{{range $cname, $config := .Configs}}
 	{{$structField := $cname}}{{range $k, $v := (index $.Configs "Config").Fields}}{{if eq $v.Type $cname}}{{$structField = ($k | title)}}{{end}}{{end}}
 	{{range $fname, $field := $config.Fields}}
 	{{if $field.Env}}
-	// Apply {{$field.Env}}
-	if val := os.Getenv("{{$field.Env}}"); val != "" {
+	// Apply {{fullEnv $field}}
+	if key, val := lookupEnv({{envName $field}}); val != "" {
 		{{if eq $field.Type "string"}}
 		cfg.{{if ne $cname "Config"}}{{$structField}}.{{end}}{{$fname | title}} = &val
-		log.Debug("applied env var", "key", "{{$field.Env}}")
+		log.Debug("applied env var", "key", key)
 		{{else if eq $field.Type "int"}}
 		if i, err := strconv.Atoi(val); err == nil {
 			cfg.{{if ne $cname "Config"}}{{$structField}}.{{end}}{{$fname | title}} = &i
-			log.Debug("applied env var", "key", "{{$field.Env}}")
+			log.Debug("applied env var", "key", key)
+		} else {
+			log.Warn("invalid env var value", "key", key, "value", val, "error", err)
+		}
+		{{else if eq $field.Type "duration"}}
+		if d, err := parseDuration(val); err == nil {
+			cfg.{{if ne $cname "Config"}}{{$structField}}.{{end}}{{$fname | title}} = &d
+			log.Debug("applied env var", "key", key)
 		} else {
-			log.Warn("invalid {{$field.Env}} value", "value", val, "error", err)
+			log.Warn("invalid env var value", "key", key, "value", val, "error", err)
+		}
+		{{else if eq $field.Type "bytesize"}}
+		if b, err := parseByteSize(val); err == nil {
+			cfg.{{if ne $cname "Config"}}{{$structField}}.{{end}}{{$fname | title}} = &b
+			log.Debug("applied env var", "key", key)
+		} else {
+			log.Warn("invalid env var value", "key", key, "value", val, "error", err)
 		}
 		{{else if eq $field.Type "bool"}}
 		if b, err := strconv.ParseBool(val); err == nil {
 			cfg.{{if ne $cname "Config"}}{{$structField}}.{{end}}{{$fname | title}} = &b
-			log.Debug("applied env var", "key", "{{$field.Env}}")
+			log.Debug("applied env var", "key", key)
 		} else {
-			log.Warn("invalid {{$field.Env}} value", "value", val, "error", err)
+			log.Warn("invalid env var value", "key", key, "value", val, "error", err)
 		}
 		{{else if eq $field.Type "[]string"}}
-		// Split and clean CSV list
-		parts := strings.Split(val, ",")
-		cleaned := make([]string, 0, len(parts))
-		seen := make(map[string]struct{})
-		for _, p := range parts {
-			p = strings.TrimSpace(p)
-			if p == "" {
-				continue
-			}
-			if _, exists := seen[p]; exists {
-				continue
-			}
-			seen[p] = struct{}{}
-			cleaned = append(cleaned, p)
+		list, err := parseStringList(val)
+		if err != nil {
+			log.Warn("invalid JSON in env var, treating it as comma-separated", "key", key, "value", val, "error", err)
 		}
-		cfg.{{if ne $cname "Config"}}{{$structField}}.{{end}}{{$fname | title}} = cleaned
-		log.Debug("applied env var", "key", "{{$field.Env}}", "count", len(cleaned))
+		cfg.{{if ne $cname "Config"}}{{$structField}}.{{end}}{{$fname | title}} = list
+		log.Debug("applied env var", "key", key, "count", len(list))
 		{{end}}
 	}
 	{{end}}
@@ -855,4 +1820,168 @@ func applyEnvironmentVariables(cfg *Config, log *slog.Logger) error {
 	{{end}}
 	return nil
 }
+
+// parseStringList parses a list-valued environment variable. A value starting
+// with "[" is decoded as a JSON array of strings and used as is. Otherwise, or
+// if decoding fails, the value is split on commas, dropping empty and
+// duplicate items after trimming spaces.
+//
+// A decoding error is returned along with the comma-separated result when the
+// value also ends with "]", so it was likely meant as JSON. Values such as
+// "[::1]:2379" fall back quietly.
+func parseStringList(val string) ([]string, error) {
+	var jsonErr error
+
+	if trimmed := strings.TrimSpace(val); strings.HasPrefix(trimmed, "[") {
+		var list []string
+		err := json.Unmarshal([]byte(trimmed), &list)
+		if err == nil {
+			return list, nil
+		}
+		if strings.HasSuffix(trimmed, "]") {
+			jsonErr = err
+		}
+	}
+
+	parts := strings.Split(val, ",")
+	cleaned := make([]string, 0, len(parts))
+	seen := make(map[string]struct{})
+	for _, p := range parts {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if _, exists := seen[p]; exists {
+			continue
+		}
+		seen[p] = struct{}{}
+		cleaned = append(cleaned, p)
+	}
+
+	return cleaned, jsonErr
+}
+`
+
+const writerTemplate = `// Code generated by configgen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// MarshalTOML serializes the configuration as TOML. Only explicitly set
+// values are written: nil pointers, empty lists and empty tables are omitted,
+// so the output re-parses to the same configuration.
+func (c *Config) MarshalTOML() ([]byte, error) {
+	return toml.Marshal(c.tomlTable())
+}
+
+{{range $name, $config := .Configs}}
+// tomlTable returns the set fields of {{$name}} keyed by their TOML names
+func (c *{{$name}}) tomlTable() map[string]any {
+	t := make(map[string]any)
+	{{- range $fname, $field := $config.Fields}}
+	{{- if and (not $field.CLIOnly) $field.TOML}}
+	{{- if $field.Nested}}
+	if sub := c.{{$fname | title}}.tomlTable(); len(sub) > 0 {
+		t["{{$field.TOML}}"] = sub
+	}
+	{{- else if $field.ListOf}}
+	if len(c.{{$fname | title}}) > 0 {
+		list := make([]map[string]any, len(c.{{$fname | title}}))
+		for i := range c.{{$fname | title}} {
+			list[i] = c.{{$fname | title}}[i].tomlTable()
+		}
+		t["{{$field.TOML}}"] = list
+	}
+	{{- else if eq $field.Type "[]string"}}
+	if len(c.{{$fname | title}}) > 0 {
+		t["{{$field.TOML}}"] = c.{{$fname | title}}
+	}
+	{{- else if or (eq $field.Type "duration") (eq $field.Type "bytesize")}}
+	if c.{{$fname | title}} != nil {
+		t["{{$field.TOML}}"] = c.{{$fname | title}}.String()
+	}
+	{{- else}}
+	if c.{{$fname | title}} != nil {
+		t["{{$field.TOML}}"] = *c.{{$fname | title}}
+	}
+	{{- end}}
+	{{- end}}
+	{{- end}}
+	return t
+}
+
+// String renders {{$name}} for logs and debugging. Unset fields print as
+// <nil> and the values of sensitive fields are replaced with ****.
+func (c *{{$name}}) String() string {
+	if c == nil {
+		return "<nil>"
+	}
+
+	fields := []string{
+		{{- range $fname, $field := $config.Fields}}
+		{{- if not $field.CLIOnly}}
+		{{- if $field.Nested}}
+		"{{$fname | title}}: " + c.{{$fname | title}}.String(),
+		{{- else if $field.ListOf}}
+		"{{$fname | title}}: " + formatTables(c.{{$fname | title}}),
+		{{- else if and $field.Sensitive (eq $field.Type "[]string")}}
+		"{{$fname | title}}: " + formatRedacted(len(c.{{$fname | title}}) > 0),
+		{{- else if $field.Sensitive}}
+		"{{$fname | title}}: " + formatRedacted(c.{{$fname | title}} != nil),
+		{{- else if eq $field.Type "[]string"}}
+		"{{$fname | title}}: " + fmt.Sprintf("%q", c.{{$fname | title}}),
+		{{- else}}
+		"{{$fname | title}}: " + formatValue(c.{{$fname | title}}),
+		{{- end}}
+		{{- end}}
+		{{- end}}
+	}
+
+	return "{" + strings.Join(fields, ", ") + "}"
+}
+{{end}}
+// formatValue renders an optional value, quoting strings
+func formatValue[T any](v *T) string {
+	if v == nil {
+		return "<nil>"
+	}
+
+	if s, ok := any(*v).(string); ok {
+		return strconv.Quote(s)
+	}
+
+	return fmt.Sprint(*v)
+}
+
+{{- if .HasConfigLists}}
+
+// formatTables renders a list of tables
+func formatTables[T any, P interface {
+	*T
+	String() string
+}](list []T) string {
+	items := make([]string, len(list))
+	for i := range list {
+		items[i] = P(&list[i]).String()
+	}
+
+	return "[" + strings.Join(items, ", ") + "]"
+}
+{{- end}}
+
+// formatRedacted renders a sensitive value, showing only whether it is set
+func formatRedacted(set bool) string {
+	if !set {
+		return "<nil>"
+	}
+
+	return "****"
+}
 `
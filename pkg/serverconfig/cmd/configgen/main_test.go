@@ -0,0 +1,367 @@
+package main
+
+import (
+	"go/format"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestRequiredExpr(t *testing.T) {
+	schema := &Schema{
+		Configs: map[string]*Config{
+			"Config": {
+				Fields: map[string]*Field{
+					"server": {Type: "ServerConfig", TOML: "server", Nested: true},
+				},
+			},
+			"ServerConfig": {
+				Fields: map[string]*Field{
+					"token": {
+						Type:     "string",
+						TOML:     "token",
+						Env:      "MIREN_SERVER_TOKEN",
+						CLI:      &CLIConfig{Long: "token"},
+						Required: true,
+					},
+					"peers": {
+						Type:     "[]string",
+						TOML:     "peers",
+						Required: true,
+					},
+					"port": {
+						Type:     "int",
+						Env:      "MIREN_SERVER_PORT",
+						Required: true,
+					},
+					"start_embedded": {Type: "bool", TOML: "start_embedded"},
+					"upstream": {
+						Type:         "string",
+						TOML:         "upstream",
+						RequiredWhen: map[string]any{"start_embedded": false},
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		field string
+		want  string
+	}{
+		{
+			"token",
+			`if c.Token == nil || *c.Token == "" {
+	errs.add("", fmt.Errorf("token is required; set it with server.token, MIREN_SERVER_TOKEN or --token"))
+}`,
+		},
+		{
+			"peers",
+			`if len(c.Peers) == 0 {
+	errs.add("", fmt.Errorf("peers is required; set it with server.peers"))
+}`,
+		},
+		{
+			"port",
+			`if c.Port == nil {
+	errs.add("", fmt.Errorf("port is required; set it with MIREN_SERVER_PORT"))
+}`,
+		},
+		{
+			"upstream",
+			`if c.StartEmbedded != nil && !*c.StartEmbedded && (c.Upstream == nil || *c.Upstream == "") {
+	errs.add("", fmt.Errorf("upstream must be set when start_embedded=false; set it with server.upstream"))
+}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			got, err := requiredExpr(schema, "ServerConfig", tt.field, schema.Configs["ServerConfig"].Fields[tt.field])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("requiredExpr() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("unknown condition field", func(t *testing.T) {
+		field := &Field{Type: "string", RequiredWhen: map[string]any{"missing": true}}
+		_, err := requiredExpr(schema, "ServerConfig", "upstream", field)
+		if err == nil || !strings.Contains(err.Error(), `unknown field "missing"`) {
+			t.Errorf("requiredExpr() error = %v", err)
+		}
+	})
+}
+
+func TestWriterRedactsSensitiveFields(t *testing.T) {
+	schema := &Schema{
+		Package: "example",
+		Configs: map[string]*Config{
+			"Config": {
+				Fields: map[string]*Field{
+					"user":     {Type: "string", TOML: "user"},
+					"password": {Type: "string", TOML: "password", Sensitive: true},
+					"tokens":   {Type: "[]string", TOML: "tokens", Sensitive: true},
+				},
+			},
+		},
+	}
+
+	got, err := generateWriter(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		`"User: " + formatValue(c.User),`,
+		`"Password: " + formatRedacted(c.Password != nil),`,
+		`"Tokens: " + formatRedacted(len(c.Tokens) > 0),`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("generated writer missing %s", want)
+		}
+	}
+}
+
+func TestEnvNames(t *testing.T) {
+	schema := &Schema{EnvPrefix: "MIREN"}
+
+	tests := []struct {
+		name     string
+		field    *Field
+		wantFull string
+		wantExpr string
+	}{
+		{
+			"schema prefix",
+			&Field{Env: "SERVER_ADDRESS"},
+			"MIREN_SERVER_ADDRESS",
+			`envName(envPrefix, "SERVER_ADDRESS")`,
+		},
+		{
+			"field prefix",
+			&Field{Env: "ENDPOINTS", EnvPrefix: "ETCD"},
+			"ETCD_ENDPOINTS",
+			`"ETCD_ENDPOINTS"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fullEnvName(schema, tt.field); got != tt.wantFull {
+				t.Errorf("fullEnvName() = %q, want %q", got, tt.wantFull)
+			}
+			if got := envNameExpr(tt.field); got != tt.wantExpr {
+				t.Errorf("envNameExpr() = %s, want %s", got, tt.wantExpr)
+			}
+		})
+	}
+
+	if got := fullEnvName(&Schema{}, &Field{Env: "MIREN_MODE"}); got != "MIREN_MODE" {
+		t.Errorf("fullEnvName() without a prefix = %q, want MIREN_MODE", got)
+	}
+}
+
+func TestByteSizeExpr(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"10GB", "10 * GB"},
+		{"256MiB", "256 * MiB"},
+		{"1 gib", "1 * GiB"},
+		{"2048KB", "2000 * KiB"},
+		{"3072", "3 * KiB"},
+		{"100", "ByteSize(100)"},
+		{"0", "ByteSize(0)"},
+	}
+
+	for _, tt := range tests {
+		got, err := byteSizeExpr(tt.in)
+		if err != nil {
+			t.Errorf("byteSizeExpr(%q) error = %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("byteSizeExpr(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"", "GB", "-1GB", "1.5GB", "10 gigs", "9999999TiB"} {
+		if _, err := byteSizeExpr(bad); err == nil {
+			t.Errorf("byteSizeExpr(%q) should fail", bad)
+		}
+	}
+}
+
+func loadListSchema(t *testing.T) *Schema {
+	t.Helper()
+
+	data, err := os.ReadFile("testdata/listeners.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var schema Schema
+	if err := yaml.Unmarshal(data, &schema); err != nil {
+		t.Fatal(err)
+	}
+
+	return &schema
+}
+
+func TestConfigLists(t *testing.T) {
+	schema := loadListSchema(t)
+
+	if err := checkConfigLists(schema); err != nil {
+		t.Fatalf("checkConfigLists() = %v", err)
+	}
+
+	t.Run("locates lists", func(t *testing.T) {
+		want := []configList{
+			{GoPath: "Listeners", Elem: "ListenerConfig"},
+			{GoPath: "Server.Routes", Elem: "RouteConfig"},
+		}
+		if got := configLists(schema); !reflect.DeepEqual(got, want) {
+			t.Errorf("configLists() = %v, want %v", got, want)
+		}
+
+		wantKeys := []tomlKey{
+			{Table: "listener", Key: "idle_timeout"},
+			{Table: "server.route", Key: "timeout"},
+		}
+		if got := durationKeys(schema); !reflect.DeepEqual(got, wantKeys) {
+			t.Errorf("durationKeys() = %v, want %v", got, wantKeys)
+		}
+
+		if !schema.IsListElement("ListenerConfig") || schema.IsListElement("ServerConfig") {
+			t.Error("IsListElement() should only hold for configs in lists")
+		}
+	})
+
+	generated := map[string]string{}
+	for name, gen := range map[string]func(*Schema) (string, error){
+		"config":     generateConfig,
+		"defaults":   generateDefaults,
+		"validation": generateValidation,
+		"writer":     generateWriter,
+	} {
+		got, err := gen(schema)
+		if err != nil {
+			t.Fatalf("generating %s: %v", name, err)
+		}
+
+		formatted, err := format.Source([]byte(got))
+		if err != nil {
+			t.Fatalf("generated %s doesn't parse: %v", name, err)
+		}
+
+		generated[name] = string(formatted)
+	}
+
+	tests := []struct {
+		name string
+		file string
+		want []string
+	}{
+		{
+			"declares slices of structs",
+			"config",
+			[]string{
+				"Listeners []ListenerConfig `toml:\"listener\"`",
+				"Routes  []RouteConfig `toml:\"route\"`",
+			},
+		},
+		{
+			"fills in defaults of elements",
+			"defaults",
+			[]string{
+				"func (c *ListenerConfig) applyDefaults() {",
+				"if c.Protocol == nil {\n\t\tc.Protocol = d.Protocol",
+				"if len(c.Hosts) == 0 {\n\t\tc.Hosts = d.Hosts",
+				"func (c *RouteConfig) applyDefaults() {",
+			},
+		},
+		{
+			"validates each element under its index",
+			"validation",
+			[]string{
+				"errs.add(fmt.Sprintf(\"listener[%d]\", i), c.Listeners[i].Validate())",
+				"errs.add(fmt.Sprintf(\"route[%d]\", i), c.Routes[i].Validate())",
+				"name is required; set it with listener.name",
+			},
+		},
+		{
+			"writes arrays of tables",
+			"writer",
+			[]string{
+				"list[i] = c.Listeners[i].tomlTable()",
+				"\"Listeners: \" + formatTables(c.Listeners),",
+				"func formatTables[",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, want := range tt.want {
+				if !strings.Contains(generated[tt.file], want) {
+					t.Errorf("generated %s missing %s", tt.file, want)
+				}
+			}
+		})
+	}
+
+	if strings.Contains(generated["defaults"], "func (c *ServerConfig) applyDefaults()") {
+		t.Error("only list elements should have applyDefaults")
+	}
+
+	t.Run("rejects unsupported lists", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			modify func(s *Schema)
+			want   string
+		}{
+			{
+				"unknown element",
+				func(s *Schema) { s.Configs["Config"].Fields["listeners"].Type = "[]MissingConfig" },
+				"MissingConfig is not a config",
+			},
+			{
+				"list from the environment",
+				func(s *Schema) { s.Configs["Config"].Fields["listeners"].Env = "LISTENERS" },
+				"lists can only be set in the config file",
+			},
+			{
+				"element field from a flag",
+				func(s *Schema) { s.Configs["ListenerConfig"].Fields["name"].CLI = &CLIConfig{Long: "listener-name"} },
+				"fields of list elements can only be set in the config file",
+			},
+			{
+				"element holding a table",
+				func(s *Schema) {
+					s.Configs["ListenerConfig"].Fields["server"] = &Field{Type: "ServerConfig", TOML: "server", Nested: true}
+				},
+				"list elements can't hold tables",
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				schema := loadListSchema(t)
+				tt.modify(schema)
+
+				err := checkConfigLists(schema)
+				if err == nil || !strings.Contains(err.Error(), tt.want) {
+					t.Errorf("checkConfigLists() = %v, want an error containing %q", err, tt.want)
+				}
+			})
+		}
+	})
+}
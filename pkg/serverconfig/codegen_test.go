@@ -1,11 +1,17 @@
 package serverconfig
 
 import (
+	"errors"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/jessevdk/go-flags"
+	"github.com/pelletier/go-toml/v2"
 )
 
 func TestGeneratedCode(t *testing.T) {
@@ -294,3 +300,627 @@ endpoints = ["http://etcd1:2379", "http://etcd2:2379"]`,
 		})
 	}
 }
+
+func TestMarshalTOML(t *testing.T) {
+	t.Run("omits unset values", func(t *testing.T) {
+		cfg := &Config{}
+		cfg.SetMode("distributed")
+		cfg.Etcd.SetClientPort(2379)
+		cfg.Etcd.Endpoints = []string{"http://etcd1:2379", "http://etcd2:2379"}
+		cfg.TLS.AdditionalNames = []string{}
+
+		data, err := cfg.MarshalTOML()
+		if err != nil {
+			t.Fatalf("MarshalTOML() error = %v", err)
+		}
+
+		var got map[string]any
+		if err := toml.Unmarshal(data, &got); err != nil {
+			t.Fatalf("failed to parse output: %v\n%s", err, data)
+		}
+
+		want := map[string]any{
+			"mode": "distributed",
+			"etcd": map[string]any{
+				"client_port": int64(2379),
+				"endpoints":   []any{"http://etcd1:2379", "http://etcd2:2379"},
+			},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("MarshalTOML() wrote\n%s\nwant keys %v", data, want)
+		}
+	})
+
+	t.Run("loaded config round-trips", func(t *testing.T) {
+		tmpDir := t.TempDir()
+
+		configPath := filepath.Join(tmpDir, "server.toml")
+		content := `mode = "standalone"
+
+[server]
+address = ":9443"
+http_request_timeout = 30
+
+[tls]
+additional_names = ["miren.example.com", "alt.example.com"]
+additional_ips = ["10.0.0.1"]
+standard_tls = false
+
+[etcd]
+client_port = 9999
+prefix = "/test"
+
+[buildkit]
+gc_keep_storage = "512 mib"
+`
+		if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		cfg, err := Load(configPath, nil, nil)
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+
+		data, err := cfg.MarshalTOML()
+		if err != nil {
+			t.Fatalf("MarshalTOML() error = %v", err)
+		}
+
+		dumpPath := filepath.Join(tmpDir, "dump.toml")
+		if err := os.WriteFile(dumpPath, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		reloaded, err := Load(dumpPath, nil, nil)
+		if err != nil {
+			t.Fatalf("Load() of dumped config error = %v\n%s", err, data)
+		}
+
+		if !reflect.DeepEqual(cfg, reloaded) {
+			t.Errorf("reloaded config differs from original\ndumped:\n%s", data)
+		}
+
+		// Nested configs and lists come back as written
+		if reloaded.Etcd.ClientPort == nil || *reloaded.Etcd.ClientPort != 9999 {
+			t.Errorf("Etcd.ClientPort = %v, want 9999", reloaded.Etcd.ClientPort)
+		}
+		if !reflect.DeepEqual(reloaded.Etcd.Endpoints, []string{"http://127.0.0.1:9999"}) {
+			t.Errorf("Etcd.Endpoints = %v", reloaded.Etcd.Endpoints)
+		}
+		if !reflect.DeepEqual(reloaded.TLS.AdditionalNames, []string{"miren.example.com", "alt.example.com"}) {
+			t.Errorf("TLS.AdditionalNames = %v", reloaded.TLS.AdditionalNames)
+		}
+		if got := reloaded.Buildkit.GetGcKeepStorage(); got != 512*MiB {
+			t.Errorf("Buildkit.GcKeepStorage = %v, want 512MiB", got)
+		}
+	})
+}
+
+func TestDurationFields(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "server.toml")
+
+	tests := []struct {
+		name          string
+		configContent string
+		envVars       map[string]string
+		args          []string
+		want          time.Duration
+		wantErr       bool
+	}{
+		{
+			name: "default",
+			want: time.Minute,
+		},
+		{
+			name: "config string",
+			configContent: `[server]
+http_request_timeout = "90s"`,
+			want: 90 * time.Second,
+		},
+		{
+			name: "config integer is seconds",
+			configContent: `[server]
+http_request_timeout = 30`,
+			want: 30 * time.Second,
+		},
+		{
+			name: "invalid config string",
+			configContent: `[server]
+http_request_timeout = "soon"`,
+			wantErr: true,
+		},
+		{
+			name: "env var",
+			configContent: `[server]
+http_request_timeout = "90s"`,
+			envVars: map[string]string{"MIREN_SERVER_HTTP_REQUEST_TIMEOUT": "2m30s"},
+			want:    150 * time.Second,
+		},
+		{
+			name: "CLI flag",
+			configContent: `[server]
+http_request_timeout = "90s"`,
+			envVars: map[string]string{"MIREN_SERVER_HTTP_REQUEST_TIMEOUT": "2m"},
+			args:    []string{"--http-request-timeout=5m"},
+			want:    5 * time.Minute,
+		},
+		{
+			name: "CLI flag integer is seconds",
+			args: []string{"--http-request-timeout", "90"},
+			want: 90 * time.Second,
+		},
+		{
+			name: "below minimum",
+			configContent: `[server]
+http_request_timeout = "500ms"`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.WriteFile(configPath, []byte(tt.configContent), 0644); err != nil {
+				t.Fatalf("failed to write config: %v", err)
+			}
+
+			for k, v := range tt.envVars {
+				t.Setenv(k, v)
+			}
+
+			opts := NewCLIFlags()
+			if _, err := flags.NewParser(opts, flags.Default).ParseArgs(tt.args); err != nil {
+				t.Fatalf("failed to parse flags: %v", err)
+			}
+
+			cfg, err := Load(configPath, opts, nil)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got timeout %v", cfg.Server.GetHTTPRequestTimeout())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+
+			if got := cfg.Server.GetHTTPRequestTimeout(); got != tt.want {
+				t.Errorf("HTTPRequestTimeout = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidationAggregatesErrors(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.SetMode("clustered")
+	cfg.Etcd.SetClientPort(70000)
+	cfg.Etcd.SetPeerPort(0)
+	cfg.Server.SetAddress("no-port")
+	cfg.TLS.AdditionalIPs = []string{"10.0.0.1", "not-an-ip"}
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("expected validation to fail")
+	}
+
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected a *ValidationError, got %T", err)
+	}
+
+	want := []string{
+		"etcd: client_port must be between 1 and 65535, got 70000",
+		"etcd: peer_port must be between 1 and 65535, got 0",
+		`invalid mode "clustered": must be one of [standalone distributed]`,
+		`server: invalid address "no-port": address no-port: missing port in address`,
+		`tls: invalid IP address "not-an-ip" in additional_ips`,
+	}
+
+	var got []string
+	for _, e := range verr.Unwrap() {
+		got = append(got, e.Error())
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Validate() errors =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if err.Error() != strings.Join(want, "\n") {
+		t.Errorf("Error() = %q", err.Error())
+	}
+
+	// A valid config reports no error at all, not an empty ValidationError.
+	if err := DefaultConfig().Validate(); err != nil {
+		t.Errorf("default config should be valid, got %v", err)
+	}
+
+	// Nested configs report their own problems without a prefix.
+	etcd := DefaultEtcdConfig()
+	etcd.SetClientPort(0)
+	if err := etcd.Validate(); err == nil || err.Error() != "client_port must be between 1 and 65535, got 0" {
+		t.Errorf("EtcdConfig.Validate() = %v", err)
+	}
+}
+
+func TestRequiredFieldsNameTheirSources(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Etcd.SetStartEmbedded(false)
+	cfg.Etcd.Endpoints = nil
+
+	err := cfg.Validate()
+	want := "etcd: endpoints must be set when start_embedded=false; set it with etcd.endpoints, MIREN_ETCD_ENDPOINTS or --etcd"
+	if err == nil || err.Error() != want {
+		t.Errorf("Validate() = %v, want %q", err, want)
+	}
+
+	cfg.Etcd.Endpoints = []string{"http://etcd:2379"}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+}
+
+func TestEnvStringLists(t *testing.T) {
+	tests := []struct {
+		name    string
+		val     string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "comma-separated",
+			val:  " a, b ,,a,c ",
+			want: []string{"a", "b", "c"},
+		},
+		{
+			name: "JSON array keeps commas",
+			val:  `["app=web,tier=frontend", "env=prod"]`,
+			want: []string{"app=web,tier=frontend", "env=prod"},
+		},
+		{
+			name: "JSON array is used as is",
+			val:  ` [" a ", " a "] `,
+			want: []string{" a ", " a "},
+		},
+		{
+			name:    "malformed JSON falls back to comma-separated",
+			val:     `["a", "b",]`,
+			want:    []string{`["a"`, `"b"`, `]`},
+			wantErr: true,
+		},
+		{
+			name: "bracketed IPv6 addresses aren't JSON",
+			val:  "[::1]:2379,[::2]:2379",
+			want: []string{"[::1]:2379", "[::2]:2379"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseStringList(tt.val)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseStringList() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseStringList() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("applied from the environment", func(t *testing.T) {
+		t.Setenv("MIREN_TLS_ADDITIONAL_NAMES", `["a.example.com", "b.example.com"]`)
+		t.Setenv("MIREN_ETCD_ENDPOINTS", "http://etcd1:2379, http://etcd2:2379")
+
+		cfg := DefaultConfig()
+		if err := applyEnvironmentVariables(cfg, EnvPrefix, slog.Default()); err != nil {
+			t.Fatal(err)
+		}
+
+		if want := []string{"a.example.com", "b.example.com"}; !reflect.DeepEqual(cfg.TLS.AdditionalNames, want) {
+			t.Errorf("TLS.AdditionalNames = %q, want %q", cfg.TLS.AdditionalNames, want)
+		}
+		if want := []string{"http://etcd1:2379", "http://etcd2:2379"}; !reflect.DeepEqual(cfg.Etcd.Endpoints, want) {
+			t.Errorf("Etcd.Endpoints = %q, want %q", cfg.Etcd.Endpoints, want)
+		}
+	})
+}
+
+func TestConfigString(t *testing.T) {
+	t.Run("nil receiver", func(t *testing.T) {
+		var cfg *Config
+		if got := cfg.String(); got != "<nil>" {
+			t.Errorf("String() = %q, want <nil>", got)
+		}
+	})
+
+	t.Run("renders set values and nested configs", func(t *testing.T) {
+		cfg := &Config{}
+		cfg.SetMode("distributed")
+		cfg.Server.SetHTTPRequestTimeout(90 * time.Second)
+		cfg.Etcd.SetClientPort(2379)
+		cfg.Etcd.Endpoints = []string{"http://etcd1:2379"}
+
+		got := cfg.String()
+
+		for _, want := range []string{
+			`Mode: "distributed"`,
+			`Server: {Address: <nil>,`,
+			`HTTPRequestTimeout: 1m30s`,
+			`ClientPort: 2379`,
+			`Endpoints: ["http://etcd1:2379"]`,
+		} {
+			if !strings.Contains(got, want) {
+				t.Errorf("String() = %s\nmissing %s", got, want)
+			}
+		}
+	})
+}
+
+func TestLoadWithEnvPrefix(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "server.toml")
+	if err := os.WriteFile(configPath, []byte(`mode = "standalone"`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("MIREN_SERVER_ADDRESS", "0.0.0.0:1111")
+	t.Setenv("TEST_SERVER_ADDRESS", "0.0.0.0:2222")
+	t.Setenv("TEST_ETCD_START_EMBEDDED", "false")
+	t.Setenv("TEST_ETCD_ENDPOINTS", "http://etcd:2379")
+
+	t.Run("reads the prefixed variables", func(t *testing.T) {
+		cfg, err := LoadWithEnvPrefix("TEST", configPath, nil, slog.Default())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := cfg.Server.GetAddress(); got != "0.0.0.0:2222" {
+			t.Errorf("Server.Address = %q, want the TEST_ value", got)
+		}
+		if cfg.Etcd.GetStartEmbedded() {
+			t.Error("Etcd.StartEmbedded should be overridden by TEST_ETCD_START_EMBEDDED")
+		}
+	})
+
+	t.Run("Load uses the schema prefix", func(t *testing.T) {
+		cfg, err := Load(configPath, nil, slog.Default())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := cfg.Server.GetAddress(); got != "0.0.0.0:1111" {
+			t.Errorf("Server.Address = %q, want the MIREN_ value", got)
+		}
+		if !cfg.Etcd.GetStartEmbedded() {
+			t.Error("Etcd.StartEmbedded should ignore TEST_ variables")
+		}
+	})
+
+	t.Run("an empty prefix reads unprefixed names", func(t *testing.T) {
+		t.Setenv("SERVER_ADDRESS", "0.0.0.0:3333")
+
+		cfg, err := LoadWithEnvPrefix("", configPath, nil, slog.Default())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := cfg.Server.GetAddress(); got != "0.0.0.0:3333" {
+			t.Errorf("Server.Address = %q, want the unprefixed value", got)
+		}
+	})
+}
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want ByteSize
+	}{
+		{"0", 0},
+		{"512", 512},
+		{"512B", 512},
+		{"256MB", 256_000_000},
+		{"256MiB", 256 << 20},
+		{"1GiB", 1 << 30},
+		{"10GB", 10 * GB},
+		{"4kib", 4096},
+		{"2 TB", 2_000_000_000_000},
+		{" 64 KiB ", 64 * KiB},
+	}
+
+	for _, tt := range tests {
+		got, err := parseByteSize(tt.in)
+		if err != nil {
+			t.Errorf("parseByteSize(%q) error = %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseByteSize(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+
+		// String renders a value that parses back the same
+		if again, err := parseByteSize(got.String()); err != nil || again != got {
+			t.Errorf("parseByteSize(%q) = %d, %v; want %d", got.String(), again, err, got)
+		}
+	}
+
+	for _, bad := range []string{"", "MB", "-5MB", "1.5GB", "10 gigs", "10PB", "9000000TiB"} {
+		if _, err := parseByteSize(bad); err == nil {
+			t.Errorf("parseByteSize(%q) should fail", bad)
+		}
+	}
+
+	for size, want := range map[ByteSize]string{
+		0:           "0B",
+		100:         "100B",
+		3072:        "3KiB",
+		256 * MiB:   "256MiB",
+		256 * MB:    "256MB",
+		10 * GB:     "10GB",
+		1024 * GiB:  "1TiB",
+		2048 * 1000: "2000KiB",
+	} {
+		if got := size.String(); got != want {
+			t.Errorf("ByteSize(%d).String() = %q, want %q", int64(size), got, want)
+		}
+	}
+}
+
+func TestByteSizeFields(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "server.toml")
+
+	tests := []struct {
+		name          string
+		configContent string
+		envVars       map[string]string
+		args          []string
+		want          ByteSize
+		wantErr       bool
+	}{
+		{
+			name: "default",
+			want: 10 * GB,
+		},
+		{
+			name: "config string",
+			configContent: `[buildkit]
+gc_keep_storage = "256MB"`,
+			want: 256 * MB,
+		},
+		{
+			name: "config integer is bytes",
+			configContent: `[buildkit]
+gc_keep_storage = 1048576`,
+			want: MiB,
+		},
+		{
+			name: "invalid config string",
+			configContent: `[buildkit]
+gc_keep_storage = "lots"`,
+			wantErr: true,
+		},
+		{
+			name: "negative config integer",
+			configContent: `[buildkit]
+gc_keep_storage = -1`,
+			wantErr: true,
+		},
+		{
+			name: "env var",
+			configContent: `[buildkit]
+gc_keep_storage = "256MB"`,
+			envVars: map[string]string{"MIREN_BUILDKIT_GC_KEEP_STORAGE": "1GiB"},
+			want:    GiB,
+		},
+		{
+			name: "CLI flag",
+			configContent: `[buildkit]
+gc_keep_storage = "256MB"`,
+			envVars: map[string]string{"MIREN_BUILDKIT_GC_KEEP_STORAGE": "1GiB"},
+			args:    []string{"--buildkit-gc-storage=50GiB"},
+			want:    50 * GiB,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.WriteFile(configPath, []byte(tt.configContent), 0644); err != nil {
+				t.Fatalf("failed to write config: %v", err)
+			}
+
+			for k, v := range tt.envVars {
+				t.Setenv(k, v)
+			}
+
+			opts := NewCLIFlags()
+			if _, err := flags.NewParser(opts, flags.Default).ParseArgs(tt.args); err != nil {
+				t.Fatalf("failed to parse flags: %v", err)
+			}
+
+			cfg, err := Load(configPath, opts, nil)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got size %v", cfg.Buildkit.GetGcKeepStorage())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+
+			if got := cfg.Buildkit.GetGcKeepStorage(); got != tt.want {
+				t.Errorf("GcKeepStorage = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeDurationInArrayOfTables(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		want    []any
+		wantErr string
+	}{
+		{
+			name:   "empty",
+			config: `listener = []`,
+			want:   []any{},
+		},
+		{
+			name: "single",
+			config: `[[listener]]
+idle_timeout = "2m"`,
+			want: []any{map[string]any{"idle_timeout": int64(2 * time.Minute)}},
+		},
+		{
+			name: "multiple",
+			config: `[[listener]]
+idle_timeout = 30
+
+[[listener]]
+name = "internal"
+
+[[listener]]
+idle_timeout = "1h"`,
+			want: []any{
+				map[string]any{"idle_timeout": int64(30 * time.Second)},
+				map[string]any{"name": "internal"},
+				map[string]any{"idle_timeout": int64(time.Hour)},
+			},
+		},
+		{
+			name: "invalid element",
+			config: `[[listener]]
+idle_timeout = "1m"
+
+[[listener]]
+idle_timeout = "soon"`,
+			wantErr: "invalid duration for listener[1].idle_timeout",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var doc map[string]any
+			if err := toml.Unmarshal([]byte(tt.config), &doc); err != nil {
+				t.Fatal(err)
+			}
+
+			err := normalizeDuration(doc, "listener", "idle_timeout")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("normalizeDuration() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(doc["listener"], tt.want) {
+				t.Errorf("listener = %#v, want %#v", doc["listener"], tt.want)
+			}
+		})
+	}
+}
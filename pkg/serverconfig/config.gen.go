@@ -3,16 +3,132 @@
 package serverconfig
 
 import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
 	"time"
 )
 
+// ByteSize is a count of bytes, written in config as a string such as "256MB"
+// or "1GiB". A bare integer is a count of bytes.
+type ByteSize int64
+
+// Common byte sizes, in decimal and binary units
+const (
+	KB ByteSize = 1000
+	MB          = 1000 * KB
+	GB          = 1000 * MB
+	TB          = 1000 * GB
+
+	KiB ByteSize = 1 << 10
+	MiB          = 1 << 20
+	GiB          = 1 << 30
+	TiB          = 1 << 40
+)
+
+// byteSizeUnits are the suffixes accepted by parseByteSize, matched without
+// regard to case
+var byteSizeUnits = map[string]ByteSize{
+	"":    1,
+	"b":   1,
+	"kb":  KB,
+	"mb":  MB,
+	"gb":  GB,
+	"tb":  TB,
+	"kib": KiB,
+	"mib": MiB,
+	"gib": GiB,
+	"tib": TiB,
+}
+
+// parseByteSize parses a byte size such as "256MB" or "1GiB". Units may be
+// separated from the number by a space.
+func parseByteSize(s string) (ByteSize, error) {
+	s = strings.TrimSpace(s)
+	num := strings.TrimRight(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ ")
+
+	unit, ok := byteSizeUnits[strings.ToLower(strings.TrimSpace(s[len(num):]))]
+	if !ok {
+		return 0, fmt.Errorf("unknown unit in byte size %q", s)
+	}
+
+	n, err := strconv.ParseInt(num, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid byte size %q", s)
+	}
+	if n > math.MaxInt64/int64(unit) {
+		return 0, fmt.Errorf("byte size %q is too large", s)
+	}
+
+	return ByteSize(n) * unit, nil
+}
+
+// Bytes returns the size as a count of bytes
+func (b ByteSize) Bytes() int64 {
+	return int64(b)
+}
+
+// String renders the size in the largest unit that holds it exactly, such as
+// "256MiB", so that it parses back to the same value
+func (b ByteSize) String() string {
+	units := []struct {
+		size ByteSize
+		name string
+	}{
+		{TiB, "TiB"},
+		{TB, "TB"},
+		{GiB, "GiB"},
+		{GB, "GB"},
+		{MiB, "MiB"},
+		{MB, "MB"},
+		{KiB, "KiB"},
+		{KB, "KB"},
+	}
+
+	for _, u := range units {
+		if b != 0 && b%u.size == 0 {
+			return strconv.FormatInt(int64(b/u.size), 10) + u.name
+		}
+	}
+
+	return strconv.FormatInt(int64(b), 10) + "B"
+}
+
+// UnmarshalFlag parses a byte size given as a command-line flag
+func (b *ByteSize) UnmarshalFlag(value string) error {
+	size, err := parseByteSize(value)
+	if err != nil {
+		return err
+	}
+
+	*b = size
+	return nil
+}
+
+// Duration is a length of time given as a command-line flag, in Go duration
+// syntax such as "30s". A bare integer is taken as seconds, as it is in the
+// config file and environment.
+type Duration time.Duration
+
+// UnmarshalFlag parses a duration given as a command-line flag
+func (d *Duration) UnmarshalFlag(value string) error {
+	v, err := parseDuration(value)
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(v)
+	return nil
+}
+
 // BuildkitConfig BuildKit daemon configuration
 type BuildkitConfig struct {
-	GcKeepDuration *string `toml:"gc_keep_duration" env:"MIREN_BUILDKIT_GC_KEEP_DURATION"`
-	GcKeepStorage  *string `toml:"gc_keep_storage" env:"MIREN_BUILDKIT_GC_KEEP_STORAGE"`
-	SocketDir      *string `toml:"socket_dir" env:"MIREN_BUILDKIT_SOCKET_DIR"`
-	SocketPath     *string `toml:"socket_path" env:"MIREN_BUILDKIT_SOCKET_PATH"`
-	StartEmbedded  *bool   `toml:"start_embedded" env:"MIREN_BUILDKIT_START_EMBEDDED"`
+	GcKeepDuration *string   `toml:"gc_keep_duration" env:"MIREN_BUILDKIT_GC_KEEP_DURATION"`
+	GcKeepStorage  *ByteSize `toml:"gc_keep_storage" env:"MIREN_BUILDKIT_GC_KEEP_STORAGE"`
+	SocketDir      *string   `toml:"socket_dir" env:"MIREN_BUILDKIT_SOCKET_DIR"`
+	SocketPath     *string   `toml:"socket_path" env:"MIREN_BUILDKIT_SOCKET_PATH"`
+	StartEmbedded  *bool     `toml:"start_embedded" env:"MIREN_BUILDKIT_START_EMBEDDED"`
 }
 
 // GetGcKeepDuration returns the value of GcKeepDuration or its zero value if nil
@@ -29,15 +145,15 @@ func (c *BuildkitConfig) SetGcKeepDuration(v string) {
 }
 
 // GetGcKeepStorage returns the value of GcKeepStorage or its zero value if nil
-func (c *BuildkitConfig) GetGcKeepStorage() string {
+func (c *BuildkitConfig) GetGcKeepStorage() ByteSize {
 	if c.GcKeepStorage != nil {
 		return *c.GcKeepStorage
 	}
-	return ""
+	return 0
 }
 
 // SetGcKeepStorage sets the value of GcKeepStorage
-func (c *BuildkitConfig) SetGcKeepStorage(v string) {
+func (c *BuildkitConfig) SetGcKeepStorage(v ByteSize) {
 	c.GcKeepStorage = &v
 }
 
@@ -153,12 +269,13 @@ func (c *ContainerdConfig) SetStartEmbedded(v bool) {
 
 // EtcdConfig Etcd configuration
 type EtcdConfig struct {
-	ClientPort     *int     `toml:"client_port" env:"MIREN_ETCD_CLIENT_PORT"`
-	Endpoints      []string `toml:"endpoints" env:"MIREN_ETCD_ENDPOINTS"`
-	HTTPClientPort *int     `toml:"http_client_port" env:"MIREN_ETCD_HTTP_CLIENT_PORT"`
-	PeerPort       *int     `toml:"peer_port" env:"MIREN_ETCD_PEER_PORT"`
-	Prefix         *string  `toml:"prefix" env:"MIREN_ETCD_PREFIX"`
-	StartEmbedded  *bool    `toml:"start_embedded" env:"MIREN_ETCD_START_EMBEDDED"`
+	ClientPort      *int           `toml:"client_port" env:"MIREN_ETCD_CLIENT_PORT"`
+	DeleteRetention *time.Duration `toml:"delete_retention" env:"MIREN_ETCD_DELETE_RETENTION"`
+	Endpoints       []string       `toml:"endpoints" env:"MIREN_ETCD_ENDPOINTS"`
+	HTTPClientPort  *int           `toml:"http_client_port" env:"MIREN_ETCD_HTTP_CLIENT_PORT"`
+	PeerPort        *int           `toml:"peer_port" env:"MIREN_ETCD_PEER_PORT"`
+	Prefix          *string        `toml:"prefix" env:"MIREN_ETCD_PREFIX"`
+	StartEmbedded   *bool          `toml:"start_embedded" env:"MIREN_ETCD_START_EMBEDDED"`
 }
 
 // GetClientPort returns the value of ClientPort or its zero value if nil
@@ -174,6 +291,19 @@ func (c *EtcdConfig) SetClientPort(v int) {
 	c.ClientPort = &v
 }
 
+// GetDeleteRetention returns the value of DeleteRetention or its zero value if nil
+func (c *EtcdConfig) GetDeleteRetention() time.Duration {
+	if c.DeleteRetention != nil {
+		return *c.DeleteRetention
+	}
+	return 0
+}
+
+// SetDeleteRetention sets the value of DeleteRetention
+func (c *EtcdConfig) SetDeleteRetention(v time.Duration) {
+	c.DeleteRetention = &v
+}
+
 // GetHTTPClientPort returns the value of HTTPClientPort or its zero value if nil
 func (c *EtcdConfig) GetHTTPClientPort() int {
 	if c.HTTPClientPort != nil {
@@ -228,15 +358,18 @@ func (c *EtcdConfig) SetStartEmbedded(v bool) {
 
 // ServerConfig Core server settings
 type ServerConfig struct {
-	Address                 *string `toml:"address" env:"MIREN_SERVER_ADDRESS"`
-	ConfigClusterName       *string `toml:"config_cluster_name" env:"MIREN_SERVER_CONFIG_CLUSTER_NAME"`
-	DataPath                *string `toml:"data_path" env:"MIREN_SERVER_DATA_PATH"`
-	HTTPRequestTimeout      *int    `toml:"http_request_timeout" env:"MIREN_SERVER_HTTP_REQUEST_TIMEOUT"`
-	ReleasePath             *string `toml:"release_path" env:"MIREN_SERVER_RELEASE_PATH"`
-	RunnerAddress           *string `toml:"runner_address" env:"MIREN_SERVER_RUNNER_ADDRESS"`
-	RunnerID                *string `toml:"runner_id" env:"MIREN_SERVER_RUNNER_ID"`
-	SkipClientConfig        *bool   `toml:"skip_client_config" env:"MIREN_SERVER_SKIP_CLIENT_CONFIG"`
-	StopSandboxesOnShutdown *bool   `toml:"stop_sandboxes_on_shutdown" env:"MIREN_SERVER_STOP_SANDBOXES_ON_SHUTDOWN"`
+	Address                  *string        `toml:"address" env:"MIREN_SERVER_ADDRESS"`
+	ConfigClusterName        *string        `toml:"config_cluster_name" env:"MIREN_SERVER_CONFIG_CLUSTER_NAME"`
+	DataPath                 *string        `toml:"data_path" env:"MIREN_SERVER_DATA_PATH"`
+	HTTPRequestTimeout       *time.Duration `toml:"http_request_timeout" env:"MIREN_SERVER_HTTP_REQUEST_TIMEOUT"`
+	PreemptionNoticeURL      *string        `toml:"preemption_notice_url" env:"MIREN_SERVER_PREEMPTION_NOTICE_URL"`
+	ReleasePath              *string        `toml:"release_path" env:"MIREN_SERVER_RELEASE_PATH"`
+	RunnerAddress            *string        `toml:"runner_address" env:"MIREN_SERVER_RUNNER_ADDRESS"`
+	RunnerID                 *string        `toml:"runner_id" env:"MIREN_SERVER_RUNNER_ID"`
+	SandboxHostPathAllowlist []string       `toml:"sandbox_host_path_allowlist" env:"MIREN_SERVER_SANDBOX_HOST_PATH_ALLOWLIST"`
+	SandboxSysctlAllowlist   []string       `toml:"sandbox_sysctl_allowlist" env:"MIREN_SERVER_SANDBOX_SYSCTL_ALLOWLIST"`
+	SkipClientConfig         *bool          `toml:"skip_client_config" env:"MIREN_SERVER_SKIP_CLIENT_CONFIG"`
+	StopSandboxesOnShutdown  *bool          `toml:"stop_sandboxes_on_shutdown" env:"MIREN_SERVER_STOP_SANDBOXES_ON_SHUTDOWN"`
 }
 
 // GetAddress returns the value of Address or its zero value if nil
@@ -279,7 +412,7 @@ func (c *ServerConfig) SetDataPath(v string) {
 }
 
 // GetHTTPRequestTimeout returns the value of HTTPRequestTimeout or its zero value if nil
-func (c *ServerConfig) GetHTTPRequestTimeout() int {
+func (c *ServerConfig) GetHTTPRequestTimeout() time.Duration {
 	if c.HTTPRequestTimeout != nil {
 		return *c.HTTPRequestTimeout
 	}
@@ -287,10 +420,23 @@ func (c *ServerConfig) GetHTTPRequestTimeout() int {
 }
 
 // SetHTTPRequestTimeout sets the value of HTTPRequestTimeout
-func (c *ServerConfig) SetHTTPRequestTimeout(v int) {
+func (c *ServerConfig) SetHTTPRequestTimeout(v time.Duration) {
 	c.HTTPRequestTimeout = &v
 }
 
+// GetPreemptionNoticeURL returns the value of PreemptionNoticeURL or its zero value if nil
+func (c *ServerConfig) GetPreemptionNoticeURL() string {
+	if c.PreemptionNoticeURL != nil {
+		return *c.PreemptionNoticeURL
+	}
+	return ""
+}
+
+// SetPreemptionNoticeURL sets the value of PreemptionNoticeURL
+func (c *ServerConfig) SetPreemptionNoticeURL(v string) {
+	c.PreemptionNoticeURL = &v
+}
+
 // GetReleasePath returns the value of ReleasePath or its zero value if nil
 func (c *ServerConfig) GetReleasePath() string {
 	if c.ReleasePath != nil {
@@ -406,10 +552,12 @@ func (c *TLSConfig) SetStandardTLS(v bool) {
 
 // VictoriaLogsConfig VictoriaLogs configuration
 type VictoriaLogsConfig struct {
-	Address         *string `toml:"address" env:"MIREN_VICTORIALOGS_ADDRESS"`
-	HTTPPort        *int    `toml:"http_port" env:"MIREN_VICTORIALOGS_HTTP_PORT"`
-	RetentionPeriod *string `toml:"retention_period" env:"MIREN_VICTORIALOGS_RETENTION_PERIOD"`
-	StartEmbedded   *bool   `toml:"start_embedded" env:"MIREN_VICTORIALOGS_START_EMBEDDED"`
+	Address         *string        `toml:"address" env:"MIREN_VICTORIALOGS_ADDRESS"`
+	ArchiveAfter    *time.Duration `toml:"archive_after" env:"MIREN_VICTORIALOGS_ARCHIVE_AFTER"`
+	ArchiveDir      *string        `toml:"archive_dir" env:"MIREN_VICTORIALOGS_ARCHIVE_DIR"`
+	HTTPPort        *int           `toml:"http_port" env:"MIREN_VICTORIALOGS_HTTP_PORT"`
+	RetentionPeriod *string        `toml:"retention_period" env:"MIREN_VICTORIALOGS_RETENTION_PERIOD"`
+	StartEmbedded   *bool          `toml:"start_embedded" env:"MIREN_VICTORIALOGS_START_EMBEDDED"`
 }
 
 // GetAddress returns the value of Address or its zero value if nil
@@ -425,6 +573,32 @@ func (c *VictoriaLogsConfig) SetAddress(v string) {
 	c.Address = &v
 }
 
+// GetArchiveAfter returns the value of ArchiveAfter or its zero value if nil
+func (c *VictoriaLogsConfig) GetArchiveAfter() time.Duration {
+	if c.ArchiveAfter != nil {
+		return *c.ArchiveAfter
+	}
+	return 0
+}
+
+// SetArchiveAfter sets the value of ArchiveAfter
+func (c *VictoriaLogsConfig) SetArchiveAfter(v time.Duration) {
+	c.ArchiveAfter = &v
+}
+
+// GetArchiveDir returns the value of ArchiveDir or its zero value if nil
+func (c *VictoriaLogsConfig) GetArchiveDir() string {
+	if c.ArchiveDir != nil {
+		return *c.ArchiveDir
+	}
+	return ""
+}
+
+// SetArchiveDir sets the value of ArchiveDir
+func (c *VictoriaLogsConfig) SetArchiveDir(v string) {
+	c.ArchiveDir = &v
+}
+
 // GetHTTPPort returns the value of HTTPPort or its zero value if nil
 func (c *VictoriaLogsConfig) GetHTTPPort() int {
 	if c.HTTPPort != nil {
@@ -523,11 +697,3 @@ func (c *VictoriaMetricsConfig) GetStartEmbedded() bool {
 func (c *VictoriaMetricsConfig) SetStartEmbedded(v bool) {
 	c.StartEmbedded = &v
 }
-
-// HTTPRequestTimeoutDuration returns the timeout as a time.Duration
-func (c *ServerConfig) HTTPRequestTimeoutDuration() time.Duration {
-	if c.HTTPRequestTimeout != nil {
-		return time.Duration(*c.HTTPRequestTimeout) * time.Second
-	}
-	return 60 * time.Second // default
-}
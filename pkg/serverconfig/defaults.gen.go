@@ -2,10 +2,16 @@
 
 package serverconfig
 
+import (
+	"time"
+)
+
 // Helper functions for creating pointers to literals
-func boolPtr(b bool) *bool    { return &b }
-func intPtr(i int) *int       { return &i }
-func strPtr(s string) *string { return &s }
+func boolPtr(b bool) *bool                       { return &b }
+func intPtr(i int) *int                          { return &i }
+func strPtr(s string) *string                    { return &s }
+func durationPtr(d time.Duration) *time.Duration { return &d }
+func byteSizePtr(b ByteSize) *ByteSize           { return &b }
 
 // DefaultConfig returns the default configuration
 func DefaultConfig() *Config {
@@ -25,7 +31,7 @@ func DefaultConfig() *Config {
 func DefaultBuildkitConfig() BuildkitConfig {
 	return BuildkitConfig{
 		GcKeepDuration: strPtr("7d"),
-		GcKeepStorage:  strPtr("10GB"),
+		GcKeepStorage:  byteSizePtr(10 * GB),
 		SocketDir:      strPtr(""),
 		SocketPath:     strPtr(""),
 		StartEmbedded:  nil,
@@ -44,27 +50,31 @@ func DefaultContainerdConfig() ContainerdConfig {
 // DefaultEtcdConfig returns default EtcdConfig
 func DefaultEtcdConfig() EtcdConfig {
 	return EtcdConfig{
-		ClientPort:     intPtr(12379),
-		Endpoints:      []string{},
-		HTTPClientPort: intPtr(12381),
-		PeerPort:       intPtr(12380),
-		Prefix:         strPtr("/miren"),
-		StartEmbedded:  nil,
+		ClientPort:      intPtr(12379),
+		DeleteRetention: durationPtr(time.Duration(0)),
+		Endpoints:       []string{},
+		HTTPClientPort:  intPtr(12381),
+		PeerPort:        intPtr(12380),
+		Prefix:          strPtr("/miren"),
+		StartEmbedded:   nil,
 	}
 }
 
 // DefaultServerConfig returns default ServerConfig
 func DefaultServerConfig() ServerConfig {
 	return ServerConfig{
-		Address:                 strPtr(":8443"),
-		ConfigClusterName:       strPtr("local"),
-		DataPath:                strPtr("/var/lib/miren"),
-		HTTPRequestTimeout:      intPtr(60),
-		ReleasePath:             strPtr(""),
-		RunnerAddress:           strPtr("localhost:8444"),
-		RunnerID:                strPtr("miren"),
-		SkipClientConfig:        boolPtr(false),
-		StopSandboxesOnShutdown: boolPtr(false),
+		Address:                  strPtr(":8443"),
+		ConfigClusterName:        strPtr("local"),
+		DataPath:                 strPtr("/var/lib/miren"),
+		HTTPRequestTimeout:       durationPtr(1 * time.Minute),
+		PreemptionNoticeURL:      strPtr(""),
+		ReleasePath:              strPtr(""),
+		RunnerAddress:            strPtr("localhost:8444"),
+		RunnerID:                 strPtr("miren"),
+		SandboxHostPathAllowlist: []string{},
+		SandboxSysctlAllowlist:   []string{},
+		SkipClientConfig:         boolPtr(false),
+		StopSandboxesOnShutdown:  boolPtr(false),
 	}
 }
 
@@ -83,6 +93,8 @@ func DefaultTLSConfig() TLSConfig {
 func DefaultVictoriaLogsConfig() VictoriaLogsConfig {
 	return VictoriaLogsConfig{
 		Address:         strPtr("victorialogs:9428"),
+		ArchiveAfter:    durationPtr(168 * time.Hour),
+		ArchiveDir:      strPtr(""),
 		HTTPPort:        intPtr(9428),
 		RetentionPeriod: strPtr("30d"),
 		StartEmbedded:   nil,
@@ -3,411 +3,507 @@
 package serverconfig
 
 import (
+	"encoding/json"
 	"log/slog"
 	"os"
 	"strconv"
 	"strings"
 )
 
-// applyEnvironmentVariables applies environment variables to the configuration
-func applyEnvironmentVariables(cfg *Config, log *slog.Logger) error {
+// EnvPrefix is joined with the names of the environment variables Load reads
+const EnvPrefix = "MIREN"
+
+// envName joins an environment variable name with prefix, if there is one
+func envName(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "_" + name
+}
+
+// lookupEnv returns an environment variable's value along with its name
+func lookupEnv(name string) (string, string) {
+	return name, os.Getenv(name)
+}
+
+// applyEnvironmentVariables applies environment variables, with names joined
+// with envPrefix, to the configuration.
+// List values may be a JSON array of strings, such as ["a,b", "c"], for items
+// containing commas. Any other value, or a JSON array that fails to decode, is
+// taken as a comma-separated list.
+func applyEnvironmentVariables(cfg *Config, envPrefix string, log *slog.Logger) error {
 
 	// Apply MIREN_BUILDKIT_GC_KEEP_DURATION
-	if val := os.Getenv("MIREN_BUILDKIT_GC_KEEP_DURATION"); val != "" {
+	if key, val := lookupEnv(envName(envPrefix, "BUILDKIT_GC_KEEP_DURATION")); val != "" {
 
 		cfg.Buildkit.GcKeepDuration = &val
-		log.Debug("applied env var", "key", "MIREN_BUILDKIT_GC_KEEP_DURATION")
+		log.Debug("applied env var", "key", key)
 
 	}
 
 	// Apply MIREN_BUILDKIT_GC_KEEP_STORAGE
-	if val := os.Getenv("MIREN_BUILDKIT_GC_KEEP_STORAGE"); val != "" {
+	if key, val := lookupEnv(envName(envPrefix, "BUILDKIT_GC_KEEP_STORAGE")); val != "" {
 
-		cfg.Buildkit.GcKeepStorage = &val
-		log.Debug("applied env var", "key", "MIREN_BUILDKIT_GC_KEEP_STORAGE")
+		if b, err := parseByteSize(val); err == nil {
+			cfg.Buildkit.GcKeepStorage = &b
+			log.Debug("applied env var", "key", key)
+		} else {
+			log.Warn("invalid env var value", "key", key, "value", val, "error", err)
+		}
 
 	}
 
 	// Apply MIREN_BUILDKIT_SOCKET_DIR
-	if val := os.Getenv("MIREN_BUILDKIT_SOCKET_DIR"); val != "" {
+	if key, val := lookupEnv(envName(envPrefix, "BUILDKIT_SOCKET_DIR")); val != "" {
 
 		cfg.Buildkit.SocketDir = &val
-		log.Debug("applied env var", "key", "MIREN_BUILDKIT_SOCKET_DIR")
+		log.Debug("applied env var", "key", key)
 
 	}
 
 	// Apply MIREN_BUILDKIT_SOCKET_PATH
-	if val := os.Getenv("MIREN_BUILDKIT_SOCKET_PATH"); val != "" {
+	if key, val := lookupEnv(envName(envPrefix, "BUILDKIT_SOCKET_PATH")); val != "" {
 
 		cfg.Buildkit.SocketPath = &val
-		log.Debug("applied env var", "key", "MIREN_BUILDKIT_SOCKET_PATH")
+		log.Debug("applied env var", "key", key)
 
 	}
 
 	// Apply MIREN_BUILDKIT_START_EMBEDDED
-	if val := os.Getenv("MIREN_BUILDKIT_START_EMBEDDED"); val != "" {
+	if key, val := lookupEnv(envName(envPrefix, "BUILDKIT_START_EMBEDDED")); val != "" {
 
 		if b, err := strconv.ParseBool(val); err == nil {
 			cfg.Buildkit.StartEmbedded = &b
-			log.Debug("applied env var", "key", "MIREN_BUILDKIT_START_EMBEDDED")
+			log.Debug("applied env var", "key", key)
 		} else {
-			log.Warn("invalid MIREN_BUILDKIT_START_EMBEDDED value", "value", val, "error", err)
+			log.Warn("invalid env var value", "key", key, "value", val, "error", err)
 		}
 
 	}
 
 	// Apply MIREN_MODE
-	if val := os.Getenv("MIREN_MODE"); val != "" {
+	if key, val := lookupEnv(envName(envPrefix, "MODE")); val != "" {
 
 		cfg.Mode = &val
-		log.Debug("applied env var", "key", "MIREN_MODE")
+		log.Debug("applied env var", "key", key)
 
 	}
 
 	// Apply MIREN_CONTAINERD_BINARY_PATH
-	if val := os.Getenv("MIREN_CONTAINERD_BINARY_PATH"); val != "" {
+	if key, val := lookupEnv(envName(envPrefix, "CONTAINERD_BINARY_PATH")); val != "" {
 
 		cfg.Containerd.BinaryPath = &val
-		log.Debug("applied env var", "key", "MIREN_CONTAINERD_BINARY_PATH")
+		log.Debug("applied env var", "key", key)
 
 	}
 
 	// Apply MIREN_CONTAINERD_SOCKET_PATH
-	if val := os.Getenv("MIREN_CONTAINERD_SOCKET_PATH"); val != "" {
+	if key, val := lookupEnv(envName(envPrefix, "CONTAINERD_SOCKET_PATH")); val != "" {
 
 		cfg.Containerd.SocketPath = &val
-		log.Debug("applied env var", "key", "MIREN_CONTAINERD_SOCKET_PATH")
+		log.Debug("applied env var", "key", key)
 
 	}
 
 	// Apply MIREN_CONTAINERD_START_EMBEDDED
-	if val := os.Getenv("MIREN_CONTAINERD_START_EMBEDDED"); val != "" {
+	if key, val := lookupEnv(envName(envPrefix, "CONTAINERD_START_EMBEDDED")); val != "" {
 
 		if b, err := strconv.ParseBool(val); err == nil {
 			cfg.Containerd.StartEmbedded = &b
-			log.Debug("applied env var", "key", "MIREN_CONTAINERD_START_EMBEDDED")
+			log.Debug("applied env var", "key", key)
 		} else {
-			log.Warn("invalid MIREN_CONTAINERD_START_EMBEDDED value", "value", val, "error", err)
+			log.Warn("invalid env var value", "key", key, "value", val, "error", err)
 		}
 
 	}
 
 	// Apply MIREN_ETCD_CLIENT_PORT
-	if val := os.Getenv("MIREN_ETCD_CLIENT_PORT"); val != "" {
+	if key, val := lookupEnv(envName(envPrefix, "ETCD_CLIENT_PORT")); val != "" {
 
 		if i, err := strconv.Atoi(val); err == nil {
 			cfg.Etcd.ClientPort = &i
-			log.Debug("applied env var", "key", "MIREN_ETCD_CLIENT_PORT")
+			log.Debug("applied env var", "key", key)
+		} else {
+			log.Warn("invalid env var value", "key", key, "value", val, "error", err)
+		}
+
+	}
+
+	// Apply MIREN_ETCD_DELETE_RETENTION
+	if key, val := lookupEnv(envName(envPrefix, "ETCD_DELETE_RETENTION")); val != "" {
+
+		if d, err := parseDuration(val); err == nil {
+			cfg.Etcd.DeleteRetention = &d
+			log.Debug("applied env var", "key", key)
 		} else {
-			log.Warn("invalid MIREN_ETCD_CLIENT_PORT value", "value", val, "error", err)
+			log.Warn("invalid env var value", "key", key, "value", val, "error", err)
 		}
 
 	}
 
 	// Apply MIREN_ETCD_ENDPOINTS
-	if val := os.Getenv("MIREN_ETCD_ENDPOINTS"); val != "" {
-
-		// Split and clean CSV list
-		parts := strings.Split(val, ",")
-		cleaned := make([]string, 0, len(parts))
-		seen := make(map[string]struct{})
-		for _, p := range parts {
-			p = strings.TrimSpace(p)
-			if p == "" {
-				continue
-			}
-			if _, exists := seen[p]; exists {
-				continue
-			}
-			seen[p] = struct{}{}
-			cleaned = append(cleaned, p)
+	if key, val := lookupEnv(envName(envPrefix, "ETCD_ENDPOINTS")); val != "" {
+
+		list, err := parseStringList(val)
+		if err != nil {
+			log.Warn("invalid JSON in env var, treating it as comma-separated", "key", key, "value", val, "error", err)
 		}
-		cfg.Etcd.Endpoints = cleaned
-		log.Debug("applied env var", "key", "MIREN_ETCD_ENDPOINTS", "count", len(cleaned))
+		cfg.Etcd.Endpoints = list
+		log.Debug("applied env var", "key", key, "count", len(list))
 
 	}
 
 	// Apply MIREN_ETCD_HTTP_CLIENT_PORT
-	if val := os.Getenv("MIREN_ETCD_HTTP_CLIENT_PORT"); val != "" {
+	if key, val := lookupEnv(envName(envPrefix, "ETCD_HTTP_CLIENT_PORT")); val != "" {
 
 		if i, err := strconv.Atoi(val); err == nil {
 			cfg.Etcd.HTTPClientPort = &i
-			log.Debug("applied env var", "key", "MIREN_ETCD_HTTP_CLIENT_PORT")
+			log.Debug("applied env var", "key", key)
 		} else {
-			log.Warn("invalid MIREN_ETCD_HTTP_CLIENT_PORT value", "value", val, "error", err)
+			log.Warn("invalid env var value", "key", key, "value", val, "error", err)
 		}
 
 	}
 
 	// Apply MIREN_ETCD_PEER_PORT
-	if val := os.Getenv("MIREN_ETCD_PEER_PORT"); val != "" {
+	if key, val := lookupEnv(envName(envPrefix, "ETCD_PEER_PORT")); val != "" {
 
 		if i, err := strconv.Atoi(val); err == nil {
 			cfg.Etcd.PeerPort = &i
-			log.Debug("applied env var", "key", "MIREN_ETCD_PEER_PORT")
+			log.Debug("applied env var", "key", key)
 		} else {
-			log.Warn("invalid MIREN_ETCD_PEER_PORT value", "value", val, "error", err)
+			log.Warn("invalid env var value", "key", key, "value", val, "error", err)
 		}
 
 	}
 
 	// Apply MIREN_ETCD_PREFIX
-	if val := os.Getenv("MIREN_ETCD_PREFIX"); val != "" {
+	if key, val := lookupEnv(envName(envPrefix, "ETCD_PREFIX")); val != "" {
 
 		cfg.Etcd.Prefix = &val
-		log.Debug("applied env var", "key", "MIREN_ETCD_PREFIX")
+		log.Debug("applied env var", "key", key)
 
 	}
 
 	// Apply MIREN_ETCD_START_EMBEDDED
-	if val := os.Getenv("MIREN_ETCD_START_EMBEDDED"); val != "" {
+	if key, val := lookupEnv(envName(envPrefix, "ETCD_START_EMBEDDED")); val != "" {
 
 		if b, err := strconv.ParseBool(val); err == nil {
 			cfg.Etcd.StartEmbedded = &b
-			log.Debug("applied env var", "key", "MIREN_ETCD_START_EMBEDDED")
+			log.Debug("applied env var", "key", key)
 		} else {
-			log.Warn("invalid MIREN_ETCD_START_EMBEDDED value", "value", val, "error", err)
+			log.Warn("invalid env var value", "key", key, "value", val, "error", err)
 		}
 
 	}
 
 	// Apply MIREN_SERVER_ADDRESS
-	if val := os.Getenv("MIREN_SERVER_ADDRESS"); val != "" {
+	if key, val := lookupEnv(envName(envPrefix, "SERVER_ADDRESS")); val != "" {
 
 		cfg.Server.Address = &val
-		log.Debug("applied env var", "key", "MIREN_SERVER_ADDRESS")
+		log.Debug("applied env var", "key", key)
 
 	}
 
 	// Apply MIREN_SERVER_CONFIG_CLUSTER_NAME
-	if val := os.Getenv("MIREN_SERVER_CONFIG_CLUSTER_NAME"); val != "" {
+	if key, val := lookupEnv(envName(envPrefix, "SERVER_CONFIG_CLUSTER_NAME")); val != "" {
 
 		cfg.Server.ConfigClusterName = &val
-		log.Debug("applied env var", "key", "MIREN_SERVER_CONFIG_CLUSTER_NAME")
+		log.Debug("applied env var", "key", key)
 
 	}
 
 	// Apply MIREN_SERVER_DATA_PATH
-	if val := os.Getenv("MIREN_SERVER_DATA_PATH"); val != "" {
+	if key, val := lookupEnv(envName(envPrefix, "SERVER_DATA_PATH")); val != "" {
 
 		cfg.Server.DataPath = &val
-		log.Debug("applied env var", "key", "MIREN_SERVER_DATA_PATH")
+		log.Debug("applied env var", "key", key)
 
 	}
 
 	// Apply MIREN_SERVER_HTTP_REQUEST_TIMEOUT
-	if val := os.Getenv("MIREN_SERVER_HTTP_REQUEST_TIMEOUT"); val != "" {
+	if key, val := lookupEnv(envName(envPrefix, "SERVER_HTTP_REQUEST_TIMEOUT")); val != "" {
 
-		if i, err := strconv.Atoi(val); err == nil {
-			cfg.Server.HTTPRequestTimeout = &i
-			log.Debug("applied env var", "key", "MIREN_SERVER_HTTP_REQUEST_TIMEOUT")
+		if d, err := parseDuration(val); err == nil {
+			cfg.Server.HTTPRequestTimeout = &d
+			log.Debug("applied env var", "key", key)
 		} else {
-			log.Warn("invalid MIREN_SERVER_HTTP_REQUEST_TIMEOUT value", "value", val, "error", err)
+			log.Warn("invalid env var value", "key", key, "value", val, "error", err)
 		}
 
 	}
 
+	// Apply MIREN_SERVER_PREEMPTION_NOTICE_URL
+	if key, val := lookupEnv(envName(envPrefix, "SERVER_PREEMPTION_NOTICE_URL")); val != "" {
+
+		cfg.Server.PreemptionNoticeURL = &val
+		log.Debug("applied env var", "key", key)
+
+	}
+
 	// Apply MIREN_SERVER_RELEASE_PATH
-	if val := os.Getenv("MIREN_SERVER_RELEASE_PATH"); val != "" {
+	if key, val := lookupEnv(envName(envPrefix, "SERVER_RELEASE_PATH")); val != "" {
 
 		cfg.Server.ReleasePath = &val
-		log.Debug("applied env var", "key", "MIREN_SERVER_RELEASE_PATH")
+		log.Debug("applied env var", "key", key)
 
 	}
 
 	// Apply MIREN_SERVER_RUNNER_ADDRESS
-	if val := os.Getenv("MIREN_SERVER_RUNNER_ADDRESS"); val != "" {
+	if key, val := lookupEnv(envName(envPrefix, "SERVER_RUNNER_ADDRESS")); val != "" {
 
 		cfg.Server.RunnerAddress = &val
-		log.Debug("applied env var", "key", "MIREN_SERVER_RUNNER_ADDRESS")
+		log.Debug("applied env var", "key", key)
 
 	}
 
 	// Apply MIREN_SERVER_RUNNER_ID
-	if val := os.Getenv("MIREN_SERVER_RUNNER_ID"); val != "" {
+	if key, val := lookupEnv(envName(envPrefix, "SERVER_RUNNER_ID")); val != "" {
 
 		cfg.Server.RunnerID = &val
-		log.Debug("applied env var", "key", "MIREN_SERVER_RUNNER_ID")
+		log.Debug("applied env var", "key", key)
+
+	}
+
+	// Apply MIREN_SERVER_SANDBOX_HOST_PATH_ALLOWLIST
+	if key, val := lookupEnv(envName(envPrefix, "SERVER_SANDBOX_HOST_PATH_ALLOWLIST")); val != "" {
+
+		list, err := parseStringList(val)
+		if err != nil {
+			log.Warn("invalid JSON in env var, treating it as comma-separated", "key", key, "value", val, "error", err)
+		}
+		cfg.Server.SandboxHostPathAllowlist = list
+		log.Debug("applied env var", "key", key, "count", len(list))
+
+	}
+
+	// Apply MIREN_SERVER_SANDBOX_SYSCTL_ALLOWLIST
+	if key, val := lookupEnv(envName(envPrefix, "SERVER_SANDBOX_SYSCTL_ALLOWLIST")); val != "" {
+
+		list, err := parseStringList(val)
+		if err != nil {
+			log.Warn("invalid JSON in env var, treating it as comma-separated", "key", key, "value", val, "error", err)
+		}
+		cfg.Server.SandboxSysctlAllowlist = list
+		log.Debug("applied env var", "key", key, "count", len(list))
 
 	}
 
 	// Apply MIREN_SERVER_SKIP_CLIENT_CONFIG
-	if val := os.Getenv("MIREN_SERVER_SKIP_CLIENT_CONFIG"); val != "" {
+	if key, val := lookupEnv(envName(envPrefix, "SERVER_SKIP_CLIENT_CONFIG")); val != "" {
 
 		if b, err := strconv.ParseBool(val); err == nil {
 			cfg.Server.SkipClientConfig = &b
-			log.Debug("applied env var", "key", "MIREN_SERVER_SKIP_CLIENT_CONFIG")
+			log.Debug("applied env var", "key", key)
 		} else {
-			log.Warn("invalid MIREN_SERVER_SKIP_CLIENT_CONFIG value", "value", val, "error", err)
+			log.Warn("invalid env var value", "key", key, "value", val, "error", err)
 		}
 
 	}
 
 	// Apply MIREN_SERVER_STOP_SANDBOXES_ON_SHUTDOWN
-	if val := os.Getenv("MIREN_SERVER_STOP_SANDBOXES_ON_SHUTDOWN"); val != "" {
+	if key, val := lookupEnv(envName(envPrefix, "SERVER_STOP_SANDBOXES_ON_SHUTDOWN")); val != "" {
 
 		if b, err := strconv.ParseBool(val); err == nil {
 			cfg.Server.StopSandboxesOnShutdown = &b
-			log.Debug("applied env var", "key", "MIREN_SERVER_STOP_SANDBOXES_ON_SHUTDOWN")
+			log.Debug("applied env var", "key", key)
 		} else {
-			log.Warn("invalid MIREN_SERVER_STOP_SANDBOXES_ON_SHUTDOWN value", "value", val, "error", err)
+			log.Warn("invalid env var value", "key", key, "value", val, "error", err)
 		}
 
 	}
 
 	// Apply MIREN_TLS_ACME_DNS_PROVIDER
-	if val := os.Getenv("MIREN_TLS_ACME_DNS_PROVIDER"); val != "" {
+	if key, val := lookupEnv(envName(envPrefix, "TLS_ACME_DNS_PROVIDER")); val != "" {
 
 		cfg.TLS.AcmeDNSProvider = &val
-		log.Debug("applied env var", "key", "MIREN_TLS_ACME_DNS_PROVIDER")
+		log.Debug("applied env var", "key", key)
 
 	}
 
 	// Apply MIREN_TLS_ACME_EMAIL
-	if val := os.Getenv("MIREN_TLS_ACME_EMAIL"); val != "" {
+	if key, val := lookupEnv(envName(envPrefix, "TLS_ACME_EMAIL")); val != "" {
 
 		cfg.TLS.AcmeEmail = &val
-		log.Debug("applied env var", "key", "MIREN_TLS_ACME_EMAIL")
+		log.Debug("applied env var", "key", key)
 
 	}
 
 	// Apply MIREN_TLS_ADDITIONAL_IPS
-	if val := os.Getenv("MIREN_TLS_ADDITIONAL_IPS"); val != "" {
-
-		// Split and clean CSV list
-		parts := strings.Split(val, ",")
-		cleaned := make([]string, 0, len(parts))
-		seen := make(map[string]struct{})
-		for _, p := range parts {
-			p = strings.TrimSpace(p)
-			if p == "" {
-				continue
-			}
-			if _, exists := seen[p]; exists {
-				continue
-			}
-			seen[p] = struct{}{}
-			cleaned = append(cleaned, p)
+	if key, val := lookupEnv(envName(envPrefix, "TLS_ADDITIONAL_IPS")); val != "" {
+
+		list, err := parseStringList(val)
+		if err != nil {
+			log.Warn("invalid JSON in env var, treating it as comma-separated", "key", key, "value", val, "error", err)
 		}
-		cfg.TLS.AdditionalIPs = cleaned
-		log.Debug("applied env var", "key", "MIREN_TLS_ADDITIONAL_IPS", "count", len(cleaned))
+		cfg.TLS.AdditionalIPs = list
+		log.Debug("applied env var", "key", key, "count", len(list))
 
 	}
 
 	// Apply MIREN_TLS_ADDITIONAL_NAMES
-	if val := os.Getenv("MIREN_TLS_ADDITIONAL_NAMES"); val != "" {
-
-		// Split and clean CSV list
-		parts := strings.Split(val, ",")
-		cleaned := make([]string, 0, len(parts))
-		seen := make(map[string]struct{})
-		for _, p := range parts {
-			p = strings.TrimSpace(p)
-			if p == "" {
-				continue
-			}
-			if _, exists := seen[p]; exists {
-				continue
-			}
-			seen[p] = struct{}{}
-			cleaned = append(cleaned, p)
+	if key, val := lookupEnv(envName(envPrefix, "TLS_ADDITIONAL_NAMES")); val != "" {
+
+		list, err := parseStringList(val)
+		if err != nil {
+			log.Warn("invalid JSON in env var, treating it as comma-separated", "key", key, "value", val, "error", err)
 		}
-		cfg.TLS.AdditionalNames = cleaned
-		log.Debug("applied env var", "key", "MIREN_TLS_ADDITIONAL_NAMES", "count", len(cleaned))
+		cfg.TLS.AdditionalNames = list
+		log.Debug("applied env var", "key", key, "count", len(list))
 
 	}
 
 	// Apply MIREN_TLS_STANDARD_TLS
-	if val := os.Getenv("MIREN_TLS_STANDARD_TLS"); val != "" {
+	if key, val := lookupEnv(envName(envPrefix, "TLS_STANDARD_TLS")); val != "" {
 
 		if b, err := strconv.ParseBool(val); err == nil {
 			cfg.TLS.StandardTLS = &b
-			log.Debug("applied env var", "key", "MIREN_TLS_STANDARD_TLS")
+			log.Debug("applied env var", "key", key)
 		} else {
-			log.Warn("invalid MIREN_TLS_STANDARD_TLS value", "value", val, "error", err)
+			log.Warn("invalid env var value", "key", key, "value", val, "error", err)
 		}
 
 	}
 
 	// Apply MIREN_VICTORIALOGS_ADDRESS
-	if val := os.Getenv("MIREN_VICTORIALOGS_ADDRESS"); val != "" {
+	if key, val := lookupEnv(envName(envPrefix, "VICTORIALOGS_ADDRESS")); val != "" {
 
 		cfg.Victorialogs.Address = &val
-		log.Debug("applied env var", "key", "MIREN_VICTORIALOGS_ADDRESS")
+		log.Debug("applied env var", "key", key)
+
+	}
+
+	// Apply MIREN_VICTORIALOGS_ARCHIVE_AFTER
+	if key, val := lookupEnv(envName(envPrefix, "VICTORIALOGS_ARCHIVE_AFTER")); val != "" {
+
+		if d, err := parseDuration(val); err == nil {
+			cfg.Victorialogs.ArchiveAfter = &d
+			log.Debug("applied env var", "key", key)
+		} else {
+			log.Warn("invalid env var value", "key", key, "value", val, "error", err)
+		}
+
+	}
+
+	// Apply MIREN_VICTORIALOGS_ARCHIVE_DIR
+	if key, val := lookupEnv(envName(envPrefix, "VICTORIALOGS_ARCHIVE_DIR")); val != "" {
+
+		cfg.Victorialogs.ArchiveDir = &val
+		log.Debug("applied env var", "key", key)
 
 	}
 
 	// Apply MIREN_VICTORIALOGS_HTTP_PORT
-	if val := os.Getenv("MIREN_VICTORIALOGS_HTTP_PORT"); val != "" {
+	if key, val := lookupEnv(envName(envPrefix, "VICTORIALOGS_HTTP_PORT")); val != "" {
 
 		if i, err := strconv.Atoi(val); err == nil {
 			cfg.Victorialogs.HTTPPort = &i
-			log.Debug("applied env var", "key", "MIREN_VICTORIALOGS_HTTP_PORT")
+			log.Debug("applied env var", "key", key)
 		} else {
-			log.Warn("invalid MIREN_VICTORIALOGS_HTTP_PORT value", "value", val, "error", err)
+			log.Warn("invalid env var value", "key", key, "value", val, "error", err)
 		}
 
 	}
 
 	// Apply MIREN_VICTORIALOGS_RETENTION_PERIOD
-	if val := os.Getenv("MIREN_VICTORIALOGS_RETENTION_PERIOD"); val != "" {
+	if key, val := lookupEnv(envName(envPrefix, "VICTORIALOGS_RETENTION_PERIOD")); val != "" {
 
 		cfg.Victorialogs.RetentionPeriod = &val
-		log.Debug("applied env var", "key", "MIREN_VICTORIALOGS_RETENTION_PERIOD")
+		log.Debug("applied env var", "key", key)
 
 	}
 
 	// Apply MIREN_VICTORIALOGS_START_EMBEDDED
-	if val := os.Getenv("MIREN_VICTORIALOGS_START_EMBEDDED"); val != "" {
+	if key, val := lookupEnv(envName(envPrefix, "VICTORIALOGS_START_EMBEDDED")); val != "" {
 
 		if b, err := strconv.ParseBool(val); err == nil {
 			cfg.Victorialogs.StartEmbedded = &b
-			log.Debug("applied env var", "key", "MIREN_VICTORIALOGS_START_EMBEDDED")
+			log.Debug("applied env var", "key", key)
 		} else {
-			log.Warn("invalid MIREN_VICTORIALOGS_START_EMBEDDED value", "value", val, "error", err)
+			log.Warn("invalid env var value", "key", key, "value", val, "error", err)
 		}
 
 	}
 
 	// Apply MIREN_VICTORIAMETRICS_ADDRESS
-	if val := os.Getenv("MIREN_VICTORIAMETRICS_ADDRESS"); val != "" {
+	if key, val := lookupEnv(envName(envPrefix, "VICTORIAMETRICS_ADDRESS")); val != "" {
 
 		cfg.Victoriametrics.Address = &val
-		log.Debug("applied env var", "key", "MIREN_VICTORIAMETRICS_ADDRESS")
+		log.Debug("applied env var", "key", key)
 
 	}
 
 	// Apply MIREN_VICTORIAMETRICS_HTTP_PORT
-	if val := os.Getenv("MIREN_VICTORIAMETRICS_HTTP_PORT"); val != "" {
+	if key, val := lookupEnv(envName(envPrefix, "VICTORIAMETRICS_HTTP_PORT")); val != "" {
 
 		if i, err := strconv.Atoi(val); err == nil {
 			cfg.Victoriametrics.HTTPPort = &i
-			log.Debug("applied env var", "key", "MIREN_VICTORIAMETRICS_HTTP_PORT")
+			log.Debug("applied env var", "key", key)
 		} else {
-			log.Warn("invalid MIREN_VICTORIAMETRICS_HTTP_PORT value", "value", val, "error", err)
+			log.Warn("invalid env var value", "key", key, "value", val, "error", err)
 		}
 
 	}
 
 	// Apply MIREN_VICTORIAMETRICS_RETENTION_PERIOD
-	if val := os.Getenv("MIREN_VICTORIAMETRICS_RETENTION_PERIOD"); val != "" {
+	if key, val := lookupEnv(envName(envPrefix, "VICTORIAMETRICS_RETENTION_PERIOD")); val != "" {
 
 		cfg.Victoriametrics.RetentionPeriod = &val
-		log.Debug("applied env var", "key", "MIREN_VICTORIAMETRICS_RETENTION_PERIOD")
+		log.Debug("applied env var", "key", key)
 
 	}
 
 	// Apply MIREN_VICTORIAMETRICS_START_EMBEDDED
-	if val := os.Getenv("MIREN_VICTORIAMETRICS_START_EMBEDDED"); val != "" {
+	if key, val := lookupEnv(envName(envPrefix, "VICTORIAMETRICS_START_EMBEDDED")); val != "" {
 
 		if b, err := strconv.ParseBool(val); err == nil {
 			cfg.Victoriametrics.StartEmbedded = &b
-			log.Debug("applied env var", "key", "MIREN_VICTORIAMETRICS_START_EMBEDDED")
+			log.Debug("applied env var", "key", key)
 		} else {
-			log.Warn("invalid MIREN_VICTORIAMETRICS_START_EMBEDDED value", "value", val, "error", err)
+			log.Warn("invalid env var value", "key", key, "value", val, "error", err)
 		}
 
 	}
 
 	return nil
 }
+
+// parseStringList parses a list-valued environment variable. A value starting
+// with "[" is decoded as a JSON array of strings and used as is. Otherwise, or
+// if decoding fails, the value is split on commas, dropping empty and
+// duplicate items after trimming spaces.
+//
+// A decoding error is returned along with the comma-separated result when the
+// value also ends with "]", so it was likely meant as JSON. Values such as
+// "[::1]:2379" fall back quietly.
+func parseStringList(val string) ([]string, error) {
+	var jsonErr error
+
+	if trimmed := strings.TrimSpace(val); strings.HasPrefix(trimmed, "[") {
+		var list []string
+		err := json.Unmarshal([]byte(trimmed), &list)
+		if err == nil {
+			return list, nil
+		}
+		if strings.HasSuffix(trimmed, "]") {
+			jsonErr = err
+		}
+	}
+
+	parts := strings.Split(val, ",")
+	cleaned := make([]string, 0, len(parts))
+	seen := make(map[string]struct{})
+	for _, p := range parts {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if _, exists := seen[p]; exists {
+			continue
+		}
+		seen[p] = struct{}{}
+		cleaned = append(cleaned, p)
+	}
+
+	return cleaned, jsonErr
+}
@@ -7,6 +7,9 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/pelletier/go-toml/v2"
 )
@@ -14,6 +17,14 @@ import (
 // Load loads configuration from all sources with proper precedence:
 // CLI flags > Environment variables > Config file > Defaults
 func Load(configPath string, flags *CLIFlags, log *slog.Logger) (*Config, error) {
+	return LoadWithEnvPrefix(EnvPrefix, configPath, flags, log)
+}
+
+// LoadWithEnvPrefix is like Load, but joins the names of environment variables
+// with envPrefix instead of EnvPrefix, so that tests can use their own
+// variables. An empty envPrefix reads the unprefixed names. Fields given their
+// own prefix in the schema are unaffected.
+func LoadWithEnvPrefix(envPrefix, configPath string, flags *CLIFlags, log *slog.Logger) (*Config, error) {
 	if log == nil {
 		log = slog.Default()
 	}
@@ -28,7 +39,7 @@ func Load(configPath string, flags *CLIFlags, log *slog.Logger) (*Config, error)
 	}
 	if flags != nil && flags.ServerConfigDataPath != nil && *flags.ServerConfigDataPath != "" {
 		dataPathForSearch = *flags.ServerConfigDataPath
-	} else if envDataPath := os.Getenv("MIREN_SERVER_DATA_PATH"); envDataPath != "" {
+	} else if envDataPath := os.Getenv(envName(envPrefix, "SERVER_DATA_PATH")); envDataPath != "" {
 		dataPathForSearch = envDataPath
 	}
 
@@ -49,7 +60,7 @@ func Load(configPath string, flags *CLIFlags, log *slog.Logger) (*Config, error)
 	if cfg.Mode != nil {
 		effectiveMode = *cfg.Mode
 	}
-	if envMode := os.Getenv("MIREN_MODE"); envMode != "" {
+	if envMode := os.Getenv(envName(envPrefix, "MODE")); envMode != "" {
 		effectiveMode = envMode
 	}
 	if flags != nil && flags.Mode != nil && *flags.Mode != "" {
@@ -86,7 +97,7 @@ func Load(configPath string, flags *CLIFlags, log *slog.Logger) (*Config, error)
 	}
 
 	// Apply environment variables (can override mode defaults)
-	if err := applyEnvironmentVariables(cfg, log); err != nil {
+	if err := applyEnvironmentVariables(cfg, envPrefix, log); err != nil {
 		return nil, fmt.Errorf("failed to apply environment variables: %w", err)
 	}
 
@@ -141,6 +152,26 @@ func loadConfigFile(path string, cfg *Config) error {
 		return fmt.Errorf("failed to read config file: %w", err)
 	}
 
+	// go-toml can't decode duration or byte size strings, so rewrite them to
+	// nanoseconds and bytes before decoding into the config
+	var doc map[string]any
+	if err := toml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse TOML: %w", err)
+	}
+
+	if err := normalizeDurations(doc); err != nil {
+		return err
+	}
+
+	if err := normalizeByteSizes(doc); err != nil {
+		return err
+	}
+
+	data, err = toml.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("failed to re-encode TOML: %w", err)
+	}
+
 	if err := toml.Unmarshal(data, cfg); err != nil {
 		return fmt.Errorf("failed to parse TOML: %w", err)
 	}
@@ -148,13 +179,136 @@ func loadConfigFile(path string, cfg *Config) error {
 	return nil
 }
 
+// tomlTable is a table of a decoded TOML document, with the name to report
+// problems in it by
+type tomlTable struct {
+	name  string
+	table map[string]any
+}
+
+// tomlTables returns the tables found at the dotted path in doc. An array of
+// tables gives each element, named by its index, such as "listener[1]".
+func tomlTables(doc map[string]any, path string) []tomlTable {
+	tables := []tomlTable{{table: doc}}
+	if path == "" {
+		return tables
+	}
+
+	for _, key := range strings.Split(path, ".") {
+		var next []tomlTable
+		for _, t := range tables {
+			name := key
+			if t.name != "" {
+				name = t.name + "." + key
+			}
+
+			switch v := t.table[key].(type) {
+			case map[string]any:
+				next = append(next, tomlTable{name: name, table: v})
+			case []any:
+				for i, elem := range v {
+					if sub, ok := elem.(map[string]any); ok {
+						next = append(next, tomlTable{name: fmt.Sprintf("%s[%d]", name, i), table: sub})
+					}
+				}
+			}
+		}
+		tables = next
+	}
+
+	return tables
+}
+
+// parseDuration parses Go duration syntax such as "30s". A bare integer is
+// taken as seconds, matching how these settings were written before they
+// became durations.
+func parseDuration(s string) (time.Duration, error) {
+	if secs, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Duration(secs) * time.Second, nil
+	}
+
+	return time.ParseDuration(s)
+}
+
+// normalizeDurations replaces the duration values in a decoded TOML document
+// with their length in nanoseconds
+func normalizeDurations(doc map[string]any) error {
+	if err := normalizeDuration(doc, "etcd", "delete_retention"); err != nil {
+		return err
+	}
+	if err := normalizeDuration(doc, "server", "http_request_timeout"); err != nil {
+		return err
+	}
+	if err := normalizeDuration(doc, "victorialogs", "archive_after"); err != nil {
+		return err
+	}
+	return nil
+}
+
+func normalizeDuration(doc map[string]any, table, key string) error {
+	for _, t := range tomlTables(doc, table) {
+		name := key
+		if t.name != "" {
+			name = t.name + "." + key
+		}
+
+		switch v := t.table[key].(type) {
+		case nil:
+		case string:
+			d, err := parseDuration(v)
+			if err != nil {
+				return fmt.Errorf("invalid duration for %s: %w", name, err)
+			}
+			t.table[key] = int64(d)
+		case int64:
+			t.table[key] = int64(time.Duration(v) * time.Second)
+		default:
+			return fmt.Errorf("invalid duration for %s: expected a string, got %T", name, v)
+		}
+	}
+
+	return nil
+}
+
+// normalizeByteSizes replaces the byte size values in a decoded TOML document
+// with their count of bytes
+func normalizeByteSizes(doc map[string]any) error {
+	if err := normalizeByteSize(doc, "buildkit", "gc_keep_storage"); err != nil {
+		return err
+	}
+	return nil
+}
+
+func normalizeByteSize(doc map[string]any, table, key string) error {
+	for _, t := range tomlTables(doc, table) {
+		name := key
+		if t.name != "" {
+			name = t.name + "." + key
+		}
+
+		switch v := t.table[key].(type) {
+		case nil, int64:
+		case string:
+			b, err := parseByteSize(v)
+			if err != nil {
+				return fmt.Errorf("invalid byte size for %s: %w", name, err)
+			}
+			t.table[key] = int64(b)
+		default:
+			return fmt.Errorf("invalid byte size for %s: expected a string, got %T", name, v)
+		}
+	}
+
+	return nil
+}
+
 func applyCLIFlags(cfg *Config, flags *CLIFlags) {
 
 	if flags.BuildkitConfigGcKeepDuration != nil && *flags.BuildkitConfigGcKeepDuration != "" {
 		cfg.Buildkit.GcKeepDuration = flags.BuildkitConfigGcKeepDuration
 	}
 
-	if flags.BuildkitConfigGcKeepStorage != nil && *flags.BuildkitConfigGcKeepStorage != "" {
+	if flags.BuildkitConfigGcKeepStorage != nil {
 		cfg.Buildkit.GcKeepStorage = flags.BuildkitConfigGcKeepStorage
 	}
 
@@ -190,6 +344,10 @@ func applyCLIFlags(cfg *Config, flags *CLIFlags) {
 		cfg.Etcd.ClientPort = flags.EtcdConfigClientPort
 	}
 
+	if flags.EtcdConfigDeleteRetention != nil {
+		cfg.Etcd.DeleteRetention = durationPtr(time.Duration(*flags.EtcdConfigDeleteRetention))
+	}
+
 	if len(flags.EtcdConfigEndpoints) > 0 {
 		cfg.Etcd.Endpoints = flags.EtcdConfigEndpoints
 	}
@@ -223,7 +381,11 @@ func applyCLIFlags(cfg *Config, flags *CLIFlags) {
 	}
 
 	if flags.ServerConfigHTTPRequestTimeout != nil {
-		cfg.Server.HTTPRequestTimeout = flags.ServerConfigHTTPRequestTimeout
+		cfg.Server.HTTPRequestTimeout = durationPtr(time.Duration(*flags.ServerConfigHTTPRequestTimeout))
+	}
+
+	if flags.ServerConfigPreemptionNoticeURL != nil && *flags.ServerConfigPreemptionNoticeURL != "" {
+		cfg.Server.PreemptionNoticeURL = flags.ServerConfigPreemptionNoticeURL
 	}
 
 	if flags.ServerConfigReleasePath != nil && *flags.ServerConfigReleasePath != "" {
@@ -238,6 +400,14 @@ func applyCLIFlags(cfg *Config, flags *CLIFlags) {
 		cfg.Server.RunnerID = flags.ServerConfigRunnerID
 	}
 
+	if len(flags.ServerConfigSandboxHostPathAllowlist) > 0 {
+		cfg.Server.SandboxHostPathAllowlist = flags.ServerConfigSandboxHostPathAllowlist
+	}
+
+	if len(flags.ServerConfigSandboxSysctlAllowlist) > 0 {
+		cfg.Server.SandboxSysctlAllowlist = flags.ServerConfigSandboxSysctlAllowlist
+	}
+
 	if flags.ServerConfigSkipClientConfig != nil {
 		cfg.Server.SkipClientConfig = flags.ServerConfigSkipClientConfig
 	}
@@ -270,6 +440,14 @@ func applyCLIFlags(cfg *Config, flags *CLIFlags) {
 		cfg.Victorialogs.Address = flags.VictoriaLogsConfigAddress
 	}
 
+	if flags.VictoriaLogsConfigArchiveAfter != nil {
+		cfg.Victorialogs.ArchiveAfter = durationPtr(time.Duration(*flags.VictoriaLogsConfigArchiveAfter))
+	}
+
+	if flags.VictoriaLogsConfigArchiveDir != nil && *flags.VictoriaLogsConfigArchiveDir != "" {
+		cfg.Victorialogs.ArchiveDir = flags.VictoriaLogsConfigArchiveDir
+	}
+
 	if flags.VictoriaLogsConfigHTTPPort != nil {
 		cfg.Victorialogs.HTTPPort = flags.VictoriaLogsConfigHTTPPort
 	}
@@ -6,22 +6,68 @@ import (
 	"fmt"
 	"net"
 	"regexp"
+	"strings"
+	"time"
 )
 
-// Validate validates the configuration
-func (c *Config) Validate() error {
+// ValidationError collects every problem found while validating a
+// configuration, so they can all be fixed at once. Errors from nested
+// configs are prefixed with their table name.
+type ValidationError struct {
+	Errors []error
+}
 
-	if err := c.Buildkit.Validate(); err != nil {
-		return fmt.Errorf("buildkit: %w", err)
+// Error lists the problems one per line
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
 	}
+	return strings.Join(msgs, "\n")
+}
 
-	if err := c.Containerd.Validate(); err != nil {
-		return fmt.Errorf("containerd: %w", err)
+// Unwrap returns the individual problems
+func (e *ValidationError) Unwrap() []error {
+	return e.Errors
+}
+
+// add records err under prefix, flattening nested ValidationErrors
+func (e *ValidationError) add(prefix string, err error) {
+	if err == nil {
+		return
 	}
 
-	if err := c.Etcd.Validate(); err != nil {
-		return fmt.Errorf("etcd: %w", err)
+	if ve, ok := err.(*ValidationError); ok {
+		for _, sub := range ve.Errors {
+			e.add(prefix, sub)
+		}
+		return
 	}
+
+	if prefix != "" {
+		err = fmt.Errorf("%s: %w", prefix, err)
+	}
+
+	e.Errors = append(e.Errors, err)
+}
+
+// result returns e, or nil if nothing was recorded
+func (e *ValidationError) result() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Validate validates the configuration
+func (c *Config) Validate() error {
+	var errs ValidationError
+
+	errs.add("buildkit", c.Buildkit.Validate())
+
+	errs.add("containerd", c.Containerd.Validate())
+
+	errs.add("etcd", c.Etcd.Validate())
 	// Validate mode
 	if c.Mode != nil {
 		validModes := map[string]bool{
@@ -29,191 +75,198 @@ func (c *Config) Validate() error {
 			"distributed": true,
 		}
 		if !validModes[*c.Mode] {
-			return fmt.Errorf("invalid mode %q: must be one of [standalone distributed]", *c.Mode)
+			errs.add("", fmt.Errorf("invalid mode %q: must be one of [standalone distributed]", *c.Mode))
 		}
 	}
 
-	if err := c.Server.Validate(); err != nil {
-		return fmt.Errorf("server: %w", err)
-	}
+	errs.add("server", c.Server.Validate())
 
-	if err := c.TLS.Validate(); err != nil {
-		return fmt.Errorf("tls: %w", err)
-	}
+	errs.add("tls", c.TLS.Validate())
 
-	if err := c.Victorialogs.Validate(); err != nil {
-		return fmt.Errorf("victorialogs: %w", err)
-	}
+	errs.add("victorialogs", c.Victorialogs.Validate())
 
-	if err := c.Victoriametrics.Validate(); err != nil {
-		return fmt.Errorf("victoriametrics: %w", err)
-	}
-	return nil
+	errs.add("victoriametrics", c.Victoriametrics.Validate())
+	return errs.result()
 }
 
 // Validate validates BuildkitConfig
 func (c *BuildkitConfig) Validate() error {
+	var errs ValidationError
+
+	// Validate gc_keep_storage is not negative
+	if c.GcKeepStorage != nil && *c.GcKeepStorage < 0 {
+		errs.add("", fmt.Errorf("gc_keep_storage must not be negative, got %d", *c.GcKeepStorage))
+	}
 
 	// Check for port conflicts in BuildkitConfig
 
-	return nil
+	return errs.result()
 }
 
 // Validate validates ContainerdConfig
 func (c *ContainerdConfig) Validate() error {
+	var errs ValidationError
 
 	// Check for port conflicts in ContainerdConfig
 
-	return nil
+	return errs.result()
 }
 
 // Validate validates EtcdConfig
 func (c *EtcdConfig) Validate() error {
+	var errs ValidationError
 
 	// Validate client_port
 	if c.ClientPort != nil && (*c.ClientPort < 1 || *c.ClientPort > 65535) {
-		return fmt.Errorf("client_port must be between 1 and 65535, got %d", *c.ClientPort)
+		errs.add("", fmt.Errorf("client_port must be between 1 and 65535, got %d", *c.ClientPort))
 	}
 
 	// Validate http_client_port
 	if c.HTTPClientPort != nil && (*c.HTTPClientPort < 1 || *c.HTTPClientPort > 65535) {
-		return fmt.Errorf("http_client_port must be between 1 and 65535, got %d", *c.HTTPClientPort)
+		errs.add("", fmt.Errorf("http_client_port must be between 1 and 65535, got %d", *c.HTTPClientPort))
 	}
 
 	// Validate peer_port
 	if c.PeerPort != nil && (*c.PeerPort < 1 || *c.PeerPort > 65535) {
-		return fmt.Errorf("peer_port must be between 1 and 65535, got %d", *c.PeerPort)
+		errs.add("", fmt.Errorf("peer_port must be between 1 and 65535, got %d", *c.PeerPort))
 	}
 
 	// Check for port conflicts in EtcdConfig
 	seen := make(map[int]bool)
 	if c.ClientPort != nil {
 		if seen[*c.ClientPort] {
-			return fmt.Errorf("port conflict: port %d is used multiple times", *c.ClientPort)
+			errs.add("", fmt.Errorf("port conflict: port %d is used multiple times", *c.ClientPort))
 		}
 		seen[*c.ClientPort] = true
 	}
 	if c.HTTPClientPort != nil {
 		if seen[*c.HTTPClientPort] {
-			return fmt.Errorf("port conflict: port %d is used multiple times", *c.HTTPClientPort)
+			errs.add("", fmt.Errorf("port conflict: port %d is used multiple times", *c.HTTPClientPort))
 		}
 		seen[*c.HTTPClientPort] = true
 	}
 	if c.PeerPort != nil {
 		if seen[*c.PeerPort] {
-			return fmt.Errorf("port conflict: port %d is used multiple times", *c.PeerPort)
+			errs.add("", fmt.Errorf("port conflict: port %d is used multiple times", *c.PeerPort))
 		}
 		seen[*c.PeerPort] = true
 	}
 
-	// Validate etcd endpoints requirement
+	// Validate endpoints is set
 	if c.StartEmbedded != nil && !*c.StartEmbedded && len(c.Endpoints) == 0 {
-		return fmt.Errorf("etcd endpoints must be set when start_embedded=false")
+		errs.add("", fmt.Errorf("endpoints must be set when start_embedded=false; set it with etcd.endpoints, MIREN_ETCD_ENDPOINTS or --etcd"))
 	}
 
-	return nil
+	return errs.result()
 }
 
 // Validate validates ServerConfig
 func (c *ServerConfig) Validate() error {
+	var errs ValidationError
 
 	// Validate address
 	if c.Address != nil && *c.Address != "" {
 		if _, _, err := net.SplitHostPort(*c.Address); err != nil {
-			return fmt.Errorf("invalid address %q: %w", *c.Address, err)
+			errs.add("", fmt.Errorf("invalid address %q: %w", *c.Address, err))
 		}
 	}
 
 	// Validate http_request_timeout minimum
-	if c.HTTPRequestTimeout != nil && *c.HTTPRequestTimeout < 1 {
-		return fmt.Errorf("http_request_timeout must be at least 1, got %d", *c.HTTPRequestTimeout)
+	if c.HTTPRequestTimeout != nil && *c.HTTPRequestTimeout < 1*time.Second {
+		errs.add("", fmt.Errorf("http_request_timeout must be at least 1s, got %s", *c.HTTPRequestTimeout))
 	}
 
 	// Validate runner_address
 	if c.RunnerAddress != nil && *c.RunnerAddress != "" {
 		if _, _, err := net.SplitHostPort(*c.RunnerAddress); err != nil {
-			return fmt.Errorf("invalid runner_address %q: %w", *c.RunnerAddress, err)
+			errs.add("", fmt.Errorf("invalid runner_address %q: %w", *c.RunnerAddress, err))
 		}
 	}
 
 	// Check for port conflicts in ServerConfig
 
-	return nil
+	return errs.result()
 }
 
 // Validate validates TLSConfig
 func (c *TLSConfig) Validate() error {
+	var errs ValidationError
 
 	// Validate additional_ips
 	for _, ip := range c.AdditionalIPs {
 		if net.ParseIP(ip) == nil {
-			return fmt.Errorf("invalid IP address %q in additional_ips", ip)
+			errs.add("", fmt.Errorf("invalid IP address %q in additional_ips", ip))
 		}
 	}
 
 	// Check for port conflicts in TLSConfig
 
-	return nil
+	return errs.result()
 }
 
 // Validate validates VictoriaLogsConfig
 func (c *VictoriaLogsConfig) Validate() error {
+	var errs ValidationError
 
 	// Validate address
 	if c.Address != nil && *c.Address != "" {
 		if _, _, err := net.SplitHostPort(*c.Address); err != nil {
-			return fmt.Errorf("invalid address %q: %w", *c.Address, err)
+			errs.add("", fmt.Errorf("invalid address %q: %w", *c.Address, err))
 		}
 	}
 
+	// Validate archive_after minimum
+	if c.ArchiveAfter != nil && *c.ArchiveAfter < 24*time.Hour {
+		errs.add("", fmt.Errorf("archive_after must be at least 24h, got %s", *c.ArchiveAfter))
+	}
+
 	// Validate http_port
 	if c.HTTPPort != nil && (*c.HTTPPort < 1 || *c.HTTPPort > 65535) {
-		return fmt.Errorf("http_port must be between 1 and 65535, got %d", *c.HTTPPort)
+		errs.add("", fmt.Errorf("http_port must be between 1 and 65535, got %d", *c.HTTPPort))
 	}
 
 	// Validate retention_period regex
 	if c.RetentionPeriod != nil && *c.RetentionPeriod != "" {
 		matched, err := regexp.MatchString(`^\d+(ms|s|m|h|d|w|y)$`, *c.RetentionPeriod)
 		if err != nil {
-			return fmt.Errorf("invalid regex pattern for retention_period: %w", err)
-		}
-		if !matched {
-			return fmt.Errorf("invalid retention_period %q: must match pattern %q", *c.RetentionPeriod, `^\d+(ms|s|m|h|d|w|y)$`)
+			errs.add("", fmt.Errorf("invalid regex pattern for retention_period: %w", err))
+		} else if !matched {
+			errs.add("", fmt.Errorf("invalid retention_period %q: must match pattern %q", *c.RetentionPeriod, `^\d+(ms|s|m|h|d|w|y)$`))
 		}
 	}
 
 	// Check for port conflicts in VictoriaLogsConfig
 
-	return nil
+	return errs.result()
 }
 
 // Validate validates VictoriaMetricsConfig
 func (c *VictoriaMetricsConfig) Validate() error {
+	var errs ValidationError
 
 	// Validate address
 	if c.Address != nil && *c.Address != "" {
 		if _, _, err := net.SplitHostPort(*c.Address); err != nil {
-			return fmt.Errorf("invalid address %q: %w", *c.Address, err)
+			errs.add("", fmt.Errorf("invalid address %q: %w", *c.Address, err))
 		}
 	}
 
 	// Validate http_port
 	if c.HTTPPort != nil && (*c.HTTPPort < 1 || *c.HTTPPort > 65535) {
-		return fmt.Errorf("http_port must be between 1 and 65535, got %d", *c.HTTPPort)
+		errs.add("", fmt.Errorf("http_port must be between 1 and 65535, got %d", *c.HTTPPort))
 	}
 
 	// Validate retention_period regex
 	if c.RetentionPeriod != nil && *c.RetentionPeriod != "" {
 		matched, err := regexp.MatchString(`^\d+$`, *c.RetentionPeriod)
 		if err != nil {
-			return fmt.Errorf("invalid regex pattern for retention_period: %w", err)
-		}
-		if !matched {
-			return fmt.Errorf("invalid retention_period %q: must match pattern %q", *c.RetentionPeriod, `^\d+$`)
+			errs.add("", fmt.Errorf("invalid regex pattern for retention_period: %w", err))
+		} else if !matched {
+			errs.add("", fmt.Errorf("invalid retention_period %q: must match pattern %q", *c.RetentionPeriod, `^\d+$`))
 		}
 	}
 
 	// Check for port conflicts in VictoriaMetricsConfig
 
-	return nil
+	return errs.result()
 }
@@ -0,0 +1,392 @@
+// Code generated by configgen. DO NOT EDIT.
+
+package serverconfig
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// MarshalTOML serializes the configuration as TOML. Only explicitly set
+// values are written: nil pointers, empty lists and empty tables are omitted,
+// so the output re-parses to the same configuration.
+func (c *Config) MarshalTOML() ([]byte, error) {
+	return toml.Marshal(c.tomlTable())
+}
+
+// tomlTable returns the set fields of BuildkitConfig keyed by their TOML names
+func (c *BuildkitConfig) tomlTable() map[string]any {
+	t := make(map[string]any)
+	if c.GcKeepDuration != nil {
+		t["gc_keep_duration"] = *c.GcKeepDuration
+	}
+	if c.GcKeepStorage != nil {
+		t["gc_keep_storage"] = c.GcKeepStorage.String()
+	}
+	if c.SocketDir != nil {
+		t["socket_dir"] = *c.SocketDir
+	}
+	if c.SocketPath != nil {
+		t["socket_path"] = *c.SocketPath
+	}
+	if c.StartEmbedded != nil {
+		t["start_embedded"] = *c.StartEmbedded
+	}
+	return t
+}
+
+// String renders BuildkitConfig for logs and debugging. Unset fields print as
+// <nil> and the values of sensitive fields are replaced with ****.
+func (c *BuildkitConfig) String() string {
+	if c == nil {
+		return "<nil>"
+	}
+
+	fields := []string{
+		"GcKeepDuration: " + formatValue(c.GcKeepDuration),
+		"GcKeepStorage: " + formatValue(c.GcKeepStorage),
+		"SocketDir: " + formatValue(c.SocketDir),
+		"SocketPath: " + formatValue(c.SocketPath),
+		"StartEmbedded: " + formatValue(c.StartEmbedded),
+	}
+
+	return "{" + strings.Join(fields, ", ") + "}"
+}
+
+// tomlTable returns the set fields of Config keyed by their TOML names
+func (c *Config) tomlTable() map[string]any {
+	t := make(map[string]any)
+	if sub := c.Buildkit.tomlTable(); len(sub) > 0 {
+		t["buildkit"] = sub
+	}
+	if sub := c.Containerd.tomlTable(); len(sub) > 0 {
+		t["containerd"] = sub
+	}
+	if sub := c.Etcd.tomlTable(); len(sub) > 0 {
+		t["etcd"] = sub
+	}
+	if c.Mode != nil {
+		t["mode"] = *c.Mode
+	}
+	if sub := c.Server.tomlTable(); len(sub) > 0 {
+		t["server"] = sub
+	}
+	if sub := c.TLS.tomlTable(); len(sub) > 0 {
+		t["tls"] = sub
+	}
+	if sub := c.Victorialogs.tomlTable(); len(sub) > 0 {
+		t["victorialogs"] = sub
+	}
+	if sub := c.Victoriametrics.tomlTable(); len(sub) > 0 {
+		t["victoriametrics"] = sub
+	}
+	return t
+}
+
+// String renders Config for logs and debugging. Unset fields print as
+// <nil> and the values of sensitive fields are replaced with ****.
+func (c *Config) String() string {
+	if c == nil {
+		return "<nil>"
+	}
+
+	fields := []string{
+		"Buildkit: " + c.Buildkit.String(),
+		"Containerd: " + c.Containerd.String(),
+		"Etcd: " + c.Etcd.String(),
+		"Mode: " + formatValue(c.Mode),
+		"Server: " + c.Server.String(),
+		"TLS: " + c.TLS.String(),
+		"Victorialogs: " + c.Victorialogs.String(),
+		"Victoriametrics: " + c.Victoriametrics.String(),
+	}
+
+	return "{" + strings.Join(fields, ", ") + "}"
+}
+
+// tomlTable returns the set fields of ContainerdConfig keyed by their TOML names
+func (c *ContainerdConfig) tomlTable() map[string]any {
+	t := make(map[string]any)
+	if c.BinaryPath != nil {
+		t["binary_path"] = *c.BinaryPath
+	}
+	if c.SocketPath != nil {
+		t["socket_path"] = *c.SocketPath
+	}
+	if c.StartEmbedded != nil {
+		t["start_embedded"] = *c.StartEmbedded
+	}
+	return t
+}
+
+// String renders ContainerdConfig for logs and debugging. Unset fields print as
+// <nil> and the values of sensitive fields are replaced with ****.
+func (c *ContainerdConfig) String() string {
+	if c == nil {
+		return "<nil>"
+	}
+
+	fields := []string{
+		"BinaryPath: " + formatValue(c.BinaryPath),
+		"SocketPath: " + formatValue(c.SocketPath),
+		"StartEmbedded: " + formatValue(c.StartEmbedded),
+	}
+
+	return "{" + strings.Join(fields, ", ") + "}"
+}
+
+// tomlTable returns the set fields of EtcdConfig keyed by their TOML names
+func (c *EtcdConfig) tomlTable() map[string]any {
+	t := make(map[string]any)
+	if c.ClientPort != nil {
+		t["client_port"] = *c.ClientPort
+	}
+	if c.DeleteRetention != nil {
+		t["delete_retention"] = c.DeleteRetention.String()
+	}
+	if len(c.Endpoints) > 0 {
+		t["endpoints"] = c.Endpoints
+	}
+	if c.HTTPClientPort != nil {
+		t["http_client_port"] = *c.HTTPClientPort
+	}
+	if c.PeerPort != nil {
+		t["peer_port"] = *c.PeerPort
+	}
+	if c.Prefix != nil {
+		t["prefix"] = *c.Prefix
+	}
+	if c.StartEmbedded != nil {
+		t["start_embedded"] = *c.StartEmbedded
+	}
+	return t
+}
+
+// String renders EtcdConfig for logs and debugging. Unset fields print as
+// <nil> and the values of sensitive fields are replaced with ****.
+func (c *EtcdConfig) String() string {
+	if c == nil {
+		return "<nil>"
+	}
+
+	fields := []string{
+		"ClientPort: " + formatValue(c.ClientPort),
+		"DeleteRetention: " + formatValue(c.DeleteRetention),
+		"Endpoints: " + fmt.Sprintf("%q", c.Endpoints),
+		"HTTPClientPort: " + formatValue(c.HTTPClientPort),
+		"PeerPort: " + formatValue(c.PeerPort),
+		"Prefix: " + formatValue(c.Prefix),
+		"StartEmbedded: " + formatValue(c.StartEmbedded),
+	}
+
+	return "{" + strings.Join(fields, ", ") + "}"
+}
+
+// tomlTable returns the set fields of ServerConfig keyed by their TOML names
+func (c *ServerConfig) tomlTable() map[string]any {
+	t := make(map[string]any)
+	if c.Address != nil {
+		t["address"] = *c.Address
+	}
+	if c.ConfigClusterName != nil {
+		t["config_cluster_name"] = *c.ConfigClusterName
+	}
+	if c.DataPath != nil {
+		t["data_path"] = *c.DataPath
+	}
+	if c.HTTPRequestTimeout != nil {
+		t["http_request_timeout"] = c.HTTPRequestTimeout.String()
+	}
+	if c.PreemptionNoticeURL != nil {
+		t["preemption_notice_url"] = *c.PreemptionNoticeURL
+	}
+	if c.ReleasePath != nil {
+		t["release_path"] = *c.ReleasePath
+	}
+	if c.RunnerAddress != nil {
+		t["runner_address"] = *c.RunnerAddress
+	}
+	if c.RunnerID != nil {
+		t["runner_id"] = *c.RunnerID
+	}
+	if len(c.SandboxHostPathAllowlist) > 0 {
+		t["sandbox_host_path_allowlist"] = c.SandboxHostPathAllowlist
+	}
+	if len(c.SandboxSysctlAllowlist) > 0 {
+		t["sandbox_sysctl_allowlist"] = c.SandboxSysctlAllowlist
+	}
+	if c.SkipClientConfig != nil {
+		t["skip_client_config"] = *c.SkipClientConfig
+	}
+	if c.StopSandboxesOnShutdown != nil {
+		t["stop_sandboxes_on_shutdown"] = *c.StopSandboxesOnShutdown
+	}
+	return t
+}
+
+// String renders ServerConfig for logs and debugging. Unset fields print as
+// <nil> and the values of sensitive fields are replaced with ****.
+func (c *ServerConfig) String() string {
+	if c == nil {
+		return "<nil>"
+	}
+
+	fields := []string{
+		"Address: " + formatValue(c.Address),
+		"ConfigClusterName: " + formatValue(c.ConfigClusterName),
+		"DataPath: " + formatValue(c.DataPath),
+		"HTTPRequestTimeout: " + formatValue(c.HTTPRequestTimeout),
+		"PreemptionNoticeURL: " + formatValue(c.PreemptionNoticeURL),
+		"ReleasePath: " + formatValue(c.ReleasePath),
+		"RunnerAddress: " + formatValue(c.RunnerAddress),
+		"RunnerID: " + formatValue(c.RunnerID),
+		"SandboxHostPathAllowlist: " + fmt.Sprintf("%q", c.SandboxHostPathAllowlist),
+		"SandboxSysctlAllowlist: " + fmt.Sprintf("%q", c.SandboxSysctlAllowlist),
+		"SkipClientConfig: " + formatValue(c.SkipClientConfig),
+		"StopSandboxesOnShutdown: " + formatValue(c.StopSandboxesOnShutdown),
+	}
+
+	return "{" + strings.Join(fields, ", ") + "}"
+}
+
+// tomlTable returns the set fields of TLSConfig keyed by their TOML names
+func (c *TLSConfig) tomlTable() map[string]any {
+	t := make(map[string]any)
+	if c.AcmeDNSProvider != nil {
+		t["acme_dns_provider"] = *c.AcmeDNSProvider
+	}
+	if c.AcmeEmail != nil {
+		t["acme_email"] = *c.AcmeEmail
+	}
+	if len(c.AdditionalIPs) > 0 {
+		t["additional_ips"] = c.AdditionalIPs
+	}
+	if len(c.AdditionalNames) > 0 {
+		t["additional_names"] = c.AdditionalNames
+	}
+	if c.StandardTLS != nil {
+		t["standard_tls"] = *c.StandardTLS
+	}
+	return t
+}
+
+// String renders TLSConfig for logs and debugging. Unset fields print as
+// <nil> and the values of sensitive fields are replaced with ****.
+func (c *TLSConfig) String() string {
+	if c == nil {
+		return "<nil>"
+	}
+
+	fields := []string{
+		"AcmeDNSProvider: " + formatValue(c.AcmeDNSProvider),
+		"AcmeEmail: " + formatValue(c.AcmeEmail),
+		"AdditionalIPs: " + fmt.Sprintf("%q", c.AdditionalIPs),
+		"AdditionalNames: " + fmt.Sprintf("%q", c.AdditionalNames),
+		"StandardTLS: " + formatValue(c.StandardTLS),
+	}
+
+	return "{" + strings.Join(fields, ", ") + "}"
+}
+
+// tomlTable returns the set fields of VictoriaLogsConfig keyed by their TOML names
+func (c *VictoriaLogsConfig) tomlTable() map[string]any {
+	t := make(map[string]any)
+	if c.Address != nil {
+		t["address"] = *c.Address
+	}
+	if c.ArchiveAfter != nil {
+		t["archive_after"] = c.ArchiveAfter.String()
+	}
+	if c.ArchiveDir != nil {
+		t["archive_dir"] = *c.ArchiveDir
+	}
+	if c.HTTPPort != nil {
+		t["http_port"] = *c.HTTPPort
+	}
+	if c.RetentionPeriod != nil {
+		t["retention_period"] = *c.RetentionPeriod
+	}
+	if c.StartEmbedded != nil {
+		t["start_embedded"] = *c.StartEmbedded
+	}
+	return t
+}
+
+// String renders VictoriaLogsConfig for logs and debugging. Unset fields print as
+// <nil> and the values of sensitive fields are replaced with ****.
+func (c *VictoriaLogsConfig) String() string {
+	if c == nil {
+		return "<nil>"
+	}
+
+	fields := []string{
+		"Address: " + formatValue(c.Address),
+		"ArchiveAfter: " + formatValue(c.ArchiveAfter),
+		"ArchiveDir: " + formatValue(c.ArchiveDir),
+		"HTTPPort: " + formatValue(c.HTTPPort),
+		"RetentionPeriod: " + formatValue(c.RetentionPeriod),
+		"StartEmbedded: " + formatValue(c.StartEmbedded),
+	}
+
+	return "{" + strings.Join(fields, ", ") + "}"
+}
+
+// tomlTable returns the set fields of VictoriaMetricsConfig keyed by their TOML names
+func (c *VictoriaMetricsConfig) tomlTable() map[string]any {
+	t := make(map[string]any)
+	if c.Address != nil {
+		t["address"] = *c.Address
+	}
+	if c.HTTPPort != nil {
+		t["http_port"] = *c.HTTPPort
+	}
+	if c.RetentionPeriod != nil {
+		t["retention_period"] = *c.RetentionPeriod
+	}
+	if c.StartEmbedded != nil {
+		t["start_embedded"] = *c.StartEmbedded
+	}
+	return t
+}
+
+// String renders VictoriaMetricsConfig for logs and debugging. Unset fields print as
+// <nil> and the values of sensitive fields are replaced with ****.
+func (c *VictoriaMetricsConfig) String() string {
+	if c == nil {
+		return "<nil>"
+	}
+
+	fields := []string{
+		"Address: " + formatValue(c.Address),
+		"HTTPPort: " + formatValue(c.HTTPPort),
+		"RetentionPeriod: " + formatValue(c.RetentionPeriod),
+		"StartEmbedded: " + formatValue(c.StartEmbedded),
+	}
+
+	return "{" + strings.Join(fields, ", ") + "}"
+}
+
+// formatValue renders an optional value, quoting strings
+func formatValue[T any](v *T) string {
+	if v == nil {
+		return "<nil>"
+	}
+
+	if s, ok := any(*v).(string); ok {
+		return strconv.Quote(s)
+	}
+
+	return fmt.Sprint(*v)
+}
+
+// formatRedacted renders a sensitive value, showing only whether it is set
+func formatRedacted(set bool) string {
+	if !set {
+		return "<nil>"
+	}
+
+	return "****"
+}
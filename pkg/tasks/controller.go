@@ -0,0 +1,165 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// Controller is a handle on the procs of a Procfile started by Start.
+type Controller struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	procs map[string]*procHandle
+
+	failed chan error
+	exited chan error
+}
+
+// Start starts the procs in pf and returns without waiting for them. Procs
+// that wait for others are started in the background once those are ready.
+// They run until ctx is done or Wait returns.
+func Start(ctx context.Context, pf *Procfile, opts ...RunOption) (*Controller, error) {
+	o := runOpts{
+		PortStart: DefaultPortStart,
+		PortEnd:   DefaultPortEnd,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	for _, proc := range pf.Proceses {
+		if err := proc.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := checkDeps(pf); err != nil {
+		return nil, err
+	}
+
+	err := assignPorts(pf, NewPortAllocator(o.PortStart, o.PortEnd))
+	if err != nil {
+		return nil, err
+	}
+
+	// Stop anything still waiting on its dependencies once the run is over
+	ctx, cancel := context.WithCancel(ctx)
+
+	c := &Controller{
+		ctx:    ctx,
+		cancel: cancel,
+		procs:  make(map[string]*procHandle),
+		failed: make(chan error, len(pf.Proceses)),
+		exited: make(chan error, len(pf.Proceses)),
+	}
+
+	var (
+		width int
+		ready = make(map[string]chan struct{})
+	)
+
+	for _, proc := range pf.Proceses {
+		if len(proc.Name) > width {
+			width = len(proc.Name)
+		}
+
+		ready[proc.Name] = make(chan struct{})
+	}
+
+	out := newOutput(&o, width)
+
+	for _, proc := range pf.Proceses {
+		c.procs[proc.Name] = newProcHandle(proc, out.proc(proc.Name), ready[proc.Name])
+	}
+
+	start := func(proc *Proc) error {
+		h := c.procs[proc.Name]
+
+		if err := h.start(ctx); err != nil {
+			return err
+		}
+
+		if proc.ExitWhenDone {
+			go func() {
+				c.exited <- <-h.done
+			}()
+		}
+
+		return nil
+	}
+
+	for _, proc := range pf.Proceses {
+		if len(proc.WaitFor) == 0 {
+			if err := start(proc); err != nil {
+				cancel()
+				return nil, err
+			}
+
+			continue
+		}
+
+		go func() {
+			err := waitForDeps(ctx, proc, ready)
+			if err == nil {
+				err = start(proc)
+			}
+
+			if err != nil {
+				c.failed <- err
+			}
+		}()
+	}
+
+	return c, nil
+}
+
+func (c *Controller) proc(name string) (*procHandle, error) {
+	h, ok := c.procs[name]
+	if !ok {
+		return nil, fmt.Errorf("no proc named %s", name)
+	}
+
+	return h, nil
+}
+
+// Signal sends sig to the named proc.
+func (c *Controller) Signal(name string, sig os.Signal) error {
+	h, err := c.proc(name)
+	if err != nil {
+		return err
+	}
+
+	return h.signal(sig)
+}
+
+// Restart stops the named proc with SIGTERM and starts it again, whatever
+// its restart policy, without waiting out any backoff. The proc is killed
+// if it doesn't exit within its StopTimeout. Procs that have exited for
+// good can't be restarted.
+func (c *Controller) Restart(name string) error {
+	h, err := c.proc(name)
+	if err != nil {
+		return err
+	}
+
+	return h.requestRestart()
+}
+
+// Wait blocks until the context passed to Start is done, a proc fails to
+// start, or a proc with ExitWhenDone exits, returning its error. The
+// remaining procs are then stopped.
+func (c *Controller) Wait() error {
+	defer c.cancel()
+
+	select {
+	case <-c.ctx.Done():
+		return c.ctx.Err()
+	case err := <-c.failed:
+		return err
+	case err := <-c.exited:
+		return err
+	}
+}
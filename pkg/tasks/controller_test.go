@@ -0,0 +1,149 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestController(t *testing.T) {
+	// sleeper appends its pid to a file each time it starts, then runs until
+	// it's sent SIGTERM. SIGUSR1 makes it note that it got it.
+	sleeper := func(t *testing.T, name string) (*Proc, string, func() []int) {
+		dir := t.TempDir()
+
+		proc := &Proc{
+			Name: name,
+			Command: []string{"sh", "-c", fmt.Sprintf(
+				`echo $$ >> %[1]s/pids; trap 'echo usr1 >> %[1]s/signals' USR1; trap 'kill $!; exit 0' TERM; while true; do sleep 30 & wait; done`, dir)},
+		}
+
+		return proc, dir, func() []int {
+			data, _ := os.ReadFile(filepath.Join(dir, "pids"))
+
+			var pids []int
+			for _, f := range strings.Fields(string(data)) {
+				pid, _ := strconv.Atoi(f)
+				pids = append(pids, pid)
+			}
+
+			return pids
+		}
+	}
+
+	alive := func(pid int) bool {
+		return syscall.Kill(pid, 0) == nil
+	}
+
+	t.Run("restarts one proc while the others keep running", func(t *testing.T) {
+		r := require.New(t)
+
+		web, _, webPids := sleeper(t, "web")
+		worker, _, workerPids := sleeper(t, "worker")
+
+		ctx, cancel := context.WithCancel(t.Context())
+		defer cancel()
+
+		c, err := Start(ctx, &Procfile{Proceses: []*Proc{web, worker}}, WithOutput(io.Discard))
+		r.NoError(err)
+
+		r.Eventually(func() bool {
+			return len(webPids()) == 1 && len(workerPids()) == 1
+		}, 5*time.Second, 10*time.Millisecond)
+
+		// web never restarts on its own, but can still be restarted
+		r.NoError(c.Restart("web"))
+
+		r.Eventually(func() bool {
+			return len(webPids()) == 2
+		}, 5*time.Second, 10*time.Millisecond)
+
+		pids := webPids()
+		r.NotEqual(pids[0], pids[1])
+		r.Eventually(func() bool { return !alive(pids[0]) }, 5*time.Second, 10*time.Millisecond)
+		r.True(alive(pids[1]))
+
+		r.Len(workerPids(), 1)
+		r.True(alive(workerPids()[0]), "worker was stopped by web's restart")
+
+		r.Error(c.Restart("db"))
+
+		cancel()
+		r.ErrorIs(c.Wait(), context.Canceled)
+	})
+
+	t.Run("signals a proc", func(t *testing.T) {
+		r := require.New(t)
+
+		web, dir, webPids := sleeper(t, "web")
+
+		c, err := Start(t.Context(), &Procfile{Proceses: []*Proc{web}}, WithOutput(io.Discard))
+		r.NoError(err)
+
+		r.Eventually(func() bool { return len(webPids()) == 1 }, 5*time.Second, 10*time.Millisecond)
+
+		signals := filepath.Join(dir, "signals")
+
+		r.NoError(c.Signal("web", syscall.SIGUSR1))
+		r.Eventually(func() bool {
+			_, err := os.Stat(signals)
+			return err == nil
+		}, 5*time.Second, 10*time.Millisecond)
+
+		r.Error(c.Signal("db", syscall.SIGUSR1))
+	})
+
+	t.Run("restarts a proc waiting out its backoff right away", func(t *testing.T) {
+		r := require.New(t)
+
+		path := filepath.Join(t.TempDir(), "runs")
+
+		proc := &Proc{
+			Name:           "crasher",
+			Command:        []string{"sh", "-c", fmt.Sprintf("echo run >> %s; exit 1", path)},
+			Restart:        RestartOnFailure,
+			RestartBackoff: time.Hour,
+		}
+
+		c, err := Start(t.Context(), &Procfile{Proceses: []*Proc{proc}}, WithOutput(io.Discard))
+		r.NoError(err)
+
+		runs := func() int {
+			data, _ := os.ReadFile(path)
+			return strings.Count(string(data), "run")
+		}
+
+		r.Eventually(func() bool { return runs() == 1 }, 5*time.Second, 10*time.Millisecond)
+
+		// Let it exit and start waiting
+		time.Sleep(50 * time.Millisecond)
+
+		r.NoError(c.Restart("crasher"))
+		r.Eventually(func() bool { return runs() == 2 }, 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("can't restart a proc that exited for good", func(t *testing.T) {
+		r := require.New(t)
+
+		proc := &Proc{
+			Name:         "once",
+			Command:      []string{"true"},
+			ExitWhenDone: true,
+		}
+
+		c, err := Start(t.Context(), &Procfile{Proceses: []*Proc{proc}}, WithOutput(io.Discard))
+		r.NoError(err)
+
+		r.NoError(c.Wait())
+		r.ErrorContains(c.Restart("once"), "isn't running")
+	})
+}
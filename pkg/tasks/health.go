@@ -0,0 +1,203 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultHealthInterval is how often a proc's health check runs until it
+// passes, when HealthCheck.Interval is zero.
+const DefaultHealthInterval = 250 * time.Millisecond
+
+// HealthCheck decides when a proc is ready for the procs waiting for it.
+// It either connects to Addr over TCP or runs Command, passing once the
+// connection succeeds or the command exits 0.
+type HealthCheck struct {
+	// Addr is the address to connect to. Empty means the proc's own port on
+	// localhost.
+	Addr string
+
+	Command []string
+
+	Interval time.Duration
+}
+
+// ParseHealthCheck parses a health check directive:
+//
+//	tcp
+//	tcp localhost:5432
+//	cmd pg_isready -h localhost
+func ParseHealthCheck(str string) (*HealthCheck, error) {
+	kind, arg, _ := strings.Cut(strings.TrimSpace(str), " ")
+	arg = strings.TrimSpace(arg)
+
+	switch kind {
+	case "tcp":
+		return &HealthCheck{Addr: arg}, nil
+	case "cmd":
+		if arg == "" {
+			return nil, fmt.Errorf("cmd health check needs a command")
+		}
+
+		return &HealthCheck{Command: []string{"sh", "-c", arg}}, nil
+	default:
+		return nil, fmt.Errorf("unknown health check: %s", kind)
+	}
+}
+
+func (hc *HealthCheck) interval() time.Duration {
+	if hc.Interval > 0 {
+		return hc.Interval
+	}
+
+	return DefaultHealthInterval
+}
+
+func (hc *HealthCheck) validate(pr *Proc) error {
+	if hc.Command == nil && hc.Addr == "" && pr.PortMode == PortNone {
+		return fmt.Errorf("proc %s: tcp health check needs an address or a port", pr.Name)
+	}
+
+	return nil
+}
+
+// check runs the health check once, returning nil if the proc is healthy.
+func (hc *HealthCheck) check(ctx context.Context, pr *Proc) error {
+	ctx, cancel := context.WithTimeout(ctx, hc.interval()*4)
+	defer cancel()
+
+	if hc.Command != nil {
+		cmd := exec.CommandContext(ctx, hc.Command[0], hc.Command[1:]...)
+
+		if pr.PortMode != PortNone {
+			cmd.Env = append(os.Environ(), fmt.Sprintf("%s=%d", pr.portEnv(), pr.Port))
+		}
+
+		return cmd.Run()
+	}
+
+	addr := hc.Addr
+	if addr == "" {
+		addr = net.JoinHostPort("localhost", strconv.Itoa(pr.Port))
+	}
+
+	var d net.Dialer
+
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	return conn.Close()
+}
+
+// waitHealthy runs the proc's health check until it passes, then closes
+// ready. Procs without a health check are ready as soon as they start.
+func waitHealthy(ctx context.Context, pr *Proc, out *procOutput, ready chan struct{}) {
+	if pr.Health == nil {
+		close(ready)
+		return
+	}
+
+	ticker := time.NewTicker(pr.Health.interval())
+	defer ticker.Stop()
+
+	for {
+		if pr.Health.check(ctx, pr) == nil {
+			out.status("ready")
+
+			close(ready)
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// waitForDeps blocks until every proc pr waits for is ready, failing once
+// pr.WaitTimeout passes.
+func waitForDeps(ctx context.Context, pr *Proc, ready map[string]chan struct{}) error {
+	wctx := ctx
+
+	if pr.WaitTimeout > 0 {
+		var cancel context.CancelFunc
+		wctx, cancel = context.WithTimeout(ctx, pr.WaitTimeout)
+		defer cancel()
+	}
+
+	for _, dep := range pr.WaitFor {
+		select {
+		case <-ready[dep]:
+		case <-wctx.Done():
+			if ctx.Err() == nil && errors.Is(wctx.Err(), context.DeadlineExceeded) {
+				return fmt.Errorf("proc %s: %s wasn't ready after %s", pr.Name, dep, pr.WaitTimeout)
+			}
+
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+// checkDeps makes sure every proc waited for exists and that none of them
+// end up waiting on each other.
+func checkDeps(pf *Procfile) error {
+	procs := make(map[string]*Proc)
+	for _, pr := range pf.Proceses {
+		procs[pr.Name] = pr
+	}
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+
+	state := make(map[string]int)
+
+	var visit func(pr *Proc) error
+	visit = func(pr *Proc) error {
+		switch state[pr.Name] {
+		case visiting:
+			return fmt.Errorf("proc %s waits for itself", pr.Name)
+		case visited:
+			return nil
+		}
+
+		state[pr.Name] = visiting
+
+		for _, dep := range pr.WaitFor {
+			dp, ok := procs[dep]
+			if !ok {
+				return fmt.Errorf("proc %s waits for unknown proc %s", pr.Name, dep)
+			}
+
+			if err := visit(dp); err != nil {
+				return err
+			}
+		}
+
+		state[pr.Name] = visited
+
+		return nil
+	}
+
+	for _, pr := range pf.Proceses {
+		if err := visit(pr); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,163 @@
+package tasks
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHealthCheck(t *testing.T) {
+	r := require.New(t)
+
+	hc, err := ParseHealthCheck("tcp")
+	r.NoError(err)
+	r.Equal(&HealthCheck{}, hc)
+
+	hc, err = ParseHealthCheck("tcp localhost:5432")
+	r.NoError(err)
+	r.Equal("localhost:5432", hc.Addr)
+
+	hc, err = ParseHealthCheck("cmd pg_isready -h localhost")
+	r.NoError(err)
+	r.Equal([]string{"sh", "-c", "pg_isready -h localhost"}, hc.Command)
+
+	_, err = ParseHealthCheck("cmd")
+	r.Error(err)
+
+	_, err = ParseHealthCheck("http /up")
+	r.Error(err)
+}
+
+func TestParseFileWaitFor(t *testing.T) {
+	r := require.New(t)
+
+	procfilePath := filepath.Join(t.TempDir(), "Procfile")
+	err := os.WriteFile(procfilePath, []byte(`db: postgres
+  health: tcp localhost:5432
+  health-interval: 100ms
+web: npm start
+  wait_for: db, cache
+  wait-timeout: 30s
+`), 0644)
+	r.NoError(err)
+
+	pf, err := ParseFile(procfilePath)
+	r.NoError(err)
+	r.Len(pf.Proceses, 2)
+
+	r.Equal(&HealthCheck{Addr: "localhost:5432", Interval: 100 * time.Millisecond}, pf.Proceses[0].Health)
+
+	r.Equal([]string{"db", "cache"}, pf.Proceses[1].WaitFor)
+	r.Equal(30*time.Second, pf.Proceses[1].WaitTimeout)
+}
+
+// closedAddr returns a local address with nothing listening on it.
+func closedAddr(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	addr := l.Addr().String()
+	l.Close()
+
+	return addr
+}
+
+func TestRunWaitFor(t *testing.T) {
+	t.Run("starts a proc once the proc it waits for is healthy", func(t *testing.T) {
+		r := require.New(t)
+
+		addr := closedAddr(t)
+		started := filepath.Join(t.TempDir(), "started")
+
+		db := &Proc{
+			Name:    "db",
+			Command: []string{"sleep", "10"},
+			Health:  &HealthCheck{Addr: addr, Interval: 10 * time.Millisecond},
+		}
+
+		app := &Proc{
+			Name:         "app",
+			Command:      []string{"touch", started},
+			ExitWhenDone: true,
+			WaitFor:      []string{"db"},
+		}
+
+		done := make(chan error, 1)
+		go func() {
+			done <- Run(t.Context(), &Procfile{Proceses: []*Proc{app, db}})
+		}()
+
+		time.Sleep(100 * time.Millisecond)
+		r.NoFileExists(started, "app started before db was ready")
+
+		// db comes up
+		l, err := net.Listen("tcp", addr)
+		r.NoError(err)
+		defer l.Close()
+
+		select {
+		case err := <-done:
+			r.NoError(err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("app never started")
+		}
+
+		r.FileExists(started)
+	})
+
+	t.Run("fails the run when a dependency is never ready", func(t *testing.T) {
+		r := require.New(t)
+
+		started := filepath.Join(t.TempDir(), "started")
+
+		db := &Proc{
+			Name:    "db",
+			Command: []string{"sleep", "10"},
+			Health:  &HealthCheck{Addr: closedAddr(t), Interval: 10 * time.Millisecond},
+		}
+
+		app := &Proc{
+			Name:         "app",
+			Command:      []string{"touch", started},
+			ExitWhenDone: true,
+			WaitFor:      []string{"db"},
+			WaitTimeout:  100 * time.Millisecond,
+		}
+
+		err := Run(t.Context(), &Procfile{Proceses: []*Proc{db, app}})
+		r.ErrorContains(err, "db wasn't ready")
+
+		r.NoFileExists(started)
+	})
+
+	for name, procs := range map[string][]*Proc{
+		"unknown proc": {
+			{Name: "app", Command: []string{"true"}, WaitFor: []string{"db"}},
+		},
+		"itself": {
+			{Name: "app", Command: []string{"true"}, WaitFor: []string{"worker"}},
+			{Name: "worker", Command: []string{"true"}, WaitFor: []string{"app"}},
+		},
+	} {
+		t.Run("rejects waiting for "+name, func(t *testing.T) {
+			err := Run(t.Context(), &Procfile{Proceses: procs})
+			assert.ErrorContains(t, err, name)
+		})
+	}
+
+	t.Run("rejects a tcp health check without an address or port", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(t.Context(), time.Second)
+		defer cancel()
+
+		err := Run(ctx, &Procfile{Proceses: []*Proc{
+			{Name: "db", Command: []string{"true"}, Health: &HealthCheck{}},
+		}})
+		assert.ErrorContains(t, err, "needs an address or a port")
+	})
+}
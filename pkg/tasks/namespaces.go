@@ -0,0 +1,78 @@
+package tasks
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Namespace is a set of Linux namespaces a proc is run in, apart from the
+// host and the other procs.
+type Namespace int
+
+const (
+	// NamespacePID hides the processes of the host and other procs. It
+	// implies NamespaceMount so that /proc can be mounted for the new PID
+	// namespace.
+	NamespacePID Namespace = 1 << iota
+
+	// NamespaceMount gives the proc mounts that aren't seen by the host.
+	NamespaceMount
+
+	// NamespaceNetwork gives the proc its own network stack with only a
+	// loopback interface, so it can bind ports other procs are using.
+	NamespaceNetwork
+)
+
+var namespaceNames = []struct {
+	ns   Namespace
+	name string
+}{
+	{NamespacePID, "pid"},
+	{NamespaceMount, "mount"},
+	{NamespaceNetwork, "net"},
+}
+
+// ParseNamespaces parses a comma separated list of namespaces, such as
+// "pid,net". "network" is accepted as well as "net".
+func ParseNamespaces(str string) (Namespace, error) {
+	var ns Namespace
+
+	for _, part := range strings.Split(str, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		if part == "network" {
+			part = "net"
+		}
+
+		found := false
+
+		for _, n := range namespaceNames {
+			if n.name == part {
+				ns |= n.ns
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return 0, fmt.Errorf("unknown namespace: %s", part)
+		}
+	}
+
+	return ns, nil
+}
+
+func (n Namespace) String() string {
+	var names []string
+
+	for _, x := range namespaceNames {
+		if n&x.ns != 0 {
+			names = append(names, x.name)
+		}
+	}
+
+	return strings.Join(names, ",")
+}
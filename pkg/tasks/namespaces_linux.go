@@ -0,0 +1,83 @@
+package tasks
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"syscall"
+)
+
+func (n Namespace) sysProcAttr() *syscall.SysProcAttr {
+	attr := &syscall.SysProcAttr{}
+
+	if n&NamespacePID != 0 {
+		attr.Cloneflags |= syscall.CLONE_NEWPID
+		n |= NamespaceMount
+	}
+
+	if n&NamespaceNetwork != 0 {
+		attr.Cloneflags |= syscall.CLONE_NEWNET
+	}
+
+	// Unsharing rather than cloning the mount namespace has the runtime make
+	// the mounts private, so that mounting /proc doesn't reach the host.
+	if n&NamespaceMount != 0 {
+		attr.Unshareflags |= syscall.CLONE_NEWNS
+	}
+
+	return attr
+}
+
+// setupScript is run by sh inside the namespaces before the proc is exec'd,
+// to finish setting them up.
+func (n Namespace) setupScript() string {
+	steps := []string{"set -e"}
+
+	if n&NamespacePID != 0 {
+		steps = append(steps, "mount -t proc proc /proc")
+	}
+
+	if n&NamespaceNetwork != 0 {
+		steps = append(steps, "ip link set lo up")
+	}
+
+	return strings.Join(steps, "; ")
+}
+
+// checkNamespaces reports whether procs can be run in ns, by setting it up
+// for a command that does nothing. Creating namespaces needs privileges the
+// runner may not have.
+func checkNamespaces(ns Namespace) error {
+	cmd := exec.Command("sh", "-c", ns.setupScript())
+	cmd.SysProcAttr = ns.sysProcAttr()
+
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if len(out) > 0 {
+			return fmt.Errorf("unable to set up %s namespaces: %w: %s", ns, err, strings.TrimSpace(string(out)))
+		}
+
+		return fmt.Errorf("unable to set up %s namespaces: %w", ns, err)
+	}
+
+	return nil
+}
+
+// isolate changes cmd to run in ns, leaving it as is if ns can't be set up.
+func isolate(cmd *exec.Cmd, ns Namespace) error {
+	if err := checkNamespaces(ns); err != nil {
+		return err
+	}
+
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		return err
+	}
+
+	args := append([]string{"sh", "-c", ns.setupScript() + `; exec "$@"`, "sh", cmd.Path}, cmd.Args[1:]...)
+
+	cmd.Path = sh
+	cmd.Args = args
+	cmd.SysProcAttr = ns.sysProcAttr()
+
+	return nil
+}
@@ -0,0 +1,16 @@
+//go:build !linux
+
+package tasks
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+func checkNamespaces(ns Namespace) error {
+	return fmt.Errorf("%s namespaces are only supported on Linux", ns)
+}
+
+func isolate(cmd *exec.Cmd, ns Namespace) error {
+	return checkNamespaces(ns)
+}
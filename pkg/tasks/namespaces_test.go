@@ -0,0 +1,79 @@
+package tasks
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNamespaces(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Namespace
+		wantErr bool
+	}{
+		{in: "", want: 0},
+		{in: "pid", want: NamespacePID},
+		{in: "pid,net", want: NamespacePID | NamespaceNetwork},
+		{in: " mount , network ", want: NamespaceMount | NamespaceNetwork},
+		{in: "pid,user", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			ns, err := ParseNamespaces(tt.in)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, ns)
+		})
+	}
+
+	assert.Equal(t, "pid,mount,net", (NamespacePID | NamespaceMount | NamespaceNetwork).String())
+}
+
+func TestNamespaceIsolation(t *testing.T) {
+	ns := NamespacePID | NamespaceNetwork
+
+	if err := checkNamespaces(ns); err != nil {
+		t.Skipf("namespaces unavailable: %s", err)
+	}
+
+	// Prints the proc's pid, then every pid it can see along with its parent,
+	// then its network interfaces. The pids are listed with builtins so the
+	// listing itself doesn't start any processes.
+	cmd := exec.Command("sh", "-c", `echo $$; for p in /proc/[0-9]*; do read -r pid _ _ ppid _ < $p/stat && printf '%s:%s ' $pid $ppid; done; echo; tail -n +3 /proc/net/dev | cut -d: -f1`)
+
+	require.NoError(t, isolate(cmd, ns))
+
+	out, err := cmd.Output()
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	require.Len(t, lines, 3, "unexpected output: %s", out)
+
+	assert.Equal(t, "1", lines[0], "proc should be pid 1 in its namespace")
+
+	self := strconv.Itoa(os.Getpid())
+
+	procs := strings.Fields(lines[1])
+	require.NotEmpty(t, procs, "proc should see itself")
+
+	for _, proc := range procs {
+		pid, ppid, ok := strings.Cut(proc, ":")
+		require.True(t, ok, "unexpected proc entry %q", proc)
+
+		assert.True(t, pid == "1" || ppid == "1", "proc can see pid %s, which isn't one of its children", pid)
+		assert.NotEqual(t, self, pid, "proc can see the test process")
+	}
+
+	assert.Equal(t, "lo", strings.TrimSpace(lines[2]), "proc should only have loopback")
+}
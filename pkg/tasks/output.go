@@ -0,0 +1,187 @@
+package tasks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/mattn/go-isatty"
+)
+
+// OutputFormat is how Run writes the output of its procs.
+type OutputFormat int
+
+const (
+	// OutputText prefixes each line with the name of the proc that wrote it.
+	OutputText OutputFormat = iota
+
+	// OutputJSON writes each line as a JSON object:
+	//
+	//	{"proc":"web","line":"...","stream":"stdout"}
+	//
+	// Lines from the runner itself, like a proc starting or exiting, use the
+	// "status" stream.
+	OutputJSON
+)
+
+// Streams of an OutputLine.
+const (
+	StreamStdout = "stdout"
+	StreamStderr = "stderr"
+	StreamStatus = "status"
+)
+
+// OutputLine is one line of a proc's output in OutputJSON.
+type OutputLine struct {
+	Proc   string `json:"proc"`
+	Line   string `json:"line"`
+	Stream string `json:"stream"`
+}
+
+// prefixColors are the ANSI colors proc names are shown in, skipping red so
+// it doesn't read as an error.
+var prefixColors = []int{32, 33, 34, 35, 36, 92, 93, 94, 95, 96}
+
+// procColor picks a color for name, the same one on every run.
+func procColor(name string) int {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+
+	return prefixColors[h.Sum32()%uint32(len(prefixColors))]
+}
+
+// output serializes the lines of every proc onto one writer.
+type output struct {
+	mu     sync.Mutex
+	w      io.Writer
+	format OutputFormat
+	color  bool
+	width  int
+}
+
+func newOutput(o *runOpts, width int) *output {
+	color := false
+
+	switch {
+	case o.Color != nil:
+		color = *o.Color
+	case o.Output == nil:
+		color = os.Getenv("NO_COLOR") == "" && isatty.IsTerminal(os.Stdout.Fd())
+	}
+
+	w := o.Output
+	if w == nil {
+		w = os.Stdout
+	}
+
+	return &output{
+		w:      w,
+		format: o.Format,
+		color:  color && o.Format == OutputText,
+		width:  width,
+	}
+}
+
+// procOutput writes lines on behalf of one proc.
+type procOutput struct {
+	*output
+
+	name   string
+	prefix []byte
+}
+
+func (o *output) proc(name string) *procOutput {
+	var buf bytes.Buffer
+
+	if o.color {
+		fmt.Fprintf(&buf, "\x1b[%dm", procColor(name))
+	}
+
+	fmt.Fprintf(&buf, "%-*s |", o.width, name)
+
+	if o.color {
+		buf.WriteString("\x1b[0m")
+	}
+
+	buf.WriteByte(' ')
+
+	return &procOutput{output: o, name: name, prefix: buf.Bytes()}
+}
+
+func (p *procOutput) line(stream string, line []byte) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.format == OutputJSON {
+		data, _ := json.Marshal(OutputLine{
+			Proc:   p.name,
+			Line:   string(line),
+			Stream: stream,
+		})
+
+		p.w.Write(append(data, '\n'))
+		return
+	}
+
+	buf := make([]byte, 0, len(p.prefix)+len(line)+1)
+	buf = append(buf, p.prefix...)
+	buf = append(buf, line...)
+	buf = append(buf, '\n')
+
+	p.w.Write(buf)
+}
+
+// status writes a message from the runner about the proc.
+func (p *procOutput) status(format string, args ...any) {
+	p.line(StreamStatus, fmt.Appendf(nil, format, args...))
+}
+
+// stream returns a writer for the proc's stream, for use as a command's
+// Stdout or Stderr.
+func (p *procOutput) stream(stream string) *lineWriter {
+	return &lineWriter{out: p, stream: stream}
+}
+
+// lineWriter splits what's written to it into lines, holding on to a
+// partial line until the rest of it arrives or Flush is called.
+type lineWriter struct {
+	out    *procOutput
+	stream string
+	buf    []byte
+}
+
+func (w *lineWriter) Write(data []byte) (int, error) {
+	w.buf = append(w.buf, data...)
+
+	for {
+		idx := bytes.IndexByte(w.buf, '\n')
+		if idx < 0 {
+			break
+		}
+
+		w.out.line(w.stream, bytes.TrimSuffix(w.buf[:idx], []byte("\r")))
+		w.buf = w.buf[idx+1:]
+	}
+
+	// Move any partial line to the front, so buf doesn't keep growing
+	if len(w.buf) == 0 {
+		w.buf = nil
+	} else {
+		w.buf = append([]byte(nil), w.buf...)
+	}
+
+	return len(data), nil
+}
+
+// Flush writes out a partial line left by a proc that exited without
+// ending its last line.
+func (w *lineWriter) Flush() {
+	if len(w.buf) > 0 {
+		w.out.line(w.stream, w.buf)
+		w.buf = nil
+	}
+}
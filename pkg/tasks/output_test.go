@@ -0,0 +1,108 @@
+package tasks
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunOutput(t *testing.T) {
+	proc := func() *Proc {
+		return &Proc{
+			Name:         "web",
+			Command:      []string{"sh", "-c", "echo one; echo oops >&2; printf two"},
+			ExitWhenDone: true,
+		}
+	}
+
+	t.Run("prefixes lines with the proc name", func(t *testing.T) {
+		r := require.New(t)
+
+		var buf bytes.Buffer
+
+		err := Run(t.Context(), &Procfile{Proceses: []*Proc{proc()}}, WithOutput(&buf))
+		r.NoError(err)
+
+		out := buf.String()
+		r.Contains(out, "web | starting...\n")
+		r.Contains(out, "web | one\n")
+		r.Contains(out, "web | oops\n")
+		r.Contains(out, "web | two\n", "partial last line wasn't flushed")
+		r.NotContains(out, "\x1b[")
+	})
+
+	t.Run("colors proc names the same each run", func(t *testing.T) {
+		r := require.New(t)
+
+		r.Equal(procColor("web"), procColor("web"))
+
+		var buf bytes.Buffer
+
+		err := Run(t.Context(), &Procfile{Proceses: []*Proc{proc()}}, WithOutput(&buf), WithColor(true))
+		r.NoError(err)
+
+		r.Contains(buf.String(), fmt.Sprintf("\x1b[%dmweb |\x1b[0m one\n", procColor("web")))
+	})
+
+	t.Run("writes lines as JSON", func(t *testing.T) {
+		r := require.New(t)
+
+		var buf bytes.Buffer
+
+		err := Run(t.Context(), &Procfile{Proceses: []*Proc{proc()}},
+			WithOutput(&buf), WithOutputFormat(OutputJSON), WithColor(true))
+		r.NoError(err)
+
+		var (
+			lines   []OutputLine
+			streams = map[string][]string{}
+		)
+
+		sc := bufio.NewScanner(&buf)
+		for sc.Scan() {
+			var line OutputLine
+			r.NoError(json.Unmarshal(sc.Bytes(), &line), "not JSON: %s", sc.Text())
+
+			lines = append(lines, line)
+			streams[line.Stream] = append(streams[line.Stream], line.Line)
+		}
+
+		r.NotEmpty(lines)
+		for _, line := range lines {
+			r.Equal("web", line.Proc)
+			r.NotContains(line.Line, "\x1b[")
+		}
+
+		r.Equal([]string{"one", "two"}, streams[StreamStdout])
+		r.Equal([]string{"oops"}, streams[StreamStderr])
+		r.Equal([]string{"starting..."}, streams[StreamStatus])
+	})
+}
+
+func TestLineWriter(t *testing.T) {
+	r := require.New(t)
+
+	var buf bytes.Buffer
+
+	o := &output{w: &buf, width: 3}
+	w := o.proc("web").stream(StreamStdout)
+
+	fmt.Fprint(w, "hel")
+	r.Empty(buf.String())
+
+	fmt.Fprint(w, "lo\r\nwor")
+	r.Equal("web | hello\n", buf.String())
+
+	fmt.Fprint(w, "ld\n\npartial")
+	r.Equal("web | hello\nweb | world\nweb | \n", buf.String())
+
+	w.Flush()
+	r.Equal("web | hello\nweb | world\nweb | \nweb | partial\n", buf.String())
+
+	w.Flush()
+	r.Equal("web | hello\nweb | world\nweb | \nweb | partial\n", buf.String())
+}
@@ -0,0 +1,159 @@
+package tasks
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+)
+
+// PortMode controls whether a proc is given a port when it runs.
+type PortMode int
+
+const (
+	// PortNone procs get no port.
+	PortNone PortMode = iota
+
+	// PortAuto procs get a free port chosen by the runner.
+	PortAuto
+
+	// PortFixed procs always get the port set in Proc.Port.
+	PortFixed
+)
+
+// DefaultPortEnv is the environment variable a proc's port is passed in when
+// Proc.PortEnv is empty.
+const DefaultPortEnv = "PORT"
+
+var ErrPortsExhausted = errors.New("no free ports left in range")
+
+// PortAllocator hands out ports from [Start, End], never giving the same port
+// out twice. Ports that are reserved or already bound on the host are skipped.
+type PortAllocator struct {
+	Start, End int
+
+	// Probe reports whether a port can be bound. It defaults to trying to
+	// listen on the port on all interfaces.
+	Probe func(port int) bool
+
+	used map[int]bool
+	next int
+}
+
+func NewPortAllocator(start, end int) *PortAllocator {
+	return &PortAllocator{
+		Start: start,
+		End:   end,
+	}
+}
+
+func probePort(port int) bool {
+	l, err := net.Listen("tcp", net.JoinHostPort("", strconv.Itoa(port)))
+	if err != nil {
+		return false
+	}
+
+	l.Close()
+	return true
+}
+
+// Reserve marks port as taken so it is never handed out by Allocate. It fails
+// if the port is already reserved or allocated.
+func (p *PortAllocator) Reserve(port int) error {
+	if p.used == nil {
+		p.used = make(map[int]bool)
+	}
+
+	if p.used[port] {
+		return fmt.Errorf("port %d is already in use by another proc", port)
+	}
+
+	p.used[port] = true
+	return nil
+}
+
+// Allocate returns the next free port in the range.
+func (p *PortAllocator) Allocate() (int, error) {
+	if p.used == nil {
+		p.used = make(map[int]bool)
+	}
+
+	probe := p.Probe
+	if probe == nil {
+		probe = probePort
+	}
+
+	if p.next < p.Start || p.next > p.End {
+		p.next = p.Start
+	}
+
+	for port := p.next; port <= p.End; port++ {
+		if p.used[port] || !probe(port) {
+			continue
+		}
+
+		p.used[port] = true
+		p.next = port + 1
+		return port, nil
+	}
+
+	return 0, fmt.Errorf("%w (%d-%d)", ErrPortsExhausted, p.Start, p.End)
+}
+
+// Release returns port to the allocator once the proc using it is gone.
+func (p *PortAllocator) Release(port int) {
+	delete(p.used, port)
+}
+
+// assignPorts fills in Port for every proc that wants one. Fixed ports are
+// reserved first so auto procs never collide with them regardless of order.
+func assignPorts(pf *Procfile, alloc *PortAllocator) error {
+	for _, proc := range pf.Proceses {
+		if proc.PortMode == PortFixed {
+			if err := assignPort(proc, alloc); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, proc := range pf.Proceses {
+		if proc.PortMode == PortAuto {
+			if err := assignPort(proc, alloc); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// assignPort reserves a fixed proc's port, or allocates an auto proc one.
+func assignPort(proc *Proc, alloc *PortAllocator) error {
+	switch proc.PortMode {
+	case PortFixed:
+		if proc.Port <= 0 {
+			return fmt.Errorf("proc %s has a fixed port mode but no port", proc.Name)
+		}
+
+		if err := alloc.Reserve(proc.Port); err != nil {
+			return fmt.Errorf("proc %s: %w", proc.Name, err)
+		}
+	case PortAuto:
+		port, err := alloc.Allocate()
+		if err != nil {
+			return fmt.Errorf("proc %s: %w", proc.Name, err)
+		}
+
+		proc.Port = port
+	}
+
+	return nil
+}
+
+func (pr *Proc) portEnv() string {
+	if pr.PortEnv != "" {
+		return pr.PortEnv
+	}
+
+	return DefaultPortEnv
+}
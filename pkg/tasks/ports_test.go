@@ -0,0 +1,87 @@
+package tasks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssignPorts(t *testing.T) {
+	t.Run("gives autos distinct ports and honors fixed ports", func(t *testing.T) {
+		r := require.New(t)
+
+		pf := &Procfile{
+			Proceses: []*Proc{
+				{Name: "web", PortMode: PortAuto},
+				{Name: "api", PortMode: PortFixed, Port: 7000},
+				{Name: "admin", PortMode: PortAuto},
+				{Name: "worker"},
+			},
+		}
+
+		alloc := NewPortAllocator(7000, 7010)
+		alloc.Probe = func(int) bool { return true }
+
+		r.NoError(assignPorts(pf, alloc))
+
+		web, api, admin, worker := pf.Proceses[0], pf.Proceses[1], pf.Proceses[2], pf.Proceses[3]
+
+		r.Equal(7000, api.Port)
+		r.NotEqual(web.Port, admin.Port)
+		r.NotEqual(7000, web.Port)
+		r.NotEqual(7000, admin.Port)
+		r.Equal(0, worker.Port)
+
+		for _, port := range []int{web.Port, admin.Port} {
+			r.GreaterOrEqual(port, 7000)
+			r.LessOrEqual(port, 7010)
+		}
+	})
+
+	t.Run("skips ports already bound on the host", func(t *testing.T) {
+		r := require.New(t)
+
+		pf := &Procfile{
+			Proceses: []*Proc{
+				{Name: "web", PortMode: PortAuto},
+			},
+		}
+
+		alloc := NewPortAllocator(7000, 7010)
+		alloc.Probe = func(port int) bool { return port != 7000 }
+
+		r.NoError(assignPorts(pf, alloc))
+		r.Equal(7001, pf.Proceses[0].Port)
+	})
+
+	t.Run("rejects two procs fixed to the same port", func(t *testing.T) {
+		pf := &Procfile{
+			Proceses: []*Proc{
+				{Name: "web", PortMode: PortFixed, Port: 7000},
+				{Name: "api", PortMode: PortFixed, Port: 7000},
+			},
+		}
+
+		err := assignPorts(pf, NewPortAllocator(7000, 7010))
+		require.ErrorContains(t, err, "proc api: port 7000 is already in use")
+	})
+
+	t.Run("reports exhaustion", func(t *testing.T) {
+		r := require.New(t)
+
+		pf := &Procfile{
+			Proceses: []*Proc{
+				{Name: "web", PortMode: PortFixed, Port: 7000},
+				{Name: "api", PortMode: PortAuto},
+				{Name: "admin", PortMode: PortAuto},
+			},
+		}
+
+		alloc := NewPortAllocator(7000, 7001)
+		alloc.Probe = func(int) bool { return true }
+
+		err := assignPorts(pf, alloc)
+		r.ErrorIs(err, ErrPortsExhausted)
+		r.ErrorContains(err, "proc admin")
+	})
+}
@@ -5,9 +5,14 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"strconv"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 )
 
 type Proc struct {
@@ -15,6 +20,36 @@ type Proc struct {
 	Command []string
 
 	ExitWhenDone bool
+
+	// PortMode selects whether the proc is given a port. The port is passed
+	// in the PortEnv environment variable, PORT by default.
+	PortMode PortMode
+	Port     int
+	PortEnv  string
+
+	// Namespaces are the Linux namespaces the proc is isolated in. When they
+	// can't be set up, such as without privileges, the proc runs without them.
+	Namespaces Namespace
+
+	// Restart is whether the proc is started again once it exits. Restarts
+	// wait RestartBackoff, DefaultRestartBackoff if zero, doubling after each
+	// one. MaxRestarts caps how many times it's restarted, 0 for no limit.
+	Restart        RestartPolicy
+	RestartBackoff time.Duration
+	MaxRestarts    int
+
+	// WaitFor names the procs that have to be ready before this one starts,
+	// failing the run if they aren't within WaitTimeout, 0 for no limit.
+	WaitFor     []string
+	WaitTimeout time.Duration
+
+	// Health decides when the proc is ready for the procs waiting for it.
+	// Without one, it's ready once it starts.
+	Health *HealthCheck
+
+	// StopTimeout is how long the proc has to exit after being sent SIGTERM
+	// before it's killed, DefaultStopTimeout if zero.
+	StopTimeout time.Duration
 }
 
 type Procfile struct {
@@ -38,6 +73,8 @@ func ParseFile(path string) (*Procfile, error) {
 
 		// Process the line if we got any data, even if there was an error
 		if len(line) > 0 {
+			indented := line[0] == ' ' || line[0] == '\t'
+
 			line = bytes.TrimSpace(line)
 
 			// Skip empty lines
@@ -53,6 +90,20 @@ func ParseFile(path string) (*Procfile, error) {
 				return nil, fmt.Errorf("invalid line: %s", string(line))
 			}
 
+			// Indented lines are directives for the proc above them
+			if indented && len(procs) > 0 {
+				derr := procs[len(procs)-1].setDirective(
+					strings.TrimSpace(string(name)), strings.TrimSpace(string(command)))
+				if derr != nil {
+					return nil, derr
+				}
+
+				if err != nil {
+					break
+				}
+				continue
+			}
+
 			procs = append(procs, &Proc{
 				Name:    string(name),
 				Command: []string{"sh", "-c", strings.TrimSpace(string(command))},
@@ -70,106 +121,355 @@ func ParseFile(path string) (*Procfile, error) {
 	return &Procfile{Proceses: procs}, nil
 }
 
-func Run(ctx context.Context, pf *Procfile) error {
-	var (
-		width   int
-		waitFor *exec.Cmd
-	)
-
-	for _, proc := range pf.Proceses {
-		if len(proc.Name) > width {
-			width = len(proc.Name)
+// setDirective sets one of the proc's options from a directive line:
+//
+//	restart: always|on-failure|never
+//	restart-backoff: 2s
+//	max-restarts: 5
+//	wait-for: db, cache
+//	wait-timeout: 30s
+//	health: tcp [addr] | cmd <command>
+//	health-interval: 500ms
+//
+// Underscores in keys are read as dashes, so wait_for works too.
+func (pr *Proc) setDirective(key, value string) error {
+	var err error
+
+	key = strings.ReplaceAll(key, "_", "-")
+
+	switch key {
+	case "restart":
+		pr.Restart, err = ParseRestartPolicy(value)
+	case "restart-backoff":
+		pr.RestartBackoff, err = time.ParseDuration(value)
+	case "max-restarts":
+		pr.MaxRestarts, err = strconv.Atoi(value)
+	case "wait-for":
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				pr.WaitFor = append(pr.WaitFor, name)
+			}
+		}
+	case "wait-timeout":
+		pr.WaitTimeout, err = time.ParseDuration(value)
+	case "health":
+		pr.Health, err = ParseHealthCheck(value)
+	case "health-interval":
+		if pr.Health == nil {
+			return fmt.Errorf("proc %s: health-interval set before health", pr.Name)
 		}
+
+		pr.Health.Interval, err = time.ParseDuration(value)
+	default:
+		return fmt.Errorf("proc %s: unknown directive: %s", pr.Name, key)
 	}
 
-	for _, proc := range pf.Proceses {
-		cmd, err := runProc(ctx, proc, width)
-		if err != nil {
-			return err
-		}
+	if err != nil {
+		return fmt.Errorf("proc %s: invalid %s: %w", pr.Name, key, err)
+	}
 
-		if proc.ExitWhenDone {
-			waitFor = cmd
-		}
+	return nil
+}
+
+func (pr *Proc) validate() error {
+	if pr.ExitWhenDone && pr.Restart == RestartAlways {
+		return fmt.Errorf("proc %s can't both exit when done and always restart", pr.Name)
 	}
 
-	if waitFor == nil {
-		<-ctx.Done()
-		return ctx.Err()
+	if pr.Health != nil {
+		return pr.Health.validate(pr)
 	}
 
-	return waitFor.Wait()
+	return nil
 }
 
-func runProc(ctx context.Context, pr *Proc, width int) (*exec.Cmd, error) {
-	cmd := exec.CommandContext(ctx, pr.Command[0], pr.Command[1:]...)
+type runOpts struct {
+	PortStart, PortEnd int
 
-	outr, err := cmd.StdoutPipe()
-	if err != nil {
-		return nil, err
+	// Output is where the procs' output goes, os.Stdout if nil.
+	Output io.Writer
+	Format OutputFormat
+
+	// Color, if set, forces proc names to be colored or not. Otherwise
+	// they're colored when writing to a terminal.
+	Color *bool
+}
+
+type RunOption func(*runOpts)
+
+// WithPortRange sets the range auto ports are allocated from.
+func WithPortRange(start, end int) RunOption {
+	return func(o *runOpts) {
+		o.PortStart = start
+		o.PortEnd = end
 	}
+}
+
+// WithOutput sends the procs' output to w rather than os.Stdout.
+func WithOutput(w io.Writer) RunOption {
+	return func(o *runOpts) {
+		o.Output = w
+	}
+}
+
+// WithOutputFormat sets how the procs' output is written, OutputText by
+// default.
+func WithOutputFormat(format OutputFormat) RunOption {
+	return func(o *runOpts) {
+		o.Format = format
+	}
+}
 
-	errr, err := cmd.StderrPipe()
+// WithColor forces coloring each proc's name in OutputText on or off.
+func WithColor(color bool) RunOption {
+	return func(o *runOpts) {
+		o.Color = &color
+	}
+}
+
+const (
+	DefaultPortStart = 5000
+	DefaultPortEnd   = 5999
+)
+
+// Run runs the procs in pf until ctx is done or a proc with ExitWhenDone
+// exits, returning its error.
+func Run(ctx context.Context, pf *Procfile, opts ...RunOption) error {
+	c, err := Start(ctx, pf, opts...)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	var buf bytes.Buffer
-	buf.WriteString(pr.Name)
+	return c.Wait()
+}
 
-	for i := 0; i < width-len(pr.Name); i++ {
-		buf.WriteString(" ")
+// procHandle is a proc that's restarted as its policy says, and on request.
+type procHandle struct {
+	pr    *Proc
+	out   *procOutput
+	ready chan struct{}
+
+	// done receives the error of the proc's last exit once it's stopped
+	// for good.
+	done chan error
+
+	// restart is sent to when a restart is requested, skipping the policy
+	// and any backoff.
+	restart chan struct{}
+
+	mu      sync.Mutex
+	cmd     *exec.Cmd
+	started bool
+	stopped bool
+}
+
+func newProcHandle(pr *Proc, out *procOutput, ready chan struct{}) *procHandle {
+	return &procHandle{
+		pr:      pr,
+		out:     out,
+		ready:   ready,
+		done:    make(chan error, 1),
+		restart: make(chan struct{}, 1),
 	}
+}
 
-	buf.WriteString(" | ")
+// start starts the proc, then keeps it running in the background. ready is
+// closed once the proc first passes its health check.
+func (h *procHandle) start(ctx context.Context) error {
+	cmd, wait, err := startProc(ctx, h.pr, h.out)
+	if err != nil {
+		return err
+	}
 
-	prefix := buf.Bytes()
+	h.mu.Lock()
+	h.cmd = cmd
+	h.started = true
+	h.mu.Unlock()
 
-	os.Stdout.Write(prefix)
-	os.Stdout.Write([]byte("starting...\n"))
+	go waitHealthy(ctx, h.pr, h.out, h.ready)
+	go h.supervise(ctx, wait)
 
-	go func() {
-		defer outr.Close()
-		sc := bufio.NewReader(outr)
+	return nil
+}
 
-		for {
-			line, err := sc.ReadBytes('\n')
-			if err != nil {
-				break
-			}
+func (h *procHandle) supervise(ctx context.Context, wait func() error) {
+	pr, out := h.pr, h.out
+	backoff := pr.restartBackoff()
+
+	for restarts := 0; ; {
+		err := wait()
+		if err != nil {
+			out.status("error: %s", err)
+		}
 
-			os.Stdout.Write(prefix)
-			os.Stdout.Write(line)
+		h.mu.Lock()
+		h.cmd = nil
+
+		var requested bool
+
+		select {
+		case <-h.restart:
+			requested = true
+		default:
 		}
-	}()
 
-	go func() {
-		defer errr.Close()
-		sc := bufio.NewReader(errr)
+		if ctx.Err() != nil || (!requested && !pr.shouldRestart(err, restarts)) {
+			h.stopped = true
+			h.mu.Unlock()
+
+			h.done <- err
+			return
+		}
 
-		for {
-			line, err := sc.ReadBytes('\n')
-			if err != nil {
-				break
+		h.mu.Unlock()
+
+		if requested {
+			out.status("restarting...")
+		} else {
+			out.status("restarting in %s...", backoff)
+
+			select {
+			case <-ctx.Done():
+				h.finish(err)
+				return
+			case <-h.restart:
+			case <-time.After(backoff):
 			}
 
-			os.Stdout.Write(prefix)
-			os.Stdout.Write(line)
+			backoff = min(backoff*2, maxRestartBackoff)
+			restarts++
 		}
-	}()
 
-	err = cmd.Start()
-	if err != nil {
-		return nil, err
+		cmd, next, err := startProc(ctx, pr, out)
+		if err != nil {
+			out.status("error: %s", err)
+			h.finish(err)
+			return
+		}
+
+		h.mu.Lock()
+		h.cmd = cmd
+		h.mu.Unlock()
+
+		wait = next
 	}
+}
 
-	go func() {
-		err = cmd.Wait()
-		if err != nil {
-			os.Stdout.Write(prefix)
-			fmt.Printf("error: %s\n", err)
+func (h *procHandle) finish(err error) {
+	h.mu.Lock()
+	h.stopped = true
+	h.mu.Unlock()
+
+	h.done <- err
+}
+
+// signal sends sig to the proc's current process.
+func (h *procHandle) signal(sig os.Signal) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.cmd == nil {
+		return fmt.Errorf("proc %s isn't running", h.pr.Name)
+	}
+
+	return h.cmd.Process.Signal(sig)
+}
+
+// requestRestart stops the proc's current process with SIGTERM, killing it
+// if it hasn't exited after its StopTimeout, and starts it again.
+func (h *procHandle) requestRestart() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !h.started || h.stopped {
+		return fmt.Errorf("proc %s isn't running", h.pr.Name)
+	}
+
+	select {
+	case h.restart <- struct{}{}:
+	default:
+		// Already restarting
+		return nil
+	}
+
+	cmd := h.cmd
+	if cmd == nil {
+		// Waiting to be restarted, which now happens right away
+		return nil
+	}
+
+	if err := cmd.Process.Signal(syscall.SIGTERM); err != nil {
+		return err
+	}
+
+	time.AfterFunc(h.pr.stopTimeout(), func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+
+		if h.cmd == cmd {
+			cmd.Process.Kill()
 		}
-	}()
+	})
+
+	return nil
+}
+
+// DefaultStopTimeout is how long a proc has to exit once stopped when
+// Proc.StopTimeout is zero.
+const DefaultStopTimeout = 10 * time.Second
+
+func (pr *Proc) stopTimeout() time.Duration {
+	if pr.StopTimeout > 0 {
+		return pr.StopTimeout
+	}
+
+	return DefaultStopTimeout
+}
+
+// startProc starts the proc, returning it along with a function that waits
+// for it to exit and for the last of its output to be written. Once ctx is
+// done, the proc is sent SIGTERM, and killed if it's still running after its
+// StopTimeout. Its output is read for at most as long after it exits, in
+// case something it started holds on to its stdout or stderr.
+func startProc(ctx context.Context, pr *Proc, out *procOutput) (*exec.Cmd, func() error, error) {
+	cmd := exec.CommandContext(ctx, pr.Command[0], pr.Command[1:]...)
+	cmd.Cancel = func() error {
+		return cmd.Process.Signal(syscall.SIGTERM)
+	}
+	cmd.WaitDelay = pr.stopTimeout()
+
+	if pr.PortMode != PortNone {
+		cmd.Env = append(os.Environ(), fmt.Sprintf("%s=%d", pr.portEnv(), pr.Port))
+	}
+
+	if pr.Namespaces != 0 {
+		if err := isolate(cmd, pr.Namespaces); err != nil {
+			out.status("warning: running without namespaces: %s", err)
+		}
+	}
+
+	stdout := out.stream(StreamStdout)
+	stderr := out.stream(StreamStderr)
+
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	if pr.PortMode != PortNone {
+		out.status("starting on port %d...", pr.Port)
+	} else {
+		out.status("starting...")
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, nil, err
+	}
+
+	return cmd, func() error {
+		err := cmd.Wait()
+
+		stdout.Flush()
+		stderr.Flush()
 
-	return cmd, nil
+		return err
+	}, nil
 }
@@ -1,9 +1,13 @@
 package tasks
 
 import (
+	"context"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -198,3 +202,120 @@ func TestParseFileHandlesLastLineWithoutNewline(t *testing.T) {
 		assert.Equal(t, []string{"sh", "-c", "npm run scheduler"}, pf.Proceses[2].Command)
 	})
 }
+
+func TestParseFileDirectives(t *testing.T) {
+	t.Run("applies indented directives to the proc above", func(t *testing.T) {
+		r := require.New(t)
+
+		procfilePath := filepath.Join(t.TempDir(), "Procfile")
+		err := os.WriteFile(procfilePath, []byte(`web: npm start
+  restart: always
+worker: npm run worker
+	restart: on-failure
+	restart-backoff: 250ms
+	max-restarts: 3
+release: rake db:migrate
+`), 0644)
+		r.NoError(err)
+
+		pf, err := ParseFile(procfilePath)
+		r.NoError(err)
+		r.Len(pf.Proceses, 3)
+
+		r.Equal(RestartAlways, pf.Proceses[0].Restart)
+
+		r.Equal(RestartOnFailure, pf.Proceses[1].Restart)
+		r.Equal(250*time.Millisecond, pf.Proceses[1].RestartBackoff)
+		r.Equal(3, pf.Proceses[1].MaxRestarts)
+
+		r.Equal(RestartNever, pf.Proceses[2].Restart)
+	})
+
+	for _, content := range []string{
+		"web: npm start\n  restart: sometimes\n",
+		"web: npm start\n  max-restarts: lots\n",
+		"web: npm start\n  healthcheck: /up\n",
+	} {
+		t.Run("rejects "+strings.TrimSpace(strings.SplitN(content, "\n", 2)[1]), func(t *testing.T) {
+			procfilePath := filepath.Join(t.TempDir(), "Procfile")
+			require.NoError(t, os.WriteFile(procfilePath, []byte(content), 0644))
+
+			_, err := ParseFile(procfilePath)
+			assert.ErrorContains(t, err, "proc web")
+		})
+	}
+}
+
+func TestRunRestart(t *testing.T) {
+	// countingProc appends a line to a file each time it runs, then exits
+	// with status.
+	countingProc := func(t *testing.T, status int) (*Proc, func() int) {
+		path := filepath.Join(t.TempDir(), "runs")
+
+		proc := &Proc{
+			Name:           "proc",
+			Command:        []string{"sh", "-c", fmt.Sprintf("echo run >> %s; exit %d", path, status)},
+			ExitWhenDone:   true,
+			RestartBackoff: 10 * time.Millisecond,
+		}
+
+		return proc, func() int {
+			data, _ := os.ReadFile(path)
+			return strings.Count(string(data), "run")
+		}
+	}
+
+	t.Run("restarts a crashing on-failure proc", func(t *testing.T) {
+		r := require.New(t)
+
+		proc, runs := countingProc(t, 1)
+		proc.Restart = RestartOnFailure
+		proc.MaxRestarts = 2
+
+		err := Run(t.Context(), &Procfile{Proceses: []*Proc{proc}})
+		r.Error(err)
+
+		r.Equal(3, runs())
+	})
+
+	t.Run("doesn't restart an on-failure proc that exits cleanly", func(t *testing.T) {
+		r := require.New(t)
+
+		proc, runs := countingProc(t, 0)
+		proc.Restart = RestartOnFailure
+
+		err := Run(t.Context(), &Procfile{Proceses: []*Proc{proc}})
+		r.NoError(err)
+
+		r.Equal(1, runs())
+	})
+
+	t.Run("always restarts a proc that exits cleanly", func(t *testing.T) {
+		r := require.New(t)
+
+		proc, runs := countingProc(t, 0)
+		proc.ExitWhenDone = false
+		proc.Restart = RestartAlways
+		proc.MaxRestarts = 2
+
+		ctx, cancel := context.WithCancel(t.Context())
+		defer cancel()
+
+		go Run(ctx, &Procfile{Proceses: []*Proc{proc}})
+
+		r.Eventually(func() bool { return runs() == 3 }, 5*time.Second, 10*time.Millisecond)
+
+		time.Sleep(100 * time.Millisecond)
+		r.Equal(3, runs(), "restarted past MaxRestarts")
+	})
+
+	t.Run("rejects a proc that exits when done and always restarts", func(t *testing.T) {
+		proc, runs := countingProc(t, 0)
+		proc.Restart = RestartAlways
+
+		err := Run(t.Context(), &Procfile{Proceses: []*Proc{proc}})
+		assert.ErrorContains(t, err, "always restart")
+
+		assert.Zero(t, runs())
+	})
+}
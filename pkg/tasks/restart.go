@@ -0,0 +1,81 @@
+package tasks
+
+import (
+	"fmt"
+	"time"
+)
+
+// RestartPolicy controls whether a proc is started again once it exits.
+type RestartPolicy int
+
+const (
+	// RestartNever leaves the proc stopped once it exits.
+	RestartNever RestartPolicy = iota
+
+	// RestartOnFailure restarts the proc when it exits with an error, such
+	// as a non-zero status or a signal.
+	RestartOnFailure
+
+	// RestartAlways restarts the proc however it exits.
+	RestartAlways
+)
+
+var restartNames = map[RestartPolicy]string{
+	RestartNever:     "never",
+	RestartOnFailure: "on-failure",
+	RestartAlways:    "always",
+}
+
+// ParseRestartPolicy parses "never", "on-failure" or "always".
+func ParseRestartPolicy(str string) (RestartPolicy, error) {
+	for policy, name := range restartNames {
+		if name == str {
+			return policy, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown restart policy: %s", str)
+}
+
+func (r RestartPolicy) String() string {
+	if name, ok := restartNames[r]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("RestartPolicy(%d)", int(r))
+}
+
+const (
+	// DefaultRestartBackoff is how long a proc waits before its first
+	// restart when Proc.RestartBackoff is zero.
+	DefaultRestartBackoff = time.Second
+
+	// maxRestartBackoff caps the wait between restarts, which doubles after
+	// each one.
+	maxRestartBackoff = 30 * time.Second
+)
+
+func (pr *Proc) restartBackoff() time.Duration {
+	if pr.RestartBackoff > 0 {
+		return pr.RestartBackoff
+	}
+
+	return DefaultRestartBackoff
+}
+
+// shouldRestart reports whether the proc is restarted after exiting with
+// err, having been restarted restarts times already.
+func (pr *Proc) shouldRestart(err error, restarts int) bool {
+	if pr.MaxRestarts > 0 && restarts >= pr.MaxRestarts {
+		return false
+	}
+
+	switch pr.Restart {
+	case RestartAlways:
+		return true
+	case RestartOnFailure:
+		return err != nil
+	default:
+		return false
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"slices"
 	"strings"
 
 	"github.com/spf13/pflag"
@@ -16,6 +17,12 @@ import (
 var (
 	fProcfile = pflag.StringP("file", "f", "Procfile", "path to Procfile")
 	fPath     = pflag.StringArrayP("path", "p", nil, "entries to add to PATH")
+	fAutoPort = pflag.StringArray("auto-port", nil, "procs to allocate a free PORT for")
+	fPort     = pflag.StringToInt("port", nil, "procs to give a fixed PORT (name=port)")
+
+	fNamespaces = pflag.StringArray("namespaces", nil, "procs to run in their own Linux namespaces (name=pid,mount,net)")
+
+	fJSON = pflag.Bool("json", false, "write each line of output as a JSON object")
 )
 
 func main() {
@@ -44,6 +51,30 @@ func main() {
 
 	os.Setenv("WORKTMP", tmpPath)
 
+	namespaces := map[string]tasks.Namespace{}
+
+	for _, ent := range *fNamespaces {
+		name, str, _ := strings.Cut(ent, "=")
+
+		ns, err := tasks.ParseNamespaces(str)
+		if err != nil {
+			log.Fatalf("Error parsing namespaces of %s: %v", name, err)
+		}
+
+		namespaces[name] = ns
+	}
+
+	for _, proc := range procfile.Proceses {
+		if port, ok := (*fPort)[proc.Name]; ok {
+			proc.PortMode = tasks.PortFixed
+			proc.Port = port
+		} else if slices.Contains(*fAutoPort, proc.Name) {
+			proc.PortMode = tasks.PortAuto
+		}
+
+		proc.Namespaces = namespaces[proc.Name]
+	}
+
 	if pflag.NArg() == 0 {
 		procfile.Proceses = append(procfile.Proceses, &tasks.Proc{
 			Name:         "command",
@@ -52,7 +83,13 @@ func main() {
 		})
 	}
 
-	err = tasks.Run(ctx, procfile)
+	var opts []tasks.RunOption
+
+	if *fJSON {
+		opts = append(opts, tasks.WithOutputFormat(tasks.OutputJSON))
+	}
+
+	err = tasks.Run(ctx, procfile, opts...)
 	if err != nil {
 		fmt.Printf("error running procfile: %s\n", err)
 		os.Exit(1)
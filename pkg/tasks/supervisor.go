@@ -0,0 +1,237 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Supervisor runs procs that are added and removed while it's running,
+// rather than a fixed Procfile, so it can be embedded in a larger service.
+// Each proc is restarted according to its policy until it's removed.
+type Supervisor struct {
+	ctx   context.Context
+	out   *output
+	ports *PortAllocator
+
+	mu     sync.Mutex
+	procs  map[string]*supervisedProc
+	order  []string
+	closed bool
+}
+
+type supervisedProc struct {
+	proc    *Proc
+	started time.Time
+	cancel  context.CancelFunc
+	ready   chan struct{}
+
+	// stopped is closed once the proc has exited for good, with err set to
+	// the error of its last exit.
+	stopped chan struct{}
+	err     error
+}
+
+// ProcStatus describes a proc run by a Supervisor.
+type ProcStatus struct {
+	Name string
+	Port int
+
+	// Started is zero while the proc waits for the procs it depends on.
+	Started time.Time
+
+	// Running is false once the proc has exited and won't be restarted,
+	// with Err set to why it last exited.
+	Running bool
+	Err     error
+}
+
+// NewSupervisor returns a supervisor whose procs run until they're removed
+// or ctx is done. It takes the same options as Run.
+func NewSupervisor(ctx context.Context, opts ...RunOption) *Supervisor {
+	o := runOpts{
+		PortStart: DefaultPortStart,
+		PortEnd:   DefaultPortEnd,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return &Supervisor{
+		ctx:   ctx,
+		out:   newOutput(&o, 0),
+		ports: NewPortAllocator(o.PortStart, o.PortEnd),
+		procs: make(map[string]*supervisedProc),
+	}
+}
+
+// Add starts running pr. Its name has to be unique among the supervisor's
+// procs. If it waits for other procs, which have to have already been
+// added, Add blocks until they're ready.
+func (s *Supervisor) Add(pr *Proc) error {
+	if err := pr.validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+
+	if s.closed {
+		s.mu.Unlock()
+		return fmt.Errorf("supervisor is closed")
+	}
+
+	if _, ok := s.procs[pr.Name]; ok {
+		s.mu.Unlock()
+		return fmt.Errorf("proc %s already exists", pr.Name)
+	}
+
+	ready := make(map[string]chan struct{})
+
+	for _, dep := range pr.WaitFor {
+		sp, ok := s.procs[dep]
+		if !ok {
+			s.mu.Unlock()
+			return fmt.Errorf("proc %s waits for unknown proc %s", pr.Name, dep)
+		}
+
+		ready[dep] = sp.ready
+	}
+
+	if err := assignPort(pr, s.ports); err != nil {
+		s.mu.Unlock()
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(s.ctx)
+
+	sp := &supervisedProc{
+		proc:    pr,
+		cancel:  cancel,
+		ready:   make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+
+	// Hold the name while waiting on dependencies
+	s.procs[pr.Name] = sp
+	s.order = append(s.order, pr.Name)
+
+	s.mu.Unlock()
+
+	err := waitForDeps(ctx, pr, ready)
+	if err == nil {
+		s.mu.Lock()
+		sp.started = time.Now()
+		s.mu.Unlock()
+
+		h := newProcHandle(pr, s.out.proc(pr.Name), sp.ready)
+
+		err = h.start(ctx)
+		if err == nil {
+			go func() {
+				sp.err = <-h.done
+				close(sp.stopped)
+			}()
+
+			return nil
+		}
+	}
+
+	cancel()
+	close(sp.stopped)
+	s.forget(sp)
+
+	return err
+}
+
+// Remove stops the named proc, waiting for it to exit. It's sent SIGTERM
+// and killed if it doesn't exit within its StopTimeout.
+func (s *Supervisor) Remove(name string) error {
+	s.mu.Lock()
+	sp, ok := s.procs[name]
+	s.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("no proc named %s", name)
+	}
+
+	sp.cancel()
+	<-sp.stopped
+
+	s.forget(sp)
+
+	return nil
+}
+
+// forget drops sp, unless it's already been replaced by a new proc of the
+// same name.
+func (s *Supervisor) forget(sp *supervisedProc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	name := sp.proc.Name
+
+	if s.procs[name] != sp {
+		return
+	}
+
+	delete(s.procs, name)
+
+	for i, n := range s.order {
+		if n == name {
+			s.order = append(s.order[:i], s.order[i+1:]...)
+			break
+		}
+	}
+
+	if sp.proc.PortMode != PortNone {
+		s.ports.Release(sp.proc.Port)
+	}
+}
+
+// List returns the status of every proc, in the order they were added.
+func (s *Supervisor) List() []ProcStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]ProcStatus, 0, len(s.order))
+
+	for _, name := range s.order {
+		sp := s.procs[name]
+
+		st := ProcStatus{
+			Name:    name,
+			Port:    sp.proc.Port,
+			Started: sp.started,
+			Running: true,
+		}
+
+		select {
+		case <-sp.stopped:
+			st.Running = false
+			st.Err = sp.err
+		default:
+		}
+
+		statuses = append(statuses, st)
+	}
+
+	return statuses
+}
+
+// Close stops every proc, the most recently added first so procs stop
+// before the ones they wait for, and waits for them to exit. No procs can
+// be added after.
+func (s *Supervisor) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	names := append([]string(nil), s.order...)
+	s.mu.Unlock()
+
+	for i := len(names) - 1; i >= 0; i-- {
+		s.Remove(names[i])
+	}
+
+	return nil
+}
@@ -0,0 +1,171 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSupervisor(t *testing.T) {
+	// sleeper writes its pid to a file, then runs until it's sent SIGTERM,
+	// noting that it was before exiting.
+	sleeper := func(t *testing.T, name string) (*Proc, string) {
+		dir := t.TempDir()
+
+		return &Proc{
+			Name: name,
+			Command: []string{"sh", "-c", fmt.Sprintf(
+				`echo $$ > %[1]s/pid; trap 'echo term > %[1]s/stopped; kill $!; exit 0' TERM; sleep 30 & wait`, dir)},
+		}, dir
+	}
+
+	readPid := func(dir string) int {
+		data, err := os.ReadFile(filepath.Join(dir, "pid"))
+		if err != nil {
+			return 0
+		}
+
+		pid, _ := strconv.Atoi(strings.TrimSpace(string(data)))
+		return pid
+	}
+
+	t.Run("adds and removes procs", func(t *testing.T) {
+		r := require.New(t)
+
+		s := NewSupervisor(t.Context(), WithOutput(io.Discard))
+		defer s.Close()
+
+		web, webDir := sleeper(t, "web")
+		worker, workerDir := sleeper(t, "worker")
+
+		r.NoError(s.Add(web))
+		r.NoError(s.Add(worker))
+
+		r.Eventually(func() bool {
+			return readPid(webDir) != 0 && readPid(workerDir) != 0
+		}, 5*time.Second, 10*time.Millisecond)
+
+		list := s.List()
+		r.Len(list, 2)
+		r.Equal("web", list[0].Name)
+		r.Equal("worker", list[1].Name)
+
+		for _, st := range list {
+			r.True(st.Running)
+			r.False(st.Started.IsZero())
+		}
+
+		r.ErrorContains(s.Add(&Proc{Name: "web", Command: []string{"true"}}), "already exists")
+
+		pid := readPid(workerDir)
+
+		r.NoError(s.Remove("worker"))
+
+		// It was asked to stop rather than killed, and is gone
+		r.FileExists(filepath.Join(workerDir, "stopped"))
+		r.ErrorIs(syscall.Kill(pid, 0), syscall.ESRCH)
+
+		list = s.List()
+		r.Len(list, 1)
+		r.Equal("web", list[0].Name)
+
+		r.Error(s.Remove("worker"))
+
+		// The name can be reused once the proc is removed
+		worker, _ = sleeper(t, "worker")
+		r.NoError(s.Add(worker))
+
+		r.NoError(s.Close())
+		r.Empty(s.List())
+		r.FileExists(filepath.Join(webDir, "stopped"))
+
+		r.ErrorContains(s.Add(&Proc{Name: "late", Command: []string{"true"}}), "closed")
+	})
+
+	t.Run("restarts procs by their policy", func(t *testing.T) {
+		r := require.New(t)
+
+		s := NewSupervisor(t.Context(), WithOutput(io.Discard))
+		defer s.Close()
+
+		path := filepath.Join(t.TempDir(), "runs")
+
+		r.NoError(s.Add(&Proc{
+			Name:           "crasher",
+			Command:        []string{"sh", "-c", fmt.Sprintf("echo run >> %s; exit 1", path)},
+			Restart:        RestartOnFailure,
+			RestartBackoff: 10 * time.Millisecond,
+			MaxRestarts:    2,
+		}))
+
+		r.Eventually(func() bool {
+			return !s.List()[0].Running
+		}, 5*time.Second, 10*time.Millisecond)
+
+		st := s.List()[0]
+		r.Error(st.Err)
+
+		data, err := os.ReadFile(path)
+		r.NoError(err)
+		r.Equal(3, strings.Count(string(data), "run"))
+
+		r.NoError(s.Remove("crasher"))
+	})
+
+	t.Run("kills a proc that ignores SIGTERM after its stop timeout", func(t *testing.T) {
+		r := require.New(t)
+
+		s := NewSupervisor(t.Context(), WithOutput(io.Discard))
+		defer s.Close()
+
+		r.NoError(s.Add(&Proc{
+			Name:        "stubborn",
+			Command:     []string{"sh", "-c", "trap '' TERM; sleep 30 & wait"},
+			StopTimeout: 100 * time.Millisecond,
+		}))
+
+		time.Sleep(50 * time.Millisecond)
+
+		start := time.Now()
+		r.NoError(s.Remove("stubborn"))
+		r.Less(time.Since(start), 5*time.Second)
+	})
+
+	t.Run("waits for the procs a proc depends on", func(t *testing.T) {
+		r := require.New(t)
+
+		ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+		defer cancel()
+
+		s := NewSupervisor(ctx, WithOutput(io.Discard))
+		defer s.Close()
+
+		r.ErrorContains(s.Add(&Proc{Name: "app", Command: []string{"true"}, WaitFor: []string{"db"}}), "unknown proc db")
+
+		r.NoError(s.Add(&Proc{
+			Name:    "db",
+			Command: []string{"sleep", "30"},
+			Health:  &HealthCheck{Addr: closedAddr(t), Interval: 10 * time.Millisecond},
+		}))
+
+		err := s.Add(&Proc{
+			Name:        "app",
+			Command:     []string{"true"},
+			WaitFor:     []string{"db"},
+			WaitTimeout: 50 * time.Millisecond,
+		})
+		assert.ErrorContains(t, err, "db wasn't ready")
+
+		r.Len(s.List(), 1)
+	})
+}
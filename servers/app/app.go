@@ -89,12 +89,36 @@ func (r *AppInfo) Destroy(ctx context.Context, state *app_v1alpha.CrudDestroy) e
 }
 
 func (r *AppInfo) List(ctx context.Context, state *app_v1alpha.CrudList) error {
-	list, err := r.EC.List(ctx, entity.Ref(entity.EntityKind, core_v1alpha.KindApp))
+	var ai []*app_v1alpha.AppInfo
+
+	err := r.eachApp(ctx, func(a *app_v1alpha.AppInfo) error {
+		ai = append(ai, a)
+		return nil
+	})
 	if err != nil {
 		return err
 	}
 
-	var ai []*app_v1alpha.AppInfo
+	state.Results().SetApps(ai)
+
+	return nil
+}
+
+func (r *AppInfo) ListStream(ctx context.Context, state *app_v1alpha.CrudListStream) error {
+	send := state.Args().Apps()
+
+	return r.eachApp(ctx, func(a *app_v1alpha.AppInfo) error {
+		_, err := send.Send(ctx, a)
+		return err
+	})
+}
+
+// eachApp calls fn with the info of each app in turn.
+func (r *AppInfo) eachApp(ctx context.Context, fn func(*app_v1alpha.AppInfo) error) error {
+	list, err := r.EC.List(ctx, entity.Ref(entity.EntityKind, core_v1alpha.KindApp))
+	if err != nil {
+		return err
+	}
 
 	for list.Next() {
 		var app core_v1alpha.App
@@ -119,11 +143,11 @@ func (r *AppInfo) List(ctx context.Context, state *app_v1alpha.CrudList) error {
 			a.SetCurrentVersion(&vi)
 		}
 
-		ai = append(ai, &a)
+		if err := fn(&a); err != nil {
+			return err
+		}
 	}
 
-	state.Results().SetApps(ai)
-
 	return nil
 }
 
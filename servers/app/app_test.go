@@ -3,6 +3,7 @@ package app
 import (
 	"context"
 	"log/slog"
+	"slices"
 	"testing"
 
 	"miren.dev/runtime/api/app/app_v1alpha"
@@ -11,6 +12,7 @@ import (
 	"miren.dev/runtime/metrics"
 	"miren.dev/runtime/pkg/entity/testutils"
 	"miren.dev/runtime/pkg/rpc"
+	"miren.dev/runtime/pkg/rpc/stream"
 )
 
 func TestSetConfiguration_DuplicateEnvVars(t *testing.T) {
@@ -354,3 +356,62 @@ func TestSetConfiguration_EnvVarDeletion(t *testing.T) {
 		}
 	})
 }
+
+func TestListStream(t *testing.T) {
+	ctx := context.Background()
+
+	inmem, cleanup := testutils.NewInMemEntityServer(t)
+	defer cleanup()
+
+	ec := entityserver.NewClient(slog.Default(), inmem.EAC)
+
+	appInfo := &AppInfo{
+		Log:  slog.Default(),
+		EC:   ec,
+		CPU:  &metrics.CPUUsage{},
+		Mem:  &metrics.MemoryUsage{},
+		HTTP: &metrics.HTTPMetrics{},
+	}
+
+	client := &app_v1alpha.CrudClient{
+		Client: rpc.LocalClient(app_v1alpha.AdaptCrud(appInfo)),
+	}
+
+	for _, name := range []string{"app-a", "app-b", "app-c"} {
+		if _, err := inmem.Client.Create(ctx, name, &core_v1alpha.App{}); err != nil {
+			t.Fatalf("failed to create app: %v", err)
+		}
+	}
+
+	listed, err := client.List(ctx)
+	if err != nil {
+		t.Fatalf("failed to list apps: %v", err)
+	}
+
+	var want []string
+	for _, a := range listed.Apps() {
+		want = append(want, a.Name())
+	}
+
+	var streamed []string
+
+	_, err = client.ListStream(ctx, stream.StreamRecv(func(a *app_v1alpha.AppInfo) error {
+		streamed = append(streamed, a.Name())
+		return nil
+	}))
+	if err != nil {
+		t.Fatalf("failed to stream apps: %v", err)
+	}
+
+	if len(streamed) != 3 {
+		t.Fatalf("expected 3 streamed apps, got %v", streamed)
+	}
+
+	// The store doesn't list in a stable order.
+	slices.Sort(want)
+	slices.Sort(streamed)
+
+	if !slices.Equal(want, streamed) {
+		t.Errorf("streamed apps %v differ from listed %v", streamed, want)
+	}
+}
@@ -15,8 +15,18 @@ import (
 var _ app_v1alpha.AppStatus = &AppInfo{}
 
 func (a *AppInfo) AppInfo(ctx context.Context, state *app_v1alpha.AppStatusAppInfo) error {
-	name := state.Args().Application()
+	rai, err := a.Status(ctx, state.Args().Application())
+	if err != nil {
+		return err
+	}
+
+	state.Results().SetStatus(rai)
+
+	return nil
+}
 
+// Status returns the current status of the app name.
+func (a *AppInfo) Status(ctx context.Context, name string) (*app_v1alpha.ApplicationStatus, error) {
 	var appRec core_v1alpha.App
 
 	var rai app_v1alpha.ApplicationStatus
@@ -26,11 +36,10 @@ func (a *AppInfo) AppInfo(ctx context.Context, state *app_v1alpha.AppStatusAppIn
 	if err != nil {
 		if errors.Is(err, cond.ErrNotFound{}) {
 			// No app, no status
-			state.Results().SetStatus(&rai)
-			return nil
+			return &rai, nil
 		}
 
-		return err
+		return nil, err
 	}
 
 	var appVer core_v1alpha.AppVersion
@@ -38,7 +47,7 @@ func (a *AppInfo) AppInfo(ctx context.Context, state *app_v1alpha.AppStatusAppIn
 	if appRec.ActiveVersion != "" {
 		appVerEntity, err := a.EC.GetByIdWithEntity(ctx, appRec.ActiveVersion, &appVer)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		rai.SetActiveVersion(appVer.Version)
 		rai.SetLastDeploy(standard.ToTimestamp(appVerEntity.Entity().GetCreatedAt()))
@@ -48,7 +57,7 @@ func (a *AppInfo) AppInfo(ctx context.Context, state *app_v1alpha.AppStatusAppIn
 
 	uats, err := a.CPU.CPUUsageLastHour(appRec.ID.String())
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	var usages []*app_v1alpha.CpuUsage
@@ -64,7 +73,7 @@ func (a *AppInfo) AppInfo(ctx context.Context, state *app_v1alpha.AppStatusAppIn
 
 	memusages, err := a.Mem.UsageLastHour(appRec.ID.String())
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	rai.SetCpuOverHour(usages)
@@ -183,7 +192,7 @@ func (a *AppInfo) AppInfo(ctx context.Context, state *app_v1alpha.AppStatusAppIn
 	/*
 		instances, err := a.DB.ListInstancesForApp(ac.Id)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		// Convert to API format
@@ -200,7 +209,5 @@ func (a *AppInfo) AppInfo(ctx context.Context, state *app_v1alpha.AppStatusAppIn
 		rai.SetAddons(addons)
 	*/
 
-	state.Results().SetStatus(&rai)
-
-	return nil
+	return &rai, nil
 }
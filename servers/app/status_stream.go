@@ -0,0 +1,299 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"sync"
+	"time"
+
+	"miren.dev/runtime/api/app/app_v1alpha"
+)
+
+// StatusFunc returns the current status of an app.
+type StatusFunc func(ctx context.Context, name string) (*app_v1alpha.ApplicationStatus, error)
+
+// StatusStream serves live app status as server-sent events, so dashboards
+// can follow an app without polling AppInfo. A subscriber first receives a
+// "status" event with the whole ApplicationStatus as JSON, then a "delta"
+// event with just the fields that changed whenever it changes, a removed
+// field being sent as null.
+//
+// Each app with subscribers is polled once however many are following it.
+// Subscribers that fall behind don't hold up the others: their undelivered
+// deltas are merged so they catch up with the latest status.
+type StatusStream struct {
+	Log      *slog.Logger
+	Status   StatusFunc
+	Interval time.Duration
+
+	mu    sync.Mutex
+	feeds map[string]*statusFeed
+}
+
+func NewStatusStream(log *slog.Logger, status StatusFunc, interval time.Duration) *StatusStream {
+	return &StatusStream{
+		Log:      log.With("module", "app-status-stream"),
+		Status:   status,
+		Interval: interval,
+		feeds:    make(map[string]*statusFeed),
+	}
+}
+
+// statusFields is an ApplicationStatus split into its JSON fields, so that
+// statuses can be compared and sent field by field.
+type statusFields map[string]json.RawMessage
+
+func splitStatus(st *app_v1alpha.ApplicationStatus) (statusFields, error) {
+	data, err := json.Marshal(st)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields statusFields
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
+
+// delta returns the fields of next that differ from prev, with fields prev
+// had and next doesn't set to null.
+func (prev statusFields) delta(next statusFields) statusFields {
+	d := statusFields{}
+
+	for k, v := range next {
+		if !bytes.Equal(prev[k], v) {
+			d[k] = v
+		}
+	}
+
+	for k := range prev {
+		if _, ok := next[k]; !ok {
+			d[k] = json.RawMessage("null")
+		}
+	}
+
+	return d
+}
+
+// statusEvent is an event waiting to be sent to a subscriber.
+type statusEvent struct {
+	full   bool
+	fields statusFields
+}
+
+func (e *statusEvent) name() string {
+	if e.full {
+		return "status"
+	}
+
+	return "delta"
+}
+
+// statusSub is a subscriber to a feed. It holds at most one pending event,
+// merging new ones into it, so a slow subscriber only ever has the latest
+// status to catch up on.
+type statusSub struct {
+	mu      sync.Mutex
+	pending *statusEvent
+	notify  chan struct{}
+}
+
+func newStatusSub() *statusSub {
+	return &statusSub{notify: make(chan struct{}, 1)}
+}
+
+func (s *statusSub) push(ev *statusEvent) {
+	s.mu.Lock()
+
+	if s.pending == nil {
+		s.pending = &statusEvent{full: ev.full, fields: maps.Clone(ev.fields)}
+	} else {
+		s.pending.full = s.pending.full || ev.full
+		maps.Copy(s.pending.fields, ev.fields)
+	}
+
+	s.mu.Unlock()
+
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (s *statusSub) take() *statusEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ev := s.pending
+	s.pending = nil
+
+	return ev
+}
+
+// statusFeed polls the status of one app for its subscribers.
+type statusFeed struct {
+	cancel context.CancelFunc
+	subs   map[*statusSub]struct{}
+	last   statusFields
+}
+
+func (s *StatusStream) subscribe(name string) *statusSub {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.feeds == nil {
+		s.feeds = make(map[string]*statusFeed)
+	}
+
+	sub := newStatusSub()
+
+	feed, ok := s.feeds[name]
+	if !ok {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		feed = &statusFeed{
+			cancel: cancel,
+			subs:   make(map[*statusSub]struct{}),
+		}
+
+		s.feeds[name] = feed
+
+		go s.poll(ctx, name, feed)
+	} else if feed.last != nil {
+		sub.push(&statusEvent{full: true, fields: feed.last})
+	}
+
+	feed.subs[sub] = struct{}{}
+
+	return sub
+}
+
+func (s *StatusStream) unsubscribe(name string, sub *statusSub) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	feed, ok := s.feeds[name]
+	if !ok {
+		return
+	}
+
+	delete(feed.subs, sub)
+
+	if len(feed.subs) == 0 {
+		feed.cancel()
+		delete(s.feeds, name)
+	}
+}
+
+func (s *StatusStream) poll(ctx context.Context, name string, feed *statusFeed) {
+	interval := s.Interval
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.update(ctx, name, feed)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// update fetches the app's status and publishes what changed.
+func (s *StatusStream) update(ctx context.Context, name string, feed *statusFeed) {
+	st, err := s.Status(ctx, name)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.Log.Warn("failed to get app status", "app", name, "error", err)
+		}
+		return
+	}
+
+	fields, err := splitStatus(st)
+	if err != nil {
+		s.Log.Error("failed to encode app status", "app", name, "error", err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ev := &statusEvent{full: feed.last == nil, fields: fields}
+
+	if !ev.full {
+		ev.fields = feed.last.delta(fields)
+		if len(ev.fields) == 0 {
+			return
+		}
+	}
+
+	feed.last = fields
+
+	for sub := range feed.subs {
+		sub.push(ev)
+	}
+}
+
+// ServeHTTP streams the status of the app named by the "app" path value until
+// the client disconnects.
+func (s *StatusStream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("app")
+	if name == "" {
+		http.Error(w, "missing app name", http.StatusBadRequest)
+		return
+	}
+
+	rc := http.NewResponseController(w)
+
+	sub := s.subscribe(name)
+	defer s.unsubscribe(name, sub)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	if err := rc.Flush(); err != nil {
+		s.Log.Error("status stream requires flushing", "error", err)
+		return
+	}
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-sub.notify:
+		}
+
+		ev := sub.take()
+		if ev == nil {
+			continue
+		}
+
+		data, err := json.Marshal(ev.fields)
+		if err != nil {
+			s.Log.Error("failed to encode app status", "app", name, "error", err)
+			return
+		}
+
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.name(), data); err != nil {
+			return
+		}
+
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"miren.dev/runtime/api/app/app_v1alpha"
+)
+
+// readEvent reads the next server-sent event from sc.
+func readEvent(t *testing.T, sc *bufio.Scanner) (string, map[string]any) {
+	var (
+		name string
+		data map[string]any
+	)
+
+	for sc.Scan() {
+		line := sc.Text()
+
+		switch {
+		case line == "":
+			return name, data
+		case strings.HasPrefix(line, "event: "):
+			name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &data))
+		}
+	}
+
+	t.Fatalf("stream ended: %v", sc.Err())
+	return "", nil
+}
+
+func TestStatusStream(t *testing.T) {
+	r := require.New(t)
+
+	var rps atomic.Int64
+	rps.Store(1)
+
+	ss := NewStatusStream(slog.Default(), func(ctx context.Context, name string) (*app_v1alpha.ApplicationStatus, error) {
+		var st app_v1alpha.ApplicationStatus
+		st.SetName(name)
+		st.SetActiveVersion("v1")
+		st.SetRequestsPerSecond(float64(rps.Load()))
+		return &st, nil
+	}, 10*time.Millisecond)
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /apps/{app}/status", ss)
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/apps/web/status", nil)
+	r.NoError(err)
+
+	resp, err := http.DefaultClient.Do(req)
+	r.NoError(err)
+	defer resp.Body.Close()
+
+	r.Equal(http.StatusOK, resp.StatusCode)
+	r.Equal("text/event-stream", resp.Header.Get("Content-Type"))
+
+	sc := bufio.NewScanner(resp.Body)
+
+	name, data := readEvent(t, sc)
+	r.Equal("status", name)
+	r.Equal("web", data["name"])
+	r.Equal("v1", data["active_version"])
+	r.Equal(float64(1), data["requests_per_second"])
+
+	rps.Store(7)
+
+	name, data = readEvent(t, sc)
+	r.Equal("delta", name)
+	r.Equal(map[string]any{"requests_per_second": float64(7)}, data)
+
+	// A second subscriber starts from the latest status.
+	resp2, err := http.Get(srv.URL + "/apps/web/status")
+	r.NoError(err)
+
+	name, data = readEvent(t, bufio.NewScanner(resp2.Body))
+	r.Equal("status", name)
+	r.Equal(float64(7), data["requests_per_second"])
+
+	resp2.Body.Close()
+	cancel()
+
+	r.Eventually(func() bool {
+		ss.mu.Lock()
+		defer ss.mu.Unlock()
+		return len(ss.feeds) == 0
+	}, 5*time.Second, 10*time.Millisecond, "feed not stopped after clients disconnected")
+}
+
+func TestStatusSubDropsToLatest(t *testing.T) {
+	r := require.New(t)
+
+	sub := newStatusSub()
+
+	sub.push(&statusEvent{full: true, fields: statusFields{
+		"name":                json.RawMessage(`"web"`),
+		"requests_per_second": json.RawMessage(`1`),
+	}})
+
+	for i := range 100 {
+		sub.push(&statusEvent{fields: statusFields{
+			"requests_per_second": json.RawMessage(strings.Repeat("9", i+1)),
+		}})
+	}
+
+	sub.push(&statusEvent{fields: statusFields{"active_version": json.RawMessage(`"v2"`)}})
+
+	ev := sub.take()
+	r.NotNil(ev)
+	r.True(ev.full, "merged events should keep the full status")
+	r.Equal(statusFields{
+		"name":                json.RawMessage(`"web"`),
+		"requests_per_second": json.RawMessage(strings.Repeat("9", 100)),
+		"active_version":      json.RawMessage(`"v2"`),
+	}, ev.fields)
+
+	r.Nil(sub.take())
+}
+
+func TestStatusDelta(t *testing.T) {
+	prev := statusFields{
+		"name":         json.RawMessage(`"web"`),
+		"pools":        json.RawMessage(`[]`),
+		"last_min_cpu": json.RawMessage(`1`),
+	}
+
+	next := statusFields{
+		"name":         json.RawMessage(`"web"`),
+		"last_min_cpu": json.RawMessage(`2`),
+	}
+
+	require.Equal(t, statusFields{
+		"pools":        json.RawMessage(`null`),
+		"last_min_cpu": json.RawMessage(`2`),
+	}, prev.delta(next))
+}